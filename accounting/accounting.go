@@ -1,23 +1,76 @@
 package accounting
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/aceextension/accounting/repository"
 	"github.com/aceextension/accounting/service"
 	"github.com/aceextension/core/db"
+	"github.com/aceextension/files"
 	"github.com/aceextension/fiscal"
+	fiscalDomain "github.com/aceextension/fiscal/domain"
+	"github.com/google/uuid"
 )
 
+// bundleInterval is how often the worker looks for queued export bundles
+const bundleInterval = 15 * time.Second
+
 var (
 	Service service.AccountingService
+	// BundleService prepares year-end export bundles; other modules register their reports on it
+	BundleService service.ExportBundleService
+	// InterCompanyService links companies with a common owner; crm sets its purchase mirror
+	InterCompanyService service.InterCompanyService
+	// YearEndCloseService closes the ledger and carries balances forward when fiscal.Service closes a year
+	YearEndCloseService service.YearEndCloseService
 )
 
 func Init() {
 	log.Println("Initializing Accounting Module...")
 	repoAccount := repository.NewPostgresAccountRepository(db.MainPool)
 	repoJournal := repository.NewPostgresJournalRepository(db.MainPool)
+	repoBundle := repository.NewPostgresExportBundleRepository(db.MainPool)
+	repoInterCompany := repository.NewPostgresInterCompanyRepository(db.MainPool)
+	repoPosting := repository.NewPostgresPostingAccountRepository(db.MainPool)
+	repoOpening := repository.NewPostgresOpeningBalanceRepository(db.MainPool)
 
-	Service = service.NewAccountingService(repoAccount, repoJournal, fiscal.Service)
+	// Sales, purchase bills and other documents are posted through Service once their
+	// modules register a posting source; call accounting.Init before them
+	Service = service.NewAccountingService(repoAccount, repoJournal, repoPosting, fiscal.Service, fiscal.DateDisplay)
+	// Bundles are saved to files.ExportStore (MinIO or local disk); call files.Init first
+	BundleService = service.NewExportBundleService(repoBundle, repoAccount, repoJournal, fiscal.Service, files.ExportStore, fiscal.DateDisplay)
+	InterCompanyService = service.NewInterCompanyService(repoInterCompany, repoAccount, repoJournal)
+	YearEndCloseService = service.NewYearEndCloseService(repoAccount, repoJournal, repoPosting, repoOpening, fiscal.Service)
+	// Closing a fiscal year fails, and the year stays open, if its ledger cannot be closed
+	fiscal.Service.RegisterCloseHook(func(ctx context.Context, fy *fiscalDomain.FiscalYear, closedBy uuid.UUID) error {
+		_, err := YearEndCloseService.CloseYear(ctx, fy, closedBy)
+		return err
+	})
 	log.Println("Accounting Module Initialized")
 }
+
+// StartExportBundleWorker builds queued export bundles every bundleInterval and
+// removes expired ones hourly. Call after Init and after other modules have
+// registered their bundle sections.
+func StartExportBundleWorker() {
+	go func() {
+		build := time.NewTicker(bundleInterval)
+		defer build.Stop()
+		purge := time.NewTicker(time.Hour)
+		defer purge.Stop()
+		for {
+			select {
+			case <-build.C:
+				if err := BundleService.ProcessQueued(context.Background()); err != nil {
+					log.Printf("Export bundle worker error: %v", err)
+				}
+			case <-purge.C:
+				if err := BundleService.PurgeExpired(context.Background()); err != nil {
+					log.Printf("Export bundle purge error: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -26,8 +26,10 @@ type Account struct {
 	ParentID    *uuid.UUID  `json:"parentId"` // For hierarchical CoA
 	IsActive    bool        `json:"isActive"`
 	Description *string     `json:"description"`
-	CreatedAt   time.Time   `json:"createdAt"`
-	UpdatedAt   time.Time   `json:"updatedAt"`
+	// MergedIntoID is the account this one was merged into; merged accounts are archived
+	MergedIntoID *uuid.UUID `json:"mergedIntoId,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
 }
 
 func NewAccount(tenantID uuid.UUID, code, name string, accType AccountType) *Account {
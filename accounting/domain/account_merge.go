@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAccountNotFound is returned for an account the tenant does not have
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrAccountMergeInvalid is returned when an account is merged into itself or into an archived account
+	ErrAccountMergeInvalid = errors.New("invalid account merge")
+	// ErrAccountMergeTypeMismatch is returned when the accounts are of different types
+	ErrAccountMergeTypeMismatch = errors.New("accounts of different types cannot be merged")
+	// ErrAccountMerged is returned for an account already merged into another
+	ErrAccountMerged = errors.New("account was merged into another account")
+)
+
+// AccountMerge moves everything booked to a duplicate account (the source) onto the
+// account kept (the target), then archives the source. Balances are natural, as of
+// the merge, and the target's balance after is its own plus the source's.
+type AccountMerge struct {
+	TenantID uuid.UUID `json:"tenantId"`
+	SourceID uuid.UUID `json:"sourceAccountId"`
+	TargetID uuid.UUID `json:"targetAccountId"`
+
+	LinesMoved        int64 `json:"linesMoved"`        // Journal lines re-pointed, in every fiscal year
+	PostingRolesMoved int64 `json:"postingRolesMoved"` // Posting roles mapped to the source
+	LinksMoved        int64 `json:"linksMoved"`        // Inter-company links using the source
+	ChildrenMoved     int64 `json:"childrenMoved"`     // Sub-accounts now under the target
+
+	SourceBalance       float64 `json:"sourceBalance"`
+	TargetBalanceBefore float64 `json:"targetBalanceBefore"`
+	TargetBalanceAfter  float64 `json:"targetBalanceAfter"`
+
+	MergedBy uuid.UUID `json:"mergedBy"`
+	MergedAt time.Time `json:"mergedAt"`
+}
+
+// NewAccountMerge checks the accounts can be merged and prepares the merge
+func NewAccountMerge(source, target *Account, mergedBy uuid.UUID) (*AccountMerge, error) {
+	if source.ID == target.ID {
+		return nil, fmt.Errorf("%w: an account cannot be merged into itself", ErrAccountMergeInvalid)
+	}
+	if source.MergedIntoID != nil {
+		return nil, ErrAccountMerged
+	}
+	if target.MergedIntoID != nil || !target.IsActive {
+		return nil, fmt.Errorf("%w: the account kept must be active", ErrAccountMergeInvalid)
+	}
+	if source.Type != target.Type {
+		return nil, ErrAccountMergeTypeMismatch
+	}
+
+	return &AccountMerge{
+		TenantID: source.TenantID,
+		SourceID: source.ID,
+		TargetID: target.ID,
+		MergedBy: mergedBy,
+		MergedAt: time.Now(),
+	}, nil
+}
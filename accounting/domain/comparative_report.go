@@ -0,0 +1,223 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	fiscalUtils "github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidComparativeReport is returned for an unknown comparative report or a
+// fiscal year compared with itself
+var ErrInvalidComparativeReport = errors.New("invalid comparative report")
+
+// ComparativeReportType is the figure a comparative report compares across fiscal years
+type ComparativeReportType string
+
+const (
+	ComparativeSales         ComparativeReportType = "sales"           // Revenue accounts
+	ComparativeExpenses      ComparativeReportType = "expenses"        // Expense accounts
+	ComparativeProfitAndLoss ComparativeReportType = "profit-and-loss" // Revenue less expenses
+)
+
+// Valid reports whether the report type is known
+func (r ComparativeReportType) Valid() bool {
+	switch r {
+	case ComparativeSales, ComparativeExpenses, ComparativeProfitAndLoss:
+		return true
+	}
+	return false
+}
+
+// amount is the day's figure for the report, on the accounts' normal side
+func (r ComparativeReportType) amount(t DailyTypeTotal) float64 {
+	natural := AccountTotal{Debit: t.Debit, Credit: t.Credit}.NaturalBalance(t.Type)
+	switch {
+	case t.Type == AccountTypeRevenue && r != ComparativeExpenses:
+		return natural
+	case t.Type == AccountTypeExpense && r == ComparativeExpenses:
+		return natural
+	case t.Type == AccountTypeExpense && r == ComparativeProfitAndLoss:
+		return -natural
+	}
+	return 0
+}
+
+// FiscalMonthNames are the Nepali months in fiscal year order, Shrawan to Ashad
+var FiscalMonthNames = [12]string{
+	"Shrawan", "Bhadra", "Ashwin", "Kartik", "Mangsir", "Poush",
+	"Magh", "Falgun", "Chaitra", "Baishakh", "Jestha", "Ashad",
+}
+
+// DailyTypeTotal is the posted debits and credits of one account type on one day
+type DailyTypeTotal struct {
+	Date   time.Time
+	Type   AccountType
+	Debit  float64
+	Credit float64
+}
+
+// FiscalDay places a date in its Nepali fiscal month
+type FiscalDay struct {
+	Date  time.Time
+	Month int // 1 is Shrawan, 12 is Ashad
+	Day   int // Day of the Nepali month
+}
+
+// ComparativeYear is one side of a comparison: a fiscal year's days, in order, and its
+// revenue and expense totals per day
+type ComparativeYear struct {
+	FiscalYearID uuid.UUID
+	Name         string
+	Days         []FiscalDay
+	Totals       []DailyTypeTotal
+}
+
+// ComparativeMonth compares one Nepali month with the same month of the earlier year.
+// Nepali months run 29 to 32 days and differ from year to year, so besides the month
+// totals the daily averages are compared. For the month in progress the earlier year
+// is cut at the same day of the month, e.g. Shrawan 1-10 against last Shrawan 1-10;
+// months not yet started have no change.
+type ComparativeMonth struct {
+	FiscalMonth          int      `json:"fiscalMonth"` // 1 is Shrawan, 12 is Ashad
+	Name                 string   `json:"name"`
+	Days                 int      `json:"days"`        // Days in the month this year
+	DaysElapsed          int      `json:"daysElapsed"` // Days of the month up to the report date
+	Amount               float64  `json:"amount"`
+	PreviousDays         int      `json:"previousDays"` // Days of the earlier month compared
+	PreviousAmount       float64  `json:"previousAmount"`
+	PreviousMonthDays    int      `json:"previousMonthDays"`
+	PreviousMonthAmount  float64  `json:"previousMonthAmount"` // The whole earlier month
+	Change               *float64 `json:"change"`
+	ChangePercent        *float64 `json:"changePercent"` // Nil when the earlier amount is zero
+	DailyAverage         *float64 `json:"dailyAverage"`
+	PreviousDailyAverage *float64 `json:"previousDailyAverage"`
+	DailyChangePercent   *float64 `json:"dailyChangePercent"` // Change in the daily averages
+}
+
+// ComparativeTotal compares the year to date with the same span of the earlier year
+type ComparativeTotal struct {
+	Amount             float64  `json:"amount"`
+	PreviousAmount     float64  `json:"previousAmount"`
+	PreviousYearAmount float64  `json:"previousYearAmount"` // The whole earlier year
+	Change             float64  `json:"change"`
+	ChangePercent      *float64 `json:"changePercent"`
+}
+
+// ComparativeReport compares a fiscal year with an earlier one month by Nepali month.
+// Percentages are against the absolute earlier amount, so a smaller loss is a rise.
+type ComparativeReport struct {
+	Report                ComparativeReportType `json:"report"`
+	FiscalYearID          uuid.UUID             `json:"fiscalYearId"`
+	FiscalYearName        string                `json:"fiscalYearName"`
+	CompareFiscalYearID   uuid.UUID             `json:"compareFiscalYearId"`
+	CompareFiscalYearName string                `json:"compareFiscalYearName"`
+	AsOf                  time.Time             `json:"asOf"`
+	AsOfBS                string                `json:"asOfBs"`    // Filled in by SetDates
+	AsOfLabel             string                `json:"asOfLabel"` // Both calendars as printed, the tenant's first
+	Months                []*ComparativeMonth   `json:"months"`
+	Total                 ComparativeTotal      `json:"total"`
+}
+
+// NewComparativeReport compares current up to asOf with previous, month by month.
+// Year-end closing entries must already be left out of the totals.
+func NewComparativeReport(report ComparativeReportType, current, previous ComparativeYear, asOf time.Time) *ComparativeReport {
+	r := &ComparativeReport{
+		Report:                report,
+		FiscalYearID:          current.FiscalYearID,
+		FiscalYearName:        current.Name,
+		CompareFiscalYearID:   previous.FiscalYearID,
+		CompareFiscalYearName: previous.Name,
+		AsOf:                  asOf,
+		Months:                make([]*ComparativeMonth, 12),
+	}
+	for i := range r.Months {
+		r.Months[i] = &ComparativeMonth{FiscalMonth: i + 1, Name: FiscalMonthNames[i]}
+	}
+
+	currentAmounts := dailyAmounts(report, current.Totals)
+	for _, day := range current.Days {
+		m := r.Months[day.Month-1]
+		m.Days++
+		if day.Date.After(asOf) {
+			continue
+		}
+		m.DaysElapsed++
+		m.Amount += currentAmounts[day.Date.Format("2006-01-02")]
+	}
+
+	previousAmounts := dailyAmounts(report, previous.Totals)
+	for _, day := range previous.Days {
+		m := r.Months[day.Month-1]
+		amount := previousAmounts[day.Date.Format("2006-01-02")]
+		m.PreviousMonthDays++
+		m.PreviousMonthAmount += amount
+		r.Total.PreviousYearAmount += amount
+		// A finished month is compared whole, however long either year's month is
+		if m.DaysElapsed > 0 && (m.DaysElapsed == m.Days || day.Day <= m.DaysElapsed) {
+			m.PreviousDays++
+			m.PreviousAmount += amount
+		}
+	}
+
+	for _, m := range r.Months {
+		m.Amount = roundAmount(m.Amount)
+		m.PreviousAmount = roundAmount(m.PreviousAmount)
+		m.PreviousMonthAmount = roundAmount(m.PreviousMonthAmount)
+		r.Total.Amount += m.Amount
+		r.Total.PreviousAmount += m.PreviousAmount
+		if m.DaysElapsed == 0 {
+			continue
+		}
+
+		change := roundAmount(m.Amount - m.PreviousAmount)
+		m.Change = &change
+		m.ChangePercent = percentChange(m.Amount, m.PreviousAmount)
+		m.DailyAverage = dailyAverage(m.Amount, m.DaysElapsed)
+		m.PreviousDailyAverage = dailyAverage(m.PreviousAmount, m.PreviousDays)
+		if m.PreviousDailyAverage != nil {
+			m.DailyChangePercent = percentChange(*m.DailyAverage, *m.PreviousDailyAverage)
+		}
+	}
+
+	r.Total.Amount = roundAmount(r.Total.Amount)
+	r.Total.PreviousAmount = roundAmount(r.Total.PreviousAmount)
+	r.Total.PreviousYearAmount = roundAmount(r.Total.PreviousYearAmount)
+	r.Total.Change = roundAmount(r.Total.Amount - r.Total.PreviousAmount)
+	r.Total.ChangePercent = percentChange(r.Total.Amount, r.Total.PreviousAmount)
+	return r
+}
+
+// SetDates fills in the as-of date in BS and its printed label
+func (r *ComparativeReport) SetDates(display fiscalUtils.DateDisplay) {
+	r.AsOfBS = fiscalUtils.BSDate(r.AsOf)
+	r.AsOfLabel = fiscalUtils.FormatDualDate(r.AsOf, display)
+}
+
+// dailyAmounts sums the report's figure per day, keyed by YYYY-MM-DD
+func dailyAmounts(report ComparativeReportType, totals []DailyTypeTotal) map[string]float64 {
+	amounts := make(map[string]float64)
+	for _, t := range totals {
+		amounts[t.Date.Format("2006-01-02")] += report.amount(t)
+	}
+	return amounts
+}
+
+// percentChange is the change from previous to current in percent, nil when previous is zero
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	pct := math.Round((current-previous)/math.Abs(previous)*10000) / 100
+	return &pct
+}
+
+func dailyAverage(amount float64, days int) *float64 {
+	if days == 0 {
+		return nil
+	}
+	avg := roundAmount(amount / float64(days))
+	return &avg
+}
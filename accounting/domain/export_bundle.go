@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BundleStatus string
+
+const (
+	BundleStatusQueued    BundleStatus = "queued"
+	BundleStatusRunning   BundleStatus = "running"
+	BundleStatusCompleted BundleStatus = "completed"
+	BundleStatusFailed    BundleStatus = "failed"
+	BundleStatusExpired   BundleStatus = "expired" // Download window passed and the file was removed
+)
+
+// Report formats in a bundle
+const (
+	BundleFormatXLSX = "xlsx"
+	BundleFormatPDF  = "pdf"
+)
+
+// BundleLinkTTL is how long a finished bundle stays downloadable
+const BundleLinkTTL = 7 * 24 * time.Hour
+
+var (
+	ErrBundleNotFound   = errors.New("export bundle not found")
+	ErrBundleInProgress = errors.New("an export bundle for this fiscal year is already being prepared")
+	ErrBundleNotReady   = errors.New("export bundle is not ready")
+	ErrBundleExpired    = errors.New("export bundle download has expired")
+	ErrInvalidBundle    = errors.New("invalid export bundle request")
+)
+
+// ExportBundle is a year-end zip of reports for the tenant's accountant: trial
+// balance, account ledgers, VAT registers and aging, as of the fiscal year end (Ashad end)
+type ExportBundle struct {
+	ID             uuid.UUID      `json:"id"`
+	TenantID       uuid.UUID      `json:"tenantId"`
+	FiscalYearID   uuid.UUID      `json:"fiscalYearId"`
+	FiscalYearName string         `json:"fiscalYearName"` // e.g. "2082/83"
+	PeriodStart    time.Time      `json:"periodStart"`
+	PeriodEnd      time.Time      `json:"periodEnd"` // Reports are as of this day
+	Formats        []string       `json:"formats"`
+	Status         BundleStatus   `json:"status"`
+	Progress       int            `json:"progress"`    // Percent of reports written
+	CurrentStep    *string        `json:"currentStep"` // Report being written
+	Reports        []BundleReport `json:"reports"`
+	FileName       string         `json:"fileName"`
+	StorageKey     *string        `json:"-"`
+	SizeBytes      *int64         `json:"sizeBytes"`
+	Error          *string        `json:"error"`
+	RequestedBy    uuid.UUID      `json:"requestedBy"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	StartedAt      *time.Time     `json:"startedAt"`
+	CompletedAt    *time.Time     `json:"completedAt"`
+	ExpiresAt      *time.Time     `json:"expiresAt"` // Download link expiry
+}
+
+// BundleReport lists a report written into a bundle
+type BundleReport struct {
+	Name  string   `json:"name"` // File name stem, e.g. "trial-balance"
+	Title string   `json:"title"`
+	Files []string `json:"files"`
+	Rows  int      `json:"rows"`
+}
+
+// BundlePeriod is what a bundle report covers
+type BundlePeriod struct {
+	TenantID       uuid.UUID
+	FiscalYearName string
+	Start          time.Time
+	End            time.Time
+}
+
+// ReportColumn is a column of a bundle report table
+type ReportColumn struct {
+	Header string
+	Amount bool // Right-aligned with two decimals
+}
+
+// NewExportBundle queues a bundle for a fiscal year. Formats default to XLSX and PDF.
+func NewExportBundle(tenantID, fiscalYearID uuid.UUID, fiscalYearName string, start, end time.Time, formats []string, requestedBy uuid.UUID) (*ExportBundle, error) {
+	if len(formats) == 0 {
+		formats = []string{BundleFormatXLSX, BundleFormatPDF}
+	}
+	normalized := []string{}
+	for _, format := range formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != BundleFormatXLSX && format != BundleFormatPDF {
+			return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidBundle, format)
+		}
+		if !slices.Contains(normalized, format) {
+			normalized = append(normalized, format)
+		}
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: fiscal year ends before it starts", ErrInvalidBundle)
+	}
+
+	return &ExportBundle{
+		ID:             uuid.New(),
+		TenantID:       tenantID,
+		FiscalYearID:   fiscalYearID,
+		FiscalYearName: fiscalYearName,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		Formats:        normalized,
+		Status:         BundleStatusQueued,
+		Reports:        []BundleReport{},
+		FileName:       "year-end-" + strings.NewReplacer("/", "-", " ", "-").Replace(fiscalYearName) + ".zip",
+		RequestedBy:    requestedBy,
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+// HasFormat reports whether the bundle includes reports in a format
+func (b *ExportBundle) HasFormat(format string) bool {
+	return slices.Contains(b.Formats, format)
+}
+
+// Period returns what the bundle's reports cover
+func (b *ExportBundle) Period() BundlePeriod {
+	return BundlePeriod{TenantID: b.TenantID, FiscalYearName: b.FiscalYearName, Start: b.PeriodStart, End: b.PeriodEnd}
+}
+
+// Downloadable reports whether the finished bundle can still be downloaded at now
+func (b *ExportBundle) Downloadable(now time.Time) error {
+	switch {
+	case b.Status == BundleStatusExpired, b.Status == BundleStatusCompleted && b.ExpiresAt != nil && !now.Before(*b.ExpiresAt):
+		return ErrBundleExpired
+	case b.Status != BundleStatusCompleted || b.StorageKey == nil:
+		return ErrBundleNotReady
+	}
+	return nil
+}
+
+// AccountTotal is an account's posted debits and credits over a period
+type AccountTotal struct {
+	AccountID uuid.UUID
+	Debit     float64
+	Credit    float64
+}
+
+// Balance is debits less credits
+func (t AccountTotal) Balance() float64 {
+	return t.Debit - t.Credit
+}
@@ -0,0 +1,209 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	fiscalUtils "github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidReportPeriod is returned when a report's dates are missing, malformed or reversed
+var ErrInvalidReportPeriod = errors.New("invalid report period")
+
+// DebitNormal reports whether accounts of the type carry debit balances (assets and
+// expenses); liabilities, equity and revenue carry credit balances
+func (t AccountType) DebitNormal() bool {
+	return t == AccountTypeAsset || t == AccountTypeExpense
+}
+
+// NaturalBalance is the account's balance with the sign of its type: positive when it
+// is on the account's normal side, e.g. a credit balance on a revenue account
+func (t AccountTotal) NaturalBalance(accType AccountType) float64 {
+	if accType.DebitNormal() {
+		return t.Balance()
+	}
+	return -t.Balance()
+}
+
+// ReportPeriod is the range of transaction dates a financial report covers. A nil
+// From starts at the first entry.
+type ReportPeriod struct {
+	From *time.Time `json:"from,omitempty"`
+	To   time.Time  `json:"to"`
+	// Filled in by SetDates
+	FromBS string `json:"fromBs,omitempty"` // Bikram Sambat, YYYY-MM-DD
+	ToBS   string `json:"toBs"`
+	Label  string `json:"label"` // Both calendars as printed, the tenant's first
+}
+
+// SetDates fills in the period's BS dates and its printed label
+func (p *ReportPeriod) SetDates(display fiscalUtils.DateDisplay) {
+	p.ToBS = fiscalUtils.BSDate(p.To)
+	if p.From == nil {
+		p.Label = "Up to " + fiscalUtils.FormatDualDate(p.To, display)
+		return
+	}
+	p.FromBS = fiscalUtils.BSDate(*p.From)
+	p.Label = fiscalUtils.FormatDualRange(*p.From, p.To, display)
+}
+
+// TrialBalanceLine is one account's opening balance, movements and closing balance.
+// Balances are debit positive.
+type TrialBalanceLine struct {
+	AccountID      uuid.UUID   `json:"accountId"`
+	Code           string      `json:"code"`
+	Name           string      `json:"name"`
+	Type           AccountType `json:"type"`
+	OpeningBalance float64     `json:"openingBalance"`
+	Debit          float64     `json:"debit"`  // Posted in the period
+	Credit         float64     `json:"credit"` // Posted in the period
+	ClosingDebit   float64     `json:"closingDebit"`
+	ClosingCredit  float64     `json:"closingCredit"`
+}
+
+// TrialBalance lists every account with a balance or movement in the period. Posted
+// entries always balance, so the closing columns agree unless the books are damaged.
+type TrialBalance struct {
+	Period             ReportPeriod        `json:"period"`
+	Lines              []*TrialBalanceLine `json:"lines"`
+	TotalDebit         float64             `json:"totalDebit"`
+	TotalCredit        float64             `json:"totalCredit"`
+	TotalClosingDebit  float64             `json:"totalClosingDebit"`
+	TotalClosingCredit float64             `json:"totalClosingCredit"`
+	Balanced           bool                `json:"balanced"`
+}
+
+// NewTrialBalance builds a trial balance from the opening totals (before the period)
+// and the period's movements; accounts with neither are left out
+func NewTrialBalance(period ReportPeriod, accounts []*Account, opening, movements map[uuid.UUID]AccountTotal) *TrialBalance {
+	tb := &TrialBalance{Period: period, Lines: []*TrialBalanceLine{}}
+	for _, acc := range accounts {
+		open, hasOpening := opening[acc.ID]
+		moved, hasMovement := movements[acc.ID]
+		if !hasOpening && !hasMovement {
+			continue
+		}
+
+		line := &TrialBalanceLine{
+			AccountID:      acc.ID,
+			Code:           acc.Code,
+			Name:           acc.Name,
+			Type:           acc.Type,
+			OpeningBalance: roundAmount(open.Balance()),
+			Debit:          roundAmount(moved.Debit),
+			Credit:         roundAmount(moved.Credit),
+		}
+		if closing := roundAmount(open.Balance() + moved.Balance()); closing > 0 {
+			line.ClosingDebit = closing
+		} else if closing < 0 {
+			line.ClosingCredit = -closing
+		}
+		if line.OpeningBalance == 0 && line.Debit == 0 && line.Credit == 0 {
+			continue
+		}
+
+		tb.Lines = append(tb.Lines, line)
+		tb.TotalDebit += line.Debit
+		tb.TotalCredit += line.Credit
+		tb.TotalClosingDebit += line.ClosingDebit
+		tb.TotalClosingCredit += line.ClosingCredit
+	}
+
+	tb.TotalDebit = roundAmount(tb.TotalDebit)
+	tb.TotalCredit = roundAmount(tb.TotalCredit)
+	tb.TotalClosingDebit = roundAmount(tb.TotalClosingDebit)
+	tb.TotalClosingCredit = roundAmount(tb.TotalClosingCredit)
+	tb.Balanced = tb.TotalClosingDebit == tb.TotalClosingCredit
+	return tb
+}
+
+// ReportLine is one account's amount in a statement, signed by its type's normal side
+type ReportLine struct {
+	AccountID uuid.UUID `json:"accountId"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Amount    float64   `json:"amount"`
+}
+
+// ReportSection groups the accounts of one type in a statement
+type ReportSection struct {
+	Type  AccountType   `json:"type"`
+	Lines []*ReportLine `json:"lines"`
+	Total float64       `json:"total"`
+}
+
+// newReportSection collects the non-zero natural balances of the accounts of a type
+func newReportSection(accType AccountType, accounts []*Account, totals map[uuid.UUID]AccountTotal) *ReportSection {
+	section := &ReportSection{Type: accType, Lines: []*ReportLine{}}
+	for _, acc := range accounts {
+		if acc.Type != accType {
+			continue
+		}
+		amount := roundAmount(totals[acc.ID].NaturalBalance(accType))
+		if amount == 0 {
+			continue
+		}
+		section.Lines = append(section.Lines, &ReportLine{AccountID: acc.ID, Code: acc.Code, Name: acc.Name, Amount: amount})
+		section.Total += amount
+	}
+	section.Total = roundAmount(section.Total)
+	return section
+}
+
+// ProfitAndLoss is the income statement for a period
+type ProfitAndLoss struct {
+	Period    ReportPeriod   `json:"period"`
+	Revenue   *ReportSection `json:"revenue"`
+	Expenses  *ReportSection `json:"expenses"`
+	NetProfit float64        `json:"netProfit"` // Negative for a loss
+}
+
+// NewProfitAndLoss builds the income statement from the period's movements
+func NewProfitAndLoss(period ReportPeriod, accounts []*Account, movements map[uuid.UUID]AccountTotal) *ProfitAndLoss {
+	pl := &ProfitAndLoss{
+		Period:   period,
+		Revenue:  newReportSection(AccountTypeRevenue, accounts, movements),
+		Expenses: newReportSection(AccountTypeExpense, accounts, movements),
+	}
+	pl.NetProfit = roundAmount(pl.Revenue.Total - pl.Expenses.Total)
+	return pl
+}
+
+// BalanceSheet is the financial position as of a date. Revenue and expenses not yet
+// closed to retained earnings by a year-end close are shown as current earnings, so
+// assets equal liabilities plus equity.
+type BalanceSheet struct {
+	AsOf                      time.Time      `json:"asOf"`
+	AsOfBS                    string         `json:"asOfBs"`    // Filled in by SetDates
+	AsOfLabel                 string         `json:"asOfLabel"` // Both calendars as printed, the tenant's first
+	Assets                    *ReportSection `json:"assets"`
+	Liabilities               *ReportSection `json:"liabilities"`
+	Equity                    *ReportSection `json:"equity"`
+	CurrentEarnings           float64        `json:"currentEarnings"`
+	TotalEquity               float64        `json:"totalEquity"` // Equity accounts and current earnings
+	TotalLiabilitiesAndEquity float64        `json:"totalLiabilitiesAndEquity"`
+	Balanced                  bool           `json:"balanced"`
+}
+
+// NewBalanceSheet builds the balance sheet from cumulative totals up to asOf
+func NewBalanceSheet(asOf time.Time, accounts []*Account, totals map[uuid.UUID]AccountTotal) *BalanceSheet {
+	earnings := NewProfitAndLoss(ReportPeriod{To: asOf}, accounts, totals)
+	bs := &BalanceSheet{
+		AsOf:            asOf,
+		Assets:          newReportSection(AccountTypeAsset, accounts, totals),
+		Liabilities:     newReportSection(AccountTypeLiability, accounts, totals),
+		Equity:          newReportSection(AccountTypeEquity, accounts, totals),
+		CurrentEarnings: earnings.NetProfit,
+	}
+	bs.TotalEquity = roundAmount(bs.Equity.Total + bs.CurrentEarnings)
+	bs.TotalLiabilitiesAndEquity = roundAmount(bs.Liabilities.Total + bs.TotalEquity)
+	bs.Balanced = bs.Assets.Total == bs.TotalLiabilitiesAndEquity
+	return bs
+}
+
+// SetDates fills in the as-of date in BS and its printed label
+func (bs *BalanceSheet) SetDates(display fiscalUtils.DateDisplay) {
+	bs.AsOfBS = fiscalUtils.BSDate(bs.AsOf)
+	bs.AsOfLabel = fiscalUtils.FormatDualDate(bs.AsOf, display)
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Partitioning of the journal tables
+//
+// journal_entries and journal_lines are partitioned by RANGE (transaction_date), one
+// partition per month. PostgreSQL requires the partition key in every unique
+// constraint, so both primary keys are composite, (id, transaction_date), and
+// journal_lines repeats its entry's transaction_date so the (journal_entry_id,
+// transaction_date) foreign key and the header join stay inside one partition.
+//
+// fiscal_year_id is not part of the partition key. A query filtered by fiscal year
+// alone visits every partition, including those of closed years; it must also bound
+// transaction_date for the planner to prune. FiscalPeriod derives that bound from
+// fiscal year IDs.
+
+// MaxFiscalYearsPerQuery bounds how many fiscal years one journal query may span
+const MaxFiscalYearsPerQuery = 10
+
+var (
+	// ErrNoFiscalYears is returned when a fiscal year query names no year
+	ErrNoFiscalYears = errors.New("at least one fiscal year is required")
+	// ErrFiscalYearNotFound is returned for a fiscal year the tenant does not have
+	ErrFiscalYearNotFound = errors.New("fiscal year not found")
+	// ErrTooManyFiscalYears is returned when a query spans more than MaxFiscalYearsPerQuery years
+	ErrTooManyFiscalYears = errors.New("too many fiscal years in one query")
+)
+
+// FiscalYearSpan is the date range of one fiscal year
+type FiscalYearSpan struct {
+	ID    uuid.UUID
+	Start time.Time
+	End   time.Time
+}
+
+// FiscalPeriod is the fiscal years a journal query covers, with the transaction date
+// range spanning them. Rows are matched by fiscal year; the dates are the partition
+// pruning hint.
+type FiscalPeriod struct {
+	FiscalYearIDs []uuid.UUID
+	Start         time.Time // First day of the earliest year
+	End           time.Time // Last day of the latest year
+}
+
+// NewFiscalPeriod spans the given years. They need not be consecutive: the range then
+// also covers the years between them, which the fiscal year filter excludes.
+func NewFiscalPeriod(years ...FiscalYearSpan) (FiscalPeriod, error) {
+	if len(years) == 0 {
+		return FiscalPeriod{}, ErrNoFiscalYears
+	}
+	if len(years) > MaxFiscalYearsPerQuery {
+		return FiscalPeriod{}, ErrTooManyFiscalYears
+	}
+
+	period := FiscalPeriod{Start: years[0].Start, End: years[0].End}
+	seen := make(map[uuid.UUID]bool, len(years))
+	for _, year := range years {
+		if seen[year.ID] {
+			continue
+		}
+		seen[year.ID] = true
+		period.FiscalYearIDs = append(period.FiscalYearIDs, year.ID)
+
+		if year.Start.Before(period.Start) {
+			period.Start = year.Start
+		}
+		if year.End.After(period.End) {
+			period.End = year.End
+		}
+	}
+	return period, nil
+}
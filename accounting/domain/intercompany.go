@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MirrorStatus tracks the partner's copy of an inter-company document
+type MirrorStatus string
+
+const (
+	MirrorStatusPending MirrorStatus = "pending"
+	MirrorStatusCreated MirrorStatus = "created" // Draft purchase created in the partner tenant
+	MirrorStatusFailed  MirrorStatus = "failed"  // Retry with POST /intercompany/transactions/:id/mirror
+	MirrorStatusSkipped MirrorStatus = "skipped" // No mirror is configured
+)
+
+var (
+	ErrTenantLinkNotFound   = errors.New("inter-company link not found")
+	ErrTenantLinkExists     = errors.New("tenants are already linked")
+	ErrNotOwnerOfBoth       = errors.New("only an owner of both companies can link them")
+	ErrInvalidTenantLink    = errors.New("a company cannot be linked to itself")
+	ErrInterCompanyNotFound = errors.New("inter-company transaction not found")
+	ErrInvalidInterCompany  = errors.New("invalid inter-company transaction")
+	ErrAccountMapping       = errors.New("invalid inter-company account")
+)
+
+// TenantLink is one side of a link between two companies (tenants) with a common
+// owner. Links are stored in pairs, one row per tenant, each with that tenant's
+// accounts for balances and income with the partner.
+type TenantLink struct {
+	ID              uuid.UUID `json:"id"`
+	TenantID        uuid.UUID `json:"tenantId"`
+	PartnerTenantID uuid.UUID `json:"partnerTenantId"`
+	PartnerName     string    `json:"partnerName"` // Joined from tenants
+
+	// Account mapping, used to post and eliminate inter-company balances
+	ReceivableAccountID *uuid.UUID `json:"receivableAccountId"` // Due from the partner (ASSET)
+	PayableAccountID    *uuid.UUID `json:"payableAccountId"`    // Due to the partner (LIABILITY)
+	RevenueAccountID    *uuid.UUID `json:"revenueAccountId"`    // Sales to the partner (REVENUE)
+	ExpenseAccountID    *uuid.UUID `json:"expenseAccountId"`    // Purchases from the partner (EXPENSE)
+
+	IsActive  bool      `json:"isActive"`
+	CreatedBy uuid.UUID `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// NewTenantLinkPair creates both sides of a link between two tenants
+func NewTenantLinkPair(tenantID, partnerTenantID, createdBy uuid.UUID) (*TenantLink, *TenantLink, error) {
+	if tenantID == partnerTenantID {
+		return nil, nil, ErrInvalidTenantLink
+	}
+	now := time.Now()
+	side := func(tenant, partner uuid.UUID) *TenantLink {
+		return &TenantLink{
+			ID:              uuid.New(),
+			TenantID:        tenant,
+			PartnerTenantID: partner,
+			IsActive:        true,
+			CreatedBy:       createdBy,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+	}
+	return side(tenantID, partnerTenantID), side(partnerTenantID, tenantID), nil
+}
+
+// MappedAccounts returns the mapped account IDs with the type each must have
+func (l *TenantLink) MappedAccounts() map[AccountType]*uuid.UUID {
+	return map[AccountType]*uuid.UUID{
+		AccountTypeAsset:     l.ReceivableAccountID,
+		AccountTypeLiability: l.PayableAccountID,
+		AccountTypeRevenue:   l.RevenueAccountID,
+		AccountTypeExpense:   l.ExpenseAccountID,
+	}
+}
+
+// Company is a tenant as named on inter-company documents
+type Company struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	PAN  string    `json:"pan"`
+}
+
+// InterCompanyTransaction is a sale from one linked company to the other. The
+// buyer's side is mirrored as a draft purchase bill for its review.
+type InterCompanyTransaction struct {
+	ID              uuid.UUID  `json:"id"`
+	TenantID        uuid.UUID  `json:"tenantId"`        // Seller
+	PartnerTenantID uuid.UUID  `json:"partnerTenantId"` // Buyer
+	LinkID          uuid.UUID  `json:"linkId"`
+	DocumentNumber  string     `json:"documentNumber"` // Seller's invoice number
+	DocumentID      *uuid.UUID `json:"documentId"`     // Seller's invoice, when recorded from sales
+	Date            time.Time  `json:"date"`
+	Description     string     `json:"description"`
+	SubTotal        float64    `json:"subTotal"`
+	VATAmount       float64    `json:"vatAmount"`
+	TotalAmount     float64    `json:"totalAmount"`
+
+	MirrorStatus     MirrorStatus `json:"mirrorStatus"`
+	MirrorDocumentID *uuid.UUID   `json:"mirrorDocumentId"` // Buyer's draft purchase bill
+	MirrorError      *string      `json:"mirrorError"`
+
+	CreatedBy uuid.UUID `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// NewInterCompanySale records a sale over a link; the total is the subtotal plus VAT
+func NewInterCompanySale(link *TenantLink, number string, documentID *uuid.UUID, date time.Time, description string, subTotal, vat float64, createdBy uuid.UUID) (*InterCompanyTransaction, error) {
+	number = strings.TrimSpace(number)
+	switch {
+	case !link.IsActive:
+		return nil, ErrTenantLinkNotFound
+	case number == "":
+		return nil, fmt.Errorf("%w: document number is required", ErrInvalidInterCompany)
+	case subTotal <= 0 || vat < 0:
+		return nil, fmt.Errorf("%w: amounts must be positive", ErrInvalidInterCompany)
+	}
+
+	now := time.Now()
+	return &InterCompanyTransaction{
+		ID:              uuid.New(),
+		TenantID:        link.TenantID,
+		PartnerTenantID: link.PartnerTenantID,
+		LinkID:          link.ID,
+		DocumentNumber:  number,
+		DocumentID:      documentID,
+		Date:            date,
+		Description:     strings.TrimSpace(description),
+		SubTotal:        subTotal,
+		VATAmount:       vat,
+		TotalAmount:     subTotal + vat,
+		MirrorStatus:    MirrorStatusPending,
+		CreatedBy:       createdBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}, nil
+}
+
+// EliminationReport pairs the inter-company balances and income of two linked
+// companies for consolidation. Each line is removed from the combined accounts;
+// a non-zero difference means the two books disagree and need reconciling.
+type EliminationReport struct {
+	Company   Company           `json:"company"`
+	Partner   Company           `json:"partner"`
+	From      time.Time         `json:"from"`
+	To        time.Time         `json:"to"`
+	SalesTo   float64           `json:"salesToPartner"`   // Recorded inter-company sales to the partner
+	SalesFrom float64           `json:"salesFromPartner"` // Recorded inter-company sales from the partner
+	Lines     []EliminationLine `json:"lines"`
+	Balanced  bool              `json:"balanced"` // No line has a difference
+}
+
+// EliminationLine eliminates one account against its counterpart in the partner's books
+type EliminationLine struct {
+	Description   string     `json:"description"`
+	DebitTenant   uuid.UUID  `json:"debitTenantId"`
+	DebitAccount  *uuid.UUID `json:"debitAccountId"` // nil when the account is not mapped
+	DebitAmount   float64    `json:"debitAmount"`    // Balance of the account being debited
+	CreditTenant  uuid.UUID  `json:"creditTenantId"`
+	CreditAccount *uuid.UUID `json:"creditAccountId"`
+	CreditAmount  float64    `json:"creditAmount"`
+	Amount        float64    `json:"amount"`     // Eliminated: the smaller of the two
+	Difference    float64    `json:"difference"` // DebitAmount - CreditAmount
+}
+
+// NewEliminationLine pairs two balances, both given as positive amounts
+func NewEliminationLine(description string, debitTenant uuid.UUID, debitAccount *uuid.UUID, debitAmount float64, creditTenant uuid.UUID, creditAccount *uuid.UUID, creditAmount float64) EliminationLine {
+	return EliminationLine{
+		Description:   description,
+		DebitTenant:   debitTenant,
+		DebitAccount:  debitAccount,
+		DebitAmount:   roundAmount(debitAmount),
+		CreditTenant:  creditTenant,
+		CreditAccount: creditAccount,
+		CreditAmount:  roundAmount(creditAmount),
+		Amount:        roundAmount(min(debitAmount, creditAmount)),
+		Difference:    roundAmount(debitAmount - creditAmount),
+	}
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
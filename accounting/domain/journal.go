@@ -8,6 +8,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrJournalEntryNotFound is returned for a journal entry the tenant does not have
+var ErrJournalEntryNotFound = errors.New("journal entry not found")
+
 type JournalStatus string
 
 const (
@@ -29,6 +32,9 @@ type JournalEntry struct {
 	CreatedAt       time.Time     `json:"createdAt"`
 	UpdatedAt       time.Time     `json:"updatedAt"`
 
+	// AttachmentCount is the number of supporting documents (read-only, from the files module)
+	AttachmentCount int `json:"attachmentCount"`
+
 	Lines []JournalLine `json:"lines"`
 }
 
@@ -67,6 +73,15 @@ func (j *JournalEntry) AddLine(accountID uuid.UUID, debit, credit float64, descr
 	j.Lines = append(j.Lines, line)
 }
 
+// Totals sums the entry's debits and credits
+func (j *JournalEntry) Totals() (debit, credit float64) {
+	for _, line := range j.Lines {
+		debit += line.Debit
+		credit += line.Credit
+	}
+	return debit, credit
+}
+
 func (j *JournalEntry) Validate() error {
 	if len(j.Lines) < 2 {
 		return errors.New("journal entry must have at least 2 lines")
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"github.com/aceextension/core/dataimport"
+	"github.com/google/uuid"
+)
+
+// JournalImportReferenceType marks entries created by a journal import
+const JournalImportReferenceType = "IMPORT"
+
+// JournalImportReport is the outcome of importing vouchers. Rows count header and line
+// rows alike; the vouchers are created as drafts only when every row is valid.
+type JournalImportReport struct {
+	*dataimport.Report
+	Vouchers int         `json:"vouchers"` // Vouchers read
+	EntryIDs []uuid.UUID `json:"entryIds"` // Draft entries created, in file order
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"time"
 
+	fiscalUtils "github.com/aceextension/fiscal/utils"
 	"github.com/google/uuid"
 )
 
@@ -18,4 +19,13 @@ type LedgerEntry struct {
 	Debit           float64   `json:"debit"`
 	Credit          float64   `json:"credit"`
 	StepBalance     float64   `json:"stepBalance"` // Calculated during report generation
+	// Filled in for reports by SetDates
+	TransactionDateBS string `json:"transactionDateBs"` // Bikram Sambat, YYYY-MM-DD
+	DateLabel         string `json:"dateLabel"`         // Both dates as printed, the tenant's calendar first
+}
+
+// SetDates fills in the entry's BS date and its printed label
+func (e *LedgerEntry) SetDates(display fiscalUtils.DateDisplay) {
+	e.TransactionDateBS = fiscalUtils.BSDate(e.TransactionDate)
+	e.DateLabel = fiscalUtils.FormatDualDate(e.TransactionDate, display)
 }
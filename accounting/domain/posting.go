@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reference types of journal entries posted from other modules' documents
+const (
+	ReferenceInvoice         = "INVOICE"          // Issued sales invoice
+	ReferenceInvoiceVoid     = "INVOICE_VOID"     // Reversal of a voided invoice
+	ReferencePurchaseBill    = "PURCHASE_BILL"    // Confirmed supplier bill
+	ReferenceConsignmentBill = "CONSIGNMENT_BILL" // Supplier bill for consigned goods sold
+	ReferencePayment         = "PAYMENT"          // Customer receipt or supplier payment
+	ReferencePaymentVoid     = "PAYMENT_VOID"     // Reversal of a voided payment
+)
+
+// PostingRole is what an amount of a document is booked as; each tenant maps the
+// roles to accounts of its chart
+type PostingRole string
+
+const (
+	RoleReceivable       PostingRole = "receivable"        // Due from customers (ASSET)
+	RoleCash             PostingRole = "cash"              // Cash sales (ASSET)
+	RoleBank             PostingRole = "bank"              // Bank deposits and transfers (ASSET)
+	RoleWallet           PostingRole = "wallet"            // Digital wallet balances such as eSewa and Khalti (ASSET)
+	RoleRevenue          PostingRole = "revenue"           // Sales (REVENUE)
+	RoleOutputVAT        PostingRole = "output_vat"        // VAT charged on sales (LIABILITY)
+	RolePayable          PostingRole = "payable"           // Due to suppliers (LIABILITY)
+	RolePurchases        PostingRole = "purchases"         // Purchases (EXPENSE, or ASSET for inventory)
+	RoleInputVAT         PostingRole = "input_vat"         // VAT paid on purchases, claimable (ASSET)
+	RoleRetainedEarnings PostingRole = "retained_earnings" // Profit or loss of closed fiscal years (EQUITY)
+	RoleRoundOff         PostingRole = "round_off"         // Rounding differences of document totals (EXPENSE or REVENUE)
+)
+
+// postingRoleTypes are the account types each role may be mapped to
+var postingRoleTypes = map[PostingRole][]AccountType{
+	RoleReceivable:       {AccountTypeAsset},
+	RoleCash:             {AccountTypeAsset},
+	RoleBank:             {AccountTypeAsset},
+	RoleWallet:           {AccountTypeAsset},
+	RoleRevenue:          {AccountTypeRevenue},
+	RoleOutputVAT:        {AccountTypeLiability},
+	RolePayable:          {AccountTypeLiability},
+	RolePurchases:        {AccountTypeExpense, AccountTypeAsset},
+	RoleInputVAT:         {AccountTypeAsset},
+	RoleRetainedEarnings: {AccountTypeEquity},
+	RoleRoundOff:         {AccountTypeExpense, AccountTypeRevenue},
+}
+
+var (
+	ErrUnknownReferenceType   = errors.New("no posting source for reference type")
+	ErrReferenceNotPostable   = errors.New("document cannot be posted")
+	ErrReferenceAlreadyPosted = errors.New("document is already posted")
+	// ErrReferenceNotPosted is returned when reversing a document that was never posted
+	ErrReferenceNotPosted    = errors.New("document was not posted")
+	ErrPostingAccountMissing = errors.New("no account is mapped for posting role")
+	ErrPostingAccountInvalid = errors.New("invalid posting account")
+)
+
+// PostingRoles lists the roles in a stable order
+func PostingRoles() []PostingRole {
+	roles := make([]PostingRole, 0, len(postingRoleTypes))
+	for role := range postingRoleTypes {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i] < roles[j] })
+	return roles
+}
+
+// Valid reports whether the role is known
+func (r PostingRole) Valid() bool {
+	_, ok := postingRoleTypes[r]
+	return ok
+}
+
+// Accepts reports whether an account of the type can be mapped to the role
+func (r PostingRole) Accepts(accountType AccountType) bool {
+	for _, t := range postingRoleTypes[r] {
+		if t == accountType {
+			return true
+		}
+	}
+	return false
+}
+
+// PostingAccounts maps a tenant's posting roles to its accounts
+type PostingAccounts map[PostingRole]uuid.UUID
+
+// PostingDocument is a document as the journal sees it, read by a PostingSource
+type PostingDocument struct {
+	TenantID uuid.UUID
+	// FiscalYearID is the document's fiscal year; nil finds the year containing Date
+	FiscalYearID *uuid.UUID
+	Date         time.Time
+	Description  string
+	CreatedBy    *uuid.UUID
+	Amounts      []PostingAmount
+	// Reverses names the reference type, with the same reference ID, whose posted
+	// entry this document cancels; Amounts are then ignored and the entry's lines
+	// are booked the other way round
+	Reverses string
+}
+
+// PostingAmount is one amount of a document booked to the account of its role
+type PostingAmount struct {
+	Role        PostingRole
+	Debit       float64
+	Credit      float64
+	Description *string
+}
+
+// Debit adds an amount booked on the debit side; zero amounts are left out
+func (d *PostingDocument) Debit(role PostingRole, amount float64, description string) {
+	d.add(PostingAmount{Role: role, Debit: amount}, description)
+}
+
+// Credit adds an amount booked on the credit side; zero amounts are left out
+func (d *PostingDocument) Credit(role PostingRole, amount float64, description string) {
+	d.add(PostingAmount{Role: role, Credit: amount}, description)
+}
+
+// RoundOff books what rounding a document's total gained, as on a sale rounded up,
+// as a credit; a negative amount was lost, as on a purchase rounded up, and is debited
+func (d *PostingDocument) RoundOff(amount float64) {
+	if amount > 0 {
+		d.Credit(RoleRoundOff, amount, "Round-off")
+	} else {
+		d.Debit(RoleRoundOff, -amount, "Round-off")
+	}
+}
+
+func (d *PostingDocument) add(amount PostingAmount, description string) {
+	if math.Abs(amount.Debit) < 0.005 && math.Abs(amount.Credit) < 0.005 {
+		return
+	}
+	if description != "" {
+		amount.Description = &description
+	}
+	d.Amounts = append(d.Amounts, amount)
+}
+
+// Lines resolves the document's amounts to the mapped accounts
+func (d *PostingDocument) Lines(accounts PostingAccounts) ([]JournalLine, error) {
+	lines := make([]JournalLine, 0, len(d.Amounts))
+	for _, amount := range d.Amounts {
+		accountID, ok := accounts[amount.Role]
+		if !ok {
+			return nil, fmt.Errorf("%w %s", ErrPostingAccountMissing, amount.Role)
+		}
+		lines = append(lines, JournalLine{
+			AccountID:   accountID,
+			Debit:       amount.Debit,
+			Credit:      amount.Credit,
+			Description: amount.Description,
+		})
+	}
+	return lines, nil
+}
+
+// ReverseLines books an entry's lines the other way round
+func ReverseLines(lines []JournalLine) []JournalLine {
+	reversed := make([]JournalLine, 0, len(lines))
+	for _, line := range lines {
+		reversed = append(reversed, JournalLine{
+			AccountID:   line.AccountID,
+			Debit:       line.Credit,
+			Credit:      line.Debit,
+			Description: line.Description,
+		})
+	}
+	return reversed
+}
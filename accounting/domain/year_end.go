@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReferenceYearEndClose marks the entries closing a fiscal year's revenue and expenses
+// to retained earnings; the reference ID is the fiscal year
+const ReferenceYearEndClose = "YEAR_END_CLOSE"
+
+// ErrFiscalYearOpen is returned when balances are carried forward from a year not yet closed
+var ErrFiscalYearOpen = errors.New("fiscal year is not closed")
+
+// OpeningBalance is an asset, liability or equity account's balance brought forward
+// into a fiscal year from the close of the year before
+type OpeningBalance struct {
+	TenantID           uuid.UUID `json:"tenantId"`
+	FiscalYearID       uuid.UUID `json:"fiscalYearId"`
+	AccountID          uuid.UUID `json:"accountId"`
+	Debit              float64   `json:"debit"`
+	Credit             float64   `json:"credit"`
+	SourceFiscalYearID uuid.UUID `json:"sourceFiscalYearId"` // The closed year carried forward
+	CreatedAt          time.Time `json:"createdAt"`
+}
+
+// YearEndClose is the outcome of closing a fiscal year's ledger
+type YearEndClose struct {
+	FiscalYearID uuid.UUID `json:"fiscalYearId"`
+	// ClosingEntry moves revenue and expense balances to retained earnings; nil when they
+	// were already zero, e.g. on closing a reopened year again without new entries
+	ClosingEntry *JournalEntry `json:"closingEntry,omitempty"`
+	NetProfit    float64       `json:"netProfit"` // Closed to retained earnings by this entry
+	// NextFiscalYearID is the year balances were carried into, nil until one is created
+	NextFiscalYearID *uuid.UUID        `json:"nextFiscalYearId,omitempty"`
+	OpeningBalances  []*OpeningBalance `json:"openingBalances"`
+}
+
+// NewClosingEntry builds the posted entry that zeroes the revenue and expense balances
+// as of the year end against retained earnings, and the net profit it closes. It
+// returns nil when there is nothing left to close.
+func NewClosingEntry(tenantID, fiscalYearID uuid.UUID, name string, endDate time.Time, accounts []*Account, totals map[uuid.UUID]AccountTotal, retainedEarningsID, closedBy uuid.UUID) (*JournalEntry, float64) {
+	entry := NewJournalEntry(tenantID, fiscalYearID, endDate, "Year-end close "+name)
+	var debits, credits float64
+	for _, acc := range accounts {
+		if acc.Type != AccountTypeRevenue && acc.Type != AccountTypeExpense {
+			continue
+		}
+		balance := roundAmount(totals[acc.ID].Balance())
+		switch {
+		case balance > 0:
+			entry.AddLine(acc.ID, 0, balance, nil)
+			credits += balance
+		case balance < 0:
+			entry.AddLine(acc.ID, -balance, 0, nil)
+			debits -= balance
+		}
+	}
+	if len(entry.Lines) == 0 {
+		return nil, 0
+	}
+
+	// Revenue closed with debits beyond the expenses credited is profit
+	netProfit := roundAmount(debits - credits)
+	note := "Profit for the year"
+	if netProfit >= 0 {
+		entry.AddLine(retainedEarningsID, 0, netProfit, &note)
+	} else {
+		note = "Loss for the year"
+		entry.AddLine(retainedEarningsID, -netProfit, 0, &note)
+	}
+
+	now := time.Now()
+	referenceID, referenceType := fiscalYearID, ReferenceYearEndClose
+	entry.ReferenceID = &referenceID
+	entry.ReferenceType = &referenceType
+	entry.CreatedByUserID = &closedBy
+	entry.Status = JournalStatusPosted
+	entry.PostedAt = &now
+	return entry, netProfit
+}
+
+// NewOpeningBalances carries the balance sheet accounts' closing balances into the next year
+func NewOpeningBalances(tenantID, fiscalYearID, sourceFiscalYearID uuid.UUID, accounts []*Account, totals map[uuid.UUID]AccountTotal) []*OpeningBalance {
+	now := time.Now()
+	balances := []*OpeningBalance{}
+	for _, acc := range accounts {
+		if acc.Type == AccountTypeRevenue || acc.Type == AccountTypeExpense {
+			continue
+		}
+		balance := roundAmount(totals[acc.ID].Balance())
+		if balance == 0 {
+			continue
+		}
+
+		ob := &OpeningBalance{
+			TenantID:           tenantID,
+			FiscalYearID:       fiscalYearID,
+			AccountID:          acc.ID,
+			SourceFiscalYearID: sourceFiscalYearID,
+			CreatedAt:          now,
+		}
+		if balance > 0 {
+			ob.Debit = balance
+		} else {
+			ob.Credit = -balance
+		}
+		balances = append(balances, ob)
+	}
+	return balances
+}
@@ -21,3 +21,14 @@ type UpdateAccountRequest struct {
 	IsActive    bool               `json:"isActive"`
 	Description *string            `json:"description"`
 }
+
+// PostingAccountsRequest maps posting roles (receivable, cash, bank, wallet, revenue, output_vat,
+// payable, purchases, input_vat, retained_earnings, round_off) to accounts; roles left out are unmapped
+type PostingAccountsRequest struct {
+	Accounts map[domain.PostingRole]uuid.UUID `json:"accounts"`
+}
+
+// MergeAccountRequest names the account a duplicate is merged into
+type MergeAccountRequest struct {
+	TargetAccountID uuid.UUID `json:"targetAccountId" validate:"required"`
+}
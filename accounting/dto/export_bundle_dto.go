@@ -0,0 +1,17 @@
+package dto
+
+import (
+	"github.com/aceextension/accounting/domain"
+	"github.com/google/uuid"
+)
+
+type CreateExportBundleRequest struct {
+	FiscalYearID uuid.UUID `json:"fiscalYearId" validate:"required"`
+	Formats      []string  `json:"formats" validate:"omitempty,dive,oneof=xlsx pdf"` // Defaults to both
+}
+
+// ExportBundleResponse is a bundle with its download link once it is ready
+type ExportBundleResponse struct {
+	*domain.ExportBundle
+	DownloadURL *string `json:"downloadUrl"` // Presigned link, valid until expiresAt
+}
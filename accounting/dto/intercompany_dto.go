@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateTenantLinkRequest struct {
+	PartnerTenantID uuid.UUID `json:"partnerTenantId" validate:"required"`
+}
+
+// InterCompanyAccountsRequest maps this company's accounts for the partner; nil clears a mapping
+type InterCompanyAccountsRequest struct {
+	ReceivableAccountID *uuid.UUID `json:"receivableAccountId"`
+	PayableAccountID    *uuid.UUID `json:"payableAccountId"`
+	RevenueAccountID    *uuid.UUID `json:"revenueAccountId"`
+	ExpenseAccountID    *uuid.UUID `json:"expenseAccountId"`
+}
+
+type RecordInterCompanySaleRequest struct {
+	PartnerTenantID uuid.UUID  `json:"partnerTenantId" validate:"required"`
+	DocumentNumber  string     `json:"documentNumber" validate:"required,max=100"`
+	DocumentID      *uuid.UUID `json:"documentId"`
+	Date            time.Time  `json:"date" validate:"required"`
+	Description     string     `json:"description"`
+	SubTotal        float64    `json:"subTotal" validate:"gt=0"`
+	VATAmount       float64    `json:"vatAmount" validate:"gte=0"`
+}
@@ -21,3 +21,8 @@ type JournalLineRequest struct {
 	Credit      float64   `json:"credit" validate:"gte=0"`
 	Description *string   `json:"description"`
 }
+
+type PostReferenceRequest struct {
+	ReferenceType string    `json:"referenceType" validate:"required"`
+	ReferenceID   uuid.UUID `json:"referenceId" validate:"required"`
+}
@@ -3,14 +3,20 @@ module github.com/aceextension/accounting
 go 1.24.0
 
 require (
+	github.com/aceextension/audit v0.0.0
 	github.com/aceextension/core v0.0.0
 	github.com/aceextension/identity v0.0.0
+	github.com/aceextension/files v0.0.0
 	github.com/aceextension/fiscal v0.0.0
 	github.com/google/uuid v1.6.0
 )
 
 replace (
+	github.com/aceextension/audit => ../audit
 	github.com/aceextension/core => ../core
-	github.com/aceextension/identity => ../identity
+	github.com/aceextension/files => ../files
 	github.com/aceextension/fiscal => ../fiscal
+	github.com/aceextension/identity => ../identity
+	github.com/aceextension/notification => ../notification
+	github.com/aceextension/onboarding => ../onboarding
 )
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/aceextension/accounting/domain"
 	"github.com/aceextension/accounting/dto"
 	"github.com/aceextension/accounting/service"
 	"github.com/aceextension/core/db" // For GetTenantID
@@ -85,12 +87,17 @@ func (h *AccountHandler) ListAccounts(c echo.Context) error {
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/accounting/accounts/{id} [get]
 func (h *AccountHandler) GetAccount(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid account ID"})
 	}
 
-	account, err := h.service.GetAccount(c.Request().Context(), id)
+	account, err := h.service.GetAccount(c.Request().Context(), tenantID, id)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -111,9 +118,15 @@ func (h *AccountHandler) GetAccount(c echo.Context) error {
 // @Param request body dto.UpdateAccountRequest true "Update Request"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/accounting/accounts/{id} [put]
 func (h *AccountHandler) UpdateAccount(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid account ID"})
@@ -124,9 +137,120 @@ func (h *AccountHandler) UpdateAccount(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 	}
 
-	if err := h.service.UpdateAccount(c.Request().Context(), id, req); err != nil {
+	if err := h.service.UpdateAccount(c.Request().Context(), tenantID, id, req); err != nil {
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "Account updated successfully"})
 }
+
+// MergeAccount merges a duplicate account into another
+// @Summary Merge Account
+// @Description Merge a duplicate account (the path ID) into the account kept: its journal lines in every
+// @Description fiscal year, posting roles, inter-company links and sub-accounts move to the target, and the
+// @Description duplicate is archived. Only accounts of the same type can be merged. Logged to audit.
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID to merge away"
+// @Param request body dto.MergeAccountRequest true "Account kept"
+// @Success 200 {object} domain.AccountMerge
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/accounts/{id}/merge [post]
+func (h *AccountHandler) MergeAccount(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid account ID"})
+	}
+
+	var req dto.MergeAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	merge, err := h.service.MergeAccounts(c.Request().Context(), tenantID, userID, id, req.TargetAccountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrAccountNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, domain.ErrAccountMergeInvalid), errors.Is(err, domain.ErrAccountMergeTypeMismatch):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, domain.ErrAccountMerged):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, merge)
+}
+
+// GetPostingAccounts returns the accounts documents are posted to
+// @Summary Get Posting Accounts
+// @Description Accounts each posting role (receivable, cash, bank, wallet, revenue, output_vat, payable, purchases, input_vat, retained_earnings, round_off) is booked to
+// @Tags Accounting
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/posting-accounts [get]
+func (h *AccountHandler) GetPostingAccounts(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	accounts, err := h.service.GetPostingAccounts(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, accounts)
+}
+
+// SetPostingAccounts replaces the accounts documents are posted to
+// @Summary Set Posting Accounts
+// @Description Map posting roles to accounts of matching type; roles left out are unmapped and documents needing them are not posted
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Param request body dto.PostingAccountsRequest true "Posting Accounts"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/posting-accounts [put]
+func (h *AccountHandler) SetPostingAccounts(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	var req dto.PostingAccountsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	accounts, err := h.service.SetPostingAccounts(c.Request().Context(), tenantID, req)
+	if err != nil {
+		return postingError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, accounts)
+}
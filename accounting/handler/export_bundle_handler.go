@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/accounting/dto"
+	"github.com/aceextension/accounting/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ExportBundleHandler struct {
+	service service.ExportBundleService
+}
+
+func NewExportBundleHandler(service service.ExportBundleService) *ExportBundleHandler {
+	return &ExportBundleHandler{service: service}
+}
+
+// CreateExportBundle queues a year-end export bundle
+// @Summary Request Year-End Export Bundle
+// @Description Queue a zip of the fiscal year's reports for the accountant: trial balance, account ledgers,
+// @Description VAT registers and AR/AP aging as of the fiscal year end (Ashad end), in XLSX and/or PDF.
+// @Description Poll the bundle for progress; the download link is valid for seven days once it completes.
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateExportBundleRequest true "Export Bundle Request"
+// @Success 202 {object} dto.ExportBundleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string "A bundle for the fiscal year is already being prepared"
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/export-bundles [post]
+func (h *ExportBundleHandler) CreateExportBundle(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	var req dto.CreateExportBundleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	bundle, err := h.service.Request(c.Request().Context(), tenantID, userID, req.FiscalYearID, req.Formats)
+	if err != nil {
+		return exportBundleError(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, dto.ExportBundleResponse{ExportBundle: bundle})
+}
+
+// ListExportBundles lists the tenant's export bundles
+// @Summary List Export Bundles
+// @Description List year-end export bundles, newest first
+// @Tags Accounting
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.ExportBundle
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/export-bundles [get]
+func (h *ExportBundleHandler) ListExportBundles(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	bundles, err := h.service.List(c.Request().Context(), tenantID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, bundles)
+}
+
+// GetExportBundle returns a bundle's progress, and its download link once ready
+// @Summary Get Export Bundle
+// @Description Get a bundle's status and progress. Completed bundles carry a presigned download link when the files are on MinIO.
+// @Tags Accounting
+// @Produce json
+// @Param id path string true "Export Bundle ID"
+// @Success 200 {object} dto.ExportBundleResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/export-bundles/{id} [get]
+func (h *ExportBundleHandler) GetExportBundle(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid export bundle ID"})
+	}
+
+	bundle, err := h.service.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return exportBundleError(c, err)
+	}
+
+	resp := dto.ExportBundleResponse{ExportBundle: bundle}
+	if bundle.Downloadable(time.Now()) == nil {
+		link, err := h.service.DownloadURL(c.Request().Context(), bundle)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		if link != "" {
+			resp.DownloadURL = &link
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DownloadExportBundle downloads a completed bundle
+// @Summary Download Export Bundle
+// @Description Redirects to the presigned MinIO link, or streams the zip when files are stored locally
+// @Tags Accounting
+// @Produce application/zip
+// @Param id path string true "Export Bundle ID"
+// @Success 200 {file} file
+// @Success 302 "Redirect to the presigned link"
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "The bundle is not ready"
+// @Failure 410 {object} map[string]string "The download has expired"
+// @Router /api/v1/accounting/export-bundles/{id}/download [get]
+func (h *ExportBundleHandler) DownloadExportBundle(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid export bundle ID"})
+	}
+
+	bundle, err := h.service.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return exportBundleError(c, err)
+	}
+
+	link, err := h.service.DownloadURL(c.Request().Context(), bundle)
+	if err != nil {
+		return exportBundleError(c, err)
+	}
+	if link != "" {
+		return c.Redirect(http.StatusFound, link)
+	}
+
+	content, err := h.service.Open(c.Request().Context(), bundle)
+	if err != nil {
+		return exportBundleError(c, err)
+	}
+	defer content.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", bundle.FileName))
+	if bundle.SizeBytes != nil {
+		c.Response().Header().Set(echo.HeaderContentLength, fmt.Sprint(*bundle.SizeBytes))
+	}
+	return c.Stream(http.StatusOK, "application/zip", content)
+}
+
+// exportBundleError maps bundle errors to HTTP statuses
+func exportBundleError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrBundleNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidBundle):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrBundleInProgress), errors.Is(err, domain.ErrBundleNotReady):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrBundleExpired):
+		return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,354 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/accounting/dto"
+	"github.com/aceextension/accounting/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type InterCompanyHandler struct {
+	service service.InterCompanyService
+}
+
+func NewInterCompanyHandler(service service.InterCompanyService) *InterCompanyHandler {
+	return &InterCompanyHandler{service: service}
+}
+
+// CreateLink links the current company with another the user owns
+// @Summary Link Companies
+// @Description Link the current tenant with another tenant (e.g. trading and manufacturing companies) for inter-company transactions. The user must be an owner of both.
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateTenantLinkRequest true "Link Request"
+// @Success 201 {object} domain.TenantLink
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/accounting/intercompany/links [post]
+func (h *InterCompanyHandler) CreateLink(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	var req dto.CreateTenantLinkRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	link, err := h.service.Link(c.Request().Context(), tenantID, userID, req.PartnerTenantID)
+	if err != nil {
+		return interCompanyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, link)
+}
+
+// ListLinks lists the companies linked with the current one
+// @Summary List Linked Companies
+// @Description List active inter-company links with this company's account mapping
+// @Tags Accounting
+// @Produce json
+// @Success 200 {array} domain.TenantLink
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/intercompany/links [get]
+func (h *InterCompanyHandler) ListLinks(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	links, err := h.service.ListLinks(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, links)
+}
+
+// UpdateLinkAccounts maps this company's inter-company accounts for a partner
+// @Summary Map Inter-Company Accounts
+// @Description Set the accounts holding balances (receivable ASSET, payable LIABILITY) and income (REVENUE, EXPENSE) with the partner. Omitted accounts are cleared.
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Param id path string true "Link ID"
+// @Param request body dto.InterCompanyAccountsRequest true "Account Mapping"
+// @Success 200 {object} domain.TenantLink
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/accounting/intercompany/links/{id}/accounts [put]
+func (h *InterCompanyHandler) UpdateLinkAccounts(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid link ID"})
+	}
+
+	var req dto.InterCompanyAccountsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	link, err := h.service.SetLinkAccounts(c.Request().Context(), tenantID, id, req)
+	if err != nil {
+		return interCompanyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, link)
+}
+
+// DeleteLink ends an inter-company link on both sides
+// @Summary Unlink Companies
+// @Description End the link with a partner company. Recorded transactions are kept.
+// @Tags Accounting
+// @Param id path string true "Link ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/accounting/intercompany/links/{id} [delete]
+func (h *InterCompanyHandler) DeleteLink(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid link ID"})
+	}
+
+	if err := h.service.Unlink(c.Request().Context(), tenantID, userID, id); err != nil {
+		return interCompanyError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetEliminations pairs the linked companies' inter-company balances for consolidation
+// @Summary Inter-Company Elimination Report
+// @Description Receivables against payables as of endDate, and sales against purchases from startDate to endDate, across both companies' books.
+// @Description Lines with a difference need reconciling before consolidation. The user must be an owner of both companies.
+// @Tags Accounting
+// @Produce json
+// @Param id path string true "Link ID"
+// @Param startDate query string true "Start Date (YYYY-MM-DD)"
+// @Param endDate query string true "End Date (YYYY-MM-DD)"
+// @Success 200 {object} domain.EliminationReport
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/accounting/intercompany/links/{id}/eliminations [get]
+func (h *InterCompanyHandler) GetEliminations(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid link ID"})
+	}
+
+	from, err := time.Parse("2006-01-02", c.QueryParam("startDate"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "startDate is required (YYYY-MM-DD)"})
+	}
+	to, err := time.Parse("2006-01-02", c.QueryParam("endDate"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "endDate is required (YYYY-MM-DD)"})
+	}
+
+	report, err := h.service.EliminationReport(c.Request().Context(), tenantID, userID, id, from, to)
+	if err != nil {
+		return interCompanyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// RecordSale records a sale to a linked company
+// @Summary Record Inter-Company Sale
+// @Description Record a sale to a linked company. A draft purchase bill is created in the buyer's books for review; check mirrorStatus.
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Param request body dto.RecordInterCompanySaleRequest true "Sale"
+// @Success 201 {object} domain.InterCompanyTransaction
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/accounting/intercompany/transactions [post]
+func (h *InterCompanyHandler) RecordSale(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	var req dto.RecordInterCompanySaleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	sale, err := h.service.RecordSale(c.Request().Context(), tenantID, userID, req)
+	if err != nil {
+		return interCompanyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, sale)
+}
+
+// ListTransactions lists inter-company sales and purchases
+// @Summary List Inter-Company Transactions
+// @Description List sales to and purchases from linked companies, newest first
+// @Tags Accounting
+// @Produce json
+// @Param partnerTenantId query string false "Only transactions with this company"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.InterCompanyTransaction
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/intercompany/transactions [get]
+func (h *InterCompanyHandler) ListTransactions(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	var partnerID *uuid.UUID
+	if raw := c.QueryParam("partnerTenantId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid partnerTenantId"})
+		}
+		partnerID = &id
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	txns, err := h.service.ListTransactions(c.Request().Context(), tenantID, partnerID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, txns)
+}
+
+// GetTransaction returns an inter-company transaction
+// @Summary Get Inter-Company Transaction
+// @Tags Accounting
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} domain.InterCompanyTransaction
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/accounting/intercompany/transactions/{id} [get]
+func (h *InterCompanyHandler) GetTransaction(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid transaction ID"})
+	}
+
+	txn, err := h.service.GetTransaction(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return interCompanyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, txn)
+}
+
+// RetryMirror creates the buyer's draft purchase again
+// @Summary Retry Inter-Company Mirror
+// @Description Create the buyer's draft purchase bill for a sale whose mirror failed
+// @Tags Accounting
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} domain.InterCompanyTransaction
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /api/v1/accounting/intercompany/transactions/{id}/mirror [post]
+func (h *InterCompanyHandler) RetryMirror(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid transaction ID"})
+	}
+
+	txn, err := h.service.RetryMirror(c.Request().Context(), tenantID, id)
+	if errors.Is(err, domain.ErrInterCompanyNotFound) {
+		return interCompanyError(c, err)
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, txn)
+}
+
+// interCompanyError maps inter-company errors to HTTP statuses
+func interCompanyError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrTenantLinkNotFound), errors.Is(err, domain.ErrInterCompanyNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotOwnerOfBoth):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrTenantLinkExists):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidTenantLink), errors.Is(err, domain.ErrInvalidInterCompany), errors.Is(err, domain.ErrAccountMapping):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
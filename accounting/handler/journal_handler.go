@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/aceextension/accounting/domain"
 	"github.com/aceextension/accounting/dto"
 	"github.com/aceextension/accounting/service"
+	"github.com/aceextension/core/dataimport"
 	"github.com/aceextension/core/db"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -62,12 +67,13 @@ func (h *JournalHandler) CreateJournalEntry(c echo.Context) error {
 	return c.JSON(http.StatusCreated, entry)
 }
 
-// ListJournalEntries retrieves journal entries for a fiscal year
+// ListJournalEntries retrieves journal entries for one or more fiscal years
 // @Summary List Journal Entries
-// @Description List journal entries
+// @Description List journal entries. Repeat fiscalYearId (or separate IDs with commas) to
+// @Description span several years; only the partitions of those years are read.
 // @Tags Accounting
 // @Produce json
-// @Param fiscalYearId query string true "Fiscal Year ID"
+// @Param fiscalYearId query []string true "Fiscal Year IDs" collectionFormat(multi)
 // @Success 200 {array} domain.JournalEntry
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -78,18 +84,17 @@ func (h *JournalHandler) ListJournalEntries(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
 	}
 
-	fiscalYearIDStr := c.QueryParam("fiscalYearId")
-	if fiscalYearIDStr == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "fiscalYearId query param is required"})
-	}
-	fiscalYearID, err := uuid.Parse(fiscalYearIDStr)
+	fiscalYearIDs, err := fiscalYearIDsParam(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid fiscalYearId"})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if len(fiscalYearIDs) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "fiscalYearId query param is required"})
 	}
 
-	entries, err := h.service.ListJournalEntries(c.Request().Context(), tenantID, fiscalYearID)
+	entries, err := h.service.ListJournalEntries(c.Request().Context(), tenantID, fiscalYearIDs)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return fiscalPeriodError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, entries)
@@ -106,18 +111,22 @@ func (h *JournalHandler) ListJournalEntries(c echo.Context) error {
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/accounting/journals/{id} [get]
 func (h *JournalHandler) GetJournalEntry(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid journal entry ID"})
 	}
 
-	entry, err := h.service.GetJournalEntry(c.Request().Context(), id)
+	entry, err := h.service.GetJournalEntry(c.Request().Context(), tenantID, id)
 	if err != nil {
+		if errors.Is(err, domain.ErrJournalEntryNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
-	if entry == nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "Journal entry not found"})
-	}
 
 	return c.JSON(http.StatusOK, entry)
 }
@@ -130,9 +139,14 @@ func (h *JournalHandler) GetJournalEntry(c echo.Context) error {
 // @Param id path string true "Journal Entry ID"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/accounting/journals/{id}/post [post]
 func (h *JournalHandler) PostJournalEntry(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid journal entry ID"})
@@ -142,9 +156,147 @@ func (h *JournalHandler) PostJournalEntry(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
 	}
 
-	if err := h.service.PostJournalEntry(c.Request().Context(), id, userID); err != nil {
+	if err := h.service.PostJournalEntry(c.Request().Context(), tenantID, id, userID); err != nil {
+		if errors.Is(err, domain.ErrJournalEntryNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "Journal entry posted successfully"})
 }
+
+// ImportJournalEntries creates draft journal entries from a CSV or XLSX file of vouchers
+// @Summary Import Journal Entries
+// @Description Import vouchers, e.g. migrated from Excel, from a CSV or XLSX file (first sheet) under a header row.
+// @Description Each voucher is a row of type "header" with date (AD, YYYY-MM-DD) or dateBs (BS, YYYY-MM-DD),
+// @Description description and optionally voucher (its number in the old books), followed by rows of type "line"
+// @Description with account (account code), debit or credit, and optionally narration. Every voucher must balance
+// @Description and fall in an open fiscal year. Vouchers are created as drafts, all together, only when every row
+// @Description is valid; with dryRun nothing is created. The report lists each rejected row's errors by row
+// @Description number, the header being row 1; a voucher that does not balance is reported on its header row.
+// @Tags Accounting
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file (max 10 MB, 5000 rows)"
+// @Param dryRun formData bool false "Only validate"
+// @Success 200 {object} domain.JournalImportReport
+// @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 422 {object} domain.JournalImportReport
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/journals/import [post]
+func (h *JournalHandler) ImportJournalEntries(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required"})
+	}
+	table, err := dataimport.ReadUpload(fileHeader)
+	if err != nil {
+		if errors.Is(err, dataimport.ErrFileTooLarge) {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	dryRun, _ := strconv.ParseBool(c.FormValue("dryRun"))
+
+	report, err := h.service.ImportJournalEntries(c.Request().Context(), tenantID, userID, table, dryRun)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if !report.OK() {
+		return c.JSON(http.StatusUnprocessableEntity, report)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// PostReference posts a sales invoice, purchase bill or other document to the journal
+// @Summary Post Document
+// @Description Book a document as a posted journal entry on the tenant's posting accounts.
+// @Description Documents are posted when issued; use this after fixing a failed posting.
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Param request body dto.PostReferenceRequest true "Document (referenceType INVOICE, INVOICE_VOID or PURCHASE_BILL)"
+// @Success 201 {object} domain.JournalEntry
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/journals/post-reference [post]
+func (h *JournalHandler) PostReference(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	var req dto.PostReferenceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	entry, err := h.service.PostFromReference(c.Request().Context(), tenantID, req.ReferenceType, req.ReferenceID)
+	if err != nil {
+		return postingError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, entry)
+}
+
+// fiscalYearIDsParam reads the fiscalYearId query param, which may be repeated or hold
+// comma-separated IDs
+func fiscalYearIDsParam(c echo.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, value := range c.QueryParams()["fiscalYearId"] {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			id, err := uuid.Parse(part)
+			if err != nil {
+				return nil, errors.New("Invalid fiscalYearId")
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// fiscalPeriodError maps fiscal year lookup errors to HTTP statuses
+func fiscalPeriodError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrFiscalYearNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrNoFiscalYears), errors.Is(err, domain.ErrTooManyFiscalYears), errors.Is(err, domain.ErrInvalidReportPeriod),
+		errors.Is(err, domain.ErrInvalidComparativeReport):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// postingError maps automatic posting errors to HTTP statuses
+func postingError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrReferenceAlreadyPosted):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrUnknownReferenceType), errors.Is(err, domain.ErrReferenceNotPostable),
+		errors.Is(err, domain.ErrReferenceNotPosted), errors.Is(err, domain.ErrPostingAccountMissing),
+		errors.Is(err, domain.ErrPostingAccountInvalid):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
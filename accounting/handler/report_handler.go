@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/aceextension/accounting/domain"
 	"github.com/aceextension/accounting/service"
 	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/stream"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -19,12 +22,19 @@ func NewReportHandler(service service.AccountingService) *ReportHandler {
 
 // GetGeneralLedger retrieves the general ledger for an account
 // @Summary Get General Ledger
-// @Description Get general ledger entries for an account within a date range
+// @Description Get general ledger entries for an account within a date range, or for one
+// @Description or more fiscal years (fiscalYearId, repeated or comma-separated) instead of dates.
+// @Description Fiscal years are resolved to their date range so only their partitions are read.
 // @Tags Accounting
+// @Description Rows are streamed as they are read: a JSON array by default, or NDJSON
+// @Description (one entry per line) with format=ndjson or Accept: application/x-ndjson.
 // @Produce json
+// @Produce application/x-ndjson
 // @Param accountId query string true "Account ID"
-// @Param startDate query string true "Start Date (YYYY-MM-DD)"
-// @Param endDate query string true "End Date (YYYY-MM-DD)"
+// @Param fiscalYearId query []string false "Fiscal Year IDs, instead of dates" collectionFormat(multi)
+// @Param startDate query string false "Start Date (YYYY-MM-DD)"
+// @Param endDate query string false "End Date (YYYY-MM-DD)"
+// @Param format query string false "json (default) or ndjson"
 // @Success 200 {array} domain.LedgerEntry
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -44,16 +54,183 @@ func (h *ReportHandler) GetGeneralLedger(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid accountId"})
 	}
 
+	fiscalYearIDs, err := fiscalYearIDsParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
 	startDate := c.QueryParam("startDate")
 	endDate := c.QueryParam("endDate")
-	if startDate == "" || endDate == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "startDate and endDate are required"})
+	if len(fiscalYearIDs) == 0 && (startDate == "" || endDate == "") {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "fiscalYearId or startDate and endDate are required"})
+	}
+
+	// Year-long ledgers of busy accounts run to hundreds of thousands of lines, so
+	// rows go straight from the database cursor to the client instead of a slice
+	out := stream.NewWriter(c.Response(), stream.Negotiate(c.Request()))
+	write := func(entry *domain.LedgerEntry) error {
+		return out.Write(entry)
+	}
+	if len(fiscalYearIDs) > 0 {
+		err = h.service.StreamFiscalLedger(c.Request().Context(), tenantID, accountID, fiscalYearIDs, write)
+	} else {
+		err = h.service.StreamLedger(c.Request().Context(), tenantID, accountID, startDate, endDate, write)
+	}
+	if err != nil && !out.Started() {
+		return fiscalPeriodError(c, err)
+	}
+	if err != nil {
+		c.Logger().Errorf("general ledger stream failed after %d rows: %v", out.Rows(), err)
+	}
+	return out.Close(err)
+}
+
+// GetTrialBalance retrieves the trial balance
+// @Summary Get Trial Balance
+// @Description Each account's opening balance, posted debits and credits in the period and
+// @Description closing balance as a debit or credit. Give one or more fiscal years
+// @Description (fiscalYearId, repeated or comma-separated) or endDate, with an optional startDate.
+// @Tags Accounting
+// @Produce json
+// @Param fiscalYearId query []string false "Fiscal Year IDs, instead of dates" collectionFormat(multi)
+// @Param startDate query string false "Start Date (YYYY-MM-DD); without it movements run from the first entry"
+// @Param endDate query string false "End Date (YYYY-MM-DD)"
+// @Success 200 {object} domain.TrialBalance
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/reports/trial-balance [get]
+func (h *ReportHandler) GetTrialBalance(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	fiscalYearIDs, err := fiscalYearIDsParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	report, err := h.service.GetTrialBalance(c.Request().Context(), tenantID, fiscalYearIDs, c.QueryParam("startDate"), c.QueryParam("endDate"))
+	if err != nil {
+		return fiscalPeriodError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetProfitAndLoss retrieves the profit and loss statement
+// @Summary Get Profit and Loss
+// @Description Revenue and expense accounts totalled over one or more fiscal years
+// @Description (fiscalYearId) or startDate..endDate, with the net profit (negative for a loss).
+// @Tags Accounting
+// @Produce json
+// @Param fiscalYearId query []string false "Fiscal Year IDs, instead of dates" collectionFormat(multi)
+// @Param startDate query string false "Start Date (YYYY-MM-DD)"
+// @Param endDate query string false "End Date (YYYY-MM-DD)"
+// @Success 200 {object} domain.ProfitAndLoss
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/reports/profit-and-loss [get]
+func (h *ReportHandler) GetProfitAndLoss(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
 	}
 
-	entries, err := h.service.GetLedger(c.Request().Context(), tenantID, accountID, startDate, endDate)
+	fiscalYearIDs, err := fiscalYearIDsParam(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, entries)
+	report, err := h.service.GetProfitAndLoss(c.Request().Context(), tenantID, fiscalYearIDs, c.QueryParam("startDate"), c.QueryParam("endDate"))
+	if err != nil {
+		return fiscalPeriodError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetBalanceSheet retrieves the balance sheet
+// @Summary Get Balance Sheet
+// @Description Asset, liability and equity balances as of asOfDate, or the end of the latest
+// @Description fiscal year given. Revenue less expenses not yet closed to equity is shown as
+// @Description current earnings.
+// @Tags Accounting
+// @Produce json
+// @Param fiscalYearId query []string false "Fiscal Year IDs, instead of asOfDate" collectionFormat(multi)
+// @Param asOfDate query string false "As Of Date (YYYY-MM-DD)"
+// @Success 200 {object} domain.BalanceSheet
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/reports/balance-sheet [get]
+func (h *ReportHandler) GetBalanceSheet(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	fiscalYearIDs, err := fiscalYearIDsParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	report, err := h.service.GetBalanceSheet(c.Request().Context(), tenantID, fiscalYearIDs, c.QueryParam("asOfDate"))
+	if err != nil {
+		return fiscalPeriodError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetComparativeReport compares a fiscal year with an earlier one by Nepali month
+// @Summary Get Comparative Report
+// @Description Sales (revenue accounts), expenses or profit and loss of a fiscal year compared
+// @Description with an earlier year month by Nepali month, Shrawan to Ashad, with the change and
+// @Description percentage change. The running year is reported up to today, and its month in
+// @Description progress is compared with the same days of the earlier month. As months differ in
+// @Description length between years, daily averages are compared too. Defaults to the current
+// @Description fiscal year against the one before it.
+// @Tags Accounting
+// @Produce json
+// @Param report path string true "sales, expenses or profit-and-loss"
+// @Param fiscalYearId query string false "Fiscal Year ID, defaults to the current year"
+// @Param compareFiscalYearId query string false "Fiscal Year ID to compare with, defaults to the year before"
+// @Success 200 {object} domain.ComparativeReport
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/reports/comparative/{report} [get]
+func (h *ReportHandler) GetComparativeReport(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	fiscalYearID, err := optionalIDParam(c, "fiscalYearId")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	compareFiscalYearID, err := optionalIDParam(c, "compareFiscalYearId")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	report, err := h.service.GetComparativeReport(c.Request().Context(), tenantID, domain.ComparativeReportType(c.Param("report")), fiscalYearID, compareFiscalYearID)
+	if err != nil {
+		return fiscalPeriodError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// optionalIDParam parses an optional UUID query parameter
+func optionalIDParam(c echo.Context, name string) (*uuid.UUID, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s", name)
+	}
+	return &id, nil
 }
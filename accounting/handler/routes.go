@@ -1,24 +1,60 @@
 package handler
 
 import (
+	"github.com/aceextension/core/jobs"
+	"github.com/aceextension/core/middleware"
 	"github.com/labstack/echo/v4"
 )
 
-func RegisterRoutes(e *echo.Group, accountHandler *AccountHandler, journalHandler *JournalHandler, reportHandler *ReportHandler) {
-	accountingGroup := e.Group("/accounting")
+// RegisterRoutes registers the accounting routes on accountingGroup, which the caller mounts
+// at /api/v1/accounting behind its authentication and scope checks. The routes are only
+// open to tenants whose plan includes accounting.
+func RegisterRoutes(accountingGroup *echo.Group, accountHandler *AccountHandler, journalHandler *JournalHandler, reportHandler *ReportHandler, bundleHandler *ExportBundleHandler, interCompanyHandler *InterCompanyHandler, yearEndHandler *YearEndHandler) {
+	accountingGroup.Use(middleware.RequireFeature(middleware.FeatureAccounting))
 
 	// Accounts
 	accountingGroup.POST("/accounts", accountHandler.CreateAccount)
 	accountingGroup.GET("/accounts", accountHandler.ListAccounts)
 	accountingGroup.GET("/accounts/:id", accountHandler.GetAccount)
 	accountingGroup.PUT("/accounts/:id", accountHandler.UpdateAccount)
+	accountingGroup.POST("/accounts/:id/merge", accountHandler.MergeAccount)
+	accountingGroup.GET("/posting-accounts", accountHandler.GetPostingAccounts)
+	accountingGroup.PUT("/posting-accounts", accountHandler.SetPostingAccounts)
 
 	// Journal Entries
 	accountingGroup.POST("/journals", journalHandler.CreateJournalEntry)
+	accountingGroup.POST("/journals/post-reference", journalHandler.PostReference)
+	accountingGroup.POST("/journals/import", journalHandler.ImportJournalEntries)
 	accountingGroup.GET("/journals", journalHandler.ListJournalEntries)
 	accountingGroup.GET("/journals/:id", journalHandler.GetJournalEntry)
 	accountingGroup.POST("/journals/:id/post", journalHandler.PostJournalEntry)
 
-	// Reports
-	accountingGroup.GET("/reports/general-ledger", reportHandler.GetGeneralLedger)
+	// Reports. Those over a long period can outlive the load balancer's timeout, so they
+	// turn into background jobs past the execution budget
+	accountingGroup.GET("/reports/general-ledger", reportHandler.GetGeneralLedger, jobs.Async)
+	accountingGroup.GET("/reports/trial-balance", reportHandler.GetTrialBalance, jobs.Async)
+	accountingGroup.GET("/reports/profit-and-loss", reportHandler.GetProfitAndLoss, jobs.Async)
+	accountingGroup.GET("/reports/balance-sheet", reportHandler.GetBalanceSheet, jobs.Async)
+	accountingGroup.GET("/reports/comparative/:report", reportHandler.GetComparativeReport, jobs.Async)
+
+	// Year-end close
+	accountingGroup.POST("/year-end/:fiscalYearId/carry-forward", yearEndHandler.CarryForward)
+	accountingGroup.GET("/opening-balances", yearEndHandler.ListOpeningBalances)
+
+	// Year-end export bundles
+	accountingGroup.POST("/export-bundles", bundleHandler.CreateExportBundle)
+	accountingGroup.GET("/export-bundles", bundleHandler.ListExportBundles)
+	accountingGroup.GET("/export-bundles/:id", bundleHandler.GetExportBundle)
+	accountingGroup.GET("/export-bundles/:id/download", bundleHandler.DownloadExportBundle)
+
+	// Inter-company
+	accountingGroup.POST("/intercompany/links", interCompanyHandler.CreateLink)
+	accountingGroup.GET("/intercompany/links", interCompanyHandler.ListLinks)
+	accountingGroup.PUT("/intercompany/links/:id/accounts", interCompanyHandler.UpdateLinkAccounts)
+	accountingGroup.DELETE("/intercompany/links/:id", interCompanyHandler.DeleteLink)
+	accountingGroup.GET("/intercompany/links/:id/eliminations", interCompanyHandler.GetEliminations)
+	accountingGroup.POST("/intercompany/transactions", interCompanyHandler.RecordSale)
+	accountingGroup.GET("/intercompany/transactions", interCompanyHandler.ListTransactions)
+	accountingGroup.GET("/intercompany/transactions/:id", interCompanyHandler.GetTransaction)
+	accountingGroup.POST("/intercompany/transactions/:id/mirror", interCompanyHandler.RetryMirror)
 }
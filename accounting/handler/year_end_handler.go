@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/accounting/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type YearEndHandler struct {
+	service service.YearEndCloseService
+}
+
+func NewYearEndHandler(service service.YearEndCloseService) *YearEndHandler {
+	return &YearEndHandler{service: service}
+}
+
+// CarryForward brings a closed year's balances into the next year again
+// @Summary Carry Forward Balances
+// @Description Bring a closed fiscal year's asset, liability and equity balances into the next
+// @Description fiscal year as opening balances, replacing any earlier carry-forward. Closing a year
+// @Description does this already when the next year exists; run it after creating the next year.
+// @Tags Accounting
+// @Produce json
+// @Param fiscalYearId path string true "Closed Fiscal Year ID"
+// @Success 200 {object} domain.YearEndClose
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/year-end/{fiscalYearId}/carry-forward [post]
+func (h *YearEndHandler) CarryForward(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	fiscalYearID, err := uuid.Parse(c.Param("fiscalYearId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid fiscalYearId"})
+	}
+
+	result, err := h.service.CarryForward(c.Request().Context(), tenantID, fiscalYearID)
+	if err != nil {
+		if errors.Is(err, domain.ErrFiscalYearOpen) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return fiscalPeriodError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// ListOpeningBalances lists the balances brought forward into a fiscal year
+// @Summary List Opening Balances
+// @Description Balances brought forward into a fiscal year when the year before was closed
+// @Tags Accounting
+// @Produce json
+// @Param fiscalYearId query string true "Fiscal Year ID"
+// @Success 200 {array} domain.OpeningBalance
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/accounting/opening-balances [get]
+func (h *YearEndHandler) ListOpeningBalances(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	fiscalYearID, err := uuid.Parse(c.QueryParam("fiscalYearId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid fiscalYearId"})
+	}
+
+	balances, err := h.service.ListOpeningBalances(c.Request().Context(), tenantID, fiscalYearID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, balances)
+}
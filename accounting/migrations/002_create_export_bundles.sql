@@ -0,0 +1,43 @@
+-- 002_create_export_bundles.sql
+
+-- Year-end report bundles for accountants, built by a background worker
+CREATE TABLE IF NOT EXISTS export_bundles (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    fiscal_year_id UUID NOT NULL,
+    fiscal_year_name VARCHAR(20) NOT NULL,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL, -- Reports are as of this day (Ashad end)
+    formats TEXT[] NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued', -- queued, running, completed, failed, expired
+    progress INT NOT NULL DEFAULT 0, -- Percent of reports written
+    current_step VARCHAR(255),
+    reports JSONB NOT NULL DEFAULT '[]', -- Reports and files in the zip
+    file_name VARCHAR(255) NOT NULL,
+    storage_key VARCHAR(255),
+    size_bytes BIGINT,
+    error TEXT,
+    requested_by UUID NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ, -- Download link expiry
+    CONSTRAINT chk_export_bundles_status CHECK (status IN ('queued', 'running', 'completed', 'failed', 'expired')),
+    CONSTRAINT chk_export_bundles_progress CHECK (progress BETWEEN 0 AND 100)
+);
+
+-- One bundle at a time per fiscal year
+CREATE UNIQUE INDEX IF NOT EXISTS uq_export_bundles_in_progress
+    ON export_bundles(tenant_id, fiscal_year_id) WHERE status IN ('queued', 'running');
+CREATE INDEX IF NOT EXISTS idx_export_bundles_tenant ON export_bundles(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_export_bundles_queue ON export_bundles(created_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_export_bundles_expiry ON export_bundles(expires_at) WHERE status = 'completed';
+
+ALTER TABLE export_bundles ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON export_bundles;
+CREATE POLICY tenant_isolation ON export_bundles
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
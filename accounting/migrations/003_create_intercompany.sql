@@ -0,0 +1,66 @@
+-- 003_create_intercompany.sql
+
+-- Links between companies (tenants) with a common owner, one row per side
+CREATE TABLE IF NOT EXISTS tenant_links (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    partner_tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    receivable_account_id UUID REFERENCES accounts(id), -- Due from the partner
+    payable_account_id UUID REFERENCES accounts(id), -- Due to the partner
+    revenue_account_id UUID REFERENCES accounts(id), -- Sales to the partner
+    expense_account_id UUID REFERENCES accounts(id), -- Purchases from the partner
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT chk_tenant_links_self CHECK (tenant_id <> partner_tenant_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tenant_links_active
+    ON tenant_links(tenant_id, partner_tenant_id) WHERE is_active = true;
+
+-- Sales between linked companies; the buyer's side is mirrored as a draft purchase bill
+CREATE TABLE IF NOT EXISTS intercompany_transactions (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL, -- Seller
+    partner_tenant_id UUID NOT NULL, -- Buyer
+    link_id UUID NOT NULL REFERENCES tenant_links(id),
+    document_number VARCHAR(100) NOT NULL,
+    document_id UUID,
+    document_date DATE NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    sub_total DECIMAL(15, 2) NOT NULL,
+    vat_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15, 2) NOT NULL,
+    mirror_status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, created, failed, skipped
+    mirror_document_id UUID, -- Buyer's draft purchase bill
+    mirror_error TEXT,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT chk_intercompany_mirror_status CHECK (mirror_status IN ('pending', 'created', 'failed', 'skipped')),
+    CONSTRAINT uq_intercompany_document UNIQUE (tenant_id, document_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_intercompany_seller ON intercompany_transactions(tenant_id, partner_tenant_id, document_date);
+CREATE INDEX IF NOT EXISTS idx_intercompany_buyer ON intercompany_transactions(partner_tenant_id, tenant_id, document_date);
+
+ALTER TABLE tenant_links ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON tenant_links;
+CREATE POLICY tenant_isolation ON tenant_links
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+-- Both the seller and the buyer see a transaction
+ALTER TABLE intercompany_transactions ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON intercompany_transactions;
+CREATE POLICY tenant_isolation ON intercompany_transactions
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR partner_tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
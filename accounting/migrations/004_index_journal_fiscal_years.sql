@@ -0,0 +1,11 @@
+-- 004_index_journal_fiscal_years.sql
+
+-- Journal queries by fiscal year also bound transaction_date (the partition key), so
+-- the planner prunes to the year's partitions; within them these indexes find the rows.
+-- Indexes on the partitioned parents are created on every partition, including those
+-- of archived and closed years.
+CREATE INDEX IF NOT EXISTS idx_journal_entries_tenant_fiscal_year
+    ON journal_entries(tenant_id, fiscal_year_id, transaction_date);
+
+CREATE INDEX IF NOT EXISTS idx_journal_lines_account_date
+    ON journal_lines(account_id, transaction_date);
@@ -0,0 +1,42 @@
+-- 005_create_journal_postings.sql
+
+-- Accounts each posting role is booked to when documents are posted automatically
+CREATE TABLE IF NOT EXISTS posting_accounts (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    role VARCHAR(30) NOT NULL, -- receivable, cash, revenue, output_vat, payable, purchases, input_vat
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, role)
+);
+
+-- The journal entry posted for each document. journal_entries is partitioned by
+-- transaction_date, so a unique reference cannot be enforced there; this table
+-- keeps a document from being posted twice.
+CREATE TABLE IF NOT EXISTS journal_postings (
+    tenant_id UUID NOT NULL,
+    reference_type VARCHAR(50) NOT NULL, -- INVOICE, INVOICE_VOID, PURCHASE_BILL
+    reference_id UUID NOT NULL,
+    journal_entry_id UUID NOT NULL,
+    transaction_date DATE NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, reference_type, reference_id),
+    FOREIGN KEY (journal_entry_id, transaction_date) REFERENCES journal_entries (id, transaction_date) ON DELETE CASCADE
+);
+
+ALTER TABLE posting_accounts ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON posting_accounts;
+CREATE POLICY tenant_isolation ON posting_accounts
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+ALTER TABLE journal_postings ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON journal_postings;
+CREATE POLICY tenant_isolation ON journal_postings
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,8 @@
+-- 006_add_account_merges.sql
+
+-- A duplicate account merged into another keeps its row, archived, so old references
+-- resolve; its journal lines, posting roles and links are moved to the account kept
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS merged_into_id UUID REFERENCES accounts(id);
+
+CREATE INDEX IF NOT EXISTS idx_accounts_merged_into
+    ON accounts(merged_into_id) WHERE merged_into_id IS NOT NULL;
@@ -0,0 +1,26 @@
+-- 007_create_opening_balances.sql
+
+-- Balances of asset, liability and equity accounts brought forward into a fiscal year
+-- when the year before is closed. Revenue and expenses are closed to retained earnings
+-- by a YEAR_END_CLOSE journal entry first, so they start every year at zero.
+CREATE TABLE IF NOT EXISTS opening_balances (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    fiscal_year_id UUID NOT NULL,
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    debit DECIMAL(20, 4) NOT NULL DEFAULT 0,
+    credit DECIMAL(20, 4) NOT NULL DEFAULT 0,
+    source_fiscal_year_id UUID NOT NULL, -- The closed year carried forward
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (fiscal_year_id, account_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_opening_balances_tenant ON opening_balances(tenant_id, fiscal_year_id);
+
+ALTER TABLE opening_balances ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON opening_balances;
+CREATE POLICY tenant_isolation ON opening_balances
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
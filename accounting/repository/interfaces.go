@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/aceextension/accounting/domain"
 	"github.com/google/uuid"
@@ -9,18 +10,97 @@ import (
 
 type AccountRepository interface {
 	Create(ctx context.Context, account *domain.Account) error
-	GetByID(ctx context.Context, id uuid.UUID) (*domain.Account, error)
+	// GetByID returns the tenant's account, nil when the tenant has no account with the ID
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Account, error)
 	GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.Account, error)
 	List(ctx context.Context, tenantID uuid.UUID) ([]*domain.Account, error)
 	Update(ctx context.Context, account *domain.Account) error
-	Delete(ctx context.Context, id uuid.UUID) error // Soft delete
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error // Soft delete
+	// Merge re-points the source's journal lines, posting roles, inter-company links,
+	// opening balances and sub-accounts to the target and archives the source, in one transaction; fills in
+	// the counts. ErrAccountMerged if the source was merged meanwhile.
+	Merge(ctx context.Context, merge *domain.AccountMerge) error
 }
 
 type JournalRepository interface {
 	Create(ctx context.Context, entry *domain.JournalEntry) error
-	GetByID(ctx context.Context, id uuid.UUID) (*domain.JournalEntry, error)
-	List(ctx context.Context, tenantID uuid.UUID, fiscalYearID uuid.UUID) ([]*domain.JournalEntry, error)
-	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.JournalStatus) error
+	// CreateBatch saves the entries in one transaction, so all of them are saved or none
+	CreateBatch(ctx context.Context, entries []*domain.JournalEntry) error
+	// CreatePosting saves an entry posted from a document; ErrReferenceAlreadyPosted
+	// if the document has an entry
+	CreatePosting(ctx context.Context, entry *domain.JournalEntry) error
+	// GetByID returns the tenant's entry, nil when the tenant has no entry with the ID
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.JournalEntry, error)
+	// GetByReference returns the entry posted from a document, nil when there is none
+	GetByReference(ctx context.Context, tenantID uuid.UUID, referenceType string, referenceID uuid.UUID) (*domain.JournalEntry, error)
+	// List returns the latest entries of the period's fiscal years
+	List(ctx context.Context, tenantID uuid.UUID, period domain.FiscalPeriod) ([]*domain.JournalEntry, error)
+	// UpdateStatus takes the entry's transaction date so only its partition is scanned
+	UpdateStatus(ctx context.Context, tenantID, id uuid.UUID, transactionDate time.Time, status domain.JournalStatus) error
 	// GetLedgerEntries returns flattened ledger lines for a specific account and date range
 	GetLedgerEntries(ctx context.Context, tenantID uuid.UUID, accountID uuid.UUID, startStr, endStr string) ([]*domain.LedgerEntry, error)
+	// StreamLedgerEntries passes the same lines to fn one at a time, without collecting them
+	StreamLedgerEntries(ctx context.Context, tenantID uuid.UUID, accountID uuid.UUID, startStr, endStr string, fn func(*domain.LedgerEntry) error) error
+	// StreamFiscalLedgerEntries passes the lines of the period's fiscal years to fn
+	StreamFiscalLedgerEntries(ctx context.Context, tenantID uuid.UUID, accountID uuid.UUID, period domain.FiscalPeriod, fn func(*domain.LedgerEntry) error) error
+	// AccountTotals sums posted debits and credits per account for lines dated from..to;
+	// a nil from starts at the first entry. Accounts without lines are omitted.
+	AccountTotals(ctx context.Context, tenantID uuid.UUID, from *time.Time, to time.Time) (map[uuid.UUID]domain.AccountTotal, error)
+	// ReferenceTotals is AccountTotals over entries of one reference type only
+	ReferenceTotals(ctx context.Context, tenantID uuid.UUID, referenceType string, from *time.Time, to time.Time) (map[uuid.UUID]domain.AccountTotal, error)
+	// DailyTypeTotals sums posted revenue and expense lines per day and account type,
+	// leaving out year-end closing entries
+	DailyTypeTotals(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]domain.DailyTypeTotal, error)
+}
+
+type PostingAccountRepository interface {
+	Get(ctx context.Context, tenantID uuid.UUID) (domain.PostingAccounts, error)
+	// Replace saves the tenant's whole mapping; roles left out are unmapped
+	Replace(ctx context.Context, tenantID uuid.UUID, accounts domain.PostingAccounts) error
+}
+
+type OpeningBalanceRepository interface {
+	// Replace saves the balances brought forward into a fiscal year, replacing any earlier carry-forward
+	Replace(ctx context.Context, tenantID, fiscalYearID uuid.UUID, balances []*domain.OpeningBalance) error
+	List(ctx context.Context, tenantID, fiscalYearID uuid.UUID) ([]*domain.OpeningBalance, error)
+}
+
+type ExportBundleRepository interface {
+	// Create queues a bundle; ErrBundleInProgress if one for the fiscal year is queued or running
+	Create(ctx context.Context, bundle *domain.ExportBundle) error
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.ExportBundle, error)
+	List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.ExportBundle, error)
+	// ClaimNext marks the oldest queued bundle of any tenant running and returns it, nil when none is queued
+	ClaimNext(ctx context.Context) (*domain.ExportBundle, error)
+	UpdateProgress(ctx context.Context, id uuid.UUID, progress int, step string) error
+	// Finish saves the outcome of a run: completed with its file, or failed with an error
+	Finish(ctx context.Context, bundle *domain.ExportBundle) error
+	// ListExpired returns completed bundles whose download window has passed
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*domain.ExportBundle, error)
+	MarkExpired(ctx context.Context, id uuid.UUID) error
+}
+
+type InterCompanyRepository interface {
+	// CreateLinkPair saves both sides of a link; ErrTenantLinkExists if the tenants are linked
+	CreateLinkPair(ctx context.Context, link, reverse *domain.TenantLink) error
+	GetLink(ctx context.Context, tenantID, id uuid.UUID) (*domain.TenantLink, error)
+	// GetLinkByPartner returns the tenant's active side of its link with partnerTenantID
+	GetLinkByPartner(ctx context.Context, tenantID, partnerTenantID uuid.UUID) (*domain.TenantLink, error)
+	ListLinks(ctx context.Context, tenantID uuid.UUID) ([]*domain.TenantLink, error)
+	UpdateLinkAccounts(ctx context.Context, link *domain.TenantLink) error
+	// DeactivateLinkPair switches off both sides of the link between two tenants
+	DeactivateLinkPair(ctx context.Context, tenantID, partnerTenantID uuid.UUID) error
+
+	// IsOwner reports whether the user is an owner of the tenant, by home tenant or membership
+	IsOwner(ctx context.Context, userID, tenantID uuid.UUID) (bool, error)
+	GetCompany(ctx context.Context, tenantID uuid.UUID) (*domain.Company, error)
+
+	CreateTransaction(ctx context.Context, txn *domain.InterCompanyTransaction) error
+	// GetTransaction finds a transaction the tenant sold or bought
+	GetTransaction(ctx context.Context, tenantID, id uuid.UUID) (*domain.InterCompanyTransaction, error)
+	// ListTransactions lists the tenant's sales and purchases, newest first, optionally with one partner
+	ListTransactions(ctx context.Context, tenantID uuid.UUID, partnerTenantID *uuid.UUID, limit, offset int) ([]*domain.InterCompanyTransaction, error)
+	UpdateMirror(ctx context.Context, txn *domain.InterCompanyTransaction) error
+	// SalesTotal sums the totals of sales from seller to buyer dated from..to
+	SalesTotal(ctx context.Context, sellerID, buyerID uuid.UUID, from, to time.Time) (float64, error)
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/aceextension/accounting/domain"
@@ -30,16 +31,16 @@ func (r *postgresAccountRepository) Create(ctx context.Context, account *domain.
 	return err
 }
 
-func (r *postgresAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Account, error) {
+func (r *postgresAccountRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Account, error) {
 	query := `
-		SELECT id, tenant_id, code, name, type, parent_id, is_active, description, created_at, updated_at
+		SELECT id, tenant_id, code, name, type, parent_id, is_active, description, merged_into_id, created_at, updated_at
 		FROM accounts
-		WHERE id = $1
+		WHERE id = $1 AND tenant_id = $2
 	`
 	var acc domain.Account
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := r.pool.QueryRow(ctx, query, id, tenantID).Scan(
 		&acc.ID, &acc.TenantID, &acc.Code, &acc.Name, &acc.Type,
-		&acc.ParentID, &acc.IsActive, &acc.Description, &acc.CreatedAt, &acc.UpdatedAt,
+		&acc.ParentID, &acc.IsActive, &acc.Description, &acc.MergedIntoID, &acc.CreatedAt, &acc.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -52,14 +53,14 @@ func (r *postgresAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (
 
 func (r *postgresAccountRepository) GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.Account, error) {
 	query := `
-		SELECT id, tenant_id, code, name, type, parent_id, is_active, description, created_at, updated_at
+		SELECT id, tenant_id, code, name, type, parent_id, is_active, description, merged_into_id, created_at, updated_at
 		FROM accounts
 		WHERE tenant_id = $1 AND code = $2
 	`
 	var acc domain.Account
 	err := r.pool.QueryRow(ctx, query, tenantID, code).Scan(
 		&acc.ID, &acc.TenantID, &acc.Code, &acc.Name, &acc.Type,
-		&acc.ParentID, &acc.IsActive, &acc.Description, &acc.CreatedAt, &acc.UpdatedAt,
+		&acc.ParentID, &acc.IsActive, &acc.Description, &acc.MergedIntoID, &acc.CreatedAt, &acc.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -72,7 +73,7 @@ func (r *postgresAccountRepository) GetByCode(ctx context.Context, tenantID uuid
 
 func (r *postgresAccountRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*domain.Account, error) {
 	query := `
-		SELECT id, tenant_id, code, name, type, parent_id, is_active, description, created_at, updated_at
+		SELECT id, tenant_id, code, name, type, parent_id, is_active, description, merged_into_id, created_at, updated_at
 		FROM accounts
 		WHERE tenant_id = $1
 		ORDER BY code ASC
@@ -88,7 +89,7 @@ func (r *postgresAccountRepository) List(ctx context.Context, tenantID uuid.UUID
 		var acc domain.Account
 		if err := rows.Scan(
 			&acc.ID, &acc.TenantID, &acc.Code, &acc.Name, &acc.Type,
-			&acc.ParentID, &acc.IsActive, &acc.Description, &acc.CreatedAt, &acc.UpdatedAt,
+			&acc.ParentID, &acc.IsActive, &acc.Description, &acc.MergedIntoID, &acc.CreatedAt, &acc.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -101,19 +102,104 @@ func (r *postgresAccountRepository) Update(ctx context.Context, account *domain.
 	query := `
 		UPDATE accounts
 		SET code=$2, name=$3, type=$4, parent_id=$5, is_active=$6, description=$7, updated_at=$8
-		WHERE id=$1
+		WHERE id=$1 AND tenant_id=$9
 	`
 	_, err := r.pool.Exec(ctx, query,
 		account.ID, account.Code, account.Name, account.Type,
-		account.ParentID, account.IsActive, account.Description, time.Now(),
+		account.ParentID, account.IsActive, account.Description, time.Now(), account.TenantID,
 	)
 	return err
 }
 
-func (r *postgresAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+func (r *postgresAccountRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
 	// Soft delete by setting is_active = false
 	// Alternatively, implement strict deletion logic if no transactions exist
-	query := `UPDATE accounts SET is_active=false, updated_at=$2 WHERE id=$1`
-	_, err := r.pool.Exec(ctx, query, id, time.Now())
+	query := `UPDATE accounts SET is_active=false, updated_at=$3 WHERE id=$1 AND tenant_id=$2`
+	_, err := r.pool.Exec(ctx, query, id, tenantID, time.Now())
 	return err
 }
+
+func (r *postgresAccountRepository) Merge(ctx context.Context, merge *domain.AccountMerge) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		// Lock both accounts so no line is booked to the source while it is moved
+		if _, err := tx.Exec(ctx, `SELECT id FROM accounts WHERE id IN ($1, $2) FOR UPDATE`, merge.SourceID, merge.TargetID); err != nil {
+			return fmt.Errorf("failed to lock accounts: %w", err)
+		}
+
+		var sourceParentID *uuid.UUID
+		err := tx.QueryRow(ctx, `
+			UPDATE accounts SET is_active = false, merged_into_id = $2, updated_at = $3
+			WHERE id = $1 AND tenant_id = $4 AND merged_into_id IS NULL
+			RETURNING parent_id`,
+			merge.SourceID, merge.TargetID, merge.MergedAt, merge.TenantID,
+		).Scan(&sourceParentID)
+		if err == pgx.ErrNoRows {
+			return domain.ErrAccountMerged
+		}
+		if err != nil {
+			return fmt.Errorf("failed to archive account: %w", err)
+		}
+
+		// Sub-accounts move under the target; the target itself, if it was one, takes the source's parent
+		tag, err := tx.Exec(ctx, `
+			UPDATE accounts SET parent_id = CASE WHEN id = $2 THEN $3 ELSE $2 END, updated_at = $4
+			WHERE parent_id = $1 AND tenant_id = $5`,
+			merge.SourceID, merge.TargetID, sourceParentID, merge.MergedAt, merge.TenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to move sub-accounts: %w", err)
+		}
+		merge.ChildrenMoved = tag.RowsAffected()
+
+		// Lines of every partition, closed years included, so history stays in one account
+		tag, err = tx.Exec(ctx, `UPDATE journal_lines SET account_id = $2 WHERE account_id = $1`, merge.SourceID, merge.TargetID)
+		if err != nil {
+			return fmt.Errorf("failed to move journal lines: %w", err)
+		}
+		merge.LinesMoved = tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `
+			UPDATE posting_accounts SET account_id = $2, updated_at = $3
+			WHERE tenant_id = $4 AND account_id = $1`,
+			merge.SourceID, merge.TargetID, merge.MergedAt, merge.TenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to move posting roles: %w", err)
+		}
+		merge.PostingRolesMoved = tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `
+			UPDATE tenant_links SET
+				receivable_account_id = CASE WHEN receivable_account_id = $1 THEN $2 ELSE receivable_account_id END,
+				payable_account_id = CASE WHEN payable_account_id = $1 THEN $2 ELSE payable_account_id END,
+				revenue_account_id = CASE WHEN revenue_account_id = $1 THEN $2 ELSE revenue_account_id END,
+				expense_account_id = CASE WHEN expense_account_id = $1 THEN $2 ELSE expense_account_id END,
+				updated_at = $3
+			WHERE tenant_id = $4
+			  AND $1 IN (receivable_account_id, payable_account_id, revenue_account_id, expense_account_id)`,
+			merge.SourceID, merge.TargetID, merge.MergedAt, merge.TenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to move inter-company links: %w", err)
+		}
+		merge.LinksMoved = tag.RowsAffected()
+
+		// Balances brought forward add up on the target, netted to one side
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO opening_balances (tenant_id, fiscal_year_id, account_id, debit, credit, source_fiscal_year_id, created_at)
+			SELECT tenant_id, fiscal_year_id, $2, debit, credit, source_fiscal_year_id, created_at
+			FROM opening_balances WHERE account_id = $1
+			ON CONFLICT (fiscal_year_id, account_id) DO UPDATE SET
+				debit = GREATEST(opening_balances.debit - opening_balances.credit + EXCLUDED.debit - EXCLUDED.credit, 0),
+				credit = GREATEST(opening_balances.credit - opening_balances.debit + EXCLUDED.credit - EXCLUDED.debit, 0)`,
+			merge.SourceID, merge.TargetID,
+		); err != nil {
+			return fmt.Errorf("failed to move opening balances: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM opening_balances WHERE account_id = $1`, merge.SourceID); err != nil {
+			return fmt.Errorf("failed to move opening balances: %w", err)
+		}
+
+		return nil
+	})
+}
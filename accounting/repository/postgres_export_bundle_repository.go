@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type postgresExportBundleRepository struct {
+	pool db.QueryExecutor
+}
+
+func NewPostgresExportBundleRepository(pool db.QueryExecutor) ExportBundleRepository {
+	return &postgresExportBundleRepository{pool: pool}
+}
+
+const exportBundleColumns = `
+	id, tenant_id, fiscal_year_id, fiscal_year_name, period_start, period_end, formats,
+	status, progress, current_step, reports, file_name, storage_key, size_bytes, error,
+	requested_by, created_at, started_at, completed_at, expires_at`
+
+func (r *postgresExportBundleRepository) Create(ctx context.Context, b *domain.ExportBundle) error {
+	reports, err := json.Marshal(b.Reports)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO export_bundles (` + exportBundleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+	`
+	_, err = r.pool.Exec(ctx, query,
+		b.ID, b.TenantID, b.FiscalYearID, b.FiscalYearName, b.PeriodStart, b.PeriodEnd, b.Formats,
+		b.Status, b.Progress, b.CurrentStep, reports, b.FileName, b.StorageKey, b.SizeBytes, b.Error,
+		b.RequestedBy, b.CreatedAt, b.StartedAt, b.CompletedAt, b.ExpiresAt,
+	)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uq_export_bundles_in_progress" {
+		return domain.ErrBundleInProgress
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create export bundle: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresExportBundleRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.ExportBundle, error) {
+	query := `SELECT ` + exportBundleColumns + ` FROM export_bundles WHERE id = $1 AND tenant_id = $2`
+	return scanExportBundle(r.pool.QueryRow(ctx, query, id, tenantID))
+}
+
+func (r *postgresExportBundleRepository) List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.ExportBundle, error) {
+	query := `
+		SELECT ` + exportBundleColumns + `
+		FROM export_bundles
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	return r.queryExportBundles(ctx, query, tenantID, limit, offset)
+}
+
+// ClaimNext takes the oldest queued bundle, skipping rows another worker has locked
+func (r *postgresExportBundleRepository) ClaimNext(ctx context.Context) (*domain.ExportBundle, error) {
+	query := `
+		UPDATE export_bundles SET status = 'running', started_at = NOW(), progress = 0
+		WHERE id = (
+			SELECT id FROM export_bundles
+			WHERE status = 'queued'
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + exportBundleColumns
+
+	bundle, err := scanExportBundle(r.pool.QueryRow(ctx, query))
+	if errors.Is(err, domain.ErrBundleNotFound) {
+		return nil, nil
+	}
+	return bundle, err
+}
+
+func (r *postgresExportBundleRepository) UpdateProgress(ctx context.Context, id uuid.UUID, progress int, step string) error {
+	query := `UPDATE export_bundles SET progress = $2, current_step = $3 WHERE id = $1 AND status = 'running'`
+	if _, err := r.pool.Exec(ctx, query, id, progress, step); err != nil {
+		return fmt.Errorf("failed to update export bundle progress: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresExportBundleRepository) Finish(ctx context.Context, b *domain.ExportBundle) error {
+	reports, err := json.Marshal(b.Reports)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE export_bundles
+		SET status = $2, progress = $3, current_step = $4, reports = $5, storage_key = $6,
+		    size_bytes = $7, error = $8, completed_at = $9, expires_at = $10
+		WHERE id = $1 AND status = 'running'
+	`
+	_, err = r.pool.Exec(ctx, query,
+		b.ID, b.Status, b.Progress, b.CurrentStep, reports, b.StorageKey,
+		b.SizeBytes, b.Error, b.CompletedAt, b.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save export bundle: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresExportBundleRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*domain.ExportBundle, error) {
+	query := `
+		SELECT ` + exportBundleColumns + `
+		FROM export_bundles
+		WHERE status = 'completed' AND expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+	return r.queryExportBundles(ctx, query, now, limit)
+}
+
+func (r *postgresExportBundleRepository) MarkExpired(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE export_bundles SET status = 'expired', storage_key = NULL WHERE id = $1 AND status = 'completed'`
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to expire export bundle: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresExportBundleRepository) queryExportBundles(ctx context.Context, query string, args ...any) ([]*domain.ExportBundle, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query export bundles: %w", err)
+	}
+	defer rows.Close()
+
+	bundles := []*domain.ExportBundle{}
+	for rows.Next() {
+		bundle, err := scanExportBundle(rows)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, bundle)
+	}
+	return bundles, rows.Err()
+}
+
+func scanExportBundle(row pgx.Row) (*domain.ExportBundle, error) {
+	var b domain.ExportBundle
+	var reports []byte
+
+	err := row.Scan(
+		&b.ID, &b.TenantID, &b.FiscalYearID, &b.FiscalYearName, &b.PeriodStart, &b.PeriodEnd, &b.Formats,
+		&b.Status, &b.Progress, &b.CurrentStep, &reports, &b.FileName, &b.StorageKey, &b.SizeBytes, &b.Error,
+		&b.RequestedBy, &b.CreatedAt, &b.StartedAt, &b.CompletedAt, &b.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBundleNotFound
+		}
+		return nil, fmt.Errorf("failed to scan export bundle: %w", err)
+	}
+
+	if err := json.Unmarshal(reports, &b.Reports); err != nil {
+		return nil, fmt.Errorf("failed to decode export bundle reports: %w", err)
+	}
+	return &b, nil
+}
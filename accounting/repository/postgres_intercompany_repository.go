@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type postgresInterCompanyRepository struct {
+	pool db.QueryExecutor
+}
+
+func NewPostgresInterCompanyRepository(pool db.QueryExecutor) InterCompanyRepository {
+	return &postgresInterCompanyRepository{pool: pool}
+}
+
+const tenantLinkSelect = `
+	SELECT l.id, l.tenant_id, l.partner_tenant_id, COALESCE(t.business_name, t.name, ''),
+	       l.receivable_account_id, l.payable_account_id, l.revenue_account_id, l.expense_account_id,
+	       l.is_active, l.created_by, l.created_at, l.updated_at
+	FROM tenant_links l
+	LEFT JOIN tenants t ON t.id = l.partner_tenant_id`
+
+const interCompanyColumns = `
+	id, tenant_id, partner_tenant_id, link_id, document_number, document_id, document_date, description,
+	sub_total, vat_amount, total_amount, mirror_status, mirror_document_id, mirror_error,
+	created_by, created_at, updated_at`
+
+func (r *postgresInterCompanyRepository) CreateLinkPair(ctx context.Context, link, reverse *domain.TenantLink) error {
+	query := `
+		INSERT INTO tenant_links (id, tenant_id, partner_tenant_id, is_active, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	// A batch runs in one implicit transaction, so both sides are saved or neither
+	batch := &pgx.Batch{}
+	for _, l := range []*domain.TenantLink{link, reverse} {
+		batch.Queue(query, l.ID, l.TenantID, l.PartnerTenantID, l.IsActive, l.CreatedBy, l.CreatedAt, l.UpdatedAt)
+	}
+
+	br := r.pool.SendBatch(ctx, batch)
+	defer br.Close()
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := br.Exec(); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return domain.ErrTenantLinkExists
+			}
+			return fmt.Errorf("failed to create tenant link: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *postgresInterCompanyRepository) GetLink(ctx context.Context, tenantID, id uuid.UUID) (*domain.TenantLink, error) {
+	query := tenantLinkSelect + ` WHERE l.id = $1 AND l.tenant_id = $2`
+	return scanTenantLink(r.pool.QueryRow(ctx, query, id, tenantID))
+}
+
+func (r *postgresInterCompanyRepository) GetLinkByPartner(ctx context.Context, tenantID, partnerTenantID uuid.UUID) (*domain.TenantLink, error) {
+	query := tenantLinkSelect + ` WHERE l.tenant_id = $1 AND l.partner_tenant_id = $2 AND l.is_active = true`
+	return scanTenantLink(r.pool.QueryRow(ctx, query, tenantID, partnerTenantID))
+}
+
+func (r *postgresInterCompanyRepository) ListLinks(ctx context.Context, tenantID uuid.UUID) ([]*domain.TenantLink, error) {
+	query := tenantLinkSelect + `
+		WHERE l.tenant_id = $1 AND l.is_active = true
+		ORDER BY COALESCE(t.business_name, t.name)
+	`
+	rows, err := r.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenant links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []*domain.TenantLink{}
+	for rows.Next() {
+		link, err := scanTenantLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+func (r *postgresInterCompanyRepository) UpdateLinkAccounts(ctx context.Context, link *domain.TenantLink) error {
+	query := `
+		UPDATE tenant_links
+		SET receivable_account_id = $3, payable_account_id = $4, revenue_account_id = $5,
+		    expense_account_id = $6, updated_at = $7
+		WHERE id = $1 AND tenant_id = $2 AND is_active = true
+	`
+	tag, err := r.pool.Exec(ctx, query, link.ID, link.TenantID,
+		link.ReceivableAccountID, link.PayableAccountID, link.RevenueAccountID, link.ExpenseAccountID, link.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant link: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTenantLinkNotFound
+	}
+	return nil
+}
+
+func (r *postgresInterCompanyRepository) DeactivateLinkPair(ctx context.Context, tenantID, partnerTenantID uuid.UUID) error {
+	query := `
+		UPDATE tenant_links SET is_active = false, updated_at = NOW()
+		WHERE is_active = true
+		  AND ((tenant_id = $1 AND partner_tenant_id = $2) OR (tenant_id = $2 AND partner_tenant_id = $1))
+	`
+	tag, err := r.pool.Exec(ctx, query, tenantID, partnerTenantID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate tenant link: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTenantLinkNotFound
+	}
+	return nil
+}
+
+func (r *postgresInterCompanyRepository) IsOwner(ctx context.Context, userID, tenantID uuid.UUID) (bool, error) {
+	// users.role is authoritative for the home tenant, memberships for the others
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM users WHERE id = $1 AND tenant_id = $2 AND role = 'owner' AND is_active = true
+		) OR EXISTS (
+			SELECT 1 FROM tenant_memberships
+			WHERE user_id = $1 AND tenant_id = $2 AND role = 'owner'
+			  AND is_active = true AND is_guest = false AND revoked_at IS NULL
+		)
+	`
+	var owner bool
+	if err := r.pool.QueryRow(ctx, query, userID, tenantID).Scan(&owner); err != nil {
+		return false, fmt.Errorf("failed to check tenant owner: %w", err)
+	}
+	return owner, nil
+}
+
+func (r *postgresInterCompanyRepository) GetCompany(ctx context.Context, tenantID uuid.UUID) (*domain.Company, error) {
+	query := `
+		SELECT id, COALESCE(business_name, name), COALESCE(pan_number, vat_number, '')
+		FROM tenants WHERE id = $1 AND is_active = true
+	`
+	var c domain.Company
+	if err := r.pool.QueryRow(ctx, query, tenantID).Scan(&c.ID, &c.Name, &c.PAN); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTenantLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+	return &c, nil
+}
+
+func (r *postgresInterCompanyRepository) CreateTransaction(ctx context.Context, t *domain.InterCompanyTransaction) error {
+	query := `
+		INSERT INTO intercompany_transactions (` + interCompanyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		t.ID, t.TenantID, t.PartnerTenantID, t.LinkID, t.DocumentNumber, t.DocumentID, t.Date, t.Description,
+		t.SubTotal, t.VATAmount, t.TotalAmount, t.MirrorStatus, t.MirrorDocumentID, t.MirrorError,
+		t.CreatedBy, t.CreatedAt, t.UpdatedAt,
+	)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return fmt.Errorf("%w: document %s was already recorded", domain.ErrInvalidInterCompany, t.DocumentNumber)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create inter-company transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresInterCompanyRepository) GetTransaction(ctx context.Context, tenantID, id uuid.UUID) (*domain.InterCompanyTransaction, error) {
+	query := `
+		SELECT ` + interCompanyColumns + `
+		FROM intercompany_transactions
+		WHERE id = $1 AND (tenant_id = $2 OR partner_tenant_id = $2)
+	`
+	return scanInterCompanyTransaction(r.pool.QueryRow(ctx, query, id, tenantID))
+}
+
+func (r *postgresInterCompanyRepository) ListTransactions(ctx context.Context, tenantID uuid.UUID, partnerTenantID *uuid.UUID, limit, offset int) ([]*domain.InterCompanyTransaction, error) {
+	query := `
+		SELECT ` + interCompanyColumns + `
+		FROM intercompany_transactions
+		WHERE (tenant_id = $1 AND ($2::uuid IS NULL OR partner_tenant_id = $2))
+		   OR (partner_tenant_id = $1 AND ($2::uuid IS NULL OR tenant_id = $2))
+		ORDER BY document_date DESC, created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.pool.Query(ctx, query, tenantID, partnerTenantID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inter-company transactions: %w", err)
+	}
+	defer rows.Close()
+
+	txns := []*domain.InterCompanyTransaction{}
+	for rows.Next() {
+		txn, err := scanInterCompanyTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
+	}
+	return txns, rows.Err()
+}
+
+func (r *postgresInterCompanyRepository) UpdateMirror(ctx context.Context, t *domain.InterCompanyTransaction) error {
+	query := `
+		UPDATE intercompany_transactions
+		SET mirror_status = $2, mirror_document_id = $3, mirror_error = $4, updated_at = $5
+		WHERE id = $1
+	`
+	if _, err := r.pool.Exec(ctx, query, t.ID, t.MirrorStatus, t.MirrorDocumentID, t.MirrorError, t.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to update inter-company mirror: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresInterCompanyRepository) SalesTotal(ctx context.Context, sellerID, buyerID uuid.UUID, from, to time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(total_amount), 0)
+		FROM intercompany_transactions
+		WHERE tenant_id = $1 AND partner_tenant_id = $2
+		  AND document_date >= $3 AND document_date <= $4
+	`
+	var total float64
+	if err := r.pool.QueryRow(ctx, query, sellerID, buyerID, from, to).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to sum inter-company sales: %w", err)
+	}
+	return total, nil
+}
+
+func scanTenantLink(row pgx.Row) (*domain.TenantLink, error) {
+	var l domain.TenantLink
+	err := row.Scan(
+		&l.ID, &l.TenantID, &l.PartnerTenantID, &l.PartnerName,
+		&l.ReceivableAccountID, &l.PayableAccountID, &l.RevenueAccountID, &l.ExpenseAccountID,
+		&l.IsActive, &l.CreatedBy, &l.CreatedAt, &l.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTenantLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to scan tenant link: %w", err)
+	}
+	return &l, nil
+}
+
+func scanInterCompanyTransaction(row pgx.Row) (*domain.InterCompanyTransaction, error) {
+	var t domain.InterCompanyTransaction
+	err := row.Scan(
+		&t.ID, &t.TenantID, &t.PartnerTenantID, &t.LinkID, &t.DocumentNumber, &t.DocumentID, &t.Date, &t.Description,
+		&t.SubTotal, &t.VATAmount, &t.TotalAmount, &t.MirrorStatus, &t.MirrorDocumentID, &t.MirrorError,
+		&t.CreatedBy, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrInterCompanyNotFound
+		}
+		return nil, fmt.Errorf("failed to scan inter-company transaction: %w", err)
+	}
+	return &t, nil
+}
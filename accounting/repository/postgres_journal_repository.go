@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/aceextension/core/db"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type postgresJournalRepository struct {
@@ -21,7 +23,31 @@ func NewPostgresJournalRepository(pool db.QueryExecutor) JournalRepository {
 
 func (r *postgresJournalRepository) Create(ctx context.Context, entry *domain.JournalEntry) error {
 	batch := &pgx.Batch{}
+	queueEntry(batch, entry, false)
+	return r.sendEntry(ctx, batch)
+}
+
+func (r *postgresJournalRepository) CreateBatch(ctx context.Context, entries []*domain.JournalEntry) error {
+	batch := &pgx.Batch{}
+	for _, entry := range entries {
+		queueEntry(batch, entry, false)
+	}
+	return r.sendEntry(ctx, batch)
+}
 
+func (r *postgresJournalRepository) CreatePosting(ctx context.Context, entry *domain.JournalEntry) error {
+	batch := &pgx.Batch{}
+	queueEntry(batch, entry, true)
+	err := r.sendEntry(ctx, batch)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.ErrReferenceAlreadyPosted
+	}
+	return err
+}
+
+// queueEntry queues the header, the document's posting record if asked, and the lines
+func queueEntry(batch *pgx.Batch, entry *domain.JournalEntry, posting bool) {
 	// 1. Insert Header
 	queryHeader := `
 		INSERT INTO journal_entries (
@@ -34,7 +60,16 @@ func (r *postgresJournalRepository) Create(ctx context.Context, entry *domain.Jo
 		entry.ReferenceID, entry.ReferenceType, entry.CreatedByUserID, entry.PostedAt, entry.CreatedAt, entry.UpdatedAt,
 	)
 
-	// 2. Insert Lines
+	// 2. Claim the document; a second posting of it fails on the primary key
+	if posting {
+		queryPosting := `
+			INSERT INTO journal_postings (tenant_id, reference_type, reference_id, journal_entry_id, transaction_date)
+			VALUES ($1, $2, $3, $4, $5)
+		`
+		batch.Queue(queryPosting, entry.TenantID, entry.ReferenceType, entry.ReferenceID, entry.ID, entry.TransactionDate)
+	}
+
+	// 3. Insert Lines
 	queryLine := `
 		INSERT INTO journal_lines (
 			id, journal_entry_id, transaction_date, account_id, debit, credit, description
@@ -45,8 +80,10 @@ func (r *postgresJournalRepository) Create(ctx context.Context, entry *domain.Jo
 			line.ID, line.JournalEntryID, entry.TransactionDate, line.AccountID, line.Debit, line.Credit, line.Description,
 		)
 	}
+}
 
-	// Execute Batch
+// sendEntry runs a queued entry; a batch is one implicit transaction, so all of it is saved or none
+func (r *postgresJournalRepository) sendEntry(ctx context.Context, batch *pgx.Batch) error {
 	br := r.pool.SendBatch(ctx, batch)
 	defer br.Close()
 
@@ -61,21 +98,24 @@ func (r *postgresJournalRepository) Create(ctx context.Context, entry *domain.Jo
 	return nil
 }
 
-func (r *postgresJournalRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.JournalEntry, error) {
+func (r *postgresJournalRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.JournalEntry, error) {
 	// Querying by ID without Date scans all partitions.
 	// Acceptable for single lookup, but ideally we should provide date.
 	// For now, we rely on the ID being unique globally (UUID).
 
 	queryEntry := `
 		SELECT id, tenant_id, fiscal_year_id, transaction_date, description, status,
-		       reference_id, reference_type, created_by_user_id, posted_at, created_at, updated_at
+		       reference_id, reference_type, created_by_user_id, posted_at, created_at, updated_at,
+		       (SELECT COUNT(*) FROM attachments a
+		        WHERE a.entity_type = 'journal_entry' AND a.entity_id = journal_entries.id AND a.deleted_at IS NULL)
 		FROM journal_entries
-		WHERE id = $1
+		WHERE tenant_id = $1 AND id = $2
 	`
 	var entry domain.JournalEntry
-	err := r.pool.QueryRow(ctx, queryEntry, id).Scan(
+	err := r.pool.QueryRow(ctx, queryEntry, tenantID, id).Scan(
 		&entry.ID, &entry.TenantID, &entry.FiscalYearID, &entry.TransactionDate, &entry.Description, &entry.Status,
 		&entry.ReferenceID, &entry.ReferenceType, &entry.CreatedByUserID, &entry.PostedAt, &entry.CreatedAt, &entry.UpdatedAt,
+		&entry.AttachmentCount,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -110,17 +150,37 @@ func (r *postgresJournalRepository) GetByID(ctx context.Context, id uuid.UUID) (
 	return &entry, nil
 }
 
-func (r *postgresJournalRepository) List(ctx context.Context, tenantID uuid.UUID, fiscalYearID uuid.UUID) ([]*domain.JournalEntry, error) {
+func (r *postgresJournalRepository) GetByReference(ctx context.Context, tenantID uuid.UUID, referenceType string, referenceID uuid.UUID) (*domain.JournalEntry, error) {
+	query := `
+		SELECT journal_entry_id FROM journal_postings
+		WHERE tenant_id = $1 AND reference_type = $2 AND reference_id = $3
+	`
+	var entryID uuid.UUID
+	if err := r.pool.QueryRow(ctx, query, tenantID, referenceType, referenceID).Scan(&entryID); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.GetByID(ctx, tenantID, entryID)
+}
+
+// List returns the latest entries of the period's fiscal years. The transaction date
+// range keeps the scan to the partitions of those years.
+func (r *postgresJournalRepository) List(ctx context.Context, tenantID uuid.UUID, period domain.FiscalPeriod) ([]*domain.JournalEntry, error) {
 	// Limit to reasonable default (e.g., 100 recent) or require pagination filters
 	query := `
 		SELECT id, tenant_id, fiscal_year_id, transaction_date, description, status,
-		       reference_id, reference_type, created_by_user_id, posted_at, created_at, updated_at
+		       reference_id, reference_type, created_by_user_id, posted_at, created_at, updated_at,
+		       (SELECT COUNT(*) FROM attachments a
+		        WHERE a.entity_type = 'journal_entry' AND a.entity_id = journal_entries.id AND a.deleted_at IS NULL)
 		FROM journal_entries
-		WHERE tenant_id = $1 AND fiscal_year_id = $2
+		WHERE tenant_id = $1 AND fiscal_year_id = ANY($2)
+		  AND transaction_date >= $3 AND transaction_date <= $4
 		ORDER BY transaction_date DESC, created_at DESC
 		LIMIT 100
 	`
-	rows, err := r.pool.Query(ctx, query, tenantID, fiscalYearID)
+	rows, err := r.pool.Query(ctx, query, tenantID, period.FiscalYearIDs, period.Start, period.End)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +192,7 @@ func (r *postgresJournalRepository) List(ctx context.Context, tenantID uuid.UUID
 		if err := rows.Scan(
 			&entry.ID, &entry.TenantID, &entry.FiscalYearID, &entry.TransactionDate, &entry.Description, &entry.Status,
 			&entry.ReferenceID, &entry.ReferenceType, &entry.CreatedByUserID, &entry.PostedAt, &entry.CreatedAt, &entry.UpdatedAt,
+			&entry.AttachmentCount,
 		); err != nil {
 			return nil, err
 		}
@@ -140,30 +201,61 @@ func (r *postgresJournalRepository) List(ctx context.Context, tenantID uuid.UUID
 	return entries, nil
 }
 
-func (r *postgresJournalRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.JournalStatus) error {
-	// Updates without partition key scan all partitions.
+func (r *postgresJournalRepository) UpdateStatus(ctx context.Context, tenantID, id uuid.UUID, transactionDate time.Time, status domain.JournalStatus) error {
+	// The transaction date is the partition key, so only the entry's partition is touched
 	query := `
 		UPDATE journal_entries
-		SET status = $2, updated_at = $3, posted_at = CASE WHEN $2 = 'POSTED' THEN $3 ELSE posted_at END
-		WHERE id = $1
+		SET status = $4, updated_at = $5, posted_at = CASE WHEN $4 = 'POSTED' THEN $5 ELSE posted_at END
+		WHERE tenant_id = $1 AND id = $2 AND transaction_date = $3
 	`
-	_, err := r.pool.Exec(ctx, query, id, status, time.Now())
-	return err
+	tag, err := r.pool.Exec(ctx, query, tenantID, id, transactionDate, status, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrJournalEntryNotFound
+	}
+	return nil
 }
 
 func (r *postgresJournalRepository) GetLedgerEntries(ctx context.Context, tenantID uuid.UUID, accountID uuid.UUID, startStr, endStr string) ([]*domain.LedgerEntry, error) {
-	// Flattened View: Journal Lines joined with Header
-	// Filter by Date Range (Crucial for Partition Pruning)
+	var entries []*domain.LedgerEntry
+	err := r.StreamLedgerEntries(ctx, tenantID, accountID, startStr, endStr, func(le *domain.LedgerEntry) error {
+		entries = append(entries, le)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
 
+// StreamLedgerEntries reads ledger lines one at a time, passing each to fn as it is scanned
+func (r *postgresJournalRepository) StreamLedgerEntries(ctx context.Context, tenantID uuid.UUID, accountID uuid.UUID, startStr, endStr string, fn func(*domain.LedgerEntry) error) error {
 	startDate, err := time.Parse("2006-01-02", startStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start date: %w", err)
+		return fmt.Errorf("invalid start date: %w", err)
 	}
 	endDate, err := time.Parse("2006-01-02", endStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid end date: %w", err)
+		return fmt.Errorf("invalid end date: %w", err)
 	}
 
+	return r.streamLedger(ctx, accountID, nil, startDate, endDate, fn)
+}
+
+// StreamFiscalLedgerEntries reads the ledger lines of the period's fiscal years
+func (r *postgresJournalRepository) StreamFiscalLedgerEntries(ctx context.Context, tenantID uuid.UUID, accountID uuid.UUID, period domain.FiscalPeriod, fn func(*domain.LedgerEntry) error) error {
+	return r.streamLedger(ctx, accountID, period.FiscalYearIDs, period.Start, period.End, fn)
+}
+
+// streamLedger reads posted lines of an account dated startDate..endDate, limited to
+// the given fiscal years unless fiscalYearIDs is nil
+func (r *postgresJournalRepository) streamLedger(ctx context.Context, accountID uuid.UUID, fiscalYearIDs []uuid.UUID, startDate, endDate time.Time, fn func(*domain.LedgerEntry) error) error {
+	// Flattened View: Journal Lines joined with Header
+	// Filter by Date Range (Crucial for Partition Pruning). The range is repeated for
+	// the header: the planner carries equalities across a join but not ranges, and
+	// each table prunes only on its own conditions.
 	query := `
 		SELECT
 			jl.id, jl.journal_entry_id, jl.account_id, jl.transaction_date,
@@ -173,17 +265,18 @@ func (r *postgresJournalRepository) GetLedgerEntries(ctx context.Context, tenant
 		JOIN journal_entries je ON jl.journal_entry_id = je.id AND jl.transaction_date = je.transaction_date
 		WHERE jl.account_id = $1
 		  AND jl.transaction_date >= $2 AND jl.transaction_date <= $3
+		  AND je.transaction_date >= $2 AND je.transaction_date <= $3
+		  AND ($4::uuid[] IS NULL OR je.fiscal_year_id = ANY($4))
 		  AND je.status = 'POSTED'
 		ORDER BY jl.transaction_date ASC, je.created_at ASC
 	`
 
-	rows, err := r.pool.Query(ctx, query, accountID, startDate, endDate)
+	rows, err := r.pool.Query(ctx, query, accountID, startDate, endDate, fiscalYearIDs)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var entries []*domain.LedgerEntry
 	var runningBalance float64 = 0
 
 	// Note: Running balance calculation here assumes we start from an opening balance?
@@ -197,7 +290,7 @@ func (r *postgresJournalRepository) GetLedgerEntries(ctx context.Context, tenant
 			&le.Description, &le.LineDescription,
 			&le.Debit, &le.Credit,
 		); err != nil {
-			return nil, err
+			return err
 		}
 
 		// TODO: Adjust sign based on Account Type (Asset/Expense: Debit+, Liability/Revenue: Credit+)
@@ -205,8 +298,88 @@ func (r *postgresJournalRepository) GetLedgerEntries(ctx context.Context, tenant
 		runningBalance += le.Debit - le.Credit
 		le.StepBalance = runningBalance
 
-		entries = append(entries, &le)
+		if err := fn(&le); err != nil {
+			return err
+		}
 	}
 
-	return entries, nil
+	return rows.Err()
+}
+
+// AccountTotals sums posted lines per account over a date range
+func (r *postgresJournalRepository) AccountTotals(ctx context.Context, tenantID uuid.UUID, from *time.Time, to time.Time) (map[uuid.UUID]domain.AccountTotal, error) {
+	return r.accountTotals(ctx, tenantID, nil, from, to)
+}
+
+// ReferenceTotals sums posted lines of one reference type per account over a date range
+func (r *postgresJournalRepository) ReferenceTotals(ctx context.Context, tenantID uuid.UUID, referenceType string, from *time.Time, to time.Time) (map[uuid.UUID]domain.AccountTotal, error) {
+	return r.accountTotals(ctx, tenantID, &referenceType, from, to)
+}
+
+func (r *postgresJournalRepository) accountTotals(ctx context.Context, tenantID uuid.UUID, referenceType *string, from *time.Time, to time.Time) (map[uuid.UUID]domain.AccountTotal, error) {
+	query := `
+		SELECT jl.account_id, COALESCE(SUM(jl.debit), 0), COALESCE(SUM(jl.credit), 0)
+		FROM journal_lines jl
+		JOIN journal_entries je ON jl.journal_entry_id = je.id AND jl.transaction_date = je.transaction_date
+		WHERE je.tenant_id = $1
+		  AND ($2::date IS NULL OR jl.transaction_date >= $2)
+		  AND jl.transaction_date <= $3
+		  AND ($2::date IS NULL OR je.transaction_date >= $2)
+		  AND je.transaction_date <= $3
+		  AND je.status = 'POSTED'
+		  AND ($4::text IS NULL OR je.reference_type = $4)
+		GROUP BY jl.account_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, tenantID, from, to, referenceType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[uuid.UUID]domain.AccountTotal)
+	for rows.Next() {
+		var t domain.AccountTotal
+		if err := rows.Scan(&t.AccountID, &t.Debit, &t.Credit); err != nil {
+			return nil, err
+		}
+		totals[t.AccountID] = t
+	}
+
+	return totals, rows.Err()
+}
+
+// DailyTypeTotals sums posted revenue and expense lines per day and account type
+func (r *postgresJournalRepository) DailyTypeTotals(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]domain.DailyTypeTotal, error) {
+	query := `
+		SELECT jl.transaction_date, a.type, COALESCE(SUM(jl.debit), 0), COALESCE(SUM(jl.credit), 0)
+		FROM journal_lines jl
+		JOIN journal_entries je ON jl.journal_entry_id = je.id AND jl.transaction_date = je.transaction_date
+		JOIN accounts a ON a.id = jl.account_id
+		WHERE je.tenant_id = $1
+		  AND jl.transaction_date BETWEEN $2 AND $3
+		  AND je.transaction_date BETWEEN $2 AND $3
+		  AND je.status = 'POSTED'
+		  AND je.reference_type IS DISTINCT FROM $4
+		  AND a.type IN ('REVENUE', 'EXPENSE')
+		GROUP BY jl.transaction_date, a.type
+		ORDER BY jl.transaction_date
+	`
+
+	rows, err := r.pool.Query(ctx, query, tenantID, from, to, domain.ReferenceYearEndClose)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []domain.DailyTypeTotal
+	for rows.Next() {
+		var t domain.DailyTypeTotal
+		if err := rows.Scan(&t.Date, &t.Type, &t.Debit, &t.Credit); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+
+	return totals, rows.Err()
 }
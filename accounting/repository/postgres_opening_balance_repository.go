@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type postgresOpeningBalanceRepository struct {
+	pool db.QueryExecutor
+}
+
+func NewPostgresOpeningBalanceRepository(pool db.QueryExecutor) OpeningBalanceRepository {
+	return &postgresOpeningBalanceRepository{pool: pool}
+}
+
+func (r *postgresOpeningBalanceRepository) Replace(ctx context.Context, tenantID, fiscalYearID uuid.UUID, balances []*domain.OpeningBalance) error {
+	// A batch runs in one implicit transaction, so the year's balances are replaced as a whole
+	batch := &pgx.Batch{}
+	batch.Queue(`DELETE FROM opening_balances WHERE tenant_id = $1 AND fiscal_year_id = $2`, tenantID, fiscalYearID)
+	for _, ob := range balances {
+		batch.Queue(`
+			INSERT INTO opening_balances (tenant_id, fiscal_year_id, account_id, debit, credit, source_fiscal_year_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			tenantID, fiscalYearID, ob.AccountID, ob.Debit, ob.Credit, ob.SourceFiscalYearID, ob.CreatedAt)
+	}
+
+	br := r.pool.SendBatch(ctx, batch)
+	defer br.Close()
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := br.Exec(); err != nil {
+			return fmt.Errorf("failed to save opening balances: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *postgresOpeningBalanceRepository) List(ctx context.Context, tenantID, fiscalYearID uuid.UUID) ([]*domain.OpeningBalance, error) {
+	query := `
+		SELECT ob.tenant_id, ob.fiscal_year_id, ob.account_id, ob.debit, ob.credit, ob.source_fiscal_year_id, ob.created_at
+		FROM opening_balances ob
+		JOIN accounts a ON a.id = ob.account_id
+		WHERE ob.tenant_id = $1 AND ob.fiscal_year_id = $2
+		ORDER BY a.code
+	`
+	rows, err := r.pool.Query(ctx, query, tenantID, fiscalYearID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []*domain.OpeningBalance{}
+	for rows.Next() {
+		var ob domain.OpeningBalance
+		if err := rows.Scan(&ob.TenantID, &ob.FiscalYearID, &ob.AccountID, &ob.Debit, &ob.Credit, &ob.SourceFiscalYearID, &ob.CreatedAt); err != nil {
+			return nil, err
+		}
+		balances = append(balances, &ob)
+	}
+	return balances, rows.Err()
+}
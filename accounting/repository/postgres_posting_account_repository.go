@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type postgresPostingAccountRepository struct {
+	pool db.QueryExecutor
+}
+
+func NewPostgresPostingAccountRepository(pool db.QueryExecutor) PostingAccountRepository {
+	return &postgresPostingAccountRepository{pool: pool}
+}
+
+func (r *postgresPostingAccountRepository) Get(ctx context.Context, tenantID uuid.UUID) (domain.PostingAccounts, error) {
+	query := `SELECT role, account_id FROM posting_accounts WHERE tenant_id = $1`
+	rows, err := r.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := domain.PostingAccounts{}
+	for rows.Next() {
+		var role domain.PostingRole
+		var accountID uuid.UUID
+		if err := rows.Scan(&role, &accountID); err != nil {
+			return nil, err
+		}
+		accounts[role] = accountID
+	}
+	return accounts, rows.Err()
+}
+
+func (r *postgresPostingAccountRepository) Replace(ctx context.Context, tenantID uuid.UUID, accounts domain.PostingAccounts) error {
+	// A batch runs in one implicit transaction, so the mapping is replaced as a whole
+	batch := &pgx.Batch{}
+	batch.Queue(`DELETE FROM posting_accounts WHERE tenant_id = $1`, tenantID)
+	for role, accountID := range accounts {
+		batch.Queue(`INSERT INTO posting_accounts (tenant_id, role, account_id) VALUES ($1, $2, $3)`,
+			tenantID, role, accountID)
+	}
+
+	br := r.pool.SendBatch(ctx, batch)
+	defer br.Close()
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := br.Exec(); err != nil {
+			return fmt.Errorf("failed to save posting accounts: %w", err)
+		}
+	}
+	return nil
+}
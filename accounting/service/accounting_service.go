@@ -4,30 +4,46 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/aceextension/accounting/domain"
 	"github.com/aceextension/accounting/dto"
 	"github.com/aceextension/accounting/repository"
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	fiscalDomain "github.com/aceextension/fiscal/domain"
 	fiscalService "github.com/aceextension/fiscal/service"
+	fiscalUtils "github.com/aceextension/fiscal/utils"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type accountingService struct {
 	accountRepo   repository.AccountRepository
 	journalRepo   repository.JournalRepository
+	postingRepo   repository.PostingAccountRepository
 	fiscalService fiscalService.FiscalYearService
+	dateDisplay   DateDisplayFunc
+
+	sourcesMu sync.RWMutex
+	sources   map[string]PostingSource
 }
 
 func NewAccountingService(
 	accountRepo repository.AccountRepository,
 	journalRepo repository.JournalRepository,
+	postingRepo repository.PostingAccountRepository,
 	fiscalService fiscalService.FiscalYearService,
+	dateDisplay DateDisplayFunc,
 ) AccountingService {
 	return &accountingService{
 		accountRepo:   accountRepo,
 		journalRepo:   journalRepo,
+		postingRepo:   postingRepo,
 		fiscalService: fiscalService,
+		dateDisplay:   dateDisplay,
+		sources:       make(map[string]PostingSource),
 	}
 }
 
@@ -58,21 +74,21 @@ func (s *accountingService) CreateAccount(ctx context.Context, tenantID uuid.UUI
 	return account, nil
 }
 
-func (s *accountingService) GetAccount(ctx context.Context, id uuid.UUID) (*domain.Account, error) {
-	return s.accountRepo.GetByID(ctx, id)
+func (s *accountingService) GetAccount(ctx context.Context, tenantID, id uuid.UUID) (*domain.Account, error) {
+	return s.accountRepo.GetByID(ctx, tenantID, id)
 }
 
 func (s *accountingService) ListAccounts(ctx context.Context, tenantID uuid.UUID) ([]*domain.Account, error) {
 	return s.accountRepo.List(ctx, tenantID)
 }
 
-func (s *accountingService) UpdateAccount(ctx context.Context, id uuid.UUID, req dto.UpdateAccountRequest) error {
-	account, err := s.accountRepo.GetByID(ctx, id)
+func (s *accountingService) UpdateAccount(ctx context.Context, tenantID, id uuid.UUID, req dto.UpdateAccountRequest) error {
+	account, err := s.accountRepo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return fmt.Errorf("failed to get account: %w", err)
 	}
 	if account == nil {
-		return errors.New("account not found")
+		return fmt.Errorf("%w: %s", domain.ErrAccountNotFound, id)
 	}
 
 	if req.Code != "" {
@@ -100,11 +116,77 @@ func (s *accountingService) UpdateAccount(ctx context.Context, id uuid.UUID, req
 	return s.accountRepo.Update(ctx, account)
 }
 
+func (s *accountingService) MergeAccounts(ctx context.Context, tenantID, userID, sourceID, targetID uuid.UUID) (*domain.AccountMerge, error) {
+	source, err := s.tenantAccount(ctx, tenantID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.tenantAccount(ctx, tenantID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	merge, err := domain.NewAccountMerge(source, target, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.journalRepo.AccountTotals(ctx, tenantID, nil, merge.MergedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total balances: %w", err)
+	}
+	merge.SourceBalance = before[source.ID].NaturalBalance(source.Type)
+	merge.TargetBalanceBefore = before[target.ID].NaturalBalance(target.Type)
+
+	if err := s.accountRepo.Merge(ctx, merge); err != nil {
+		return nil, err
+	}
+
+	// Read back rather than add up, so lines booked while merging are counted
+	after, err := s.journalRepo.AccountTotals(ctx, tenantID, nil, merge.MergedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total balances: %w", err)
+	}
+	merge.TargetBalanceAfter = after[target.ID].NaturalBalance(target.Type)
+
+	auditCtx := &auditDomain.AuditContext{TenantID: &tenantID, UserID: &userID}
+	entityIDStr := source.ID.String()
+	audit.Service.Log(ctx, "MERGE_ACCOUNT", "Account", &entityIDStr, map[string]interface{}{
+		"source_code":           source.Code,
+		"source_name":           source.Name,
+		"target_id":             target.ID,
+		"target_code":           target.Code,
+		"target_name":           target.Name,
+		"type":                  source.Type,
+		"lines_moved":           merge.LinesMoved,
+		"posting_roles_moved":   merge.PostingRolesMoved,
+		"links_moved":           merge.LinksMoved,
+		"children_moved":        merge.ChildrenMoved,
+		"source_balance":        merge.SourceBalance,
+		"target_balance_before": merge.TargetBalanceBefore,
+		"target_balance_after":  merge.TargetBalanceAfter,
+	}, auditCtx)
+
+	return merge, nil
+}
+
+// tenantAccount returns the tenant's account, or ErrAccountNotFound
+func (s *accountingService) tenantAccount(ctx context.Context, tenantID, id uuid.UUID) (*domain.Account, error) {
+	acc, err := s.accountRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if acc == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrAccountNotFound, id)
+	}
+	return acc, nil
+}
+
 // Journal Entry Management
 
 func (s *accountingService) CreateJournalEntry(ctx context.Context, tenantID, userID uuid.UUID, req dto.CreateJournalEntryRequest) (*domain.JournalEntry, error) {
 	// 1. Validate Fiscal Year
-	fy, err := s.fiscalService.GetByID(ctx, req.FiscalYearID)
+	fy, err := s.fiscalService.GetByID(ctx, tenantID, req.FiscalYearID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get fiscal year: %w", err)
 	}
@@ -125,13 +207,16 @@ func (s *accountingService) CreateJournalEntry(ctx context.Context, tenantID, us
 	// 3. Add Lines and Validate Accounts
 	for _, lineReq := range req.Lines {
 		// Verify account exists
-		acc, err := s.accountRepo.GetByID(ctx, lineReq.AccountID)
+		acc, err := s.accountRepo.GetByID(ctx, tenantID, lineReq.AccountID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get account %s: %w", lineReq.AccountID, err)
 		}
 		if acc == nil {
 			return nil, fmt.Errorf("account %s not found", lineReq.AccountID)
 		}
+		if acc.MergedIntoID != nil {
+			return nil, fmt.Errorf("%w: book %s to %s instead", domain.ErrAccountMerged, acc.Code, acc.MergedIntoID)
+		}
 
 		entry.AddLine(lineReq.AccountID, lineReq.Debit, lineReq.Credit, lineReq.Description)
 	}
@@ -149,21 +234,29 @@ func (s *accountingService) CreateJournalEntry(ctx context.Context, tenantID, us
 	return entry, nil
 }
 
-func (s *accountingService) GetJournalEntry(ctx context.Context, id uuid.UUID) (*domain.JournalEntry, error) {
-	return s.journalRepo.GetByID(ctx, id)
+func (s *accountingService) GetJournalEntry(ctx context.Context, tenantID, id uuid.UUID) (*domain.JournalEntry, error) {
+	entry, err := s.journalRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, domain.ErrJournalEntryNotFound
+	}
+	return entry, nil
 }
 
-func (s *accountingService) ListJournalEntries(ctx context.Context, tenantID, fiscalYearID uuid.UUID) ([]*domain.JournalEntry, error) {
-	return s.journalRepo.List(ctx, tenantID, fiscalYearID)
+func (s *accountingService) ListJournalEntries(ctx context.Context, tenantID uuid.UUID, fiscalYearIDs []uuid.UUID) ([]*domain.JournalEntry, error) {
+	period, err := s.fiscalPeriod(ctx, tenantID, fiscalYearIDs)
+	if err != nil {
+		return nil, err
+	}
+	return s.journalRepo.List(ctx, tenantID, period)
 }
 
-func (s *accountingService) PostJournalEntry(ctx context.Context, id, userID uuid.UUID) error {
-	entry, err := s.journalRepo.GetByID(ctx, id)
+func (s *accountingService) PostJournalEntry(ctx context.Context, tenantID, id, userID uuid.UUID) error {
+	entry, err := s.GetJournalEntry(ctx, tenantID, id)
 	if err != nil {
-		return fmt.Errorf("failed to get journal entry: %w", err)
-	}
-	if entry == nil {
-		return errors.New("journal entry not found")
+		return err
 	}
 
 	if entry.Status == domain.JournalStatusPosted {
@@ -171,7 +264,7 @@ func (s *accountingService) PostJournalEntry(ctx context.Context, id, userID uui
 	}
 
 	// Re-verify Fiscal Year is open (status might have changed since creation)
-	fy, err := s.fiscalService.GetByID(ctx, entry.FiscalYearID)
+	fy, err := s.fiscalService.GetByID(ctx, tenantID, entry.FiscalYearID)
 	if err != nil {
 		return fmt.Errorf("failed to get fiscal year: %w", err)
 	}
@@ -179,20 +272,422 @@ func (s *accountingService) PostJournalEntry(ctx context.Context, id, userID uui
 		return errors.New("cannot post to a closed fiscal year")
 	}
 
-	return s.journalRepo.UpdateStatus(ctx, id, domain.JournalStatusPosted)
+	return s.journalRepo.UpdateStatus(ctx, tenantID, id, entry.TransactionDate, domain.JournalStatusPosted)
+}
+
+// Automatic Posting
+
+func (s *accountingService) RegisterPostingSource(referenceType string, source PostingSource) {
+	s.sourcesMu.Lock()
+	defer s.sourcesMu.Unlock()
+	s.sources[referenceType] = source
+}
+
+func (s *accountingService) PostFromReference(ctx context.Context, tenantID uuid.UUID, referenceType string, referenceID uuid.UUID) (*domain.JournalEntry, error) {
+	s.sourcesMu.RLock()
+	source, ok := s.sources[referenceType]
+	s.sourcesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %s", domain.ErrUnknownReferenceType, referenceType)
+	}
+
+	existing, err := s.journalRepo.GetByReference(ctx, tenantID, referenceType, referenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check posted entries: %w", err)
+	}
+	if existing != nil {
+		return nil, domain.ErrReferenceAlreadyPosted
+	}
+
+	doc, err := source(ctx, tenantID, referenceID)
+	if err != nil {
+		return nil, err
+	}
+	fy, err := s.postingFiscalYear(ctx, tenantID, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []domain.JournalLine
+	if doc.Reverses != "" {
+		// Reversals book the original entry's accounts, even if the mapping changed since
+		original, err := s.journalRepo.GetByReference(ctx, tenantID, doc.Reverses, referenceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the entry to reverse: %w", err)
+		}
+		if original == nil {
+			return nil, domain.ErrReferenceNotPosted
+		}
+		lines = domain.ReverseLines(original.Lines)
+	} else {
+		accounts, err := s.postingRepo.Get(ctx, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get posting accounts: %w", err)
+		}
+		if lines, err = doc.Lines(accounts); err != nil {
+			return nil, err
+		}
+	}
+
+	entry := domain.NewJournalEntry(tenantID, fy.ID, doc.Date, doc.Description)
+	entry.ReferenceType = &referenceType
+	entry.ReferenceID = &referenceID
+	entry.CreatedByUserID = doc.CreatedBy
+	for _, line := range lines {
+		entry.AddLine(line.AccountID, line.Debit, line.Credit, line.Description)
+	}
+	if err := entry.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrReferenceNotPostable, err.Error())
+	}
+
+	// Documents are final when posted, so their entries are too
+	postedAt := time.Now()
+	entry.Status = domain.JournalStatusPosted
+	entry.PostedAt = &postedAt
+
+	if err := s.journalRepo.CreatePosting(ctx, entry); err != nil {
+		if errors.Is(err, domain.ErrReferenceAlreadyPosted) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create journal entry: %w", err)
+	}
+	return entry, nil
+}
+
+// postingFiscalYear finds the open fiscal year a document is posted to
+func (s *accountingService) postingFiscalYear(ctx context.Context, tenantID uuid.UUID, doc *domain.PostingDocument) (*fiscalDomain.FiscalYear, error) {
+	var fy *fiscalDomain.FiscalYear
+	if doc.FiscalYearID != nil {
+		found, err := s.fiscalService.GetByID(ctx, tenantID, *doc.FiscalYearID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get fiscal year: %w", err)
+		}
+		fy = found
+	} else {
+		years, err := s.fiscalService.GetByTenantID(ctx, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fiscal years: %w", err)
+		}
+		for _, year := range years {
+			if !doc.Date.Before(year.StartDate) && !doc.Date.After(year.EndDate) {
+				fy = year
+				break
+			}
+		}
+	}
+
+	if fy == nil {
+		return nil, fmt.Errorf("%w: no fiscal year covers %s", domain.ErrReferenceNotPostable, doc.Date.Format("2006-01-02"))
+	}
+	if fy.IsClosed {
+		return nil, fmt.Errorf("%w: fiscal year %s is closed", domain.ErrReferenceNotPostable, fy.Name)
+	}
+	if doc.Date.Before(fy.StartDate) || doc.Date.After(fy.EndDate) {
+		return nil, fmt.Errorf("%w: %s is outside fiscal year %s", domain.ErrReferenceNotPostable, doc.Date.Format("2006-01-02"), fy.Name)
+	}
+	return fy, nil
+}
+
+func (s *accountingService) GetPostingAccounts(ctx context.Context, tenantID uuid.UUID) (domain.PostingAccounts, error) {
+	return s.postingRepo.Get(ctx, tenantID)
+}
+
+func (s *accountingService) SetPostingAccounts(ctx context.Context, tenantID uuid.UUID, req dto.PostingAccountsRequest) (domain.PostingAccounts, error) {
+	accounts := domain.PostingAccounts{}
+	for role, accountID := range req.Accounts {
+		if !role.Valid() {
+			return nil, fmt.Errorf("%w: unknown posting role %q", domain.ErrPostingAccountInvalid, role)
+		}
+		acc, err := s.accountRepo.GetByID(ctx, tenantID, accountID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account: %w", err)
+		}
+		if acc == nil {
+			return nil, fmt.Errorf("%w: account %s not found", domain.ErrPostingAccountInvalid, accountID)
+		}
+		if !acc.IsActive {
+			return nil, fmt.Errorf("%w: %s %s is inactive", domain.ErrPostingAccountInvalid, acc.Code, acc.Name)
+		}
+		if !role.Accepts(acc.Type) {
+			return nil, fmt.Errorf("%w: %s %s cannot be used for %s", domain.ErrPostingAccountInvalid, acc.Code, acc.Name, role)
+		}
+		accounts[role] = accountID
+	}
+
+	if err := s.postingRepo.Replace(ctx, tenantID, accounts); err != nil {
+		return nil, err
+	}
+	return accounts, nil
 }
 
 // Reports
 
 func (s *accountingService) GetLedger(ctx context.Context, tenantID, accountID uuid.UUID, startStr, endStr string) ([]*domain.LedgerEntry, error) {
-	// Verify account exists and belongs to tenant
-	acc, err := s.accountRepo.GetByID(ctx, accountID)
+	if err := s.checkLedgerAccount(ctx, tenantID, accountID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.journalRepo.GetLedgerEntries(ctx, tenantID, accountID, startStr, endStr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get account: %w", err)
+		return nil, err
 	}
-	if acc == nil || acc.TenantID != tenantID {
-		return nil, errors.New("account not found or access denied")
+	display := s.dateDisplay(ctx, tenantID)
+	for _, entry := range entries {
+		entry.SetDates(display)
 	}
+	return entries, nil
+}
 
-	return s.journalRepo.GetLedgerEntries(ctx, tenantID, accountID, startStr, endStr)
+func (s *accountingService) StreamLedger(ctx context.Context, tenantID, accountID uuid.UUID, startStr, endStr string, fn func(*domain.LedgerEntry) error) error {
+	if err := s.checkLedgerAccount(ctx, tenantID, accountID); err != nil {
+		return err
+	}
+
+	return s.journalRepo.StreamLedgerEntries(ctx, tenantID, accountID, startStr, endStr, datedLedger(s.dateDisplay(ctx, tenantID), fn))
+}
+
+func (s *accountingService) StreamFiscalLedger(ctx context.Context, tenantID, accountID uuid.UUID, fiscalYearIDs []uuid.UUID, fn func(*domain.LedgerEntry) error) error {
+	if err := s.checkLedgerAccount(ctx, tenantID, accountID); err != nil {
+		return err
+	}
+
+	period, err := s.fiscalPeriod(ctx, tenantID, fiscalYearIDs)
+	if err != nil {
+		return err
+	}
+	return s.journalRepo.StreamFiscalLedgerEntries(ctx, tenantID, accountID, period, datedLedger(s.dateDisplay(ctx, tenantID), fn))
+}
+
+// datedLedger fills in each streamed entry's BS date before passing it on
+func datedLedger(display fiscalUtils.DateDisplay, fn func(*domain.LedgerEntry) error) func(*domain.LedgerEntry) error {
+	return func(entry *domain.LedgerEntry) error {
+		entry.SetDates(display)
+		return fn(entry)
+	}
+}
+
+func (s *accountingService) GetTrialBalance(ctx context.Context, tenantID uuid.UUID, fiscalYearIDs []uuid.UUID, startStr, endStr string) (*domain.TrialBalance, error) {
+	period, err := s.reportPeriod(ctx, tenantID, fiscalYearIDs, startStr, endStr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.accountRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	opening := map[uuid.UUID]domain.AccountTotal{}
+	if period.From != nil {
+		opening, err = s.journalRepo.AccountTotals(ctx, tenantID, nil, period.From.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to total opening balances: %w", err)
+		}
+	}
+	movements, err := s.journalRepo.AccountTotals(ctx, tenantID, period.From, period.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total movements: %w", err)
+	}
+
+	period.SetDates(s.dateDisplay(ctx, tenantID))
+	return domain.NewTrialBalance(period, accounts, opening, movements), nil
+}
+
+func (s *accountingService) GetProfitAndLoss(ctx context.Context, tenantID uuid.UUID, fiscalYearIDs []uuid.UUID, startStr, endStr string) (*domain.ProfitAndLoss, error) {
+	period, err := s.reportPeriod(ctx, tenantID, fiscalYearIDs, startStr, endStr, true)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.accountRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	movements, err := s.journalRepo.AccountTotals(ctx, tenantID, period.From, period.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total movements: %w", err)
+	}
+	// Year-end closing entries zero revenue and expenses; the statement shows the year before them
+	closing, err := s.journalRepo.ReferenceTotals(ctx, tenantID, domain.ReferenceYearEndClose, period.From, period.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total closing entries: %w", err)
+	}
+	for id, t := range closing {
+		m := movements[id]
+		m.Debit -= t.Debit
+		m.Credit -= t.Credit
+		movements[id] = m
+	}
+
+	period.SetDates(s.dateDisplay(ctx, tenantID))
+	return domain.NewProfitAndLoss(period, accounts, movements), nil
+}
+
+func (s *accountingService) GetBalanceSheet(ctx context.Context, tenantID uuid.UUID, fiscalYearIDs []uuid.UUID, asOfStr string) (*domain.BalanceSheet, error) {
+	period, err := s.reportPeriod(ctx, tenantID, fiscalYearIDs, "", asOfStr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.accountRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	// Balances are cumulative, whatever year the entries were posted in
+	totals, err := s.journalRepo.AccountTotals(ctx, tenantID, nil, period.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total balances: %w", err)
+	}
+
+	bs := domain.NewBalanceSheet(period.To, accounts, totals)
+	bs.SetDates(s.dateDisplay(ctx, tenantID))
+	return bs, nil
+}
+
+func (s *accountingService) GetComparativeReport(ctx context.Context, tenantID uuid.UUID, report domain.ComparativeReportType, fiscalYearID, compareFiscalYearID *uuid.UUID) (*domain.ComparativeReport, error) {
+	if !report.Valid() {
+		return nil, fmt.Errorf("%w: unknown report %q", domain.ErrInvalidComparativeReport, report)
+	}
+
+	years, err := s.fiscalService.GetByTenantID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fiscal years: %w", err)
+	}
+	current := findFiscalYear(years, fiscalYearID)
+	if current == nil && fiscalYearID != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrFiscalYearNotFound, *fiscalYearID)
+	}
+	if current == nil {
+		return nil, fmt.Errorf("%w: no current fiscal year", domain.ErrFiscalYearNotFound)
+	}
+	var previous *fiscalDomain.FiscalYear
+	if compareFiscalYearID != nil {
+		if previous = findFiscalYear(years, compareFiscalYearID); previous == nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrFiscalYearNotFound, *compareFiscalYearID)
+		}
+	} else {
+		for _, year := range years {
+			if year.EndDate.Before(current.StartDate) && (previous == nil || year.EndDate.After(previous.EndDate)) {
+				previous = year
+			}
+		}
+	}
+	if previous == nil {
+		return nil, fmt.Errorf("%w: no fiscal year to compare %s with", domain.ErrFiscalYearNotFound, current.Name)
+	}
+	if previous.ID == current.ID {
+		return nil, fmt.Errorf("%w: a fiscal year cannot be compared with itself", domain.ErrInvalidComparativeReport)
+	}
+
+	// A closed or finished year is reported whole; the running year up to today
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	asOf := current.EndDate
+	if today.Before(asOf) {
+		asOf = today
+	}
+
+	currentSide, err := s.comparativeYear(ctx, tenantID, current, asOf)
+	if err != nil {
+		return nil, err
+	}
+	previousSide, err := s.comparativeYear(ctx, tenantID, previous, previous.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	comparative := domain.NewComparativeReport(report, currentSide, previousSide, asOf)
+	comparative.SetDates(s.dateDisplay(ctx, tenantID))
+	return comparative, nil
+}
+
+// comparativeYear places the fiscal year's days in their Nepali months and totals its
+// revenue and expenses up to asOf
+func (s *accountingService) comparativeYear(ctx context.Context, tenantID uuid.UUID, fy *fiscalDomain.FiscalYear, asOf time.Time) (domain.ComparativeYear, error) {
+	year := domain.ComparativeYear{FiscalYearID: fy.ID, Name: fy.Name}
+	for day := fy.StartDate; !day.After(fy.EndDate); day = day.AddDate(0, 0, 1) {
+		bs := fiscalUtils.ADToBS(day)
+		// Shrawan, the 4th month of the Nepali calendar, starts the fiscal year
+		year.Days = append(year.Days, domain.FiscalDay{Date: day, Month: (bs.Month+8)%12 + 1, Day: bs.Day})
+	}
+
+	if !asOf.Before(fy.StartDate) {
+		totals, err := s.journalRepo.DailyTypeTotals(ctx, tenantID, fy.StartDate, asOf)
+		if err != nil {
+			return year, fmt.Errorf("failed to total %s: %w", fy.Name, err)
+		}
+		year.Totals = totals
+	}
+	return year, nil
+}
+
+// findFiscalYear returns the year with the ID, or the current year for a nil ID
+func findFiscalYear(years []*fiscalDomain.FiscalYear, id *uuid.UUID) *fiscalDomain.FiscalYear {
+	for _, year := range years {
+		if (id == nil && year.IsCurrent) || (id != nil && year.ID == *id) {
+			return year
+		}
+	}
+	return nil
+}
+
+// reportPeriod resolves a report's fiscal years, or else its dates, to a date range.
+// The end date is always required; the start only when requireStart is set.
+func (s *accountingService) reportPeriod(ctx context.Context, tenantID uuid.UUID, fiscalYearIDs []uuid.UUID, startStr, endStr string, requireStart bool) (domain.ReportPeriod, error) {
+	if len(fiscalYearIDs) > 0 {
+		fp, err := s.fiscalPeriod(ctx, tenantID, fiscalYearIDs)
+		if err != nil {
+			return domain.ReportPeriod{}, err
+		}
+		return domain.ReportPeriod{From: &fp.Start, To: fp.End}, nil
+	}
+
+	if endStr == "" || (requireStart && startStr == "") {
+		return domain.ReportPeriod{}, fmt.Errorf("%w: fiscalYearId or dates are required", domain.ErrInvalidReportPeriod)
+	}
+	end, err := time.Parse("2006-01-02", endStr)
+	if err != nil {
+		return domain.ReportPeriod{}, fmt.Errorf("%w: dates must be YYYY-MM-DD", domain.ErrInvalidReportPeriod)
+	}
+	period := domain.ReportPeriod{To: end}
+	if startStr != "" {
+		start, err := time.Parse("2006-01-02", startStr)
+		if err != nil {
+			return domain.ReportPeriod{}, fmt.Errorf("%w: dates must be YYYY-MM-DD", domain.ErrInvalidReportPeriod)
+		}
+		if start.After(end) {
+			return domain.ReportPeriod{}, fmt.Errorf("%w: start date is after end date", domain.ErrInvalidReportPeriod)
+		}
+		period.From = &start
+	}
+	return period, nil
+}
+
+// fiscalPeriod looks up the tenant's fiscal years and spans their dates, so journal
+// queries by fiscal year only read the partitions of those years
+func (s *accountingService) fiscalPeriod(ctx context.Context, tenantID uuid.UUID, fiscalYearIDs []uuid.UUID) (domain.FiscalPeriod, error) {
+	if len(fiscalYearIDs) > domain.MaxFiscalYearsPerQuery {
+		return domain.FiscalPeriod{}, domain.ErrTooManyFiscalYears
+	}
+
+	spans := make([]domain.FiscalYearSpan, 0, len(fiscalYearIDs))
+	for _, id := range fiscalYearIDs {
+		fy, err := s.fiscalService.GetByID(ctx, tenantID, id)
+		if err != nil || fy == nil {
+			return domain.FiscalPeriod{}, fmt.Errorf("%w: %s", domain.ErrFiscalYearNotFound, id)
+		}
+		spans = append(spans, domain.FiscalYearSpan{ID: fy.ID, Start: fy.StartDate, End: fy.EndDate})
+	}
+	return domain.NewFiscalPeriod(spans...)
+}
+
+// checkLedgerAccount verifies the account exists and belongs to the tenant
+func (s *accountingService) checkLedgerAccount(ctx context.Context, tenantID, accountID uuid.UUID) error {
+	acc, err := s.accountRepo.GetByID(ctx, tenantID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+	if acc == nil {
+		return errors.New("account not found or access denied")
+	}
+	return nil
 }
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/core/pdf"
+	fiscalUtils "github.com/aceextension/fiscal/utils"
+)
+
+// Table layout on an A4 page, in points
+const (
+	tableLeft     = 36.0
+	tableRight    = pdf.PageWidth - 36
+	tableTop      = 50.0
+	tableBottom   = pdf.PageHeight - 40
+	tableFontSize = 8.0
+	tableRowStep  = 12.0
+)
+
+// pdfReport lays out bundle tables as paginated A4 pages. Text columns get twice
+// the width of amount columns, and cells too long for their column are cut. Dates
+// are printed in both calendars, display's first.
+type pdfReport struct {
+	doc      *pdf.Document
+	subtitle string
+	display  fiscalUtils.DateDisplay
+	page     *pdf.Page
+	pageNum  int
+	y        float64
+	title    string
+	columns  []domain.ReportColumn
+	edges    []float64 // Left edge of each column, plus the right edge of the last
+}
+
+func newPDFReport(title, subtitle string, display fiscalUtils.DateDisplay) *pdfReport {
+	return &pdfReport{doc: pdf.New(title), subtitle: subtitle, display: display}
+}
+
+// Table starts a table on a new page
+func (r *pdfReport) Table(title string, columns []domain.ReportColumn) {
+	r.title = title
+	r.columns = columns
+
+	units := 0
+	for _, col := range columns {
+		units += columnUnits(col)
+	}
+	r.edges = []float64{tableLeft}
+	x := tableLeft
+	for _, col := range columns {
+		x += (tableRight - tableLeft) * float64(columnUnits(col)) / float64(max(units, 1))
+		r.edges = append(r.edges, x)
+	}
+
+	r.newPage()
+}
+
+// Row adds a row, continuing on a new page when this one is full
+func (r *pdfReport) Row(values ...any) {
+	if r.page == nil {
+		return
+	}
+	if r.y > tableBottom {
+		r.newPage()
+	}
+	for i, col := range r.columns {
+		if i >= len(values) {
+			break
+		}
+		r.cell(i, col, formatCell(values[i], r.display), false)
+	}
+	r.y += tableRowStep
+}
+
+// Bytes serializes the report
+func (r *pdfReport) Bytes() []byte {
+	if r.page == nil {
+		r.doc.AddPage()
+	}
+	return r.doc.Bytes()
+}
+
+func (r *pdfReport) newPage() {
+	r.page = r.doc.AddPage()
+	r.pageNum++
+
+	r.page.Text(tableLeft, tableTop, 12, true, r.title)
+	r.page.Text(tableLeft, tableTop+14, 8, false, r.subtitle)
+	r.page.TextRight(tableRight, tableTop+14, 8, false, "Page "+strconv.Itoa(r.pageNum))
+
+	r.y = tableTop + 36
+	r.page.FillRect(tableLeft, r.y-9, tableRight-tableLeft, 13, 0.9)
+	for i, col := range r.columns {
+		r.cell(i, col, col.Header, true)
+	}
+	r.y += tableRowStep + 2
+}
+
+func (r *pdfReport) cell(i int, col domain.ReportColumn, text string, bold bool) {
+	const pad = 3.0
+	left, right := r.edges[i]+pad, r.edges[i+1]-pad
+	text = fitText(text, right-left)
+	if col.Amount {
+		r.page.TextRight(right, r.y, tableFontSize, bold, text)
+	} else {
+		r.page.Text(left, r.y, tableFontSize, bold, text)
+	}
+}
+
+func columnUnits(col domain.ReportColumn) int {
+	if col.Amount {
+		return 1
+	}
+	return 2
+}
+
+// fitText cuts text to the width, marking the cut with ".."
+func fitText(text string, width float64) string {
+	if pdf.TextWidth(text, tableFontSize) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.TextWidth(string(runes)+"..", tableFontSize) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + ".."
+}
+
+// formatCell renders a value the way the XLSX sheets format it, with dates in both calendars
+func formatCell(v any, display fiscalUtils.DateDisplay) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return formatAmount(v)
+	case time.Time:
+		return fiscalUtils.FormatDualDate(v, display)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return fiscalUtils.FormatDualDate(*v, display)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatAmount writes an amount with two decimals and thousands separators
+func formatAmount(v float64) string {
+	text := strconv.FormatFloat(v, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	whole, frac, _ := strings.Cut(text, ".")
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	return sign + whole + "." + frac
+}
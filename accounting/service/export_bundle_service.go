@@ -0,0 +1,449 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/accounting/repository"
+	"github.com/aceextension/core/xlsx"
+	"github.com/aceextension/files/storage"
+	fiscalService "github.com/aceextension/fiscal/service"
+	fiscalUtils "github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+)
+
+// expiredBundleBatch is how many expired bundles PurgeExpired removes per call
+const expiredBundleBatch = 100
+
+// ReportSink receives a bundle report as one or more tables. Each table becomes a
+// sheet of the report's workbook and a run of pages in its PDF.
+type ReportSink interface {
+	Table(title string, columns []domain.ReportColumn) error
+	// Row adds a row to the current table; values follow the xlsx.Sheet.Row types
+	Row(values ...any) error
+}
+
+// BundleSection writes one report of the year-end bundle. Modules that own the
+// data register their sections (AR aging, VAT registers) during Init.
+type BundleSection struct {
+	Name  string // File name stem, e.g. "ar-aging"
+	Title string
+	PDF   bool // Also rendered as PDF when requested; off for reports too long to print
+	Write func(ctx context.Context, period domain.BundlePeriod, sink ReportSink) error
+}
+
+type exportBundleService struct {
+	bundleRepo    repository.ExportBundleRepository
+	accountRepo   repository.AccountRepository
+	journalRepo   repository.JournalRepository
+	fiscalService fiscalService.FiscalYearService
+	store         storage.Storage
+	dateDisplay   DateDisplayFunc
+
+	mu       sync.RWMutex
+	sections []BundleSection
+}
+
+// NewExportBundleService creates the bundle service with the trial balance and
+// account ledgers as its first sections. Bundles are saved to store.
+func NewExportBundleService(
+	bundleRepo repository.ExportBundleRepository,
+	accountRepo repository.AccountRepository,
+	journalRepo repository.JournalRepository,
+	fiscalService fiscalService.FiscalYearService,
+	store storage.Storage,
+	dateDisplay DateDisplayFunc,
+) ExportBundleService {
+	s := &exportBundleService{
+		bundleRepo:    bundleRepo,
+		accountRepo:   accountRepo,
+		journalRepo:   journalRepo,
+		fiscalService: fiscalService,
+		store:         store,
+		dateDisplay:   dateDisplay,
+	}
+	s.RegisterSection(BundleSection{Name: "trial-balance", Title: "Trial Balance", PDF: true, Write: s.writeTrialBalance})
+	s.RegisterSection(BundleSection{Name: "ledgers", Title: "Account Ledgers", Write: s.writeLedgers})
+	return s
+}
+
+func (s *exportBundleService) RegisterSection(section BundleSection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.sections {
+		if existing.Name == section.Name {
+			s.sections[i] = section
+			return
+		}
+	}
+	s.sections = append(s.sections, section)
+}
+
+func (s *exportBundleService) Request(ctx context.Context, tenantID, userID, fiscalYearID uuid.UUID, formats []string) (*domain.ExportBundle, error) {
+	fy, err := s.fiscalService.GetByID(ctx, tenantID, fiscalYearID)
+	if err != nil || fy == nil {
+		return nil, fmt.Errorf("%w: fiscal year not found", domain.ErrInvalidBundle)
+	}
+
+	bundle, err := domain.NewExportBundle(tenantID, fy.ID, fy.Name, fy.StartDate, fy.EndDate, formats, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.bundleRepo.Create(ctx, bundle); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+func (s *exportBundleService) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.ExportBundle, error) {
+	return s.bundleRepo.GetByID(ctx, tenantID, id)
+}
+
+func (s *exportBundleService) List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.ExportBundle, error) {
+	return s.bundleRepo.List(ctx, tenantID, limit, offset)
+}
+
+func (s *exportBundleService) DownloadURL(ctx context.Context, bundle *domain.ExportBundle) (string, error) {
+	now := time.Now()
+	if err := bundle.Downloadable(now); err != nil {
+		return "", err
+	}
+	presigner, ok := s.store.(storage.Presigner)
+	if !ok {
+		return "", nil
+	}
+	// The link lasts as long as the bundle does
+	return presigner.PresignGet(ctx, *bundle.StorageKey, bundle.FileName, bundle.ExpiresAt.Sub(now))
+}
+
+func (s *exportBundleService) Open(ctx context.Context, bundle *domain.ExportBundle) (io.ReadCloser, error) {
+	if err := bundle.Downloadable(time.Now()); err != nil {
+		return nil, err
+	}
+	return s.store.Open(ctx, *bundle.StorageKey)
+}
+
+func (s *exportBundleService) ProcessQueued(ctx context.Context) error {
+	for {
+		bundle, err := s.bundleRepo.ClaimNext(ctx)
+		if err != nil {
+			return err
+		}
+		if bundle == nil {
+			return nil
+		}
+
+		if err := s.build(ctx, bundle); err != nil {
+			log.Printf("Export bundle %s failed: %v", bundle.ID, err)
+			message := err.Error()
+			bundle.Status = domain.BundleStatusFailed
+			bundle.Error = &message
+			bundle.StorageKey = nil
+		}
+		now := time.Now()
+		bundle.CompletedAt = &now
+		if err := s.bundleRepo.Finish(ctx, bundle); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *exportBundleService) PurgeExpired(ctx context.Context) error {
+	bundles, err := s.bundleRepo.ListExpired(ctx, time.Now(), expiredBundleBatch)
+	if err != nil {
+		return err
+	}
+	for _, bundle := range bundles {
+		if bundle.StorageKey != nil && s.store != nil {
+			if err := s.store.Delete(ctx, *bundle.StorageKey); err != nil {
+				return fmt.Errorf("failed to delete export bundle %s: %w", bundle.ID, err)
+			}
+		}
+		if err := s.bundleRepo.MarkExpired(ctx, bundle.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build writes every section into a zip in a temp file and saves it to the store
+func (s *exportBundleService) build(ctx context.Context, bundle *domain.ExportBundle) error {
+	if s.store == nil {
+		return errors.New("no export storage is configured")
+	}
+
+	s.mu.RLock()
+	sections := append([]BundleSection(nil), s.sections...)
+	s.mu.RUnlock()
+
+	tmp, err := os.CreateTemp("", "export-bundle-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	zw := zip.NewWriter(tmp)
+	period := bundle.Period()
+	bundle.Reports = []domain.BundleReport{}
+	display := s.dateDisplay(ctx, bundle.TenantID)
+
+	for i, section := range sections {
+		step := section.Title
+		bundle.Progress, bundle.CurrentStep = i*100/len(sections), &step
+		if err := s.bundleRepo.UpdateProgress(ctx, bundle.ID, bundle.Progress, step); err != nil {
+			return err
+		}
+		report, err := writeSection(ctx, zw, bundle, section, display)
+		if err != nil {
+			return fmt.Errorf("%s: %w", section.Title, err)
+		}
+		bundle.Reports = append(bundle.Reports, report)
+	}
+
+	manifest, err := createEntry(zw, "manifest.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(manifest)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(map[string]any{
+		"fiscalYear":    period.FiscalYearName,
+		"periodStart":   period.Start.Format("2006-01-02"),
+		"periodEnd":     period.End.Format("2006-01-02"),
+		"periodStartBs": fiscalUtils.BSDate(period.Start),
+		"periodEndBs":   fiscalUtils.BSDate(period.End),
+		"generatedAt":   time.Now().UTC(),
+		"reports":       bundle.Reports,
+	}); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("bundles/%s/%s.zip", bundle.TenantID, bundle.ID)
+	if err := s.store.Put(ctx, key, tmp); err != nil {
+		return fmt.Errorf("failed to save bundle: %w", err)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(domain.BundleLinkTTL)
+	size := info.Size()
+	bundle.Status = domain.BundleStatusCompleted
+	bundle.Progress = 100
+	bundle.CurrentStep = nil
+	bundle.StorageKey = &key
+	bundle.SizeBytes = &size
+	bundle.ExpiresAt = &expiresAt
+	return nil
+}
+
+// writeSection adds a section's workbook and, when asked for and supported, its
+// PDF. Sections without a PDF layout always get a workbook so they are never left out.
+// PDFs print dates in both calendars, display's first.
+func writeSection(ctx context.Context, zw *zip.Writer, bundle *domain.ExportBundle, section BundleSection, display fiscalUtils.DateDisplay) (domain.BundleReport, error) {
+	report := domain.BundleReport{Name: section.Name, Title: section.Title, Files: []string{}}
+	withPDF := section.PDF && bundle.HasFormat(domain.BundleFormatPDF)
+	withXLSX := bundle.HasFormat(domain.BundleFormatXLSX) || !withPDF
+
+	sink := &bundleSink{}
+	if withXLSX {
+		name := section.Name + ".xlsx"
+		w, err := createEntry(zw, name)
+		if err != nil {
+			return report, err
+		}
+		sink.workbook = xlsx.NewWorkbook(w)
+		report.Files = append(report.Files, name)
+	}
+	if withPDF {
+		subtitle := fmt.Sprintf("Fiscal year %s, %s", bundle.FiscalYearName,
+			fiscalUtils.FormatDualRange(bundle.PeriodStart, bundle.PeriodEnd, display))
+		sink.pdf = newPDFReport(section.Title, subtitle, display)
+	}
+
+	if err := section.Write(ctx, bundle.Period(), sink); err != nil {
+		return report, err
+	}
+	if sink.workbook != nil {
+		if err := sink.workbook.Close(); err != nil {
+			return report, err
+		}
+	}
+	if sink.pdf != nil {
+		name := section.Name + ".pdf"
+		w, err := createEntry(zw, name)
+		if err != nil {
+			return report, err
+		}
+		if _, err := w.Write(sink.pdf.Bytes()); err != nil {
+			return report, err
+		}
+		report.Files = append(report.Files, name)
+	}
+
+	report.Rows = sink.rows
+	return report, nil
+}
+
+// createEntry starts a compressed zip entry dated now
+func createEntry(zw *zip.Writer, name string) (io.Writer, error) {
+	return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+}
+
+// bundleSink writes a section to its workbook and PDF at once
+type bundleSink struct {
+	workbook *xlsx.Workbook
+	sheet    *xlsx.Sheet
+	pdf      *pdfReport
+	rows     int
+}
+
+func (k *bundleSink) Table(title string, columns []domain.ReportColumn) error {
+	if k.workbook != nil {
+		sheet, err := k.workbook.AddSheet(title)
+		if err != nil {
+			return err
+		}
+		headers := make([]string, len(columns))
+		for i, col := range columns {
+			headers[i] = col.Header
+		}
+		if err := sheet.Header(headers...); err != nil {
+			return err
+		}
+		k.sheet = sheet
+	}
+	if k.pdf != nil {
+		k.pdf.Table(title, columns)
+	}
+	return nil
+}
+
+func (k *bundleSink) Row(values ...any) error {
+	if k.sheet == nil && k.pdf == nil {
+		return errors.New("row written before a table was started")
+	}
+	if k.sheet != nil {
+		if err := k.sheet.Row(values...); err != nil {
+			return err
+		}
+	}
+	if k.pdf != nil {
+		k.pdf.Row(values...)
+	}
+	k.rows++
+	return nil
+}
+
+// writeTrialBalance lists each account's closing balance as of the period end
+func (s *exportBundleService) writeTrialBalance(ctx context.Context, period domain.BundlePeriod, sink ReportSink) error {
+	accounts, err := s.accountRepo.List(ctx, period.TenantID)
+	if err != nil {
+		return err
+	}
+	totals, err := s.journalRepo.AccountTotals(ctx, period.TenantID, nil, period.End)
+	if err != nil {
+		return err
+	}
+
+	if err := sink.Table("Trial Balance", []domain.ReportColumn{
+		{Header: "Code"}, {Header: "Account"}, {Header: "Type"},
+		{Header: "Debit", Amount: true}, {Header: "Credit", Amount: true},
+	}); err != nil {
+		return err
+	}
+
+	var totalDebit, totalCredit float64
+	for _, acc := range accounts {
+		balance := totals[acc.ID].Balance()
+		if balance == 0 {
+			continue
+		}
+		var debit, credit any
+		if balance > 0 {
+			debit = balance
+			totalDebit += balance
+		} else {
+			credit = -balance
+			totalCredit -= balance
+		}
+		if err := sink.Row(acc.Code, acc.Name, string(acc.Type), debit, credit); err != nil {
+			return err
+		}
+	}
+	return sink.Row(nil, "Total", nil, totalDebit, totalCredit)
+}
+
+// writeLedgers writes a sheet per account that has an opening balance or
+// movements in the period, with a running balance from the opening
+func (s *exportBundleService) writeLedgers(ctx context.Context, period domain.BundlePeriod, sink ReportSink) error {
+	accounts, err := s.accountRepo.List(ctx, period.TenantID)
+	if err != nil {
+		return err
+	}
+	opening, err := s.journalRepo.AccountTotals(ctx, period.TenantID, nil, period.Start.AddDate(0, 0, -1))
+	if err != nil {
+		return err
+	}
+	movements, err := s.journalRepo.AccountTotals(ctx, period.TenantID, &period.Start, period.End)
+	if err != nil {
+		return err
+	}
+
+	columns := []domain.ReportColumn{
+		{Header: "Date"}, {Header: "Date (BS)"}, {Header: "Description"}, {Header: "Line Note"},
+		{Header: "Debit", Amount: true}, {Header: "Credit", Amount: true}, {Header: "Balance", Amount: true},
+	}
+	start, end := period.Start.Format("2006-01-02"), period.End.Format("2006-01-02")
+
+	for _, acc := range accounts {
+		balance := opening[acc.ID].Balance()
+		movement, moved := movements[acc.ID]
+		if balance == 0 && !moved {
+			continue
+		}
+
+		if err := sink.Table(acc.Code+" "+acc.Name, columns); err != nil {
+			return err
+		}
+		if err := sink.Row(period.Start, fiscalUtils.BSDate(period.Start), "Opening balance", nil, nil, nil, balance); err != nil {
+			return err
+		}
+		err := s.journalRepo.StreamLedgerEntries(ctx, period.TenantID, acc.ID, start, end, func(entry *domain.LedgerEntry) error {
+			balance += entry.Debit - entry.Credit
+			var note any
+			if entry.LineDescription != nil {
+				note = *entry.LineDescription
+			}
+			return sink.Row(entry.TransactionDate, fiscalUtils.BSDate(entry.TransactionDate), entry.Description, note, entry.Debit, entry.Credit, balance)
+		})
+		if err != nil {
+			return err
+		}
+		if err := sink.Row(period.End, fiscalUtils.BSDate(period.End), "Closing balance", nil, movement.Debit, movement.Credit, balance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
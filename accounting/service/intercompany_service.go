@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/accounting/dto"
+	"github.com/aceextension/accounting/repository"
+	"github.com/google/uuid"
+)
+
+type interCompanyService struct {
+	repo        repository.InterCompanyRepository
+	accountRepo repository.AccountRepository
+	journalRepo repository.JournalRepository
+	mirror      PurchaseMirror
+}
+
+// NewInterCompanyService creates the inter-company service. Sales are not mirrored
+// until a PurchaseMirror is set.
+func NewInterCompanyService(
+	repo repository.InterCompanyRepository,
+	accountRepo repository.AccountRepository,
+	journalRepo repository.JournalRepository,
+) InterCompanyService {
+	return &interCompanyService{repo: repo, accountRepo: accountRepo, journalRepo: journalRepo}
+}
+
+func (s *interCompanyService) SetPurchaseMirror(mirror PurchaseMirror) {
+	s.mirror = mirror
+}
+
+// Links
+
+func (s *interCompanyService) Link(ctx context.Context, tenantID, userID, partnerTenantID uuid.UUID) (*domain.TenantLink, error) {
+	link, reverse, err := domain.NewTenantLinkPair(tenantID, partnerTenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireOwner(ctx, userID, tenantID, partnerTenantID); err != nil {
+		return nil, err
+	}
+
+	partner, err := s.repo.GetCompany(ctx, partnerTenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateLinkPair(ctx, link, reverse); err != nil {
+		return nil, err
+	}
+	link.PartnerName = partner.Name
+	return link, nil
+}
+
+func (s *interCompanyService) ListLinks(ctx context.Context, tenantID uuid.UUID) ([]*domain.TenantLink, error) {
+	return s.repo.ListLinks(ctx, tenantID)
+}
+
+func (s *interCompanyService) SetLinkAccounts(ctx context.Context, tenantID, linkID uuid.UUID, req dto.InterCompanyAccountsRequest) (*domain.TenantLink, error) {
+	link, err := s.repo.GetLink(ctx, tenantID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	if !link.IsActive {
+		return nil, domain.ErrTenantLinkNotFound
+	}
+
+	link.ReceivableAccountID = req.ReceivableAccountID
+	link.PayableAccountID = req.PayableAccountID
+	link.RevenueAccountID = req.RevenueAccountID
+	link.ExpenseAccountID = req.ExpenseAccountID
+	for accountType, accountID := range link.MappedAccounts() {
+		if accountID == nil {
+			continue
+		}
+		acc, err := s.accountRepo.GetByID(ctx, tenantID, *accountID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account: %w", err)
+		}
+		if acc == nil {
+			return nil, fmt.Errorf("%w: account %s not found", domain.ErrAccountMapping, accountID)
+		}
+		if acc.Type != accountType {
+			return nil, fmt.Errorf("%w: %s %s must be of type %s", domain.ErrAccountMapping, acc.Code, acc.Name, accountType)
+		}
+	}
+
+	link.UpdatedAt = time.Now()
+	if err := s.repo.UpdateLinkAccounts(ctx, link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (s *interCompanyService) Unlink(ctx context.Context, tenantID, userID, linkID uuid.UUID) error {
+	link, err := s.repo.GetLink(ctx, tenantID, linkID)
+	if err != nil {
+		return err
+	}
+	owner, err := s.repo.IsOwner(ctx, userID, tenantID)
+	if err != nil {
+		return err
+	}
+	if !owner {
+		return domain.ErrNotOwnerOfBoth
+	}
+	return s.repo.DeactivateLinkPair(ctx, tenantID, link.PartnerTenantID)
+}
+
+// Transactions
+
+func (s *interCompanyService) RecordSale(ctx context.Context, tenantID, userID uuid.UUID, req dto.RecordInterCompanySaleRequest) (*domain.InterCompanyTransaction, error) {
+	link, err := s.repo.GetLinkByPartner(ctx, tenantID, req.PartnerTenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	sale, err := domain.NewInterCompanySale(link, req.DocumentNumber, req.DocumentID, req.Date, req.Description, req.SubTotal, req.VATAmount, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateTransaction(ctx, sale); err != nil {
+		return nil, err
+	}
+
+	// The sale stands even if the buyer's draft cannot be created; it can be retried
+	if err := s.mirrorSale(ctx, sale); err != nil {
+		log.Printf("Inter-company sale %s mirror failed: %v", sale.ID, err)
+	}
+	return sale, nil
+}
+
+func (s *interCompanyService) GetTransaction(ctx context.Context, tenantID, id uuid.UUID) (*domain.InterCompanyTransaction, error) {
+	return s.repo.GetTransaction(ctx, tenantID, id)
+}
+
+func (s *interCompanyService) ListTransactions(ctx context.Context, tenantID uuid.UUID, partnerTenantID *uuid.UUID, limit, offset int) ([]*domain.InterCompanyTransaction, error) {
+	return s.repo.ListTransactions(ctx, tenantID, partnerTenantID, limit, offset)
+}
+
+func (s *interCompanyService) RetryMirror(ctx context.Context, tenantID, id uuid.UUID) (*domain.InterCompanyTransaction, error) {
+	sale, err := s.repo.GetTransaction(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sale.TenantID != tenantID {
+		return nil, domain.ErrInterCompanyNotFound // Only the seller retries
+	}
+	if sale.MirrorStatus == domain.MirrorStatusCreated {
+		return sale, nil
+	}
+	if err := s.mirrorSale(ctx, sale); err != nil {
+		return nil, err
+	}
+	return sale, nil
+}
+
+// mirrorSale creates the buyer's draft and saves the outcome on the sale
+func (s *interCompanyService) mirrorSale(ctx context.Context, sale *domain.InterCompanyTransaction) error {
+	var mirrorErr error
+	switch {
+	case s.mirror == nil:
+		sale.MirrorStatus = domain.MirrorStatusSkipped
+	default:
+		seller, err := s.repo.GetCompany(ctx, sale.TenantID)
+		var draftID uuid.UUID
+		if err == nil {
+			draftID, err = s.mirror.MirrorSale(ctx, sale, *seller)
+		}
+		if err != nil {
+			message := err.Error()
+			sale.MirrorStatus = domain.MirrorStatusFailed
+			sale.MirrorError = &message
+			mirrorErr = err
+		} else {
+			sale.MirrorStatus = domain.MirrorStatusCreated
+			sale.MirrorDocumentID = &draftID
+			sale.MirrorError = nil
+		}
+	}
+
+	sale.UpdatedAt = time.Now()
+	if err := s.repo.UpdateMirror(ctx, sale); err != nil {
+		return err
+	}
+	return mirrorErr
+}
+
+// Reports
+
+func (s *interCompanyService) EliminationReport(ctx context.Context, tenantID, userID, linkID uuid.UUID, from, to time.Time) (*domain.EliminationReport, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: end date is before start date", domain.ErrInvalidInterCompany)
+	}
+	link, err := s.repo.GetLink(ctx, tenantID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	if !link.IsActive {
+		return nil, domain.ErrTenantLinkNotFound
+	}
+	if err := s.requireOwner(ctx, userID, tenantID, link.PartnerTenantID); err != nil {
+		return nil, err
+	}
+	reverse, err := s.repo.GetLinkByPartner(ctx, link.PartnerTenantID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := s.repo.GetCompany(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	partner, err := s.repo.GetCompany(ctx, link.PartnerTenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.EliminationReport{Company: *company, Partner: *partner, From: from, To: to, Balanced: true}
+	if report.SalesTo, err = s.repo.SalesTotal(ctx, tenantID, link.PartnerTenantID, from, to); err != nil {
+		return nil, err
+	}
+	if report.SalesFrom, err = s.repo.SalesTotal(ctx, link.PartnerTenantID, tenantID, from, to); err != nil {
+		return nil, err
+	}
+
+	// Balances are as of the end date, income over the period
+	ours, err := s.balances(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := s.balances(ctx, link.PartnerTenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Lines = []domain.EliminationLine{
+		domain.NewEliminationLine("Due to "+company.Name+" against due from "+partner.Name,
+			partner.ID, reverse.PayableAccountID, -theirs.closing(reverse.PayableAccountID),
+			company.ID, link.ReceivableAccountID, ours.closing(link.ReceivableAccountID)),
+		domain.NewEliminationLine("Due to "+partner.Name+" against due from "+company.Name,
+			company.ID, link.PayableAccountID, -ours.closing(link.PayableAccountID),
+			partner.ID, reverse.ReceivableAccountID, theirs.closing(reverse.ReceivableAccountID)),
+		domain.NewEliminationLine("Sales by "+company.Name+" against purchases by "+partner.Name,
+			company.ID, link.RevenueAccountID, -ours.movement(link.RevenueAccountID),
+			partner.ID, reverse.ExpenseAccountID, theirs.movement(reverse.ExpenseAccountID)),
+		domain.NewEliminationLine("Sales by "+partner.Name+" against purchases by "+company.Name,
+			partner.ID, reverse.RevenueAccountID, -theirs.movement(reverse.RevenueAccountID),
+			company.ID, link.ExpenseAccountID, ours.movement(link.ExpenseAccountID)),
+	}
+	for _, line := range report.Lines {
+		if line.Difference != 0 {
+			report.Balanced = false
+		}
+	}
+	return report, nil
+}
+
+// tenantBalances holds a tenant's posted totals: to date and over the report period
+type tenantBalances struct {
+	toDate   map[uuid.UUID]domain.AccountTotal
+	inPeriod map[uuid.UUID]domain.AccountTotal
+}
+
+func (s *interCompanyService) balances(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (*tenantBalances, error) {
+	toDate, err := s.journalRepo.AccountTotals(ctx, tenantID, nil, to)
+	if err != nil {
+		return nil, err
+	}
+	inPeriod, err := s.journalRepo.AccountTotals(ctx, tenantID, &from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantBalances{toDate: toDate, inPeriod: inPeriod}, nil
+}
+
+// closing is an account's debit balance at the end date; 0 when it is not mapped
+func (b *tenantBalances) closing(accountID *uuid.UUID) float64 {
+	if accountID == nil {
+		return 0
+	}
+	return b.toDate[*accountID].Balance()
+}
+
+// movement is an account's net debits over the period; 0 when it is not mapped
+func (b *tenantBalances) movement(accountID *uuid.UUID) float64 {
+	if accountID == nil {
+		return 0
+	}
+	return b.inPeriod[*accountID].Balance()
+}
+
+// requireOwner checks the user owns every given tenant
+func (s *interCompanyService) requireOwner(ctx context.Context, userID uuid.UUID, tenantIDs ...uuid.UUID) error {
+	for _, tenantID := range tenantIDs {
+		owner, err := s.repo.IsOwner(ctx, userID, tenantID)
+		if err != nil {
+			return err
+		}
+		if !owner {
+			return domain.ErrNotOwnerOfBoth
+		}
+	}
+	return nil
+}
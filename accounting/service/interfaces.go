@@ -2,25 +2,137 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/aceextension/accounting/domain"
 	"github.com/aceextension/accounting/dto"
+	"github.com/aceextension/core/dataimport"
+	fiscalDomain "github.com/aceextension/fiscal/domain"
+	fiscalUtils "github.com/aceextension/fiscal/utils"
 	"github.com/google/uuid"
 )
 
 type AccountingService interface {
 	// Account Management
 	CreateAccount(ctx context.Context, tenantID uuid.UUID, req dto.CreateAccountRequest) (*domain.Account, error)
-	GetAccount(ctx context.Context, id uuid.UUID) (*domain.Account, error)
+	GetAccount(ctx context.Context, tenantID, id uuid.UUID) (*domain.Account, error)
 	ListAccounts(ctx context.Context, tenantID uuid.UUID) ([]*domain.Account, error)
-	UpdateAccount(ctx context.Context, id uuid.UUID, req dto.UpdateAccountRequest) error
+	UpdateAccount(ctx context.Context, tenantID, id uuid.UUID, req dto.UpdateAccountRequest) error
+	// MergeAccounts moves everything booked to a duplicate account onto the account kept,
+	// archives the duplicate and logs the merge to audit. Both must be of the same type.
+	MergeAccounts(ctx context.Context, tenantID, userID, sourceID, targetID uuid.UUID) (*domain.AccountMerge, error)
 
 	// Journal Entry Management
 	CreateJournalEntry(ctx context.Context, tenantID, userID uuid.UUID, req dto.CreateJournalEntryRequest) (*domain.JournalEntry, error)
-	GetJournalEntry(ctx context.Context, id uuid.UUID) (*domain.JournalEntry, error)
-	ListJournalEntries(ctx context.Context, tenantID, fiscalYearID uuid.UUID) ([]*domain.JournalEntry, error)
-	PostJournalEntry(ctx context.Context, id, userID uuid.UUID) error
+	// GetJournalEntry returns ErrJournalEntryNotFound for another tenant's entry
+	GetJournalEntry(ctx context.Context, tenantID, id uuid.UUID) (*domain.JournalEntry, error)
+	// ListJournalEntries returns the latest entries of one or more fiscal years
+	ListJournalEntries(ctx context.Context, tenantID uuid.UUID, fiscalYearIDs []uuid.UUID) ([]*domain.JournalEntry, error)
+	PostJournalEntry(ctx context.Context, tenantID, id, userID uuid.UUID) error
+	// ImportJournalEntries reads vouchers from an upload, each a header row followed by its
+	// line rows, and creates them as draft entries when every row is valid
+	ImportJournalEntries(ctx context.Context, tenantID, userID uuid.UUID, table *dataimport.Table, dryRun bool) (*domain.JournalImportReport, error)
+
+	// Automatic Posting
+	// RegisterPostingSource lets a module have its documents of a reference type posted
+	RegisterPostingSource(referenceType string, source PostingSource)
+	// PostFromReference books a document as a posted journal entry on the tenant's
+	// posting accounts. Each document is posted once; ErrReferenceAlreadyPosted after.
+	PostFromReference(ctx context.Context, tenantID uuid.UUID, referenceType string, referenceID uuid.UUID) (*domain.JournalEntry, error)
+	GetPostingAccounts(ctx context.Context, tenantID uuid.UUID) (domain.PostingAccounts, error)
+	// SetPostingAccounts replaces the accounts posting roles are booked to
+	SetPostingAccounts(ctx context.Context, tenantID uuid.UUID, req dto.PostingAccountsRequest) (domain.PostingAccounts, error)
 
 	// Reports
 	GetLedger(ctx context.Context, tenantID, accountID uuid.UUID, startStr, endStr string) ([]*domain.LedgerEntry, error)
+	// StreamLedger passes ledger lines to fn as they are read, for large date ranges
+	StreamLedger(ctx context.Context, tenantID, accountID uuid.UUID, startStr, endStr string, fn func(*domain.LedgerEntry) error) error
+	// StreamFiscalLedger streams the ledger of one or more fiscal years, which may be closed
+	StreamFiscalLedger(ctx context.Context, tenantID, accountID uuid.UUID, fiscalYearIDs []uuid.UUID, fn func(*domain.LedgerEntry) error) error
+
+	// Financial statements cover posted entries from the first to the last of the fiscal
+	// years given, or else the dates startStr..endStr (YYYY-MM-DD)
+
+	// GetTrialBalance lists balances as of the end with movements in the period; without
+	// a start, movements run from the first entry
+	GetTrialBalance(ctx context.Context, tenantID uuid.UUID, fiscalYearIDs []uuid.UUID, startStr, endStr string) (*domain.TrialBalance, error)
+	// GetProfitAndLoss totals revenue and expense accounts over the period
+	GetProfitAndLoss(ctx context.Context, tenantID uuid.UUID, fiscalYearIDs []uuid.UUID, startStr, endStr string) (*domain.ProfitAndLoss, error)
+	// GetBalanceSheet totals asset, liability and equity accounts as of asOfStr, or the
+	// end of the latest fiscal year given
+	GetBalanceSheet(ctx context.Context, tenantID uuid.UUID, fiscalYearIDs []uuid.UUID, asOfStr string) (*domain.BalanceSheet, error)
+	// GetComparativeReport compares a fiscal year, up to today, with an earlier one by
+	// Nepali month. A nil fiscalYearID is the current year and a nil compareFiscalYearID
+	// the year before it.
+	GetComparativeReport(ctx context.Context, tenantID uuid.UUID, report domain.ComparativeReportType, fiscalYearID, compareFiscalYearID *uuid.UUID) (*domain.ComparativeReport, error)
+}
+
+// DateDisplayFunc returns which calendar a tenant's reports print first. Init uses
+// the tenant's fiscal date display.
+type DateDisplayFunc func(ctx context.Context, tenantID uuid.UUID) fiscalUtils.DateDisplay
+
+// PostingSource reads one of the tenant's documents for posting. It returns
+// ErrReferenceNotPostable, wrapped, for a document that is not in a postable state.
+type PostingSource func(ctx context.Context, tenantID, referenceID uuid.UUID) (*domain.PostingDocument, error)
+
+// YearEndCloseService closes the ledger of a fiscal year as the year is closed
+type YearEndCloseService interface {
+	// CloseYear posts the entry closing revenue and expenses to retained earnings as of
+	// the year end and carries the balance sheet balances into the next year, if it
+	// exists. Runs as a fiscal close hook, before the year is marked closed.
+	CloseYear(ctx context.Context, fy *fiscalDomain.FiscalYear, closedBy uuid.UUID) (*domain.YearEndClose, error)
+	// CarryForward brings a closed year's balances into the next year again, e.g. once
+	// the next year is created or after late adjustments
+	CarryForward(ctx context.Context, tenantID, fiscalYearID uuid.UUID) (*domain.YearEndClose, error)
+	ListOpeningBalances(ctx context.Context, tenantID, fiscalYearID uuid.UUID) ([]*domain.OpeningBalance, error)
+}
+
+// ExportBundleService prepares year-end report bundles for accountants
+type ExportBundleService interface {
+	// RegisterSection adds a report to every bundle; a section with the same name is replaced
+	RegisterSection(section BundleSection)
+	// Request queues a bundle of the fiscal year's reports; formats default to XLSX and PDF
+	Request(ctx context.Context, tenantID, userID, fiscalYearID uuid.UUID, formats []string) (*domain.ExportBundle, error)
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.ExportBundle, error)
+	List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.ExportBundle, error)
+	// DownloadURL returns a link that expires with the bundle, or "" when the store
+	// cannot presign and the file must be served through Open
+	DownloadURL(ctx context.Context, bundle *domain.ExportBundle) (string, error)
+	Open(ctx context.Context, bundle *domain.ExportBundle) (io.ReadCloser, error)
+
+	// ProcessQueued builds queued bundles until none are left (called by a worker)
+	ProcessQueued(ctx context.Context) error
+	// PurgeExpired deletes the files of bundles whose download window has passed
+	PurgeExpired(ctx context.Context) error
+}
+
+// InterCompanyService links companies with a common owner and records sales between them
+type InterCompanyService interface {
+	// Link connects the tenant with a partner; the user must own both
+	Link(ctx context.Context, tenantID, userID, partnerTenantID uuid.UUID) (*domain.TenantLink, error)
+	ListLinks(ctx context.Context, tenantID uuid.UUID) ([]*domain.TenantLink, error)
+	// SetLinkAccounts maps the tenant's accounts for balances and income with the partner
+	SetLinkAccounts(ctx context.Context, tenantID, linkID uuid.UUID, req dto.InterCompanyAccountsRequest) (*domain.TenantLink, error)
+	// Unlink ends the link on both sides; an owner of either company may do so
+	Unlink(ctx context.Context, tenantID, userID, linkID uuid.UUID) error
+
+	// RecordSale records a sale to a linked company and mirrors it as the buyer's draft purchase
+	RecordSale(ctx context.Context, tenantID, userID uuid.UUID, req dto.RecordInterCompanySaleRequest) (*domain.InterCompanyTransaction, error)
+	GetTransaction(ctx context.Context, tenantID, id uuid.UUID) (*domain.InterCompanyTransaction, error)
+	ListTransactions(ctx context.Context, tenantID uuid.UUID, partnerTenantID *uuid.UUID, limit, offset int) ([]*domain.InterCompanyTransaction, error)
+	// RetryMirror creates the buyer's draft again for a sale whose mirror failed
+	RetryMirror(ctx context.Context, tenantID, id uuid.UUID) (*domain.InterCompanyTransaction, error)
+
+	// EliminationReport pairs the linked companies' inter-company balances as of to and
+	// income from..to; the user must own both
+	EliminationReport(ctx context.Context, tenantID, userID, linkID uuid.UUID, from, to time.Time) (*domain.EliminationReport, error)
+
+	SetPurchaseMirror(mirror PurchaseMirror)
+}
+
+// PurchaseMirror creates the buyer's copy of an inter-company sale, such as a draft
+// purchase bill awaiting review, and returns its ID
+type PurchaseMirror interface {
+	MirrorSale(ctx context.Context, sale *domain.InterCompanyTransaction, seller domain.Company) (uuid.UUID, error)
 }
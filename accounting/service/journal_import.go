@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/core/dataimport"
+	fiscalDomain "github.com/aceextension/fiscal/domain"
+	fiscalUtils "github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+)
+
+// Journal import columns. Each voucher is a header row, with its date and description,
+// followed by its line rows, with an account code and a debit or a credit.
+const (
+	importColType        = "type"    // header or line
+	importColVoucher     = "voucher" // Voucher number in the old books, on header rows
+	importColDate        = "date"    // AD date (YYYY-MM-DD) on header rows
+	importColDateBS      = "dateBs"  // BS date (YYYY-MM-DD), used when date is empty
+	importColDescription = "description"
+	importColAccount     = "account" // Account code on line rows
+	importColDebit       = "debit"
+	importColCredit      = "credit"
+	importColNarration   = "narration" // Line description
+)
+
+// importVoucher is a voucher read from the upload; entry is nil when its header row is invalid
+type importVoucher struct {
+	row     int
+	entry   *domain.JournalEntry
+	invalid bool // A line row has errors
+}
+
+// ImportJournalEntries validates a voucher upload row by row and each voucher's balance
+// and, unless it is a dry run, creates the vouchers as draft entries when every row is
+// valid. All vouchers are saved in one transaction, so a failure leaves none created.
+func (s *accountingService) ImportJournalEntries(ctx context.Context, tenantID, userID uuid.UUID, table *dataimport.Table, dryRun bool) (*domain.JournalImportReport, error) {
+	report := &domain.JournalImportReport{Report: dataimport.NewReport(table, dryRun), EntryIDs: []uuid.UUID{}}
+	missing := table.Missing(importColType, importColDescription, importColAccount, importColDebit, importColCredit)
+	if !table.Has(importColDate) && !table.Has(importColDateBS) {
+		missing = append(missing, importColDate)
+	}
+	if missing != nil {
+		report.FailMissing(missing)
+		return report, nil
+	}
+
+	years, err := s.fiscalService.GetByTenantID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fiscal years: %w", err)
+	}
+
+	accounts := map[string]*domain.Account{}
+	voucherRows := map[string]int{}
+	var vouchers []*importVoucher
+	var current *importVoucher
+	for _, row := range table.Rows {
+		switch strings.ToLower(row.Get(importColType)) {
+		case "header", "h":
+			current = &importVoucher{row: row.Number, entry: importHeader(tenantID, userID, row, years, voucherRows, report.Report)}
+			vouchers = append(vouchers, current)
+		case "line", "l":
+			if current == nil {
+				report.Fail(row.Number, importColType, "line comes before any header row")
+				continue
+			}
+			line, err := s.importLine(ctx, tenantID, row, report.Report, accounts)
+			if err != nil {
+				return nil, err
+			}
+			if line == nil {
+				current.invalid = true
+			} else if current.entry != nil {
+				current.entry.AddLine(line.AccountID, line.Debit, line.Credit, line.Description)
+			}
+		default:
+			report.Fail(row.Number, importColType, "type must be header or line")
+		}
+	}
+
+	// A voucher is only checked as a whole once all of its rows are valid
+	entries := make([]*domain.JournalEntry, 0, len(vouchers))
+	for _, v := range vouchers {
+		if v.entry == nil || v.invalid {
+			continue
+		}
+		if err := v.entry.Validate(); err != nil {
+			debit, credit := v.entry.Totals()
+			report.Fail(v.row, "", fmt.Sprintf("%s (debits %.2f, credits %.2f)", err, debit, credit))
+			continue
+		}
+		entries = append(entries, v.entry)
+	}
+	report.Vouchers = len(vouchers)
+
+	failed := map[int]bool{}
+	for _, e := range report.Errors {
+		failed[e.Row] = true
+	}
+	report.Valid = report.Rows - len(failed)
+
+	if dryRun || !report.OK() {
+		return report, nil
+	}
+	if err := s.journalRepo.CreateBatch(ctx, entries); err != nil {
+		return nil, fmt.Errorf("failed to create journal entries: %w", err)
+	}
+	report.Imported = report.Rows
+	for _, entry := range entries {
+		report.EntryIDs = append(report.EntryIDs, entry.ID)
+	}
+	return report, nil
+}
+
+// importHeader reads a voucher's header row; problems go to the report and give a nil entry
+func importHeader(tenantID, userID uuid.UUID, row dataimport.Row, years []*fiscalDomain.FiscalYear, voucherRows map[string]int, report *dataimport.Report) *domain.JournalEntry {
+	ok := true
+	fail := func(column, message string) {
+		report.Fail(row.Number, column, message)
+		ok = false
+	}
+
+	description := row.Get(importColDescription)
+	if description == "" {
+		fail(importColDescription, "description is required")
+	}
+	voucher := row.Get(importColVoucher)
+	if voucher != "" {
+		if first, dup := voucherRows[voucher]; dup {
+			fail(importColVoucher, fmt.Sprintf("voucher repeats row %d", first))
+		} else {
+			voucherRows[voucher] = row.Number
+		}
+		description = fmt.Sprintf("Voucher %s: %s", voucher, description)
+	}
+
+	date, column, err := importDate(row)
+	var fy *fiscalDomain.FiscalYear
+	if err != nil {
+		fail(column, err.Error())
+	} else {
+		for _, year := range years {
+			if !date.Before(year.StartDate) && !date.After(year.EndDate) {
+				fy = year
+				break
+			}
+		}
+		if fy == nil {
+			fail(column, fmt.Sprintf("no fiscal year covers %s", date.Format("2006-01-02")))
+		} else if fy.IsClosed {
+			fail(column, fmt.Sprintf("fiscal year %s is closed", fy.Name))
+		}
+	}
+	if !ok {
+		return nil
+	}
+
+	entry := domain.NewJournalEntry(tenantID, fy.ID, date, description)
+	referenceType := domain.JournalImportReferenceType
+	entry.ReferenceType = &referenceType
+	entry.CreatedByUserID = &userID
+	return entry
+}
+
+// importDate reads a header row's date, AD or else BS, and the column it came from
+func importDate(row dataimport.Row) (time.Time, string, error) {
+	if value := row.Get(importColDate); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return time.Time{}, importColDate, fmt.Errorf("%q is not a date (YYYY-MM-DD)", value)
+		}
+		return date, importColDate, nil
+	}
+	if value := row.Get(importColDateBS); value != "" {
+		bs, err := fiscalUtils.ParseNepaliDate(value)
+		if err != nil {
+			return time.Time{}, importColDateBS, fmt.Errorf("%q is not a BS date (YYYY-MM-DD)", value)
+		}
+		return fiscalUtils.BSToAD(bs), importColDateBS, nil
+	}
+	return time.Time{}, importColDate, errors.New("date or dateBs is required")
+}
+
+// importLine reads and validates one line row; problems go to the report and give a nil line
+func (s *accountingService) importLine(ctx context.Context, tenantID uuid.UUID, row dataimport.Row, report *dataimport.Report, accounts map[string]*domain.Account) (*domain.JournalLine, error) {
+	ok := true
+	fail := func(column, message string) {
+		report.Fail(row.Number, column, message)
+		ok = false
+	}
+
+	debit, _, err := row.Float(importColDebit)
+	if err != nil {
+		fail(importColDebit, err.Error())
+	} else if debit < 0 {
+		fail(importColDebit, "debit cannot be negative")
+	}
+	credit, _, err := row.Float(importColCredit)
+	if err != nil {
+		fail(importColCredit, err.Error())
+	} else if credit < 0 {
+		fail(importColCredit, "credit cannot be negative")
+	}
+	if ok && debit > 0 && credit > 0 {
+		fail(importColDebit, "a line has either a debit or a credit, not both")
+	} else if ok && debit == 0 && credit == 0 {
+		fail(importColDebit, "debit or credit is required")
+	}
+
+	account, err := s.importAccount(ctx, tenantID, row.Get(importColAccount), accounts)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case account == nil:
+		fail(importColAccount, "no account with this code")
+	case account.MergedIntoID != nil:
+		fail(importColAccount, fmt.Sprintf("account %s was merged; book to the account it was merged into", account.Code))
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	return &domain.JournalLine{
+		AccountID:   account.ID,
+		Debit:       debit,
+		Credit:      credit,
+		Description: row.Optional(importColNarration),
+	}, nil
+}
+
+// importAccount resolves an account code, remembering earlier rows' lookups; nil when
+// the tenant has no account with the code
+func (s *accountingService) importAccount(ctx context.Context, tenantID uuid.UUID, code string, accounts map[string]*domain.Account) (*domain.Account, error) {
+	if account, ok := accounts[code]; ok {
+		return account, nil
+	}
+	if code == "" {
+		return nil, nil
+	}
+	account, err := s.accountRepo.GetByCode(ctx, tenantID, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account %s: %w", code, err)
+	}
+	accounts[code] = account
+	return account, nil
+}
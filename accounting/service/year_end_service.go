@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/accounting/domain"
+	"github.com/aceextension/accounting/repository"
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	fiscalDomain "github.com/aceextension/fiscal/domain"
+	fiscalService "github.com/aceextension/fiscal/service"
+	"github.com/google/uuid"
+)
+
+type yearEndCloseService struct {
+	accountRepo   repository.AccountRepository
+	journalRepo   repository.JournalRepository
+	postingRepo   repository.PostingAccountRepository
+	openingRepo   repository.OpeningBalanceRepository
+	fiscalService fiscalService.FiscalYearService
+}
+
+func NewYearEndCloseService(
+	accountRepo repository.AccountRepository,
+	journalRepo repository.JournalRepository,
+	postingRepo repository.PostingAccountRepository,
+	openingRepo repository.OpeningBalanceRepository,
+	fiscalService fiscalService.FiscalYearService,
+) YearEndCloseService {
+	return &yearEndCloseService{
+		accountRepo:   accountRepo,
+		journalRepo:   journalRepo,
+		postingRepo:   postingRepo,
+		openingRepo:   openingRepo,
+		fiscalService: fiscalService,
+	}
+}
+
+func (s *yearEndCloseService) CloseYear(ctx context.Context, fy *fiscalDomain.FiscalYear, closedBy uuid.UUID) (*domain.YearEndClose, error) {
+	accounts, err := s.accountRepo.List(ctx, fy.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	totals, err := s.journalRepo.AccountTotals(ctx, fy.TenantID, nil, fy.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total balances: %w", err)
+	}
+
+	result := &domain.YearEndClose{FiscalYearID: fy.ID}
+
+	// Balances already closed by an earlier close of a reopened year are zero by now,
+	// so only what was booked since is closed again
+	if needsClosing(accounts, totals) {
+		postingAccounts, err := s.postingRepo.Get(ctx, fy.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get posting accounts: %w", err)
+		}
+		retainedEarningsID, ok := postingAccounts[domain.RoleRetainedEarnings]
+		if !ok {
+			return nil, fmt.Errorf("%w %s", domain.ErrPostingAccountMissing, domain.RoleRetainedEarnings)
+		}
+
+		entry, netProfit := domain.NewClosingEntry(fy.TenantID, fy.ID, fy.Name, fy.EndDate, accounts, totals, retainedEarningsID, closedBy)
+		if err := s.journalRepo.Create(ctx, entry); err != nil {
+			return nil, fmt.Errorf("failed to create closing entry: %w", err)
+		}
+		result.ClosingEntry = entry
+		result.NetProfit = netProfit
+
+		// Totals again, now with the year's profit in retained earnings
+		if totals, err = s.journalRepo.AccountTotals(ctx, fy.TenantID, nil, fy.EndDate); err != nil {
+			return nil, fmt.Errorf("failed to total balances: %w", err)
+		}
+	}
+
+	if err := s.carryForward(ctx, fy, accounts, totals, result); err != nil {
+		return nil, err
+	}
+
+	auditCtx := &auditDomain.AuditContext{TenantID: &fy.TenantID, UserID: &closedBy}
+	entityIDStr := fy.ID.String()
+	details := map[string]interface{}{
+		"fiscal_year":      fy.Name,
+		"net_profit":       result.NetProfit,
+		"opening_balances": len(result.OpeningBalances),
+	}
+	if result.ClosingEntry != nil {
+		details["closing_entry_id"] = result.ClosingEntry.ID
+	}
+	if result.NextFiscalYearID != nil {
+		details["next_fiscal_year_id"] = *result.NextFiscalYearID
+	}
+	audit.Service.Log(ctx, "YEAR_END_CLOSE", "FiscalYear", &entityIDStr, details, auditCtx)
+
+	return result, nil
+}
+
+func (s *yearEndCloseService) CarryForward(ctx context.Context, tenantID, fiscalYearID uuid.UUID) (*domain.YearEndClose, error) {
+	fy, err := s.fiscalService.GetByID(ctx, tenantID, fiscalYearID)
+	if err != nil || fy == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrFiscalYearNotFound, fiscalYearID)
+	}
+	if !fy.IsClosed {
+		return nil, domain.ErrFiscalYearOpen
+	}
+
+	accounts, err := s.accountRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	totals, err := s.journalRepo.AccountTotals(ctx, tenantID, nil, fy.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total balances: %w", err)
+	}
+
+	result := &domain.YearEndClose{FiscalYearID: fy.ID}
+	if err := s.carryForward(ctx, fy, accounts, totals, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *yearEndCloseService) ListOpeningBalances(ctx context.Context, tenantID, fiscalYearID uuid.UUID) ([]*domain.OpeningBalance, error) {
+	return s.openingRepo.List(ctx, tenantID, fiscalYearID)
+}
+
+// carryForward brings the closing balances into the year starting after fy, if the
+// tenant has created it
+func (s *yearEndCloseService) carryForward(ctx context.Context, fy *fiscalDomain.FiscalYear, accounts []*domain.Account, totals map[uuid.UUID]domain.AccountTotal, result *domain.YearEndClose) error {
+	years, err := s.fiscalService.GetByTenantID(ctx, fy.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list fiscal years: %w", err)
+	}
+	var next *fiscalDomain.FiscalYear
+	for _, year := range years {
+		if year.StartDate.After(fy.EndDate) && (next == nil || year.StartDate.Before(next.StartDate)) {
+			next = year
+		}
+	}
+	if next == nil {
+		result.OpeningBalances = []*domain.OpeningBalance{}
+		return nil
+	}
+
+	balances := domain.NewOpeningBalances(fy.TenantID, next.ID, fy.ID, accounts, totals)
+	if err := s.openingRepo.Replace(ctx, fy.TenantID, next.ID, balances); err != nil {
+		return err
+	}
+	result.NextFiscalYearID = &next.ID
+	result.OpeningBalances = balances
+	return nil
+}
+
+// needsClosing reports whether a revenue or expense account has a balance left
+func needsClosing(accounts []*domain.Account, totals map[uuid.UUID]domain.AccountTotal) bool {
+	for _, acc := range accounts {
+		if acc.Type != domain.AccountTypeRevenue && acc.Type != domain.AccountTypeExpense {
+			continue
+		}
+		if t := totals[acc.ID]; t.Debit != t.Credit {
+			return true
+		}
+	}
+	return false
+}
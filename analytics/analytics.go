@@ -0,0 +1,154 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/analytics/domain"
+	"github.com/aceextension/analytics/repository"
+	"github.com/aceextension/analytics/service"
+	"github.com/aceextension/core/config"
+	"github.com/aceextension/core/logger"
+)
+
+// anomalyHour is the local hour at which the previous day is evaluated
+const anomalyHour = 6
+
+// Global service instances
+var (
+	PivotService     service.PivotService
+	AnomalyService   service.AnomalyService
+	DashboardService service.DashboardService
+)
+
+// purchasesFactSQL is one row per consignment bill line. Consignment bills
+// carry no VAT, so net and gross are the same.
+const purchasesFactSQL = `
+	SELECT b.created_at AS txn_date,
+	       l.product_id, p.name AS product_name,
+	       p.category_id, c.name AS category_name,
+	       b.supplier_id AS party_id, s.name AS party_name,
+	       NULL::uuid AS salesperson_id, NULL::text AS salesperson_name,
+	       l.quantity AS qty, l.amount AS net, 0::numeric AS vat, l.amount AS gross
+	FROM consignment_bills b
+	JOIN consignment_bill_lines l ON l.bill_id = b.id
+	JOIN suppliers s ON s.id = b.supplier_id
+	LEFT JOIN products p ON p.id = l.product_id
+	LEFT JOIN categories c ON c.id = p.category_id
+	WHERE b.tenant_id = $1 AND b.created_at >= $2 AND b.created_at < $3`
+
+// voidsDailySQL counts each user's voids per day from the audit trail, where
+// voids are logged as VOID_<ENTITY> actions (e.g. VOID_INVOICE)
+const voidsDailySQL = `
+	SELECT created_at::date AS day, user_id AS subject_id, NULL::text AS subject_name, COUNT(*) AS value
+	FROM audit_logs
+	WHERE tenant_id = $1 AND action LIKE 'VOID\_%' AND created_at >= $2 AND created_at < $3
+	GROUP BY 1, 2`
+
+// builtinDashboards are the dashboards a tenant starts with: an overview for
+// owners and managers, the day's takings for the counter and tax figures for
+// accountants. Widgets of sources that are not registered are left out.
+var builtinDashboards = []domain.DashboardTemplate{
+	{
+		Name:       "Business overview",
+		Menu:       "Overview",
+		Roles:      []string{"owner", "admin", "manager"},
+		DefaultFor: []string{"owner", "admin", "manager"},
+		Widgets: []domain.Widget{
+			{Key: "sales-today", Title: "Sales today", Kind: domain.WidgetTotal, Source: "sales", Metric: domain.MetricGross, Days: 1, Width: 4},
+			{Key: "sales-month", Title: "Sales, last 30 days", Kind: domain.WidgetTotal, Source: "sales", Metric: domain.MetricGross, Days: 30, Width: 4},
+			{Key: "purchases-month", Title: "Purchases, last 30 days", Kind: domain.WidgetTotal, Source: "purchases", Metric: domain.MetricGross, Days: 30, Width: 4},
+			{Key: "sales-by-month", Title: "Sales by month", Kind: domain.WidgetSummary, Source: "sales", GroupBy: domain.DimensionMonthBS, Metric: domain.MetricNet, Days: 365, Width: 12},
+			{Key: "top-products", Title: "Top products", Kind: domain.WidgetSummary, Source: "sales", GroupBy: domain.DimensionProduct, Metric: domain.MetricNet, Days: 30},
+			{Key: "top-suppliers", Title: "Top suppliers", Kind: domain.WidgetSummary, Source: "purchases", GroupBy: domain.DimensionSupplier, Metric: domain.MetricNet, Days: 30},
+			{Key: "alerts", Title: "Unusual activity", Kind: domain.WidgetAlerts, Limit: 5, Width: 12},
+		},
+	},
+	{
+		Name:       "Counter",
+		Menu:       "Overview",
+		Roles:      []string{"staff", "cashier"},
+		DefaultFor: []string{"staff", "cashier"},
+		Widgets: []domain.Widget{
+			{Key: "sales-today", Title: "Sales today", Kind: domain.WidgetTotal, Source: "sales", Metric: domain.MetricGross, Days: 1},
+			{Key: "items-today", Title: "Items sold today", Kind: domain.WidgetTotal, Source: "sales", Metric: domain.MetricQty, Days: 1},
+			{Key: "products-today", Title: "Today's products", Kind: domain.WidgetSummary, Source: "sales", GroupBy: domain.DimensionProduct, Metric: domain.MetricQty, Days: 1, Width: 12},
+		},
+	},
+	{
+		Name:       "VAT and purchases",
+		Menu:       "Accounts/Tax",
+		Roles:      []string{"owner", "admin", "accountant"},
+		DefaultFor: []string{"accountant"},
+		Widgets: []domain.Widget{
+			{Key: "output-vat", Title: "Output VAT by month", Kind: domain.WidgetSummary, Source: "sales", GroupBy: domain.DimensionMonthBS, Metric: domain.MetricVAT, Days: 365},
+			{Key: "purchases-by-month", Title: "Purchases by month", Kind: domain.WidgetSummary, Source: "purchases", GroupBy: domain.DimensionMonthBS, Metric: domain.MetricNet, Days: 365},
+			{Key: "purchases-by-supplier", Title: "Purchases by supplier", Kind: domain.WidgetSummary, Source: "purchases", GroupBy: domain.DimensionSupplier, Metric: domain.MetricNet, Days: 30, Width: 12},
+		},
+	},
+}
+
+// Init initializes the analytics module and registers the purchases source, the
+// voids metric and the built-in dashboards. Modules owning other transactions
+// (e.g. sales) register theirs through PivotService.RegisterSource and
+// AnomalyService.RegisterMetric in their own Init.
+func Init() {
+	PivotService = service.NewPivotService(repository.NewPostgresPivotRepository())
+
+	PivotService.RegisterSource(domain.Source{
+		Name:    "purchases",
+		FactSQL: purchasesFactSQL,
+		Dimensions: []domain.Dimension{
+			domain.DimensionProduct,
+			domain.DimensionCategory,
+			domain.DimensionSupplier,
+			domain.DimensionMonthBS,
+		},
+	})
+
+	AnomalyService = service.NewAnomalyService(repository.NewPostgresAnomalyRepository())
+	if config.GlobalConfig != nil {
+		if config.GlobalConfig.AnomalyThreshold > 0 {
+			AnomalyService.SetThreshold(config.GlobalConfig.AnomalyThreshold)
+		}
+		AnomalyService.SetLinkBase(config.GlobalConfig.AppURL)
+	}
+
+	AnomalyService.RegisterMetric(domain.AnomalyMetric{
+		Code:         "voids",
+		Name:         "voided bills",
+		Direction:    domain.AnomalyHigh,
+		DailySQL:     voidsDailySQL,
+		AuditDB:      true,
+		ZeroFill:     true,
+		MinDeviation: 3,
+		Link:         "/api/v1/audit/logs?userId={subject}&startDate={from}&endDate={to}",
+	})
+
+	DashboardService = service.NewDashboardService(repository.NewPostgresDashboardRepository(), PivotService, AnomalyService)
+	for _, template := range builtinDashboards {
+		DashboardService.RegisterTemplate(template)
+	}
+}
+
+// StartAnomalyScheduler evaluates the previous day's metrics once a day at
+// anomalyHour. Call after Init; alerts are sent through the notification module.
+func StartAnomalyScheduler() {
+	go func() {
+		for {
+			time.Sleep(time.Until(nextAnomalyRun(time.Now())))
+			if err := AnomalyService.RunScheduled(context.Background()); err != nil {
+				logger.Log.Error("Daily anomaly evaluation error: " + err.Error())
+			}
+		}
+	}()
+}
+
+// nextAnomalyRun returns the next anomalyHour after now
+func nextAnomalyRun(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), anomalyHour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
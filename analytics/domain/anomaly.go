@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAlertNotFound is returned when an alert does not exist for the tenant
+	ErrAlertNotFound = errors.New("alert not found")
+)
+
+const (
+	// BaselineDays is the rolling window a day is compared against
+	BaselineDays = 28
+	// MinBaselineSamples is how many days of history a baseline needs before it can alert
+	MinBaselineSamples = 7
+	// DefaultAnomalyThreshold is how many standard deviations from the mean is unusual
+	DefaultAnomalyThreshold = 3.0
+)
+
+// AnomalyDirection is which side of the baseline is worth an alert
+type AnomalyDirection string
+
+const (
+	AnomalyLow  AnomalyDirection = "low"  // e.g. sales
+	AnomalyHigh AnomalyDirection = "high" // e.g. voids
+	AnomalyBoth AnomalyDirection = "both"
+)
+
+// AnomalyMetric is a daily business metric watched for unusual values, registered
+// by the module that owns its data.
+//
+// DailySQL selects the metric per day and subject for tenant $1 with days in
+// [$2, $3), with the columns day, subject_id (text, NULL for the whole business),
+// subject_name and value. Link is the client path to the underlying data, with
+// {from}, {to} and {subject} replaced for the alerted day.
+type AnomalyMetric struct {
+	Code      string
+	Name      string
+	Direction AnomalyDirection
+	DailySQL  string
+	AuditDB   bool // DailySQL reads the audit database
+	// ZeroFill counts days without rows as zero (counts such as voids); otherwise
+	// they are days the business was closed and are left out of the baseline
+	ZeroFill bool
+	// MinDeviation is the smallest difference from the mean that can alert, so a
+	// steady metric does not alert on noise
+	MinDeviation float64
+	Link         string
+}
+
+// DailyValue is one day of a metric for one subject
+type DailyValue struct {
+	Day         time.Time
+	SubjectKey  string // Empty for the whole business
+	SubjectName string
+	Value       float64
+}
+
+// Baseline is the rolling mean and standard deviation of a metric for one subject
+type Baseline struct {
+	TenantID    uuid.UUID `json:"tenantId" db:"tenant_id"`
+	MetricCode  string    `json:"metricCode" db:"metric_code"`
+	SubjectKey  string    `json:"subjectKey" db:"subject_key"`
+	SubjectName string    `json:"subjectName" db:"subject_name"`
+	Mean        float64   `json:"mean" db:"mean"`
+	StdDev      float64   `json:"stdDev" db:"std_dev"`
+	Samples     int       `json:"samples" db:"samples"`
+	WindowFrom  time.Time `json:"windowFrom" db:"window_from"`
+	WindowTo    time.Time `json:"windowTo" db:"window_to"`
+	ComputedAt  time.Time `json:"computedAt" db:"computed_at"`
+}
+
+// NewBaseline computes a baseline from a window of daily values
+func NewBaseline(tenantID uuid.UUID, metricCode, subjectKey, subjectName string, values []float64, from, to time.Time) *Baseline {
+	b := &Baseline{
+		TenantID:    tenantID,
+		MetricCode:  metricCode,
+		SubjectKey:  subjectKey,
+		SubjectName: subjectName,
+		Samples:     len(values),
+		WindowFrom:  from,
+		WindowTo:    to,
+		ComputedAt:  time.Now(),
+	}
+	if len(values) == 0 {
+		return b
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	b.Mean = sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - b.Mean) * (v - b.Mean)
+	}
+	b.StdDev = math.Sqrt(squares / float64(len(values)))
+	return b
+}
+
+// Score returns how many standard deviations value is from the mean and whether
+// that is an anomaly in the metric's direction. A flat baseline scores any
+// change of at least minDeviation past the threshold.
+func (b *Baseline) Score(value, threshold, minDeviation float64, direction AnomalyDirection) (float64, bool) {
+	if b.Samples < MinBaselineSamples {
+		return 0, false
+	}
+
+	deviation := value - b.Mean
+	if math.Abs(deviation) < minDeviation || deviation == 0 {
+		return 0, false
+	}
+
+	var z float64
+	if b.StdDev > 0 {
+		z = deviation / b.StdDev
+	} else {
+		z = math.Copysign(threshold, deviation)
+	}
+	z = math.Round(z*100) / 100
+
+	switch direction {
+	case AnomalyLow:
+		return z, z <= -threshold
+	case AnomalyHigh:
+		return z, z >= threshold
+	default:
+		return z, math.Abs(z) >= threshold
+	}
+}
+
+// TopicAlerts is the realtime topic new alerts are published on
+const TopicAlerts = "alerts"
+
+// EventAlertRaised is the event type of a new alert
+const EventAlertRaised = "alert.raised"
+
+// AlertViewerRoles receive alerts as live updates
+var AlertViewerRoles = []string{"owner", "admin", "manager"}
+
+// AlertStatus is where an alert is in review
+type AlertStatus string
+
+const (
+	AlertOpen         AlertStatus = "open"
+	AlertAcknowledged AlertStatus = "acknowledged"
+)
+
+// Alert records an unusual day of a metric
+type Alert struct {
+	ID             uuid.UUID   `json:"id" db:"id"`
+	TenantID       uuid.UUID   `json:"tenantId" db:"tenant_id"`
+	MetricCode     string      `json:"metricCode" db:"metric_code"`
+	MetricName     string      `json:"metricName" db:"metric_name"`
+	SubjectKey     string      `json:"subjectKey" db:"subject_key"`
+	SubjectName    string      `json:"subjectName" db:"subject_name"`
+	Day            time.Time   `json:"day" db:"day"`
+	Value          float64     `json:"value" db:"value"`
+	Mean           float64     `json:"mean" db:"mean"`
+	StdDev         float64     `json:"stdDev" db:"std_dev"`
+	ZScore         float64     `json:"zScore" db:"z_score"`
+	Link           string      `json:"link" db:"link"`
+	Status         AlertStatus `json:"status" db:"status"`
+	NotifiedCount  int         `json:"notifiedCount" db:"notified_count"`
+	AcknowledgedBy *uuid.UUID  `json:"acknowledgedBy,omitempty" db:"acknowledged_by"`
+	AcknowledgedAt *time.Time  `json:"acknowledgedAt,omitempty" db:"acknowledged_at"`
+	CreatedAt      time.Time   `json:"createdAt" db:"created_at"`
+}
+
+// Acknowledge marks an alert as seen
+func (a *Alert) Acknowledge(userID uuid.UUID) {
+	if a.Status == AlertAcknowledged {
+		return
+	}
+	now := time.Now()
+	a.Status = AlertAcknowledged
+	a.AcknowledgedBy = &userID
+	a.AcknowledgedAt = &now
+}
+
+// AnomalyRun summarises one evaluation of a tenant's metrics
+type AnomalyRun struct {
+	Day       string   `json:"day"`
+	Baselines int      `json:"baselines"`
+	Alerts    []*Alert `json:"alerts"`
+}
@@ -0,0 +1,298 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrDashboardNotFound is returned when a dashboard does not exist or is not visible to the user
+	ErrDashboardNotFound = errors.New("dashboard not found")
+	// ErrInvalidDashboard is returned for a dashboard without a name or with too many widgets
+	ErrInvalidDashboard = errors.New("invalid dashboard")
+	// ErrInvalidWidget is returned for a widget with an unknown kind or settings out of range
+	ErrInvalidWidget = errors.New("invalid widget")
+	// ErrDashboardNotPermitted is returned when the role may not change a shared dashboard
+	ErrDashboardNotPermitted = errors.New("only owners and admins may change shared dashboards")
+)
+
+const (
+	// MaxDashboardWidgets bounds the queries one data request runs
+	MaxDashboardWidgets = 24
+	// MaxMenuDepth bounds how many levels a dashboard menu path may have
+	MaxMenuDepth = 3
+	// DefaultWidgetDays is the period a widget covers when none is set
+	DefaultWidgetDays = 30
+	// DefaultWidgetLimit is how many rows a widget shows when none is set
+	DefaultWidgetLimit = 10
+	// DefaultWidgetWidth is half of the 12-column grid
+	DefaultWidgetWidth = 6
+)
+
+// DashboardManagerRoles may create, change and remove shared dashboards and role defaults
+var DashboardManagerRoles = []string{"owner", "admin"}
+
+// CanManageDashboards reports whether a role may change shared dashboards
+func CanManageDashboards(role string) bool {
+	for _, manager := range DashboardManagerRoles {
+		if role == manager {
+			return true
+		}
+	}
+	return false
+}
+
+// WidgetKind is what a widget shows
+type WidgetKind string
+
+const (
+	WidgetSummary WidgetKind = "summary" // A summary table or chart of a source
+	WidgetTotal   WidgetKind = "total"   // The total of a source's metric, e.g. today's sales
+	WidgetAlerts  WidgetKind = "alerts"  // The latest open metric alerts
+)
+
+// Widget is one tile of a dashboard. Summary and total widgets read a pivot
+// source over the Days up to the requested day.
+type Widget struct {
+	Key     string     `json:"key"` // Unique within the dashboard
+	Title   string     `json:"title"`
+	Kind    WidgetKind `json:"kind"`
+	Source  string     `json:"source,omitempty"`
+	GroupBy Dimension  `json:"groupBy,omitempty"` // Summary widgets only
+	Metric  Metric     `json:"metric,omitempty"`
+	Days    int        `json:"days,omitempty"`  // 1 for the requested day alone
+	Limit   int        `json:"limit,omitempty"` // Rows of summary and alerts widgets
+	Width   int        `json:"width,omitempty"` // Columns of a 12-column grid
+}
+
+// Normalize fills in defaults and checks the widget's settings. Whether the
+// source exists and supports the grouping is checked when its data is loaded,
+// since sources are registered by other modules.
+func (w *Widget) Normalize() error {
+	w.Key = strings.TrimSpace(w.Key)
+	if w.Key == "" || w.Title == "" {
+		return ErrInvalidWidget
+	}
+	if w.Width == 0 {
+		w.Width = DefaultWidgetWidth
+	}
+	if w.Width < 1 || w.Width > 12 {
+		return ErrInvalidWidget
+	}
+	if w.Limit == 0 {
+		w.Limit = DefaultWidgetLimit
+	}
+	if w.Limit < 1 || w.Limit > 100 {
+		return ErrInvalidWidget
+	}
+
+	switch w.Kind {
+	case WidgetSummary, WidgetTotal:
+		if w.Source == "" {
+			return ErrInvalidWidget
+		}
+		if w.Kind == WidgetSummary && w.GroupBy == "" {
+			return ErrInvalidWidget
+		}
+		if w.Kind == WidgetTotal {
+			w.GroupBy = ""
+		}
+		if w.Metric == "" {
+			w.Metric = MetricNet
+		}
+		if !w.Metric.Valid() {
+			return ErrInvalidMetric
+		}
+		if w.Days == 0 {
+			w.Days = DefaultWidgetDays
+		}
+		if w.Days < 1 || w.Days > MaxPivotDays {
+			return ErrInvalidWidget
+		}
+	case WidgetAlerts:
+		w.Source, w.GroupBy, w.Metric, w.Days = "", "", "", 0
+	default:
+		return ErrInvalidWidget
+	}
+	return nil
+}
+
+// Dashboard is a named set of widgets placed in a multi-level menu. A shared
+// dashboard is seen by the roles it lists (everyone when none are listed); a
+// personal one only by its owner. A personal copy of a shared dashboard
+// (BasedOn) replaces it in its owner's menu.
+type Dashboard struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	TenantID  uuid.UUID  `json:"tenantId" db:"tenant_id"`
+	Name      string     `json:"name" db:"name"`
+	Menu      string     `json:"menu" db:"menu"` // Menu path, levels separated by "/", e.g. "Reports/Sales"
+	Position  int        `json:"position" db:"position"`
+	Roles     []string   `json:"roles" db:"roles"`
+	OwnerID   *uuid.UUID `json:"ownerId,omitempty" db:"owner_id"` // Set for personal dashboards
+	BasedOn   *uuid.UUID `json:"basedOn,omitempty" db:"based_on"` // Shared dashboard a personal copy customizes
+	Widgets   []Widget   `json:"widgets" db:"widgets"`
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// NewDashboard creates a shared dashboard
+func NewDashboard(tenantID uuid.UUID, name, menu string, roles []string, widgets []Widget, createdBy *uuid.UUID) *Dashboard {
+	now := time.Now()
+	return &Dashboard{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Name:      name,
+		Menu:      menu,
+		Roles:     roles,
+		Widgets:   widgets,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Personal reports whether the dashboard belongs to one user
+func (d *Dashboard) Personal() bool {
+	return d.OwnerID != nil
+}
+
+// VisibleTo reports whether a user with a role may see the dashboard
+func (d *Dashboard) VisibleTo(userID uuid.UUID, role string) bool {
+	if d.OwnerID != nil {
+		return *d.OwnerID == userID
+	}
+	if len(d.Roles) == 0 {
+		return true
+	}
+	for _, r := range d.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// EditableBy reports whether a user with a role may change or remove the dashboard
+func (d *Dashboard) EditableBy(userID uuid.UUID, role string) bool {
+	if d.OwnerID != nil {
+		return *d.OwnerID == userID
+	}
+	return CanManageDashboards(role)
+}
+
+// CopyFor makes a personal copy of a shared dashboard for a user to customize
+func (d *Dashboard) CopyFor(userID uuid.UUID) *Dashboard {
+	copied := NewDashboard(d.TenantID, d.Name, d.Menu, nil, append([]Widget(nil), d.Widgets...), &userID)
+	copied.Position = d.Position
+	copied.OwnerID = &userID
+	copied.BasedOn = &d.ID
+	return copied
+}
+
+// Validate normalizes the menu path, roles and widgets
+func (d *Dashboard) Validate() error {
+	d.Name = strings.TrimSpace(d.Name)
+	if d.Name == "" || len(d.Widgets) > MaxDashboardWidgets {
+		return ErrInvalidDashboard
+	}
+
+	levels := MenuLevels(d.Menu)
+	if len(levels) > MaxMenuDepth {
+		return ErrInvalidDashboard
+	}
+	d.Menu = strings.Join(levels, "/")
+
+	if d.OwnerID != nil {
+		d.Roles = nil
+	}
+	if d.Roles == nil {
+		d.Roles = []string{}
+	}
+	if d.Widgets == nil {
+		d.Widgets = []Widget{}
+	}
+
+	keys := make(map[string]bool, len(d.Widgets))
+	for i := range d.Widgets {
+		if err := d.Widgets[i].Normalize(); err != nil {
+			return err
+		}
+		if keys[d.Widgets[i].Key] {
+			return ErrInvalidWidget
+		}
+		keys[d.Widgets[i].Key] = true
+	}
+	return nil
+}
+
+// MenuLevels splits a menu path into its non-empty levels
+func MenuLevels(menu string) []string {
+	levels := []string{}
+	for _, level := range strings.Split(menu, "/") {
+		if level = strings.TrimSpace(level); level != "" {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// RoleDefault is the dashboard a role lands on
+type RoleDefault struct {
+	Role        string    `json:"role" db:"role"`
+	DashboardID uuid.UUID `json:"dashboardId" db:"dashboard_id"`
+}
+
+// MenuEntry is a dashboard in the menu
+type MenuEntry struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Personal bool      `json:"personal"`
+}
+
+// MenuNode is one level of the dashboard menu
+type MenuNode struct {
+	Name       string      `json:"name"`
+	Dashboards []MenuEntry `json:"dashboards"`
+	Children   []*MenuNode `json:"children"`
+}
+
+// DashboardMenu is a user's dashboards arranged by menu path, with the one they land on
+type DashboardMenu struct {
+	Home       *uuid.UUID  `json:"home"`
+	Dashboards []MenuEntry `json:"dashboards"` // Those without a menu path
+	Children   []*MenuNode `json:"children"`
+}
+
+// WidgetData is the loaded content of one widget; a widget that fails carries
+// its error without failing the rest of the dashboard
+type WidgetData struct {
+	Key     string       `json:"key"`
+	Kind    WidgetKind   `json:"kind"`
+	From    string       `json:"from,omitempty"`
+	To      string       `json:"to,omitempty"`
+	Summary *PivotResult `json:"summary,omitempty"`
+	Total   *float64     `json:"total,omitempty"`
+	Alerts  []*Alert     `json:"alerts,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// DashboardData is every widget of a dashboard loaded for one day
+type DashboardData struct {
+	DashboardID uuid.UUID    `json:"dashboardId"`
+	Day         string       `json:"day"`
+	Widgets     []WidgetData `json:"widgets"`
+}
+
+// DashboardTemplate is a built-in dashboard every tenant starts with, landing
+// the DefaultFor roles on it
+type DashboardTemplate struct {
+	Name       string
+	Menu       string
+	Roles      []string
+	DefaultFor []string
+	Widgets    []Widget
+}
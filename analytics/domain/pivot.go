@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnknownSource is returned for a data source no module has registered
+	ErrUnknownSource = errors.New("unknown summary source")
+	// ErrInvalidDimension is returned for a group_by the source does not support
+	ErrInvalidDimension = errors.New("invalid group_by for this source")
+	// ErrInvalidMetric is returned for an unknown metric
+	ErrInvalidMetric = errors.New("invalid metric: use qty, net, vat or gross")
+	// ErrInvalidPeriod is returned when from is after to or the period is too long
+	ErrInvalidPeriod = errors.New("invalid period")
+)
+
+// MaxPivotDays bounds a summary period; longer comparisons belong in fiscal year reports
+const MaxPivotDays = 731
+
+// Dimension is what a summary is grouped by
+type Dimension string
+
+const (
+	DimensionProduct     Dimension = "product"
+	DimensionCategory    Dimension = "category"
+	DimensionCustomer    Dimension = "customer" // Party of a sale
+	DimensionSupplier    Dimension = "supplier" // Party of a purchase
+	DimensionSalesperson Dimension = "salesperson"
+	DimensionMonthBS     Dimension = "month_bs" // Bikram Sambat month, e.g. 2081-06
+)
+
+// Metric is the summed measure
+type Metric string
+
+const (
+	MetricQty   Metric = "qty"
+	MetricNet   Metric = "net" // Before VAT
+	MetricVAT   Metric = "vat"
+	MetricGross Metric = "gross" // Net plus VAT
+)
+
+// Valid reports whether the metric is known
+func (m Metric) Valid() bool {
+	switch m {
+	case MetricQty, MetricNet, MetricVAT, MetricGross:
+		return true
+	}
+	return false
+}
+
+// Source is a set of transaction lines summaries are computed over, registered by
+// the module that owns the tables.
+//
+// FactSQL selects one row per line for tenant $1 with txn_date in [$2, $3), with
+// the columns txn_date, product_id, product_name, category_id, category_name,
+// party_id, party_name, salesperson_id, salesperson_name, qty, net, vat and gross.
+// Columns for dimensions the source does not support may be NULL. The tables it
+// reads should be indexed on (tenant_id, date).
+type Source struct {
+	Name       string
+	FactSQL    string
+	Dimensions []Dimension
+}
+
+// Supports reports whether the source can be grouped by a dimension
+func (s *Source) Supports(dimension Dimension) bool {
+	for _, d := range s.Dimensions {
+		if d == dimension {
+			return true
+		}
+	}
+	return false
+}
+
+// PivotQuery asks for one metric of a source grouped by one dimension over from..to (inclusive days)
+type PivotQuery struct {
+	TenantID uuid.UUID
+	Source   string
+	GroupBy  Dimension
+	Metric   Metric
+	From     time.Time
+	To       time.Time
+	Limit    int // Largest groups kept; ignored for month_bs
+}
+
+// Validate checks the period
+func (q *PivotQuery) Validate() error {
+	if q.From.After(q.To) {
+		return ErrInvalidPeriod
+	}
+	if q.To.Sub(q.From) > MaxPivotDays*24*time.Hour {
+		return ErrInvalidPeriod
+	}
+	return nil
+}
+
+// PivotRow is one group of a summary
+type PivotRow struct {
+	Key   string  `json:"key"`   // Entity ID, or YYYY-MM for month_bs
+	Label string  `json:"label"` // Name, or "Ashwin 2081" for month_bs
+	Value float64 `json:"value"`
+	Share float64 `json:"share"` // Percent of the total
+}
+
+// PivotResult is a summary table
+type PivotResult struct {
+	Source  string     `json:"source"`
+	GroupBy Dimension  `json:"groupBy"`
+	Metric  Metric     `json:"metric"`
+	From    string     `json:"from"`
+	To      string     `json:"to"`
+	Rows    []PivotRow `json:"rows"`
+	Total   float64    `json:"total"`  // Over every group, including those past the limit
+	Others  float64    `json:"others"` // Total of the groups past the limit
+}
+
+// SourceInfo describes a registered source for clients building summary screens
+type SourceInfo struct {
+	Name       string      `json:"name"`
+	Dimensions []Dimension `json:"dimensions"`
+	Metrics    []Metric    `json:"metrics"`
+}
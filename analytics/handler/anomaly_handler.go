@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/analytics"
+	"github.com/aceextension/analytics/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// AnomalyHandler handles HTTP requests for metric baselines and alerts
+type AnomalyHandler struct{}
+
+// NewAnomalyHandler creates a new anomaly handler
+func NewAnomalyHandler() *AnomalyHandler {
+	return &AnomalyHandler{}
+}
+
+// ListAlerts godoc
+// @Summary List metric alerts
+// @Description List unusual days of business metrics (e.g. a cashier's voids), newest first. Each alert links to the underlying data.
+// @Tags analytics
+// @Produce json
+// @Param status query string false "open or acknowledged; empty for all"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.Alert
+// @Router /api/v1/analytics/alerts [get]
+// @Security BearerAuth
+func (h *AnomalyHandler) ListAlerts(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	alerts, err := analytics.AnomalyService.ListAlerts(c.Request().Context(), tenantID, domain.AlertStatus(c.QueryParam("status")), limit, offset)
+	if err != nil {
+		return anomalyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, alerts)
+}
+
+// GetAlert godoc
+// @Summary Get a metric alert
+// @Description Get an alert with the baseline it was measured against
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Alert ID"
+// @Success 200 {object} domain.Alert
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/analytics/alerts/{id} [get]
+// @Security BearerAuth
+func (h *AnomalyHandler) GetAlert(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid alert ID"})
+	}
+
+	alert, err := analytics.AnomalyService.GetAlert(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return anomalyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, alert)
+}
+
+// AcknowledgeAlert godoc
+// @Summary Acknowledge a metric alert
+// @Description Mark an alert as seen
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Alert ID"
+// @Success 200 {object} domain.Alert
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/analytics/alerts/{id}/acknowledge [post]
+// @Security BearerAuth
+func (h *AnomalyHandler) AcknowledgeAlert(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid alert ID"})
+	}
+
+	alert, err := analytics.AnomalyService.Acknowledge(c.Request().Context(), tenantID, id, userID)
+	if err != nil {
+		return anomalyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, alert)
+}
+
+// ListBaselines godoc
+// @Summary List metric baselines
+// @Description List the rolling mean and standard deviation of each watched metric per subject, as of the last evaluation
+// @Tags analytics
+// @Produce json
+// @Success 200 {array} domain.Baseline
+// @Router /api/v1/analytics/baselines [get]
+// @Security BearerAuth
+func (h *AnomalyHandler) ListBaselines(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	baselines, err := analytics.AnomalyService.ListBaselines(c.Request().Context(), tenantID)
+	if err != nil {
+		return anomalyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, baselines)
+}
+
+// Evaluate godoc
+// @Summary Evaluate metrics for a day
+// @Description Recompute baselines and alert on a day's unusual values now instead of waiting for the daily job. A day already alerted is not alerted again.
+// @Tags analytics
+// @Produce json
+// @Param day query string false "Day (YYYY-MM-DD), defaults to yesterday"
+// @Success 200 {object} domain.AnomalyRun
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/analytics/anomalies/evaluate [post]
+// @Security BearerAuth
+func (h *AnomalyHandler) Evaluate(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	day := time.Now().AddDate(0, 0, -1)
+	if value := c.QueryParam("day"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid day, expected YYYY-MM-DD"})
+		}
+		day = parsed
+	}
+
+	run, err := analytics.AnomalyService.Evaluate(c.Request().Context(), tenantID, day)
+	if err != nil {
+		return anomalyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, run)
+}
+
+// anomalyError maps anomaly domain errors to HTTP responses
+func anomalyError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrAlertNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
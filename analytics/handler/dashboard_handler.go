@@ -0,0 +1,389 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aceextension/analytics"
+	"github.com/aceextension/analytics/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// DashboardHandler handles HTTP requests for saved dashboards
+type DashboardHandler struct{}
+
+// NewDashboardHandler creates a new dashboard handler
+func NewDashboardHandler() *DashboardHandler {
+	return &DashboardHandler{}
+}
+
+// DashboardRequest is the body for creating or updating a dashboard
+type DashboardRequest struct {
+	Name     string          `json:"name"`
+	Menu     string          `json:"menu"` // Menu path, e.g. "Reports/Sales"
+	Position int             `json:"position"`
+	Shared   bool            `json:"shared"` // Visible to Roles instead of only the creator; owners and admins only
+	Roles    []string        `json:"roles"`
+	Widgets  []domain.Widget `json:"widgets"`
+}
+
+// RoleDefaultRequest is the body for setting the dashboard a role lands on
+type RoleDefaultRequest struct {
+	DashboardID uuid.UUID `json:"dashboardId"`
+}
+
+// GetMenu godoc
+// @Summary Get the dashboard menu
+// @Description Get the caller's dashboards arranged by menu path, with the one to land on (their role's default, or their customized copy of it). A tenant without dashboards gets the built-in ones first.
+// @Tags dashboards
+// @Produce json
+// @Success 200 {object} domain.DashboardMenu
+// @Router /api/v1/dashboards/menu [get]
+// @Security BearerAuth
+func (h *DashboardHandler) GetMenu(c echo.Context) error {
+	tenantID, userID, role, err := dashboardCaller(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	menu, err := analytics.DashboardService.Menu(c.Request().Context(), tenantID, userID, role)
+	if err != nil {
+		return dashboardError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, menu)
+}
+
+// ListDashboards godoc
+// @Summary List dashboards
+// @Description List the shared dashboards for the caller's role and their personal ones
+// @Tags dashboards
+// @Produce json
+// @Success 200 {array} domain.Dashboard
+// @Router /api/v1/dashboards [get]
+// @Security BearerAuth
+func (h *DashboardHandler) ListDashboards(c echo.Context) error {
+	tenantID, userID, role, err := dashboardCaller(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	dashboards, err := analytics.DashboardService.List(c.Request().Context(), tenantID, userID, role)
+	if err != nil {
+		return dashboardError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dashboards)
+}
+
+// CreateDashboard godoc
+// @Summary Create a dashboard
+// @Description Create a personal dashboard, or a shared one for the listed roles (all roles when none are listed). Only owners and admins create shared dashboards.
+// @Tags dashboards
+// @Accept json
+// @Produce json
+// @Param request body DashboardRequest true "Dashboard"
+// @Success 201 {object} domain.Dashboard
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/dashboards [post]
+// @Security BearerAuth
+func (h *DashboardHandler) CreateDashboard(c echo.Context) error {
+	tenantID, userID, role, err := dashboardCaller(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	var req DashboardRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	dashboard := domain.NewDashboard(tenantID, req.Name, req.Menu, req.Roles, req.Widgets, &userID)
+	dashboard.Position = req.Position
+	if !req.Shared {
+		dashboard.OwnerID = &userID
+	}
+
+	if err := analytics.DashboardService.Create(c.Request().Context(), dashboard, role); err != nil {
+		return dashboardError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dashboard)
+}
+
+// GetDashboard godoc
+// @Summary Get a dashboard
+// @Description Get a dashboard's definition
+// @Tags dashboards
+// @Produce json
+// @Param id path string true "Dashboard ID"
+// @Success 200 {object} domain.Dashboard
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dashboards/{id} [get]
+// @Security BearerAuth
+func (h *DashboardHandler) GetDashboard(c echo.Context) error {
+	tenantID, userID, role, err := dashboardCaller(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid dashboard ID"})
+	}
+
+	dashboard, err := analytics.DashboardService.Get(c.Request().Context(), tenantID, id, userID, role)
+	if err != nil {
+		return dashboardError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dashboard)
+}
+
+// UpdateDashboard godoc
+// @Summary Update a dashboard
+// @Description Replace a dashboard's name, menu placement, roles and widgets. Users change their personal dashboards; owners and admins change shared ones.
+// @Tags dashboards
+// @Accept json
+// @Produce json
+// @Param id path string true "Dashboard ID"
+// @Param request body DashboardRequest true "Dashboard"
+// @Success 200 {object} domain.Dashboard
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dashboards/{id} [put]
+// @Security BearerAuth
+func (h *DashboardHandler) UpdateDashboard(c echo.Context) error {
+	tenantID, userID, role, err := dashboardCaller(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid dashboard ID"})
+	}
+
+	var req DashboardRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	changes := &domain.Dashboard{Name: req.Name, Menu: req.Menu, Position: req.Position, Roles: req.Roles, Widgets: req.Widgets}
+	dashboard, err := analytics.DashboardService.Update(c.Request().Context(), tenantID, id, userID, role, changes)
+	if err != nil {
+		return dashboardError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dashboard)
+}
+
+// DeleteDashboard godoc
+// @Summary Delete a dashboard
+// @Description Delete a dashboard. Deleting a customized copy brings back the shared dashboard; deleting a shared dashboard removes everyone's copies of it.
+// @Tags dashboards
+// @Param id path string true "Dashboard ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dashboards/{id} [delete]
+// @Security BearerAuth
+func (h *DashboardHandler) DeleteDashboard(c echo.Context) error {
+	tenantID, userID, role, err := dashboardCaller(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid dashboard ID"})
+	}
+
+	if err := analytics.DashboardService.Delete(c.Request().Context(), tenantID, id, userID, role); err != nil {
+		return dashboardError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CustomizeDashboard godoc
+// @Summary Customize a shared dashboard
+// @Description Get the caller's personal copy of a shared dashboard, making it on first use. The copy replaces the shared dashboard in their menu and can be changed like any personal dashboard.
+// @Tags dashboards
+// @Produce json
+// @Param id path string true "Dashboard ID"
+// @Success 200 {object} domain.Dashboard
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dashboards/{id}/customize [post]
+// @Security BearerAuth
+func (h *DashboardHandler) CustomizeDashboard(c echo.Context) error {
+	tenantID, userID, role, err := dashboardCaller(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid dashboard ID"})
+	}
+
+	dashboard, err := analytics.DashboardService.Customize(c.Request().Context(), tenantID, id, userID, role)
+	if err != nil {
+		return dashboardError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dashboard)
+}
+
+// GetDashboardData godoc
+// @Summary Get a dashboard's data
+// @Description Load every widget of a dashboard in one request. Summary and total widgets cover their number of days ending on day. A widget that fails carries its error without failing the others.
+// @Tags dashboards
+// @Produce json
+// @Param id path string true "Dashboard ID"
+// @Param day query string false "Last day of the widget periods (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} domain.DashboardData
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dashboards/{id}/data [get]
+// @Security BearerAuth
+func (h *DashboardHandler) GetDashboardData(c echo.Context) error {
+	tenantID, userID, role, err := dashboardCaller(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid dashboard ID"})
+	}
+
+	day := time.Now()
+	if value := c.QueryParam("day"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid day, expected YYYY-MM-DD"})
+		}
+		day = parsed
+	}
+
+	data, err := analytics.DashboardService.Data(c.Request().Context(), tenantID, id, userID, role, day)
+	if err != nil {
+		return dashboardError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+// ListRoleDefaults godoc
+// @Summary List role default dashboards
+// @Description List the dashboard each role lands on
+// @Tags dashboards
+// @Produce json
+// @Success 200 {array} domain.RoleDefault
+// @Router /api/v1/dashboards/defaults [get]
+// @Security BearerAuth
+func (h *DashboardHandler) ListRoleDefaults(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	defaults, err := analytics.DashboardService.ListRoleDefaults(c.Request().Context(), tenantID)
+	if err != nil {
+		return dashboardError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, defaults)
+}
+
+// SetRoleDefault godoc
+// @Summary Set a role's default dashboard
+// @Description Land a role on a shared dashboard it can see. Owners and admins only.
+// @Tags dashboards
+// @Accept json
+// @Produce json
+// @Param role path string true "Role, e.g. staff"
+// @Param request body RoleDefaultRequest true "Dashboard"
+// @Success 200 {object} domain.RoleDefault
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dashboards/defaults/{role} [put]
+// @Security BearerAuth
+func (h *DashboardHandler) SetRoleDefault(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	var req RoleDefaultRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	def := domain.RoleDefault{Role: c.Param("role"), DashboardID: req.DashboardID}
+	if err := analytics.DashboardService.SetRoleDefault(c.Request().Context(), tenantID, def, role); err != nil {
+		return dashboardError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, def)
+}
+
+// DeleteRoleDefault godoc
+// @Summary Remove a role's default dashboard
+// @Description Remove a role's default; its users land on the first dashboard in their menu. Owners and admins only.
+// @Tags dashboards
+// @Param role path string true "Role"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dashboards/defaults/{role} [delete]
+// @Security BearerAuth
+func (h *DashboardHandler) DeleteRoleDefault(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	if err := analytics.DashboardService.DeleteRoleDefault(c.Request().Context(), tenantID, c.Param("role"), role); err != nil {
+		return dashboardError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// dashboardCaller reads the tenant, user and role of the request
+func dashboardCaller(c echo.Context) (uuid.UUID, uuid.UUID, string, error) {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return uuid.Nil, uuid.Nil, "", errors.New("Tenant ID not found")
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return uuid.Nil, uuid.Nil, "", errors.New("User ID not found")
+	}
+	role, _ := db.GetRole(c.Request().Context())
+	return tenantID, userID, role, nil
+}
+
+// dashboardError maps dashboard domain errors to HTTP responses
+func dashboardError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrDashboardNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidDashboard), errors.Is(err, domain.ErrInvalidWidget), errors.Is(err, domain.ErrInvalidMetric):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrDashboardNotPermitted):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/analytics"
+	"github.com/aceextension/analytics/domain"
+	"github.com/aceextension/core/db"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultSummaryDays is the period a summary covers when no from date is given
+	defaultSummaryDays = 30
+	// defaultSummaryLimit is how many groups a summary returns when no limit is given
+	defaultSummaryLimit = 50
+)
+
+// PivotHandler handles HTTP requests for summary tables
+type PivotHandler struct{}
+
+// NewPivotHandler creates a new pivot handler
+func NewPivotHandler() *PivotHandler {
+	return &PivotHandler{}
+}
+
+// ListSources godoc
+// @Summary List summary sources
+// @Description List the transaction sources summaries can be built from, with the group_by values each supports
+// @Tags analytics
+// @Produce json
+// @Success 200 {array} domain.SourceInfo
+// @Router /api/v1/analytics/sources [get]
+// @Security BearerAuth
+func (h *PivotHandler) ListSources(c echo.Context) error {
+	return c.JSON(http.StatusOK, analytics.PivotService.Sources())
+}
+
+// Summary godoc
+// @Summary Get a summary table
+// @Description Sum a metric of a source's transaction lines grouped by one dimension, largest first (month_bs is chronological). Groups past the limit are totalled in others.
+// @Tags analytics
+// @Produce json
+// @Param source path string true "Source, e.g. sales or purchases"
+// @Param group_by query string true "product, category, customer, supplier, salesperson or month_bs"
+// @Param metric query string false "qty, net, vat or gross" default(net)
+// @Param from query string false "From date (YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "To date (YYYY-MM-DD), inclusive, defaults to today"
+// @Param limit query int false "Largest groups returned" default(50)
+// @Success 200 {object} domain.PivotResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/analytics/{source}/summary [get]
+// @Security BearerAuth
+func (h *PivotHandler) Summary(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	metric := domain.MetricNet
+	if value := c.QueryParam("metric"); value != "" {
+		metric = domain.Metric(value)
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = defaultSummaryLimit
+	}
+
+	result, err := analytics.PivotService.Summarize(c.Request().Context(), &domain.PivotQuery{
+		TenantID: tenantID,
+		Source:   c.Param("source"),
+		GroupBy:  domain.Dimension(c.QueryParam("group_by")),
+		Metric:   metric,
+		From:     from,
+		To:       to,
+		Limit:    limit,
+	})
+	if err != nil {
+		return pivotError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// parseDateRange reads the from and to query dates; to defaults to today and
+// from to defaultSummaryDays before it
+func parseDateRange(c echo.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if value := c.QueryParam("to"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to date, expected YYYY-MM-DD")
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -defaultSummaryDays)
+	if value := c.QueryParam("from"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from date, expected YYYY-MM-DD")
+		}
+		from = parsed
+	}
+
+	return from, to, nil
+}
+
+// pivotError maps analytics domain errors to HTTP responses
+func pivotError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrUnknownSource):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidDimension), errors.Is(err, domain.ErrInvalidMetric), errors.Is(err, domain.ErrInvalidPeriod):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
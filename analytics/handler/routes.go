@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"github.com/aceextension/core/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes registers all analytics routes
+func RegisterRoutes(e *echo.Echo) {
+	pivotHandler := NewPivotHandler()
+	anomalyHandler := NewAnomalyHandler()
+	dashboardHandler := NewDashboardHandler()
+
+	v1 := e.Group("/api/v1/analytics")
+	v1.Use(middleware.TenantMiddleware)
+
+	v1.GET("/sources", pivotHandler.ListSources)
+	v1.GET("/:source/summary", pivotHandler.Summary)
+
+	v1.GET("/alerts", anomalyHandler.ListAlerts)
+	v1.GET("/alerts/:id", anomalyHandler.GetAlert)
+	v1.POST("/alerts/:id/acknowledge", anomalyHandler.AcknowledgeAlert)
+	v1.GET("/baselines", anomalyHandler.ListBaselines)
+	v1.POST("/anomalies/evaluate", anomalyHandler.Evaluate)
+
+	dashboards := e.Group("/api/v1/dashboards")
+	dashboards.Use(middleware.TenantMiddleware)
+
+	dashboards.GET("", dashboardHandler.ListDashboards)
+	dashboards.POST("", dashboardHandler.CreateDashboard)
+	dashboards.GET("/menu", dashboardHandler.GetMenu)
+	dashboards.GET("/defaults", dashboardHandler.ListRoleDefaults)
+	dashboards.PUT("/defaults/:role", dashboardHandler.SetRoleDefault)
+	dashboards.DELETE("/defaults/:role", dashboardHandler.DeleteRoleDefault)
+	dashboards.GET("/:id", dashboardHandler.GetDashboard)
+	dashboards.PUT("/:id", dashboardHandler.UpdateDashboard)
+	dashboards.DELETE("/:id", dashboardHandler.DeleteDashboard)
+	dashboards.POST("/:id/customize", dashboardHandler.CustomizeDashboard)
+	dashboards.GET("/:id/data", dashboardHandler.GetDashboardData)
+}
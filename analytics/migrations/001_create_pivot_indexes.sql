@@ -0,0 +1,3 @@
+-- Summary queries filter each source's transactions by tenant and period
+
+CREATE INDEX IF NOT EXISTS idx_consignment_bills_period ON consignment_bills(tenant_id, created_at);
@@ -0,0 +1,74 @@
+-- ============================================================================
+-- METRIC BASELINES
+-- Rolling mean and standard deviation of each watched daily metric, per tenant
+-- and subject (e.g. cashier). Recomputed by the daily evaluation job.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS metric_baselines (
+    tenant_id UUID NOT NULL,
+    metric_code VARCHAR(50) NOT NULL,
+    subject_key VARCHAR(100) NOT NULL DEFAULT '', -- Empty for the whole business
+    subject_name VARCHAR(255) NOT NULL DEFAULT '',
+    mean DECIMAL(18, 4) NOT NULL,
+    std_dev DECIMAL(18, 4) NOT NULL,
+    samples INTEGER NOT NULL,
+    window_from DATE NOT NULL,
+    window_to DATE NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, metric_code, subject_key),
+    CONSTRAINT fk_metric_baseline_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+-- ============================================================================
+-- METRIC ALERTS
+-- One row per unusual day of a metric and subject; owners are notified once
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS metric_alerts (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    metric_code VARCHAR(50) NOT NULL,
+    metric_name VARCHAR(100) NOT NULL,
+    subject_key VARCHAR(100) NOT NULL DEFAULT '',
+    subject_name VARCHAR(255) NOT NULL DEFAULT '',
+    day DATE NOT NULL,
+    value DECIMAL(18, 4) NOT NULL,
+    mean DECIMAL(18, 4) NOT NULL,
+    std_dev DECIMAL(18, 4) NOT NULL,
+    z_score DECIMAL(8, 2) NOT NULL,
+    link VARCHAR(500) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    notified_count INTEGER NOT NULL DEFAULT 0,
+    acknowledged_by UUID,
+    acknowledged_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT fk_metric_alert_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT uq_metric_alert_day UNIQUE (tenant_id, metric_code, subject_key, day),
+    CONSTRAINT chk_metric_alert_status CHECK (status IN ('open', 'acknowledged'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_metric_alerts_status
+    ON metric_alerts(tenant_id, status, day DESC);
+
+-- ============================================================================
+-- ROW LEVEL SECURITY
+-- ============================================================================
+
+ALTER TABLE metric_baselines ENABLE ROW LEVEL SECURITY;
+ALTER TABLE metric_alerts ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON metric_baselines;
+CREATE POLICY tenant_isolation ON metric_baselines
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+DROP POLICY IF EXISTS tenant_isolation ON metric_alerts;
+CREATE POLICY tenant_isolation ON metric_alerts
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
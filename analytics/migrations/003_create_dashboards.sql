@@ -0,0 +1,69 @@
+-- ============================================================================
+-- DASHBOARDS
+-- Saved sets of analytics widgets arranged in a multi-level menu. Shared
+-- dashboards are seen by the listed roles (all roles when empty); personal
+-- ones by their owner, who may keep a customized copy of a shared one.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS dashboards (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    menu VARCHAR(255) NOT NULL DEFAULT '', -- Menu path, e.g. 'Reports/Sales'
+    position INTEGER NOT NULL DEFAULT 0,
+    roles TEXT[] NOT NULL DEFAULT '{}',
+    owner_id UUID,
+    based_on UUID,
+    widgets JSONB NOT NULL DEFAULT '[]',
+    created_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT fk_dashboard_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT fk_dashboard_owner FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_dashboard_based_on FOREIGN KEY (based_on) REFERENCES dashboards(id) ON DELETE CASCADE,
+    CONSTRAINT chk_dashboard_copy_owner CHECK (based_on IS NULL OR owner_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dashboards_tenant ON dashboards(tenant_id, menu, position);
+
+-- One customized copy of a shared dashboard per user
+CREATE UNIQUE INDEX IF NOT EXISTS uq_dashboards_copy
+    ON dashboards(tenant_id, based_on, owner_id) WHERE based_on IS NOT NULL;
+
+-- ============================================================================
+-- DASHBOARD ROLE DEFAULTS
+-- The dashboard each role lands on
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS dashboard_role_defaults (
+    tenant_id UUID NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    dashboard_id UUID NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, role),
+    CONSTRAINT fk_dashboard_default_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT fk_dashboard_default_dashboard FOREIGN KEY (dashboard_id) REFERENCES dashboards(id) ON DELETE CASCADE
+);
+
+-- ============================================================================
+-- ROW LEVEL SECURITY
+-- ============================================================================
+
+ALTER TABLE dashboards ENABLE ROW LEVEL SECURITY;
+ALTER TABLE dashboard_role_defaults ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON dashboards;
+CREATE POLICY tenant_isolation ON dashboards
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+DROP POLICY IF EXISTS tenant_isolation ON dashboard_role_defaults;
+CREATE POLICY tenant_isolation ON dashboard_role_defaults
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
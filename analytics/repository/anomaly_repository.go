@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/analytics/domain"
+	"github.com/google/uuid"
+)
+
+// AlertRecipient is a tenant owner told about alerts
+type AlertRecipient struct {
+	UserID uuid.UUID
+	Name   string
+	Email  *string
+	Phone  string
+}
+
+// AnomalyRepository defines the interface for metric baselines and alerts
+type AnomalyRepository interface {
+	// DailyValues runs a metric's DailySQL for days in [from, to)
+	DailyValues(ctx context.Context, metric *domain.AnomalyMetric, tenantID uuid.UUID, from, to time.Time) ([]domain.DailyValue, error)
+	// UserNames returns the names of the tenant's users by ID, for subjects read from the audit database
+	UserNames(ctx context.Context, tenantID uuid.UUID, ids []string) (map[string]string, error)
+
+	UpsertBaseline(ctx context.Context, baseline *domain.Baseline) error
+	ListBaselines(ctx context.Context, tenantID uuid.UUID) ([]*domain.Baseline, error)
+
+	// CreateAlert records an alert; it returns false when the day was already alerted
+	CreateAlert(ctx context.Context, alert *domain.Alert) (bool, error)
+	GetAlert(ctx context.Context, tenantID, id uuid.UUID) (*domain.Alert, error)
+	// ListAlerts returns the tenant's alerts, newest day first; an empty status lists all
+	ListAlerts(ctx context.Context, tenantID uuid.UUID, status domain.AlertStatus, limit, offset int) ([]*domain.Alert, error)
+	UpdateAlert(ctx context.Context, alert *domain.Alert) error
+
+	// ListActiveTenants returns the tenants evaluated by the daily job
+	ListActiveTenants(ctx context.Context) ([]uuid.UUID, error)
+	// ListRecipients returns the tenant's active owners
+	ListRecipients(ctx context.Context, tenantID uuid.UUID) ([]AlertRecipient, error)
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/analytics/domain"
+	"github.com/google/uuid"
+)
+
+// DashboardRepository defines the interface for saved dashboards and role defaults
+type DashboardRepository interface {
+	Create(ctx context.Context, dashboard *domain.Dashboard) error
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Dashboard, error)
+	Update(ctx context.Context, dashboard *domain.Dashboard) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+	// ListVisible returns the shared dashboards for a role and the user's personal ones, by menu and position
+	ListVisible(ctx context.Context, tenantID, userID uuid.UUID, role string) ([]*domain.Dashboard, error)
+	// GetCopy returns the user's personal copy of a shared dashboard
+	GetCopy(ctx context.Context, tenantID, basedOn, ownerID uuid.UUID) (*domain.Dashboard, error)
+
+	// Seed saves the built-in dashboards and role defaults for a tenant that has
+	// no dashboards yet; it returns false when the tenant already had some
+	Seed(ctx context.Context, tenantID uuid.UUID, dashboards []*domain.Dashboard, defaults []domain.RoleDefault) (bool, error)
+
+	ListRoleDefaults(ctx context.Context, tenantID uuid.UUID) ([]domain.RoleDefault, error)
+	// GetRoleDefault returns the dashboard a role lands on, or nil when none is set
+	GetRoleDefault(ctx context.Context, tenantID uuid.UUID, role string) (*uuid.UUID, error)
+	SetRoleDefault(ctx context.Context, tenantID uuid.UUID, def domain.RoleDefault) error
+	DeleteRoleDefault(ctx context.Context, tenantID uuid.UUID, role string) error
+}
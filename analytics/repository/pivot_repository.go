@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/analytics/domain"
+	"github.com/google/uuid"
+)
+
+// GroupTotal is one group of a source's fact rows summed
+type GroupTotal struct {
+	Key   string
+	Label string
+	Value float64
+}
+
+// DayTotal is one day of a source's fact rows summed
+type DayTotal struct {
+	Date  time.Time
+	Value float64
+}
+
+// PivotRepository defines the interface for summary queries over registered sources
+type PivotRepository interface {
+	// GroupTotals returns the largest limit groups of a dimension and the total over all of them
+	GroupTotals(ctx context.Context, source *domain.Source, query *domain.PivotQuery) ([]GroupTotal, float64, error)
+	// DayTotals returns the metric per transaction day, oldest first
+	DayTotals(ctx context.Context, source *domain.Source, tenantID uuid.UUID, metric domain.Metric, from, to time.Time) ([]DayTotal, error)
+}
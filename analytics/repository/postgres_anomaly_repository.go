@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/analytics/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresAnomalyRepository implements AnomalyRepository using PostgreSQL
+type PostgresAnomalyRepository struct{}
+
+// NewPostgresAnomalyRepository creates a new PostgreSQL anomaly repository
+func NewPostgresAnomalyRepository() *PostgresAnomalyRepository {
+	return &PostgresAnomalyRepository{}
+}
+
+const alertColumns = `
+	id, tenant_id, metric_code, metric_name, subject_key, subject_name, day, value::float8,
+	mean::float8, std_dev::float8, z_score::float8, link, status, notified_count,
+	acknowledged_by, acknowledged_at, created_at`
+
+// DailyValues runs a metric's DailySQL on the main or audit database
+func (r *PostgresAnomalyRepository) DailyValues(ctx context.Context, metric *domain.AnomalyMetric, tenantID uuid.UUID, from, to time.Time) ([]domain.DailyValue, error) {
+	var pool db.QueryExecutor = db.MainPool
+	if metric.AuditDB {
+		pool = db.AuditPool
+	}
+
+	query := `
+		SELECT day::date, COALESCE(subject_id::text, ''), COALESCE(subject_name, ''), COALESCE(value, 0)::float8
+		FROM (` + metric.DailySQL + `) m
+	`
+
+	rows, err := pool.Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metric %s: %w", metric.Code, err)
+	}
+	defer rows.Close()
+
+	values := []domain.DailyValue{}
+	for rows.Next() {
+		var v domain.DailyValue
+		if err := rows.Scan(&v.Day, &v.SubjectKey, &v.SubjectName, &v.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan metric %s: %w", metric.Code, err)
+		}
+		values = append(values, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return values, nil
+}
+
+// UserNames returns the names of the tenant's users by ID
+func (r *PostgresAnomalyRepository) UserNames(ctx context.Context, tenantID uuid.UUID, ids []string) (map[string]string, error) {
+	names := make(map[string]string)
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	query := `
+		SELECT u.id::text, u.name
+		FROM users u
+		JOIN tenant_memberships m ON m.user_id = u.id AND m.tenant_id = $1
+		WHERE u.id::text = ANY($2)
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user names: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan user name: %w", err)
+		}
+		names[id] = name
+	}
+
+	return names, rows.Err()
+}
+
+// UpsertBaseline saves a metric's latest baseline for a subject
+func (r *PostgresAnomalyRepository) UpsertBaseline(ctx context.Context, b *domain.Baseline) error {
+	query := `
+		INSERT INTO metric_baselines (tenant_id, metric_code, subject_key, subject_name, mean, std_dev, samples, window_from, window_to, computed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (tenant_id, metric_code, subject_key) DO UPDATE
+		SET subject_name = EXCLUDED.subject_name, mean = EXCLUDED.mean, std_dev = EXCLUDED.std_dev,
+		    samples = EXCLUDED.samples, window_from = EXCLUDED.window_from, window_to = EXCLUDED.window_to,
+		    computed_at = EXCLUDED.computed_at
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		b.TenantID, b.MetricCode, b.SubjectKey, b.SubjectName, b.Mean, b.StdDev, b.Samples,
+		b.WindowFrom, b.WindowTo, b.ComputedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save baseline: %w", err)
+	}
+
+	return nil
+}
+
+// ListBaselines retrieves the tenant's baselines by metric and subject
+func (r *PostgresAnomalyRepository) ListBaselines(ctx context.Context, tenantID uuid.UUID) ([]*domain.Baseline, error) {
+	query := `
+		SELECT tenant_id, metric_code, subject_key, subject_name, mean::float8, std_dev::float8, samples,
+		       window_from, window_to, computed_at
+		FROM metric_baselines
+		WHERE tenant_id = $1
+		ORDER BY metric_code, subject_name, subject_key
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query baselines: %w", err)
+	}
+	defer rows.Close()
+
+	baselines := []*domain.Baseline{}
+	for rows.Next() {
+		var b domain.Baseline
+		err := rows.Scan(&b.TenantID, &b.MetricCode, &b.SubjectKey, &b.SubjectName, &b.Mean, &b.StdDev, &b.Samples,
+			&b.WindowFrom, &b.WindowTo, &b.ComputedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan baseline: %w", err)
+		}
+		baselines = append(baselines, &b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return baselines, nil
+}
+
+// CreateAlert records an alert unless the day is already alerted
+func (r *PostgresAnomalyRepository) CreateAlert(ctx context.Context, a *domain.Alert) (bool, error) {
+	query := `
+		INSERT INTO metric_alerts (` + alertColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		ON CONFLICT (tenant_id, metric_code, subject_key, day) DO NOTHING
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		a.ID, a.TenantID, a.MetricCode, a.MetricName, a.SubjectKey, a.SubjectName, a.Day, a.Value,
+		a.Mean, a.StdDev, a.ZScore, a.Link, a.Status, a.NotifiedCount,
+		a.AcknowledgedBy, a.AcknowledgedAt, a.CreatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create alert: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetAlert retrieves an alert of the tenant
+func (r *PostgresAnomalyRepository) GetAlert(ctx context.Context, tenantID, id uuid.UUID) (*domain.Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM metric_alerts WHERE id = $1 AND tenant_id = $2`
+	return r.scanAlert(db.MainPool.QueryRow(ctx, query, id, tenantID))
+}
+
+// ListAlerts retrieves the tenant's alerts, newest day first
+func (r *PostgresAnomalyRepository) ListAlerts(ctx context.Context, tenantID uuid.UUID, status domain.AlertStatus, limit, offset int) ([]*domain.Alert, error) {
+	query := `
+		SELECT ` + alertColumns + `
+		FROM metric_alerts
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY day DESC, created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, string(status), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []*domain.Alert{}
+	for rows.Next() {
+		alert, err := r.scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// UpdateAlert saves an alert's review and notification count
+func (r *PostgresAnomalyRepository) UpdateAlert(ctx context.Context, a *domain.Alert) error {
+	query := `
+		UPDATE metric_alerts
+		SET status = $1, notified_count = $2, acknowledged_by = $3, acknowledged_at = $4
+		WHERE id = $5 AND tenant_id = $6
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query, a.Status, a.NotifiedCount, a.AcknowledgedBy, a.AcknowledgedAt, a.ID, a.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to update alert: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAlertNotFound
+	}
+
+	return nil
+}
+
+// ListActiveTenants returns the active tenants
+func (r *PostgresAnomalyRepository) ListActiveTenants(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := db.MainPool.Query(ctx, `SELECT id FROM tenants WHERE is_active = true ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, id)
+	}
+
+	return tenants, rows.Err()
+}
+
+// ListRecipients returns the tenant's active owners
+func (r *PostgresAnomalyRepository) ListRecipients(ctx context.Context, tenantID uuid.UUID) ([]AlertRecipient, error) {
+	query := `
+		SELECT u.id, u.name, u.email, u.phone
+		FROM tenant_memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.tenant_id = $1 AND m.role = 'owner' AND m.is_active = true AND u.is_active = true
+		ORDER BY u.name
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert recipients: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := []AlertRecipient{}
+	for rows.Next() {
+		var rcpt AlertRecipient
+		if err := rows.Scan(&rcpt.UserID, &rcpt.Name, &rcpt.Email, &rcpt.Phone); err != nil {
+			return nil, fmt.Errorf("failed to scan alert recipient: %w", err)
+		}
+		recipients = append(recipients, rcpt)
+	}
+
+	return recipients, rows.Err()
+}
+
+// scanAlert scans a single alert row
+func (r *PostgresAnomalyRepository) scanAlert(row pgx.Row) (*domain.Alert, error) {
+	var a domain.Alert
+	err := row.Scan(
+		&a.ID, &a.TenantID, &a.MetricCode, &a.MetricName, &a.SubjectKey, &a.SubjectName, &a.Day, &a.Value,
+		&a.Mean, &a.StdDev, &a.ZScore, &a.Link, &a.Status, &a.NotifiedCount,
+		&a.AcknowledgedBy, &a.AcknowledgedAt, &a.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAlertNotFound
+		}
+		return nil, fmt.Errorf("failed to scan alert: %w", err)
+	}
+	return &a, nil
+}
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/analytics/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresDashboardRepository implements DashboardRepository using PostgreSQL
+type PostgresDashboardRepository struct{}
+
+// NewPostgresDashboardRepository creates a new PostgreSQL dashboard repository
+func NewPostgresDashboardRepository() *PostgresDashboardRepository {
+	return &PostgresDashboardRepository{}
+}
+
+const dashboardColumns = `
+	id, tenant_id, name, menu, position, roles, owner_id, based_on, widgets,
+	created_by, created_at, updated_at`
+
+const insertDashboardSQL = `
+	INSERT INTO dashboards (` + dashboardColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+// Create saves a new dashboard
+func (r *PostgresDashboardRepository) Create(ctx context.Context, d *domain.Dashboard) error {
+	args, err := dashboardArgs(d)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.MainPool.Exec(ctx, insertDashboardSQL, args...); err != nil {
+		return fmt.Errorf("failed to create dashboard: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a dashboard of the tenant
+func (r *PostgresDashboardRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Dashboard, error) {
+	query := `SELECT ` + dashboardColumns + ` FROM dashboards WHERE id = $1 AND tenant_id = $2`
+	return r.scanDashboard(db.MainPool.QueryRow(ctx, query, id, tenantID))
+}
+
+// Update saves a dashboard's name, menu placement, roles and widgets
+func (r *PostgresDashboardRepository) Update(ctx context.Context, d *domain.Dashboard) error {
+	widgetsJSON, err := json.Marshal(d.Widgets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal widgets: %w", err)
+	}
+
+	query := `
+		UPDATE dashboards
+		SET name = $3, menu = $4, position = $5, roles = $6, widgets = $7, updated_at = $8
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query, d.ID, d.TenantID, d.Name, d.Menu, d.Position, d.Roles, widgetsJSON, d.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update dashboard: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDashboardNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a dashboard; personal copies of it and role defaults pointing at it go with it
+func (r *PostgresDashboardRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := db.MainPool.Exec(ctx, `DELETE FROM dashboards WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete dashboard: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDashboardNotFound
+	}
+
+	return nil
+}
+
+// ListVisible retrieves the shared dashboards for a role and the user's personal ones
+func (r *PostgresDashboardRepository) ListVisible(ctx context.Context, tenantID, userID uuid.UUID, role string) ([]*domain.Dashboard, error) {
+	query := `
+		SELECT ` + dashboardColumns + `
+		FROM dashboards
+		WHERE tenant_id = $1
+		  AND (owner_id = $2 OR (owner_id IS NULL AND (cardinality(roles) = 0 OR $3 = ANY(roles))))
+		ORDER BY menu, position, name
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, userID, role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dashboards: %w", err)
+	}
+	defer rows.Close()
+
+	dashboards := []*domain.Dashboard{}
+	for rows.Next() {
+		dashboard, err := r.scanDashboard(rows)
+		if err != nil {
+			return nil, err
+		}
+		dashboards = append(dashboards, dashboard)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return dashboards, nil
+}
+
+// GetCopy retrieves a user's personal copy of a shared dashboard
+func (r *PostgresDashboardRepository) GetCopy(ctx context.Context, tenantID, basedOn, ownerID uuid.UUID) (*domain.Dashboard, error) {
+	query := `SELECT ` + dashboardColumns + ` FROM dashboards WHERE tenant_id = $1 AND based_on = $2 AND owner_id = $3`
+	return r.scanDashboard(db.MainPool.QueryRow(ctx, query, tenantID, basedOn, ownerID))
+}
+
+// Seed saves the built-in dashboards for a tenant without any. The tenant row is
+// locked so concurrent first requests seed once.
+func (r *PostgresDashboardRepository) Seed(ctx context.Context, tenantID uuid.UUID, dashboards []*domain.Dashboard, defaults []domain.RoleDefault) (bool, error) {
+	seeded := false
+	err := db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM tenants WHERE id = $1 FOR UPDATE`, tenantID); err != nil {
+			return fmt.Errorf("failed to lock tenant: %w", err)
+		}
+
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM dashboards WHERE tenant_id = $1)`, tenantID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check dashboards: %w", err)
+		}
+		if exists {
+			return nil
+		}
+
+		for _, d := range dashboards {
+			args, err := dashboardArgs(d)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, insertDashboardSQL, args...); err != nil {
+				return fmt.Errorf("failed to create dashboard: %w", err)
+			}
+		}
+		for _, def := range defaults {
+			if _, err := tx.Exec(ctx, upsertRoleDefaultSQL, tenantID, def.Role, def.DashboardID); err != nil {
+				return fmt.Errorf("failed to set role default: %w", err)
+			}
+		}
+
+		seeded = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return seeded, nil
+}
+
+// ListRoleDefaults retrieves the tenant's role defaults by role
+func (r *PostgresDashboardRepository) ListRoleDefaults(ctx context.Context, tenantID uuid.UUID) ([]domain.RoleDefault, error) {
+	rows, err := db.MainPool.Query(ctx, `SELECT role, dashboard_id FROM dashboard_role_defaults WHERE tenant_id = $1 ORDER BY role`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query role defaults: %w", err)
+	}
+	defer rows.Close()
+
+	defaults := []domain.RoleDefault{}
+	for rows.Next() {
+		var def domain.RoleDefault
+		if err := rows.Scan(&def.Role, &def.DashboardID); err != nil {
+			return nil, fmt.Errorf("failed to scan role default: %w", err)
+		}
+		defaults = append(defaults, def)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return defaults, nil
+}
+
+// GetRoleDefault retrieves the dashboard a role lands on
+func (r *PostgresDashboardRepository) GetRoleDefault(ctx context.Context, tenantID uuid.UUID, role string) (*uuid.UUID, error) {
+	var id uuid.UUID
+	err := db.MainPool.QueryRow(ctx, `SELECT dashboard_id FROM dashboard_role_defaults WHERE tenant_id = $1 AND role = $2`, tenantID, role).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get role default: %w", err)
+	}
+
+	return &id, nil
+}
+
+const upsertRoleDefaultSQL = `
+	INSERT INTO dashboard_role_defaults (tenant_id, role, dashboard_id, updated_at)
+	VALUES ($1, $2, $3, NOW())
+	ON CONFLICT (tenant_id, role) DO UPDATE
+	SET dashboard_id = EXCLUDED.dashboard_id, updated_at = EXCLUDED.updated_at`
+
+// SetRoleDefault sets or replaces the dashboard a role lands on
+func (r *PostgresDashboardRepository) SetRoleDefault(ctx context.Context, tenantID uuid.UUID, def domain.RoleDefault) error {
+	if _, err := db.MainPool.Exec(ctx, upsertRoleDefaultSQL, tenantID, def.Role, def.DashboardID); err != nil {
+		return fmt.Errorf("failed to set role default: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRoleDefault removes a role's default dashboard
+func (r *PostgresDashboardRepository) DeleteRoleDefault(ctx context.Context, tenantID uuid.UUID, role string) error {
+	tag, err := db.MainPool.Exec(ctx, `DELETE FROM dashboard_role_defaults WHERE tenant_id = $1 AND role = $2`, tenantID, role)
+	if err != nil {
+		return fmt.Errorf("failed to delete role default: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDashboardNotFound
+	}
+
+	return nil
+}
+
+// dashboardArgs returns a dashboard's values in dashboardColumns order
+func dashboardArgs(d *domain.Dashboard) ([]interface{}, error) {
+	widgetsJSON, err := json.Marshal(d.Widgets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal widgets: %w", err)
+	}
+
+	return []interface{}{
+		d.ID, d.TenantID, d.Name, d.Menu, d.Position, d.Roles, d.OwnerID, d.BasedOn, widgetsJSON,
+		d.CreatedBy, d.CreatedAt, d.UpdatedAt,
+	}, nil
+}
+
+// scanDashboard scans a single dashboard row
+func (r *PostgresDashboardRepository) scanDashboard(row pgx.Row) (*domain.Dashboard, error) {
+	var d domain.Dashboard
+	var widgetsJSON []byte
+
+	err := row.Scan(
+		&d.ID, &d.TenantID, &d.Name, &d.Menu, &d.Position, &d.Roles, &d.OwnerID, &d.BasedOn, &widgetsJSON,
+		&d.CreatedBy, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDashboardNotFound
+		}
+		return nil, fmt.Errorf("failed to scan dashboard: %w", err)
+	}
+
+	if len(widgetsJSON) > 0 {
+		if err := json.Unmarshal(widgetsJSON, &d.Widgets); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal widgets: %w", err)
+		}
+	}
+	if d.Widgets == nil {
+		d.Widgets = []domain.Widget{}
+	}
+	if d.Roles == nil {
+		d.Roles = []string{}
+	}
+
+	return &d, nil
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aceextension/analytics/domain"
+	"github.com/aceextension/analytics/repository"
+	"github.com/aceextension/core/events"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/notification"
+	notificationDomain "github.com/aceextension/notification/domain"
+	notificationService "github.com/aceextension/notification/service"
+	"github.com/google/uuid"
+)
+
+// anomalyService implements AnomalyService
+type anomalyService struct {
+	repo      repository.AnomalyRepository
+	mu        sync.RWMutex
+	metrics   []domain.AnomalyMetric
+	threshold float64
+	linkBase  string
+}
+
+// NewAnomalyService creates a new anomaly service with no metrics registered
+func NewAnomalyService(repo repository.AnomalyRepository) AnomalyService {
+	return &anomalyService{
+		repo:      repo,
+		threshold: domain.DefaultAnomalyThreshold,
+	}
+}
+
+// RegisterMetric adds a metric, replacing one with the same code
+func (s *anomalyService) RegisterMetric(metric domain.AnomalyMetric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.metrics {
+		if s.metrics[i].Code == metric.Code {
+			s.metrics[i] = metric
+			return
+		}
+	}
+	s.metrics = append(s.metrics, metric)
+}
+
+// SetThreshold sets the alert threshold in standard deviations
+func (s *anomalyService) SetThreshold(threshold float64) {
+	s.threshold = threshold
+}
+
+// SetLinkBase sets the origin alert links are made absolute with
+func (s *anomalyService) SetLinkBase(base string) {
+	s.linkBase = strings.TrimRight(base, "/")
+}
+
+// Evaluate compares day with the BaselineDays before it for every metric and
+// subject. A metric that fails to load is logged and skipped so the others still run.
+func (s *anomalyService) Evaluate(ctx context.Context, tenantID uuid.UUID, day time.Time) (*domain.AnomalyRun, error) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	run := &domain.AnomalyRun{Day: day.Format("2006-01-02"), Alerts: []*domain.Alert{}}
+
+	s.mu.RLock()
+	metrics := append([]domain.AnomalyMetric(nil), s.metrics...)
+	s.mu.RUnlock()
+
+	var errs []error
+	for i := range metrics {
+		alerts, baselines, err := s.evaluateMetric(ctx, tenantID, &metrics[i], day)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Anomaly: metric %s for tenant %s: %v", metrics[i].Code, tenantID, err))
+			errs = append(errs, err)
+			continue
+		}
+		run.Baselines += baselines
+		run.Alerts = append(run.Alerts, alerts...)
+	}
+
+	return run, errors.Join(errs...)
+}
+
+// subjectSeries is one subject's daily values within the window
+type subjectSeries struct {
+	name  string
+	first time.Time
+	days  map[string]float64
+}
+
+// evaluateMetric saves each subject's baseline and records and sends new alerts
+func (s *anomalyService) evaluateMetric(ctx context.Context, tenantID uuid.UUID, metric *domain.AnomalyMetric, day time.Time) ([]*domain.Alert, int, error) {
+	from := day.AddDate(0, 0, -domain.BaselineDays)
+	values, err := s.repo.DailyValues(ctx, metric, tenantID, from, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	series := make(map[string]*subjectSeries)
+	var keys []string
+	for _, v := range values {
+		ss, ok := series[v.SubjectKey]
+		if !ok {
+			ss = &subjectSeries{name: v.SubjectName, first: v.Day, days: make(map[string]float64)}
+			series[v.SubjectKey] = ss
+			keys = append(keys, v.SubjectKey)
+		}
+		if v.Day.Before(ss.first) {
+			ss.first = v.Day
+		}
+		ss.days[v.Day.Format("2006-01-02")] += v.Value
+	}
+
+	if metric.AuditDB {
+		// The audit database only has user IDs
+		names, err := s.repo.UserNames(ctx, tenantID, keys)
+		if err != nil {
+			return nil, 0, err
+		}
+		for key, name := range names {
+			series[key].name = name
+		}
+	}
+
+	alerts := []*domain.Alert{}
+	dayKey := day.Format("2006-01-02")
+	for _, key := range keys {
+		ss := series[key]
+
+		// History is the window before day; a zero-filled metric counts the
+		// empty days since the subject first appeared
+		var history []float64
+		for d := from; d.Before(day); d = d.AddDate(0, 0, 1) {
+			value, ok := ss.days[d.Format("2006-01-02")]
+			if !ok && (!metric.ZeroFill || d.Before(ss.first)) {
+				continue
+			}
+			history = append(history, value)
+		}
+
+		baseline := domain.NewBaseline(tenantID, metric.Code, key, ss.name, history, from, day.AddDate(0, 0, -1))
+		if err := s.repo.UpsertBaseline(ctx, baseline); err != nil {
+			return nil, 0, err
+		}
+
+		value, ok := ss.days[dayKey]
+		if !ok && !metric.ZeroFill {
+			continue
+		}
+		z, anomalous := baseline.Score(value, s.threshold, metric.MinDeviation, metric.Direction)
+		if !anomalous {
+			continue
+		}
+
+		alert := &domain.Alert{
+			ID:          uuid.New(),
+			TenantID:    tenantID,
+			MetricCode:  metric.Code,
+			MetricName:  metric.Name,
+			SubjectKey:  key,
+			SubjectName: ss.name,
+			Day:         day,
+			Value:       value,
+			Mean:        baseline.Mean,
+			StdDev:      baseline.StdDev,
+			ZScore:      z,
+			Link:        alertLink(metric.Link, day, key),
+			Status:      domain.AlertOpen,
+			CreatedAt:   time.Now(),
+		}
+		created, err := s.repo.CreateAlert(ctx, alert)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !created {
+			continue // Alerted by an earlier run
+		}
+
+		s.notify(ctx, alert)
+		events.Publish(events.New(alert.TenantID, domain.TopicAlerts, domain.EventAlertRaised, alert).ForRoles(domain.AlertViewerRoles...))
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, len(keys), nil
+}
+
+// notify tells the tenant's owners about an alert by email, or SMS when they
+// have no email. Failures are logged; the alert stays listed either way.
+func (s *anomalyService) notify(ctx context.Context, alert *domain.Alert) {
+	if notification.Service == nil {
+		return
+	}
+
+	recipients, err := s.repo.ListRecipients(ctx, alert.TenantID)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("Anomaly: failed to load recipients for tenant %s: %v", alert.TenantID, err))
+		return
+	}
+
+	content := alertMessage(alert, s.linkBase)
+	referenceType := "METRIC_ALERT"
+	for _, rcpt := range recipients {
+		channel, to := notificationDomain.ChannelSMS, rcpt.Phone
+		if rcpt.Email != nil && *rcpt.Email != "" {
+			channel, to = notificationDomain.ChannelEmail, *rcpt.Email
+		}
+		if to == "" {
+			continue
+		}
+
+		_, err := notification.Service.Send(ctx, notificationService.SendRequest{
+			TenantID:      alert.TenantID,
+			UserID:        &rcpt.UserID,
+			Channel:       channel,
+			Recipient:     to,
+			Content:       content,
+			Priority:      notificationDomain.PriorityLow,
+			ReferenceType: &referenceType,
+			ReferenceID:   &alert.ID,
+		})
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Anomaly: failed to notify %s of alert %s: %v", to, alert.ID, err))
+			continue
+		}
+		alert.NotifiedCount++
+	}
+
+	if alert.NotifiedCount > 0 {
+		if err := s.repo.UpdateAlert(ctx, alert); err != nil {
+			logger.Log.Error(fmt.Sprintf("Anomaly: failed to save alert %s: %v", alert.ID, err))
+		}
+	}
+}
+
+// RunScheduled evaluates yesterday for each active tenant; one tenant's failure does not stop the rest
+func (s *anomalyService) RunScheduled(ctx context.Context) error {
+	tenants, err := s.repo.ListActiveTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	var errs []error
+	for _, tenantID := range tenants {
+		if _, err := s.Evaluate(ctx, tenantID, yesterday); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ListBaselines retrieves the tenant's current baselines
+func (s *anomalyService) ListBaselines(ctx context.Context, tenantID uuid.UUID) ([]*domain.Baseline, error) {
+	return s.repo.ListBaselines(ctx, tenantID)
+}
+
+// ListAlerts retrieves the tenant's alerts
+func (s *anomalyService) ListAlerts(ctx context.Context, tenantID uuid.UUID, status domain.AlertStatus, limit, offset int) ([]*domain.Alert, error) {
+	return s.repo.ListAlerts(ctx, tenantID, status, limit, offset)
+}
+
+// GetAlert retrieves an alert
+func (s *anomalyService) GetAlert(ctx context.Context, tenantID, id uuid.UUID) (*domain.Alert, error) {
+	return s.repo.GetAlert(ctx, tenantID, id)
+}
+
+// Acknowledge marks an alert as seen
+func (s *anomalyService) Acknowledge(ctx context.Context, tenantID, id, userID uuid.UUID) (*domain.Alert, error) {
+	alert, err := s.repo.GetAlert(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	alert.Acknowledge(userID)
+	if err := s.repo.UpdateAlert(ctx, alert); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// alertLink fills a metric's link template for the alerted day and subject
+func alertLink(template string, day time.Time, subject string) string {
+	date := day.Format("2006-01-02")
+	return strings.NewReplacer("{from}", date, "{to}", date, "{subject}", subject).Replace(template)
+}
+
+// alertMessage is the notification text of an alert
+func alertMessage(alert *domain.Alert, linkBase string) string {
+	subject := ""
+	if alert.SubjectName != "" {
+		subject = " for " + alert.SubjectName
+	} else if alert.SubjectKey != "" {
+		subject = " for " + alert.SubjectKey
+	}
+
+	direction := "higher"
+	if alert.ZScore < 0 {
+		direction = "lower"
+	}
+
+	message := fmt.Sprintf("Unusual %s%s on %s: %.2f, %s than the usual %.2f.",
+		alert.MetricName, subject, alert.Day.Format("2006-01-02"), alert.Value, direction, alert.Mean)
+	if alert.Link != "" {
+		message += " Details: " + linkBase + alert.Link
+	}
+	return message
+}
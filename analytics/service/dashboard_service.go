@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aceextension/analytics/domain"
+	"github.com/aceextension/analytics/repository"
+	"github.com/google/uuid"
+)
+
+// dashboardService implements DashboardService
+type dashboardService struct {
+	repo      repository.DashboardRepository
+	pivot     PivotService
+	anomaly   AnomalyService
+	mu        sync.RWMutex
+	templates []domain.DashboardTemplate
+	seeded    sync.Map // Tenants known to have dashboards
+}
+
+// NewDashboardService creates a new dashboard service with no templates registered.
+// Widgets read their data through the pivot and anomaly services.
+func NewDashboardService(repo repository.DashboardRepository, pivot PivotService, anomaly AnomalyService) DashboardService {
+	return &dashboardService{
+		repo:    repo,
+		pivot:   pivot,
+		anomaly: anomaly,
+	}
+}
+
+// RegisterTemplate adds a built-in dashboard, replacing one with the same menu and name
+func (s *dashboardService) RegisterTemplate(template domain.DashboardTemplate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.templates {
+		if s.templates[i].Menu == template.Menu && s.templates[i].Name == template.Name {
+			s.templates[i] = template
+			return
+		}
+	}
+	s.templates = append(s.templates, template)
+}
+
+// ensureSeeded gives a tenant without dashboards the built-in ones. Widgets of
+// sources no module registered are left out.
+func (s *dashboardService) ensureSeeded(ctx context.Context, tenantID uuid.UUID) error {
+	if _, ok := s.seeded.Load(tenantID); ok {
+		return nil
+	}
+
+	s.mu.RLock()
+	templates := append([]domain.DashboardTemplate(nil), s.templates...)
+	s.mu.RUnlock()
+
+	registered := make(map[string]bool)
+	for _, source := range s.pivot.Sources() {
+		registered[source.Name] = true
+	}
+
+	dashboards := make([]*domain.Dashboard, 0, len(templates))
+	defaults := []domain.RoleDefault{}
+	for i, template := range templates {
+		widgets := []domain.Widget{}
+		for _, widget := range template.Widgets {
+			if widget.Source == "" || registered[widget.Source] {
+				widgets = append(widgets, widget)
+			}
+		}
+		if len(widgets) == 0 {
+			continue
+		}
+
+		dashboard := domain.NewDashboard(tenantID, template.Name, template.Menu, template.Roles, widgets, nil)
+		dashboard.Position = i
+		if err := dashboard.Validate(); err != nil {
+			return err
+		}
+		dashboards = append(dashboards, dashboard)
+		for _, role := range template.DefaultFor {
+			defaults = append(defaults, domain.RoleDefault{Role: role, DashboardID: dashboard.ID})
+		}
+	}
+
+	if _, err := s.repo.Seed(ctx, tenantID, dashboards, defaults); err != nil {
+		return err
+	}
+	s.seeded.Store(tenantID, true)
+	return nil
+}
+
+// Menu arranges the user's dashboards by menu path. A personal copy takes the
+// place of the shared dashboard it customizes. The user lands on their role's
+// default (or their copy of it), else the first dashboard in the menu.
+func (s *dashboardService) Menu(ctx context.Context, tenantID, userID uuid.UUID, role string) (*domain.DashboardMenu, error) {
+	dashboards, err := s.List(ctx, tenantID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	copies := make(map[uuid.UUID]uuid.UUID)
+	for _, d := range dashboards {
+		if d.BasedOn != nil {
+			copies[*d.BasedOn] = d.ID
+		}
+	}
+
+	menu := &domain.DashboardMenu{}
+	root := &domain.MenuNode{Dashboards: []domain.MenuEntry{}, Children: []*domain.MenuNode{}}
+	for _, d := range dashboards {
+		if _, replaced := copies[d.ID]; replaced {
+			continue
+		}
+		node := root
+		for _, level := range domain.MenuLevels(d.Menu) {
+			node = menuChild(node, level)
+		}
+		node.Dashboards = append(node.Dashboards, domain.MenuEntry{ID: d.ID, Name: d.Name, Personal: d.Personal()})
+		if menu.Home == nil {
+			id := d.ID
+			menu.Home = &id
+		}
+	}
+	menu.Dashboards, menu.Children = root.Dashboards, root.Children
+
+	home, err := s.repo.GetRoleDefault(ctx, tenantID, role)
+	if err != nil {
+		return nil, err
+	}
+	if home != nil {
+		for _, d := range dashboards {
+			if d.ID == *home {
+				if copyID, ok := copies[d.ID]; ok {
+					home = &copyID
+				}
+				menu.Home = home
+				break
+			}
+		}
+	}
+
+	return menu, nil
+}
+
+// menuChild returns the node's child of a name, adding it in order of first use
+func menuChild(node *domain.MenuNode, name string) *domain.MenuNode {
+	for _, child := range node.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	child := &domain.MenuNode{Name: name, Dashboards: []domain.MenuEntry{}, Children: []*domain.MenuNode{}}
+	node.Children = append(node.Children, child)
+	return child
+}
+
+// List retrieves the dashboards the user can see
+func (s *dashboardService) List(ctx context.Context, tenantID, userID uuid.UUID, role string) ([]*domain.Dashboard, error) {
+	if err := s.ensureSeeded(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListVisible(ctx, tenantID, userID, role)
+}
+
+// Get retrieves a dashboard the user can see
+func (s *dashboardService) Get(ctx context.Context, tenantID, id, userID uuid.UUID, role string) (*domain.Dashboard, error) {
+	dashboard, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !dashboard.VisibleTo(userID, role) {
+		return nil, domain.ErrDashboardNotFound
+	}
+	return dashboard, nil
+}
+
+// Create saves a new dashboard; shared ones need a dashboard manager role
+func (s *dashboardService) Create(ctx context.Context, dashboard *domain.Dashboard, role string) error {
+	if !dashboard.Personal() && !domain.CanManageDashboards(role) {
+		return domain.ErrDashboardNotPermitted
+	}
+	if err := dashboard.Validate(); err != nil {
+		return err
+	}
+	if err := s.ensureSeeded(ctx, dashboard.TenantID); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, dashboard)
+}
+
+// Update replaces a dashboard's settings; roles only apply to shared dashboards
+func (s *dashboardService) Update(ctx context.Context, tenantID, id, userID uuid.UUID, role string, changes *domain.Dashboard) (*domain.Dashboard, error) {
+	dashboard, err := s.Get(ctx, tenantID, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !dashboard.EditableBy(userID, role) {
+		return nil, domain.ErrDashboardNotPermitted
+	}
+
+	dashboard.Name = changes.Name
+	dashboard.Menu = changes.Menu
+	dashboard.Position = changes.Position
+	dashboard.Roles = changes.Roles
+	dashboard.Widgets = changes.Widgets
+	dashboard.UpdatedAt = time.Now()
+	if err := dashboard.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, dashboard); err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+// Delete removes a dashboard. Removing a personal copy puts the shared
+// dashboard back in the user's menu.
+func (s *dashboardService) Delete(ctx context.Context, tenantID, id, userID uuid.UUID, role string) error {
+	dashboard, err := s.Get(ctx, tenantID, id, userID, role)
+	if err != nil {
+		return err
+	}
+	if !dashboard.EditableBy(userID, role) {
+		return domain.ErrDashboardNotPermitted
+	}
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// Customize returns the user's copy of a shared dashboard, making it on first use.
+// A personal dashboard is returned as it is.
+func (s *dashboardService) Customize(ctx context.Context, tenantID, id, userID uuid.UUID, role string) (*domain.Dashboard, error) {
+	dashboard, err := s.Get(ctx, tenantID, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if dashboard.Personal() {
+		return dashboard, nil
+	}
+
+	existing, err := s.repo.GetCopy(ctx, tenantID, dashboard.ID, userID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, domain.ErrDashboardNotFound) {
+		return nil, err
+	}
+
+	copied := dashboard.CopyFor(userID)
+	if err := copied.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// Data loads every widget concurrently. Each widget's period is its Days ending on day.
+func (s *dashboardService) Data(ctx context.Context, tenantID, id, userID uuid.UUID, role string, day time.Time) (*domain.DashboardData, error) {
+	dashboard, err := s.Get(ctx, tenantID, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	data := &domain.DashboardData{
+		DashboardID: dashboard.ID,
+		Day:         day.Format("2006-01-02"),
+		Widgets:     make([]domain.WidgetData, len(dashboard.Widgets)),
+	}
+
+	var wg sync.WaitGroup
+	for i := range dashboard.Widgets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data.Widgets[i] = s.loadWidget(ctx, tenantID, &dashboard.Widgets[i], day)
+		}(i)
+	}
+	wg.Wait()
+
+	return data, nil
+}
+
+// loadWidget runs one widget's query, recording a failure on the widget
+func (s *dashboardService) loadWidget(ctx context.Context, tenantID uuid.UUID, widget *domain.Widget, day time.Time) domain.WidgetData {
+	result := domain.WidgetData{Key: widget.Key, Kind: widget.Kind}
+
+	var err error
+	switch widget.Kind {
+	case domain.WidgetAlerts:
+		result.Alerts, err = s.anomaly.ListAlerts(ctx, tenantID, domain.AlertOpen, widget.Limit, 0)
+	case domain.WidgetSummary, domain.WidgetTotal:
+		query := &domain.PivotQuery{
+			TenantID: tenantID,
+			Source:   widget.Source,
+			GroupBy:  widget.GroupBy,
+			Metric:   widget.Metric,
+			From:     day.AddDate(0, 0, 1-widget.Days),
+			To:       day,
+			Limit:    widget.Limit,
+		}
+		result.From, result.To = query.From.Format("2006-01-02"), query.To.Format("2006-01-02")
+		if widget.Kind == domain.WidgetSummary {
+			result.Summary, err = s.pivot.Summarize(ctx, query)
+		} else {
+			var total float64
+			total, err = s.pivot.Total(ctx, query)
+			result.Total = &total
+		}
+	default:
+		err = domain.ErrInvalidWidget
+	}
+
+	if err != nil {
+		result.Summary, result.Total, result.Alerts = nil, nil, nil
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// ListRoleDefaults retrieves the dashboard each role lands on
+func (s *dashboardService) ListRoleDefaults(ctx context.Context, tenantID uuid.UUID) ([]domain.RoleDefault, error) {
+	if err := s.ensureSeeded(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRoleDefaults(ctx, tenantID)
+}
+
+// SetRoleDefault lands a role on a shared dashboard it can see
+func (s *dashboardService) SetRoleDefault(ctx context.Context, tenantID uuid.UUID, def domain.RoleDefault, role string) error {
+	if !domain.CanManageDashboards(role) {
+		return domain.ErrDashboardNotPermitted
+	}
+	if def.Role == "" {
+		return domain.ErrInvalidDashboard
+	}
+
+	dashboard, err := s.repo.GetByID(ctx, tenantID, def.DashboardID)
+	if err != nil {
+		return err
+	}
+	if dashboard.Personal() || !dashboard.VisibleTo(uuid.Nil, def.Role) {
+		return domain.ErrInvalidDashboard
+	}
+
+	return s.repo.SetRoleDefault(ctx, tenantID, def)
+}
+
+// DeleteRoleDefault removes a role's default; its users land on their first dashboard
+func (s *dashboardService) DeleteRoleDefault(ctx context.Context, tenantID uuid.UUID, defaultRole, role string) error {
+	if !domain.CanManageDashboards(role) {
+		return domain.ErrDashboardNotPermitted
+	}
+	return s.repo.DeleteRoleDefault(ctx, tenantID, defaultRole)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/aceextension/analytics/domain"
+	"github.com/aceextension/analytics/repository"
+	"github.com/aceextension/fiscal/utils"
+)
+
+// pivotService implements PivotService
+type pivotService struct {
+	repo    repository.PivotRepository
+	mu      sync.RWMutex
+	sources map[string]domain.Source
+}
+
+// NewPivotService creates a new pivot service with no sources registered
+func NewPivotService(repo repository.PivotRepository) PivotService {
+	return &pivotService{
+		repo:    repo,
+		sources: make(map[string]domain.Source),
+	}
+}
+
+// RegisterSource makes a source available, replacing one of the same name
+func (s *pivotService) RegisterSource(source domain.Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[source.Name] = source
+}
+
+// Sources lists the registered sources by name
+func (s *pivotService) Sources() []domain.SourceInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	metrics := []domain.Metric{domain.MetricQty, domain.MetricNet, domain.MetricVAT, domain.MetricGross}
+	infos := make([]domain.SourceInfo, 0, len(s.sources))
+	for _, source := range s.sources {
+		infos = append(infos, domain.SourceInfo{Name: source.Name, Dimensions: source.Dimensions, Metrics: metrics})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Summarize groups a source's metric by one dimension. Share is each group's
+// percent of the total over every group, not only those returned.
+func (s *pivotService) Summarize(ctx context.Context, q *domain.PivotQuery) (*domain.PivotResult, error) {
+	s.mu.RLock()
+	source, ok := s.sources[q.Source]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, domain.ErrUnknownSource
+	}
+	if !source.Supports(q.GroupBy) {
+		return nil, domain.ErrInvalidDimension
+	}
+	if !q.Metric.Valid() {
+		return nil, domain.ErrInvalidMetric
+	}
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := &domain.PivotResult{
+		Source:  source.Name,
+		GroupBy: q.GroupBy,
+		Metric:  q.Metric,
+		From:    q.From.Format("2006-01-02"),
+		To:      q.To.Format("2006-01-02"),
+	}
+
+	if q.GroupBy == domain.DimensionMonthBS {
+		days, err := s.repo.DayTotals(ctx, &source, q.TenantID, q.Metric, q.From, q.To)
+		if err != nil {
+			return nil, err
+		}
+		result.Rows = bsMonthRows(days)
+		for _, row := range result.Rows {
+			result.Total += row.Value
+		}
+	} else {
+		groups, total, err := s.repo.GroupTotals(ctx, &source, q)
+		if err != nil {
+			return nil, err
+		}
+		result.Rows = make([]domain.PivotRow, len(groups))
+		var listed float64
+		for i, g := range groups {
+			result.Rows[i] = domain.PivotRow{Key: g.Key, Label: g.Label, Value: round2(g.Value)}
+			listed += g.Value
+		}
+		result.Total = total
+		result.Others = round2(total - listed)
+	}
+
+	for i := range result.Rows {
+		if result.Total != 0 {
+			result.Rows[i].Share = round2(result.Rows[i].Value / result.Total * 100)
+		}
+	}
+	result.Total = round2(result.Total)
+
+	return result, nil
+}
+
+// Total sums a source's metric over the query period
+func (s *pivotService) Total(ctx context.Context, q *domain.PivotQuery) (float64, error) {
+	s.mu.RLock()
+	source, ok := s.sources[q.Source]
+	s.mu.RUnlock()
+	if !ok {
+		return 0, domain.ErrUnknownSource
+	}
+	if !q.Metric.Valid() {
+		return 0, domain.ErrInvalidMetric
+	}
+	if err := q.Validate(); err != nil {
+		return 0, err
+	}
+
+	days, err := s.repo.DayTotals(ctx, &source, q.TenantID, q.Metric, q.From, q.To)
+	if err != nil {
+		return 0, err
+	}
+	var total float64
+	for _, day := range days {
+		total += day.Value
+	}
+	return round2(total), nil
+}
+
+// bsMonthRows buckets day totals into Bikram Sambat months, oldest first
+func bsMonthRows(days []repository.DayTotal) []domain.PivotRow {
+	rows := []domain.PivotRow{}
+	for _, day := range days {
+		bs := utils.ADToBS(day.Date)
+		key := fmt.Sprintf("%04d-%02d", bs.Year, bs.Month)
+		if n := len(rows); n > 0 && rows[n-1].Key == key {
+			rows[n-1].Value += day.Value
+			continue
+		}
+		rows = append(rows, domain.PivotRow{
+			Key:   key,
+			Label: fmt.Sprintf("%s %d", bs.NepaliMonthName(), bs.Year),
+			Value: day.Value,
+		})
+	}
+	for i := range rows {
+		rows[i].Value = round2(rows[i].Value)
+	}
+	return rows
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/analytics/domain"
+	"github.com/google/uuid"
+)
+
+// PivotService defines the interface for server-side summary tables
+type PivotService interface {
+	// RegisterSource makes a source available for summaries; modules call it from Init
+	RegisterSource(source domain.Source)
+	// Sources lists the registered sources by name
+	Sources() []domain.SourceInfo
+	// Summarize groups a source's metric by one dimension
+	Summarize(ctx context.Context, query *domain.PivotQuery) (*domain.PivotResult, error)
+	// Total sums a source's metric over the query period; GroupBy and Limit are ignored
+	Total(ctx context.Context, query *domain.PivotQuery) (float64, error)
+}
+
+// AnomalyService defines the interface for daily metric baselines and alerts
+type AnomalyService interface {
+	// RegisterMetric adds a metric to the daily evaluation; modules call it from Init
+	RegisterMetric(metric domain.AnomalyMetric)
+	// SetThreshold sets how many standard deviations from the mean is unusual
+	SetThreshold(threshold float64)
+	// SetLinkBase sets the origin alert links are made absolute with in notifications
+	SetLinkBase(base string)
+
+	// Evaluate recomputes the tenant's baselines from the days before day and alerts on day's unusual values
+	Evaluate(ctx context.Context, tenantID uuid.UUID, day time.Time) (*domain.AnomalyRun, error)
+	// RunScheduled evaluates yesterday for every active tenant (called by the daily job)
+	RunScheduled(ctx context.Context) error
+
+	ListBaselines(ctx context.Context, tenantID uuid.UUID) ([]*domain.Baseline, error)
+	ListAlerts(ctx context.Context, tenantID uuid.UUID, status domain.AlertStatus, limit, offset int) ([]*domain.Alert, error)
+	GetAlert(ctx context.Context, tenantID, id uuid.UUID) (*domain.Alert, error)
+	Acknowledge(ctx context.Context, tenantID, id, userID uuid.UUID) (*domain.Alert, error)
+}
+
+// DashboardService defines the interface for saved dashboards of analytics widgets
+type DashboardService interface {
+	// RegisterTemplate adds a built-in dashboard new tenants start with; modules call it from Init
+	RegisterTemplate(template domain.DashboardTemplate)
+
+	// Menu arranges the user's dashboards by menu path and picks the one they land on
+	Menu(ctx context.Context, tenantID, userID uuid.UUID, role string) (*domain.DashboardMenu, error)
+	List(ctx context.Context, tenantID, userID uuid.UUID, role string) ([]*domain.Dashboard, error)
+	Get(ctx context.Context, tenantID, id, userID uuid.UUID, role string) (*domain.Dashboard, error)
+	// Create saves a personal dashboard, or a shared one when OwnerID is nil
+	Create(ctx context.Context, dashboard *domain.Dashboard, role string) error
+	// Update replaces a dashboard's name, menu placement, roles and widgets with those of changes
+	Update(ctx context.Context, tenantID, id, userID uuid.UUID, role string, changes *domain.Dashboard) (*domain.Dashboard, error)
+	Delete(ctx context.Context, tenantID, id, userID uuid.UUID, role string) error
+	// Customize returns the user's personal copy of a shared dashboard, making it on first use
+	Customize(ctx context.Context, tenantID, id, userID uuid.UUID, role string) (*domain.Dashboard, error)
+	// Data loads every widget of a dashboard for the periods ending on day
+	Data(ctx context.Context, tenantID, id, userID uuid.UUID, role string, day time.Time) (*domain.DashboardData, error)
+
+	ListRoleDefaults(ctx context.Context, tenantID uuid.UUID) ([]domain.RoleDefault, error)
+	SetRoleDefault(ctx context.Context, tenantID uuid.UUID, def domain.RoleDefault, role string) error
+	DeleteRoleDefault(ctx context.Context, tenantID uuid.UUID, defaultRole, role string) error
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/identity/repository"
+	"github.com/aceextension/notification"
+	notificationDomain "github.com/aceextension/notification/domain"
+	notificationService "github.com/aceextension/notification/service"
+	subscriptionDomain "github.com/aceextension/subscription/domain"
+	"github.com/google/uuid"
+)
+
+// tenantBillingDetails supplies the invoice buyer from the tenant's registered business details
+type tenantBillingDetails struct {
+	tenantRepo repository.TenantRepository
+}
+
+func (b *tenantBillingDetails) BillingParty(ctx context.Context, tenantID uuid.UUID) (subscriptionDomain.Party, error) {
+	tenant, err := b.tenantRepo.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return subscriptionDomain.Party{}, err
+	}
+
+	party := subscriptionDomain.Party{
+		Name:    tenant.Name,
+		Address: tenant.Address,
+		Email:   tenant.Email,
+	}
+	if tenant.BusinessName != nil && *tenant.BusinessName != "" {
+		party.Name = *tenant.BusinessName
+	}
+	// VAT-registered tenants need their VAT number on the invoice to claim input credit
+	party.VATNumber = tenant.VatNumber
+	if party.VATNumber == nil || *party.VATNumber == "" {
+		party.VATNumber = tenant.PanNumber
+	}
+	return party, nil
+}
+
+// invoiceEmailMailer sends subscription invoices through the notification queue.
+// Notifications carry text only, so the email links to the PDF endpoint.
+type invoiceEmailMailer struct{}
+
+func (invoiceEmailMailer) SendInvoice(ctx context.Context, invoice *subscriptionDomain.SubscriptionInvoice, _ []byte) error {
+	kind := "invoice"
+	if invoice.IsPaid() {
+		kind = "receipt"
+	}
+
+	content := fmt.Sprintf(
+		"Dear %s,\n\nYour subscription %s %s for %s %.2f (incl. VAT %.2f) is ready.\n"+
+			"Service period: %s to %s.\n\nDownload: /api/v1/subscriptions/invoices/%s/pdf\n",
+		invoice.Buyer.Name, kind, invoice.InvoiceNumber, invoice.Currency, invoice.Total, invoice.VATAmount,
+		invoice.PeriodStart.Format("2006-01-02"), invoice.PeriodEnd.Format("2006-01-02"), invoice.ID,
+	)
+	referenceType := "SUBSCRIPTION_INVOICE"
+
+	_, err := notification.Service.Send(ctx, notificationService.SendRequest{
+		TenantID:      invoice.TenantID,
+		Channel:       notificationDomain.ChannelEmail,
+		Recipient:     *invoice.Buyer.Email,
+		Subject:       fmt.Sprintf("Your subscription %s %s", kind, invoice.InvoiceNumber),
+		Content:       content,
+		Priority:      notificationDomain.PriorityLow,
+		ReferenceType: &referenceType,
+		ReferenceID:   &invoice.ID,
+	})
+	return err
+}
+
+// retentionEmailNotifier emails cancellation and data-retention notices to the tenant's billing address
+type retentionEmailNotifier struct {
+	tenantRepo repository.TenantRepository
+}
+
+func (n *retentionEmailNotifier) SendRetentionNotice(ctx context.Context, c *subscriptionDomain.Cancellation, notice subscriptionDomain.RetentionNotice, daysLeft int) error {
+	tenant, err := n.tenantRepo.GetTenantByID(ctx, c.TenantID)
+	if err != nil {
+		return err
+	}
+	if tenant.Email == nil || *tenant.Email == "" {
+		return nil
+	}
+
+	var subject, content string
+	switch notice {
+	case subscriptionDomain.RetentionNoticeScheduled:
+		subject = "Your subscription has been cancelled"
+		content = fmt.Sprintf(
+			"Dear %s,\n\nYour subscription has been cancelled and will not renew. You keep full access until %s.\n"+
+				"After that your data is kept read-only until %s so you can export it. You can reactivate at any time before then.\n",
+			tenant.Name, c.EffectiveAt.Format("2006-01-02"), c.PurgeAfter.Format("2006-01-02"))
+	case subscriptionDomain.RetentionNoticeEffective:
+		subject = "Your subscription has ended"
+		content = fmt.Sprintf(
+			"Dear %s,\n\nYour subscription ended on %s. Please export your data within %d days; it will be permanently deleted after %s.\n"+
+				"Reactivate your subscription before then to keep everything.\n",
+			tenant.Name, c.EffectiveAt.Format("2006-01-02"), daysLeft, c.PurgeAfter.Format("2006-01-02"))
+	case subscriptionDomain.RetentionNoticeReminder:
+		subject = "Your data will be deleted soon"
+		content = fmt.Sprintf(
+			"Dear %s,\n\nReminder: your data will be permanently deleted in %d day(s), on %s.\n"+
+				"Export your data now, or reactivate your subscription to keep it.\n",
+			tenant.Name, daysLeft, c.PurgeAfter.Format("2006-01-02"))
+	case subscriptionDomain.RetentionNoticeReactivated:
+		subject = "Your subscription has been reactivated"
+		content = fmt.Sprintf("Dear %s,\n\nWelcome back! Your subscription has been reactivated and your data will be kept.\n", tenant.Name)
+	default:
+		return nil
+	}
+	referenceType := "SUBSCRIPTION_CANCELLATION"
+
+	_, err = notification.Service.Send(ctx, notificationService.SendRequest{
+		TenantID:      c.TenantID,
+		Channel:       notificationDomain.ChannelEmail,
+		Recipient:     *tenant.Email,
+		Subject:       subject,
+		Content:       content,
+		Priority:      notificationDomain.PriorityLow,
+		ReferenceType: &referenceType,
+		ReferenceID:   &c.ID,
+	})
+	return err
+}
+
+// expiryEmailNotifier emails renewal and expiry notices to the tenant's billing address.
+// billingURL is where paid plans are renewed through checkout.
+type expiryEmailNotifier struct {
+	tenantRepo repository.TenantRepository
+	billingURL string
+}
+
+func (n *expiryEmailNotifier) SendExpiryNotice(ctx context.Context, sub *subscriptionDomain.Subscription, notice subscriptionDomain.ExpiryNotice, graceEnds time.Time) error {
+	tenant, err := n.tenantRepo.GetTenantByID(ctx, sub.TenantID)
+	if err != nil {
+		return err
+	}
+	if tenant.Email == nil || *tenant.Email == "" {
+		return nil
+	}
+
+	planName := "your plan"
+	if sub.Plan != nil {
+		planName = sub.Plan.Name
+	}
+	renew := ""
+	if n.billingURL != "" {
+		renew = fmt.Sprintf("\nRenew here: %s\n", n.billingURL)
+	}
+
+	var subject, content string
+	switch notice {
+	case subscriptionDomain.ExpiryNoticeUpcoming:
+		subject = "Your subscription ends soon"
+		content = fmt.Sprintf(
+			"Dear %s,\n\nYour %s subscription ends on %s. Pay for the next period to keep full access without interruption.\n%s",
+			tenant.Name, planName, sub.EndDate.Format("2006-01-02"), renew)
+	case subscriptionDomain.ExpiryNoticeRenewed:
+		subject = "Your subscription has been renewed"
+		content = fmt.Sprintf("Dear %s,\n\nYour %s subscription has been renewed until %s.\n",
+			tenant.Name, planName, sub.EndDate.Format("2006-01-02"))
+	case subscriptionDomain.ExpiryNoticePaymentDue:
+		subject = "Your subscription payment is due"
+		content = fmt.Sprintf(
+			"Dear %s,\n\nYour %s subscription ended on %s. You keep full access until %s; after that your account becomes read-only.\n%s",
+			tenant.Name, planName, sub.EndDate.Format("2006-01-02"), graceEnds.Format("2006-01-02"), renew)
+	case subscriptionDomain.ExpiryNoticeExpired:
+		subject = "Your subscription has expired"
+		content = fmt.Sprintf(
+			"Dear %s,\n\nYour %s subscription expired on %s and your account is now read-only. Your data is kept; renew to continue working.\n%s",
+			tenant.Name, planName, graceEnds.Format("2006-01-02"), renew)
+	default:
+		return nil
+	}
+	referenceType := "SUBSCRIPTION"
+
+	_, err = notification.Service.Send(ctx, notificationService.SendRequest{
+		TenantID:      sub.TenantID,
+		Channel:       notificationDomain.ChannelEmail,
+		Recipient:     *tenant.Email,
+		Subject:       subject,
+		Content:       content,
+		Priority:      notificationDomain.PriorityLow,
+		ReferenceType: &referenceType,
+		ReferenceID:   &sub.ID,
+	})
+	return err
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription"
+	subscriptionDomain "github.com/aceextension/subscription/domain"
+	subscriptionService "github.com/aceextension/subscription/service"
+	"github.com/google/uuid"
+)
+
+// catalogProductQuota gives the catalog the room left under the tenant's plan product
+// limit, so an import or a variant matrix is checked as a whole before any product is
+// created. Like the plan limit middleware it never limits super admins.
+type catalogProductQuota struct{}
+
+func (catalogProductQuota) RemainingProducts(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	if db.IsSuperAdmin(ctx) {
+		return -1, nil
+	}
+	status, err := subscription.LimitChecker.Check(ctx, tenantID, subscriptionDomain.LimitKeyProducts)
+	if errors.Is(err, subscriptionService.ErrLimitExceeded) {
+		return 0, nil
+	}
+	if err != nil || status == nil {
+		return -1, err
+	}
+	return status.Remaining, nil
+}
@@ -3,17 +3,30 @@
 // @description High performance Go API for AceExtension
 // @host localhost:4000
 // @BasePath /api
+//
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description "Bearer <access token or API key>". API keys (ak_...) and sessions logged in with scopes are limited to their scopes on top of the role: read:<area> views, write:<area> also changes, and the area * covers every area (read:* is read-only). Areas: catalog, sales, crm, purchasing, inventory, accounting, fiscal, analytics, users, settings, integrations, api_keys, subscriptions, audit, notifications, presence, jobs, admin. GET /auth/scopes lists them.
 package main
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	_ "github.com/aceextension/api/docs"
 	"github.com/aceextension/core/apperrors"
 	"github.com/aceextension/core/appvalidator"
 	"github.com/aceextension/core/config"
 	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/events"
+	"github.com/aceextension/core/jobs"
 	"github.com/aceextension/core/logger"
+	coreMiddleware "github.com/aceextension/core/middleware"
+	"github.com/aceextension/core/presence"
+	"github.com/aceextension/core/realtime"
+	"github.com/aceextension/core/security"
 	"github.com/aceextension/identity/handler"
 	"github.com/aceextension/identity/middleware"
 	"github.com/aceextension/identity/repository"
@@ -25,10 +38,24 @@ import (
 	"context"
 	"time"
 
+	"github.com/aceextension/accounting"
+	accountingHandler "github.com/aceextension/accounting/handler"
+	"github.com/aceextension/audit"
+	auditHandler "github.com/aceextension/audit/handler"
+	"github.com/aceextension/catalog"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/files"
+	"github.com/aceextension/fiscal"
 	"github.com/aceextension/notification"
 	notificationHandler "github.com/aceextension/notification/handler"
+	"github.com/aceextension/sales"
+	salesHandler "github.com/aceextension/sales/handler"
 	"github.com/aceextension/subscription"
+	subscriptionDomain "github.com/aceextension/subscription/domain"
 	subscriptionHandler "github.com/aceextension/subscription/handler"
+	subscriptionMiddleware "github.com/aceextension/subscription/middleware"
+	subscriptionService "github.com/aceextension/subscription/service"
+	"github.com/google/uuid"
 )
 
 func main() {
@@ -42,17 +69,54 @@ func main() {
 	// 3. Initialize Database
 	db.Init(cfg.DatabaseURL, cfg.AuditDatabaseURL)
 	defer db.Close()
+	regionURLs, err := db.ParseRegionURLs(cfg.RegionalDatabaseURLs)
+	if err != nil {
+		logger.Log.Fatal("Invalid REGIONAL_DATABASE_URLS: " + err.Error())
+	}
+	db.InitRegions(cfg.DataRegion, regionURLs)
+
+	// Audit trail (identity logs invitations to it)
+	audit.Init()
 
 	// 4. Initialize Dependency Injection
 	authRepo := repository.NewAuthRepository()
 	tenantRepo := repository.NewTenantRepository()
 	userRepo := repository.NewUserRepository()
 
-	authService := service.NewAuthService(authRepo, tenantRepo)
+	membershipRepo := repository.NewMembershipRepository()
+
+	authService := service.NewAuthService(authRepo, tenantRepo, membershipRepo)
+	guestService := service.NewGuestAccessService(authRepo, membershipRepo, repository.NewGuestAccessRepository())
+	middleware.SetGuestGuard(guestService)
+	// Read-only support shadowing, with the tenant's consent
+	supportService := service.NewSupportSessionService(authRepo, repository.NewSupportSessionRepository())
+	middleware.SetSupportGuard(supportService)
 	userService := service.NewUserService(userRepo, tenantRepo, authRepo)
 
+	domainService := service.NewDomainService(repository.NewTenantDomainRepository(), cfg.BaseDomain)
+	coreMiddleware.SetDomainResolver(domainService)
+	brandingService := service.NewBrandingService(repository.NewBrandingRepository(), tenantRepo, domainService)
+
+	// Tenant integration credentials are sealed with CREDENTIAL_KEYS
+	credentialCipher, err := security.ConfigCipher()
+	if err != nil {
+		logger.Log.Warn("Integration credentials cannot be stored: " + err.Error())
+	}
+	integrationService := service.NewIntegrationService(repository.NewIntegrationCredentialRepository(), credentialCipher)
+	security.SetCredentialStore(integrationService)
+
 	authHandler := handler.NewAuthHandler(authService)
 	userHandler := handler.NewUserHandler(userService)
+	domainHandler := handler.NewDomainHandler(domainService)
+	brandingHandler := handler.NewBrandingHandler(brandingService)
+	integrationHandler := handler.NewIntegrationHandler(integrationService)
+	guestHandler := handler.NewGuestHandler(guestService)
+	supportHandler := handler.NewSupportHandler(supportService)
+
+	// API keys authenticate through JWTMiddleware, limited to their scopes
+	apiKeyService := service.NewAPIKeyService(authRepo, repository.NewAPIKeyRepository())
+	middleware.SetAPIKeyAuthenticator(apiKeyService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
 
 	e := echo.New()
 	e.HTTPErrorHandler = apperrors.GlobalErrorHandler
@@ -61,10 +125,28 @@ func main() {
 	// Middleware
 	e.Use(echoMiddleware.Logger())
 	e.Use(echoMiddleware.Recover())
+	e.Use(coreMiddleware.TenantMiddleware) // Resolves tenant from subdomain, custom domain or X-Tenant-ID
+	e.Use(audit.Middleware)                // Attributes audit entries to the request's IP address and User-Agent
+	// Writes sent with X-Request-Nonce and X-Request-Timestamp are accepted once per nonce
+	replayGuard := security.NewPostgresReplayGuard()
+	e.Use(security.ReplayProtection(security.ReplayConfig{Guard: replayGuard}))
 	e.Use(echoMiddleware.CORSWithConfig(echoMiddleware.CORSConfig{
 		AllowOrigins: []string{"*"},
 		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
 	}))
+	// Injected latency and failures for testing clients, never in production
+	if cfg.ChaosEnabled {
+		chaosRules, err := coreMiddleware.ParseChaosRules(cfg.ChaosRules)
+		if err != nil {
+			logger.Log.Fatal("Invalid CHAOS_RULES: " + err.Error())
+		}
+		if cfg.Env == "production" {
+			logger.Log.Warn("CHAOS_ENABLED is ignored in production")
+		} else {
+			logger.Log.Warn("Fault injection is enabled")
+			e.Use(coreMiddleware.Chaos(coreMiddleware.ChaosConfig{Rules: chaosRules, AllowHeaders: cfg.ChaosHeaders}))
+		}
+	}
 
 	// Swagger Documentation
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
@@ -101,29 +183,180 @@ func main() {
 		})
 	})
 
+	// Usage metering for soft plan limits (runs after JWT so the tenant is known)
+	subscription.Init()
+	subscription.Metering.RegisterGauge(subscriptionDomain.LimitKeyUsers, func(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+		count, err := userRepo.GetUserCountByTenant(ctx, tenantID)
+		return int64(count), err
+	})
+	subscription.Metering.RegisterGauge(subscriptionDomain.LimitKeyProducts,
+		subscriptionService.CountGauge(db.MainPool, `SELECT COUNT(*) FROM products WHERE tenant_id = $1`))
+	subscription.Metering.RegisterGauge(subscriptionDomain.LimitKeyInvoicesPerMonth,
+		subscriptionService.CountGauge(db.MainPool, `SELECT COUNT(*) FROM sales_invoices WHERE tenant_id = $1 AND created_at >= date_trunc('month', NOW())`))
+	usageMiddleware := subscriptionMiddleware.UsageMiddleware(subscription.Metering)
+	readOnlyMiddleware := subscriptionMiddleware.ReadOnlyMiddleware(subscription.Enforcement, cfg.BillingUpgradeURL)
+	// Hard plan limits on creating users, products and invoices (routes use coreMiddleware.PlanLimit)
+	coreMiddleware.SetPlanLimitGuard(func(limitKey string) echo.MiddlewareFunc {
+		return subscriptionMiddleware.PlanLimitMiddleware(subscription.LimitChecker, limitKey, cfg.BillingUpgradeURL)
+	})
+	// Modules such as accounting and notifications are switched on per plan (coreMiddleware.RequireFeature)
+	coreMiddleware.SetFeatureGuard(func(feature string) echo.MiddlewareFunc {
+		return subscriptionMiddleware.RequireFeature(subscription.Service, feature, cfg.BillingUpgradeURL)
+	})
+
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := subscription.Metering.Flush(context.Background()); err != nil {
+				logger.Log.Error("Usage metering flush error: " + err.Error())
+			}
+		}
+	}()
+
 	// Auth Routes
 	auth := api.Group("/auth")
 	auth.POST("/register", authHandler.RegisterTenant)
 	auth.POST("/verify-otp", authHandler.VerifyOTP)
 	auth.POST("/login", authHandler.Login)
-	auth.POST("/logout", authHandler.Logout, middleware.JWTMiddleware)
+	auth.POST("/logout", authHandler.Logout, middleware.JWTMiddleware, middleware.RequireSession)
 	auth.POST("/refresh", authHandler.RefreshToken)
-	auth.POST("/change-password", authHandler.ChangePassword, middleware.JWTMiddleware)
+	auth.POST("/change-password", authHandler.ChangePassword, middleware.JWTMiddleware, middleware.RequireSession)
 	auth.POST("/forgot-password", authHandler.ForgotPassword)
 	auth.POST("/reset-password", authHandler.ResetPassword)
-	auth.POST("/impersonate/:tenantId", authHandler.Impersonate, middleware.JWTMiddleware)
-	auth.GET("/me", authHandler.GetMe, middleware.JWTMiddleware)
+	auth.POST("/impersonate/:tenantId", authHandler.Impersonate, middleware.JWTMiddleware, middleware.RequireSession)
+	auth.GET("/me", authHandler.GetMe, middleware.JWTMiddleware, usageMiddleware)
+	auth.GET("/tenants", authHandler.ListTenants, middleware.JWTMiddleware, middleware.RequireSession)
+	auth.POST("/switch-tenant", authHandler.SwitchTenant, middleware.JWTMiddleware, middleware.RequireSession)
+	auth.GET("/scopes", apiKeyHandler.ListScopes)
 
 	// User Management Routes
-	users := api.Group("/users", middleware.JWTMiddleware)
+	users := api.Group("/users", middleware.JWTMiddleware, readOnlyMiddleware, usageMiddleware, middleware.RequireScope("users"))
 	users.GET("", userHandler.ListUsers)
-	users.POST("/invite", userHandler.InviteUser)
+	users.POST("/invite", userHandler.InviteUser, coreMiddleware.PlanLimit(coreMiddleware.PlanLimitUsers))
 	users.POST("/join", userHandler.JoinTenant) // Join is public but with token
 
+	// Tenant Domain Routes
+	tenantDomains := api.Group("/tenant/domains", middleware.JWTMiddleware, readOnlyMiddleware, middleware.RequireRole("owner", "admin"), middleware.RequireScope("settings"))
+	tenantDomains.GET("", domainHandler.ListDomains)
+	tenantDomains.POST("", domainHandler.AddDomain)
+	tenantDomains.POST("/:id/verify", domainHandler.VerifyDomain)
+	tenantDomains.DELETE("/:id", domainHandler.RemoveDomain)
+
+	// Branding Routes
+	api.GET("/public/branding", brandingHandler.GetPublicBranding)
+	branding := api.Group("/tenant/branding", middleware.JWTMiddleware, readOnlyMiddleware, middleware.RequireScope("settings"))
+	branding.GET("", brandingHandler.GetBranding)
+	branding.PUT("", brandingHandler.UpdateBranding, middleware.RequireRole("owner", "admin"))
+
+	// Integration Credential Routes
+	integrations := api.Group("/tenant/integrations", middleware.JWTMiddleware, readOnlyMiddleware, middleware.RequireRole("owner", "admin"), middleware.RequireScope("integrations"))
+	integrations.GET("", integrationHandler.ListIntegrations)
+	integrations.GET("/:name", integrationHandler.GetIntegration)
+	integrations.PUT("/:name", integrationHandler.SetIntegration)
+	integrations.DELETE("/:name", integrationHandler.RemoveIntegration)
+
+	// Guest Access Routes
+	guests := api.Group("/tenant/guests", middleware.JWTMiddleware, readOnlyMiddleware, middleware.RequireRole("owner", "admin"), middleware.RequireScope("settings"))
+	guests.GET("", guestHandler.ListGuests)
+	guests.POST("", guestHandler.GrantAccess)
+	guests.DELETE("/:id", guestHandler.RevokeAccess)
+	guests.GET("/:id/activity", guestHandler.ListActivity)
+
+	// Support Access Routes: the tenant's consent and the sessions support opened
+	supportAccess := api.Group("/tenant/support-access", middleware.JWTMiddleware, readOnlyMiddleware, middleware.RequireRole("owner", "admin"), middleware.RequireScope("settings"))
+	supportAccess.GET("", supportHandler.GetSupportAccess)
+	supportAccess.PUT("", supportHandler.SetSupportAccess, middleware.RequireRole("owner"))
+	supportAccess.GET("/sessions", supportHandler.ListSessions)
+	supportAccess.POST("/sessions/:id/end", supportHandler.EndSession)
+	supportAccess.GET("/sessions/:id/activity", supportHandler.ListActivity)
+
+	// API Key Routes
+	apiKeys := api.Group("/tenant/api-keys", middleware.JWTMiddleware, readOnlyMiddleware, middleware.RequireRole("owner", "admin"), middleware.RequireScope("api_keys"))
+	apiKeys.GET("", apiKeyHandler.ListKeys)
+	apiKeys.POST("", apiKeyHandler.CreateKey)
+	apiKeys.DELETE("/:id", apiKeyHandler.RevokeKey)
+
+	// Re-seal credentials left under a rotated-out key
+	go func() {
+		resealed, err := integrationService.ResealAll(context.Background())
+		if err != nil {
+			logger.Log.Error("Integration credential reseal error: " + err.Error())
+		}
+		if resealed > 0 {
+			logger.Log.Info(fmt.Sprintf("Re-sealed %d integration credentials under the current key", resealed))
+		}
+	}()
+
+	// Expire lapsed guest access
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := guestService.ExpireLapsed(context.Background()); err != nil {
+				logger.Log.Error("Guest access expiry error: " + err.Error())
+			}
+		}
+	}()
+
+	// Forget request nonces whose replay window has passed
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := replayGuard.Purge(context.Background()); err != nil {
+				logger.Log.Error("Request nonce purge error: " + err.Error())
+			}
+		}
+	}()
+
+	// Live updates: events published by the modules, pushed to the tenant's clients
+	realtimeHub := realtime.NewHub()
+	realtimeHub.SetAuthenticator(middleware.RealtimeAuthenticator)
+	realtimeHub.Attach(events.Default)
+	api.GET("/v1/realtime/ws", realtimeHub.ServeWebSocket)
+	api.GET("/v1/realtime/events", realtimeHub.ServeEvents)
+
+	// Who is viewing or editing a record, announced on the realtime hub. Heartbeats
+	// arrive every few seconds per open record, so they are not metered as usage.
+	var presenceStore presence.Store = presence.NewMemoryStore()
+	if cfg.RedisURL != "" {
+		redisStore, err := presence.NewRedisStore(cfg.RedisURL)
+		if err != nil {
+			logger.Log.Fatal("Invalid REDIS_URL: " + err.Error())
+		}
+		if err := redisStore.Ping(context.Background()); err != nil {
+			logger.Log.Warn("Redis is unreachable, presence will fail until it is back: " + err.Error())
+		}
+		presenceStore = redisStore
+	}
+	presenceService := presence.NewService(presenceStore, events.Default)
+	presenceService.SetNameResolver(func(ctx context.Context, userID uuid.UUID) (string, error) {
+		user, err := authRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		return user.Name, nil
+	})
+	presence.NewHandler(presenceService).RegisterRoutes(api.Group("/v1/presence", middleware.JWTMiddleware, middleware.RequireScope("presence")))
+
+	// Exports and reports that outlive the budget carry on as jobs, polled here, so
+	// they are not cut off by the load balancer's timeout
+	jobResults, err := jobs.NewDirStore(cfg.FileStoragePath + "/jobs")
+	if err != nil {
+		logger.Log.Fatal(err.Error())
+	}
+	jobs.Init(jobResults, time.Duration(cfg.AsyncRequestBudgetSeconds)*time.Second)
+	jobs.StartPurgeWorker()
+	jobs.NewHandler(jobs.Default).RegisterRoutes(api.Group("/v1/jobs", middleware.JWTMiddleware, middleware.RequireScope("jobs")))
+
 	// 5. Initialize Notification Module & Worker
 	notification.Init()
+	notification.Service.SetBrandingProvider(brandingService)
+	notification.Service.SetCipher(credentialCipher)
+	authService.SetOTPSender(notificationOTPSender{})
 	// Register Notification Routes
-	notificationHandler.RegisterRoutes(e)
+	notificationHandler.RegisterRoutes(api.Group("/v1/notifications", middleware.JWTMiddleware, readOnlyMiddleware, middleware.RequireRole("owner", "admin"), middleware.RequireScope("notifications")))
 
 	// Start Notification Worker
 	go func() {
@@ -138,21 +371,128 @@ func main() {
 		}
 	}()
 
+	// Owners' daily digest of critical audit events, emailed through notifications
+	auditHandler.RegisterDigestRoutes(api.Group("/v1/audit/digest", middleware.JWTMiddleware, middleware.RequireRole("owner"), middleware.RequireScope("audit")))
+	audit.StartDigestScheduler()
+
+	// Audit entries past AUDIT_RETENTION_DAYS are purged daily, except under legal hold
+	audit.StartRetentionScheduler(cfg.AuditRetentionDays)
+
 	// 6. Subscription Module
-	subscription.Init()
+	subscription.Invoices.SetBillingDetailsProvider(&tenantBillingDetails{tenantRepo: tenantRepo})
+	subscription.Invoices.SetMailer(invoiceEmailMailer{})
+	subscription.Cancellations.SetRetentionNotifier(&retentionEmailNotifier{tenantRepo: tenantRepo})
+	// Payers return from eSewa/Khalti to the billing page the read-only notice links to
+	checkoutReturnURL := ""
+	if cfg.AppURL != "" {
+		checkoutReturnURL = strings.TrimRight(cfg.AppURL, "/") + cfg.BillingUpgradeURL
+	}
+	subscription.Renewals.SetExpiryNotifier(&expiryEmailNotifier{tenantRepo: tenantRepo, billingURL: checkoutReturnURL})
+
+	// End cancelled subscriptions at period end and run the data retention countdown
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := subscription.Cancellations.ProcessDue(context.Background()); err != nil {
+				logger.Log.Error("Subscription cancellation worker error: " + err.Error())
+			}
+		}
+	}()
+
+	// Renew free plans, warn before periods end and expire subscriptions past the grace period
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := subscription.Renewals.ProcessDue(context.Background()); err != nil {
+				logger.Log.Error("Subscription renewal worker error: " + err.Error())
+			}
+		}
+	}()
+
 	subPlanHandler := subscriptionHandler.NewPlanHandler(subscription.Service)
 	subHandler := subscriptionHandler.NewSubscriptionHandler(subscription.Service, authService)
+	subInvoiceHandler := subscriptionHandler.NewInvoiceHandler(subscription.Invoices)
+	enforcementHandler := subscriptionHandler.NewEnforcementHandler(subscription.Enforcement)
+	cancellationHandler := subscriptionHandler.NewCancellationHandler(subscription.Cancellations)
+	apiUsageHandler := subscriptionHandler.NewAPIUsageHandler(subscription.Metering)
+	limitHandler := subscriptionHandler.NewLimitHandler(subscription.LimitChecker)
+	checkoutHandler := subscriptionHandler.NewCheckoutHandler(subscription.Checkout, checkoutReturnURL)
 	// subv1 variable was unused, removed.
 	// Let's attach to api group directly
 
 	plans := api.Group("/v1/plans")
-	plans.POST("", subPlanHandler.Create, middleware.JWTMiddleware) // Admin only ideally
+	// Plans are listed publicly for the pricing page; only super admins create them
+	plans.POST("", subPlanHandler.Create, middleware.JWTMiddleware, middleware.RequireRole("super_admin"), middleware.RequireScope("admin"))
 	plans.GET("", subPlanHandler.List)
 
 	subs := api.Group("/v1/subscriptions")
-	subs.Use(middleware.JWTMiddleware)
+	subs.Use(middleware.JWTMiddleware, readOnlyMiddleware, usageMiddleware, middleware.RequireScope("subscriptions"))
 	subs.GET("/current", subHandler.GetCurrentSubscription)
 	subs.POST("/subscribe", subHandler.Subscribe)
+	subs.GET("/history", subHandler.GetHistory)
+	subs.GET("/access", enforcementHandler.GetAccess)
+	subs.GET("/usage", limitHandler.GetUsage)
+	subs.GET("/invoices", subInvoiceHandler.List)
+	subs.GET("/invoices/:id", subInvoiceHandler.Get)
+	subs.GET("/invoices/:id/pdf", subInvoiceHandler.DownloadPDF)
+	subs.GET("/invoices/:id/prints", subInvoiceHandler.ListPrints)
+	subs.POST("/invoices/:id/send", subInvoiceHandler.Send, middleware.RequireRole("owner", "admin"))
+	subs.GET("/cancellation", cancellationHandler.GetCancellation)
+	subs.POST("/cancel", cancellationHandler.Cancel, middleware.RequireRole("owner"))
+	subs.POST("/reactivate", cancellationHandler.Reactivate, middleware.RequireRole("owner"))
+	subs.GET("/gateways", checkoutHandler.ListGateways)
+	subs.POST("/checkout", checkoutHandler.Checkout, middleware.RequireRole("owner", "admin"))
+	subs.GET("/payments", checkoutHandler.ListPayments)
+	subs.GET("/payments/:id", checkoutHandler.GetPayment)
+	subs.POST("/payments/:id/verify", checkoutHandler.VerifyPayment)
+
+	// Gateways send the payer back here without a token; payments are verified server side
+	api.GET("/v1/subscriptions/payments/callback/:gateway", checkoutHandler.Callback)
+
+	// Which API keys, users and endpoints drive the tenant's traffic
+	usage := api.Group("/v1/usage", middleware.JWTMiddleware, middleware.RequireRole("owner", "admin"), middleware.RequireScope("api_keys"))
+	usage.GET("/api", apiUsageHandler.GetAPIUsage)
+
+	adminTenants := api.Group("/v1/admin/tenants", middleware.JWTMiddleware, middleware.RequireRole("super_admin"), middleware.RequireScope("admin"))
+	adminTenants.POST("/:tenantId/access-override", enforcementHandler.GrantOverride)
+	adminTenants.DELETE("/:tenantId/access-override", enforcementHandler.RevokeOverride)
+	adminTenants.POST("/:tenantId/support-sessions", supportHandler.StartSession)
+	adminTenants.GET("/:tenantId/support-sessions", supportHandler.ListSessions)
+	adminTenants.POST("/:tenantId/support-sessions/:id/end", supportHandler.EndSession)
+	adminTenants.GET("/:tenantId/support-sessions/:id/activity", supportHandler.ListActivity)
+
+	// Legal holds keep tenants' audit entries from the retention purge
+	auditHandler.RegisterLegalHoldRoutes(api.Group("/v1/admin/audit/legal-holds", middleware.JWTMiddleware, middleware.RequireRole("super_admin"), middleware.RequireScope("admin")))
+
+	// 7. Accounting Module: the ledger invoices are posted to, switched on per plan.
+	// Export bundles are saved to the files module's export store, so it starts first
+	fiscal.Init()
+	files.Init()
+	accounting.Init()
+	accounting.StartExportBundleWorker()
+	accountingHandler.RegisterRoutes(
+		api.Group("/v1/accounting", middleware.JWTMiddleware, readOnlyMiddleware, usageMiddleware, middleware.RequireScope("accounting")),
+		accountingHandler.NewAccountHandler(accounting.Service),
+		accountingHandler.NewJournalHandler(accounting.Service),
+		accountingHandler.NewReportHandler(accounting.Service),
+		accountingHandler.NewExportBundleHandler(accounting.BundleService),
+		accountingHandler.NewInterCompanyHandler(accounting.InterCompanyService),
+		accountingHandler.NewYearEndHandler(accounting.YearEndCloseService),
+	)
+
+	// 8. Sales Module: invoices are numbered from fiscal series, made out to crm customers,
+	// priced from the catalog and posted to accounting, so those modules start first
+	catalog.Init()
+	catalog.ProductService.SetQuota(catalogProductQuota{})
+	crm.Init()
+	sales.Init()
+	sales.StartPostingRetryWorker()
+	salesHandler.RegisterRoutes(
+		api.Group("/v1/sales", middleware.JWTMiddleware, readOnlyMiddleware, usageMiddleware, middleware.RequireScope("sales")),
+		api.Group("/v1/public/invoices"),
+	)
 
 	// Start server
 	port := cfg.Port
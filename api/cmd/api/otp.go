@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	identityService "github.com/aceextension/identity/service"
+	"github.com/aceextension/notification"
+	notificationDomain "github.com/aceextension/notification/domain"
+	notificationService "github.com/aceextension/notification/service"
+)
+
+// notificationOTPSender sends registration and password reset codes through the
+// notification module, by SMS or by email when the user asked from their email.
+// They go out at high priority, and fail over along the tenant's USER_OTP chain. The
+// code is a message secret, so it is never stored in the notification's content.
+type notificationOTPSender struct{}
+
+func (notificationOTPSender) SendOTP(ctx context.Context, otp identityService.OTP) error {
+	minutes := int(math.Ceil(time.Until(otp.ExpiresAt).Minutes()))
+	subject := "Your AceExtension verification code"
+	content := fmt.Sprintf("Your AceExtension verification code is {{secret.code}}. It expires in %d minutes. Do not share it with anyone.", minutes)
+	if otp.Purpose == identityService.OTPPurposePasswordReset {
+		subject = "Your AceExtension password reset code"
+		content = fmt.Sprintf("Your AceExtension password reset code is {{secret.code}}. It expires in %d minutes. If you did not ask to reset your password, ignore this message.", minutes)
+	}
+
+	channel, recipient := notificationDomain.ChannelSMS, otp.Phone
+	if otp.Email != "" {
+		channel, recipient = notificationDomain.ChannelEmail, otp.Email
+	}
+	referenceType := "USER_OTP"
+
+	_, err := notification.Service.Send(ctx, notificationService.SendRequest{
+		TenantID:      otp.TenantID,
+		UserID:        &otp.UserID,
+		Channel:       channel,
+		Recipient:     recipient,
+		Subject:       subject,
+		Content:       content,
+		Secrets:       map[string]string{"code": otp.Code},
+		Priority:      notificationDomain.PriorityHigh,
+		ReferenceType: &referenceType,
+		ReferenceID:   &otp.UserID,
+	})
+	return err
+}
@@ -3448,6 +3448,14 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "\"Bearer <access token or API key>\". API keys (ak_...) and sessions logged in with scopes are limited to their scopes on top of the role: read:<area> views, write:<area> also changes, and the area * covers every area (read:* is read-only). Areas: catalog, sales, crm, purchasing, inventory, accounting, fiscal, analytics, users, settings, integrations, api_keys, subscriptions, audit. GET /auth/scopes lists them.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -15,6 +15,13 @@ replace github.com/aceextension/subscription => ../subscription
 toolchain go1.24.12
 
 require (
+	github.com/aceextension/accounting v0.0.0
+	github.com/aceextension/audit v0.0.0
+	github.com/aceextension/catalog v0.0.0
+	github.com/aceextension/crm v0.0.0
+	github.com/aceextension/files v0.0.0
+	github.com/aceextension/fiscal v0.0.0
+	github.com/aceextension/sales v0.0.0
 	github.com/aceextension/core v0.0.0-00010101000000-000000000000
 	github.com/aceextension/identity v0.0.0-00010101000000-000000000000
 	github.com/aceextension/notification v0.0.0-00010101000000-000000000000
@@ -74,3 +81,5 @@ require (
 	golang.org/x/tools v0.40.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
+
+replace github.com/aceextension/audit => ../audit
@@ -1,15 +1,86 @@
 package audit
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/aceextension/audit/repository"
 	"github.com/aceextension/audit/service"
 )
 
+// digestHour is the local hour the owners' daily digest is sent
+const digestHour = 7
+
+// retentionHour is the local hour entries past retention are purged
+const retentionHour = 8
+
 // Global audit service instance
 var Service service.AuditService
 
+// DigestService sends owners the daily digest of critical events
+var DigestService service.DigestService
+
+// RetentionService purges entries past retention and manages legal holds
+var RetentionService service.RetentionService
+
 // Init initializes the audit module
 func Init() {
 	repo := repository.NewPostgresAuditRepository()
 	Service = service.NewAuditService(repo)
+	DigestService = service.NewDigestService(repository.NewPostgresDigestRepository())
+	RetentionService = service.NewRetentionService(repository.NewPostgresLegalHoldRepository(), Service)
+}
+
+// StartDigestScheduler sends the owners' digest once a day at digestHour.
+// Call after Init; digests are delivered through the notification module.
+func StartDigestScheduler() {
+	go func() {
+		for {
+			time.Sleep(time.Until(nextDigestRun(time.Now())))
+			if err := DigestService.RunDaily(context.Background()); err != nil {
+				fmt.Printf("Audit digest run error: %v\n", err)
+			}
+		}
+	}()
+}
+
+// StartRetentionScheduler purges entries older than retentionDays once a day at
+// retentionHour; entries under legal hold are kept. Zero or less keeps every entry
+// and starts nothing. Call after Init.
+func StartRetentionScheduler(retentionDays int) {
+	if retentionDays <= 0 {
+		return
+	}
+	go func() {
+		for {
+			time.Sleep(time.Until(nextRetentionRun(time.Now())))
+			run, err := RetentionService.Purge(context.Background(), time.Now().AddDate(0, 0, -retentionDays))
+			if err != nil {
+				fmt.Printf("Audit retention run error: %v\n", err)
+			}
+			if run != nil {
+				fmt.Printf("Audit retention: purged %d entries before %s, kept %d under legal hold\n",
+					run.Deleted, run.Before.Format("2006-01-02"), run.Held)
+			}
+		}
+	}()
+}
+
+// nextRetentionRun returns the next retentionHour after now
+func nextRetentionRun(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), retentionHour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// nextDigestRun returns the next digestHour after now
+func nextDigestRun(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), digestHour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
 }
@@ -0,0 +1,30 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/aceextension/audit/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// FromContext returns the audit context of the request ctx belongs to: its user,
+// tenant, IP address and User-Agent. Service.Log fills what a caller's audit
+// context leaves out from it, so services need not pass the actor along.
+func FromContext(ctx context.Context) *domain.AuditContext {
+	return domain.FromContext(ctx)
+}
+
+// Middleware records the client's IP address and User-Agent in the request context
+// for audit entries logged while handling it. Register it with e.Use; the user and
+// tenant are read when each entry is logged, after the auth and tenant middleware
+// of the route have run.
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := domain.WithRequest(c.Request().Context(), domain.RequestInfo{
+			IPAddress: c.RealIP(),
+			UserAgent: c.Request().UserAgent(),
+		})
+		c.SetRequest(c.Request().WithContext(ctx))
+		return next(c)
+	}
+}
@@ -12,6 +12,7 @@ type AuditLog struct {
 	TenantID  *uuid.UUID `json:"tenantId,omitempty" db:"tenant_id"`
 	UserID    *uuid.UUID `json:"userId,omitempty" db:"user_id"`
 	Action    string     `json:"action" db:"action"`                // e.g., "CREATE_USER", "UPDATE_SALE"
+	Severity  Severity   `json:"severity" db:"severity"`            // Set from the action's severity rule
 	Entity    string     `json:"entity" db:"entity"`                // e.g., "User", "Sale", "Purchase"
 	EntityID  *string    `json:"entityId,omitempty" db:"entity_id"` // ID of the affected entity
 	IPAddress *string    `json:"ipAddress,omitempty" db:"ip_address"`
@@ -37,6 +38,7 @@ func NewAuditLog(action, entity string, entityID *string, details any, ctx *Audi
 		TenantID:  ctx.TenantID,
 		UserID:    ctx.UserID,
 		Action:    action,
+		Severity:  SeverityInfo,
 		Entity:    entity,
 		EntityID:  entityID,
 		IPAddress: ctx.IPAddress,
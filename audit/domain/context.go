@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+)
+
+// requestKey is the context key of the request's RequestInfo
+type requestKey struct{}
+
+// RequestInfo is where a request came from, as audit.Middleware saw it
+type RequestInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// WithRequest adds the request's origin to context
+func WithRequest(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestKey{}, info)
+}
+
+// FromContext builds the audit context of a request: the user and tenant the auth
+// and tenant middleware put in ctx, read now so middleware running after
+// audit.Middleware counts, and the IP address and User-Agent it recorded. Fields
+// ctx does not carry are left nil.
+func FromContext(ctx context.Context) *AuditContext {
+	auditCtx := &AuditContext{}
+	if tenantID, ok := db.GetTenantID(ctx); ok && tenantID != uuid.Nil {
+		auditCtx.TenantID = &tenantID
+	}
+	if userID, ok := db.GetUserID(ctx); ok && userID != uuid.Nil {
+		auditCtx.UserID = &userID
+	}
+	if info, ok := ctx.Value(requestKey{}).(RequestInfo); ok {
+		if info.IPAddress != "" {
+			auditCtx.IPAddress = &info.IPAddress
+		}
+		if info.UserAgent != "" {
+			auditCtx.UserAgent = &info.UserAgent
+		}
+	}
+	return auditCtx
+}
+
+// Fill returns a copy of the audit context with what it leaves out, nil or the nil
+// UUID, taken from other. What the caller set is kept.
+func (a *AuditContext) Fill(other *AuditContext) *AuditContext {
+	if a == nil {
+		return other
+	}
+	filled := *a
+	if filled.TenantID == nil || *filled.TenantID == uuid.Nil {
+		filled.TenantID = other.TenantID
+	}
+	if filled.UserID == nil || *filled.UserID == uuid.Nil {
+		filled.UserID = other.UserID
+	}
+	if filled.IPAddress == nil {
+		filled.IPAddress = other.IPAddress
+	}
+	if filled.UserAgent == nil {
+		filled.UserAgent = other.UserAgent
+	}
+	return &filled
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotTenantOwner is returned when someone other than an owner manages the digest
+var ErrNotTenantOwner = errors.New("only tenant owners receive the audit digest")
+
+// DigestMaxEvents caps the events listed in one digest; the rest are counted
+const DigestMaxEvents = 50
+
+// DigestSubscription is an owner's daily digest setting for one tenant. Owners
+// without a saved setting receive critical events.
+type DigestSubscription struct {
+	TenantID    uuid.UUID  `json:"tenantId" db:"tenant_id"`
+	UserID      uuid.UUID  `json:"userId" db:"user_id"`
+	IsEnabled   bool       `json:"isEnabled" db:"is_enabled"`
+	MinSeverity Severity   `json:"minSeverity" db:"min_severity"` // Lowest severity included
+	LastSentAt  *time.Time `json:"lastSentAt,omitempty" db:"last_sent_at"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// NewDigestSubscription returns the default setting: enabled, critical events only
+func NewDigestSubscription(tenantID, userID uuid.UUID) *DigestSubscription {
+	now := time.Now()
+	return &DigestSubscription{
+		TenantID:    tenantID,
+		UserID:      userID,
+		IsEnabled:   true,
+		MinSeverity: SeverityCritical,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Severities lists the severities the subscription includes
+func (s *DigestSubscription) Severities() []string {
+	severities := []string{}
+	for _, severity := range []Severity{SeverityInfo, SeverityWarning, SeverityCritical} {
+		if severity.AtLeast(s.MinSeverity) {
+			severities = append(severities, string(severity))
+		}
+	}
+	return severities
+}
+
+// DigestOwner is a tenant owner who can receive the digest
+type DigestOwner struct {
+	TenantID   uuid.UUID
+	TenantName string
+	UserID     uuid.UUID
+	Name       string
+	Email      string
+}
+
+// Digest is the summary of a tenant's events sent to one owner
+type Digest struct {
+	TenantID   uuid.UUID   `json:"tenantId"`
+	TenantName string      `json:"tenantName"`
+	From       time.Time   `json:"from"`
+	To         time.Time   `json:"to"`
+	Events     []*AuditLog `json:"events"` // Newest first, at most DigestMaxEvents
+	Total      int         `json:"total"`  // All matching events, including those not listed
+}
+
+// Subject is the digest email's subject line
+func (d *Digest) Subject() string {
+	return fmt.Sprintf("%s: %d important event(s) on %s", d.TenantName, d.Total, d.To.Format("2006-01-02"))
+}
+
+// Body renders the digest as plain text
+func (d *Digest) Body(ownerName string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hello %s,\n\n", ownerName)
+	fmt.Fprintf(&b, "%d important event(s) were recorded for %s between %s and %s:\n\n",
+		d.Total, d.TenantName, d.From.Format("2006-01-02 15:04"), d.To.Format("2006-01-02 15:04"))
+	b.WriteString(d.Lines())
+	b.WriteString("\nYou can change or turn off this digest in your audit settings.\n")
+	return b.String()
+}
+
+// Lines lists the events one per line, noting how many more were not listed
+func (d *Digest) Lines() string {
+	var b strings.Builder
+	for _, event := range d.Events {
+		fmt.Fprintf(&b, "- %s [%s] %s %s", event.CreatedAt.Format("Jan 02 15:04"),
+			strings.ToUpper(string(event.Severity)), event.Action, event.Entity)
+		if event.EntityID != nil {
+			fmt.Fprintf(&b, " %s", *event.EntityID)
+		}
+		b.WriteString("\n")
+	}
+	if more := d.Total - len(d.Events); more > 0 {
+		fmt.Fprintf(&b, "...and %d more. See the audit log for the full list.\n", more)
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Severity classifies how much an audit event matters to the tenant's owners
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical" // Included in the owners' daily digest
+)
+
+const (
+	// PriceChangeThreshold is the relative price move above which a price change is critical
+	PriceChangeThreshold = 0.10
+	// LargeCreditNoteAmount is the credit note total from which a credit note is critical
+	LargeCreditNoteAmount = 100000.0
+)
+
+// rank orders severities from info to critical
+func (s Severity) rank() int {
+	switch s {
+	case SeverityWarning:
+		return 1
+	case SeverityCritical:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// AtLeast reports whether s is as severe as min
+func (s Severity) AtLeast(min Severity) bool {
+	return s.rank() >= min.rank()
+}
+
+// ParseSeverity validates a severity name
+func ParseSeverity(value string) (Severity, error) {
+	switch s := Severity(value); s {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return s, nil
+	default:
+		return "", fmt.Errorf("invalid severity %q: must be info, warning or critical", value)
+	}
+}
+
+// SeverityRule decides the severity of an action from its details
+type SeverityRule func(details any) Severity
+
+// Always classifies every occurrence of an action the same
+func Always(severity Severity) SeverityRule {
+	return func(any) Severity { return severity }
+}
+
+// DefaultSeverityRules classifies the platform's common actions. Actions without a
+// rule are info; modules add their own with AuditService.RegisterSeverityRule.
+func DefaultSeverityRules() map[string]SeverityRule {
+	return map[string]SeverityRule{
+		"INVITE_USER":       Always(SeverityCritical),
+		"CREATE_USER":       Always(SeverityCritical),
+		"DELETE_USER":       Always(SeverityCritical),
+		"CHANGE_USER_ROLE":  Always(SeverityCritical),
+		"CLOSE_FISCAL_YEAR": Always(SeverityCritical),
+		// Mutation attempts from a read-only support session
+		"SUPPORT_WRITE_BLOCKED": Always(SeverityCritical),
+		"CHANGE_PRICE":          priceChangeSeverity,
+		"CREATE_CREDIT_NOTE": func(details any) Severity {
+			if total, ok := DetailNumber(details, "total_amount"); ok && total >= LargeCreditNoteAmount {
+				return SeverityCritical
+			}
+			return SeverityWarning
+		},
+		"DEACTIVATE_USER":       Always(SeverityWarning),
+		"RESET_PASSWORD":        Always(SeverityWarning),
+		"LOGIN_FAILED":          Always(SeverityWarning),
+		"SUSPEND_TENANT":        Always(SeverityWarning),
+		"START_SUPPORT_SESSION": Always(SeverityWarning),
+		"ENABLE_SUPPORT_ACCESS": Always(SeverityWarning),
+	}
+}
+
+// priceChangeSeverity is critical when the price moved by more than PriceChangeThreshold;
+// details carry old_price and new_price
+func priceChangeSeverity(details any) Severity {
+	oldPrice, okOld := DetailNumber(details, "old_price")
+	newPrice, okNew := DetailNumber(details, "new_price")
+	if !okOld || !okNew {
+		return SeverityInfo
+	}
+	if oldPrice <= 0 || math.Abs(newPrice-oldPrice)/oldPrice > PriceChangeThreshold {
+		return SeverityCritical
+	}
+	return SeverityInfo
+}
+
+// DetailNumber reads a numeric field from an event's details, which may be a map or
+// any JSON-encodable struct
+func DetailNumber(details any, key string) (float64, bool) {
+	fields, ok := details.(map[string]interface{})
+	if !ok {
+		raw, err := json.Marshal(details)
+		if err != nil || json.Unmarshal(raw, &fields) != nil {
+			return 0, false
+		}
+	}
+
+	switch v := fields[key].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/audit"
+	"github.com/aceextension/audit/domain"
+	"github.com/aceextension/audit/repository"
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/export"
+	"github.com/aceextension/core/stream"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// AuditHandler handles HTTP requests for a tenant's audit trail
+type AuditHandler struct{}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler() *AuditHandler {
+	return &AuditHandler{}
+}
+
+// Search godoc
+// @Summary Search audit logs
+// @Description Get the tenant's audit logs, newest first, filtered by user, action, entity, severity and date range
+// @Tags audit
+// @Produce json
+// @Param userId query string false "User ID"
+// @Param action query string false "Action (e.g. UPDATE_SALE)"
+// @Param entity query string false "Entity (e.g. Sale)"
+// @Param entityId query string false "Entity ID"
+// @Param startDate query string false "From (YYYY-MM-DD or timestamp)"
+// @Param endDate query string false "To (YYYY-MM-DD includes the whole day)"
+// @Param severity query string false "Severity (info, warning, critical)"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.AuditLog
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/audit/logs [get]
+// @Security BearerAuth
+func (h *AuditHandler) Search(c echo.Context) error {
+	filters, err := h.filters(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if filters == nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	filters.Limit = limit
+	filters.Offset = offset
+
+	logs, err := audit.Service.Search(c.Request().Context(), filters)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, logs)
+}
+
+// auditExportColumns are the columns of a CSV or XLSX export; details are written as JSON
+var auditExportColumns = []export.Column[*domain.AuditLog]{
+	{Name: "createdAt", Value: func(l *domain.AuditLog) any { return l.CreatedAt }},
+	{Name: "action", Value: func(l *domain.AuditLog) any { return l.Action }},
+	{Name: "severity", Value: func(l *domain.AuditLog) any { return string(l.Severity) }},
+	{Name: "entity", Value: func(l *domain.AuditLog) any { return l.Entity }},
+	{Name: "entityId", Value: func(l *domain.AuditLog) any { return l.EntityID }},
+	{Name: "userId", Value: func(l *domain.AuditLog) any { return l.UserID }},
+	{Name: "ipAddress", Value: func(l *domain.AuditLog) any { return l.IPAddress }},
+	{Name: "userAgent", Value: func(l *domain.AuditLog) any { return l.UserAgent }},
+	{Name: "details", Value: func(l *domain.AuditLog) any {
+		if l.Details == nil {
+			return nil
+		}
+		details, _ := json.Marshal(l.Details)
+		return string(details)
+	}},
+	{Name: "id", Value: func(l *domain.AuditLog) any { return l.ID }},
+}
+
+// Export godoc
+// @Summary Export audit logs
+// @Description Download every matching audit log, streamed as it is read: a JSON array by default,
+// @Description NDJSON (one entry per line) with format=ndjson or Accept: application/x-ndjson,
+// @Description CSV with format=csv or an Excel workbook with format=xlsx.
+// @Description A failed NDJSON export ends with an {"error": "..."} line, a failed CSV export with a "#error" row.
+// @Tags audit
+// @Produce json
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param userId query string false "User ID"
+// @Param action query string false "Action (e.g. UPDATE_SALE)"
+// @Param entity query string false "Entity (e.g. Sale)"
+// @Param entityId query string false "Entity ID"
+// @Param startDate query string false "From (YYYY-MM-DD or timestamp)"
+// @Param endDate query string false "To (YYYY-MM-DD includes the whole day)"
+// @Param severity query string false "Severity (info, warning, critical)"
+// @Param format query string false "json (default), ndjson, csv or xlsx"
+// @Success 200 {array} domain.AuditLog
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/audit/logs/export [get]
+// @Security BearerAuth
+func (h *AuditHandler) Export(c echo.Context) error {
+	filters, err := h.filters(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if filters == nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if format, ok := export.Negotiate(c.Request()); ok {
+		out := export.NewWriter(c.Response(), format, "audit-logs", auditExportColumns)
+		err = audit.Service.Export(c.Request().Context(), filters, out.Write)
+		return export.Finish(c, out, err)
+	}
+	out := stream.NewWriter(c.Response(), stream.Negotiate(c.Request())).AsAttachment("audit-logs")
+	err = audit.Service.Export(c.Request().Context(), filters, func(log *domain.AuditLog) error {
+		return out.Write(log)
+	})
+	return export.Finish(c, out, err)
+}
+
+// filters reads the search filters, always scoped to the caller's tenant.
+// It returns nil filters when the request has no tenant.
+func (h *AuditHandler) filters(c echo.Context) (*repository.AuditSearchFilters, error) {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return nil, nil
+	}
+
+	filters := &repository.AuditSearchFilters{TenantID: &tenantID}
+
+	if value := c.QueryParam("userId"); value != "" {
+		userID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, errors.New("Invalid user ID")
+		}
+		filters.UserID = &userID
+	}
+
+	optional := func(name string) *string {
+		if value := c.QueryParam(name); value != "" {
+			return &value
+		}
+		return nil
+	}
+	filters.Action = optional("action")
+	filters.Entity = optional("entity")
+	filters.EntityID = optional("entityId")
+	filters.StartDate = optional("startDate")
+	filters.EndDate = optional("endDate")
+	if severity := optional("severity"); severity != nil {
+		if _, err := domain.ParseSeverity(*severity); err != nil {
+			return nil, err
+		}
+		filters.Severity = severity
+	}
+
+	return filters, nil
+}
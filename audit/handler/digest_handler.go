@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/audit"
+	"github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// DigestHandler handles an owner's daily digest settings
+type DigestHandler struct{}
+
+// NewDigestHandler creates a new digest handler
+func NewDigestHandler() *DigestHandler {
+	return &DigestHandler{}
+}
+
+// UpdateDigestRequest is an owner's digest setting
+type UpdateDigestRequest struct {
+	IsEnabled   bool   `json:"isEnabled"`
+	MinSeverity string `json:"minSeverity"` // info, warning or critical (default)
+}
+
+// GetSubscription godoc
+// @Summary Get digest setting
+// @Description Get the owner's daily digest setting. Owners receive critical events unless they change it.
+// @Tags audit
+// @Produce json
+// @Success 200 {object} domain.DigestSubscription
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/audit/digest [get]
+// @Security BearerAuth
+func (h *DigestHandler) GetSubscription(c echo.Context) error {
+	tenantID, userID, ok := h.caller(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant or user not found"})
+	}
+
+	sub, err := audit.DigestService.GetSubscription(c.Request().Context(), tenantID, userID)
+	if err != nil {
+		return digestError(c, err)
+	}
+	return c.JSON(http.StatusOK, sub)
+}
+
+// UpdateSubscription godoc
+// @Summary Update digest setting
+// @Description Turn the owner's daily digest on or off and choose the lowest severity it includes
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param request body UpdateDigestRequest true "Digest setting"
+// @Success 200 {object} domain.DigestSubscription
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/audit/digest [put]
+// @Security BearerAuth
+func (h *DigestHandler) UpdateSubscription(c echo.Context) error {
+	tenantID, userID, ok := h.caller(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant or user not found"})
+	}
+
+	var req UpdateDigestRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if req.MinSeverity == "" {
+		req.MinSeverity = string(domain.SeverityCritical)
+	}
+	severity, err := domain.ParseSeverity(req.MinSeverity)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	sub, err := audit.DigestService.UpdateSubscription(c.Request().Context(), tenantID, userID, req.IsEnabled, severity)
+	if err != nil {
+		return digestError(c, err)
+	}
+	return c.JSON(http.StatusOK, sub)
+}
+
+// Preview godoc
+// @Summary Preview digest
+// @Description Get the events the owner's next digest would include
+// @Tags audit
+// @Produce json
+// @Success 200 {object} domain.Digest
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/audit/digest/preview [get]
+// @Security BearerAuth
+func (h *DigestHandler) Preview(c echo.Context) error {
+	tenantID, userID, ok := h.caller(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant or user not found"})
+	}
+
+	digest, err := audit.DigestService.Preview(c.Request().Context(), tenantID, userID)
+	if err != nil {
+		return digestError(c, err)
+	}
+	return c.JSON(http.StatusOK, digest)
+}
+
+// caller returns the request's tenant and user
+func (h *DigestHandler) caller(c echo.Context) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	return tenantID, userID, ok
+}
+
+// digestError maps digest errors to HTTP statuses
+func digestError(c echo.Context, err error) error {
+	if errors.Is(err, domain.ErrNotTenantOwner) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
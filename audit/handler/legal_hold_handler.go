@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/audit"
+	"github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// LegalHoldHandler handles super admins' legal holds on audit retention
+type LegalHoldHandler struct{}
+
+// NewLegalHoldHandler creates a new legal hold handler
+func NewLegalHoldHandler() *LegalHoldHandler {
+	return &LegalHoldHandler{}
+}
+
+// PlaceLegalHoldRequest places a hold on a tenant's audit entries
+type PlaceLegalHoldRequest struct {
+	TenantID uuid.UUID `json:"tenantId" validate:"required"`
+	Entity   *string   `json:"entity,omitempty"`   // e.g. Invoice; omit to hold every entry of the tenant
+	EntityID *string   `json:"entityId,omitempty"` // Omit to hold every entry of the entity type
+	Reason   string    `json:"reason" validate:"required"`
+}
+
+// ReleaseLegalHoldRequest gives the reason a hold is lifted
+type ReleaseLegalHoldRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// ListHolds godoc
+// @Summary List legal holds
+// @Description Legal holds on audit retention, newest first (super admin)
+// @Tags admin
+// @Produce json
+// @Param tenantId query string false "Tenant ID"
+// @Param active query bool false "Only holds not yet released"
+// @Success 200 {array} domain.LegalHold
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/admin/audit/legal-holds [get]
+// @Security BearerAuth
+func (h *LegalHoldHandler) ListHolds(c echo.Context) error {
+	filter := domain.LegalHoldFilter{ActiveOnly: c.QueryParam("active") == "true"}
+	if raw := c.QueryParam("tenantId"); raw != "" {
+		tenantID, err := uuid.Parse(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tenant ID"})
+		}
+		filter.TenantID = &tenantID
+	}
+
+	holds, err := audit.RetentionService.ListHolds(c.Request().Context(), filter)
+	if err != nil {
+		return legalHoldError(c, err)
+	}
+	return c.JSON(http.StatusOK, holds)
+}
+
+// PlaceHold godoc
+// @Summary Place a legal hold
+// @Description Keep a tenant's audit entries, or those of an entity type or one entity, from being purged by
+// @Description audit retention until the hold is released (super admin). Logged to the tenant's audit trail.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body PlaceLegalHoldRequest true "Hold"
+// @Success 201 {object} domain.LegalHold
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/admin/audit/legal-holds [post]
+// @Security BearerAuth
+func (h *LegalHoldHandler) PlaceHold(c echo.Context) error {
+	var req PlaceLegalHoldRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	hold, err := audit.RetentionService.PlaceHold(c.Request().Context(), req.TenantID, req.Entity, req.EntityID, req.Reason, callerID(c))
+	if err != nil {
+		return legalHoldError(c, err)
+	}
+	return c.JSON(http.StatusCreated, hold)
+}
+
+// ReleaseHold godoc
+// @Summary Release a legal hold
+// @Description Lift a hold with a reason (super admin). Entries it kept are purged by the next retention run
+// @Description unless another hold covers them. The hold stays listed as released.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Param request body ReleaseLegalHoldRequest true "Reason"
+// @Success 200 {object} domain.LegalHold
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/admin/audit/legal-holds/{id}/release [post]
+// @Security BearerAuth
+func (h *LegalHoldHandler) ReleaseHold(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid hold ID"})
+	}
+
+	var req ReleaseLegalHoldRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	hold, err := audit.RetentionService.ReleaseHold(c.Request().Context(), id, req.Reason, callerID(c))
+	if err != nil {
+		return legalHoldError(c, err)
+	}
+	return c.JSON(http.StatusOK, hold)
+}
+
+// callerID returns the calling user, if known
+func callerID(c echo.Context) *uuid.UUID {
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		return &userID
+	}
+	return nil
+}
+
+// legalHoldError maps legal hold errors to HTTP statuses
+func legalHoldError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidLegalHold):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrLegalHoldNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrLegalHoldReleased):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
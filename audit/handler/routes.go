@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/aceextension/core/jobs"
+	"github.com/aceextension/core/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes registers the audit trail routes
+func RegisterRoutes(e *echo.Echo) {
+	auditHandler := NewAuditHandler()
+
+	// API v1 group
+	v1 := e.Group("/api/v1")
+
+	// Apply tenant middleware
+	v1.Use(middleware.TenantMiddleware)
+
+	logs := v1.Group("/audit/logs")
+	{
+		logs.GET("", auditHandler.Search)
+		logs.GET("/export", auditHandler.Export, jobs.Async)
+	}
+}
+
+// RegisterDigestRoutes mounts an owner's daily digest settings on g. The group must
+// authenticate the user (e.g. /api/v1/audit/digest behind the JWT middleware).
+func RegisterDigestRoutes(g *echo.Group) {
+	digestHandler := NewDigestHandler()
+
+	g.GET("", digestHandler.GetSubscription)
+	g.PUT("", digestHandler.UpdateSubscription)
+	g.GET("/preview", digestHandler.Preview)
+}
+
+// RegisterLegalHoldRoutes mounts legal hold management on g. The group must restrict
+// access to super admins (e.g. /api/v1/admin/audit/legal-holds behind the JWT
+// middleware and RequireRole("super_admin")).
+func RegisterLegalHoldRoutes(g *echo.Group) {
+	legalHoldHandler := NewLegalHoldHandler()
+
+	g.GET("", legalHoldHandler.ListHolds)
+	g.POST("", legalHoldHandler.PlaceHold)
+	g.POST("/:id/release", legalHoldHandler.ReleaseHold)
+}
@@ -0,0 +1,31 @@
+-- Migration: Audit search indexes
+-- Audit logs are append-only and inserted in created_at order, so BRIN indexes stay
+-- tiny while still pruning months of data; btree indexes serve the selective lookups.
+
+-- Date-range scans over a tenant's history
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_created_brin
+    ON audit_logs USING BRIN (tenant_id, created_at) WITH (pages_per_range = 32);
+
+-- Entity history within a tenant ("what happened to sale X")
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_entity
+    ON audit_logs(tenant_id, entity, entity_id, created_at DESC);
+
+-- User and action searches within a tenant; system events have no user
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_user
+    ON audit_logs(tenant_id, user_id, created_at DESC)
+    WHERE user_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_action
+    ON audit_logs(tenant_id, action, created_at DESC);
+
+-- Super admin actions are logged without a tenant
+CREATE INDEX IF NOT EXISTS idx_audit_logs_platform
+    ON audit_logs(created_at DESC)
+    WHERE tenant_id IS NULL;
+
+-- Superseded by the tenant-scoped indexes above
+DROP INDEX IF EXISTS idx_audit_logs_action;
+
+ANALYZE audit_logs;
+
+COMMENT ON INDEX idx_audit_logs_tenant_created_brin IS 'BRIN over insertion-ordered rows for tenant date-range searches';
@@ -0,0 +1,26 @@
+-- Migration: Event severity and owners' daily digest
+-- Severity is set when the entry is written, from the action's severity rule.
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS severity VARCHAR(20) NOT NULL DEFAULT 'info';
+
+-- Digest and "critical only" searches skip the bulk of routine entries
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_severity
+    ON audit_logs(tenant_id, severity, created_at DESC)
+    WHERE severity <> 'info';
+
+-- Owners without a row receive critical events; a row records opting out or a lower threshold
+CREATE TABLE IF NOT EXISTS audit_digest_subscriptions (
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    min_severity VARCHAR(20) NOT NULL DEFAULT 'critical' CHECK (min_severity IN ('info', 'warning', 'critical')),
+    last_sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, user_id)
+);
+
+COMMENT ON COLUMN audit_logs.severity IS 'info, warning or critical; critical events go into the owners'' daily digest';
+COMMENT ON TABLE audit_digest_subscriptions IS 'Per-owner daily digest settings; owners are users in the main database';
+COMMENT ON COLUMN audit_digest_subscriptions.last_sent_at IS 'End of the period covered by the last digest sent';
@@ -0,0 +1,27 @@
+-- Migration: Legal holds on audit retention
+-- While a hold is active, the retention purge keeps the tenant's entries it covers.
+
+CREATE TABLE IF NOT EXISTS audit_legal_holds (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    entity VARCHAR(100),
+    entity_id TEXT,
+    reason TEXT NOT NULL,
+    placed_by UUID,
+    placed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    released_by UUID,
+    released_at TIMESTAMP,
+    release_reason TEXT,
+
+    CONSTRAINT chk_audit_legal_holds_entity CHECK (entity_id IS NULL OR entity IS NOT NULL)
+);
+
+-- The purge checks every expired entry against the active holds of its tenant
+CREATE INDEX IF NOT EXISTS idx_audit_legal_holds_active
+    ON audit_legal_holds(tenant_id, entity, entity_id)
+    WHERE released_at IS NULL;
+
+COMMENT ON TABLE audit_legal_holds IS 'Holds exempting audit entries from the retention purge; released holds are kept as history';
+COMMENT ON COLUMN audit_legal_holds.entity IS 'Entity type held; NULL holds every entry of the tenant';
+COMMENT ON COLUMN audit_legal_holds.entity_id IS 'Entity held; NULL holds every entry of the entity type';
+COMMENT ON COLUMN audit_legal_holds.placed_by IS 'Super admin who placed the hold, a user in the main database';
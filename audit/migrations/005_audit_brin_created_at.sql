@@ -0,0 +1,13 @@
+-- Migration: BRIN over created_at only
+-- Tenants' entries are interleaved, so tenant_id in a BRIN summarizes to near the full
+-- UUID range in every block and prunes nothing. The BRIN keeps to the insertion order;
+-- tenant filtering stays with the btree on (tenant_id, created_at).
+
+DROP INDEX IF EXISTS idx_audit_logs_tenant_created_brin;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_brin
+    ON audit_logs USING BRIN (created_at) WITH (pages_per_range = 32);
+
+ANALYZE audit_logs;
+
+COMMENT ON INDEX idx_audit_logs_created_brin IS 'BRIN over insertion-ordered rows for date-range searches';
@@ -26,6 +26,15 @@ type AuditRepository interface {
 
 	// Search retrieves audit logs with filters
 	Search(ctx context.Context, filters *AuditSearchFilters) ([]*domain.AuditLog, error)
+
+	// StreamSearch passes each matching audit log to fn as it is read, for exports
+	StreamSearch(ctx context.Context, filters *AuditSearchFilters, fn func(*domain.AuditLog) error) error
+
+	// ExplainSearches reports how the hot search paths are planned
+	ExplainSearches(ctx context.Context) ([]*SearchPlan, error)
+
+	// VerifySearchPlans fails if a hot search path has no usable index
+	VerifySearchPlans(ctx context.Context) error
 }
 
 // AuditSearchFilters defines search criteria for audit logs
@@ -37,6 +46,7 @@ type AuditSearchFilters struct {
 	EntityID  *string
 	StartDate *string
 	EndDate   *string
+	Severity  *string
 	Limit     int
 	Offset    int
 }
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/audit/domain"
+	"github.com/google/uuid"
+)
+
+// DigestRepository stores owners' digest settings and reads the events they summarize
+type DigestRepository interface {
+	// GetSubscription retrieves an owner's setting; nil when none was saved
+	GetSubscription(ctx context.Context, tenantID, userID uuid.UUID) (*domain.DigestSubscription, error)
+
+	// SaveSubscription creates or replaces an owner's setting
+	SaveSubscription(ctx context.Context, sub *domain.DigestSubscription) error
+
+	// ListSubscriptions retrieves every saved setting across tenants
+	ListSubscriptions(ctx context.Context) ([]*domain.DigestSubscription, error)
+
+	// MarkSent records when an owner's digest was last sent
+	MarkSent(ctx context.Context, tenantID, userID uuid.UUID, sentAt time.Time) error
+
+	// Events retrieves a tenant's events with the given severities in from..to, newest
+	// first, up to limit, with the total count of matching events
+	Events(ctx context.Context, tenantID uuid.UUID, severities []string, from, to time.Time, limit int) ([]*domain.AuditLog, int, error)
+
+	// ListOwners retrieves active tenant owners with an email address, from the main database
+	ListOwners(ctx context.Context) ([]*domain.DigestOwner, error)
+
+	// GetOwner retrieves a user if they are an active owner of the tenant; nil otherwise
+	GetOwner(ctx context.Context, tenantID, userID uuid.UUID) (*domain.DigestOwner, error)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/audit/domain"
+	"github.com/google/uuid"
+)
+
+// LegalHoldRepository stores legal holds and purges audit entries past retention
+type LegalHoldRepository interface {
+	// Create saves a new hold
+	Create(ctx context.Context, hold *domain.LegalHold) error
+
+	// Get retrieves a hold; ErrLegalHoldNotFound if there is none
+	Get(ctx context.Context, id uuid.UUID) (*domain.LegalHold, error)
+
+	// List retrieves holds, newest first
+	List(ctx context.Context, filter domain.LegalHoldFilter) ([]*domain.LegalHold, error)
+
+	// SaveRelease records a hold's release
+	SaveRelease(ctx context.Context, hold *domain.LegalHold) error
+
+	// PurgeBefore deletes up to limit entries created before the cutoff that no active
+	// hold covers, other than legal hold changes, and returns how many it deleted
+	PurgeBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+
+	// CountHeld counts entries created before the cutoff that an active hold covers
+	CountHeld(ctx context.Context, before time.Time) (int64, error)
+}
@@ -22,9 +22,9 @@ func NewPostgresAuditRepository() *PostgresAuditRepository {
 func (r *PostgresAuditRepository) Create(ctx context.Context, log *domain.AuditLog) error {
 	query := `
 		INSERT INTO audit_logs (
-			id, tenant_id, user_id, action, entity, entity_id,
+			id, tenant_id, user_id, action, severity, entity, entity_id,
 			ip_address, user_agent, details, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	// Convert details to JSON
@@ -42,6 +42,7 @@ func (r *PostgresAuditRepository) Create(ctx context.Context, log *domain.AuditL
 		log.TenantID,
 		log.UserID,
 		log.Action,
+		log.Severity,
 		log.Entity,
 		log.EntityID,
 		log.IPAddress,
@@ -60,7 +61,7 @@ func (r *PostgresAuditRepository) Create(ctx context.Context, log *domain.AuditL
 // GetByID retrieves an audit log by ID
 func (r *PostgresAuditRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.AuditLog, error) {
 	query := `
-		SELECT id, tenant_id, user_id, action, entity, entity_id,
+		SELECT id, tenant_id, user_id, action, severity, entity, entity_id,
 		       ip_address, user_agent, details, created_at
 		FROM audit_logs
 		WHERE id = $1
@@ -74,6 +75,7 @@ func (r *PostgresAuditRepository) GetByID(ctx context.Context, id uuid.UUID) (*d
 		&log.TenantID,
 		&log.UserID,
 		&log.Action,
+		&log.Severity,
 		&log.Entity,
 		&log.EntityID,
 		&log.IPAddress,
@@ -99,7 +101,7 @@ func (r *PostgresAuditRepository) GetByID(ctx context.Context, id uuid.UUID) (*d
 // GetByTenantID retrieves audit logs for a specific tenant
 func (r *PostgresAuditRepository) GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.AuditLog, error) {
 	query := `
-		SELECT id, tenant_id, user_id, action, entity, entity_id,
+		SELECT id, tenant_id, user_id, action, severity, entity, entity_id,
 		       ip_address, user_agent, details, created_at
 		FROM audit_logs
 		WHERE tenant_id = $1
@@ -119,7 +121,7 @@ func (r *PostgresAuditRepository) GetByTenantID(ctx context.Context, tenantID uu
 // GetByEntity retrieves audit logs for a specific entity
 func (r *PostgresAuditRepository) GetByEntity(ctx context.Context, entity string, entityID string, limit, offset int) ([]*domain.AuditLog, error) {
 	query := `
-		SELECT id, tenant_id, user_id, action, entity, entity_id,
+		SELECT id, tenant_id, user_id, action, severity, entity, entity_id,
 		       ip_address, user_agent, details, created_at
 		FROM audit_logs
 		WHERE entity = $1 AND entity_id = $2
@@ -139,7 +141,7 @@ func (r *PostgresAuditRepository) GetByEntity(ctx context.Context, entity string
 // GetByUserID retrieves audit logs for a specific user
 func (r *PostgresAuditRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.AuditLog, error) {
 	query := `
-		SELECT id, tenant_id, user_id, action, entity, entity_id,
+		SELECT id, tenant_id, user_id, action, severity, entity, entity_id,
 		       ip_address, user_agent, details, created_at
 		FROM audit_logs
 		WHERE user_id = $1
@@ -156,79 +158,46 @@ func (r *PostgresAuditRepository) GetByUserID(ctx context.Context, userID uuid.U
 	return r.scanRows(rows)
 }
 
-// Search retrieves audit logs with filters
+// Search retrieves audit logs with filters. Each combination of filters maps to one
+// fixed statement (see searchStatement), so the driver's statement cache reuses the
+// prepared plan instead of re-planning a freshly assembled query on every call.
 func (r *PostgresAuditRepository) Search(ctx context.Context, filters *AuditSearchFilters) ([]*domain.AuditLog, error) {
-	query := `
-		SELECT id, tenant_id, user_id, action, entity, entity_id,
-		       ip_address, user_agent, details, created_at
-		FROM audit_logs
-		WHERE 1=1
-	`
-	args := []interface{}{}
-	argCount := 1
+	shape, args := filters.shape()
 
-	if filters.TenantID != nil {
-		query += fmt.Sprintf(" AND tenant_id = $%d", argCount)
-		args = append(args, *filters.TenantID)
-		argCount++
-	}
-
-	if filters.UserID != nil {
-		query += fmt.Sprintf(" AND user_id = $%d", argCount)
-		args = append(args, *filters.UserID)
-		argCount++
-	}
-
-	if filters.Action != nil {
-		query += fmt.Sprintf(" AND action = $%d", argCount)
-		args = append(args, *filters.Action)
-		argCount++
-	}
-
-	if filters.Entity != nil {
-		query += fmt.Sprintf(" AND entity = $%d", argCount)
-		args = append(args, *filters.Entity)
-		argCount++
-	}
-
-	if filters.EntityID != nil {
-		query += fmt.Sprintf(" AND entity_id = $%d", argCount)
-		args = append(args, *filters.EntityID)
-		argCount++
-	}
-
-	if filters.StartDate != nil {
-		query += fmt.Sprintf(" AND created_at >= $%d", argCount)
-		args = append(args, *filters.StartDate)
-		argCount++
+	rows, err := db.AuditPool.Query(ctx, searchStatement(shape), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search audit logs: %w", err)
 	}
+	defer rows.Close()
 
-	if filters.EndDate != nil {
-		query += fmt.Sprintf(" AND created_at <= $%d", argCount)
-		args = append(args, *filters.EndDate)
-		argCount++
-	}
+	return r.scanRows(rows)
+}
 
-	query += " ORDER BY created_at DESC"
+// StreamSearch passes each matching audit log to fn as it is read, without collecting them
+func (r *PostgresAuditRepository) StreamSearch(ctx context.Context, filters *AuditSearchFilters, fn func(*domain.AuditLog) error) error {
+	shape, args := filters.shape()
 
-	if filters.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argCount)
-		args = append(args, filters.Limit)
-		argCount++
+	rows, err := db.AuditPool.Query(ctx, searchStatement(shape), args...)
+	if err != nil {
+		return fmt.Errorf("failed to search audit logs: %w", err)
 	}
+	defer rows.Close()
 
-	if filters.Offset > 0 {
-		query += fmt.Sprintf(" OFFSET $%d", argCount)
-		args = append(args, filters.Offset)
+	for rows.Next() {
+		log, err := r.scanRow(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
 	}
 
-	rows, err := db.AuditPool.Query(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search audit logs: %w", err)
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
 	}
-	defer rows.Close()
 
-	return r.scanRows(rows)
+	return nil
 }
 
 // scanRows is a helper function to scan multiple rows
@@ -240,34 +209,11 @@ func (r *PostgresAuditRepository) scanRows(rows interface {
 	logs := []*domain.AuditLog{}
 
 	for rows.Next() {
-		var log domain.AuditLog
-		var detailsJSON []byte
-
-		err := rows.Scan(
-			&log.ID,
-			&log.TenantID,
-			&log.UserID,
-			&log.Action,
-			&log.Entity,
-			&log.EntityID,
-			&log.IPAddress,
-			&log.UserAgent,
-			&detailsJSON,
-			&log.CreatedAt,
-		)
-
+		log, err := r.scanRow(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+			return nil, err
 		}
-
-		// Unmarshal details
-		if len(detailsJSON) > 0 {
-			if err := json.Unmarshal(detailsJSON, &log.Details); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal details: %w", err)
-			}
-		}
-
-		logs = append(logs, &log)
+		logs = append(logs, log)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -276,3 +222,38 @@ func (r *PostgresAuditRepository) scanRows(rows interface {
 
 	return logs, nil
 }
+
+// scanRow scans the current row into an audit log
+func (r *PostgresAuditRepository) scanRow(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.AuditLog, error) {
+	var log domain.AuditLog
+	var detailsJSON []byte
+
+	err := row.Scan(
+		&log.ID,
+		&log.TenantID,
+		&log.UserID,
+		&log.Action,
+		&log.Severity,
+		&log.Entity,
+		&log.EntityID,
+		&log.IPAddress,
+		&log.UserAgent,
+		&detailsJSON,
+		&log.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan audit log: %w", err)
+	}
+
+	// Unmarshal details
+	if len(detailsJSON) > 0 {
+		if err := json.Unmarshal(detailsJSON, &log.Details); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal details: %w", err)
+		}
+	}
+
+	return &log, nil
+}
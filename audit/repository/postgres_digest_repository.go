@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresDigestRepository implements DigestRepository. Settings and events are in
+// the audit database; owners are read from the main database.
+type PostgresDigestRepository struct {
+	audit PostgresAuditRepository
+}
+
+// NewPostgresDigestRepository creates a new PostgreSQL digest repository
+func NewPostgresDigestRepository() *PostgresDigestRepository {
+	return &PostgresDigestRepository{}
+}
+
+const digestSubscriptionColumns = `tenant_id, user_id, is_enabled, min_severity, last_sent_at, created_at, updated_at`
+
+// GetSubscription retrieves an owner's setting
+func (r *PostgresDigestRepository) GetSubscription(ctx context.Context, tenantID, userID uuid.UUID) (*domain.DigestSubscription, error) {
+	query := `SELECT ` + digestSubscriptionColumns + ` FROM audit_digest_subscriptions WHERE tenant_id = $1 AND user_id = $2`
+
+	sub, err := scanDigestSubscription(db.AuditPool.QueryRow(ctx, query, tenantID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// SaveSubscription upserts an owner's setting, keeping when the digest was last sent
+func (r *PostgresDigestRepository) SaveSubscription(ctx context.Context, sub *domain.DigestSubscription) error {
+	query := `
+		INSERT INTO audit_digest_subscriptions (` + digestSubscriptionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE
+		SET is_enabled = EXCLUDED.is_enabled, min_severity = EXCLUDED.min_severity, updated_at = EXCLUDED.updated_at
+	`
+	_, err := db.AuditPool.Exec(ctx, query,
+		sub.TenantID, sub.UserID, sub.IsEnabled, sub.MinSeverity, sub.LastSentAt, sub.CreatedAt, sub.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save digest subscription: %w", err)
+	}
+	return nil
+}
+
+// ListSubscriptions retrieves every saved setting
+func (r *PostgresDigestRepository) ListSubscriptions(ctx context.Context) ([]*domain.DigestSubscription, error) {
+	query := `SELECT ` + digestSubscriptionColumns + ` FROM audit_digest_subscriptions`
+
+	rows, err := db.AuditPool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query digest subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []*domain.DigestSubscription{}
+	for rows.Next() {
+		sub, err := scanDigestSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan digest subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// MarkSent records the send time, creating the default setting if the owner had none
+func (r *PostgresDigestRepository) MarkSent(ctx context.Context, tenantID, userID uuid.UUID, sentAt time.Time) error {
+	query := `
+		INSERT INTO audit_digest_subscriptions (tenant_id, user_id, is_enabled, min_severity, last_sent_at, created_at, updated_at)
+		VALUES ($1, $2, true, $3, $4, $4, $4)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET last_sent_at = EXCLUDED.last_sent_at
+	`
+	if _, err := db.AuditPool.Exec(ctx, query, tenantID, userID, domain.SeverityCritical, sentAt); err != nil {
+		return fmt.Errorf("failed to mark digest sent: %w", err)
+	}
+	return nil
+}
+
+// Events retrieves a tenant's events by severity in a time range
+func (r *PostgresDigestRepository) Events(ctx context.Context, tenantID uuid.UUID, severities []string, from, to time.Time, limit int) ([]*domain.AuditLog, int, error) {
+	var total int
+	countQuery := `
+		SELECT COUNT(*) FROM audit_logs
+		WHERE tenant_id = $1 AND severity = ANY($2) AND created_at >= $3 AND created_at < $4
+	`
+	if err := db.AuditPool.QueryRow(ctx, countQuery, tenantID, severities, from, to).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count digest events: %w", err)
+	}
+	if total == 0 {
+		return []*domain.AuditLog{}, 0, nil
+	}
+
+	query := `
+		SELECT id, tenant_id, user_id, action, severity, entity, entity_id,
+		       ip_address, user_agent, details, created_at
+		FROM audit_logs
+		WHERE tenant_id = $1 AND severity = ANY($2) AND created_at >= $3 AND created_at < $4
+		ORDER BY created_at DESC, id DESC
+		LIMIT $5
+	`
+	rows, err := db.AuditPool.Query(ctx, query, tenantID, severities, from, to, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query digest events: %w", err)
+	}
+	defer rows.Close()
+
+	logs, err := r.audit.scanRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// digestOwnersQuery selects active owners of active tenants. users.role covers the
+// home tenant and memberships the others; guests are never owners.
+const digestOwnersQuery = `
+	SELECT o.tenant_id, COALESCE(t.business_name, t.name), u.id, u.name, u.email
+	FROM (
+		SELECT id AS user_id, tenant_id FROM users WHERE role = 'owner' AND tenant_id IS NOT NULL
+		UNION
+		SELECT user_id, tenant_id FROM tenant_memberships
+		WHERE role = 'owner' AND is_active = true AND is_guest = false AND revoked_at IS NULL
+	) o
+	JOIN users u ON u.id = o.user_id
+	JOIN tenants t ON t.id = o.tenant_id
+	WHERE u.is_active = true AND t.is_active = true AND COALESCE(u.email, '') <> ''`
+
+// ListOwners retrieves the owners of every tenant
+func (r *PostgresDigestRepository) ListOwners(ctx context.Context) ([]*domain.DigestOwner, error) {
+	rows, err := db.MainPool.Query(ctx, digestOwnersQuery+` ORDER BY o.tenant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenant owners: %w", err)
+	}
+	defer rows.Close()
+
+	owners := []*domain.DigestOwner{}
+	for rows.Next() {
+		owner, err := scanDigestOwner(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tenant owner: %w", err)
+		}
+		owners = append(owners, owner)
+	}
+	return owners, rows.Err()
+}
+
+// GetOwner retrieves a user if they own the tenant; nil otherwise
+func (r *PostgresDigestRepository) GetOwner(ctx context.Context, tenantID, userID uuid.UUID) (*domain.DigestOwner, error) {
+	query := digestOwnersQuery + ` AND o.tenant_id = $1 AND o.user_id = $2`
+
+	owner, err := scanDigestOwner(db.MainPool.QueryRow(ctx, query, tenantID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant owner: %w", err)
+	}
+	return owner, nil
+}
+
+func scanDigestOwner(row pgx.Row) (*domain.DigestOwner, error) {
+	var owner domain.DigestOwner
+	if err := row.Scan(&owner.TenantID, &owner.TenantName, &owner.UserID, &owner.Name, &owner.Email); err != nil {
+		return nil, err
+	}
+	return &owner, nil
+}
+
+func scanDigestSubscription(row pgx.Row) (*domain.DigestSubscription, error) {
+	var sub domain.DigestSubscription
+	err := row.Scan(&sub.TenantID, &sub.UserID, &sub.IsEnabled, &sub.MinSeverity, &sub.LastSentAt, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresLegalHoldRepository implements LegalHoldRepository in the audit database
+type PostgresLegalHoldRepository struct{}
+
+// NewPostgresLegalHoldRepository creates a new PostgreSQL legal hold repository
+func NewPostgresLegalHoldRepository() *PostgresLegalHoldRepository {
+	return &PostgresLegalHoldRepository{}
+}
+
+const legalHoldColumns = `id, tenant_id, entity, entity_id, reason, placed_by, placed_at, released_by, released_at, release_reason`
+
+// heldEntry matches an audit_logs row l that an active hold covers
+const heldEntry = `EXISTS (
+	SELECT 1 FROM audit_legal_holds h
+	WHERE h.released_at IS NULL AND h.tenant_id = l.tenant_id
+	  AND (h.entity IS NULL OR h.entity = l.entity)
+	  AND (h.entity_id IS NULL OR h.entity_id = l.entity_id))`
+
+// Create saves a new hold
+func (r *PostgresLegalHoldRepository) Create(ctx context.Context, hold *domain.LegalHold) error {
+	query := `INSERT INTO audit_legal_holds (` + legalHoldColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := db.AuditPool.Exec(ctx, query,
+		hold.ID, hold.TenantID, hold.Entity, hold.EntityID, hold.Reason,
+		hold.PlacedBy, hold.PlacedAt, hold.ReleasedBy, hold.ReleasedAt, hold.ReleaseReason)
+	if err != nil {
+		return fmt.Errorf("failed to create legal hold: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a hold
+func (r *PostgresLegalHoldRepository) Get(ctx context.Context, id uuid.UUID) (*domain.LegalHold, error) {
+	query := `SELECT ` + legalHoldColumns + ` FROM audit_legal_holds WHERE id = $1`
+
+	hold, err := scanLegalHold(db.AuditPool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrLegalHoldNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legal hold: %w", err)
+	}
+	return hold, nil
+}
+
+// List retrieves holds, newest first
+func (r *PostgresLegalHoldRepository) List(ctx context.Context, filter domain.LegalHoldFilter) ([]*domain.LegalHold, error) {
+	query := `SELECT ` + legalHoldColumns + ` FROM audit_legal_holds
+		WHERE ($1::uuid IS NULL OR tenant_id = $1) AND (NOT $2 OR released_at IS NULL)
+		ORDER BY placed_at DESC`
+
+	rows, err := db.AuditPool.Query(ctx, query, filter.TenantID, filter.ActiveOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query legal holds: %w", err)
+	}
+	defer rows.Close()
+
+	holds := []*domain.LegalHold{}
+	for rows.Next() {
+		hold, err := scanLegalHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan legal hold: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+	return holds, rows.Err()
+}
+
+// SaveRelease records a hold's release, unless another release got there first
+func (r *PostgresLegalHoldRepository) SaveRelease(ctx context.Context, hold *domain.LegalHold) error {
+	query := `
+		UPDATE audit_legal_holds SET released_by = $2, released_at = $3, release_reason = $4
+		WHERE id = $1 AND released_at IS NULL
+	`
+	tag, err := db.AuditPool.Exec(ctx, query, hold.ID, hold.ReleasedBy, hold.ReleasedAt, hold.ReleaseReason)
+	if err != nil {
+		return fmt.Errorf("failed to release legal hold: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrLegalHoldReleased
+	}
+	return nil
+}
+
+// PurgeBefore deletes one batch of unheld entries past retention
+func (r *PostgresLegalHoldRepository) PurgeBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM audit_logs WHERE (id, created_at) IN (
+			SELECT l.id, l.created_at FROM audit_logs l
+			WHERE l.created_at < $1 AND l.entity <> $2 AND NOT ` + heldEntry + `
+			LIMIT $3
+		)
+	`
+	tag, err := db.AuditPool.Exec(ctx, query, before, domain.LegalHoldEntity, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge audit logs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CountHeld counts held entries past retention
+func (r *PostgresLegalHoldRepository) CountHeld(ctx context.Context, before time.Time) (int64, error) {
+	query := `SELECT COUNT(*) FROM audit_logs l WHERE l.created_at < $1 AND ` + heldEntry
+
+	var held int64
+	if err := db.AuditPool.QueryRow(ctx, query, before).Scan(&held); err != nil {
+		return 0, fmt.Errorf("failed to count held audit logs: %w", err)
+	}
+	return held, nil
+}
+
+func scanLegalHold(row pgx.Row) (*domain.LegalHold, error) {
+	var h domain.LegalHold
+	err := row.Scan(&h.ID, &h.TenantID, &h.Entity, &h.EntityID, &h.Reason,
+		&h.PlacedBy, &h.PlacedAt, &h.ReleasedBy, &h.ReleasedAt, &h.ReleaseReason)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
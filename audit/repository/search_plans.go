@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// errExplainDone rolls back the read-only explain transaction
+var errExplainDone = errors.New("explain done")
+
+// SearchPlan is the EXPLAIN outcome for one hot audit query
+type SearchPlan struct {
+	Name      string   `json:"name"`
+	Indexes   []string `json:"indexes"`   // Indexes the plan reads
+	SeqScan   bool     `json:"seqScan"`   // The plan still scans audit_logs sequentially
+	TotalCost float64  `json:"totalCost"` // Planner estimate
+}
+
+// hotSearches are the audit queries run from the UI: a tenant's recent activity, a date
+// range over months of history, an entity's trail, and a user's or action's history
+func hotSearches() []struct {
+	name    string
+	filters AuditSearchFilters
+} {
+	tenantID := uuid.New()
+	userID := uuid.New()
+	action := "UPDATE_SALE"
+	entity := "Sale"
+	entityID := uuid.NewString()
+	start := time.Now().AddDate(0, -6, 0).Format("2006-01-02")
+	end := time.Now().Format("2006-01-02")
+	critical := "critical"
+
+	return []struct {
+		name    string
+		filters AuditSearchFilters
+	}{
+		{"tenant_recent", AuditSearchFilters{TenantID: &tenantID, Limit: 50}},
+		{"tenant_date_range", AuditSearchFilters{TenantID: &tenantID, StartDate: &start, EndDate: &end, Limit: 100}},
+		{"tenant_entity", AuditSearchFilters{TenantID: &tenantID, Entity: &entity, EntityID: &entityID, Limit: 50}},
+		{"tenant_user", AuditSearchFilters{TenantID: &tenantID, UserID: &userID, StartDate: &start, Limit: 50}},
+		{"tenant_action", AuditSearchFilters{TenantID: &tenantID, Action: &action, StartDate: &start, EndDate: &end, Limit: 50}},
+		{"tenant_critical", AuditSearchFilters{TenantID: &tenantID, Severity: &critical, StartDate: &start, Limit: 50}},
+	}
+}
+
+// ExplainSearches plans each hot search with sequential scans disabled. On a small
+// table the planner prefers a sequential scan regardless, so forbidding it shows
+// whether a usable index exists: a Seq Scan that survives means one is missing.
+// Run it after migrations (e.g. in CI) to catch index regressions; the repository's
+// tests do so when AUDIT_TEST_DATABASE_URL is set.
+func (r *PostgresAuditRepository) ExplainSearches(ctx context.Context) ([]*SearchPlan, error) {
+	plans := []*SearchPlan{}
+
+	err := pgx.BeginFunc(ctx, db.AuditPool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SET LOCAL enable_seqscan = off"); err != nil {
+			return fmt.Errorf("failed to disable sequential scans: %w", err)
+		}
+
+		for _, search := range hotSearches() {
+			shape, args := search.filters.shape()
+
+			var raw []byte
+			if err := tx.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+searchStatement(shape), args...).Scan(&raw); err != nil {
+				return fmt.Errorf("failed to explain %s: %w", search.name, err)
+			}
+
+			var explained []struct {
+				Plan planNode `json:"Plan"`
+			}
+			if err := json.Unmarshal(raw, &explained); err != nil || len(explained) == 0 {
+				return fmt.Errorf("failed to read plan for %s: %v", search.name, err)
+			}
+
+			plan := &SearchPlan{Name: search.name, Indexes: []string{}, TotalCost: explained[0].Plan.TotalCost}
+			explained[0].Plan.walk(plan)
+			plans = append(plans, plan)
+		}
+
+		return errExplainDone
+	})
+	if err != nil && !errors.Is(err, errExplainDone) {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+// VerifySearchPlans fails if any hot search can only be answered by a sequential scan
+func (r *PostgresAuditRepository) VerifySearchPlans(ctx context.Context) error {
+	plans, err := r.ExplainSearches(ctx)
+	if err != nil {
+		return err
+	}
+
+	missing := []string{}
+	for _, plan := range plans {
+		if plan.SeqScan {
+			missing = append(missing, plan.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("audit searches without a usable index: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// planNode is the part of a PostgreSQL JSON plan the check reads
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	IndexName    string     `json:"Index Name"`
+	TotalCost    float64    `json:"Total Cost"`
+	Plans        []planNode `json:"Plans"`
+}
+
+func (n *planNode) walk(plan *SearchPlan) {
+	if n.NodeType == "Seq Scan" && n.RelationName == "audit_logs" {
+		plan.SeqScan = true
+	}
+	if n.IndexName != "" {
+		plan.Indexes = append(plan.Indexes, n.IndexName)
+	}
+	for i := range n.Plans {
+		n.Plans[i].walk(plan)
+	}
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TestVerifySearchPlans runs the audit migrations into a scratch schema and checks
+// every hot search can be answered from an index. It needs a PostgreSQL database in
+// AUDIT_TEST_DATABASE_URL and is skipped without one.
+func TestVerifySearchPlans(t *testing.T) {
+	connStr := os.Getenv("AUDIT_TEST_DATABASE_URL")
+	if connStr == "" {
+		t.Skip("AUDIT_TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, connStr)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer admin.Close(ctx)
+
+	schema := fmt.Sprintf("audit_plans_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	defer admin.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE")
+
+	config, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		t.Fatalf("failed to parse connection string: %v", err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer pool.Close()
+
+	files, err := filepath.Glob(filepath.Join("..", "migrations", "*.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no audit migrations found: %v", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		migration, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		if _, err := pool.Exec(ctx, string(migration)); err != nil {
+			t.Fatalf("failed to run %s: %v", filepath.Base(file), err)
+		}
+	}
+
+	previous := db.AuditPool
+	db.AuditPool = pool
+	defer func() { db.AuditPool = previous }()
+
+	repo := NewPostgresAuditRepository()
+	if err := repo.VerifySearchPlans(ctx); err != nil {
+		plans, _ := repo.ExplainSearches(ctx)
+		for _, plan := range plans {
+			t.Logf("%s: indexes %v, seq scan %t", plan.Name, plan.Indexes, plan.SeqScan)
+		}
+		t.Fatal(err)
+	}
+}
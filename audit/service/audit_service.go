@@ -11,10 +11,12 @@ import (
 
 // AuditService defines the interface for audit logging operations
 type AuditService interface {
-	// Log creates a new audit log entry (non-blocking)
+	// Log creates a new audit log entry (non-blocking). The user, tenant, IP address
+	// and User-Agent auditCtx leaves out, or all of them for a nil auditCtx, are taken
+	// from the request ctx belongs to.
 	Log(ctx context.Context, action, entity string, entityID *string, details any, auditCtx *domain.AuditContext) error
 
-	// LogSync creates a new audit log entry (blocking)
+	// LogSync creates a new audit log entry (blocking), attributed like Log
 	LogSync(ctx context.Context, action, entity string, entityID *string, details any, auditCtx *domain.AuditContext) error
 
 	// GetByID retrieves an audit log by ID
@@ -28,17 +30,32 @@ type AuditService interface {
 
 	// Search retrieves audit logs with filters
 	Search(ctx context.Context, filters *repository.AuditSearchFilters) ([]*domain.AuditLog, error)
+
+	// Export passes every matching audit log to fn as it is read; unlike Search there is no default limit
+	Export(ctx context.Context, filters *repository.AuditSearchFilters, fn func(*domain.AuditLog) error) error
+
+	// ExplainSearches reports how the hot search paths are planned
+	ExplainSearches(ctx context.Context) ([]*repository.SearchPlan, error)
+
+	// VerifySearchPlans fails if a hot search path has no usable index (run after migrations)
+	VerifySearchPlans(ctx context.Context) error
+
+	// RegisterSeverityRule sets how an action is classified, replacing any default rule.
+	// Call during module Init, before events are logged.
+	RegisterSeverityRule(action string, rule domain.SeverityRule)
 }
 
 // auditService implements AuditService
 type auditService struct {
-	repo repository.AuditRepository
+	repo  repository.AuditRepository
+	rules map[string]domain.SeverityRule
 }
 
 // NewAuditService creates a new audit service
 func NewAuditService(repo repository.AuditRepository) AuditService {
 	return &auditService{
-		repo: repo,
+		repo:  repo,
+		rules: domain.DefaultSeverityRules(),
 	}
 }
 
@@ -46,7 +63,8 @@ func NewAuditService(repo repository.AuditRepository) AuditService {
 // This is the recommended method for most use cases
 func (s *auditService) Log(ctx context.Context, action, entity string, entityID *string, details any, auditCtx *domain.AuditContext) error {
 	// Create audit log
-	log := domain.NewAuditLog(action, entity, entityID, details, auditCtx)
+	log := domain.NewAuditLog(action, entity, entityID, details, auditCtx.Fill(domain.FromContext(ctx)))
+	log.Severity = s.classify(action, details)
 
 	// Log asynchronously to avoid blocking main operations
 	go func() {
@@ -64,7 +82,8 @@ func (s *auditService) Log(ctx context.Context, action, entity string, entityID
 // LogSync creates a new audit log entry synchronously (blocking)
 // Use this when you need to ensure the audit log is written before proceeding
 func (s *auditService) LogSync(ctx context.Context, action, entity string, entityID *string, details any, auditCtx *domain.AuditContext) error {
-	log := domain.NewAuditLog(action, entity, entityID, details, auditCtx)
+	log := domain.NewAuditLog(action, entity, entityID, details, auditCtx.Fill(domain.FromContext(ctx)))
+	log.Severity = s.classify(action, details)
 
 	if err := s.repo.Create(ctx, log); err != nil {
 		return fmt.Errorf("failed to create audit log: %w", err)
@@ -101,3 +120,31 @@ func (s *auditService) Search(ctx context.Context, filters *repository.AuditSear
 	}
 	return s.repo.Search(ctx, filters)
 }
+
+// Export streams matching audit logs to fn
+func (s *auditService) Export(ctx context.Context, filters *repository.AuditSearchFilters, fn func(*domain.AuditLog) error) error {
+	return s.repo.StreamSearch(ctx, filters, fn)
+}
+
+// ExplainSearches reports how the hot search paths are planned
+func (s *auditService) ExplainSearches(ctx context.Context) ([]*repository.SearchPlan, error) {
+	return s.repo.ExplainSearches(ctx)
+}
+
+// VerifySearchPlans fails if a hot search path has no usable index
+func (s *auditService) VerifySearchPlans(ctx context.Context) error {
+	return s.repo.VerifySearchPlans(ctx)
+}
+
+// RegisterSeverityRule sets the severity rule for an action
+func (s *auditService) RegisterSeverityRule(action string, rule domain.SeverityRule) {
+	s.rules[action] = rule
+}
+
+// classify returns the action's severity; actions without a rule are info
+func (s *auditService) classify(action string, details any) domain.Severity {
+	if rule, ok := s.rules[action]; ok {
+		return rule(details)
+	}
+	return domain.SeverityInfo
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/audit/domain"
+	"github.com/aceextension/audit/repository"
+	"github.com/aceextension/notification"
+	notificationDomain "github.com/aceextension/notification/domain"
+	notificationService "github.com/aceextension/notification/service"
+	"github.com/google/uuid"
+)
+
+// DigestTemplateCode is the tenant email template that replaces the built-in digest text.
+// It can use ownerName, tenantName, date, total and events (one line per event).
+const DigestTemplateCode = "AUDIT_DIGEST"
+
+// DigestService sends tenant owners a daily summary of important audit events
+type DigestService interface {
+	// GetSubscription returns the owner's digest setting, or the default when none was saved
+	GetSubscription(ctx context.Context, tenantID, userID uuid.UUID) (*domain.DigestSubscription, error)
+
+	// UpdateSubscription turns the owner's digest on or off and sets the lowest severity included
+	UpdateSubscription(ctx context.Context, tenantID, userID uuid.UUID, enabled bool, minSeverity domain.Severity) (*domain.DigestSubscription, error)
+
+	// Preview returns what the owner's next digest would contain
+	Preview(ctx context.Context, tenantID, userID uuid.UUID) (*domain.Digest, error)
+
+	// RunDaily emails each subscribed owner the events since their last digest.
+	// One owner's failure does not stop the rest.
+	RunDaily(ctx context.Context) error
+}
+
+type digestService struct {
+	repo repository.DigestRepository
+}
+
+// NewDigestService creates a new digest service
+func NewDigestService(repo repository.DigestRepository) DigestService {
+	return &digestService{repo: repo}
+}
+
+// GetSubscription returns the owner's setting
+func (s *digestService) GetSubscription(ctx context.Context, tenantID, userID uuid.UUID) (*domain.DigestSubscription, error) {
+	if _, err := s.owner(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+	return s.subscription(ctx, tenantID, userID)
+}
+
+// UpdateSubscription saves the owner's setting
+func (s *digestService) UpdateSubscription(ctx context.Context, tenantID, userID uuid.UUID, enabled bool, minSeverity domain.Severity) (*domain.DigestSubscription, error) {
+	if _, err := s.owner(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+	sub, err := s.subscription(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.IsEnabled = enabled
+	sub.MinSeverity = minSeverity
+	sub.UpdatedAt = time.Now()
+	if err := s.repo.SaveSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Preview builds the owner's next digest without sending it
+func (s *digestService) Preview(ctx context.Context, tenantID, userID uuid.UUID) (*domain.Digest, error) {
+	owner, err := s.owner(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.subscription(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.build(ctx, owner, sub, time.Now())
+}
+
+// RunDaily sends the digests due today
+func (s *digestService) RunDaily(ctx context.Context) error {
+	owners, err := s.repo.ListOwners(ctx)
+	if err != nil {
+		return err
+	}
+	saved, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	type key struct{ tenantID, userID uuid.UUID }
+	subs := make(map[key]*domain.DigestSubscription, len(saved))
+	for _, sub := range saved {
+		subs[key{sub.TenantID, sub.UserID}] = sub
+	}
+
+	now := time.Now()
+	var sent, failed int
+	for _, owner := range owners {
+		sub, ok := subs[key{owner.TenantID, owner.UserID}]
+		if !ok {
+			sub = domain.NewDigestSubscription(owner.TenantID, owner.UserID)
+		}
+		if !sub.IsEnabled {
+			continue
+		}
+
+		delivered, err := s.send(ctx, owner, sub, now)
+		if err != nil {
+			failed++
+			fmt.Printf("Audit digest: failed for owner %s of tenant %s: %v\n", owner.UserID, owner.TenantID, err)
+			continue
+		}
+		if delivered {
+			sent++
+		}
+	}
+
+	fmt.Printf("Audit digest: sent %d, failed %d\n", sent, failed)
+	return nil
+}
+
+// send emails one owner's digest; nothing is sent when there were no events
+func (s *digestService) send(ctx context.Context, owner *domain.DigestOwner, sub *domain.DigestSubscription, now time.Time) (bool, error) {
+	digest, err := s.build(ctx, owner, sub, now)
+	if err != nil {
+		return false, err
+	}
+	if digest.Total == 0 {
+		return false, nil
+	}
+	if notification.Service == nil {
+		return false, errors.New("notification module is not initialized")
+	}
+
+	req := notificationService.SendRequest{
+		TenantID:  owner.TenantID,
+		UserID:    &owner.UserID,
+		Channel:   notificationDomain.ChannelEmail,
+		Recipient: owner.Email,
+		Content:   digest.Subject() + "\n\n" + digest.Body(owner.Name),
+		Priority:  notificationDomain.PriorityLow,
+	}
+	if template, err := notification.TemplateRepo.GetByCode(ctx, owner.TenantID, DigestTemplateCode, notificationDomain.ChannelEmail); err == nil && template.IsActive {
+		req.TemplateID = &template.ID
+		req.Variables = map[string]interface{}{
+			"ownerName":  owner.Name,
+			"tenantName": digest.TenantName,
+			"date":       digest.To.Format("2006-01-02"),
+			"total":      digest.Total,
+			"events":     digest.Lines(),
+		}
+	}
+	referenceType := "AUDIT_DIGEST"
+	req.ReferenceType = &referenceType
+
+	if _, err := notification.Service.Send(ctx, req); err != nil {
+		return false, err
+	}
+
+	// The next digest starts where this one ended
+	return true, s.repo.MarkSent(ctx, owner.TenantID, owner.UserID, digest.To)
+}
+
+// build collects the events since the last digest, or over the past day for the first one
+func (s *digestService) build(ctx context.Context, owner *domain.DigestOwner, sub *domain.DigestSubscription, now time.Time) (*domain.Digest, error) {
+	from := now.Add(-24 * time.Hour)
+	if sub.LastSentAt != nil {
+		from = *sub.LastSentAt
+	}
+
+	events, total, err := s.repo.Events(ctx, owner.TenantID, sub.Severities(), from, now, domain.DigestMaxEvents)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Digest{
+		TenantID:   owner.TenantID,
+		TenantName: owner.TenantName,
+		From:       from,
+		To:         now,
+		Events:     events,
+		Total:      total,
+	}, nil
+}
+
+// owner returns the user if they own the tenant, else ErrNotTenantOwner
+func (s *digestService) owner(ctx context.Context, tenantID, userID uuid.UUID) (*domain.DigestOwner, error) {
+	owner, err := s.repo.GetOwner(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, domain.ErrNotTenantOwner
+	}
+	return owner, nil
+}
+
+// subscription returns the saved setting or the default
+func (s *digestService) subscription(ctx context.Context, tenantID, userID uuid.UUID) (*domain.DigestSubscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		sub = domain.NewDigestSubscription(tenantID, userID)
+	}
+	return sub, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/audit/domain"
+	"github.com/aceextension/audit/repository"
+	"github.com/google/uuid"
+)
+
+// purgeBatchSize is how many entries one retention delete removes, so a first run
+// over years of history does not hold one long transaction
+const purgeBatchSize = 5000
+
+// RetentionService purges audit entries past retention, except those under legal hold
+type RetentionService interface {
+	// PlaceHold exempts a tenant's entries, or those of an entity type or entity, from
+	// the purge until the hold is released. Logged as PLACE_LEGAL_HOLD.
+	PlaceHold(ctx context.Context, tenantID uuid.UUID, entity, entityID *string, reason string, placedBy *uuid.UUID) (*domain.LegalHold, error)
+
+	// ReleaseHold lifts a hold with a reason. Logged as RELEASE_LEGAL_HOLD.
+	ReleaseHold(ctx context.Context, id uuid.UUID, reason string, releasedBy *uuid.UUID) (*domain.LegalHold, error)
+
+	// ListHolds returns holds, newest first
+	ListHolds(ctx context.Context, filter domain.LegalHoldFilter) ([]*domain.LegalHold, error)
+
+	// Purge deletes entries created before the cutoff that no active hold covers.
+	// Legal hold changes are never purged.
+	Purge(ctx context.Context, before time.Time) (*domain.RetentionRun, error)
+}
+
+type retentionService struct {
+	repo  repository.LegalHoldRepository
+	audit AuditService
+}
+
+// NewRetentionService creates a new retention service; hold changes are logged through audit
+func NewRetentionService(repo repository.LegalHoldRepository, audit AuditService) RetentionService {
+	return &retentionService{repo: repo, audit: audit}
+}
+
+// PlaceHold saves and logs a new hold
+func (s *retentionService) PlaceHold(ctx context.Context, tenantID uuid.UUID, entity, entityID *string, reason string, placedBy *uuid.UUID) (*domain.LegalHold, error) {
+	hold, err := domain.NewLegalHold(tenantID, entity, entityID, reason, placedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, hold); err != nil {
+		return nil, err
+	}
+
+	s.logHold(ctx, "PLACE_LEGAL_HOLD", hold, placedBy)
+	return hold, nil
+}
+
+// ReleaseHold records and logs a hold's release
+func (s *retentionService) ReleaseHold(ctx context.Context, id uuid.UUID, reason string, releasedBy *uuid.UUID) (*domain.LegalHold, error) {
+	hold, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := hold.Release(reason, releasedBy); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveRelease(ctx, hold); err != nil {
+		return nil, err
+	}
+
+	s.logHold(ctx, "RELEASE_LEGAL_HOLD", hold, releasedBy)
+	return hold, nil
+}
+
+// ListHolds returns holds, newest first
+func (s *retentionService) ListHolds(ctx context.Context, filter domain.LegalHoldFilter) ([]*domain.LegalHold, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Purge deletes unheld entries past retention in batches
+func (s *retentionService) Purge(ctx context.Context, before time.Time) (*domain.RetentionRun, error) {
+	run := &domain.RetentionRun{Before: before}
+	for {
+		deleted, err := s.repo.PurgeBefore(ctx, before, purgeBatchSize)
+		if err != nil {
+			return run, err
+		}
+		run.Deleted += deleted
+		if deleted < purgeBatchSize {
+			break
+		}
+	}
+
+	held, err := s.repo.CountHeld(ctx, before)
+	if err != nil {
+		return run, err
+	}
+	run.Held = held
+	return run, nil
+}
+
+// logHold writes a hold change to the held tenant's trail before returning, so the
+// change is on record by the time the caller sees it
+func (s *retentionService) logHold(ctx context.Context, action string, hold *domain.LegalHold, userID *uuid.UUID) {
+	entityID := hold.ID.String()
+	err := s.audit.LogSync(ctx, action, domain.LegalHoldEntity, &entityID, map[string]interface{}{
+		"entity":         hold.Entity,
+		"entity_id":      hold.EntityID,
+		"reason":         hold.Reason,
+		"release_reason": hold.ReleaseReason,
+	}, &domain.AuditContext{UserID: userID, TenantID: &hold.TenantID})
+	if err != nil {
+		fmt.Printf("Failed to log %s for legal hold %s: %v\n", action, hold.ID, err)
+	}
+}
@@ -0,0 +1,30 @@
+package automation
+
+import (
+	"context"
+
+	"github.com/aceextension/automation/repository"
+	"github.com/aceextension/automation/service"
+	"github.com/aceextension/core/events"
+)
+
+// Module-level service instances
+var (
+	AutomationService service.AutomationService
+)
+
+// Init initializes the automation module.
+// Modules publishing events (sales invoices, catalog products) register them as triggers through
+// AutomationService.RegisterTrigger; modules owning records with custom attributes (catalog products,
+// crm customers and suppliers) register them through AutomationService.RegisterAttributeTarget.
+func Init() {
+	AutomationService = service.NewAutomationService(repository.NewPostgresAutomationRepository())
+}
+
+// StartAutomationWorker subscribes to the default event bus and runs matching rules in
+// the background, so publishers are never held up by a rule's webhook or notifications.
+// Call once after Init from the process that hosts background jobs.
+func StartAutomationWorker() {
+	events.Subscribe(AutomationService.Handle)
+	go AutomationService.Work(context.Background())
+}
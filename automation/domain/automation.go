@@ -0,0 +1,351 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Execution limits per tenant. Actions can publish events that trigger further
+// rules, so the hourly cap also bounds loops.
+const (
+	MaxRulesPerTenant    = 50
+	MaxConditionsPerRule = 10
+	MaxActionsPerRule    = 5
+	MaxRunsPerHour       = 500
+	WebhookTimeout       = 5 * time.Second
+)
+
+var (
+	// ErrRuleNotFound is returned when a rule does not exist for the tenant
+	ErrRuleNotFound = errors.New("automation rule not found")
+	// ErrInvalidRule is returned for a rule with an unknown trigger, field, operator or action
+	ErrInvalidRule = errors.New("invalid automation rule")
+	// ErrRuleLimit is returned when the tenant already has MaxRulesPerTenant rules
+	ErrRuleLimit = fmt.Errorf("a tenant can have at most %d automation rules", MaxRulesPerTenant)
+)
+
+// Entity types of records ID fields point to
+const (
+	EntityProduct  = "product"
+	EntityCustomer = "customer"
+	EntitySupplier = "supplier"
+	EntityInvoice  = "invoice"
+)
+
+// FieldType is the kind of value an event field holds
+type FieldType string
+
+const (
+	FieldString FieldType = "string"
+	FieldNumber FieldType = "number"
+	FieldBool   FieldType = "bool"
+	FieldID     FieldType = "id" // A record ID; EntityType says of what
+)
+
+// TriggerField is a field of an event's data that conditions and actions can use
+type TriggerField struct {
+	Name       string    `json:"name"`
+	Type       FieldType `json:"type"`
+	EntityType string    `json:"entityType,omitempty"` // For ID fields: customer, product, ...
+}
+
+// Trigger is an event type rules can run on, registered by the module publishing it
+type Trigger struct {
+	Event       string         `json:"event"` // e.g. invoice.created
+	Description string         `json:"description"`
+	Fields      []TriggerField `json:"fields"`
+}
+
+// Field returns the named field
+func (t Trigger) Field(name string) (TriggerField, bool) {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return TriggerField{}, false
+}
+
+// Operator compares an event field with a condition's value
+type Operator string
+
+const (
+	OpEquals    Operator = "eq"
+	OpNotEquals Operator = "ne"
+	OpGreater   Operator = "gt"
+	OpGreaterEq Operator = "gte"
+	OpLess      Operator = "lt"
+	OpLessEq    Operator = "lte"
+	OpContains  Operator = "contains" // Case-insensitive substring
+	OpIn        Operator = "in"       // Value is a list
+	OpExists    Operator = "exists"   // The field is set; no value
+	OpNotExists Operator = "not_exists"
+)
+
+// operatorsByType lists the operators each field type supports
+var operatorsByType = map[FieldType][]Operator{
+	FieldString: {OpEquals, OpNotEquals, OpContains, OpIn, OpExists, OpNotExists},
+	FieldNumber: {OpEquals, OpNotEquals, OpGreater, OpGreaterEq, OpLess, OpLessEq, OpExists, OpNotExists},
+	FieldBool:   {OpEquals, OpNotEquals},
+	FieldID:     {OpEquals, OpNotEquals, OpIn, OpExists, OpNotExists},
+}
+
+// Condition is a test on one field of the event; a rule runs when all hold
+type Condition struct {
+	Field    string   `json:"field"`
+	Operator Operator `json:"operator"`
+	Value    any      `json:"value,omitempty"`
+}
+
+// ActionType is what a rule does
+type ActionType string
+
+const (
+	ActionSetAttribute ActionType = "set_attribute" // Set a custom attribute on the record an ID field points to
+	ActionAddTag       ActionType = "add_tag"       // Tag the record an ID field points to
+	ActionNotify       ActionType = "notify"        // Notify the tenant's users with a role, in-app and by email
+	ActionWebhook      ActionType = "webhook"       // POST the event to an https URL, signed with the rule's secret
+)
+
+// Action is one step of a rule. Messages can use {{field}} placeholders.
+type Action struct {
+	Type      ActionType `json:"type"`
+	Field     string     `json:"field,omitempty"`     // set_attribute, add_tag: ID field of the record
+	Attribute string     `json:"attribute,omitempty"` // set_attribute
+	Value     any        `json:"value,omitempty"`     // set_attribute
+	Tags      []string   `json:"tags,omitempty"`      // add_tag
+	Role      string     `json:"role,omitempty"`      // notify: owner by default
+	Message   string     `json:"message,omitempty"`   // notify
+	URL       string     `json:"url,omitempty"`       // webhook
+}
+
+// Rule is a tenant's automation: when the trigger event happens and every
+// condition holds, the actions run in order
+type Rule struct {
+	ID            uuid.UUID   `json:"id" db:"id"`
+	TenantID      uuid.UUID   `json:"tenantId" db:"tenant_id"`
+	Name          string      `json:"name" db:"name"`
+	Trigger       string      `json:"trigger" db:"trigger"`
+	Conditions    []Condition `json:"conditions" db:"conditions"`
+	Actions       []Action    `json:"actions" db:"actions"`
+	Enabled       bool        `json:"enabled" db:"enabled"`
+	WebhookSecret string      `json:"webhookSecret" db:"webhook_secret"` // Signs webhook calls: X-Automation-Signature is hex HMAC-SHA256 of the body
+	CreatedBy     *uuid.UUID  `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt     time.Time   `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time   `json:"updatedAt" db:"updated_at"`
+}
+
+var attributePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,63}$`)
+
+// Validate checks the rule against its trigger's fields. attributeTargets are the
+// entity types custom attributes can be set on.
+func (r *Rule) Validate(trigger Trigger, attributeTargets map[string]bool) error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || len(r.Name) > 255 {
+		return fmt.Errorf("%w: name is required", ErrInvalidRule)
+	}
+	if len(r.Conditions) > MaxConditionsPerRule {
+		return fmt.Errorf("%w: at most %d conditions", ErrInvalidRule, MaxConditionsPerRule)
+	}
+	if len(r.Actions) == 0 || len(r.Actions) > MaxActionsPerRule {
+		return fmt.Errorf("%w: between 1 and %d actions", ErrInvalidRule, MaxActionsPerRule)
+	}
+
+	for _, c := range r.Conditions {
+		if err := c.validate(trigger); err != nil {
+			return err
+		}
+	}
+	for i := range r.Actions {
+		if err := r.Actions[i].validate(trigger, attributeTargets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c Condition) validate(trigger Trigger) error {
+	field, ok := trigger.Field(c.Field)
+	if !ok {
+		return fmt.Errorf("%w: %s has no field %q", ErrInvalidRule, trigger.Event, c.Field)
+	}
+	supported := false
+	for _, op := range operatorsByType[field.Type] {
+		supported = supported || op == c.Operator
+	}
+	if !supported {
+		return fmt.Errorf("%w: operator %q does not apply to %s", ErrInvalidRule, c.Operator, c.Field)
+	}
+
+	switch c.Operator {
+	case OpExists, OpNotExists:
+		return nil
+	case OpIn:
+		values, ok := c.Value.([]any)
+		if !ok || len(values) == 0 {
+			return fmt.Errorf("%w: %s in needs a list of values", ErrInvalidRule, c.Field)
+		}
+		for _, v := range values {
+			if !valueFits(field.Type, v) {
+				return fmt.Errorf("%w: %v is not a %s for %s", ErrInvalidRule, v, field.Type, c.Field)
+			}
+		}
+		return nil
+	}
+	if !valueFits(field.Type, c.Value) {
+		return fmt.Errorf("%w: %v is not a %s for %s", ErrInvalidRule, c.Value, field.Type, c.Field)
+	}
+	return nil
+}
+
+// valueFits reports whether a JSON value can be compared with a field of the type
+func valueFits(fieldType FieldType, value any) bool {
+	switch fieldType {
+	case FieldNumber:
+		_, ok := value.(float64)
+		return ok
+	case FieldBool:
+		_, ok := value.(bool)
+		return ok
+	case FieldID:
+		s, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, err := uuid.Parse(s)
+		return err == nil
+	default:
+		_, ok := value.(string)
+		return ok
+	}
+}
+
+func (a *Action) validate(trigger Trigger, attributeTargets map[string]bool) error {
+	switch a.Type {
+	case ActionSetAttribute, ActionAddTag:
+		field, ok := trigger.Field(a.Field)
+		if !ok || field.Type != FieldID || field.EntityType == "" {
+			return fmt.Errorf("%w: %s needs the ID field of a record", ErrInvalidRule, a.Type)
+		}
+		if a.Type == ActionAddTag {
+			if len(a.Tags) == 0 {
+				return fmt.Errorf("%w: add_tag needs tags", ErrInvalidRule)
+			}
+			return nil
+		}
+		if !attributeTargets[field.EntityType] {
+			return fmt.Errorf("%w: attributes cannot be set on a %s", ErrInvalidRule, field.EntityType)
+		}
+		if !attributePattern.MatchString(a.Attribute) {
+			return fmt.Errorf("%w: attribute must be a letter followed by letters, digits or '_'", ErrInvalidRule)
+		}
+		switch a.Value.(type) {
+		case string, float64, bool:
+		default:
+			return fmt.Errorf("%w: attribute value must be a string, number or boolean", ErrInvalidRule)
+		}
+	case ActionNotify:
+		if a.Role == "" {
+			a.Role = "owner"
+		}
+		if strings.TrimSpace(a.Message) == "" || len(a.Message) > 1000 {
+			return fmt.Errorf("%w: notify needs a message of up to 1000 characters", ErrInvalidRule)
+		}
+	case ActionWebhook:
+		u, err := url.Parse(a.URL)
+		if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+			return fmt.Errorf("%w: webhook needs an https URL", ErrInvalidRule)
+		}
+		if host := strings.ToLower(u.Hostname()); host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".internal") {
+			return fmt.Errorf("%w: webhook URL must be a public host", ErrInvalidRule)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidRule, a.Type)
+	}
+	return nil
+}
+
+// Matches reports whether every condition holds for the event data
+func (r *Rule) Matches(data map[string]any) bool {
+	for _, c := range r.Conditions {
+		if !c.holds(data[c.Field]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Condition) holds(actual any) bool {
+	present := actual != nil && actual != ""
+	switch c.Operator {
+	case OpExists:
+		return present
+	case OpNotExists:
+		return !present
+	case OpEquals:
+		return equal(actual, c.Value)
+	case OpNotEquals:
+		return !equal(actual, c.Value)
+	case OpIn:
+		values, _ := c.Value.([]any)
+		for _, v := range values {
+			if equal(actual, v) {
+				return true
+			}
+		}
+		return false
+	case OpContains:
+		a, ok1 := actual.(string)
+		v, ok2 := c.Value.(string)
+		return ok1 && ok2 && strings.Contains(strings.ToLower(a), strings.ToLower(v))
+	}
+
+	a, ok1 := actual.(float64)
+	v, ok2 := c.Value.(float64)
+	if !ok1 || !ok2 {
+		return false
+	}
+	switch c.Operator {
+	case OpGreater:
+		return a > v
+	case OpGreaterEq:
+		return a >= v
+	case OpLess:
+		return a < v
+	case OpLessEq:
+		return a <= v
+	}
+	return false
+}
+
+// equal compares JSON values; IDs and other strings ignore case
+func equal(actual, expected any) bool {
+	if a, ok := actual.(string); ok {
+		e, ok := expected.(string)
+		return ok && strings.EqualFold(a, e)
+	}
+	return actual == expected
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_]+)\s*\}\}`)
+
+// Render fills {{field}} placeholders in a message from the event data; unknown fields are left empty
+func Render(message string, data map[string]any) string {
+	return placeholderPattern.ReplaceAllStringFunc(message, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		value, ok := data[name]
+		if !ok || value == nil {
+			return ""
+		}
+		if f, ok := value.(float64); ok {
+			return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", f), "0"), ".")
+		}
+		return fmt.Sprint(value)
+	})
+}
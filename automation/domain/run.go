@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RunStatus is how a rule's execution ended
+type RunStatus string
+
+const (
+	RunSucceeded RunStatus = "succeeded" // Every action ran
+	RunFailed    RunStatus = "failed"    // At least one action failed; the others still ran
+	RunSkipped   RunStatus = "skipped"   // The tenant had used its hourly runs; nothing ran
+)
+
+// ActionResult is the outcome of one action of a run
+type ActionResult struct {
+	Type  ActionType `json:"type"`
+	OK    bool       `json:"ok"`
+	Error string     `json:"error,omitempty"`
+}
+
+// Run is one execution of a rule for an event whose conditions it matched
+type Run struct {
+	ID         uuid.UUID      `json:"id" db:"id"`
+	TenantID   uuid.UUID      `json:"tenantId" db:"tenant_id"`
+	RuleID     uuid.UUID      `json:"ruleId" db:"rule_id"`
+	RuleName   string         `json:"ruleName" db:"rule_name"`
+	EventID    uuid.UUID      `json:"eventId" db:"event_id"`
+	EventType  string         `json:"eventType" db:"event_type"`
+	Status     RunStatus      `json:"status" db:"status"`
+	Results    []ActionResult `json:"results" db:"results"`
+	StartedAt  time.Time      `json:"startedAt" db:"started_at"`
+	FinishedAt time.Time      `json:"finishedAt" db:"finished_at"`
+}
+
+// NewRun starts a run of a rule for an event
+func NewRun(rule *Rule, eventID uuid.UUID, eventType string) *Run {
+	return &Run{
+		ID:        uuid.New(),
+		TenantID:  rule.TenantID,
+		RuleID:    rule.ID,
+		RuleName:  rule.Name,
+		EventID:   eventID,
+		EventType: eventType,
+		Results:   []ActionResult{},
+		StartedAt: time.Now(),
+	}
+}
+
+// Record adds an action's outcome
+func (r *Run) Record(actionType ActionType, err error) {
+	result := ActionResult{Type: actionType, OK: err == nil}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	r.Results = append(r.Results, result)
+}
+
+// Finish sets the status from the action outcomes
+func (r *Run) Finish() {
+	r.Status = RunSucceeded
+	for _, result := range r.Results {
+		if !result.OK {
+			r.Status = RunFailed
+		}
+	}
+	r.FinishedAt = time.Now()
+}
+
+// Skip ends a run that was over the tenant's limit
+func (r *Run) Skip() {
+	r.Status = RunSkipped
+	r.FinishedAt = time.Now()
+}
+
+// RunFilter selects runs
+type RunFilter struct {
+	RuleID *uuid.UUID
+	Status *RunStatus
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/automation/domain"
+	"github.com/aceextension/automation/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type AutomationHandler struct {
+	service service.AutomationService
+}
+
+func NewAutomationHandler(service service.AutomationService) *AutomationHandler {
+	return &AutomationHandler{service: service}
+}
+
+// RuleRequest is the body for creating or replacing a rule
+type RuleRequest struct {
+	Name       string             `json:"name"`
+	Trigger    string             `json:"trigger"`
+	Conditions []domain.Condition `json:"conditions"`
+	Actions    []domain.Action    `json:"actions"`
+	Enabled    *bool              `json:"enabled"` // Defaults to true
+}
+
+// RuleResponse is the API representation of a rule. The webhook secret verifies
+// the X-Automation-Signature of webhook calls.
+type RuleResponse struct {
+	ID            uuid.UUID          `json:"id"`
+	Name          string             `json:"name"`
+	Trigger       string             `json:"trigger"`
+	Conditions    []domain.Condition `json:"conditions"`
+	Actions       []domain.Action    `json:"actions"`
+	Enabled       bool               `json:"enabled"`
+	WebhookSecret string             `json:"webhookSecret"`
+	CreatedAt     string             `json:"createdAt"`
+	UpdatedAt     string             `json:"updatedAt"`
+}
+
+func toRuleResponse(r *domain.Rule) RuleResponse {
+	return RuleResponse{
+		ID:            r.ID,
+		Name:          r.Name,
+		Trigger:       r.Trigger,
+		Conditions:    r.Conditions,
+		Actions:       r.Actions,
+		Enabled:       r.Enabled,
+		WebhookSecret: r.WebhookSecret,
+		CreatedAt:     r.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     r.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func (req RuleRequest) input() service.RuleInput {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return service.RuleInput{
+		Name:       req.Name,
+		Trigger:    req.Trigger,
+		Conditions: req.Conditions,
+		Actions:    req.Actions,
+		Enabled:    enabled,
+	}
+}
+
+// ListTriggers returns the events rules can run on
+// @Summary List Automation Triggers
+// @Description Events rules can run on, with the fields conditions and actions can use
+// @Tags Automations
+// @Produce json
+// @Success 200 {array} domain.Trigger
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/automations/triggers [get]
+func (h *AutomationHandler) ListTriggers(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.service.Triggers())
+}
+
+// ListRules returns the tenant's rules
+// @Summary List Automations
+// @Tags Automations
+// @Produce json
+// @Success 200 {array} RuleResponse
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/automations [get]
+func (h *AutomationHandler) ListRules(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	rules, err := h.service.ListRules(c.Request().Context(), tenantID)
+	if err != nil {
+		return automationError(c, err)
+	}
+
+	response := make([]RuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		response = append(response, toRuleResponse(rule))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetRule returns a rule
+// @Summary Get Automation
+// @Tags Automations
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} RuleResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/automations/{id} [get]
+func (h *AutomationHandler) GetRule(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid rule ID"})
+	}
+
+	rule, err := h.service.GetRule(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return automationError(c, err)
+	}
+	return c.JSON(http.StatusOK, toRuleResponse(rule))
+}
+
+// CreateRule adds a rule
+// @Summary Create Automation
+// @Description When the trigger event happens and every condition holds, the actions run in order
+// @Tags Automations
+// @Accept json
+// @Produce json
+// @Param request body RuleRequest true "Rule"
+// @Success 201 {object} RuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/automations [post]
+func (h *AutomationHandler) CreateRule(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	var req RuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	rule, err := h.service.CreateRule(c.Request().Context(), tenantID, req.input(), &userID)
+	if err != nil {
+		return automationError(c, err)
+	}
+	return c.JSON(http.StatusCreated, toRuleResponse(rule))
+}
+
+// UpdateRule replaces a rule's definition
+// @Summary Update Automation
+// @Tags Automations
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body RuleRequest true "Rule"
+// @Success 200 {object} RuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/automations/{id} [put]
+func (h *AutomationHandler) UpdateRule(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid rule ID"})
+	}
+
+	var req RuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	rule, err := h.service.UpdateRule(c.Request().Context(), tenantID, id, req.input(), &userID)
+	if err != nil {
+		return automationError(c, err)
+	}
+	return c.JSON(http.StatusOK, toRuleResponse(rule))
+}
+
+// DeleteRule removes a rule and its execution log
+// @Summary Delete Automation
+// @Tags Automations
+// @Param id path string true "Rule ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/automations/{id} [delete]
+func (h *AutomationHandler) DeleteRule(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid rule ID"})
+	}
+
+	if err := h.service.DeleteRule(c.Request().Context(), tenantID, id, &userID); err != nil {
+		return automationError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListRuns returns the execution log
+// @Summary List Automation Runs
+// @Description Executions of the tenant's rules, newest first
+// @Tags Automations
+// @Produce json
+// @Param ruleId query string false "Rule ID"
+// @Param status query string false "succeeded, failed or skipped"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} domain.Run
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/automations/runs [get]
+func (h *AutomationHandler) ListRuns(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	filter := domain.RunFilter{Limit: 50}
+	if raw := c.QueryParam("ruleId"); raw != "" {
+		ruleID, err := uuid.Parse(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid rule ID"})
+		}
+		filter.RuleID = &ruleID
+	}
+	if raw := c.QueryParam("status"); raw != "" {
+		status := domain.RunStatus(raw)
+		filter.Status = &status
+	}
+	if limit, _ := strconv.Atoi(c.QueryParam("limit")); limit > 0 {
+		filter.Limit = min(limit, 100)
+	}
+	if offset, _ := strconv.Atoi(c.QueryParam("offset")); offset > 0 {
+		filter.Offset = offset
+	}
+
+	runs, err := h.service.Runs(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return automationError(c, err)
+	}
+	return c.JSON(http.StatusOK, runs)
+}
+
+// requireManager lets only owners and admins through: automations act on the whole tenant
+func requireManager(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		role, _ := db.GetRole(c.Request().Context())
+		if role != "owner" && role != "admin" {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Only owners and admins can manage automations"})
+		}
+		return next(c)
+	}
+}
+
+// automationError maps automation errors to HTTP statuses
+func automationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidRule):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrRuleNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrRuleLimit):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
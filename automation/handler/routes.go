@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(e *echo.Group, automationHandler *AutomationHandler) {
+	automationsGroup := e.Group("/automations", requireManager)
+
+	// Events rules can run on
+	automationsGroup.GET("/triggers", automationHandler.ListTriggers)
+
+	// Execution log
+	automationsGroup.GET("/runs", automationHandler.ListRuns)
+
+	// Rules
+	automationsGroup.GET("", automationHandler.ListRules)
+	automationsGroup.POST("", automationHandler.CreateRule)
+	automationsGroup.GET("/:id", automationHandler.GetRule)
+	automationsGroup.PUT("/:id", automationHandler.UpdateRule)
+	automationsGroup.DELETE("/:id", automationHandler.DeleteRule)
+}
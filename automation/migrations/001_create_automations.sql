@@ -0,0 +1,56 @@
+-- Automation Module: Tenant Rules and Execution Log
+-- Migration: 001_create_automations.sql
+-- Rules run on events published by other modules: when every condition holds for
+-- the event, the actions run in order. Each matching execution is logged.
+
+CREATE TABLE IF NOT EXISTS automation_rules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    trigger VARCHAR(100) NOT NULL,                   -- Event type, e.g. invoice.created
+    conditions JSONB NOT NULL DEFAULT '[]',          -- [{"field":"totalAmount","operator":"gt","value":100000}]
+    actions JSONB NOT NULL,                          -- [{"type":"add_tag","field":"customerId","tags":["VIP"]}]
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    webhook_secret VARCHAR(64) NOT NULL,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_rules_trigger ON automation_rules(tenant_id, trigger) WHERE enabled;
+
+CREATE TABLE IF NOT EXISTS automation_runs (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    rule_id UUID NOT NULL REFERENCES automation_rules(id) ON DELETE CASCADE,
+    rule_name VARCHAR(255) NOT NULL,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,                     -- succeeded, failed, skipped
+    results JSONB NOT NULL DEFAULT '[]',             -- [{"type":"webhook","ok":false,"error":"..."}]
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    CONSTRAINT chk_automation_runs_status CHECK (status IN ('succeeded', 'failed', 'skipped'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_runs_tenant ON automation_runs(tenant_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_automation_runs_rule ON automation_runs(rule_id, started_at DESC);
+
+COMMENT ON TABLE automation_rules IS 'Tenant-defined automations: trigger event, conditions and actions';
+COMMENT ON COLUMN automation_rules.webhook_secret IS 'Key for the HMAC-SHA256 signature sent with webhook actions';
+COMMENT ON TABLE automation_runs IS 'Executions of automation rules; runs over the hourly limit are logged as skipped';
+
+ALTER TABLE automation_rules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE automation_runs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON automation_rules
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON automation_runs
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/automation/domain"
+	"github.com/google/uuid"
+)
+
+// Recipient is a tenant user an automation can notify
+type Recipient struct {
+	ID    uuid.UUID
+	Name  string
+	Email string
+}
+
+// AutomationRepository defines the interface for automation rule and run data access
+type AutomationRepository interface {
+	CreateRule(ctx context.Context, rule *domain.Rule) error
+	GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.Rule, error)
+	// ListRules retrieves the tenant's rules by name
+	ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.Rule, error)
+	UpdateRule(ctx context.Context, rule *domain.Rule) error
+	DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error
+	CountRules(ctx context.Context, tenantID uuid.UUID) (int, error)
+	// EnabledRules retrieves the tenant's enabled rules on a trigger, oldest first
+	EnabledRules(ctx context.Context, tenantID uuid.UUID, trigger string) ([]*domain.Rule, error)
+
+	CreateRun(ctx context.Context, run *domain.Run) error
+	// ListRuns retrieves runs, newest first
+	ListRuns(ctx context.Context, tenantID uuid.UUID, filter domain.RunFilter) ([]*domain.Run, error)
+	// CountRunsSince counts the tenant's runs that were not skipped since a time
+	CountRunsSince(ctx context.Context, tenantID uuid.UUID, since time.Time) (int, error)
+
+	// Recipients retrieves the tenant's active users with a role
+	Recipients(ctx context.Context, tenantID uuid.UUID, role string) ([]Recipient, error)
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/automation/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresAutomationRepository implements AutomationRepository using PostgreSQL
+type PostgresAutomationRepository struct{}
+
+// NewPostgresAutomationRepository creates a new PostgreSQL automation repository
+func NewPostgresAutomationRepository() *PostgresAutomationRepository {
+	return &PostgresAutomationRepository{}
+}
+
+const ruleColumns = `id, tenant_id, name, trigger, conditions, actions, enabled, webhook_secret, created_by, created_at, updated_at`
+
+const runColumns = `id, tenant_id, rule_id, rule_name, event_id, event_type, status, results, started_at, finished_at`
+
+// CreateRule stores a rule
+func (r *PostgresAutomationRepository) CreateRule(ctx context.Context, rule *domain.Rule) error {
+	conditionsJSON, actionsJSON, err := marshalRule(rule)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO automation_rules (` + ruleColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err = db.MainPool.Exec(ctx, query,
+		rule.ID, rule.TenantID, rule.Name, rule.Trigger, conditionsJSON, actionsJSON, rule.Enabled,
+		rule.WebhookSecret, rule.CreatedBy, rule.CreatedAt, rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create automation rule: %w", err)
+	}
+	return nil
+}
+
+// GetRule retrieves a rule
+func (r *PostgresAutomationRepository) GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.Rule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM automation_rules WHERE tenant_id = $1 AND id = $2`
+
+	rule, err := scanRule(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRuleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get automation rule: %w", err)
+	}
+	return rule, nil
+}
+
+// ListRules retrieves the tenant's rules
+func (r *PostgresAutomationRepository) ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.Rule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM automation_rules WHERE tenant_id = $1 ORDER BY name, created_at`
+	return r.queryRules(ctx, query, tenantID)
+}
+
+// UpdateRule saves a rule's definition
+func (r *PostgresAutomationRepository) UpdateRule(ctx context.Context, rule *domain.Rule) error {
+	conditionsJSON, actionsJSON, err := marshalRule(rule)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE automation_rules
+		SET name = $3, trigger = $4, conditions = $5, actions = $6, enabled = $7, updated_at = $8
+		WHERE tenant_id = $1 AND id = $2
+	`
+	tag, err := db.MainPool.Exec(ctx, query,
+		rule.TenantID, rule.ID, rule.Name, rule.Trigger, conditionsJSON, actionsJSON, rule.Enabled, rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update automation rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRuleNotFound
+	}
+	return nil
+}
+
+// DeleteRule removes a rule and its runs
+func (r *PostgresAutomationRepository) DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := db.MainPool.Exec(ctx, `DELETE FROM automation_rules WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete automation rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRuleNotFound
+	}
+	return nil
+}
+
+// CountRules counts the tenant's rules
+func (r *PostgresAutomationRepository) CountRules(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	var count int
+	if err := db.MainPool.QueryRow(ctx, `SELECT COUNT(*) FROM automation_rules WHERE tenant_id = $1`, tenantID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count automation rules: %w", err)
+	}
+	return count, nil
+}
+
+// EnabledRules retrieves the enabled rules on a trigger
+func (r *PostgresAutomationRepository) EnabledRules(ctx context.Context, tenantID uuid.UUID, trigger string) ([]*domain.Rule, error) {
+	query := `
+		SELECT ` + ruleColumns + ` FROM automation_rules
+		WHERE tenant_id = $1 AND trigger = $2 AND enabled
+		ORDER BY created_at
+	`
+	return r.queryRules(ctx, query, tenantID, trigger)
+}
+
+func (r *PostgresAutomationRepository) queryRules(ctx context.Context, query string, args ...any) ([]*domain.Rule, error) {
+	rows, err := db.MainPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list automation rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*domain.Rule{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan automation rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// CreateRun logs a run
+func (r *PostgresAutomationRepository) CreateRun(ctx context.Context, run *domain.Run) error {
+	resultsJSON, err := json.Marshal(run.Results)
+	if err != nil {
+		return fmt.Errorf("failed to marshal automation results: %w", err)
+	}
+
+	query := `INSERT INTO automation_runs (` + runColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err = db.MainPool.Exec(ctx, query,
+		run.ID, run.TenantID, run.RuleID, run.RuleName, run.EventID, run.EventType, run.Status, resultsJSON,
+		run.StartedAt, run.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to log automation run: %w", err)
+	}
+	return nil
+}
+
+// ListRuns retrieves runs, newest first
+func (r *PostgresAutomationRepository) ListRuns(ctx context.Context, tenantID uuid.UUID, filter domain.RunFilter) ([]*domain.Run, error) {
+	query := `
+		SELECT ` + runColumns + `
+		FROM automation_runs
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR rule_id = $2)
+		  AND ($3::varchar IS NULL OR status = $3)
+		ORDER BY started_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.RuleID, filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list automation runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*domain.Run{}
+	for rows.Next() {
+		var run domain.Run
+		var resultsJSON []byte
+		err := rows.Scan(&run.ID, &run.TenantID, &run.RuleID, &run.RuleName, &run.EventID, &run.EventType,
+			&run.Status, &resultsJSON, &run.StartedAt, &run.FinishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan automation run: %w", err)
+		}
+		if err := json.Unmarshal(resultsJSON, &run.Results); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal automation results: %w", err)
+		}
+		runs = append(runs, &run)
+	}
+	return runs, rows.Err()
+}
+
+// CountRunsSince counts runs that executed since a time
+func (r *PostgresAutomationRepository) CountRunsSince(ctx context.Context, tenantID uuid.UUID, since time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM automation_runs WHERE tenant_id = $1 AND started_at >= $2 AND status <> 'skipped'`
+	if err := db.MainPool.QueryRow(ctx, query, tenantID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count automation runs: %w", err)
+	}
+	return count, nil
+}
+
+// Recipients retrieves active members with the role
+func (r *PostgresAutomationRepository) Recipients(ctx context.Context, tenantID uuid.UUID, role string) ([]Recipient, error) {
+	query := `
+		SELECT u.id, u.name, COALESCE(u.email, '')
+		FROM tenant_memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.tenant_id = $1 AND m.role = $2 AND m.is_active = true AND u.is_active = true
+		ORDER BY u.name
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation recipients: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := []Recipient{}
+	for rows.Next() {
+		var rcpt Recipient
+		if err := rows.Scan(&rcpt.ID, &rcpt.Name, &rcpt.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan automation recipient: %w", err)
+		}
+		recipients = append(recipients, rcpt)
+	}
+	return recipients, rows.Err()
+}
+
+// marshalRule encodes a rule's conditions and actions for their JSONB columns
+func marshalRule(rule *domain.Rule) ([]byte, []byte, error) {
+	conditionsJSON, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal automation conditions: %w", err)
+	}
+	actionsJSON, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal automation actions: %w", err)
+	}
+	return conditionsJSON, actionsJSON, nil
+}
+
+// scanRule scans an automation_rules row in ruleColumns order
+func scanRule(row pgx.Row) (*domain.Rule, error) {
+	var rule domain.Rule
+	var conditionsJSON, actionsJSON []byte
+	err := row.Scan(&rule.ID, &rule.TenantID, &rule.Name, &rule.Trigger, &conditionsJSON, &actionsJSON, &rule.Enabled,
+		&rule.WebhookSecret, &rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(conditionsJSON, &rule.Conditions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal automation conditions: %w", err)
+	}
+	if err := json.Unmarshal(actionsJSON, &rule.Actions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal automation actions: %w", err)
+	}
+	return &rule, nil
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aceextension/automation/domain"
+	"github.com/aceextension/core/events"
+	"github.com/aceextension/notification"
+	notificationDomain "github.com/aceextension/notification/domain"
+	notificationService "github.com/aceextension/notification/service"
+	"github.com/aceextension/tags"
+	tagService "github.com/aceextension/tags/service"
+	"github.com/google/uuid"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of a webhook body, keyed with the rule's secret
+const SignatureHeader = "X-Automation-Signature"
+
+// errBlockedAddress is returned when a webhook host resolves to a non-public address
+var errBlockedAddress = errors.New("webhook host resolves to a non-public address")
+
+// perform runs one action of a rule for an event
+func (s *automationService) perform(ctx context.Context, rule *domain.Rule, action domain.Action, event events.Event, data map[string]any) error {
+	switch action.Type {
+	case domain.ActionSetAttribute:
+		entityType, entityID, err := s.target(rule, action, data)
+		if err != nil {
+			return err
+		}
+		s.mu.RLock()
+		set, ok := s.attributeTargets[entityType]
+		s.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("attributes cannot be set on a %s", entityType)
+		}
+		return set(ctx, rule.TenantID, entityID, action.Attribute, action.Value)
+
+	case domain.ActionAddTag:
+		entityType, entityID, err := s.target(rule, action, data)
+		if err != nil {
+			return err
+		}
+		if tags.TagService == nil {
+			return errors.New("tags module is not initialized")
+		}
+		_, err = tags.TagService.Tag(ctx, tagService.BulkTagInput{
+			TenantID:   rule.TenantID,
+			EntityType: entityType,
+			EntityIDs:  []uuid.UUID{entityID},
+			Tags:       action.Tags,
+		})
+		return err
+
+	case domain.ActionNotify:
+		return s.notify(ctx, rule, action, domain.Render(action.Message, data))
+
+	case domain.ActionWebhook:
+		return s.client.post(ctx, rule, action.URL, event)
+	}
+	return fmt.Errorf("unknown action %q", action.Type)
+}
+
+// target resolves the record an action's ID field points to
+func (s *automationService) target(rule *domain.Rule, action domain.Action, data map[string]any) (string, uuid.UUID, error) {
+	s.mu.RLock()
+	trigger, ok := s.triggers[rule.Trigger]
+	s.mu.RUnlock()
+	if !ok {
+		return "", uuid.Nil, fmt.Errorf("trigger %s is no longer registered", rule.Trigger)
+	}
+	field, ok := trigger.Field(action.Field)
+	if !ok {
+		return "", uuid.Nil, fmt.Errorf("%s has no field %s", rule.Trigger, action.Field)
+	}
+
+	raw, _ := data[action.Field].(string)
+	entityID, err := uuid.Parse(raw)
+	if err != nil || entityID == uuid.Nil {
+		return "", uuid.Nil, fmt.Errorf("event has no %s", action.Field)
+	}
+	return field.EntityType, entityID, nil
+}
+
+// notify tells the tenant's users with the action's role in-app and, when they
+// have an address, by email
+func (s *automationService) notify(ctx context.Context, rule *domain.Rule, action domain.Action, message string) error {
+	if notification.Service == nil {
+		return errors.New("notification module is not initialized")
+	}
+	recipients, err := s.repo.Recipients(ctx, rule.TenantID, action.Role)
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("no active %s to notify", action.Role)
+	}
+
+	referenceType := "AUTOMATION"
+	failed := 0
+	for _, rcpt := range recipients {
+		userID := rcpt.ID
+		requests := []notificationService.SendRequest{{
+			TenantID:  rule.TenantID,
+			UserID:    &userID,
+			Channel:   notificationDomain.ChannelInApp,
+			Recipient: userID.String(),
+			Content:   message,
+			Priority:  notificationDomain.PriorityHigh,
+		}}
+		if rcpt.Email != "" {
+			requests = append(requests, notificationService.SendRequest{
+				TenantID:  rule.TenantID,
+				UserID:    &userID,
+				Channel:   notificationDomain.ChannelEmail,
+				Recipient: rcpt.Email,
+				Content:   message,
+				Priority:  notificationDomain.PriorityLow,
+			})
+		}
+
+		for _, req := range requests {
+			req.ReferenceType = &referenceType
+			req.ReferenceID = &rule.ID
+			if _, err := notification.Service.Send(ctx, req); err != nil {
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d notifications failed", failed)
+	}
+	return nil
+}
+
+// webhookClient calls tenant webhooks. It only connects to public addresses,
+// so rules cannot reach the platform's internal network.
+type webhookClient struct {
+	http *http.Client
+}
+
+func newWebhookClient() *webhookClient {
+	dialer := &net.Dialer{
+		Timeout: domain.WebhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+				ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+				return errBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	return &webhookClient{
+		http: &http.Client{
+			Timeout: domain.WebhookTimeout,
+			Transport: &http.Transport{
+				Proxy:               nil,
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: domain.WebhookTimeout,
+			},
+			// Redirects could lead anywhere; the rule's URL is the only one called
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// webhookPayload is the body POSTed to a webhook
+type webhookPayload struct {
+	RuleID     uuid.UUID `json:"ruleId"`
+	RuleName   string    `json:"ruleName"`
+	EventID    uuid.UUID `json:"eventId"`
+	Event      string    `json:"event"`
+	Data       any       `json:"data"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// post sends the event to the URL and expects a 2xx response
+func (c *webhookClient) post(ctx context.Context, rule *domain.Rule, url string, event events.Event) error {
+	body, err := json.Marshal(webhookPayload{
+		RuleID:     rule.ID,
+		RuleName:   rule.Name,
+		EventID:    event.ID,
+		Event:      event.Type,
+		Data:       event.Data,
+		OccurredAt: event.OccurredAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(rule.WebhookSecret))
+	mac.Write(body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook call failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", strings.TrimSpace(resp.Status))
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/automation/domain"
+	"github.com/aceextension/automation/repository"
+	"github.com/aceextension/core/events"
+	"github.com/aceextension/core/logger"
+	"github.com/google/uuid"
+)
+
+// queueSize is how many published events can wait for the worker
+const queueSize = 1000
+
+// automationService implements AutomationService
+type automationService struct {
+	repo   repository.AutomationRepository
+	queue  chan events.Event
+	client *webhookClient
+
+	mu               sync.RWMutex
+	triggers         map[string]domain.Trigger
+	attributeTargets map[string]AttributeSetter
+}
+
+// NewAutomationService creates a new automation service
+func NewAutomationService(repo repository.AutomationRepository) AutomationService {
+	return &automationService{
+		repo:             repo,
+		queue:            make(chan events.Event, queueSize),
+		client:           newWebhookClient(),
+		triggers:         make(map[string]domain.Trigger),
+		attributeTargets: make(map[string]AttributeSetter),
+	}
+}
+
+// RegisterTrigger makes an event type available to rules
+func (s *automationService) RegisterTrigger(trigger domain.Trigger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.triggers[trigger.Event] = trigger
+}
+
+// RegisterAttributeTarget enables set_attribute on an entity type
+func (s *automationService) RegisterAttributeTarget(entityType string, set AttributeSetter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributeTargets[entityType] = set
+}
+
+// Triggers lists the registered triggers
+func (s *automationService) Triggers() []domain.Trigger {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	triggers := make([]domain.Trigger, 0, len(s.triggers))
+	for _, trigger := range s.triggers {
+		triggers = append(triggers, trigger)
+	}
+	sort.Slice(triggers, func(i, j int) bool { return triggers[i].Event < triggers[j].Event })
+	return triggers
+}
+
+// CreateRule stores a new rule within the tenant's rule limit
+func (s *automationService) CreateRule(ctx context.Context, tenantID uuid.UUID, input RuleInput, userID *uuid.UUID) (*domain.Rule, error) {
+	count, err := s.repo.CountRules(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= domain.MaxRulesPerTenant {
+		return nil, domain.ErrRuleLimit
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	now := time.Now()
+	rule := &domain.Rule{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		WebhookSecret: hex.EncodeToString(secret),
+		CreatedBy:     userID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.apply(rule, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "CREATE_AUTOMATION", rule, userID)
+	return rule, nil
+}
+
+// GetRule retrieves a rule
+func (s *automationService) GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.Rule, error) {
+	return s.repo.GetRule(ctx, tenantID, id)
+}
+
+// ListRules returns the tenant's rules
+func (s *automationService) ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.Rule, error) {
+	return s.repo.ListRules(ctx, tenantID)
+}
+
+// UpdateRule replaces a rule's definition; its webhook secret is kept
+func (s *automationService) UpdateRule(ctx context.Context, tenantID, id uuid.UUID, input RuleInput, userID *uuid.UUID) (*domain.Rule, error) {
+	rule, err := s.repo.GetRule(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(rule, input); err != nil {
+		return nil, err
+	}
+	rule.UpdatedAt = time.Now()
+	if err := s.repo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "UPDATE_AUTOMATION", rule, userID)
+	return rule, nil
+}
+
+// DeleteRule removes a rule
+func (s *automationService) DeleteRule(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) error {
+	rule, err := s.repo.GetRule(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteRule(ctx, tenantID, id); err != nil {
+		return err
+	}
+
+	s.audit(ctx, "DELETE_AUTOMATION", rule, userID)
+	return nil
+}
+
+// Runs returns the execution log
+func (s *automationService) Runs(ctx context.Context, tenantID uuid.UUID, filter domain.RunFilter) ([]*domain.Run, error) {
+	return s.repo.ListRuns(ctx, tenantID, filter)
+}
+
+// apply validates the input against its trigger and copies it onto the rule
+func (s *automationService) apply(rule *domain.Rule, input RuleInput) error {
+	s.mu.RLock()
+	trigger, ok := s.triggers[input.Trigger]
+	targets := make(map[string]bool, len(s.attributeTargets))
+	for entityType := range s.attributeTargets {
+		targets[entityType] = true
+	}
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: unknown trigger %q", domain.ErrInvalidRule, input.Trigger)
+	}
+
+	rule.Name = input.Name
+	rule.Trigger = input.Trigger
+	rule.Conditions = input.Conditions
+	rule.Actions = input.Actions
+	rule.Enabled = input.Enabled
+	if rule.Conditions == nil {
+		rule.Conditions = []domain.Condition{}
+	}
+	return rule.Validate(trigger, targets)
+}
+
+// Handle queues an event with a registered trigger
+func (s *automationService) Handle(event events.Event) {
+	s.mu.RLock()
+	_, ok := s.triggers[event.Type]
+	s.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case s.queue <- event:
+	default:
+		logger.Log.Warn(fmt.Sprintf("Automation queue full, dropped %s event %s for tenant %s", event.Type, event.ID, event.TenantID))
+	}
+}
+
+// Work executes queued events one at a time
+func (s *automationService) Work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-s.queue:
+			if err := s.Execute(ctx, event); err != nil {
+				logger.Log.Error(fmt.Sprintf("Automation error for %s event %s: %v", event.Type, event.ID, err))
+			}
+		}
+	}
+}
+
+// Execute runs every enabled rule on the event's trigger whose conditions hold.
+// Runs past the tenant's hourly limit are logged as skipped; a failed action is
+// recorded and the rule's remaining actions still run.
+func (s *automationService) Execute(ctx context.Context, event events.Event) error {
+	rules, err := s.repo.EnabledRules(ctx, event.TenantID, event.Type)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	data, err := eventData(event)
+	if err != nil {
+		return err
+	}
+
+	used, err := s.repo.CountRunsSince(ctx, event.TenantID, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if !rule.Matches(data) {
+			continue
+		}
+
+		run := domain.NewRun(rule, event.ID, event.Type)
+		if used >= domain.MaxRunsPerHour {
+			run.Skip()
+		} else {
+			used++
+			for _, action := range rule.Actions {
+				run.Record(action.Type, s.perform(ctx, rule, action, event, data))
+			}
+			run.Finish()
+		}
+
+		if err := s.repo.CreateRun(ctx, run); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to log run of automation %s: %v", rule.ID, err))
+		}
+	}
+	return nil
+}
+
+// eventData converts an event's payload to the field map conditions are tested on
+func eventData(event events.Event) (map[string]any, error) {
+	raw, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+	data := map[string]any{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event data: %w", err)
+	}
+	return data, nil
+}
+
+// audit logs a change to a rule
+func (s *automationService) audit(ctx context.Context, action string, rule *domain.Rule, userID *uuid.UUID) {
+	if audit.Service == nil {
+		return
+	}
+	entityIDStr := rule.ID.String()
+	audit.Service.Log(ctx, action, "AutomationRule", &entityIDStr, map[string]interface{}{
+		"name":    rule.Name,
+		"trigger": rule.Trigger,
+		"enabled": rule.Enabled,
+		"actions": len(rule.Actions),
+	}, &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &rule.TenantID,
+	})
+}
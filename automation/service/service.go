@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"github.com/aceextension/automation/domain"
+	"github.com/aceextension/core/events"
+	"github.com/google/uuid"
+)
+
+// AttributeSetter sets a custom attribute on a record of a registered entity type,
+// returning an error if the tenant has no such record
+type AttributeSetter func(ctx context.Context, tenantID, entityID uuid.UUID, attribute string, value any) error
+
+// RuleInput holds the editable fields of a rule
+type RuleInput struct {
+	Name       string
+	Trigger    string
+	Conditions []domain.Condition
+	Actions    []domain.Action
+	Enabled    bool
+}
+
+// AutomationService defines the interface for tenant automations
+type AutomationService interface {
+	// RegisterTrigger makes an event type available to rules; the publishing module registers it
+	RegisterTrigger(trigger domain.Trigger)
+	// RegisterAttributeTarget enables set_attribute on an entity type owned by another module
+	RegisterAttributeTarget(entityType string, set AttributeSetter)
+	// Triggers lists the registered triggers by event type
+	Triggers() []domain.Trigger
+
+	// CreateRule validates and stores a rule with a new webhook secret
+	CreateRule(ctx context.Context, tenantID uuid.UUID, input RuleInput, userID *uuid.UUID) (*domain.Rule, error)
+	GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.Rule, error)
+	ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.Rule, error)
+	UpdateRule(ctx context.Context, tenantID, id uuid.UUID, input RuleInput, userID *uuid.UUID) (*domain.Rule, error)
+	// DeleteRule removes a rule with its execution log
+	DeleteRule(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) error
+	// Runs returns the execution log, newest first
+	Runs(ctx context.Context, tenantID uuid.UUID, filter domain.RunFilter) ([]*domain.Run, error)
+
+	// Handle queues a published event for the worker without blocking the publisher;
+	// events are dropped, and logged, while the queue is full
+	Handle(event events.Event)
+	// Work executes queued events until the context is done
+	Work(ctx context.Context)
+	// Execute runs the tenant's matching rules for an event and logs each run
+	Execute(ctx context.Context, event events.Event) error
+}
@@ -1,14 +1,69 @@
 package catalog
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/automation"
+	automationDomain "github.com/aceextension/automation/domain"
+	"github.com/aceextension/catalog/domain"
 	"github.com/aceextension/catalog/repository"
 	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/comments"
+	commentsDomain "github.com/aceextension/comments/domain"
+	commentsService "github.com/aceextension/comments/service"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/onboarding"
+	onboardingDomain "github.com/aceextension/onboarding/domain"
+	onboardingService "github.com/aceextension/onboarding/service"
+	"github.com/aceextension/tags"
+	tagsDomain "github.com/aceextension/tags/domain"
+	"github.com/google/uuid"
 )
 
+// repricingHour is the local hour at which the nightly repricing job runs
+const repricingHour = 2
+
+// demandHour is the local hour at which demand statistics are recomputed, after repricing
+const demandHour = 3
+
+// alertRuleHour is the local hour at which low-stock and expiry alert rules are evaluated
+const alertRuleHour = 7
+
+// reservationExpiryInterval is how often lapsed stock reservations are marked expired
+const reservationExpiryInterval = 5 * time.Minute
+
+// productTriggerFields are the fields of domain.ProductChange automations can use
+var productTriggerFields = []automationDomain.TriggerField{
+	{Name: "id", Type: automationDomain.FieldID, EntityType: automationDomain.EntityProduct},
+	{Name: "productCode", Type: automationDomain.FieldString},
+	{Name: "name", Type: automationDomain.FieldString},
+	{Name: "sellingPrice", Type: automationDomain.FieldNumber},
+	{Name: "barcode", Type: automationDomain.FieldString},
+	{Name: "isActive", Type: automationDomain.FieldBool},
+}
+
 // Module-level service instances
 var (
-	CategoryService service.CategoryService
-	ProductService  service.ProductService
+	CategoryService  service.CategoryService
+	ProductService   service.ProductService
+	UnitService      service.UnitService
+	TaxService       service.TaxService
+	HSCodeService    service.HSCodeService
+	QuickPickService service.QuickPickService
+	PricingService   service.PricingService
+	GuardrailService service.GuardrailService
+	BarcodeService   service.BarcodeService
+	AssemblyService  service.AssemblyService
+	BinService       service.BinService
+
+	ReservationService service.ReservationService
+	DemandService      service.DemandService
+	AlertRuleService   service.AlertRuleService
+
+	LocalizationService service.LocalizationService
+	VariantService      service.VariantService
 )
 
 // Init initializes the catalog module
@@ -16,8 +71,158 @@ func Init() {
 	// Initialize repositories
 	categoryRepo := repository.NewPostgresCategoryRepository()
 	productRepo := repository.NewPostgresProductRepository()
+	unitRepo := repository.NewPostgresUnitRepository()
+	taxRepo := repository.NewPostgresTaxRepository()
+	hsCodeRepo := repository.NewPostgresHSCodeRepository()
+	quickPickRepo := repository.NewPostgresQuickPickRepository()
+	pricingRepo := repository.NewPostgresPricingRepository()
+	guardrailRepo := repository.NewPostgresGuardrailRepository()
+	barcodeRuleRepo := repository.NewPostgresBarcodeRuleRepository()
+	productBarcodeRepo := repository.NewPostgresProductBarcodeRepository()
+	assemblyRepo := repository.NewPostgresAssemblyRepository()
+	binRepo := repository.NewPostgresBinRepository()
+	reservationRepo := repository.NewPostgresReservationRepository()
+	demandRepo := repository.NewPostgresDemandRepository()
+	alertRuleRepo := repository.NewPostgresAlertRuleRepository()
 
 	// Initialize services
-	CategoryService = service.NewCategoryService(categoryRepo)
-	ProductService = service.NewProductService(productRepo)
+	UnitService = service.NewUnitService(unitRepo)
+	TaxService = service.NewTaxService(taxRepo, categoryRepo)
+	HSCodeService = service.NewHSCodeService(hsCodeRepo)
+	CategoryService = service.NewCategoryService(categoryRepo, TaxService)
+	GuardrailService = service.NewGuardrailService(guardrailRepo, productRepo, TaxService)
+	PricingService = service.NewPricingService(pricingRepo, repository.NewPostgresBulkPriceRepository(), productRepo, categoryRepo, GuardrailService)
+	ProductService = service.NewProductService(productRepo, categoryRepo, productBarcodeRepo, UnitService, TaxService, PricingService, GuardrailService)
+	QuickPickService = service.NewQuickPickService(quickPickRepo, productRepo)
+	BarcodeService = service.NewBarcodeService(barcodeRuleRepo, productRepo, productBarcodeRepo, UnitService)
+	AssemblyService = service.NewAssemblyService(assemblyRepo, productRepo, PricingService)
+	BinService = service.NewBinService(binRepo, productRepo, UnitService)
+	ReservationService = service.NewReservationService(reservationRepo, productRepo, binRepo, UnitService)
+	// Modules that record sales register their quantities with DemandService.RegisterSource
+	DemandService = service.NewDemandService(demandRepo, productRepo, ReservationService)
+	AlertRuleService = service.NewAlertRuleService(alertRuleRepo, productRepo, categoryRepo, ReservationService)
+	LocalizationService = service.NewLocalizationService(repository.NewPostgresLocalizationRepository(), productRepo, categoryRepo)
+	VariantService = service.NewVariantService(repository.NewPostgresVariantRepository(), ProductService)
+
+	// Products can be tagged and filtered by tag; call tags.Init first
+	if tags.TagService != nil {
+		tags.TagService.RegisterEntityType(tagsDomain.EntityProduct, productRepo.CountByIDs)
+	}
+
+	// Teams can discuss products; call comments.Init first
+	if comments.CommentService != nil {
+		comments.CommentService.RegisterEntityType(commentsDomain.EntityProduct, commentsService.ExistsByCount(productRepo.CountByIDs))
+	}
+
+	// Tenants can automate on products being added and changed, and set their attributes;
+	// call automation.Init first
+	if automation.AutomationService != nil {
+		automation.AutomationService.RegisterTrigger(automationDomain.Trigger{
+			Event:       domain.EventProductCreated,
+			Description: "A product is added",
+			Fields:      productTriggerFields,
+		})
+		automation.AutomationService.RegisterTrigger(automationDomain.Trigger{
+			Event:       domain.EventProductUpdated,
+			Description: "A product is changed",
+			Fields:      productTriggerFields,
+		})
+		automation.AutomationService.RegisterAttributeTarget(automationDomain.EntityProduct, setProductAttribute)
+	}
+
+	// Adding a product is a setup step; call onboarding.Init first
+	if onboarding.ChecklistService != nil {
+		onboarding.ChecklistService.RegisterStep(onboardingDomain.Step{Key: onboardingDomain.StepFirstProduct, Title: "Add your first product"}, onboardingService.DoneByCount(productRepo.Count))
+	}
+}
+
+// setProductAttribute saves a custom attribute set by an automation
+func setProductAttribute(ctx context.Context, tenantID, productID uuid.UUID, attribute string, value any) error {
+	product, err := ProductService.GetByID(ctx, tenantID, productID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrProductNotFound, productID)
+	}
+	product.SetCustomAttribute(attribute, value)
+	product.UpdatedAt = time.Now()
+	return ProductService.Update(ctx, product)
+}
+
+// StartRepricingScheduler runs the markup repricing job every night at repricingHour.
+// Call once after Init from the process that hosts background jobs.
+func StartRepricingScheduler() {
+	go func() {
+		for {
+			time.Sleep(time.Until(nextRepricingRun(time.Now())))
+			if err := PricingService.RunScheduledRepricing(context.Background()); err != nil {
+				logger.Log.Error("Nightly repricing error: " + err.Error())
+			}
+		}
+	}()
+}
+
+// StartReservationExpiryWorker marks lapsed stock reservations expired every
+// reservationExpiryInterval. Call once after Init from the process that hosts background jobs.
+func StartReservationExpiryWorker() {
+	go func() {
+		ticker := time.NewTicker(reservationExpiryInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := ReservationService.ExpireDue(context.Background()); err != nil {
+				logger.Log.Error("Reservation expiry worker error: " + err.Error())
+			}
+		}
+	}()
+}
+
+// StartDemandScheduler recomputes sales velocity and seasonality every night at demandHour.
+// Call once after Init and after the modules with demand sources have registered them.
+func StartDemandScheduler() {
+	go func() {
+		for {
+			time.Sleep(time.Until(nextDemandRun(time.Now())))
+			if err := DemandService.RunScheduled(context.Background()); err != nil {
+				logger.Log.Error("Nightly demand statistics error: " + err.Error())
+			}
+		}
+	}()
+}
+
+// StartAlertRuleScheduler evaluates low-stock and expiry alert rules every morning at
+// alertRuleHour. Call once after Init; alerts are sent through the notification module.
+func StartAlertRuleScheduler() {
+	go func() {
+		for {
+			time.Sleep(time.Until(nextAlertRuleRun(time.Now())))
+			if err := AlertRuleService.RunScheduled(context.Background()); err != nil {
+				logger.Log.Error("Daily alert rule evaluation error: " + err.Error())
+			}
+		}
+	}()
+}
+
+// nextRepricingRun returns the next repricingHour after now
+func nextRepricingRun(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), repricingHour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// nextDemandRun returns the next demandHour after now
+func nextDemandRun(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), demandHour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// nextAlertRuleRun returns the next alertRuleHour after now
+func nextAlertRuleRun(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), alertRuleHour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
 }
@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidAlertRule is returned when an alert rule is malformed
+	ErrInvalidAlertRule = errors.New("invalid alert rule")
+	// ErrAlertRuleNotFound is returned when an alert rule does not exist for the tenant
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+)
+
+// maxAlertRecipients caps how many addresses one rule notifies
+const maxAlertRecipients = 20
+
+// AlertCondition is what an alert rule watches for
+type AlertCondition string
+
+const (
+	AlertLowStock AlertCondition = "low_stock" // On hand below Threshold units
+	AlertExpiring AlertCondition = "expiring"  // Expiry date within Threshold days
+)
+
+// AlertChannel is how an alert rule's recipients are told; the values match the
+// notification module's channels
+type AlertChannel string
+
+const (
+	AlertChannelEmail    AlertChannel = "EMAIL"
+	AlertChannelSMS      AlertChannel = "SMS"
+	AlertChannelWhatsApp AlertChannel = "WHATSAPP"
+)
+
+// AlertSeverity is how bad a product's alert is. A product is notified again only
+// when its severity rises.
+type AlertSeverity string
+
+const (
+	AlertWarning  AlertSeverity = "warning"  // Under the threshold, or expiring within it
+	AlertCritical AlertSeverity = "critical" // Out of stock, or already expired
+)
+
+// rank orders severities so escalation can be detected
+func (s AlertSeverity) rank() int {
+	if s == AlertCritical {
+		return 2
+	}
+	return 1
+}
+
+// AlertRule is a tenant's standing request to be told about products, e.g.
+// "notify me when any pharmacy item is under 10 units". A rule covers a product,
+// a category with its subcategories, or every product when neither is set.
+type AlertRule struct {
+	ID         uuid.UUID
+	TenantID   uuid.UUID
+	Name       string
+	Condition  AlertCondition
+	Threshold  float64 // Units for low_stock, days for expiring
+	CategoryID *uuid.UUID
+	ProductID  *uuid.UUID
+	Recipients []string // Email addresses or phone numbers, per Channel
+	Channel    AlertChannel
+	IsActive   bool
+
+	LastEvaluatedAt *time.Time
+
+	// Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewAlertRule creates an active alert rule
+func NewAlertRule(tenantID uuid.UUID, name string, condition AlertCondition, threshold float64, recipients []string, channel AlertChannel) *AlertRule {
+	now := time.Now()
+	return &AlertRule{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		Name:       strings.TrimSpace(name),
+		Condition:  condition,
+		Threshold:  threshold,
+		Recipients: NormalizeRecipients(recipients),
+		Channel:    channel,
+		IsActive:   true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// NormalizeRecipients trims recipients and drops blanks and repeats
+func NormalizeRecipients(recipients []string) []string {
+	seen := make(map[string]bool, len(recipients))
+	normalized := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		recipient = strings.TrimSpace(recipient)
+		if recipient == "" || seen[strings.ToLower(recipient)] {
+			continue
+		}
+		seen[strings.ToLower(recipient)] = true
+		normalized = append(normalized, recipient)
+	}
+	return normalized
+}
+
+// Validate checks the condition, threshold, scope and recipients
+func (r *AlertRule) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	switch r.Condition {
+	case AlertLowStock:
+		if r.Threshold <= 0 {
+			return errors.New("low stock threshold must be positive")
+		}
+	case AlertExpiring:
+		if r.Threshold < 1 || r.Threshold > 730 || r.Threshold != math.Trunc(r.Threshold) {
+			return errors.New("expiry threshold must be a whole number of days between 1 and 730")
+		}
+	default:
+		return errors.New("condition must be low_stock or expiring")
+	}
+	if r.CategoryID != nil && r.ProductID != nil {
+		return errors.New("a rule covers a category or a product, not both")
+	}
+	if r.Channel != AlertChannelEmail && r.Channel != AlertChannelSMS && r.Channel != AlertChannelWhatsApp {
+		return errors.New("channel must be EMAIL, SMS or WHATSAPP")
+	}
+	if len(r.Recipients) == 0 {
+		return errors.New("at least one recipient is required")
+	}
+	if len(r.Recipients) > maxAlertRecipients {
+		return fmt.Errorf("at most %d recipients are allowed", maxAlertRecipients)
+	}
+	if r.Channel == AlertChannelEmail {
+		for _, recipient := range r.Recipients {
+			if !strings.Contains(recipient, "@") {
+				return fmt.Errorf("%s is not an email address", recipient)
+			}
+		}
+	}
+	return nil
+}
+
+// AlertCandidate is a product in a rule's scope with what the rule checks
+type AlertCandidate struct {
+	ProductID   uuid.UUID
+	ProductCode string
+	ProductName string
+	Unit        string
+	OnHand      float64
+	ExpiryDate  *time.Time
+}
+
+// Evaluate reports whether the candidate trips the rule on today, and how badly
+func (r *AlertRule) Evaluate(candidate AlertCandidate, today time.Time) (AlertSeverity, string, bool) {
+	switch r.Condition {
+	case AlertLowStock:
+		if candidate.OnHand <= 0 {
+			return AlertCritical, fmt.Sprintf("out of stock (%g %s)", candidate.OnHand, candidate.Unit), true
+		}
+		if candidate.OnHand < r.Threshold {
+			return AlertWarning, fmt.Sprintf("%g %s left, under %g", candidate.OnHand, candidate.Unit, r.Threshold), true
+		}
+	case AlertExpiring:
+		if candidate.ExpiryDate == nil {
+			return "", "", false
+		}
+		days := int(math.Round(candidate.ExpiryDate.Sub(today).Hours() / 24))
+		if days < 0 {
+			return AlertCritical, "expired on " + candidate.ExpiryDate.Format("2006-01-02"), true
+		}
+		if float64(days) <= r.Threshold {
+			return AlertWarning, fmt.Sprintf("expires on %s, in %d days", candidate.ExpiryDate.Format("2006-01-02"), days), true
+		}
+	}
+	return "", "", false
+}
+
+// StockAlert is a product tripping a rule. It stays open while the condition
+// holds so the product is not notified every day, and resolves once it clears.
+type StockAlert struct {
+	ID            uuid.UUID
+	TenantID      uuid.UUID
+	RuleID        uuid.UUID
+	ProductID     uuid.UUID
+	ProductCode   string
+	ProductName   string
+	Severity      AlertSeverity
+	Detail        string
+	RaisedAt      time.Time
+	NotifiedAt    *time.Time // Last time recipients were told, at raising or escalation
+	NotifiedCount int
+	ResolvedAt    *time.Time
+}
+
+// NewStockAlert opens an alert for a product
+func NewStockAlert(rule *AlertRule, candidate AlertCandidate, severity AlertSeverity, detail string) *StockAlert {
+	return &StockAlert{
+		ID:          uuid.New(),
+		TenantID:    rule.TenantID,
+		RuleID:      rule.ID,
+		ProductID:   candidate.ProductID,
+		ProductCode: candidate.ProductCode,
+		ProductName: candidate.ProductName,
+		Severity:    severity,
+		Detail:      detail,
+		RaisedAt:    time.Now(),
+	}
+}
+
+// Escalates reports whether severity is worse than the alert's
+func (a *StockAlert) Escalates(severity AlertSeverity) bool {
+	return severity.rank() > a.Severity.rank()
+}
+
+// AlertFilter narrows a stock alert listing
+type AlertFilter struct {
+	RuleID   *uuid.UUID
+	OpenOnly bool
+	Limit    int
+	Offset   int
+}
+
+// AlertRun is the outcome of evaluating a tenant's rules
+type AlertRun struct {
+	Rules     int
+	Raised    int
+	Escalated int
+	Resolved  int
+	Notified  int // Messages sent
+}
@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidBOM is returned when a bill of materials is malformed
+	ErrInvalidBOM = errors.New("invalid bill of materials")
+	// ErrBOMNotFound is returned when a product has no bill of materials
+	ErrBOMNotFound = errors.New("product has no bill of materials")
+	// ErrAssemblyOrderNotFound is returned when an assembly order does not exist for the tenant
+	ErrAssemblyOrderNotFound = errors.New("assembly order not found")
+	// ErrAssemblyOrderState is returned when an order cannot move to the requested status
+	ErrAssemblyOrderState = errors.New("assembly order cannot change to that status")
+)
+
+// BOMComponent is a raw material or sub-assembly used to make one unit of a finished product
+type BOMComponent struct {
+	ID           uuid.UUID
+	ComponentID  uuid.UUID
+	Quantity     float64 // Per unit of the finished product, in the component's unit
+	WastePercent float64 // Expected scrap on top of Quantity
+}
+
+// RequiredFor returns the component quantity consumed to make quantity finished units
+func (c *BOMComponent) RequiredFor(quantity float64) float64 {
+	return roundQuantity(c.Quantity * (1 + c.WastePercent/100) * quantity)
+}
+
+// BillOfMaterials lists the components a finished product is assembled from
+type BillOfMaterials struct {
+	TenantID   uuid.UUID
+	ProductID  uuid.UUID
+	Components []BOMComponent
+	UpdatedAt  time.Time
+}
+
+// Validate checks that the BOM has components, none of them the product itself or repeated
+func (b *BillOfMaterials) Validate() error {
+	if len(b.Components) == 0 {
+		return errors.New("at least one component is required")
+	}
+
+	seen := make(map[uuid.UUID]bool, len(b.Components))
+	for _, component := range b.Components {
+		if component.ComponentID == b.ProductID {
+			return errors.New("a product cannot be a component of itself")
+		}
+		if seen[component.ComponentID] {
+			return errors.New("each component may appear only once")
+		}
+		seen[component.ComponentID] = true
+
+		if component.Quantity <= 0 {
+			return errors.New("component quantity must be positive")
+		}
+		if component.WastePercent < 0 || component.WastePercent >= 100 {
+			return errors.New("waste percent must be between 0 and 100")
+		}
+	}
+	return nil
+}
+
+// Cost rolls up the standard cost of one finished unit from component unit costs
+func (b *BillOfMaterials) Cost(unitCosts map[uuid.UUID]float64) float64 {
+	total := 0.0
+	for i := range b.Components {
+		total += b.Components[i].RequiredFor(1) * unitCosts[b.Components[i].ComponentID]
+	}
+	return roundCurrency(total)
+}
+
+// AssemblyOrderStatus represents where an assembly order is in production
+type AssemblyOrderStatus string
+
+const (
+	AssemblyOrderDraft      AssemblyOrderStatus = "draft"       // Planned; nothing consumed yet
+	AssemblyOrderInProgress AssemblyOrderStatus = "in_progress" // Components issued to work in progress
+	AssemblyOrderCompleted  AssemblyOrderStatus = "completed"   // Finished goods received into stock
+	AssemblyOrderCancelled  AssemblyOrderStatus = "cancelled"
+)
+
+// AssemblyStage is the accounting event of an assembly order
+type AssemblyStage string
+
+const (
+	// AssemblyStageStart moves component cost from raw materials into work in progress
+	AssemblyStageStart AssemblyStage = "start"
+	// AssemblyStageComplete moves the rolled-up cost from work in progress into finished goods
+	AssemblyStageComplete AssemblyStage = "complete"
+)
+
+// AssemblyOrder builds a quantity of a finished product from its bill of materials.
+// Components are costed when the order is planned; overhead (labour, power) is
+// added on completion and the total is spread over the units actually produced.
+type AssemblyOrder struct {
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	OrderNumber string
+	ProductID   uuid.UUID
+	Quantity    float64 // Planned output
+	ProducedQty float64 // Actual output, set on completion
+	Status      AssemblyOrderStatus
+	Components  []AssemblyComponent
+
+	// Cost roll-up
+	MaterialCost float64
+	OverheadCost float64
+	TotalCost    float64
+	UnitCost     float64 // TotalCost / ProducedQty; becomes the product's cost price
+
+	// Ledger links
+	WIPJournalEntryID      *uuid.UUID // Raw materials -> work in progress
+	FinishedJournalEntryID *uuid.UUID // Work in progress -> finished goods
+
+	Note        *string
+	CreatedBy   *uuid.UUID
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+
+	// Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AssemblyComponent is a component consumed by an assembly order
+type AssemblyComponent struct {
+	ID          uuid.UUID
+	OrderID     uuid.UUID
+	ComponentID uuid.UUID
+	Quantity    float64
+	UnitCost    float64
+	Cost        float64
+}
+
+// NewAssemblyOrder plans an order from a BOM, costing components at unitCosts
+func NewAssemblyOrder(tenantID uuid.UUID, bom *BillOfMaterials, quantity float64, unitCosts map[uuid.UUID]float64) *AssemblyOrder {
+	now := time.Now()
+	order := &AssemblyOrder{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		ProductID:  bom.ProductID,
+		Quantity:   quantity,
+		Status:     AssemblyOrderDraft,
+		Components: make([]AssemblyComponent, 0, len(bom.Components)),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	for i := range bom.Components {
+		required := bom.Components[i].RequiredFor(quantity)
+		unitCost := unitCosts[bom.Components[i].ComponentID]
+		component := AssemblyComponent{
+			ID:          uuid.New(),
+			OrderID:     order.ID,
+			ComponentID: bom.Components[i].ComponentID,
+			Quantity:    required,
+			UnitCost:    unitCost,
+			Cost:        roundCurrency(required * unitCost),
+		}
+		order.MaterialCost += component.Cost
+		order.Components = append(order.Components, component)
+	}
+	order.MaterialCost = roundCurrency(order.MaterialCost)
+	order.TotalCost = order.MaterialCost
+
+	return order
+}
+
+// PlannedUnitCost returns the material cost per unit if the planned quantity is produced
+func (o *AssemblyOrder) PlannedUnitCost() float64 {
+	if o.Quantity <= 0 {
+		return 0
+	}
+	return roundCurrency(o.TotalCost / o.Quantity)
+}
+
+// Start issues the components to work in progress
+func (o *AssemblyOrder) Start() error {
+	if o.Status != AssemblyOrderDraft {
+		return ErrAssemblyOrderState
+	}
+	now := time.Now()
+	o.Status = AssemblyOrderInProgress
+	o.StartedAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// Complete receives the finished goods and rolls up their unit cost
+func (o *AssemblyOrder) Complete(producedQty, overheadCost float64) error {
+	if o.Status != AssemblyOrderInProgress {
+		return ErrAssemblyOrderState
+	}
+	if producedQty <= 0 {
+		return errors.New("produced quantity must be positive")
+	}
+	if overheadCost < 0 {
+		return errors.New("overhead cost cannot be negative")
+	}
+
+	now := time.Now()
+	o.Status = AssemblyOrderCompleted
+	o.ProducedQty = producedQty
+	o.OverheadCost = roundCurrency(overheadCost)
+	o.TotalCost = roundCurrency(o.MaterialCost + o.OverheadCost)
+	o.UnitCost = roundCurrency(o.TotalCost / producedQty)
+	o.CompletedAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// Cancel abandons an order that has not started
+func (o *AssemblyOrder) Cancel() error {
+	if o.Status != AssemblyOrderDraft {
+		return ErrAssemblyOrderState
+	}
+	o.Status = AssemblyOrderCancelled
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+// roundQuantity rounds to the 3 decimal places quantities are stored with
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
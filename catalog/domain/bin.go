@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidBin is returned when a bin location is malformed
+	ErrInvalidBin = errors.New("invalid bin location")
+	// ErrBinNotFound is returned when a bin does not exist for the tenant
+	ErrBinNotFound = errors.New("bin location not found")
+	// ErrBinCodeExists is returned when another of the tenant's bins has the code
+	ErrBinCodeExists = errors.New("bin code already exists")
+	// ErrBinNotEmpty is returned when deleting a bin that still holds stock
+	ErrBinNotEmpty = errors.New("bin still holds stock")
+	// ErrInsufficientBinStock is returned when moving more out of a bin than it holds
+	ErrInsufficientBinStock = errors.New("not enough stock in bin")
+)
+
+// maxBinCodeLength matches the bins.code column
+const maxBinCodeLength = 30
+
+// Bin is a shelf location in the store, e.g. aisle A, rack 03, shelf 2 coded "A-03-2".
+// Pickers walk bins in PickSequence order, then by code.
+type Bin struct {
+	ID           uuid.UUID
+	TenantID     uuid.UUID
+	WarehouseID  *uuid.UUID // Inventory warehouse the bin is in; nil without the inventory module
+	Code         string     // Unique per tenant, stored upper case
+	Zone         *string    // Optional grouping such as "Cold room" or "Back store"
+	Description  *string
+	PickSequence int
+	IsActive     bool
+
+	// Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewBin creates an active bin location
+func NewBin(tenantID uuid.UUID, code string, pickSequence int) *Bin {
+	now := time.Now()
+	return &Bin{
+		ID:           uuid.New(),
+		TenantID:     tenantID,
+		Code:         NormalizeBinCode(code),
+		PickSequence: pickSequence,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// NormalizeBinCode trims and upper-cases a bin code so "a-03-2" and "A-03-2" are one bin
+func NormalizeBinCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks the code and pick sequence
+func (b *Bin) Validate() error {
+	if b.Code == "" {
+		return errors.New("bin code is required")
+	}
+	if len(b.Code) > maxBinCodeLength {
+		return errors.New("bin code must be at most 30 characters")
+	}
+	if b.PickSequence < 0 {
+		return errors.New("pick sequence cannot be negative")
+	}
+	return nil
+}
+
+// BinStock is the quantity of a product held in a bin
+type BinStock struct {
+	BinID        uuid.UUID
+	BinCode      string
+	WarehouseID  *uuid.UUID
+	PickSequence int
+	ProductID    uuid.UUID
+	Quantity     float64
+	UpdatedAt    time.Time
+}
+
+// BinMovementType is why stock moved between bins
+type BinMovementType string
+
+const (
+	BinMovementCount    BinMovementType = "count"    // Quantity set by a shelf count or put-away
+	BinMovementTransfer BinMovementType = "transfer" // Moved from one bin to another
+)
+
+// BinMovement records a change of a product's quantity in bins. A count has only
+// ToBinID, the bin it set, with the signed difference as Quantity; a transfer has both.
+type BinMovement struct {
+	ID        uuid.UUID
+	TenantID  uuid.UUID
+	ProductID uuid.UUID
+	Type      BinMovementType
+	FromBinID *uuid.UUID
+	ToBinID   *uuid.UUID
+	Quantity  float64
+	Note      *string
+	CreatedBy *uuid.UUID
+	CreatedAt time.Time
+}
+
+// NewBinCount creates a movement that sets a product's quantity in a bin; the
+// repository fills in the difference
+func NewBinCount(tenantID, productID, binID uuid.UUID) *BinMovement {
+	return &BinMovement{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		ProductID: productID,
+		Type:      BinMovementCount,
+		ToBinID:   &binID,
+		CreatedAt: time.Now(),
+	}
+}
+
+// NewBinTransfer creates a movement of quantity from one bin to another
+func NewBinTransfer(tenantID, productID, fromBinID, toBinID uuid.UUID, quantity float64) *BinMovement {
+	return &BinMovement{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		ProductID: productID,
+		Type:      BinMovementTransfer,
+		FromBinID: &fromBinID,
+		ToBinID:   &toBinID,
+		Quantity:  quantity,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Validate checks a transfer moves a positive quantity between two different bins
+func (m *BinMovement) Validate() error {
+	if m.FromBinID == nil || m.ToBinID == nil || *m.FromBinID == *m.ToBinID {
+		return errors.New("a transfer needs two different bins")
+	}
+	if m.Quantity <= 0 {
+		return errors.New("transfer quantity must be positive")
+	}
+	return nil
+}
+
+// PickItem is a product and quantity to collect from the shelves, e.g. an order line
+type PickItem struct {
+	ProductID uuid.UUID
+	Quantity  float64
+}
+
+// PickLine tells the picker to take a quantity of a product from a bin
+type PickLine struct {
+	BinID        uuid.UUID
+	BinCode      string
+	WarehouseID  *uuid.UUID
+	PickSequence int
+	ProductID    uuid.UUID
+	Quantity     float64
+}
+
+// PickShortage is the part of an item the bins cannot cover
+type PickShortage struct {
+	ProductID uuid.UUID
+	Quantity  float64
+}
+
+// PickList is the walk through the store that collects a set of items
+type PickList struct {
+	Lines     []PickLine
+	Shortages []PickShortage
+}
+
+// BuildPickList allocates items to the bins holding them and orders the lines by
+// bin, so the picker passes each shelf once. stock lists each product's bins in
+// pick order; items for the same product are combined and taken from its bins in
+// that order, emptying earlier bins first.
+func BuildPickList(items []PickItem, stock map[uuid.UUID][]BinStock) *PickList {
+	var order []uuid.UUID
+	wanted := make(map[uuid.UUID]float64, len(items))
+	for _, item := range items {
+		if _, seen := wanted[item.ProductID]; !seen {
+			order = append(order, item.ProductID)
+		}
+		wanted[item.ProductID] += item.Quantity
+	}
+
+	list := &PickList{Lines: []PickLine{}, Shortages: []PickShortage{}}
+	for _, productID := range order {
+		remaining := roundQuantity(wanted[productID])
+		for _, held := range stock[productID] {
+			if remaining <= 0 {
+				break
+			}
+			if held.Quantity <= 0 {
+				continue
+			}
+			take := held.Quantity
+			if take > remaining {
+				take = remaining
+			}
+			list.Lines = append(list.Lines, PickLine{
+				BinID:        held.BinID,
+				BinCode:      held.BinCode,
+				PickSequence: held.PickSequence,
+				ProductID:    productID,
+				Quantity:     take,
+			})
+			remaining = roundQuantity(remaining - take)
+		}
+		if remaining > 0 {
+			list.Shortages = append(list.Shortages, PickShortage{ProductID: productID, Quantity: remaining})
+		}
+	}
+
+	sort.SliceStable(list.Lines, func(i, j int) bool {
+		a, b := list.Lines[i], list.Lines[j]
+		if a.PickSequence != b.PickSequence {
+			return a.PickSequence < b.PickSequence
+		}
+		return a.BinCode < b.BinCode
+	})
+	return list
+}
@@ -0,0 +1,237 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidBulkPriceUpdate is returned when a bulk price update's filter or adjustment is malformed
+	ErrInvalidBulkPriceUpdate = errors.New("invalid bulk price update")
+	// ErrBulkPriceTooMany is returned when the filter matches more products than one update may change
+	ErrBulkPriceTooMany = errors.New("filter matches too many products for one bulk price update")
+	// ErrBulkPriceConflict is returned when prices changed between loading and applying; nothing is applied
+	ErrBulkPriceConflict = errors.New("prices changed while the bulk update was applied; preview again")
+	// ErrBulkPriceUpdateNotFound is returned when a rollback token does not match an update
+	ErrBulkPriceUpdateNotFound = errors.New("bulk price update not found")
+	// ErrBulkPriceRollbackExpired is returned when the rollback window has passed
+	ErrBulkPriceRollbackExpired = errors.New("rollback window for this bulk price update has passed")
+	// ErrBulkPriceRolledBack is returned when an update was already rolled back
+	ErrBulkPriceRolledBack = errors.New("bulk price update was already rolled back")
+)
+
+// MaxBulkPriceProducts caps how many products one bulk price update changes
+const MaxBulkPriceProducts = 10000
+
+// BulkPriceRollbackWindow is how long a bulk price update can be rolled back
+const BulkPriceRollbackWindow = 24 * time.Hour
+
+// PriceAdjustmentType is how a bulk price update moves prices
+type PriceAdjustmentType string
+
+const (
+	PriceAdjustmentPercent PriceAdjustmentType = "percent" // Value is a percentage, e.g. 5 or -10
+	PriceAdjustmentAmount  PriceAdjustmentType = "amount"  // Value is added to the price, e.g. 20 or -5
+)
+
+// PriceRoundingMode is the direction prices are rounded in
+type PriceRoundingMode string
+
+const (
+	PriceRoundNearest PriceRoundingMode = "nearest"
+	PriceRoundUp      PriceRoundingMode = "up"
+	PriceRoundDown    PriceRoundingMode = "down"
+)
+
+// PriceRounding rounds adjusted prices to a step, optionally to prices ending in
+// Ending (step 10, ending 9 gives 109, 119, ...). No step rounds to the paisa.
+type PriceRounding struct {
+	Step   float64
+	Mode   PriceRoundingMode
+	Ending float64
+}
+
+// Validate checks the rounding step, mode and ending
+func (r PriceRounding) Validate() error {
+	if r.Step < 0 {
+		return errors.New("rounding step cannot be negative")
+	}
+	switch r.Mode {
+	case "", PriceRoundNearest, PriceRoundUp, PriceRoundDown:
+	default:
+		return errors.New("rounding mode must be nearest, up or down")
+	}
+	if r.Ending < 0 || (r.Ending > 0 && r.Ending >= r.Step) {
+		return errors.New("rounding ending must be below the step")
+	}
+	return nil
+}
+
+// Round rounds a price under the rule
+func (r PriceRounding) Round(price float64) float64 {
+	if r.Step <= 0 {
+		return math.Round(price*100) / 100
+	}
+	// Small epsilon so float error doesn't move a price a whole step
+	steps := (price - r.Ending) / r.Step
+	switch r.Mode {
+	case PriceRoundUp:
+		steps = math.Ceil(steps - 1e-9)
+	case PriceRoundDown:
+		steps = math.Floor(steps + 1e-9)
+	default:
+		steps = math.Round(steps)
+	}
+	return math.Round((steps*r.Step+r.Ending)*100) / 100
+}
+
+// PriceAdjustment is the change a bulk price update makes to each matched price
+type PriceAdjustment struct {
+	Type     PriceAdjustmentType
+	Value    float64
+	Rounding PriceRounding
+}
+
+// Validate checks the adjustment type, value and rounding
+func (a PriceAdjustment) Validate() error {
+	switch a.Type {
+	case PriceAdjustmentPercent:
+		if a.Value <= -100 || a.Value > 1000 {
+			return errors.New("percent adjustment must be above -100 and at most 1000")
+		}
+	case PriceAdjustmentAmount:
+	default:
+		return errors.New("adjustment type must be percent or amount")
+	}
+	if a.Value == 0 {
+		return errors.New("adjustment value cannot be zero")
+	}
+	return a.Rounding.Validate()
+}
+
+// Apply returns the adjusted, rounded price
+func (a PriceAdjustment) Apply(price float64) float64 {
+	if a.Type == PriceAdjustmentPercent {
+		price = price * (1 + a.Value/100)
+	} else {
+		price += a.Value
+	}
+	return a.Rounding.Round(price)
+}
+
+// BulkPriceFilter selects the products a bulk price update changes; empty fields match everything
+type BulkPriceFilter struct {
+	CategoryID      *uuid.UUID  // Includes its subcategories
+	ProductIDs      []uuid.UUID // Only these products
+	Query           string      // Matches name, product code or SKU
+	MinPrice        *float64    // Current selling price at least
+	MaxPrice        *float64    // Current selling price at most
+	IncludeInactive bool        // Also change inactive and discontinued products
+}
+
+// Bulk price item flags
+const (
+	BulkPriceFlagCappedAtMRP = "capped_at_mrp" // The adjusted price was lowered to stay within the MRP
+	BulkPriceFlagBelowCost   = "below_cost"    // The new price is below the cost price
+)
+
+// BulkPriceItem is one product's price before and after a bulk price update
+type BulkPriceItem struct {
+	ProductID   uuid.UUID
+	ProductCode string
+	Name        string
+	CostPrice   float64
+	OldPrice    float64
+	NewPrice    float64
+	Flags       []string
+}
+
+// ChangePercent is the price move as a percentage of the old price
+func (i *BulkPriceItem) ChangePercent() float64 {
+	if i.OldPrice == 0 {
+		return 0
+	}
+	return math.Round((i.NewPrice-i.OldPrice)/i.OldPrice*10000) / 100
+}
+
+// BulkPriceUpdate is a price adjustment applied to every product matching a filter at once.
+// Until RollbackExpiresAt, RollbackToken restores the old prices.
+type BulkPriceUpdate struct {
+	ID                uuid.UUID
+	TenantID          uuid.UUID
+	Filter            BulkPriceFilter
+	Adjustment        PriceAdjustment
+	ProductsMatched   int
+	ProductsChanged   int
+	RollbackToken     string
+	RollbackExpiresAt time.Time
+	AppliedBy         *uuid.UUID
+	AppliedAt         time.Time
+	RolledBackBy      *uuid.UUID
+	RolledBackAt      *time.Time
+
+	// Items are the changed prices; products whose price would not move are left out
+	Items []*BulkPriceItem
+	// Skipped counts matched products left unchanged, by reason
+	Skipped map[string]int
+}
+
+// NewBulkPriceUpdate creates a bulk price update with a fresh rollback token
+func NewBulkPriceUpdate(tenantID uuid.UUID, filter BulkPriceFilter, adjustment PriceAdjustment, appliedBy *uuid.UUID) *BulkPriceUpdate {
+	now := time.Now()
+	return &BulkPriceUpdate{
+		ID:                uuid.New(),
+		TenantID:          tenantID,
+		Filter:            filter,
+		Adjustment:        adjustment,
+		RollbackToken:     newRollbackToken(),
+		RollbackExpiresAt: now.Add(BulkPriceRollbackWindow),
+		AppliedBy:         appliedBy,
+		AppliedAt:         now,
+		Skipped:           map[string]int{},
+	}
+}
+
+// Add records a product's adjusted price, or why it is skipped
+func (u *BulkPriceUpdate) Add(item *BulkPriceItem) {
+	u.ProductsMatched++
+	switch {
+	case item.NewPrice <= 0:
+		u.Skipped["non_positive_price"]++
+	case math.Round(item.NewPrice*100) == math.Round(item.OldPrice*100):
+		u.Skipped["unchanged"]++
+	default:
+		u.Items = append(u.Items, item)
+		u.ProductsChanged++
+	}
+}
+
+// CanRollback reports why the update cannot be rolled back at now, if it cannot
+func (u *BulkPriceUpdate) CanRollback(now time.Time) error {
+	if u.RolledBackAt != nil {
+		return ErrBulkPriceRolledBack
+	}
+	if !now.Before(u.RollbackExpiresAt) {
+		return ErrBulkPriceRollbackExpired
+	}
+	return nil
+}
+
+// BulkPriceRollback is the outcome of rolling back a bulk price update
+type BulkPriceRollback struct {
+	Update   *BulkPriceUpdate
+	Restored int
+	// Kept are items whose price was changed again after the update; they keep that price
+	Kept []*BulkPriceItem
+}
+
+func newRollbackToken() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,11 +1,15 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrCategoryNotFound is returned when a category does not exist for the tenant
+var ErrCategoryNotFound = errors.New("category not found")
+
 // Category represents a product category with hierarchical support
 type Category struct {
 	ID           uuid.UUID
@@ -18,10 +22,14 @@ type Category struct {
 	Path         string     // Materialized path: /1/5/12
 	SortOrder    int        // Display order
 	IsActive     bool
+	TaxGroupID   *uuid.UUID // Default tax group for products in this category
 
 	// Custom attributes stored as JSONB
 	CustomAttributes map[string]interface{}
 
+	// Locale is the language Name and Description were read in; empty for the catalog's own
+	Locale string
+
 	// Metadata
 	CreatedAt time.Time
 	UpdatedAt time.Time
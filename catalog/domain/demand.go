@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrDemandNotFound is returned when no demand statistics have been computed for a product
+	ErrDemandNotFound = errors.New("no demand statistics for product")
+	// ErrInvalidReorderQuery is returned when reorder suggestion parameters are out of range
+	ErrInvalidReorderQuery = errors.New("invalid reorder query")
+)
+
+const (
+	// DemandHistoryDays is how far back sales are read for seasonality
+	DemandHistoryDays = 730
+	// SeasonalityMinDays is the sales history a product needs before its months are
+	// compared; with less, a rising or falling trend would read as a season
+	SeasonalityMinDays = 365
+)
+
+// DemandSource is a module's sold quantities, e.g. sales invoice lines net of returns.
+// DailySQL selects `day, product_id, qty` for tenant $1 with day in [$2, $3); a
+// product may appear more than once per day.
+type DemandSource struct {
+	Name     string
+	DailySQL string
+}
+
+// DemandTotals is a product's sales over the history window as read from the sources
+type DemandTotals struct {
+	ProductID   uuid.UUID
+	Sold30      float64
+	Sold90      float64
+	ByMonth     [12]float64 // Sold in each Bikram Sambat month, Baishakh first
+	FirstSoldOn time.Time
+	LastSoldOn  time.Time
+}
+
+// ProductDemand is a product's sales velocity. Seasonality holds an index per
+// Bikram Sambat month, Baishakh first: 1.5 sells half again the average day, 1 is
+// average or not yet known. BaseDaily is the 90-day rate with the season taken out.
+type ProductDemand struct {
+	TenantID    uuid.UUID
+	ProductID   uuid.UUID
+	ProductCode string
+	ProductName string
+	Unit        string
+	Sold30      float64
+	Sold90      float64
+	AvgDaily30  float64
+	AvgDaily90  float64
+	BaseDaily   float64
+	Seasonality [12]float64
+	FirstSoldOn *time.Time
+	LastSoldOn  *time.Time
+	ComputedAt  time.Time
+}
+
+// NewProductDemand computes a product's statistics from its totals. historyDays is
+// how many days it has been selling, up to DemandHistoryDays; monthDays counts those
+// days per BS month, and recentDays counts the last min(90, historyDays) of them.
+func NewProductDemand(tenantID uuid.UUID, totals DemandTotals, historyDays int, monthDays, recentDays [12]int) *ProductDemand {
+	demand := &ProductDemand{
+		TenantID:    tenantID,
+		ProductID:   totals.ProductID,
+		Sold30:      roundQuantity(totals.Sold30),
+		Sold90:      roundQuantity(totals.Sold90),
+		AvgDaily30:  roundRate(totals.Sold30 / float64(min(30, max(historyDays, 1)))),
+		AvgDaily90:  roundRate(totals.Sold90 / float64(min(90, max(historyDays, 1)))),
+		Seasonality: neutralSeasonality(),
+		FirstSoldOn: &totals.FirstSoldOn,
+		LastSoldOn:  &totals.LastSoldOn,
+		ComputedAt:  time.Now(),
+	}
+
+	if historyDays >= SeasonalityMinDays {
+		demand.Seasonality = SeasonalIndices(totals.ByMonth, monthDays)
+	}
+
+	// Average the index over the recent days so a 90-day rate taken in peak
+	// season is not projected onto the quiet months
+	var weighted float64
+	var days int
+	for i, n := range recentDays {
+		weighted += demand.Seasonality[i] * float64(n)
+		days += n
+	}
+	demand.BaseDaily = demand.AvgDaily90
+	if days > 0 && weighted > 0 {
+		demand.BaseDaily = roundRate(demand.AvgDaily90 / (weighted / float64(days)))
+	}
+
+	return demand
+}
+
+// SeasonalIndices compares the average day of each month with the average day
+// overall. Months with no days in the history are left at 1.
+func SeasonalIndices(sold [12]float64, days [12]int) [12]float64 {
+	indices := neutralSeasonality()
+
+	var total float64
+	var totalDays int
+	for i := range sold {
+		total += sold[i]
+		totalDays += days[i]
+	}
+	if total <= 0 || totalDays == 0 {
+		return indices
+	}
+
+	overall := total / float64(totalDays)
+	for i := range sold {
+		if days[i] > 0 {
+			indices[i] = math.Round(sold[i]/float64(days[i])/overall*10000) / 10000
+		}
+	}
+	return indices
+}
+
+// Forecast returns the expected sales over days falling in the given BS months (1-12), one entry per day
+func (d *ProductDemand) Forecast(months []int) float64 {
+	var quantity float64
+	for _, month := range months {
+		quantity += d.BaseDaily * d.Seasonality[month-1]
+	}
+	return roundQuantity(quantity)
+}
+
+// DemandForecast is a product's expected sales over the days starting at From
+type DemandForecast struct {
+	Demand   *ProductDemand
+	From     time.Time
+	Days     int
+	Quantity float64
+}
+
+// ReorderQuery is the horizon reorder suggestions cover: stock must last through
+// the supplier's lead time and then CoverDays more
+type ReorderQuery struct {
+	LeadDays  int
+	CoverDays int
+	Limit     int
+}
+
+// Validate checks the horizon is between a day and a year
+func (q ReorderQuery) Validate() error {
+	if q.LeadDays < 0 || q.LeadDays > 365 {
+		return errors.New("lead days must be between 0 and 365")
+	}
+	if q.CoverDays < 1 || q.CoverDays > 365 {
+		return errors.New("cover days must be between 1 and 365")
+	}
+	return nil
+}
+
+// ReorderSuggestion is how much of a product to order so it does not run out
+type ReorderSuggestion struct {
+	ProductID    uuid.UUID
+	ProductCode  string
+	ProductName  string
+	Unit         string
+	BaseDaily    float64
+	Forecast     float64 // Expected sales over lead and cover days
+	Available    float64 // On hand less reservations
+	DaysOfStock  float64 // How long Available lasts at BaseDaily
+	SuggestedQty float64 // Forecast less Available, rounded up
+}
+
+// NewReorderSuggestion suggests ordering what the forecast needs beyond the available stock
+func NewReorderSuggestion(demand *ProductDemand, forecast, available float64) ReorderSuggestion {
+	suggestion := ReorderSuggestion{
+		ProductID:   demand.ProductID,
+		ProductCode: demand.ProductCode,
+		ProductName: demand.ProductName,
+		Unit:        demand.Unit,
+		BaseDaily:   demand.BaseDaily,
+		Forecast:    forecast,
+		Available:   available,
+	}
+	if demand.BaseDaily > 0 {
+		suggestion.DaysOfStock = math.Round(available/demand.BaseDaily*10) / 10
+	}
+	if short := roundQuantity(forecast - available); short > 0 {
+		suggestion.SuggestedQty = math.Ceil(short)
+	}
+	return suggestion
+}
+
+func neutralSeasonality() [12]float64 {
+	var indices [12]float64
+	for i := range indices {
+		indices[i] = 1
+	}
+	return indices
+}
+
+func roundRate(rate float64) float64 {
+	return math.Round(rate*10000) / 10000
+}
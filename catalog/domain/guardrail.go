@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAboveMRP is returned when a price including tax exceeds the product's MRP.
+	// Selling above MRP is unlawful for MRP-labelled goods, so it cannot be overridden.
+	ErrAboveMRP = errors.New("price including tax exceeds MRP")
+	// ErrDiscountLimitExceeded is returned when a discount is above the seller's role limit
+	ErrDiscountLimitExceeded = errors.New("discount exceeds the maximum allowed for your role")
+	// ErrInvalidDiscountOverride is returned when an override is missing, expired, used or for another sale
+	ErrInvalidDiscountOverride = errors.New("discount override is not valid for this sale")
+	// ErrOverrideReasonRequired is returned when an override has no reason
+	ErrOverrideReasonRequired = errors.New("a reason is required to override the discount limit")
+)
+
+// DiscountOverrideTTL is how long an approved override can be redeemed at the till
+const DiscountOverrideTTL = 15 * time.Minute
+
+// CheckMRP returns ErrAboveMRP when the tax-inclusive price is above the MRP (if one is set)
+func CheckMRP(priceInclTax float64, mrp *float64) error {
+	if mrp == nil || *mrp <= 0 {
+		return nil
+	}
+	// Compare at paisa precision so rounding noise doesn't trip the check
+	if math.Round(priceInclTax*100) > math.Round(*mrp*100) {
+		return fmt.Errorf("%w: %.2f > MRP %.2f", ErrAboveMRP, priceInclTax, *mrp)
+	}
+	return nil
+}
+
+// DiscountPercent returns the discount of salePrice from listPrice as a percentage
+func DiscountPercent(listPrice, salePrice float64) float64 {
+	if listPrice <= 0 || salePrice >= listPrice {
+		return 0
+	}
+	return math.Round((listPrice-salePrice)/listPrice*10000) / 100
+}
+
+// DiscountLimit caps the discount a role may give at sale time.
+// Roles without a limit are not restricted.
+type DiscountLimit struct {
+	TenantID           uuid.UUID
+	Role               string
+	MaxDiscountPercent float64
+
+	// Metadata
+	UpdatedAt time.Time
+}
+
+// Allows reports whether a discount is within the limit
+func (l *DiscountLimit) Allows(discountPercent float64) bool {
+	return discountPercent <= l.MaxDiscountPercent+1e-9
+}
+
+// DiscountOverride is a one-time approval by a higher role to sell below the seller's limit
+type DiscountOverride struct {
+	ID              uuid.UUID
+	TenantID        uuid.UUID
+	ProductID       uuid.UUID
+	ListPrice       float64
+	SalePrice       float64
+	DiscountPercent float64
+	Reason          string
+	RequestedBy     *uuid.UUID // The seller who needed the override
+	ApprovedBy      *uuid.UUID
+	ApproverRole    string
+	ExpiresAt       time.Time
+	UsedAt          *time.Time
+
+	// Metadata
+	CreatedAt time.Time
+}
+
+// NewDiscountOverride creates an override approval valid for DiscountOverrideTTL
+func NewDiscountOverride(tenantID uuid.UUID, product *Product, salePrice float64, reason string, requestedBy, approvedBy *uuid.UUID, approverRole string) *DiscountOverride {
+	now := time.Now()
+	return &DiscountOverride{
+		ID:              uuid.New(),
+		TenantID:        tenantID,
+		ProductID:       product.ID,
+		ListPrice:       product.SellingPrice,
+		SalePrice:       salePrice,
+		DiscountPercent: DiscountPercent(product.SellingPrice, salePrice),
+		Reason:          reason,
+		RequestedBy:     requestedBy,
+		ApprovedBy:      approvedBy,
+		ApproverRole:    approverRole,
+		ExpiresAt:       now.Add(DiscountOverrideTTL),
+		CreatedAt:       now,
+	}
+}
+
+// Covers reports whether the override can be redeemed for this product at this price
+func (o *DiscountOverride) Covers(productID uuid.UUID, salePrice float64, now time.Time) bool {
+	return o.UsedAt == nil && now.Before(o.ExpiresAt) && o.ProductID == productID &&
+		math.Round(salePrice*100) >= math.Round(o.SalePrice*100)
+}
+
+// SaleCheck is the outcome of validating a sale price against MRP and discount guardrails
+type SaleCheck struct {
+	ProductID          uuid.UUID
+	ListPrice          float64
+	SalePrice          float64
+	PriceInclTax       float64
+	MRP                *float64
+	DiscountPercent    float64
+	MaxDiscountPercent *float64 // nil when the role has no limit
+	OverrideID         *uuid.UUID
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidHSCode is returned when an HS code is not 4, 6 or 8 digits
+var ErrInvalidHSCode = errors.New("invalid HS code: expected 4, 6 or 8 digits")
+
+// HSCode represents an entry in the Harmonized System commodity code list
+type HSCode struct {
+	Code        string // Digits only, 4 (heading), 6 (subheading) or 8 (national tariff line)
+	Description string
+	Chapter     string // First 2 digits
+	Heading     string // First 4 digits
+}
+
+// HSChapterSummary aggregates a tenant's products by HS chapter for customs reporting
+type HSChapterSummary struct {
+	Chapter      string
+	ProductCount int64
+	StockValue   float64 // Sum of cost price, used as a declared-value reference
+}
+
+// NormalizeHSCode strips separators ("8471.30.00" -> "84713000") and validates the format
+func NormalizeHSCode(code string) (string, error) {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(code) {
+		switch {
+		case unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '.' || r == ' ' || r == '-':
+			continue
+		default:
+			return "", ErrInvalidHSCode
+		}
+	}
+
+	normalized := b.String()
+	switch len(normalized) {
+	case 4, 6, 8:
+		return normalized, nil
+	}
+	return "", ErrInvalidHSCode
+}
+
+// FormatHSCode renders a normalized code with the customs dot notation (8471.30.00)
+func FormatHSCode(code string) string {
+	switch len(code) {
+	case 6:
+		return code[:4] + "." + code[4:]
+	case 8:
+		return code[:4] + "." + code[4:6] + "." + code[6:]
+	}
+	return code
+}
@@ -0,0 +1,206 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidLocale is returned for a locale that is not a language code such as en or ne
+	ErrInvalidLocale = errors.New("invalid locale")
+	// ErrInvalidTranslation is returned for a translation without a name
+	ErrInvalidTranslation = errors.New("invalid translation")
+	// ErrTranslationNotFound is returned when an entity has no translation in a locale
+	ErrTranslationNotFound = errors.New("translation not found")
+	// ErrUnknownDocumentType is returned when setting the locale of a document the tenant cannot print
+	ErrUnknownDocumentType = errors.New("unknown document type")
+	// ErrDocumentLocaleNotFound is returned when a document type has no locale set
+	ErrDocumentLocaleNotFound = errors.New("document locale not found")
+)
+
+// maxPreferredLocales caps how many Accept-Language entries are looked up
+const maxPreferredLocales = 5
+
+// TranslationEntity is what a translation is of
+type TranslationEntity string
+
+const (
+	TranslationProduct  TranslationEntity = "product"
+	TranslationCategory TranslationEntity = "category"
+)
+
+// Translation is a product's or category's name and description in another
+// language. The entity's own fields stay in the catalog's language, and are what
+// reads fall back to when no translation matches.
+type Translation struct {
+	TenantID    uuid.UUID
+	EntityType  TranslationEntity
+	EntityID    uuid.UUID
+	Locale      string // Language code, e.g. ne
+	Name        string
+	Description *string // Nil keeps the entity's own description
+	UpdatedAt   time.Time
+}
+
+// NewTranslation creates a translation, normalizing the locale
+func NewTranslation(tenantID uuid.UUID, entityType TranslationEntity, entityID uuid.UUID, locale, name string, description *string) (*Translation, error) {
+	locale, err := NormalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidTranslation)
+	}
+	if len(name) > 255 {
+		return nil, fmt.Errorf("%w: name is longer than 255 characters", ErrInvalidTranslation)
+	}
+	return &Translation{
+		TenantID:    tenantID,
+		EntityType:  entityType,
+		EntityID:    entityID,
+		Locale:      locale,
+		Name:        name,
+		Description: description,
+		UpdatedAt:   time.Now(),
+	}, nil
+}
+
+// NormalizeLocale reduces a language tag to its lower-case language code, so
+// ne-NP and NE are both stored and matched as ne
+func NormalizeLocale(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.ToLower(tag)
+	if len(tag) < 2 || len(tag) > 3 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLocale, tag)
+	}
+	for _, r := range tag {
+		if r < 'a' || r > 'z' {
+			return "", fmt.Errorf("%w: %q", ErrInvalidLocale, tag)
+		}
+	}
+	return tag, nil
+}
+
+// PreferredLocales lists the languages a reader asked for, best first. An explicit
+// comma-separated list (the locale query parameter) wins over the Accept-Language
+// header. Unparseable entries, the * wildcard and q=0 are skipped.
+func PreferredLocales(explicit, acceptLanguage string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var entries []weighted
+	if strings.TrimSpace(explicit) != "" {
+		for _, tag := range strings.Split(explicit, ",") {
+			entries = append(entries, weighted{locale: tag, q: 1})
+		}
+	} else {
+		for _, part := range strings.Split(acceptLanguage, ",") {
+			tag, params, _ := strings.Cut(part, ";")
+			q := 1.0
+			if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				parsed, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					continue
+				}
+				q = parsed
+			}
+			if q > 0 {
+				entries = append(entries, weighted{locale: tag, q: q})
+			}
+		}
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+	}
+
+	var locales []string
+	for _, entry := range entries {
+		locale, err := NormalizeLocale(entry.locale)
+		if err != nil || contains(locales, locale) {
+			continue
+		}
+		locales = append(locales, locale)
+		if len(locales) == maxPreferredLocales {
+			break
+		}
+	}
+	return locales
+}
+
+// PickTranslation returns the translation in the most preferred locale, or nil
+func PickTranslation(translations []*Translation, preferred []string) *Translation {
+	for _, locale := range preferred {
+		for _, t := range translations {
+			if t.Locale == locale {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// Localize shows the product in a translation's language
+func (p *Product) Localize(t *Translation) {
+	p.Name = t.Name
+	if t.Description != nil {
+		p.Description = t.Description
+	}
+	p.Locale = t.Locale
+}
+
+// Localize shows the category in a translation's language
+func (c *Category) Localize(t *Translation) {
+	c.Name = t.Name
+	if t.Description != nil {
+		c.Description = t.Description
+	}
+	c.Locale = t.Locale
+}
+
+// Documents whose product names can be printed in another language
+const (
+	DocumentSalesInvoice  = "sales_invoice"
+	DocumentPurchaseOrder = "purchase_order"
+)
+
+// DocumentTypes are the documents a locale can be set for
+var DocumentTypes = []string{DocumentSalesInvoice, DocumentPurchaseOrder}
+
+// DocumentLocale is the language a tenant prints a document in. Product names on
+// the document are copied from the translation in that locale where there is one.
+type DocumentLocale struct {
+	TenantID     uuid.UUID
+	DocumentType string
+	Locale       string
+	UpdatedAt    time.Time
+}
+
+// NewDocumentLocale creates a document locale setting
+func NewDocumentLocale(tenantID uuid.UUID, documentType, locale string) (*DocumentLocale, error) {
+	if !contains(DocumentTypes, documentType) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDocumentType, documentType)
+	}
+	locale, err := NormalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	return &DocumentLocale{TenantID: tenantID, DocumentType: documentType, Locale: locale, UpdatedAt: time.Now()}, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
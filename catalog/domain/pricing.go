@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidMarkupRule is returned when a markup rule is malformed
+	ErrInvalidMarkupRule = errors.New("invalid markup rule")
+	// ErrMarkupRuleNotFound is returned when a markup rule does not exist for the tenant
+	ErrMarkupRuleNotFound = errors.New("markup rule not found")
+	// ErrPriceChangeNotFound is returned when a price change does not exist for the tenant
+	ErrPriceChangeNotFound = errors.New("price change not found")
+	// ErrPriceChangeNotPending is returned when approving or rejecting a reviewed change
+	ErrPriceChangeNotPending = errors.New("price change is not pending approval")
+)
+
+// MarkupRule sets a product's selling price to its cost plus a percentage.
+// A rule targets either a single product or a category (and its subcategories);
+// product rules win over category rules, and nearer categories over ancestors.
+type MarkupRule struct {
+	ID              uuid.UUID
+	TenantID        uuid.UUID
+	ProductID       *uuid.UUID
+	CategoryID      *uuid.UUID
+	MarkupPercent   float64 // Selling price = cost * (1 + MarkupPercent/100)
+	RoundTo         float64 // Round the price up to a multiple of this (e.g. 1, 5, 10); 0 = paisa
+	RequireApproval bool    // Queue recomputed prices for review instead of applying them
+	IsActive        bool
+
+	// Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewMarkupRule creates an active markup rule
+func NewMarkupRule(tenantID uuid.UUID, productID, categoryID *uuid.UUID, markupPercent, roundTo float64, requireApproval bool) *MarkupRule {
+	now := time.Now()
+	return &MarkupRule{
+		ID:              uuid.New(),
+		TenantID:        tenantID,
+		ProductID:       productID,
+		CategoryID:      categoryID,
+		MarkupPercent:   markupPercent,
+		RoundTo:         roundTo,
+		RequireApproval: requireApproval,
+		IsActive:        true,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
+
+// Validate checks that the rule targets exactly one product or category and has sane numbers
+func (r *MarkupRule) Validate() error {
+	if (r.ProductID == nil) == (r.CategoryID == nil) {
+		return errors.New("markup rule must target either a product or a category")
+	}
+	if r.MarkupPercent < 0 || r.MarkupPercent > 1000 {
+		return errors.New("markup percent must be between 0 and 1000")
+	}
+	if r.RoundTo < 0 {
+		return errors.New("rounding step cannot be negative")
+	}
+	return nil
+}
+
+// Apply returns the selling price for a cost under this rule
+func (r *MarkupRule) Apply(cost float64) float64 {
+	price := cost * (1 + r.MarkupPercent/100)
+	if r.RoundTo > 0 {
+		// Small epsilon so 100.0000001 from float error doesn't round up a whole step
+		return math.Ceil(price/r.RoundTo-1e-9) * r.RoundTo
+	}
+	return math.Round(price*100) / 100
+}
+
+// PriceChangeSource records what triggered a recomputed price
+type PriceChangeSource string
+
+const (
+	PriceChangeSourceCost      PriceChangeSource = "cost_change" // Product cost was updated
+	PriceChangeSourceScheduled PriceChangeSource = "scheduled"   // Nightly repricing job
+	PriceChangeSourceManual    PriceChangeSource = "manual"      // Repricing run started by a user
+)
+
+// PriceChangeStatus is the review state of a recomputed price
+type PriceChangeStatus string
+
+const (
+	PriceChangeStatusPending    PriceChangeStatus = "pending"
+	PriceChangeStatusApplied    PriceChangeStatus = "applied"
+	PriceChangeStatusRejected   PriceChangeStatus = "rejected"
+	PriceChangeStatusSuperseded PriceChangeStatus = "superseded" // A newer recomputation replaced it before review
+)
+
+// PriceChange is a selling price recomputed from a markup rule
+type PriceChange struct {
+	ID         uuid.UUID
+	TenantID   uuid.UUID
+	ProductID  uuid.UUID
+	RuleID     uuid.UUID
+	RunID      *uuid.UUID // Set when produced by a repricing run
+	Source     PriceChangeSource
+	Status     PriceChangeStatus
+	CostPrice  float64
+	OldPrice   float64
+	NewPrice   float64
+	ReviewedBy *uuid.UUID
+	ReviewedAt *time.Time
+
+	// Metadata
+	CreatedAt time.Time
+}
+
+// NewPriceChange proposes moving a product to the price given by its rule
+func NewPriceChange(product *Product, rule *MarkupRule, source PriceChangeSource) *PriceChange {
+	status := PriceChangeStatusApplied
+	if rule.RequireApproval {
+		status = PriceChangeStatusPending
+	}
+	return &PriceChange{
+		ID:        uuid.New(),
+		TenantID:  product.TenantID,
+		ProductID: product.ID,
+		RuleID:    rule.ID,
+		Source:    source,
+		Status:    status,
+		CostPrice: product.CostPrice,
+		OldPrice:  product.SellingPrice,
+		NewPrice:  rule.Apply(product.CostPrice),
+		CreatedAt: time.Now(),
+	}
+}
+
+// Review records the reviewer's decision on a pending change
+func (c *PriceChange) Review(status PriceChangeStatus, reviewedBy *uuid.UUID) error {
+	if c.Status != PriceChangeStatusPending {
+		return ErrPriceChangeNotPending
+	}
+	now := time.Now()
+	c.Status = status
+	c.ReviewedBy = reviewedBy
+	c.ReviewedAt = &now
+	return nil
+}
+
+// RepricingRun is one pass of recomputing prices for a tenant, kept as a change report
+type RepricingRun struct {
+	ID              uuid.UUID
+	TenantID        uuid.UUID
+	Source          PriceChangeSource
+	StartedBy       *uuid.UUID
+	ProductsChecked int
+	PricesApplied   int
+	PendingApproval int
+	StartedAt       time.Time
+	FinishedAt      *time.Time
+
+	// Changes are loaded on demand for the report
+	Changes []*PriceChange
+}
+
+// NewRepricingRun starts a repricing run
+func NewRepricingRun(tenantID uuid.UUID, source PriceChangeSource, startedBy *uuid.UUID) *RepricingRun {
+	return &RepricingRun{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Source:    source,
+		StartedBy: startedBy,
+		StartedAt: time.Now(),
+	}
+}
+
+// Record counts a change in the run totals
+func (r *RepricingRun) Record(change *PriceChange) {
+	switch change.Status {
+	case PriceChangeStatusApplied:
+		r.PricesApplied++
+	case PriceChangeStatusPending:
+		r.PendingApproval++
+	}
+	r.Changes = append(r.Changes, change)
+}
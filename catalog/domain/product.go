@@ -1,11 +1,41 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrProductNotFound is returned when a product does not exist for the tenant
+	ErrProductNotFound = errors.New("product not found")
+	// ErrProductLimitReached is returned when creating the products would take the tenant
+	// past their plan's product limit
+	ErrProductLimitReached = errors.New("plan product limit reached; upgrade your plan to add more")
+)
+
+// TopicProducts is the realtime topic product changes are published on, so the
+// POS picks up new items and prices without a refresh
+const TopicProducts = "products"
+
+// Product event types
+const (
+	EventProductCreated = "product.created"
+	EventProductUpdated = "product.updated"
+	EventProductDeleted = "product.deleted"
+)
+
+// ProductChange is the payload of a product event; clients refetch the product for the rest
+type ProductChange struct {
+	ID           uuid.UUID `json:"id"`
+	ProductCode  string    `json:"productCode"`
+	Name         string    `json:"name"`
+	SellingPrice float64   `json:"sellingPrice"`
+	Barcode      *string   `json:"barcode,omitempty"`
+	IsActive     bool      `json:"isActive"`
+}
+
 // ProductStatus represents the status of a product
 type ProductStatus string
 
@@ -27,13 +57,15 @@ type Product struct {
 	// Pricing
 	CostPrice    float64
 	SellingPrice float64
-	MRP          *float64 // Maximum Retail Price
-	TaxRate      float64
+	MRP          *float64   // Maximum Retail Price
+	TaxRate      float64    // Legacy flat rate, used when no tax group applies
+	TaxGroupID   *uuid.UUID // Overrides the category's tax group
 
 	// Inventory
 	SKU     *string
 	Barcode *string
-	Unit    string // pcs, kg, liter, box, etc.
+	HSCode  *string // Harmonized System commodity code, digits only
+	Unit    string  // pcs, kg, liter, box, etc.
 
 	// Status
 	Status   ProductStatus
@@ -42,6 +74,9 @@ type Product struct {
 	// Custom attributes stored as JSONB
 	CustomAttributes map[string]interface{}
 
+	// Locale is the language Name and Description were read in; empty for the catalog's own
+	Locale string
+
 	// Metadata
 	CreatedAt time.Time
 	UpdatedAt time.Time
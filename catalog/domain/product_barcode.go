@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidProductBarcode is returned when a product's pack barcodes are malformed
+	ErrInvalidProductBarcode = errors.New("invalid product barcode")
+	// ErrBarcodeInUse is returned when a barcode already belongs to another of the tenant's products
+	ErrBarcodeInUse = errors.New("barcode is already assigned to another product")
+)
+
+// maxBarcodeLength matches the products.barcode column
+const maxBarcodeLength = 100
+
+// ProductBarcode is a barcode printed on a pack of a product, e.g. the strip or
+// box of a medicine sold by the piece. Scanning it rings up Quantity base units.
+type ProductBarcode struct {
+	ID        uuid.UUID
+	TenantID  uuid.UUID
+	ProductID uuid.UUID
+	Barcode   string
+	Unit      string  // Pack unit code: strip, box
+	Quantity  float64 // Product base units per pack, e.g. 10 pcs per strip
+	CreatedAt time.Time
+}
+
+// NewProductBarcode creates a pack barcode for a product
+func NewProductBarcode(tenantID, productID uuid.UUID, barcode, unit string, quantity float64) ProductBarcode {
+	return ProductBarcode{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		ProductID: productID,
+		Barcode:   strings.TrimSpace(barcode),
+		Unit:      NormalizeUnitCode(unit),
+		Quantity:  quantity,
+		CreatedAt: time.Now(),
+	}
+}
+
+// ValidateProductBarcodes checks a product's pack barcodes: each set, positive and
+// distinct from the others and from the product's own barcode
+func ValidateProductBarcodes(own *string, barcodes []ProductBarcode) error {
+	seen := make(map[string]bool, len(barcodes)+1)
+	if own != nil && *own != "" {
+		seen[*own] = true
+	}
+
+	for _, barcode := range barcodes {
+		if barcode.Barcode == "" {
+			return errors.New("barcode is required")
+		}
+		if len(barcode.Barcode) > maxBarcodeLength {
+			return errors.New("barcode must be at most 100 characters")
+		}
+		if seen[barcode.Barcode] {
+			return errors.New("barcode " + barcode.Barcode + " appears more than once on the product")
+		}
+		seen[barcode.Barcode] = true
+
+		if barcode.Unit == "" {
+			return errors.New("pack unit is required")
+		}
+		if barcode.Quantity <= 0 {
+			return errors.New("pack quantity must be positive")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxRecentProducts is how many recently viewed products are kept per user
+const MaxRecentProducts = 50
+
+// ProductQuickPick is a product on a user's POS quick-pick grid:
+// either pinned as a favorite or recently viewed
+type ProductQuickPick struct {
+	Product      *Product
+	UserID       uuid.UUID
+	IsFavorite   bool
+	Position     int // Favorite ordering, lower first
+	ViewCount    int
+	LastViewedAt *time.Time
+}
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidReservation is returned when a reservation request is malformed
+	ErrInvalidReservation = errors.New("invalid stock reservation")
+	// ErrReservationNotFound is returned when a reservation does not exist for the tenant
+	ErrReservationNotFound = errors.New("stock reservation not found")
+	// ErrReservationClosed is returned when releasing or consuming a reservation that is no longer active
+	ErrReservationClosed = errors.New("stock reservation is no longer active")
+	// ErrInsufficientStock is returned when a reservation needs more than is available
+	ErrInsufficientStock = errors.New("not enough stock available")
+)
+
+// DefaultReservationTTL is how long a reservation holds stock when no expiry is given
+const DefaultReservationTTL = 48 * time.Hour
+
+// ReservationReference is the kind of document stock is reserved for
+type ReservationReference string
+
+const (
+	ReservationForOrder ReservationReference = "order"
+	ReservationForQuote ReservationReference = "quote"
+)
+
+// Valid reports whether the reference type is known
+func (r ReservationReference) Valid() bool {
+	return r == ReservationForOrder || r == ReservationForQuote
+}
+
+// ReservationStatus is where a reservation is in its life
+type ReservationStatus string
+
+const (
+	ReservationActive   ReservationStatus = "active"   // Holding stock
+	ReservationReleased ReservationStatus = "released" // Let go, e.g. the order was cancelled
+	ReservationConsumed ReservationStatus = "consumed" // The order was fulfilled from the held stock
+	ReservationExpired  ReservationStatus = "expired"  // Not released or consumed before ExpiresAt
+)
+
+// StockReservation holds a quantity of a product for an order or quote so POS
+// cannot sell it to someone else. Active reservations past ExpiresAt hold nothing,
+// whether or not the expiry job has marked them yet.
+type StockReservation struct {
+	ID              uuid.UUID
+	TenantID        uuid.UUID
+	ProductID       uuid.UUID
+	Quantity        float64
+	ReferenceType   ReservationReference
+	ReferenceID     uuid.UUID
+	ReferenceNumber *string // e.g. the order number, for reports
+	Status          ReservationStatus
+	ExpiresAt       time.Time
+	Note            *string
+	CreatedBy       *uuid.UUID
+	CreatedAt       time.Time
+	ClosedAt        *time.Time
+	CloseReason     *string
+}
+
+// NewStockReservation creates an active reservation that expires at expiresAt
+func NewStockReservation(tenantID, productID uuid.UUID, quantity float64, referenceType ReservationReference, referenceID uuid.UUID, expiresAt time.Time) *StockReservation {
+	return &StockReservation{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		ProductID:     productID,
+		Quantity:      quantity,
+		ReferenceType: referenceType,
+		ReferenceID:   referenceID,
+		Status:        ReservationActive,
+		ExpiresAt:     expiresAt,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// Validate checks the quantity, reference and expiry
+func (r *StockReservation) Validate() error {
+	if r.Quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+	if !r.ReferenceType.Valid() {
+		return errors.New("reference type must be order or quote")
+	}
+	if r.ReferenceID == uuid.Nil {
+		return errors.New("reference ID is required")
+	}
+	if !r.ExpiresAt.After(r.CreatedAt) {
+		return errors.New("expiry must be in the future")
+	}
+	return nil
+}
+
+// Holding reports whether the reservation still holds stock at now
+func (r *StockReservation) Holding(now time.Time) bool {
+	return r.Status == ReservationActive && now.Before(r.ExpiresAt)
+}
+
+// ReservationFilter narrows a reservation listing
+type ReservationFilter struct {
+	Status        ReservationStatus // Empty for any
+	ProductID     *uuid.UUID
+	ReferenceType ReservationReference // Empty for any
+	ReferenceID   *uuid.UUID
+	Limit         int
+	Offset        int
+}
+
+// StockAvailability is what of a product can still be sold
+type StockAvailability struct {
+	ProductID uuid.UUID
+	OnHand    float64
+	Reserved  float64
+	Available float64 // OnHand minus Reserved, never below zero
+}
+
+// NewStockAvailability computes availability from on-hand and reserved quantities
+func NewStockAvailability(productID uuid.UUID, onHand, reserved float64) StockAvailability {
+	available := roundQuantity(onHand - reserved)
+	if available < 0 {
+		available = 0
+	}
+	return StockAvailability{ProductID: productID, OnHand: onHand, Reserved: reserved, Available: available}
+}
+
+// ReservationSummary is one product's line of the reservations report
+type ReservationSummary struct {
+	ProductID    uuid.UUID
+	ProductCode  string
+	ProductName  string
+	Reservations int
+	StockAvailability
+	NextExpiry time.Time
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidBarcodeRule is returned when a scale barcode rule is malformed
+	ErrInvalidBarcodeRule = errors.New("invalid barcode rule")
+	// ErrBarcodeRuleNotFound is returned when a barcode rule does not exist for the tenant
+	ErrBarcodeRuleNotFound = errors.New("barcode rule not found")
+	// ErrBadCheckDigit is returned when a scale barcode fails its check digit
+	ErrBadCheckDigit = errors.New("barcode check digit mismatch")
+)
+
+// ScaleValueType is what the value digits of a scale barcode encode
+type ScaleValueType string
+
+const (
+	ScaleValueWeight ScaleValueType = "weight" // Net weight in the product's unit (usually kg)
+	ScaleValuePrice  ScaleValueType = "price"  // Line total; quantity is derived from the selling price
+)
+
+// maxScaleBarcodeLength is the longest barcode a rule may describe (EAN-13)
+const maxScaleBarcodeLength = 13
+
+// BarcodeRule describes a tenant's in-store (scale label) barcode layout:
+//
+//	[prefix][item code][value][check digit]
+//
+// e.g. the common EAN-13 layout 2 + 5 + 5 + 1 = "21" "01234" "01250" "C"
+// encodes item 01234 weighing 1.250 kg when Decimals is 3.
+// The item code is matched against the product SKU, then the product code.
+type BarcodeRule struct {
+	ID             uuid.UUID
+	TenantID       uuid.UUID
+	Name           string
+	Prefix         string // Usually 20-29, the GS1 range reserved for in-store use
+	ItemCodeDigits int
+	ValueDigits    int
+	ValueType      ScaleValueType
+	Decimals       int // Implied decimal places of the value (3 for grams as kg, 2 for paisa)
+	IsActive       bool
+
+	// Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewBarcodeRule creates an active scale barcode rule
+func NewBarcodeRule(tenantID uuid.UUID, name, prefix string, itemCodeDigits, valueDigits int, valueType ScaleValueType, decimals int) *BarcodeRule {
+	now := time.Now()
+	return &BarcodeRule{
+		ID:             uuid.New(),
+		TenantID:       tenantID,
+		Name:           name,
+		Prefix:         strings.TrimSpace(prefix),
+		ItemCodeDigits: itemCodeDigits,
+		ValueDigits:    valueDigits,
+		ValueType:      valueType,
+		Decimals:       decimals,
+		IsActive:       true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Length returns the total barcode length the rule describes, including the check digit
+func (r *BarcodeRule) Length() int {
+	return len(r.Prefix) + r.ItemCodeDigits + r.ValueDigits + 1
+}
+
+// Validate checks that the layout is well formed and fits an EAN-13
+func (r *BarcodeRule) Validate() error {
+	if r.Prefix == "" || len(r.Prefix) > 3 || !isDigits(r.Prefix) {
+		return errors.New("prefix must be 1 to 3 digits")
+	}
+	if r.ItemCodeDigits < 1 || r.ValueDigits < 1 {
+		return errors.New("item code and value must each have at least one digit")
+	}
+	if r.Length() > maxScaleBarcodeLength {
+		return fmt.Errorf("layout is %d digits, longer than %d", r.Length(), maxScaleBarcodeLength)
+	}
+	if r.ValueType != ScaleValueWeight && r.ValueType != ScaleValuePrice {
+		return errors.New("value type must be weight or price")
+	}
+	if r.Decimals < 0 || r.Decimals > r.ValueDigits {
+		return errors.New("decimals must be between 0 and the number of value digits")
+	}
+	return nil
+}
+
+// Matches reports whether a scanned barcode has this rule's prefix and length
+func (r *BarcodeRule) Matches(barcode string) bool {
+	return r.IsActive && len(barcode) == r.Length() && strings.HasPrefix(barcode, r.Prefix) && isDigits(barcode)
+}
+
+// Parse splits a matching barcode into its item code and embedded value
+func (r *BarcodeRule) Parse(barcode string) (*ScaleBarcode, error) {
+	if !r.Matches(barcode) {
+		return nil, fmt.Errorf("barcode does not match rule %s", r.Name)
+	}
+	if !ValidCheckDigit(barcode) {
+		return nil, ErrBadCheckDigit
+	}
+
+	start := len(r.Prefix)
+	itemCode := barcode[start : start+r.ItemCodeDigits]
+	digits := barcode[start+r.ItemCodeDigits : start+r.ItemCodeDigits+r.ValueDigits]
+
+	raw, err := strconv.Atoi(digits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value digits %q: %w", digits, err)
+	}
+
+	return &ScaleBarcode{
+		Barcode:   barcode,
+		RuleID:    r.ID,
+		ItemCode:  itemCode,
+		ValueType: r.ValueType,
+		Value:     float64(raw) / math.Pow(10, float64(r.Decimals)),
+	}, nil
+}
+
+// ValidCheckDigit verifies the GS1 mod-10 check digit in the last position
+func ValidCheckDigit(barcode string) bool {
+	if len(barcode) < 2 || !isDigits(barcode) {
+		return false
+	}
+
+	sum := 0
+	body := barcode[:len(barcode)-1]
+	for i := len(body) - 1; i >= 0; i-- {
+		digit := int(body[i] - '0')
+		// Weights alternate 3,1 from the digit nearest the check digit
+		if (len(body)-1-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	return (10-sum%10)%10 == int(barcode[len(barcode)-1]-'0')
+}
+
+// ScaleBarcode is a scanned scale label broken into its parts
+type ScaleBarcode struct {
+	Barcode   string
+	RuleID    uuid.UUID
+	ItemCode  string
+	ValueType ScaleValueType
+	Value     float64 // Weight or price, with the rule's decimals applied
+}
+
+// BarcodeLookup is a product resolved from a scan, with the quantity and amount to ring up
+type BarcodeLookup struct {
+	Product  *Product
+	Quantity float64
+	Amount   float64         // Line total before tax
+	Scale    *ScaleBarcode   // Set when resolved through a scale barcode rule
+	Pack     *ProductBarcode // Set when resolved through a pack barcode
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,269 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnknownTax is returned when a tax rate or tax group does not exist
+	ErrUnknownTax = errors.New("unknown tax rate or group")
+	// ErrNoEffectiveRate is returned when a tax rate has no version in effect on a date
+	ErrNoEffectiveRate = errors.New("no tax rate in effect on the given date")
+	// ErrSystemTaxReadOnly is returned when trying to modify a built-in tax
+	ErrSystemTaxReadOnly = errors.New("system taxes cannot be modified")
+)
+
+// TaxKind classifies a tax rate for VAT reporting
+type TaxKind string
+
+const (
+	TaxKindStandard  TaxKind = "standard"   // Taxable at a positive rate (e.g. VAT 13%)
+	TaxKindZeroRated TaxKind = "zero_rated" // Taxable at 0%, input credit allowed (exports)
+	TaxKindExempt    TaxKind = "exempt"     // Outside VAT, no input credit
+)
+
+// TaxRate represents a single levy in the tax registry.
+// The percentage itself is effective-dated through TaxRateVersion.
+type TaxRate struct {
+	ID       uuid.UUID
+	TenantID *uuid.UUID // nil for built-in system taxes
+	Code     string     // VAT13, EXEMPT, ZERO
+	Name     string
+	Kind     TaxKind
+	IsSystem bool
+	IsActive bool
+
+	// Versions ordered by EffectiveFrom, loaded on demand
+	Versions []*TaxRateVersion
+
+	// Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TaxRateVersion is the percentage of a tax rate from a given date onwards
+type TaxRateVersion struct {
+	ID            uuid.UUID
+	TaxRateID     uuid.UUID
+	Rate          float64 // Percentage (0-100)
+	EffectiveFrom time.Time
+	CreatedAt     time.Time
+}
+
+// TaxGroup bundles one or more tax rates into a composite levy
+// that is assigned to products and categories
+type TaxGroup struct {
+	ID          uuid.UUID
+	TenantID    *uuid.UUID // nil for built-in system groups
+	Code        string
+	Name        string
+	Description *string
+	IsSystem    bool
+	IsActive    bool
+	Components  []*TaxGroupComponent
+
+	// Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TaxGroupComponent is one tax rate within a group
+type TaxGroupComponent struct {
+	TaxRateID uuid.UUID
+	Sequence  int  // Application order
+	Compound  bool // Applied on the amount plus previously applied taxes
+}
+
+// EffectiveTaxComponent is a group component resolved to the rate in effect on a date
+type EffectiveTaxComponent struct {
+	TaxRateID uuid.UUID
+	Code      string
+	Name      string
+	Kind      TaxKind
+	Rate      float64
+	Sequence  int
+	Compound  bool
+}
+
+// TaxLine is the tax computed for one component
+type TaxLine struct {
+	TaxRateID     uuid.UUID
+	Code          string
+	Name          string
+	Kind          TaxKind
+	Rate          float64
+	TaxableAmount float64
+	TaxAmount     float64
+}
+
+// TaxBreakdown is the result of applying a tax group to an amount
+type TaxBreakdown struct {
+	TaxGroupID    *uuid.UUID // nil when the legacy flat rate was used
+	TaxableAmount float64
+	Lines         []TaxLine
+	TotalTax      float64
+	TotalAmount   float64
+}
+
+// NewTaxRate creates a new tenant-specific tax rate
+func NewTaxRate(tenantID uuid.UUID, code, name string, kind TaxKind) *TaxRate {
+	now := time.Now()
+	return &TaxRate{
+		ID:        uuid.New(),
+		TenantID:  &tenantID,
+		Code:      strings.ToUpper(strings.TrimSpace(code)),
+		Name:      name,
+		Kind:      kind,
+		IsSystem:  false,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewTaxRateVersion creates a new effective-dated rate
+func NewTaxRateVersion(taxRateID uuid.UUID, rate float64, effectiveFrom time.Time) *TaxRateVersion {
+	return &TaxRateVersion{
+		ID:            uuid.New(),
+		TaxRateID:     taxRateID,
+		Rate:          rate,
+		EffectiveFrom: truncateToDate(effectiveFrom),
+		CreatedAt:     time.Now(),
+	}
+}
+
+// NewTaxGroup creates a new tenant-specific tax group
+func NewTaxGroup(tenantID uuid.UUID, code, name string) *TaxGroup {
+	now := time.Now()
+	return &TaxGroup{
+		ID:         uuid.New(),
+		TenantID:   &tenantID,
+		Code:       strings.ToUpper(strings.TrimSpace(code)),
+		Name:       name,
+		IsSystem:   false,
+		IsActive:   true,
+		Components: []*TaxGroupComponent{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// IsValidTaxKind checks if a tax kind is supported
+func IsValidTaxKind(kind TaxKind) bool {
+	switch kind {
+	case TaxKindStandard, TaxKindZeroRated, TaxKindExempt:
+		return true
+	}
+	return false
+}
+
+// ValidateRate checks a percentage against the tax kind
+func (t *TaxRate) ValidateRate(rate float64) error {
+	if rate < 0 || rate > 100 {
+		return errors.New("tax rate must be between 0 and 100")
+	}
+	if t.Kind != TaxKindStandard && rate != 0 {
+		return errors.New("zero-rated and exempt taxes must have a 0% rate")
+	}
+	return nil
+}
+
+// RateOn returns the version in effect on the given date
+func (t *TaxRate) RateOn(date time.Time) (*TaxRateVersion, error) {
+	day := truncateToDate(date)
+
+	var effective *TaxRateVersion
+	for _, v := range t.Versions {
+		if v.EffectiveFrom.After(day) {
+			continue
+		}
+		if effective == nil || v.EffectiveFrom.After(effective.EffectiveFrom) {
+			effective = v
+		}
+	}
+
+	if effective == nil {
+		return nil, ErrNoEffectiveRate
+	}
+	return effective, nil
+}
+
+// IsEditableBy returns true if the tenant owns this tax rate
+func (t *TaxRate) IsEditableBy(tenantID uuid.UUID) bool {
+	return !t.IsSystem && t.TenantID != nil && *t.TenantID == tenantID
+}
+
+// IsEditableBy returns true if the tenant owns this tax group
+func (g *TaxGroup) IsEditableBy(tenantID uuid.UUID) bool {
+	return !g.IsSystem && g.TenantID != nil && *g.TenantID == tenantID
+}
+
+// ComputeTax applies resolved components to a tax-exclusive amount
+func ComputeTax(amount float64, components []*EffectiveTaxComponent) *TaxBreakdown {
+	sorted := make([]*EffectiveTaxComponent, len(components))
+	copy(sorted, components)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Sequence < sorted[j].Sequence })
+
+	breakdown := &TaxBreakdown{
+		TaxableAmount: amount,
+		Lines:         make([]TaxLine, 0, len(sorted)),
+	}
+
+	for _, c := range sorted {
+		base := amount
+		if c.Compound {
+			base = amount + breakdown.TotalTax
+		}
+
+		taxAmount := roundCurrency(base * c.Rate / 100)
+		breakdown.Lines = append(breakdown.Lines, TaxLine{
+			TaxRateID:     c.TaxRateID,
+			Code:          c.Code,
+			Name:          c.Name,
+			Kind:          c.Kind,
+			Rate:          c.Rate,
+			TaxableAmount: base,
+			TaxAmount:     taxAmount,
+		})
+		breakdown.TotalTax += taxAmount
+	}
+
+	breakdown.TotalTax = roundCurrency(breakdown.TotalTax)
+	breakdown.TotalAmount = roundCurrency(amount + breakdown.TotalTax)
+	return breakdown
+}
+
+// ComputeFlatTax applies the legacy per-product percentage
+func ComputeFlatTax(amount, rate float64) *TaxBreakdown {
+	breakdown := &TaxBreakdown{TaxableAmount: amount, Lines: []TaxLine{}}
+	if rate > 0 {
+		taxAmount := roundCurrency(amount * rate / 100)
+		breakdown.Lines = append(breakdown.Lines, TaxLine{
+			Code:          "FLAT",
+			Name:          "Product tax rate",
+			Kind:          TaxKindStandard,
+			Rate:          rate,
+			TaxableAmount: amount,
+			TaxAmount:     taxAmount,
+		})
+		breakdown.TotalTax = taxAmount
+	}
+	breakdown.TotalAmount = roundCurrency(amount + breakdown.TotalTax)
+	return breakdown
+}
+
+// roundCurrency rounds to 2 decimal places (paisa)
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// truncateToDate strips the time of day
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnknownUnit is returned when a unit code is not in the tenant's registry
+	ErrUnknownUnit = errors.New("unknown unit of measure")
+	// ErrSystemUnitReadOnly is returned when trying to modify a built-in unit
+	ErrSystemUnitReadOnly = errors.New("system units cannot be modified")
+)
+
+// UnitOfMeasure represents a unit in the UOM registry.
+// System units are shared by all tenants (TenantID is nil),
+// custom units belong to a single tenant.
+type UnitOfMeasure struct {
+	ID            uuid.UUID
+	TenantID      *uuid.UUID // nil for built-in system units
+	Code          string     // Canonical lowercase code: pcs, kg, ltr
+	Name          string     // Display name: Pieces, Kilogram
+	Symbol        string     // Printed symbol: pcs, kg, L
+	DecimalPlaces int        // Allowed quantity precision, 0 = integer-only
+	IsSystem      bool
+	IsActive      bool
+
+	// Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewUnitOfMeasure creates a new tenant-specific unit
+func NewUnitOfMeasure(tenantID uuid.UUID, code, name, symbol string, decimalPlaces int) *UnitOfMeasure {
+	now := time.Now()
+	return &UnitOfMeasure{
+		ID:            uuid.New(),
+		TenantID:      &tenantID,
+		Code:          NormalizeUnitCode(code),
+		Name:          name,
+		Symbol:        symbol,
+		DecimalPlaces: decimalPlaces,
+		IsSystem:      false,
+		IsActive:      true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// unitAliases maps common free-text spellings to canonical unit codes
+var unitAliases = map[string]string{
+	"pc":         "pcs",
+	"piece":      "pcs",
+	"pieces":     "pcs",
+	"nos":        "pcs",
+	"no":         "pcs",
+	"kgs":        "kg",
+	"kilo":       "kg",
+	"kilogram":   "kg",
+	"kilograms":  "kg",
+	"gm":         "g",
+	"gms":        "g",
+	"gram":       "g",
+	"grams":      "g",
+	"l":          "ltr",
+	"lt":         "ltr",
+	"liter":      "ltr",
+	"litre":      "ltr",
+	"liters":     "ltr",
+	"litres":     "ltr",
+	"millilitre": "ml",
+	"milliliter": "ml",
+	"mtr":        "m",
+	"meter":      "m",
+	"metre":      "m",
+	"boxes":      "box",
+	"packet":     "pack",
+	"packets":    "pack",
+	"pkt":        "pack",
+	"dz":         "dozen",
+	"doz":        "dozen",
+	"strips":     "strip",
+	"bottles":    "bottle",
+	"btl":        "bottle",
+	"sets":       "set",
+	"pairs":      "pair",
+}
+
+// NormalizeUnitCode converts a free-text unit ("Kg", " KGS ") to its canonical code ("kg")
+func NormalizeUnitCode(code string) string {
+	normalized := strings.ToLower(strings.TrimSpace(code))
+	if canonical, ok := unitAliases[normalized]; ok {
+		return canonical
+	}
+	return normalized
+}
+
+// ValidateQuantity checks that a quantity respects the unit's decimal precision
+func (u *UnitOfMeasure) ValidateQuantity(quantity float64) error {
+	if quantity < 0 {
+		return fmt.Errorf("quantity cannot be negative")
+	}
+
+	factor := math.Pow(10, float64(u.DecimalPlaces))
+	scaled := quantity * factor
+	if math.Abs(scaled-math.Round(scaled)) > 1e-9 {
+		if u.DecimalPlaces == 0 {
+			return fmt.Errorf("quantity for unit %s must be a whole number", u.Code)
+		}
+		return fmt.Errorf("quantity for unit %s allows at most %d decimal places", u.Code, u.DecimalPlaces)
+	}
+
+	return nil
+}
+
+// RoundQuantity rounds a quantity to the unit's decimal precision
+func (u *UnitOfMeasure) RoundQuantity(quantity float64) float64 {
+	factor := math.Pow(10, float64(u.DecimalPlaces))
+	return math.Round(quantity*factor) / factor
+}
+
+// IsEditableBy returns true if the tenant owns this unit
+func (u *UnitOfMeasure) IsEditableBy(tenantID uuid.UUID) bool {
+	return !u.IsSystem && u.TenantID != nil && *u.TenantID == tenantID
+}
@@ -0,0 +1,255 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidVariantMatrix is returned for attribute axes or a SKU pattern that do
+	// not make a usable set of variants
+	ErrInvalidVariantMatrix = errors.New("invalid variant matrix")
+	// ErrVariantNotFound is returned for a product that is not a variant of the given product
+	ErrVariantNotFound = errors.New("variant not found")
+	// ErrNestedVariant is returned when generating variants of a product that is itself a variant
+	ErrNestedVariant = errors.New("a variant cannot have variants of its own")
+)
+
+const (
+	// MaxVariantAxes is how many attributes, e.g. size and color, variants can differ by
+	MaxVariantAxes = 3
+	// MaxVariants is how many variants one product can have
+	MaxVariants = 100
+)
+
+// variantPlaceholder matches the {name} placeholders of a SKU pattern
+var variantPlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// VariantAxis is an attribute variants differ by, with the values it takes
+type VariantAxis struct {
+	Name   string         `json:"name"` // e.g. size; the SKU pattern's {size}
+	Values []VariantValue `json:"values"`
+}
+
+// VariantValue is one value of an axis
+type VariantValue struct {
+	Value      string  `json:"value"`                // e.g. Red
+	Code       string  `json:"code,omitempty"`       // In the SKU; defaults to the value upper-cased without spaces, e.g. RED
+	PriceDelta float64 `json:"priceDelta,omitempty"` // Added to the product's selling price and MRP
+}
+
+// VariantMatrix generates a product's variants: one per combination of its axes'
+// values. SKUPattern builds each variant's SKU from {sku}, the product's SKU or
+// code, and one {axis} placeholder per axis.
+type VariantMatrix struct {
+	Axes       []VariantAxis `json:"axes"`
+	SKUPattern string        `json:"skuPattern,omitempty"` // Defaults to {sku}-{axis1}-{axis2}...
+	Activate   bool          `json:"activate"`             // Sell new variants right away; otherwise they are created inactive
+}
+
+// Normalize trims names and values, fills in codes and the default SKU pattern, and
+// checks the matrix: at most MaxVariantAxes axes with distinct names, distinct
+// values, at most MaxVariants combinations, and a pattern naming every axis so no
+// two variants share a SKU
+func (m *VariantMatrix) Normalize() error {
+	if len(m.Axes) == 0 || len(m.Axes) > MaxVariantAxes {
+		return fmt.Errorf("%w: between 1 and %d axes", ErrInvalidVariantMatrix, MaxVariantAxes)
+	}
+
+	names := map[string]bool{}
+	combinations := 1
+	for i := range m.Axes {
+		axis := &m.Axes[i]
+		axis.Name = strings.ToLower(strings.TrimSpace(axis.Name))
+		if axis.Name == "" || axis.Name == "sku" || strings.ContainsAny(axis.Name, "{}") {
+			return fmt.Errorf("%w: axis %d needs a name other than sku", ErrInvalidVariantMatrix, i+1)
+		}
+		if names[axis.Name] {
+			return fmt.Errorf("%w: axis %q is given twice", ErrInvalidVariantMatrix, axis.Name)
+		}
+		names[axis.Name] = true
+
+		if len(axis.Values) == 0 {
+			return fmt.Errorf("%w: axis %q has no values", ErrInvalidVariantMatrix, axis.Name)
+		}
+		values := map[string]bool{}
+		for j := range axis.Values {
+			value := &axis.Values[j]
+			value.Value = strings.TrimSpace(value.Value)
+			if value.Value == "" {
+				return fmt.Errorf("%w: axis %q has an empty value", ErrInvalidVariantMatrix, axis.Name)
+			}
+			if values[strings.ToLower(value.Value)] {
+				return fmt.Errorf("%w: %q is given twice for %s", ErrInvalidVariantMatrix, value.Value, axis.Name)
+			}
+			values[strings.ToLower(value.Value)] = true
+
+			value.Code = strings.TrimSpace(value.Code)
+			if value.Code == "" {
+				value.Code = strings.ToUpper(strings.Join(strings.Fields(value.Value), ""))
+			}
+			if math.IsNaN(value.PriceDelta) || math.IsInf(value.PriceDelta, 0) {
+				return fmt.Errorf("%w: invalid price delta for %s %s", ErrInvalidVariantMatrix, axis.Name, value.Value)
+			}
+		}
+
+		combinations *= len(axis.Values)
+		if combinations > MaxVariants {
+			return fmt.Errorf("%w: at most %d variants", ErrInvalidVariantMatrix, MaxVariants)
+		}
+	}
+
+	m.SKUPattern = strings.TrimSpace(m.SKUPattern)
+	if m.SKUPattern == "" {
+		placeholders := []string{"{sku}"}
+		for _, axis := range m.Axes {
+			placeholders = append(placeholders, "{"+axis.Name+"}")
+		}
+		m.SKUPattern = strings.Join(placeholders, "-")
+	}
+	used := map[string]bool{}
+	for _, match := range variantPlaceholder.FindAllStringSubmatch(m.SKUPattern, -1) {
+		name := strings.ToLower(match[1])
+		if name != "sku" && !names[name] {
+			return fmt.Errorf("%w: the SKU pattern has unknown placeholder {%s}", ErrInvalidVariantMatrix, match[1])
+		}
+		used[name] = true
+	}
+	for _, axis := range m.Axes {
+		if !used[axis.Name] {
+			return fmt.Errorf("%w: the SKU pattern needs {%s}", ErrInvalidVariantMatrix, axis.Name)
+		}
+	}
+	return nil
+}
+
+// Combinations lists every combination of the axes' values, the first axis
+// varying slowest. Call Normalize first.
+func (m *VariantMatrix) Combinations() []VariantCombination {
+	combinations := []VariantCombination{{}}
+	for _, axis := range m.Axes {
+		next := make([]VariantCombination, 0, len(combinations)*len(axis.Values))
+		for _, combination := range combinations {
+			for _, value := range axis.Values {
+				extended := VariantCombination{
+					Attributes: append(append([]VariantAttribute{}, combination.Attributes...), VariantAttribute{
+						Name: axis.Name, Value: value.Value, Code: value.Code,
+					}),
+					PriceDelta: combination.PriceDelta + value.PriceDelta,
+				}
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// VariantAttribute is an axis's value in one variant
+type VariantAttribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Code  string `json:"-"`
+}
+
+// VariantCombination is one variant a matrix generates
+type VariantCombination struct {
+	Attributes []VariantAttribute
+	PriceDelta float64
+}
+
+// Key identifies the combination whatever the order or case of its axes, so
+// generating again skips variants the product already has
+func (c VariantCombination) Key() string {
+	return VariantKey(c.Values())
+}
+
+// Values maps each axis to its value
+func (c VariantCombination) Values() map[string]string {
+	values := make(map[string]string, len(c.Attributes))
+	for _, attribute := range c.Attributes {
+		values[attribute.Name] = attribute.Value
+	}
+	return values
+}
+
+// SKU fills the pattern with the product's SKU and the combination's codes
+func (c VariantCombination) SKU(pattern, productSKU string) string {
+	return variantPlaceholder.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		name := strings.ToLower(placeholder[1 : len(placeholder)-1])
+		if name == "sku" {
+			return productSKU
+		}
+		for _, attribute := range c.Attributes {
+			if attribute.Name == name {
+				return attribute.Code
+			}
+		}
+		return placeholder
+	})
+}
+
+// Name is the product's name followed by the combination's values, e.g. "T-Shirt - M / Red"
+func (c VariantCombination) Name(productName string) string {
+	values := make([]string, len(c.Attributes))
+	for i, attribute := range c.Attributes {
+		values[i] = attribute.Value
+	}
+	return productName + " - " + strings.Join(values, " / ")
+}
+
+// VariantKey is the key of a variant's attribute values: name=value pairs in name
+// order, lower case
+func VariantKey(values map[string]string) string {
+	pairs := make([]string, 0, len(values))
+	for name, value := range values {
+		pairs = append(pairs, strings.ToLower(name)+"="+strings.ToLower(value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "|")
+}
+
+// ProductVariant links a product to the product it is a variant of. A variant is a
+// product of its own, with its own code, SKU, price and stock.
+type ProductVariant struct {
+	TenantID   uuid.UUID
+	ProductID  uuid.UUID
+	ParentID   uuid.UUID
+	Attributes map[string]string // Axis name to value, e.g. size: M
+	PriceDelta float64           // Added to the parent's price when generated
+	CreatedAt  time.Time
+
+	// Product is the variant's product when read with it
+	Product *Product
+}
+
+// NewProductVariant links a generated variant product to its parent
+func NewProductVariant(parent *Product, product *Product, combination VariantCombination) *ProductVariant {
+	return &ProductVariant{
+		TenantID:   parent.TenantID,
+		ProductID:  product.ID,
+		ParentID:   parent.ID,
+		Attributes: combination.Values(),
+		PriceDelta: combination.PriceDelta,
+		CreatedAt:  time.Now(),
+		Product:    product,
+	}
+}
+
+// Key identifies the variant's combination of values
+func (v *ProductVariant) Key() string {
+	return VariantKey(v.Attributes)
+}
+
+// VariantGeneration is what generating a matrix did
+type VariantGeneration struct {
+	Created []*ProductVariant
+	Skipped int // Combinations the product already had a variant for
+}
@@ -3,8 +3,14 @@ module github.com/aceextension/catalog
 go 1.24.0
 
 require (
+	github.com/aceextension/audit v0.0.0
+	github.com/aceextension/automation v0.0.0
+	github.com/aceextension/comments v0.0.0
 	github.com/aceextension/core v0.0.0
 	github.com/aceextension/fiscal v0.0.0
+	github.com/aceextension/notification v0.0.0
+	github.com/aceextension/onboarding v0.0.0
+	github.com/aceextension/tags v0.0.0
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v5 v5.8.0
 	github.com/labstack/echo/v4 v4.15.0
@@ -28,6 +34,11 @@ require (
 
 replace (
 	github.com/aceextension/audit => ../audit
+	github.com/aceextension/automation => ../automation
+	github.com/aceextension/comments => ../comments
 	github.com/aceextension/core => ../core
 	github.com/aceextension/fiscal => ../fiscal
+	github.com/aceextension/notification => ../notification
+	github.com/aceextension/onboarding => ../onboarding
+	github.com/aceextension/tags => ../tags
 )
@@ -0,0 +1,406 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// AlertRuleHandler handles low-stock and expiry alert rule HTTP requests
+type AlertRuleHandler struct {
+	service service.AlertRuleService
+}
+
+// NewAlertRuleHandler creates a new alert rule handler
+func NewAlertRuleHandler(service service.AlertRuleService) *AlertRuleHandler {
+	return &AlertRuleHandler{service: service}
+}
+
+// AlertRuleRequest represents the request to create or update an alert rule
+type AlertRuleRequest struct {
+	Name       string   `json:"name" validate:"required,max=100"`
+	Condition  string   `json:"condition" validate:"required,oneof=low_stock expiring"`
+	Threshold  float64  `json:"threshold" validate:"gt=0"`
+	CategoryID *string  `json:"categoryId,omitempty" validate:"omitempty,uuid"`
+	ProductID  *string  `json:"productId,omitempty" validate:"omitempty,uuid"`
+	Recipients []string `json:"recipients" validate:"required,min=1"`
+	Channel    string   `json:"channel" validate:"required,oneof=EMAIL SMS WHATSAPP"`
+	IsActive   *bool    `json:"isActive,omitempty"`
+}
+
+// AlertRuleResponse represents an alert rule
+type AlertRuleResponse struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Condition       string   `json:"condition"`
+	Threshold       float64  `json:"threshold"`
+	CategoryID      *string  `json:"categoryId,omitempty"`
+	ProductID       *string  `json:"productId,omitempty"`
+	Recipients      []string `json:"recipients"`
+	Channel         string   `json:"channel"`
+	IsActive        bool     `json:"isActive"`
+	LastEvaluatedAt *string  `json:"lastEvaluatedAt,omitempty"`
+	UpdatedAt       string   `json:"updatedAt"`
+}
+
+// StockAlertResponse represents a product tripping an alert rule
+type StockAlertResponse struct {
+	ID            string  `json:"id"`
+	RuleID        string  `json:"ruleId"`
+	ProductID     string  `json:"productId"`
+	ProductCode   string  `json:"productCode"`
+	ProductName   string  `json:"productName"`
+	Severity      string  `json:"severity"`
+	Detail        string  `json:"detail"`
+	RaisedAt      string  `json:"raisedAt"`
+	NotifiedAt    *string `json:"notifiedAt,omitempty"`
+	NotifiedCount int     `json:"notifiedCount"`
+	ResolvedAt    *string `json:"resolvedAt,omitempty"`
+}
+
+// AlertRunResponse represents the outcome of evaluating the rules
+type AlertRunResponse struct {
+	Rules     int `json:"rules"`
+	Raised    int `json:"raised"`
+	Escalated int `json:"escalated"`
+	Resolved  int `json:"resolved"`
+	Notified  int `json:"notified"`
+}
+
+// @Summary Create an alert rule
+// @Description Notify recipients when products in scope run low (threshold in units) or near expiry (threshold in days).
+// @Description Scope is one product, a category with its subcategories, or every product.
+// @Tags alert-rules
+// @Accept json
+// @Produce json
+// @Param rule body AlertRuleRequest true "Alert rule"
+// @Success 201 {object} AlertRuleResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/alert-rules [post]
+// @Security BearerAuth
+func (h *AlertRuleHandler) Create(c echo.Context) error {
+	var req AlertRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rule := domain.NewAlertRule(tenantID, req.Name, domain.AlertCondition(req.Condition), req.Threshold, req.Recipients, domain.AlertChannel(req.Channel))
+	if err := applyAlertRuleScope(rule, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := h.service.CreateRule(c.Request().Context(), rule); err != nil {
+		return alertRuleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toAlertRuleResponse(rule))
+}
+
+// @Summary List alert rules
+// @Tags alert-rules
+// @Produce json
+// @Success 200 {array} AlertRuleResponse
+// @Router /api/v1/alert-rules [get]
+// @Security BearerAuth
+func (h *AlertRuleHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rules, err := h.service.ListRules(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]AlertRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = toAlertRuleResponse(rule)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Get an alert rule
+// @Tags alert-rules
+// @Produce json
+// @Param id path string true "Alert rule ID"
+// @Success 200 {object} AlertRuleResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/alert-rules/{id} [get]
+// @Security BearerAuth
+func (h *AlertRuleHandler) Get(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid alert rule ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rule, err := h.service.GetRule(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return alertRuleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toAlertRuleResponse(rule))
+}
+
+// @Summary Update an alert rule
+// @Tags alert-rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Alert rule ID"
+// @Param rule body AlertRuleRequest true "Alert rule"
+// @Success 200 {object} AlertRuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/alert-rules/{id} [put]
+// @Security BearerAuth
+func (h *AlertRuleHandler) Update(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid alert rule ID"})
+	}
+
+	var req AlertRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rule, err := h.service.GetRule(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return alertRuleError(c, err)
+	}
+
+	updated := domain.NewAlertRule(tenantID, req.Name, domain.AlertCondition(req.Condition), req.Threshold, req.Recipients, domain.AlertChannel(req.Channel))
+	rule.Name = updated.Name
+	rule.Condition = updated.Condition
+	rule.Threshold = updated.Threshold
+	rule.Recipients = updated.Recipients
+	rule.Channel = updated.Channel
+	rule.CategoryID, rule.ProductID = nil, nil
+	if err := applyAlertRuleScope(rule, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := h.service.UpdateRule(c.Request().Context(), rule); err != nil {
+		return alertRuleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toAlertRuleResponse(rule))
+}
+
+// @Summary Delete an alert rule
+// @Description Delete a rule with its alert history
+// @Tags alert-rules
+// @Param id path string true "Alert rule ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/alert-rules/{id} [delete]
+// @Security BearerAuth
+func (h *AlertRuleHandler) Delete(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid alert rule ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.DeleteRule(c.Request().Context(), tenantID, id); err != nil {
+		return alertRuleError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary List stock alerts
+// @Description Products that tripped a rule, newest first
+// @Tags alert-rules
+// @Produce json
+// @Param ruleId query string false "Only this rule"
+// @Param open query bool false "Only unresolved alerts"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} StockAlertResponse
+// @Router /api/v1/alert-rules/alerts [get]
+// @Security BearerAuth
+func (h *AlertRuleHandler) ListAlerts(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	filter := domain.AlertFilter{OpenOnly: c.QueryParam("open") == "true"}
+	if raw := c.QueryParam("ruleId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid alert rule ID"})
+		}
+		filter.RuleID = &id
+	}
+
+	filter.Limit, _ = strconv.Atoi(c.QueryParam("limit"))
+	if filter.Limit == 0 {
+		filter.Limit = 50
+	}
+	filter.Offset, _ = strconv.Atoi(c.QueryParam("offset"))
+
+	alerts, err := h.service.ListAlerts(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]StockAlertResponse, len(alerts))
+	for i, alert := range alerts {
+		responses[i] = toStockAlertResponse(alert)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Evaluate alert rules now
+// @Description Run the tenant's active rules instead of waiting for the daily job. Products already notified
+// @Description at the same severity are not notified again.
+// @Tags alert-rules
+// @Produce json
+// @Success 200 {object} AlertRunResponse
+// @Router /api/v1/alert-rules/evaluate [post]
+// @Security BearerAuth
+func (h *AlertRuleHandler) Evaluate(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	run, err := h.service.Evaluate(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, AlertRunResponse{
+		Rules:     run.Rules,
+		Raised:    run.Raised,
+		Escalated: run.Escalated,
+		Resolved:  run.Resolved,
+		Notified:  run.Notified,
+	})
+}
+
+// applyAlertRuleScope sets the rule's product or category from the request
+func applyAlertRuleScope(rule *domain.AlertRule, req *AlertRuleRequest) error {
+	if req.CategoryID != nil {
+		id, err := uuid.Parse(*req.CategoryID)
+		if err != nil {
+			return errors.New("Invalid category ID")
+		}
+		rule.CategoryID = &id
+	}
+	if req.ProductID != nil {
+		id, err := uuid.Parse(*req.ProductID)
+		if err != nil {
+			return errors.New("Invalid product ID")
+		}
+		rule.ProductID = &id
+	}
+	return nil
+}
+
+// alertRuleError maps alert rule errors to HTTP statuses
+func alertRuleError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrAlertRuleNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidAlertRule):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// toAlertRuleResponse converts domain.AlertRule to AlertRuleResponse
+func toAlertRuleResponse(rule *domain.AlertRule) AlertRuleResponse {
+	resp := AlertRuleResponse{
+		ID:         rule.ID.String(),
+		Name:       rule.Name,
+		Condition:  string(rule.Condition),
+		Threshold:  rule.Threshold,
+		Recipients: rule.Recipients,
+		Channel:    string(rule.Channel),
+		IsActive:   rule.IsActive,
+		UpdatedAt:  rule.UpdatedAt.Format(time.RFC3339),
+	}
+	if rule.CategoryID != nil {
+		id := rule.CategoryID.String()
+		resp.CategoryID = &id
+	}
+	if rule.ProductID != nil {
+		id := rule.ProductID.String()
+		resp.ProductID = &id
+	}
+	if rule.LastEvaluatedAt != nil {
+		evaluatedAt := rule.LastEvaluatedAt.Format(time.RFC3339)
+		resp.LastEvaluatedAt = &evaluatedAt
+	}
+	return resp
+}
+
+// toStockAlertResponse converts domain.StockAlert to StockAlertResponse
+func toStockAlertResponse(alert *domain.StockAlert) StockAlertResponse {
+	resp := StockAlertResponse{
+		ID:            alert.ID.String(),
+		RuleID:        alert.RuleID.String(),
+		ProductID:     alert.ProductID.String(),
+		ProductCode:   alert.ProductCode,
+		ProductName:   alert.ProductName,
+		Severity:      string(alert.Severity),
+		Detail:        alert.Detail,
+		RaisedAt:      alert.RaisedAt.Format(time.RFC3339),
+		NotifiedCount: alert.NotifiedCount,
+	}
+	if alert.NotifiedAt != nil {
+		notifiedAt := alert.NotifiedAt.Format(time.RFC3339)
+		resp.NotifiedAt = &notifiedAt
+	}
+	if alert.ResolvedAt != nil {
+		resolvedAt := alert.ResolvedAt.Format(time.RFC3339)
+		resp.ResolvedAt = &resolvedAt
+	}
+	return resp
+}
@@ -0,0 +1,495 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// AssemblyHandler handles bill of materials and assembly order HTTP requests
+type AssemblyHandler struct {
+	service service.AssemblyService
+}
+
+// NewAssemblyHandler creates a new assembly handler
+func NewAssemblyHandler(service service.AssemblyService) *AssemblyHandler {
+	return &AssemblyHandler{service: service}
+}
+
+// BOMComponentRequest represents a component line of a bill of materials
+type BOMComponentRequest struct {
+	ComponentID  string  `json:"componentId" validate:"required,uuid"`
+	Quantity     float64 `json:"quantity" validate:"gt=0"`
+	WastePercent float64 `json:"wastePercent" validate:"gte=0,lt=100"`
+}
+
+// SaveBOMRequest represents the request to set a product's bill of materials
+type SaveBOMRequest struct {
+	Components []BOMComponentRequest `json:"components" validate:"required,min=1,dive"`
+}
+
+// CreateAssemblyOrderRequest represents the request to plan an assembly order
+type CreateAssemblyOrderRequest struct {
+	ProductID string  `json:"productId" validate:"required,uuid"`
+	Quantity  float64 `json:"quantity" validate:"gt=0"`
+	Note      *string `json:"note,omitempty"`
+}
+
+// CompleteAssemblyOrderRequest represents the actual output and conversion cost of an order
+type CompleteAssemblyOrderRequest struct {
+	ProducedQty  float64 `json:"producedQty" validate:"gt=0"`
+	OverheadCost float64 `json:"overheadCost" validate:"gte=0"` // Labour, power and other conversion costs
+}
+
+// BOMComponentResponse represents a component line of a bill of materials
+type BOMComponentResponse struct {
+	ComponentID  string  `json:"componentId"`
+	Quantity     float64 `json:"quantity"`
+	WastePercent float64 `json:"wastePercent"`
+}
+
+// BOMResponse represents a product's bill of materials
+type BOMResponse struct {
+	ProductID  string                 `json:"productId"`
+	Components []BOMComponentResponse `json:"components"`
+	UpdatedAt  string                 `json:"updatedAt"`
+}
+
+// AssemblyComponentResponse represents a component consumed by an assembly order
+type AssemblyComponentResponse struct {
+	ComponentID string  `json:"componentId"`
+	Quantity    float64 `json:"quantity"`
+	UnitCost    float64 `json:"unitCost"`
+	Cost        float64 `json:"cost"`
+}
+
+// AssemblyOrderResponse represents an assembly order and its cost roll-up
+type AssemblyOrderResponse struct {
+	ID                     string                      `json:"id,omitempty"`
+	OrderNumber            string                      `json:"orderNumber,omitempty"`
+	ProductID              string                      `json:"productId"`
+	Quantity               float64                     `json:"quantity"`
+	ProducedQty            float64                     `json:"producedQty"`
+	Status                 string                      `json:"status"`
+	MaterialCost           float64                     `json:"materialCost"`
+	OverheadCost           float64                     `json:"overheadCost"`
+	TotalCost              float64                     `json:"totalCost"`
+	UnitCost               float64                     `json:"unitCost"`
+	WIPJournalEntryID      *string                     `json:"wipJournalEntryId,omitempty"`
+	FinishedJournalEntryID *string                     `json:"finishedJournalEntryId,omitempty"`
+	Note                   *string                     `json:"note,omitempty"`
+	StartedAt              *string                     `json:"startedAt,omitempty"`
+	CompletedAt            *string                     `json:"completedAt,omitempty"`
+	CreatedAt              string                      `json:"createdAt,omitempty"`
+	Components             []AssemblyComponentResponse `json:"components,omitempty"`
+}
+
+// @Summary Get a product's bill of materials
+// @Description Get the components one unit of the product is assembled from
+// @Tags assembly
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} BOMResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/bom [get]
+// @Security BearerAuth
+func (h *AssemblyHandler) GetBOM(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	bom, err := h.service.GetBOM(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		return assemblyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBOMResponse(bom))
+}
+
+// @Summary Set a product's bill of materials
+// @Description Replace the components (per finished unit, with expected waste) a product is assembled from
+// @Tags assembly
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param bom body SaveBOMRequest true "Bill of materials"
+// @Success 200 {object} BOMResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/bom [put]
+// @Security BearerAuth
+func (h *AssemblyHandler) SaveBOM(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	var req SaveBOMRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	bom := &domain.BillOfMaterials{
+		TenantID:   tenantID,
+		ProductID:  productID,
+		Components: make([]domain.BOMComponent, 0, len(req.Components)),
+	}
+	for _, component := range req.Components {
+		componentID, err := uuid.Parse(component.ComponentID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid component ID"})
+		}
+		bom.Components = append(bom.Components, domain.BOMComponent{
+			ID:           uuid.New(),
+			ComponentID:  componentID,
+			Quantity:     component.Quantity,
+			WastePercent: component.WastePercent,
+		})
+	}
+
+	if err := h.service.SaveBOM(c.Request().Context(), bom); err != nil {
+		return assemblyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBOMResponse(bom))
+}
+
+// @Summary Delete a product's bill of materials
+// @Description Remove the product's components. Existing assembly orders are unaffected.
+// @Tags assembly
+// @Param id path string true "Product ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/bom [delete]
+// @Security BearerAuth
+func (h *AssemblyHandler) DeleteBOM(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.DeleteBOM(c.Request().Context(), tenantID, productID); err != nil {
+		return assemblyError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary Roll up a product's BOM cost
+// @Description Cost an assembly of the given quantity from current component cost prices, without creating an order
+// @Tags assembly
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param quantity query number false "Quantity to assemble" default(1)
+// @Success 200 {object} AssemblyOrderResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/bom/cost [get]
+// @Security BearerAuth
+func (h *AssemblyHandler) CostBOM(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	quantity := 1.0
+	if raw := c.QueryParam("quantity"); raw != "" {
+		quantity, err = strconv.ParseFloat(raw, 64)
+		if err != nil || quantity <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid quantity"})
+		}
+	}
+
+	order, err := h.service.PlanOrder(c.Request().Context(), tenantID, productID, quantity)
+	if err != nil {
+		return assemblyError(c, err)
+	}
+
+	resp := toAssemblyOrderResponse(order)
+	resp.ID = ""
+	resp.CreatedAt = ""
+	resp.UnitCost = order.PlannedUnitCost()
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Create an assembly order
+// @Description Plan an assembly of a product; components are costed at their current cost prices
+// @Tags assembly
+// @Accept json
+// @Produce json
+// @Param order body CreateAssemblyOrderRequest true "Assembly order"
+// @Success 201 {object} AssemblyOrderResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/assembly-orders [post]
+// @Security BearerAuth
+func (h *AssemblyHandler) CreateOrder(c echo.Context) error {
+	var req CreateAssemblyOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	productID, err := uuid.Parse(req.ProductID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	var createdBy *uuid.UUID
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		createdBy = &userID
+	}
+
+	order, err := h.service.CreateOrder(c.Request().Context(), tenantID, productID, req.Quantity, req.Note, createdBy)
+	if err != nil {
+		return assemblyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toAssemblyOrderResponse(order))
+}
+
+// @Summary List assembly orders
+// @Description Get the tenant's assembly orders, newest first
+// @Tags assembly
+// @Produce json
+// @Param status query string false "Filter by status (draft, in_progress, completed, cancelled)"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} AssemblyOrderResponse
+// @Router /api/v1/assembly-orders [get]
+// @Security BearerAuth
+func (h *AssemblyHandler) ListOrders(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	status := domain.AssemblyOrderStatus(c.QueryParam("status"))
+	orders, err := h.service.ListOrders(c.Request().Context(), tenantID, status, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	resp := make([]AssemblyOrderResponse, len(orders))
+	for i, order := range orders {
+		resp[i] = toAssemblyOrderResponse(order)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Get an assembly order
+// @Description Get an assembly order with its components and cost roll-up
+// @Tags assembly
+// @Produce json
+// @Param id path string true "Assembly order ID"
+// @Success 200 {object} AssemblyOrderResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/assembly-orders/{id} [get]
+// @Security BearerAuth
+func (h *AssemblyHandler) GetOrder(c echo.Context) error {
+	return h.orderAction(c, h.service.GetOrder)
+}
+
+// @Summary Start an assembly order
+// @Description Consume the components into work in progress
+// @Tags assembly
+// @Produce json
+// @Param id path string true "Assembly order ID"
+// @Success 200 {object} AssemblyOrderResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/assembly-orders/{id}/start [post]
+// @Security BearerAuth
+func (h *AssemblyHandler) StartOrder(c echo.Context) error {
+	return h.orderAction(c, h.service.StartOrder)
+}
+
+// @Summary Cancel an assembly order
+// @Description Abandon an order that has not started
+// @Tags assembly
+// @Produce json
+// @Param id path string true "Assembly order ID"
+// @Success 200 {object} AssemblyOrderResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/assembly-orders/{id}/cancel [post]
+// @Security BearerAuth
+func (h *AssemblyHandler) CancelOrder(c echo.Context) error {
+	return h.orderAction(c, h.service.CancelOrder)
+}
+
+// @Summary Complete an assembly order
+// @Description Receive the finished goods. Material plus overhead cost is spread over the produced quantity and becomes the product's cost price.
+// @Tags assembly
+// @Accept json
+// @Produce json
+// @Param id path string true "Assembly order ID"
+// @Param completion body CompleteAssemblyOrderRequest true "Actual output"
+// @Success 200 {object} AssemblyOrderResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/assembly-orders/{id}/complete [post]
+// @Security BearerAuth
+func (h *AssemblyHandler) CompleteOrder(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid assembly order ID"})
+	}
+
+	var req CompleteAssemblyOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	order, err := h.service.CompleteOrder(c.Request().Context(), tenantID, id, req.ProducedQty, req.OverheadCost)
+	if err != nil {
+		return assemblyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toAssemblyOrderResponse(order))
+}
+
+// orderAction runs a service call on the order in the path and returns the result
+func (h *AssemblyHandler) orderAction(c echo.Context, action func(ctx context.Context, tenantID, id uuid.UUID) (*domain.AssemblyOrder, error)) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid assembly order ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	order, err := action(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return assemblyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toAssemblyOrderResponse(order))
+}
+
+// assemblyError maps assembly domain errors to HTTP responses
+func assemblyError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidBOM):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrBOMNotFound), errors.Is(err, domain.ErrAssemblyOrderNotFound),
+		errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrAssemblyOrderState):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
+
+// toBOMResponse converts domain.BillOfMaterials to BOMResponse
+func toBOMResponse(bom *domain.BillOfMaterials) BOMResponse {
+	resp := BOMResponse{
+		ProductID:  bom.ProductID.String(),
+		Components: make([]BOMComponentResponse, len(bom.Components)),
+		UpdatedAt:  bom.UpdatedAt.Format(time.RFC3339),
+	}
+	for i, component := range bom.Components {
+		resp.Components[i] = BOMComponentResponse{
+			ComponentID:  component.ComponentID.String(),
+			Quantity:     component.Quantity,
+			WastePercent: component.WastePercent,
+		}
+	}
+	return resp
+}
+
+// toAssemblyOrderResponse converts domain.AssemblyOrder to AssemblyOrderResponse
+func toAssemblyOrderResponse(order *domain.AssemblyOrder) AssemblyOrderResponse {
+	resp := AssemblyOrderResponse{
+		ID:                     order.ID.String(),
+		OrderNumber:            order.OrderNumber,
+		ProductID:              order.ProductID.String(),
+		Quantity:               order.Quantity,
+		ProducedQty:            order.ProducedQty,
+		Status:                 string(order.Status),
+		MaterialCost:           order.MaterialCost,
+		OverheadCost:           order.OverheadCost,
+		TotalCost:              order.TotalCost,
+		UnitCost:               order.UnitCost,
+		WIPJournalEntryID:      optionalIDString(order.WIPJournalEntryID),
+		FinishedJournalEntryID: optionalIDString(order.FinishedJournalEntryID),
+		Note:                   order.Note,
+		CreatedAt:              order.CreatedAt.Format(time.RFC3339),
+	}
+	if order.StartedAt != nil {
+		startedAt := order.StartedAt.Format(time.RFC3339)
+		resp.StartedAt = &startedAt
+	}
+	if order.CompletedAt != nil {
+		completedAt := order.CompletedAt.Format(time.RFC3339)
+		resp.CompletedAt = &completedAt
+	}
+	for _, component := range order.Components {
+		resp.Components = append(resp.Components, AssemblyComponentResponse{
+			ComponentID: component.ComponentID.String(),
+			Quantity:    component.Quantity,
+			UnitCost:    component.UnitCost,
+			Cost:        component.Cost,
+		})
+	}
+	return resp
+}
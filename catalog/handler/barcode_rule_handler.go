@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// BarcodeRuleHandler handles scale barcode rule HTTP requests
+type BarcodeRuleHandler struct {
+	service service.BarcodeService
+}
+
+// NewBarcodeRuleHandler creates a new barcode rule handler
+func NewBarcodeRuleHandler(service service.BarcodeService) *BarcodeRuleHandler {
+	return &BarcodeRuleHandler{service: service}
+}
+
+// BarcodeRuleRequest represents the request to create or update a scale barcode rule
+type BarcodeRuleRequest struct {
+	Name           string `json:"name" validate:"required,max=100"`
+	Prefix         string `json:"prefix" validate:"required,numeric,max=3"`
+	ItemCodeDigits int    `json:"itemCodeDigits" validate:"gte=1,lte=10"`
+	ValueDigits    int    `json:"valueDigits" validate:"gte=1,lte=10"`
+	ValueType      string `json:"valueType" validate:"required,oneof=weight price"`
+	Decimals       int    `json:"decimals" validate:"gte=0,lte=10"`
+	IsActive       *bool  `json:"isActive,omitempty"`
+}
+
+// BarcodeRuleResponse represents the scale barcode rule response
+type BarcodeRuleResponse struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Prefix         string `json:"prefix"`
+	ItemCodeDigits int    `json:"itemCodeDigits"`
+	ValueDigits    int    `json:"valueDigits"`
+	ValueType      string `json:"valueType"`
+	Decimals       int    `json:"decimals"`
+	Length         int    `json:"length"`
+	IsActive       bool   `json:"isActive"`
+	UpdatedAt      string `json:"updatedAt"`
+}
+
+// @Summary Create a barcode rule
+// @Description Define how scale-printed barcodes with this prefix embed an item code and a weight or price
+// @Tags barcode-rules
+// @Accept json
+// @Produce json
+// @Param rule body BarcodeRuleRequest true "Barcode rule"
+// @Success 201 {object} BarcodeRuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/barcode-rules [post]
+// @Security BearerAuth
+func (h *BarcodeRuleHandler) Create(c echo.Context) error {
+	var req BarcodeRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rule := domain.NewBarcodeRule(tenantID, req.Name, req.Prefix, req.ItemCodeDigits, req.ValueDigits,
+		domain.ScaleValueType(req.ValueType), req.Decimals)
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := h.service.CreateRule(c.Request().Context(), rule); err != nil {
+		return barcodeRuleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toBarcodeRuleResponse(rule))
+}
+
+// @Summary List barcode rules
+// @Description Get the tenant's scale barcode rules
+// @Tags barcode-rules
+// @Produce json
+// @Success 200 {array} BarcodeRuleResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/barcode-rules [get]
+// @Security BearerAuth
+func (h *BarcodeRuleHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rules, err := h.service.ListRules(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]BarcodeRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = toBarcodeRuleResponse(rule)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Update a barcode rule
+// @Description Change a scale barcode rule's layout or active flag
+// @Tags barcode-rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Barcode rule ID"
+// @Param rule body BarcodeRuleRequest true "Barcode rule"
+// @Success 200 {object} BarcodeRuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/barcode-rules/{id} [put]
+// @Security BearerAuth
+func (h *BarcodeRuleHandler) Update(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	var req BarcodeRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rule, err := h.service.GetRule(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return barcodeRuleError(c, err)
+	}
+
+	rule.Name = req.Name
+	rule.Prefix = req.Prefix
+	rule.ItemCodeDigits = req.ItemCodeDigits
+	rule.ValueDigits = req.ValueDigits
+	rule.ValueType = domain.ScaleValueType(req.ValueType)
+	rule.Decimals = req.Decimals
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := h.service.UpdateRule(c.Request().Context(), rule); err != nil {
+		return barcodeRuleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBarcodeRuleResponse(rule))
+}
+
+// @Summary Delete a barcode rule
+// @Description Delete a scale barcode rule
+// @Tags barcode-rules
+// @Param id path string true "Barcode rule ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/barcode-rules/{id} [delete]
+// @Security BearerAuth
+func (h *BarcodeRuleHandler) Delete(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.DeleteRule(c.Request().Context(), tenantID, id); err != nil {
+		return barcodeRuleError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// barcodeRuleError maps barcode rule domain errors to HTTP responses
+func barcodeRuleError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidBarcodeRule):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrBarcodeRuleNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// toBarcodeRuleResponse converts domain.BarcodeRule to BarcodeRuleResponse
+func toBarcodeRuleResponse(rule *domain.BarcodeRule) BarcodeRuleResponse {
+	return BarcodeRuleResponse{
+		ID:             rule.ID.String(),
+		Name:           rule.Name,
+		Prefix:         rule.Prefix,
+		ItemCodeDigits: rule.ItemCodeDigits,
+		ValueDigits:    rule.ValueDigits,
+		ValueType:      string(rule.ValueType),
+		Decimals:       rule.Decimals,
+		Length:         rule.Length(),
+		IsActive:       rule.IsActive,
+		UpdatedAt:      rule.UpdatedAt.Format(time.RFC3339),
+	}
+}
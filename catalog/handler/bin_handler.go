@@ -0,0 +1,652 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// BinHandler handles shelf/bin location HTTP requests
+type BinHandler struct {
+	service service.BinService
+}
+
+// NewBinHandler creates a new bin handler
+func NewBinHandler(service service.BinService) *BinHandler {
+	return &BinHandler{service: service}
+}
+
+// BinRequest represents the request to create or update a bin location
+type BinRequest struct {
+	WarehouseID  *string `json:"warehouseId,omitempty" validate:"omitempty,uuid"` // Defaults to the main warehouse
+	Code         string  `json:"code" validate:"required,max=30"`
+	Zone         *string `json:"zone,omitempty" validate:"omitempty,max=100"`
+	Description  *string `json:"description,omitempty"`
+	PickSequence int     `json:"pickSequence" validate:"gte=0"`
+	IsActive     *bool   `json:"isActive,omitempty"`
+}
+
+// SetBinQuantityRequest represents a shelf count or put-away of a product in a bin
+type SetBinQuantityRequest struct {
+	Quantity float64 `json:"quantity" validate:"gte=0"`
+	Note     *string `json:"note,omitempty"`
+}
+
+// BinTransferRequest represents the request to move stock between bins
+type BinTransferRequest struct {
+	ProductID string  `json:"productId" validate:"required,uuid"`
+	FromBinID string  `json:"fromBinId" validate:"required,uuid"`
+	ToBinID   string  `json:"toBinId" validate:"required,uuid"`
+	Quantity  float64 `json:"quantity" validate:"gt=0"`
+	Note      *string `json:"note,omitempty"`
+}
+
+// PickItemRequest represents a product and quantity to pick
+type PickItemRequest struct {
+	ProductID string  `json:"productId" validate:"required,uuid"`
+	Quantity  float64 `json:"quantity" validate:"gt=0"`
+}
+
+// PickListRequest represents the items to build a pick list for, e.g. an order's lines
+type PickListRequest struct {
+	WarehouseID *string           `json:"warehouseId,omitempty" validate:"omitempty,uuid"` // Walk only this warehouse's bins
+	Items       []PickItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// BinResponse represents a bin location
+type BinResponse struct {
+	ID           string  `json:"id"`
+	WarehouseID  *string `json:"warehouseId,omitempty"`
+	Code         string  `json:"code"`
+	Zone         *string `json:"zone,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	PickSequence int     `json:"pickSequence"`
+	IsActive     bool    `json:"isActive"`
+	UpdatedAt    string  `json:"updatedAt"`
+}
+
+// BinStockResponse represents a product's quantity in a bin
+type BinStockResponse struct {
+	BinID       string  `json:"binId"`
+	BinCode     string  `json:"binCode"`
+	WarehouseID *string `json:"warehouseId,omitempty"`
+	ProductID   string  `json:"productId"`
+	Quantity    float64 `json:"quantity"`
+	UpdatedAt   string  `json:"updatedAt"`
+}
+
+// BinMovementResponse represents a shelf count or bin transfer
+type BinMovementResponse struct {
+	ID        string  `json:"id"`
+	ProductID string  `json:"productId"`
+	Type      string  `json:"type"`
+	FromBinID *string `json:"fromBinId,omitempty"`
+	ToBinID   *string `json:"toBinId,omitempty"`
+	Quantity  float64 `json:"quantity"`
+	Note      *string `json:"note,omitempty"`
+	CreatedBy *string `json:"createdBy,omitempty"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+// PickLineResponse tells the picker what to take from a bin
+type PickLineResponse struct {
+	BinID     string  `json:"binId"`
+	BinCode   string  `json:"binCode"`
+	ProductID string  `json:"productId"`
+	Quantity  float64 `json:"quantity"`
+}
+
+// PickShortageResponse is the part of an item no bin holds
+type PickShortageResponse struct {
+	ProductID string  `json:"productId"`
+	Quantity  float64 `json:"quantity"`
+}
+
+// PickListResponse represents a pick list in walking order
+type PickListResponse struct {
+	Lines     []PickLineResponse     `json:"lines"`
+	Shortages []PickShortageResponse `json:"shortages"`
+}
+
+// @Summary Create a bin location
+// @Description Add a shelf or rack location, e.g. A-03-2. Pick lists walk bins by pick sequence, then code.
+// @Tags bins
+// @Accept json
+// @Produce json
+// @Param bin body BinRequest true "Bin location"
+// @Success 201 {object} BinResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/bins [post]
+// @Security BearerAuth
+func (h *BinHandler) Create(c echo.Context) error {
+	var req BinRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	warehouseID, err := parseOptionalID(req.WarehouseID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warehouse ID"})
+	}
+
+	bin := domain.NewBin(tenantID, req.Code, req.PickSequence)
+	bin.WarehouseID = warehouseID
+	bin.Zone = req.Zone
+	bin.Description = req.Description
+	if req.IsActive != nil {
+		bin.IsActive = *req.IsActive
+	}
+
+	if err := h.service.CreateBin(c.Request().Context(), bin); err != nil {
+		return binError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toBinResponse(bin))
+}
+
+// @Summary List bin locations
+// @Description Get the tenant's bin locations in pick order
+// @Tags bins
+// @Produce json
+// @Success 200 {array} BinResponse
+// @Router /api/v1/bins [get]
+// @Security BearerAuth
+func (h *BinHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	bins, err := h.service.ListBins(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]BinResponse, len(bins))
+	for i, bin := range bins {
+		responses[i] = toBinResponse(bin)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Get a bin location
+// @Tags bins
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Success 200 {object} BinResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/bins/{id} [get]
+// @Security BearerAuth
+func (h *BinHandler) Get(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid bin ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	bin, err := h.service.GetBin(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return binError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBinResponse(bin))
+}
+
+// @Summary Update a bin location
+// @Description Change a bin's code, zone, pick sequence or active flag. Inactive bins are skipped by pick lists.
+// @Tags bins
+// @Accept json
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Param bin body BinRequest true "Bin location"
+// @Success 200 {object} BinResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/bins/{id} [put]
+// @Security BearerAuth
+func (h *BinHandler) Update(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid bin ID"})
+	}
+
+	var req BinRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	bin, err := h.service.GetBin(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return binError(c, err)
+	}
+
+	if bin.WarehouseID, err = parseOptionalID(req.WarehouseID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warehouse ID"})
+	}
+	bin.Code = req.Code
+	bin.Zone = req.Zone
+	bin.Description = req.Description
+	bin.PickSequence = req.PickSequence
+	if req.IsActive != nil {
+		bin.IsActive = *req.IsActive
+	}
+
+	if err := h.service.UpdateBin(c.Request().Context(), bin); err != nil {
+		return binError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBinResponse(bin))
+}
+
+// @Summary Delete a bin location
+// @Description Delete an empty bin; move or count out its stock first
+// @Tags bins
+// @Param id path string true "Bin ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/bins/{id} [delete]
+// @Security BearerAuth
+func (h *BinHandler) Delete(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid bin ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.DeleteBin(c.Request().Context(), tenantID, id); err != nil {
+		return binError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary List a bin's stock
+// @Description Get the products held in a bin with their quantities
+// @Tags bins
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Success 200 {array} BinStockResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/bins/{id}/stock [get]
+// @Security BearerAuth
+func (h *BinHandler) ListStock(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid bin ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	stock, err := h.service.ListBinStock(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return binError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBinStockResponses(stock))
+}
+
+// @Summary Set a product's quantity in a bin
+// @Description Record a shelf count or put-away; the difference is kept as a count movement. Zero removes the product from the bin.
+// @Tags bins
+// @Accept json
+// @Produce json
+// @Param id path string true "Bin ID"
+// @Param productId path string true "Product ID"
+// @Param request body SetBinQuantityRequest true "Quantity"
+// @Success 200 {object} BinMovementResponse
+// @Success 204 "Quantity unchanged"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/bins/{id}/stock/{productId} [put]
+// @Security BearerAuth
+func (h *BinHandler) SetQuantity(c echo.Context) error {
+	binID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid bin ID"})
+	}
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	var req SetBinQuantityRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	movement := domain.NewBinCount(tenantID, productID, binID)
+	movement.Note = req.Note
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		movement.CreatedBy = &userID
+	}
+
+	if err := h.service.SetBinQuantity(c.Request().Context(), movement, req.Quantity); err != nil {
+		return binError(c, err)
+	}
+	if movement.Quantity == 0 {
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	return c.JSON(http.StatusOK, toBinMovementResponse(movement))
+}
+
+// @Summary Transfer stock between bins
+// @Description Move a quantity of a product from one bin to another
+// @Tags bins
+// @Accept json
+// @Produce json
+// @Param request body BinTransferRequest true "Transfer"
+// @Success 201 {object} BinMovementResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/bins/transfers [post]
+// @Security BearerAuth
+func (h *BinHandler) Transfer(c echo.Context) error {
+	var req BinTransferRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	productID, err := uuid.Parse(req.ProductID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+	fromBinID, err := uuid.Parse(req.FromBinID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid bin ID"})
+	}
+	toBinID, err := uuid.Parse(req.ToBinID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid bin ID"})
+	}
+
+	movement := domain.NewBinTransfer(tenantID, productID, fromBinID, toBinID, req.Quantity)
+	movement.Note = req.Note
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		movement.CreatedBy = &userID
+	}
+
+	if err := h.service.Transfer(c.Request().Context(), movement); err != nil {
+		return binError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toBinMovementResponse(movement))
+}
+
+// @Summary List bin movements
+// @Description Shelf counts and bin transfers, newest first
+// @Tags bins
+// @Produce json
+// @Param productId query string false "Only this product"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} BinMovementResponse
+// @Router /api/v1/bins/movements [get]
+// @Security BearerAuth
+func (h *BinHandler) ListMovements(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var productID *uuid.UUID
+	if raw := c.QueryParam("productId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+		}
+		productID = &id
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	movements, err := h.service.ListMovements(c.Request().Context(), tenantID, productID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]BinMovementResponse, len(movements))
+	for i, movement := range movements {
+		responses[i] = toBinMovementResponse(movement)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Build a pick list
+// @Description Allocate items to the active bins holding them and order the lines so the picker walks the
+// @Description store once. Quantities no bin holds are listed as shortages. Bin stock is not changed.
+// @Tags bins
+// @Accept json
+// @Produce json
+// @Param request body PickListRequest true "Items to pick"
+// @Success 200 {object} PickListResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/bins/pick-list [post]
+// @Security BearerAuth
+func (h *BinHandler) PickList(c echo.Context) error {
+	var req PickListRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	items := make([]domain.PickItem, len(req.Items))
+	for i, item := range req.Items {
+		productID, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+		}
+		items[i] = domain.PickItem{ProductID: productID, Quantity: item.Quantity}
+	}
+
+	warehouseID, err := parseOptionalID(req.WarehouseID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warehouse ID"})
+	}
+
+	list, err := h.service.PickList(c.Request().Context(), tenantID, warehouseID, items)
+	if err != nil {
+		return binError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toPickListResponse(list))
+}
+
+// @Summary List a product's bins
+// @Description Where a product is shelved, in pick order
+// @Tags bins
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {array} BinStockResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/bins [get]
+// @Security BearerAuth
+func (h *BinHandler) ListProductBins(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	stock, err := h.service.ListProductBins(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		return binError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBinStockResponses(stock))
+}
+
+// binError maps bin errors to HTTP statuses
+func binError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrBinNotFound), errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrBinCodeExists), errors.Is(err, domain.ErrBinNotEmpty),
+		errors.Is(err, domain.ErrInsufficientBinStock):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidBin), errors.Is(err, domain.ErrUnknownUnit):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// toBinResponse converts domain.Bin to BinResponse
+func toBinResponse(bin *domain.Bin) BinResponse {
+	resp := BinResponse{
+		ID:           bin.ID.String(),
+		Code:         bin.Code,
+		Zone:         bin.Zone,
+		Description:  bin.Description,
+		PickSequence: bin.PickSequence,
+		IsActive:     bin.IsActive,
+		UpdatedAt:    bin.UpdatedAt.Format(time.RFC3339),
+	}
+	if bin.WarehouseID != nil {
+		id := bin.WarehouseID.String()
+		resp.WarehouseID = &id
+	}
+	return resp
+}
+
+func toBinStockResponses(stock []domain.BinStock) []BinStockResponse {
+	responses := make([]BinStockResponse, len(stock))
+	for i, held := range stock {
+		responses[i] = BinStockResponse{
+			BinID:     held.BinID.String(),
+			BinCode:   held.BinCode,
+			ProductID: held.ProductID.String(),
+			Quantity:  held.Quantity,
+			UpdatedAt: held.UpdatedAt.Format(time.RFC3339),
+		}
+		if held.WarehouseID != nil {
+			id := held.WarehouseID.String()
+			responses[i].WarehouseID = &id
+		}
+	}
+	return responses
+}
+
+// toBinMovementResponse converts domain.BinMovement to BinMovementResponse
+func toBinMovementResponse(movement *domain.BinMovement) BinMovementResponse {
+	resp := BinMovementResponse{
+		ID:        movement.ID.String(),
+		ProductID: movement.ProductID.String(),
+		Type:      string(movement.Type),
+		Quantity:  movement.Quantity,
+		Note:      movement.Note,
+		CreatedAt: movement.CreatedAt.Format(time.RFC3339),
+	}
+	if movement.FromBinID != nil {
+		id := movement.FromBinID.String()
+		resp.FromBinID = &id
+	}
+	if movement.ToBinID != nil {
+		id := movement.ToBinID.String()
+		resp.ToBinID = &id
+	}
+	if movement.CreatedBy != nil {
+		id := movement.CreatedBy.String()
+		resp.CreatedBy = &id
+	}
+	return resp
+}
+
+// toPickListResponse converts domain.PickList to PickListResponse
+func toPickListResponse(list *domain.PickList) PickListResponse {
+	resp := PickListResponse{
+		Lines:     make([]PickLineResponse, len(list.Lines)),
+		Shortages: make([]PickShortageResponse, len(list.Shortages)),
+	}
+	for i, line := range list.Lines {
+		resp.Lines[i] = PickLineResponse{
+			BinID:     line.BinID.String(),
+			BinCode:   line.BinCode,
+			ProductID: line.ProductID.String(),
+			Quantity:  line.Quantity,
+		}
+	}
+	for i, shortage := range list.Shortages {
+		resp.Shortages[i] = PickShortageResponse{
+			ProductID: shortage.ProductID.String(),
+			Quantity:  shortage.Quantity,
+		}
+	}
+	return resp
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// BulkPriceFilterRequest selects the products a bulk price update changes; omitted fields match everything
+type BulkPriceFilterRequest struct {
+	CategoryID      *string  `json:"categoryId,omitempty"`
+	ProductIDs      []string `json:"productIds,omitempty"`
+	Query           string   `json:"query,omitempty"`
+	MinPrice        *float64 `json:"minPrice,omitempty" validate:"omitempty,gte=0"`
+	MaxPrice        *float64 `json:"maxPrice,omitempty" validate:"omitempty,gte=0"`
+	IncludeInactive bool     `json:"includeInactive"`
+}
+
+// PriceRoundingRequest rounds adjusted prices, e.g. step 10 with ending 9 for prices like 109, 119
+type PriceRoundingRequest struct {
+	Step   float64 `json:"step" validate:"gte=0"`
+	Mode   string  `json:"mode,omitempty" validate:"omitempty,oneof=nearest up down"`
+	Ending float64 `json:"ending" validate:"gte=0"`
+}
+
+// PriceAdjustmentRequest is the change applied to each matched price
+type PriceAdjustmentRequest struct {
+	Type     string               `json:"type" validate:"required,oneof=percent amount"`
+	Value    float64              `json:"value"`
+	Rounding PriceRoundingRequest `json:"rounding"`
+}
+
+// BulkPriceUpdateRequest represents the request to preview or apply a bulk price update
+type BulkPriceUpdateRequest struct {
+	Filter     BulkPriceFilterRequest `json:"filter"`
+	Adjustment PriceAdjustmentRequest `json:"adjustment"`
+	DryRun     bool                   `json:"dryRun"`
+}
+
+// BulkPriceRollbackRequest represents the request to roll back a bulk price update
+type BulkPriceRollbackRequest struct {
+	RollbackToken string `json:"rollbackToken" validate:"required"`
+}
+
+// BulkPriceItemResponse represents one product's price before and after
+type BulkPriceItemResponse struct {
+	ProductID     string   `json:"productId"`
+	ProductCode   string   `json:"productCode"`
+	Name          string   `json:"name"`
+	CostPrice     float64  `json:"costPrice"`
+	OldPrice      float64  `json:"oldPrice"`
+	NewPrice      float64  `json:"newPrice"`
+	ChangePercent float64  `json:"changePercent"`
+	Flags         []string `json:"flags,omitempty"`
+}
+
+// BulkPriceUpdateResponse represents a bulk price update preview or result
+type BulkPriceUpdateResponse struct {
+	ID                *string                 `json:"id,omitempty"`
+	DryRun            bool                    `json:"dryRun"`
+	ProductsMatched   int                     `json:"productsMatched"`
+	ProductsChanged   int                     `json:"productsChanged"`
+	Skipped           map[string]int          `json:"skipped"`
+	RollbackToken     *string                 `json:"rollbackToken,omitempty"`
+	RollbackExpiresAt *string                 `json:"rollbackExpiresAt,omitempty"`
+	AppliedAt         *string                 `json:"appliedAt,omitempty"`
+	Items             []BulkPriceItemResponse `json:"items"`
+}
+
+// BulkPriceRollbackResponse represents a rolled back bulk price update
+type BulkPriceRollbackResponse struct {
+	ID           string                  `json:"id"`
+	Restored     int                     `json:"restored"`
+	RolledBackAt string                  `json:"rolledBackAt"`
+	Kept         []BulkPriceItemResponse `json:"kept"`
+}
+
+// @Summary Bulk update prices
+// @Description Raise or lower the price of every product matching a filter by a percent or amount, with optional rounding (e.g. step 10, ending 9). With dryRun the before/after prices are only previewed. Otherwise all prices change in one transaction and a rollback token valid for 24 hours is returned. Prices are capped at the MRP.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param request body BulkPriceUpdateRequest true "Filter and adjustment"
+// @Success 200 {object} BulkPriceUpdateResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/products/bulk-price-update [post]
+// @Security BearerAuth
+func (h *PricingHandler) BulkUpdatePrices(c echo.Context) error {
+	var req BulkPriceUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	categoryID, err := parseOptionalID(req.Filter.CategoryID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid category ID"})
+	}
+	productIDs := make([]uuid.UUID, len(req.Filter.ProductIDs))
+	for i, raw := range req.Filter.ProductIDs {
+		if productIDs[i], err = uuid.Parse(raw); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+		}
+	}
+
+	filter := domain.BulkPriceFilter{
+		CategoryID:      categoryID,
+		ProductIDs:      productIDs,
+		Query:           req.Filter.Query,
+		MinPrice:        req.Filter.MinPrice,
+		MaxPrice:        req.Filter.MaxPrice,
+		IncludeInactive: req.Filter.IncludeInactive,
+	}
+	adjustment := domain.PriceAdjustment{
+		Type:  domain.PriceAdjustmentType(req.Adjustment.Type),
+		Value: req.Adjustment.Value,
+		Rounding: domain.PriceRounding{
+			Step:   req.Adjustment.Rounding.Step,
+			Mode:   domain.PriceRoundingMode(req.Adjustment.Rounding.Mode),
+			Ending: req.Adjustment.Rounding.Ending,
+		},
+	}
+
+	var appliedBy *uuid.UUID
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		appliedBy = &userID
+	}
+
+	update, err := h.service.BulkUpdatePrices(c.Request().Context(), tenantID, filter, adjustment, appliedBy, req.DryRun)
+	if err != nil {
+		return pricingError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBulkPriceUpdateResponse(update, req.DryRun))
+}
+
+// @Summary Roll back a bulk price update
+// @Description Restore the prices a bulk price update changed, within 24 hours of applying it. Products repriced again since then keep their newer price and are listed as kept.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param request body BulkPriceRollbackRequest true "Rollback token"
+// @Success 200 {object} BulkPriceRollbackResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Router /api/v1/products/bulk-price-update/rollback [post]
+// @Security BearerAuth
+func (h *PricingHandler) RollbackBulkUpdate(c echo.Context) error {
+	var req BulkPriceRollbackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var rolledBackBy *uuid.UUID
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		rolledBackBy = &userID
+	}
+
+	rollback, err := h.service.RollbackBulkUpdate(c.Request().Context(), tenantID, req.RollbackToken, rolledBackBy)
+	if err != nil {
+		return pricingError(c, err)
+	}
+
+	resp := BulkPriceRollbackResponse{
+		ID:       rollback.Update.ID.String(),
+		Restored: rollback.Restored,
+		Kept:     toBulkPriceItemResponses(rollback.Kept),
+	}
+	if rollback.Update.RolledBackAt != nil {
+		resp.RolledBackAt = rollback.Update.RolledBackAt.Format(time.RFC3339)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// toBulkPriceUpdateResponse converts domain.BulkPriceUpdate to BulkPriceUpdateResponse
+func toBulkPriceUpdateResponse(update *domain.BulkPriceUpdate, dryRun bool) BulkPriceUpdateResponse {
+	resp := BulkPriceUpdateResponse{
+		DryRun:          dryRun,
+		ProductsMatched: update.ProductsMatched,
+		ProductsChanged: update.ProductsChanged,
+		Skipped:         update.Skipped,
+		Items:           toBulkPriceItemResponses(update.Items),
+	}
+	// Only an applied update has been recorded and can be rolled back
+	if update.RollbackToken != "" {
+		id := update.ID.String()
+		token := update.RollbackToken
+		expiresAt := update.RollbackExpiresAt.Format(time.RFC3339)
+		appliedAt := update.AppliedAt.Format(time.RFC3339)
+		resp.ID = &id
+		resp.RollbackToken = &token
+		resp.RollbackExpiresAt = &expiresAt
+		resp.AppliedAt = &appliedAt
+	}
+	return resp
+}
+
+func toBulkPriceItemResponses(items []*domain.BulkPriceItem) []BulkPriceItemResponse {
+	responses := make([]BulkPriceItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = BulkPriceItemResponse{
+			ProductID:     item.ProductID.String(),
+			ProductCode:   item.ProductCode,
+			Name:          item.Name,
+			CostPrice:     item.CostPrice,
+			OldPrice:      item.OldPrice,
+			NewPrice:      item.NewPrice,
+			ChangePercent: item.ChangePercent(),
+			Flags:         item.Flags,
+		}
+	}
+	return responses
+}
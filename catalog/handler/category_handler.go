@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -13,12 +14,13 @@ import (
 
 // CategoryHandler handles category HTTP requests
 type CategoryHandler struct {
-	service service.CategoryService
+	service      service.CategoryService
+	localization service.LocalizationService
 }
 
 // NewCategoryHandler creates a new category handler
-func NewCategoryHandler(service service.CategoryService) *CategoryHandler {
-	return &CategoryHandler{service: service}
+func NewCategoryHandler(service service.CategoryService, localization service.LocalizationService) *CategoryHandler {
+	return &CategoryHandler{service: service, localization: localization}
 }
 
 // CreateCategoryRequest represents the request to create a category
@@ -27,6 +29,7 @@ type CreateCategoryRequest struct {
 	Description      *string                `json:"description,omitempty"`
 	ParentID         *string                `json:"parentId,omitempty"`
 	SortOrder        int                    `json:"sortOrder"`
+	TaxGroupID       *string                `json:"taxGroupId,omitempty"`
 	CustomAttributes map[string]interface{} `json:"customAttributes,omitempty"`
 }
 
@@ -36,6 +39,7 @@ type UpdateCategoryRequest struct {
 	Description      *string                `json:"description,omitempty"`
 	ParentID         *string                `json:"parentId,omitempty"`
 	SortOrder        int                    `json:"sortOrder"`
+	TaxGroupID       *string                `json:"taxGroupId,omitempty"`
 	IsActive         bool                   `json:"isActive"`
 	CustomAttributes map[string]interface{} `json:"customAttributes,omitempty"`
 }
@@ -51,8 +55,10 @@ type CategoryResponse struct {
 	Level            int                    `json:"level"`
 	Path             string                 `json:"path"`
 	SortOrder        int                    `json:"sortOrder"`
+	TaxGroupID       *string                `json:"taxGroupId,omitempty"`
 	IsActive         bool                   `json:"isActive"`
 	CustomAttributes map[string]interface{} `json:"customAttributes"`
+	Locale           string                 `json:"locale,omitempty"` // Language of name and description when translated
 	CreatedAt        string                 `json:"createdAt"`
 	UpdatedAt        string                 `json:"updatedAt"`
 }
@@ -98,7 +104,17 @@ func (h *CategoryHandler) Create(c echo.Context) error {
 		category.ParentID = &parentUUID
 	}
 
+	taxGroupID, err := parseOptionalID(req.TaxGroupID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tax group ID"})
+	}
+	category.TaxGroupID = taxGroupID
+
 	if err := h.service.Create(c.Request().Context(), category); err != nil {
+		// A missing category here is the parent
+		if errors.Is(err, domain.ErrUnknownTax) || errors.Is(err, domain.ErrCategoryNotFound) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -106,12 +122,14 @@ func (h *CategoryHandler) Create(c echo.Context) error {
 }
 
 // @Summary List categories
-// @Description Get all categories for the tenant
+// @Description Get the tenant's categories in display order, a page at a time. Pass the nextCursor of a page as cursor to get the next; it is absent on the last page.
 // @Tags categories
 // @Produce json
-// @Param limit query int false "Limit" default(10)
-// @Param offset query int false "Offset" default(0)
-// @Success 200 {array} CategoryResponse
+// @Param limit query int false "Page size (max 200)" default(10)
+// @Param cursor query string false "nextCursor of the previous page"
+// @Param locale query string false "Languages to show names in, best first (default Accept-Language)"
+// @Success 200 {object} db.Page[CategoryResponse]
+// @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Router /api/v1/categories [get]
 // @Security BearerAuth
@@ -121,23 +139,21 @@ func (h *CategoryHandler) List(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
 	}
 
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	if limit == 0 {
-		limit = 10
+	page, err := db.ParsePageRequest(c.QueryParam("cursor"), c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
-	offset, _ := strconv.Atoi(c.QueryParam("offset"))
 
-	categories, err := h.service.GetByTenantID(c.Request().Context(), tenantID, limit, offset)
+	categories, err := h.service.List(c.Request().Context(), tenantID, page)
 	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
-	responses := make([]CategoryResponse, len(categories))
-	for i, cat := range categories {
-		responses[i] = toCategoryResponse(cat)
-	}
-
-	return c.JSON(http.StatusOK, responses)
+	h.localize(c, categories.Items...)
+	return c.JSON(http.StatusOK, db.MapPage(categories, toCategoryResponse))
 }
 
 // @Summary Search categories
@@ -147,6 +163,7 @@ func (h *CategoryHandler) List(c echo.Context) error {
 // @Param q query string true "Search query"
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
+// @Param locale query string false "Languages to show names in, best first (default Accept-Language)"
 // @Success 200 {array} CategoryResponse
 // @Failure 401 {object} map[string]string
 // @Router /api/v1/categories/search [get]
@@ -169,6 +186,8 @@ func (h *CategoryHandler) Search(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	h.localize(c, categories...)
+
 	responses := make([]CategoryResponse, len(categories))
 	for i, cat := range categories {
 		responses[i] = toCategoryResponse(cat)
@@ -181,6 +200,7 @@ func (h *CategoryHandler) Search(c echo.Context) error {
 // @Description Get root categories (tree structure)
 // @Tags categories
 // @Produce json
+// @Param locale query string false "Languages to show names in, best first (default Accept-Language)"
 // @Success 200 {array} CategoryResponse
 // @Failure 401 {object} map[string]string
 // @Router /api/v1/categories/tree [get]
@@ -196,6 +216,8 @@ func (h *CategoryHandler) GetTree(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	h.localize(c, categories...)
+
 	responses := make([]CategoryResponse, len(categories))
 	for i, cat := range categories {
 		responses[i] = toCategoryResponse(cat)
@@ -209,6 +231,7 @@ func (h *CategoryHandler) GetTree(c echo.Context) error {
 // @Tags categories
 // @Produce json
 // @Param id path string true "Category ID"
+// @Param locale query string false "Languages to show the name in, best first (default Accept-Language)"
 // @Success 200 {object} CategoryResponse
 // @Failure 404 {object} map[string]string
 // @Router /api/v1/categories/{id} [get]
@@ -219,11 +242,17 @@ func (h *CategoryHandler) GetByID(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
 	}
 
-	category, err := h.service.GetByID(c.Request().Context(), id)
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	category, err := h.service.GetByID(c.Request().Context(), tenantID, id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
 	}
 
+	h.localize(c, category)
 	return c.JSON(http.StatusOK, toCategoryResponse(category))
 }
 
@@ -232,6 +261,7 @@ func (h *CategoryHandler) GetByID(c echo.Context) error {
 // @Tags categories
 // @Produce json
 // @Param id path string true "Parent Category ID"
+// @Param locale query string false "Languages to show names in, best first (default Accept-Language)"
 // @Success 200 {array} CategoryResponse
 // @Failure 404 {object} map[string]string
 // @Router /api/v1/categories/{id}/children [get]
@@ -247,6 +277,8 @@ func (h *CategoryHandler) GetChildren(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	h.localize(c, categories...)
+
 	responses := make([]CategoryResponse, len(categories))
 	for i, cat := range categories {
 		responses[i] = toCategoryResponse(cat)
@@ -282,7 +314,12 @@ func (h *CategoryHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	category, err := h.service.GetByID(c.Request().Context(), id)
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	category, err := h.service.GetByID(c.Request().Context(), tenantID, id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
 	}
@@ -291,11 +328,21 @@ func (h *CategoryHandler) Update(c echo.Context) error {
 	category.Description = req.Description
 	category.SortOrder = req.SortOrder
 	category.IsActive = req.IsActive
+	category.TaxGroupID, err = parseOptionalID(req.TaxGroupID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tax group ID"})
+	}
 	if req.CustomAttributes != nil {
 		category.CustomAttributes = req.CustomAttributes
 	}
 
 	if err := h.service.Update(c.Request().Context(), category); err != nil {
+		if errors.Is(err, domain.ErrUnknownTax) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -316,7 +363,15 @@ func (h *CategoryHandler) Delete(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
 	}
 
-	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.Delete(c.Request().Context(), tenantID, id); err != nil {
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -336,6 +391,7 @@ func toCategoryResponse(cat *domain.Category) CategoryResponse {
 		SortOrder:        cat.SortOrder,
 		IsActive:         cat.IsActive,
 		CustomAttributes: cat.CustomAttributes,
+		Locale:           cat.Locale,
 		CreatedAt:        cat.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:        cat.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -345,5 +401,10 @@ func toCategoryResponse(cat *domain.Category) CategoryResponse {
 		resp.ParentID = &parentID
 	}
 
+	if cat.TaxGroupID != nil {
+		taxGroupID := cat.TaxGroupID.String()
+		resp.TaxGroupID = &taxGroupID
+	}
+
 	return resp
 }
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// DemandHandler handles sales velocity and demand forecasting HTTP requests
+type DemandHandler struct {
+	service service.DemandService
+}
+
+// NewDemandHandler creates a new demand handler
+func NewDemandHandler(service service.DemandService) *DemandHandler {
+	return &DemandHandler{service: service}
+}
+
+// SeasonalityResponse is a Bikram Sambat month's seasonal index
+type SeasonalityResponse struct {
+	Month int     `json:"month"`
+	Name  string  `json:"name"`
+	Index float64 `json:"index"`
+}
+
+// ProductDemandResponse represents a product's sales velocity
+type ProductDemandResponse struct {
+	ProductID   string                `json:"productId"`
+	ProductCode string                `json:"productCode"`
+	ProductName string                `json:"productName"`
+	Unit        string                `json:"unit"`
+	Sold30      float64               `json:"sold30"`
+	Sold90      float64               `json:"sold90"`
+	AvgDaily30  float64               `json:"avgDaily30"`
+	AvgDaily90  float64               `json:"avgDaily90"`
+	BaseDaily   float64               `json:"baseDaily"`
+	Seasonality []SeasonalityResponse `json:"seasonality"`
+	FirstSoldOn *string               `json:"firstSoldOn,omitempty"`
+	LastSoldOn  *string               `json:"lastSoldOn,omitempty"`
+	ComputedAt  string                `json:"computedAt"`
+}
+
+// DemandForecastResponse represents a product's expected sales over the coming days
+type DemandForecastResponse struct {
+	ProductDemandResponse
+	ForecastFrom string  `json:"forecastFrom"`
+	ForecastDays int     `json:"forecastDays"`
+	Forecast     float64 `json:"forecast"`
+}
+
+// ReorderSuggestionResponse represents how much of a product to order
+type ReorderSuggestionResponse struct {
+	ProductID    string  `json:"productId"`
+	ProductCode  string  `json:"productCode"`
+	ProductName  string  `json:"productName"`
+	Unit         string  `json:"unit"`
+	BaseDaily    float64 `json:"baseDaily"`
+	Forecast     float64 `json:"forecast"`
+	Available    float64 `json:"available"`
+	DaysOfStock  float64 `json:"daysOfStock"`
+	SuggestedQty float64 `json:"suggestedQty"`
+}
+
+// RecomputeDemandResponse reports how many products the statistics cover
+type RecomputeDemandResponse struct {
+	Products int `json:"products"`
+}
+
+// @Summary List demand statistics
+// @Description Per-product sales velocity of active products, fastest selling first. Computed nightly.
+// @Tags demand
+// @Produce json
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} ProductDemandResponse
+// @Router /api/v1/demand [get]
+// @Security BearerAuth
+func (h *DemandHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	demands, err := h.service.ListDemand(c.Request().Context(), tenantID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]ProductDemandResponse, len(demands))
+	for i, demand := range demands {
+		responses[i] = toProductDemandResponse(demand)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Get a product's demand forecast
+// @Description Rolling 30/90-day averages, seasonality by Nepali month and the expected sales over the next days
+// @Tags demand
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param days query int false "Days to forecast" default(30)
+// @Success 200 {object} DemandForecastResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/demand [get]
+// @Security BearerAuth
+func (h *DemandHandler) Forecast(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	days, _ := strconv.Atoi(c.QueryParam("days"))
+	if days == 0 {
+		days = 30
+	}
+	if days < 1 || days > 365 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "days must be between 1 and 365"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	forecast, err := h.service.Forecast(c.Request().Context(), tenantID, productID, days)
+	if err != nil {
+		return demandError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, DemandForecastResponse{
+		ProductDemandResponse: toProductDemandResponse(forecast.Demand),
+		ForecastFrom:          forecast.From.Format("2006-01-02"),
+		ForecastDays:          forecast.Days,
+		Forecast:              forecast.Quantity,
+	})
+}
+
+// @Summary Get reorder suggestions
+// @Description Products whose available stock will not last the supplier lead time plus the cover period at the
+// @Description forecast rate, with the quantity to order, soonest to run out first
+// @Tags demand
+// @Produce json
+// @Param leadDays query int false "Supplier lead time in days" default(7)
+// @Param coverDays query int false "Days the order should last after it arrives" default(30)
+// @Param limit query int false "Limit" default(50)
+// @Success 200 {array} ReorderSuggestionResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/demand/reorder-suggestions [get]
+// @Security BearerAuth
+func (h *DemandHandler) ReorderSuggestions(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	query := domain.ReorderQuery{LeadDays: 7, CoverDays: 30, Limit: 50}
+	if raw := c.QueryParam("leadDays"); raw != "" {
+		query.LeadDays, _ = strconv.Atoi(raw)
+	}
+	if raw := c.QueryParam("coverDays"); raw != "" {
+		query.CoverDays, _ = strconv.Atoi(raw)
+	}
+	if limit, _ := strconv.Atoi(c.QueryParam("limit")); limit > 0 {
+		query.Limit = limit
+	}
+
+	suggestions, err := h.service.ReorderSuggestions(c.Request().Context(), tenantID, query)
+	if err != nil {
+		return demandError(c, err)
+	}
+
+	responses := make([]ReorderSuggestionResponse, len(suggestions))
+	for i, s := range suggestions {
+		responses[i] = ReorderSuggestionResponse{
+			ProductID:    s.ProductID.String(),
+			ProductCode:  s.ProductCode,
+			ProductName:  s.ProductName,
+			Unit:         s.Unit,
+			BaseDaily:    s.BaseDaily,
+			Forecast:     s.Forecast,
+			Available:    s.Available,
+			DaysOfStock:  s.DaysOfStock,
+			SuggestedQty: s.SuggestedQty,
+		}
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Recompute demand statistics
+// @Description Rebuild the tenant's statistics now instead of waiting for the nightly job
+// @Tags demand
+// @Produce json
+// @Success 200 {object} RecomputeDemandResponse
+// @Router /api/v1/demand/recompute [post]
+// @Security BearerAuth
+func (h *DemandHandler) Recompute(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	products, err := h.service.Recompute(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, RecomputeDemandResponse{Products: products})
+}
+
+// demandError maps demand errors to HTTP statuses
+func demandError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, domain.ErrDemandNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidReorderQuery):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// toProductDemandResponse converts domain.ProductDemand to ProductDemandResponse
+func toProductDemandResponse(demand *domain.ProductDemand) ProductDemandResponse {
+	resp := ProductDemandResponse{
+		ProductID:   demand.ProductID.String(),
+		ProductCode: demand.ProductCode,
+		ProductName: demand.ProductName,
+		Unit:        demand.Unit,
+		Sold30:      demand.Sold30,
+		Sold90:      demand.Sold90,
+		AvgDaily30:  demand.AvgDaily30,
+		AvgDaily90:  demand.AvgDaily90,
+		BaseDaily:   demand.BaseDaily,
+		Seasonality: make([]SeasonalityResponse, len(demand.Seasonality)),
+		ComputedAt:  demand.ComputedAt.Format(time.RFC3339),
+	}
+	for i, index := range demand.Seasonality {
+		month := utils.NepaliDate{Month: i + 1}
+		resp.Seasonality[i] = SeasonalityResponse{Month: month.Month, Name: month.NepaliMonthName(), Index: index}
+	}
+	if demand.FirstSoldOn != nil {
+		firstSoldOn := demand.FirstSoldOn.Format("2006-01-02")
+		resp.FirstSoldOn = &firstSoldOn
+	}
+	if demand.LastSoldOn != nil {
+		lastSoldOn := demand.LastSoldOn.Format("2006-01-02")
+		resp.LastSoldOn = &lastSoldOn
+	}
+	return resp
+}
@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// GuardrailHandler handles MRP and discount guardrail HTTP requests
+type GuardrailHandler struct {
+	service service.GuardrailService
+}
+
+// NewGuardrailHandler creates a new guardrail handler
+func NewGuardrailHandler(service service.GuardrailService) *GuardrailHandler {
+	return &GuardrailHandler{service: service}
+}
+
+// SetDiscountLimitRequest represents the request to set a role's discount limit
+type SetDiscountLimitRequest struct {
+	MaxDiscountPercent float64 `json:"maxDiscountPercent" validate:"gte=0,lte=100"`
+}
+
+// DiscountLimitResponse represents a role's discount limit
+type DiscountLimitResponse struct {
+	Role               string  `json:"role"`
+	MaxDiscountPercent float64 `json:"maxDiscountPercent"`
+	UpdatedAt          string  `json:"updatedAt"`
+}
+
+// SaleCheckRequest represents a sale price to validate at the till
+type SaleCheckRequest struct {
+	ProductID  string  `json:"productId" validate:"required"`
+	SalePrice  float64 `json:"salePrice" validate:"gte=0"`
+	OverrideID *string `json:"overrideId,omitempty"`
+}
+
+// SaleCheckResponse represents the outcome of a sale price check
+type SaleCheckResponse struct {
+	Allowed            bool     `json:"allowed"`
+	Error              string   `json:"error,omitempty"`
+	ProductID          string   `json:"productId"`
+	ListPrice          float64  `json:"listPrice"`
+	SalePrice          float64  `json:"salePrice"`
+	PriceInclTax       float64  `json:"priceInclTax"`
+	MRP                *float64 `json:"mrp,omitempty"`
+	DiscountPercent    float64  `json:"discountPercent"`
+	MaxDiscountPercent *float64 `json:"maxDiscountPercent,omitempty"`
+	OverrideID         *string  `json:"overrideId,omitempty"`
+}
+
+// ApproveOverrideRequest represents a manager approving a discount above the seller's limit
+type ApproveOverrideRequest struct {
+	ProductID   string  `json:"productId" validate:"required"`
+	SalePrice   float64 `json:"salePrice" validate:"gte=0"`
+	Reason      string  `json:"reason" validate:"required"`
+	RequestedBy *string `json:"requestedBy,omitempty"`
+}
+
+// DiscountOverrideResponse represents an approved discount override
+type DiscountOverrideResponse struct {
+	ID              string  `json:"id"`
+	ProductID       string  `json:"productId"`
+	ListPrice       float64 `json:"listPrice"`
+	SalePrice       float64 `json:"salePrice"`
+	DiscountPercent float64 `json:"discountPercent"`
+	Reason          string  `json:"reason"`
+	RequestedBy     *string `json:"requestedBy,omitempty"`
+	ApprovedBy      *string `json:"approvedBy,omitempty"`
+	ApproverRole    string  `json:"approverRole"`
+	ExpiresAt       string  `json:"expiresAt"`
+	UsedAt          *string `json:"usedAt,omitempty"`
+	CreatedAt       string  `json:"createdAt"`
+}
+
+// @Summary List discount limits
+// @Description Get the maximum discount percentage per role; roles not listed are unrestricted
+// @Tags pricing
+// @Produce json
+// @Success 200 {array} DiscountLimitResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/pricing/discount-limits [get]
+// @Security BearerAuth
+func (h *GuardrailHandler) ListDiscountLimits(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limits, err := h.service.ListDiscountLimits(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]DiscountLimitResponse, len(limits))
+	for i, limit := range limits {
+		responses[i] = toDiscountLimitResponse(limit)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Set a discount limit
+// @Description Set the maximum discount percentage a role may give at sale time
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param role path string true "Role"
+// @Param limit body SetDiscountLimitRequest true "Discount limit"
+// @Success 200 {object} DiscountLimitResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/pricing/discount-limits/{role} [put]
+// @Security BearerAuth
+func (h *GuardrailHandler) SetDiscountLimit(c echo.Context) error {
+	var req SetDiscountLimitRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, err := h.service.SetDiscountLimit(c.Request().Context(), tenantID, c.Param("role"), req.MaxDiscountPercent)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toDiscountLimitResponse(limit))
+}
+
+// @Summary Remove a discount limit
+// @Description Remove a role's discount limit so the role is unrestricted
+// @Tags pricing
+// @Param role path string true "Role"
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/pricing/discount-limits/{role} [delete]
+// @Security BearerAuth
+func (h *GuardrailHandler) DeleteDiscountLimit(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.DeleteDiscountLimit(c.Request().Context(), tenantID, c.Param("role")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary Check a sale price
+// @Description Validate a sale price against the MRP and the caller's role discount limit.
+// @Description Passing an approved overrideId redeems it; the sale price must not be below the approved price.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param check body SaleCheckRequest true "Sale price"
+// @Success 200 {object} SaleCheckResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} SaleCheckResponse
+// @Router /api/v1/pricing/sale-check [post]
+// @Security BearerAuth
+func (h *GuardrailHandler) CheckSale(c echo.Context) error {
+	var req SaleCheckRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	productID, err := uuid.Parse(req.ProductID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+	overrideID, err := parseOptionalID(req.OverrideID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid override ID"})
+	}
+
+	check, err := h.service.CheckSale(c.Request().Context(), tenantID, productID, req.SalePrice, role, overrideID)
+	if check == nil {
+		return guardrailError(c, err)
+	}
+
+	// Guardrail violations still return the figures so the till can show why
+	resp := toSaleCheckResponse(check)
+	if err != nil {
+		if !isGuardrailViolation(err) {
+			return guardrailError(c, err)
+		}
+		resp.Error = err.Error()
+		return c.JSON(http.StatusUnprocessableEntity, resp)
+	}
+	resp.Allowed = true
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Approve a discount override
+// @Description Approve a one-time sale below the seller's discount limit. The caller is the approver
+// @Description and their own role limit must cover the discount. MRP cannot be overridden.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param override body ApproveOverrideRequest true "Override"
+// @Success 201 {object} DiscountOverrideResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/pricing/discount-overrides [post]
+// @Security BearerAuth
+func (h *GuardrailHandler) ApproveOverride(c echo.Context) error {
+	var req ApproveOverrideRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	var approvedBy *uuid.UUID
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		approvedBy = &userID
+	}
+
+	productID, err := uuid.Parse(req.ProductID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+	requestedBy, err := parseOptionalID(req.RequestedBy)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid requester ID"})
+	}
+
+	override, err := h.service.ApproveOverride(c.Request().Context(), tenantID, productID, req.SalePrice, req.Reason, requestedBy, approvedBy, role)
+	if err != nil {
+		return guardrailError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toDiscountOverrideResponse(override))
+}
+
+// @Summary List discount overrides
+// @Description Get the tenant's discount override history with reasons, newest first
+// @Tags pricing
+// @Produce json
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} DiscountOverrideResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/pricing/discount-overrides [get]
+// @Security BearerAuth
+func (h *GuardrailHandler) ListOverrides(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	overrides, err := h.service.ListOverrides(c.Request().Context(), tenantID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]DiscountOverrideResponse, len(overrides))
+	for i, override := range overrides {
+		responses[i] = toDiscountOverrideResponse(override)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// isGuardrailViolation reports whether err is a sale being refused rather than a failure
+func isGuardrailViolation(err error) bool {
+	return errors.Is(err, domain.ErrAboveMRP) ||
+		errors.Is(err, domain.ErrDiscountLimitExceeded) ||
+		errors.Is(err, domain.ErrInvalidDiscountOverride)
+}
+
+// guardrailError maps guardrail domain errors to HTTP responses
+func guardrailError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrAboveMRP), errors.Is(err, domain.ErrOverrideReasonRequired):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrDiscountLimitExceeded):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidDiscountOverride):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// toDiscountLimitResponse converts domain.DiscountLimit to DiscountLimitResponse
+func toDiscountLimitResponse(limit *domain.DiscountLimit) DiscountLimitResponse {
+	return DiscountLimitResponse{
+		Role:               limit.Role,
+		MaxDiscountPercent: limit.MaxDiscountPercent,
+		UpdatedAt:          limit.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// toSaleCheckResponse converts domain.SaleCheck to SaleCheckResponse
+func toSaleCheckResponse(check *domain.SaleCheck) SaleCheckResponse {
+	return SaleCheckResponse{
+		ProductID:          check.ProductID.String(),
+		ListPrice:          check.ListPrice,
+		SalePrice:          check.SalePrice,
+		PriceInclTax:       check.PriceInclTax,
+		MRP:                check.MRP,
+		DiscountPercent:    check.DiscountPercent,
+		MaxDiscountPercent: check.MaxDiscountPercent,
+		OverrideID:         optionalIDString(check.OverrideID),
+	}
+}
+
+// toDiscountOverrideResponse converts domain.DiscountOverride to DiscountOverrideResponse
+func toDiscountOverrideResponse(override *domain.DiscountOverride) DiscountOverrideResponse {
+	resp := DiscountOverrideResponse{
+		ID:              override.ID.String(),
+		ProductID:       override.ProductID.String(),
+		ListPrice:       override.ListPrice,
+		SalePrice:       override.SalePrice,
+		DiscountPercent: override.DiscountPercent,
+		Reason:          override.Reason,
+		RequestedBy:     optionalIDString(override.RequestedBy),
+		ApprovedBy:      optionalIDString(override.ApprovedBy),
+		ApproverRole:    override.ApproverRole,
+		ExpiresAt:       override.ExpiresAt.Format(time.RFC3339),
+		CreatedAt:       override.CreatedAt.Format(time.RFC3339),
+	}
+	if override.UsedAt != nil {
+		usedAt := override.UsedAt.Format(time.RFC3339)
+		resp.UsedAt = &usedAt
+	}
+	return resp
+}
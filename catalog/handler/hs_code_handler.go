@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/labstack/echo/v4"
+)
+
+// HSCodeHandler handles HS code lookup HTTP requests
+type HSCodeHandler struct {
+	service service.HSCodeService
+}
+
+// NewHSCodeHandler creates a new HS code handler
+func NewHSCodeHandler(service service.HSCodeService) *HSCodeHandler {
+	return &HSCodeHandler{service: service}
+}
+
+// HSCodeResponse represents an HS code list entry
+type HSCodeResponse struct {
+	Code        string `json:"code"`
+	Formatted   string `json:"formatted"`
+	Description string `json:"description"`
+	Chapter     string `json:"chapter"`
+	Heading     string `json:"heading"`
+}
+
+// @Summary Search HS codes
+// @Description Search the built-in HS code list by code prefix or description
+// @Tags hs-codes
+// @Produce json
+// @Param q query string true "Code prefix or description"
+// @Param limit query int false "Limit" default(20)
+// @Success 200 {array} HSCodeResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/hs-codes [get]
+// @Security BearerAuth
+func (h *HSCodeHandler) Search(c echo.Context) error {
+	query := c.QueryParam("q")
+	if query == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Query parameter 'q' is required"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+
+	codes, err := h.service.Search(c.Request().Context(), query, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]HSCodeResponse, len(codes))
+	for i, code := range codes {
+		responses[i] = toHSCodeResponse(code)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Validate HS code
+// @Description Validate an HS code's format and return its built-in description if known
+// @Tags hs-codes
+// @Produce json
+// @Param code path string true "HS code"
+// @Success 200 {object} HSCodeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/hs-codes/{code} [get]
+// @Security BearerAuth
+func (h *HSCodeHandler) Lookup(c echo.Context) error {
+	code, err := h.service.Lookup(c.Request().Context(), c.Param("code"))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidHSCode) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if code == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "HS code is valid but not in the built-in list"})
+	}
+
+	return c.JSON(http.StatusOK, toHSCodeResponse(code))
+}
+
+// toHSCodeResponse converts domain.HSCode to HSCodeResponse
+func toHSCodeResponse(code *domain.HSCode) HSCodeResponse {
+	return HSCodeResponse{
+		Code:        code.Code,
+		Formatted:   domain.FormatHSCode(code.Code),
+		Description: code.Description,
+		Chapter:     code.Chapter,
+		Heading:     code.Heading,
+	}
+}
@@ -0,0 +1,372 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// LocalizationHandler handles translation and document locale HTTP requests
+type LocalizationHandler struct {
+	service service.LocalizationService
+}
+
+// NewLocalizationHandler creates a new localization handler
+func NewLocalizationHandler(service service.LocalizationService) *LocalizationHandler {
+	return &LocalizationHandler{service: service}
+}
+
+// SaveTranslationRequest represents a product or category name in another language
+type SaveTranslationRequest struct {
+	Name        string  `json:"name" validate:"required,max=255"`
+	Description *string `json:"description,omitempty"` // Omit to keep the catalog's own description
+}
+
+// TranslationResponse represents a translation
+type TranslationResponse struct {
+	EntityType  string  `json:"entityType"`
+	EntityID    string  `json:"entityId"`
+	Locale      string  `json:"locale"`
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+	UpdatedAt   string  `json:"updatedAt"`
+}
+
+// SetDocumentLocaleRequest represents the language a document is printed in
+type SetDocumentLocaleRequest struct {
+	Locale string `json:"locale" validate:"required"`
+}
+
+// DocumentLocaleResponse represents a document type's language
+type DocumentLocaleResponse struct {
+	DocumentType string `json:"documentType"`
+	Locale       string `json:"locale"`
+	UpdatedAt    string `json:"updatedAt"`
+}
+
+// @Summary List product translations
+// @Description Get a product's name and description in each language it is translated into
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {array} TranslationResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/translations [get]
+// @Security BearerAuth
+func (h *LocalizationHandler) ListProductTranslations(c echo.Context) error {
+	return h.listTranslations(c, domain.TranslationProduct)
+}
+
+// @Summary Save product translation
+// @Description Set a product's name and description in a language, e.g. ne. Reads that ask for the
+// @Description language with the locale parameter or Accept-Language get these instead of the catalog's own.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param locale path string true "Language code"
+// @Param translation body SaveTranslationRequest true "Translated text"
+// @Success 200 {object} TranslationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/translations/{locale} [put]
+// @Security BearerAuth
+func (h *LocalizationHandler) SaveProductTranslation(c echo.Context) error {
+	return h.saveTranslation(c, domain.TranslationProduct)
+}
+
+// @Summary Delete product translation
+// @Description Remove a product's translation into a language
+// @Tags products
+// @Param id path string true "Product ID"
+// @Param locale path string true "Language code"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/translations/{locale} [delete]
+// @Security BearerAuth
+func (h *LocalizationHandler) DeleteProductTranslation(c echo.Context) error {
+	return h.deleteTranslation(c, domain.TranslationProduct)
+}
+
+// @Summary List category translations
+// @Description Get a category's name and description in each language it is translated into
+// @Tags categories
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {array} TranslationResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/categories/{id}/translations [get]
+// @Security BearerAuth
+func (h *LocalizationHandler) ListCategoryTranslations(c echo.Context) error {
+	return h.listTranslations(c, domain.TranslationCategory)
+}
+
+// @Summary Save category translation
+// @Description Set a category's name and description in a language, e.g. ne
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param locale path string true "Language code"
+// @Param translation body SaveTranslationRequest true "Translated text"
+// @Success 200 {object} TranslationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/categories/{id}/translations/{locale} [put]
+// @Security BearerAuth
+func (h *LocalizationHandler) SaveCategoryTranslation(c echo.Context) error {
+	return h.saveTranslation(c, domain.TranslationCategory)
+}
+
+// @Summary Delete category translation
+// @Description Remove a category's translation into a language
+// @Tags categories
+// @Param id path string true "Category ID"
+// @Param locale path string true "Language code"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/categories/{id}/translations/{locale} [delete]
+// @Security BearerAuth
+func (h *LocalizationHandler) DeleteCategoryTranslation(c echo.Context) error {
+	return h.deleteTranslation(c, domain.TranslationCategory)
+}
+
+func (h *LocalizationHandler) listTranslations(c echo.Context, entityType domain.TranslationEntity) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	translations, err := h.service.ListTranslations(c.Request().Context(), tenantID, entityType, id)
+	if err != nil {
+		return translationError(c, err)
+	}
+
+	responses := make([]TranslationResponse, len(translations))
+	for i, t := range translations {
+		responses[i] = toTranslationResponse(t)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+func (h *LocalizationHandler) saveTranslation(c echo.Context, entityType domain.TranslationEntity) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	var req SaveTranslationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	translation, err := domain.NewTranslation(tenantID, entityType, id, c.Param("locale"), req.Name, req.Description)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.service.SaveTranslation(c.Request().Context(), translation); err != nil {
+		return translationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toTranslationResponse(translation))
+}
+
+func (h *LocalizationHandler) deleteTranslation(c echo.Context, entityType domain.TranslationEntity) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.DeleteTranslation(c.Request().Context(), tenantID, entityType, id, c.Param("locale")); err != nil {
+		return translationError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// translationError maps translation errors to HTTP statuses
+func translationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidLocale):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+	case errors.Is(err, domain.ErrTranslationNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// @Summary List document locales
+// @Description Get the language each document type prints product names in. Document types
+// @Description without one print the catalog's own names.
+// @Tags products
+// @Produce json
+// @Success 200 {array} DocumentLocaleResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/document-locales [get]
+// @Security BearerAuth
+func (h *LocalizationHandler) ListDocumentLocales(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	settings, err := h.service.ListDocumentLocales(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]DocumentLocaleResponse, len(settings))
+	for i, s := range settings {
+		responses[i] = toDocumentLocaleResponse(s)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Set document locale
+// @Description Print a document type's product names in a language: sales_invoice or purchase_order.
+// @Description Products without a translation in it keep the catalog's own name.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param documentType path string true "sales_invoice or purchase_order"
+// @Param locale body SetDocumentLocaleRequest true "Language code"
+// @Success 200 {object} DocumentLocaleResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/document-locales/{documentType} [put]
+// @Security BearerAuth
+func (h *LocalizationHandler) SetDocumentLocale(c echo.Context) error {
+	var req SetDocumentLocaleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	setting, err := domain.NewDocumentLocale(tenantID, c.Param("documentType"), req.Locale)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.service.SaveDocumentLocale(c.Request().Context(), setting); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toDocumentLocaleResponse(setting))
+}
+
+// @Summary Clear document locale
+// @Description Print a document type's product names in the catalog's own language again
+// @Tags products
+// @Param documentType path string true "sales_invoice or purchase_order"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/document-locales/{documentType} [delete]
+// @Security BearerAuth
+func (h *LocalizationHandler) DeleteDocumentLocale(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.DeleteDocumentLocale(c.Request().Context(), tenantID, c.Param("documentType")); err != nil {
+		if errors.Is(err, domain.ErrDocumentLocaleNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// requestLocales returns the languages the client asked to read names in, from the
+// locale query parameter or Accept-Language
+func requestLocales(c echo.Context) []string {
+	c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+	return domain.PreferredLocales(c.QueryParam("locale"), c.Request().Header.Get("Accept-Language"))
+}
+
+// localize shows products in the requested language. A failed lookup leaves the
+// catalog's own names rather than failing the read.
+func (h *ProductHandler) localize(c echo.Context, products ...*domain.Product) {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	locales := requestLocales(c)
+	if !ok || len(locales) == 0 || len(products) == 0 {
+		return
+	}
+	if err := h.localization.LocalizeProducts(c.Request().Context(), tenantID, locales, products...); err != nil {
+		logger.Log.Warn("Failed to localize products: " + err.Error())
+	}
+}
+
+// localize shows categories in the requested language, like ProductHandler.localize
+func (h *CategoryHandler) localize(c echo.Context, categories ...*domain.Category) {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	locales := requestLocales(c)
+	if !ok || len(locales) == 0 || len(categories) == 0 {
+		return
+	}
+	if err := h.localization.LocalizeCategories(c.Request().Context(), tenantID, locales, categories...); err != nil {
+		logger.Log.Warn("Failed to localize categories: " + err.Error())
+	}
+}
+
+// toTranslationResponse converts domain.Translation to TranslationResponse
+func toTranslationResponse(t *domain.Translation) TranslationResponse {
+	return TranslationResponse{
+		EntityType:  string(t.EntityType),
+		EntityID:    t.EntityID.String(),
+		Locale:      t.Locale,
+		Name:        t.Name,
+		Description: t.Description,
+		UpdatedAt:   t.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// toDocumentLocaleResponse converts domain.DocumentLocale to DocumentLocaleResponse
+func toDocumentLocaleResponse(s *domain.DocumentLocale) DocumentLocaleResponse {
+	return DocumentLocaleResponse{
+		DocumentType: s.DocumentType,
+		Locale:       s.Locale,
+		UpdatedAt:    s.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,489 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// PricingHandler handles markup rule and repricing HTTP requests
+type PricingHandler struct {
+	service service.PricingService
+}
+
+// NewPricingHandler creates a new pricing handler
+func NewPricingHandler(service service.PricingService) *PricingHandler {
+	return &PricingHandler{service: service}
+}
+
+// CreateMarkupRuleRequest represents the request to create a markup rule
+type CreateMarkupRuleRequest struct {
+	ProductID       *string `json:"productId,omitempty"`
+	CategoryID      *string `json:"categoryId,omitempty"`
+	MarkupPercent   float64 `json:"markupPercent" validate:"gte=0,lte=1000"`
+	RoundTo         float64 `json:"roundTo" validate:"gte=0"`
+	RequireApproval bool    `json:"requireApproval"`
+}
+
+// UpdateMarkupRuleRequest represents the request to update a markup rule
+type UpdateMarkupRuleRequest struct {
+	MarkupPercent   float64 `json:"markupPercent" validate:"gte=0,lte=1000"`
+	RoundTo         float64 `json:"roundTo" validate:"gte=0"`
+	RequireApproval bool    `json:"requireApproval"`
+	IsActive        bool    `json:"isActive"`
+}
+
+// MarkupRuleResponse represents the markup rule response
+type MarkupRuleResponse struct {
+	ID              string  `json:"id"`
+	ProductID       *string `json:"productId,omitempty"`
+	CategoryID      *string `json:"categoryId,omitempty"`
+	MarkupPercent   float64 `json:"markupPercent"`
+	RoundTo         float64 `json:"roundTo"`
+	RequireApproval bool    `json:"requireApproval"`
+	IsActive        bool    `json:"isActive"`
+	UpdatedAt       string  `json:"updatedAt"`
+}
+
+// PriceChangeResponse represents a recomputed price
+type PriceChangeResponse struct {
+	ID         string  `json:"id"`
+	ProductID  string  `json:"productId"`
+	RuleID     string  `json:"ruleId"`
+	RunID      *string `json:"runId,omitempty"`
+	Source     string  `json:"source"`
+	Status     string  `json:"status"`
+	CostPrice  float64 `json:"costPrice"`
+	OldPrice   float64 `json:"oldPrice"`
+	NewPrice   float64 `json:"newPrice"`
+	ReviewedBy *string `json:"reviewedBy,omitempty"`
+	ReviewedAt *string `json:"reviewedAt,omitempty"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+// RepricingRunResponse represents a repricing run and its change report
+type RepricingRunResponse struct {
+	ID              string                `json:"id"`
+	Source          string                `json:"source"`
+	StartedBy       *string               `json:"startedBy,omitempty"`
+	ProductsChecked int                   `json:"productsChecked"`
+	PricesApplied   int                   `json:"pricesApplied"`
+	PendingApproval int                   `json:"pendingApproval"`
+	StartedAt       string                `json:"startedAt"`
+	FinishedAt      *string               `json:"finishedAt,omitempty"`
+	Changes         []PriceChangeResponse `json:"changes,omitempty"`
+}
+
+// @Summary Create a markup rule
+// @Description Price a product or category at cost + markup%. Product rules win over category rules.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param rule body CreateMarkupRuleRequest true "Markup rule"
+// @Success 201 {object} MarkupRuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/pricing/markup-rules [post]
+// @Security BearerAuth
+func (h *PricingHandler) CreateRule(c echo.Context) error {
+	var req CreateMarkupRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	productID, err := parseOptionalID(req.ProductID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+	categoryID, err := parseOptionalID(req.CategoryID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid category ID"})
+	}
+
+	rule := domain.NewMarkupRule(tenantID, productID, categoryID, req.MarkupPercent, req.RoundTo, req.RequireApproval)
+	if err := h.service.CreateRule(c.Request().Context(), rule); err != nil {
+		return pricingError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toMarkupRuleResponse(rule))
+}
+
+// @Summary List markup rules
+// @Description Get the tenant's markup rules
+// @Tags pricing
+// @Produce json
+// @Success 200 {array} MarkupRuleResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/pricing/markup-rules [get]
+// @Security BearerAuth
+func (h *PricingHandler) ListRules(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rules, err := h.service.ListRules(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]MarkupRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = toMarkupRuleResponse(rule)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Update a markup rule
+// @Description Change a rule's markup, rounding, approval requirement or active flag. Prices follow on the next cost change or repricing run.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param id path string true "Markup rule ID"
+// @Param rule body UpdateMarkupRuleRequest true "Markup rule"
+// @Success 200 {object} MarkupRuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/pricing/markup-rules/{id} [put]
+// @Security BearerAuth
+func (h *PricingHandler) UpdateRule(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	var req UpdateMarkupRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rule, err := h.service.GetRule(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return pricingError(c, err)
+	}
+
+	rule.MarkupPercent = req.MarkupPercent
+	rule.RoundTo = req.RoundTo
+	rule.RequireApproval = req.RequireApproval
+	rule.IsActive = req.IsActive
+
+	if err := h.service.UpdateRule(c.Request().Context(), rule); err != nil {
+		return pricingError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toMarkupRuleResponse(rule))
+}
+
+// @Summary Delete a markup rule
+// @Description Delete a markup rule; current selling prices are kept
+// @Tags pricing
+// @Param id path string true "Markup rule ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/pricing/markup-rules/{id} [delete]
+// @Security BearerAuth
+func (h *PricingHandler) DeleteRule(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.DeleteRule(c.Request().Context(), tenantID, id); err != nil {
+		return pricingError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary List price changes
+// @Description Get prices recomputed from markup rules; use status=pending for the approval queue
+// @Tags pricing
+// @Produce json
+// @Param status query string false "pending, applied, rejected or superseded"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} PriceChangeResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/pricing/changes [get]
+// @Security BearerAuth
+func (h *PricingHandler) ListChanges(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	status := domain.PriceChangeStatus(c.QueryParam("status"))
+	changes, err := h.service.ListChanges(c.Request().Context(), tenantID, status, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toPriceChangeResponses(changes))
+}
+
+// @Summary Approve a price change
+// @Description Apply a pending recomputed price to the product
+// @Tags pricing
+// @Produce json
+// @Param id path string true "Price change ID"
+// @Success 200 {object} PriceChangeResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/pricing/changes/{id}/approve [post]
+// @Security BearerAuth
+func (h *PricingHandler) ApproveChange(c echo.Context) error {
+	return h.reviewChange(c, h.service.ApproveChange)
+}
+
+// @Summary Reject a price change
+// @Description Discard a pending recomputed price; the product keeps its current price
+// @Tags pricing
+// @Produce json
+// @Param id path string true "Price change ID"
+// @Success 200 {object} PriceChangeResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/pricing/changes/{id}/reject [post]
+// @Security BearerAuth
+func (h *PricingHandler) RejectChange(c echo.Context) error {
+	return h.reviewChange(c, h.service.RejectChange)
+}
+
+func (h *PricingHandler) reviewChange(c echo.Context, review func(ctx context.Context, tenantID, id uuid.UUID, reviewedBy *uuid.UUID) (*domain.PriceChange, error)) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var reviewedBy *uuid.UUID
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		reviewedBy = &userID
+	}
+
+	change, err := review(c.Request().Context(), tenantID, id, reviewedBy)
+	if err != nil {
+		return pricingError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toPriceChangeResponse(change))
+}
+
+// @Summary Run repricing
+// @Description Recompute every active product's price from its markup rule now and return the change report
+// @Tags pricing
+// @Produce json
+// @Success 200 {object} RepricingRunResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/pricing/repricing-runs [post]
+// @Security BearerAuth
+func (h *PricingHandler) RunRepricing(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var startedBy *uuid.UUID
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		startedBy = &userID
+	}
+
+	run, err := h.service.RunRepricing(c.Request().Context(), tenantID, domain.PriceChangeSourceManual, startedBy)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toRepricingRunResponse(run))
+}
+
+// @Summary List repricing runs
+// @Description Get nightly and manual repricing runs with their totals, newest first
+// @Tags pricing
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} RepricingRunResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/pricing/repricing-runs [get]
+// @Security BearerAuth
+func (h *PricingHandler) ListRuns(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	runs, err := h.service.ListRuns(c.Request().Context(), tenantID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]RepricingRunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = toRepricingRunResponse(run)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Get repricing run report
+// @Description Get a repricing run with every price it changed or queued for approval
+// @Tags pricing
+// @Produce json
+// @Param id path string true "Repricing run ID"
+// @Success 200 {object} RepricingRunResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/pricing/repricing-runs/{id} [get]
+// @Security BearerAuth
+func (h *PricingHandler) GetRun(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	run, err := h.service.GetRun(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toRepricingRunResponse(run))
+}
+
+// pricingError maps pricing errors to HTTP responses
+func pricingError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidMarkupRule), errors.Is(err, domain.ErrInvalidBulkPriceUpdate), errors.Is(err, domain.ErrBulkPriceTooMany):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrMarkupRuleNotFound), errors.Is(err, domain.ErrPriceChangeNotFound), errors.Is(err, domain.ErrBulkPriceUpdateNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrPriceChangeNotPending), errors.Is(err, domain.ErrBulkPriceConflict), errors.Is(err, domain.ErrBulkPriceRolledBack):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrBulkPriceRollbackExpired):
+		return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// toMarkupRuleResponse converts domain.MarkupRule to MarkupRuleResponse
+func toMarkupRuleResponse(rule *domain.MarkupRule) MarkupRuleResponse {
+	return MarkupRuleResponse{
+		ID:              rule.ID.String(),
+		ProductID:       optionalIDString(rule.ProductID),
+		CategoryID:      optionalIDString(rule.CategoryID),
+		MarkupPercent:   rule.MarkupPercent,
+		RoundTo:         rule.RoundTo,
+		RequireApproval: rule.RequireApproval,
+		IsActive:        rule.IsActive,
+		UpdatedAt:       rule.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// toPriceChangeResponse converts domain.PriceChange to PriceChangeResponse
+func toPriceChangeResponse(change *domain.PriceChange) PriceChangeResponse {
+	resp := PriceChangeResponse{
+		ID:         change.ID.String(),
+		ProductID:  change.ProductID.String(),
+		RuleID:     change.RuleID.String(),
+		RunID:      optionalIDString(change.RunID),
+		Source:     string(change.Source),
+		Status:     string(change.Status),
+		CostPrice:  change.CostPrice,
+		OldPrice:   change.OldPrice,
+		NewPrice:   change.NewPrice,
+		ReviewedBy: optionalIDString(change.ReviewedBy),
+		CreatedAt:  change.CreatedAt.Format(time.RFC3339),
+	}
+	if change.ReviewedAt != nil {
+		reviewedAt := change.ReviewedAt.Format(time.RFC3339)
+		resp.ReviewedAt = &reviewedAt
+	}
+	return resp
+}
+
+func toPriceChangeResponses(changes []*domain.PriceChange) []PriceChangeResponse {
+	responses := make([]PriceChangeResponse, len(changes))
+	for i, change := range changes {
+		responses[i] = toPriceChangeResponse(change)
+	}
+	return responses
+}
+
+// toRepricingRunResponse converts domain.RepricingRun to RepricingRunResponse
+func toRepricingRunResponse(run *domain.RepricingRun) RepricingRunResponse {
+	resp := RepricingRunResponse{
+		ID:              run.ID.String(),
+		Source:          string(run.Source),
+		StartedBy:       optionalIDString(run.StartedBy),
+		ProductsChecked: run.ProductsChecked,
+		PricesApplied:   run.PricesApplied,
+		PendingApproval: run.PendingApproval,
+		StartedAt:       run.StartedAt.Format(time.RFC3339),
+		Changes:         toPriceChangeResponses(run.Changes),
+	}
+	if run.FinishedAt != nil {
+		finishedAt := run.FinishedAt.Format(time.RFC3339)
+		resp.FinishedAt = &finishedAt
+	}
+	return resp
+}
+
+func optionalIDString(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
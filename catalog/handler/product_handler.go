@@ -1,24 +1,33 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/aceextension/catalog/domain"
 	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/dataimport"
 	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/export"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/core/stream"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
 // ProductHandler handles product HTTP requests
 type ProductHandler struct {
-	service service.ProductService
+	service      service.ProductService
+	quickPicks   service.QuickPickService
+	barcodes     service.BarcodeService
+	localization service.LocalizationService
 }
 
 // NewProductHandler creates a new product handler
-func NewProductHandler(service service.ProductService) *ProductHandler {
-	return &ProductHandler{service: service}
+func NewProductHandler(service service.ProductService, quickPicks service.QuickPickService, barcodes service.BarcodeService, localization service.LocalizationService) *ProductHandler {
+	return &ProductHandler{service: service, quickPicks: quickPicks, barcodes: barcodes, localization: localization}
 }
 
 // CreateProductRequest represents the request to create a product
@@ -30,8 +39,10 @@ type CreateProductRequest struct {
 	SellingPrice     float64                `json:"sellingPrice" validate:"required,gt=0"`
 	MRP              *float64               `json:"mrp,omitempty"`
 	TaxRate          float64                `json:"taxRate" validate:"gte=0,lte=100"`
+	TaxGroupID       *string                `json:"taxGroupId,omitempty"`
 	SKU              *string                `json:"sku,omitempty"`
 	Barcode          *string                `json:"barcode,omitempty"`
+	HSCode           *string                `json:"hsCode,omitempty"`
 	Unit             string                 `json:"unit" validate:"required"`
 	CustomAttributes map[string]interface{} `json:"customAttributes,omitempty"`
 }
@@ -45,8 +56,10 @@ type UpdateProductRequest struct {
 	SellingPrice     float64                `json:"sellingPrice" validate:"required,gt=0"`
 	MRP              *float64               `json:"mrp,omitempty"`
 	TaxRate          float64                `json:"taxRate" validate:"gte=0,lte=100"`
+	TaxGroupID       *string                `json:"taxGroupId,omitempty"`
 	SKU              *string                `json:"sku,omitempty"`
 	Barcode          *string                `json:"barcode,omitempty"`
+	HSCode           *string                `json:"hsCode,omitempty"`
 	Unit             string                 `json:"unit" validate:"required"`
 	Status           string                 `json:"status" validate:"required,oneof=active inactive discontinued"`
 	CustomAttributes map[string]interface{} `json:"customAttributes,omitempty"`
@@ -64,16 +77,55 @@ type ProductResponse struct {
 	SellingPrice     float64                `json:"sellingPrice"`
 	MRP              *float64               `json:"mrp,omitempty"`
 	TaxRate          float64                `json:"taxRate"`
+	TaxGroupID       *string                `json:"taxGroupId,omitempty"`
 	SKU              *string                `json:"sku,omitempty"`
 	Barcode          *string                `json:"barcode,omitempty"`
+	HSCode           *string                `json:"hsCode,omitempty"`
 	Unit             string                 `json:"unit"`
 	Status           string                 `json:"status"`
 	IsActive         bool                   `json:"isActive"`
 	CustomAttributes map[string]interface{} `json:"customAttributes"`
+	Locale           string                 `json:"locale,omitempty"` // Language of name and description when translated
 	CreatedAt        string                 `json:"createdAt"`
 	UpdatedAt        string                 `json:"updatedAt"`
 }
 
+// BarcodeLookupResponse is a product resolved from a POS scan with the quantity to ring up
+type BarcodeLookupResponse struct {
+	ProductResponse
+	Quantity float64                 `json:"quantity"`
+	Amount   float64                 `json:"amount"`
+	Scale    *ScaleBarcodeResponse   `json:"scale,omitempty"`
+	Pack     *ProductBarcodeResponse `json:"pack,omitempty"`
+}
+
+// ScaleBarcodeResponse represents the parts of a scale-printed barcode
+type ScaleBarcodeResponse struct {
+	RuleID    string  `json:"ruleId"`
+	ItemCode  string  `json:"itemCode"`
+	ValueType string  `json:"valueType"`
+	Value     float64 `json:"value"`
+}
+
+// ProductBarcodeRequest represents a barcode printed on a pack of a product
+type ProductBarcodeRequest struct {
+	Barcode  string  `json:"barcode" validate:"required,max=100"`
+	Unit     string  `json:"unit" validate:"required"`
+	Quantity float64 `json:"quantity" validate:"gt=0"`
+}
+
+// SaveProductBarcodesRequest represents the request to set a product's pack barcodes
+type SaveProductBarcodesRequest struct {
+	Barcodes []ProductBarcodeRequest `json:"barcodes" validate:"dive"`
+}
+
+// ProductBarcodeResponse represents a pack barcode
+type ProductBarcodeResponse struct {
+	Barcode  string  `json:"barcode"`
+	Unit     string  `json:"unit"`
+	Quantity float64 `json:"quantity"`
+}
+
 // @Summary Create a new product
 // @Description Create a new product
 // @Tags products
@@ -110,28 +162,165 @@ func (h *ProductHandler) Create(c echo.Context) error {
 	product.CostPrice = req.CostPrice
 	product.MRP = req.MRP
 	product.TaxRate = req.TaxRate
+	product.TaxGroupID, err = parseOptionalID(req.TaxGroupID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tax group ID"})
+	}
 	product.SKU = req.SKU
 	product.Barcode = req.Barcode
+	product.HSCode = req.HSCode
 	product.Unit = req.Unit
 	if req.CustomAttributes != nil {
 		product.CustomAttributes = req.CustomAttributes
 	}
 
 	if err := h.service.Create(c.Request().Context(), product); err != nil {
+		if errors.Is(err, domain.ErrUnknownUnit) || errors.Is(err, domain.ErrUnknownTax) ||
+			errors.Is(err, domain.ErrInvalidHSCode) || errors.Is(err, domain.ErrAboveMRP) ||
+			errors.Is(err, domain.ErrCategoryNotFound) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, domain.ErrBarcodeInUse) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusCreated, toProductResponse(product))
 }
 
-// @Summary List products
-// @Description Get all products for the tenant
+// @Summary Import products
+// @Description Create products from a CSV or XLSX file (first sheet), one per row under a header row. Required columns: name, category (code or name), sellingPrice, unit; optional: costPrice, mrp, taxRate, sku, barcode, hsCode, description. Rows are checked like products added one by one and imported only when every row is valid; with dryRun nothing is created. The report lists each row's errors by row number, the header being row 1.
 // @Tags products
+// @Accept multipart/form-data
 // @Produce json
-// @Param limit query int false "Limit" default(10)
-// @Param offset query int false "Offset" default(0)
+// @Param file formData file true "CSV or XLSX file (max 10 MB, 5000 rows)"
+// @Param dryRun formData bool false "Only validate"
+// @Success 200 {object} dataimport.Report
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 402 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 422 {object} dataimport.Report
+// @Router /api/v1/products/import [post]
+// @Security BearerAuth
+func (h *ProductHandler) Import(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required"})
+	}
+	table, err := dataimport.ReadUpload(fileHeader)
+	if err != nil {
+		return importFileError(c, err)
+	}
+	dryRun, _ := strconv.ParseBool(c.FormValue("dryRun"))
+
+	report, err := h.service.Import(c.Request().Context(), tenantID, table, dryRun)
+	if errors.Is(err, domain.ErrProductLimitReached) {
+		return productLimitError(c, err)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if !report.OK() {
+		return c.JSON(http.StatusUnprocessableEntity, report)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// productExportRow is a product with its category's code, which an import takes back
+type productExportRow struct {
+	product      *domain.Product
+	categoryCode string
+}
+
+// productExportColumns are named like the import's columns, so an exported file can be
+// edited and imported into another tenant
+var productExportColumns = []export.Column[productExportRow]{
+	{Name: "productCode", Value: func(r productExportRow) any { return r.product.ProductCode }},
+	{Name: "name", Value: func(r productExportRow) any { return r.product.Name }},
+	{Name: "category", Value: func(r productExportRow) any { return r.categoryCode }},
+	{Name: "sellingPrice", Value: func(r productExportRow) any { return r.product.SellingPrice }},
+	{Name: "unit", Value: func(r productExportRow) any { return r.product.Unit }},
+	{Name: "costPrice", Value: func(r productExportRow) any { return r.product.CostPrice }},
+	{Name: "mrp", Value: func(r productExportRow) any { return r.product.MRP }},
+	{Name: "taxRate", Value: func(r productExportRow) any { return r.product.TaxRate }},
+	{Name: "sku", Value: func(r productExportRow) any { return r.product.SKU }},
+	{Name: "barcode", Value: func(r productExportRow) any { return r.product.Barcode }},
+	{Name: "hsCode", Value: func(r productExportRow) any { return r.product.HSCode }},
+	{Name: "description", Value: func(r productExportRow) any { return r.product.Description }},
+	{Name: "status", Value: func(r productExportRow) any { return string(r.product.Status) }},
+	{Name: "isActive", Value: func(r productExportRow) any { return r.product.IsActive }},
+	{Name: "createdAt", Value: func(r productExportRow) any { return r.product.CreatedAt }},
+	{Name: "updatedAt", Value: func(r productExportRow) any { return r.product.UpdatedAt }},
+}
+
+// @Summary Export products
+// @Description Download every product for the current tenant, streamed as it is read: CSV with format=csv or an
+// @Description Excel workbook with format=xlsx, with columns named like the import's; otherwise a JSON array, or
+// @Description NDJSON with format=ndjson. A failed CSV export ends with a "#error" row.
+// @Tags products
+// @Produce json
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv, xlsx, json (default) or ndjson"
 // @Success 200 {array} ProductResponse
 // @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/products/export [get]
+// @Security BearerAuth
+func (h *ProductHandler) Export(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if format, ok := export.Negotiate(c.Request()); ok {
+		out := export.NewWriter(c.Response(), format, "products", productExportColumns)
+		err := h.service.Export(c.Request().Context(), tenantID, func(product *domain.Product, categoryCode string) error {
+			return out.Write(productExportRow{product: product, categoryCode: categoryCode})
+		})
+		return export.Finish(c, out, err)
+	}
+	out := stream.NewWriter(c.Response(), stream.Negotiate(c.Request())).AsAttachment("products")
+	err := h.service.Export(c.Request().Context(), tenantID, func(product *domain.Product, _ string) error {
+		return out.Write(toProductResponse(product))
+	})
+	return export.Finish(c, out, err)
+}
+
+// importFileError maps an upload that cannot be read to its status
+func importFileError(c echo.Context, err error) error {
+	if errors.Is(err, dataimport.ErrFileTooLarge) {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+}
+
+// productLimitError answers a bulk creation that does not fit the plan like the
+// plan limit middleware does a single one
+func productLimitError(c echo.Context, err error) error {
+	return c.JSON(http.StatusPaymentRequired, map[string]string{"error": err.Error(), "code": "PLAN_LIMIT_EXCEEDED"})
+}
+
+// @Summary List products
+// @Description Get the tenant's products, newest first, a page at a time. Pass the nextCursor of a page as cursor to get the next; it is absent on the last page.
+// @Tags products
+// @Produce json
+// @Param limit query int false "Page size (max 200)" default(10)
+// @Param cursor query string false "nextCursor of the previous page"
+// @Param tags query string false "Only products with these comma-separated tags"
+// @Param tagMatch query string false "all (default) or any of the tags"
+// @Param locale query string false "Languages to show names in, best first (default Accept-Language)"
+// @Success 200 {object} db.Page[ProductResponse]
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
 // @Router /api/v1/products [get]
 // @Security BearerAuth
 func (h *ProductHandler) List(c echo.Context) error {
@@ -140,23 +329,26 @@ func (h *ProductHandler) List(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
 	}
 
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	if limit == 0 {
-		limit = 10
+	page, err := db.ParsePageRequest(c.QueryParam("cursor"), c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
-	offset, _ := strconv.Atoi(c.QueryParam("offset"))
 
-	products, err := h.service.GetByTenantID(c.Request().Context(), tenantID, limit, offset)
+	tagFilter, err := tagsDomain.ParseTagFilter(c.QueryParam("tags"), c.QueryParam("tagMatch"))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	responses := make([]ProductResponse, len(products))
-	for i, prod := range products {
-		responses[i] = toProductResponse(prod)
+	products, err := h.service.List(c.Request().Context(), tenantID, tagFilter, page)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, responses)
+	h.localize(c, products.Items...)
+	return c.JSON(http.StatusOK, db.MapPage(products, toProductResponse))
 }
 
 // @Summary Search products
@@ -166,7 +358,11 @@ func (h *ProductHandler) List(c echo.Context) error {
 // @Param q query string true "Search query"
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
+// @Param tags query string false "Only products with these comma-separated tags"
+// @Param tagMatch query string false "all (default) or any of the tags"
+// @Param locale query string false "Languages to show names in, best first (default Accept-Language)"
 // @Success 200 {array} ProductResponse
+// @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Router /api/v1/products/search [get]
 // @Security BearerAuth
@@ -183,11 +379,23 @@ func (h *ProductHandler) Search(c echo.Context) error {
 	}
 	offset, _ := strconv.Atoi(c.QueryParam("offset"))
 
-	products, err := h.service.Search(c.Request().Context(), tenantID, query, limit, offset)
+	tagFilter, err := tagsDomain.ParseTagFilter(c.QueryParam("tags"), c.QueryParam("tagMatch"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	var products []*domain.Product
+	if tagFilter != nil {
+		products, err = h.service.ListByTags(c.Request().Context(), tenantID, *tagFilter, query, limit, offset)
+	} else {
+		products, err = h.service.Search(c.Request().Context(), tenantID, query, limit, offset)
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	h.localize(c, products...)
+
 	responses := make([]ProductResponse, len(products))
 	for i, prod := range products {
 		responses[i] = toProductResponse(prod)
@@ -201,6 +409,7 @@ func (h *ProductHandler) Search(c echo.Context) error {
 // @Tags products
 // @Produce json
 // @Param id path string true "Product ID"
+// @Param locale query string false "Languages to show the name in, best first (default Accept-Language)"
 // @Success 200 {object} ProductResponse
 // @Failure 404 {object} map[string]string
 // @Router /api/v1/products/{id} [get]
@@ -211,11 +420,24 @@ func (h *ProductHandler) GetByID(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
 	}
 
-	product, err := h.service.GetByID(c.Request().Context(), id)
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	product, err := h.service.GetByID(c.Request().Context(), tenantID, id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
 	}
 
+	// Track for the user's recently viewed list; failures must not block the read
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		if err := h.quickPicks.RecordView(c.Request().Context(), tenantID, userID, product.ID); err != nil {
+			logger.Log.Warn("Failed to record product view: " + err.Error())
+		}
+	}
+
+	h.localize(c, product)
 	return c.JSON(http.StatusOK, toProductResponse(product))
 }
 
@@ -224,6 +446,7 @@ func (h *ProductHandler) GetByID(c echo.Context) error {
 // @Tags products
 // @Produce json
 // @Param sku path string true "Product SKU"
+// @Param locale query string false "Languages to show the name in, best first (default Accept-Language)"
 // @Success 200 {object} ProductResponse
 // @Failure 404 {object} map[string]string
 // @Router /api/v1/products/sku/{sku} [get]
@@ -240,15 +463,20 @@ func (h *ProductHandler) GetBySKU(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
 	}
 
+	h.localize(c, product)
 	return c.JSON(http.StatusOK, toProductResponse(product))
 }
 
 // @Summary Get product by barcode
-// @Description Get a product by barcode
+// @Description POS lookup: get a product by its barcode, a pack barcode (strip, box) or a scale label
+// @Description barcode that embeds weight or price (see barcode rules). Quantity and amount are computed
+// @Description for pack barcodes and scale labels.
 // @Tags products
 // @Produce json
 // @Param barcode path string true "Product Barcode"
-// @Success 200 {object} ProductResponse
+// @Param locale query string false "Languages to show the name in, best first (default Accept-Language)"
+// @Success 200 {object} BarcodeLookupResponse
+// @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /api/v1/products/barcode/{barcode} [get]
 // @Security BearerAuth
@@ -259,12 +487,108 @@ func (h *ProductHandler) GetByBarcode(c echo.Context) error {
 	}
 
 	barcode := c.Param("barcode")
-	product, err := h.service.GetByBarcode(c.Request().Context(), tenantID, barcode)
+	lookup, err := h.barcodes.Lookup(c.Request().Context(), tenantID, barcode)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		if errors.Is(err, domain.ErrBadCheckDigit) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, toProductResponse(product))
+	h.localize(c, lookup.Product)
+	return c.JSON(http.StatusOK, toBarcodeLookupResponse(lookup))
+}
+
+// @Summary List a product's pack barcodes
+// @Description Barcodes printed on packs of the product (strip, box), each with the base-unit quantity it rings up
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {array} ProductBarcodeResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/barcodes [get]
+// @Security BearerAuth
+func (h *ProductHandler) ListBarcodes(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	barcodes, err := h.barcodes.ListProductBarcodes(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		return productBarcodeError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toProductBarcodeResponses(barcodes))
+}
+
+// @Summary Set a product's pack barcodes
+// @Description Replace the barcodes printed on packs of the product. Each maps to a pack unit and the
+// @Description quantity of the product's base unit it rings up, e.g. a strip of 10 pcs. Barcodes must be
+// @Description unique within the tenant across product and pack barcodes.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param barcodes body SaveProductBarcodesRequest true "Pack barcodes"
+// @Success 200 {array} ProductBarcodeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/products/{id}/barcodes [put]
+// @Security BearerAuth
+func (h *ProductHandler) SaveBarcodes(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	var req SaveProductBarcodesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	barcodes := make([]domain.ProductBarcode, 0, len(req.Barcodes))
+	for _, barcode := range req.Barcodes {
+		barcodes = append(barcodes, domain.NewProductBarcode(tenantID, productID, barcode.Barcode, barcode.Unit, barcode.Quantity))
+	}
+
+	if err := h.barcodes.SaveProductBarcodes(c.Request().Context(), tenantID, productID, barcodes); err != nil {
+		return productBarcodeError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toProductBarcodeResponses(barcodes))
+}
+
+// productBarcodeError maps pack barcode errors to HTTP statuses
+func productBarcodeError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+	case errors.Is(err, domain.ErrBarcodeInUse):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidProductBarcode), errors.Is(err, domain.ErrUnknownUnit):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
 }
 
 // @Summary Get products by category
@@ -274,6 +598,7 @@ func (h *ProductHandler) GetByBarcode(c echo.Context) error {
 // @Param categoryId path string true "Category ID"
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
+// @Param locale query string false "Languages to show names in, best first (default Accept-Language)"
 // @Success 200 {array} ProductResponse
 // @Failure 404 {object} map[string]string
 // @Router /api/v1/products/category/{categoryId} [get]
@@ -295,6 +620,8 @@ func (h *ProductHandler) GetByCategory(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	h.localize(c, products...)
+
 	responses := make([]ProductResponse, len(products))
 	for i, prod := range products {
 		responses[i] = toProductResponse(prod)
@@ -303,6 +630,86 @@ func (h *ProductHandler) GetByCategory(c echo.Context) error {
 	return c.JSON(http.StatusOK, responses)
 }
 
+// @Summary Get products by HS code
+// @Description Get products whose HS code starts with a chapter, heading or full code
+// @Tags products
+// @Produce json
+// @Param code path string true "HS chapter, heading or code"
+// @Param limit query int false "Limit" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Param locale query string false "Languages to show names in, best first (default Accept-Language)"
+// @Success 200 {array} ProductResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/products/hs/{code} [get]
+// @Security BearerAuth
+func (h *ProductHandler) GetByHSCode(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit == 0 {
+		limit = 10
+	}
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	products, err := h.service.GetByHSCode(c.Request().Context(), tenantID, c.Param("code"), limit, offset)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidHSCode) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	h.localize(c, products...)
+
+	responses := make([]ProductResponse, len(products))
+	for i, prod := range products {
+		responses[i] = toProductResponse(prod)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// HSChapterSummaryResponse represents product totals for one HS chapter
+type HSChapterSummaryResponse struct {
+	Chapter      string  `json:"chapter"`
+	ProductCount int64   `json:"productCount"`
+	StockValue   float64 `json:"stockValue"`
+}
+
+// @Summary HS chapter summary
+// @Description Count products per HS chapter for customs reporting (empty chapter = unclassified)
+// @Tags products
+// @Produce json
+// @Success 200 {array} HSChapterSummaryResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/products/reports/hs-chapters [get]
+// @Security BearerAuth
+func (h *ProductHandler) SummarizeByHSChapter(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	summaries, err := h.service.SummarizeByHSChapter(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]HSChapterSummaryResponse, len(summaries))
+	for i, summary := range summaries {
+		responses[i] = HSChapterSummaryResponse{
+			Chapter:      summary.Chapter,
+			ProductCount: summary.ProductCount,
+			StockValue:   summary.StockValue,
+		}
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
 // @Summary Update product
 // @Description Update an existing product
 // @Tags products
@@ -330,7 +737,12 @@ func (h *ProductHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	product, err := h.service.GetByID(c.Request().Context(), id)
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	product, err := h.service.GetByID(c.Request().Context(), tenantID, id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
 	}
@@ -347,8 +759,13 @@ func (h *ProductHandler) Update(c echo.Context) error {
 	product.SellingPrice = req.SellingPrice
 	product.MRP = req.MRP
 	product.TaxRate = req.TaxRate
+	product.TaxGroupID, err = parseOptionalID(req.TaxGroupID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tax group ID"})
+	}
 	product.SKU = req.SKU
 	product.Barcode = req.Barcode
+	product.HSCode = req.HSCode
 	product.Unit = req.Unit
 	product.Status = domain.ProductStatus(req.Status)
 	if req.CustomAttributes != nil {
@@ -356,6 +773,17 @@ func (h *ProductHandler) Update(c echo.Context) error {
 	}
 
 	if err := h.service.Update(c.Request().Context(), product); err != nil {
+		if errors.Is(err, domain.ErrUnknownUnit) || errors.Is(err, domain.ErrUnknownTax) ||
+			errors.Is(err, domain.ErrInvalidHSCode) || errors.Is(err, domain.ErrAboveMRP) ||
+			errors.Is(err, domain.ErrCategoryNotFound) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, domain.ErrBarcodeInUse) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -376,7 +804,15 @@ func (h *ProductHandler) Delete(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
 	}
 
-	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.Delete(c.Request().Context(), tenantID, id); err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -385,7 +821,7 @@ func (h *ProductHandler) Delete(c echo.Context) error {
 
 // toProductResponse converts domain.Product to ProductResponse
 func toProductResponse(prod *domain.Product) ProductResponse {
-	return ProductResponse{
+	resp := ProductResponse{
 		ID:               prod.ID.String(),
 		TenantID:         prod.TenantID.String(),
 		ProductCode:      prod.ProductCode,
@@ -402,7 +838,59 @@ func toProductResponse(prod *domain.Product) ProductResponse {
 		Status:           string(prod.Status),
 		IsActive:         prod.IsActive,
 		CustomAttributes: prod.CustomAttributes,
+		Locale:           prod.Locale,
 		CreatedAt:        prod.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:        prod.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
+
+	if prod.TaxGroupID != nil {
+		taxGroupID := prod.TaxGroupID.String()
+		resp.TaxGroupID = &taxGroupID
+	}
+
+	if prod.HSCode != nil {
+		hsCode := domain.FormatHSCode(*prod.HSCode)
+		resp.HSCode = &hsCode
+	}
+
+	return resp
+}
+
+// toBarcodeLookupResponse converts domain.BarcodeLookup to BarcodeLookupResponse
+func toBarcodeLookupResponse(lookup *domain.BarcodeLookup) BarcodeLookupResponse {
+	resp := BarcodeLookupResponse{
+		ProductResponse: toProductResponse(lookup.Product),
+		Quantity:        lookup.Quantity,
+		Amount:          lookup.Amount,
+	}
+	if lookup.Scale != nil {
+		resp.Scale = &ScaleBarcodeResponse{
+			RuleID:    lookup.Scale.RuleID.String(),
+			ItemCode:  lookup.Scale.ItemCode,
+			ValueType: string(lookup.Scale.ValueType),
+			Value:     lookup.Scale.Value,
+		}
+	}
+	if lookup.Pack != nil {
+		pack := toProductBarcodeResponse(*lookup.Pack)
+		resp.Pack = &pack
+	}
+	return resp
+}
+
+// toProductBarcodeResponse converts domain.ProductBarcode to ProductBarcodeResponse
+func toProductBarcodeResponse(barcode domain.ProductBarcode) ProductBarcodeResponse {
+	return ProductBarcodeResponse{
+		Barcode:  barcode.Barcode,
+		Unit:     barcode.Unit,
+		Quantity: barcode.Quantity,
+	}
+}
+
+func toProductBarcodeResponses(barcodes []domain.ProductBarcode) []ProductBarcodeResponse {
+	resp := make([]ProductBarcodeResponse, len(barcodes))
+	for i, barcode := range barcodes {
+		resp[i] = toProductBarcodeResponse(barcode)
+	}
+	return resp
 }
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// PinProductRequest represents the request to pin a product to the quick-pick grid
+type PinProductRequest struct {
+	Position int `json:"position" validate:"gte=0"`
+}
+
+// QuickPickResponse represents a product on the POS quick-pick grid
+type QuickPickResponse struct {
+	Product      ProductResponse `json:"product"`
+	IsFavorite   bool            `json:"isFavorite"`
+	Position     int             `json:"position"`
+	ViewCount    int             `json:"viewCount"`
+	LastViewedAt *string         `json:"lastViewedAt,omitempty"`
+}
+
+// @Summary List quick-pick products
+// @Description Get the current user's pinned and recently viewed products for the POS grid
+// @Tags products
+// @Produce json
+// @Param locale query string false "Languages to show names in, best first (default Accept-Language)"
+// @Success 200 {array} QuickPickResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/products/quick-picks [get]
+// @Security BearerAuth
+func (h *ProductHandler) ListQuickPicks(c echo.Context) error {
+	tenantID, userID, ok := quickPickIdentity(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant or user not found"})
+	}
+
+	picks, err := h.quickPicks.List(c.Request().Context(), tenantID, userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// The grid is cached, so names are translated on copies
+	localized := make([]domain.ProductQuickPick, len(picks))
+	products := make([]*domain.Product, len(picks))
+	for i, pick := range picks {
+		product := *pick.Product
+		localized[i], products[i] = *pick, &product
+		localized[i].Product = &product
+	}
+	h.localize(c, products...)
+
+	responses := make([]QuickPickResponse, len(picks))
+	for i := range localized {
+		responses[i] = toQuickPickResponse(&localized[i])
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Pin product
+// @Description Pin a product to the current user's favorites
+// @Tags products
+// @Accept json
+// @Param id path string true "Product ID"
+// @Param request body PinProductRequest false "Grid position"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/favorite [post]
+// @Security BearerAuth
+func (h *ProductHandler) Pin(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	var req PinProductRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, userID, ok := quickPickIdentity(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant or user not found"})
+	}
+
+	if err := h.quickPicks.Pin(c.Request().Context(), tenantID, userID, id, req.Position); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary Unpin product
+// @Description Remove a product from the current user's favorites
+// @Tags products
+// @Param id path string true "Product ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/products/{id}/favorite [delete]
+// @Security BearerAuth
+func (h *ProductHandler) Unpin(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	tenantID, userID, ok := quickPickIdentity(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant or user not found"})
+	}
+
+	if err := h.quickPicks.Unpin(c.Request().Context(), tenantID, userID, id); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// quickPickIdentity returns the tenant and user the quick-pick grid belongs to
+func quickPickIdentity(c echo.Context) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tenantID, userID, true
+}
+
+// toQuickPickResponse converts domain.ProductQuickPick to QuickPickResponse
+func toQuickPickResponse(pick *domain.ProductQuickPick) QuickPickResponse {
+	resp := QuickPickResponse{
+		Product:    toProductResponse(pick.Product),
+		IsFavorite: pick.IsFavorite,
+		Position:   pick.Position,
+		ViewCount:  pick.ViewCount,
+	}
+
+	if pick.LastViewedAt != nil {
+		lastViewed := pick.LastViewedAt.Format("2006-01-02T15:04:05Z07:00")
+		resp.LastViewedAt = &lastViewed
+	}
+
+	return resp
+}
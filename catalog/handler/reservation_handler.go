@@ -0,0 +1,466 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ReservationHandler handles stock reservation HTTP requests
+type ReservationHandler struct {
+	service service.ReservationService
+}
+
+// NewReservationHandler creates a new reservation handler
+func NewReservationHandler(service service.ReservationService) *ReservationHandler {
+	return &ReservationHandler{service: service}
+}
+
+// ReservationItemRequest represents a product and quantity to hold
+type ReservationItemRequest struct {
+	ProductID string  `json:"productId" validate:"required,uuid"`
+	Quantity  float64 `json:"quantity" validate:"gt=0"`
+}
+
+// ReserveStockRequest represents the request to reserve stock for an order or quote
+type ReserveStockRequest struct {
+	ReferenceType   string                   `json:"referenceType" validate:"required,oneof=order quote"`
+	ReferenceID     string                   `json:"referenceId" validate:"required,uuid"`
+	ReferenceNumber *string                  `json:"referenceNumber,omitempty" validate:"omitempty,max=50"`
+	ExpiresAt       *time.Time               `json:"expiresAt,omitempty"`
+	Note            *string                  `json:"note,omitempty"`
+	Items           []ReservationItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// CloseReservationRequest represents the request to release a reservation
+type CloseReservationRequest struct {
+	Reason *string `json:"reason,omitempty"`
+}
+
+// CloseReferenceRequest represents the request to release or consume a document's reservations
+type CloseReferenceRequest struct {
+	ReferenceType string  `json:"referenceType" validate:"required,oneof=order quote"`
+	ReferenceID   string  `json:"referenceId" validate:"required,uuid"`
+	Reason        *string `json:"reason,omitempty"`
+}
+
+// ReservationResponse represents a stock reservation
+type ReservationResponse struct {
+	ID              string  `json:"id"`
+	ProductID       string  `json:"productId"`
+	Quantity        float64 `json:"quantity"`
+	ReferenceType   string  `json:"referenceType"`
+	ReferenceID     string  `json:"referenceId"`
+	ReferenceNumber *string `json:"referenceNumber,omitempty"`
+	Status          string  `json:"status"`
+	ExpiresAt       string  `json:"expiresAt"`
+	Note            *string `json:"note,omitempty"`
+	CreatedBy       *string `json:"createdBy,omitempty"`
+	CreatedAt       string  `json:"createdAt"`
+	ClosedAt        *string `json:"closedAt,omitempty"`
+	CloseReason     *string `json:"closeReason,omitempty"`
+}
+
+// CloseReferenceResponse reports how many reservations were closed
+type CloseReferenceResponse struct {
+	Closed int64 `json:"closed"`
+}
+
+// StockAvailabilityResponse represents what of a product can still be sold
+type StockAvailabilityResponse struct {
+	ProductID string  `json:"productId"`
+	OnHand    float64 `json:"onHand"`
+	Reserved  float64 `json:"reserved"`
+	Available float64 `json:"available"`
+}
+
+// ReservationSummaryResponse represents a product's line of the reservations report
+type ReservationSummaryResponse struct {
+	StockAvailabilityResponse
+	ProductCode  string `json:"productCode"`
+	ProductName  string `json:"productName"`
+	Reservations int    `json:"reservations"`
+	NextExpiry   string `json:"nextExpiry"`
+}
+
+// @Summary Reserve stock
+// @Description Hold stock for an order or quote so POS cannot sell it. All items are reserved or none;
+// @Description a product with less available than requested fails with 409. Expiry defaults to 48 hours.
+// @Tags stock-reservations
+// @Accept json
+// @Produce json
+// @Param request body ReserveStockRequest true "Reservation"
+// @Success 201 {array} ReservationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/stock-reservations [post]
+// @Security BearerAuth
+func (h *ReservationHandler) Reserve(c echo.Context) error {
+	var req ReserveStockRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	referenceID, err := uuid.Parse(req.ReferenceID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid reference ID"})
+	}
+
+	expiresAt := time.Now().Add(domain.DefaultReservationTTL)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+
+	var createdBy *uuid.UUID
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		createdBy = &userID
+	}
+
+	reservations := make([]*domain.StockReservation, len(req.Items))
+	for i, item := range req.Items {
+		productID, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+		}
+		reservation := domain.NewStockReservation(tenantID, productID, item.Quantity, domain.ReservationReference(req.ReferenceType), referenceID, expiresAt)
+		reservation.ReferenceNumber = req.ReferenceNumber
+		reservation.Note = req.Note
+		reservation.CreatedBy = createdBy
+		reservations[i] = reservation
+	}
+
+	if err := h.service.Reserve(c.Request().Context(), tenantID, reservations); err != nil {
+		return reservationError(c, err)
+	}
+
+	responses := make([]ReservationResponse, len(reservations))
+	for i, reservation := range reservations {
+		responses[i] = toReservationResponse(reservation)
+	}
+
+	return c.JSON(http.StatusCreated, responses)
+}
+
+// @Summary List stock reservations
+// @Description Reservations newest first, optionally filtered
+// @Tags stock-reservations
+// @Produce json
+// @Param status query string false "active, released, consumed or expired"
+// @Param productId query string false "Only this product"
+// @Param referenceType query string false "order or quote"
+// @Param referenceId query string false "Only this order or quote"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} ReservationResponse
+// @Router /api/v1/stock-reservations [get]
+// @Security BearerAuth
+func (h *ReservationHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	filter := domain.ReservationFilter{
+		Status:        domain.ReservationStatus(c.QueryParam("status")),
+		ReferenceType: domain.ReservationReference(c.QueryParam("referenceType")),
+	}
+	if raw := c.QueryParam("productId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+		}
+		filter.ProductID = &id
+	}
+	if raw := c.QueryParam("referenceId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid reference ID"})
+		}
+		filter.ReferenceID = &id
+	}
+
+	filter.Limit, _ = strconv.Atoi(c.QueryParam("limit"))
+	if filter.Limit == 0 {
+		filter.Limit = 50
+	}
+	filter.Offset, _ = strconv.Atoi(c.QueryParam("offset"))
+
+	reservations, err := h.service.ListReservations(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]ReservationResponse, len(reservations))
+	for i, reservation := range reservations {
+		responses[i] = toReservationResponse(reservation)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Get a stock reservation
+// @Tags stock-reservations
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} ReservationResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/stock-reservations/{id} [get]
+// @Security BearerAuth
+func (h *ReservationHandler) Get(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid reservation ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	reservation, err := h.service.GetReservation(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return reservationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toReservationResponse(reservation))
+}
+
+// @Summary Release a stock reservation
+// @Description Let go of one reservation so its stock can be sold again
+// @Tags stock-reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Param request body CloseReservationRequest false "Reason"
+// @Success 200 {object} ReservationResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/stock-reservations/{id}/release [post]
+// @Security BearerAuth
+func (h *ReservationHandler) Release(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid reservation ID"})
+	}
+
+	var req CloseReservationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	reservation, err := h.service.Release(c.Request().Context(), tenantID, id, req.Reason)
+	if err != nil {
+		return reservationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toReservationResponse(reservation))
+}
+
+// @Summary Release an order's or quote's reservations
+// @Description Release every active reservation of a document, e.g. when the order is cancelled
+// @Tags stock-reservations
+// @Accept json
+// @Produce json
+// @Param request body CloseReferenceRequest true "Reference"
+// @Success 200 {object} CloseReferenceResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/stock-reservations/release [post]
+// @Security BearerAuth
+func (h *ReservationHandler) ReleaseReference(c echo.Context) error {
+	return h.closeReference(c, false)
+}
+
+// @Summary Consume an order's reservations
+// @Description Close every active reservation of a document once it is fulfilled from the held stock
+// @Tags stock-reservations
+// @Accept json
+// @Produce json
+// @Param request body CloseReferenceRequest true "Reference"
+// @Success 200 {object} CloseReferenceResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/stock-reservations/consume [post]
+// @Security BearerAuth
+func (h *ReservationHandler) ConsumeReference(c echo.Context) error {
+	return h.closeReference(c, true)
+}
+
+func (h *ReservationHandler) closeReference(c echo.Context, consume bool) error {
+	var req CloseReferenceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	referenceID, err := uuid.Parse(req.ReferenceID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid reference ID"})
+	}
+	referenceType := domain.ReservationReference(req.ReferenceType)
+
+	var closed int64
+	if consume {
+		closed, err = h.service.ConsumeReference(c.Request().Context(), tenantID, referenceType, referenceID)
+	} else {
+		closed, err = h.service.ReleaseReference(c.Request().Context(), tenantID, referenceType, referenceID, req.Reason)
+	}
+	if err != nil {
+		return reservationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, CloseReferenceResponse{Closed: closed})
+}
+
+// @Summary Get stock availability
+// @Description On-hand minus active reservations for each product
+// @Tags stock-reservations
+// @Produce json
+// @Param productId query string true "Comma-separated product IDs"
+// @Success 200 {array} StockAvailabilityResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/stock-reservations/availability [get]
+// @Security BearerAuth
+func (h *ReservationHandler) Availability(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var productIDs []uuid.UUID
+	for _, raw := range strings.Split(c.QueryParam("productId"), ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+		}
+		productIDs = append(productIDs, id)
+	}
+	if len(productIDs) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "productId is required"})
+	}
+
+	availability, err := h.service.Availability(c.Request().Context(), tenantID, productIDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]StockAvailabilityResponse, len(availability))
+	for i, stock := range availability {
+		responses[i] = toStockAvailabilityResponse(stock)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Reservations report
+// @Description Products with stock held by active reservations, most reserved first, with on-hand and available
+// @Tags stock-reservations
+// @Produce json
+// @Success 200 {array} ReservationSummaryResponse
+// @Router /api/v1/stock-reservations/report [get]
+// @Security BearerAuth
+func (h *ReservationHandler) Report(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	summaries, err := h.service.Report(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]ReservationSummaryResponse, len(summaries))
+	for i, summary := range summaries {
+		responses[i] = ReservationSummaryResponse{
+			StockAvailabilityResponse: toStockAvailabilityResponse(summary.StockAvailability),
+			ProductCode:               summary.ProductCode,
+			ProductName:               summary.ProductName,
+			Reservations:              summary.Reservations,
+			NextExpiry:                summary.NextExpiry.Format(time.RFC3339),
+		}
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// reservationError maps reservation errors to HTTP statuses
+func reservationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrReservationNotFound), errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrReservationClosed), errors.Is(err, domain.ErrInsufficientStock):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidReservation), errors.Is(err, domain.ErrUnknownUnit):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// toReservationResponse converts domain.StockReservation to ReservationResponse
+func toReservationResponse(reservation *domain.StockReservation) ReservationResponse {
+	resp := ReservationResponse{
+		ID:              reservation.ID.String(),
+		ProductID:       reservation.ProductID.String(),
+		Quantity:        reservation.Quantity,
+		ReferenceType:   string(reservation.ReferenceType),
+		ReferenceID:     reservation.ReferenceID.String(),
+		ReferenceNumber: reservation.ReferenceNumber,
+		Status:          string(reservation.Status),
+		ExpiresAt:       reservation.ExpiresAt.Format(time.RFC3339),
+		Note:            reservation.Note,
+		CreatedAt:       reservation.CreatedAt.Format(time.RFC3339),
+		CloseReason:     reservation.CloseReason,
+	}
+	if reservation.CreatedBy != nil {
+		id := reservation.CreatedBy.String()
+		resp.CreatedBy = &id
+	}
+	if reservation.ClosedAt != nil {
+		closedAt := reservation.ClosedAt.Format(time.RFC3339)
+		resp.ClosedAt = &closedAt
+	}
+	return resp
+}
+
+func toStockAvailabilityResponse(stock domain.StockAvailability) StockAvailabilityResponse {
+	return StockAvailabilityResponse{
+		ProductID: stock.ProductID.String(),
+		OnHand:    stock.OnHand,
+		Reserved:  stock.Reserved,
+		Available: stock.Available,
+	}
+}
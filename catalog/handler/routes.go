@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/aceextension/catalog"
+	"github.com/aceextension/core/jobs"
 	"github.com/aceextension/core/middleware"
 	"github.com/labstack/echo/v4"
 )
@@ -9,8 +10,21 @@ import (
 // RegisterRoutes registers all catalog routes
 func RegisterRoutes(e *echo.Echo) {
 	// Create handlers
-	categoryHandler := NewCategoryHandler(catalog.CategoryService)
-	productHandler := NewProductHandler(catalog.ProductService)
+	categoryHandler := NewCategoryHandler(catalog.CategoryService, catalog.LocalizationService)
+	productHandler := NewProductHandler(catalog.ProductService, catalog.QuickPickService, catalog.BarcodeService, catalog.LocalizationService)
+	unitHandler := NewUnitHandler(catalog.UnitService)
+	taxHandler := NewTaxHandler(catalog.TaxService, catalog.ProductService)
+	hsCodeHandler := NewHSCodeHandler(catalog.HSCodeService)
+	pricingHandler := NewPricingHandler(catalog.PricingService)
+	guardrailHandler := NewGuardrailHandler(catalog.GuardrailService)
+	barcodeRuleHandler := NewBarcodeRuleHandler(catalog.BarcodeService)
+	assemblyHandler := NewAssemblyHandler(catalog.AssemblyService)
+	binHandler := NewBinHandler(catalog.BinService)
+	reservationHandler := NewReservationHandler(catalog.ReservationService)
+	demandHandler := NewDemandHandler(catalog.DemandService)
+	alertRuleHandler := NewAlertRuleHandler(catalog.AlertRuleService)
+	localizationHandler := NewLocalizationHandler(catalog.LocalizationService)
+	variantHandler := NewVariantHandler(catalog.VariantService)
 
 	// API v1 group with tenant middleware
 	v1 := e.Group("/api/v1")
@@ -24,18 +38,150 @@ func RegisterRoutes(e *echo.Echo) {
 	categories.GET("/tree", categoryHandler.GetTree)
 	categories.GET("/:id", categoryHandler.GetByID)
 	categories.GET("/:id/children", categoryHandler.GetChildren)
+	categories.GET("/:id/translations", localizationHandler.ListCategoryTranslations)
+	categories.PUT("/:id/translations/:locale", localizationHandler.SaveCategoryTranslation)
+	categories.DELETE("/:id/translations/:locale", localizationHandler.DeleteCategoryTranslation)
 	categories.PUT("/:id", categoryHandler.Update)
 	categories.DELETE("/:id", categoryHandler.Delete)
 
 	// Product routes
 	products := v1.Group("/products")
-	products.POST("", productHandler.Create)
+	products.POST("", productHandler.Create, middleware.PlanLimit(middleware.PlanLimitProducts))
 	products.GET("", productHandler.List)
 	products.GET("/search", productHandler.Search)
+	products.POST("/import", productHandler.Import, middleware.PlanLimit(middleware.PlanLimitProducts))
+	products.GET("/export", productHandler.Export, jobs.Async)
+	products.GET("/quick-picks", productHandler.ListQuickPicks)
+	products.POST("/bulk-price-update", pricingHandler.BulkUpdatePrices)
+	products.POST("/bulk-price-update/rollback", pricingHandler.RollbackBulkUpdate)
+	products.POST("/:id/favorite", productHandler.Pin)
+	products.DELETE("/:id/favorite", productHandler.Unpin)
 	products.GET("/sku/:sku", productHandler.GetBySKU)
 	products.GET("/barcode/:barcode", productHandler.GetByBarcode)
 	products.GET("/category/:categoryId", productHandler.GetByCategory)
+	products.GET("/hs/:code", productHandler.GetByHSCode)
+	products.GET("/reports/hs-chapters", productHandler.SummarizeByHSChapter)
+	products.GET("/:id/barcodes", productHandler.ListBarcodes)
+	products.PUT("/:id/barcodes", productHandler.SaveBarcodes)
+	products.GET("/:id/translations", localizationHandler.ListProductTranslations)
+	products.PUT("/:id/translations/:locale", localizationHandler.SaveProductTranslation)
+	products.DELETE("/:id/translations/:locale", localizationHandler.DeleteProductTranslation)
+	products.GET("/:id/variants", variantHandler.List)
+	products.POST("/:id/variants/generate", variantHandler.Generate, middleware.PlanLimit(middleware.PlanLimitProducts))
+	products.POST("/:id/variants/activation", variantHandler.SetActive)
+	products.GET("/:id/bins", binHandler.ListProductBins)
+	products.GET("/:id/demand", demandHandler.Forecast)
+	products.GET("/:id/bom", assemblyHandler.GetBOM)
+	products.PUT("/:id/bom", assemblyHandler.SaveBOM)
+	products.DELETE("/:id/bom", assemblyHandler.DeleteBOM)
+	products.GET("/:id/bom/cost", assemblyHandler.CostBOM)
 	products.GET("/:id", productHandler.GetByID)
 	products.PUT("/:id", productHandler.Update)
 	products.DELETE("/:id", productHandler.Delete)
+
+	// Language product names are printed in on documents
+	documentLocales := v1.Group("/document-locales")
+	documentLocales.GET("", localizationHandler.ListDocumentLocales)
+	documentLocales.PUT("/:documentType", localizationHandler.SetDocumentLocale)
+	documentLocales.DELETE("/:documentType", localizationHandler.DeleteDocumentLocale)
+
+	// Scale label barcode rules
+	barcodeRules := v1.Group("/barcode-rules")
+	barcodeRules.POST("", barcodeRuleHandler.Create)
+	barcodeRules.GET("", barcodeRuleHandler.List)
+	barcodeRules.PUT("/:id", barcodeRuleHandler.Update)
+	barcodeRules.DELETE("/:id", barcodeRuleHandler.Delete)
+
+	// Assembly (manufacturing) order routes
+	assembly := v1.Group("/assembly-orders")
+	assembly.POST("", assemblyHandler.CreateOrder)
+	assembly.GET("", assemblyHandler.ListOrders)
+	assembly.GET("/:id", assemblyHandler.GetOrder)
+	assembly.POST("/:id/start", assemblyHandler.StartOrder)
+	assembly.POST("/:id/complete", assemblyHandler.CompleteOrder)
+	assembly.POST("/:id/cancel", assemblyHandler.CancelOrder)
+
+	// Shelf/bin location routes
+	bins := v1.Group("/bins")
+	bins.POST("", binHandler.Create)
+	bins.GET("", binHandler.List)
+	bins.GET("/movements", binHandler.ListMovements)
+	bins.POST("/transfers", binHandler.Transfer)
+	bins.POST("/pick-list", binHandler.PickList)
+	bins.GET("/:id", binHandler.Get)
+	bins.PUT("/:id", binHandler.Update)
+	bins.DELETE("/:id", binHandler.Delete)
+	bins.GET("/:id/stock", binHandler.ListStock)
+	bins.PUT("/:id/stock/:productId", binHandler.SetQuantity)
+
+	// Stock reservation routes
+	reservations := v1.Group("/stock-reservations")
+	reservations.POST("", reservationHandler.Reserve)
+	reservations.GET("", reservationHandler.List)
+	reservations.GET("/availability", reservationHandler.Availability)
+	reservations.GET("/report", reservationHandler.Report)
+	reservations.POST("/release", reservationHandler.ReleaseReference)
+	reservations.POST("/consume", reservationHandler.ConsumeReference)
+	reservations.GET("/:id", reservationHandler.Get)
+	reservations.POST("/:id/release", reservationHandler.Release)
+
+	// Sales velocity and demand forecasting routes
+	demand := v1.Group("/demand")
+	demand.GET("", demandHandler.List)
+	demand.GET("/reorder-suggestions", demandHandler.ReorderSuggestions)
+	demand.POST("/recompute", demandHandler.Recompute)
+
+	// Low-stock and expiry alert rule routes
+	alertRules := v1.Group("/alert-rules")
+	alertRules.POST("", alertRuleHandler.Create)
+	alertRules.GET("", alertRuleHandler.List)
+	alertRules.GET("/alerts", alertRuleHandler.ListAlerts)
+	alertRules.POST("/evaluate", alertRuleHandler.Evaluate)
+	alertRules.GET("/:id", alertRuleHandler.Get)
+	alertRules.PUT("/:id", alertRuleHandler.Update)
+	alertRules.DELETE("/:id", alertRuleHandler.Delete)
+
+	// Unit of measure routes
+	units := v1.Group("/units")
+	units.POST("", unitHandler.Create)
+	units.GET("", unitHandler.List)
+	units.PUT("/:id", unitHandler.Update)
+	units.DELETE("/:id", unitHandler.Delete)
+
+	// Tax registry routes
+	taxes := v1.Group("/taxes")
+	taxes.GET("/rates", taxHandler.ListRates)
+	taxes.POST("/rates", taxHandler.CreateRate)
+	taxes.POST("/rates/:id/versions", taxHandler.ChangeRate)
+	taxes.GET("/groups", taxHandler.ListGroups)
+	taxes.POST("/groups", taxHandler.CreateGroup)
+	taxes.PUT("/groups/:id", taxHandler.UpdateGroup)
+	taxes.DELETE("/groups/:id", taxHandler.DeleteGroup)
+	taxes.POST("/compute", taxHandler.Compute)
+
+	// HS code lookup routes
+	hsCodes := v1.Group("/hs-codes")
+	hsCodes.GET("", hsCodeHandler.Search)
+	hsCodes.GET("/:code", hsCodeHandler.Lookup)
+
+	// Cost-based pricing routes
+	pricing := v1.Group("/pricing")
+	pricing.GET("/markup-rules", pricingHandler.ListRules)
+	pricing.POST("/markup-rules", pricingHandler.CreateRule)
+	pricing.PUT("/markup-rules/:id", pricingHandler.UpdateRule)
+	pricing.DELETE("/markup-rules/:id", pricingHandler.DeleteRule)
+	pricing.GET("/changes", pricingHandler.ListChanges)
+	pricing.POST("/changes/:id/approve", pricingHandler.ApproveChange)
+	pricing.POST("/changes/:id/reject", pricingHandler.RejectChange)
+	pricing.GET("/repricing-runs", pricingHandler.ListRuns)
+	pricing.POST("/repricing-runs", pricingHandler.RunRepricing)
+	pricing.GET("/repricing-runs/:id", pricingHandler.GetRun)
+
+	// MRP and sale-time discount guardrails
+	pricing.GET("/discount-limits", guardrailHandler.ListDiscountLimits)
+	pricing.PUT("/discount-limits/:role", guardrailHandler.SetDiscountLimit)
+	pricing.DELETE("/discount-limits/:role", guardrailHandler.DeleteDiscountLimit)
+	pricing.POST("/sale-check", guardrailHandler.CheckSale)
+	pricing.GET("/discount-overrides", guardrailHandler.ListOverrides)
+	pricing.POST("/discount-overrides", guardrailHandler.ApproveOverride)
 }
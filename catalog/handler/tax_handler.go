@@ -0,0 +1,564 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// TaxHandler handles tax registry HTTP requests
+type TaxHandler struct {
+	service        service.TaxService
+	productService service.ProductService
+}
+
+// NewTaxHandler creates a new tax handler
+func NewTaxHandler(service service.TaxService, productService service.ProductService) *TaxHandler {
+	return &TaxHandler{service: service, productService: productService}
+}
+
+// CreateTaxRateRequest represents the request to create a tax rate
+type CreateTaxRateRequest struct {
+	Code          string  `json:"code" validate:"required,min=1,max=20"`
+	Name          string  `json:"name" validate:"required,min=1,max=100"`
+	Kind          string  `json:"kind" validate:"required,oneof=standard zero_rated exempt"`
+	Rate          float64 `json:"rate" validate:"gte=0,lte=100"`
+	EffectiveFrom string  `json:"effectiveFrom" validate:"required"` // YYYY-MM-DD
+}
+
+// ChangeTaxRateRequest represents the request to schedule a new percentage
+type ChangeTaxRateRequest struct {
+	Rate          float64 `json:"rate" validate:"gte=0,lte=100"`
+	EffectiveFrom string  `json:"effectiveFrom" validate:"required"` // YYYY-MM-DD
+}
+
+// TaxGroupComponentRequest represents one tax rate in a group
+type TaxGroupComponentRequest struct {
+	TaxRateID string `json:"taxRateId" validate:"required"`
+	Sequence  int    `json:"sequence"`
+	Compound  bool   `json:"compound"`
+}
+
+// CreateTaxGroupRequest represents the request to create a tax group
+type CreateTaxGroupRequest struct {
+	Code        string                     `json:"code" validate:"required,min=1,max=20"`
+	Name        string                     `json:"name" validate:"required,min=1,max=100"`
+	Description *string                    `json:"description,omitempty"`
+	Components  []TaxGroupComponentRequest `json:"components" validate:"required,min=1,dive"`
+}
+
+// UpdateTaxGroupRequest represents the request to update a tax group
+type UpdateTaxGroupRequest struct {
+	Name        string                     `json:"name" validate:"required,min=1,max=100"`
+	Description *string                    `json:"description,omitempty"`
+	IsActive    bool                       `json:"isActive"`
+	Components  []TaxGroupComponentRequest `json:"components" validate:"required,min=1,dive"`
+}
+
+// ComputeTaxRequest represents the request to compute tax for a product
+type ComputeTaxRequest struct {
+	ProductID string  `json:"productId" validate:"required"`
+	Amount    float64 `json:"amount" validate:"gte=0"`
+	Date      string  `json:"date,omitempty"` // YYYY-MM-DD, defaults to today
+}
+
+// TaxRateVersionResponse represents an effective-dated rate
+type TaxRateVersionResponse struct {
+	Rate          float64 `json:"rate"`
+	EffectiveFrom string  `json:"effectiveFrom"`
+}
+
+// TaxRateResponse represents the tax rate response
+type TaxRateResponse struct {
+	ID          string                   `json:"id"`
+	Code        string                   `json:"code"`
+	Name        string                   `json:"name"`
+	Kind        string                   `json:"kind"`
+	CurrentRate *float64                 `json:"currentRate,omitempty"`
+	Versions    []TaxRateVersionResponse `json:"versions"`
+	IsSystem    bool                     `json:"isSystem"`
+	IsActive    bool                     `json:"isActive"`
+}
+
+// TaxGroupComponentResponse represents one tax rate in a group
+type TaxGroupComponentResponse struct {
+	TaxRateID string `json:"taxRateId"`
+	Sequence  int    `json:"sequence"`
+	Compound  bool   `json:"compound"`
+}
+
+// TaxGroupResponse represents the tax group response
+type TaxGroupResponse struct {
+	ID          string                      `json:"id"`
+	Code        string                      `json:"code"`
+	Name        string                      `json:"name"`
+	Description *string                     `json:"description,omitempty"`
+	Components  []TaxGroupComponentResponse `json:"components"`
+	IsSystem    bool                        `json:"isSystem"`
+	IsActive    bool                        `json:"isActive"`
+}
+
+// TaxLineResponse represents the tax computed for one component
+type TaxLineResponse struct {
+	Code          string  `json:"code"`
+	Name          string  `json:"name"`
+	Kind          string  `json:"kind"`
+	Rate          float64 `json:"rate"`
+	TaxableAmount float64 `json:"taxableAmount"`
+	TaxAmount     float64 `json:"taxAmount"`
+}
+
+// TaxBreakdownResponse represents the computed tax for an amount
+type TaxBreakdownResponse struct {
+	TaxGroupID    *string           `json:"taxGroupId,omitempty"`
+	TaxableAmount float64           `json:"taxableAmount"`
+	Lines         []TaxLineResponse `json:"lines"`
+	TotalTax      float64           `json:"totalTax"`
+	TotalAmount   float64           `json:"totalAmount"`
+}
+
+// @Summary Create a tax rate
+// @Description Add a tenant-specific tax rate with its first effective percentage
+// @Tags taxes
+// @Accept json
+// @Produce json
+// @Param rate body CreateTaxRateRequest true "Tax rate data"
+// @Success 201 {object} TaxRateResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/taxes/rates [post]
+// @Security BearerAuth
+func (h *TaxHandler) CreateRate(c echo.Context) error {
+	var req CreateTaxRateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	effectiveFrom, err := time.Parse("2006-01-02", req.EffectiveFrom)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid effective date, expected YYYY-MM-DD"})
+	}
+
+	rate := domain.NewTaxRate(tenantID, req.Code, req.Name, domain.TaxKind(req.Kind))
+	if err := h.service.CreateRate(c.Request().Context(), rate, req.Rate, effectiveFrom); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toTaxRateResponse(rate))
+}
+
+// @Summary List tax rates
+// @Description Get system and tenant tax rates with their rate history
+// @Tags taxes
+// @Produce json
+// @Success 200 {array} TaxRateResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/taxes/rates [get]
+// @Security BearerAuth
+func (h *TaxHandler) ListRates(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rates, err := h.service.ListRates(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]TaxRateResponse, len(rates))
+	for i, rate := range rates {
+		responses[i] = toTaxRateResponse(rate)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Change a tax rate
+// @Description Schedule a new percentage for a tenant tax rate from a given date
+// @Tags taxes
+// @Accept json
+// @Produce json
+// @Param id path string true "Tax rate ID"
+// @Param version body ChangeTaxRateRequest true "New rate"
+// @Success 200 {object} TaxRateResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/taxes/rates/{id}/versions [post]
+// @Security BearerAuth
+func (h *TaxHandler) ChangeRate(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	var req ChangeTaxRateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	effectiveFrom, err := time.Parse("2006-01-02", req.EffectiveFrom)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid effective date, expected YYYY-MM-DD"})
+	}
+
+	if err := h.service.ChangeRate(c.Request().Context(), tenantID, id, req.Rate, effectiveFrom); err != nil {
+		return taxErrorResponse(c, err)
+	}
+
+	rate, err := h.service.GetRateByID(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toTaxRateResponse(rate))
+}
+
+// @Summary Create a tax group
+// @Description Create a composite levy from one or more tax rates
+// @Tags taxes
+// @Accept json
+// @Produce json
+// @Param group body CreateTaxGroupRequest true "Tax group data"
+// @Success 201 {object} TaxGroupResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/taxes/groups [post]
+// @Security BearerAuth
+func (h *TaxHandler) CreateGroup(c echo.Context) error {
+	var req CreateTaxGroupRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	components, err := toTaxGroupComponents(req.Components)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tax rate ID"})
+	}
+
+	group := domain.NewTaxGroup(tenantID, req.Code, req.Name)
+	group.Description = req.Description
+	group.Components = components
+
+	if err := h.service.CreateGroup(c.Request().Context(), group); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toTaxGroupResponse(group))
+}
+
+// @Summary List tax groups
+// @Description Get system and tenant tax groups
+// @Tags taxes
+// @Produce json
+// @Success 200 {array} TaxGroupResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/taxes/groups [get]
+// @Security BearerAuth
+func (h *TaxHandler) ListGroups(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	groups, err := h.service.ListGroups(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]TaxGroupResponse, len(groups))
+	for i, group := range groups {
+		responses[i] = toTaxGroupResponse(group)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Update a tax group
+// @Description Update a tenant tax group and replace its components
+// @Tags taxes
+// @Accept json
+// @Produce json
+// @Param id path string true "Tax group ID"
+// @Param group body UpdateTaxGroupRequest true "Tax group data"
+// @Success 200 {object} TaxGroupResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/taxes/groups/{id} [put]
+// @Security BearerAuth
+func (h *TaxHandler) UpdateGroup(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	var req UpdateTaxGroupRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	group, err := h.service.GetGroupByID(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Tax group not found"})
+	}
+
+	components, err := toTaxGroupComponents(req.Components)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tax rate ID"})
+	}
+
+	group.Name = req.Name
+	group.Description = req.Description
+	group.IsActive = req.IsActive
+	group.Components = components
+
+	if err := h.service.UpdateGroup(c.Request().Context(), tenantID, group); err != nil {
+		return taxErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toTaxGroupResponse(group))
+}
+
+// @Summary Delete a tax group
+// @Description Delete a tenant tax group that is not assigned to any product or category
+// @Tags taxes
+// @Param id path string true "Tax group ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/taxes/groups/{id} [delete]
+// @Security BearerAuth
+func (h *TaxHandler) DeleteGroup(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.DeleteGroup(c.Request().Context(), tenantID, id); err != nil {
+		return taxErrorResponse(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary Compute product tax
+// @Description Compute the tax breakdown for a product amount on a date
+// @Tags taxes
+// @Accept json
+// @Produce json
+// @Param request body ComputeTaxRequest true "Product and amount"
+// @Success 200 {object} TaxBreakdownResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/taxes/compute [post]
+// @Security BearerAuth
+func (h *TaxHandler) Compute(c echo.Context) error {
+	var req ComputeTaxRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	productID, err := uuid.Parse(req.ProductID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		date, err = time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid date, expected YYYY-MM-DD"})
+		}
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	product, err := h.productService.GetByID(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+	}
+
+	breakdown, err := h.service.ComputeProductTax(c.Request().Context(), product, req.Amount, date)
+	if err != nil {
+		return taxErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toTaxBreakdownResponse(breakdown))
+}
+
+// taxErrorResponse maps tax registry errors to HTTP status codes
+func taxErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrSystemTaxReadOnly):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrUnknownTax):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
+
+// parseOptionalID parses an optional UUID string
+func parseOptionalID(value *string) (*uuid.UUID, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// toTaxGroupComponents converts request components to domain components
+func toTaxGroupComponents(reqs []TaxGroupComponentRequest) ([]*domain.TaxGroupComponent, error) {
+	components := make([]*domain.TaxGroupComponent, len(reqs))
+	for i, req := range reqs {
+		rateID, err := uuid.Parse(req.TaxRateID)
+		if err != nil {
+			return nil, err
+		}
+		sequence := req.Sequence
+		if sequence == 0 {
+			sequence = i + 1
+		}
+		components[i] = &domain.TaxGroupComponent{
+			TaxRateID: rateID,
+			Sequence:  sequence,
+			Compound:  req.Compound,
+		}
+	}
+	return components, nil
+}
+
+// toTaxRateResponse converts domain.TaxRate to TaxRateResponse
+func toTaxRateResponse(rate *domain.TaxRate) TaxRateResponse {
+	resp := TaxRateResponse{
+		ID:       rate.ID.String(),
+		Code:     rate.Code,
+		Name:     rate.Name,
+		Kind:     string(rate.Kind),
+		Versions: make([]TaxRateVersionResponse, len(rate.Versions)),
+		IsSystem: rate.IsSystem,
+		IsActive: rate.IsActive,
+	}
+
+	for i, v := range rate.Versions {
+		resp.Versions[i] = TaxRateVersionResponse{
+			Rate:          v.Rate,
+			EffectiveFrom: v.EffectiveFrom.Format("2006-01-02"),
+		}
+	}
+
+	if current, err := rate.RateOn(time.Now()); err == nil {
+		resp.CurrentRate = &current.Rate
+	}
+
+	return resp
+}
+
+// toTaxGroupResponse converts domain.TaxGroup to TaxGroupResponse
+func toTaxGroupResponse(group *domain.TaxGroup) TaxGroupResponse {
+	resp := TaxGroupResponse{
+		ID:          group.ID.String(),
+		Code:        group.Code,
+		Name:        group.Name,
+		Description: group.Description,
+		Components:  make([]TaxGroupComponentResponse, len(group.Components)),
+		IsSystem:    group.IsSystem,
+		IsActive:    group.IsActive,
+	}
+
+	for i, comp := range group.Components {
+		resp.Components[i] = TaxGroupComponentResponse{
+			TaxRateID: comp.TaxRateID.String(),
+			Sequence:  comp.Sequence,
+			Compound:  comp.Compound,
+		}
+	}
+
+	return resp
+}
+
+// toTaxBreakdownResponse converts domain.TaxBreakdown to TaxBreakdownResponse
+func toTaxBreakdownResponse(breakdown *domain.TaxBreakdown) TaxBreakdownResponse {
+	resp := TaxBreakdownResponse{
+		TaxableAmount: breakdown.TaxableAmount,
+		Lines:         make([]TaxLineResponse, len(breakdown.Lines)),
+		TotalTax:      breakdown.TotalTax,
+		TotalAmount:   breakdown.TotalAmount,
+	}
+
+	if breakdown.TaxGroupID != nil {
+		groupID := breakdown.TaxGroupID.String()
+		resp.TaxGroupID = &groupID
+	}
+
+	for i, line := range breakdown.Lines {
+		resp.Lines[i] = TaxLineResponse{
+			Code:          line.Code,
+			Name:          line.Name,
+			Kind:          string(line.Kind),
+			Rate:          line.Rate,
+			TaxableAmount: line.TaxableAmount,
+			TaxAmount:     line.TaxAmount,
+		}
+	}
+
+	return resp
+}
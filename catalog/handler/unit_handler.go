@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// UnitHandler handles unit of measure HTTP requests
+type UnitHandler struct {
+	service service.UnitService
+}
+
+// NewUnitHandler creates a new unit handler
+func NewUnitHandler(service service.UnitService) *UnitHandler {
+	return &UnitHandler{service: service}
+}
+
+// CreateUnitRequest represents the request to create a custom unit
+type CreateUnitRequest struct {
+	Code          string `json:"code" validate:"required,min=1,max=20"`
+	Name          string `json:"name" validate:"required,min=1,max=100"`
+	Symbol        string `json:"symbol" validate:"required,max=20"`
+	DecimalPlaces int    `json:"decimalPlaces" validate:"gte=0,lte=6"`
+}
+
+// UpdateUnitRequest represents the request to update a custom unit
+type UpdateUnitRequest struct {
+	Name          string `json:"name" validate:"required,min=1,max=100"`
+	Symbol        string `json:"symbol" validate:"required,max=20"`
+	DecimalPlaces int    `json:"decimalPlaces" validate:"gte=0,lte=6"`
+	IsActive      bool   `json:"isActive"`
+}
+
+// UnitResponse represents the unit of measure response
+type UnitResponse struct {
+	ID            string `json:"id"`
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	Symbol        string `json:"symbol"`
+	DecimalPlaces int    `json:"decimalPlaces"`
+	IsSystem      bool   `json:"isSystem"`
+	IsActive      bool   `json:"isActive"`
+}
+
+// @Summary Create a custom unit
+// @Description Add a tenant-specific unit of measure to the registry
+// @Tags units
+// @Accept json
+// @Produce json
+// @Param unit body CreateUnitRequest true "Unit data"
+// @Success 201 {object} UnitResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/units [post]
+// @Security BearerAuth
+func (h *UnitHandler) Create(c echo.Context) error {
+	var req CreateUnitRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	unit := domain.NewUnitOfMeasure(tenantID, req.Code, req.Name, req.Symbol, req.DecimalPlaces)
+
+	if err := h.service.Create(c.Request().Context(), unit); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, toUnitResponse(unit))
+}
+
+// @Summary List units
+// @Description Get system units and the tenant's custom units
+// @Tags units
+// @Produce json
+// @Success 200 {array} UnitResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/units [get]
+// @Security BearerAuth
+func (h *UnitHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	units, err := h.service.List(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]UnitResponse, len(units))
+	for i, unit := range units {
+		responses[i] = toUnitResponse(unit)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// @Summary Update a custom unit
+// @Description Update a tenant-specific unit of measure
+// @Tags units
+// @Accept json
+// @Produce json
+// @Param id path string true "Unit ID"
+// @Param unit body UpdateUnitRequest true "Unit data"
+// @Success 200 {object} UnitResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/units/{id} [put]
+// @Security BearerAuth
+func (h *UnitHandler) Update(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	var req UpdateUnitRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	unit, err := h.service.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Unit not found"})
+	}
+
+	unit.Name = req.Name
+	unit.Symbol = req.Symbol
+	unit.DecimalPlaces = req.DecimalPlaces
+	unit.IsActive = req.IsActive
+
+	if err := h.service.Update(c.Request().Context(), tenantID, unit); err != nil {
+		if errors.Is(err, domain.ErrSystemUnitReadOnly) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, toUnitResponse(unit))
+}
+
+// @Summary Delete a custom unit
+// @Description Delete a tenant-specific unit that is not used by any product
+// @Tags units
+// @Param id path string true "Unit ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/units/{id} [delete]
+// @Security BearerAuth
+func (h *UnitHandler) Delete(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.Delete(c.Request().Context(), tenantID, id); err != nil {
+		if errors.Is(err, domain.ErrSystemUnitReadOnly) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, domain.ErrUnknownUnit) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Unit not found"})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// toUnitResponse converts domain.UnitOfMeasure to UnitResponse
+func toUnitResponse(unit *domain.UnitOfMeasure) UnitResponse {
+	return UnitResponse{
+		ID:            unit.ID.String(),
+		Code:          unit.Code,
+		Name:          unit.Name,
+		Symbol:        unit.Symbol,
+		DecimalPlaces: unit.DecimalPlaces,
+		IsSystem:      unit.IsSystem,
+		IsActive:      unit.IsActive,
+	}
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// VariantHandler handles product variant HTTP requests
+type VariantHandler struct {
+	service service.VariantService
+}
+
+// NewVariantHandler creates a new variant handler
+func NewVariantHandler(service service.VariantService) *VariantHandler {
+	return &VariantHandler{service: service}
+}
+
+// GenerateVariantsRequest represents the attribute axes to generate a product's variants from
+type GenerateVariantsRequest struct {
+	Axes       []domain.VariantAxis `json:"axes" validate:"required,min=1"`
+	SKUPattern string               `json:"skuPattern,omitempty"` // e.g. {sku}-{size}-{color}; the default
+	Activate   bool                 `json:"activate"`             // Otherwise variants are created inactive
+}
+
+// VariantActivationRequest represents variants to activate or deactivate
+type VariantActivationRequest struct {
+	VariantIDs []string `json:"variantIds,omitempty" validate:"omitempty,dive,uuid"` // Omit for all of the product's variants
+	Active     bool     `json:"active"`
+}
+
+// VariantResponse represents a variant product with the attribute values that set it apart
+type VariantResponse struct {
+	ProductResponse
+	ParentID   string            `json:"parentId"`
+	Attributes map[string]string `json:"attributes"`
+	PriceDelta float64           `json:"priceDelta"`
+}
+
+// GenerateVariantsResponse represents the variants a generation created
+type GenerateVariantsResponse struct {
+	Created []VariantResponse `json:"created"`
+	Skipped int               `json:"skipped"` // Combinations the product already had
+}
+
+// @Summary List product variants
+// @Description Get the variants generated from a product, each a product of its own with its attribute values
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {array} VariantResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/variants [get]
+// @Security BearerAuth
+func (h *VariantHandler) List(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	variants, err := h.service.List(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return variantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toVariantResponses(variants))
+}
+
+// @Summary Generate product variants
+// @Description Create a variant of the product for every combination of the axes' values, e.g. size S/M/L by
+// @Description color red/blue for six. Each copies the product with its own SKU from the pattern, where {sku} is
+// @Description the product's SKU or code and {size} the value's code, and the values' price deltas added to
+// @Description its selling price and MRP. Combinations the product already has are skipped, so axes can be
+// @Description extended later. At most 3 axes and 100 variants per product.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body GenerateVariantsRequest true "Variant matrix"
+// @Success 201 {object} GenerateVariantsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 402 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/variants/generate [post]
+// @Security BearerAuth
+func (h *VariantHandler) Generate(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	var req GenerateVariantsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	matrix := domain.VariantMatrix{Axes: req.Axes, SKUPattern: req.SKUPattern, Activate: req.Activate}
+	generation, err := h.service.Generate(c.Request().Context(), tenantID, id, matrix)
+	if err != nil {
+		return variantError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, GenerateVariantsResponse{
+		Created: toVariantResponses(generation.Created),
+		Skipped: generation.Skipped,
+	})
+}
+
+// @Summary Activate or deactivate variants
+// @Description Put the given variants of a product, or all of them, on or off sale in one go
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body VariantActivationRequest true "Variants and state"
+// @Success 200 {array} VariantResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/products/{id}/variants/activation [post]
+// @Security BearerAuth
+func (h *VariantHandler) SetActive(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+	}
+
+	var req VariantActivationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	variantIDs := make([]uuid.UUID, len(req.VariantIDs))
+	for i, s := range req.VariantIDs {
+		if variantIDs[i], err = uuid.Parse(s); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid variant ID"})
+		}
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	variants, err := h.service.SetActive(c.Request().Context(), tenantID, id, variantIDs, req.Active)
+	if err != nil {
+		return variantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toVariantResponses(variants))
+}
+
+// variantError maps variant errors to HTTP statuses
+func variantError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidVariantMatrix) || errors.Is(err, domain.ErrNestedVariant) ||
+		errors.Is(err, domain.ErrUnknownUnit) || errors.Is(err, domain.ErrUnknownTax) ||
+		errors.Is(err, domain.ErrInvalidHSCode) || errors.Is(err, domain.ErrAboveMRP) ||
+		errors.Is(err, domain.ErrCategoryNotFound):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+	case errors.Is(err, domain.ErrVariantNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrBarcodeInUse):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrProductLimitReached):
+		return productLimitError(c, err)
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
+
+func toVariantResponses(variants []*domain.ProductVariant) []VariantResponse {
+	responses := make([]VariantResponse, len(variants))
+	for i, v := range variants {
+		responses[i] = VariantResponse{
+			ProductResponse: toProductResponse(v.Product),
+			ParentID:        v.ParentID.String(),
+			Attributes:      v.Attributes,
+			PriceDelta:      v.PriceDelta,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,109 @@
+-- Catalog Module: Unit of Measure Registry
+-- Migration: 002_create_units_of_measure.sql
+
+-- ============================================================================
+-- UNITS OF MEASURE TABLE
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS units_of_measure (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID,
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    symbol VARCHAR(20) NOT NULL,
+    decimal_places INTEGER NOT NULL DEFAULT 0,
+    is_system BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    CONSTRAINT check_uom_decimal_places CHECK (decimal_places BETWEEN 0 AND 6),
+    CONSTRAINT check_uom_system_tenant CHECK (
+        (is_system = true AND tenant_id IS NULL) OR (is_system = false AND tenant_id IS NOT NULL)
+    )
+);
+
+-- One code per tenant; system units share the nil tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_uom_tenant_code
+    ON units_of_measure (COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'::uuid), code);
+CREATE INDEX IF NOT EXISTS idx_uom_tenant_id ON units_of_measure(tenant_id);
+
+-- Enable RLS for units_of_measure
+ALTER TABLE units_of_measure ENABLE ROW LEVEL SECURITY;
+
+-- RLS Policy: System units are visible to every tenant
+CREATE POLICY tenant_isolation ON units_of_measure
+    USING (
+        tenant_id IS NULL
+        OR tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE TRIGGER update_units_of_measure_updated_at BEFORE UPDATE ON units_of_measure
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE units_of_measure IS 'Unit of measure registry: shared system units plus tenant custom units';
+COMMENT ON COLUMN units_of_measure.decimal_places IS 'Allowed quantity precision (0 = whole numbers only)';
+
+-- ============================================================================
+-- SEED SYSTEM UNITS
+-- ============================================================================
+
+INSERT INTO units_of_measure (tenant_id, code, name, symbol, decimal_places, is_system) VALUES
+    (NULL, 'pcs',    'Pieces',     'pcs',  0, true),
+    (NULL, 'kg',     'Kilogram',   'kg',   3, true),
+    (NULL, 'g',      'Gram',       'g',    0, true),
+    (NULL, 'ltr',    'Litre',      'L',    3, true),
+    (NULL, 'ml',     'Millilitre', 'ml',   0, true),
+    (NULL, 'm',      'Metre',      'm',    2, true),
+    (NULL, 'box',    'Box',        'box',  0, true),
+    (NULL, 'pack',   'Pack',       'pack', 0, true),
+    (NULL, 'dozen',  'Dozen',      'dz',   0, true),
+    (NULL, 'strip',  'Strip',      'strip', 0, true),
+    (NULL, 'bottle', 'Bottle',     'btl',  0, true),
+    (NULL, 'set',    'Set',        'set',  0, true),
+    (NULL, 'pair',   'Pair',       'pair', 0, true)
+ON CONFLICT DO NOTHING;
+
+-- ============================================================================
+-- MIGRATE EXISTING PRODUCT UNITS
+-- ============================================================================
+
+-- Normalize free-text units to canonical codes (mirrors domain.NormalizeUnitCode)
+UPDATE products SET unit = CASE lower(trim(unit))
+        WHEN 'pc' THEN 'pcs' WHEN 'piece' THEN 'pcs' WHEN 'pieces' THEN 'pcs'
+        WHEN 'nos' THEN 'pcs' WHEN 'no' THEN 'pcs'
+        WHEN 'kgs' THEN 'kg' WHEN 'kilo' THEN 'kg' WHEN 'kilogram' THEN 'kg' WHEN 'kilograms' THEN 'kg'
+        WHEN 'gm' THEN 'g' WHEN 'gms' THEN 'g' WHEN 'gram' THEN 'g' WHEN 'grams' THEN 'g'
+        WHEN 'l' THEN 'ltr' WHEN 'lt' THEN 'ltr' WHEN 'liter' THEN 'ltr' WHEN 'litre' THEN 'ltr'
+        WHEN 'liters' THEN 'ltr' WHEN 'litres' THEN 'ltr'
+        WHEN 'millilitre' THEN 'ml' WHEN 'milliliter' THEN 'ml'
+        WHEN 'mtr' THEN 'm' WHEN 'meter' THEN 'm' WHEN 'metre' THEN 'm'
+        WHEN 'boxes' THEN 'box'
+        WHEN 'packet' THEN 'pack' WHEN 'packets' THEN 'pack' WHEN 'pkt' THEN 'pack'
+        WHEN 'dz' THEN 'dozen' WHEN 'doz' THEN 'dozen'
+        WHEN 'strips' THEN 'strip'
+        WHEN 'bottles' THEN 'bottle' WHEN 'btl' THEN 'bottle'
+        WHEN 'sets' THEN 'set' WHEN 'pairs' THEN 'pair'
+        ELSE lower(trim(unit))
+    END
+WHERE unit IS NOT NULL;
+
+-- Register any remaining unknown units as tenant custom units so existing products stay valid
+INSERT INTO units_of_measure (tenant_id, code, name, symbol, decimal_places, is_system)
+SELECT DISTINCT p.tenant_id, p.unit, p.unit, p.unit, 0, false
+FROM products p
+WHERE p.unit IS NOT NULL AND p.unit <> ''
+  AND NOT EXISTS (
+      SELECT 1 FROM units_of_measure u
+      WHERE u.code = p.unit AND (u.tenant_id IS NULL OR u.tenant_id = p.tenant_id)
+  )
+ON CONFLICT DO NOTHING;
+
+-- ============================================================================
+-- SUMMARY
+-- ============================================================================
+-- Tables created: 1 (units_of_measure)
+-- System units seeded: 13
+-- RLS policies: 1 (tenant isolation, system units shared)
+-- Data migration: products.unit normalized to registry codes
@@ -0,0 +1,162 @@
+-- Catalog Module: Tax Registry
+-- Migration: 003_create_tax_registry.sql
+
+-- ============================================================================
+-- TAX RATES TABLE
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID,
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL DEFAULT 'standard',
+    is_system BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    CONSTRAINT check_tax_rate_kind CHECK (kind IN ('standard', 'zero_rated', 'exempt')),
+    CONSTRAINT check_tax_rate_system_tenant CHECK (
+        (is_system = true AND tenant_id IS NULL) OR (is_system = false AND tenant_id IS NOT NULL)
+    )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tax_rates_tenant_code
+    ON tax_rates (COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'::uuid), code);
+CREATE INDEX IF NOT EXISTS idx_tax_rates_tenant_id ON tax_rates(tenant_id);
+
+-- ============================================================================
+-- TAX RATE VERSIONS TABLE (effective-dated percentages)
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS tax_rate_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tax_rate_id UUID NOT NULL REFERENCES tax_rates(id) ON DELETE CASCADE,
+    rate DECIMAL(5,2) NOT NULL,
+    effective_from DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+
+    CONSTRAINT check_tax_rate_version_rate CHECK (rate >= 0 AND rate <= 100),
+    CONSTRAINT uq_tax_rate_version_date UNIQUE (tax_rate_id, effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_rate_versions_lookup ON tax_rate_versions(tax_rate_id, effective_from DESC);
+
+-- ============================================================================
+-- TAX GROUPS TABLE
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS tax_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID,
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    is_system BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    CONSTRAINT check_tax_group_system_tenant CHECK (
+        (is_system = true AND tenant_id IS NULL) OR (is_system = false AND tenant_id IS NOT NULL)
+    )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tax_groups_tenant_code
+    ON tax_groups (COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'::uuid), code);
+CREATE INDEX IF NOT EXISTS idx_tax_groups_tenant_id ON tax_groups(tenant_id);
+
+CREATE TABLE IF NOT EXISTS tax_group_components (
+    tax_group_id UUID NOT NULL REFERENCES tax_groups(id) ON DELETE CASCADE,
+    tax_rate_id UUID NOT NULL REFERENCES tax_rates(id) ON DELETE RESTRICT,
+    sequence INTEGER NOT NULL DEFAULT 1,
+    compound BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (tax_group_id, tax_rate_id)
+);
+
+-- ============================================================================
+-- ROW LEVEL SECURITY (system rows shared by all tenants)
+-- ============================================================================
+
+ALTER TABLE tax_rates ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tax_rates
+    USING (
+        tenant_id IS NULL
+        OR tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+ALTER TABLE tax_groups ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tax_groups
+    USING (
+        tenant_id IS NULL
+        OR tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE TRIGGER update_tax_rates_updated_at BEFORE UPDATE ON tax_rates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_tax_groups_updated_at BEFORE UPDATE ON tax_groups
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE tax_rates IS 'Tax registry: individual levies classified for VAT reporting';
+COMMENT ON COLUMN tax_rates.kind IS 'standard = taxable at rate, zero_rated = taxable at 0%, exempt = outside VAT';
+COMMENT ON TABLE tax_rate_versions IS 'Effective-dated percentages; the latest effective_from <= document date applies';
+COMMENT ON TABLE tax_groups IS 'Composite levies assigned to products and categories';
+COMMENT ON COLUMN tax_group_components.compound IS 'Applied on the amount plus taxes of earlier components';
+
+-- ============================================================================
+-- SEED SYSTEM TAXES (Nepal VAT)
+-- ============================================================================
+
+INSERT INTO tax_rates (tenant_id, code, name, kind, is_system) VALUES
+    (NULL, 'VAT13',  'VAT 13%',    'standard',   true),
+    (NULL, 'ZERO',   'Zero Rated', 'zero_rated', true),
+    (NULL, 'EXEMPT', 'VAT Exempt', 'exempt',     true)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO tax_rate_versions (tax_rate_id, rate, effective_from)
+SELECT id, CASE code WHEN 'VAT13' THEN 13 ELSE 0 END, DATE '2000-01-01'
+FROM tax_rates
+WHERE tenant_id IS NULL AND code IN ('VAT13', 'ZERO', 'EXEMPT')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO tax_groups (tenant_id, code, name, is_system) VALUES
+    (NULL, 'VAT13',  'VAT 13%',    true),
+    (NULL, 'ZERO',   'Zero Rated', true),
+    (NULL, 'EXEMPT', 'VAT Exempt', true)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO tax_group_components (tax_group_id, tax_rate_id, sequence, compound)
+SELECT g.id, r.id, 1, false
+FROM tax_groups g
+JOIN tax_rates r ON r.code = g.code AND r.tenant_id IS NULL
+WHERE g.tenant_id IS NULL
+ON CONFLICT DO NOTHING;
+
+-- ============================================================================
+-- PRODUCT / CATEGORY TAX ASSIGNMENT
+-- ============================================================================
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tax_group_id UUID REFERENCES tax_groups(id) ON DELETE SET NULL;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_group_id UUID REFERENCES tax_groups(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_categories_tax_group ON categories(tax_group_id);
+CREATE INDEX IF NOT EXISTS idx_products_tax_group ON products(tax_group_id);
+
+COMMENT ON COLUMN products.tax_group_id IS 'Tax group; falls back to the category chain, then to tax_rate';
+COMMENT ON COLUMN products.tax_rate IS 'Legacy flat tax percentage, used only when no tax group applies';
+
+-- Products already charging 13% move to the VAT13 group
+UPDATE products
+SET tax_group_id = (SELECT id FROM tax_groups WHERE tenant_id IS NULL AND code = 'VAT13')
+WHERE tax_group_id IS NULL AND tax_rate = 13;
+
+-- ============================================================================
+-- SUMMARY
+-- ============================================================================
+-- Tables created: 4 (tax_rates, tax_rate_versions, tax_groups, tax_group_components)
+-- System taxes seeded: 3 (VAT13, ZERO, EXEMPT) with matching groups
+-- Columns added: categories.tax_group_id, products.tax_group_id
@@ -0,0 +1,91 @@
+-- Catalog Module: HS Commodity Codes
+-- Migration: 004_add_hs_codes.sql
+
+-- ============================================================================
+-- HS CODES TABLE (shared reference data, no tenant)
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS hs_codes (
+    code VARCHAR(8) PRIMARY KEY,
+    description VARCHAR(500) NOT NULL,
+    chapter VARCHAR(2) GENERATED ALWAYS AS (LEFT(code, 2)) STORED,
+    heading VARCHAR(4) GENERATED ALWAYS AS (LEFT(code, 4)) STORED,
+
+    CONSTRAINT check_hs_code_format CHECK (code ~ '^([0-9]{4}|[0-9]{6}|[0-9]{8})$')
+);
+
+CREATE INDEX IF NOT EXISTS idx_hs_codes_chapter ON hs_codes(chapter);
+CREATE INDEX IF NOT EXISTS idx_hs_codes_description ON hs_codes USING GIN (to_tsvector('simple', description));
+
+COMMENT ON TABLE hs_codes IS 'Built-in Harmonized System code list used for customs classification';
+
+-- Common headings for retail and import businesses
+INSERT INTO hs_codes (code, description) VALUES
+    ('0402', 'Milk and cream, concentrated or containing added sugar'),
+    ('0713', 'Dried leguminous vegetables (lentils, beans, peas)'),
+    ('0902', 'Tea, whether or not flavoured'),
+    ('1006', 'Rice'),
+    ('1101', 'Wheat or meslin flour'),
+    ('1507', 'Soya-bean oil and its fractions'),
+    ('1701', 'Cane or beet sugar'),
+    ('1905', 'Bread, pastry, cakes, biscuits and other bakers'' wares'),
+    ('2106', 'Food preparations not elsewhere specified'),
+    ('2202', 'Waters and non-alcoholic beverages with added sugar or flavour'),
+    ('2523', 'Portland cement and similar hydraulic cements'),
+    ('3004', 'Medicaments in measured doses or packed for retail sale'),
+    ('3304', 'Beauty, make-up and skin-care preparations'),
+    ('3305', 'Preparations for use on the hair'),
+    ('3401', 'Soap and organic surface-active products'),
+    ('3402', 'Washing and cleaning preparations'),
+    ('3923', 'Plastic articles for the conveyance or packing of goods'),
+    ('4011', 'New pneumatic tyres, of rubber'),
+    ('4802', 'Uncoated paper for writing, printing or other graphic purposes'),
+    ('4820', 'Registers, notebooks, diaries and similar stationery'),
+    ('5208', 'Woven fabrics of cotton'),
+    ('6109', 'T-shirts, singlets and other vests, knitted'),
+    ('6203', 'Men''s suits, jackets, trousers and shorts'),
+    ('6204', 'Women''s suits, dresses, skirts and trousers'),
+    ('6403', 'Footwear with outer soles of rubber or plastics and uppers of leather'),
+    ('7214', 'Bars and rods of iron or non-alloy steel'),
+    ('7323', 'Table, kitchen and household articles of iron or steel'),
+    ('8414', 'Air or vacuum pumps, compressors and fans'),
+    ('8415', 'Air conditioning machines'),
+    ('8418', 'Refrigerators, freezers and other refrigerating equipment'),
+    ('8443', 'Printing machinery; printers, copying machines'),
+    ('8471', 'Automatic data processing machines (computers)'),
+    ('847130', 'Portable computers weighing not more than 10 kg (laptops)'),
+    ('84713000', 'Portable automatic data processing machines, not more than 10 kg'),
+    ('8504', 'Electrical transformers, static converters and inductors'),
+    ('8507', 'Electric accumulators (batteries)'),
+    ('8516', 'Electric water heaters, hair dryers, irons and cooking appliances'),
+    ('8517', 'Telephone sets, including smartphones, and other communication apparatus'),
+    ('851713', 'Smartphones'),
+    ('85171300', 'Smartphones for cellular networks'),
+    ('8528', 'Monitors, projectors and television receivers'),
+    ('8539', 'Electric filament or discharge lamps, LED lamps'),
+    ('8544', 'Insulated wire and cable'),
+    ('8703', 'Motor cars and other motor vehicles for transport of persons'),
+    ('8711', 'Motorcycles and cycles fitted with an auxiliary motor'),
+    ('9018', 'Medical, surgical and dental instruments'),
+    ('9403', 'Other furniture and parts thereof'),
+    ('9503', 'Toys, scale models and puzzles'),
+    ('9608', 'Ball point pens, felt tipped pens and markers')
+ON CONFLICT (code) DO NOTHING;
+
+-- ============================================================================
+-- PRODUCT ASSIGNMENT
+-- ============================================================================
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS hs_code VARCHAR(8);
+ALTER TABLE products ADD CONSTRAINT chk_products_hs_code_format
+    CHECK (hs_code IS NULL OR hs_code ~ '^([0-9]{4}|[0-9]{6}|[0-9]{8})$');
+
+CREATE INDEX IF NOT EXISTS idx_products_hs_code ON products(tenant_id, hs_code);
+
+COMMENT ON COLUMN products.hs_code IS 'Harmonized System code (4, 6 or 8 digits, no separators)';
+
+-- ============================================================================
+-- SUMMARY
+-- ============================================================================
+-- Tables created: 1 (hs_codes, seeded with common headings)
+-- Columns added: products.hs_code
@@ -0,0 +1,27 @@
+-- Catalog Module: Per-user Favorite and Recently Viewed Products
+-- Migration: 005_create_product_quick_picks.sql
+
+CREATE TABLE IF NOT EXISTS product_quick_picks (
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    is_favorite BOOLEAN NOT NULL DEFAULT false,
+    position INTEGER NOT NULL DEFAULT 0,
+    view_count INTEGER NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP,
+    PRIMARY KEY (user_id, product_id)
+);
+
+-- Grid lookup: favorites first, then most recent views
+CREATE INDEX IF NOT EXISTS idx_product_quick_picks_grid
+    ON product_quick_picks(tenant_id, user_id, is_favorite DESC, position, last_viewed_at DESC);
+
+ALTER TABLE product_quick_picks ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON product_quick_picks
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE product_quick_picks IS 'POS quick-pick grid: pinned favorites and the last 50 viewed products per user';
@@ -0,0 +1,80 @@
+-- Catalog Module: Cost-based Markup Rules and Repricing
+-- Migration: 006_create_markup_rules.sql
+
+CREATE TABLE IF NOT EXISTS markup_rules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    product_id UUID REFERENCES products(id) ON DELETE CASCADE,
+    category_id UUID REFERENCES categories(id) ON DELETE CASCADE,
+    markup_percent DECIMAL(7, 2) NOT NULL,
+    round_to DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    require_approval BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_markup_rules_target CHECK ((product_id IS NULL) <> (category_id IS NULL))
+);
+
+-- One rule per product and per category
+CREATE UNIQUE INDEX IF NOT EXISTS idx_markup_rules_product ON markup_rules(tenant_id, product_id) WHERE product_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_markup_rules_category ON markup_rules(tenant_id, category_id) WHERE category_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS repricing_runs (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    started_by UUID,
+    products_checked INTEGER NOT NULL DEFAULT 0,
+    prices_applied INTEGER NOT NULL DEFAULT 0,
+    pending_approval INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_repricing_runs_tenant ON repricing_runs(tenant_id, started_at DESC);
+
+CREATE TABLE IF NOT EXISTS price_changes (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    rule_id UUID NOT NULL,
+    run_id UUID REFERENCES repricing_runs(id) ON DELETE SET NULL,
+    source VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    cost_price DECIMAL(15, 2) NOT NULL,
+    old_price DECIMAL(15, 2) NOT NULL,
+    new_price DECIMAL(15, 2) NOT NULL,
+    reviewed_by UUID,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_changes_status ON price_changes(tenant_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_price_changes_run ON price_changes(run_id);
+CREATE INDEX IF NOT EXISTS idx_price_changes_product ON price_changes(product_id, status);
+
+ALTER TABLE markup_rules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE repricing_runs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE price_changes ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON markup_rules
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON repricing_runs
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON price_changes
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE markup_rules IS 'Selling price = cost + markup%, per product or category';
+COMMENT ON TABLE price_changes IS 'Prices recomputed from markup rules; pending rows await approval';
+COMMENT ON TABLE repricing_runs IS 'Nightly and manual repricing passes with their change totals';
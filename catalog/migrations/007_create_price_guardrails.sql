@@ -0,0 +1,47 @@
+-- Catalog Module: MRP Compliance and Discount Guardrails
+-- Migration: 007_create_price_guardrails.sql
+
+CREATE TABLE IF NOT EXISTS discount_limits (
+    tenant_id UUID NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    max_discount_percent DECIMAL(5, 2) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, role),
+    CONSTRAINT chk_discount_limits_percent CHECK (max_discount_percent BETWEEN 0 AND 100)
+);
+
+CREATE TABLE IF NOT EXISTS discount_overrides (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    list_price DECIMAL(15, 2) NOT NULL,
+    sale_price DECIMAL(15, 2) NOT NULL,
+    discount_percent DECIMAL(5, 2) NOT NULL,
+    reason TEXT NOT NULL,
+    requested_by UUID,
+    approved_by UUID,
+    approver_role VARCHAR(50) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_discount_overrides_tenant ON discount_overrides(tenant_id, created_at DESC);
+
+ALTER TABLE discount_limits ENABLE ROW LEVEL SECURITY;
+ALTER TABLE discount_overrides ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON discount_limits
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON discount_overrides
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE discount_limits IS 'Maximum sale-time discount per role; roles without a row are unrestricted';
+COMMENT ON TABLE discount_overrides IS 'One-time approvals to exceed a discount limit, with the reason given';
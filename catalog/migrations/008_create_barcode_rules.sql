@@ -0,0 +1,31 @@
+-- Catalog Module: Scale Label (Price/Weight-embedded) Barcode Rules
+-- Migration: 008_create_barcode_rules.sql
+
+CREATE TABLE IF NOT EXISTS barcode_rules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(3) NOT NULL,
+    item_code_digits SMALLINT NOT NULL,
+    value_digits SMALLINT NOT NULL,
+    value_type VARCHAR(10) NOT NULL,
+    decimals SMALLINT NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_barcode_rules_value_type CHECK (value_type IN ('weight', 'price')),
+    CONSTRAINT chk_barcode_rules_length CHECK (LENGTH(prefix) + item_code_digits + value_digits + 1 <= 13)
+);
+
+-- A prefix maps to exactly one layout per tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_barcode_rules_prefix ON barcode_rules(tenant_id, prefix);
+
+ALTER TABLE barcode_rules ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON barcode_rules
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE barcode_rules IS 'Per-tenant layouts for scale-printed barcodes that embed weight or price (GS1 prefixes 20-29)';
@@ -0,0 +1,75 @@
+-- Catalog Module: Bills of Materials and Assembly Orders
+-- Migration: 009_create_assembly_orders.sql
+
+CREATE TABLE IF NOT EXISTS bom_components (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    component_id UUID NOT NULL REFERENCES products(id),
+    quantity DECIMAL(15, 3) NOT NULL,
+    waste_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_bom_components UNIQUE (product_id, component_id),
+    CONSTRAINT chk_bom_components_self CHECK (product_id <> component_id),
+    CONSTRAINT chk_bom_components_qty CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bom_components_product ON bom_components(tenant_id, product_id);
+CREATE INDEX IF NOT EXISTS idx_bom_components_component ON bom_components(component_id);
+
+CREATE TABLE IF NOT EXISTS assembly_orders (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    order_number VARCHAR(50) NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id),
+    quantity DECIMAL(15, 3) NOT NULL,
+    produced_qty DECIMAL(15, 3) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    material_cost DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    overhead_cost DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    total_cost DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    unit_cost DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    wip_journal_entry_id UUID,
+    finished_journal_entry_id UUID,
+    note TEXT,
+    created_by UUID,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_assembly_orders_number UNIQUE (tenant_id, order_number),
+    CONSTRAINT chk_assembly_orders_status CHECK (status IN ('draft', 'in_progress', 'completed', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_assembly_orders_status ON assembly_orders(tenant_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_assembly_orders_product ON assembly_orders(product_id);
+
+CREATE TABLE IF NOT EXISTS assembly_order_components (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES assembly_orders(id) ON DELETE CASCADE,
+    component_id UUID NOT NULL REFERENCES products(id),
+    quantity DECIMAL(15, 3) NOT NULL,
+    unit_cost DECIMAL(15, 2) NOT NULL,
+    cost DECIMAL(15, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_assembly_order_components_order ON assembly_order_components(order_id);
+
+ALTER TABLE bom_components ENABLE ROW LEVEL SECURITY;
+ALTER TABLE assembly_orders ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON bom_components
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON assembly_orders
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE bom_components IS 'Bill of materials: components per unit of an assembled product';
+COMMENT ON TABLE assembly_orders IS 'Production runs that consume BOM components and produce finished stock at rolled-up cost';
+COMMENT ON TABLE assembly_order_components IS 'Components consumed by an assembly order, costed when planned';
@@ -0,0 +1,31 @@
+-- Catalog Module: Pack Barcodes
+-- Migration: 010_create_product_barcodes.sql
+
+CREATE TABLE IF NOT EXISTS product_barcodes (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    barcode VARCHAR(100) NOT NULL,
+    unit VARCHAR(20) NOT NULL,
+    quantity DECIMAL(15, 3) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_product_barcodes_barcode UNIQUE (tenant_id, barcode),
+    CONSTRAINT chk_product_barcodes_qty CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_barcodes_product ON product_barcodes(tenant_id, product_id);
+
+-- Uniqueness checks look up a tenant's own product barcodes as well
+CREATE INDEX IF NOT EXISTS idx_products_tenant_barcode ON products(tenant_id, barcode) WHERE barcode IS NOT NULL;
+
+ALTER TABLE product_barcodes ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON product_barcodes
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE product_barcodes IS 'Extra barcodes printed on packs of a product (strip, box), each ringing up a quantity of base units';
+COMMENT ON COLUMN product_barcodes.unit IS 'Pack unit code from units_of_measure';
+COMMENT ON COLUMN product_barcodes.quantity IS 'Product base units per pack';
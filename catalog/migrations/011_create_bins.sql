@@ -0,0 +1,74 @@
+-- Catalog Module: Shelf/Bin Locations
+-- Migration: 011_create_bins.sql
+
+CREATE TABLE IF NOT EXISTS bins (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    code VARCHAR(30) NOT NULL,
+    zone VARCHAR(100),
+    description TEXT,
+    pick_sequence INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_bins_code UNIQUE (tenant_id, code),
+    CONSTRAINT chk_bins_pick_sequence CHECK (pick_sequence >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bins_pick_order ON bins(tenant_id, pick_sequence, code);
+
+CREATE TABLE IF NOT EXISTS bin_stock (
+    tenant_id UUID NOT NULL,
+    bin_id UUID NOT NULL REFERENCES bins(id),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity DECIMAL(15, 3) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (bin_id, product_id),
+    CONSTRAINT chk_bin_stock_qty CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bin_stock_product ON bin_stock(tenant_id, product_id);
+
+-- Movements keep the IDs of bins deleted later, so they reference no table
+CREATE TABLE IF NOT EXISTS bin_movements (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    movement_type VARCHAR(20) NOT NULL,
+    from_bin_id UUID,
+    to_bin_id UUID,
+    quantity DECIMAL(15, 3) NOT NULL,
+    note TEXT,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_bin_movements_type CHECK (movement_type IN ('count', 'transfer'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_bin_movements_tenant ON bin_movements(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_bin_movements_product ON bin_movements(tenant_id, product_id, created_at DESC);
+
+ALTER TABLE bins ENABLE ROW LEVEL SECURITY;
+ALTER TABLE bin_stock ENABLE ROW LEVEL SECURITY;
+ALTER TABLE bin_movements ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON bins
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON bin_stock
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON bin_movements
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE bins IS 'Shelf/rack locations in the store, walked in pick_sequence order';
+COMMENT ON TABLE bin_stock IS 'Quantity of each product held in each bin';
+COMMENT ON TABLE bin_movements IS 'Shelf counts and bin-to-bin transfers';
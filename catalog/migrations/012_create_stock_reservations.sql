@@ -0,0 +1,40 @@
+-- Catalog Module: Stock Reservations
+-- Migration: 012_create_stock_reservations.sql
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity DECIMAL(15, 3) NOT NULL,
+    reference_type VARCHAR(20) NOT NULL,
+    reference_id UUID NOT NULL,
+    reference_number VARCHAR(50),
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP NOT NULL,
+    note TEXT,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMP,
+    close_reason TEXT,
+    CONSTRAINT chk_stock_reservations_qty CHECK (quantity > 0),
+    CONSTRAINT chk_stock_reservations_reference CHECK (reference_type IN ('order', 'quote')),
+    CONSTRAINT chk_stock_reservations_status CHECK (status IN ('active', 'released', 'consumed', 'expired'))
+);
+
+-- Availability sums what active reservations hold per product
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_active ON stock_reservations(tenant_id, product_id, expires_at)
+    WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_reference ON stock_reservations(tenant_id, reference_type, reference_id);
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_created ON stock_reservations(tenant_id, created_at DESC);
+-- The expiry job scans every tenant
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_expiry ON stock_reservations(expires_at) WHERE status = 'active';
+
+ALTER TABLE stock_reservations ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON stock_reservations
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE stock_reservations IS 'Stock held for orders and quotes; available = on hand - active unexpired reservations';
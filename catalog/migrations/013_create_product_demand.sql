@@ -0,0 +1,31 @@
+-- Catalog Module: Product Demand Statistics
+-- Migration: 013_create_product_demand.sql
+
+CREATE TABLE IF NOT EXISTS product_demand (
+    tenant_id UUID NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sold_30 DECIMAL(15, 3) NOT NULL DEFAULT 0,
+    sold_90 DECIMAL(15, 3) NOT NULL DEFAULT 0,
+    avg_daily_30 DECIMAL(15, 4) NOT NULL DEFAULT 0,
+    avg_daily_90 DECIMAL(15, 4) NOT NULL DEFAULT 0,
+    base_daily DECIMAL(15, 4) NOT NULL DEFAULT 0,
+    seasonality DECIMAL(8, 4)[] NOT NULL,
+    first_sold_on DATE,
+    last_sold_on DATE,
+    computed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT pk_product_demand PRIMARY KEY (tenant_id, product_id),
+    CONSTRAINT chk_product_demand_seasonality CHECK (array_length(seasonality, 1) = 12)
+);
+
+-- Reorder suggestions read the fastest sellers first
+CREATE INDEX IF NOT EXISTS idx_product_demand_base ON product_demand(tenant_id, base_daily DESC);
+
+ALTER TABLE product_demand ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON product_demand
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE product_demand IS 'Nightly per-product sales velocity with a seasonal index per Bikram Sambat month (Baishakh first)';
@@ -0,0 +1,61 @@
+-- Catalog Module: Low-Stock and Expiry Alert Rules
+-- Migration: 014_create_alert_rules.sql
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    condition VARCHAR(20) NOT NULL,
+    threshold DECIMAL(15, 3) NOT NULL,
+    category_id UUID REFERENCES categories(id) ON DELETE CASCADE,
+    product_id UUID REFERENCES products(id) ON DELETE CASCADE,
+    recipients TEXT[] NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    last_evaluated_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_alert_rules_condition CHECK (condition IN ('low_stock', 'expiring')),
+    CONSTRAINT chk_alert_rules_threshold CHECK (threshold > 0),
+    CONSTRAINT chk_alert_rules_channel CHECK (channel IN ('EMAIL', 'SMS', 'WHATSAPP')),
+    CONSTRAINT chk_alert_rules_scope CHECK (category_id IS NULL OR product_id IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_tenant ON alert_rules(tenant_id, name);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_active ON alert_rules(tenant_id) WHERE is_active = true;
+
+-- One row per product tripping a rule; the open row is what stops daily re-sends
+CREATE TABLE IF NOT EXISTS stock_alerts (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    rule_id UUID NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    severity VARCHAR(20) NOT NULL,
+    detail TEXT NOT NULL,
+    raised_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    notified_at TIMESTAMP,
+    notified_count INTEGER NOT NULL DEFAULT 0,
+    resolved_at TIMESTAMP,
+    CONSTRAINT chk_stock_alerts_severity CHECK (severity IN ('warning', 'critical'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_stock_alerts_open ON stock_alerts(rule_id, product_id) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_alerts_tenant ON stock_alerts(tenant_id, raised_at DESC);
+
+ALTER TABLE alert_rules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE stock_alerts ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON alert_rules
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON stock_alerts
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE alert_rules IS 'Tenant-configured low-stock and expiry notification rules, evaluated daily';
+COMMENT ON TABLE stock_alerts IS 'Products tripping alert rules; re-notified only when severity rises';
@@ -0,0 +1,11 @@
+-- Catalog Module: Bins per Warehouse
+-- Migration: 015_add_bin_warehouses.sql
+-- Tenants with several outlets shelve stock in each. A bin belongs to one inventory
+-- warehouse; bins created before warehouses are placed in the tenant's default one
+-- by the inventory module's warehouse migration.
+
+ALTER TABLE bins ADD COLUMN IF NOT EXISTS warehouse_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_bins_warehouse ON bins(tenant_id, warehouse_id, pick_sequence, code);
+
+COMMENT ON COLUMN bins.warehouse_id IS 'Inventory warehouse the bin is in; pick lists can walk one warehouse''s bins';
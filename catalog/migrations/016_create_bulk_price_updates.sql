@@ -0,0 +1,54 @@
+-- Catalog Module: Bulk Price Updates
+-- Migration: 016_create_bulk_price_updates.sql
+-- A percent or amount adjustment applied at once to every product matching a filter
+-- (e.g. +5% on a category for the festival season). The old prices are kept per item
+-- so the update can be rolled back with its token for 24 hours.
+
+CREATE TABLE IF NOT EXISTS bulk_price_updates (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    filter JSONB NOT NULL,
+    adjustment JSONB NOT NULL,
+    products_matched INTEGER NOT NULL DEFAULT 0,
+    products_changed INTEGER NOT NULL DEFAULT 0,
+    rollback_token VARCHAR(64) NOT NULL,
+    rollback_expires_at TIMESTAMP NOT NULL,
+    applied_by UUID,
+    applied_at TIMESTAMP NOT NULL,
+    rolled_back_by UUID,
+    rolled_back_at TIMESTAMP,
+    CONSTRAINT uq_bulk_price_updates_token UNIQUE (tenant_id, rollback_token)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bulk_price_updates_tenant ON bulk_price_updates(tenant_id, applied_at DESC);
+
+CREATE TABLE IF NOT EXISTS bulk_price_update_items (
+    update_id UUID NOT NULL REFERENCES bulk_price_updates(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    product_code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    cost_price DECIMAL(15, 2) NOT NULL,
+    old_price DECIMAL(15, 2) NOT NULL,
+    new_price DECIMAL(15, 2) NOT NULL,
+    flags JSONB NOT NULL DEFAULT '[]'::jsonb,
+    PRIMARY KEY (update_id, product_id)
+);
+
+ALTER TABLE bulk_price_updates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE bulk_price_update_items ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON bulk_price_updates
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON bulk_price_update_items
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE bulk_price_updates IS 'Filter-wide price adjustments, rollable back by token until rollback_expires_at';
+COMMENT ON TABLE bulk_price_update_items IS 'Each changed product''s price before and after a bulk update';
@@ -0,0 +1,7 @@
+-- Migration: Keyset pagination indexes
+-- Product and category lists page by their sort key instead of OFFSET, so each page
+-- is an index range scan however deep it is.
+
+CREATE INDEX IF NOT EXISTS idx_products_tenant_created ON products(tenant_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_categories_tenant_sort ON categories(tenant_id, sort_order, name, id);
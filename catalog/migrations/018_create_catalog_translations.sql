@@ -0,0 +1,46 @@
+-- Catalog Module: Product and Category Translations
+-- Migration: 018_create_catalog_translations.sql
+-- Products and categories keep their name and description in the catalog's own
+-- language; translations hold the same text in other languages for readers and
+-- printed documents that ask for them.
+
+CREATE TABLE IF NOT EXISTS catalog_translations (
+    tenant_id UUID NOT NULL,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id UUID NOT NULL,
+    locale VARCHAR(3) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (entity_type, entity_id, locale),
+    CONSTRAINT chk_catalog_translations_entity CHECK (entity_type IN ('product', 'category'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_catalog_translations_tenant ON catalog_translations(tenant_id, entity_type, locale);
+
+-- The language each document type's product names are printed in
+CREATE TABLE IF NOT EXISTS document_locales (
+    tenant_id UUID NOT NULL,
+    document_type VARCHAR(50) NOT NULL,
+    locale VARCHAR(3) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, document_type)
+);
+
+ALTER TABLE catalog_translations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE document_locales ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON catalog_translations
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON document_locales
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE catalog_translations IS 'Product and category names and descriptions in other languages';
+COMMENT ON TABLE document_locales IS 'Language product names are printed in, per tenant and document type';
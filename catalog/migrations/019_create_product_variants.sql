@@ -0,0 +1,29 @@
+-- Catalog Module: Product Variants
+-- Migration: 019_create_product_variants.sql
+-- A variant is a product of its own, with its own code, SKU, price and stock. This
+-- links it to the product it was generated from and records the attribute values,
+-- e.g. size M and color red, that set it apart from its siblings.
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    product_id UUID PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    parent_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    attributes JSONB NOT NULL,
+    variant_key VARCHAR(500) NOT NULL,   -- name=value pairs in name order, lower case
+    price_delta DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_product_variants_key UNIQUE (tenant_id, parent_id, variant_key),
+    CONSTRAINT chk_product_variants_parent CHECK (parent_id <> product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_parent ON product_variants(parent_id, created_at);
+
+ALTER TABLE product_variants ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON product_variants
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE product_variants IS 'Products generated from another product''s attribute matrix, with their attribute values';
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// AlertChanges is what one evaluation of a rule changed
+type AlertChanges struct {
+	Raised    []*domain.StockAlert
+	Escalated []*domain.StockAlert
+	Resolved  []uuid.UUID // IDs of open alerts whose condition cleared
+}
+
+// AlertRuleRepository defines the interface for alert rule and stock alert data access
+type AlertRuleRepository interface {
+	CreateRule(ctx context.Context, rule *domain.AlertRule) error
+	GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.AlertRule, error)
+	ListRules(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*domain.AlertRule, error)
+	UpdateRule(ctx context.Context, rule *domain.AlertRule) error
+	DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error
+	// ListTenantsWithRules returns tenants with at least one active rule
+	ListTenantsWithRules(ctx context.Context) ([]uuid.UUID, error)
+	// Candidates returns the active products in the rule's scope. For expiring rules
+	// only products with an expiry date on or before expiringBy are returned.
+	Candidates(ctx context.Context, rule *domain.AlertRule, expiringBy time.Time) ([]domain.AlertCandidate, error)
+	// OpenAlerts returns the rule's unresolved alerts by product
+	OpenAlerts(ctx context.Context, tenantID, ruleID uuid.UUID) (map[uuid.UUID]*domain.StockAlert, error)
+	// RecordEvaluation saves the changes and the rule's evaluation time together
+	RecordEvaluation(ctx context.Context, rule *domain.AlertRule, changes AlertChanges, evaluatedAt time.Time) error
+	ListAlerts(ctx context.Context, tenantID uuid.UUID, filter domain.AlertFilter) ([]*domain.StockAlert, error)
+}
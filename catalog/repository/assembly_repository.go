@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// AssemblyRepository defines the interface for bills of materials and assembly orders
+type AssemblyRepository interface {
+	// Bills of materials
+	// GetBOM returns ErrBOMNotFound when the product has no components
+	GetBOM(ctx context.Context, tenantID, productID uuid.UUID) (*domain.BillOfMaterials, error)
+	// SaveBOM replaces the product's components
+	SaveBOM(ctx context.Context, bom *domain.BillOfMaterials) error
+	DeleteBOM(ctx context.Context, tenantID, productID uuid.UUID) error
+
+	// Assembly orders
+	// CreateOrder saves an order with its components, assigning the next order number
+	CreateOrder(ctx context.Context, order *domain.AssemblyOrder) error
+	GetOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.AssemblyOrder, error)
+	ListOrders(ctx context.Context, tenantID uuid.UUID, status domain.AssemblyOrderStatus, limit, offset int) ([]*domain.AssemblyOrder, error)
+	// UpdateOrder stores the order's status, output and cost roll-up
+	UpdateOrder(ctx context.Context, order *domain.AssemblyOrder) error
+	SetJournalEntry(ctx context.Context, orderID uuid.UUID, stage domain.AssemblyStage, journalEntryID uuid.UUID) error
+}
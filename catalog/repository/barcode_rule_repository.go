@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// BarcodeRuleRepository defines the interface for scale barcode rule data access
+type BarcodeRuleRepository interface {
+	Create(ctx context.Context, rule *domain.BarcodeRule) error
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.BarcodeRule, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]*domain.BarcodeRule, error)
+	// ListActive returns the tenant's active rules, longest prefix first
+	ListActive(ctx context.Context, tenantID uuid.UUID) ([]*domain.BarcodeRule, error)
+	Update(ctx context.Context, rule *domain.BarcodeRule) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+}
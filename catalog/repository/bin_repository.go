@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// BinRepository defines the interface for bin locations and the stock held in them
+type BinRepository interface {
+	// Create returns ErrBinCodeExists when the tenant already has a bin with the code
+	Create(ctx context.Context, bin *domain.Bin) error
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Bin, error)
+	// List returns the tenant's bins in pick order
+	List(ctx context.Context, tenantID uuid.UUID) ([]*domain.Bin, error)
+	Update(ctx context.Context, bin *domain.Bin) error
+	// Delete returns ErrBinNotEmpty while the bin holds stock
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+
+	// ListStockByBin returns what a bin holds
+	ListStockByBin(ctx context.Context, tenantID, binID uuid.UUID) ([]domain.BinStock, error)
+	// ListStockByProducts returns the bins holding the products, in pick order,
+	// skipping inactive bins when activeOnly
+	ListStockByProducts(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID, activeOnly bool) ([]domain.BinStock, error)
+	// SetStock sets a product's quantity in a bin and records the difference as a
+	// count movement, which it fills in; nothing is recorded when the quantity is unchanged
+	SetStock(ctx context.Context, movement *domain.BinMovement, quantity float64) error
+	// Transfer moves stock between bins, returning ErrInsufficientBinStock when the
+	// source bin holds less than the movement's quantity
+	Transfer(ctx context.Context, movement *domain.BinMovement) error
+	// ListMovements returns movements newest first, of one product when productID is set
+	ListMovements(ctx context.Context, tenantID uuid.UUID, productID *uuid.UUID, limit, offset int) ([]*domain.BinMovement, error)
+}
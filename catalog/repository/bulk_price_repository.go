@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// BulkPriceRepository defines the interface for applying and rolling back bulk price updates
+type BulkPriceRepository interface {
+	// Apply sets the items' new prices and records the update in one transaction.
+	// If any product's price is no longer the item's old price, nothing is applied
+	// and domain.ErrBulkPriceConflict is returned.
+	Apply(ctx context.Context, update *domain.BulkPriceUpdate) error
+	// GetByToken retrieves an update with its items by rollback token
+	GetByToken(ctx context.Context, tenantID uuid.UUID, token string) (*domain.BulkPriceUpdate, error)
+	// Rollback restores the old price of each item still at its new price and marks the
+	// update rolled back in one transaction; it returns the restored products
+	Rollback(ctx context.Context, update *domain.BulkPriceUpdate, rolledBackBy *uuid.UUID, rolledBackAt time.Time) ([]uuid.UUID, error)
+}
@@ -4,19 +4,22 @@ import (
 	"context"
 
 	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
 	"github.com/google/uuid"
 )
 
 // CategoryRepository defines the interface for category data access
 type CategoryRepository interface {
 	Create(ctx context.Context, category *domain.Category) error
-	GetByID(ctx context.Context, id uuid.UUID) (*domain.Category, error)
+	// Single-record operations are scoped to the tenant; another tenant's category is ErrCategoryNotFound
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Category, error)
 	GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.Category, error)
-	GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Category, error)
+	// List retrieves a page of categories in display order (sort order, then name) with the total
+	List(ctx context.Context, tenantID uuid.UUID, page db.PageRequest) ([]*domain.Category, int64, error)
 	GetRootCategories(ctx context.Context, tenantID uuid.UUID) ([]*domain.Category, error)
 	GetChildren(ctx context.Context, parentID uuid.UUID) ([]*domain.Category, error)
 	Update(ctx context.Context, category *domain.Category) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
 	Search(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) ([]*domain.Category, error)
 	Count(ctx context.Context, tenantID uuid.UUID) (int64, error)
 	GetNextCategoryNumber(ctx context.Context, tenantID uuid.UUID) (int64, error)
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// DemandRepository defines the interface for product demand statistics data access
+type DemandRepository interface {
+	// ListTenants returns the tenants with active products
+	ListTenants(ctx context.Context) ([]uuid.UUID, error)
+	// Totals sums each of the tenant's products sold in the sources from from until to.
+	// days and months pair each day of the window with its Bikram Sambat month.
+	Totals(ctx context.Context, sources []domain.DemandSource, tenantID uuid.UUID, from, to time.Time, days []time.Time, months []int) ([]domain.DemandTotals, error)
+	// Replace swaps the tenant's statistics for a freshly computed set
+	Replace(ctx context.Context, tenantID uuid.UUID, demands []*domain.ProductDemand) error
+	GetByProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.ProductDemand, error)
+	// List returns statistics of active products, fastest selling first
+	List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.ProductDemand, error)
+}
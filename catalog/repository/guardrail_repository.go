@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// GuardrailRepository defines the interface for discount limits and overrides
+type GuardrailRepository interface {
+	// GetDiscountLimit returns the role's limit, or nil if the role is unrestricted
+	GetDiscountLimit(ctx context.Context, tenantID uuid.UUID, role string) (*domain.DiscountLimit, error)
+	ListDiscountLimits(ctx context.Context, tenantID uuid.UUID) ([]*domain.DiscountLimit, error)
+	// SetDiscountLimit creates or replaces a role's limit
+	SetDiscountLimit(ctx context.Context, limit *domain.DiscountLimit) error
+	DeleteDiscountLimit(ctx context.Context, tenantID uuid.UUID, role string) error
+
+	CreateOverride(ctx context.Context, override *domain.DiscountOverride) error
+	GetOverride(ctx context.Context, tenantID, id uuid.UUID) (*domain.DiscountOverride, error)
+	// MarkOverrideUsed redeems an override; returns false if it was already used
+	MarkOverrideUsed(ctx context.Context, tenantID, id uuid.UUID) (bool, error)
+	ListOverrides(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.DiscountOverride, error)
+}
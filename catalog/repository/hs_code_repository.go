@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+)
+
+// HSCodeRepository defines the interface for the built-in HS code list
+type HSCodeRepository interface {
+	GetByCode(ctx context.Context, code string) (*domain.HSCode, error)
+	// Search matches codes by prefix and descriptions by text
+	Search(ctx context.Context, query string, limit int) ([]*domain.HSCode, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// LocalizationRepository defines the interface for catalog translations and document locales
+type LocalizationRepository interface {
+	// SaveTranslation creates or replaces an entity's translation in its locale
+	SaveTranslation(ctx context.Context, translation *domain.Translation) error
+	// DeleteTranslation returns ErrTranslationNotFound if the entity has no translation in the locale
+	DeleteTranslation(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, entityID uuid.UUID, locale string) error
+	// ListTranslations returns an entity's translations ordered by locale
+	ListTranslations(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, entityID uuid.UUID) ([]*domain.Translation, error)
+	// FindTranslations returns the translations of the entities in any of the locales
+	FindTranslations(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, entityIDs []uuid.UUID, locales []string) ([]*domain.Translation, error)
+
+	// SaveDocumentLocale creates or replaces the locale of a document type
+	SaveDocumentLocale(ctx context.Context, setting *domain.DocumentLocale) error
+	// DeleteDocumentLocale returns ErrDocumentLocaleNotFound if the document type has no locale set
+	DeleteDocumentLocale(ctx context.Context, tenantID uuid.UUID, documentType string) error
+	ListDocumentLocales(ctx context.Context, tenantID uuid.UUID) ([]*domain.DocumentLocale, error)
+}
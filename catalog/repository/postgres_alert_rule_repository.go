@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresAlertRuleRepository implements AlertRuleRepository using PostgreSQL
+type PostgresAlertRuleRepository struct{}
+
+// NewPostgresAlertRuleRepository creates a new PostgreSQL alert rule repository
+func NewPostgresAlertRuleRepository() *PostgresAlertRuleRepository {
+	return &PostgresAlertRuleRepository{}
+}
+
+const alertRuleColumns = `
+	id, tenant_id, name, condition, threshold, category_id, product_id, recipients, channel,
+	is_active, last_evaluated_at, created_at, updated_at
+`
+
+// CreateRule inserts an alert rule
+func (r *PostgresAlertRuleRepository) CreateRule(ctx context.Context, rule *domain.AlertRule) error {
+	query := `INSERT INTO alert_rules (` + alertRuleColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		rule.ID, rule.TenantID, rule.Name, rule.Condition, rule.Threshold, rule.CategoryID, rule.ProductID,
+		rule.Recipients, rule.Channel, rule.IsActive, rule.LastEvaluatedAt, rule.CreatedAt, rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create alert rule: %w", err)
+	}
+
+	return nil
+}
+
+// GetRule retrieves a tenant's alert rule
+func (r *PostgresAlertRuleRepository) GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.AlertRule, error) {
+	query := `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE tenant_id = $1 AND id = $2`
+
+	rule, err := scanAlertRule(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrAlertRuleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// ListRules retrieves the tenant's alert rules by name
+func (r *PostgresAlertRuleRepository) ListRules(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*domain.AlertRule, error) {
+	query := `
+		SELECT ` + alertRuleColumns + ` FROM alert_rules
+		WHERE tenant_id = $1 AND (is_active = true OR NOT $2)
+		ORDER BY name
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*domain.AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return rules, nil
+}
+
+// UpdateRule updates an alert rule
+func (r *PostgresAlertRuleRepository) UpdateRule(ctx context.Context, rule *domain.AlertRule) error {
+	query := `
+		UPDATE alert_rules
+		SET name = $1, condition = $2, threshold = $3, category_id = $4, product_id = $5,
+		    recipients = $6, channel = $7, is_active = $8, updated_at = $9
+		WHERE tenant_id = $10 AND id = $11
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		rule.Name, rule.Condition, rule.Threshold, rule.CategoryID, rule.ProductID,
+		rule.Recipients, rule.Channel, rule.IsActive, rule.UpdatedAt,
+		rule.TenantID, rule.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+// DeleteRule deletes an alert rule with its alerts
+func (r *PostgresAlertRuleRepository) DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := db.MainPool.Exec(ctx, `DELETE FROM alert_rules WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+// ListTenantsWithRules retrieves tenants with at least one active alert rule
+func (r *PostgresAlertRuleRepository) ListTenantsWithRules(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := db.MainPool.Query(ctx, `SELECT DISTINCT tenant_id FROM alert_rules WHERE is_active = true`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants with alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []uuid.UUID{}
+	for rows.Next() {
+		var tenantID uuid.UUID
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, tenantID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// Candidates retrieves the rule's products. A category covers its subcategories,
+// whose materialized paths contain it. Expiry dates are kept as YYYY-MM-DD custom
+// attributes, which compare as text; malformed ones are skipped.
+func (r *PostgresAlertRuleRepository) Candidates(ctx context.Context, rule *domain.AlertRule, expiringBy time.Time) ([]domain.AlertCandidate, error) {
+	query := `
+		SELECT p.id, p.product_code, p.name, p.unit,
+		       CASE WHEN p.custom_attributes->>'expiry_date' ~ '^\d{4}-\d{2}-\d{2}$'
+		            THEN p.custom_attributes->>'expiry_date' END
+		FROM products p
+		JOIN categories c ON c.id = p.category_id
+		WHERE p.tenant_id = $1 AND p.is_active = true
+		  AND ($2::uuid IS NULL OR p.id = $2)
+		  AND ($3::uuid IS NULL OR position('/' || $3::text IN c.path) > 0)
+		  AND (NOT $4 OR (p.custom_attributes->>'expiry_date' ~ '^\d{4}-\d{2}-\d{2}$'
+		                  AND p.custom_attributes->>'expiry_date' <= $5))
+		ORDER BY p.product_code
+	`
+
+	rows, err := db.MainPool.Query(ctx, query,
+		rule.TenantID, rule.ProductID, rule.CategoryID,
+		rule.Condition == domain.AlertExpiring, expiringBy.Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert candidates: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []domain.AlertCandidate{}
+	for rows.Next() {
+		var candidate domain.AlertCandidate
+		var expiry *string
+		if err := rows.Scan(&candidate.ProductID, &candidate.ProductCode, &candidate.ProductName, &candidate.Unit, &expiry); err != nil {
+			return nil, fmt.Errorf("failed to scan alert candidate: %w", err)
+		}
+		if expiry != nil {
+			if date, err := time.Parse("2006-01-02", *expiry); err == nil {
+				candidate.ExpiryDate = &date
+			}
+		}
+		if rule.Condition == domain.AlertExpiring && candidate.ExpiryDate == nil {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return candidates, nil
+}
+
+const stockAlertColumns = `
+	a.id, a.tenant_id, a.rule_id, a.product_id, p.product_code, p.name, a.severity, a.detail,
+	a.raised_at, a.notified_at, a.notified_count, a.resolved_at
+`
+
+// OpenAlerts retrieves the rule's unresolved alerts keyed by product
+func (r *PostgresAlertRuleRepository) OpenAlerts(ctx context.Context, tenantID, ruleID uuid.UUID) (map[uuid.UUID]*domain.StockAlert, error) {
+	query := `
+		SELECT ` + stockAlertColumns + `
+		FROM stock_alerts a
+		JOIN products p ON p.id = a.product_id
+		WHERE a.tenant_id = $1 AND a.rule_id = $2 AND a.resolved_at IS NULL
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open stock alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := make(map[uuid.UUID]*domain.StockAlert)
+	for rows.Next() {
+		alert, err := scanStockAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stock alert: %w", err)
+		}
+		alerts[alert.ProductID] = alert
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// RecordEvaluation inserts raised alerts, updates escalated ones, resolves
+// cleared ones and stamps the rule in one transaction
+func (r *PostgresAlertRuleRepository) RecordEvaluation(ctx context.Context, rule *domain.AlertRule, changes AlertChanges, evaluatedAt time.Time) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for _, alert := range changes.Raised {
+			batch.Queue(`
+				INSERT INTO stock_alerts (
+					id, tenant_id, rule_id, product_id, severity, detail, raised_at, notified_at, notified_count
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+				alert.ID, alert.TenantID, alert.RuleID, alert.ProductID, alert.Severity, alert.Detail,
+				alert.RaisedAt, alert.NotifiedAt, alert.NotifiedCount,
+			)
+		}
+		for _, alert := range changes.Escalated {
+			batch.Queue(`
+				UPDATE stock_alerts SET severity = $1, detail = $2, notified_at = $3, notified_count = $4
+				WHERE tenant_id = $5 AND id = $6`,
+				alert.Severity, alert.Detail, alert.NotifiedAt, alert.NotifiedCount, alert.TenantID, alert.ID,
+			)
+		}
+		if len(changes.Resolved) > 0 {
+			batch.Queue(`
+				UPDATE stock_alerts SET resolved_at = $1
+				WHERE tenant_id = $2 AND id = ANY($3) AND resolved_at IS NULL`,
+				evaluatedAt, rule.TenantID, changes.Resolved,
+			)
+		}
+		batch.Queue(`UPDATE alert_rules SET last_evaluated_at = $1 WHERE tenant_id = $2 AND id = $3`,
+			evaluatedAt, rule.TenantID, rule.ID,
+		)
+
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("failed to record alert evaluation: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ListAlerts retrieves stock alerts, newest first
+func (r *PostgresAlertRuleRepository) ListAlerts(ctx context.Context, tenantID uuid.UUID, filter domain.AlertFilter) ([]*domain.StockAlert, error) {
+	query := `
+		SELECT ` + stockAlertColumns + `
+		FROM stock_alerts a
+		JOIN products p ON p.id = a.product_id
+		WHERE a.tenant_id = $1
+		  AND ($2::uuid IS NULL OR a.rule_id = $2)
+		  AND (NOT $3 OR a.resolved_at IS NULL)
+		ORDER BY a.raised_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.RuleID, filter.OpenOnly, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stock alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []*domain.StockAlert{}
+	for rows.Next() {
+		alert, err := scanStockAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stock alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return alerts, nil
+}
+
+func scanAlertRule(row pgx.Row) (*domain.AlertRule, error) {
+	var rule domain.AlertRule
+	err := row.Scan(
+		&rule.ID, &rule.TenantID, &rule.Name, &rule.Condition, &rule.Threshold, &rule.CategoryID, &rule.ProductID,
+		&rule.Recipients, &rule.Channel, &rule.IsActive, &rule.LastEvaluatedAt, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func scanStockAlert(row pgx.Row) (*domain.StockAlert, error) {
+	var alert domain.StockAlert
+	err := row.Scan(
+		&alert.ID, &alert.TenantID, &alert.RuleID, &alert.ProductID, &alert.ProductCode, &alert.ProductName,
+		&alert.Severity, &alert.Detail, &alert.RaisedAt, &alert.NotifiedAt, &alert.NotifiedCount, &alert.ResolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresAssemblyRepository implements AssemblyRepository using PostgreSQL
+type PostgresAssemblyRepository struct{}
+
+// NewPostgresAssemblyRepository creates a new PostgreSQL assembly repository
+func NewPostgresAssemblyRepository() *PostgresAssemblyRepository {
+	return &PostgresAssemblyRepository{}
+}
+
+const assemblyOrderColumns = `id, tenant_id, order_number, product_id, quantity, produced_qty, status,
+	material_cost, overhead_cost, total_cost, unit_cost, wip_journal_entry_id, finished_journal_entry_id,
+	note, created_by, started_at, completed_at, created_at, updated_at`
+
+// GetBOM retrieves a product's bill of materials
+func (r *PostgresAssemblyRepository) GetBOM(ctx context.Context, tenantID, productID uuid.UUID) (*domain.BillOfMaterials, error) {
+	query := `
+		SELECT id, component_id, quantity, waste_percent, updated_at
+		FROM bom_components
+		WHERE tenant_id = $1 AND product_id = $2
+		ORDER BY component_id
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bill of materials: %w", err)
+	}
+	defer rows.Close()
+
+	bom := &domain.BillOfMaterials{
+		TenantID:   tenantID,
+		ProductID:  productID,
+		Components: []domain.BOMComponent{},
+	}
+	for rows.Next() {
+		var component domain.BOMComponent
+		if err := rows.Scan(
+			&component.ID, &component.ComponentID, &component.Quantity, &component.WastePercent, &bom.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan BOM component: %w", err)
+		}
+		bom.Components = append(bom.Components, component)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	if len(bom.Components) == 0 {
+		return nil, domain.ErrBOMNotFound
+	}
+
+	return bom, nil
+}
+
+// SaveBOM replaces a product's components
+func (r *PostgresAssemblyRepository) SaveBOM(ctx context.Context, bom *domain.BillOfMaterials) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			`DELETE FROM bom_components WHERE tenant_id = $1 AND product_id = $2`, bom.TenantID, bom.ProductID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to clear bill of materials: %w", err)
+		}
+
+		query := `
+			INSERT INTO bom_components (id, tenant_id, product_id, component_id, quantity, waste_percent, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		for _, component := range bom.Components {
+			_, err := tx.Exec(ctx, query,
+				component.ID, bom.TenantID, bom.ProductID, component.ComponentID,
+				component.Quantity, component.WastePercent, bom.UpdatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to save BOM component: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteBOM deletes a product's bill of materials
+func (r *PostgresAssemblyRepository) DeleteBOM(ctx context.Context, tenantID, productID uuid.UUID) error {
+	tag, err := db.MainPool.Exec(ctx,
+		`DELETE FROM bom_components WHERE tenant_id = $1 AND product_id = $2`, tenantID, productID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete bill of materials: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBOMNotFound
+	}
+
+	return nil
+}
+
+// CreateOrder creates an assembly order and its components
+func (r *PostgresAssemblyRepository) CreateOrder(ctx context.Context, order *domain.AssemblyOrder) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var next int64
+		err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) + 1 FROM assembly_orders WHERE tenant_id = $1`, order.TenantID,
+		).Scan(&next)
+		if err != nil {
+			return fmt.Errorf("failed to number assembly order: %w", err)
+		}
+		order.OrderNumber = fmt.Sprintf("ASM-%05d", next)
+
+		query := `INSERT INTO assembly_orders (` + assemblyOrderColumns + `)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`
+
+		_, err = tx.Exec(ctx, query,
+			order.ID, order.TenantID, order.OrderNumber, order.ProductID, order.Quantity, order.ProducedQty, order.Status,
+			order.MaterialCost, order.OverheadCost, order.TotalCost, order.UnitCost,
+			order.WIPJournalEntryID, order.FinishedJournalEntryID,
+			order.Note, order.CreatedBy, order.StartedAt, order.CompletedAt, order.CreatedAt, order.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create assembly order: %w", err)
+		}
+
+		componentQuery := `
+			INSERT INTO assembly_order_components (id, order_id, component_id, quantity, unit_cost, cost)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		for _, component := range order.Components {
+			_, err := tx.Exec(ctx, componentQuery,
+				component.ID, order.ID, component.ComponentID, component.Quantity, component.UnitCost, component.Cost,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create assembly order component: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetOrder retrieves an assembly order with its components
+func (r *PostgresAssemblyRepository) GetOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.AssemblyOrder, error) {
+	query := `SELECT ` + assemblyOrderColumns + ` FROM assembly_orders WHERE tenant_id = $1 AND id = $2`
+
+	order, err := r.scanOrder(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAssemblyOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get assembly order: %w", err)
+	}
+
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT id, order_id, component_id, quantity, unit_cost, cost
+		FROM assembly_order_components
+		WHERE order_id = $1
+		ORDER BY component_id
+	`, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assembly order components: %w", err)
+	}
+	defer rows.Close()
+
+	order.Components = []domain.AssemblyComponent{}
+	for rows.Next() {
+		var component domain.AssemblyComponent
+		if err := rows.Scan(
+			&component.ID, &component.OrderID, &component.ComponentID, &component.Quantity, &component.UnitCost, &component.Cost,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan assembly order component: %w", err)
+		}
+		order.Components = append(order.Components, component)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return order, nil
+}
+
+// ListOrders retrieves a tenant's assembly orders (without components), newest first
+func (r *PostgresAssemblyRepository) ListOrders(ctx context.Context, tenantID uuid.UUID, status domain.AssemblyOrderStatus, limit, offset int) ([]*domain.AssemblyOrder, error) {
+	query := `
+		SELECT ` + assemblyOrderColumns + `
+		FROM assembly_orders
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, string(status), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assembly orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []*domain.AssemblyOrder{}
+	for rows.Next() {
+		order, err := r.scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan assembly order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return orders, nil
+}
+
+// UpdateOrder stores an assembly order's progress and costs
+func (r *PostgresAssemblyRepository) UpdateOrder(ctx context.Context, order *domain.AssemblyOrder) error {
+	query := `
+		UPDATE assembly_orders
+		SET status = $1, produced_qty = $2, material_cost = $3, overhead_cost = $4, total_cost = $5,
+		    unit_cost = $6, started_at = $7, completed_at = $8, updated_at = $9
+		WHERE tenant_id = $10 AND id = $11
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		order.Status, order.ProducedQty, order.MaterialCost, order.OverheadCost, order.TotalCost,
+		order.UnitCost, order.StartedAt, order.CompletedAt, order.UpdatedAt,
+		order.TenantID, order.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update assembly order: %w", err)
+	}
+
+	return nil
+}
+
+// SetJournalEntry links an assembly order stage to the journal entry that booked it
+func (r *PostgresAssemblyRepository) SetJournalEntry(ctx context.Context, orderID uuid.UUID, stage domain.AssemblyStage, journalEntryID uuid.UUID) error {
+	column := "wip_journal_entry_id"
+	if stage == domain.AssemblyStageComplete {
+		column = "finished_journal_entry_id"
+	}
+
+	query := `UPDATE assembly_orders SET ` + column + ` = $1 WHERE id = $2`
+	if _, err := db.MainPool.Exec(ctx, query, journalEntryID, orderID); err != nil {
+		return fmt.Errorf("failed to link assembly order journal: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresAssemblyRepository) scanOrder(row pgx.Row) (*domain.AssemblyOrder, error) {
+	order := &domain.AssemblyOrder{}
+	err := row.Scan(
+		&order.ID, &order.TenantID, &order.OrderNumber, &order.ProductID, &order.Quantity, &order.ProducedQty, &order.Status,
+		&order.MaterialCost, &order.OverheadCost, &order.TotalCost, &order.UnitCost,
+		&order.WIPJournalEntryID, &order.FinishedJournalEntryID,
+		&order.Note, &order.CreatedBy, &order.StartedAt, &order.CompletedAt, &order.CreatedAt, &order.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
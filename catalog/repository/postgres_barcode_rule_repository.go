@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresBarcodeRuleRepository implements BarcodeRuleRepository using PostgreSQL
+type PostgresBarcodeRuleRepository struct{}
+
+// NewPostgresBarcodeRuleRepository creates a new PostgreSQL barcode rule repository
+func NewPostgresBarcodeRuleRepository() *PostgresBarcodeRuleRepository {
+	return &PostgresBarcodeRuleRepository{}
+}
+
+// Create creates a new barcode rule
+func (r *PostgresBarcodeRuleRepository) Create(ctx context.Context, rule *domain.BarcodeRule) error {
+	query := `
+		INSERT INTO barcode_rules (
+			id, tenant_id, name, prefix, item_code_digits, value_digits,
+			value_type, decimals, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		rule.ID, rule.TenantID, rule.Name, rule.Prefix, rule.ItemCodeDigits, rule.ValueDigits,
+		rule.ValueType, rule.Decimals, rule.IsActive, rule.CreatedAt, rule.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create barcode rule: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a tenant's barcode rule
+func (r *PostgresBarcodeRuleRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.BarcodeRule, error) {
+	query := `
+		SELECT id, tenant_id, name, prefix, item_code_digits, value_digits,
+		       value_type, decimals, is_active, created_at, updated_at
+		FROM barcode_rules
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	return r.scanRule(db.MainPool.QueryRow(ctx, query, tenantID, id))
+}
+
+// List retrieves all of a tenant's barcode rules
+func (r *PostgresBarcodeRuleRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*domain.BarcodeRule, error) {
+	query := `
+		SELECT id, tenant_id, name, prefix, item_code_digits, value_digits,
+		       value_type, decimals, is_active, created_at, updated_at
+		FROM barcode_rules
+		WHERE tenant_id = $1
+		ORDER BY prefix
+	`
+
+	return r.queryRules(ctx, query, tenantID)
+}
+
+// ListActive retrieves a tenant's active barcode rules, longest prefix first
+func (r *PostgresBarcodeRuleRepository) ListActive(ctx context.Context, tenantID uuid.UUID) ([]*domain.BarcodeRule, error) {
+	query := `
+		SELECT id, tenant_id, name, prefix, item_code_digits, value_digits,
+		       value_type, decimals, is_active, created_at, updated_at
+		FROM barcode_rules
+		WHERE tenant_id = $1 AND is_active = true
+		ORDER BY LENGTH(prefix) DESC, prefix
+	`
+
+	return r.queryRules(ctx, query, tenantID)
+}
+
+// Update updates a barcode rule
+func (r *PostgresBarcodeRuleRepository) Update(ctx context.Context, rule *domain.BarcodeRule) error {
+	query := `
+		UPDATE barcode_rules
+		SET name = $1, prefix = $2, item_code_digits = $3, value_digits = $4,
+		    value_type = $5, decimals = $6, is_active = $7, updated_at = $8
+		WHERE tenant_id = $9 AND id = $10
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		rule.Name, rule.Prefix, rule.ItemCodeDigits, rule.ValueDigits,
+		rule.ValueType, rule.Decimals, rule.IsActive, rule.UpdatedAt,
+		rule.TenantID, rule.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update barcode rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBarcodeRuleNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a barcode rule
+func (r *PostgresBarcodeRuleRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	query := `DELETE FROM barcode_rules WHERE tenant_id = $1 AND id = $2`
+
+	tag, err := db.MainPool.Exec(ctx, query, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete barcode rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBarcodeRuleNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresBarcodeRuleRepository) queryRules(ctx context.Context, query string, args ...interface{}) ([]*domain.BarcodeRule, error) {
+	rows, err := db.MainPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query barcode rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*domain.BarcodeRule{}
+	for rows.Next() {
+		rule, err := r.scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return rules, nil
+}
+
+// scanRule scans a single barcode rule row
+func (r *PostgresBarcodeRuleRepository) scanRule(row pgx.Row) (*domain.BarcodeRule, error) {
+	var rule domain.BarcodeRule
+
+	err := row.Scan(
+		&rule.ID, &rule.TenantID, &rule.Name, &rule.Prefix, &rule.ItemCodeDigits, &rule.ValueDigits,
+		&rule.ValueType, &rule.Decimals, &rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBarcodeRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to scan barcode rule: %w", err)
+	}
+
+	return &rule, nil
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresBinRepository implements BinRepository using PostgreSQL
+type PostgresBinRepository struct{}
+
+// NewPostgresBinRepository creates a new PostgreSQL bin repository
+func NewPostgresBinRepository() *PostgresBinRepository {
+	return &PostgresBinRepository{}
+}
+
+const binColumns = `id, tenant_id, warehouse_id, code, zone, description, pick_sequence, is_active, created_at, updated_at`
+
+// Create creates a new bin location
+func (r *PostgresBinRepository) Create(ctx context.Context, bin *domain.Bin) error {
+	query := `INSERT INTO bins (` + binColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		bin.ID, bin.TenantID, bin.WarehouseID, bin.Code, bin.Zone, bin.Description, bin.PickSequence,
+		bin.IsActive, bin.CreatedAt, bin.UpdatedAt,
+	)
+
+	if isBinCodeConflict(err) {
+		return fmt.Errorf("%w: %s", domain.ErrBinCodeExists, bin.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create bin: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a tenant's bin location
+func (r *PostgresBinRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Bin, error) {
+	query := `SELECT ` + binColumns + ` FROM bins WHERE tenant_id = $1 AND id = $2`
+
+	var bin domain.Bin
+	err := db.MainPool.QueryRow(ctx, query, tenantID, id).Scan(
+		&bin.ID, &bin.TenantID, &bin.WarehouseID, &bin.Code, &bin.Zone, &bin.Description, &bin.PickSequence,
+		&bin.IsActive, &bin.CreatedAt, &bin.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBinNotFound
+		}
+		return nil, fmt.Errorf("failed to get bin: %w", err)
+	}
+
+	return &bin, nil
+}
+
+// List retrieves the tenant's bin locations in pick order
+func (r *PostgresBinRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*domain.Bin, error) {
+	query := `SELECT ` + binColumns + ` FROM bins WHERE tenant_id = $1 ORDER BY pick_sequence, code`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bins: %w", err)
+	}
+	defer rows.Close()
+
+	bins := []*domain.Bin{}
+	for rows.Next() {
+		var bin domain.Bin
+		if err := rows.Scan(
+			&bin.ID, &bin.TenantID, &bin.WarehouseID, &bin.Code, &bin.Zone, &bin.Description, &bin.PickSequence,
+			&bin.IsActive, &bin.CreatedAt, &bin.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan bin: %w", err)
+		}
+		bins = append(bins, &bin)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return bins, nil
+}
+
+// Update updates a bin location
+func (r *PostgresBinRepository) Update(ctx context.Context, bin *domain.Bin) error {
+	query := `
+		UPDATE bins
+		SET warehouse_id = $1, code = $2, zone = $3, description = $4, pick_sequence = $5, is_active = $6, updated_at = $7
+		WHERE tenant_id = $8 AND id = $9
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		bin.WarehouseID, bin.Code, bin.Zone, bin.Description, bin.PickSequence, bin.IsActive, bin.UpdatedAt,
+		bin.TenantID, bin.ID,
+	)
+
+	if isBinCodeConflict(err) {
+		return fmt.Errorf("%w: %s", domain.ErrBinCodeExists, bin.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update bin: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBinNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes an empty bin location; its movement history is kept
+func (r *PostgresBinRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var held int
+		err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) FROM bin_stock WHERE tenant_id = $1 AND bin_id = $2`, tenantID, id,
+		).Scan(&held)
+		if err != nil {
+			return fmt.Errorf("failed to check bin stock: %w", err)
+		}
+		if held > 0 {
+			return domain.ErrBinNotEmpty
+		}
+
+		tag, err := tx.Exec(ctx, `DELETE FROM bins WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete bin: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrBinNotFound
+		}
+
+		return nil
+	})
+}
+
+// ListStockByBin retrieves the products held in a bin
+func (r *PostgresBinRepository) ListStockByBin(ctx context.Context, tenantID, binID uuid.UUID) ([]domain.BinStock, error) {
+	query := `
+		SELECT s.bin_id, b.code, b.warehouse_id, b.pick_sequence, s.product_id, s.quantity, s.updated_at
+		FROM bin_stock s
+		JOIN bins b ON b.id = s.bin_id
+		WHERE s.tenant_id = $1 AND s.bin_id = $2
+		ORDER BY s.product_id
+	`
+
+	return r.queryStock(ctx, query, tenantID, binID)
+}
+
+// ListStockByProducts retrieves the bins holding the products in pick order
+func (r *PostgresBinRepository) ListStockByProducts(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID, activeOnly bool) ([]domain.BinStock, error) {
+	if len(productIDs) == 0 {
+		return []domain.BinStock{}, nil
+	}
+
+	query := `
+		SELECT s.bin_id, b.code, b.warehouse_id, b.pick_sequence, s.product_id, s.quantity, s.updated_at
+		FROM bin_stock s
+		JOIN bins b ON b.id = s.bin_id
+		WHERE s.tenant_id = $1 AND s.product_id = ANY($2) AND (b.is_active OR NOT $3)
+		ORDER BY b.pick_sequence, b.code
+	`
+
+	return r.queryStock(ctx, query, tenantID, productIDs, activeOnly)
+}
+
+func (r *PostgresBinRepository) queryStock(ctx context.Context, query string, args ...interface{}) ([]domain.BinStock, error) {
+	rows, err := db.MainPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bin stock: %w", err)
+	}
+	defer rows.Close()
+
+	stock := []domain.BinStock{}
+	for rows.Next() {
+		var held domain.BinStock
+		if err := rows.Scan(
+			&held.BinID, &held.BinCode, &held.WarehouseID, &held.PickSequence, &held.ProductID, &held.Quantity, &held.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan bin stock: %w", err)
+		}
+		stock = append(stock, held)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return stock, nil
+}
+
+// SetStock sets a product's quantity in a bin and records the count
+func (r *PostgresBinRepository) SetStock(ctx context.Context, movement *domain.BinMovement, quantity float64) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		current, err := lockBinStock(ctx, tx, movement.TenantID, *movement.ToBinID, movement.ProductID)
+		if err != nil {
+			return err
+		}
+
+		movement.Quantity = math.Round((quantity-current)*1000) / 1000
+		if movement.Quantity == 0 {
+			return nil
+		}
+		if err := setBinStock(ctx, tx, movement.TenantID, *movement.ToBinID, movement.ProductID, quantity, movement.CreatedAt); err != nil {
+			return err
+		}
+		return insertBinMovement(ctx, tx, movement)
+	})
+}
+
+// Transfer moves stock between bins and records the movement
+func (r *PostgresBinRepository) Transfer(ctx context.Context, movement *domain.BinMovement) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		from, err := lockBinStock(ctx, tx, movement.TenantID, *movement.FromBinID, movement.ProductID)
+		if err != nil {
+			return err
+		}
+		if from < movement.Quantity {
+			return fmt.Errorf("%w: %g held, %g requested", domain.ErrInsufficientBinStock, from, movement.Quantity)
+		}
+		to, err := lockBinStock(ctx, tx, movement.TenantID, *movement.ToBinID, movement.ProductID)
+		if err != nil {
+			return err
+		}
+
+		if err := setBinStock(ctx, tx, movement.TenantID, *movement.FromBinID, movement.ProductID, from-movement.Quantity, movement.CreatedAt); err != nil {
+			return err
+		}
+		if err := setBinStock(ctx, tx, movement.TenantID, *movement.ToBinID, movement.ProductID, to+movement.Quantity, movement.CreatedAt); err != nil {
+			return err
+		}
+		return insertBinMovement(ctx, tx, movement)
+	})
+}
+
+// ListMovements retrieves bin movements newest first
+func (r *PostgresBinRepository) ListMovements(ctx context.Context, tenantID uuid.UUID, productID *uuid.UUID, limit, offset int) ([]*domain.BinMovement, error) {
+	query := `
+		SELECT id, tenant_id, product_id, movement_type, from_bin_id, to_bin_id, quantity, note, created_by, created_at
+		FROM bin_movements
+		WHERE tenant_id = $1 AND ($2::uuid IS NULL OR product_id = $2)
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, productID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bin movements: %w", err)
+	}
+	defer rows.Close()
+
+	movements := []*domain.BinMovement{}
+	for rows.Next() {
+		var movement domain.BinMovement
+		if err := rows.Scan(
+			&movement.ID, &movement.TenantID, &movement.ProductID, &movement.Type, &movement.FromBinID,
+			&movement.ToBinID, &movement.Quantity, &movement.Note, &movement.CreatedBy, &movement.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan bin movement: %w", err)
+		}
+		movements = append(movements, &movement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return movements, nil
+}
+
+// lockBinStock reads a product's quantity in a bin for update, zero when it holds none
+func lockBinStock(ctx context.Context, tx pgx.Tx, tenantID, binID, productID uuid.UUID) (float64, error) {
+	var quantity float64
+	err := tx.QueryRow(ctx, `
+		SELECT quantity FROM bin_stock
+		WHERE tenant_id = $1 AND bin_id = $2 AND product_id = $3
+		FOR UPDATE
+	`, tenantID, binID, productID).Scan(&quantity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock bin stock: %w", err)
+	}
+	return quantity, nil
+}
+
+// setBinStock stores a product's quantity in a bin; an emptied bin drops the product
+func setBinStock(ctx context.Context, tx pgx.Tx, tenantID, binID, productID uuid.UUID, quantity float64, at time.Time) error {
+	var err error
+	if quantity <= 0 {
+		_, err = tx.Exec(ctx,
+			`DELETE FROM bin_stock WHERE tenant_id = $1 AND bin_id = $2 AND product_id = $3`,
+			tenantID, binID, productID,
+		)
+	} else {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO bin_stock (tenant_id, bin_id, product_id, quantity, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (bin_id, product_id) DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = EXCLUDED.updated_at
+		`, tenantID, binID, productID, quantity, at)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save bin stock: %w", err)
+	}
+	return nil
+}
+
+func insertBinMovement(ctx context.Context, tx pgx.Tx, movement *domain.BinMovement) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO bin_movements (id, tenant_id, product_id, movement_type, from_bin_id, to_bin_id, quantity, note, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, movement.ID, movement.TenantID, movement.ProductID, movement.Type, movement.FromBinID,
+		movement.ToBinID, movement.Quantity, movement.Note, movement.CreatedBy, movement.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record bin movement: %w", err)
+	}
+	return nil
+}
+
+func isBinCodeConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uq_bins_code"
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresBulkPriceRepository implements BulkPriceRepository using PostgreSQL
+type PostgresBulkPriceRepository struct{}
+
+// NewPostgresBulkPriceRepository creates a new PostgreSQL bulk price repository
+func NewPostgresBulkPriceRepository() *PostgresBulkPriceRepository {
+	return &PostgresBulkPriceRepository{}
+}
+
+const bulkPriceUpdateColumns = `id, tenant_id, filter, adjustment, products_matched, products_changed,
+	rollback_token, rollback_expires_at, applied_by, applied_at, rolled_back_by, rolled_back_at`
+
+// Apply updates the prices with a compare on the old price, then records the update and its items
+func (r *PostgresBulkPriceRepository) Apply(ctx context.Context, update *domain.BulkPriceUpdate) error {
+	filterJSON, err := json.Marshal(update.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bulk price filter: %w", err)
+	}
+	adjustmentJSON, err := json.Marshal(update.Adjustment)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bulk price adjustment: %w", err)
+	}
+
+	n := len(update.Items)
+	productIDs := make([]uuid.UUID, n)
+	codes := make([]string, n)
+	names := make([]string, n)
+	costs := make([]float64, n)
+	oldPrices := make([]float64, n)
+	newPrices := make([]float64, n)
+	flags := make([]string, n)
+	for i, item := range update.Items {
+		itemFlags := item.Flags
+		if itemFlags == nil {
+			itemFlags = []string{}
+		}
+		flagsJSON, err := json.Marshal(itemFlags)
+		if err != nil {
+			return fmt.Errorf("failed to marshal bulk price item flags: %w", err)
+		}
+		productIDs[i], codes[i], names[i], costs[i] = item.ProductID, item.ProductCode, item.Name, item.CostPrice
+		oldPrices[i], newPrices[i], flags[i] = item.OldPrice, item.NewPrice, string(flagsJSON)
+	}
+
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE products p SET selling_price = v.new_price, updated_at = $5
+			FROM unnest($2::uuid[], $3::numeric[], $4::numeric[]) AS v(id, old_price, new_price)
+			WHERE p.tenant_id = $1 AND p.id = v.id AND p.selling_price = v.old_price`,
+			update.TenantID, productIDs, oldPrices, newPrices, update.AppliedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to apply bulk prices: %w", err)
+		}
+		if int(tag.RowsAffected()) != n {
+			return domain.ErrBulkPriceConflict
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO bulk_price_updates (`+bulkPriceUpdateColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULL, NULL)`,
+			update.ID, update.TenantID, filterJSON, adjustmentJSON, update.ProductsMatched, update.ProductsChanged,
+			update.RollbackToken, update.RollbackExpiresAt, update.AppliedBy, update.AppliedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record bulk price update: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO bulk_price_update_items (update_id, tenant_id, product_id, product_code, name, cost_price, old_price, new_price, flags)
+			SELECT $1, $2, v.product_id, v.product_code, v.name, v.cost_price, v.old_price, v.new_price, v.flags::jsonb
+			FROM unnest($3::uuid[], $4::text[], $5::text[], $6::numeric[], $7::numeric[], $8::numeric[], $9::text[])
+				AS v(product_id, product_code, name, cost_price, old_price, new_price, flags)`,
+			update.ID, update.TenantID, productIDs, codes, names, costs, oldPrices, newPrices, flags,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record bulk price items: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetByToken retrieves an update and its items by rollback token
+func (r *PostgresBulkPriceRepository) GetByToken(ctx context.Context, tenantID uuid.UUID, token string) (*domain.BulkPriceUpdate, error) {
+	query := `SELECT ` + bulkPriceUpdateColumns + ` FROM bulk_price_updates WHERE tenant_id = $1 AND rollback_token = $2`
+
+	update, err := r.scanUpdate(db.MainPool.QueryRow(ctx, query, tenantID, token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBulkPriceUpdateNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT product_id, product_code, name, cost_price, old_price, new_price, flags
+		FROM bulk_price_update_items
+		WHERE update_id = $1
+		ORDER BY product_code`, update.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bulk price items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item domain.BulkPriceItem
+		var flagsJSON []byte
+		if err := rows.Scan(&item.ProductID, &item.ProductCode, &item.Name, &item.CostPrice,
+			&item.OldPrice, &item.NewPrice, &flagsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan bulk price item: %w", err)
+		}
+		if err := json.Unmarshal(flagsJSON, &item.Flags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal bulk price item flags: %w", err)
+		}
+		update.Items = append(update.Items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return update, nil
+}
+
+// Rollback marks the update rolled back and restores the prices nobody changed since
+func (r *PostgresBulkPriceRepository) Rollback(ctx context.Context, update *domain.BulkPriceUpdate, rolledBackBy *uuid.UUID, rolledBackAt time.Time) ([]uuid.UUID, error) {
+	var restored []uuid.UUID
+	err := db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		// Claims the rollback so two clicks cannot both restore
+		tag, err := tx.Exec(ctx, `
+			UPDATE bulk_price_updates SET rolled_back_by = $1, rolled_back_at = $2
+			WHERE tenant_id = $3 AND id = $4 AND rolled_back_at IS NULL`,
+			rolledBackBy, rolledBackAt, update.TenantID, update.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to mark bulk price update rolled back: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrBulkPriceRolledBack
+		}
+
+		rows, err := tx.Query(ctx, `
+			UPDATE products p SET selling_price = i.old_price, updated_at = $3
+			FROM bulk_price_update_items i
+			WHERE i.update_id = $2 AND p.tenant_id = $1 AND p.id = i.product_id AND p.selling_price = i.new_price
+			RETURNING p.id`,
+			update.TenantID, update.ID, rolledBackAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to restore bulk prices: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				return fmt.Errorf("failed to scan restored product: %w", err)
+			}
+			restored = append(restored, id)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	update.RolledBackBy = rolledBackBy
+	update.RolledBackAt = &rolledBackAt
+	return restored, nil
+}
+
+func (r *PostgresBulkPriceRepository) scanUpdate(row pgx.Row) (*domain.BulkPriceUpdate, error) {
+	var update domain.BulkPriceUpdate
+	var filterJSON, adjustmentJSON []byte
+
+	err := row.Scan(
+		&update.ID, &update.TenantID, &filterJSON, &adjustmentJSON, &update.ProductsMatched, &update.ProductsChanged,
+		&update.RollbackToken, &update.RollbackExpiresAt, &update.AppliedBy, &update.AppliedAt,
+		&update.RolledBackBy, &update.RolledBackAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(filterJSON, &update.Filter); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bulk price filter: %w", err)
+	}
+	if err := json.Unmarshal(adjustmentJSON, &update.Adjustment); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bulk price adjustment: %w", err)
+	}
+
+	return &update, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/aceextension/catalog/domain"
@@ -29,15 +30,15 @@ func (r *PostgresCategoryRepository) Create(ctx context.Context, category *domai
 	query := `
 		INSERT INTO categories (
 			id, tenant_id, category_code, name, description, parent_id,
-			level, path, sort_order, is_active, custom_attributes, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			level, path, sort_order, is_active, tax_group_id, custom_attributes, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	_, err = db.MainPool.Exec(ctx, query,
 		category.ID, category.TenantID, category.CategoryCode,
 		category.Name, category.Description, category.ParentID,
 		category.Level, category.Path, category.SortOrder, category.IsActive,
-		attrsJSON, category.CreatedAt, category.UpdatedAt,
+		category.TaxGroupID, attrsJSON, category.CreatedAt, category.UpdatedAt,
 	)
 
 	if err != nil {
@@ -47,23 +48,27 @@ func (r *PostgresCategoryRepository) Create(ctx context.Context, category *domai
 	return nil
 }
 
-// GetByID retrieves a category by ID
-func (r *PostgresCategoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+// GetByID retrieves a tenant's category by ID
+func (r *PostgresCategoryRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Category, error) {
 	query := `
 		SELECT id, tenant_id, category_code, name, description, parent_id,
-		       level, path, sort_order, is_active, custom_attributes, created_at, updated_at
+		       level, path, sort_order, is_active, tax_group_id, custom_attributes, created_at, updated_at
 		FROM categories
-		WHERE id = $1
+		WHERE tenant_id = $1 AND id = $2
 	`
 
-	return r.scanCategory(db.MainPool.QueryRow(ctx, query, id))
+	category, err := r.scanCategory(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return category, err
 }
 
 // GetByCode retrieves a category by code
 func (r *PostgresCategoryRepository) GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.Category, error) {
 	query := `
 		SELECT id, tenant_id, category_code, name, description, parent_id,
-		       level, path, sort_order, is_active, custom_attributes, created_at, updated_at
+		       level, path, sort_order, is_active, tax_group_id, custom_attributes, created_at, updated_at
 		FROM categories
 		WHERE tenant_id = $1 AND category_code = $2
 	`
@@ -71,31 +76,51 @@ func (r *PostgresCategoryRepository) GetByCode(ctx context.Context, tenantID uui
 	return r.scanCategory(db.MainPool.QueryRow(ctx, query, tenantID, code))
 }
 
-// GetByTenantID retrieves all categories for a tenant
-func (r *PostgresCategoryRepository) GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Category, error) {
+// List retrieves a page of a tenant's categories in display order, and how many there are in all
+func (r *PostgresCategoryRepository) List(ctx context.Context, tenantID uuid.UUID, page db.PageRequest) ([]*domain.Category, int64, error) {
+	var afterSort *int
+	var afterName *string
+	var afterID *uuid.UUID
+	if page.After != nil {
+		afterSort, afterName, afterID = new(int), new(string), new(uuid.UUID)
+		if err := page.After.Scan(afterSort, afterName, afterID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	total, err := r.Count(ctx, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	query := `
 		SELECT id, tenant_id, category_code, name, description, parent_id,
-		       level, path, sort_order, is_active, custom_attributes, created_at, updated_at
+		       level, path, sort_order, is_active, tax_group_id, custom_attributes, created_at, updated_at
 		FROM categories
 		WHERE tenant_id = $1
-		ORDER BY sort_order, name
-		LIMIT $2 OFFSET $3
+		AND ($2::int IS NULL OR (sort_order, name, id) > ($2, $3, $4))
+		ORDER BY sort_order, name, id
+		LIMIT $5
 	`
 
-	rows, err := db.MainPool.Query(ctx, query, tenantID, limit, offset)
+	rows, err := db.MainPool.Query(ctx, query, tenantID, afterSort, afterName, afterID, page.Fetch())
 	if err != nil {
-		return nil, fmt.Errorf("failed to query categories: %w", err)
+		return nil, 0, fmt.Errorf("failed to query categories: %w", err)
 	}
 	defer rows.Close()
 
-	return r.scanCategories(rows)
+	categories, err := r.scanCategories(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return categories, total, nil
 }
 
 // GetRootCategories retrieves root categories (no parent)
 func (r *PostgresCategoryRepository) GetRootCategories(ctx context.Context, tenantID uuid.UUID) ([]*domain.Category, error) {
 	query := `
 		SELECT id, tenant_id, category_code, name, description, parent_id,
-		       level, path, sort_order, is_active, custom_attributes, created_at, updated_at
+		       level, path, sort_order, is_active, tax_group_id, custom_attributes, created_at, updated_at
 		FROM categories
 		WHERE tenant_id = $1 AND parent_id IS NULL
 		ORDER BY sort_order, name
@@ -114,7 +139,7 @@ func (r *PostgresCategoryRepository) GetRootCategories(ctx context.Context, tena
 func (r *PostgresCategoryRepository) GetChildren(ctx context.Context, parentID uuid.UUID) ([]*domain.Category, error) {
 	query := `
 		SELECT id, tenant_id, category_code, name, description, parent_id,
-		       level, path, sort_order, is_active, custom_attributes, created_at, updated_at
+		       level, path, sort_order, is_active, tax_group_id, custom_attributes, created_at, updated_at
 		FROM categories
 		WHERE parent_id = $1
 		ORDER BY sort_order, name
@@ -139,31 +164,38 @@ func (r *PostgresCategoryRepository) Update(ctx context.Context, category *domai
 	query := `
 		UPDATE categories
 		SET name = $1, description = $2, parent_id = $3, level = $4,
-		    path = $5, sort_order = $6, is_active = $7, custom_attributes = $8, updated_at = $9
-		WHERE id = $10
+		    path = $5, sort_order = $6, is_active = $7, tax_group_id = $8,
+		    custom_attributes = $9, updated_at = $10
+		WHERE tenant_id = $11 AND id = $12
 	`
 
-	_, err = db.MainPool.Exec(ctx, query,
+	tag, err := db.MainPool.Exec(ctx, query,
 		category.Name, category.Description, category.ParentID, category.Level,
-		category.Path, category.SortOrder, category.IsActive, attrsJSON,
-		category.UpdatedAt, category.ID,
+		category.Path, category.SortOrder, category.IsActive, category.TaxGroupID, attrsJSON,
+		category.UpdatedAt, category.TenantID, category.ID,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to update category: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCategoryNotFound
+	}
 
 	return nil
 }
 
-// Delete deletes a category
-func (r *PostgresCategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM categories WHERE id = $1`
+// Delete deletes a tenant's category
+func (r *PostgresCategoryRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	query := `DELETE FROM categories WHERE tenant_id = $1 AND id = $2`
 
-	_, err := db.MainPool.Exec(ctx, query, id)
+	tag, err := db.MainPool.Exec(ctx, query, tenantID, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete category: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCategoryNotFound
+	}
 
 	return nil
 }
@@ -172,7 +204,7 @@ func (r *PostgresCategoryRepository) Delete(ctx context.Context, id uuid.UUID) e
 func (r *PostgresCategoryRepository) Search(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) ([]*domain.Category, error) {
 	searchQuery := `
 		SELECT id, tenant_id, category_code, name, description, parent_id,
-		       level, path, sort_order, is_active, custom_attributes, created_at, updated_at
+		       level, path, sort_order, is_active, tax_group_id, custom_attributes, created_at, updated_at
 		FROM categories
 		WHERE tenant_id = $1
 		AND (name ILIKE $2 OR category_code ILIKE $2 OR description ILIKE $2)
@@ -226,7 +258,7 @@ func (r *PostgresCategoryRepository) scanCategory(row pgx.Row) (*domain.Category
 	err := row.Scan(
 		&category.ID, &category.TenantID, &category.CategoryCode,
 		&category.Name, &category.Description, &category.ParentID,
-		&category.Level, &category.Path, &category.SortOrder, &category.IsActive,
+		&category.Level, &category.Path, &category.SortOrder, &category.IsActive, &category.TaxGroupID,
 		&attrsJSON, &category.CreatedAt, &category.UpdatedAt,
 	)
 
@@ -259,7 +291,7 @@ func (r *PostgresCategoryRepository) scanCategories(rows pgx.Rows) ([]*domain.Ca
 		err := rows.Scan(
 			&category.ID, &category.TenantID, &category.CategoryCode,
 			&category.Name, &category.Description, &category.ParentID,
-			&category.Level, &category.Path, &category.SortOrder, &category.IsActive,
+			&category.Level, &category.Path, &category.SortOrder, &category.IsActive, &category.TaxGroupID,
 			&attrsJSON, &category.CreatedAt, &category.UpdatedAt,
 		)
 
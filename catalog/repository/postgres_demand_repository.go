@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresDemandRepository implements DemandRepository using PostgreSQL
+type PostgresDemandRepository struct{}
+
+// NewPostgresDemandRepository creates a new PostgreSQL demand repository
+func NewPostgresDemandRepository() *PostgresDemandRepository {
+	return &PostgresDemandRepository{}
+}
+
+// ListTenants retrieves tenants with at least one active product
+func (r *PostgresDemandRepository) ListTenants(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := db.MainPool.Query(ctx, `SELECT DISTINCT tenant_id FROM products WHERE is_active = true`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants with products: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []uuid.UUID{}
+	for rows.Next() {
+		var tenantID uuid.UUID
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, tenantID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// Totals sums sold quantities per product and BS month in one pass over the
+// sources. BS months do not line up with AD ones, so the service supplies the
+// month of every day and the query joins on it.
+func (r *PostgresDemandRepository) Totals(ctx context.Context, sources []domain.DemandSource, tenantID uuid.UUID, from, to time.Time, days []time.Time, months []int) ([]domain.DemandTotals, error) {
+	if len(sources) == 0 {
+		return []domain.DemandTotals{}, nil
+	}
+
+	selects := make([]string, len(sources))
+	for i, source := range sources {
+		selects[i] = fmt.Sprintf(`SELECT day::date AS day, product_id, qty FROM (%s) s%d`, source.DailySQL, i)
+	}
+
+	query := fmt.Sprintf(`
+		WITH sold AS (%s),
+		calendar AS (
+			SELECT * FROM unnest($4::date[], $5::int[]) AS c(day, month_bs)
+		),
+		monthly AS (
+			SELECT sold.product_id, calendar.month_bs,
+			       SUM(sold.qty) AS qty,
+			       SUM(sold.qty) FILTER (WHERE sold.day >= $6) AS qty_30,
+			       SUM(sold.qty) FILTER (WHERE sold.day >= $7) AS qty_90,
+			       MIN(sold.day) AS first_day, MAX(sold.day) AS last_day
+			FROM sold
+			JOIN calendar ON calendar.day = sold.day
+			WHERE sold.product_id IS NOT NULL
+			GROUP BY sold.product_id, calendar.month_bs
+		)
+		SELECT m.product_id,
+		       COALESCE(SUM(m.qty_30), 0)::float8, COALESCE(SUM(m.qty_90), 0)::float8,
+		       array_agg(m.month_bs ORDER BY m.month_bs), array_agg(m.qty::float8 ORDER BY m.month_bs),
+		       MIN(m.first_day), MAX(m.last_day)
+		FROM monthly m
+		JOIN products p ON p.id = m.product_id AND p.tenant_id = $1
+		GROUP BY m.product_id
+	`, strings.Join(selects, " UNION ALL "))
+
+	rows, err := db.MainPool.Query(ctx, query,
+		tenantID, from, to, days, months, to.AddDate(0, 0, -30), to.AddDate(0, 0, -90),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query demand totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []domain.DemandTotals{}
+	for rows.Next() {
+		var t domain.DemandTotals
+		var monthBS []int32
+		var quantities []float64
+		if err := rows.Scan(&t.ProductID, &t.Sold30, &t.Sold90, &monthBS, &quantities, &t.FirstSoldOn, &t.LastSoldOn); err != nil {
+			return nil, fmt.Errorf("failed to scan demand totals: %w", err)
+		}
+		for i, month := range monthBS {
+			t.ByMonth[month-1] = quantities[i]
+		}
+		totals = append(totals, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return totals, nil
+}
+
+// Replace deletes the tenant's statistics and inserts the new set in one transaction
+func (r *PostgresDemandRepository) Replace(ctx context.Context, tenantID uuid.UUID, demands []*domain.ProductDemand) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM product_demand WHERE tenant_id = $1`, tenantID); err != nil {
+			return fmt.Errorf("failed to clear product demand: %w", err)
+		}
+
+		query := `
+			INSERT INTO product_demand (
+				tenant_id, product_id, sold_30, sold_90, avg_daily_30, avg_daily_90, base_daily,
+				seasonality, first_sold_on, last_sold_on, computed_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`
+		batch := &pgx.Batch{}
+		for _, d := range demands {
+			batch.Queue(query,
+				tenantID, d.ProductID, d.Sold30, d.Sold90, d.AvgDaily30, d.AvgDaily90, d.BaseDaily,
+				d.Seasonality[:], d.FirstSoldOn, d.LastSoldOn, d.ComputedAt,
+			)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("failed to save product demand: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// demandColumns are the product_demand columns read with the product's code, name and unit
+const demandColumns = `
+	d.tenant_id, d.product_id, p.product_code, p.name, p.unit,
+	d.sold_30, d.sold_90, d.avg_daily_30, d.avg_daily_90, d.base_daily,
+	d.seasonality::float8[], d.first_sold_on, d.last_sold_on, d.computed_at
+`
+
+// GetByProduct retrieves a product's statistics
+func (r *PostgresDemandRepository) GetByProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.ProductDemand, error) {
+	query := `SELECT ` + demandColumns + `
+		FROM product_demand d
+		JOIN products p ON p.id = d.product_id
+		WHERE d.tenant_id = $1 AND d.product_id = $2
+	`
+
+	demand, err := scanDemand(db.MainPool.QueryRow(ctx, query, tenantID, productID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrDemandNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product demand: %w", err)
+	}
+
+	return demand, nil
+}
+
+// List retrieves statistics of active products by base daily rate, highest first
+func (r *PostgresDemandRepository) List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.ProductDemand, error) {
+	query := `SELECT ` + demandColumns + `
+		FROM product_demand d
+		JOIN products p ON p.id = d.product_id
+		WHERE d.tenant_id = $1 AND p.is_active = true
+		ORDER BY d.base_daily DESC, p.product_code
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product demand: %w", err)
+	}
+	defer rows.Close()
+
+	demands := []*domain.ProductDemand{}
+	for rows.Next() {
+		demand, err := scanDemand(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product demand: %w", err)
+		}
+		demands = append(demands, demand)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return demands, nil
+}
+
+func scanDemand(row pgx.Row) (*domain.ProductDemand, error) {
+	var d domain.ProductDemand
+	var seasonality []float64
+	if err := row.Scan(
+		&d.TenantID, &d.ProductID, &d.ProductCode, &d.ProductName, &d.Unit,
+		&d.Sold30, &d.Sold90, &d.AvgDaily30, &d.AvgDaily90, &d.BaseDaily,
+		&seasonality, &d.FirstSoldOn, &d.LastSoldOn, &d.ComputedAt,
+	); err != nil {
+		return nil, err
+	}
+	copy(d.Seasonality[:], seasonality)
+	return &d, nil
+}
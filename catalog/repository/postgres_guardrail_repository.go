@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresGuardrailRepository implements GuardrailRepository using PostgreSQL
+type PostgresGuardrailRepository struct{}
+
+// NewPostgresGuardrailRepository creates a new PostgreSQL guardrail repository
+func NewPostgresGuardrailRepository() *PostgresGuardrailRepository {
+	return &PostgresGuardrailRepository{}
+}
+
+const discountOverrideColumns = `id, tenant_id, product_id, list_price, sale_price, discount_percent, reason,
+	requested_by, approved_by, approver_role, expires_at, used_at, created_at`
+
+// GetDiscountLimit retrieves a role's discount limit
+func (r *PostgresGuardrailRepository) GetDiscountLimit(ctx context.Context, tenantID uuid.UUID, role string) (*domain.DiscountLimit, error) {
+	query := `
+		SELECT tenant_id, role, max_discount_percent, updated_at
+		FROM discount_limits
+		WHERE tenant_id = $1 AND role = $2
+	`
+
+	limit := &domain.DiscountLimit{}
+	err := db.MainPool.QueryRow(ctx, query, tenantID, role).Scan(
+		&limit.TenantID, &limit.Role, &limit.MaxDiscountPercent, &limit.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discount limit: %w", err)
+	}
+
+	return limit, nil
+}
+
+// ListDiscountLimits retrieves all of a tenant's discount limits
+func (r *PostgresGuardrailRepository) ListDiscountLimits(ctx context.Context, tenantID uuid.UUID) ([]*domain.DiscountLimit, error) {
+	query := `
+		SELECT tenant_id, role, max_discount_percent, updated_at
+		FROM discount_limits
+		WHERE tenant_id = $1
+		ORDER BY role
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query discount limits: %w", err)
+	}
+	defer rows.Close()
+
+	limits := []*domain.DiscountLimit{}
+	for rows.Next() {
+		limit := &domain.DiscountLimit{}
+		if err := rows.Scan(&limit.TenantID, &limit.Role, &limit.MaxDiscountPercent, &limit.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan discount limit: %w", err)
+		}
+		limits = append(limits, limit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return limits, nil
+}
+
+// SetDiscountLimit creates or replaces a role's discount limit
+func (r *PostgresGuardrailRepository) SetDiscountLimit(ctx context.Context, limit *domain.DiscountLimit) error {
+	query := `
+		INSERT INTO discount_limits (tenant_id, role, max_discount_percent, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, role) DO UPDATE
+		SET max_discount_percent = EXCLUDED.max_discount_percent, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, limit.TenantID, limit.Role, limit.MaxDiscountPercent, limit.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set discount limit: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDiscountLimit removes a role's limit, leaving the role unrestricted
+func (r *PostgresGuardrailRepository) DeleteDiscountLimit(ctx context.Context, tenantID uuid.UUID, role string) error {
+	_, err := db.MainPool.Exec(ctx, `DELETE FROM discount_limits WHERE tenant_id = $1 AND role = $2`, tenantID, role)
+	if err != nil {
+		return fmt.Errorf("failed to delete discount limit: %w", err)
+	}
+
+	return nil
+}
+
+// CreateOverride records an approved discount override
+func (r *PostgresGuardrailRepository) CreateOverride(ctx context.Context, o *domain.DiscountOverride) error {
+	query := `INSERT INTO discount_overrides (` + discountOverrideColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		o.ID, o.TenantID, o.ProductID, o.ListPrice, o.SalePrice, o.DiscountPercent, o.Reason,
+		o.RequestedBy, o.ApprovedBy, o.ApproverRole, o.ExpiresAt, o.UsedAt, o.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create discount override: %w", err)
+	}
+
+	return nil
+}
+
+// GetOverride retrieves a tenant's discount override
+func (r *PostgresGuardrailRepository) GetOverride(ctx context.Context, tenantID, id uuid.UUID) (*domain.DiscountOverride, error) {
+	query := `SELECT ` + discountOverrideColumns + ` FROM discount_overrides WHERE tenant_id = $1 AND id = $2`
+
+	override, err := r.scanOverride(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrInvalidDiscountOverride
+	}
+	return override, err
+}
+
+// MarkOverrideUsed redeems an override exactly once
+func (r *PostgresGuardrailRepository) MarkOverrideUsed(ctx context.Context, tenantID, id uuid.UUID) (bool, error) {
+	tag, err := db.MainPool.Exec(ctx,
+		`UPDATE discount_overrides SET used_at = NOW() WHERE tenant_id = $1 AND id = $2 AND used_at IS NULL`,
+		tenantID, id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to redeem discount override: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListOverrides retrieves a tenant's discount overrides, newest first
+func (r *PostgresGuardrailRepository) ListOverrides(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.DiscountOverride, error) {
+	query := `
+		SELECT ` + discountOverrideColumns + `
+		FROM discount_overrides
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query discount overrides: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := []*domain.DiscountOverride{}
+	for rows.Next() {
+		override, err := r.scanOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return overrides, nil
+}
+
+func (r *PostgresGuardrailRepository) scanOverride(row pgx.Row) (*domain.DiscountOverride, error) {
+	o := &domain.DiscountOverride{}
+	err := row.Scan(
+		&o.ID, &o.TenantID, &o.ProductID, &o.ListPrice, &o.SalePrice, &o.DiscountPercent, &o.Reason,
+		&o.RequestedBy, &o.ApprovedBy, &o.ApproverRole, &o.ExpiresAt, &o.UsedAt, &o.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresHSCodeRepository implements HSCodeRepository using PostgreSQL
+type PostgresHSCodeRepository struct{}
+
+// NewPostgresHSCodeRepository creates a new PostgreSQL HS code repository
+func NewPostgresHSCodeRepository() *PostgresHSCodeRepository {
+	return &PostgresHSCodeRepository{}
+}
+
+// GetByCode retrieves an HS code entry
+func (r *PostgresHSCodeRepository) GetByCode(ctx context.Context, code string) (*domain.HSCode, error) {
+	query := `SELECT code, description, chapter, heading FROM hs_codes WHERE code = $1`
+
+	var hs domain.HSCode
+	err := db.MainPool.QueryRow(ctx, query, code).Scan(&hs.Code, &hs.Description, &hs.Chapter, &hs.Heading)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get HS code: %w", err)
+	}
+
+	return &hs, nil
+}
+
+// Search searches HS codes by code prefix or description
+func (r *PostgresHSCodeRepository) Search(ctx context.Context, query string, limit int) ([]*domain.HSCode, error) {
+	searchQuery := `
+		SELECT code, description, chapter, heading
+		FROM hs_codes
+		WHERE code LIKE $1 OR description ILIKE $2
+		ORDER BY code
+		LIMIT $3
+	`
+
+	rows, err := db.MainPool.Query(ctx, searchQuery, query+"%", "%"+query+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search HS codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := []*domain.HSCode{}
+	for rows.Next() {
+		var hs domain.HSCode
+		if err := rows.Scan(&hs.Code, &hs.Description, &hs.Chapter, &hs.Heading); err != nil {
+			return nil, fmt.Errorf("failed to scan HS code: %w", err)
+		}
+		codes = append(codes, &hs)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresLocalizationRepository implements LocalizationRepository using PostgreSQL
+type PostgresLocalizationRepository struct{}
+
+// NewPostgresLocalizationRepository creates a new PostgreSQL localization repository
+func NewPostgresLocalizationRepository() *PostgresLocalizationRepository {
+	return &PostgresLocalizationRepository{}
+}
+
+// SaveTranslation upserts a translation
+func (r *PostgresLocalizationRepository) SaveTranslation(ctx context.Context, t *domain.Translation) error {
+	query := `
+		INSERT INTO catalog_translations (tenant_id, entity_type, entity_id, locale, name, description, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (entity_type, entity_id, locale) DO UPDATE
+		SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, t.TenantID, t.EntityType, t.EntityID, t.Locale, t.Name, t.Description, t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save translation: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTranslation removes a translation
+func (r *PostgresLocalizationRepository) DeleteTranslation(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, entityID uuid.UUID, locale string) error {
+	query := `DELETE FROM catalog_translations WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3 AND locale = $4`
+
+	tag, err := db.MainPool.Exec(ctx, query, tenantID, entityType, entityID, locale)
+	if err != nil {
+		return fmt.Errorf("failed to delete translation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTranslationNotFound
+	}
+
+	return nil
+}
+
+// ListTranslations retrieves all of an entity's translations
+func (r *PostgresLocalizationRepository) ListTranslations(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, entityID uuid.UUID) ([]*domain.Translation, error) {
+	query := `
+		SELECT tenant_id, entity_type, entity_id, locale, name, description, updated_at
+		FROM catalog_translations
+		WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3
+		ORDER BY locale
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query translations: %w", err)
+	}
+	return scanTranslations(rows)
+}
+
+// FindTranslations retrieves the translations of many entities in the given locales in one query
+func (r *PostgresLocalizationRepository) FindTranslations(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, entityIDs []uuid.UUID, locales []string) ([]*domain.Translation, error) {
+	if len(entityIDs) == 0 || len(locales) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT tenant_id, entity_type, entity_id, locale, name, description, updated_at
+		FROM catalog_translations
+		WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = ANY($3) AND locale = ANY($4)
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, entityType, entityIDs, locales)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query translations: %w", err)
+	}
+	return scanTranslations(rows)
+}
+
+func scanTranslations(rows pgx.Rows) ([]*domain.Translation, error) {
+	defer rows.Close()
+
+	translations := []*domain.Translation{}
+	for rows.Next() {
+		var t domain.Translation
+		if err := rows.Scan(&t.TenantID, &t.EntityType, &t.EntityID, &t.Locale, &t.Name, &t.Description, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan translation: %w", err)
+		}
+		translations = append(translations, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return translations, nil
+}
+
+// SaveDocumentLocale upserts a document type's locale
+func (r *PostgresLocalizationRepository) SaveDocumentLocale(ctx context.Context, setting *domain.DocumentLocale) error {
+	query := `
+		INSERT INTO document_locales (tenant_id, document_type, locale, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, document_type) DO UPDATE
+		SET locale = EXCLUDED.locale, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, setting.TenantID, setting.DocumentType, setting.Locale, setting.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save document locale: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDocumentLocale removes a document type's locale, so it prints the catalog's own names
+func (r *PostgresLocalizationRepository) DeleteDocumentLocale(ctx context.Context, tenantID uuid.UUID, documentType string) error {
+	tag, err := db.MainPool.Exec(ctx, `DELETE FROM document_locales WHERE tenant_id = $1 AND document_type = $2`, tenantID, documentType)
+	if err != nil {
+		return fmt.Errorf("failed to delete document locale: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDocumentLocaleNotFound
+	}
+
+	return nil
+}
+
+// ListDocumentLocales retrieves the tenant's document locales
+func (r *PostgresLocalizationRepository) ListDocumentLocales(ctx context.Context, tenantID uuid.UUID) ([]*domain.DocumentLocale, error) {
+	query := `
+		SELECT tenant_id, document_type, locale, updated_at
+		FROM document_locales
+		WHERE tenant_id = $1
+		ORDER BY document_type
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query document locales: %w", err)
+	}
+	defer rows.Close()
+
+	settings := []*domain.DocumentLocale{}
+	for rows.Next() {
+		var s domain.DocumentLocale
+		if err := rows.Scan(&s.TenantID, &s.DocumentType, &s.Locale, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan document locale: %w", err)
+		}
+		settings = append(settings, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,374 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresPricingRepository implements PricingRepository using PostgreSQL
+type PostgresPricingRepository struct{}
+
+// NewPostgresPricingRepository creates a new PostgreSQL pricing repository
+func NewPostgresPricingRepository() *PostgresPricingRepository {
+	return &PostgresPricingRepository{}
+}
+
+const markupRuleColumns = `id, tenant_id, product_id, category_id, markup_percent, round_to,
+	require_approval, is_active, created_at, updated_at`
+
+const priceChangeColumns = `id, tenant_id, product_id, rule_id, run_id, source, status,
+	cost_price, old_price, new_price, reviewed_by, reviewed_at, created_at`
+
+const repricingRunColumns = `id, tenant_id, source, started_by, products_checked, prices_applied,
+	pending_approval, started_at, finished_at`
+
+// CreateRule creates a markup rule
+func (r *PostgresPricingRepository) CreateRule(ctx context.Context, rule *domain.MarkupRule) error {
+	query := `INSERT INTO markup_rules (` + markupRuleColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		rule.ID, rule.TenantID, rule.ProductID, rule.CategoryID, rule.MarkupPercent, rule.RoundTo,
+		rule.RequireApproval, rule.IsActive, rule.CreatedAt, rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create markup rule: %w", err)
+	}
+
+	return nil
+}
+
+// GetRuleByID retrieves a tenant's markup rule
+func (r *PostgresPricingRepository) GetRuleByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.MarkupRule, error) {
+	query := `SELECT ` + markupRuleColumns + ` FROM markup_rules WHERE tenant_id = $1 AND id = $2`
+
+	rule, err := r.scanRule(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrMarkupRuleNotFound
+	}
+	return rule, err
+}
+
+// ListRules retrieves all markup rules for a tenant
+func (r *PostgresPricingRepository) ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.MarkupRule, error) {
+	query := `
+		SELECT ` + markupRuleColumns + `
+		FROM markup_rules
+		WHERE tenant_id = $1
+		ORDER BY product_id NULLS LAST, created_at
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query markup rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*domain.MarkupRule{}
+	for rows.Next() {
+		rule, err := r.scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return rules, nil
+}
+
+// UpdateRule updates a markup rule's terms
+func (r *PostgresPricingRepository) UpdateRule(ctx context.Context, rule *domain.MarkupRule) error {
+	query := `
+		UPDATE markup_rules
+		SET markup_percent = $1, round_to = $2, require_approval = $3, is_active = $4, updated_at = $5
+		WHERE tenant_id = $6 AND id = $7
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		rule.MarkupPercent, rule.RoundTo, rule.RequireApproval, rule.IsActive, rule.UpdatedAt,
+		rule.TenantID, rule.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update markup rule: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRule deletes a markup rule
+func (r *PostgresPricingRepository) DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := db.MainPool.Exec(ctx, `DELETE FROM markup_rules WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete markup rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMarkupRuleNotFound
+	}
+
+	return nil
+}
+
+// GetActiveRuleForProduct retrieves the product's own active rule
+func (r *PostgresPricingRepository) GetActiveRuleForProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.MarkupRule, error) {
+	query := `SELECT ` + markupRuleColumns + ` FROM markup_rules WHERE tenant_id = $1 AND product_id = $2 AND is_active = true`
+
+	rule, err := r.scanRule(db.MainPool.QueryRow(ctx, query, tenantID, productID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return rule, err
+}
+
+// GetActiveRuleForCategory retrieves the category's own active rule
+func (r *PostgresPricingRepository) GetActiveRuleForCategory(ctx context.Context, tenantID, categoryID uuid.UUID) (*domain.MarkupRule, error) {
+	query := `SELECT ` + markupRuleColumns + ` FROM markup_rules WHERE tenant_id = $1 AND category_id = $2 AND is_active = true`
+
+	rule, err := r.scanRule(db.MainPool.QueryRow(ctx, query, tenantID, categoryID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return rule, err
+}
+
+// ListTenantsWithRules retrieves tenants with at least one active markup rule
+func (r *PostgresPricingRepository) ListTenantsWithRules(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := db.MainPool.Query(ctx, `SELECT DISTINCT tenant_id FROM markup_rules WHERE is_active = true`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenants with markup rules: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, id)
+	}
+
+	return tenants, rows.Err()
+}
+
+// CreateChange records a recomputed price
+func (r *PostgresPricingRepository) CreateChange(ctx context.Context, change *domain.PriceChange) error {
+	query := `INSERT INTO price_changes (` + priceChangeColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		change.ID, change.TenantID, change.ProductID, change.RuleID, change.RunID, change.Source, change.Status,
+		change.CostPrice, change.OldPrice, change.NewPrice, change.ReviewedBy, change.ReviewedAt, change.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create price change: %w", err)
+	}
+
+	return nil
+}
+
+// GetChangeByID retrieves a tenant's price change
+func (r *PostgresPricingRepository) GetChangeByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.PriceChange, error) {
+	query := `SELECT ` + priceChangeColumns + ` FROM price_changes WHERE tenant_id = $1 AND id = $2`
+
+	change, err := r.scanChange(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPriceChangeNotFound
+	}
+	return change, err
+}
+
+// ListChanges retrieves a tenant's price changes, optionally filtered by status
+func (r *PostgresPricingRepository) ListChanges(ctx context.Context, tenantID uuid.UUID, status domain.PriceChangeStatus, limit, offset int) ([]*domain.PriceChange, error) {
+	query := `
+		SELECT ` + priceChangeColumns + `
+		FROM price_changes
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	return r.queryChanges(ctx, query, tenantID, string(status), limit, offset)
+}
+
+// UpdateChange records the review of a price change
+func (r *PostgresPricingRepository) UpdateChange(ctx context.Context, change *domain.PriceChange) error {
+	query := `
+		UPDATE price_changes
+		SET status = $1, reviewed_by = $2, reviewed_at = $3
+		WHERE tenant_id = $4 AND id = $5
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, change.Status, change.ReviewedBy, change.ReviewedAt, change.TenantID, change.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update price change: %w", err)
+	}
+
+	return nil
+}
+
+// SupersedePending marks a product's pending changes as superseded
+func (r *PostgresPricingRepository) SupersedePending(ctx context.Context, tenantID, productID uuid.UUID) error {
+	query := `
+		UPDATE price_changes
+		SET status = $1
+		WHERE tenant_id = $2 AND product_id = $3 AND status = $4
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, domain.PriceChangeStatusSuperseded, tenantID, productID, domain.PriceChangeStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to supersede pending price changes: %w", err)
+	}
+
+	return nil
+}
+
+// CreateRun records the start of a repricing run
+func (r *PostgresPricingRepository) CreateRun(ctx context.Context, run *domain.RepricingRun) error {
+	query := `INSERT INTO repricing_runs (` + repricingRunColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		run.ID, run.TenantID, run.Source, run.StartedBy, run.ProductsChecked, run.PricesApplied,
+		run.PendingApproval, run.StartedAt, run.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create repricing run: %w", err)
+	}
+
+	return nil
+}
+
+// FinishRun stores the run totals
+func (r *PostgresPricingRepository) FinishRun(ctx context.Context, run *domain.RepricingRun) error {
+	query := `
+		UPDATE repricing_runs
+		SET products_checked = $1, prices_applied = $2, pending_approval = $3, finished_at = $4
+		WHERE id = $5
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, run.ProductsChecked, run.PricesApplied, run.PendingApproval, run.FinishedAt, run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to finish repricing run: %w", err)
+	}
+
+	return nil
+}
+
+// GetRun retrieves a repricing run with its changes
+func (r *PostgresPricingRepository) GetRun(ctx context.Context, tenantID, id uuid.UUID) (*domain.RepricingRun, error) {
+	query := `SELECT ` + repricingRunColumns + ` FROM repricing_runs WHERE tenant_id = $1 AND id = $2`
+
+	run, err := r.scanRun(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("repricing run not found")
+		}
+		return nil, err
+	}
+
+	changesQuery := `SELECT ` + priceChangeColumns + ` FROM price_changes WHERE run_id = $1 ORDER BY created_at`
+	run.Changes, err = r.queryChanges(ctx, changesQuery, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// ListRuns retrieves a tenant's repricing runs, newest first
+func (r *PostgresPricingRepository) ListRuns(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.RepricingRun, error) {
+	query := `
+		SELECT ` + repricingRunColumns + `
+		FROM repricing_runs
+		WHERE tenant_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repricing runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*domain.RepricingRun{}
+	for rows.Next() {
+		run, err := r.scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return runs, nil
+}
+
+func (r *PostgresPricingRepository) queryChanges(ctx context.Context, query string, args ...interface{}) ([]*domain.PriceChange, error) {
+	rows, err := db.MainPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []*domain.PriceChange{}
+	for rows.Next() {
+		change, err := r.scanChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return changes, nil
+}
+
+func (r *PostgresPricingRepository) scanRule(row pgx.Row) (*domain.MarkupRule, error) {
+	rule := &domain.MarkupRule{}
+	err := row.Scan(
+		&rule.ID, &rule.TenantID, &rule.ProductID, &rule.CategoryID, &rule.MarkupPercent, &rule.RoundTo,
+		&rule.RequireApproval, &rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *PostgresPricingRepository) scanChange(row pgx.Row) (*domain.PriceChange, error) {
+	change := &domain.PriceChange{}
+	err := row.Scan(
+		&change.ID, &change.TenantID, &change.ProductID, &change.RuleID, &change.RunID, &change.Source, &change.Status,
+		&change.CostPrice, &change.OldPrice, &change.NewPrice, &change.ReviewedBy, &change.ReviewedAt, &change.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan price change: %w", err)
+	}
+	return change, nil
+}
+
+func (r *PostgresPricingRepository) scanRun(row pgx.Row) (*domain.RepricingRun, error) {
+	run := &domain.RepricingRun{}
+	err := row.Scan(
+		&run.ID, &run.TenantID, &run.Source, &run.StartedBy, &run.ProductsChecked, &run.PricesApplied,
+		&run.PendingApproval, &run.StartedAt, &run.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresProductBarcodeRepository implements ProductBarcodeRepository using PostgreSQL
+type PostgresProductBarcodeRepository struct{}
+
+// NewPostgresProductBarcodeRepository creates a new PostgreSQL product barcode repository
+func NewPostgresProductBarcodeRepository() *PostgresProductBarcodeRepository {
+	return &PostgresProductBarcodeRepository{}
+}
+
+// ListByProduct retrieves a product's pack barcodes
+func (r *PostgresProductBarcodeRepository) ListByProduct(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.ProductBarcode, error) {
+	query := `
+		SELECT id, tenant_id, product_id, barcode, unit, quantity, created_at
+		FROM product_barcodes
+		WHERE tenant_id = $1 AND product_id = $2
+		ORDER BY quantity, barcode
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product barcodes: %w", err)
+	}
+	defer rows.Close()
+
+	barcodes := []domain.ProductBarcode{}
+	for rows.Next() {
+		var barcode domain.ProductBarcode
+		if err := rows.Scan(
+			&barcode.ID, &barcode.TenantID, &barcode.ProductID, &barcode.Barcode,
+			&barcode.Unit, &barcode.Quantity, &barcode.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan product barcode: %w", err)
+		}
+		barcodes = append(barcodes, barcode)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return barcodes, nil
+}
+
+// Replace swaps a product's pack barcodes
+func (r *PostgresProductBarcodeRepository) Replace(ctx context.Context, tenantID, productID uuid.UUID, barcodes []domain.ProductBarcode) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			`DELETE FROM product_barcodes WHERE tenant_id = $1 AND product_id = $2`, tenantID, productID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to clear product barcodes: %w", err)
+		}
+
+		query := `
+			INSERT INTO product_barcodes (id, tenant_id, product_id, barcode, unit, quantity, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		for _, barcode := range barcodes {
+			_, err := tx.Exec(ctx, query,
+				barcode.ID, tenantID, productID, barcode.Barcode, barcode.Unit, barcode.Quantity, barcode.CreatedAt,
+			)
+
+			// A concurrent save of another product can claim the barcode after the service checked it
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uq_product_barcodes_barcode" {
+				return fmt.Errorf("%w: %s", domain.ErrBarcodeInUse, barcode.Barcode)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to save product barcode: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetByBarcode retrieves the pack barcode matching a scan
+func (r *PostgresProductBarcodeRepository) GetByBarcode(ctx context.Context, tenantID uuid.UUID, barcode string) (*domain.ProductBarcode, error) {
+	query := `
+		SELECT id, tenant_id, product_id, barcode, unit, quantity, created_at
+		FROM product_barcodes
+		WHERE tenant_id = $1 AND barcode = $2
+	`
+
+	var pack domain.ProductBarcode
+	err := db.MainPool.QueryRow(ctx, query, tenantID, barcode).Scan(
+		&pack.ID, &pack.TenantID, &pack.ProductID, &pack.Barcode, &pack.Unit, &pack.Quantity, &pack.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to get product barcode: %w", err)
+	}
+
+	return &pack, nil
+}
+
+// FindTaken looks the barcodes up on other products and their packs
+func (r *PostgresProductBarcodeRepository) FindTaken(ctx context.Context, tenantID, productID uuid.UUID, barcodes []string) (map[string]uuid.UUID, error) {
+	owners := make(map[string]uuid.UUID, len(barcodes))
+	if len(barcodes) == 0 {
+		return owners, nil
+	}
+
+	query := `
+		SELECT barcode, id FROM products
+		WHERE tenant_id = $1 AND barcode = ANY($2) AND id <> $3
+		UNION ALL
+		SELECT barcode, product_id FROM product_barcodes
+		WHERE tenant_id = $1 AND barcode = ANY($2) AND product_id <> $3
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, barcodes, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query barcode owners: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var barcode string
+		var productID uuid.UUID
+		if err := rows.Scan(&barcode, &productID); err != nil {
+			return nil, fmt.Errorf("failed to scan barcode owner: %w", err)
+		}
+		owners[barcode] = productID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return owners, nil
+}
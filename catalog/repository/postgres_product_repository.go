@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aceextension/catalog/domain"
 	"github.com/aceextension/core/db"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
@@ -29,17 +32,17 @@ func (r *PostgresProductRepository) Create(ctx context.Context, product *domain.
 	query := `
 		INSERT INTO products (
 			id, tenant_id, product_code, name, description, category_id,
-			cost_price, selling_price, mrp, tax_rate,
-			sku, barcode, unit, status, is_active,
+			cost_price, selling_price, mrp, tax_rate, tax_group_id,
+			sku, barcode, hs_code, unit, status, is_active,
 			custom_attributes, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 	`
 
 	_, err = db.MainPool.Exec(ctx, query,
 		product.ID, product.TenantID, product.ProductCode,
 		product.Name, product.Description, product.CategoryID,
-		product.CostPrice, product.SellingPrice, product.MRP, product.TaxRate,
-		product.SKU, product.Barcode, product.Unit, product.Status, product.IsActive,
+		product.CostPrice, product.SellingPrice, product.MRP, product.TaxRate, product.TaxGroupID,
+		product.SKU, product.Barcode, product.HSCode, product.Unit, product.Status, product.IsActive,
 		attrsJSON, product.CreatedAt, product.UpdatedAt,
 	)
 
@@ -50,26 +53,30 @@ func (r *PostgresProductRepository) Create(ctx context.Context, product *domain.
 	return nil
 }
 
-// GetByID retrieves a product by ID
-func (r *PostgresProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+// GetByID retrieves a tenant's product by ID
+func (r *PostgresProductRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Product, error) {
 	query := `
 		SELECT id, tenant_id, product_code, name, description, category_id,
-		       cost_price, selling_price, mrp, tax_rate,
-		       sku, barcode, unit, status, is_active,
+		       cost_price, selling_price, mrp, tax_rate, tax_group_id,
+		       sku, barcode, hs_code, unit, status, is_active,
 		       custom_attributes, created_at, updated_at
 		FROM products
-		WHERE id = $1
+		WHERE tenant_id = $1 AND id = $2
 	`
 
-	return r.scanProduct(db.MainPool.QueryRow(ctx, query, id))
+	product, err := r.scanProduct(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrProductNotFound
+	}
+	return product, err
 }
 
 // GetByCode retrieves a product by code
 func (r *PostgresProductRepository) GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.Product, error) {
 	query := `
 		SELECT id, tenant_id, product_code, name, description, category_id,
-		       cost_price, selling_price, mrp, tax_rate,
-		       sku, barcode, unit, status, is_active,
+		       cost_price, selling_price, mrp, tax_rate, tax_group_id,
+		       sku, barcode, hs_code, unit, status, is_active,
 		       custom_attributes, created_at, updated_at
 		FROM products
 		WHERE tenant_id = $1 AND product_code = $2
@@ -82,8 +89,8 @@ func (r *PostgresProductRepository) GetByCode(ctx context.Context, tenantID uuid
 func (r *PostgresProductRepository) GetBySKU(ctx context.Context, tenantID uuid.UUID, sku string) (*domain.Product, error) {
 	query := `
 		SELECT id, tenant_id, product_code, name, description, category_id,
-		       cost_price, selling_price, mrp, tax_rate,
-		       sku, barcode, unit, status, is_active,
+		       cost_price, selling_price, mrp, tax_rate, tax_group_id,
+		       sku, barcode, hs_code, unit, status, is_active,
 		       custom_attributes, created_at, updated_at
 		FROM products
 		WHERE tenant_id = $1 AND sku = $2
@@ -96,8 +103,8 @@ func (r *PostgresProductRepository) GetBySKU(ctx context.Context, tenantID uuid.
 func (r *PostgresProductRepository) GetByBarcode(ctx context.Context, tenantID uuid.UUID, barcode string) (*domain.Product, error) {
 	query := `
 		SELECT id, tenant_id, product_code, name, description, category_id,
-		       cost_price, selling_price, mrp, tax_rate,
-		       sku, barcode, unit, status, is_active,
+		       cost_price, selling_price, mrp, tax_rate, tax_group_id,
+		       sku, barcode, hs_code, unit, status, is_active,
 		       custom_attributes, created_at, updated_at
 		FROM products
 		WHERE tenant_id = $1 AND barcode = $2
@@ -110,8 +117,8 @@ func (r *PostgresProductRepository) GetByBarcode(ctx context.Context, tenantID u
 func (r *PostgresProductRepository) GetByCategory(ctx context.Context, categoryID uuid.UUID, limit, offset int) ([]*domain.Product, error) {
 	query := `
 		SELECT id, tenant_id, product_code, name, description, category_id,
-		       cost_price, selling_price, mrp, tax_rate,
-		       sku, barcode, unit, status, is_active,
+		       cost_price, selling_price, mrp, tax_rate, tax_group_id,
+		       sku, barcode, hs_code, unit, status, is_active,
 		       custom_attributes, created_at, updated_at
 		FROM products
 		WHERE category_id = $1
@@ -128,12 +135,66 @@ func (r *PostgresProductRepository) GetByCategory(ctx context.Context, categoryI
 	return r.scanProducts(rows)
 }
 
+// GetByHSCode retrieves products by HS code prefix
+func (r *PostgresProductRepository) GetByHSCode(ctx context.Context, tenantID uuid.UUID, prefix string, limit, offset int) ([]*domain.Product, error) {
+	query := `
+		SELECT id, tenant_id, product_code, name, description, category_id,
+		       cost_price, selling_price, mrp, tax_rate, tax_group_id,
+		       sku, barcode, hs_code, unit, status, is_active,
+		       custom_attributes, created_at, updated_at
+		FROM products
+		WHERE tenant_id = $1 AND hs_code LIKE $2
+		ORDER BY hs_code, name
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, prefix+"%", limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products by HS code: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanProducts(rows)
+}
+
+// SummarizeByHSChapter counts a tenant's products per HS chapter
+func (r *PostgresProductRepository) SummarizeByHSChapter(ctx context.Context, tenantID uuid.UUID) ([]*domain.HSChapterSummary, error) {
+	query := `
+		SELECT COALESCE(LEFT(hs_code, 2), ''), COUNT(*), COALESCE(SUM(cost_price), 0)
+		FROM products
+		WHERE tenant_id = $1
+		GROUP BY LEFT(hs_code, 2)
+		ORDER BY 1
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize products by HS chapter: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []*domain.HSChapterSummary{}
+	for rows.Next() {
+		var summary domain.HSChapterSummary
+		if err := rows.Scan(&summary.Chapter, &summary.ProductCount, &summary.StockValue); err != nil {
+			return nil, fmt.Errorf("failed to scan HS chapter summary: %w", err)
+		}
+		summaries = append(summaries, &summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return summaries, nil
+}
+
 // GetByTenantID retrieves all products for a tenant
 func (r *PostgresProductRepository) GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Product, error) {
 	query := `
 		SELECT id, tenant_id, product_code, name, description, category_id,
-		       cost_price, selling_price, mrp, tax_rate,
-		       sku, barcode, unit, status, is_active,
+		       cost_price, selling_price, mrp, tax_rate, tax_group_id,
+		       sku, barcode, hs_code, unit, status, is_active,
 		       custom_attributes, created_at, updated_at
 		FROM products
 		WHERE tenant_id = $1
@@ -150,6 +211,124 @@ func (r *PostgresProductRepository) GetByTenantID(ctx context.Context, tenantID
 	return r.scanProducts(rows)
 }
 
+// StreamByTenantID passes every product for a tenant to fn with its category's code as
+// it is read, oldest first
+func (r *PostgresProductRepository) StreamByTenantID(ctx context.Context, tenantID uuid.UUID, fn func(*domain.Product, string) error) error {
+	query := `
+		SELECT p.id, p.tenant_id, p.product_code, p.name, p.description, p.category_id,
+		       p.cost_price, p.selling_price, p.mrp, p.tax_rate, p.tax_group_id,
+		       p.sku, p.barcode, p.hs_code, p.unit, p.status, p.is_active,
+		       p.custom_attributes, p.created_at, p.updated_at,
+		       COALESCE(c.category_code, '')
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id AND c.tenant_id = p.tenant_id
+		WHERE p.tenant_id = $1
+		ORDER BY p.created_at, p.id
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to query products: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var product domain.Product
+		var attrsJSON []byte
+		var categoryCode string
+		err := rows.Scan(
+			&product.ID, &product.TenantID, &product.ProductCode,
+			&product.Name, &product.Description, &product.CategoryID,
+			&product.CostPrice, &product.SellingPrice, &product.MRP, &product.TaxRate, &product.TaxGroupID,
+			&product.SKU, &product.Barcode, &product.HSCode, &product.Unit, &product.Status, &product.IsActive,
+			&attrsJSON, &product.CreatedAt, &product.UpdatedAt,
+			&categoryCode,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan product: %w", err)
+		}
+		if len(attrsJSON) > 0 {
+			if err := json.Unmarshal(attrsJSON, &product.CustomAttributes); err != nil {
+				return fmt.Errorf("failed to unmarshal custom attributes: %w", err)
+			}
+		}
+		if product.CustomAttributes == nil {
+			product.CustomAttributes = make(map[string]interface{})
+		}
+		if err := fn(&product, categoryCode); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return nil
+}
+
+// productListFilter selects the products List pages through and counts: the tenant's
+// products, only those carrying the tags in $3 when it is not null
+const productListFilter = `
+	tenant_id = $1
+	AND ($3::text[] IS NULL OR id IN (
+		SELECT ta.entity_id
+		FROM tag_assignments ta
+		JOIN tags t ON t.id = ta.tag_id
+		WHERE ta.tenant_id = $1 AND ta.entity_type = $2 AND t.name = ANY($3)
+		GROUP BY ta.entity_id
+		HAVING COUNT(*) >= $4
+	))
+`
+
+// List retrieves a page of a tenant's products, newest first, and how many there are in all.
+// Rows are read by keyset, so deep pages cost the same as the first.
+func (r *PostgresProductRepository) List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) ([]*domain.Product, int64, error) {
+	var names []string
+	minMatches := 0
+	if tags != nil {
+		names, minMatches = tags.Names, tags.MinMatches()
+	}
+	var afterAt *time.Time
+	var afterID *uuid.UUID
+	if page.After != nil {
+		afterAt, afterID = new(time.Time), new(uuid.UUID)
+		if err := page.After.Scan(afterAt, afterID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM products WHERE ` + productListFilter
+	if err := db.MainPool.QueryRow(ctx, countQuery, tenantID, tagsDomain.EntityProduct, names, minMatches).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count products: %w", err)
+	}
+
+	listQuery := `
+		SELECT id, tenant_id, product_code, name, description, category_id,
+		       cost_price, selling_price, mrp, tax_rate, tax_group_id,
+		       sku, barcode, hs_code, unit, status, is_active,
+		       custom_attributes, created_at, updated_at
+		FROM products
+		WHERE ` + productListFilter + `
+		AND ($5::timestamp IS NULL OR (created_at, id) < ($5, $6))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7
+	`
+
+	rows, err := db.MainPool.Query(ctx, listQuery, tenantID, tagsDomain.EntityProduct, names, minMatches, afterAt, afterID, page.Fetch())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list products: %w", err)
+	}
+	defer rows.Close()
+
+	products, err := r.scanProducts(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
 // Update updates a product
 func (r *PostgresProductRepository) Update(ctx context.Context, product *domain.Product) error {
 	attrsJSON, err := json.Marshal(product.CustomAttributes)
@@ -160,34 +339,40 @@ func (r *PostgresProductRepository) Update(ctx context.Context, product *domain.
 	query := `
 		UPDATE products
 		SET name = $1, description = $2, category_id = $3,
-		    cost_price = $4, selling_price = $5, mrp = $6, tax_rate = $7,
-		    sku = $8, barcode = $9, unit = $10, status = $11, is_active = $12,
-		    custom_attributes = $13, updated_at = $14
-		WHERE id = $15
+		    cost_price = $4, selling_price = $5, mrp = $6, tax_rate = $7, tax_group_id = $8,
+		    sku = $9, barcode = $10, hs_code = $11, unit = $12, status = $13, is_active = $14,
+		    custom_attributes = $15, updated_at = $16
+		WHERE tenant_id = $17 AND id = $18
 	`
 
-	_, err = db.MainPool.Exec(ctx, query,
+	tag, err := db.MainPool.Exec(ctx, query,
 		product.Name, product.Description, product.CategoryID,
-		product.CostPrice, product.SellingPrice, product.MRP, product.TaxRate,
-		product.SKU, product.Barcode, product.Unit, product.Status, product.IsActive,
-		attrsJSON, product.UpdatedAt, product.ID,
+		product.CostPrice, product.SellingPrice, product.MRP, product.TaxRate, product.TaxGroupID,
+		product.SKU, product.Barcode, product.HSCode, product.Unit, product.Status, product.IsActive,
+		attrsJSON, product.UpdatedAt, product.TenantID, product.ID,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to update product: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrProductNotFound
+	}
 
 	return nil
 }
 
-// Delete deletes a product
-func (r *PostgresProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM products WHERE id = $1`
+// Delete deletes a tenant's product
+func (r *PostgresProductRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	query := `DELETE FROM products WHERE tenant_id = $1 AND id = $2`
 
-	_, err := db.MainPool.Exec(ctx, query, id)
+	tag, err := db.MainPool.Exec(ctx, query, tenantID, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrProductNotFound
+	}
 
 	return nil
 }
@@ -196,8 +381,8 @@ func (r *PostgresProductRepository) Delete(ctx context.Context, id uuid.UUID) er
 func (r *PostgresProductRepository) Search(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) ([]*domain.Product, error) {
 	searchQuery := `
 		SELECT id, tenant_id, product_code, name, description, category_id,
-		       cost_price, selling_price, mrp, tax_rate,
-		       sku, barcode, unit, status, is_active,
+		       cost_price, selling_price, mrp, tax_rate, tax_group_id,
+		       sku, barcode, hs_code, unit, status, is_active,
 		       custom_attributes, created_at, updated_at
 		FROM products
 		WHERE tenant_id = $1
@@ -222,6 +407,89 @@ func (r *PostgresProductRepository) Search(ctx context.Context, tenantID uuid.UU
 	return r.scanProducts(rows)
 }
 
+// ListByTags retrieves products carrying all (or any) of the filter's tags
+func (r *PostgresProductRepository) ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*domain.Product, error) {
+	listQuery := `
+		SELECT id, tenant_id, product_code, name, description, category_id,
+		       cost_price, selling_price, mrp, tax_rate, tax_group_id,
+		       sku, barcode, hs_code, unit, status, is_active,
+		       custom_attributes, created_at, updated_at
+		FROM products
+		WHERE tenant_id = $1
+		AND id IN (
+			SELECT ta.entity_id
+			FROM tag_assignments ta
+			JOIN tags t ON t.id = ta.tag_id
+			WHERE ta.tenant_id = $1 AND ta.entity_type = $2 AND t.name = ANY($3)
+			GROUP BY ta.entity_id
+			HAVING COUNT(*) >= $4
+		)
+		AND (
+			$5 = ''
+			OR name ILIKE '%' || $5 || '%'
+			OR product_code ILIKE '%' || $5 || '%'
+			OR sku ILIKE '%' || $5 || '%'
+			OR barcode ILIKE '%' || $5 || '%'
+			OR description ILIKE '%' || $5 || '%'
+		)
+		ORDER BY name
+		LIMIT $6 OFFSET $7
+	`
+
+	rows, err := db.MainPool.Query(ctx, listQuery, tenantID, tagsDomain.EntityProduct, filter.Names, filter.MinMatches(), query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products by tags: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanProducts(rows)
+}
+
+// ListForPriceUpdate retrieves the products matching a bulk price update filter,
+// the category filter taking in its subcategories
+func (r *PostgresProductRepository) ListForPriceUpdate(ctx context.Context, tenantID uuid.UUID, filter domain.BulkPriceFilter, limit int) ([]*domain.Product, error) {
+	listQuery := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE tenant_id = $1 AND id = $2
+			UNION
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id WHERE c.tenant_id = $1
+		)
+		SELECT id, tenant_id, product_code, name, description, category_id,
+		       cost_price, selling_price, mrp, tax_rate, tax_group_id,
+		       sku, barcode, hs_code, unit, status, is_active,
+		       custom_attributes, created_at, updated_at
+		FROM products
+		WHERE tenant_id = $1
+		AND ($2::uuid IS NULL OR category_id IN (SELECT id FROM subtree))
+		AND (cardinality($3::uuid[]) = 0 OR id = ANY($3))
+		AND (
+			$4 = ''
+			OR name ILIKE '%' || $4 || '%'
+			OR product_code ILIKE '%' || $4 || '%'
+			OR sku ILIKE '%' || $4 || '%'
+		)
+		AND ($5::numeric IS NULL OR selling_price >= $5)
+		AND ($6::numeric IS NULL OR selling_price <= $6)
+		AND ($7 OR (is_active AND status = 'active'))
+		ORDER BY product_code
+		LIMIT $8
+	`
+
+	productIDs := filter.ProductIDs
+	if productIDs == nil {
+		productIDs = []uuid.UUID{}
+	}
+
+	rows, err := db.MainPool.Query(ctx, listQuery, tenantID, filter.CategoryID, productIDs, filter.Query,
+		filter.MinPrice, filter.MaxPrice, filter.IncludeInactive, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products for price update: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanProducts(rows)
+}
+
 // Count returns total number of products for a tenant
 func (r *PostgresProductRepository) Count(ctx context.Context, tenantID uuid.UUID) (int64, error) {
 	var count int64
@@ -236,6 +504,18 @@ func (r *PostgresProductRepository) Count(ctx context.Context, tenantID uuid.UUI
 	return count, nil
 }
 
+// CountByIDs counts the tenant's products among ids
+func (r *PostgresProductRepository) CountByIDs(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (int, error) {
+	var count int
+
+	query := `SELECT COUNT(*) FROM products WHERE tenant_id = $1 AND id = ANY($2)`
+	if err := db.MainPool.QueryRow(ctx, query, tenantID, ids).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count products: %w", err)
+	}
+
+	return count, nil
+}
+
 // GetNextProductNumber gets the next product number for code generation
 func (r *PostgresProductRepository) GetNextProductNumber(ctx context.Context, tenantID uuid.UUID) (int64, error) {
 	var count int64
@@ -258,8 +538,8 @@ func (r *PostgresProductRepository) scanProduct(row pgx.Row) (*domain.Product, e
 	err := row.Scan(
 		&product.ID, &product.TenantID, &product.ProductCode,
 		&product.Name, &product.Description, &product.CategoryID,
-		&product.CostPrice, &product.SellingPrice, &product.MRP, &product.TaxRate,
-		&product.SKU, &product.Barcode, &product.Unit, &product.Status, &product.IsActive,
+		&product.CostPrice, &product.SellingPrice, &product.MRP, &product.TaxRate, &product.TaxGroupID,
+		&product.SKU, &product.Barcode, &product.HSCode, &product.Unit, &product.Status, &product.IsActive,
 		&attrsJSON, &product.CreatedAt, &product.UpdatedAt,
 	)
 
@@ -292,8 +572,8 @@ func (r *PostgresProductRepository) scanProducts(rows pgx.Rows) ([]*domain.Produ
 		err := rows.Scan(
 			&product.ID, &product.TenantID, &product.ProductCode,
 			&product.Name, &product.Description, &product.CategoryID,
-			&product.CostPrice, &product.SellingPrice, &product.MRP, &product.TaxRate,
-			&product.SKU, &product.Barcode, &product.Unit, &product.Status, &product.IsActive,
+			&product.CostPrice, &product.SellingPrice, &product.MRP, &product.TaxRate, &product.TaxGroupID,
+			&product.SKU, &product.Barcode, &product.HSCode, &product.Unit, &product.Status, &product.IsActive,
 			&attrsJSON, &product.CreatedAt, &product.UpdatedAt,
 		)
 
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+)
+
+// PostgresQuickPickRepository implements QuickPickRepository using PostgreSQL
+type PostgresQuickPickRepository struct{}
+
+// NewPostgresQuickPickRepository creates a new PostgreSQL quick-pick repository
+func NewPostgresQuickPickRepository() *PostgresQuickPickRepository {
+	return &PostgresQuickPickRepository{}
+}
+
+// RecordView records that a user opened a product
+func (r *PostgresQuickPickRepository) RecordView(ctx context.Context, tenantID, userID, productID uuid.UUID) error {
+	query := `
+		INSERT INTO product_quick_picks (tenant_id, user_id, product_id, view_count, last_viewed_at)
+		VALUES ($1, $2, $3, 1, NOW())
+		ON CONFLICT (user_id, product_id) DO UPDATE
+		SET view_count = product_quick_picks.view_count + 1, last_viewed_at = NOW()
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, tenantID, userID, productID)
+	if err != nil {
+		return fmt.Errorf("failed to record product view: %w", err)
+	}
+
+	return nil
+}
+
+// SetFavorite pins or unpins a product for a user
+func (r *PostgresQuickPickRepository) SetFavorite(ctx context.Context, tenantID, userID, productID uuid.UUID, favorite bool, position int) error {
+	query := `
+		INSERT INTO product_quick_picks (tenant_id, user_id, product_id, is_favorite, position)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, product_id) DO UPDATE
+		SET is_favorite = EXCLUDED.is_favorite, position = EXCLUDED.position
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, tenantID, userID, productID, favorite, position)
+	if err != nil {
+		return fmt.Errorf("failed to set product favorite: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves a user's quick-pick products with product details
+func (r *PostgresQuickPickRepository) List(ctx context.Context, tenantID, userID uuid.UUID, limit int) ([]*domain.ProductQuickPick, error) {
+	query := `
+		SELECT p.id, p.tenant_id, p.product_code, p.name, p.description, p.category_id,
+		       p.cost_price, p.selling_price, p.mrp, p.tax_rate, p.tax_group_id,
+		       p.sku, p.barcode, p.hs_code, p.unit, p.status, p.is_active,
+		       p.custom_attributes, p.created_at, p.updated_at,
+		       q.user_id, q.is_favorite, q.position, q.view_count, q.last_viewed_at
+		FROM product_quick_picks q
+		JOIN products p ON p.id = q.product_id
+		WHERE q.tenant_id = $1 AND q.user_id = $2 AND p.is_active = true
+		ORDER BY q.is_favorite DESC, q.position, q.last_viewed_at DESC NULLS LAST
+		LIMIT $3
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quick picks: %w", err)
+	}
+	defer rows.Close()
+
+	picks := []*domain.ProductQuickPick{}
+	for rows.Next() {
+		var product domain.Product
+		var pick domain.ProductQuickPick
+		var attrsJSON []byte
+
+		err := rows.Scan(
+			&product.ID, &product.TenantID, &product.ProductCode,
+			&product.Name, &product.Description, &product.CategoryID,
+			&product.CostPrice, &product.SellingPrice, &product.MRP, &product.TaxRate, &product.TaxGroupID,
+			&product.SKU, &product.Barcode, &product.HSCode, &product.Unit, &product.Status, &product.IsActive,
+			&attrsJSON, &product.CreatedAt, &product.UpdatedAt,
+			&pick.UserID, &pick.IsFavorite, &pick.Position, &pick.ViewCount, &pick.LastViewedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quick pick: %w", err)
+		}
+
+		if len(attrsJSON) > 0 {
+			if err := json.Unmarshal(attrsJSON, &product.CustomAttributes); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal custom attributes: %w", err)
+			}
+		}
+		if product.CustomAttributes == nil {
+			product.CustomAttributes = make(map[string]interface{})
+		}
+
+		pick.Product = &product
+		picks = append(picks, &pick)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return picks, nil
+}
+
+// TrimRecent deletes a user's oldest non-favorite views beyond keep
+func (r *PostgresQuickPickRepository) TrimRecent(ctx context.Context, tenantID, userID uuid.UUID, keep int) error {
+	query := `
+		DELETE FROM product_quick_picks
+		WHERE tenant_id = $1 AND user_id = $2 AND is_favorite = false
+		AND product_id NOT IN (
+			SELECT product_id FROM product_quick_picks
+			WHERE tenant_id = $1 AND user_id = $2 AND is_favorite = false
+			ORDER BY last_viewed_at DESC NULLS LAST
+			LIMIT $3
+		)
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, tenantID, userID, keep)
+	if err != nil {
+		return fmt.Errorf("failed to trim recent products: %w", err)
+	}
+
+	return nil
+}
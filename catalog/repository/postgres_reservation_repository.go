@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresReservationRepository implements ReservationRepository using PostgreSQL
+type PostgresReservationRepository struct{}
+
+// NewPostgresReservationRepository creates a new PostgreSQL reservation repository
+func NewPostgresReservationRepository() *PostgresReservationRepository {
+	return &PostgresReservationRepository{}
+}
+
+const reservationColumns = `id, tenant_id, product_id, quantity, reference_type, reference_id, reference_number,
+	status, expires_at, note, created_by, created_at, closed_at, close_reason`
+
+// CreateIfAvailable saves reservations that fit the stock not already held
+func (r *PostgresReservationRepository) CreateIfAvailable(ctx context.Context, tenantID uuid.UUID, reservations []*domain.StockReservation, onHand map[uuid.UUID]float64) error {
+	wanted := make(map[uuid.UUID]float64, len(reservations))
+	productIDs := make([]uuid.UUID, 0, len(reservations))
+	for _, reservation := range reservations {
+		if _, seen := wanted[reservation.ProductID]; !seen {
+			productIDs = append(productIDs, reservation.ProductID)
+		}
+		wanted[reservation.ProductID] += reservation.Quantity
+	}
+	// Locking in ID order keeps two multi-product reservations from deadlocking
+	sort.Slice(productIDs, func(i, j int) bool { return productIDs[i].String() < productIDs[j].String() })
+
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		// NO KEY UPDATE serializes reservations of a product without blocking inserts that reference it
+		_, err := tx.Exec(ctx, `
+			SELECT id FROM products
+			WHERE tenant_id = $1 AND id = ANY($2)
+			ORDER BY id::text
+			FOR NO KEY UPDATE
+		`, tenantID, productIDs)
+		if err != nil {
+			return fmt.Errorf("failed to lock products: %w", err)
+		}
+
+		reserved, err := queryReserved(ctx, tx, tenantID, productIDs)
+		if err != nil {
+			return err
+		}
+		for _, productID := range productIDs {
+			available := onHand[productID] - reserved[productID]
+			if wanted[productID] > available+1e-9 {
+				return fmt.Errorf("%w: product %s has %g available, %g requested",
+					domain.ErrInsufficientStock, productID, available, wanted[productID])
+			}
+		}
+
+		query := `INSERT INTO stock_reservations (` + reservationColumns + `)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+		for _, reservation := range reservations {
+			_, err := tx.Exec(ctx, query,
+				reservation.ID, reservation.TenantID, reservation.ProductID, reservation.Quantity,
+				reservation.ReferenceType, reservation.ReferenceID, reservation.ReferenceNumber,
+				reservation.Status, reservation.ExpiresAt, reservation.Note, reservation.CreatedBy,
+				reservation.CreatedAt, reservation.ClosedAt, reservation.CloseReason,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create stock reservation: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetByID retrieves a tenant's reservation
+func (r *PostgresReservationRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.StockReservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM stock_reservations WHERE tenant_id = $1 AND id = $2`
+
+	return scanReservation(db.MainPool.QueryRow(ctx, query, tenantID, id))
+}
+
+// List retrieves reservations newest first
+func (r *PostgresReservationRepository) List(ctx context.Context, tenantID uuid.UUID, filter domain.ReservationFilter) ([]*domain.StockReservation, error) {
+	query := `
+		SELECT ` + reservationColumns + `
+		FROM stock_reservations
+		WHERE tenant_id = $1
+		  AND ($2 = '' OR status = $2)
+		  AND ($3::uuid IS NULL OR product_id = $3)
+		  AND ($4 = '' OR reference_type = $4)
+		  AND ($5::uuid IS NULL OR reference_id = $5)
+		ORDER BY created_at DESC, id
+		LIMIT $6 OFFSET $7
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, string(filter.Status), filter.ProductID,
+		string(filter.ReferenceType), filter.ReferenceID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stock reservations: %w", err)
+	}
+	defer rows.Close()
+
+	reservations := []*domain.StockReservation{}
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return reservations, nil
+}
+
+// Reserved sums the held quantities of the products
+func (r *PostgresReservationRepository) Reserved(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	return queryReserved(ctx, db.MainPool, tenantID, productIDs)
+}
+
+// Close closes an active reservation
+func (r *PostgresReservationRepository) Close(ctx context.Context, tenantID, id uuid.UUID, status domain.ReservationStatus, reason *string) (*domain.StockReservation, error) {
+	query := `
+		UPDATE stock_reservations
+		SET status = $1, closed_at = $2, close_reason = $3
+		WHERE tenant_id = $4 AND id = $5 AND status = 'active'
+		RETURNING ` + reservationColumns
+
+	reservation, err := scanReservation(db.MainPool.QueryRow(ctx, query, status, time.Now(), reason, tenantID, id))
+	if errors.Is(err, domain.ErrReservationNotFound) {
+		// Tell a missing reservation from one already closed
+		if _, getErr := r.GetByID(ctx, tenantID, id); getErr == nil {
+			return nil, domain.ErrReservationClosed
+		}
+	}
+	return reservation, err
+}
+
+// CloseByReference closes a document's active reservations
+func (r *PostgresReservationRepository) CloseByReference(ctx context.Context, tenantID uuid.UUID, referenceType domain.ReservationReference, referenceID uuid.UUID, status domain.ReservationStatus, reason *string) (int64, error) {
+	query := `
+		UPDATE stock_reservations
+		SET status = $1, closed_at = $2, close_reason = $3
+		WHERE tenant_id = $4 AND reference_type = $5 AND reference_id = $6 AND status = 'active'
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query, status, time.Now(), reason, tenantID, referenceType, referenceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to close stock reservations: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// ExpireDue marks lapsed reservations expired
+func (r *PostgresReservationRepository) ExpireDue(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE stock_reservations
+		SET status = 'expired', closed_at = expires_at
+		WHERE status = 'active' AND expires_at <= $1
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire stock reservations: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// Summarize totals held reservations per product
+func (r *PostgresReservationRepository) Summarize(ctx context.Context, tenantID uuid.UUID) ([]*domain.ReservationSummary, error) {
+	query := `
+		SELECT r.product_id, p.product_code, p.name, COUNT(*), SUM(r.quantity), MIN(r.expires_at)
+		FROM stock_reservations r
+		JOIN products p ON p.id = r.product_id
+		WHERE r.tenant_id = $1 AND r.status = 'active' AND r.expires_at > NOW()
+		GROUP BY r.product_id, p.product_code, p.name
+		ORDER BY SUM(r.quantity) DESC, p.product_code
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize stock reservations: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []*domain.ReservationSummary{}
+	for rows.Next() {
+		var summary domain.ReservationSummary
+		if err := rows.Scan(
+			&summary.ProductID, &summary.ProductCode, &summary.ProductName,
+			&summary.Reservations, &summary.Reserved, &summary.NextExpiry,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan reservation summary: %w", err)
+		}
+		summaries = append(summaries, &summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// queryer is what queryReserved needs of a pool or transaction
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// queryReserved sums held reservations per product
+func queryReserved(ctx context.Context, q queryer, tenantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	reserved := make(map[uuid.UUID]float64, len(productIDs))
+	if len(productIDs) == 0 {
+		return reserved, nil
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT product_id, SUM(quantity)
+		FROM stock_reservations
+		WHERE tenant_id = $1 AND product_id = ANY($2) AND status = 'active' AND expires_at > NOW()
+		GROUP BY product_id
+	`, tenantID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reserved stock: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID uuid.UUID
+		var quantity float64
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan reserved stock: %w", err)
+		}
+		reserved[productID] = quantity
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return reserved, nil
+}
+
+func scanReservation(row pgx.Row) (*domain.StockReservation, error) {
+	var reservation domain.StockReservation
+	err := row.Scan(
+		&reservation.ID, &reservation.TenantID, &reservation.ProductID, &reservation.Quantity,
+		&reservation.ReferenceType, &reservation.ReferenceID, &reservation.ReferenceNumber,
+		&reservation.Status, &reservation.ExpiresAt, &reservation.Note, &reservation.CreatedBy,
+		&reservation.CreatedAt, &reservation.ClosedAt, &reservation.CloseReason,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrReservationNotFound
+		}
+		return nil, fmt.Errorf("failed to scan stock reservation: %w", err)
+	}
+
+	return &reservation, nil
+}
@@ -0,0 +1,446 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresTaxRepository implements TaxRepository using PostgreSQL
+type PostgresTaxRepository struct{}
+
+// NewPostgresTaxRepository creates a new PostgreSQL tax repository
+func NewPostgresTaxRepository() *PostgresTaxRepository {
+	return &PostgresTaxRepository{}
+}
+
+// CreateRate creates a new tax rate
+func (r *PostgresTaxRepository) CreateRate(ctx context.Context, rate *domain.TaxRate) error {
+	query := `
+		INSERT INTO tax_rates (
+			id, tenant_id, code, name, kind, is_system, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		rate.ID, rate.TenantID, rate.Code, rate.Name, rate.Kind,
+		rate.IsSystem, rate.IsActive, rate.CreatedAt, rate.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create tax rate: %w", err)
+	}
+
+	return nil
+}
+
+// GetRateByID retrieves a tax rate with its versions
+func (r *PostgresTaxRepository) GetRateByID(ctx context.Context, id uuid.UUID) (*domain.TaxRate, error) {
+	query := `
+		SELECT id, tenant_id, code, name, kind, is_system, is_active, created_at, updated_at
+		FROM tax_rates
+		WHERE id = $1
+	`
+
+	rate, err := r.scanRate(db.MainPool.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadVersions(ctx, []*domain.TaxRate{rate}); err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+// ListRates retrieves system rates and the tenant's custom rates with their versions
+func (r *PostgresTaxRepository) ListRates(ctx context.Context, tenantID uuid.UUID) ([]*domain.TaxRate, error) {
+	query := `
+		SELECT id, tenant_id, code, name, kind, is_system, is_active, created_at, updated_at
+		FROM tax_rates
+		WHERE tenant_id = $1 OR tenant_id IS NULL
+		ORDER BY is_system DESC, code
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tax rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []*domain.TaxRate{}
+	for rows.Next() {
+		rate, err := r.scanRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	if err := r.loadVersions(ctx, rates); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// UpdateRate updates a tenant tax rate
+func (r *PostgresTaxRepository) UpdateRate(ctx context.Context, rate *domain.TaxRate) error {
+	query := `
+		UPDATE tax_rates
+		SET name = $1, is_active = $2, updated_at = $3
+		WHERE id = $4 AND is_system = false
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, rate.Name, rate.IsActive, rate.UpdatedAt, rate.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update tax rate: %w", err)
+	}
+
+	return nil
+}
+
+// AddRateVersion adds an effective-dated percentage to a tax rate
+func (r *PostgresTaxRepository) AddRateVersion(ctx context.Context, version *domain.TaxRateVersion) error {
+	query := `
+		INSERT INTO tax_rate_versions (id, tax_rate_id, rate, effective_from, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		version.ID, version.TaxRateID, version.Rate, version.EffectiveFrom, version.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to add tax rate version: %w", err)
+	}
+
+	return nil
+}
+
+// CreateGroup creates a tax group with its components
+func (r *PostgresTaxRepository) CreateGroup(ctx context.Context, group *domain.TaxGroup) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO tax_groups (
+				id, tenant_id, code, name, description, is_system, is_active, created_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`
+
+		_, err := tx.Exec(ctx, query,
+			group.ID, group.TenantID, group.Code, group.Name, group.Description,
+			group.IsSystem, group.IsActive, group.CreatedAt, group.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create tax group: %w", err)
+		}
+
+		return r.insertComponents(ctx, tx, group)
+	})
+}
+
+// GetGroupByID retrieves a tax group with its components
+func (r *PostgresTaxRepository) GetGroupByID(ctx context.Context, id uuid.UUID) (*domain.TaxGroup, error) {
+	query := `
+		SELECT id, tenant_id, code, name, description, is_system, is_active, created_at, updated_at
+		FROM tax_groups
+		WHERE id = $1
+	`
+
+	group, err := r.scanGroup(db.MainPool.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadComponents(ctx, []*domain.TaxGroup{group}); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// ListGroups retrieves system groups and the tenant's custom groups
+func (r *PostgresTaxRepository) ListGroups(ctx context.Context, tenantID uuid.UUID) ([]*domain.TaxGroup, error) {
+	query := `
+		SELECT id, tenant_id, code, name, description, is_system, is_active, created_at, updated_at
+		FROM tax_groups
+		WHERE tenant_id = $1 OR tenant_id IS NULL
+		ORDER BY is_system DESC, code
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tax groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []*domain.TaxGroup{}
+	for rows.Next() {
+		group, err := r.scanGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	if err := r.loadComponents(ctx, groups); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// UpdateGroup updates a tenant tax group and replaces its components
+func (r *PostgresTaxRepository) UpdateGroup(ctx context.Context, group *domain.TaxGroup) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := `
+			UPDATE tax_groups
+			SET name = $1, description = $2, is_active = $3, updated_at = $4
+			WHERE id = $5 AND is_system = false
+		`
+
+		_, err := tx.Exec(ctx, query, group.Name, group.Description, group.IsActive, group.UpdatedAt, group.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update tax group: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM tax_group_components WHERE tax_group_id = $1`, group.ID); err != nil {
+			return fmt.Errorf("failed to clear tax group components: %w", err)
+		}
+
+		return r.insertComponents(ctx, tx, group)
+	})
+}
+
+// DeleteGroup deletes a tenant tax group
+func (r *PostgresTaxRepository) DeleteGroup(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM tax_groups WHERE id = $1 AND is_system = false`
+
+	_, err := db.MainPool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tax group: %w", err)
+	}
+
+	return nil
+}
+
+// CountGroupAssignments returns how many products and categories use a tax group
+func (r *PostgresTaxRepository) CountGroupAssignments(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+
+	query := `
+		SELECT (SELECT COUNT(*) FROM products WHERE tax_group_id = $1)
+		     + (SELECT COUNT(*) FROM categories WHERE tax_group_id = $1)
+	`
+
+	err := db.MainPool.QueryRow(ctx, query, id).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tax group assignments: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetEffectiveComponents resolves each component of a group to the rate in effect on a date
+func (r *PostgresTaxRepository) GetEffectiveComponents(ctx context.Context, groupID uuid.UUID, date time.Time) ([]*domain.EffectiveTaxComponent, error) {
+	query := `
+		SELECT t.id, t.code, t.name, t.kind, v.rate, c.sequence, c.compound
+		FROM tax_group_components c
+		JOIN tax_rates t ON t.id = c.tax_rate_id
+		LEFT JOIN LATERAL (
+			SELECT rate
+			FROM tax_rate_versions
+			WHERE tax_rate_id = t.id AND effective_from <= $2
+			ORDER BY effective_from DESC
+			LIMIT 1
+		) v ON true
+		WHERE c.tax_group_id = $1
+		ORDER BY c.sequence
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, groupID, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query effective tax components: %w", err)
+	}
+	defer rows.Close()
+
+	components := []*domain.EffectiveTaxComponent{}
+	for rows.Next() {
+		var c domain.EffectiveTaxComponent
+		var rate *float64
+
+		if err := rows.Scan(&c.TaxRateID, &c.Code, &c.Name, &c.Kind, &rate, &c.Sequence, &c.Compound); err != nil {
+			return nil, fmt.Errorf("failed to scan effective tax component: %w", err)
+		}
+		if rate == nil {
+			return nil, fmt.Errorf("%w: %s on %s", domain.ErrNoEffectiveRate, c.Code, date.Format("2006-01-02"))
+		}
+
+		c.Rate = *rate
+		components = append(components, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return components, nil
+}
+
+// insertComponents writes a group's components inside a transaction
+func (r *PostgresTaxRepository) insertComponents(ctx context.Context, tx pgx.Tx, group *domain.TaxGroup) error {
+	query := `
+		INSERT INTO tax_group_components (tax_group_id, tax_rate_id, sequence, compound)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	for _, c := range group.Components {
+		if _, err := tx.Exec(ctx, query, group.ID, c.TaxRateID, c.Sequence, c.Compound); err != nil {
+			return fmt.Errorf("failed to add tax group component: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadVersions attaches versions to the given rates
+func (r *PostgresTaxRepository) loadVersions(ctx context.Context, rates []*domain.TaxRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(rates))
+	byID := make(map[uuid.UUID]*domain.TaxRate, len(rates))
+	for i, rate := range rates {
+		ids[i] = rate.ID
+		byID[rate.ID] = rate
+		rate.Versions = []*domain.TaxRateVersion{}
+	}
+
+	query := `
+		SELECT id, tax_rate_id, rate, effective_from, created_at
+		FROM tax_rate_versions
+		WHERE tax_rate_id = ANY($1)
+		ORDER BY effective_from
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query tax rate versions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v domain.TaxRateVersion
+		if err := rows.Scan(&v.ID, &v.TaxRateID, &v.Rate, &v.EffectiveFrom, &v.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan tax rate version: %w", err)
+		}
+		if rate, ok := byID[v.TaxRateID]; ok {
+			rate.Versions = append(rate.Versions, &v)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return nil
+}
+
+// loadComponents attaches components to the given groups
+func (r *PostgresTaxRepository) loadComponents(ctx context.Context, groups []*domain.TaxGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(groups))
+	byID := make(map[uuid.UUID]*domain.TaxGroup, len(groups))
+	for i, group := range groups {
+		ids[i] = group.ID
+		byID[group.ID] = group
+		group.Components = []*domain.TaxGroupComponent{}
+	}
+
+	query := `
+		SELECT tax_group_id, tax_rate_id, sequence, compound
+		FROM tax_group_components
+		WHERE tax_group_id = ANY($1)
+		ORDER BY sequence
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query tax group components: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var groupID uuid.UUID
+		var c domain.TaxGroupComponent
+		if err := rows.Scan(&groupID, &c.TaxRateID, &c.Sequence, &c.Compound); err != nil {
+			return fmt.Errorf("failed to scan tax group component: %w", err)
+		}
+		if group, ok := byID[groupID]; ok {
+			group.Components = append(group.Components, &c)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return nil
+}
+
+// scanRate scans a single tax rate row
+func (r *PostgresTaxRepository) scanRate(row pgx.Row) (*domain.TaxRate, error) {
+	var rate domain.TaxRate
+
+	err := row.Scan(
+		&rate.ID, &rate.TenantID, &rate.Code, &rate.Name, &rate.Kind,
+		&rate.IsSystem, &rate.IsActive, &rate.CreatedAt, &rate.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUnknownTax
+		}
+		return nil, fmt.Errorf("failed to scan tax rate: %w", err)
+	}
+
+	return &rate, nil
+}
+
+// scanGroup scans a single tax group row
+func (r *PostgresTaxRepository) scanGroup(row pgx.Row) (*domain.TaxGroup, error) {
+	var group domain.TaxGroup
+
+	err := row.Scan(
+		&group.ID, &group.TenantID, &group.Code, &group.Name, &group.Description,
+		&group.IsSystem, &group.IsActive, &group.CreatedAt, &group.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUnknownTax
+		}
+		return nil, fmt.Errorf("failed to scan tax group: %w", err)
+	}
+
+	return &group, nil
+}
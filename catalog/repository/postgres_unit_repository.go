@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresUnitRepository implements UnitRepository using PostgreSQL
+type PostgresUnitRepository struct{}
+
+// NewPostgresUnitRepository creates a new PostgreSQL unit repository
+func NewPostgresUnitRepository() *PostgresUnitRepository {
+	return &PostgresUnitRepository{}
+}
+
+// Create creates a new tenant unit
+func (r *PostgresUnitRepository) Create(ctx context.Context, unit *domain.UnitOfMeasure) error {
+	query := `
+		INSERT INTO units_of_measure (
+			id, tenant_id, code, name, symbol, decimal_places,
+			is_system, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		unit.ID, unit.TenantID, unit.Code, unit.Name, unit.Symbol, unit.DecimalPlaces,
+		unit.IsSystem, unit.IsActive, unit.CreatedAt, unit.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create unit: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a unit by ID
+func (r *PostgresUnitRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.UnitOfMeasure, error) {
+	query := `
+		SELECT id, tenant_id, code, name, symbol, decimal_places,
+		       is_system, is_active, created_at, updated_at
+		FROM units_of_measure
+		WHERE id = $1
+	`
+
+	return r.scanUnit(db.MainPool.QueryRow(ctx, query, id))
+}
+
+// GetByCode retrieves a unit by code, preferring the tenant's own unit over a system unit
+func (r *PostgresUnitRepository) GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.UnitOfMeasure, error) {
+	query := `
+		SELECT id, tenant_id, code, name, symbol, decimal_places,
+		       is_system, is_active, created_at, updated_at
+		FROM units_of_measure
+		WHERE code = $2 AND (tenant_id = $1 OR tenant_id IS NULL)
+		ORDER BY tenant_id NULLS LAST
+		LIMIT 1
+	`
+
+	return r.scanUnit(db.MainPool.QueryRow(ctx, query, tenantID, code))
+}
+
+// List retrieves system units and the tenant's custom units
+func (r *PostgresUnitRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*domain.UnitOfMeasure, error) {
+	query := `
+		SELECT id, tenant_id, code, name, symbol, decimal_places,
+		       is_system, is_active, created_at, updated_at
+		FROM units_of_measure
+		WHERE tenant_id = $1 OR tenant_id IS NULL
+		ORDER BY is_system DESC, code
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query units: %w", err)
+	}
+	defer rows.Close()
+
+	units := []*domain.UnitOfMeasure{}
+	for rows.Next() {
+		unit, err := r.scanUnit(rows)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return units, nil
+}
+
+// Update updates a tenant unit
+func (r *PostgresUnitRepository) Update(ctx context.Context, unit *domain.UnitOfMeasure) error {
+	query := `
+		UPDATE units_of_measure
+		SET name = $1, symbol = $2, decimal_places = $3, is_active = $4, updated_at = $5
+		WHERE id = $6 AND is_system = false
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		unit.Name, unit.Symbol, unit.DecimalPlaces, unit.IsActive, unit.UpdatedAt, unit.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update unit: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a tenant unit
+func (r *PostgresUnitRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM units_of_measure WHERE id = $1 AND is_system = false`
+
+	_, err := db.MainPool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete unit: %w", err)
+	}
+
+	return nil
+}
+
+// CountProductsUsing returns the number of tenant products using a unit code
+func (r *PostgresUnitRepository) CountProductsUsing(ctx context.Context, tenantID uuid.UUID, code string) (int64, error) {
+	var count int64
+
+	query := `SELECT COUNT(*) FROM products WHERE tenant_id = $1 AND unit = $2`
+
+	err := db.MainPool.QueryRow(ctx, query, tenantID, code).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count products using unit: %w", err)
+	}
+
+	return count, nil
+}
+
+// scanUnit scans a single unit row
+func (r *PostgresUnitRepository) scanUnit(row pgx.Row) (*domain.UnitOfMeasure, error) {
+	var unit domain.UnitOfMeasure
+
+	err := row.Scan(
+		&unit.ID, &unit.TenantID, &unit.Code, &unit.Name, &unit.Symbol, &unit.DecimalPlaces,
+		&unit.IsSystem, &unit.IsActive, &unit.CreatedAt, &unit.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUnknownUnit
+		}
+		return nil, fmt.Errorf("failed to scan unit: %w", err)
+	}
+
+	return &unit, nil
+}
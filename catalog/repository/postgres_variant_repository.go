@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresVariantRepository implements VariantRepository using PostgreSQL
+type PostgresVariantRepository struct{}
+
+// NewPostgresVariantRepository creates a new PostgreSQL variant repository
+func NewPostgresVariantRepository() *PostgresVariantRepository {
+	return &PostgresVariantRepository{}
+}
+
+// Create links a variant to its parent
+func (r *PostgresVariantRepository) Create(ctx context.Context, v *domain.ProductVariant) error {
+	attrsJSON, err := json.Marshal(v.Attributes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal variant attributes: %w", err)
+	}
+
+	query := `
+		INSERT INTO product_variants (product_id, tenant_id, parent_id, attributes, variant_key, price_delta, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err = db.MainPool.Exec(ctx, query, v.ProductID, v.TenantID, v.ParentID, attrsJSON, v.Key(), v.PriceDelta, v.CreatedAt)
+	if err != nil {
+		if isVariantKeyConflict(err) {
+			return fmt.Errorf("%w: the product already has a variant %s", domain.ErrInvalidVariantMatrix, v.Key())
+		}
+		return fmt.Errorf("failed to create product variant: %w", err)
+	}
+
+	return nil
+}
+
+// ListByParent retrieves a product's variants, oldest first
+func (r *PostgresVariantRepository) ListByParent(ctx context.Context, tenantID, parentID uuid.UUID) ([]*domain.ProductVariant, error) {
+	query := `
+		SELECT product_id, tenant_id, parent_id, attributes, price_delta, created_at
+		FROM product_variants
+		WHERE tenant_id = $1 AND parent_id = $2
+		ORDER BY created_at, variant_key
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product variants: %w", err)
+	}
+	defer rows.Close()
+
+	variants := []*domain.ProductVariant{}
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return variants, nil
+}
+
+// GetByProduct retrieves the variant link of a product, if it is one
+func (r *PostgresVariantRepository) GetByProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.ProductVariant, error) {
+	query := `
+		SELECT product_id, tenant_id, parent_id, attributes, price_delta, created_at
+		FROM product_variants
+		WHERE tenant_id = $1 AND product_id = $2
+	`
+
+	v, err := scanVariant(db.MainPool.QueryRow(ctx, query, tenantID, productID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return v, err
+}
+
+func isVariantKeyConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uq_product_variants_key"
+}
+
+func scanVariant(row pgx.Row) (*domain.ProductVariant, error) {
+	var v domain.ProductVariant
+	var attrsJSON []byte
+	if err := row.Scan(&v.ProductID, &v.TenantID, &v.ParentID, &attrsJSON, &v.PriceDelta, &v.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan product variant: %w", err)
+	}
+	if err := json.Unmarshal(attrsJSON, &v.Attributes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal variant attributes: %w", err)
+	}
+	return &v, nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// PricingRepository defines the interface for markup rules, price changes and repricing runs
+type PricingRepository interface {
+	// Markup rules
+	CreateRule(ctx context.Context, rule *domain.MarkupRule) error
+	GetRuleByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.MarkupRule, error)
+	ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.MarkupRule, error)
+	UpdateRule(ctx context.Context, rule *domain.MarkupRule) error
+	DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error
+	// GetActiveRuleForProduct returns the product's own active rule, or nil
+	GetActiveRuleForProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.MarkupRule, error)
+	// GetActiveRuleForCategory returns the category's own active rule, or nil
+	GetActiveRuleForCategory(ctx context.Context, tenantID, categoryID uuid.UUID) (*domain.MarkupRule, error)
+	// ListTenantsWithRules returns tenants that have at least one active rule
+	ListTenantsWithRules(ctx context.Context) ([]uuid.UUID, error)
+
+	// Price changes
+	CreateChange(ctx context.Context, change *domain.PriceChange) error
+	GetChangeByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.PriceChange, error)
+	ListChanges(ctx context.Context, tenantID uuid.UUID, status domain.PriceChangeStatus, limit, offset int) ([]*domain.PriceChange, error)
+	UpdateChange(ctx context.Context, change *domain.PriceChange) error
+	// SupersedePending marks a product's pending changes as superseded
+	SupersedePending(ctx context.Context, tenantID, productID uuid.UUID) error
+
+	// Repricing runs
+	CreateRun(ctx context.Context, run *domain.RepricingRun) error
+	FinishRun(ctx context.Context, run *domain.RepricingRun) error
+	// GetRun retrieves a run with its changes
+	GetRun(ctx context.Context, tenantID, id uuid.UUID) (*domain.RepricingRun, error)
+	ListRuns(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.RepricingRun, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// ProductBarcodeRepository defines the interface for product pack barcode data access
+type ProductBarcodeRepository interface {
+	// ListByProduct returns a product's pack barcodes, smallest pack first
+	ListByProduct(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.ProductBarcode, error)
+	// Replace swaps a product's pack barcodes for the given ones
+	Replace(ctx context.Context, tenantID, productID uuid.UUID, barcodes []domain.ProductBarcode) error
+	// GetByBarcode returns the pack barcode, ErrProductNotFound when no pack carries it
+	GetByBarcode(ctx context.Context, tenantID uuid.UUID, barcode string) (*domain.ProductBarcode, error)
+	// FindTaken maps those of the barcodes that belong to products other than productID,
+	// as their own barcode or a pack barcode, to the product using each
+	FindTaken(ctx context.Context, tenantID, productID uuid.UUID, barcodes []string) (map[string]uuid.UUID, error)
+}
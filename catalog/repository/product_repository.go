@@ -4,21 +4,37 @@ import (
 	"context"
 
 	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/db"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 )
 
 // ProductRepository defines the interface for product data access
 type ProductRepository interface {
 	Create(ctx context.Context, product *domain.Product) error
-	GetByID(ctx context.Context, id uuid.UUID) (*domain.Product, error)
+	// Single-record operations are scoped to the tenant; another tenant's product is ErrProductNotFound
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Product, error)
 	GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.Product, error)
 	GetBySKU(ctx context.Context, tenantID uuid.UUID, sku string) (*domain.Product, error)
 	GetByBarcode(ctx context.Context, tenantID uuid.UUID, barcode string) (*domain.Product, error)
 	GetByCategory(ctx context.Context, categoryID uuid.UUID, limit, offset int) ([]*domain.Product, error)
+	// GetByHSCode retrieves products whose HS code starts with the given chapter, heading or code
+	GetByHSCode(ctx context.Context, tenantID uuid.UUID, prefix string, limit, offset int) ([]*domain.Product, error)
+	SummarizeByHSChapter(ctx context.Context, tenantID uuid.UUID) ([]*domain.HSChapterSummary, error)
 	GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Product, error)
+	// StreamByTenantID passes every product for a tenant to fn with its category's code, as it is read
+	StreamByTenantID(ctx context.Context, tenantID uuid.UUID, fn func(product *domain.Product, categoryCode string) error) error
+	// List retrieves a page of products, newest first, with the total; a tags filter keeps products carrying them
+	List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) ([]*domain.Product, int64, error)
 	Update(ctx context.Context, product *domain.Product) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
 	Search(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) ([]*domain.Product, error)
+	// ListByTags retrieves products carrying the filter's tags by name; a non-empty query also matches the search fields
+	ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*domain.Product, error)
+	// ListForPriceUpdate retrieves the products a bulk price update filter matches, at most limit
+	ListForPriceUpdate(ctx context.Context, tenantID uuid.UUID, filter domain.BulkPriceFilter, limit int) ([]*domain.Product, error)
 	Count(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	// CountByIDs returns how many of the given products belong to the tenant
+	CountByIDs(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (int, error)
 	GetNextProductNumber(ctx context.Context, tenantID uuid.UUID) (int64, error)
 }
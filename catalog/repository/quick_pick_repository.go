@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// QuickPickRepository defines the interface for per-user favorite and recent products
+type QuickPickRepository interface {
+	// RecordView bumps the view count and timestamp for a product
+	RecordView(ctx context.Context, tenantID, userID, productID uuid.UUID) error
+	// SetFavorite pins or unpins a product; position orders pinned products
+	SetFavorite(ctx context.Context, tenantID, userID, productID uuid.UUID, favorite bool, position int) error
+	// List returns favorites first, then recently viewed products
+	List(ctx context.Context, tenantID, userID uuid.UUID, limit int) ([]*domain.ProductQuickPick, error)
+	// TrimRecent keeps only the most recent non-favorite views for a user
+	TrimRecent(ctx context.Context, tenantID, userID uuid.UUID, keep int) error
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// ReservationRepository defines the interface for stock reservation data access
+type ReservationRepository interface {
+	// CreateIfAvailable saves the reservations unless, with what is already held, they
+	// would reserve more of a product than onHand; it then returns ErrInsufficientStock.
+	// Concurrent reservations of the same product are serialized.
+	CreateIfAvailable(ctx context.Context, tenantID uuid.UUID, reservations []*domain.StockReservation, onHand map[uuid.UUID]float64) error
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.StockReservation, error)
+	List(ctx context.Context, tenantID uuid.UUID, filter domain.ReservationFilter) ([]*domain.StockReservation, error)
+	// Reserved sums the quantities still held per product
+	Reserved(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]float64, error)
+	// Close moves an active reservation to status, returning ErrReservationClosed when it is not active
+	Close(ctx context.Context, tenantID, id uuid.UUID, status domain.ReservationStatus, reason *string) (*domain.StockReservation, error)
+	// CloseByReference moves a document's active reservations to status and returns how many it closed
+	CloseByReference(ctx context.Context, tenantID uuid.UUID, referenceType domain.ReservationReference, referenceID uuid.UUID, status domain.ReservationStatus, reason *string) (int64, error)
+	// ExpireDue marks every tenant's active reservations past their expiry as expired
+	ExpireDue(ctx context.Context, now time.Time) (int64, error)
+	// Summarize returns held quantities per product with the product's code and name
+	Summarize(ctx context.Context, tenantID uuid.UUID) ([]*domain.ReservationSummary, error)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// TaxRepository defines the interface for tax registry data access
+type TaxRepository interface {
+	// Tax rates
+	CreateRate(ctx context.Context, rate *domain.TaxRate) error
+	GetRateByID(ctx context.Context, id uuid.UUID) (*domain.TaxRate, error)
+	// ListRates returns system rates plus the tenant's custom rates, with versions
+	ListRates(ctx context.Context, tenantID uuid.UUID) ([]*domain.TaxRate, error)
+	UpdateRate(ctx context.Context, rate *domain.TaxRate) error
+	AddRateVersion(ctx context.Context, version *domain.TaxRateVersion) error
+
+	// Tax groups
+	CreateGroup(ctx context.Context, group *domain.TaxGroup) error
+	GetGroupByID(ctx context.Context, id uuid.UUID) (*domain.TaxGroup, error)
+	ListGroups(ctx context.Context, tenantID uuid.UUID) ([]*domain.TaxGroup, error)
+	// UpdateGroup updates the group and replaces its components
+	UpdateGroup(ctx context.Context, group *domain.TaxGroup) error
+	DeleteGroup(ctx context.Context, id uuid.UUID) error
+	CountGroupAssignments(ctx context.Context, id uuid.UUID) (int64, error)
+
+	// GetEffectiveComponents resolves a group's components to the rates in effect on a date
+	GetEffectiveComponents(ctx context.Context, groupID uuid.UUID, date time.Time) ([]*domain.EffectiveTaxComponent, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// UnitRepository defines the interface for unit of measure data access
+type UnitRepository interface {
+	Create(ctx context.Context, unit *domain.UnitOfMeasure) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.UnitOfMeasure, error)
+	// GetByCode resolves a code against the tenant's custom units and the system units
+	GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.UnitOfMeasure, error)
+	// List returns system units plus the tenant's custom units
+	List(ctx context.Context, tenantID uuid.UUID) ([]*domain.UnitOfMeasure, error)
+	Update(ctx context.Context, unit *domain.UnitOfMeasure) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// CountProductsUsing returns how many of the tenant's products use the unit code
+	CountProductsUsing(ctx context.Context, tenantID uuid.UUID, code string) (int64, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/google/uuid"
+)
+
+// VariantRepository defines the interface for the links between products and their variants
+type VariantRepository interface {
+	// Create returns ErrInvalidVariantMatrix if the parent already has a variant with the same attribute values
+	Create(ctx context.Context, variant *domain.ProductVariant) error
+	// ListByParent returns a product's variants in the order they were generated, without their products
+	ListByParent(ctx context.Context, tenantID, parentID uuid.UUID) ([]*domain.ProductVariant, error)
+	// GetByProduct returns nil if the product is not a variant
+	GetByProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.ProductVariant, error)
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/notification"
+	notificationDomain "github.com/aceextension/notification/domain"
+	notificationService "github.com/aceextension/notification/service"
+	"github.com/google/uuid"
+)
+
+// alertRuleService implements AlertRuleService
+type alertRuleService struct {
+	repo         repository.AlertRuleRepository
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	reservations ReservationService
+}
+
+// NewAlertRuleService creates a new alert rule service; stock levels are read through reservations
+func NewAlertRuleService(repo repository.AlertRuleRepository, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, reservations ReservationService) AlertRuleService {
+	return &alertRuleService{
+		repo:         repo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		reservations: reservations,
+	}
+}
+
+// CreateRule validates and saves an alert rule
+func (s *alertRuleService) CreateRule(ctx context.Context, rule *domain.AlertRule) error {
+	if err := s.checkRule(ctx, rule); err != nil {
+		return err
+	}
+	return s.repo.CreateRule(ctx, rule)
+}
+
+// GetRule retrieves a tenant's alert rule
+func (s *alertRuleService) GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.AlertRule, error) {
+	return s.repo.GetRule(ctx, tenantID, id)
+}
+
+// ListRules lists the tenant's alert rules
+func (s *alertRuleService) ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.AlertRule, error) {
+	return s.repo.ListRules(ctx, tenantID, false)
+}
+
+// UpdateRule validates and saves changes to an alert rule
+func (s *alertRuleService) UpdateRule(ctx context.Context, rule *domain.AlertRule) error {
+	if err := s.checkRule(ctx, rule); err != nil {
+		return err
+	}
+	rule.UpdatedAt = time.Now()
+	return s.repo.UpdateRule(ctx, rule)
+}
+
+// DeleteRule deletes an alert rule and its alerts
+func (s *alertRuleService) DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.DeleteRule(ctx, tenantID, id)
+}
+
+// ListAlerts lists stock alerts, newest first
+func (s *alertRuleService) ListAlerts(ctx context.Context, tenantID uuid.UUID, filter domain.AlertFilter) ([]*domain.StockAlert, error) {
+	return s.repo.ListAlerts(ctx, tenantID, filter)
+}
+
+// checkRule validates a rule and that its product or category is the tenant's
+func (s *alertRuleService) checkRule(ctx context.Context, rule *domain.AlertRule) error {
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidAlertRule, err.Error())
+	}
+	if rule.ProductID != nil {
+		if _, err := s.productRepo.GetByID(ctx, rule.TenantID, *rule.ProductID); err != nil {
+			return fmt.Errorf("%w: unknown product", domain.ErrInvalidAlertRule)
+		}
+	}
+	if rule.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, rule.TenantID, *rule.CategoryID); err != nil {
+			return fmt.Errorf("%w: unknown category", domain.ErrInvalidAlertRule)
+		}
+	}
+	return nil
+}
+
+// Evaluate runs each active rule; a failing rule is logged and the rest still run
+func (s *alertRuleService) Evaluate(ctx context.Context, tenantID uuid.UUID) (*domain.AlertRun, error) {
+	rules, err := s.repo.ListRules(ctx, tenantID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &domain.AlertRun{}
+	for _, rule := range rules {
+		if err := s.evaluateRule(ctx, rule, run); err != nil {
+			logger.Log.Error(fmt.Sprintf("Alert rule %s failed for tenant %s: %v", rule.ID, tenantID, err))
+			continue
+		}
+		run.Rules++
+	}
+
+	return run, nil
+}
+
+// evaluateRule compares the rule's products with its open alerts: new trips are
+// raised, worse ones escalated and both notified together; cleared ones resolve
+func (s *alertRuleService) evaluateRule(ctx context.Context, rule *domain.AlertRule, run *domain.AlertRun) error {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	candidates, err := s.repo.Candidates(ctx, rule, today.AddDate(0, 0, int(rule.Threshold)))
+	if err != nil {
+		return err
+	}
+
+	if rule.Condition == domain.AlertLowStock && len(candidates) > 0 {
+		productIDs := make([]uuid.UUID, len(candidates))
+		for i, candidate := range candidates {
+			productIDs[i] = candidate.ProductID
+		}
+		availability, err := s.reservations.Availability(ctx, rule.TenantID, productIDs)
+		if err != nil {
+			return err
+		}
+		for i := range candidates {
+			candidates[i].OnHand = availability[i].OnHand
+		}
+	}
+
+	open, err := s.repo.OpenAlerts(ctx, rule.TenantID, rule.ID)
+	if err != nil {
+		return err
+	}
+
+	var changes repository.AlertChanges
+	for _, candidate := range candidates {
+		severity, detail, tripped := rule.Evaluate(candidate, today)
+		if !tripped {
+			continue
+		}
+
+		alert, wasOpen := open[candidate.ProductID]
+		delete(open, candidate.ProductID)
+		switch {
+		case !wasOpen:
+			changes.Raised = append(changes.Raised, domain.NewStockAlert(rule, candidate, severity, detail))
+		case alert.Escalates(severity):
+			alert.Severity = severity
+			alert.Detail = detail
+			changes.Escalated = append(changes.Escalated, alert)
+		}
+	}
+	for _, alert := range open {
+		changes.Resolved = append(changes.Resolved, alert.ID)
+	}
+
+	if len(changes.Raised)+len(changes.Escalated) > 0 {
+		run.Notified += s.notify(ctx, rule, changes)
+	}
+
+	if err := s.repo.RecordEvaluation(ctx, rule, changes, now); err != nil {
+		return err
+	}
+
+	run.Raised += len(changes.Raised)
+	run.Escalated += len(changes.Escalated)
+	run.Resolved += len(changes.Resolved)
+	return nil
+}
+
+// notify sends the rule's recipients one message listing the raised and escalated
+// products and returns how many were sent. Failures are logged; the alerts are
+// recorded either way so they are not retried daily.
+func (s *alertRuleService) notify(ctx context.Context, rule *domain.AlertRule, changes repository.AlertChanges) int {
+	if notification.Service == nil {
+		return 0
+	}
+
+	content := alertRuleMessage(rule, changes)
+	referenceType := "ALERT_RULE"
+	sent := 0
+	for _, recipient := range rule.Recipients {
+		_, err := notification.Service.Send(ctx, notificationService.SendRequest{
+			TenantID:      rule.TenantID,
+			Channel:       notificationDomain.ChannelType(rule.Channel),
+			Recipient:     recipient,
+			Content:       content,
+			Priority:      notificationDomain.PriorityLow,
+			ReferenceType: &referenceType,
+			ReferenceID:   &rule.ID,
+		})
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Alert rule %s: failed to notify %s: %v", rule.ID, recipient, err))
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		now := time.Now()
+		for _, alert := range append(changes.Raised, changes.Escalated...) {
+			alert.NotifiedAt = &now
+			alert.NotifiedCount++
+		}
+	}
+	return sent
+}
+
+// alertRuleMessage is the notification text of a rule's raised and escalated products
+func alertRuleMessage(rule *domain.AlertRule, changes repository.AlertChanges) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d product(s) need attention\n", rule.Name, len(changes.Raised)+len(changes.Escalated))
+	for _, alert := range changes.Escalated {
+		fmt.Fprintf(&b, "\n[%s] %s %s: %s", strings.ToUpper(string(alert.Severity)), alert.ProductCode, alert.ProductName, alert.Detail)
+	}
+	for _, alert := range changes.Raised {
+		fmt.Fprintf(&b, "\n%s %s: %s", alert.ProductCode, alert.ProductName, alert.Detail)
+	}
+	return b.String()
+}
+
+// RunScheduled evaluates every tenant with active rules, logging and skipping tenants that fail
+func (s *alertRuleService) RunScheduled(ctx context.Context) error {
+	tenants, err := s.repo.ListTenantsWithRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenants {
+		run, err := s.Evaluate(db.WithTenantID(ctx, tenantID), tenantID)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Alert rules failed for tenant %s: %v", tenantID, err))
+			continue
+		}
+		if run.Raised > 0 || run.Escalated > 0 {
+			logger.Log.Info(fmt.Sprintf("Alert rules for tenant %s: %d raised, %d escalated, %d messages",
+				tenantID, run.Raised, run.Escalated, run.Notified))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/aceextension/core/logger"
+	"github.com/google/uuid"
+)
+
+// maxBOMDepth bounds the walk through sub-assemblies when checking for cycles
+const maxBOMDepth = 10
+
+// assemblyService implements AssemblyService
+type assemblyService struct {
+	repo        repository.AssemblyRepository
+	productRepo repository.ProductRepository
+	pricing     PricingService
+	stock       AssemblyStock
+	journal     AssemblyJournal
+}
+
+// NewAssemblyService creates a new assembly service
+func NewAssemblyService(repo repository.AssemblyRepository, productRepo repository.ProductRepository, pricing PricingService) AssemblyService {
+	return &assemblyService{
+		repo:        repo,
+		productRepo: productRepo,
+		pricing:     pricing,
+	}
+}
+
+// SetStockLedger sets where assembly stock movements are recorded
+func (s *assemblyService) SetStockLedger(stock AssemblyStock) {
+	s.stock = stock
+}
+
+// SetJournal sets the ledger that work-in-progress postings go to
+func (s *assemblyService) SetJournal(journal AssemblyJournal) {
+	s.journal = journal
+}
+
+// SaveBOM validates and replaces a product's bill of materials
+func (s *assemblyService) SaveBOM(ctx context.Context, bom *domain.BillOfMaterials) error {
+	if err := bom.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidBOM, err.Error())
+	}
+
+	if _, err := s.tenantProduct(ctx, bom.TenantID, bom.ProductID); err != nil {
+		return err
+	}
+	for _, component := range bom.Components {
+		if _, err := s.tenantProduct(ctx, bom.TenantID, component.ComponentID); err != nil {
+			return fmt.Errorf("%w: unknown component %s", domain.ErrInvalidBOM, component.ComponentID)
+		}
+		if err := s.checkCycle(ctx, bom.TenantID, bom.ProductID, component.ComponentID, 1); err != nil {
+			return err
+		}
+	}
+
+	bom.UpdatedAt = time.Now()
+	return s.repo.SaveBOM(ctx, bom)
+}
+
+// checkCycle rejects a component whose own BOM (at any depth) uses the product
+func (s *assemblyService) checkCycle(ctx context.Context, tenantID, productID, componentID uuid.UUID, depth int) error {
+	if depth > maxBOMDepth {
+		return fmt.Errorf("%w: sub-assemblies nested deeper than %d levels", domain.ErrInvalidBOM, maxBOMDepth)
+	}
+
+	sub, err := s.repo.GetBOM(ctx, tenantID, componentID)
+	if errors.Is(err, domain.ErrBOMNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, component := range sub.Components {
+		if component.ComponentID == productID {
+			return fmt.Errorf("%w: component %s is made from this product", domain.ErrInvalidBOM, componentID)
+		}
+		if err := s.checkCycle(ctx, tenantID, productID, component.ComponentID, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBOM retrieves a product's bill of materials
+func (s *assemblyService) GetBOM(ctx context.Context, tenantID, productID uuid.UUID) (*domain.BillOfMaterials, error) {
+	return s.repo.GetBOM(ctx, tenantID, productID)
+}
+
+// DeleteBOM removes a product's bill of materials; existing orders keep their components
+func (s *assemblyService) DeleteBOM(ctx context.Context, tenantID, productID uuid.UUID) error {
+	return s.repo.DeleteBOM(ctx, tenantID, productID)
+}
+
+// PlanOrder explodes the BOM for quantity units and costs each component at its cost price
+func (s *assemblyService) PlanOrder(ctx context.Context, tenantID, productID uuid.UUID, quantity float64) (*domain.AssemblyOrder, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+
+	bom, err := s.repo.GetBOM(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	unitCosts := make(map[uuid.UUID]float64, len(bom.Components))
+	for _, component := range bom.Components {
+		product, err := s.tenantProduct(ctx, tenantID, component.ComponentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cost component %s: %w", component.ComponentID, err)
+		}
+		unitCosts[component.ComponentID] = product.CostPrice
+	}
+
+	return domain.NewAssemblyOrder(tenantID, bom, quantity, unitCosts), nil
+}
+
+// CreateOrder plans and saves a draft assembly order
+func (s *assemblyService) CreateOrder(ctx context.Context, tenantID, productID uuid.UUID, quantity float64, note *string, createdBy *uuid.UUID) (*domain.AssemblyOrder, error) {
+	order, err := s.PlanOrder(ctx, tenantID, productID, quantity)
+	if err != nil {
+		return nil, err
+	}
+	order.Note = note
+	order.CreatedBy = createdBy
+
+	if err := s.repo.CreateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// GetOrder retrieves an assembly order with its components
+func (s *assemblyService) GetOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.AssemblyOrder, error) {
+	return s.repo.GetOrder(ctx, tenantID, id)
+}
+
+// ListOrders returns assembly orders, optionally only those with a given status
+func (s *assemblyService) ListOrders(ctx context.Context, tenantID uuid.UUID, status domain.AssemblyOrderStatus, limit, offset int) ([]*domain.AssemblyOrder, error) {
+	return s.repo.ListOrders(ctx, tenantID, status, limit, offset)
+}
+
+// StartOrder issues the components to production
+func (s *assemblyService) StartOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.AssemblyOrder, error) {
+	order, err := s.repo.GetOrder(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := order.Start(); err != nil {
+		return nil, err
+	}
+
+	if s.stock != nil {
+		if err := s.stock.ConsumeComponents(ctx, order); err != nil {
+			return nil, fmt.Errorf("failed to consume components: %w", err)
+		}
+	}
+
+	if err := s.repo.UpdateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	s.post(ctx, order, domain.AssemblyStageStart)
+	return order, nil
+}
+
+// CompleteOrder receives the finished goods and rolls their cost into the product
+func (s *assemblyService) CompleteOrder(ctx context.Context, tenantID, id uuid.UUID, producedQty, overheadCost float64) (*domain.AssemblyOrder, error) {
+	order, err := s.repo.GetOrder(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := order.Complete(producedQty, overheadCost); err != nil {
+		return nil, err
+	}
+
+	if s.stock != nil {
+		if err := s.stock.ReceiveFinished(ctx, order); err != nil {
+			return nil, fmt.Errorf("failed to receive finished goods: %w", err)
+		}
+	}
+
+	if err := s.repo.UpdateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	s.post(ctx, order, domain.AssemblyStageComplete)
+
+	if err := s.updateCost(ctx, order); err != nil {
+		return nil, fmt.Errorf("order completed but cost roll-up failed: %w", err)
+	}
+
+	return order, nil
+}
+
+// updateCost sets the finished product's cost price to the order's unit cost and reprices it
+func (s *assemblyService) updateCost(ctx context.Context, order *domain.AssemblyOrder) error {
+	product, err := s.tenantProduct(ctx, order.TenantID, order.ProductID)
+	if err != nil {
+		return err
+	}
+	if product.CostPrice == order.UnitCost {
+		return nil
+	}
+
+	product.CostPrice = order.UnitCost
+	product.UpdatedAt = time.Now()
+	if err := s.productRepo.Update(ctx, product); err != nil {
+		return err
+	}
+
+	_, err = s.pricing.Reprice(ctx, product, domain.PriceChangeSourceCost)
+	return err
+}
+
+// CancelOrder abandons a draft order
+func (s *assemblyService) CancelOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.AssemblyOrder, error) {
+	order, err := s.repo.GetOrder(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := order.Cancel(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// post books an order stage in the ledger. The order is already saved, so a ledger
+// failure is logged and leaves the stage unlinked for follow-up.
+func (s *assemblyService) post(ctx context.Context, order *domain.AssemblyOrder, stage domain.AssemblyStage) {
+	if s.journal == nil || order.TotalCost == 0 {
+		return
+	}
+
+	entryID, err := s.journal.PostAssembly(ctx, order, stage)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to post assembly order %s (%s) to ledger: %v", order.OrderNumber, stage, err))
+		return
+	}
+
+	if stage == domain.AssemblyStageStart {
+		order.WIPJournalEntryID = &entryID
+	} else {
+		order.FinishedJournalEntryID = &entryID
+	}
+	if err := s.repo.SetJournalEntry(ctx, order.ID, stage, entryID); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to link assembly order %s to journal %s: %v", order.OrderNumber, entryID, err))
+	}
+}
+
+// tenantProduct loads a product and checks it belongs to the tenant
+func (s *assemblyService) tenantProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.Product, error) {
+	return s.productRepo.GetByID(ctx, tenantID, productID)
+}
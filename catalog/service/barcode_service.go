@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/google/uuid"
+)
+
+// defaultScaleDecimals is the quantity precision used when a product's unit is not in the registry
+const defaultScaleDecimals = 3
+
+// barcodeService implements BarcodeService
+type barcodeService struct {
+	repo        repository.BarcodeRuleRepository
+	productRepo repository.ProductRepository
+	packRepo    repository.ProductBarcodeRepository
+	unitService UnitService
+}
+
+// NewBarcodeService creates a new barcode service
+func NewBarcodeService(repo repository.BarcodeRuleRepository, productRepo repository.ProductRepository, packRepo repository.ProductBarcodeRepository, unitService UnitService) BarcodeService {
+	return &barcodeService{
+		repo:        repo,
+		productRepo: productRepo,
+		packRepo:    packRepo,
+		unitService: unitService,
+	}
+}
+
+// CreateRule creates a scale barcode rule
+func (s *barcodeService) CreateRule(ctx context.Context, rule *domain.BarcodeRule) error {
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidBarcodeRule, err.Error())
+	}
+	if err := s.checkPrefixFree(ctx, rule); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, rule)
+}
+
+// GetRule retrieves a tenant's barcode rule
+func (s *barcodeService) GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.BarcodeRule, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// ListRules returns the tenant's barcode rules
+func (s *barcodeService) ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.BarcodeRule, error) {
+	return s.repo.List(ctx, tenantID)
+}
+
+// UpdateRule updates a barcode rule's layout and active flag
+func (s *barcodeService) UpdateRule(ctx context.Context, rule *domain.BarcodeRule) error {
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidBarcodeRule, err.Error())
+	}
+	if err := s.checkPrefixFree(ctx, rule); err != nil {
+		return err
+	}
+
+	rule.UpdatedAt = time.Now()
+	return s.repo.Update(ctx, rule)
+}
+
+// checkPrefixFree rejects a prefix already used by another of the tenant's rules
+func (s *barcodeService) checkPrefixFree(ctx context.Context, rule *domain.BarcodeRule) error {
+	rules, err := s.repo.List(ctx, rule.TenantID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range rules {
+		if existing.ID != rule.ID && existing.Prefix == rule.Prefix {
+			return fmt.Errorf("%w: prefix %s is already used by %s", domain.ErrInvalidBarcodeRule, rule.Prefix, existing.Name)
+		}
+	}
+	return nil
+}
+
+// DeleteRule deletes a barcode rule
+func (s *barcodeService) DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// ListProductBarcodes returns a product's pack barcodes
+func (s *barcodeService) ListProductBarcodes(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.ProductBarcode, error) {
+	if _, err := s.tenantProduct(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+	return s.packRepo.ListByProduct(ctx, tenantID, productID)
+}
+
+// SaveProductBarcodes replaces a product's pack barcodes. Pack units are normalized
+// to registry codes and pack quantities checked against the product's own unit; no
+// barcode may belong to another of the tenant's products.
+func (s *barcodeService) SaveProductBarcodes(ctx context.Context, tenantID, productID uuid.UUID, barcodes []domain.ProductBarcode) error {
+	product, err := s.tenantProduct(ctx, tenantID, productID)
+	if err != nil {
+		return err
+	}
+
+	codes := make([]string, len(barcodes))
+	for i := range barcodes {
+		unit, err := s.unitService.Resolve(ctx, tenantID, barcodes[i].Unit)
+		if err != nil {
+			return err
+		}
+		barcodes[i].Unit = unit.Code
+		if err := s.unitService.ValidateQuantity(ctx, tenantID, product.Unit, barcodes[i].Quantity); err != nil {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidProductBarcode, err.Error())
+		}
+		codes[i] = barcodes[i].Barcode
+	}
+	if err := domain.ValidateProductBarcodes(product.Barcode, barcodes); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidProductBarcode, err.Error())
+	}
+
+	taken, err := s.packRepo.FindTaken(ctx, tenantID, productID, codes)
+	if err != nil {
+		return err
+	}
+	for _, code := range codes {
+		if _, ok := taken[code]; ok {
+			return fmt.Errorf("%w: %s", domain.ErrBarcodeInUse, code)
+		}
+	}
+
+	return s.packRepo.Replace(ctx, tenantID, productID, barcodes)
+}
+
+// tenantProduct loads a product and checks it belongs to the tenant
+func (s *barcodeService) tenantProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.Product, error) {
+	return s.productRepo.GetByID(ctx, tenantID, productID)
+}
+
+// Lookup resolves a scanned barcode to a product and the quantity to ring up.
+// A product's own barcode rings up one unit and a pack barcode the pack's quantity;
+// otherwise the tenant's scale rules are tried.
+func (s *barcodeService) Lookup(ctx context.Context, tenantID uuid.UUID, barcode string) (*domain.BarcodeLookup, error) {
+	barcode = strings.TrimSpace(barcode)
+
+	if product, err := s.productRepo.GetByBarcode(ctx, tenantID, barcode); err == nil {
+		return &domain.BarcodeLookup{Product: product, Quantity: 1, Amount: product.SellingPrice}, nil
+	}
+
+	if pack, err := s.packRepo.GetByBarcode(ctx, tenantID, barcode); err == nil {
+		product, err := s.tenantProduct(ctx, tenantID, pack.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.BarcodeLookup{
+			Product:  product,
+			Quantity: pack.Quantity,
+			Amount:   math.Round(pack.Quantity*product.SellingPrice*100) / 100,
+			Pack:     pack,
+		}, nil
+	}
+
+	rules, err := s.repo.ListActive(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if !rule.Matches(barcode) {
+			continue
+		}
+
+		scale, err := rule.Parse(barcode)
+		if err != nil {
+			return nil, err
+		}
+
+		product, err := s.resolveItemCode(ctx, tenantID, scale.ItemCode)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.scaleLookup(ctx, product, scale)
+	}
+
+	return nil, domain.ErrProductNotFound
+}
+
+// resolveItemCode finds the product for a scale item code by SKU, then product code.
+// Scales often zero-pad item codes, so the unpadded code is tried as well.
+func (s *barcodeService) resolveItemCode(ctx context.Context, tenantID uuid.UUID, itemCode string) (*domain.Product, error) {
+	codes := []string{itemCode}
+	if trimmed := strings.TrimLeft(itemCode, "0"); trimmed != "" && trimmed != itemCode {
+		codes = append(codes, trimmed)
+	}
+
+	for _, code := range codes {
+		if product, err := s.productRepo.GetBySKU(ctx, tenantID, code); err == nil {
+			return product, nil
+		}
+		if product, err := s.productRepo.GetByCode(ctx, tenantID, code); err == nil {
+			return product, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: scale item %s", domain.ErrProductNotFound, itemCode)
+}
+
+// scaleLookup computes quantity and line amount from the value embedded in the barcode
+func (s *barcodeService) scaleLookup(ctx context.Context, product *domain.Product, scale *domain.ScaleBarcode) (*domain.BarcodeLookup, error) {
+	lookup := &domain.BarcodeLookup{Product: product, Scale: scale}
+
+	switch scale.ValueType {
+	case domain.ScaleValueWeight:
+		lookup.Quantity = s.roundQuantity(ctx, product, scale.Value)
+		lookup.Amount = math.Round(lookup.Quantity*product.SellingPrice*100) / 100
+	case domain.ScaleValuePrice:
+		if product.SellingPrice <= 0 {
+			return nil, fmt.Errorf("product %s has no selling price to derive quantity from", product.ProductCode)
+		}
+		lookup.Amount = scale.Value
+		lookup.Quantity = s.roundQuantity(ctx, product, scale.Value/product.SellingPrice)
+	}
+
+	return lookup, nil
+}
+
+// roundQuantity rounds to the product unit's precision
+func (s *barcodeService) roundQuantity(ctx context.Context, product *domain.Product, quantity float64) float64 {
+	unit, err := s.unitService.Resolve(ctx, product.TenantID, product.Unit)
+	if err != nil {
+		factor := math.Pow(10, defaultScaleDecimals)
+		return math.Round(quantity*factor) / factor
+	}
+	return unit.RoundQuantity(quantity)
+}
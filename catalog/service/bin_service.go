@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/google/uuid"
+)
+
+// binService implements BinService
+type binService struct {
+	repo        repository.BinRepository
+	productRepo repository.ProductRepository
+	unitService UnitService
+	warehouses  Warehouses
+}
+
+// NewBinService creates a new bin service
+func NewBinService(repo repository.BinRepository, productRepo repository.ProductRepository, unitService UnitService) BinService {
+	return &binService{
+		repo:        repo,
+		productRepo: productRepo,
+		unitService: unitService,
+	}
+}
+
+// SetWarehouses sets where bins are placed
+func (s *binService) SetWarehouses(warehouses Warehouses) {
+	s.warehouses = warehouses
+}
+
+// CreateBin creates a bin location
+func (s *binService) CreateBin(ctx context.Context, bin *domain.Bin) error {
+	if err := bin.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidBin, err.Error())
+	}
+	if err := s.placeBin(ctx, bin); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, bin)
+}
+
+// GetBin retrieves a tenant's bin location
+func (s *binService) GetBin(ctx context.Context, tenantID, id uuid.UUID) (*domain.Bin, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// ListBins returns the tenant's bin locations
+func (s *binService) ListBins(ctx context.Context, tenantID uuid.UUID) ([]*domain.Bin, error) {
+	return s.repo.List(ctx, tenantID)
+}
+
+// UpdateBin updates a bin's code, zone, pick sequence and active flag
+func (s *binService) UpdateBin(ctx context.Context, bin *domain.Bin) error {
+	bin.Code = domain.NormalizeBinCode(bin.Code)
+	if err := bin.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidBin, err.Error())
+	}
+
+	if err := s.placeBin(ctx, bin); err != nil {
+		return err
+	}
+
+	bin.UpdatedAt = time.Now()
+	return s.repo.Update(ctx, bin)
+}
+
+// placeBin checks the bin's warehouse, putting a bin without one in the default warehouse
+func (s *binService) placeBin(ctx context.Context, bin *domain.Bin) error {
+	if s.warehouses == nil {
+		return nil
+	}
+	warehouseID, err := s.warehouses.ResolveWarehouse(ctx, bin.TenantID, bin.WarehouseID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidBin, err.Error())
+	}
+	bin.WarehouseID = &warehouseID
+	return nil
+}
+
+// DeleteBin deletes an empty bin location
+func (s *binService) DeleteBin(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// ListBinStock returns the products held in a bin
+func (s *binService) ListBinStock(ctx context.Context, tenantID, binID uuid.UUID) ([]domain.BinStock, error) {
+	if _, err := s.repo.GetByID(ctx, tenantID, binID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListStockByBin(ctx, tenantID, binID)
+}
+
+// ListProductBins returns the bins a product is shelved in
+func (s *binService) ListProductBins(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.BinStock, error) {
+	if _, err := s.tenantProduct(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListStockByProducts(ctx, tenantID, []uuid.UUID{productID}, false)
+}
+
+// SetBinQuantity sets a product's quantity in an active bin; zero removes it from the bin
+func (s *binService) SetBinQuantity(ctx context.Context, movement *domain.BinMovement, quantity float64) error {
+	if quantity < 0 {
+		return fmt.Errorf("%w: quantity cannot be negative", domain.ErrInvalidBin)
+	}
+	if err := s.checkMovement(ctx, movement, quantity); err != nil {
+		return err
+	}
+	return s.repo.SetStock(ctx, movement, quantity)
+}
+
+// Transfer moves a product from one bin to another active bin
+func (s *binService) Transfer(ctx context.Context, movement *domain.BinMovement) error {
+	if err := movement.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidBin, err.Error())
+	}
+	if _, err := s.repo.GetByID(ctx, movement.TenantID, *movement.FromBinID); err != nil {
+		return err
+	}
+	if err := s.checkMovement(ctx, movement, movement.Quantity); err != nil {
+		return err
+	}
+	return s.repo.Transfer(ctx, movement)
+}
+
+// ListMovements returns bin counts and transfers, of one product when productID is set
+func (s *binService) ListMovements(ctx context.Context, tenantID uuid.UUID, productID *uuid.UUID, limit, offset int) ([]*domain.BinMovement, error) {
+	return s.repo.ListMovements(ctx, tenantID, productID, limit, offset)
+}
+
+// PickList allocates the items to bins in pick order
+func (s *binService) PickList(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID, items []domain.PickItem) (*domain.PickList, error) {
+	productIDs := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: pick quantity must be positive", domain.ErrInvalidBin)
+		}
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	held, err := s.repo.ListStockByProducts(ctx, tenantID, productIDs, true)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := make(map[uuid.UUID][]domain.BinStock, len(productIDs))
+	for _, entry := range held {
+		if warehouseID != nil && (entry.WarehouseID == nil || *entry.WarehouseID != *warehouseID) {
+			continue
+		}
+		stock[entry.ProductID] = append(stock[entry.ProductID], entry)
+	}
+	return domain.BuildPickList(items, stock), nil
+}
+
+// checkMovement checks the destination bin is active and the quantity fits the product's unit
+func (s *binService) checkMovement(ctx context.Context, movement *domain.BinMovement, quantity float64) error {
+	bin, err := s.repo.GetByID(ctx, movement.TenantID, *movement.ToBinID)
+	if err != nil {
+		return err
+	}
+	if !bin.IsActive {
+		return fmt.Errorf("%w: bin %s is inactive", domain.ErrInvalidBin, bin.Code)
+	}
+
+	product, err := s.tenantProduct(ctx, movement.TenantID, movement.ProductID)
+	if err != nil {
+		return err
+	}
+	if err := s.unitService.ValidateQuantity(ctx, movement.TenantID, product.Unit, quantity); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidBin, err.Error())
+	}
+	return nil
+}
+
+// tenantProduct loads a product and checks it belongs to the tenant
+func (s *binService) tenantProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.Product, error) {
+	return s.productRepo.GetByID(ctx, tenantID, productID)
+}
@@ -6,24 +6,36 @@ import (
 
 	"github.com/aceextension/catalog/domain"
 	"github.com/aceextension/catalog/repository"
+	"github.com/aceextension/core/db"
 	"github.com/aceextension/fiscal"
 	"github.com/google/uuid"
 )
 
 // categoryService implements CategoryService
 type categoryService struct {
-	repo repository.CategoryRepository
+	repo       repository.CategoryRepository
+	taxService TaxService
 }
 
 // NewCategoryService creates a new category service
-func NewCategoryService(repo repository.CategoryRepository) CategoryService {
+func NewCategoryService(repo repository.CategoryRepository, taxService TaxService) CategoryService {
 	return &categoryService{
-		repo: repo,
+		repo:       repo,
+		taxService: taxService,
 	}
 }
 
 // Create creates a new category
 func (s *categoryService) Create(ctx context.Context, category *domain.Category) error {
+	if err := s.checkParent(ctx, category); err != nil {
+		return err
+	}
+	if category.TaxGroupID != nil {
+		if err := s.taxService.ValidateGroup(ctx, category.TenantID, *category.TaxGroupID); err != nil {
+			return err
+		}
+	}
+
 	// Generate category code
 	nextNum, err := s.repo.GetNextCategoryNumber(ctx, category.TenantID)
 	if err != nil {
@@ -33,7 +45,7 @@ func (s *categoryService) Create(ctx context.Context, category *domain.Category)
 	// Get fiscal year for code generation
 	fiscalYear := fiscal.GetActiveFiscalYear(ctx, category.TenantID)
 	if fiscalYear != nil {
-		category.CategoryCode = fmt.Sprintf("CAT-%s-%04d", fiscalYear.Code, nextNum)
+		category.CategoryCode = fmt.Sprintf("CAT-%s-%04d", fiscalYear.Code(), nextNum)
 	} else {
 		category.CategoryCode = fmt.Sprintf("CAT-%04d", nextNum)
 	}
@@ -52,9 +64,9 @@ func (s *categoryService) Create(ctx context.Context, category *domain.Category)
 	return nil
 }
 
-// GetByID retrieves a category by ID
-func (s *categoryService) GetByID(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
-	return s.repo.GetByID(ctx, id)
+// GetByID retrieves a tenant's category by ID
+func (s *categoryService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Category, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
 }
 
 // GetByCode retrieves a category by code
@@ -62,9 +74,15 @@ func (s *categoryService) GetByCode(ctx context.Context, tenantID uuid.UUID, cod
 	return s.repo.GetByCode(ctx, tenantID, code)
 }
 
-// GetByTenantID retrieves all categories for a tenant
-func (s *categoryService) GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Category, error) {
-	return s.repo.GetByTenantID(ctx, tenantID, limit, offset)
+// List returns a page of a tenant's categories in display order
+func (s *categoryService) List(ctx context.Context, tenantID uuid.UUID, page db.PageRequest) (db.Page[*domain.Category], error) {
+	categories, total, err := s.repo.List(ctx, tenantID, page)
+	if err != nil {
+		return db.Page[*domain.Category]{}, err
+	}
+	return db.NewPage(categories, total, page, func(c *domain.Category) db.Cursor {
+		return db.NewCursor(c.SortOrder, c.Name, c.ID)
+	}), nil
 }
 
 // GetRootCategories retrieves root categories
@@ -79,12 +97,30 @@ func (s *categoryService) GetChildren(ctx context.Context, parentID uuid.UUID) (
 
 // Update updates a category
 func (s *categoryService) Update(ctx context.Context, category *domain.Category) error {
+	if err := s.checkParent(ctx, category); err != nil {
+		return err
+	}
+	if category.TaxGroupID != nil {
+		if err := s.taxService.ValidateGroup(ctx, category.TenantID, *category.TaxGroupID); err != nil {
+			return err
+		}
+	}
+
 	return s.repo.Update(ctx, category)
 }
 
-// Delete deletes a category
-func (s *categoryService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+// Delete deletes a tenant's category
+func (s *categoryService) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// checkParent rejects a parent category of another tenant
+func (s *categoryService) checkParent(ctx context.Context, category *domain.Category) error {
+	if category.ParentID == nil {
+		return nil
+	}
+	_, err := s.repo.GetByID(ctx, category.TenantID, *category.ParentID)
+	return err
 }
 
 // Search searches categories
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+)
+
+// maxReorderProducts is how many of the fastest sellers reorder suggestions consider
+const maxReorderProducts = 5000
+
+// demandService implements DemandService
+type demandService struct {
+	repo         repository.DemandRepository
+	productRepo  repository.ProductRepository
+	reservations ReservationService
+
+	mu      sync.RWMutex
+	sources map[string]domain.DemandSource
+}
+
+// NewDemandService creates a new demand service. Statistics stay empty until a
+// module registers a source of sold quantities.
+func NewDemandService(repo repository.DemandRepository, productRepo repository.ProductRepository, reservations ReservationService) DemandService {
+	return &demandService{
+		repo:         repo,
+		productRepo:  productRepo,
+		reservations: reservations,
+		sources:      make(map[string]domain.DemandSource),
+	}
+}
+
+// RegisterSource makes a source count towards demand, replacing one of the same name
+func (s *demandService) RegisterSource(source domain.DemandSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[source.Name] = source
+}
+
+// Recompute reads the last DemandHistoryDays of sales, up to yesterday, and
+// replaces the tenant's statistics
+func (s *demandService) Recompute(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	s.mu.RLock()
+	sources := make([]domain.DemandSource, 0, len(s.sources))
+	for _, source := range s.sources {
+		sources = append(sources, source)
+	}
+	s.mu.RUnlock()
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -domain.DemandHistoryDays)
+
+	// monthDays[i] counts the days of each BS month in the window before day i
+	days := make([]time.Time, domain.DemandHistoryDays)
+	months := make([]int, domain.DemandHistoryDays)
+	monthDays := make([][12]int, domain.DemandHistoryDays+1)
+	for i := range days {
+		days[i] = from.AddDate(0, 0, i)
+		months[i] = utils.ADToBS(days[i]).Month
+		monthDays[i+1] = monthDays[i]
+		monthDays[i+1][months[i]-1]++
+	}
+
+	totals, err := s.repo.Totals(ctx, sources, tenantID, from, to, days, months)
+	if err != nil {
+		return 0, err
+	}
+
+	demands := make([]*domain.ProductDemand, len(totals))
+	for i, t := range totals {
+		start := max(int(t.FirstSoldOn.Sub(from).Hours()/24), 0)
+		historyDays := domain.DemandHistoryDays - start
+		recentStart := domain.DemandHistoryDays - min(90, historyDays)
+		demands[i] = domain.NewProductDemand(tenantID, t, historyDays,
+			countBetween(monthDays, start), countBetween(monthDays, recentStart))
+	}
+
+	if err := s.repo.Replace(ctx, tenantID, demands); err != nil {
+		return 0, err
+	}
+	return len(demands), nil
+}
+
+// countBetween counts the days of each BS month from start to the end of the window
+func countBetween(monthDays [][12]int, start int) [12]int {
+	end := monthDays[len(monthDays)-1]
+	var counts [12]int
+	for m := range counts {
+		counts[m] = end[m] - monthDays[start][m]
+	}
+	return counts
+}
+
+// RunScheduled recomputes every tenant, logging and skipping tenants that fail
+func (s *demandService) RunScheduled(ctx context.Context) error {
+	tenants, err := s.repo.ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenants {
+		if _, err := s.Recompute(db.WithTenantID(ctx, tenantID), tenantID); err != nil {
+			logger.Log.Error(fmt.Sprintf("Demand statistics failed for tenant %s: %v", tenantID, err))
+		}
+	}
+
+	return nil
+}
+
+// GetProductDemand returns a product's statistics
+func (s *demandService) GetProductDemand(ctx context.Context, tenantID, productID uuid.UUID) (*domain.ProductDemand, error) {
+	if _, err := s.productRepo.GetByID(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByProduct(ctx, tenantID, productID)
+}
+
+// ListDemand returns statistics of active products, fastest selling first
+func (s *demandService) ListDemand(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.ProductDemand, error) {
+	return s.repo.List(ctx, tenantID, limit, offset)
+}
+
+// Forecast projects a product's sales from today over days
+func (s *demandService) Forecast(ctx context.Context, tenantID, productID uuid.UUID, days int) (*domain.DemandForecast, error) {
+	demand, err := s.GetProductDemand(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Now()
+	return &domain.DemandForecast{
+		Demand:   demand,
+		From:     from,
+		Days:     days,
+		Quantity: demand.Forecast(bsMonths(from, days)),
+	}, nil
+}
+
+// ReorderSuggestions compares each product's forecast over lead and cover days
+// with its available stock
+func (s *demandService) ReorderSuggestions(ctx context.Context, tenantID uuid.UUID, query domain.ReorderQuery) ([]domain.ReorderSuggestion, error) {
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidReorderQuery, err.Error())
+	}
+
+	demands, err := s.repo.List(ctx, tenantID, maxReorderProducts, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	selling := make([]*domain.ProductDemand, 0, len(demands))
+	productIDs := make([]uuid.UUID, 0, len(demands))
+	for _, demand := range demands {
+		if demand.BaseDaily > 0 {
+			selling = append(selling, demand)
+			productIDs = append(productIDs, demand.ProductID)
+		}
+	}
+
+	availability, err := s.reservations.Availability(ctx, tenantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	months := bsMonths(time.Now(), query.LeadDays+query.CoverDays)
+	suggestions := []domain.ReorderSuggestion{}
+	for i, demand := range selling {
+		suggestion := domain.NewReorderSuggestion(demand, demand.Forecast(months), availability[i].Available)
+		if suggestion.SuggestedQty > 0 {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].DaysOfStock < suggestions[j].DaysOfStock
+	})
+	if query.Limit > 0 && len(suggestions) > query.Limit {
+		suggestions = suggestions[:query.Limit]
+	}
+	return suggestions, nil
+}
+
+// bsMonths returns the Bikram Sambat month of each of days days starting at from
+func bsMonths(from time.Time, days int) []int {
+	months := make([]int, days)
+	for i := range months {
+		months[i] = utils.ADToBS(from.AddDate(0, 0, i)).Month
+	}
+	return months
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/google/uuid"
+)
+
+// guardrailService implements GuardrailService
+type guardrailService struct {
+	repo        repository.GuardrailRepository
+	productRepo repository.ProductRepository
+	taxService  TaxService
+}
+
+// NewGuardrailService creates a new guardrail service
+func NewGuardrailService(repo repository.GuardrailRepository, productRepo repository.ProductRepository, taxService TaxService) GuardrailService {
+	return &guardrailService{
+		repo:        repo,
+		productRepo: productRepo,
+		taxService:  taxService,
+	}
+}
+
+// CheckMRP validates that the product's selling price including tax is within its MRP
+func (s *guardrailService) CheckMRP(ctx context.Context, product *domain.Product) error {
+	return s.checkPriceMRP(ctx, product, product.SellingPrice)
+}
+
+func (s *guardrailService) checkPriceMRP(ctx context.Context, product *domain.Product, price float64) error {
+	if product.MRP == nil || *product.MRP <= 0 {
+		return nil
+	}
+
+	breakdown, err := s.taxService.ComputeProductTax(ctx, product, price, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return domain.CheckMRP(breakdown.TotalAmount, product.MRP)
+}
+
+// MaxPriceUnderMRP returns the highest tax-exclusive price whose tax-inclusive amount fits the MRP
+func (s *guardrailService) MaxPriceUnderMRP(ctx context.Context, product *domain.Product) (float64, bool, error) {
+	if product.MRP == nil || *product.MRP <= 0 {
+		return 0, false, nil
+	}
+
+	// Tax is proportional to the amount, so the tax-inclusive factor of 1 scales to any price
+	breakdown, err := s.taxService.ComputeProductTax(ctx, product, 1, time.Now())
+	if err != nil {
+		return 0, false, err
+	}
+	factor := breakdown.TotalAmount
+	if factor <= 0 {
+		factor = 1
+	}
+
+	return math.Floor(*product.MRP/factor*100) / 100, true, nil
+}
+
+// CheckSale validates a sale-time price for a product against MRP and the seller's discount limit.
+// An override approved by a higher role lifts the discount limit once; MRP can never be overridden.
+func (s *guardrailService) CheckSale(ctx context.Context, tenantID, productID uuid.UUID, salePrice float64, role string, overrideID *uuid.UUID) (*domain.SaleCheck, error) {
+	product, err := s.productRepo.GetByID(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown, err := s.taxService.ComputeProductTax(ctx, product, salePrice, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	check := &domain.SaleCheck{
+		ProductID:       product.ID,
+		ListPrice:       product.SellingPrice,
+		SalePrice:       salePrice,
+		PriceInclTax:    breakdown.TotalAmount,
+		MRP:             product.MRP,
+		DiscountPercent: domain.DiscountPercent(product.SellingPrice, salePrice),
+	}
+
+	if err := domain.CheckMRP(check.PriceInclTax, product.MRP); err != nil {
+		return check, err
+	}
+
+	limit, err := s.repo.GetDiscountLimit(ctx, tenantID, role)
+	if err != nil {
+		return nil, err
+	}
+	if limit == nil {
+		return check, nil
+	}
+	check.MaxDiscountPercent = &limit.MaxDiscountPercent
+	if limit.Allows(check.DiscountPercent) {
+		return check, nil
+	}
+
+	if overrideID == nil {
+		return check, fmt.Errorf("%w: %.2f%% > %.2f%%", domain.ErrDiscountLimitExceeded, check.DiscountPercent, limit.MaxDiscountPercent)
+	}
+
+	override, err := s.repo.GetOverride(ctx, tenantID, *overrideID)
+	if err != nil {
+		return check, err
+	}
+	if !override.Covers(product.ID, salePrice, time.Now()) {
+		return check, domain.ErrInvalidDiscountOverride
+	}
+	redeemed, err := s.repo.MarkOverrideUsed(ctx, tenantID, override.ID)
+	if err != nil {
+		return check, err
+	}
+	if !redeemed {
+		return check, domain.ErrInvalidDiscountOverride
+	}
+
+	check.OverrideID = &override.ID
+	return check, nil
+}
+
+// ApproveOverride lets a user whose own limit covers the discount approve it for another seller
+func (s *guardrailService) ApproveOverride(ctx context.Context, tenantID, productID uuid.UUID, salePrice float64, reason string, requestedBy, approvedBy *uuid.UUID, approverRole string) (*domain.DiscountOverride, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, domain.ErrOverrideReasonRequired
+	}
+
+	product, err := s.productRepo.GetByID(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPriceMRP(ctx, product, salePrice); err != nil {
+		return nil, err
+	}
+
+	override := domain.NewDiscountOverride(tenantID, product, salePrice, reason, requestedBy, approvedBy, approverRole)
+
+	limit, err := s.repo.GetDiscountLimit(ctx, tenantID, approverRole)
+	if err != nil {
+		return nil, err
+	}
+	if limit != nil && !limit.Allows(override.DiscountPercent) {
+		return nil, fmt.Errorf("%w: approver limit is %.2f%%", domain.ErrDiscountLimitExceeded, limit.MaxDiscountPercent)
+	}
+
+	if err := s.repo.CreateOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	return override, nil
+}
+
+// ListOverrides returns the tenant's override history, newest first
+func (s *guardrailService) ListOverrides(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.DiscountOverride, error) {
+	return s.repo.ListOverrides(ctx, tenantID, limit, offset)
+}
+
+// SetDiscountLimit sets a role's maximum discount percentage
+func (s *guardrailService) SetDiscountLimit(ctx context.Context, tenantID uuid.UUID, role string, maxDiscountPercent float64) (*domain.DiscountLimit, error) {
+	role = strings.TrimSpace(role)
+	if role == "" {
+		return nil, fmt.Errorf("role is required")
+	}
+	if maxDiscountPercent < 0 || maxDiscountPercent > 100 {
+		return nil, fmt.Errorf("maximum discount must be between 0 and 100 percent")
+	}
+
+	limit := &domain.DiscountLimit{
+		TenantID:           tenantID,
+		Role:               role,
+		MaxDiscountPercent: maxDiscountPercent,
+		UpdatedAt:          time.Now(),
+	}
+	if err := s.repo.SetDiscountLimit(ctx, limit); err != nil {
+		return nil, err
+	}
+
+	return limit, nil
+}
+
+// ListDiscountLimits returns the tenant's per-role discount limits
+func (s *guardrailService) ListDiscountLimits(ctx context.Context, tenantID uuid.UUID) ([]*domain.DiscountLimit, error) {
+	return s.repo.ListDiscountLimits(ctx, tenantID)
+}
+
+// DeleteDiscountLimit removes a role's limit
+func (s *guardrailService) DeleteDiscountLimit(ctx context.Context, tenantID uuid.UUID, role string) error {
+	return s.repo.DeleteDiscountLimit(ctx, tenantID, role)
+}
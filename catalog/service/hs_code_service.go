@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+)
+
+// hsCodeService implements HSCodeService
+type hsCodeService struct {
+	repo repository.HSCodeRepository
+}
+
+// NewHSCodeService creates a new HS code service
+func NewHSCodeService(repo repository.HSCodeRepository) HSCodeService {
+	return &hsCodeService{
+		repo: repo,
+	}
+}
+
+// Search searches the built-in HS code list
+func (s *hsCodeService) Search(ctx context.Context, query string, limit int) ([]*domain.HSCode, error) {
+	query = strings.TrimSpace(query)
+	if isNumericCode(query) {
+		query = strings.NewReplacer(".", "", " ", "", "-", "").Replace(query)
+	}
+	return s.repo.Search(ctx, query, limit)
+}
+
+// Lookup validates a code and returns its built-in entry (nil if not in the list)
+func (s *hsCodeService) Lookup(ctx context.Context, code string) (*domain.HSCode, error) {
+	normalized, err := domain.NormalizeHSCode(code)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByCode(ctx, normalized)
+}
+
+// isNumericCode reports whether a search term looks like a dotted HS code
+func isNumericCode(query string) bool {
+	if query == "" {
+		return false
+	}
+	for _, r := range query {
+		if (r < '0' || r > '9') && r != '.' && r != ' ' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
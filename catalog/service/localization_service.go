@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/google/uuid"
+)
+
+// localizationService implements LocalizationService
+type localizationService struct {
+	repo         repository.LocalizationRepository
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+}
+
+// NewLocalizationService creates a new localization service
+func NewLocalizationService(repo repository.LocalizationRepository, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository) LocalizationService {
+	return &localizationService{
+		repo:         repo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// SaveTranslation saves a translation of a product or category the tenant has
+func (s *localizationService) SaveTranslation(ctx context.Context, translation *domain.Translation) error {
+	if err := s.checkEntity(ctx, translation.TenantID, translation.EntityType, translation.EntityID); err != nil {
+		return err
+	}
+	return s.repo.SaveTranslation(ctx, translation)
+}
+
+// DeleteTranslation removes a translation
+func (s *localizationService) DeleteTranslation(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, entityID uuid.UUID, locale string) error {
+	locale, err := domain.NormalizeLocale(locale)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteTranslation(ctx, tenantID, entityType, entityID, locale)
+}
+
+// ListTranslations returns the translations of a product or category the tenant has
+func (s *localizationService) ListTranslations(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, entityID uuid.UUID) ([]*domain.Translation, error) {
+	if err := s.checkEntity(ctx, tenantID, entityType, entityID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTranslations(ctx, tenantID, entityType, entityID)
+}
+
+// checkEntity returns the entity type's not-found error if the tenant has no such entity
+func (s *localizationService) checkEntity(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, entityID uuid.UUID) error {
+	if entityType == domain.TranslationCategory {
+		if _, err := s.categoryRepo.GetByID(ctx, tenantID, entityID); err != nil {
+			return domain.ErrCategoryNotFound
+		}
+		return nil
+	}
+	if _, err := s.productRepo.GetByID(ctx, tenantID, entityID); err != nil {
+		return domain.ErrProductNotFound
+	}
+	return nil
+}
+
+// LocalizeProducts replaces each product's name and description with its
+// translation in the most preferred locale it has one in
+func (s *localizationService) LocalizeProducts(ctx context.Context, tenantID uuid.UUID, locales []string, products ...*domain.Product) error {
+	ids := make([]uuid.UUID, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	byID, err := s.find(ctx, tenantID, domain.TranslationProduct, ids, locales)
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		if t := domain.PickTranslation(byID[p.ID], locales); t != nil {
+			p.Localize(t)
+		}
+	}
+	return nil
+}
+
+// LocalizeCategories replaces each category's name and description with its
+// translation in the most preferred locale it has one in
+func (s *localizationService) LocalizeCategories(ctx context.Context, tenantID uuid.UUID, locales []string, categories ...*domain.Category) error {
+	ids := make([]uuid.UUID, 0, len(categories))
+	for _, c := range categories {
+		ids = append(ids, c.ID)
+	}
+	byID, err := s.find(ctx, tenantID, domain.TranslationCategory, ids, locales)
+	if err != nil {
+		return err
+	}
+	for _, c := range categories {
+		if t := domain.PickTranslation(byID[c.ID], locales); t != nil {
+			c.Localize(t)
+		}
+	}
+	return nil
+}
+
+// find loads the entities' translations in the locales, grouped by entity
+func (s *localizationService) find(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, ids []uuid.UUID, locales []string) (map[uuid.UUID][]*domain.Translation, error) {
+	translations, err := s.repo.FindTranslations(ctx, tenantID, entityType, ids, locales)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID][]*domain.Translation)
+	for _, t := range translations {
+		byID[t.EntityID] = append(byID[t.EntityID], t)
+	}
+	return byID, nil
+}
+
+// SaveDocumentLocale sets the language a document type is printed in
+func (s *localizationService) SaveDocumentLocale(ctx context.Context, setting *domain.DocumentLocale) error {
+	return s.repo.SaveDocumentLocale(ctx, setting)
+}
+
+// DeleteDocumentLocale returns a document type to the catalog's own names
+func (s *localizationService) DeleteDocumentLocale(ctx context.Context, tenantID uuid.UUID, documentType string) error {
+	return s.repo.DeleteDocumentLocale(ctx, tenantID, documentType)
+}
+
+// ListDocumentLocales returns the tenant's document locales
+func (s *localizationService) ListDocumentLocales(ctx context.Context, tenantID uuid.UUID) ([]*domain.DocumentLocale, error) {
+	return s.repo.ListDocumentLocales(ctx, tenantID)
+}
+
+// DocumentLocale returns the locale a document type is printed in, or empty for the catalog's own names
+func (s *localizationService) DocumentLocale(ctx context.Context, tenantID uuid.UUID, documentType string) (string, error) {
+	settings, err := s.repo.ListDocumentLocales(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	for _, setting := range settings {
+		if setting.DocumentType == documentType {
+			return setting.Locale, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
+	"github.com/google/uuid"
+)
+
+// repricingPageSize is how many products a repricing run loads at a time
+const repricingPageSize = 500
+
+// pricingService implements PricingService
+type pricingService struct {
+	repo         repository.PricingRepository
+	bulkRepo     repository.BulkPriceRepository
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	guardrails   GuardrailService
+}
+
+// NewPricingService creates a new pricing service
+func NewPricingService(repo repository.PricingRepository, bulkRepo repository.BulkPriceRepository, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, guardrails GuardrailService) PricingService {
+	return &pricingService{
+		repo:         repo,
+		bulkRepo:     bulkRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		guardrails:   guardrails,
+	}
+}
+
+// CreateRule creates a markup rule for a product or category
+func (s *pricingService) CreateRule(ctx context.Context, rule *domain.MarkupRule) error {
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidMarkupRule, err.Error())
+	}
+
+	if rule.ProductID != nil {
+		if _, err := s.productRepo.GetByID(ctx, rule.TenantID, *rule.ProductID); err != nil {
+			return fmt.Errorf("%w: unknown product", domain.ErrInvalidMarkupRule)
+		}
+	}
+	if rule.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, rule.TenantID, *rule.CategoryID); err != nil {
+			return fmt.Errorf("%w: unknown category", domain.ErrInvalidMarkupRule)
+		}
+	}
+
+	return s.repo.CreateRule(ctx, rule)
+}
+
+// ListRules returns the tenant's markup rules
+func (s *pricingService) ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.MarkupRule, error) {
+	return s.repo.ListRules(ctx, tenantID)
+}
+
+// UpdateRule updates a markup rule's percentage, rounding, approval and active flag
+func (s *pricingService) UpdateRule(ctx context.Context, rule *domain.MarkupRule) error {
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidMarkupRule, err.Error())
+	}
+
+	rule.UpdatedAt = time.Now()
+	return s.repo.UpdateRule(ctx, rule)
+}
+
+// GetRule retrieves a tenant's markup rule
+func (s *pricingService) GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.MarkupRule, error) {
+	return s.repo.GetRuleByID(ctx, tenantID, id)
+}
+
+// DeleteRule deletes a markup rule; prices already set are kept
+func (s *pricingService) DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.DeleteRule(ctx, tenantID, id)
+}
+
+// ResolveRule returns the rule that prices a product: its own, else the nearest category's
+func (s *pricingService) ResolveRule(ctx context.Context, product *domain.Product) (*domain.MarkupRule, error) {
+	rule, err := s.repo.GetActiveRuleForProduct(ctx, product.TenantID, product.ID)
+	if err != nil || rule != nil {
+		return rule, err
+	}
+
+	categoryID := &product.CategoryID
+	for depth := 0; categoryID != nil && depth < maxCategoryDepth; depth++ {
+		rule, err := s.repo.GetActiveRuleForCategory(ctx, product.TenantID, *categoryID)
+		if err != nil {
+			return nil, err
+		}
+		if rule != nil {
+			return rule, nil
+		}
+
+		category, err := s.categoryRepo.GetByID(ctx, product.TenantID, *categoryID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve category markup rule: %w", err)
+		}
+		categoryID = category.ParentID
+	}
+
+	return nil, nil
+}
+
+// Reprice recomputes a product's selling price from its markup rule.
+// Returns nil when no rule applies or the price is already right.
+func (s *pricingService) Reprice(ctx context.Context, product *domain.Product, source domain.PriceChangeSource) (*domain.PriceChange, error) {
+	return s.reprice(ctx, product, source, nil)
+}
+
+func (s *pricingService) reprice(ctx context.Context, product *domain.Product, source domain.PriceChangeSource, runID *uuid.UUID) (*domain.PriceChange, error) {
+	if product.CostPrice <= 0 {
+		return nil, nil
+	}
+
+	rule, err := s.ResolveRule(ctx, product)
+	if err != nil || rule == nil {
+		return nil, err
+	}
+
+	change := domain.NewPriceChange(product, rule, source)
+	change.RunID = runID
+
+	// Markup never pushes the price over the MRP
+	maxPrice, hasMRP, err := s.guardrails.MaxPriceUnderMRP(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+	if hasMRP && change.NewPrice > maxPrice {
+		change.NewPrice = maxPrice
+	}
+	if change.NewPrice == product.SellingPrice {
+		return nil, nil
+	}
+
+	// Only the latest recomputation is worth reviewing
+	if err := s.repo.SupersedePending(ctx, product.TenantID, product.ID); err != nil {
+		return nil, err
+	}
+
+	if change.Status == domain.PriceChangeStatusApplied {
+		product.SellingPrice = change.NewPrice
+		product.UpdatedAt = time.Now()
+		if err := s.productRepo.Update(ctx, product); err != nil {
+			return nil, fmt.Errorf("failed to apply recomputed price: %w", err)
+		}
+		publishProductChange(product, domain.EventProductUpdated)
+		auditPriceChange(ctx, product, change.OldPrice, string(source))
+	}
+
+	if err := s.repo.CreateChange(ctx, change); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// ListChanges returns price changes, optionally only those with a given status
+func (s *pricingService) ListChanges(ctx context.Context, tenantID uuid.UUID, status domain.PriceChangeStatus, limit, offset int) ([]*domain.PriceChange, error) {
+	return s.repo.ListChanges(ctx, tenantID, status, limit, offset)
+}
+
+// ApproveChange applies a pending price to the product
+func (s *pricingService) ApproveChange(ctx context.Context, tenantID, id uuid.UUID, reviewedBy *uuid.UUID) (*domain.PriceChange, error) {
+	change, err := s.repo.GetChangeByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := change.Review(domain.PriceChangeStatusApplied, reviewedBy); err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetByID(ctx, tenantID, change.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	oldPrice := product.SellingPrice
+	product.SellingPrice = change.NewPrice
+	product.UpdatedAt = time.Now()
+	if err := s.productRepo.Update(ctx, product); err != nil {
+		return nil, fmt.Errorf("failed to apply approved price: %w", err)
+	}
+	publishProductChange(product, domain.EventProductUpdated)
+	auditPriceChange(ctx, product, oldPrice, string(change.Source))
+
+	if err := s.repo.UpdateChange(ctx, change); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// RejectChange discards a pending price; the product keeps its current price
+func (s *pricingService) RejectChange(ctx context.Context, tenantID, id uuid.UUID, reviewedBy *uuid.UUID) (*domain.PriceChange, error) {
+	change, err := s.repo.GetChangeByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := change.Review(domain.PriceChangeStatusRejected, reviewedBy); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateChange(ctx, change); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// RunRepricing recomputes every active product of a tenant and returns the change report
+func (s *pricingService) RunRepricing(ctx context.Context, tenantID uuid.UUID, source domain.PriceChangeSource, startedBy *uuid.UUID) (*domain.RepricingRun, error) {
+	run := domain.NewRepricingRun(tenantID, source, startedBy)
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	for offset := 0; ; offset += repricingPageSize {
+		products, err := s.productRepo.GetByTenantID(ctx, tenantID, repricingPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, product := range products {
+			if !product.IsAvailable() {
+				continue
+			}
+			run.ProductsChecked++
+
+			change, err := s.reprice(ctx, product, source, &run.ID)
+			if err != nil {
+				// One bad product must not stop the run
+				logger.Log.Warn(fmt.Sprintf("Repricing failed for product %s: %v", product.ProductCode, err))
+				continue
+			}
+			if change != nil {
+				run.Record(change)
+			}
+		}
+
+		if len(products) < repricingPageSize {
+			break
+		}
+	}
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	if err := s.repo.FinishRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// RunScheduledRepricing runs repricing for every tenant with active markup rules
+func (s *pricingService) RunScheduledRepricing(ctx context.Context) error {
+	tenants, err := s.repo.ListTenantsWithRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenants {
+		run, err := s.RunRepricing(db.WithTenantID(ctx, tenantID), tenantID, domain.PriceChangeSourceScheduled, nil)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Scheduled repricing failed for tenant %s: %v", tenantID, err))
+			continue
+		}
+		if run.PricesApplied > 0 || run.PendingApproval > 0 {
+			logger.Log.Info(fmt.Sprintf("Repriced tenant %s: %d applied, %d pending approval",
+				tenantID, run.PricesApplied, run.PendingApproval))
+		}
+	}
+
+	return nil
+}
+
+// GetRun returns a repricing run with its changes
+func (s *pricingService) GetRun(ctx context.Context, tenantID, id uuid.UUID) (*domain.RepricingRun, error) {
+	return s.repo.GetRun(ctx, tenantID, id)
+}
+
+// ListRuns returns the tenant's repricing runs, newest first
+func (s *pricingService) ListRuns(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.RepricingRun, error) {
+	return s.repo.ListRuns(ctx, tenantID, limit, offset)
+}
+
+// BulkUpdatePrices previews or applies a price adjustment to every product matching the filter.
+// Adjusted prices are capped at the MRP like markup prices; products whose price would not
+// move, or would not stay positive, are skipped.
+func (s *pricingService) BulkUpdatePrices(ctx context.Context, tenantID uuid.UUID, filter domain.BulkPriceFilter, adjustment domain.PriceAdjustment, appliedBy *uuid.UUID, dryRun bool) (*domain.BulkPriceUpdate, error) {
+	if err := adjustment.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidBulkPriceUpdate, err.Error())
+	}
+	if filter.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, tenantID, *filter.CategoryID); err != nil {
+			return nil, fmt.Errorf("%w: unknown category", domain.ErrInvalidBulkPriceUpdate)
+		}
+	}
+
+	products, err := s.productRepo.ListForPriceUpdate(ctx, tenantID, filter, domain.MaxBulkPriceProducts+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) > domain.MaxBulkPriceProducts {
+		return nil, fmt.Errorf("%w: at most %d", domain.ErrBulkPriceTooMany, domain.MaxBulkPriceProducts)
+	}
+
+	update := domain.NewBulkPriceUpdate(tenantID, filter, adjustment, appliedBy)
+	byID := make(map[uuid.UUID]*domain.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+		item := &domain.BulkPriceItem{
+			ProductID:   product.ID,
+			ProductCode: product.ProductCode,
+			Name:        product.Name,
+			CostPrice:   product.CostPrice,
+			OldPrice:    product.SellingPrice,
+			NewPrice:    adjustment.Apply(product.SellingPrice),
+		}
+
+		maxPrice, hasMRP, err := s.guardrails.MaxPriceUnderMRP(ctx, product)
+		if err != nil {
+			return nil, err
+		}
+		if hasMRP && item.NewPrice > maxPrice {
+			item.NewPrice = maxPrice
+			item.Flags = append(item.Flags, domain.BulkPriceFlagCappedAtMRP)
+		}
+		if item.NewPrice > 0 && item.NewPrice < product.CostPrice {
+			item.Flags = append(item.Flags, domain.BulkPriceFlagBelowCost)
+		}
+		update.Add(item)
+	}
+
+	// A preview or an update that changes nothing leaves nothing to roll back
+	if dryRun || len(update.Items) == 0 {
+		update.RollbackToken = ""
+		return update, nil
+	}
+
+	if err := s.bulkRepo.Apply(ctx, update); err != nil {
+		return nil, err
+	}
+
+	for _, item := range update.Items {
+		product := byID[item.ProductID]
+		product.SellingPrice = item.NewPrice
+		product.UpdatedAt = update.AppliedAt
+		publishProductChange(product, domain.EventProductUpdated)
+		auditPriceChange(ctx, product, item.OldPrice, "bulk_update")
+	}
+
+	return update, nil
+}
+
+// RollbackBulkUpdate restores the old prices of a bulk update. Products repriced again
+// since the update keep their newer price.
+func (s *pricingService) RollbackBulkUpdate(ctx context.Context, tenantID uuid.UUID, token string, rolledBackBy *uuid.UUID) (*domain.BulkPriceRollback, error) {
+	update, err := s.bulkRepo.GetByToken(ctx, tenantID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := update.CanRollback(now); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.bulkRepo.Rollback(ctx, update, rolledBackBy, now)
+	if err != nil {
+		return nil, err
+	}
+
+	isRestored := make(map[uuid.UUID]bool, len(restored))
+	for _, id := range restored {
+		isRestored[id] = true
+	}
+
+	result := &domain.BulkPriceRollback{Update: update, Restored: len(restored)}
+	for _, item := range update.Items {
+		if !isRestored[item.ProductID] {
+			result.Kept = append(result.Kept, item)
+			continue
+		}
+
+		product, err := s.productRepo.GetByID(ctx, tenantID, item.ProductID)
+		if err != nil {
+			logger.Log.Warn(fmt.Sprintf("Rolled back price of product %s but could not reload it: %v", item.ProductCode, err))
+			continue
+		}
+		publishProductChange(product, domain.EventProductUpdated)
+		auditPriceChange(ctx, product, item.NewPrice, "bulk_rollback")
+	}
+
+	return result, nil
+}
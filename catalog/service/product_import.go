@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/dataimport"
+	"github.com/google/uuid"
+)
+
+// Product import columns; the first four are required
+const (
+	importColName         = "name"
+	importColCategory     = "category" // Category code or name
+	importColSellingPrice = "sellingPrice"
+	importColUnit         = "unit"
+	importColCostPrice    = "costPrice"
+	importColMRP          = "mrp"
+	importColTaxRate      = "taxRate"
+	importColSKU          = "sku"
+	importColBarcode      = "barcode"
+	importColHSCode       = "hsCode"
+	importColDescription  = "description"
+)
+
+// Import validates a product upload row by row and, unless it is a dry run, creates
+// the products when every row is valid and they all fit the plan's product limit,
+// which is ErrProductLimitReached otherwise. Each product is created like one added by
+// hand, so a failure partway, e.g. a barcode taken meanwhile, leaves the earlier rows
+// created; the report counts them and gives the failed row's error.
+func (s *productService) Import(ctx context.Context, tenantID uuid.UUID, table *dataimport.Table, dryRun bool) (*dataimport.Report, error) {
+	report := dataimport.NewReport(table, dryRun)
+	if missing := table.Missing(importColName, importColCategory, importColSellingPrice, importColUnit); missing != nil {
+		report.FailMissing(missing)
+		return report, nil
+	}
+
+	categories := map[string]uuid.UUID{}
+	barcodes := map[string]int{}
+	skus := map[string]int{}
+	products := make([]*domain.Product, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		before := len(report.Errors)
+		product, err := s.importRow(ctx, tenantID, row, report, categories)
+		if err != nil {
+			return nil, err
+		}
+		if product == nil {
+			continue
+		}
+
+		if product.Barcode != nil {
+			if first, dup := barcodes[*product.Barcode]; dup {
+				report.Fail(row.Number, importColBarcode, fmt.Sprintf("barcode repeats row %d", first))
+			} else {
+				barcodes[*product.Barcode] = row.Number
+			}
+		}
+		if product.SKU != nil {
+			if first, dup := skus[*product.SKU]; dup {
+				report.Fail(row.Number, importColSKU, fmt.Sprintf("SKU repeats row %d", first))
+			} else {
+				skus[*product.SKU] = row.Number
+			}
+		}
+		if len(report.Errors) == before {
+			report.Valid++
+			products = append(products, product)
+		}
+	}
+
+	if !report.OK() {
+		return report, nil
+	}
+	// The whole upload is checked, so a dry run also tells whether it would fit
+	if err := s.CheckQuota(ctx, tenantID, len(products)); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return report, nil
+	}
+	// Every row is valid, so products line up with the table's rows
+	for i, product := range products {
+		if err := s.Create(ctx, product); err != nil {
+			column, invalid := productImportColumn(err)
+			if !invalid {
+				return nil, fmt.Errorf("row %d: %w", table.Rows[i].Number, err)
+			}
+			report.Fail(table.Rows[i].Number, column, err.Error())
+			return report, nil
+		}
+		report.Imported++
+	}
+	return report, nil
+}
+
+// importRow reads and validates one row; problems go to the report and give a nil product
+func (s *productService) importRow(ctx context.Context, tenantID uuid.UUID, row dataimport.Row, report *dataimport.Report, categories map[string]uuid.UUID) (*domain.Product, error) {
+	ok := true
+	fail := func(column, message string) {
+		report.Fail(row.Number, column, message)
+		ok = false
+	}
+
+	name := row.Get(importColName)
+	if len(name) < 2 || len(name) > 255 {
+		fail(importColName, "name must be 2 to 255 characters")
+	}
+	sellingPrice, set, err := row.Float(importColSellingPrice)
+	if err != nil {
+		fail(importColSellingPrice, err.Error())
+	} else if !set || sellingPrice <= 0 {
+		fail(importColSellingPrice, "selling price must be above zero")
+	}
+	costPrice, _, err := row.Float(importColCostPrice)
+	if err != nil {
+		fail(importColCostPrice, err.Error())
+	} else if costPrice < 0 {
+		fail(importColCostPrice, "cost price cannot be negative")
+	}
+	mrp, mrpSet, err := row.Float(importColMRP)
+	if err != nil {
+		fail(importColMRP, err.Error())
+	}
+	taxRate, _, err := row.Float(importColTaxRate)
+	if err != nil {
+		fail(importColTaxRate, err.Error())
+	} else if taxRate < 0 || taxRate > 100 {
+		fail(importColTaxRate, "tax rate must be between 0 and 100")
+	}
+	unit := row.Get(importColUnit)
+	if unit == "" {
+		fail(importColUnit, "unit is required")
+	}
+
+	categoryID, err := s.importCategory(ctx, tenantID, row.Get(importColCategory), categories)
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		fail(importColCategory, "no category with this code or name")
+	} else if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	product := domain.NewProduct(tenantID, categoryID, name, sellingPrice)
+	product.Description = row.Optional(importColDescription)
+	product.CostPrice = costPrice
+	if mrpSet {
+		product.MRP = &mrp
+	}
+	product.TaxRate = taxRate
+	product.SKU = row.Optional(importColSKU)
+	product.Barcode = row.Optional(importColBarcode)
+	product.HSCode = row.Optional(importColHSCode)
+	product.Unit = unit
+
+	if err := s.validateNew(ctx, product); err != nil {
+		column, invalid := productImportColumn(err)
+		if !invalid {
+			return nil, err
+		}
+		fail(column, err.Error())
+		return nil, nil
+	}
+	return product, nil
+}
+
+// importCategory resolves a category code or name, remembering earlier rows' lookups
+func (s *productService) importCategory(ctx context.Context, tenantID uuid.UUID, ref string, categories map[string]uuid.UUID) (uuid.UUID, error) {
+	key := strings.ToLower(ref)
+	if id, ok := categories[key]; ok {
+		return id, nil
+	}
+	if ref == "" {
+		return uuid.Nil, domain.ErrCategoryNotFound
+	}
+
+	if category, err := s.categoryRepo.GetByCode(ctx, tenantID, ref); err == nil {
+		categories[key] = category.ID
+		return category.ID, nil
+	}
+	matches, err := s.categoryRepo.Search(ctx, tenantID, ref, 50, 0)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	for _, category := range matches {
+		if strings.EqualFold(category.Name, ref) {
+			categories[key] = category.ID
+			return category.ID, nil
+		}
+	}
+	return uuid.Nil, domain.ErrCategoryNotFound
+}
+
+// productImportColumn is the column a product validation error belongs to; other
+// errors are not the row's fault
+func productImportColumn(err error) (string, bool) {
+	switch {
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		return importColCategory, true
+	case errors.Is(err, domain.ErrUnknownUnit):
+		return importColUnit, true
+	case errors.Is(err, domain.ErrInvalidHSCode):
+		return importColHSCode, true
+	case errors.Is(err, domain.ErrAboveMRP):
+		return importColSellingPrice, true
+	case errors.Is(err, domain.ErrBarcodeInUse):
+		return importColBarcode, true
+	case errors.Is(err, domain.ErrUnknownTax):
+		return importColTaxRate, true
+	}
+	return "", false
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/aceextension/core/dataimport"
+	"github.com/google/uuid"
+)
+
+// importCategories resolves every category code to one category
+type importCategories struct {
+	repository.CategoryRepository
+	category *domain.Category
+}
+
+func (r importCategories) GetByCode(context.Context, uuid.UUID, string) (*domain.Category, error) {
+	return r.category, nil
+}
+
+func (r importCategories) GetByID(context.Context, uuid.UUID, uuid.UUID) (*domain.Category, error) {
+	return r.category, nil
+}
+
+// importUnits resolves every unit as given
+type importUnits struct{ UnitService }
+
+func (importUnits) Resolve(_ context.Context, tenantID uuid.UUID, code string) (*domain.UnitOfMeasure, error) {
+	return domain.NewUnitOfMeasure(tenantID, code, code, code, 0), nil
+}
+
+// importGuardrails allows every price
+type importGuardrails struct{ GuardrailService }
+
+func (importGuardrails) CheckMRP(context.Context, *domain.Product) error { return nil }
+
+// importProducts fails the test if the import creates a product
+type importProducts struct {
+	repository.ProductRepository
+	t *testing.T
+}
+
+func (r importProducts) Create(_ context.Context, product *domain.Product) error {
+	r.t.Errorf("product %q was created past the plan limit", product.Name)
+	return nil
+}
+
+// fixedQuota leaves the same room for every tenant
+type fixedQuota int64
+
+func (q fixedQuota) RemainingProducts(context.Context, uuid.UUID) (int64, error) {
+	return int64(q), nil
+}
+
+// newImportService builds a product service whose plan has room for remaining more products
+func newImportService(t *testing.T, tenantID uuid.UUID, remaining int64) ProductService {
+	category := domain.NewCategory(tenantID, "Beverages")
+	s := NewProductService(importProducts{t: t}, importCategories{category: category}, nil, importUnits{}, nil, nil, importGuardrails{})
+	s.SetQuota(fixedQuota(remaining))
+	return s
+}
+
+// readImport reads a product upload of n valid rows
+func readImport(t *testing.T, n int) *dataimport.Table {
+	var b strings.Builder
+	b.WriteString("name,category,sellingPrice,unit\n")
+	for i := 0; i < n; i++ {
+		b.WriteString("Mineral Water " + strings.Repeat("I", i+1) + ",BEV,25,pcs\n")
+	}
+	table, err := dataimport.Read("products.csv", strings.NewReader(b.String()), int64(b.Len()))
+	if err != nil {
+		t.Fatalf("read upload: %v", err)
+	}
+	return table
+}
+
+// TestImportPastPlanLimit imports more products than the plan has room for; nothing
+// may be created, and a dry run must warn the same way
+func TestImportPastPlanLimit(t *testing.T) {
+	tenantID := uuid.New()
+	for _, dryRun := range []bool{false, true} {
+		s := newImportService(t, tenantID, 2)
+		report, err := s.Import(context.Background(), tenantID, readImport(t, 3), dryRun)
+		if !errors.Is(err, domain.ErrProductLimitReached) {
+			t.Errorf("dryRun=%v: got report %+v and error %v, want %v", dryRun, report, err, domain.ErrProductLimitReached)
+		}
+	}
+}
+
+// TestImportWithinPlanLimit checks an upload that exactly fills the plan is accepted
+func TestImportWithinPlanLimit(t *testing.T) {
+	tenantID := uuid.New()
+	s := newImportService(t, tenantID, 3)
+	report, err := s.Import(context.Background(), tenantID, readImport(t, 3), true)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if !report.OK() || report.Valid != 3 {
+		t.Errorf("got report %+v, want 3 valid rows", report)
+	}
+}
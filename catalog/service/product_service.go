@@ -3,27 +3,50 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
 	"github.com/aceextension/catalog/domain"
 	"github.com/aceextension/catalog/repository"
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/events"
 	"github.com/aceextension/fiscal"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 )
 
 // productService implements ProductService
 type productService struct {
-	repo repository.ProductRepository
+	repo         repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	barcodes     repository.ProductBarcodeRepository
+	unitService  UnitService
+	taxService   TaxService
+	pricing      PricingService
+	guardrails   GuardrailService
+	quota        ProductQuota
 }
 
 // NewProductService creates a new product service
-func NewProductService(repo repository.ProductRepository) ProductService {
+func NewProductService(repo repository.ProductRepository, categoryRepo repository.CategoryRepository, barcodes repository.ProductBarcodeRepository, unitService UnitService, taxService TaxService, pricing PricingService, guardrails GuardrailService) ProductService {
 	return &productService{
-		repo: repo,
+		repo:         repo,
+		categoryRepo: categoryRepo,
+		barcodes:     barcodes,
+		unitService:  unitService,
+		taxService:   taxService,
+		pricing:      pricing,
+		guardrails:   guardrails,
 	}
 }
 
 // Create creates a new product
 func (s *productService) Create(ctx context.Context, product *domain.Product) error {
+	if err := s.validateNew(ctx, product); err != nil {
+		return err
+	}
+
 	// Generate product code
 	nextNum, err := s.repo.GetNextProductNumber(ctx, product.TenantID)
 	if err != nil {
@@ -33,7 +56,7 @@ func (s *productService) Create(ctx context.Context, product *domain.Product) er
 	// Get fiscal year for code generation
 	fiscalYear := fiscal.GetActiveFiscalYear(ctx, product.TenantID)
 	if fiscalYear != nil {
-		product.ProductCode = fmt.Sprintf("PROD-%s-%04d", fiscalYear.Code, nextNum)
+		product.ProductCode = fmt.Sprintf("PROD-%s-%04d", fiscalYear.Code(), nextNum)
 	} else {
 		product.ProductCode = fmt.Sprintf("PROD-%04d", nextNum)
 	}
@@ -43,12 +66,56 @@ func (s *productService) Create(ctx context.Context, product *domain.Product) er
 		return fmt.Errorf("failed to create product: %w", err)
 	}
 
+	publishProductChange(product, domain.EventProductCreated)
 	return nil
 }
 
-// GetByID retrieves a product by ID
-func (s *productService) GetByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
-	return s.repo.GetByID(ctx, id)
+// SetQuota sets the plan product limit bulk creations are checked against
+func (s *productService) SetQuota(quota ProductQuota) {
+	s.quota = quota
+}
+
+// CheckQuota returns ErrProductLimitReached when fewer than n more products fit the plan
+func (s *productService) CheckQuota(ctx context.Context, tenantID uuid.UUID, n int) error {
+	if s.quota == nil || n <= 0 {
+		return nil
+	}
+	remaining, err := s.quota.RemainingProducts(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to check the plan product limit: %w", err)
+	}
+	if remaining >= 0 && int64(n) > remaining {
+		return fmt.Errorf("%w: %d to create, %d left on the plan", domain.ErrProductLimitReached, n, remaining)
+	}
+	return nil
+}
+
+// validateNew checks a new product's category, unit, HS code, tax group, MRP and
+// barcode, normalizing the unit and HS code
+func (s *productService) validateNew(ctx context.Context, product *domain.Product) error {
+	if _, err := s.categoryRepo.GetByID(ctx, product.TenantID, product.CategoryID); err != nil {
+		return err
+	}
+	if err := s.normalizeUnit(ctx, product); err != nil {
+		return err
+	}
+	if err := s.normalizeHSCode(product); err != nil {
+		return err
+	}
+	if product.TaxGroupID != nil {
+		if err := s.taxService.ValidateGroup(ctx, product.TenantID, *product.TaxGroupID); err != nil {
+			return err
+		}
+	}
+	if err := s.guardrails.CheckMRP(ctx, product); err != nil {
+		return err
+	}
+	return s.checkBarcodeFree(ctx, product)
+}
+
+// GetByID retrieves a tenant's product by ID
+func (s *productService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Product, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
 }
 
 // GetByCode retrieves a product by code
@@ -61,9 +128,18 @@ func (s *productService) GetBySKU(ctx context.Context, tenantID uuid.UUID, sku s
 	return s.repo.GetBySKU(ctx, tenantID, sku)
 }
 
-// GetByBarcode retrieves a product by barcode
+// GetByBarcode retrieves a product by its own barcode or one of its pack barcodes
 func (s *productService) GetByBarcode(ctx context.Context, tenantID uuid.UUID, barcode string) (*domain.Product, error) {
-	return s.repo.GetByBarcode(ctx, tenantID, barcode)
+	product, err := s.repo.GetByBarcode(ctx, tenantID, barcode)
+	if err == nil {
+		return product, nil
+	}
+
+	pack, packErr := s.barcodes.GetByBarcode(ctx, tenantID, barcode)
+	if packErr != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, tenantID, pack.ProductID)
 }
 
 // GetByCategory retrieves products by category
@@ -71,19 +147,95 @@ func (s *productService) GetByCategory(ctx context.Context, categoryID uuid.UUID
 	return s.repo.GetByCategory(ctx, categoryID, limit, offset)
 }
 
-// GetByTenantID retrieves all products for a tenant
-func (s *productService) GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Product, error) {
-	return s.repo.GetByTenantID(ctx, tenantID, limit, offset)
+// GetByHSCode retrieves products by HS chapter, heading or full code
+func (s *productService) GetByHSCode(ctx context.Context, tenantID uuid.UUID, code string, limit, offset int) ([]*domain.Product, error) {
+	prefix := strings.NewReplacer(".", "", " ", "", "-", "").Replace(strings.TrimSpace(code))
+	if prefix == "" {
+		return nil, domain.ErrInvalidHSCode
+	}
+	return s.repo.GetByHSCode(ctx, tenantID, prefix, limit, offset)
+}
+
+// SummarizeByHSChapter returns product counts per HS chapter
+func (s *productService) SummarizeByHSChapter(ctx context.Context, tenantID uuid.UUID) ([]*domain.HSChapterSummary, error) {
+	return s.repo.SummarizeByHSChapter(ctx, tenantID)
+}
+
+// Export passes every product for a tenant to fn with its category's code as it is read from the database
+func (s *productService) Export(ctx context.Context, tenantID uuid.UUID, fn func(*domain.Product, string) error) error {
+	return s.repo.StreamByTenantID(ctx, tenantID, fn)
+}
+
+// List returns a page of a tenant's products, newest first
+func (s *productService) List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) (db.Page[*domain.Product], error) {
+	products, total, err := s.repo.List(ctx, tenantID, tags, page)
+	if err != nil {
+		return db.Page[*domain.Product]{}, err
+	}
+	return db.NewPage(products, total, page, func(p *domain.Product) db.Cursor {
+		return db.NewCursor(p.CreatedAt, p.ID)
+	}), nil
 }
 
 // Update updates a product
 func (s *productService) Update(ctx context.Context, product *domain.Product) error {
-	return s.repo.Update(ctx, product)
+	if _, err := s.categoryRepo.GetByID(ctx, product.TenantID, product.CategoryID); err != nil {
+		return err
+	}
+	if err := s.normalizeUnit(ctx, product); err != nil {
+		return err
+	}
+	if err := s.normalizeHSCode(product); err != nil {
+		return err
+	}
+	if product.TaxGroupID != nil {
+		if err := s.taxService.ValidateGroup(ctx, product.TenantID, *product.TaxGroupID); err != nil {
+			return err
+		}
+	}
+	if err := s.guardrails.CheckMRP(ctx, product); err != nil {
+		return err
+	}
+	if err := s.checkBarcodeFree(ctx, product); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByID(ctx, product.TenantID, product.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, product); err != nil {
+		return err
+	}
+	publishProductChange(product, domain.EventProductUpdated)
+	if existing.SellingPrice != product.SellingPrice {
+		auditPriceChange(ctx, product, existing.SellingPrice, "manual")
+	}
+
+	// A new purchase cost recomputes the selling price under the product's markup rule
+	if existing.CostPrice != product.CostPrice {
+		if _, err := s.pricing.Reprice(ctx, product, domain.PriceChangeSourceCost); err != nil {
+			return fmt.Errorf("product saved but repricing failed: %w", err)
+		}
+	}
+
+	return nil
 }
 
-// Delete deletes a product
-func (s *productService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+// Delete deletes a tenant's product
+func (s *productService) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	// Read first for the event
+	product, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, tenantID, id); err != nil {
+		return err
+	}
+
+	publishProductChange(product, domain.EventProductDeleted)
+	return nil
 }
 
 // Search searches products
@@ -91,7 +243,95 @@ func (s *productService) Search(ctx context.Context, tenantID uuid.UUID, query s
 	return s.repo.Search(ctx, tenantID, query, limit, offset)
 }
 
+// ListByTags retrieves products by tag
+func (s *productService) ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*domain.Product, error) {
+	return s.repo.ListByTags(ctx, tenantID, filter, query, limit, offset)
+}
+
 // Count returns total number of products
 func (s *productService) Count(ctx context.Context, tenantID uuid.UUID) (int64, error) {
 	return s.repo.Count(ctx, tenantID)
 }
+
+// normalizeUnit replaces the product's free-text unit with the canonical registry code
+func (s *productService) normalizeUnit(ctx context.Context, product *domain.Product) error {
+	unit, err := s.unitService.Resolve(ctx, product.TenantID, product.Unit)
+	if err != nil {
+		return err
+	}
+
+	product.Unit = unit.Code
+	return nil
+}
+
+// checkBarcodeFree rejects a product barcode another of the tenant's products
+// already uses, as its own barcode or on a pack
+func (s *productService) checkBarcodeFree(ctx context.Context, product *domain.Product) error {
+	if product.Barcode == nil {
+		return nil
+	}
+	barcode := strings.TrimSpace(*product.Barcode)
+	if barcode == "" {
+		product.Barcode = nil
+		return nil
+	}
+	product.Barcode = &barcode
+
+	taken, err := s.barcodes.FindTaken(ctx, product.TenantID, product.ID, []string{barcode})
+	if err != nil {
+		return err
+	}
+	if len(taken) > 0 {
+		return fmt.Errorf("%w: %s", domain.ErrBarcodeInUse, barcode)
+	}
+	return nil
+}
+
+// normalizeHSCode validates the HS code format and stores it without separators
+func (s *productService) normalizeHSCode(product *domain.Product) error {
+	if product.HSCode == nil || strings.TrimSpace(*product.HSCode) == "" {
+		product.HSCode = nil
+		return nil
+	}
+
+	code, err := domain.NormalizeHSCode(*product.HSCode)
+	if err != nil {
+		return err
+	}
+
+	product.HSCode = &code
+	return nil
+}
+
+// publishProductChange announces a saved product change to live clients
+func publishProductChange(product *domain.Product, eventType string) {
+	events.Publish(events.New(product.TenantID, domain.TopicProducts, eventType, domain.ProductChange{
+		ID:           product.ID,
+		ProductCode:  product.ProductCode,
+		Name:         product.Name,
+		SellingPrice: product.SellingPrice,
+		Barcode:      product.Barcode,
+		IsActive:     product.IsActive,
+	}))
+}
+
+// auditPriceChange logs a selling price change; moves over 10% are critical and
+// reach the owners' daily digest
+func auditPriceChange(ctx context.Context, product *domain.Product, oldPrice float64, source string) {
+	if audit.Service == nil {
+		return
+	}
+	auditCtx := &auditDomain.AuditContext{TenantID: &product.TenantID}
+	if userID, ok := db.GetUserID(ctx); ok {
+		auditCtx.UserID = &userID
+	}
+
+	entityIDStr := product.ID.String()
+	audit.Service.Log(ctx, "CHANGE_PRICE", "Product", &entityIDStr, map[string]interface{}{
+		"product_code": product.ProductCode,
+		"name":         product.Name,
+		"old_price":    oldPrice,
+		"new_price":    product.SellingPrice,
+		"source":       source,
+	}, auditCtx)
+}
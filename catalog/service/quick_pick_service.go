@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/aceextension/core/cache"
+	"github.com/google/uuid"
+)
+
+const (
+	// quickPickGridSize is the number of products shown on the POS quick-pick grid
+	quickPickGridSize = 20
+	quickPickCacheTTL = 5 * time.Minute
+)
+
+// quickPickKey identifies a user's grid within a tenant
+type quickPickKey struct {
+	tenantID uuid.UUID
+	userID   uuid.UUID
+}
+
+// quickPickService implements QuickPickService
+type quickPickService struct {
+	repo        repository.QuickPickRepository
+	productRepo repository.ProductRepository
+	cache       *cache.TTLCache[quickPickKey, []*domain.ProductQuickPick]
+}
+
+// NewQuickPickService creates a new quick-pick service
+func NewQuickPickService(repo repository.QuickPickRepository, productRepo repository.ProductRepository) QuickPickService {
+	return &quickPickService{
+		repo:        repo,
+		productRepo: productRepo,
+		cache:       cache.New[quickPickKey, []*domain.ProductQuickPick](quickPickCacheTTL, 10000),
+	}
+}
+
+// RecordView records a product view and keeps the recent list bounded
+func (s *quickPickService) RecordView(ctx context.Context, tenantID, userID, productID uuid.UUID) error {
+	if err := s.repo.RecordView(ctx, tenantID, userID, productID); err != nil {
+		return err
+	}
+	if err := s.repo.TrimRecent(ctx, tenantID, userID, domain.MaxRecentProducts); err != nil {
+		return err
+	}
+
+	s.cache.Delete(quickPickKey{tenantID, userID})
+	return nil
+}
+
+// Pin adds a product to the user's favorites
+func (s *quickPickService) Pin(ctx context.Context, tenantID, userID, productID uuid.UUID, position int) error {
+	if _, err := s.productRepo.GetByID(ctx, tenantID, productID); err != nil {
+		return err
+	}
+
+	if err := s.repo.SetFavorite(ctx, tenantID, userID, productID, true, position); err != nil {
+		return err
+	}
+
+	s.cache.Delete(quickPickKey{tenantID, userID})
+	return nil
+}
+
+// Unpin removes a product from the user's favorites, keeping it as a recent view
+func (s *quickPickService) Unpin(ctx context.Context, tenantID, userID, productID uuid.UUID) error {
+	if err := s.repo.SetFavorite(ctx, tenantID, userID, productID, false, 0); err != nil {
+		return err
+	}
+
+	s.cache.Delete(quickPickKey{tenantID, userID})
+	return nil
+}
+
+// List returns the user's quick-pick grid
+func (s *quickPickService) List(ctx context.Context, tenantID, userID uuid.UUID) ([]*domain.ProductQuickPick, error) {
+	key := quickPickKey{tenantID, userID}
+	if picks, ok := s.cache.Get(key); ok {
+		return picks, nil
+	}
+
+	picks, err := s.repo.List(ctx, tenantID, userID, quickPickGridSize)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(key, picks)
+	return picks, nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/google/uuid"
+)
+
+// reservationService implements ReservationService
+type reservationService struct {
+	repo        repository.ReservationRepository
+	productRepo repository.ProductRepository
+	unitService UnitService
+	levels      StockLevels
+}
+
+// NewReservationService creates a new reservation service that counts on-hand stock from bins
+// until SetStockLevels is called
+func NewReservationService(repo repository.ReservationRepository, productRepo repository.ProductRepository, binRepo repository.BinRepository, unitService UnitService) ReservationService {
+	return &reservationService{
+		repo:        repo,
+		productRepo: productRepo,
+		unitService: unitService,
+		levels:      binStockLevels{repo: binRepo},
+	}
+}
+
+// SetStockLevels sets where on-hand quantities come from
+func (s *reservationService) SetStockLevels(levels StockLevels) {
+	s.levels = levels
+}
+
+// Reserve validates the reservations and saves them if the stock is available
+func (s *reservationService) Reserve(ctx context.Context, tenantID uuid.UUID, reservations []*domain.StockReservation) error {
+	if len(reservations) == 0 {
+		return fmt.Errorf("%w: at least one item is required", domain.ErrInvalidReservation)
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(reservations))
+	for _, reservation := range reservations {
+		if reservation.TenantID != tenantID {
+			return fmt.Errorf("%w: reservation belongs to another tenant", domain.ErrInvalidReservation)
+		}
+		if err := reservation.Validate(); err != nil {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidReservation, err.Error())
+		}
+
+		product, err := s.productRepo.GetByID(ctx, tenantID, reservation.ProductID)
+		if err != nil {
+			return err
+		}
+		if err := s.unitService.ValidateQuantity(ctx, tenantID, product.Unit, reservation.Quantity); err != nil {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidReservation, err.Error())
+		}
+		productIDs = append(productIDs, reservation.ProductID)
+	}
+
+	onHand, err := s.levels.OnHand(ctx, tenantID, productIDs)
+	if err != nil {
+		return fmt.Errorf("failed to read stock levels: %w", err)
+	}
+	return s.repo.CreateIfAvailable(ctx, tenantID, reservations, onHand)
+}
+
+// GetReservation retrieves a tenant's reservation
+func (s *reservationService) GetReservation(ctx context.Context, tenantID, id uuid.UUID) (*domain.StockReservation, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// ListReservations lists reservations newest first
+func (s *reservationService) ListReservations(ctx context.Context, tenantID uuid.UUID, filter domain.ReservationFilter) ([]*domain.StockReservation, error) {
+	return s.repo.List(ctx, tenantID, filter)
+}
+
+// Release lets go of one reservation
+func (s *reservationService) Release(ctx context.Context, tenantID, id uuid.UUID, reason *string) (*domain.StockReservation, error) {
+	return s.repo.Close(ctx, tenantID, id, domain.ReservationReleased, reason)
+}
+
+// ReleaseReference releases every active reservation of a document
+func (s *reservationService) ReleaseReference(ctx context.Context, tenantID uuid.UUID, referenceType domain.ReservationReference, referenceID uuid.UUID, reason *string) (int64, error) {
+	if !referenceType.Valid() {
+		return 0, fmt.Errorf("%w: reference type must be order or quote", domain.ErrInvalidReservation)
+	}
+	return s.repo.CloseByReference(ctx, tenantID, referenceType, referenceID, domain.ReservationReleased, reason)
+}
+
+// ConsumeReference marks a document's active reservations as fulfilled
+func (s *reservationService) ConsumeReference(ctx context.Context, tenantID uuid.UUID, referenceType domain.ReservationReference, referenceID uuid.UUID) (int64, error) {
+	if !referenceType.Valid() {
+		return 0, fmt.Errorf("%w: reference type must be order or quote", domain.ErrInvalidReservation)
+	}
+	return s.repo.CloseByReference(ctx, tenantID, referenceType, referenceID, domain.ReservationConsumed, nil)
+}
+
+// Availability computes on-hand minus reserved per product
+func (s *reservationService) Availability(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) ([]domain.StockAvailability, error) {
+	onHand, err := s.levels.OnHand(ctx, tenantID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stock levels: %w", err)
+	}
+	reserved, err := s.repo.Reserved(ctx, tenantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := make([]domain.StockAvailability, len(productIDs))
+	for i, productID := range productIDs {
+		availability[i] = domain.NewStockAvailability(productID, onHand[productID], reserved[productID])
+	}
+	return availability, nil
+}
+
+// Report summarizes held reservations per product with current availability
+func (s *reservationService) Report(ctx context.Context, tenantID uuid.UUID) ([]*domain.ReservationSummary, error) {
+	summaries, err := s.repo.Summarize(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]uuid.UUID, len(summaries))
+	for i, summary := range summaries {
+		productIDs[i] = summary.ProductID
+	}
+	onHand, err := s.levels.OnHand(ctx, tenantID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stock levels: %w", err)
+	}
+
+	for _, summary := range summaries {
+		summary.StockAvailability = domain.NewStockAvailability(summary.ProductID, onHand[summary.ProductID], summary.Reserved)
+	}
+	return summaries, nil
+}
+
+// ExpireDue marks lapsed reservations expired. Availability already ignores them;
+// this keeps their status and the report honest.
+func (s *reservationService) ExpireDue(ctx context.Context) (int64, error) {
+	return s.repo.ExpireDue(ctx, time.Now())
+}
+
+// binStockLevels counts a product's on-hand stock as the total shelved in its bins
+type binStockLevels struct {
+	repo repository.BinRepository
+}
+
+func (l binStockLevels) OnHand(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	stock, err := l.repo.ListStockByProducts(ctx, tenantID, productIDs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	onHand := make(map[uuid.UUID]float64, len(productIDs))
+	for _, held := range stock {
+		onHand[held.ProductID] += held.Quantity
+	}
+	return onHand, nil
+}
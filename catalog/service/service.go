@@ -2,21 +2,27 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/core/dataimport"
+	"github.com/aceextension/core/db"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 )
 
 // CategoryService defines the interface for category business logic
 type CategoryService interface {
 	Create(ctx context.Context, category *domain.Category) error
-	GetByID(ctx context.Context, id uuid.UUID) (*domain.Category, error)
+	// GetByID, Update and Delete only reach the tenant's own categories; others are ErrCategoryNotFound
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Category, error)
 	GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.Category, error)
-	GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Category, error)
+	// List returns a page of categories in display order
+	List(ctx context.Context, tenantID uuid.UUID, page db.PageRequest) (db.Page[*domain.Category], error)
 	GetRootCategories(ctx context.Context, tenantID uuid.UUID) ([]*domain.Category, error)
 	GetChildren(ctx context.Context, parentID uuid.UUID) ([]*domain.Category, error)
 	Update(ctx context.Context, category *domain.Category) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
 	Search(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) ([]*domain.Category, error)
 	Count(ctx context.Context, tenantID uuid.UUID) (int64, error)
 }
@@ -24,14 +30,316 @@ type CategoryService interface {
 // ProductService defines the interface for product business logic
 type ProductService interface {
 	Create(ctx context.Context, product *domain.Product) error
-	GetByID(ctx context.Context, id uuid.UUID) (*domain.Product, error)
+	// GetByID, Update and Delete only reach the tenant's own products; others are ErrProductNotFound
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Product, error)
 	GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.Product, error)
 	GetBySKU(ctx context.Context, tenantID uuid.UUID, sku string) (*domain.Product, error)
 	GetByBarcode(ctx context.Context, tenantID uuid.UUID, barcode string) (*domain.Product, error)
 	GetByCategory(ctx context.Context, categoryID uuid.UUID, limit, offset int) ([]*domain.Product, error)
-	GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Product, error)
+	GetByHSCode(ctx context.Context, tenantID uuid.UUID, code string, limit, offset int) ([]*domain.Product, error)
+	// List returns a page of products, newest first; a tags filter keeps products carrying them
+	List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) (db.Page[*domain.Product], error)
+	SummarizeByHSChapter(ctx context.Context, tenantID uuid.UUID) ([]*domain.HSChapterSummary, error)
+	// Export passes every product for a tenant to fn with its category's code, as it is read
+	Export(ctx context.Context, tenantID uuid.UUID, fn func(product *domain.Product, categoryCode string) error) error
+	// Import creates products from an upload when every row is valid; a dry run only validates
+	Import(ctx context.Context, tenantID uuid.UUID, table *dataimport.Table, dryRun bool) (*dataimport.Report, error)
 	Update(ctx context.Context, product *domain.Product) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
 	Search(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) ([]*domain.Product, error)
+	// ListByTags retrieves products carrying the filter's tags, optionally narrowed by a search query
+	ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*domain.Product, error)
 	Count(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	// CheckQuota returns ErrProductLimitReached when the tenant's plan leaves room for
+	// fewer than n more products; bulk creations call it before creating any
+	CheckQuota(ctx context.Context, tenantID uuid.UUID, n int) error
+
+	SetQuota(quota ProductQuota)
+}
+
+// ProductQuota reports the room left under the tenant's plan product limit. The
+// subscription side sets its own after Init; until then products are not limited.
+type ProductQuota interface {
+	// RemainingProducts returns how many more products the tenant may create, or -1 when unlimited
+	RemainingProducts(ctx context.Context, tenantID uuid.UUID) (int64, error)
+}
+
+// UnitService defines the interface for unit of measure business logic
+type UnitService interface {
+	Create(ctx context.Context, unit *domain.UnitOfMeasure) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.UnitOfMeasure, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]*domain.UnitOfMeasure, error)
+	Update(ctx context.Context, tenantID uuid.UUID, unit *domain.UnitOfMeasure) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+	// Resolve normalizes a free-text unit and returns the matching registry entry
+	Resolve(ctx context.Context, tenantID uuid.UUID, code string) (*domain.UnitOfMeasure, error)
+	// ValidateQuantity checks a quantity against the unit's decimal precision
+	ValidateQuantity(ctx context.Context, tenantID uuid.UUID, code string, quantity float64) error
+}
+
+// TaxService defines the interface for tax registry business logic
+type TaxService interface {
+	CreateRate(ctx context.Context, rate *domain.TaxRate, initialRate float64, effectiveFrom time.Time) error
+	GetRateByID(ctx context.Context, id uuid.UUID) (*domain.TaxRate, error)
+	ListRates(ctx context.Context, tenantID uuid.UUID) ([]*domain.TaxRate, error)
+	// ChangeRate schedules a new percentage from effectiveFrom onwards
+	ChangeRate(ctx context.Context, tenantID, rateID uuid.UUID, rate float64, effectiveFrom time.Time) error
+
+	CreateGroup(ctx context.Context, group *domain.TaxGroup) error
+	GetGroupByID(ctx context.Context, id uuid.UUID) (*domain.TaxGroup, error)
+	ListGroups(ctx context.Context, tenantID uuid.UUID) ([]*domain.TaxGroup, error)
+	UpdateGroup(ctx context.Context, tenantID uuid.UUID, group *domain.TaxGroup) error
+	DeleteGroup(ctx context.Context, tenantID, id uuid.UUID) error
+	// ValidateGroup checks that a group exists, is active and is visible to the tenant
+	ValidateGroup(ctx context.Context, tenantID, groupID uuid.UUID) error
+
+	// ResolveProductGroup returns the product's tax group, falling back to its category chain
+	ResolveProductGroup(ctx context.Context, product *domain.Product) (*uuid.UUID, error)
+	// ComputeProductTax computes tax on a tax-exclusive amount for a product on a date.
+	// Sales and purchase documents should use this instead of Product.TaxRate.
+	ComputeProductTax(ctx context.Context, product *domain.Product, amount float64, date time.Time) (*domain.TaxBreakdown, error)
+	ComputeGroupTax(ctx context.Context, groupID uuid.UUID, amount float64, date time.Time) (*domain.TaxBreakdown, error)
+}
+
+// HSCodeService defines the interface for HS commodity code lookup
+type HSCodeService interface {
+	Search(ctx context.Context, query string, limit int) ([]*domain.HSCode, error)
+	// Lookup validates the code format and returns the built-in entry, if any
+	Lookup(ctx context.Context, code string) (*domain.HSCode, error)
+}
+
+// QuickPickService defines the interface for per-user favorite and recently viewed products
+type QuickPickService interface {
+	RecordView(ctx context.Context, tenantID, userID, productID uuid.UUID) error
+	Pin(ctx context.Context, tenantID, userID, productID uuid.UUID, position int) error
+	Unpin(ctx context.Context, tenantID, userID, productID uuid.UUID) error
+	// List returns the POS quick-pick grid, served from cache when warm
+	List(ctx context.Context, tenantID, userID uuid.UUID) ([]*domain.ProductQuickPick, error)
+}
+
+// PricingService defines the interface for cost-based markup rules and repricing
+type PricingService interface {
+	CreateRule(ctx context.Context, rule *domain.MarkupRule) error
+	GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.MarkupRule, error)
+	ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.MarkupRule, error)
+	UpdateRule(ctx context.Context, rule *domain.MarkupRule) error
+	DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error
+	// ResolveRule returns the product's rule, falling back to its category chain
+	ResolveRule(ctx context.Context, product *domain.Product) (*domain.MarkupRule, error)
+
+	// Reprice recomputes a product's price; it is applied directly or queued for approval
+	Reprice(ctx context.Context, product *domain.Product, source domain.PriceChangeSource) (*domain.PriceChange, error)
+	ListChanges(ctx context.Context, tenantID uuid.UUID, status domain.PriceChangeStatus, limit, offset int) ([]*domain.PriceChange, error)
+	ApproveChange(ctx context.Context, tenantID, id uuid.UUID, reviewedBy *uuid.UUID) (*domain.PriceChange, error)
+	RejectChange(ctx context.Context, tenantID, id uuid.UUID, reviewedBy *uuid.UUID) (*domain.PriceChange, error)
+
+	// RunRepricing recomputes all of a tenant's products and returns the change report
+	RunRepricing(ctx context.Context, tenantID uuid.UUID, source domain.PriceChangeSource, startedBy *uuid.UUID) (*domain.RepricingRun, error)
+	// RunScheduledRepricing is the nightly job across all tenants with markup rules
+	RunScheduledRepricing(ctx context.Context) error
+	GetRun(ctx context.Context, tenantID, id uuid.UUID) (*domain.RepricingRun, error)
+	ListRuns(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.RepricingRun, error)
+
+	// BulkUpdatePrices adjusts the price of every product matching the filter in one
+	// transaction; with dryRun it only returns the before/after preview
+	BulkUpdatePrices(ctx context.Context, tenantID uuid.UUID, filter domain.BulkPriceFilter, adjustment domain.PriceAdjustment, appliedBy *uuid.UUID, dryRun bool) (*domain.BulkPriceUpdate, error)
+	// RollbackBulkUpdate restores the prices a bulk update changed, within domain.BulkPriceRollbackWindow
+	RollbackBulkUpdate(ctx context.Context, tenantID uuid.UUID, token string, rolledBackBy *uuid.UUID) (*domain.BulkPriceRollback, error)
+}
+
+// GuardrailService defines the interface for MRP compliance and sale-time discount limits
+type GuardrailService interface {
+	// CheckMRP rejects a selling price that exceeds the product's MRP once tax is added
+	CheckMRP(ctx context.Context, product *domain.Product) error
+	// MaxPriceUnderMRP returns the highest tax-exclusive price allowed by the MRP (false if no MRP)
+	MaxPriceUnderMRP(ctx context.Context, product *domain.Product) (float64, bool, error)
+	// CheckSale validates a sale price against MRP and the seller role's discount limit,
+	// redeeming the override if one is given
+	CheckSale(ctx context.Context, tenantID, productID uuid.UUID, salePrice float64, role string, overrideID *uuid.UUID) (*domain.SaleCheck, error)
+	// ApproveOverride records a one-time approval for a discount above the seller's limit
+	ApproveOverride(ctx context.Context, tenantID, productID uuid.UUID, salePrice float64, reason string, requestedBy, approvedBy *uuid.UUID, approverRole string) (*domain.DiscountOverride, error)
+	ListOverrides(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.DiscountOverride, error)
+
+	SetDiscountLimit(ctx context.Context, tenantID uuid.UUID, role string, maxDiscountPercent float64) (*domain.DiscountLimit, error)
+	ListDiscountLimits(ctx context.Context, tenantID uuid.UUID) ([]*domain.DiscountLimit, error)
+	DeleteDiscountLimit(ctx context.Context, tenantID uuid.UUID, role string) error
+}
+
+// BarcodeService defines the interface for scale barcode rules, pack barcodes and POS barcode lookup
+type BarcodeService interface {
+	CreateRule(ctx context.Context, rule *domain.BarcodeRule) error
+	GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.BarcodeRule, error)
+	ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.BarcodeRule, error)
+	UpdateRule(ctx context.Context, rule *domain.BarcodeRule) error
+	DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error
+	ListProductBarcodes(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.ProductBarcode, error)
+	// SaveProductBarcodes replaces the barcodes printed on a product's packs
+	SaveProductBarcodes(ctx context.Context, tenantID, productID uuid.UUID, barcodes []domain.ProductBarcode) error
+	// Lookup resolves a scan to a product, with the pack quantity for pack barcodes
+	// and weight or price parsed from scale labels
+	Lookup(ctx context.Context, tenantID uuid.UUID, barcode string) (*domain.BarcodeLookup, error)
+}
+
+// AssemblyService defines the interface for bills of materials and assembly orders
+type AssemblyService interface {
+	// SaveBOM replaces a product's bill of materials
+	SaveBOM(ctx context.Context, bom *domain.BillOfMaterials) error
+	GetBOM(ctx context.Context, tenantID, productID uuid.UUID) (*domain.BillOfMaterials, error)
+	DeleteBOM(ctx context.Context, tenantID, productID uuid.UUID) error
+
+	// PlanOrder costs an assembly of quantity units from component cost prices without saving it
+	PlanOrder(ctx context.Context, tenantID, productID uuid.UUID, quantity float64) (*domain.AssemblyOrder, error)
+	CreateOrder(ctx context.Context, tenantID, productID uuid.UUID, quantity float64, note *string, createdBy *uuid.UUID) (*domain.AssemblyOrder, error)
+	GetOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.AssemblyOrder, error)
+	ListOrders(ctx context.Context, tenantID uuid.UUID, status domain.AssemblyOrderStatus, limit, offset int) ([]*domain.AssemblyOrder, error)
+	// StartOrder consumes the components into work in progress
+	StartOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.AssemblyOrder, error)
+	// CompleteOrder produces the finished stock and sets the product's cost price to the rolled-up unit cost
+	CompleteOrder(ctx context.Context, tenantID, id uuid.UUID, producedQty, overheadCost float64) (*domain.AssemblyOrder, error)
+	CancelOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.AssemblyOrder, error)
+
+	SetStockLedger(stock AssemblyStock)
+	SetJournal(journal AssemblyJournal)
+}
+
+// AssemblyStock moves stock for assembly orders. Implemented by the inventory side and
+// set after Init; an error stops the order from changing status.
+type AssemblyStock interface {
+	// ConsumeComponents takes the order's components out of stock
+	ConsumeComponents(ctx context.Context, order *domain.AssemblyOrder) error
+	// ReceiveFinished puts the produced quantity into stock at the order's unit cost
+	ReceiveFinished(ctx context.Context, order *domain.AssemblyOrder) error
+}
+
+// AssemblyJournal books assembly orders in the general ledger: on start, raw materials
+// to work in progress; on completion, work in progress to finished goods. It returns
+// the journal entry ID it created.
+type AssemblyJournal interface {
+	PostAssembly(ctx context.Context, order *domain.AssemblyOrder, stage domain.AssemblyStage) (uuid.UUID, error)
+}
+
+// BinService defines the interface for shelf/bin locations, the stock held in them and pick lists
+type BinService interface {
+	CreateBin(ctx context.Context, bin *domain.Bin) error
+	GetBin(ctx context.Context, tenantID, id uuid.UUID) (*domain.Bin, error)
+	// ListBins returns the tenant's bins in pick order
+	ListBins(ctx context.Context, tenantID uuid.UUID) ([]*domain.Bin, error)
+	UpdateBin(ctx context.Context, bin *domain.Bin) error
+	// DeleteBin removes a bin that holds no stock
+	DeleteBin(ctx context.Context, tenantID, id uuid.UUID) error
+
+	ListBinStock(ctx context.Context, tenantID, binID uuid.UUID) ([]domain.BinStock, error)
+	// ListProductBins returns where a product is shelved, in pick order
+	ListProductBins(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.BinStock, error)
+	// SetBinQuantity records a shelf count or put-away of a product in a bin
+	SetBinQuantity(ctx context.Context, movement *domain.BinMovement, quantity float64) error
+	// Transfer moves a quantity of a product from one bin to another
+	Transfer(ctx context.Context, movement *domain.BinMovement) error
+	ListMovements(ctx context.Context, tenantID uuid.UUID, productID *uuid.UUID, limit, offset int) ([]*domain.BinMovement, error)
+	// PickList allocates items to the active bins holding them, ordered for one walk through the store;
+	// with a warehouse only that warehouse's bins are walked
+	PickList(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID, items []domain.PickItem) (*domain.PickList, error)
+
+	SetWarehouses(warehouses Warehouses)
+}
+
+// Warehouses places bins in the tenant's stock locations. The inventory side sets
+// its own after Init; until then bins are not placed in a warehouse.
+type Warehouses interface {
+	// ResolveWarehouse checks the tenant has the active warehouse, or returns the
+	// tenant's default warehouse when warehouseID is nil
+	ResolveWarehouse(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID) (uuid.UUID, error)
+}
+
+// ReservationService defines the interface for stock reservations and availability
+type ReservationService interface {
+	// Reserve holds stock for the reservations, all or none, returning ErrInsufficientStock
+	// when a product has less available than requested
+	Reserve(ctx context.Context, tenantID uuid.UUID, reservations []*domain.StockReservation) error
+	GetReservation(ctx context.Context, tenantID, id uuid.UUID) (*domain.StockReservation, error)
+	ListReservations(ctx context.Context, tenantID uuid.UUID, filter domain.ReservationFilter) ([]*domain.StockReservation, error)
+	Release(ctx context.Context, tenantID, id uuid.UUID, reason *string) (*domain.StockReservation, error)
+	// ReleaseReference releases a document's reservations, e.g. when an order is cancelled
+	ReleaseReference(ctx context.Context, tenantID uuid.UUID, referenceType domain.ReservationReference, referenceID uuid.UUID, reason *string) (int64, error)
+	// ConsumeReference closes a document's reservations once the order is fulfilled from the held stock
+	ConsumeReference(ctx context.Context, tenantID uuid.UUID, referenceType domain.ReservationReference, referenceID uuid.UUID) (int64, error)
+	// Availability returns on-hand minus reserved for each product
+	Availability(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) ([]domain.StockAvailability, error)
+	// Report lists the products with stock held, most reserved first
+	Report(ctx context.Context, tenantID uuid.UUID) ([]*domain.ReservationSummary, error)
+	// ExpireDue marks every tenant's lapsed reservations expired
+	ExpireDue(ctx context.Context) (int64, error)
+
+	SetStockLevels(levels StockLevels)
+}
+
+// StockLevels reports how much of each product is on hand. The inventory side sets
+// its own after Init; until then the quantities shelved in bins are used.
+type StockLevels interface {
+	OnHand(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]float64, error)
+}
+
+// DemandService defines the interface for sales velocity and demand forecasting
+type DemandService interface {
+	// RegisterSource adds a module's sold quantities, replacing a source of the same name
+	RegisterSource(source domain.DemandSource)
+	// Recompute rebuilds a tenant's statistics from the registered sources and returns how many products sold
+	Recompute(ctx context.Context, tenantID uuid.UUID) (int, error)
+	// RunScheduled recomputes the statistics of every tenant with products
+	RunScheduled(ctx context.Context) error
+	GetProductDemand(ctx context.Context, tenantID, productID uuid.UUID) (*domain.ProductDemand, error)
+	ListDemand(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.ProductDemand, error)
+	// Forecast projects a product's sales over the next days from its base rate and seasonality
+	Forecast(ctx context.Context, tenantID, productID uuid.UUID, days int) (*domain.DemandForecast, error)
+	// ReorderSuggestions lists products whose available stock will not last the query's horizon, soonest out first
+	ReorderSuggestions(ctx context.Context, tenantID uuid.UUID, query domain.ReorderQuery) ([]domain.ReorderSuggestion, error)
+}
+
+// AlertRuleService defines the interface for low-stock and expiry alert rules
+type AlertRuleService interface {
+	CreateRule(ctx context.Context, rule *domain.AlertRule) error
+	GetRule(ctx context.Context, tenantID, id uuid.UUID) (*domain.AlertRule, error)
+	ListRules(ctx context.Context, tenantID uuid.UUID) ([]*domain.AlertRule, error)
+	UpdateRule(ctx context.Context, rule *domain.AlertRule) error
+	DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error
+	ListAlerts(ctx context.Context, tenantID uuid.UUID, filter domain.AlertFilter) ([]*domain.StockAlert, error)
+	// Evaluate checks the tenant's active rules, notifying products that newly trip a
+	// rule or got worse; products already notified at the same severity are skipped
+	Evaluate(ctx context.Context, tenantID uuid.UUID) (*domain.AlertRun, error)
+	// RunScheduled evaluates every tenant with active rules
+	RunScheduled(ctx context.Context) error
+}
+
+// LocalizationService defines the interface for product and category translations
+// and the language documents are printed in
+type LocalizationService interface {
+	// SaveTranslation returns ErrProductNotFound or ErrCategoryNotFound if the tenant has no such entity
+	SaveTranslation(ctx context.Context, translation *domain.Translation) error
+	DeleteTranslation(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, entityID uuid.UUID, locale string) error
+	ListTranslations(ctx context.Context, tenantID uuid.UUID, entityType domain.TranslationEntity, entityID uuid.UUID) ([]*domain.Translation, error)
+	// LocalizeProducts shows each product in the first of the locales it is translated
+	// into; products with none keep the catalog's own name and description
+	LocalizeProducts(ctx context.Context, tenantID uuid.UUID, locales []string, products ...*domain.Product) error
+	// LocalizeCategories does the same for categories
+	LocalizeCategories(ctx context.Context, tenantID uuid.UUID, locales []string, categories ...*domain.Category) error
+
+	SaveDocumentLocale(ctx context.Context, setting *domain.DocumentLocale) error
+	DeleteDocumentLocale(ctx context.Context, tenantID uuid.UUID, documentType string) error
+	ListDocumentLocales(ctx context.Context, tenantID uuid.UUID) ([]*domain.DocumentLocale, error)
+	// DocumentLocale returns the locale a document type is printed in, or empty for the catalog's own names
+	DocumentLocale(ctx context.Context, tenantID uuid.UUID, documentType string) (string, error)
+}
+
+// VariantService defines the interface for generating and managing a product's variants
+type VariantService interface {
+	// Generate creates a variant product for each combination of the matrix's values
+	// the product has no variant for yet, copying the product with the combination's
+	// SKU, name and price deltas. A failure part way leaves the variants created so
+	// far; generating again creates the rest.
+	Generate(ctx context.Context, tenantID, productID uuid.UUID, matrix domain.VariantMatrix) (*domain.VariantGeneration, error)
+	// List returns a product's variants with their products
+	List(ctx context.Context, tenantID, productID uuid.UUID) ([]*domain.ProductVariant, error)
+	// SetActive activates or deactivates the given variants of a product, or all of
+	// them if none are given, and returns them; ErrVariantNotFound if one is not the product's
+	SetActive(ctx context.Context, tenantID, productID uuid.UUID, variantIDs []uuid.UUID, active bool) ([]*domain.ProductVariant, error)
 }
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/google/uuid"
+)
+
+// maxCategoryDepth bounds the walk up the category tree when resolving taxes
+const maxCategoryDepth = 10
+
+// taxService implements TaxService
+type taxService struct {
+	repo         repository.TaxRepository
+	categoryRepo repository.CategoryRepository
+}
+
+// NewTaxService creates a new tax service
+func NewTaxService(repo repository.TaxRepository, categoryRepo repository.CategoryRepository) TaxService {
+	return &taxService{
+		repo:         repo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// CreateRate creates a tenant tax rate with its first effective percentage
+func (s *taxService) CreateRate(ctx context.Context, rate *domain.TaxRate, initialRate float64, effectiveFrom time.Time) error {
+	if rate.Code == "" {
+		return fmt.Errorf("tax code is required")
+	}
+	if !domain.IsValidTaxKind(rate.Kind) {
+		return fmt.Errorf("invalid tax kind: %s", rate.Kind)
+	}
+	if err := rate.ValidateRate(initialRate); err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateRate(ctx, rate); err != nil {
+		return fmt.Errorf("failed to create tax rate: %w", err)
+	}
+
+	version := domain.NewTaxRateVersion(rate.ID, initialRate, effectiveFrom)
+	if err := s.repo.AddRateVersion(ctx, version); err != nil {
+		return err
+	}
+	rate.Versions = []*domain.TaxRateVersion{version}
+
+	return nil
+}
+
+// GetRateByID retrieves a tax rate with its versions
+func (s *taxService) GetRateByID(ctx context.Context, id uuid.UUID) (*domain.TaxRate, error) {
+	return s.repo.GetRateByID(ctx, id)
+}
+
+// ListRates returns all tax rates available to a tenant
+func (s *taxService) ListRates(ctx context.Context, tenantID uuid.UUID) ([]*domain.TaxRate, error) {
+	return s.repo.ListRates(ctx, tenantID)
+}
+
+// ChangeRate schedules a new percentage for a tenant tax rate
+func (s *taxService) ChangeRate(ctx context.Context, tenantID, rateID uuid.UUID, rate float64, effectiveFrom time.Time) error {
+	taxRate, err := s.repo.GetRateByID(ctx, rateID)
+	if err != nil {
+		return err
+	}
+	if !taxRate.IsEditableBy(tenantID) {
+		return domain.ErrSystemTaxReadOnly
+	}
+	if err := taxRate.ValidateRate(rate); err != nil {
+		return err
+	}
+
+	version := domain.NewTaxRateVersion(taxRate.ID, rate, effectiveFrom)
+	for _, v := range taxRate.Versions {
+		if v.EffectiveFrom.Equal(version.EffectiveFrom) {
+			return fmt.Errorf("a rate is already scheduled from %s", version.EffectiveFrom.Format("2006-01-02"))
+		}
+	}
+
+	return s.repo.AddRateVersion(ctx, version)
+}
+
+// CreateGroup creates a tenant tax group
+func (s *taxService) CreateGroup(ctx context.Context, group *domain.TaxGroup) error {
+	if group.Code == "" {
+		return fmt.Errorf("tax group code is required")
+	}
+	if err := s.validateComponents(ctx, *group.TenantID, group.Components); err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateGroup(ctx, group); err != nil {
+		return fmt.Errorf("failed to create tax group: %w", err)
+	}
+
+	return nil
+}
+
+// GetGroupByID retrieves a tax group with its components
+func (s *taxService) GetGroupByID(ctx context.Context, id uuid.UUID) (*domain.TaxGroup, error) {
+	return s.repo.GetGroupByID(ctx, id)
+}
+
+// ListGroups returns all tax groups available to a tenant
+func (s *taxService) ListGroups(ctx context.Context, tenantID uuid.UUID) ([]*domain.TaxGroup, error) {
+	return s.repo.ListGroups(ctx, tenantID)
+}
+
+// UpdateGroup updates a tenant tax group
+func (s *taxService) UpdateGroup(ctx context.Context, tenantID uuid.UUID, group *domain.TaxGroup) error {
+	if !group.IsEditableBy(tenantID) {
+		return domain.ErrSystemTaxReadOnly
+	}
+	if err := s.validateComponents(ctx, tenantID, group.Components); err != nil {
+		return err
+	}
+
+	group.UpdatedAt = time.Now()
+	return s.repo.UpdateGroup(ctx, group)
+}
+
+// DeleteGroup deletes a tenant tax group that is not assigned anywhere
+func (s *taxService) DeleteGroup(ctx context.Context, tenantID, id uuid.UUID) error {
+	group, err := s.repo.GetGroupByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !group.IsEditableBy(tenantID) {
+		return domain.ErrSystemTaxReadOnly
+	}
+
+	inUse, err := s.repo.CountGroupAssignments(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inUse > 0 {
+		return fmt.Errorf("tax group %s is assigned to %d products or categories", group.Code, inUse)
+	}
+
+	return s.repo.DeleteGroup(ctx, id)
+}
+
+// ValidateGroup checks that a group can be assigned by the tenant
+func (s *taxService) ValidateGroup(ctx context.Context, tenantID, groupID uuid.UUID) error {
+	group, err := s.repo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group.TenantID != nil && *group.TenantID != tenantID {
+		return domain.ErrUnknownTax
+	}
+	if !group.IsActive {
+		return fmt.Errorf("%w: tax group %s is inactive", domain.ErrUnknownTax, group.Code)
+	}
+	return nil
+}
+
+// ResolveProductGroup returns the tax group that applies to a product
+func (s *taxService) ResolveProductGroup(ctx context.Context, product *domain.Product) (*uuid.UUID, error) {
+	if product.TaxGroupID != nil {
+		return product.TaxGroupID, nil
+	}
+
+	categoryID := &product.CategoryID
+	for depth := 0; categoryID != nil && depth < maxCategoryDepth; depth++ {
+		category, err := s.categoryRepo.GetByID(ctx, product.TenantID, *categoryID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve category tax group: %w", err)
+		}
+		if category.TaxGroupID != nil {
+			return category.TaxGroupID, nil
+		}
+		categoryID = category.ParentID
+	}
+
+	return nil, nil
+}
+
+// ComputeProductTax computes tax for a product, falling back to the legacy flat rate
+func (s *taxService) ComputeProductTax(ctx context.Context, product *domain.Product, amount float64, date time.Time) (*domain.TaxBreakdown, error) {
+	groupID, err := s.ResolveProductGroup(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+	if groupID == nil {
+		return domain.ComputeFlatTax(amount, product.TaxRate), nil
+	}
+
+	return s.ComputeGroupTax(ctx, *groupID, amount, date)
+}
+
+// ComputeGroupTax computes tax for a tax group on a date
+func (s *taxService) ComputeGroupTax(ctx context.Context, groupID uuid.UUID, amount float64, date time.Time) (*domain.TaxBreakdown, error) {
+	components, err := s.repo.GetEffectiveComponents(ctx, groupID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := domain.ComputeTax(amount, components)
+	breakdown.TaxGroupID = &groupID
+	return breakdown, nil
+}
+
+// validateComponents checks that every component references a tax visible to the tenant
+func (s *taxService) validateComponents(ctx context.Context, tenantID uuid.UUID, components []*domain.TaxGroupComponent) error {
+	if len(components) == 0 {
+		return fmt.Errorf("tax group must have at least one tax rate")
+	}
+
+	seen := make(map[uuid.UUID]bool, len(components))
+	for _, c := range components {
+		if seen[c.TaxRateID] {
+			return fmt.Errorf("tax rate %s is listed more than once", c.TaxRateID)
+		}
+		seen[c.TaxRateID] = true
+
+		rate, err := s.repo.GetRateByID(ctx, c.TaxRateID)
+		if err != nil {
+			if errors.Is(err, domain.ErrUnknownTax) {
+				return fmt.Errorf("%w: tax rate %s", domain.ErrUnknownTax, c.TaxRateID)
+			}
+			return err
+		}
+		if rate.TenantID != nil && *rate.TenantID != tenantID {
+			return fmt.Errorf("%w: tax rate %s", domain.ErrUnknownTax, c.TaxRateID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/google/uuid"
+)
+
+// unitService implements UnitService
+type unitService struct {
+	repo repository.UnitRepository
+}
+
+// NewUnitService creates a new unit service
+func NewUnitService(repo repository.UnitRepository) UnitService {
+	return &unitService{
+		repo: repo,
+	}
+}
+
+// Create creates a new tenant-specific unit
+func (s *unitService) Create(ctx context.Context, unit *domain.UnitOfMeasure) error {
+	if unit.Code == "" {
+		return fmt.Errorf("unit code is required")
+	}
+	if unit.DecimalPlaces < 0 || unit.DecimalPlaces > 6 {
+		return fmt.Errorf("decimal places must be between 0 and 6")
+	}
+
+	// Reject codes that already resolve for this tenant (including system units)
+	existing, err := s.repo.GetByCode(ctx, *unit.TenantID, unit.Code)
+	if err != nil && !errors.Is(err, domain.ErrUnknownUnit) {
+		return fmt.Errorf("failed to check unit code: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("unit %s already exists", unit.Code)
+	}
+
+	if err := s.repo.Create(ctx, unit); err != nil {
+		return fmt.Errorf("failed to create unit: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a unit by ID
+func (s *unitService) GetByID(ctx context.Context, id uuid.UUID) (*domain.UnitOfMeasure, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns all units available to a tenant
+func (s *unitService) List(ctx context.Context, tenantID uuid.UUID) ([]*domain.UnitOfMeasure, error) {
+	return s.repo.List(ctx, tenantID)
+}
+
+// Update updates a tenant-specific unit
+func (s *unitService) Update(ctx context.Context, tenantID uuid.UUID, unit *domain.UnitOfMeasure) error {
+	if !unit.IsEditableBy(tenantID) {
+		return domain.ErrSystemUnitReadOnly
+	}
+	if unit.DecimalPlaces < 0 || unit.DecimalPlaces > 6 {
+		return fmt.Errorf("decimal places must be between 0 and 6")
+	}
+
+	unit.UpdatedAt = time.Now()
+	return s.repo.Update(ctx, unit)
+}
+
+// Delete deletes a tenant-specific unit that is not used by any product
+func (s *unitService) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	unit, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !unit.IsEditableBy(tenantID) {
+		return domain.ErrSystemUnitReadOnly
+	}
+
+	inUse, err := s.repo.CountProductsUsing(ctx, tenantID, unit.Code)
+	if err != nil {
+		return err
+	}
+	if inUse > 0 {
+		return fmt.Errorf("unit %s is used by %d products", unit.Code, inUse)
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// Resolve normalizes a free-text unit and looks it up in the registry
+func (s *unitService) Resolve(ctx context.Context, tenantID uuid.UUID, code string) (*domain.UnitOfMeasure, error) {
+	normalized := domain.NormalizeUnitCode(code)
+	if normalized == "" {
+		return nil, domain.ErrUnknownUnit
+	}
+
+	unit, err := s.repo.GetByCode(ctx, tenantID, normalized)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownUnit) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownUnit, code)
+		}
+		return nil, err
+	}
+	if !unit.IsActive {
+		return nil, fmt.Errorf("%w: %s is inactive", domain.ErrUnknownUnit, code)
+	}
+
+	return unit, nil
+}
+
+// ValidateQuantity checks a quantity against the unit's precision rules
+func (s *unitService) ValidateQuantity(ctx context.Context, tenantID uuid.UUID, code string, quantity float64) error {
+	unit, err := s.Resolve(ctx, tenantID, code)
+	if err != nil {
+		return err
+	}
+	return unit.ValidateQuantity(quantity)
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/catalog/domain"
+	"github.com/aceextension/catalog/repository"
+	"github.com/google/uuid"
+)
+
+// variantService implements VariantService
+type variantService struct {
+	repo     repository.VariantRepository
+	products ProductService
+}
+
+// NewVariantService creates a new variant service. Variants are created and updated
+// through the product service, so they are validated and announced like any product.
+func NewVariantService(repo repository.VariantRepository, products ProductService) VariantService {
+	return &variantService{repo: repo, products: products}
+}
+
+// Generate creates the matrix's missing variants of a product
+func (s *variantService) Generate(ctx context.Context, tenantID, productID uuid.UUID, matrix domain.VariantMatrix) (*domain.VariantGeneration, error) {
+	parent, err := s.products.GetByID(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	link, err := s.repo.GetByProduct(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if link != nil {
+		return nil, domain.ErrNestedVariant
+	}
+	if err := matrix.Normalize(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.ListByParent(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(existing))
+	for _, v := range existing {
+		keys[v.Key()] = true
+	}
+
+	// Build and check every new variant before creating any
+	generation := &domain.VariantGeneration{Created: []*domain.ProductVariant{}}
+	var variants []*domain.ProductVariant
+	skus := map[string]bool{}
+	for _, combination := range matrix.Combinations() {
+		if keys[combination.Key()] {
+			generation.Skipped++
+			continue
+		}
+
+		product := newVariantProduct(parent, combination, matrix)
+		if product.SellingPrice <= 0 {
+			return nil, fmt.Errorf("%w: %s would sell at %.2f", domain.ErrInvalidVariantMatrix, product.Name, product.SellingPrice)
+		}
+		if product.MRP != nil && *product.MRP <= 0 {
+			return nil, fmt.Errorf("%w: %s would have an MRP of %.2f", domain.ErrInvalidVariantMatrix, product.Name, *product.MRP)
+		}
+		if skus[*product.SKU] {
+			return nil, fmt.Errorf("%w: SKU %s is generated twice", domain.ErrInvalidVariantMatrix, *product.SKU)
+		}
+		skus[*product.SKU] = true
+		if _, err := s.products.GetBySKU(ctx, tenantID, *product.SKU); err == nil {
+			return nil, fmt.Errorf("%w: SKU %s is already in use", domain.ErrInvalidVariantMatrix, *product.SKU)
+		} else if !errors.Is(err, domain.ErrProductNotFound) {
+			return nil, err
+		}
+
+		variants = append(variants, domain.NewProductVariant(parent, product, combination))
+	}
+	if len(existing)+len(variants) > domain.MaxVariants {
+		return nil, fmt.Errorf("%w: the product has %d variants, at most %d", domain.ErrInvalidVariantMatrix, len(existing), domain.MaxVariants)
+	}
+	// Every variant is a product of its own
+	if err := s.products.CheckQuota(ctx, tenantID, len(variants)); err != nil {
+		return nil, err
+	}
+
+	for _, v := range variants {
+		if err := s.products.Create(ctx, v.Product); err != nil {
+			return generation, fmt.Errorf("failed to create variant %s: %w", v.Product.Name, err)
+		}
+		if err := s.repo.Create(ctx, v); err != nil {
+			return generation, err
+		}
+		generation.Created = append(generation.Created, v)
+	}
+
+	auditVariantGeneration(ctx, parent, &matrix, generation)
+	return generation, nil
+}
+
+// newVariantProduct copies the parent for a combination of its values
+func newVariantProduct(parent *domain.Product, combination domain.VariantCombination, matrix domain.VariantMatrix) *domain.Product {
+	sku := parent.ProductCode
+	if parent.SKU != nil && strings.TrimSpace(*parent.SKU) != "" {
+		sku = strings.TrimSpace(*parent.SKU)
+	}
+	sku = combination.SKU(matrix.SKUPattern, sku)
+
+	product := domain.NewProduct(parent.TenantID, parent.CategoryID, combination.Name(parent.Name), parent.SellingPrice+combination.PriceDelta)
+	product.Description = parent.Description
+	product.CostPrice = parent.CostPrice
+	product.TaxRate = parent.TaxRate
+	product.TaxGroupID = parent.TaxGroupID
+	product.HSCode = parent.HSCode
+	product.Unit = parent.Unit
+	product.SKU = &sku
+	if parent.MRP != nil {
+		mrp := *parent.MRP + combination.PriceDelta
+		product.MRP = &mrp
+	}
+	for key, value := range parent.CustomAttributes {
+		product.CustomAttributes[key] = value
+	}
+	if !matrix.Activate {
+		product.IsActive = false
+		product.Status = domain.ProductStatusInactive
+	}
+	return product
+}
+
+// List returns a product's variants with their products
+func (s *variantService) List(ctx context.Context, tenantID, productID uuid.UUID) ([]*domain.ProductVariant, error) {
+	if _, err := s.products.GetByID(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+	variants, err := s.repo.ListByParent(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range variants {
+		if v.Product, err = s.products.GetByID(ctx, tenantID, v.ProductID); err != nil {
+			return nil, err
+		}
+	}
+	return variants, nil
+}
+
+// SetActive activates or deactivates a product's variants
+func (s *variantService) SetActive(ctx context.Context, tenantID, productID uuid.UUID, variantIDs []uuid.UUID, active bool) ([]*domain.ProductVariant, error) {
+	variants, err := s.List(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(variantIDs) > 0 {
+		byID := make(map[uuid.UUID]*domain.ProductVariant, len(variants))
+		for _, v := range variants {
+			byID[v.ProductID] = v
+		}
+		selected := make([]*domain.ProductVariant, 0, len(variantIDs))
+		for _, id := range variantIDs {
+			v, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", domain.ErrVariantNotFound, id)
+			}
+			selected = append(selected, v)
+		}
+		variants = selected
+	}
+
+	status := domain.ProductStatusInactive
+	if active {
+		status = domain.ProductStatusActive
+	}
+	for _, v := range variants {
+		if v.Product.IsActive == active && v.Product.Status == status {
+			continue
+		}
+		v.Product.IsActive = active
+		v.Product.Status = status
+		v.Product.UpdatedAt = time.Now()
+		if err := s.products.Update(ctx, v.Product); err != nil {
+			return nil, fmt.Errorf("failed to update variant %s: %w", v.Product.Name, err)
+		}
+	}
+	return variants, nil
+}
+
+// auditVariantGeneration logs the variants generated from a product's matrix
+func auditVariantGeneration(ctx context.Context, parent *domain.Product, matrix *domain.VariantMatrix, generation *domain.VariantGeneration) {
+	if audit.Service == nil || len(generation.Created) == 0 {
+		return
+	}
+	axes := make([]string, len(matrix.Axes))
+	for i, axis := range matrix.Axes {
+		axes[i] = axis.Name
+	}
+
+	entityIDStr := parent.ID.String()
+	audit.Service.Log(ctx, "GENERATE_VARIANTS", "Product", &entityIDStr, map[string]interface{}{
+		"product_code": parent.ProductCode,
+		"axes":         axes,
+		"sku_pattern":  matrix.SKUPattern,
+		"created":      len(generation.Created),
+		"skipped":      generation.Skipped,
+		"activated":    matrix.Activate,
+	}, &auditDomain.AuditContext{TenantID: &parent.TenantID}) // The user is the request's
+}
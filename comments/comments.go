@@ -0,0 +1,18 @@
+package comments
+
+import (
+	"github.com/aceextension/comments/repository"
+	"github.com/aceextension/comments/service"
+)
+
+// Module-level service instances
+var (
+	CommentService service.CommentService
+)
+
+// Init initializes the comments module.
+// Modules owning records (crm customers and suppliers, catalog products, sales invoices)
+// register their entity types through CommentService.RegisterEntityType.
+func Init() {
+	CommentService = service.NewCommentService(repository.NewPostgresCommentRepository())
+}
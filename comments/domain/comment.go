@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Entity types that can be commented on
+const (
+	EntityCustomer = "customer"
+	EntitySupplier = "supplier"
+	EntityProduct  = "product"
+	EntityInvoice  = "invoice"
+)
+
+// MaxCommentLength bounds a comment body in characters
+const MaxCommentLength = 5000
+
+// MaxMentions bounds how many users one comment may mention
+const MaxMentions = 20
+
+// MaxFeedPageSize bounds one page of an entity's comment feed
+const MaxFeedPageSize = 100
+
+var (
+	// ErrUnknownEntityType is returned for entity types no module has registered
+	ErrUnknownEntityType = errors.New("comments are not supported for this entity type")
+	// ErrEntityNotFound is returned when the commented record does not exist for the tenant
+	ErrEntityNotFound = errors.New("entity not found")
+	// ErrCommentNotFound is returned when a comment does not exist or was deleted
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrInvalidComment is returned for an empty or over-long body, or too many mentions
+	ErrInvalidComment = errors.New("invalid comment")
+	// ErrNotCommentAuthor is returned when someone other than the author edits a comment,
+	// or someone other than the author or an owner/admin deletes it
+	ErrNotCommentAuthor = errors.New("only the author can change this comment")
+)
+
+// mentionPattern matches the mention markup inserted by the user picker:
+// @[Display Name](user-id)
+var mentionPattern = regexp.MustCompile(`@\[([^\[\]\n]{1,100})\]\(([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})\)`)
+
+// Comment is a note a team member leaves on a record, such as an invoice or a customer
+type Comment struct {
+	ID         uuid.UUID
+	TenantID   uuid.UUID
+	EntityType string
+	EntityID   uuid.UUID
+	AuthorID   uuid.UUID
+	AuthorName string // Filled by feed queries
+	Body       string // Raw text, with mentions as @[Name](user-id)
+	Mentions   []uuid.UUID
+	EditedAt   *time.Time
+	DeletedAt  *time.Time
+	DeletedBy  *uuid.UUID
+
+	// Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewComment creates a comment with its mentions parsed from the body
+func NewComment(tenantID uuid.UUID, entityType string, entityID, authorID uuid.UUID, body string) *Comment {
+	now := time.Now()
+	body = strings.TrimSpace(body)
+	return &Comment{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		AuthorID:   authorID,
+		Body:       body,
+		Mentions:   ParseMentions(body),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// Edit replaces the body and re-parses its mentions
+func (c *Comment) Edit(body string) {
+	now := time.Now()
+	c.Body = strings.TrimSpace(body)
+	c.Mentions = ParseMentions(c.Body)
+	c.EditedAt = &now
+	c.UpdatedAt = now
+}
+
+// Validate checks the body length and mention count
+func (c *Comment) Validate() error {
+	if c.Body == "" || utf8.RuneCountInString(c.Body) > MaxCommentLength {
+		return errors.Join(ErrInvalidComment, errors.New("body must be 1 to 5000 characters"))
+	}
+	if len(c.Mentions) > MaxMentions {
+		return errors.Join(ErrInvalidComment, errors.New("a comment may mention at most 20 users"))
+	}
+	return nil
+}
+
+// PlainText renders the body with mentions as @Name, for notifications
+func (c *Comment) PlainText() string {
+	return mentionPattern.ReplaceAllString(c.Body, "@$1")
+}
+
+// NewMentions returns the users mentioned now who were not mentioned in previous
+func (c *Comment) NewMentions(previous []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(previous))
+	for _, id := range previous {
+		seen[id] = true
+	}
+	added := []uuid.UUID{}
+	for _, id := range c.Mentions {
+		if !seen[id] {
+			added = append(added, id)
+		}
+	}
+	return added
+}
+
+// ParseMentions returns the distinct user IDs mentioned in a body, in order of appearance
+func ParseMentions(body string) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool)
+	mentions := []uuid.UUID{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		id, err := uuid.Parse(match[2])
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		mentions = append(mentions, id)
+	}
+	return mentions
+}
+
+// TenantUser is a member of the tenant who can be mentioned
+type TenantUser struct {
+	ID    uuid.UUID
+	Name  string
+	Email string
+}
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/comments/domain"
+	"github.com/aceextension/comments/service"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type CommentHandler struct {
+	service service.CommentService
+}
+
+func NewCommentHandler(service service.CommentService) *CommentHandler {
+	return &CommentHandler{service: service}
+}
+
+// CommentRequest is the body for posting or editing a comment. Mentions are written
+// as @[Name](user-id), as inserted by the user picker.
+type CommentRequest struct {
+	Body string `json:"body"`
+}
+
+// CommentResponse is the API representation of a comment
+type CommentResponse struct {
+	ID         uuid.UUID   `json:"id"`
+	EntityType string      `json:"entityType"`
+	EntityID   uuid.UUID   `json:"entityId"`
+	AuthorID   uuid.UUID   `json:"authorId"`
+	AuthorName string      `json:"authorName"`
+	Body       string      `json:"body"`
+	Mentions   []uuid.UUID `json:"mentions"`
+	EditedAt   *string     `json:"editedAt"`
+	CreatedAt  string      `json:"createdAt"`
+}
+
+// CommentFeedResponse is one page of a record's comments
+type CommentFeedResponse struct {
+	Comments []CommentResponse `json:"comments"`
+	Total    int               `json:"total"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+}
+
+func toCommentResponse(c *domain.Comment) CommentResponse {
+	response := CommentResponse{
+		ID:         c.ID,
+		EntityType: c.EntityType,
+		EntityID:   c.EntityID,
+		AuthorID:   c.AuthorID,
+		AuthorName: c.AuthorName,
+		Body:       c.Body,
+		Mentions:   c.Mentions,
+		CreatedAt:  c.CreatedAt.Format(time.RFC3339),
+	}
+	if c.EditedAt != nil {
+		editedAt := c.EditedAt.Format(time.RFC3339)
+		response.EditedAt = &editedAt
+	}
+	return response
+}
+
+// ListComments returns a record's comment feed
+// @Summary List Comments
+// @Description A record's comments, oldest first
+// @Tags Comments
+// @Produce json
+// @Param entityType path string true "Entity type (invoice, customer, supplier, product)"
+// @Param entityId path string true "Record ID"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} CommentFeedResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/comments/{entityType}/{entityId} [get]
+func (h *CommentHandler) ListComments(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	entityID, err := uuid.Parse(c.Param("entityId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid entity ID"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > domain.MaxFeedPageSize {
+		limit = domain.MaxFeedPageSize
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	feed, err := h.service.Feed(c.Request().Context(), tenantID, c.Param("entityType"), entityID, limit, offset)
+	if err != nil {
+		return commentError(c, err)
+	}
+
+	response := CommentFeedResponse{
+		Comments: make([]CommentResponse, 0, len(feed.Comments)),
+		Total:    feed.Total,
+		Limit:    limit,
+		Offset:   offset,
+	}
+	for _, comment := range feed.Comments {
+		response.Comments = append(response.Comments, toCommentResponse(comment))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// CreateComment posts a comment on a record
+// @Summary Post Comment
+// @Description Comment on a record; users mentioned as @[Name](user-id) are notified in-app and by email
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param entityType path string true "Entity type (invoice, customer, supplier, product)"
+// @Param entityId path string true "Record ID"
+// @Param request body CommentRequest true "Comment"
+// @Success 201 {object} CommentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/comments/{entityType}/{entityId} [post]
+func (h *CommentHandler) CreateComment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	entityID, err := uuid.Parse(c.Param("entityId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid entity ID"})
+	}
+
+	var req CommentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	comment, err := h.service.Create(c.Request().Context(), service.CommentInput{
+		TenantID:   tenantID,
+		AuthorID:   userID,
+		EntityType: c.Param("entityType"),
+		EntityID:   entityID,
+		Body:       req.Body,
+	})
+	if err != nil {
+		return commentError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toCommentResponse(comment))
+}
+
+// UpdateComment edits the caller's comment
+// @Summary Edit Comment
+// @Description Edit your own comment; users newly mentioned are notified
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param request body CommentRequest true "Comment"
+// @Success 200 {object} CommentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/comments/{id} [put]
+func (h *CommentHandler) UpdateComment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid comment ID"})
+	}
+
+	var req CommentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	comment, err := h.service.Update(c.Request().Context(), tenantID, id, userID, req.Body)
+	if err != nil {
+		return commentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toCommentResponse(comment))
+}
+
+// DeleteComment removes a comment from the feed
+// @Summary Delete Comment
+// @Description Delete your own comment; owners and admins may delete any comment
+// @Tags Comments
+// @Param id path string true "Comment ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/comments/{id} [delete]
+func (h *CommentHandler) DeleteComment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid comment ID"})
+	}
+
+	role, _ := db.GetRole(c.Request().Context())
+	moderate := role == "owner" || role == "admin"
+
+	if err := h.service.Delete(c.Request().Context(), tenantID, id, userID, moderate); err != nil {
+		return commentError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// commentError maps comment errors to HTTP statuses
+func commentError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrUnknownEntityType), errors.Is(err, domain.ErrInvalidComment):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrCommentNotFound), errors.Is(err, domain.ErrEntityNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotCommentAuthor):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(e *echo.Group, commentHandler *CommentHandler) {
+	commentsGroup := e.Group("/comments")
+
+	// Per-record feed
+	commentsGroup.GET("/:entityType/:entityId", commentHandler.ListComments)
+	commentsGroup.POST("/:entityType/:entityId", commentHandler.CreateComment)
+
+	// Edit and delete
+	commentsGroup.PUT("/:id", commentHandler.UpdateComment)
+	commentsGroup.DELETE("/:id", commentHandler.DeleteComment)
+}
@@ -0,0 +1,60 @@
+-- ============================================================================
+-- COMMENTS
+-- Team discussion on any record (invoices, customers, suppliers, products),
+-- with @mentions of tenant users.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    entity_type VARCHAR(50) NOT NULL, -- invoice, customer, supplier, product
+    entity_id UUID NOT NULL,
+    author_id UUID NOT NULL,
+    body TEXT NOT NULL, -- mentions are stored as @[Name](user-id)
+    edited_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ, -- hidden from the feed; kept for audit
+    deleted_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT fk_comment_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+-- A record's feed
+CREATE INDEX IF NOT EXISTS idx_comments_entity
+    ON comments(tenant_id, entity_type, entity_id, created_at)
+    WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    position INT NOT NULL, -- order of appearance in the body
+
+    PRIMARY KEY (comment_id, user_id)
+);
+
+-- Comments a user was mentioned in
+CREATE INDEX IF NOT EXISTS idx_comment_mentions_user
+    ON comment_mentions(tenant_id, user_id);
+
+-- ============================================================================
+-- ROW LEVEL SECURITY
+-- ============================================================================
+
+ALTER TABLE comments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE comment_mentions ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON comments;
+CREATE POLICY tenant_isolation ON comments
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+DROP POLICY IF EXISTS tenant_isolation ON comment_mentions;
+CREATE POLICY tenant_isolation ON comment_mentions
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
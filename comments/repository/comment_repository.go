@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/comments/domain"
+	"github.com/google/uuid"
+)
+
+// CommentRepository defines the interface for comment access
+type CommentRepository interface {
+	// Create stores a comment with its mentions
+	Create(ctx context.Context, comment *domain.Comment) error
+	// GetByID retrieves a live comment of the tenant
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Comment, error)
+	// ListByEntity returns a page of a record's live comments, oldest first, with the total count
+	ListByEntity(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID, limit, offset int) ([]*domain.Comment, int, error)
+	// Update saves an edited body and replaces its mentions
+	Update(ctx context.Context, comment *domain.Comment) error
+	// SoftDelete hides a comment from the feed; the row is kept for audit
+	SoftDelete(ctx context.Context, tenantID, id, deletedBy uuid.UUID) error
+
+	// TenantUsers returns the active members of the tenant among the given users
+	TenantUsers(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) ([]*domain.TenantUser, error)
+}
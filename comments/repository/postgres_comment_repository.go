@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/comments/domain"
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresCommentRepository implements CommentRepository using PostgreSQL
+type PostgresCommentRepository struct{}
+
+// NewPostgresCommentRepository creates a new PostgreSQL comment repository
+func NewPostgresCommentRepository() *PostgresCommentRepository {
+	return &PostgresCommentRepository{}
+}
+
+// commentSelect reads comments with the author's name and the mentioned user IDs
+const commentSelect = `
+	SELECT c.id, c.tenant_id, c.entity_type, c.entity_id, c.author_id, COALESCE(u.name, ''),
+		c.body, c.edited_at, c.deleted_at, c.deleted_by, c.created_at, c.updated_at,
+		ARRAY(SELECT m.user_id::text FROM comment_mentions m WHERE m.comment_id = c.id ORDER BY m.position)
+	FROM comments c
+	LEFT JOIN users u ON u.id = c.author_id`
+
+// Create stores a comment and its mentions in one transaction
+func (r *PostgresCommentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO comments (id, tenant_id, entity_type, entity_id, author_id, body, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`
+		_, err := tx.Exec(ctx, query,
+			comment.ID, comment.TenantID, comment.EntityType, comment.EntityID, comment.AuthorID,
+			comment.Body, comment.CreatedAt, comment.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create comment: %w", err)
+		}
+
+		return r.saveMentions(ctx, tx, comment)
+	})
+}
+
+// GetByID retrieves a live comment
+func (r *PostgresCommentRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Comment, error) {
+	query := commentSelect + ` WHERE c.id = $1 AND c.tenant_id = $2 AND c.deleted_at IS NULL`
+
+	comment, err := scanComment(db.MainPool.QueryRow(ctx, query, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	return comment, nil
+}
+
+// ListByEntity retrieves a page of a record's comment feed
+func (r *PostgresCommentRepository) ListByEntity(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID, limit, offset int) ([]*domain.Comment, int, error) {
+	var total int
+	countQuery := `
+		SELECT COUNT(*) FROM comments
+		WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3 AND deleted_at IS NULL
+	`
+	if err := db.MainPool.QueryRow(ctx, countQuery, tenantID, entityType, entityID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count comments: %w", err)
+	}
+
+	query := commentSelect + `
+		WHERE c.tenant_id = $1 AND c.entity_type = $2 AND c.entity_id = $3 AND c.deleted_at IS NULL
+		ORDER BY c.created_at, c.id
+		LIMIT $4 OFFSET $5
+	`
+	rows, err := db.MainPool.Query(ctx, query, tenantID, entityType, entityID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []*domain.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+	return comments, total, rows.Err()
+}
+
+// Update saves the body and replaces the mentions in one transaction
+func (r *PostgresCommentRepository) Update(ctx context.Context, comment *domain.Comment) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := `
+			UPDATE comments SET body = $1, edited_at = $2, updated_at = $3
+			WHERE id = $4 AND tenant_id = $5 AND deleted_at IS NULL
+		`
+		result, err := tx.Exec(ctx, query, comment.Body, comment.EditedAt, comment.UpdatedAt, comment.ID, comment.TenantID)
+		if err != nil {
+			return fmt.Errorf("failed to update comment: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return domain.ErrCommentNotFound
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM comment_mentions WHERE comment_id = $1`, comment.ID); err != nil {
+			return fmt.Errorf("failed to clear comment mentions: %w", err)
+		}
+		return r.saveMentions(ctx, tx, comment)
+	})
+}
+
+// SoftDelete marks a comment deleted
+func (r *PostgresCommentRepository) SoftDelete(ctx context.Context, tenantID, id, deletedBy uuid.UUID) error {
+	query := `
+		UPDATE comments SET deleted_at = NOW(), deleted_by = $1, updated_at = NOW()
+		WHERE id = $2 AND tenant_id = $3 AND deleted_at IS NULL
+	`
+	result, err := db.MainPool.Exec(ctx, query, deletedBy, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrCommentNotFound
+	}
+	return nil
+}
+
+// TenantUsers returns active users whose home tenant is the tenant or who hold an
+// active membership in it
+func (r *PostgresCommentRepository) TenantUsers(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) ([]*domain.TenantUser, error) {
+	users := []*domain.TenantUser{}
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+
+	query := `
+		SELECT u.id, u.name, COALESCE(u.email, '')
+		FROM users u
+		WHERE u.id = ANY($2) AND u.is_active = true
+			AND (u.tenant_id = $1 OR EXISTS (
+				SELECT 1 FROM tenant_memberships tm
+				WHERE tm.user_id = u.id AND tm.tenant_id = $1 AND tm.is_active = true AND tm.revoked_at IS NULL
+			))
+	`
+	rows, err := db.MainPool.Query(ctx, query, tenantID, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenant users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user domain.TenantUser
+		if err := rows.Scan(&user.ID, &user.Name, &user.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant user: %w", err)
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+// saveMentions stores the comment's mentions in order
+func (r *PostgresCommentRepository) saveMentions(ctx context.Context, tx pgx.Tx, comment *domain.Comment) error {
+	if len(comment.Mentions) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO comment_mentions (comment_id, tenant_id, user_id, position)
+		SELECT $1, $2, m.user_id, m.position
+		FROM unnest($3::uuid[]) WITH ORDINALITY AS m(user_id, position)
+	`
+	if _, err := tx.Exec(ctx, query, comment.ID, comment.TenantID, comment.Mentions); err != nil {
+		return fmt.Errorf("failed to save comment mentions: %w", err)
+	}
+	return nil
+}
+
+func scanComment(row pgx.Row) (*domain.Comment, error) {
+	var c domain.Comment
+	var mentions []string
+	err := row.Scan(
+		&c.ID, &c.TenantID, &c.EntityType, &c.EntityID, &c.AuthorID, &c.AuthorName,
+		&c.Body, &c.EditedAt, &c.DeletedAt, &c.DeletedBy, &c.CreatedAt, &c.UpdatedAt,
+		&mentions,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Mentions = make([]uuid.UUID, 0, len(mentions))
+	for _, raw := range mentions {
+		if id, err := uuid.Parse(raw); err == nil {
+			c.Mentions = append(c.Mentions, id)
+		}
+	}
+	return &c, nil
+}
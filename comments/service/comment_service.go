@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/comments/domain"
+	"github.com/aceextension/comments/repository"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/notification"
+	notificationDomain "github.com/aceextension/notification/domain"
+	notificationService "github.com/aceextension/notification/service"
+	"github.com/google/uuid"
+)
+
+// MentionTemplateCode is the tenant template that replaces the built-in mention text.
+// It can use authorName, userName, entityType, entityId and comment.
+const MentionTemplateCode = "COMMENT_MENTION"
+
+// defaultFeedPageSize is used when a feed request sets no limit
+const defaultFeedPageSize = 50
+
+// commentService implements CommentService
+type commentService struct {
+	repo repository.CommentRepository
+
+	mu          sync.RWMutex
+	entityTypes map[string]EntityExistsFunc
+}
+
+// NewCommentService creates a new comment service
+func NewCommentService(repo repository.CommentRepository) CommentService {
+	return &commentService{
+		repo:        repo,
+		entityTypes: make(map[string]EntityExistsFunc),
+	}
+}
+
+// RegisterEntityType enables comments on an entity type
+func (s *commentService) RegisterEntityType(entityType string, exists EntityExistsFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entityTypes[entityType] = exists
+}
+
+// Create validates and stores a comment, then notifies the mentioned users
+func (s *commentService) Create(ctx context.Context, input CommentInput) (*domain.Comment, error) {
+	if err := s.checkEntity(ctx, input.TenantID, input.EntityType, input.EntityID); err != nil {
+		return nil, err
+	}
+
+	comment := domain.NewComment(input.TenantID, input.EntityType, input.EntityID, input.AuthorID, input.Body)
+	if err := comment.Validate(); err != nil {
+		return nil, err
+	}
+
+	mentioned, err := s.resolveMentions(ctx, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	s.notifyMentions(ctx, comment, mentioned)
+	return comment, nil
+}
+
+// Feed retrieves a page of a record's comments
+func (s *commentService) Feed(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID, limit, offset int) (*CommentFeed, error) {
+	if err := s.checkEntity(ctx, tenantID, entityType, entityID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultFeedPageSize
+	}
+	if limit > domain.MaxFeedPageSize {
+		limit = domain.MaxFeedPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	comments, total, err := s.repo.ListByEntity(ctx, tenantID, entityType, entityID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &CommentFeed{Comments: comments, Total: total}, nil
+}
+
+// Update changes the body of the author's comment
+func (s *commentService) Update(ctx context.Context, tenantID, id, userID uuid.UUID, body string) (*domain.Comment, error) {
+	comment, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID != userID {
+		return nil, domain.ErrNotCommentAuthor
+	}
+
+	oldBody, oldMentions := comment.Body, comment.Mentions
+	comment.Edit(body)
+	if err := comment.Validate(); err != nil {
+		return nil, err
+	}
+
+	mentioned, err := s.resolveMentions(ctx, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "EDIT_COMMENT", comment, userID, map[string]interface{}{
+		"entity_type": comment.EntityType,
+		"entity_id":   comment.EntityID.String(),
+		"old_body":    oldBody,
+		"new_body":    comment.Body,
+	})
+
+	// Users mentioned before were told already
+	added := make(map[uuid.UUID]bool)
+	for _, id := range comment.NewMentions(oldMentions) {
+		added[id] = true
+	}
+	newlyMentioned := []*domain.TenantUser{}
+	for _, user := range mentioned {
+		if added[user.ID] {
+			newlyMentioned = append(newlyMentioned, user)
+		}
+	}
+	s.notifyMentions(ctx, comment, newlyMentioned)
+
+	return comment, nil
+}
+
+// Delete soft-deletes a comment
+func (s *commentService) Delete(ctx context.Context, tenantID, id, userID uuid.UUID, moderate bool) error {
+	comment, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if comment.AuthorID != userID && !moderate {
+		return domain.ErrNotCommentAuthor
+	}
+
+	if err := s.repo.SoftDelete(ctx, tenantID, id, userID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, "DELETE_COMMENT", comment, userID, map[string]interface{}{
+		"entity_type": comment.EntityType,
+		"entity_id":   comment.EntityID.String(),
+		"author_id":   comment.AuthorID.String(),
+		"body":        comment.Body,
+	})
+	return nil
+}
+
+// checkEntity validates the entity type and that the record belongs to the tenant
+func (s *commentService) checkEntity(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	s.mu.RLock()
+	exists, ok := s.entityTypes[entityType]
+	s.mu.RUnlock()
+	if !ok {
+		return domain.ErrUnknownEntityType
+	}
+
+	found, err := exists(ctx, tenantID, entityID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return domain.ErrEntityNotFound
+	}
+	return nil
+}
+
+// resolveMentions keeps only mentions of the tenant's active users, so outsiders are
+// never notified, and returns them without the author
+func (s *commentService) resolveMentions(ctx context.Context, comment *domain.Comment) ([]*domain.TenantUser, error) {
+	users, err := s.repo.TenantUsers(ctx, comment.TenantID, comment.Mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[uuid.UUID]*domain.TenantUser, len(users))
+	for _, user := range users {
+		members[user.ID] = user
+	}
+
+	kept := []uuid.UUID{}
+	mentioned := []*domain.TenantUser{}
+	for _, id := range comment.Mentions {
+		user, ok := members[id]
+		if !ok {
+			continue
+		}
+		kept = append(kept, id)
+		if id != comment.AuthorID {
+			mentioned = append(mentioned, user)
+		}
+	}
+	comment.Mentions = kept
+	return mentioned, nil
+}
+
+// notifyMentions tells each mentioned user in-app and, when they have an address, by email.
+// A failed notification is logged and does not fail the comment.
+func (s *commentService) notifyMentions(ctx context.Context, comment *domain.Comment, users []*domain.TenantUser) {
+	if len(users) == 0 || notification.Service == nil {
+		return
+	}
+
+	authorName := "Someone"
+	if authors, err := s.repo.TenantUsers(ctx, comment.TenantID, []uuid.UUID{comment.AuthorID}); err == nil && len(authors) == 1 {
+		authorName = authors[0].Name
+	}
+
+	referenceType := strings.ToUpper(comment.EntityType)
+	content := fmt.Sprintf("%s mentioned you on a %s: %s", authorName, comment.EntityType, comment.PlainText())
+
+	for _, user := range users {
+		userID := user.ID
+		requests := []notificationService.SendRequest{{
+			TenantID:  comment.TenantID,
+			UserID:    &userID,
+			Channel:   notificationDomain.ChannelInApp,
+			Recipient: userID.String(),
+			Content:   content,
+			Priority:  notificationDomain.PriorityHigh,
+		}}
+		if user.Email != "" {
+			requests = append(requests, notificationService.SendRequest{
+				TenantID:  comment.TenantID,
+				UserID:    &userID,
+				Channel:   notificationDomain.ChannelEmail,
+				Recipient: user.Email,
+				Content:   content,
+				Priority:  notificationDomain.PriorityLow,
+			})
+		}
+
+		for _, req := range requests {
+			if template, err := notification.TemplateRepo.GetByCode(ctx, comment.TenantID, MentionTemplateCode, req.Channel); err == nil && template.IsActive {
+				req.TemplateID = &template.ID
+				req.Variables = map[string]interface{}{
+					"authorName": authorName,
+					"userName":   user.Name,
+					"entityType": comment.EntityType,
+					"entityId":   comment.EntityID.String(),
+					"comment":    comment.PlainText(),
+				}
+			}
+			req.ReferenceType = &referenceType
+			req.ReferenceID = &comment.EntityID
+
+			if _, err := notification.Service.Send(ctx, req); err != nil {
+				logger.Log.Warn(fmt.Sprintf("Comments: failed to notify %s of mention in comment %s: %v", user.ID, comment.ID, err))
+			}
+		}
+	}
+}
+
+// audit logs a change to a comment
+func (s *commentService) audit(ctx context.Context, action string, comment *domain.Comment, userID uuid.UUID, details map[string]interface{}) {
+	if audit.Service == nil {
+		return
+	}
+	entityIDStr := comment.ID.String()
+	audit.Service.Log(ctx, action, "Comment", &entityIDStr, details, &auditDomain.AuditContext{
+		TenantID: &comment.TenantID,
+		UserID:   &userID,
+	})
+}
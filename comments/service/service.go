@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"github.com/aceextension/comments/domain"
+	"github.com/google/uuid"
+)
+
+// EntityExistsFunc reports whether a record of a registered entity type exists for the tenant
+type EntityExistsFunc func(ctx context.Context, tenantID, entityID uuid.UUID) (bool, error)
+
+// ExistsByCount adapts a batch count lookup, as registered with the tags module, to an EntityExistsFunc
+func ExistsByCount(count func(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (int, error)) EntityExistsFunc {
+	return func(ctx context.Context, tenantID, entityID uuid.UUID) (bool, error) {
+		n, err := count(ctx, tenantID, []uuid.UUID{entityID})
+		return n > 0, err
+	}
+}
+
+// CommentInput is a new comment on a record
+type CommentInput struct {
+	TenantID   uuid.UUID
+	AuthorID   uuid.UUID
+	EntityType string
+	EntityID   uuid.UUID
+	Body       string
+}
+
+// CommentFeed is one page of a record's comments, oldest first
+type CommentFeed struct {
+	Comments []*domain.Comment
+	Total    int
+}
+
+// CommentService defines the interface for comment business logic
+type CommentService interface {
+	// RegisterEntityType enables comments on an entity type owned by another module
+	RegisterEntityType(entityType string, exists EntityExistsFunc)
+
+	// Create adds a comment and notifies the tenant users it mentions, in-app and by email
+	Create(ctx context.Context, input CommentInput) (*domain.Comment, error)
+	// Feed returns a page of a record's comments
+	Feed(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID, limit, offset int) (*CommentFeed, error)
+	// Update lets the author change a comment; only users newly mentioned are notified
+	Update(ctx context.Context, tenantID, id, userID uuid.UUID, body string) (*domain.Comment, error)
+	// Delete hides a comment; the author or, with moderate, an owner or admin may delete it
+	Delete(ctx context.Context, tenantID, id, userID uuid.UUID, moderate bool) error
+}
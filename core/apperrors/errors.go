@@ -67,6 +67,22 @@ func formatValidationError(e validator.FieldError) string {
 	return fmt.Sprintf("%s failed on the '%s' tag", e.Field(), e.Tag())
 }
 
+// StatusCode returns the HTTP status GlobalErrorHandler answers err with
+func StatusCode(err error) int {
+	var appErr *AppError
+	var echoErr *echo.HTTPError
+	var validationErr validator.ValidationErrors
+	switch {
+	case errors.As(err, &appErr):
+		return appErr.Code
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest
+	case errors.As(err, &echoErr):
+		return echoErr.Code
+	}
+	return http.StatusInternalServerError
+}
+
 // GlobalErrorHandler handles all Echo errors
 func GlobalErrorHandler(err error, c echo.Context) {
 	var appErr *AppError
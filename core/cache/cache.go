@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// entry holds a cached value with its expiry time
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTLCache is a concurrency-safe in-memory cache with per-entry expiry.
+// It is process-local; use it for hot read paths where a short staleness
+// window is acceptable and writes invalidate explicitly.
+type TTLCache[K comparable, V any] struct {
+	mu         sync.RWMutex
+	items      map[K]entry[V]
+	ttl        time.Duration
+	maxEntries int
+}
+
+// New creates a cache whose entries expire after ttl.
+// maxEntries bounds memory use; 0 means unbounded.
+func New[K comparable, V any](ttl time.Duration, maxEntries int) *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		items:      make(map[K]entry[V]),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+	}
+}
+
+// Get returns a cached value if present and not expired
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	e, ok := c.items[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(e.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set stores a value, evicting expired entries when the cache is full
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxEntries > 0 && len(c.items) >= c.maxEntries {
+		c.evictLocked()
+	}
+
+	c.items[key] = entry[V]{value: value, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// Delete removes a key
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	delete(c.items, key)
+	c.mu.Unlock()
+}
+
+// Len returns the number of stored entries, including expired ones not yet evicted
+func (c *TTLCache[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// evictLocked drops expired entries, or the entry closest to expiry if none have expired
+func (c *TTLCache[K, V]) evictLocked() {
+	now := time.Now()
+
+	var oldestKey K
+	var oldest time.Time
+	found := false
+
+	for k, e := range c.items {
+		if now.After(e.expiresAt) {
+			delete(c.items, k)
+			continue
+		}
+		if !found || e.expiresAt.Before(oldest) {
+			oldestKey, oldest, found = k, e.expiresAt, true
+		}
+	}
+
+	if len(c.items) >= c.maxEntries && found {
+		delete(c.items, oldestKey)
+	}
+}
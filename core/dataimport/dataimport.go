@@ -0,0 +1,244 @@
+// Package dataimport reads CSV and XLSX uploads into rows addressed by column name and
+// collects per-row errors into the report bulk import endpoints return.
+package dataimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aceextension/core/xlsx"
+)
+
+// Limits of one import
+const (
+	MaxFileSize = 10 << 20 // 10 MB
+	MaxRows     = 5000     // Data rows, not counting the header
+)
+
+var (
+	// ErrUnsupportedFormat is returned for a file that is not .csv or .xlsx
+	ErrUnsupportedFormat = errors.New("file must be .csv or .xlsx")
+	// ErrInvalidFile is returned for an empty or unreadable file, or one with too many rows
+	ErrInvalidFile = errors.New("invalid import file")
+	// ErrFileTooLarge is returned for an upload over MaxFileSize
+	ErrFileTooLarge = errors.New("import file is larger than 10 MB")
+)
+
+// Table is an upload's rows under its header row. Column names match ignoring case,
+// spaces and punctuation, so "Selling Price", "selling_price" and "sellingPrice" are
+// the same column.
+type Table struct {
+	columns map[string]int
+	Rows    []Row
+}
+
+// Row is one data row; Number is its row number in the file, the header being row 1
+type Row struct {
+	Number int
+	values []string
+	table  *Table
+}
+
+// Read reads a .csv or .xlsx upload; the extension of filename picks the format.
+// Blank rows are skipped.
+func Read(filename string, r io.ReaderAt, size int64) (*Table, error) {
+	var records [][]string
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = readCSV(io.NewSectionReader(r, 0, size))
+	case ".xlsx":
+		records, err = xlsx.ReadFirstSheet(r, size, MaxRows+1)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no header row", ErrInvalidFile)
+	}
+
+	t := &Table{columns: make(map[string]int)}
+	for i, name := range records[0] {
+		if key := columnKey(name); key != "" {
+			if _, dup := t.columns[key]; !dup {
+				t.columns[key] = i
+			}
+		}
+	}
+	if len(t.columns) == 0 {
+		return nil, fmt.Errorf("%w: no header row", ErrInvalidFile)
+	}
+	for i, values := range records[1:] {
+		if blank(values) {
+			continue
+		}
+		t.Rows = append(t.Rows, Row{Number: i + 2, values: values, table: t})
+	}
+	if len(t.Rows) > MaxRows {
+		return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidFile, MaxRows)
+	}
+	return t, nil
+}
+
+// ReadUpload reads a .csv or .xlsx file uploaded as multipart form data
+func ReadUpload(fh *multipart.FileHeader) (*Table, error) {
+	if fh.Size > MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer f.Close()
+	return Read(fh.Filename, f, fh.Size)
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// Excel saves CSV with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	var records [][]string
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > MaxRows {
+			return nil, fmt.Errorf("more than %d rows", MaxRows)
+		}
+		records = append(records, record)
+	}
+}
+
+// Missing lists the required columns the header lacks
+func (t *Table) Missing(columns ...string) []string {
+	var missing []string
+	for _, column := range columns {
+		if !t.Has(column) {
+			missing = append(missing, column)
+		}
+	}
+	return missing
+}
+
+// Has reports whether the header has the column
+func (t *Table) Has(column string) bool {
+	_, ok := t.columns[columnKey(column)]
+	return ok
+}
+
+// Get is the row's trimmed value in the column, empty when the column or cell is missing.
+// The quote exports put before text like "+977..." so spreadsheets do not take it for
+// a formula is dropped, so an exported file imports as it was.
+func (r Row) Get(column string) string {
+	i, ok := r.table.columns[columnKey(column)]
+	if !ok || i >= len(r.values) {
+		return ""
+	}
+	v := strings.TrimSpace(r.values[i])
+	if len(v) > 1 && v[0] == '\'' && strings.ContainsRune("=+-@", rune(v[1])) {
+		return v[1:]
+	}
+	return v
+}
+
+// Optional is the row's value in the column, nil when it is empty
+func (r Row) Optional(column string) *string {
+	if v := r.Get(column); v != "" {
+		return &v
+	}
+	return nil
+}
+
+// Float parses the column as a number, allowing thousands separators; an empty cell is
+// not set and not an error
+func (r Row) Float(column string) (value float64, set bool, err error) {
+	v := strings.ReplaceAll(r.Get(column), ",", "")
+	if v == "" {
+		return 0, false, nil
+	}
+	value, err = strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("%q is not a number", r.Get(column))
+	}
+	return value, true, nil
+}
+
+// columnKey folds a column name to letters and digits in lower case
+func columnKey(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+func blank(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// RowError is a problem with one row, or with the file when Row is zero
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report is the outcome of an import. Rows are only imported when every row is
+// valid, so a file with errors can be fixed and uploaded again as a whole.
+type Report struct {
+	DryRun   bool       `json:"dryRun"`
+	Rows     int        `json:"rows"`     // Data rows read
+	Valid    int        `json:"valid"`    // Rows without errors
+	Imported int        `json:"imported"` // Rows created; zero on a dry run or when any row has errors
+	Errors   []RowError `json:"errors"`
+}
+
+// NewReport starts the report of importing the table
+func NewReport(t *Table, dryRun bool) *Report {
+	return &Report{DryRun: dryRun, Rows: len(t.Rows), Errors: []RowError{}}
+}
+
+// Fail records a problem with a row
+func (r *Report) Fail(row int, column, message string) {
+	r.Errors = append(r.Errors, RowError{Row: row, Column: column, Message: message})
+}
+
+// FailMissing records a header's missing required columns against the file
+func (r *Report) FailMissing(missing []string) {
+	for _, column := range missing {
+		r.Fail(0, column, "required column is missing")
+	}
+}
+
+// OK reports whether no errors have been recorded
+func (r *Report) OK() bool {
+	return len(r.Errors) == 0
+}
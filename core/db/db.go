@@ -19,24 +19,26 @@ type QueryExecutor interface {
 }
 
 var (
-	MainPool  *pgxpool.Pool
+	// MainPool routes each query to the database of the tenant's data region;
+	// with no regional databases connected everything goes to DATABASE_URL
+	MainPool  *RegionRouter
 	AuditPool *pgxpool.Pool
 )
 
 func Init(mainConnStr, auditConnStr string) {
-	var err error
 	ctx := context.Background()
 
 	// Initialize Main Database Pool
-	MainPool, err = pgxpool.New(ctx, mainConnStr)
+	mainPool, err := pgxpool.New(ctx, mainConnStr)
 	if err != nil {
 		log.Fatalf("Unable to connect to main database: %v\n", err)
 	}
 
 	// Verify connection
-	if err := MainPool.Ping(ctx); err != nil {
+	if err := mainPool.Ping(ctx); err != nil {
 		log.Fatalf("Main database ping failed: %v\n", err)
 	}
+	MainPool = NewRegionRouter(DefaultRegion, mainPool)
 	fmt.Println("🚀 Connected to Main Database")
 
 	// Initialize Audit Database Pool
@@ -52,6 +54,28 @@ func Init(mainConnStr, auditConnStr string) {
 	fmt.Println("🚀 Connected to Audit Database")
 }
 
+// InitRegions names the main database's region and connects the databases of the
+// other data regions, keyed by region. Call it after Init.
+func InitRegions(home string, urls map[string]string) {
+	ctx := context.Background()
+	router := NewRegionRouter(home, MainPool.Home())
+	for region, url := range urls {
+		if region == home {
+			continue
+		}
+		pool, err := pgxpool.New(ctx, url)
+		if err != nil {
+			log.Fatalf("Unable to connect to %s region database: %v\n", region, err)
+		}
+		if err := pool.Ping(ctx); err != nil {
+			log.Fatalf("%s region database ping failed: %v\n", region, err)
+		}
+		router.AddRegion(region, pool)
+		fmt.Printf("🚀 Connected to %s Region Database\n", region)
+	}
+	MainPool = router
+}
+
 func Close() {
 	if MainPool != nil {
 		MainPool.Close()
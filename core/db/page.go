@@ -0,0 +1,128 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// ErrInvalidCursor is returned for a page cursor that was not issued by a list endpoint
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page sizes of keyset-paginated lists
+const (
+	DefaultPageLimit = 10
+	MaxPageLimit     = 200
+)
+
+// Cursor is the sort key of the last row of a page; the next page starts after it.
+// Keys are in the list's ORDER BY order with the row's ID last, e.g. created_at, id.
+// Clients get it as an opaque string and pass it back unchanged.
+type Cursor struct {
+	keys []json.RawMessage
+}
+
+// NewCursor creates the cursor of a row from its sort key values
+func NewCursor(keys ...any) Cursor {
+	c := Cursor{keys: make([]json.RawMessage, len(keys))}
+	for i, key := range keys {
+		c.keys[i], _ = json.Marshal(key)
+	}
+	return c
+}
+
+// String encodes the cursor for a response
+func (c Cursor) String() string {
+	raw, _ := json.Marshal(c.keys)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// ParseCursor decodes a cursor from a request; an empty string is the first page
+func ParseCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c.keys); err != nil || len(c.keys) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Scan copies the cursor's keys into dest, e.g. a *time.Time and a *uuid.UUID.
+// A cursor of another list does not scan and gives ErrInvalidCursor.
+func (c *Cursor) Scan(dest ...any) error {
+	if len(dest) != len(c.keys) {
+		return ErrInvalidCursor
+	}
+	for i, key := range c.keys {
+		if err := json.Unmarshal(key, dest[i]); err != nil {
+			return ErrInvalidCursor
+		}
+	}
+	return nil
+}
+
+// PageRequest asks for the Limit rows after the cursor; a nil After is the first page
+type PageRequest struct {
+	After *Cursor
+	Limit int
+}
+
+// ParsePageRequest reads the cursor and limit query parameters, clamping the limit
+func ParsePageRequest(cursor, limit string) (PageRequest, error) {
+	after, err := ParseCursor(cursor)
+	if err != nil {
+		return PageRequest{}, err
+	}
+	n, _ := strconv.Atoi(limit)
+	if n <= 0 {
+		n = DefaultPageLimit
+	}
+	if n > MaxPageLimit {
+		n = MaxPageLimit
+	}
+	return PageRequest{After: after, Limit: n}, nil
+}
+
+// Fetch is how many rows a repository reads for the page: one more than the limit,
+// so NewPage can tell whether another page follows
+func (p PageRequest) Fetch() int {
+	return p.Limit + 1
+}
+
+// Page is the envelope list endpoints return
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	Total      int64   `json:"total"`                // Rows in the whole list, not only this page
+	NextCursor *string `json:"nextCursor,omitempty"` // Absent on the last page
+}
+
+// NewPage builds the page from the rows read for req (up to req.Fetch()) and the
+// list's total; cursorOf gives the cursor of a row
+func NewPage[T any](rows []T, total int64, req PageRequest, cursorOf func(T) Cursor) Page[T] {
+	page := Page[T]{Items: rows, Total: total}
+	if len(rows) > req.Limit {
+		page.Items = rows[:req.Limit]
+		next := cursorOf(page.Items[req.Limit-1]).String()
+		page.NextCursor = &next
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page
+}
+
+// MapPage converts a page's items, e.g. domain objects to responses
+func MapPage[T, U any](page Page[T], fn func(T) U) Page[U] {
+	items := make([]U, len(page.Items))
+	for i, item := range page.Items {
+		items[i] = fn(item)
+	}
+	return Page[U]{Items: items, Total: page.Total, NextCursor: page.NextCursor}
+}
@@ -0,0 +1,276 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrUnknownRegion is returned for a data region this deployment has no database for.
+	// A tenant's queries never fall back to another region.
+	ErrUnknownRegion = errors.New("unknown data region")
+	// ErrCrossRegionQuery is returned for a query that joins a regional tenant's tables
+	// with platform tables, which only live in the home region
+	ErrCrossRegionQuery = errors.New("query joins tables of different data regions")
+)
+
+// DefaultRegion is the home region when none is configured
+const DefaultRegion = "np"
+
+// GlobalTables live only in the home region: tenants, logins and the platform's own
+// billing. Every other table holds tenant data and lives in the tenant's region.
+var GlobalTables = map[string]bool{
+	"tenants":                        true,
+	"users":                          true,
+	"sessions":                       true,
+	"invitations":                    true,
+	"tenant_memberships":             true,
+	"tenant_domains":                 true,
+	"tenant_branding":                true,
+	"tenant_integration_credentials": true,
+	"api_keys":                       true,
+	"guest_access_logs":              true,
+	"request_nonces":                 true,
+	"plans":                          true,
+	"subscriptions":                  true,
+	"subscription_access_overrides":  true,
+	"subscription_cancellations":     true,
+	"subscription_invoices":          true,
+	"subscription_invoice_prints":    true,
+	"entitlement_snapshots":          true,
+	"tenant_usage":                   true,
+}
+
+// RegionRouter sends each query to the database of the data region of the tenant in
+// the context. Queries without a tenant, and queries reading only global tables, go
+// to the home region. Background jobs must put the tenant in the context with
+// WithTenantID for their queries to reach a regional tenant's data.
+type RegionRouter struct {
+	home    string
+	pools   map[string]*pgxpool.Pool
+	regions sync.Map // tenant ID -> region
+	tables  sync.Map // SQL -> queryTables
+}
+
+// queryTables is which kinds of tables a statement reads or writes
+type queryTables struct {
+	global bool
+	tenant bool
+}
+
+// NewRegionRouter creates a router over the home region's pool; AddRegion adds others
+func NewRegionRouter(home string, pool *pgxpool.Pool) *RegionRouter {
+	return &RegionRouter{home: home, pools: map[string]*pgxpool.Pool{home: pool}}
+}
+
+// AddRegion adds a region's database. Call it before serving requests.
+func (r *RegionRouter) AddRegion(region string, pool *pgxpool.Pool) {
+	r.pools[region] = pool
+}
+
+// HomeRegion is the region of the platform tables and of tenants without a region
+func (r *RegionRouter) HomeRegion() string {
+	return r.home
+}
+
+// HasRegion reports whether the deployment has a database for the region
+func (r *RegionRouter) HasRegion(region string) bool {
+	_, ok := r.pools[region]
+	return ok
+}
+
+// Home is the home region's pool
+func (r *RegionRouter) Home() *pgxpool.Pool {
+	return r.pools[r.home]
+}
+
+// TenantRegion looks up the tenant's data region, cached after the first lookup
+func (r *RegionRouter) TenantRegion(ctx context.Context, tenantID uuid.UUID) (string, error) {
+	if region, ok := r.regions.Load(tenantID); ok {
+		return region.(string), nil
+	}
+	var region *string
+	err := r.Home().QueryRow(ctx, `SELECT data_region FROM tenants WHERE id = $1`, tenantID).Scan(&region)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("failed to look up tenant data region: %w", err)
+	}
+	if region == nil || *region == "" {
+		region = &r.home
+	}
+	r.regions.Store(tenantID, *region)
+	return *region, nil
+}
+
+// ForgetTenant drops the cached region of a tenant, e.g. after it is deleted
+func (r *RegionRouter) ForgetTenant(tenantID uuid.UUID) {
+	r.regions.Delete(tenantID)
+}
+
+// route picks the pool for a statement run in ctx
+func (r *RegionRouter) route(ctx context.Context, sql string) (*pgxpool.Pool, error) {
+	if len(r.pools) == 1 {
+		return r.Home(), nil
+	}
+	tenantID, ok := GetTenantID(ctx)
+	if !ok {
+		return r.Home(), nil
+	}
+	region, err := r.TenantRegion(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if region == r.home {
+		return r.Home(), nil
+	}
+
+	tables := r.classify(sql)
+	switch {
+	case tables.global && tables.tenant:
+		return nil, fmt.Errorf("%w: tenant in region %s", ErrCrossRegionQuery, region)
+	case tables.global:
+		return r.Home(), nil
+	}
+	pool, ok := r.pools[region]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRegion, region)
+	}
+	return pool, nil
+}
+
+// tableRef matches the table after FROM, JOIN, INTO or UPDATE
+var tableRef = regexp.MustCompile(`(?i)\b(?:from|join|into|update)\s+(?:only\s+)?"?([a-z_][a-z0-9_]*)"?(?:\."?([a-z_][a-z0-9_]*)"?)?`)
+
+// notTableRef matches SQL where FROM is followed by a column, not a table
+var notTableRef = regexp.MustCompile(`(?i)\b(?:extract|substring|trim|overlay)\s*\([^)]*\)|\bdistinct\s+from\b`)
+
+// classify finds whether a statement touches global tables, tenant tables or both
+func (r *RegionRouter) classify(sql string) queryTables {
+	if t, ok := r.tables.Load(sql); ok {
+		return t.(queryTables)
+	}
+	var t queryTables
+	for _, m := range tableRef.FindAllStringSubmatch(notTableRef.ReplaceAllString(sql, ""), -1) {
+		name := strings.ToLower(m[1])
+		if m[2] != "" {
+			name = strings.ToLower(m[2]) // schema.table
+		}
+		if GlobalTables[name] {
+			t.global = true
+		} else {
+			t.tenant = true
+		}
+	}
+	r.tables.Store(sql, t)
+	return t
+}
+
+// Exec runs a statement in the tenant's region
+func (r *RegionRouter) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	pool, err := r.route(ctx, sql)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return pool.Exec(ctx, sql, args...)
+}
+
+// Query runs a query in the tenant's region
+func (r *RegionRouter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	pool, err := r.route(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	return pool.Query(ctx, sql, args...)
+}
+
+// QueryRow runs a single-row query in the tenant's region; a routing error comes back from Scan
+func (r *RegionRouter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	pool, err := r.route(ctx, sql)
+	if err != nil {
+		return errRow{err: err}
+	}
+	return pool.QueryRow(ctx, sql, args...)
+}
+
+// SendBatch runs a batch in the tenant's region. Every statement must route to the same one.
+func (r *RegionRouter) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	var pool *pgxpool.Pool
+	for _, q := range b.QueuedQueries {
+		p, err := r.route(ctx, q.SQL)
+		if err == nil && pool != nil && p != pool {
+			err = fmt.Errorf("%w: batch spans regions", ErrCrossRegionQuery)
+		}
+		if err != nil {
+			return errBatchResults{err: err}
+		}
+		pool = p
+	}
+	if pool == nil {
+		pool = r.Home()
+	}
+	return pool.SendBatch(ctx, b)
+}
+
+// Begin starts a transaction in the tenant's region. A transaction never spans regions,
+// so one that reaches global tables for a regional tenant fails in the database.
+func (r *RegionRouter) Begin(ctx context.Context) (pgx.Tx, error) {
+	pool, err := r.route(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return pool.Begin(ctx)
+}
+
+// Ping checks every region's database
+func (r *RegionRouter) Ping(ctx context.Context) error {
+	for region, pool := range r.pools {
+		if err := pool.Ping(ctx); err != nil {
+			return fmt.Errorf("region %s: %w", region, err)
+		}
+	}
+	return nil
+}
+
+// Close closes every region's pool
+func (r *RegionRouter) Close() {
+	for _, pool := range r.pools {
+		pool.Close()
+	}
+}
+
+// ParseRegionURLs reads regional database URLs given as region=url pairs, e.g.
+// "in=postgres://...,sg=postgres://..."
+func ParseRegionURLs(s string) (map[string]string, error) {
+	urls := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		region, url, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(region) == "" || strings.TrimSpace(url) == "" {
+			return nil, fmt.Errorf("invalid regional database URL %q, expected region=url", pair)
+		}
+		urls[strings.TrimSpace(region)] = strings.TrimSpace(url)
+	}
+	return urls, nil
+}
+
+type errRow struct{ err error }
+
+func (r errRow) Scan(...any) error { return r.err }
+
+type errBatchResults struct{ err error }
+
+func (b errBatchResults) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag{}, b.err }
+func (b errBatchResults) Query() (pgx.Rows, error)         { return nil, b.err }
+func (b errBatchResults) QueryRow() pgx.Row                { return errRow{err: b.err} }
+func (b errBatchResults) Close() error                     { return b.err }
@@ -6,16 +6,15 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // RLSManager handles Row-Level Security operations
 type RLSManager struct {
-	pool *pgxpool.Pool
+	pool QueryExecutor
 }
 
 // NewRLSManager creates a new RLS manager
-func NewRLSManager(pool *pgxpool.Pool) *RLSManager {
+func NewRLSManager(pool QueryExecutor) *RLSManager {
 	return &RLSManager{pool: pool}
 }
 
@@ -12,6 +12,7 @@ type contextKey string
 const (
 	TenantIDKey     contextKey = "tenant_id"
 	UserIDKey       contextKey = "user_id"
+	RoleKey         contextKey = "role"
 	IsSuperAdminKey contextKey = "is_super_admin"
 )
 
@@ -37,6 +38,17 @@ func GetUserID(ctx context.Context) (uuid.UUID, bool) {
 	return userID, ok
 }
 
+// WithRole adds the authenticated user's role to context
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, RoleKey, role)
+}
+
+// GetRole retrieves the authenticated user's role from context
+func GetRole(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(RoleKey).(string)
+	return role, ok && role != ""
+}
+
 // WithSuperAdmin marks context as super admin
 func WithSuperAdmin(ctx context.Context, isSuperAdmin bool) context.Context {
 	return context.WithValue(ctx, IsSuperAdminKey, isSuperAdmin)
@@ -0,0 +1,115 @@
+// Package events is the in-process event bus modules announce changes on, e.g.
+// a product's price changing or stock being received. Subscribers such as the
+// realtime hub fan the events out to connected clients.
+//
+// Publish after the change is committed. Delivery is synchronous and best
+// effort: handlers must return quickly and must not block, and nothing is
+// persisted or replayed.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is one change within a tenant
+type Event struct {
+	ID         uuid.UUID `json:"id"`
+	TenantID   uuid.UUID `json:"-"`
+	Topic      string    `json:"topic"` // What clients subscribe to, e.g. products or stock
+	Type       string    `json:"type"`  // What happened, e.g. product.updated
+	Data       any       `json:"data,omitempty"`
+	Roles      []string  `json:"-"` // Only users with one of these roles receive it; everyone when empty
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// New creates an event for a tenant's topic
+func New(tenantID uuid.UUID, topic, eventType string, data any) Event {
+	return Event{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		Topic:      topic,
+		Type:       eventType,
+		Data:       data,
+		OccurredAt: time.Now(),
+	}
+}
+
+// ForRoles limits the event to users with one of the roles
+func (e Event) ForRoles(roles ...string) Event {
+	e.Roles = roles
+	return e
+}
+
+// VisibleTo reports whether a user with a role may receive the event
+func (e Event) VisibleTo(role string) bool {
+	if len(e.Roles) == 0 {
+		return true
+	}
+	for _, r := range e.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler receives published events
+type Handler func(Event)
+
+// Bus delivers published events to its subscribers
+type Bus struct {
+	mu       sync.RWMutex
+	next     int
+	handlers map[int]Handler
+}
+
+// NewBus creates a bus without subscribers
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[int]Handler)}
+}
+
+// Subscribe adds a handler and returns the function that removes it
+func (b *Bus) Subscribe(handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.handlers[id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+	}
+}
+
+// Publish delivers an event to every subscriber
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers))
+	for _, handler := range b.handlers {
+		handlers = append(handlers, handler)
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// Default is the process-wide bus modules publish on
+var Default = NewBus()
+
+// Publish delivers an event on the default bus
+func Publish(event Event) {
+	Default.Publish(event)
+}
+
+// Subscribe adds a handler to the default bus
+func Subscribe(handler Handler) func() {
+	return Default.Subscribe(handler)
+}
@@ -0,0 +1,281 @@
+// Package export writes tenant data to an HTTP response as a CSV or XLSX download
+// while rows are read, so a tenant can pull out all its products or customers in one
+// request instead of paging through the JSON API. Like the stream package, headers go
+// out with the first row, so an error before any row still gets a normal response.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/core/xlsx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Format is the file format of an export
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// Content types of the formats
+const (
+	MIMETextCSV = "text/csv; charset=utf-8"
+	MIMEXLSX    = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// flushEvery is how many rows are buffered before the response is flushed to the client
+const flushEvery = 500
+
+// Negotiate picks a file format from the format query parameter. It reports false for
+// any other format, which the handler serves as JSON through the stream package.
+func Negotiate(r *http.Request) (Format, bool) {
+	switch Format(strings.ToLower(r.URL.Query().Get("format"))) {
+	case FormatCSV:
+		return FormatCSV, true
+	case FormatXLSX:
+		return FormatXLSX, true
+	}
+	return "", false
+}
+
+// Column is one column of an export: its header and how to read it from a row.
+// Value returns a string, number, bool, time.Time, uuid.UUID or other Stringer, a
+// pointer to one of those, or nil for an empty cell.
+type Column[T any] struct {
+	Name  string
+	Value func(T) any
+}
+
+// Writer writes rows of T under a header row of the columns' names
+type Writer[T any] struct {
+	w        http.ResponseWriter
+	format   Format
+	columns  []Column[T]
+	filename string
+	csv      *csv.Writer
+	workbook *xlsx.Workbook
+	sheet    *xlsx.Sheet
+	started  bool
+	rows     int
+}
+
+// NewWriter creates a writer that sends the response as a download named filename;
+// the extension is added from the format
+func NewWriter[T any](w http.ResponseWriter, format Format, filename string, columns []Column[T]) *Writer[T] {
+	return &Writer[T]{w: w, format: format, columns: columns, filename: filename}
+}
+
+// Started reports whether headers have been sent; after that an error can only end the download
+func (e *Writer[T]) Started() bool {
+	return e.started
+}
+
+// Rows returns how many rows have been written
+func (e *Writer[T]) Rows() int {
+	return e.rows
+}
+
+// Write writes one row
+func (e *Writer[T]) Write(row T) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	values := make([]any, len(e.columns))
+	for i, column := range e.columns {
+		values[i] = cellValue(column.Value(row))
+	}
+	if err := e.writeRow(values); err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%flushEvery == 0 {
+		e.flush()
+	}
+	return nil
+}
+
+// Close ends the download. A non-nil err ends a CSV with a final "#error" row, so a
+// failed export can be told from a complete one, and leaves an XLSX archive
+// unfinished, which spreadsheet programs refuse to open.
+func (e *Writer[T]) Close(err error) error {
+	if startErr := e.start(); startErr != nil {
+		return startErr
+	}
+
+	switch {
+	case err != nil && e.format == FormatCSV:
+		_ = e.csv.Write([]string{"#error", err.Error()})
+		e.csv.Flush()
+	case err != nil:
+	case e.format == FormatCSV:
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	default:
+		if err := e.workbook.Close(); err != nil {
+			return err
+		}
+	}
+	e.flush()
+	return nil
+}
+
+func (e *Writer[T]) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	header := e.w.Header()
+	if e.format == FormatXLSX {
+		header.Set("Content-Type", MIMEXLSX)
+	} else {
+		header.Set("Content-Type", MIMETextCSV)
+	}
+	header.Set("Content-Disposition", `attachment; filename="`+e.filename+"."+string(e.format)+`"`)
+	// Stop proxies such as nginx from buffering the whole download
+	header.Set("X-Accel-Buffering", "no")
+	e.w.WriteHeader(http.StatusOK)
+
+	names := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		names[i] = column.Name
+	}
+	if e.format == FormatXLSX {
+		e.workbook = xlsx.NewWorkbook(e.w)
+		sheet, err := e.workbook.AddSheet(e.filename)
+		if err != nil {
+			return err
+		}
+		e.sheet = sheet
+		return sheet.Header(names...)
+	}
+	// A byte order mark makes Excel read the file as UTF-8, keeping Devanagari intact
+	if _, err := e.w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return err
+	}
+	e.csv = csv.NewWriter(e.w)
+	return e.csv.Write(names)
+}
+
+func (e *Writer[T]) writeRow(values []any) error {
+	if e.format == FormatXLSX {
+		for i, v := range values {
+			if t, ok := v.(time.Time); ok {
+				values[i] = xlsx.DateTime(t)
+			}
+		}
+		return e.sheet.Row(values...)
+	}
+
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = csvText(v)
+	}
+	return e.csv.Write(record)
+}
+
+func (e *Writer[T]) flush() {
+	if e.csv != nil {
+		e.csv.Flush()
+	}
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Output is a download being streamed: a Writer, or a stream.Writer for JSON
+type Output interface {
+	Started() bool
+	Rows() int
+	Close(err error) error
+}
+
+// Finish ends a download once the rows are read. An error before any row is sent
+// gets a 500 response; after that it can only end the download, and is logged.
+func Finish(c echo.Context, out Output, err error) error {
+	if err != nil && !out.Started() {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		logger.Log.Error("Export " + c.Request().URL.Path + " failed after " + strconv.Itoa(out.Rows()) + " rows: " + err.Error())
+	}
+	return out.Close(err)
+}
+
+// cellValue reduces a column value to a string, int, int64, float64, time.Time or nil
+func cellValue(v any) any {
+	switch v := v.(type) {
+	case nil, string, int, int64, float64, time.Time:
+		return v
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *float64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *int:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *uuid.UUID:
+		if v == nil {
+			return nil
+		}
+		return v.String()
+	case float32:
+		return float64(v)
+	case int32:
+		return int64(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// csvText formats a cell for CSV. Text starting with =, +, - or @ is prefixed with a
+// quote so a spreadsheet opening the file shows it instead of running it as a formula.
+func csvText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,124 @@
+package jobs
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler serves the status and results of background jobs
+type Handler struct {
+	runner *Runner
+}
+
+// NewHandler creates a jobs handler
+func NewHandler(runner *Runner) *Handler {
+	return &Handler{runner: runner}
+}
+
+// RegisterRoutes adds the jobs routes to an authenticated group
+func (h *Handler) RegisterRoutes(g *echo.Group) {
+	g.GET("/:id", h.GetStatus)
+	g.GET("/:id/result", h.GetResult)
+}
+
+// GetStatus returns a job's status
+// @Summary Get background job status
+// @Description Poll a job returned with 202 Accepted by a slow export or report. Once
+// @Description status is succeeded or failed, download the response from resultUrl.
+// @Tags Jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} AcceptedResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/jobs/{id} [get]
+func (h *Handler) GetStatus(c echo.Context) error {
+	job, err := h.job(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, AcceptedResponse{Job: job, StatusURL: h.runner.StatusURL(job), ResultURL: h.runner.ResultURL(job)})
+}
+
+// GetResult returns a finished job's response
+// @Summary Download background job result
+// @Description Returns the response the request finished with, with its status and content
+// @Description type, e.g. the CSV file of an export. 202 with the job while it is still running.
+// @Tags Jobs
+// @Produce octet-stream
+// @Param id path string true "Job ID"
+// @Success 200 {file} file
+// @Success 202 {object} AcceptedResponse
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Router /api/v1/jobs/{id}/result [get]
+func (h *Handler) GetResult(c echo.Context) error {
+	job, err := h.job(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	body, err := h.runner.OpenResult(c.Request().Context(), job)
+	if errors.Is(err, ErrJobRunning) {
+		return c.JSON(http.StatusAccepted, AcceptedResponse{Job: job, StatusURL: h.runner.StatusURL(job), ResultURL: h.runner.ResultURL(job)})
+	}
+	if err != nil {
+		// A job that failed before its response was saved has nothing to download
+		return c.JSON(http.StatusGone, map[string]string{"error": "Job result is not available"})
+	}
+	defer body.Close()
+
+	header := c.Response().Header()
+	if job.ContentType != "" {
+		header.Set(echo.HeaderContentType, job.ContentType)
+	}
+	if job.ContentDisposition != "" {
+		header.Set(echo.HeaderContentDisposition, job.ContentDisposition)
+	}
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(job.Size, 10))
+	c.Response().WriteHeader(job.StatusCode)
+	if _, err := io.Copy(c.Response(), body); err != nil {
+		logger.Log.Error("Failed to send result of job " + job.ID.String() + ": " + err.Error())
+	}
+	return nil
+}
+
+// errUnauthenticated is returned when the request has no tenant
+var errUnauthenticated = errors.New("Tenant not found")
+
+// job loads the job in the path for the requesting user
+func (h *Handler) job(c echo.Context) (*Job, error) {
+	ctx := c.Request().Context()
+	tenantID, ok := db.GetTenantID(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+	var userID *uuid.UUID
+	if id, ok := db.GetUserID(ctx); ok {
+		userID = &id
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, ErrJobNotFound
+	}
+	return h.runner.Get(ctx, tenantID, userID, id)
+}
+
+// jobError maps job errors to HTTP statuses
+func jobError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errUnauthenticated):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrJobNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Job not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get job"})
+	}
+}
@@ -0,0 +1,123 @@
+// Package jobs turns slow requests into background jobs. A route wrapped with Async
+// runs as usual, but when its handler is still working after the execution budget the
+// client gets 202 Accepted with a status URL, while the handler keeps running and its
+// response is saved. The client polls the status URL and downloads the response from
+// the job's result URL once it is done, so exports and big reports no longer hit the
+// load balancer's timeout.
+//
+// Jobs are recorded in the async_jobs table, so any API instance can answer a status
+// request, and results are saved to a ResultStore shared by the instances.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is where a job is in its life
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded" // The request finished with a 2xx or 3xx status
+	StatusFailed    Status = "failed"    // The request finished with an error status, or its result was lost
+)
+
+const (
+	// DefaultBudget is how long a request runs before it turns into a job
+	DefaultBudget = 20 * time.Second
+	// DefaultMaxDuration is how long a job may run before its request is cancelled
+	DefaultMaxDuration = 30 * time.Minute
+	// DefaultRetention is how long a job's result is kept after the job is created
+	DefaultRetention = 24 * time.Hour
+)
+
+var (
+	// ErrJobNotFound is returned for a job that does not exist, has expired or is another tenant's or user's
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobRunning is returned when the result of a job that has not finished is requested
+	ErrJobRunning = errors.New("job is still running")
+)
+
+// Job is a request that outlived its execution budget
+type Job struct {
+	ID                 uuid.UUID  `json:"id"`
+	TenantID           uuid.UUID  `json:"-"`
+	UserID             *uuid.UUID `json:"-"` // Only this user can see the job; nil for a tenant-wide request
+	Method             string     `json:"method"`
+	Path               string     `json:"path"` // Request path with its query
+	Status             Status     `json:"status"`
+	StatusCode         int        `json:"statusCode,omitempty"` // HTTP status the request finished with
+	ContentType        string     `json:"contentType,omitempty"`
+	ContentDisposition string     `json:"-"`
+	Size               int64      `json:"size,omitempty"` // Bytes in the result
+	Error              string     `json:"error,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	FinishedAt         *time.Time `json:"finishedAt,omitempty"`
+	ExpiresAt          time.Time  `json:"expiresAt"`
+}
+
+// newJob creates a running job for a request
+func newJob(tenantID uuid.UUID, userID *uuid.UUID, method, path string, retention time.Duration) *Job {
+	now := time.Now()
+	return &Job{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		UserID:    userID,
+		Method:    method,
+		Path:      path,
+		Status:    StatusRunning,
+		CreatedAt: now,
+		ExpiresAt: now.Add(retention),
+	}
+}
+
+// finish records how the request ended
+func (j *Job) finish(statusCode int, size int64) {
+	now := time.Now()
+	j.FinishedAt = &now
+	j.StatusCode = statusCode
+	j.Size = size
+	if statusCode >= 400 {
+		j.Status = StatusFailed
+	} else {
+		j.Status = StatusSucceeded
+	}
+}
+
+// fail records a job whose result could not be kept
+func (j *Job) fail(message string) {
+	now := time.Now()
+	j.FinishedAt = &now
+	j.Status = StatusFailed
+	j.Error = message
+}
+
+// resultKey is where the job's response is saved in the ResultStore
+func (j *Job) resultKey() string {
+	return "jobs/" + j.TenantID.String() + "/" + j.ID.String()
+}
+
+// Store records jobs
+type Store interface {
+	Create(ctx context.Context, job *Job) error
+	// Get returns the tenant's job, or ErrJobNotFound
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*Job, error)
+	// Finish saves a finished job's status and result details
+	Finish(ctx context.Context, job *Job) error
+	// ListExpired returns up to limit jobs whose retention has passed
+	ListExpired(ctx context.Context, limit int) ([]*Job, error)
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+}
+
+// ResultStore keeps job responses. files/storage.Storage implementations, such as
+// the MinIO export store, satisfy it.
+type ResultStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
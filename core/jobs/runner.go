@@ -0,0 +1,361 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// memoryLimit is how much of a response is held in memory before it spills to a temp file
+const memoryLimit = 1 << 20
+
+// interruptedAfter is how long past the maximum duration a running job is taken as lost
+const interruptedAfter = 5 * time.Minute
+
+// purgeBatch is how many expired jobs PurgeExpired removes per call
+const purgeBatch = 100
+
+// Default is the runner Async uses; requests run as usual until Init sets it
+var Default *Runner
+
+// Init sets Default to a runner over the async_jobs table, saving results to results.
+// A budget of zero or less turns the fallback off.
+func Init(results ResultStore, budget time.Duration) {
+	Default = NewRunner(NewPostgresStore(), results, budget)
+}
+
+// Runner turns requests that outlive their budget into jobs
+type Runner struct {
+	store       Store
+	results     ResultStore
+	budget      time.Duration
+	maxDuration time.Duration
+	retention   time.Duration
+	basePath    string // Where the jobs routes are mounted, for status URLs
+}
+
+// NewRunner creates a runner with DefaultMaxDuration, DefaultRetention and status URLs under /api/v1/jobs
+func NewRunner(store Store, results ResultStore, budget time.Duration) *Runner {
+	return &Runner{
+		store:       store,
+		results:     results,
+		budget:      budget,
+		maxDuration: DefaultMaxDuration,
+		retention:   DefaultRetention,
+		basePath:    "/api/v1/jobs",
+	}
+}
+
+// StatusURL is where a job's status is polled
+func (r *Runner) StatusURL(job *Job) string {
+	return r.basePath + "/" + job.ID.String()
+}
+
+// ResultURL is where a finished job's response is downloaded
+func (r *Runner) ResultURL(job *Job) string {
+	return r.StatusURL(job) + "/result"
+}
+
+// AcceptedResponse is sent when a request turns into a job
+type AcceptedResponse struct {
+	Job       *Job   `json:"job"`
+	StatusURL string `json:"statusUrl"`
+	ResultURL string `json:"resultUrl"`
+}
+
+// Async is route middleware for slow endpoints such as exports and reports. The
+// request runs as usual; if the handler has not finished within the runner's budget
+// the client gets 202 Accepted with the job's status URL, and the handler's response
+// is saved as the job's result when it finishes. Requests without a tenant, and all
+// requests before Init, always run to completion.
+func Async(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if Default == nil || Default.budget <= 0 {
+			return next(c)
+		}
+		return Default.run(c, next)
+	}
+}
+
+func (r *Runner) run(c echo.Context, next echo.HandlerFunc) error {
+	req := c.Request()
+	tenantID, ok := db.GetTenantID(req.Context())
+	if !ok {
+		return next(c)
+	}
+	job := newJob(tenantID, nil, req.Method, req.URL.RequestURI(), r.retention)
+	if userID, ok := db.GetUserID(req.Context()); ok {
+		job.UserID = &userID
+	}
+
+	// The handler runs on a context that outlives the connection, so it can finish after
+	// the client has its 202; until then a client that goes away still cancels it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), r.maxDuration)
+	defer cancel()
+	out := newCapture()
+	defer out.close()
+	stop := context.AfterFunc(req.Context(), func() {
+		if !out.isDetached() {
+			cancel()
+		}
+	})
+	defer stop()
+
+	response := c.Response()
+	client := response.Writer
+	response.Writer = out
+	c.SetRequest(req.WithContext(ctx))
+
+	timer := time.AfterFunc(r.budget, func() { r.detach(ctx, job, out, client) })
+	err := next(c)
+	timer.Stop()
+
+	if !out.finish() {
+		// Finished within the budget: the client gets the response as if nothing happened
+		response.Writer = client
+		if replayErr := out.replay(client); replayErr != nil {
+			return replayErr
+		}
+		return err
+	}
+
+	if err != nil && !response.Committed {
+		c.Echo().HTTPErrorHandler(err, c)
+	}
+	r.complete(ctx, job, out)
+	return nil
+}
+
+// detach turns the request into a job and tells the client where to find it. If the
+// job cannot be recorded the request carries on and the client keeps waiting.
+func (r *Runner) detach(ctx context.Context, job *Job, out *capture, client http.ResponseWriter) {
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	if out.done {
+		return
+	}
+	if err := r.store.Create(ctx, job); err != nil {
+		logger.Log.Error("Failed to start background job for " + job.Path + ": " + err.Error())
+		return
+	}
+	out.detached = true
+
+	body, _ := json.Marshal(AcceptedResponse{Job: job, StatusURL: r.StatusURL(job), ResultURL: r.ResultURL(job)})
+	header := client.Header()
+	header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	header.Set(echo.HeaderContentLength, strconv.Itoa(len(body)))
+	header.Set(echo.HeaderLocation, r.StatusURL(job))
+	// The handler still holds the connection, so the client should not reuse it
+	header.Set(echo.HeaderConnection, "close")
+	client.WriteHeader(http.StatusAccepted)
+	_, _ = client.Write(body)
+	if flusher, ok := client.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// complete saves the finished handler's response as the job's result
+func (r *Runner) complete(ctx context.Context, job *Job, out *capture) {
+	// The request context may have run out; saving the result gets its own time
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Minute)
+	defer cancel()
+
+	status := out.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	job.ContentType = out.header.Get(echo.HeaderContentType)
+	job.ContentDisposition = out.header.Get(echo.HeaderContentDisposition)
+	body, err := out.body()
+	if err == nil {
+		err = r.results.Put(ctx, job.resultKey(), body)
+	}
+	if err != nil {
+		logger.Log.Error("Failed to save result of job " + job.ID.String() + ": " + err.Error())
+		job.fail("The result could not be saved")
+	} else {
+		job.finish(status, out.size)
+	}
+	if err := r.store.Finish(ctx, job); err != nil {
+		logger.Log.Error("Failed to finish job " + job.ID.String() + ": " + err.Error())
+	}
+}
+
+// Get returns the user's job. A job left running past the maximum duration, because
+// the instance running it stopped, is reported as failed.
+func (r *Runner) Get(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID, id uuid.UUID) (*Job, error) {
+	job, err := r.store.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != nil && (userID == nil || *job.UserID != *userID) {
+		return nil, ErrJobNotFound
+	}
+	if job.Status == StatusRunning && time.Since(job.CreatedAt) > r.maxDuration+interruptedAfter {
+		job.fail("The job was interrupted before it finished")
+	}
+	return job, nil
+}
+
+// OpenResult opens a finished job's saved response
+func (r *Runner) OpenResult(ctx context.Context, job *Job) (io.ReadCloser, error) {
+	if job.Status == StatusRunning {
+		return nil, ErrJobRunning
+	}
+	return r.results.Open(ctx, job.resultKey())
+}
+
+// PurgeExpired removes jobs whose retention has passed, with their results
+func (r *Runner) PurgeExpired(ctx context.Context) error {
+	jobs, err := r.store.ListExpired(ctx, purgeBatch)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := r.results.Delete(ctx, job.resultKey()); err != nil {
+			return err
+		}
+		if err := r.store.Delete(ctx, job.TenantID, job.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartPurgeWorker removes expired jobs hourly. Call once after Init.
+func StartPurgeWorker() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if Default == nil {
+				continue
+			}
+			if err := Default.PurgeExpired(context.Background()); err != nil {
+				logger.Log.Error("Job purge error: " + err.Error())
+			}
+		}
+	}()
+}
+
+// capture is the response writer a handler writes to while it might become a job.
+// Only the handler's goroutine writes to it; the lock guards the hand-over between
+// finishing in time and turning into a job.
+type capture struct {
+	mu       sync.Mutex
+	done     bool
+	detached bool
+
+	header http.Header
+	status int
+	buf    bytes.Buffer
+	file   *os.File // Holds the response once it passes memoryLimit
+	size   int64
+	err    error
+}
+
+func newCapture() *capture {
+	return &capture{header: make(http.Header)}
+}
+
+func (w *capture) Header() http.Header {
+	return w.header
+}
+
+func (w *capture) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *capture) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.file == nil && w.buf.Len()+len(p) > memoryLimit {
+		w.file, w.err = os.CreateTemp("", "job-*")
+		if w.err == nil {
+			_, w.err = w.buf.WriteTo(w.file)
+		}
+		if w.err != nil {
+			return 0, w.err
+		}
+	}
+	var n int
+	if w.file != nil {
+		n, w.err = w.file.Write(p)
+	} else {
+		n, _ = w.buf.Write(p)
+	}
+	w.size += int64(n)
+	return n, w.err
+}
+
+// Flush is a no-op: nothing reaches the client until the handler finishes or turns into a job
+func (w *capture) Flush() {}
+
+func (w *capture) isDetached() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.detached
+}
+
+// finish marks the handler done and reports whether it had turned into a job
+func (w *capture) finish() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.done = true
+	return w.detached
+}
+
+// body reads back what the handler wrote
+func (w *capture) body() (io.Reader, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	if w.file == nil {
+		return &w.buf, nil
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return w.file, nil
+}
+
+// replay sends what the handler wrote to the client
+func (w *capture) replay(client http.ResponseWriter) error {
+	for key, values := range w.header {
+		client.Header()[key] = values
+	}
+	if w.status == 0 {
+		return nil
+	}
+	client.WriteHeader(w.status)
+	body, err := w.body()
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(client, body)
+	return err
+}
+
+func (w *capture) close() {
+	if w.file != nil {
+		w.file.Close()
+		os.Remove(w.file.Name())
+	}
+}
@@ -0,0 +1,181 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresStore keeps jobs in the async_jobs table
+type PostgresStore struct{}
+
+// NewPostgresStore creates a job store over db.MainPool
+func NewPostgresStore() *PostgresStore {
+	return &PostgresStore{}
+}
+
+const jobColumns = `id, tenant_id, user_id, method, path, status, status_code, content_type,
+	content_disposition, size, error, created_at, finished_at, expires_at`
+
+// Create inserts a running job
+func (s *PostgresStore) Create(ctx context.Context, job *Job) error {
+	query := `INSERT INTO async_jobs (` + jobColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+	_, err := db.MainPool.Exec(ctx, query,
+		job.ID, job.TenantID, job.UserID, job.Method, job.Path, job.Status, job.StatusCode, job.ContentType,
+		job.ContentDisposition, job.Size, job.Error, job.CreatedAt, job.FinishedAt, job.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+// Get returns the tenant's job, or ErrJobNotFound
+func (s *PostgresStore) Get(ctx context.Context, tenantID, id uuid.UUID) (*Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM async_jobs WHERE id = $1 AND tenant_id = $2 AND expires_at > NOW()`
+	job, err := scanJob(db.MainPool.QueryRow(ctx, query, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	return job, err
+}
+
+// Finish saves a finished job's status and result details
+func (s *PostgresStore) Finish(ctx context.Context, job *Job) error {
+	query := `
+		UPDATE async_jobs
+		SET status = $1, status_code = $2, content_type = $3, content_disposition = $4,
+		    size = $5, error = $6, finished_at = $7
+		WHERE id = $8 AND tenant_id = $9
+	`
+	_, err := db.MainPool.Exec(ctx, query,
+		job.Status, job.StatusCode, job.ContentType, job.ContentDisposition,
+		job.Size, job.Error, job.FinishedAt, job.ID, job.TenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	return nil
+}
+
+// ListExpired returns up to limit jobs whose retention has passed. Without a tenant in
+// the context it reads the home region only.
+func (s *PostgresStore) ListExpired(ctx context.Context, limit int) ([]*Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM async_jobs WHERE expires_at <= NOW() ORDER BY expires_at LIMIT $1`
+	rows, err := db.MainPool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// Delete removes a job
+func (s *PostgresStore) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	if _, err := db.MainPool.Exec(ctx, `DELETE FROM async_jobs WHERE id = $1 AND tenant_id = $2`, id, tenantID); err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	return nil
+}
+
+func scanJob(row pgx.Row) (*Job, error) {
+	var job Job
+	err := row.Scan(
+		&job.ID, &job.TenantID, &job.UserID, &job.Method, &job.Path, &job.Status, &job.StatusCode, &job.ContentType,
+		&job.ContentDisposition, &job.Size, &job.Error, &job.CreatedAt, &job.FinishedAt, &job.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan job: %w", err)
+	}
+	return &job, nil
+}
+
+// DirStore keeps job results as files under a directory, which API instances share
+// when it is on a common volume
+type DirStore struct {
+	root string
+}
+
+// NewDirStore creates a result store rooted at dir
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create job result directory: %w", err)
+	}
+	return &DirStore{root: dir}, nil
+}
+
+// Put writes a result through a temp file, so a half-written result is never opened
+func (s *DirStore) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create job result directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".result-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write job result: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write job result: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open opens a result for reading
+func (s *DirStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete removes a result; a missing one is not an error
+func (s *DirStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path maps a key to a file under the root, refusing keys that escape it
+func (s *DirStore) path(key string) (string, error) {
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(s.root)+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid job result key %q", key)
+	}
+	return path, nil
+}
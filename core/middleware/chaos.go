@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Headers a client sends to ask for a fault on one request, when ChaosConfig.AllowHeaders is set
+const (
+	HeaderChaosLatency     = "X-Chaos-Latency"      // Delay, e.g. 800ms or 2s
+	HeaderChaosErrorRate   = "X-Chaos-Error-Rate"   // Chance of an error response, 0 to 1
+	HeaderChaosErrorStatus = "X-Chaos-Error-Status" // Status of the error response; defaults to 503
+	HeaderChaosTimeoutRate = "X-Chaos-Timeout-Rate" // Chance the request hangs until MaxHang and then fails with 504
+	// HeaderChaosInjected is set on a response that had a fault injected, naming the faults
+	HeaderChaosInjected = "X-Chaos-Injected"
+)
+
+// DefaultChaosMaxHang is how long an injected timeout holds a request when MaxHang is not set
+const DefaultChaosMaxHang = 30 * time.Second
+
+// maxChaosLatency bounds an injected delay so a typo cannot tie up a server
+const maxChaosLatency = 60 * time.Second
+
+// ChaosFault is the faults injected into matching requests
+type ChaosFault struct {
+	Latency     time.Duration // Added before the handler runs
+	Jitter      time.Duration // Random extra delay up to this much
+	ErrorRate   float64       // Chance, 0 to 1, of answering with ErrorStatus instead of running the handler
+	ErrorStatus int           // Defaults to 503
+	TimeoutRate float64       // Chance, 0 to 1, of holding the request until MaxHang and answering 504
+}
+
+// ChaosRule applies a fault to requests matching Pattern: an optional method and a
+// path, e.g. "GET /api/v1/products" or "/api/v1/invoices/*". A * matches one path
+// segment, except a trailing * which matches the rest of the path.
+type ChaosRule struct {
+	Pattern string
+	Fault   ChaosFault
+}
+
+// ChaosConfig configures Chaos
+type ChaosConfig struct {
+	Rules []ChaosRule // The first matching rule applies
+	// AllowHeaders lets clients ask for faults with the X-Chaos-* headers; header
+	// values override the matching rule's
+	AllowHeaders bool
+	MaxHang      time.Duration // Defaults to DefaultChaosMaxHang
+}
+
+// Chaos injects latency, errors and timeouts into API requests so clients' retry and
+// loading behavior can be tested against a slow or failing API. It is for staging and
+// local development only; never enable it in production.
+func Chaos(cfg ChaosConfig) echo.MiddlewareFunc {
+	if cfg.MaxHang <= 0 {
+		cfg.MaxHang = DefaultChaosMaxHang
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			var fault ChaosFault
+			for _, rule := range cfg.Rules {
+				if chaosMatch(rule.Pattern, req.Method, req.URL.Path) {
+					fault = rule.Fault
+					break
+				}
+			}
+			if cfg.AllowHeaders {
+				if err := fault.fromHeaders(req.Header); err != nil {
+					return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+				}
+			}
+
+			var injected []string
+			delay := fault.Latency
+			if fault.Jitter > 0 {
+				delay += rand.N(fault.Jitter)
+			}
+			if delay > 0 {
+				injected = append(injected, "latency="+delay.Round(time.Millisecond).String())
+				c.Response().Header().Set(HeaderChaosInjected, strings.Join(injected, ","))
+				select {
+				case <-time.After(delay):
+				case <-req.Context().Done():
+					return nil
+				}
+			}
+
+			if fault.TimeoutRate > 0 && rand.Float64() < fault.TimeoutRate {
+				injected = append(injected, "timeout")
+				c.Response().Header().Set(HeaderChaosInjected, strings.Join(injected, ","))
+				select {
+				case <-time.After(cfg.MaxHang):
+				case <-req.Context().Done():
+					return nil
+				}
+				return c.JSON(http.StatusGatewayTimeout, map[string]string{"error": "Injected timeout"})
+			}
+			if fault.ErrorRate > 0 && rand.Float64() < fault.ErrorRate {
+				status := fault.ErrorStatus
+				if status == 0 {
+					status = http.StatusServiceUnavailable
+				}
+				injected = append(injected, "error="+strconv.Itoa(status))
+				c.Response().Header().Set(HeaderChaosInjected, strings.Join(injected, ","))
+				return c.JSON(status, map[string]string{"error": "Injected failure"})
+			}
+			return next(c)
+		}
+	}
+}
+
+// fromHeaders overrides the fault with the X-Chaos-* headers the request sends
+func (f *ChaosFault) fromHeaders(h http.Header) error {
+	for _, name := range []string{HeaderChaosLatency, HeaderChaosErrorRate, HeaderChaosErrorStatus, HeaderChaosTimeoutRate} {
+		if v := h.Get(name); v != "" {
+			if err := f.set(chaosHeaderKeys[name], v); err != nil {
+				return fmt.Errorf("invalid %s header: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+var chaosHeaderKeys = map[string]string{
+	HeaderChaosLatency:     "latency",
+	HeaderChaosErrorRate:   "error",
+	HeaderChaosErrorStatus: "status",
+	HeaderChaosTimeoutRate: "timeout",
+}
+
+// set sets one fault setting by its ParseChaosRules key
+func (f *ChaosFault) set(key, value string) error {
+	switch key {
+	case "latency", "jitter":
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 || d > maxChaosLatency {
+			return fmt.Errorf("%s must be a duration up to %s, e.g. 500ms", key, maxChaosLatency)
+		}
+		if key == "latency" {
+			f.Latency = d
+		} else {
+			f.Jitter = d
+		}
+	case "error", "timeout":
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return fmt.Errorf("%s rate must be between 0 and 1", key)
+		}
+		if key == "error" {
+			f.ErrorRate = rate
+		} else {
+			f.TimeoutRate = rate
+		}
+	case "status":
+		status, err := strconv.Atoi(value)
+		if err != nil || status < 400 || status > 599 {
+			return fmt.Errorf("status must be an HTTP error status")
+		}
+		f.ErrorStatus = status
+	default:
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	return nil
+}
+
+// ParseChaosRules reads rules given as pattern=setting:value pairs separated by
+// semicolons, e.g. "GET /api/v1/products*=latency:800ms,jitter:400ms;/api/v1/invoices*=error:0.2,status:500,timeout:0.05"
+func ParseChaosRules(s string) ([]ChaosRule, error) {
+	var rules []ChaosRule
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, settings, ok := strings.Cut(entry, "=")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("invalid chaos rule %q, expected pattern=setting:value,...", entry)
+		}
+		rule := ChaosRule{Pattern: pattern}
+		for _, setting := range strings.Split(settings, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(setting), ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid chaos rule %q: setting %q is not key:value", entry, setting)
+			}
+			if err := rule.Fault.set(strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid chaos rule %q: %w", entry, err)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// chaosMatch reports whether a rule pattern matches the request
+func chaosMatch(pattern, method, urlPath string) bool {
+	if m, p, ok := strings.Cut(pattern, " "); ok {
+		if !strings.EqualFold(m, method) {
+			return false
+		}
+		pattern = strings.TrimSpace(p)
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok && !strings.Contains(prefix, "*") {
+		return strings.HasPrefix(urlPath, prefix)
+	}
+	matched, _ := path.Match(pattern, urlPath)
+	return matched
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Plan features modules gate their routes on with RequireFeature
+const (
+	FeatureAccounting    = "accounting"
+	FeatureNotifications = "notifications"
+)
+
+// FeatureGuard builds the middleware that lets requests through only when the tenant's
+// plan includes a feature
+type FeatureGuard func(feature string) echo.MiddlewareFunc
+
+var featureGuard FeatureGuard
+
+// SetFeatureGuard registers the plan feature check. It is set by the subscription module
+// at startup; core and the gated modules cannot depend on it directly.
+func SetFeatureGuard(guard FeatureGuard) {
+	featureGuard = guard
+}
+
+// RequireFeature switches a module's routes on or off by the tenant's plan. The guard
+// is looked up per request, so routes can be registered before it is set; until then
+// requests are refused with 403, as the plan cannot be checked. Once set, a request
+// whose plan cannot be looked up is refused too.
+func RequireFeature(feature string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if featureGuard == nil {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Plan features are not available"})
+			}
+			return featureGuard(feature)(next)(c)
+		}
+	}
+}
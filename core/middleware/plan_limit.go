@@ -0,0 +1,35 @@
+package middleware
+
+import "github.com/labstack/echo/v4"
+
+// Plan limit keys of the creations modules guard with PlanLimit
+const (
+	PlanLimitUsers            = "max_users"
+	PlanLimitProducts         = "max_products"
+	PlanLimitInvoicesPerMonth = "max_invoices_per_month"
+)
+
+// PlanLimitGuard builds the middleware that blocks creating one more of a plan limit key
+type PlanLimitGuard func(limitKey string) echo.MiddlewareFunc
+
+var planLimitGuard PlanLimitGuard
+
+// SetPlanLimitGuard registers the plan limit check. It is set by the subscription module
+// at startup; core and the modules creating limited records cannot depend on it directly.
+func SetPlanLimitGuard(guard PlanLimitGuard) {
+	planLimitGuard = guard
+}
+
+// PlanLimit blocks a creation once the tenant has reached their plan's limit for
+// limitKey. The guard is looked up per request, so routes can be registered before it
+// is set; until then requests pass unchecked.
+func PlanLimit(limitKey string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if planLimitGuard == nil {
+				return next(c)
+			}
+			return planLimitGuard(limitKey)(next)(c)
+		}
+	}
+}
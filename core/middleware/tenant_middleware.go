@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -112,38 +113,43 @@ func extractTenantFromJWT(c echo.Context) (uuid.UUID, error) {
 	return tenantID, nil
 }
 
-// extractTenantFromSubdomain extracts tenant ID from subdomain
+// DomainResolver maps a request host (tenant subdomain or custom domain) to a tenant
+type DomainResolver interface {
+	ResolveHost(ctx context.Context, host string) (uuid.UUID, error)
+}
+
+var domainResolver DomainResolver
+
+// SetDomainResolver registers the lookup used for host-based tenant resolution.
+// It is set by the identity module at startup; core cannot depend on identity directly.
+func SetDomainResolver(resolver DomainResolver) {
+	domainResolver = resolver
+}
+
+// extractTenantFromSubdomain extracts tenant ID from the request host
+// (e.g. tenant1.example.com or a verified custom domain like shop.tenant1.com)
 func extractTenantFromSubdomain(c echo.Context) (uuid.UUID, error) {
-	host := c.Request().Host
+	host := strings.ToLower(c.Request().Host)
 
 	// Remove port if present
 	if idx := strings.Index(host, ":"); idx != -1 {
 		host = host[:idx]
 	}
 
-	// Split by dots
-	parts := strings.Split(host, ".")
-
-	// If we have at least 3 parts (subdomain.domain.tld), extract subdomain
-	if len(parts) >= 3 {
-		subdomain := parts[0]
-
-		// Skip common subdomains
-		if subdomain == "www" || subdomain == "api" || subdomain == "admin" {
-			return uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid subdomain")
-		}
-
-		// TODO: Look up tenant by subdomain in database
-		// For now, we'll assume subdomain is the tenant slug
-		// You should implement a tenant lookup service
+	if host == "" || !strings.Contains(host, ".") {
+		return uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "No subdomain found")
+	}
 
-		// Example: Query database for tenant by subdomain
-		// tenantID, err := tenantService.GetTenantIDBySubdomain(subdomain)
+	if domainResolver == nil {
+		return uuid.Nil, echo.NewHTTPError(http.StatusNotImplemented, "Subdomain lookup not configured")
+	}
 
-		return uuid.Nil, echo.NewHTTPError(http.StatusNotImplemented, "Subdomain lookup not implemented")
+	tenantID, err := domainResolver.ResolveHost(c.Request().Context(), host)
+	if err != nil {
+		return uuid.Nil, echo.NewHTTPError(http.StatusNotFound, "Unknown tenant domain")
 	}
 
-	return uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "No subdomain found")
+	return tenantID, nil
 }
 
 // extractTenantFromHeader extracts tenant ID from X-Tenant-ID header
@@ -0,0 +1,14 @@
+-- Migration: Request nonces for replay protection
+-- Webhook signatures and API request nonces are claimed here for the length of their
+-- tolerance window, so a captured request cannot be sent again to any API instance.
+-- Not tenant-scoped: webhooks arrive before a tenant is known.
+
+CREATE TABLE IF NOT EXISTS request_nonces (
+    scope VARCHAR(100) NOT NULL, -- integration name, or api:<tenant id>
+    nonce VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (scope, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS idx_request_nonces_expiry ON request_nonces(expires_at);
@@ -0,0 +1,7 @@
+-- Migration: Tenant data region
+-- The region whose database holds the tenant's data, e.g. np. Tenants and the other
+-- platform tables stay in the home region's database; everything else the tenant
+-- owns lives in its region's database, which runs the same module migrations.
+-- Fixed at registration: moving a tenant means moving its data first.
+
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS data_region VARCHAR(20) NOT NULL DEFAULT 'np';
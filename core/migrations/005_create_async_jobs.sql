@@ -0,0 +1,31 @@
+-- Migration: Background jobs for slow requests
+-- A request that runs past its execution budget becomes a job: the client gets 202 with
+-- a status URL, and the response is saved for download when the handler finishes.
+
+CREATE TABLE IF NOT EXISTS async_jobs (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    user_id UUID, -- Only this user sees the job; NULL for a request without a user
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL, -- Request path with its query
+    status VARCHAR(20) NOT NULL DEFAULT 'running', -- running, succeeded, failed
+    status_code INTEGER NOT NULL DEFAULT 0, -- HTTP status the request finished with
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    content_disposition VARCHAR(500) NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL -- The job and its saved response are removed after this
+);
+
+CREATE INDEX IF NOT EXISTS idx_async_jobs_tenant ON async_jobs(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_async_jobs_expiry ON async_jobs(expires_at);
+
+ALTER TABLE async_jobs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON async_jobs
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,168 @@
+// Package pdf is a minimal PDF writer for business documents (invoices, receipts).
+// It supports A4 pages with Helvetica text, lines and filled rectangles, which is
+// enough for tabular documents without pulling in a full layout engine.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Document is a PDF under construction
+type Document struct {
+	pages []*Page
+	title string
+}
+
+// Page is a single page; coordinates are in points with the origin at the top-left
+type Page struct {
+	content bytes.Buffer
+}
+
+// New creates an empty document
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+// AddPage appends a blank A4 page
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Text draws text with its baseline at (x, y)
+func (p *Page) Text(x, y, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PageHeight-y, escape(text))
+}
+
+// TextRight draws text so that it ends at x
+func (p *Page) TextRight(x, y, size float64, bold bool, text string) {
+	p.Text(x-TextWidth(text, size), y, size, bold, text)
+}
+
+// Line draws a line between two points
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// FillRect fills a rectangle with a grey level (0 = black, 1 = white)
+func (p *Page) FillRect(x, y, w, h, grey float64) {
+	fmt.Fprintf(&p.content, "q %.2f g %.2f %.2f %.2f %.2f re f Q\n", grey, x, PageHeight-y-h, w, h)
+}
+
+// Watermark draws large light-grey bold text diagonally across the middle of the page
+// (e.g. "COPY 1 OF 2"). Draw it first so the page content prints over it.
+func (p *Page) Watermark(text string, size float64) {
+	const cos, sin = 0.7071, 0.7071 // 45 degrees
+	half := TextWidth(text, size) / 2
+	x := PageWidth/2 - half*cos
+	y := PageHeight/2 - half*sin
+	fmt.Fprintf(&p.content, "q 0.85 g BT /F2 %.2f Tf %.4f %.4f %.4f %.4f %.2f %.2f Tm (%s) Tj ET Q\n",
+		size, cos, sin, -sin, cos, x, y, escape(text))
+}
+
+// WriteTo serializes the document
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Fixed objects: 1 catalog, 2 page tree, 3-4 fonts, 5 info; pages follow in pairs
+	const firstPageObj = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+i*2)
+	}
+
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	obj(fmt.Sprintf("<< /Title (%s) /Producer (AceExtension) >>", escape(d.title)))
+
+	for i, p := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, firstPageObj+i*2+1))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// Bytes returns the serialized document
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	d.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// helveticaWidths are glyph widths (per 1000 em) for ASCII 32-126
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// TextWidth estimates the rendered width of text in Helvetica (bold is slightly
+// wider, but digits, which are what gets right-aligned, match)
+func TextWidth(text string, size float64) float64 {
+	total := 0
+	for _, r := range text {
+		if r >= 32 && r <= 126 {
+			total += helveticaWidths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// escape encodes text as a PDF literal string in WinAnsi; characters outside Latin-1 become '?'
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r < 32:
+			continue
+		case r < 256:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
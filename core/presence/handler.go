@@ -0,0 +1,190 @@
+package presence
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler serves presence heartbeats and edit locks
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a presence handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes adds the presence routes to an authenticated group
+func (h *Handler) RegisterRoutes(g *echo.Group) {
+	g.GET("/:entityType/:entityId", h.GetStatus)
+	g.POST("/:entityType/:entityId/heartbeat", h.Heartbeat)
+	g.DELETE("/:entityType/:entityId", h.Leave)
+	g.POST("/:entityType/:entityId/lock", h.Lock)
+	g.DELETE("/:entityType/:entityId/lock", h.Unlock)
+}
+
+// HeartbeatRequest is what a client sends while a record is open
+type HeartbeatRequest struct {
+	Activity Activity `json:"activity"` // viewing (default) or editing
+}
+
+// Heartbeat records that the user has a record open
+// @Summary Presence heartbeat
+// @Description Send every 15 seconds while a record is open. Returns who else has it open and
+// @Description a warning such as "Sita is editing". Subscribe to the realtime topic
+// @Description presence:<entityType>:<entityId> to hear of changes between heartbeats.
+// @Tags Presence
+// @Accept json
+// @Produce json
+// @Param entityType path string true "Entity type, e.g. invoice"
+// @Param entityId path string true "Entity ID"
+// @Param request body HeartbeatRequest false "Activity"
+// @Success 200 {object} Status
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/presence/{entityType}/{entityId}/heartbeat [post]
+func (h *Handler) Heartbeat(c echo.Context) error {
+	entity, userID, err := h.entity(c)
+	if err != nil {
+		return presenceError(c, err)
+	}
+
+	var req HeartbeatRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if req.Activity == "" {
+		req.Activity = ActivityViewing
+	}
+
+	status, err := h.service.Heartbeat(c.Request().Context(), entity, userID, req.Activity)
+	if err != nil {
+		return presenceError(c, err)
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// GetStatus returns who has a record open
+// @Summary Get record presence
+// @Tags Presence
+// @Produce json
+// @Param entityType path string true "Entity type, e.g. invoice"
+// @Param entityId path string true "Entity ID"
+// @Success 200 {object} Status
+// @Router /api/v1/presence/{entityType}/{entityId} [get]
+func (h *Handler) GetStatus(c echo.Context) error {
+	entity, userID, err := h.entity(c)
+	if err != nil {
+		return presenceError(c, err)
+	}
+
+	status, err := h.service.Status(c.Request().Context(), entity, userID)
+	if err != nil {
+		return presenceError(c, err)
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// Leave removes the user from a record and releases their lock
+// @Summary Leave record
+// @Tags Presence
+// @Param entityType path string true "Entity type, e.g. invoice"
+// @Param entityId path string true "Entity ID"
+// @Success 204
+// @Router /api/v1/presence/{entityType}/{entityId} [delete]
+func (h *Handler) Leave(c echo.Context) error {
+	entity, userID, err := h.entity(c)
+	if err != nil {
+		return presenceError(c, err)
+	}
+
+	if err := h.service.Leave(c.Request().Context(), entity, userID); err != nil {
+		return presenceError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Lock takes a record's edit lock
+// @Summary Lock record for editing
+// @Description Marks the user as editing and takes the edit lock, which heartbeats with
+// @Description activity editing keep alive. 409 with the status when another user holds it.
+// @Tags Presence
+// @Produce json
+// @Param entityType path string true "Entity type, e.g. invoice"
+// @Param entityId path string true "Entity ID"
+// @Success 200 {object} Status
+// @Failure 409 {object} Status
+// @Router /api/v1/presence/{entityType}/{entityId}/lock [post]
+func (h *Handler) Lock(c echo.Context) error {
+	entity, userID, err := h.entity(c)
+	if err != nil {
+		return presenceError(c, err)
+	}
+
+	status, err := h.service.Lock(c.Request().Context(), entity, userID)
+	if errors.Is(err, ErrLocked) {
+		return c.JSON(http.StatusConflict, status)
+	}
+	if err != nil {
+		return presenceError(c, err)
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// Unlock releases the user's edit lock on a record
+// @Summary Unlock record
+// @Tags Presence
+// @Param entityType path string true "Entity type, e.g. invoice"
+// @Param entityId path string true "Entity ID"
+// @Success 204
+// @Router /api/v1/presence/{entityType}/{entityId}/lock [delete]
+func (h *Handler) Unlock(c echo.Context) error {
+	entity, userID, err := h.entity(c)
+	if err != nil {
+		return presenceError(c, err)
+	}
+
+	if err := h.service.Unlock(c.Request().Context(), entity, userID); err != nil {
+		return presenceError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// errUnauthenticated is returned when the request has no tenant or user
+var errUnauthenticated = errors.New("tenant or user not found")
+
+// entity reads the record from the path and the user from the request context
+func (h *Handler) entity(c echo.Context) (Entity, uuid.UUID, error) {
+	ctx := c.Request().Context()
+	tenantID, ok := db.GetTenantID(ctx)
+	if !ok {
+		return Entity{}, uuid.Nil, errUnauthenticated
+	}
+	userID, ok := db.GetUserID(ctx)
+	if !ok {
+		return Entity{}, uuid.Nil, errUnauthenticated
+	}
+
+	id, err := uuid.Parse(c.Param("entityId"))
+	if err != nil {
+		return Entity{}, uuid.Nil, ErrInvalidEntity
+	}
+	entity := Entity{TenantID: tenantID, Type: c.Param("entityType"), ID: id}
+	return entity, userID, entity.Validate()
+}
+
+// presenceError maps presence errors to HTTP statuses
+func presenceError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errUnauthenticated):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrInvalidEntity), errors.Is(err, ErrInvalidActivity):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update presence"})
+	}
+}
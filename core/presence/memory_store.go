@@ -0,0 +1,159 @@
+package presence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore keeps presence in process memory. It suits a single API instance;
+// use RedisStore when several instances serve the same tenants.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]map[uuid.UUID]Entry
+	locks     map[string]Lock
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]map[uuid.UUID]Entry),
+		locks:   make(map[string]Lock),
+	}
+}
+
+// Touch adds or refreshes the entry
+func (s *MemoryStore) Touch(_ context.Context, scope string, entry Entry) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepLocked(time.Now())
+
+	users := s.entries[scope]
+	if users == nil {
+		users = make(map[uuid.UUID]Entry)
+		s.entries[scope] = users
+	}
+
+	var previous *Entry
+	if old, ok := users[entry.UserID]; ok && time.Now().Before(old.ExpiresAt) {
+		previous = &old
+		if old.Activity == entry.Activity {
+			entry.Since = old.Since
+		}
+	}
+	users[entry.UserID] = entry
+	return previous, nil
+}
+
+// Remove deletes the user's entry
+func (s *MemoryStore) Remove(_ context.Context, scope string, userID uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := s.entries[scope]
+	old, ok := users[userID]
+	if !ok {
+		return false, nil
+	}
+	delete(users, userID)
+	if len(users) == 0 {
+		delete(s.entries, scope)
+	}
+	return time.Now().Before(old.ExpiresAt), nil
+}
+
+// List returns unexpired entries, longest present first
+func (s *MemoryStore) List(_ context.Context, scope string) ([]Entry, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	entries := make([]Entry, 0, len(s.entries[scope]))
+	for _, entry := range s.entries[scope] {
+		if now.Before(entry.ExpiresAt) {
+			entries = append(entries, entry)
+		}
+	}
+	s.mu.Unlock()
+
+	sortEntries(entries)
+	return entries, nil
+}
+
+// AcquireLock takes or refreshes the lock unless another user holds it
+func (s *MemoryStore) AcquireLock(_ context.Context, scope string, lock Lock) (*Lock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.locks[scope]; ok && time.Now().Before(current.ExpiresAt) {
+		if current.UserID != lock.UserID {
+			return &current, nil
+		}
+		lock.AcquiredAt = current.AcquiredAt
+	}
+	s.locks[scope] = lock
+	return &lock, nil
+}
+
+// ReleaseLock deletes the lock if the user holds it
+func (s *MemoryStore) ReleaseLock(_ context.Context, scope string, userID uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.locks[scope]
+	if !ok || current.UserID != userID {
+		return false, nil
+	}
+	delete(s.locks, scope)
+	return time.Now().Before(current.ExpiresAt), nil
+}
+
+// GetLock returns the unexpired lock
+func (s *MemoryStore) GetLock(_ context.Context, scope string) (*Lock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.locks[scope]
+	if !ok || !time.Now().Before(current.ExpiresAt) {
+		return nil, nil
+	}
+	return &current, nil
+}
+
+// sweepLocked drops expired entries and locks at most once a minute, so memory
+// stays bounded by the records open within the TTL
+func (s *MemoryStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for scope, users := range s.entries {
+		for userID, entry := range users {
+			if !now.Before(entry.ExpiresAt) {
+				delete(users, userID)
+			}
+		}
+		if len(users) == 0 {
+			delete(s.entries, scope)
+		}
+	}
+	for scope, lock := range s.locks {
+		if !now.Before(lock.ExpiresAt) {
+			delete(s.locks, scope)
+		}
+	}
+}
+
+// sortEntries orders entries by when their activity started, then by user
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Since.Equal(entries[j].Since) {
+			return entries[i].Since.Before(entries[j].Since)
+		}
+		return entries[i].UserID.String() < entries[j].UserID.String()
+	})
+}
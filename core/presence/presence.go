@@ -0,0 +1,143 @@
+// Package presence tracks who is viewing or editing a record, so a user opening
+// an invoice draft someone else has open sees "Sita is editing" before their
+// changes collide.
+//
+// Clients send a heartbeat every HeartbeatInterval while a record is open, with
+// their activity (viewing or editing), and leave when they close it. An entry
+// not refreshed within the TTL expires, so a closed tab disappears on its own.
+// Warnings are advisory; a client that must keep others out takes the record's
+// edit lock, which its heartbeats keep alive while it is editing.
+//
+// Every change (a user arriving, switching activity, leaving, or a lock being
+// taken or released) is published on the event bus under Topic(entityType,
+// entityID), so clients subscribed through the realtime hub update at once.
+// Entries that expire are not announced; clients drop users whose expiresAt
+// has passed.
+//
+// Presence is kept in Redis when REDIS_URL is set, so every API instance sees
+// the same users, and in process memory otherwise.
+package presence
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// HeartbeatInterval is how often clients should send a heartbeat
+	HeartbeatInterval = 15 * time.Second
+	// DefaultTTL is how long an entry or lock lives without a heartbeat
+	DefaultTTL = 45 * time.Second
+	// EventUpdated is the event type published when presence on a record changes
+	EventUpdated = "presence.updated"
+)
+
+var (
+	// ErrInvalidEntity is returned for a malformed entity type
+	ErrInvalidEntity = errors.New("invalid entity type")
+	// ErrInvalidActivity is returned for an activity other than viewing or editing
+	ErrInvalidActivity = errors.New("activity must be viewing or editing")
+	// ErrLocked is returned when another user holds the record's edit lock
+	ErrLocked = errors.New("record is locked by another user")
+)
+
+// entityTypePattern is what an entity type looks like, e.g. invoice or purchase_order.
+// Eighteen characters keep Topic within the realtime hub's 64-character limit.
+var entityTypePattern = regexp.MustCompile(`^[a-z][a-z_]{0,17}$`)
+
+// Activity is what a user is doing with a record
+type Activity string
+
+const (
+	ActivityViewing Activity = "viewing"
+	ActivityEditing Activity = "editing"
+)
+
+// Valid reports whether the activity is known
+func (a Activity) Valid() bool {
+	return a == ActivityViewing || a == ActivityEditing
+}
+
+// Entity identifies a record within a tenant
+type Entity struct {
+	TenantID uuid.UUID
+	Type     string // e.g. invoice
+	ID       uuid.UUID
+}
+
+// Validate checks the entity type
+func (e Entity) Validate() error {
+	if !entityTypePattern.MatchString(e.Type) {
+		return ErrInvalidEntity
+	}
+	return nil
+}
+
+// scope is the store key of the entity's presence
+func (e Entity) scope() string {
+	return e.TenantID.String() + ":" + e.Type + ":" + e.ID.String()
+}
+
+// Topic is the realtime topic presence changes on a record are published under
+func Topic(entityType string, entityID uuid.UUID) string {
+	return "presence:" + entityType + ":" + entityID.String()
+}
+
+// Entry is one user present on a record
+type Entry struct {
+	UserID    uuid.UUID `json:"userId"`
+	Name      string    `json:"name"`
+	Activity  Activity  `json:"activity"`
+	Since     time.Time `json:"since"` // When the user started the current activity
+	LastSeen  time.Time `json:"lastSeen"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Lock is a record's edit lock
+type Lock struct {
+	UserID     uuid.UUID `json:"userId"`
+	Name       string    `json:"name"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Snapshot is everyone present on a record; it is the data of EventUpdated
+type Snapshot struct {
+	EntityType string    `json:"entityType"`
+	EntityID   uuid.UUID `json:"entityId"`
+	Users      []Entry   `json:"users"`
+	Lock       *Lock     `json:"lock,omitempty"`
+}
+
+// Status is a record's presence as one user sees it
+type Status struct {
+	Snapshot
+	Others  []Entry `json:"others"`            // Users other than the caller
+	Warning string  `json:"warning,omitempty"` // e.g. "Sita is editing"
+	Locked  bool    `json:"locked"`            // Another user holds the edit lock
+}
+
+// Store keeps entries and locks per record. Implementations drop expired entries
+// and locks themselves.
+type Store interface {
+	// Touch adds or refreshes an entry until its ExpiresAt and returns the entry it
+	// replaced, nil if the user was not present. An entry refreshed with the same
+	// activity keeps its Since.
+	Touch(ctx context.Context, scope string, entry Entry) (*Entry, error)
+	// Remove deletes the user's entry and reports whether there was one
+	Remove(ctx context.Context, scope string, userID uuid.UUID) (bool, error)
+	// List returns the unexpired entries
+	List(ctx context.Context, scope string) ([]Entry, error)
+
+	// AcquireLock takes the lock for lock.UserID, or refreshes it if they hold it, and
+	// returns the lock now in place, which is another user's if they hold it
+	AcquireLock(ctx context.Context, scope string, lock Lock) (*Lock, error)
+	// ReleaseLock deletes the lock if the user holds it and reports whether it did
+	ReleaseLock(ctx context.Context, scope string, userID uuid.UUID) (bool, error)
+	// GetLock returns the unexpired lock, nil when there is none
+	GetLock(ctx context.Context, scope string) (*Lock, error)
+}
@@ -0,0 +1,216 @@
+package presence
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// redisTimeout bounds a command when the context has no earlier deadline
+	redisTimeout = 2 * time.Second
+	// redisIdleConns is how many connections are kept open between commands
+	redisIdleConns = 8
+)
+
+// redisError is an error reply from the server
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// redisClient speaks the Redis protocol (RESP) over a small pool of connections.
+// It sends one command at a time per connection and supports only the replies
+// presence needs: strings, integers, nil and arrays of them.
+type redisClient struct {
+	addr     string
+	username string
+	password string
+	db       int
+	tls      *tls.Config
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// newRedisClient parses redis://[user:password@]host:port[/db] or rediss:// for TLS
+func newRedisClient(rawURL string) (*redisClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "redis" && u.Scheme != "rediss") {
+		return nil, fmt.Errorf("invalid redis url")
+	}
+
+	c := &redisClient{addr: u.Host, idle: make(chan *redisConn, redisIdleConns)}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if dbPath := strings.Trim(u.Path, "/"); dbPath != "" {
+		if c.db, err = strconv.Atoi(dbPath); err != nil || c.db < 0 {
+			return nil, fmt.Errorf("invalid redis database %q", dbPath)
+		}
+	}
+	if u.Scheme == "rediss" {
+		c.tls = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	}
+	return c, nil
+}
+
+// do sends a command and returns its reply: string, int64, nil or []any. An error
+// reply is returned as a redisError.
+func (c *redisClient) do(ctx context.Context, args ...string) (any, error) {
+	conn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > redisTimeout {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	reply, err := conn.command(args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection may be half-way through a reply; do not reuse it
+		conn.Close()
+		return nil, err
+	}
+	c.put(conn)
+	return reply, err
+}
+
+// get takes an idle connection or dials a new one
+func (c *redisClient) get(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: redisTimeout}
+	var raw net.Conn
+	var err error
+	if c.tls != nil {
+		raw, err = (&tls.Dialer{NetDialer: dialer, Config: c.tls}).DialContext(ctx, "tcp", c.addr)
+	} else {
+		raw, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	conn := &redisConn{Conn: raw, r: bufio.NewReader(raw), w: bufio.NewWriter(raw)}
+	_ = conn.SetDeadline(time.Now().Add(redisTimeout))
+	if c.password != "" {
+		args := []string{"AUTH", c.password}
+		if c.username != "" {
+			args = []string{"AUTH", c.username, c.password}
+		}
+		if _, err := conn.command(args...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if c.db != 0 {
+		if _, err := conn.command("SELECT", strconv.Itoa(c.db)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// put returns a connection to the pool, closing it when the pool is full
+func (c *redisClient) put(conn *redisConn) {
+	select {
+	case c.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// command writes a command as an array of bulk strings and reads the reply
+func (conn *redisConn) command(args ...string) (any, error) {
+	fmt.Fprintf(conn.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(conn.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := conn.w.Flush(); err != nil {
+		return nil, err
+	}
+	return conn.readReply()
+}
+
+func (conn *redisConn) readReply() (any, error) {
+	line, err := conn.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(conn.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]any, count)
+		var firstErr error
+		for i := range items {
+			// An error inside an array (e.g. from a script) is an item, not a failed read
+			item, err := conn.readReply()
+			var replyErr redisError
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			items[i] = item
+		}
+		return items, firstErr
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
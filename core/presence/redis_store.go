@@ -0,0 +1,202 @@
+package presence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// acquireLockScript sets the lock unless another user holds it, keeping the
+// acquisition time when the holder refreshes it, and returns the lock in place.
+// KEYS[1] lock key; ARGV[1] lock JSON, ARGV[2] user ID, ARGV[3] TTL in milliseconds.
+const acquireLockScript = `
+local current = redis.call('GET', KEYS[1])
+local value = ARGV[1]
+if current then
+	local holder = cjson.decode(current)
+	if holder.userId ~= ARGV[2] then
+		return current
+	end
+	local lock = cjson.decode(value)
+	lock.acquiredAt = holder.acquiredAt
+	value = cjson.encode(lock)
+end
+redis.call('SET', KEYS[1], value, 'PX', ARGV[3])
+return value
+`
+
+// releaseLockScript deletes the lock if the user holds it.
+// KEYS[1] lock key; ARGV[1] user ID.
+const releaseLockScript = `
+local current = redis.call('GET', KEYS[1])
+if current and cjson.decode(current).userId == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+// RedisStore keeps presence in Redis so every API instance sees the same users.
+// A record's entries are a hash of user ID to entry that expires with the last
+// heartbeat; its lock is a key that expires with the lock.
+type RedisStore struct {
+	client *redisClient
+	prefix string
+}
+
+// NewRedisStore connects lazily to the server at rawURL,
+// redis://[user:password@]host:port[/db] or rediss:// for TLS
+func NewRedisStore(rawURL string) (*RedisStore, error) {
+	client, err := newRedisClient(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisStore{client: client, prefix: "presence:"}, nil
+}
+
+// Ping checks the server is reachable
+func (s *RedisStore) Ping(ctx context.Context) error {
+	_, err := s.client.do(ctx, "PING")
+	return err
+}
+
+func (s *RedisStore) entriesKey(scope string) string { return s.prefix + scope }
+func (s *RedisStore) lockKey(scope string) string    { return s.prefix + scope + ":lock" }
+
+// Touch adds or refreshes the entry and extends the hash to the entry's expiry. A
+// race between two heartbeats of the same user only costs a duplicate event.
+func (s *RedisStore) Touch(ctx context.Context, scope string, entry Entry) (*Entry, error) {
+	key := s.entriesKey(scope)
+	field := entry.UserID.String()
+
+	reply, err := s.client.do(ctx, "HGET", key, field)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read presence: %w", err)
+	}
+	var previous *Entry
+	if old, ok := decodeEntry(reply); ok && time.Now().Before(old.ExpiresAt) {
+		previous = &old
+		if old.Activity == entry.Activity {
+			entry.Since = old.Since
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.client.do(ctx, "HSET", key, field, string(data)); err != nil {
+		return nil, fmt.Errorf("failed to save presence: %w", err)
+	}
+	if _, err := s.client.do(ctx, "PEXPIREAT", key, strconv.FormatInt(entry.ExpiresAt.UnixMilli(), 10)); err != nil {
+		return nil, fmt.Errorf("failed to save presence: %w", err)
+	}
+	return previous, nil
+}
+
+// Remove deletes the user's entry
+func (s *RedisStore) Remove(ctx context.Context, scope string, userID uuid.UUID) (bool, error) {
+	reply, err := s.client.do(ctx, "HDEL", s.entriesKey(scope), userID.String())
+	if err != nil {
+		return false, fmt.Errorf("failed to remove presence: %w", err)
+	}
+	removed, _ := reply.(int64)
+	return removed > 0, nil
+}
+
+// List returns unexpired entries and deletes expired ones
+func (s *RedisStore) List(ctx context.Context, scope string) ([]Entry, error) {
+	key := s.entriesKey(scope)
+	reply, err := s.client.do(ctx, "HGETALL", key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list presence: %w", err)
+	}
+	fields, _ := reply.([]any)
+
+	now := time.Now()
+	entries := make([]Entry, 0, len(fields)/2)
+	expired := []string{"HDEL", key}
+	for i := 0; i+1 < len(fields); i += 2 {
+		entry, ok := decodeEntry(fields[i+1])
+		if !ok || !now.Before(entry.ExpiresAt) {
+			if field, isString := fields[i].(string); isString {
+				expired = append(expired, field)
+			}
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(expired) > 2 {
+		_, _ = s.client.do(ctx, expired...)
+	}
+
+	sortEntries(entries)
+	return entries, nil
+}
+
+// AcquireLock takes or refreshes the lock unless another user holds it
+func (s *RedisStore) AcquireLock(ctx context.Context, scope string, lock Lock) (*Lock, error) {
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return nil, err
+	}
+	ttl := time.Until(lock.ExpiresAt).Milliseconds()
+	if ttl <= 0 {
+		ttl = 1
+	}
+
+	reply, err := s.client.do(ctx, "EVAL", acquireLockScript, "1", s.lockKey(scope),
+		string(data), lock.UserID.String(), strconv.FormatInt(ttl, 10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	current, ok := decodeLock(reply)
+	if !ok {
+		return nil, fmt.Errorf("failed to acquire lock: unexpected reply")
+	}
+	return &current, nil
+}
+
+// ReleaseLock deletes the lock if the user holds it
+func (s *RedisStore) ReleaseLock(ctx context.Context, scope string, userID uuid.UUID) (bool, error) {
+	reply, err := s.client.do(ctx, "EVAL", releaseLockScript, "1", s.lockKey(scope), userID.String())
+	if err != nil {
+		return false, fmt.Errorf("failed to release lock: %w", err)
+	}
+	released, _ := reply.(int64)
+	return released > 0, nil
+}
+
+// GetLock returns the lock; Redis expires it
+func (s *RedisStore) GetLock(ctx context.Context, scope string) (*Lock, error) {
+	reply, err := s.client.do(ctx, "GET", s.lockKey(scope))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock: %w", err)
+	}
+	lock, ok := decodeLock(reply)
+	if !ok {
+		return nil, nil
+	}
+	return &lock, nil
+}
+
+func decodeEntry(reply any) (Entry, bool) {
+	var entry Entry
+	data, ok := reply.(string)
+	if !ok || json.Unmarshal([]byte(data), &entry) != nil {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+func decodeLock(reply any) (Lock, bool) {
+	var lock Lock
+	data, ok := reply.(string)
+	if !ok || json.Unmarshal([]byte(data), &lock) != nil {
+		return Lock{}, false
+	}
+	return lock, true
+}
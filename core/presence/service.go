@@ -0,0 +1,247 @@
+package presence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/core/cache"
+	"github.com/aceextension/core/events"
+	"github.com/google/uuid"
+)
+
+// NameResolver returns the display name of a user. It is set by the identity
+// module at startup; core cannot depend on identity directly.
+type NameResolver func(ctx context.Context, userID uuid.UUID) (string, error)
+
+// Service records heartbeats and locks and announces changes on the event bus
+type Service struct {
+	store Store
+	bus   *events.Bus
+	ttl   time.Duration
+	names NameResolver
+	// Names are looked up on a user's first heartbeat and then reused
+	nameCache *cache.TTLCache[uuid.UUID, string]
+}
+
+// NewService creates a presence service over a store, publishing to bus (nil for none)
+func NewService(store Store, bus *events.Bus) *Service {
+	return &Service{
+		store:     store,
+		bus:       bus,
+		ttl:       DefaultTTL,
+		nameCache: cache.New[uuid.UUID, string](10*time.Minute, 10000),
+	}
+}
+
+// SetNameResolver sets how user names shown in warnings are looked up
+func (s *Service) SetNameResolver(names NameResolver) {
+	s.names = names
+}
+
+// Heartbeat records that the user has the record open for an activity and returns
+// who else has it open. While the user is editing, a lock they hold is kept alive;
+// switching to viewing releases it.
+func (s *Service) Heartbeat(ctx context.Context, entity Entity, userID uuid.UUID, activity Activity) (*Status, error) {
+	return s.heartbeat(ctx, entity, userID, activity, false)
+}
+
+// Leave removes the user from the record and releases their lock
+func (s *Service) Leave(ctx context.Context, entity Entity, userID uuid.UUID) error {
+	if err := entity.Validate(); err != nil {
+		return err
+	}
+
+	removed, err := s.store.Remove(ctx, entity.scope(), userID)
+	if err != nil {
+		return err
+	}
+	released, err := s.store.ReleaseLock(ctx, entity.scope(), userID)
+	if err != nil {
+		return err
+	}
+	if !removed && !released {
+		return nil
+	}
+
+	snapshot, err := s.snapshot(ctx, entity, nil, false)
+	if err != nil {
+		return err
+	}
+	s.publish(entity, snapshot)
+	return nil
+}
+
+// Status returns who has the record open, as the user sees it
+func (s *Service) Status(ctx context.Context, entity Entity, userID uuid.UUID) (*Status, error) {
+	if err := entity.Validate(); err != nil {
+		return nil, err
+	}
+	snapshot, err := s.snapshot(ctx, entity, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	return statusFor(snapshot, userID), nil
+}
+
+// Lock takes the record's edit lock for the user, who is marked as editing. When
+// another user holds it, the status is returned with ErrLocked.
+func (s *Service) Lock(ctx context.Context, entity Entity, userID uuid.UUID) (*Status, error) {
+	return s.heartbeat(ctx, entity, userID, ActivityEditing, true)
+}
+
+// heartbeat touches the user's entry, keeps or releases their lock, or takes it
+// when takeLock, and announces arrivals, activity changes and lock changes
+func (s *Service) heartbeat(ctx context.Context, entity Entity, userID uuid.UUID, activity Activity, takeLock bool) (*Status, error) {
+	if err := entity.Validate(); err != nil {
+		return nil, err
+	}
+	if !activity.Valid() {
+		return nil, ErrInvalidActivity
+	}
+
+	now := time.Now()
+	entry := Entry{
+		UserID:    userID,
+		Name:      s.userName(ctx, userID),
+		Activity:  activity,
+		Since:     now,
+		LastSeen:  now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+
+	previous, err := s.store.Touch(ctx, entity.scope(), entry)
+	if err != nil {
+		return nil, err
+	}
+	// Plain heartbeats are not announced
+	changed := previous == nil || previous.Activity != activity
+
+	lock, err := s.store.GetLock(ctx, entity.scope())
+	if err != nil {
+		return nil, err
+	}
+	held := lock != nil && lock.UserID == userID
+	switch {
+	case activity == ActivityEditing && (held || (takeLock && lock == nil)):
+		lock, err = s.store.AcquireLock(ctx, entity.scope(), Lock{UserID: userID, Name: entry.Name, AcquiredAt: now, ExpiresAt: entry.ExpiresAt})
+		changed = changed || (!held && lock != nil && lock.UserID == userID)
+	case held:
+		_, err = s.store.ReleaseLock(ctx, entity.scope(), userID)
+		lock, changed = nil, true
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := s.snapshot(ctx, entity, lock, true)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		s.publish(entity, snapshot)
+	}
+
+	status := statusFor(snapshot, userID)
+	if takeLock && status.Locked {
+		return status, ErrLocked
+	}
+	return status, nil
+}
+
+// Unlock releases the user's edit lock; the user stays present
+func (s *Service) Unlock(ctx context.Context, entity Entity, userID uuid.UUID) error {
+	if err := entity.Validate(); err != nil {
+		return err
+	}
+
+	released, err := s.store.ReleaseLock(ctx, entity.scope(), userID)
+	if err != nil || !released {
+		return err
+	}
+	snapshot, err := s.snapshot(ctx, entity, nil, false)
+	if err != nil {
+		return err
+	}
+	s.publish(entity, snapshot)
+	return nil
+}
+
+// snapshot lists the record's users with its lock, reading the lock unless haveLock
+func (s *Service) snapshot(ctx context.Context, entity Entity, lock *Lock, haveLock bool) (Snapshot, error) {
+	users, err := s.store.List(ctx, entity.scope())
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if !haveLock {
+		if lock, err = s.store.GetLock(ctx, entity.scope()); err != nil {
+			return Snapshot{}, err
+		}
+	}
+	return Snapshot{EntityType: entity.Type, EntityID: entity.ID, Users: users, Lock: lock}, nil
+}
+
+// publish announces the record's presence to clients subscribed to its topic
+func (s *Service) publish(entity Entity, snapshot Snapshot) {
+	if s.bus == nil {
+		return
+	}
+	s.bus.Publish(events.New(entity.TenantID, Topic(entity.Type, entity.ID), EventUpdated, snapshot))
+}
+
+// userName resolves a user's display name, falling back to "Someone"
+func (s *Service) userName(ctx context.Context, userID uuid.UUID) string {
+	if name, ok := s.nameCache.Get(userID); ok {
+		return name
+	}
+	if s.names == nil {
+		return "Someone"
+	}
+	name, err := s.names(ctx, userID)
+	if err != nil || name == "" {
+		return "Someone"
+	}
+	s.nameCache.Set(userID, name)
+	return name
+}
+
+// statusFor shows a snapshot to one user: the others present, a warning when
+// others are editing and whether someone else holds the lock
+func statusFor(snapshot Snapshot, userID uuid.UUID) *Status {
+	status := &Status{Snapshot: snapshot, Others: []Entry{}}
+	if status.Users == nil {
+		status.Users = []Entry{}
+	}
+
+	var editors []string
+	for _, entry := range snapshot.Users {
+		if entry.UserID == userID {
+			continue
+		}
+		status.Others = append(status.Others, entry)
+		if entry.Activity == ActivityEditing {
+			editors = append(editors, entry.Name)
+		}
+	}
+	status.Warning = editingWarning(editors)
+	status.Locked = snapshot.Lock != nil && snapshot.Lock.UserID != userID
+	if status.Locked && status.Warning == "" {
+		status.Warning = fmt.Sprintf("%s is editing", snapshot.Lock.Name)
+	}
+	return status
+}
+
+// editingWarning names who else is editing, e.g. "Sita is editing" or
+// "Sita and 2 others are editing"
+func editingWarning(editors []string) string {
+	switch len(editors) {
+	case 0:
+		return ""
+	case 1:
+		return editors[0] + " is editing"
+	case 2:
+		return editors[0] + " and " + editors[1] + " are editing"
+	default:
+		return fmt.Sprintf("%s and %d others are editing", editors[0], len(editors)-1)
+	}
+}
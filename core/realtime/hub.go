@@ -0,0 +1,247 @@
+// Package realtime pushes events from the event bus to connected clients so the
+// POS and dashboards update without refreshing.
+//
+// Clients connect over WebSocket (GET .../ws) or, where WebSockets are blocked,
+// Server-Sent Events (GET .../events), authenticating with their access token in
+// the access_token query parameter (browsers cannot set headers on either) or an
+// Authorization header. Each connection belongs to the tenant of its token and
+// only receives that tenant's events on the topics it subscribed to.
+//
+// Delivery is soft real-time: a client that falls behind is disconnected and
+// should reconnect and refetch, and events published while a client is offline
+// are not replayed.
+package realtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aceextension/core/events"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// sendBuffer is how many events may queue for a client before it is dropped
+	sendBuffer = 64
+	// heartbeatInterval keeps idle connections open through proxies and finds dead ones
+	heartbeatInterval = 25 * time.Second
+	// MaxTopics bounds the topics one connection may subscribe to
+	MaxTopics = 32
+	// maxTopicLength bounds a topic name
+	maxTopicLength = 64
+)
+
+var (
+	// ErrUnauthenticated is returned for a missing, invalid or expired token
+	ErrUnauthenticated = errors.New("invalid or expired token")
+	// ErrInvalidTopic is returned for an empty or overlong topic, or too many topics
+	ErrInvalidTopic = errors.New("invalid topic")
+)
+
+// Identity is who a connection belongs to
+type Identity struct {
+	UserID    uuid.UUID
+	TenantID  uuid.UUID
+	Role      string
+	ExpiresAt time.Time // The connection is closed when the token expires; zero for never
+}
+
+// Authenticator validates an access token. It is set by the identity module at
+// startup; core cannot depend on identity directly.
+type Authenticator func(ctx context.Context, token string) (*Identity, error)
+
+// Hub tracks connected clients by tenant and delivers events to them
+type Hub struct {
+	mu      sync.RWMutex
+	tenants map[uuid.UUID]map[*client]struct{}
+	auth    Authenticator
+}
+
+// NewHub creates a hub without clients. Connections are refused until an
+// authenticator is set.
+func NewHub() *Hub {
+	return &Hub{tenants: make(map[uuid.UUID]map[*client]struct{})}
+}
+
+// SetAuthenticator sets how access tokens are validated
+func (h *Hub) SetAuthenticator(auth Authenticator) {
+	h.auth = auth
+}
+
+// Attach delivers a bus's events to the hub's clients and returns the function that detaches it
+func (h *Hub) Attach(bus *events.Bus) func() {
+	return bus.Subscribe(h.Publish)
+}
+
+// Publish sends an event to the tenant's clients subscribed to its topic. A
+// client whose queue is full is disconnected rather than slowing the publisher.
+func (h *Hub) Publish(event events.Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.tenants[event.TenantID] {
+		if !c.subscribed(event.Topic) || !event.VisibleTo(c.identity.Role) {
+			continue
+		}
+		select {
+		case c.send <- event:
+		default:
+			c.close()
+		}
+	}
+}
+
+// Connections returns how many clients a tenant has connected
+func (h *Hub) Connections(tenantID uuid.UUID) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.tenants[tenantID])
+}
+
+// authenticate reads the access token of a connection request
+func (h *Hub) authenticate(c echo.Context) (*Identity, error) {
+	if h.auth == nil {
+		return nil, ErrUnauthenticated
+	}
+
+	token := c.QueryParam("access_token")
+	if token == "" {
+		token = strings.TrimSpace(strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer "))
+	}
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	identity, err := h.auth(c.Request().Context(), token)
+	if err != nil || identity == nil || identity.TenantID == uuid.Nil {
+		return nil, ErrUnauthenticated
+	}
+	return identity, nil
+}
+
+// connect registers a client subscribed to the comma-separated topics of the request
+func (h *Hub) connect(identity *Identity, topics string) (*client, error) {
+	c := &client{
+		identity: identity,
+		topics:   make(map[string]struct{}),
+		send:     make(chan events.Event, sendBuffer),
+		done:     make(chan struct{}),
+	}
+	if topics != "" {
+		if err := c.subscribe(strings.Split(topics, ",")); err != nil {
+			return nil, err
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tenants[identity.TenantID] == nil {
+		h.tenants[identity.TenantID] = make(map[*client]struct{})
+	}
+	h.tenants[identity.TenantID][c] = struct{}{}
+	return c, nil
+}
+
+// disconnect removes a client
+func (h *Hub) disconnect(c *client) {
+	c.close()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	clients := h.tenants[c.identity.TenantID]
+	delete(clients, c)
+	if len(clients) == 0 {
+		delete(h.tenants, c.identity.TenantID)
+	}
+}
+
+// client is one connection and its subscriptions
+type client struct {
+	identity  *Identity
+	mu        sync.RWMutex
+	topics    map[string]struct{}
+	send      chan events.Event
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *client) subscribed(topic string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.topics[topic]
+	return ok
+}
+
+// subscribe adds topics; none are added if any is invalid
+func (c *client) subscribe(topics []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	added := make(map[string]struct{})
+	for _, topic := range topics {
+		topic = strings.TrimSpace(topic)
+		if topic == "" || len(topic) > maxTopicLength {
+			return ErrInvalidTopic
+		}
+		if _, ok := c.topics[topic]; !ok {
+			added[topic] = struct{}{}
+		}
+	}
+	if len(c.topics)+len(added) > MaxTopics {
+		return ErrInvalidTopic
+	}
+
+	for topic := range added {
+		c.topics[topic] = struct{}{}
+	}
+	return nil
+}
+
+func (c *client) unsubscribe(topics []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, topic := range topics {
+		delete(c.topics, strings.TrimSpace(topic))
+	}
+}
+
+// subscriptions lists the client's topics by name
+func (c *client) subscriptions() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	topics := make([]string, 0, len(c.topics))
+	for topic := range c.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// expiry returns a channel that fires when the client's token expires, or never
+func (c *client) expiry() (<-chan time.Time, func()) {
+	if c.identity.ExpiresAt.IsZero() {
+		return nil, func() {}
+	}
+	timer := time.NewTimer(time.Until(c.identity.ExpiresAt))
+	return timer.C, func() { timer.Stop() }
+}
+
+// close signals the connection to end; safe to call more than once
+func (c *client) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// refuse answers a connection request without a valid token or topics
+func refuse(c echo.Context, err error) error {
+	status := http.StatusUnauthorized
+	if errors.Is(err, ErrInvalidTopic) {
+		status = http.StatusBadRequest
+	}
+	return c.JSON(status, map[string]string{"error": err.Error()})
+}
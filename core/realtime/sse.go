@@ -0,0 +1,64 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ServeEvents streams the topics in the comma-separated topics query parameter
+// as Server-Sent Events, named by topic with the event as JSON data. The
+// subscriptions are fixed for the connection; reconnect to change them.
+func (h *Hub) ServeEvents(c echo.Context) error {
+	identity, err := h.authenticate(c)
+	if err != nil {
+		return refuse(c, err)
+	}
+	cl, err := h.connect(identity, c.QueryParam("topics"))
+	if err != nil {
+		return refuse(c, err)
+	}
+	defer h.disconnect(cl)
+
+	header := c.Response().Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	// Stop proxies such as nginx from buffering the stream
+	header.Set("X-Accel-Buffering", "no")
+	c.Response().WriteHeader(http.StatusOK)
+	c.Response().Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	expired, stop := cl.expiry()
+	defer stop()
+
+	for {
+		select {
+		case event := <-cl.send:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Response(), "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Topic, data); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Response(), ": ping\n\n"); err != nil {
+				return nil
+			}
+		case <-expired:
+			fmt.Fprintf(c.Response(), "event: error\ndata: {\"error\":%q}\n\n", ErrUnauthenticated.Error())
+			c.Response().Flush()
+			return nil
+		case <-cl.done:
+			return nil
+		case <-c.Request().Context().Done():
+			return nil
+		}
+		c.Response().Flush()
+	}
+}
@@ -0,0 +1,117 @@
+package realtime
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aceextension/core/events"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+// maxClientMessage bounds what a client may send; its messages are only subscriptions
+const maxClientMessage = 4 << 10
+
+// ClientMessage is what a WebSocket client sends to manage its subscriptions
+type ClientMessage struct {
+	Action string   `json:"action"` // subscribe, unsubscribe or ping
+	Topics []string `json:"topics"`
+}
+
+// ServerMessage is what the hub sends over a WebSocket
+type ServerMessage struct {
+	Type   string        `json:"type"` // event, subscribed, error, ping or pong
+	Event  *events.Event `json:"event,omitempty"`
+	Topics []string      `json:"topics,omitempty"` // Current subscriptions, on subscribed
+	Error  string        `json:"error,omitempty"`
+}
+
+// ServeWebSocket upgrades an authenticated request to a WebSocket. Topics in the
+// comma-separated topics query parameter are subscribed at once; the client
+// changes them with subscribe and unsubscribe messages, each answered with its
+// current topics.
+func (h *Hub) ServeWebSocket(c echo.Context) error {
+	identity, err := h.authenticate(c)
+	if err != nil {
+		return refuse(c, err)
+	}
+	cl, err := h.connect(identity, c.QueryParam("topics"))
+	if err != nil {
+		return refuse(c, err)
+	}
+	defer h.disconnect(cl)
+
+	server := websocket.Server{
+		// Origin is not checked: the access token, which other sites cannot read, authenticates
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			ws.MaxPayloadBytes = maxClientMessage
+			go h.readWebSocket(ws, cl)
+			h.writeWebSocket(ws, cl)
+		},
+	}
+	server.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// readWebSocket applies the client's subscription messages until it disconnects
+func (h *Hub) readWebSocket(ws *websocket.Conn, cl *client) {
+	defer cl.close()
+
+	_ = websocket.JSON.Send(ws, ServerMessage{Type: "subscribed", Topics: cl.subscriptions()})
+	for {
+		var msg ClientMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			return
+		}
+
+		var reply ServerMessage
+		switch msg.Action {
+		case "subscribe":
+			if err := cl.subscribe(msg.Topics); err != nil {
+				reply = ServerMessage{Type: "error", Error: err.Error()}
+				break
+			}
+			reply = ServerMessage{Type: "subscribed", Topics: cl.subscriptions()}
+		case "unsubscribe":
+			cl.unsubscribe(msg.Topics)
+			reply = ServerMessage{Type: "subscribed", Topics: cl.subscriptions()}
+		case "ping":
+			reply = ServerMessage{Type: "pong"}
+		default:
+			reply = ServerMessage{Type: "error", Error: "unknown action"}
+		}
+		if err := websocket.JSON.Send(ws, reply); err != nil {
+			return
+		}
+	}
+}
+
+// writeWebSocket sends the client's events and heartbeats until it disconnects,
+// falls behind or its token expires
+func (h *Hub) writeWebSocket(ws *websocket.Conn, cl *client) {
+	defer ws.Close()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	expired, stop := cl.expiry()
+	defer stop()
+
+	for {
+		var msg ServerMessage
+		select {
+		case event := <-cl.send:
+			msg = ServerMessage{Type: "event", Event: &event}
+		case <-heartbeat.C:
+			msg = ServerMessage{Type: "ping"}
+		case <-expired:
+			_ = websocket.JSON.Send(ws, ServerMessage{Type: "error", Error: ErrUnauthenticated.Error()})
+			return
+		case <-cl.done:
+			return
+		}
+		if err := websocket.JSON.Send(ws, msg); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aceextension/core/config"
+)
+
+var (
+	// ErrNoEncryptionKey is returned when no encryption key is configured
+	ErrNoEncryptionKey = errors.New("no encryption key configured")
+	// ErrUnknownKey is returned when a value was sealed under a key no longer configured
+	ErrUnknownKey = errors.New("value was sealed with an unknown key")
+	// ErrInvalidSealedValue is returned for a value that is malformed or fails authentication
+	ErrInvalidSealedValue = errors.New("invalid sealed value")
+)
+
+// Cipher seals values at rest with AES-256-GCM. It holds a key ring: values are
+// sealed under the current key and opened with whichever key sealed them, so a key
+// is rotated by configuring the new one in front of the old and re-sealing.
+type Cipher struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewCipher parses a key ring of id=base64 pairs separated by commas, the current
+// key first, e.g. "k2=...,k1=...". Each key must be 32 bytes.
+func NewCipher(ring string) (*Cipher, error) {
+	c := &Cipher{keys: make(map[string]cipher.AEAD)}
+	for _, pair := range strings.Split(ring, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || id == "" || strings.Contains(id, ":") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %s must be 32 bytes, base64 encoded", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher for key %s: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher for key %s: %w", id, err)
+		}
+		if _, exists := c.keys[id]; exists {
+			return nil, fmt.Errorf("encryption key %s is listed twice", id)
+		}
+		if c.current == "" {
+			c.current = id
+		}
+		c.keys[id] = aead
+	}
+	if c.current == "" {
+		return nil, ErrNoEncryptionKey
+	}
+	return c, nil
+}
+
+// ConfigCipher builds the cipher of CREDENTIAL_KEYS
+func ConfigCipher() (*Cipher, error) {
+	if config.GlobalConfig == nil {
+		return nil, ErrNoEncryptionKey
+	}
+	return NewCipher(config.GlobalConfig.CredentialKeys)
+}
+
+// CurrentKey returns the ID of the key values are sealed under
+func (c *Cipher) CurrentKey() string {
+	return c.current
+}
+
+// Seal encrypts plaintext under the current key as "<key id>:<base64 nonce and
+// ciphertext>". The associated data is authenticated but not stored: a value sealed
+// for one record cannot be opened as another's.
+func (c *Cipher) Seal(plaintext, associatedData []byte) (string, error) {
+	aead := c.keys[c.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, associatedData)
+	return c.current + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value sealed by Seal under any key of the ring
+func (c *Cipher) Open(sealed string, associatedData []byte) ([]byte, error) {
+	id, encoded, ok := strings.Cut(sealed, ":")
+	if !ok {
+		return nil, ErrInvalidSealedValue
+	}
+	aead, ok := c.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, ErrInvalidSealedValue
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], associatedData)
+	if err != nil {
+		return nil, ErrInvalidSealedValue
+	}
+	return plaintext, nil
+}
+
+// SealedKey returns the ID of the key a sealed value was sealed under
+func SealedKey(sealed string) string {
+	id, _, _ := strings.Cut(sealed, ":")
+	return id
+}
@@ -0,0 +1,104 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
+	"github.com/google/uuid"
+)
+
+// Integrations whose credentials tenants configure for themselves
+const (
+	IntegrationCBMS   = "cbms"   // IRD Central Billing Monitoring System
+	IntegrationSMS    = "sms"    // SMS gateway account
+	IntegrationEmail  = "email"  // Email provider account
+	IntegrationESewa  = "esewa"  // eSewa merchant
+	IntegrationKhalti = "khalti" // Khalti merchant
+
+	IntegrationBackupS3 = "backup_s3" // Tenant's own S3-compatible bucket scheduled backups go to
+)
+
+// ErrNoCredentials is returned when a tenant has not configured an integration
+var ErrNoCredentials = errors.New("integration credentials not configured")
+
+// Credentials are a tenant's decrypted settings for one integration
+type Credentials struct {
+	Integration string
+	Values      map[string]string
+	// Previous holds the values replaced by the last rotation while they are still
+	// accepted, e.g. so callbacks signed with an old key verify; nil otherwise
+	Previous map[string]string
+}
+
+// Get returns a field's current value, or "" when it is not set
+func (c *Credentials) Get(field string) string {
+	return c.Values[field]
+}
+
+// CredentialStore hands connectors a tenant's decrypted integration credentials.
+// Identity sets its store at startup.
+type CredentialStore interface {
+	// Credentials returns ErrNoCredentials when the tenant has not configured the integration
+	Credentials(ctx context.Context, tenantID uuid.UUID, integration string) (*Credentials, error)
+}
+
+var (
+	credentialMu    sync.RWMutex
+	credentialStore CredentialStore
+)
+
+// SetCredentialStore sets where tenant integration credentials come from
+func SetCredentialStore(store CredentialStore) {
+	credentialMu.Lock()
+	defer credentialMu.Unlock()
+	credentialStore = store
+}
+
+// TenantCredentials returns a tenant's credentials for an integration. Without a
+// store every integration is unconfigured.
+func TenantCredentials(ctx context.Context, tenantID uuid.UUID, integration string) (*Credentials, error) {
+	credentialMu.RLock()
+	store := credentialStore
+	credentialMu.RUnlock()
+
+	if store == nil {
+		return nil, ErrNoCredentials
+	}
+	return store.Credentials(ctx, tenantID, integration)
+}
+
+// TenantSecrets reads a webhook's signing secrets from one field of the calling
+// tenant's integration credentials: the current value, then the previous one during
+// a rotation. Tenants that have not configured the integration fall back to
+// WEBHOOK_SECRETS, so platform-wide secrets keep working.
+func TenantSecrets(integration, field string) SecretSource {
+	fallback := ConfigSecrets(integration)
+	return func(ctx context.Context) ([]string, error) {
+		tenantID, ok := db.GetTenantID(ctx)
+		if !ok {
+			return fallback(ctx)
+		}
+
+		credentials, err := TenantCredentials(ctx, tenantID, integration)
+		if errors.Is(err, ErrNoCredentials) {
+			return fallback(ctx)
+		}
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to read %s credentials of tenant %s: %v", integration, tenantID, err))
+			return nil, err
+		}
+
+		secrets := []string{}
+		if value := credentials.Get(field); value != "" {
+			secrets = append(secrets, value)
+		}
+		if previous := credentials.Previous[field]; previous != "" && previous != credentials.Get(field) {
+			secrets = append(secrets, previous)
+		}
+		return secrets, nil
+	}
+}
@@ -0,0 +1,63 @@
+package security
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultMaxBodyBytes bounds a captured body; webhook payloads are small
+const DefaultMaxBodyBytes = 1 << 20
+
+// rawBodyKey is the echo context key the captured body is stored under
+const rawBodyKey = "security.raw_body"
+
+// CaptureRawBody reads the request body once, keeps the exact bytes for signature
+// checks and puts a fresh reader back so c.Bind still works. Bodies over maxBytes
+// (DefaultMaxBodyBytes when 0) are rejected with 413.
+func CaptureRawBody(maxBytes int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, err := captureBody(c, maxBytes); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// RawBody returns the body captured by CaptureRawBody or VerifyWebhook
+func RawBody(c echo.Context) ([]byte, bool) {
+	body, ok := c.Get(rawBodyKey).([]byte)
+	return body, ok
+}
+
+// captureBody returns the captured body, reading it first if no middleware has
+func captureBody(c echo.Context, maxBytes int64) ([]byte, error) {
+	if body, ok := RawBody(c); ok {
+		return body, nil
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+
+	req := c.Request()
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(req.Body, maxBytes+1))
+		req.Body.Close()
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Failed to read request body")
+		}
+		if int64(len(body)) > maxBytes {
+			return nil, echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Request body too large")
+		}
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	c.Set(rawBodyKey, body)
+	return body, nil
+}
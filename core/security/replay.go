@@ -0,0 +1,107 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aceextension/core/db"
+)
+
+// ReplayGuard remembers keys (nonces or signatures) for a window so a request can
+// be accepted only once
+type ReplayGuard interface {
+	// Claim records key under scope until ttl passes. It returns false when the key
+	// was already claimed and has not expired.
+	Claim(ctx context.Context, scope, key string, ttl time.Duration) (bool, error)
+	// Release forgets a claim, so a request whose processing failed can be retried
+	Release(ctx context.Context, scope, key string) error
+}
+
+// MemoryReplayGuard keeps claimed keys in process memory. It suits a single API
+// instance; use PostgresReplayGuard when several instances receive the same traffic.
+type MemoryReplayGuard struct {
+	mu        sync.Mutex
+	keys      map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryReplayGuard creates an in-memory replay guard
+func NewMemoryReplayGuard() *MemoryReplayGuard {
+	return &MemoryReplayGuard{keys: make(map[string]time.Time)}
+}
+
+// Claim records the key if it is new or expired
+func (g *MemoryReplayGuard) Claim(_ context.Context, scope, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	id := scope + "\x00" + key
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Drop expired keys at most once a minute so memory stays bounded by the window
+	if now.Sub(g.lastSweep) > time.Minute {
+		for k, expiresAt := range g.keys {
+			if now.After(expiresAt) {
+				delete(g.keys, k)
+			}
+		}
+		g.lastSweep = now
+	}
+
+	if expiresAt, ok := g.keys[id]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+	g.keys[id] = now.Add(ttl)
+	return true, nil
+}
+
+// Release forgets the key
+func (g *MemoryReplayGuard) Release(_ context.Context, scope, key string) error {
+	g.mu.Lock()
+	delete(g.keys, scope+"\x00"+key)
+	g.mu.Unlock()
+	return nil
+}
+
+// PostgresReplayGuard claims keys in the request_nonces table of the main database,
+// so every API instance sees the same claims
+type PostgresReplayGuard struct{}
+
+// NewPostgresReplayGuard creates a replay guard backed by PostgreSQL
+func NewPostgresReplayGuard() *PostgresReplayGuard {
+	return &PostgresReplayGuard{}
+}
+
+// Claim inserts the key, replacing an expired claim; a live claim wins the conflict
+func (g *PostgresReplayGuard) Claim(ctx context.Context, scope, key string, ttl time.Duration) (bool, error) {
+	query := `
+		INSERT INTO request_nonces (scope, nonce, expires_at)
+		VALUES ($1, $2, NOW() + $3 * INTERVAL '1 second')
+		ON CONFLICT (scope, nonce) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE request_nonces.expires_at < NOW()
+	`
+	result, err := db.MainPool.Exec(ctx, query, scope, key, int64(ttl/time.Second))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim request nonce: %w", err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// Release deletes the claim
+func (g *PostgresReplayGuard) Release(ctx context.Context, scope, key string) error {
+	if _, err := db.MainPool.Exec(ctx, `DELETE FROM request_nonces WHERE scope = $1 AND nonce = $2`, scope, key); err != nil {
+		return fmt.Errorf("failed to release request nonce: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes expired claims; call it from a periodic job
+func (g *PostgresReplayGuard) Purge(ctx context.Context) (int64, error) {
+	result, err := db.MainPool.Exec(ctx, `DELETE FROM request_nonces WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge request nonces: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
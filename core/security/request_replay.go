@@ -0,0 +1,88 @@
+package security
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/labstack/echo/v4"
+)
+
+// Headers a client sends to protect a request from replay
+const (
+	HeaderRequestTimestamp = "X-Request-Timestamp"
+	HeaderRequestNonce     = "X-Request-Nonce"
+)
+
+// maxNonceLength bounds a client nonce; a UUID is 36 characters
+const maxNonceLength = 128
+
+// ReplayConfig configures ReplayProtection
+type ReplayConfig struct {
+	Guard     ReplayGuard
+	Tolerance time.Duration // Allowed clock difference; defaults to DefaultTolerance
+	// Required rejects unsafe requests (POST, PUT, PATCH, DELETE) without the headers.
+	// When false, only requests that send a nonce are checked.
+	Required bool
+}
+
+// ReplayProtection rejects API requests that reuse a nonce within the tolerance
+// window or carry a stale timestamp. Nonces are scoped to the tenant when one is
+// known. Safe methods (GET, HEAD, OPTIONS) are never checked.
+func ReplayProtection(cfg ReplayConfig) echo.MiddlewareFunc {
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = DefaultTolerance
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+
+			nonce := c.Request().Header.Get(HeaderRequestNonce)
+			if nonce == "" {
+				if cfg.Required {
+					return c.JSON(http.StatusBadRequest, map[string]string{"error": HeaderRequestNonce + " and " + HeaderRequestTimestamp + " headers are required"})
+				}
+				return next(c)
+			}
+			if len(nonce) > maxNonceLength {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request nonce"})
+			}
+
+			if _, err := CheckTimestamp(c.Request().Header.Get(HeaderRequestTimestamp), cfg.Tolerance, time.Now()); err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+			}
+
+			scope := "api"
+			if tenantID, ok := db.GetTenantID(c.Request().Context()); ok {
+				scope = "api:" + tenantID.String()
+			}
+			claimed, err := cfg.Guard.Claim(c.Request().Context(), scope, nonce, 2*cfg.Tolerance)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check request nonce"})
+			}
+			if !claimed {
+				return c.JSON(http.StatusConflict, map[string]string{"error": ErrReplayedRequest.Error()})
+			}
+
+			// A request that failed on the server may be retried with the same nonce
+			err = next(c)
+			if err != nil || c.Response().Status >= http.StatusInternalServerError {
+				_ = cfg.Guard.Release(c.Request().Context(), scope, nonce)
+			}
+			return err
+		}
+	}
+}
+
+// IsVerificationError reports whether err is a signature, timestamp or replay failure
+// rather than an infrastructure error, for handlers that verify inline
+func IsVerificationError(err error) bool {
+	return errors.Is(err, ErrMissingSignature) || errors.Is(err, ErrInvalidSignature) ||
+		errors.Is(err, ErrInvalidTimestamp) || errors.Is(err, ErrTimestampOutOfRange) ||
+		errors.Is(err, ErrReplayedRequest)
+}
@@ -0,0 +1,173 @@
+package security
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidSNSMessage is returned for an SNS delivery whose signature does not verify
+var ErrInvalidSNSMessage = errors.New("invalid SNS message signature")
+
+// snsCertHost is the host SNS serves signing certificates from, per region
+var snsCertHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// maxSNSCertBytes bounds a downloaded signing certificate
+const maxSNSCertBytes = 64 << 10
+
+// SNSMessage is an Amazon SNS HTTP(S) delivery
+type SNSMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+}
+
+// SNSVerifier checks SNS message signatures against the certificate SNS signed them
+// with. Certificates are downloaded once per URL and only from SNS hosts.
+type SNSVerifier struct {
+	client *http.Client
+	mu     sync.Mutex
+	certs  map[string]*x509.Certificate
+}
+
+// NewSNSVerifier creates an SNS signature verifier
+func NewSNSVerifier() *SNSVerifier {
+	return &SNSVerifier{
+		client: &http.Client{Timeout: 10 * time.Second},
+		certs:  make(map[string]*x509.Certificate),
+	}
+}
+
+// Verify returns ErrInvalidSNSMessage unless the message is signed by SNS
+func (v *SNSVerifier) Verify(ctx context.Context, msg *SNSMessage) error {
+	var hash crypto.Hash
+	switch msg.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("%w: unsupported signature version %q", ErrInvalidSNSMessage, msg.SignatureVersion)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(msg.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSNSMessage)
+	}
+	cert, err := v.certificate(ctx, msg.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: unexpected certificate key", ErrInvalidSNSMessage)
+	}
+
+	payload := []byte(msg.stringToSign())
+	var digest []byte
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum(payload)
+		digest = sum[:]
+	} else {
+		sum := sha256.Sum256(payload)
+		digest = sum[:]
+	}
+	if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+		return ErrInvalidSNSMessage
+	}
+	return nil
+}
+
+// stringToSign builds the canonical form SNS signs for the message type
+func (m *SNSMessage) stringToSign() string {
+	var b strings.Builder
+	field := func(name, value string) {
+		b.WriteString(name + "\n" + value + "\n")
+	}
+
+	field("Message", m.Message)
+	field("MessageId", m.MessageID)
+	if m.Type == "Notification" {
+		if m.Subject != "" {
+			field("Subject", m.Subject)
+		}
+	} else {
+		field("SubscribeURL", m.SubscribeURL)
+	}
+	field("Timestamp", m.Timestamp)
+	if m.Type != "Notification" {
+		field("Token", m.Token)
+	}
+	field("TopicArn", m.TopicArn)
+	field("Type", m.Type)
+	return b.String()
+}
+
+// certificate returns the signing certificate at certURL, which must be on an SNS host
+func (v *SNSVerifier) certificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	target, err := url.Parse(certURL)
+	if err != nil || target.Scheme != "https" || !snsCertHost.MatchString(target.Hostname()) ||
+		!strings.HasSuffix(target.Path, ".pem") {
+		return nil, fmt.Errorf("%w: unexpected signing certificate URL %q", ErrInvalidSNSMessage, certURL)
+	}
+
+	v.mu.Lock()
+	cert, ok := v.certs[certURL]
+	v.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch SNS signing certificate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch SNS signing certificate: %s", resp.Status)
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxSNSCertBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch SNS signing certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("%w: signing certificate is not PEM", ErrInvalidSNSMessage)
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSNSMessage, err)
+	}
+
+	v.mu.Lock()
+	v.certs[certURL] = cert
+	v.mu.Unlock()
+	return cert, nil
+}
@@ -0,0 +1,196 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/config"
+	"github.com/aceextension/core/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// integrationKey is the echo context key a verified webhook's integration name is stored under
+const integrationKey = "security.integration"
+
+// SecretSource returns an integration's signing secrets: the current one first,
+// then any previous ones still accepted during a rotation
+type SecretSource func(ctx context.Context) ([]string, error)
+
+// StaticSecrets returns fixed secrets
+func StaticSecrets(secrets ...string) SecretSource {
+	return func(context.Context) ([]string, error) { return secrets, nil }
+}
+
+// ConfigSecrets reads an integration's secrets from WEBHOOK_SECRETS, which lists
+// name=secret pairs separated by commas; a secret being rotated is written as
+// new|old, e.g. "esewa=k2|k1,cbms=s1"
+func ConfigSecrets(name string) SecretSource {
+	return func(context.Context) ([]string, error) {
+		if config.GlobalConfig == nil {
+			return nil, nil
+		}
+		return ParseSecrets(config.GlobalConfig.WebhookSecrets)[name], nil
+	}
+}
+
+// ParseSecrets parses a WEBHOOK_SECRETS value into secrets per integration
+func ParseSecrets(value string) map[string][]string {
+	secrets := make(map[string][]string)
+	for _, pair := range strings.Split(value, ",") {
+		name, list, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			continue
+		}
+		for _, secret := range strings.Split(list, "|") {
+			if secret = strings.TrimSpace(secret); secret != "" {
+				secrets[name] = append(secrets[name], secret)
+			}
+		}
+	}
+	return secrets
+}
+
+// WebhookConfig describes how one integration signs its callbacks
+type WebhookConfig struct {
+	Name    string       // Integration, e.g. "esewa" or "cbms"; scopes replay claims
+	Secrets SecretSource // Defaults to ConfigSecrets(Name)
+
+	SignatureHeader string   // Defaults to X-Signature
+	Encoding        Encoding // Defaults to hex
+	// TimestampHeader, when set, is required and signed as "<timestamp>.<body>"
+	TimestampHeader string
+	Tolerance       time.Duration // Allowed clock difference; defaults to DefaultTolerance
+
+	// Replay rejects a second delivery of the same signature within the tolerance window
+	// and, with NonceHeader, of the same nonce too. The nonce is not signed, so it only
+	// adds rejections: a captured delivery sent again with a fresh nonce still carries a
+	// claimed signature. Nil disables the check. Only a signed timestamp stops a delivery
+	// from being replayed after its claims expire.
+	Replay      ReplayGuard
+	NonceHeader string
+
+	MaxBodyBytes int64 // Defaults to DefaultMaxBodyBytes
+}
+
+// VerifyWebhook rejects requests whose signature does not match one of the
+// integration's secrets, whose timestamp is stale, or that were already received.
+// Handlers read the exact signed bytes with RawBody and may still use c.Bind.
+func VerifyWebhook(cfg WebhookConfig) echo.MiddlewareFunc {
+	if cfg.Secrets == nil {
+		cfg.Secrets = ConfigSecrets(cfg.Name)
+	}
+	if cfg.SignatureHeader == "" {
+		cfg.SignatureHeader = "X-Signature"
+	}
+	if cfg.Encoding == "" {
+		cfg.Encoding = EncodingHex
+	}
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = DefaultTolerance
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			body, err := captureBody(c, cfg.MaxBodyBytes)
+			if err != nil {
+				return err
+			}
+
+			replayKeys, err := cfg.verify(c, body)
+			switch {
+			case errors.Is(err, ErrNoSecret):
+				return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": cfg.Name + " webhook is not configured"})
+			case errors.Is(err, ErrReplayedRequest):
+				return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+			case IsVerificationError(err):
+				logger.Log.Warn(fmt.Sprintf("Rejected %s webhook from %s: %v", cfg.Name, c.RealIP(), err))
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+			case err != nil:
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to verify webhook"})
+			}
+
+			c.Set(integrationKey, cfg.Name)
+			err = next(c)
+
+			// A delivery that failed is retried by the sender with the same signature
+			if err != nil || c.Response().Status >= http.StatusInternalServerError {
+				cfg.release(c.Request().Context(), replayKeys)
+			}
+			return err
+		}
+	}
+}
+
+// Integration returns the name of the integration whose signature VerifyWebhook accepted
+func Integration(c echo.Context) (string, bool) {
+	name, ok := c.Get(integrationKey).(string)
+	return name, ok
+}
+
+// replayScope is the claim scope of an integration's deliveries
+func replayScope(name string) string {
+	return "webhook:" + name
+}
+
+// verify checks the timestamp, signature and replay window of a request and returns
+// the replay keys it claimed
+func (cfg WebhookConfig) verify(c echo.Context, body []byte) ([]string, error) {
+	ctx := c.Request().Context()
+
+	timestamp := ""
+	if cfg.TimestampHeader != "" {
+		timestamp = c.Request().Header.Get(cfg.TimestampHeader)
+		if _, err := CheckTimestamp(timestamp, cfg.Tolerance, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	secrets, err := cfg.Secrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := MatchSignature(secrets, SignedPayload(timestamp, body), c.Request().Header.Get(cfg.SignatureHeader), cfg.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Replay == nil {
+		return nil, nil
+	}
+	// The signature covers the body and timestamp, so it is always claimed, as matched
+	// rather than as sent so a re-spelled header is the same claim; the nonce header is
+	// the sender's word only and is claimed on top, under a prefix
+	keys := []string{signature}
+	if cfg.NonceHeader != "" {
+		if nonce := c.Request().Header.Get(cfg.NonceHeader); nonce != "" {
+			keys = append(keys, "nonce:"+nonce)
+		}
+	}
+
+	var claimed []string
+	for _, key := range keys {
+		// Claims outlive the window on both sides of the clock, so a delivery cannot come
+		// back once its claim expires
+		ok, err := cfg.Replay.Claim(ctx, replayScope(cfg.Name), key, 2*cfg.Tolerance)
+		if err == nil && !ok {
+			err = ErrReplayedRequest
+		}
+		if err != nil {
+			cfg.release(ctx, claimed)
+			return nil, err
+		}
+		claimed = append(claimed, key)
+	}
+	return claimed, nil
+}
+
+// release gives up replay claims, so the sender can retry a delivery that failed
+func (cfg WebhookConfig) release(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		_ = cfg.Replay.Release(ctx, replayScope(cfg.Name), key)
+	}
+}
@@ -0,0 +1,56 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TestVerifyWebhookReplayRespelledSignature sends a delivery, then the same delivery
+// with its signature written differently; each replay must be refused as a replay
+func TestVerifyWebhookReplayRespelledSignature(t *testing.T) {
+	const secret = "whsec_test"
+	body := `{"event":"payment.completed","amount":1500}`
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := Sign(secret, SignedPayload(timestamp, []byte(body)), EncodingHex)
+
+	e := echo.New()
+	e.POST("/webhook", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, VerifyWebhook(WebhookConfig{
+		Name:            "test",
+		Secrets:         StaticSecrets(secret),
+		TimestampHeader: "X-Timestamp",
+		Replay:          NewMemoryReplayGuard(),
+	}))
+
+	deliver := func(signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.Header.Set("X-Signature", signature)
+		req.Header.Set("X-Timestamp", timestamp)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := deliver(signature); code != http.StatusOK {
+		t.Fatalf("first delivery: got %d, want %d", code, http.StatusOK)
+	}
+
+	replays := map[string]string{
+		"same":           signature,
+		"upper case":     strings.ToUpper(signature),
+		"sha256 prefix":  "sha256=" + signature,
+		"prefix, padded": "  sha256=" + strings.ToUpper(signature) + " ",
+	}
+	for name, respelled := range replays {
+		if code := deliver(respelled); code != http.StatusConflict {
+			t.Errorf("replay with %s signature: got %d, want %d", name, code, http.StatusConflict)
+		}
+	}
+}
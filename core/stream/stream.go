@@ -0,0 +1,149 @@
+// Package stream writes large JSON results to an HTTP response as rows are read,
+// so reports and exports never hold the whole result set in memory.
+//
+// Two formats are supported: a JSON array (the default, byte-for-byte what
+// json.Marshal of a slice would produce) and NDJSON, one object per line,
+// selected with ?format=ndjson or an Accept: application/x-ndjson header.
+// Neither sets Content-Length, so the response goes out chunked.
+package stream
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Format is the wire format of a streamed response
+type Format string
+
+const (
+	FormatJSON   Format = "json"   // A single JSON array
+	FormatNDJSON Format = "ndjson" // Newline-delimited JSON objects
+)
+
+// MIMEApplicationNDJSON is the content type of NDJSON responses
+const MIMEApplicationNDJSON = "application/x-ndjson"
+
+// flushEvery is how many rows are buffered before the response is flushed to the client
+const flushEvery = 500
+
+// Negotiate picks the format from the format query parameter or the Accept header
+func Negotiate(r *http.Request) Format {
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case "ndjson", "jsonl":
+		return FormatNDJSON
+	case "json":
+		return FormatJSON
+	}
+	if strings.Contains(r.Header.Get("Accept"), MIMEApplicationNDJSON) {
+		return FormatNDJSON
+	}
+	return FormatJSON
+}
+
+// Writer streams rows to a response. Headers are sent on the first row (or on Close
+// for an empty result), so an error before any row can still get a normal error response.
+type Writer struct {
+	w        http.ResponseWriter
+	format   Format
+	encoder  *json.Encoder
+	filename string
+	started  bool
+	rows     int
+}
+
+// NewWriter creates a writer for the response in the given format
+func NewWriter(w http.ResponseWriter, format Format) *Writer {
+	return &Writer{w: w, format: format, encoder: json.NewEncoder(w)}
+}
+
+// AsAttachment makes clients save the response as a download named filename
+// (the extension is added from the format)
+func (s *Writer) AsAttachment(filename string) *Writer {
+	s.filename = filename
+	return s
+}
+
+// Started reports whether headers have been sent; after that an error can only end the stream
+func (s *Writer) Started() bool {
+	return s.started
+}
+
+// Rows returns how many rows have been written
+func (s *Writer) Rows() int {
+	return s.rows
+}
+
+// Write encodes one row
+func (s *Writer) Write(row any) error {
+	s.start()
+
+	if s.format == FormatJSON && s.rows > 0 {
+		if _, err := s.w.Write([]byte{','}); err != nil {
+			return err
+		}
+	}
+	// Encode appends a newline: the NDJSON separator, and harmless whitespace in an array
+	if err := s.encoder.Encode(row); err != nil {
+		return err
+	}
+
+	s.rows++
+	if s.rows%flushEvery == 0 {
+		s.flush()
+	}
+	return nil
+}
+
+// Close ends the stream. For NDJSON a non-nil err is written as a final
+// {"error": "..."} line so clients can tell a failed export from a complete one;
+// a JSON array is left unterminated, which clients see as invalid JSON.
+func (s *Writer) Close(err error) error {
+	s.start()
+
+	if err != nil {
+		if s.format == FormatNDJSON {
+			_ = s.encoder.Encode(map[string]string{"error": err.Error()})
+		}
+		s.flush()
+		return nil
+	}
+
+	if s.format == FormatJSON {
+		if _, err := s.w.Write([]byte{']'}); err != nil {
+			return err
+		}
+	}
+	s.flush()
+	return nil
+}
+
+func (s *Writer) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+
+	header := s.w.Header()
+	if s.format == FormatNDJSON {
+		header.Set("Content-Type", MIMEApplicationNDJSON)
+	} else {
+		header.Set("Content-Type", "application/json; charset=UTF-8")
+	}
+	if s.filename != "" {
+		header.Set("Content-Disposition", `attachment; filename="`+s.filename+"."+string(s.format)+`"`)
+	}
+	// Stop proxies such as nginx from buffering the whole stream
+	header.Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+
+	if s.format == FormatJSON {
+		_, _ = s.w.Write([]byte{'['})
+	}
+}
+
+func (s *Writer) flush() {
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,213 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrNotWorkbook is returned for a file that is not an .xlsx workbook
+var ErrNotWorkbook = errors.New("not an xlsx workbook")
+
+// ReadFirstSheet reads the cell text of a workbook's first sheet, one slice per row.
+// Numbers come back as Excel stores them and dates as serial day numbers; empty rows
+// are kept so row numbers match what the user sees in Excel. At most maxRows rows are
+// read.
+func ReadFirstSheet(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrNotWorkbook
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := readSharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return nil, err
+	}
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, ErrNotWorkbook
+	}
+	return readSheet(sheet, shared, maxRows)
+}
+
+// firstSheetPath finds the first sheet's part through workbook.xml and its relationships
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodePart(files["xl/workbook.xml"], &workbook); err != nil || len(workbook.Sheets) == 0 {
+		return "", ErrNotWorkbook
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(files["xl/_rels/workbook.xml.rels"], &rels); err != nil {
+		return "", ErrNotWorkbook
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", ErrNotWorkbook
+}
+
+// readSharedStrings reads the workbook's string table; rich text runs are joined
+func readSharedStrings(f *zip.File) ([]string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	var table struct {
+		Items []struct {
+			T    string `xml:"t"`
+			Runs []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := decodePart(f, &table); err != nil {
+		return nil, ErrNotWorkbook
+	}
+	strs := make([]string, len(table.Items))
+	for i, item := range table.Items {
+		if len(item.Runs) == 0 {
+			strs[i] = item.T
+			continue
+		}
+		var b strings.Builder
+		for _, run := range item.Runs {
+			b.WriteString(run.T)
+		}
+		strs[i] = b.String()
+	}
+	return strs, nil
+}
+
+type sheetCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		T string `xml:"t"`
+	} `xml:"is"`
+}
+
+type sheetRow struct {
+	Num   int         `xml:"r,attr"`
+	Cells []sheetCell `xml:"c"`
+}
+
+// readSheet streams the sheet's rows so a large upload is not parsed into a tree
+func readSheet(f *zip.File, shared []string, maxRows int) ([][]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var rows [][]string
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, ErrNotWorkbook
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row sheetRow
+		if err := dec.DecodeElement(&row, &start); err != nil {
+			return nil, ErrNotWorkbook
+		}
+		num := row.Num
+		if num == 0 {
+			num = len(rows) + 1
+		}
+		if num > maxRows {
+			return nil, fmt.Errorf("sheet has more than %d rows", maxRows)
+		}
+		for len(rows) < num {
+			rows = append(rows, nil)
+		}
+		rows[num-1] = rowValues(row, shared)
+	}
+}
+
+func rowValues(row sheetRow, shared []string) []string {
+	var values []string
+	for i, cell := range row.Cells {
+		col := i
+		if cell.Ref != "" {
+			col = columnIndex(cell.Ref)
+		}
+		if col < 0 {
+			continue
+		}
+		for len(values) <= col {
+			values = append(values, "")
+		}
+		switch cell.Type {
+		case "s":
+			if n, err := strconv.Atoi(cell.Value); err == nil && n >= 0 && n < len(shared) {
+				values[col] = shared[n]
+			}
+		case "inlineStr":
+			values[col] = cell.Inline.T
+		case "b":
+			values[col] = map[string]string{"1": "true", "0": "false"}[cell.Value]
+		default:
+			values[col] = cell.Value
+		}
+	}
+	return values
+}
+
+// columnIndex is the zero-based column of a cell reference, e.g. 27 for AB5
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
+
+func decodePart(f *zip.File, v any) error {
+	if f == nil {
+		return ErrNotWorkbook
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
@@ -0,0 +1,328 @@
+// Package xlsx is a minimal streaming writer for Excel workbooks. Rows are written
+// straight into the archive as they are added, so a sheet of a few hundred thousand
+// ledger lines never sits in memory. It supports text, numbers, dates and a bold
+// header row, which is what reports handed to accountants need. ReadFirstSheet reads
+// the cell text of uploaded workbooks back.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxRows is the most rows Excel shows on one sheet
+const MaxRows = 1048576
+
+// maxSheetName is Excel's limit on sheet name length
+const maxSheetName = 31
+
+// Cell styles, as indexes into the cellXfs of styles.xml
+const (
+	styleNone   = 0
+	styleBold   = 1
+	styleAmount = 2 // #,##0.00
+	styleDate   = 3 // yyyy-mm-dd
+	styleTime   = 4 // yyyy-mm-dd hh:mm:ss
+)
+
+// DateTime is a time written with its time of day; a time.Time is written as a date
+type DateTime time.Time
+
+// ErrTooManyRows is returned when a sheet passes MaxRows
+var ErrTooManyRows = errors.New("sheet has more rows than Excel allows")
+
+// Workbook writes sheets one after another into an .xlsx archive
+type Workbook struct {
+	zw     *zip.Writer
+	sheets []string
+	names  map[string]bool
+	open   *Sheet
+	closed bool
+}
+
+// Sheet is the worksheet currently being written; adding another sheet finishes it
+type Sheet struct {
+	w    *bufio.Writer
+	rows int
+	err  error
+}
+
+// NewWorkbook starts a workbook written to w
+func NewWorkbook(w io.Writer) *Workbook {
+	return &Workbook{zw: zip.NewWriter(w), names: make(map[string]bool)}
+}
+
+// AddSheet finishes the current sheet and starts a new one. Names are cut to 31
+// characters, stripped of the characters Excel forbids and made unique.
+func (b *Workbook) AddSheet(name string) (*Sheet, error) {
+	if b.closed {
+		return nil, errors.New("workbook is closed")
+	}
+	if err := b.finishSheet(); err != nil {
+		return nil, err
+	}
+
+	name = b.uniqueName(name)
+	b.sheets = append(b.sheets, name)
+
+	w, err := b.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(b.sheets)))
+	if err != nil {
+		return nil, err
+	}
+	sheet := &Sheet{w: bufio.NewWriter(w)}
+	sheet.writeString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	b.open = sheet
+	return sheet, sheet.err
+}
+
+// Header adds a bold row
+func (s *Sheet) Header(values ...string) error {
+	cells := make([]any, len(values))
+	for i, v := range values {
+		cells[i] = v
+	}
+	return s.row(styleBold, cells)
+}
+
+// Row adds a row. Strings are written as text; ints and floats as numbers, with
+// floats formatted as amounts; time.Time as a date and DateTime as a date and time.
+// nil leaves the cell empty.
+func (s *Sheet) Row(values ...any) error {
+	return s.row(styleNone, values)
+}
+
+// Rows returns how many rows have been written
+func (s *Sheet) Rows() int {
+	return s.rows
+}
+
+func (s *Sheet) row(style int, values []any) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.rows >= MaxRows {
+		return ErrTooManyRows
+	}
+	s.rows++
+
+	s.writeString(`<row r="` + strconv.Itoa(s.rows) + `">`)
+	for i, v := range values {
+		ref := columnName(i) + strconv.Itoa(s.rows)
+		switch v := v.(type) {
+		case nil:
+			continue
+		case string:
+			s.writeString(`<c r="` + ref + `" t="inlineStr"` + styleAttr(style) + `><is><t xml:space="preserve">`)
+			s.escape(v)
+			s.writeString(`</t></is></c>`)
+		case int:
+			s.number(ref, style, strconv.Itoa(v))
+		case int64:
+			s.number(ref, style, strconv.FormatInt(v, 10))
+		case float64:
+			s.number(ref, orStyle(style, styleAmount), strconv.FormatFloat(v, 'f', -1, 64))
+		case time.Time:
+			s.number(ref, orStyle(style, styleDate), strconv.FormatFloat(serialDate(v), 'f', -1, 64))
+		case *time.Time:
+			if v != nil {
+				s.number(ref, orStyle(style, styleDate), strconv.FormatFloat(serialDate(*v), 'f', -1, 64))
+			}
+		case DateTime:
+			s.number(ref, orStyle(style, styleTime), strconv.FormatFloat(serialTime(time.Time(v)), 'f', -1, 64))
+		default:
+			s.writeString(`<c r="` + ref + `" t="inlineStr"` + styleAttr(style) + `><is><t xml:space="preserve">`)
+			s.escape(fmt.Sprint(v))
+			s.writeString(`</t></is></c>`)
+		}
+	}
+	s.writeString(`</row>`)
+	return s.err
+}
+
+func (s *Sheet) number(ref string, style int, value string) {
+	s.writeString(`<c r="` + ref + `"` + styleAttr(style) + `><v>` + value + `</v></c>`)
+}
+
+func (s *Sheet) writeString(text string) {
+	if s.err == nil {
+		_, s.err = s.w.WriteString(text)
+	}
+}
+
+func (s *Sheet) escape(text string) {
+	if s.err == nil {
+		s.err = xml.EscapeText(s.w, []byte(stripControl(text)))
+	}
+}
+
+// Close finishes the last sheet and writes the workbook parts. It does not close
+// the underlying writer. A workbook without sheets gets an empty one, as Excel
+// refuses to open a workbook with none.
+func (b *Workbook) Close() error {
+	if b.closed {
+		return nil
+	}
+	if len(b.sheets) == 0 {
+		if _, err := b.AddSheet("Sheet1"); err != nil {
+			return err
+		}
+	}
+	if err := b.finishSheet(); err != nil {
+		return err
+	}
+	b.closed = true
+
+	var sheets, rels, overrides strings.Builder
+	for i, name := range b.sheets {
+		n := i + 1
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeAttr(name), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+	}
+	stylesRel := len(b.sheets) + 1
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() +
+			fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, stylesRel) +
+			`</Relationships>`},
+		{"xl/styles.xml", stylesXML},
+	}
+	for _, part := range parts {
+		w, err := b.zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, xml.Header+part.body); err != nil {
+			return err
+		}
+	}
+
+	return b.zw.Close()
+}
+
+func (b *Workbook) finishSheet() error {
+	if b.open == nil {
+		return nil
+	}
+	sheet := b.open
+	b.open = nil
+	sheet.writeString(`</sheetData></worksheet>`)
+	if sheet.err != nil {
+		return sheet.err
+	}
+	return sheet.w.Flush()
+}
+
+func (b *Workbook) uniqueName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) || r < 32 {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		name = "Sheet" + strconv.Itoa(len(b.sheets)+1)
+	}
+	name = truncate(name, maxSheetName)
+
+	candidate := name
+	for n := 2; b.names[strings.ToLower(candidate)]; n++ {
+		suffix := " (" + strconv.Itoa(n) + ")"
+		candidate = truncate(name, maxSheetName-len(suffix)) + suffix
+	}
+	b.names[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// stylesXML defines the fonts and number formats behind the style constants
+const stylesXML = `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="5">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs></styleSheet>`
+
+// serialDate converts a date to Excel's day count from 1899-12-30
+func serialDate(t time.Time) float64 {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return float64(day.Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
+
+// serialTime converts a time to Excel's day count with the time of day as the fraction
+func serialTime(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return wall.Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)).Seconds() / 86400
+}
+
+// columnName converts a zero-based index to a column letter (0 = A, 26 = AA)
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func styleAttr(style int) string {
+	if style == styleNone {
+		return ""
+	}
+	return ` s="` + strconv.Itoa(style) + `"`
+}
+
+// orStyle keeps an explicit row style (bold headers) over a value's own format
+func orStyle(style, fallback int) int {
+	if style != styleNone {
+		return style
+	}
+	return fallback
+}
+
+// stripControl drops control characters, which are not allowed in XML 1.0
+func stripControl(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 32 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+func escapeAttr(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) > max {
+		return string(runes[:max])
+	}
+	return text
+}
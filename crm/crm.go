@@ -1,14 +1,64 @@
 package crm
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/accounting"
+	accountingDomain "github.com/aceextension/accounting/domain"
+	"github.com/aceextension/automation"
+	automationDomain "github.com/aceextension/automation/domain"
+	"github.com/aceextension/catalog"
+	"github.com/aceextension/comments"
+	commentsDomain "github.com/aceextension/comments/domain"
+	commentsService "github.com/aceextension/comments/service"
+	"github.com/aceextension/core/config"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/crm/domain"
 	"github.com/aceextension/crm/repository"
 	"github.com/aceextension/crm/service"
+	"github.com/aceextension/crm/taxid"
+	"github.com/aceextension/files"
+	filesDomain "github.com/aceextension/files/domain"
+	"github.com/aceextension/onboarding"
+	onboardingDomain "github.com/aceextension/onboarding/domain"
+	onboardingService "github.com/aceextension/onboarding/service"
+	"github.com/aceextension/payments"
+	paymentsDomain "github.com/aceextension/payments/domain"
+	paymentsService "github.com/aceextension/payments/service"
+	"github.com/aceextension/tags"
+	tagsDomain "github.com/aceextension/tags/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	// dunningHour is the local hour at which the daily dues reminders are sent
+	dunningHour = 10
+	// billExtractionInterval is how often captured bills are sent for extraction
+	billExtractionInterval = time.Minute
 )
 
 // Global service instances
 var (
-	CustomerService service.CustomerService
-	SupplierService service.SupplierService
+	CustomerService          service.CustomerService
+	SupplierService          service.SupplierService
+	QuickPickService         service.QuickPickService
+	ContainerService         service.ContainerService
+	ConsignmentService       service.ConsignmentService
+	KhataService             service.KhataService
+	DunningService           service.DunningService
+	WriteOffService          service.WriteOffService
+	DeliveryService          service.DeliveryService
+	VehicleService           service.VehicleService
+	BillCaptureService       service.BillCaptureService
+	ApprovalService          service.ApprovalService
+	TaxIDService             service.TaxIDService
+	CustomerOTPService       service.CustomerOTPService
+	PaymentTermsService      service.PaymentTermsService
+	WarrantyService          service.WarrantyService
+	PurchaseReturnService    service.PurchaseReturnService
+	SupplierScorecardService service.SupplierScorecardService
 )
 
 // Init initializes the CRM module
@@ -16,8 +66,192 @@ func Init() {
 	// Initialize repositories
 	customerRepo := repository.NewPostgresCustomerRepository()
 	supplierRepo := repository.NewPostgresSupplierRepository()
+	quickPickRepo := repository.NewPostgresQuickPickRepository()
+	containerRepo := repository.NewPostgresContainerRepository()
+	consignmentRepo := repository.NewPostgresConsignmentRepository()
+	khataRepo := repository.NewPostgresKhataRepository()
+	dunningRepo := repository.NewPostgresDunningRepository()
+	writeOffRepo := repository.NewPostgresWriteOffRepository()
+	deliveryRepo := repository.NewPostgresDeliveryRepository()
+	vehicleRepo := repository.NewPostgresVehicleRepository()
+	billCaptureRepo := repository.NewPostgresBillCaptureRepository()
+	approvalRepo := repository.NewPostgresApprovalRepository()
 
 	// Initialize services
 	CustomerService = service.NewCustomerService(customerRepo)
 	SupplierService = service.NewSupplierService(supplierRepo)
+	QuickPickService = service.NewQuickPickService(quickPickRepo, customerRepo)
+	ContainerService = service.NewContainerService(containerRepo, customerRepo)
+	ConsignmentService = service.NewConsignmentService(consignmentRepo, supplierRepo)
+	KhataService = service.NewKhataService(khataRepo, customerRepo, dunningRepo)
+	DunningService = service.NewDunningService(dunningRepo, khataRepo, customerRepo)
+	WriteOffService = service.NewWriteOffService(writeOffRepo, khataRepo, customerRepo, dunningRepo)
+	DeliveryService = service.NewDeliveryService(deliveryRepo, customerRepo, khataRepo, dunningRepo)
+	VehicleService = service.NewVehicleService(vehicleRepo, deliveryRepo)
+
+	// Warranty periods come from catalog products; call catalog.Init first
+	WarrantyService = service.NewWarrantyService(repository.NewPostgresWarrantyRepository(), customerRepo)
+	if catalog.ProductService != nil {
+		WarrantyService.SetProducts(catalogWarranties{})
+	}
+
+	// Codes confirming a credit sale's buyer are sent through the notification module
+	CustomerOTPService = service.NewCustomerOTPService(repository.NewPostgresCustomerOTPRepository(), customerRepo)
+
+	// PANs are format-checked on save; live IRD verification needs a lookup endpoint
+	TaxIDService = service.NewTaxIDService(customerRepo, supplierRepo)
+	PaymentTermsService = service.NewPaymentTermsService(customerRepo, supplierRepo)
+	if config.GlobalConfig != nil && config.GlobalConfig.IRDLookupURL != "" {
+		TaxIDService.SetLookup(taxid.NewIRDLookup(config.GlobalConfig.IRDLookupURL))
+	}
+
+	inboundDomain := ""
+	if config.GlobalConfig != nil {
+		inboundDomain = config.GlobalConfig.BillInboxDomain
+	}
+	BillCaptureService = service.NewBillCaptureService(billCaptureRepo, supplierRepo, inboundDomain)
+
+	// Goods go back against confirmed purchase bills; goods receipts register from the
+	// purchasing module, and the debit note journal and stock hook are set after Init
+	PurchaseReturnService = service.NewPurchaseReturnService(repository.NewPostgresPurchaseReturnRepository(), supplierRepo)
+	PurchaseReturnService.RegisterReceiptType(domain.ReceiptTypePurchaseBill, service.NewPurchaseBillReceipts(billCaptureRepo))
+
+	// Scorecards rate suppliers on order lines registered by the purchasing module
+	// and on the quality of what was returned to them
+	SupplierScorecardService = service.NewSupplierScorecardService(repository.NewPostgresSupplierScorecardRepository(), supplierRepo, PurchaseReturnService)
+
+	// Purchase bills over the confirmer's limit wait for approval; purchase orders
+	// and supplier payments register with ApprovalService from their own modules
+	ApprovalService = service.NewApprovalService(approvalRepo)
+	ApprovalService.RegisterDocumentType(domain.ApprovalPurchaseBill, BillCaptureService)
+	BillCaptureService.SetApprovals(ApprovalService)
+
+	// Captured bill files are purchase bill attachments; call files.Init first
+	if files.AttachmentService != nil {
+		files.AttachmentService.RegisterEntityType(filesDomain.EntityPurchaseBill, billCaptureRepo.DraftExists)
+		BillCaptureService.SetFileStore(files.AttachmentService)
+	}
+	if files.OCREngine != nil {
+		BillCaptureService.SetExtractor(service.NewOCRBillExtractor(files.OCREngine))
+	}
+
+	// Customers and suppliers can be tagged and filtered by tag; call tags.Init first
+	if tags.TagService != nil {
+		tags.TagService.RegisterEntityType(tagsDomain.EntityCustomer, customerRepo.CountByIDs)
+		tags.TagService.RegisterEntityType(tagsDomain.EntitySupplier, supplierRepo.CountByIDs)
+	}
+
+	// Teams can discuss customers and suppliers; call comments.Init first
+	if comments.CommentService != nil {
+		comments.CommentService.RegisterEntityType(commentsDomain.EntityCustomer, commentsService.ExistsByCount(customerRepo.CountByIDs))
+		comments.CommentService.RegisterEntityType(commentsDomain.EntitySupplier, commentsService.ExistsByCount(supplierRepo.CountByIDs))
+	}
+
+	// Adding a customer and a supplier are setup steps; call onboarding.Init first
+	if onboarding.ChecklistService != nil {
+		onboarding.ChecklistService.RegisterStep(onboardingDomain.Step{Key: onboardingDomain.StepFirstCustomer, Title: "Add your first customer"}, onboardingService.DoneByCount(customerRepo.Count))
+		onboarding.ChecklistService.RegisterStep(onboardingDomain.Step{Key: onboardingDomain.StepFirstSupplier, Title: "Add your first supplier"}, onboardingService.DoneByCount(supplierRepo.Count))
+	}
+
+	// Automations can set customer and supplier attributes; call automation.Init first
+	if automation.AutomationService != nil {
+		automation.AutomationService.RegisterAttributeTarget(automationDomain.EntityCustomer, setCustomerAttribute)
+		automation.AutomationService.RegisterAttributeTarget(automationDomain.EntitySupplier, setSupplierAttribute)
+	}
+
+	// Aging and the purchase register go into year-end export bundles; call accounting.Init first
+	if accounting.BundleService != nil {
+		registerBundleSections(accounting.BundleService)
+	}
+	// Confirmed purchase bills and consignment bills are posted to the journal; call
+	// accounting.Init first
+	if accounting.Service != nil {
+		accounting.Service.RegisterPostingSource(accountingDomain.ReferencePurchaseBill, postPurchaseBill)
+		accounting.Service.RegisterPostingSource(accountingDomain.ReferenceConsignmentBill, postConsignmentBill)
+		BillCaptureService.SetJournal(accountingJournal{})
+		ConsignmentService.SetBillJournal(accountingJournal{})
+	}
+	// Supplier payments are allocated to confirmed purchase bills, and customers'
+	// receipts go on their khata; call payments.Init first
+	if payments.Service != nil {
+		payments.Service.RegisterDocumentType(paymentsDomain.DocumentPurchaseBill, paymentsService.DocumentSource{
+			Direction: paymentsDomain.DirectionDisbursement,
+			Get:       paymentBill,
+			Open:      openPaymentBills,
+		})
+		payments.Service.SetParties(crmParties{})
+		payments.Service.SetCustomerLedger(khataReceipts{})
+	}
+	// A linked company's sale becomes a draft purchase bill here; call accounting.Init first
+	if accounting.InterCompanyService != nil {
+		accounting.InterCompanyService.SetPurchaseMirror(interCompanyPurchases{bills: BillCaptureService})
+	}
+}
+
+// catalogWarranties reads warranty periods from the products' warranty_months
+type catalogWarranties struct{}
+
+// WarrantyMonths returns the product's warranty period, 0 if it has none
+func (catalogWarranties) WarrantyMonths(ctx context.Context, tenantID, productID uuid.UUID) (int, error) {
+	product, err := catalog.ProductService.GetByID(ctx, tenantID, productID)
+	if err != nil {
+		return 0, fmt.Errorf("product %s not found", productID)
+	}
+	return product.GetWarrantyMonths(), nil
+}
+
+// setCustomerAttribute saves a custom attribute set by an automation
+func setCustomerAttribute(ctx context.Context, tenantID, customerID uuid.UUID, attribute string, value any) error {
+	customer, err := CustomerService.GetByID(ctx, tenantID, customerID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrCustomerNotFound, customerID)
+	}
+	customer.SetCustomAttribute(attribute, value)
+	return CustomerService.Update(ctx, customer)
+}
+
+// setSupplierAttribute saves a custom attribute set by an automation
+func setSupplierAttribute(ctx context.Context, tenantID, supplierID uuid.UUID, attribute string, value any) error {
+	supplier, err := SupplierService.GetByID(ctx, tenantID, supplierID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrSupplierNotFound, supplierID)
+	}
+	supplier.SetCustomAttribute(attribute, value)
+	return SupplierService.Update(ctx, supplier)
+}
+
+// StartDunningScheduler sends due reminders once a day at dunningHour.
+// Call after Init; reminders are delivered through the notification module.
+func StartDunningScheduler() {
+	go func() {
+		for {
+			time.Sleep(time.Until(nextDunningRun(time.Now())))
+			if err := DunningService.RunScheduled(context.Background()); err != nil {
+				logger.Log.Error("Daily dunning run error: " + err.Error())
+			}
+		}
+	}()
+}
+
+// StartBillExtractionWorker reads bill details from captured bills every
+// billExtractionInterval. Call after Init; it does nothing without an extractor.
+func StartBillExtractionWorker() {
+	go func() {
+		ticker := time.NewTicker(billExtractionInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := BillCaptureService.ProcessExtractions(context.Background()); err != nil {
+				logger.Log.Error("Bill extraction worker error: " + err.Error())
+			}
+		}
+	}()
+}
+
+// nextDunningRun returns the next dunningHour after now
+func nextDunningRun(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), dunningHour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
 }
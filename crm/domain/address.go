@@ -0,0 +1,288 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidAddress is returned when a structured address fails validation
+var ErrInvalidAddress = errors.New("invalid address")
+
+// addressDetailKey is the custom attribute holding the structured address.
+// The single-line "address" attribute is kept in step with it for printing.
+const addressDetailKey = "address_detail"
+
+// maxWards is the most wards any municipality has (Pokhara Metropolitan City)
+const maxWards = 33
+
+// Rough bounding box of Nepal, used to catch swapped or mistyped coordinates
+const (
+	minLatitude  = 26.3
+	maxLatitude  = 30.5
+	minLongitude = 80.0
+	maxLongitude = 88.3
+)
+
+// Address is a structured Nepal address, from the street down to the province.
+// District is required; the province is derived from it.
+type Address struct {
+	Line1        string   `json:"line1,omitempty"` // House number, building, street
+	Line2        string   `json:"line2,omitempty"` // Landmark or other directions
+	Tole         string   `json:"tole,omitempty"`
+	Ward         int      `json:"ward,omitempty"`
+	Municipality string   `json:"municipality,omitempty"` // Metropolitan city, municipality or rural municipality
+	District     string   `json:"district"`
+	Province     string   `json:"province,omitempty"`
+	PostalCode   string   `json:"postalCode,omitempty"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+}
+
+// Normalize trims the fields, resolves the district to its official name and
+// fills in the province, then validates the address
+func (a *Address) Normalize() error {
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.Line2 = strings.TrimSpace(a.Line2)
+	a.Tole = strings.TrimSpace(a.Tole)
+	a.Municipality = strings.TrimSpace(a.Municipality)
+	a.PostalCode = strings.TrimSpace(a.PostalCode)
+
+	district, province, ok := LookupDistrict(a.District)
+	if !ok {
+		return fmt.Errorf("%w: unknown district %q", ErrInvalidAddress, a.District)
+	}
+	if a.Province != "" {
+		given, ok := LookupProvince(a.Province)
+		if !ok {
+			return fmt.Errorf("%w: unknown province %q", ErrInvalidAddress, a.Province)
+		}
+		if given.Name != province.Name {
+			return fmt.Errorf("%w: %s district is in %s province, not %s", ErrInvalidAddress, district, province.Name, given.Name)
+		}
+	}
+	a.District = district
+	a.Province = province.Name
+
+	if a.Ward < 0 || a.Ward > maxWards {
+		return fmt.Errorf("%w: ward must be between 1 and %d", ErrInvalidAddress, maxWards)
+	}
+	if a.Ward > 0 && a.Municipality == "" {
+		return fmt.Errorf("%w: a ward needs its municipality", ErrInvalidAddress)
+	}
+	if a.PostalCode != "" && (len(a.PostalCode) != 5 || !isNumeric(a.PostalCode)) {
+		return fmt.Errorf("%w: postal code must be 5 digits", ErrInvalidAddress)
+	}
+
+	if (a.Latitude == nil) != (a.Longitude == nil) {
+		return fmt.Errorf("%w: latitude and longitude must be given together", ErrInvalidAddress)
+	}
+	if a.Latitude != nil {
+		if *a.Latitude < minLatitude || *a.Latitude > maxLatitude || *a.Longitude < minLongitude || *a.Longitude > maxLongitude {
+			return fmt.Errorf("%w: coordinates are outside Nepal", ErrInvalidAddress)
+		}
+	}
+
+	return nil
+}
+
+// String formats the address on one line, e.g.
+// "House 12, Baneshwor, Kathmandu-10, Kathmandu, Bagmati"
+func (a *Address) String() string {
+	parts := []string{}
+	for _, part := range []string{a.Line1, a.Line2, a.Tole} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if a.Municipality != "" {
+		if a.Ward > 0 {
+			parts = append(parts, a.Municipality+"-"+strconv.Itoa(a.Ward))
+		} else {
+			parts = append(parts, a.Municipality)
+		}
+	}
+	if a.District != "" {
+		parts = append(parts, a.District)
+	}
+	if a.Province != "" {
+		parts = append(parts, a.Province)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// decodeAddress reads a structured address stored in custom attributes
+func decodeAddress(value interface{}) *Address {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var address Address
+	if err := json.Unmarshal(data, &address); err != nil || address.District == "" {
+		return nil
+	}
+	return &address
+}
+
+// encodeAddress converts an address to the map form custom attributes hold after a database round trip
+func encodeAddress(address *Address) map[string]interface{} {
+	data, _ := json.Marshal(address)
+	value := map[string]interface{}{}
+	_ = json.Unmarshal(data, &value)
+	return value
+}
+
+// AreaFilter selects customers or suppliers by where they are, for delivery route planning
+type AreaFilter struct {
+	District     string
+	Municipality string // Optional
+	Ward         int    // Optional; 0 means any ward
+}
+
+// Normalize resolves the district to its official name
+func (f *AreaFilter) Normalize() error {
+	district, _, ok := LookupDistrict(f.District)
+	if !ok {
+		return fmt.Errorf("%w: unknown district %q", ErrInvalidAddress, f.District)
+	}
+	f.District = district
+	f.Municipality = strings.TrimSpace(f.Municipality)
+	if f.Ward < 0 || f.Ward > maxWards {
+		return fmt.Errorf("%w: ward must be between 1 and %d", ErrInvalidAddress, maxWards)
+	}
+	return nil
+}
+
+// Province is one of Nepal's seven provinces with its districts
+type Province struct {
+	Number    int      `json:"number"`
+	Name      string   `json:"name"`
+	Districts []string `json:"districts"`
+}
+
+// NepalProvinces lists the provinces and their 77 districts
+var NepalProvinces = []Province{
+	{Number: 1, Name: "Koshi", Districts: []string{
+		"Bhojpur", "Dhankuta", "Ilam", "Jhapa", "Khotang", "Morang", "Okhaldhunga",
+		"Panchthar", "Sankhuwasabha", "Solukhumbu", "Sunsari", "Taplejung", "Terhathum", "Udayapur",
+	}},
+	{Number: 2, Name: "Madhesh", Districts: []string{
+		"Bara", "Dhanusha", "Mahottari", "Parsa", "Rautahat", "Saptari", "Sarlahi", "Siraha",
+	}},
+	{Number: 3, Name: "Bagmati", Districts: []string{
+		"Bhaktapur", "Chitwan", "Dhading", "Dolakha", "Kathmandu", "Kavrepalanchok", "Lalitpur",
+		"Makwanpur", "Nuwakot", "Ramechhap", "Rasuwa", "Sindhuli", "Sindhupalchok",
+	}},
+	{Number: 4, Name: "Gandaki", Districts: []string{
+		"Baglung", "Gorkha", "Kaski", "Lamjung", "Manang", "Mustang", "Myagdi",
+		"Nawalpur", "Parbat", "Syangja", "Tanahun",
+	}},
+	{Number: 5, Name: "Lumbini", Districts: []string{
+		"Arghakhanchi", "Banke", "Bardiya", "Dang", "Eastern Rukum", "Gulmi", "Kapilvastu",
+		"Parasi", "Palpa", "Pyuthan", "Rolpa", "Rupandehi",
+	}},
+	{Number: 6, Name: "Karnali", Districts: []string{
+		"Dailekh", "Dolpa", "Humla", "Jajarkot", "Jumla", "Kalikot", "Mugu",
+		"Salyan", "Surkhet", "Western Rukum",
+	}},
+	{Number: 7, Name: "Sudurpashchim", Districts: []string{
+		"Achham", "Baitadi", "Bajhang", "Bajura", "Dadeldhura", "Darchula", "Doti", "Kailali", "Kanchanpur",
+	}},
+}
+
+// districtAliases maps common alternate spellings and former names to the official district name
+var districtAliases = map[string]string{
+	"kavre":            "Kavrepalanchok",
+	"kavrepalanchowk":  "Kavrepalanchok",
+	"makawanpur":       "Makwanpur",
+	"sindhupalchowk":   "Sindhupalchok",
+	"chitawan":         "Chitwan",
+	"dhanusa":          "Dhanusha",
+	"terathum":         "Terhathum",
+	"tanahu":           "Tanahun",
+	"kapilbastu":       "Kapilvastu",
+	"bardia":           "Bardiya",
+	"nawalparasi east": "Nawalpur",
+	"nawalparasi west": "Parasi",
+	"rukum east":       "Eastern Rukum",
+	"rukum west":       "Western Rukum",
+	"east rukum":       "Eastern Rukum",
+	"west rukum":       "Western Rukum",
+	"sankhuwa sabha":   "Sankhuwasabha",
+	"solu khumbu":      "Solukhumbu",
+}
+
+// provinceAliases maps numbers and former names to the official province name
+var provinceAliases = map[string]string{
+	"province 1":      "Koshi",
+	"province no. 1":  "Koshi",
+	"madhesh pradesh": "Madhesh",
+	"province 2":      "Madhesh",
+	"province no. 2":  "Madhesh",
+	"gandaki pradesh": "Gandaki",
+	"karnali pradesh": "Karnali",
+	"sudur paschim":   "Sudurpashchim",
+	"sudurpaschim":    "Sudurpashchim",
+	"far western":     "Sudurpashchim",
+}
+
+// districtEntry is a district's official name and province
+type districtEntry struct {
+	name     string
+	province *Province
+}
+
+// districtIndex maps a lower-case district name to its entry
+var districtIndex = func() map[string]districtEntry {
+	index := make(map[string]districtEntry)
+	for i := range NepalProvinces {
+		for _, district := range NepalProvinces[i].Districts {
+			index[strings.ToLower(district)] = districtEntry{name: district, province: &NepalProvinces[i]}
+		}
+	}
+	return index
+}()
+
+// LookupDistrict resolves a district name (any case, common alternate spellings)
+// to its official name and province
+func LookupDistrict(name string) (string, *Province, bool) {
+	key := strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if official, ok := districtAliases[key]; ok {
+		key = strings.ToLower(official)
+	}
+	entry, ok := districtIndex[key]
+	if !ok {
+		return "", nil, false
+	}
+	return entry.name, entry.province, true
+}
+
+// LookupProvince resolves a province by name, former name or number
+func LookupProvince(name string) (*Province, bool) {
+	key := strings.ToLower(strings.Join(strings.Fields(name), " "))
+	key = strings.TrimSuffix(key, " province")
+	if official, ok := provinceAliases[key]; ok {
+		key = strings.ToLower(official)
+	}
+	number, _ := strconv.Atoi(key)
+	for i := range NepalProvinces {
+		if strings.ToLower(NepalProvinces[i].Name) == key || NepalProvinces[i].Number == number {
+			return &NepalProvinces[i], true
+		}
+	}
+	return nil, false
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,243 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrApprovalLimitNotFound is returned when an approval limit does not exist for the tenant
+	ErrApprovalLimitNotFound = errors.New("approval limit not found")
+	// ErrInvalidApprovalLimit is returned when a limit has no known document type, names both or neither of role and user, or is negative
+	ErrInvalidApprovalLimit = errors.New("an approval limit needs a known document type, either a role or a user, and an amount of zero or more")
+	// ErrApprovalLimitNotPermitted is returned when the caller's role may not change approval limits
+	ErrApprovalLimitNotPermitted = errors.New("your role may not change approval limits")
+	// ErrApprovalRequestNotFound is returned when an approval request does not exist for the tenant
+	ErrApprovalRequestNotFound = errors.New("approval request not found")
+	// ErrApprovalState is returned when a request has already been decided or cancelled
+	ErrApprovalState = errors.New("approval request is no longer pending")
+	// ErrApprovalNotPermitted is returned when the reviewer's role or own limit does not cover the amount
+	ErrApprovalNotPermitted = errors.New("your role or approval limit does not cover this amount")
+	// ErrApprovalSelfApproval is returned when the requester tries to approve their own document
+	ErrApprovalSelfApproval = errors.New("a document must be approved by someone other than the requester")
+	// ErrUnknownApprovalDocument is returned when no module handles approvals for a document type
+	ErrUnknownApprovalDocument = errors.New("unknown approval document type")
+)
+
+// ApprovalDocumentType is a kind of document whose amount is limited per user or role
+type ApprovalDocumentType string
+
+const (
+	ApprovalPurchaseOrder   ApprovalDocumentType = "purchase_order"
+	ApprovalPurchaseBill    ApprovalDocumentType = "purchase_bill"
+	ApprovalSupplierPayment ApprovalDocumentType = "supplier_payment"
+)
+
+// ApprovalDocumentTypes are the document types limits can be set for
+var ApprovalDocumentTypes = []ApprovalDocumentType{ApprovalPurchaseOrder, ApprovalPurchaseBill, ApprovalSupplierPayment}
+
+// Valid reports whether limits can be set for the document type
+func (t ApprovalDocumentType) Valid() bool {
+	for _, known := range ApprovalDocumentTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ApprovalApproverRoles may approve documents, up to their own limit if they have one
+var ApprovalApproverRoles = []string{"owner", "admin", "manager"}
+
+// CanApproveDocuments reports whether a role may approve or reject documents
+func CanApproveDocuments(role string) bool {
+	for _, approver := range ApprovalApproverRoles {
+		if role == approver {
+			return true
+		}
+	}
+	return false
+}
+
+// ApprovalLimitManagerRoles may set and remove approval limits
+var ApprovalLimitManagerRoles = []string{"owner", "admin"}
+
+// CanManageApprovalLimits reports whether a role may set and remove approval limits
+func CanManageApprovalLimits(role string) bool {
+	for _, manager := range ApprovalLimitManagerRoles {
+		if role == manager {
+			return true
+		}
+	}
+	return false
+}
+
+// ApprovalLimit caps the amount of one document a user or role may raise without
+// approval, e.g. purchasers up to NPR 50,000 per purchase order. A user's own limit
+// replaces their role's; users with neither are not restricted.
+type ApprovalLimit struct {
+	ID           uuid.UUID            `json:"id" db:"id"`
+	TenantID     uuid.UUID            `json:"tenantId" db:"tenant_id"`
+	DocumentType ApprovalDocumentType `json:"documentType" db:"document_type"`
+	Role         *string              `json:"role,omitempty" db:"role"`
+	UserID       *uuid.UUID           `json:"userId,omitempty" db:"user_id"`
+	MaxAmount    float64              `json:"maxAmount" db:"max_amount"`
+	UpdatedBy    *uuid.UUID           `json:"updatedBy,omitempty" db:"updated_by"`
+
+	// Metadata
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// NewApprovalLimit creates a limit for a role or a user
+func NewApprovalLimit(tenantID uuid.UUID, documentType ApprovalDocumentType, role *string, userID *uuid.UUID, maxAmount float64, by *uuid.UUID) *ApprovalLimit {
+	now := time.Now()
+	if role != nil {
+		trimmed := strings.ToLower(strings.TrimSpace(*role))
+		role = &trimmed
+	}
+	return &ApprovalLimit{
+		ID:           uuid.New(),
+		TenantID:     tenantID,
+		DocumentType: documentType,
+		Role:         role,
+		UserID:       userID,
+		MaxAmount:    roundMoney(maxAmount),
+		UpdatedBy:    by,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// Validate checks the document type, subject and amount
+func (l *ApprovalLimit) Validate() error {
+	hasRole := l.Role != nil && *l.Role != ""
+	if !l.DocumentType.Valid() || hasRole == (l.UserID != nil) || l.MaxAmount < 0 || math.IsNaN(l.MaxAmount) {
+		return ErrInvalidApprovalLimit
+	}
+	return nil
+}
+
+// Allows reports whether an amount is within the limit
+func (l *ApprovalLimit) Allows(amount float64) bool {
+	return math.Round(amount*100) <= math.Round(l.MaxAmount*100)
+}
+
+// ApprovalStatus represents where an approval request is
+type ApprovalStatus string
+
+const (
+	ApprovalPending   ApprovalStatus = "pending"
+	ApprovalApproved  ApprovalStatus = "approved"
+	ApprovalRejected  ApprovalStatus = "rejected"
+	ApprovalCancelled ApprovalStatus = "cancelled" // Withdrawn by the requester
+)
+
+// ApprovalDocument is the document a request is raised for
+type ApprovalDocument struct {
+	TenantID uuid.UUID
+	Type     ApprovalDocumentType
+	ID       uuid.UUID
+	Number   *string
+	Amount   float64
+}
+
+// ApprovalRequest holds a document that exceeded its author's limit until someone
+// with a high enough limit approves it. The owning module completes or releases
+// the document when the request is decided.
+type ApprovalRequest struct {
+	ID             uuid.UUID            `json:"id" db:"id"`
+	TenantID       uuid.UUID            `json:"tenantId" db:"tenant_id"`
+	DocumentType   ApprovalDocumentType `json:"documentType" db:"document_type"`
+	DocumentID     uuid.UUID            `json:"documentId" db:"document_id"`
+	DocumentNumber *string              `json:"documentNumber,omitempty" db:"document_number"`
+	Amount         float64              `json:"amount" db:"amount"`
+	LimitAmount    float64              `json:"limitAmount" db:"limit_amount"` // The requester's limit that was exceeded
+	Status         ApprovalStatus       `json:"status" db:"status"`
+
+	// Approval
+	RequestedBy   uuid.UUID  `json:"requestedBy" db:"requested_by"`
+	RequesterRole string     `json:"requesterRole" db:"requester_role"`
+	ReviewedBy    *uuid.UUID `json:"reviewedBy,omitempty" db:"reviewed_by"`
+	ReviewerRole  *string    `json:"reviewerRole,omitempty" db:"reviewer_role"`
+	ReviewNote    *string    `json:"reviewNote,omitempty" db:"review_note"`
+	ReviewedAt    *time.Time `json:"reviewedAt,omitempty" db:"reviewed_at"`
+
+	// Metadata
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// NewApprovalRequest creates a pending request for a document over the requester's limit
+func NewApprovalRequest(doc ApprovalDocument, limit *ApprovalLimit, requestedBy uuid.UUID, role string) *ApprovalRequest {
+	now := time.Now()
+	return &ApprovalRequest{
+		ID:             uuid.New(),
+		TenantID:       doc.TenantID,
+		DocumentType:   doc.Type,
+		DocumentID:     doc.ID,
+		DocumentNumber: doc.Number,
+		Amount:         roundMoney(doc.Amount),
+		LimitAmount:    limit.MaxAmount,
+		Status:         ApprovalPending,
+		RequestedBy:    requestedBy,
+		RequesterRole:  role,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Approve marks the request approved by reviewerID acting as role. The reviewer's
+// own limit, if they have one, must cover the amount.
+func (r *ApprovalRequest) Approve(reviewerID uuid.UUID, role string, reviewerLimit *ApprovalLimit, note *string) error {
+	if err := r.review(reviewerID, role, note); err != nil {
+		return err
+	}
+	if r.RequestedBy == reviewerID {
+		return ErrApprovalSelfApproval
+	}
+	if reviewerLimit != nil && !reviewerLimit.Allows(r.Amount) {
+		return ErrApprovalNotPermitted
+	}
+	r.Status = ApprovalApproved
+	return nil
+}
+
+// Reject declines the request; the document goes back to its author
+func (r *ApprovalRequest) Reject(reviewerID uuid.UUID, role string, note *string) error {
+	if err := r.review(reviewerID, role, note); err != nil {
+		return err
+	}
+	r.Status = ApprovalRejected
+	return nil
+}
+
+// Cancel withdraws a pending request
+func (r *ApprovalRequest) Cancel() error {
+	if r.Status != ApprovalPending {
+		return ErrApprovalState
+	}
+	r.Status = ApprovalCancelled
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *ApprovalRequest) review(reviewerID uuid.UUID, role string, note *string) error {
+	if r.Status != ApprovalPending {
+		return ErrApprovalState
+	}
+	if !CanApproveDocuments(role) {
+		return ErrApprovalNotPermitted
+	}
+	now := time.Now()
+	r.ReviewedBy = &reviewerID
+	r.ReviewerRole = &role
+	r.ReviewNote = note
+	r.ReviewedAt = &now
+	r.UpdatedAt = now
+	return nil
+}
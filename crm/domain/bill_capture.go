@@ -0,0 +1,368 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrBillInboxNotFound is returned when an email is sent to an address no tenant owns
+	ErrBillInboxNotFound = errors.New("bill inbox not found")
+	// ErrBillDraftNotFound is returned when a draft purchase bill does not exist for the tenant
+	ErrBillDraftNotFound = errors.New("bill draft not found")
+	// ErrBillDraftState is returned when a draft that was already confirmed or rejected is changed
+	ErrBillDraftState = errors.New("bill draft has already been reviewed")
+	// ErrIncompleteBillDraft is returned when confirming a draft that is missing bill details
+	ErrIncompleteBillDraft = errors.New("a bill needs a supplier, bill number, bill date and total before it can be confirmed")
+	// ErrDuplicateInboundEmail is returned when the provider delivers the same message again
+	ErrDuplicateInboundEmail = errors.New("email has already been received")
+)
+
+// billInboxTokenLength is the length of the random part of an ingest address
+const billInboxTokenLength = 12
+
+// BillInbox is a tenant's ingest email address. Suppliers send bills to
+// <token>@<inbound domain>; rotating the token retires the old address.
+type BillInbox struct {
+	TenantID  uuid.UUID `json:"tenantId" db:"tenant_id"`
+	Token     string    `json:"token" db:"token"`
+	Address   string    `json:"address" db:"-"`
+	IsActive  bool      `json:"isActive" db:"is_active"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// NewBillInbox creates an active inbox with a random token
+func NewBillInbox(tenantID uuid.UUID) *BillInbox {
+	now := time.Now()
+	return &BillInbox{
+		TenantID:  tenantID,
+		Token:     NewBillInboxToken(),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewBillInboxToken generates an unguessable, lower-case mailbox name
+func NewBillInboxToken() string {
+	b := make([]byte, 10)
+	_, _ = rand.Read(b)
+	token := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+	return "bills-" + strings.ToLower(token[:billInboxTokenLength])
+}
+
+// SetAddress fills in the full address for the inbound mail domain
+func (i *BillInbox) SetAddress(inboundDomain string) {
+	if inboundDomain == "" {
+		i.Address = ""
+		return
+	}
+	i.Address = i.Token + "@" + inboundDomain
+}
+
+// BillInboxToken extracts the inbox token from a recipient address. Both
+// "<token>@domain" and plus-addressing ("bills+<token>@domain") are accepted.
+func BillInboxToken(recipient string) string {
+	address := strings.ToLower(strings.TrimSpace(recipient))
+	if start := strings.LastIndex(address, "<"); start != -1 {
+		address = strings.TrimSuffix(address[start+1:], ">")
+	}
+	local, _, ok := strings.Cut(address, "@")
+	if !ok {
+		return ""
+	}
+	if _, tag, ok := strings.Cut(local, "+"); ok {
+		return tag
+	}
+	return local
+}
+
+// InboundEmail is an email received from the mail provider, reduced to what bill capture needs
+type InboundEmail struct {
+	MessageID   string
+	From        string // Sender address only
+	To          []string
+	Subject     string
+	ReceivedAt  time.Time
+	Attachments []InboundAttachment
+}
+
+// InboundAttachment is a file attached to an inbound email
+type InboundAttachment struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// InboundEmailStatus is what happened to a received email
+type InboundEmailStatus string
+
+const (
+	InboundProcessed InboundEmailStatus = "processed" // Drafts were created
+	InboundIgnored   InboundEmailStatus = "ignored"   // No bill files attached
+)
+
+// InboundEmailRecord logs an email received at a tenant's inbox
+type InboundEmailRecord struct {
+	ID          uuid.UUID          `json:"id" db:"id"`
+	TenantID    uuid.UUID          `json:"tenantId" db:"tenant_id"`
+	MessageID   string             `json:"messageId" db:"message_id"`
+	Sender      string             `json:"sender" db:"sender"`
+	Recipient   string             `json:"recipient" db:"recipient"`
+	Subject     string             `json:"subject" db:"subject"`
+	Status      InboundEmailStatus `json:"status" db:"status"`
+	Attachments int                `json:"attachments" db:"attachments"`
+	Drafts      int                `json:"drafts" db:"drafts"`
+	Reason      *string            `json:"reason,omitempty" db:"reason"`
+	ReceivedAt  time.Time          `json:"receivedAt" db:"received_at"`
+}
+
+// BillDraftStatus is where a captured bill is in review
+type BillDraftStatus string
+
+const (
+	BillDraftPendingReview   BillDraftStatus = "pending_review"
+	BillDraftPendingApproval BillDraftStatus = "pending_approval" // Total is over the confirmer's approval limit
+	BillDraftConfirmed       BillDraftStatus = "confirmed"
+	BillDraftRejected        BillDraftStatus = "rejected" // Not a bill, duplicate or spam
+)
+
+// ExtractionStatus is the state of reading bill details from the file
+type ExtractionStatus string
+
+const (
+	ExtractionPending   ExtractionStatus = "pending"
+	ExtractionExtracted ExtractionStatus = "extracted"
+	ExtractionFailed    ExtractionStatus = "failed"
+	ExtractionSkipped   ExtractionStatus = "skipped" // No extractor configured
+)
+
+// PurchaseBillDraft is a supplier bill captured from email, waiting for someone to
+// check the details against the attached file and confirm it
+type PurchaseBillDraft struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	TenantID       uuid.UUID  `json:"tenantId" db:"tenant_id"`
+	InboundEmailID *uuid.UUID `json:"inboundEmailId,omitempty" db:"inbound_email_id"`
+	SupplierID     *uuid.UUID `json:"supplierId,omitempty" db:"supplier_id"` // Matched from the sender or the PAN on the bill
+	SenderEmail    string     `json:"senderEmail" db:"sender_email"`
+	Subject        string     `json:"subject" db:"subject"`
+	FileName       string     `json:"fileName" db:"file_name"`
+	AttachmentID   *uuid.UUID `json:"attachmentId,omitempty" db:"attachment_id"`
+
+	BillNumber  *string    `json:"billNumber,omitempty" db:"bill_number"`
+	BillDate    *time.Time `json:"billDate,omitempty" db:"bill_date"`
+	SupplierPAN *string    `json:"supplierPan,omitempty" db:"supplier_pan"`
+	SubTotal    *float64   `json:"subTotal,omitempty" db:"sub_total"`
+	VATAmount   *float64   `json:"vatAmount,omitempty" db:"vat_amount"`
+	TotalAmount *float64   `json:"totalAmount,omitempty" db:"total_amount"`
+
+	// When the bill must be paid, from the supplier's payment terms unless the reviewer sets it
+	DueDate     *time.Time       `json:"dueDate,omitempty" db:"due_date"`
+	DueSchedule []DueInstallment `json:"dueSchedule,omitempty" db:"due_schedule"`
+
+	Status               BillDraftStatus  `json:"status" db:"status"`
+	ExtractionStatus     ExtractionStatus `json:"extractionStatus" db:"extraction_status"`
+	ExtractionConfidence *float64         `json:"extractionConfidence,omitempty" db:"extraction_confidence"`
+	ExtractionError      *string          `json:"extractionError,omitempty" db:"extraction_error"`
+
+	ReviewedBy   *uuid.UUID `json:"reviewedBy,omitempty" db:"reviewed_by"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty" db:"reviewed_at"`
+	RejectReason *string    `json:"rejectReason,omitempty" db:"reject_reason"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// NewPurchaseBillDraft creates a draft awaiting review
+func NewPurchaseBillDraft(tenantID uuid.UUID, senderEmail, subject, fileName string) *PurchaseBillDraft {
+	now := time.Now()
+	return &PurchaseBillDraft{
+		ID:               uuid.New(),
+		TenantID:         tenantID,
+		SenderEmail:      strings.ToLower(strings.TrimSpace(senderEmail)),
+		Subject:          strings.TrimSpace(subject),
+		FileName:         fileName,
+		Status:           BillDraftPendingReview,
+		ExtractionStatus: ExtractionSkipped,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+// Validate checks that amounts are not negative and the bill is not due before it is dated
+func (d *PurchaseBillDraft) Validate() error {
+	for _, amount := range []*float64{d.SubTotal, d.VATAmount, d.TotalAmount} {
+		if amount != nil && *amount < 0 {
+			return errors.New("bill amounts cannot be negative")
+		}
+	}
+	if d.DueDate != nil && d.BillDate != nil && d.DueDate.Before(*d.BillDate) {
+		return errors.New("due date cannot be before the bill date")
+	}
+	return nil
+}
+
+// RoundOff is what the supplier added to the subtotal and VAT to reach the bill's
+// total by rounding it, negative when rounded down. Differences larger than
+// rounding to increment explains, and any difference when purchases are rounded
+// to the paisa, are left in the purchase amount.
+func (d *PurchaseBillDraft) RoundOff(increment float64) float64 {
+	if increment <= 0.01 || d.SubTotal == nil || d.VATAmount == nil || d.TotalAmount == nil {
+		return 0
+	}
+	diff := roundMoney(*d.TotalAmount - *d.SubTotal - *d.VATAmount)
+	if math.Abs(diff) > increment/2+0.005 {
+		return 0
+	}
+	return diff
+}
+
+// PurchaseRegisterLine is a confirmed purchase bill as listed in the purchase VAT register
+type PurchaseRegisterLine struct {
+	DraftID      uuid.UUID `json:"draftId"`
+	BillDate     time.Time `json:"billDate"`
+	BillNumber   string    `json:"billNumber"`
+	SupplierName string    `json:"supplierName"` // Supplier on record, else the sender address
+	SupplierPAN  string    `json:"supplierPan"`
+	Taxable      float64   `json:"taxable"`
+	VATAmount    float64   `json:"vatAmount"`
+	TotalAmount  float64   `json:"totalAmount"`
+}
+
+// BillExtraction is what was read from a bill file. Fields that could not be read are nil.
+type BillExtraction struct {
+	BillNumber  *string
+	BillDate    *time.Time
+	SupplierPAN *string
+	SubTotal    *float64
+	VATAmount   *float64
+	TotalAmount *float64
+	Confidence  float64 // 0-1
+}
+
+// ApplyExtraction fills in details read from the file, never overwriting what a reviewer entered
+func (d *PurchaseBillDraft) ApplyExtraction(extraction *BillExtraction) {
+	if d.BillNumber == nil {
+		d.BillNumber = extraction.BillNumber
+	}
+	if d.BillDate == nil {
+		d.BillDate = extraction.BillDate
+	}
+	if d.SupplierPAN == nil {
+		d.SupplierPAN = extraction.SupplierPAN
+	}
+	if d.SubTotal == nil {
+		d.SubTotal = extraction.SubTotal
+	}
+	if d.VATAmount == nil {
+		d.VATAmount = extraction.VATAmount
+	}
+	if d.TotalAmount == nil {
+		d.TotalAmount = extraction.TotalAmount
+	}
+	confidence := extraction.Confidence
+	d.ExtractionConfidence = &confidence
+	d.ExtractionStatus = ExtractionExtracted
+	d.ExtractionError = nil
+	d.UpdatedAt = time.Now()
+}
+
+// FailExtraction records why the details could not be read
+func (d *PurchaseBillDraft) FailExtraction(reason string) {
+	d.ExtractionStatus = ExtractionFailed
+	d.ExtractionError = &reason
+	d.UpdatedAt = time.Now()
+}
+
+// Ready checks that a pending draft has the details needed to confirm it
+func (d *PurchaseBillDraft) Ready() error {
+	if d.Status != BillDraftPendingReview {
+		return ErrBillDraftState
+	}
+	if d.SupplierID == nil || d.BillNumber == nil || *d.BillNumber == "" || d.BillDate == nil || d.TotalAmount == nil {
+		return ErrIncompleteBillDraft
+	}
+	return d.Validate()
+}
+
+// ApplyTerms sets when the bill falls due: on the reviewer's due date if given,
+// otherwise on the supplier's payment terms from the bill date
+func (d *PurchaseBillDraft) ApplyTerms(terms *PaymentTerms, calendar *utils.WorkingCalendar) {
+	if d.BillDate == nil || d.TotalAmount == nil {
+		return
+	}
+	if d.DueDate != nil {
+		d.DueSchedule = []DueInstallment{{
+			Sequence:  1,
+			DueDate:   *d.DueDate,
+			DueDateBS: utils.ADToBS(*d.DueDate).String(),
+			Amount:    roundMoney(*d.TotalAmount),
+		}}
+		return
+	}
+	d.DueSchedule = terms.Schedule(*d.BillDate, *d.TotalAmount, calendar)
+	d.DueDate = FinalDueDate(d.DueSchedule)
+}
+
+// Confirm accepts the draft once the reviewer has checked it against the file
+func (d *PurchaseBillDraft) Confirm(by *uuid.UUID) error {
+	if err := d.Ready(); err != nil {
+		return err
+	}
+	now := time.Now()
+	d.Status = BillDraftConfirmed
+	d.ReviewedBy = by
+	d.ReviewedAt = &now
+	d.UpdatedAt = now
+	return nil
+}
+
+// AwaitApproval holds a ready draft until its approval request is decided
+func (d *PurchaseBillDraft) AwaitApproval() error {
+	if err := d.Ready(); err != nil {
+		return err
+	}
+	d.Status = BillDraftPendingApproval
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
+// ApprovalDecided confirms a held draft when approved, or returns it for review
+// when the request was rejected or withdrawn
+func (d *PurchaseBillDraft) ApprovalDecided(approved bool, by *uuid.UUID) error {
+	if d.Status != BillDraftPendingApproval {
+		return ErrBillDraftState
+	}
+	now := time.Now()
+	d.UpdatedAt = now
+	if !approved {
+		d.Status = BillDraftPendingReview
+		return nil
+	}
+	d.Status = BillDraftConfirmed
+	d.ReviewedBy = by
+	d.ReviewedAt = &now
+	return nil
+}
+
+// Reject discards the draft
+func (d *PurchaseBillDraft) Reject(reason string, by *uuid.UUID) error {
+	if d.Status != BillDraftPendingReview {
+		return ErrBillDraftState
+	}
+	now := time.Now()
+	reason = strings.TrimSpace(reason)
+	d.Status = BillDraftRejected
+	d.RejectReason = &reason
+	d.ReviewedBy = by
+	d.ReviewedAt = &now
+	d.UpdatedAt = now
+	return nil
+}
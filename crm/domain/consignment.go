@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrSupplierNotFound is returned when a supplier does not exist for the tenant
+	ErrSupplierNotFound = errors.New("supplier not found")
+	// ErrConsignmentReceiptNotFound is returned when a consignment receipt does not exist for the tenant
+	ErrConsignmentReceiptNotFound = errors.New("consignment receipt not found")
+	// ErrConsignmentBillNotFound is returned when a consignment bill does not exist for the tenant
+	ErrConsignmentBillNotFound = errors.New("consignment bill not found")
+	// ErrInsufficientConsignmentStock is returned when selling or returning more than is held on consignment
+	ErrInsufficientConsignmentStock = errors.New("not enough consignment stock")
+	// ErrInvalidConsignmentLine is returned for a line with no product, a non-positive quantity or a negative cost
+	ErrInvalidConsignmentLine = errors.New("invalid consignment line")
+)
+
+// ConsignmentReceipt records goods received from a supplier on consignment.
+// The stock is held but still owned by the supplier, so no payable is raised;
+// the supplier is billed only for what is sold (see ConsignmentBill).
+type ConsignmentReceipt struct {
+	ID            uuid.UUID         `json:"id" db:"id"`
+	TenantID      uuid.UUID         `json:"tenantId" db:"tenant_id"`
+	SupplierID    uuid.UUID         `json:"supplierId" db:"supplier_id"`
+	ReceiptNumber string            `json:"receiptNumber" db:"receipt_number"`
+	ReceivedAt    time.Time         `json:"receivedAt" db:"received_at"`
+	Reference     *string           `json:"reference,omitempty" db:"reference"` // Supplier's delivery note
+	Note          *string           `json:"note,omitempty" db:"note"`
+	CreatedBy     *uuid.UUID        `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt     time.Time         `json:"createdAt" db:"created_at"`
+	Lines         []ConsignmentLine `json:"lines"`
+}
+
+// ConsignmentLine is a product received on consignment and how much of it has been settled
+type ConsignmentLine struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	ReceiptID   uuid.UUID `json:"receiptId" db:"receipt_id"`
+	SupplierID  uuid.UUID `json:"supplierId" db:"supplier_id"`
+	ProductID   uuid.UUID `json:"productId" db:"product_id"`
+	Quantity    float64   `json:"quantity" db:"quantity"`
+	UnitCost    float64   `json:"unitCost" db:"unit_cost"` // Agreed price payable to the supplier per unit sold
+	SoldQty     float64   `json:"soldQty" db:"sold_qty"`
+	ReturnedQty float64   `json:"returnedQty" db:"returned_qty"` // Unsold stock sent back to the supplier
+}
+
+// NewConsignmentReceipt creates a receipt; the number is assigned when it is saved
+func NewConsignmentReceipt(tenantID, supplierID uuid.UUID, receivedAt time.Time) *ConsignmentReceipt {
+	return &ConsignmentReceipt{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		SupplierID: supplierID,
+		ReceivedAt: receivedAt,
+		CreatedAt:  time.Now(),
+		Lines:      []ConsignmentLine{},
+	}
+}
+
+// AddLine adds a received product to the receipt
+func (r *ConsignmentReceipt) AddLine(productID uuid.UUID, quantity, unitCost float64) error {
+	if productID == uuid.Nil || quantity <= 0 || unitCost < 0 {
+		return ErrInvalidConsignmentLine
+	}
+	r.Lines = append(r.Lines, ConsignmentLine{
+		ID:         uuid.New(),
+		ReceiptID:  r.ID,
+		SupplierID: r.SupplierID,
+		ProductID:  productID,
+		Quantity:   quantity,
+		UnitCost:   unitCost,
+	})
+	return nil
+}
+
+// OnHand returns the quantity still held on consignment
+func (l *ConsignmentLine) OnHand() float64 {
+	return l.Quantity - l.SoldQty - l.ReturnedQty
+}
+
+// Consume takes up to quantity from the lines in order (oldest receipt first) and marks it sold.
+// It returns the bill lines for what was taken; any quantity left over was not on consignment.
+func Consume(lines []*ConsignmentLine, quantity float64) []ConsignmentBillLine {
+	billLines := []ConsignmentBillLine{}
+	for _, line := range lines {
+		if quantity <= 0 {
+			break
+		}
+		take := math.Min(line.OnHand(), quantity)
+		if take <= 0 {
+			continue
+		}
+		line.SoldQty += take
+		quantity -= take
+		billLines = append(billLines, ConsignmentBillLine{
+			ID:        uuid.New(),
+			LineID:    line.ID,
+			ProductID: line.ProductID,
+			Quantity:  take,
+			UnitCost:  line.UnitCost,
+			Amount:    roundMoney(take * line.UnitCost),
+		})
+	}
+	return billLines
+}
+
+// ConsignmentBill is the supplier purchase bill raised automatically when consignment stock is sold.
+// It is the point at which the store owes the supplier (the AP liability).
+type ConsignmentBill struct {
+	ID             uuid.UUID             `json:"id" db:"id"`
+	TenantID       uuid.UUID             `json:"tenantId" db:"tenant_id"`
+	SupplierID     uuid.UUID             `json:"supplierId" db:"supplier_id"`
+	BillNumber     string                `json:"billNumber" db:"bill_number"`
+	SaleType       *string               `json:"saleType,omitempty" db:"sale_type"`
+	SaleID         *uuid.UUID            `json:"saleId,omitempty" db:"sale_id"`
+	TotalAmount    float64               `json:"totalAmount" db:"total_amount"`
+	JournalEntryID *uuid.UUID            `json:"journalEntryId,omitempty" db:"journal_entry_id"`
+	CreatedAt      time.Time             `json:"createdAt" db:"created_at"`
+	Lines          []ConsignmentBillLine `json:"lines"`
+}
+
+// NewConsignmentBill creates a supplier bill for consignment stock consumed by a sale
+func NewConsignmentBill(tenantID, supplierID uuid.UUID, saleType *string, saleID *uuid.UUID, lines []ConsignmentBillLine) *ConsignmentBill {
+	bill := &ConsignmentBill{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		SupplierID: supplierID,
+		SaleType:   saleType,
+		SaleID:     saleID,
+		CreatedAt:  time.Now(),
+		Lines:      lines,
+	}
+	for i := range bill.Lines {
+		bill.Lines[i].BillID = bill.ID
+		bill.TotalAmount += bill.Lines[i].Amount
+	}
+	bill.TotalAmount = roundMoney(bill.TotalAmount)
+	return bill
+}
+
+// ConsignmentBillLine is the cost of consignment stock consumed from one receipt line
+type ConsignmentBillLine struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	BillID    uuid.UUID `json:"billId" db:"bill_id"`
+	LineID    uuid.UUID `json:"lineId" db:"line_id"` // Consignment line the stock came from
+	ProductID uuid.UUID `json:"productId" db:"product_id"`
+	Quantity  float64   `json:"quantity" db:"quantity"`
+	UnitCost  float64   `json:"unitCost" db:"unit_cost"`
+	Amount    float64   `json:"amount" db:"amount"`
+}
+
+// ConsignmentSaleItem is a sold product to settle against consignment stock
+type ConsignmentSaleItem struct {
+	ProductID uuid.UUID `json:"productId"`
+	Quantity  float64   `json:"quantity"`
+}
+
+// ConsignmentStock is a supplier's consignment stock of one product
+type ConsignmentStock struct {
+	SupplierID   uuid.UUID `json:"supplierId" db:"supplier_id"`
+	SupplierCode string    `json:"supplierCode" db:"supplier_code"`
+	SupplierName string    `json:"supplierName" db:"supplier_name"`
+	ProductID    uuid.UUID `json:"productId" db:"product_id"`
+	Received     float64   `json:"received" db:"received"`
+	Sold         float64   `json:"sold" db:"sold"`
+	Returned     float64   `json:"returned" db:"returned"`
+	OnHand       float64   `json:"onHand" db:"on_hand"`
+	OnHandValue  float64   `json:"onHandValue" db:"on_hand_value"` // At the agreed unit cost; not a liability until sold
+	SoldValue    float64   `json:"soldValue" db:"sold_value"`      // Billed to date
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrContainerTypeNotFound is returned when a container type does not exist for the tenant
+	ErrContainerTypeNotFound = errors.New("container type not found")
+	// ErrInsufficientContainers is returned when a customer returns more containers than they hold
+	ErrInsufficientContainers = errors.New("customer does not hold that many containers")
+	// ErrInvalidContainerMovement is returned for a zero or negative quantity
+	ErrInvalidContainerMovement = errors.New("container quantity must be positive")
+)
+
+// ContainerType is a returnable item carrying a deposit: a crate, bottle, keg or gas cylinder
+type ContainerType struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	TenantID      uuid.UUID `json:"tenantId" db:"tenant_id"`
+	Code          string    `json:"code" db:"code"`
+	Name          string    `json:"name" db:"name"`
+	DepositAmount float64   `json:"depositAmount" db:"deposit_amount"` // Charged per unit issued
+	IsActive      bool      `json:"isActive" db:"is_active"`
+
+	// Metadata
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// NewContainerType creates an active container type
+func NewContainerType(tenantID uuid.UUID, code, name string, depositAmount float64) *ContainerType {
+	now := time.Now()
+	return &ContainerType{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		Code:          code,
+		Name:          name,
+		DepositAmount: depositAmount,
+		IsActive:      true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// ContainerMovementKind is the direction of a container movement
+type ContainerMovementKind string
+
+const (
+	ContainerIssued   ContainerMovementKind = "issue"  // Sent out with a sale; deposit collected
+	ContainerReturned ContainerMovementKind = "return" // Brought back; deposit refunded
+)
+
+// ContainerMovement records containers going to or coming back from a customer.
+// Sales and sales returns record movements against their document via ReferenceType/ReferenceID.
+type ContainerMovement struct {
+	ID              uuid.UUID             `json:"id" db:"id"`
+	TenantID        uuid.UUID             `json:"tenantId" db:"tenant_id"`
+	CustomerID      uuid.UUID             `json:"customerId" db:"customer_id"`
+	ContainerTypeID uuid.UUID             `json:"containerTypeId" db:"container_type_id"`
+	Kind            ContainerMovementKind `json:"kind" db:"kind"`
+	Quantity        int                   `json:"quantity" db:"quantity"`
+	DepositAmount   float64               `json:"depositAmount" db:"deposit_amount"` // Total collected (issue) or refunded (return)
+	ReferenceType   *string               `json:"referenceType,omitempty" db:"reference_type"`
+	ReferenceID     *uuid.UUID            `json:"referenceId,omitempty" db:"reference_id"`
+	Note            *string               `json:"note,omitempty" db:"note"`
+	JournalEntryID  *uuid.UUID            `json:"journalEntryId,omitempty" db:"journal_entry_id"`
+	CreatedBy       *uuid.UUID            `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt       time.Time             `json:"createdAt" db:"created_at"`
+}
+
+// NewContainerMovement creates a movement; the deposit amount is filled in when it is recorded
+func NewContainerMovement(tenantID, customerID, containerTypeID uuid.UUID, kind ContainerMovementKind, quantity int) *ContainerMovement {
+	return &ContainerMovement{
+		ID:              uuid.New(),
+		TenantID:        tenantID,
+		CustomerID:      customerID,
+		ContainerTypeID: containerTypeID,
+		Kind:            kind,
+		Quantity:        quantity,
+		CreatedAt:       time.Now(),
+	}
+}
+
+// ContainerBalance is how many containers of a type a customer holds and the deposit held for them
+type ContainerBalance struct {
+	TenantID        uuid.UUID `json:"tenantId" db:"tenant_id"`
+	CustomerID      uuid.UUID `json:"customerId" db:"customer_id"`
+	CustomerCode    string    `json:"customerCode" db:"customer_code"`
+	CustomerName    string    `json:"customerName" db:"customer_name"`
+	ContainerTypeID uuid.UUID `json:"containerTypeId" db:"container_type_id"`
+	ContainerCode   string    `json:"containerCode" db:"container_code"`
+	ContainerName   string    `json:"containerName" db:"container_name"`
+	Quantity        int       `json:"quantity" db:"quantity"`
+	DepositHeld     float64   `json:"depositHeld" db:"deposit_held"` // Deposit liability owed back to the customer
+	UpdatedAt       time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// RefundFor returns the deposit refunded for returning quantity containers,
+// at the average deposit actually collected so the liability clears exactly
+func (b *ContainerBalance) RefundFor(quantity int) float64 {
+	if b.Quantity <= 0 {
+		return 0
+	}
+	if quantity >= b.Quantity {
+		return b.DepositHeld
+	}
+	return roundMoney(b.DepositHeld * float64(quantity) / float64(b.Quantity))
+}
+
+// ContainerBalanceReport totals outstanding containers and deposit liability for a tenant
+type ContainerBalanceReport struct {
+	Balances         []*ContainerBalance `json:"balances"`
+	TotalContainers  int                 `json:"totalContainers"`
+	DepositLiability float64             `json:"depositLiability"`
+}
+
+// NewContainerBalanceReport sums the given balances
+func NewContainerBalanceReport(balances []*ContainerBalance) *ContainerBalanceReport {
+	report := &ContainerBalanceReport{Balances: balances}
+	for _, balance := range balances {
+		report.TotalContainers += balance.Quantity
+		report.DepositLiability += balance.DepositHeld
+	}
+	report.DepositLiability = roundMoney(report.DepositLiability)
+	return report
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
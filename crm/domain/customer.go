@@ -1,11 +1,16 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrCustomerNotFound is returned when a customer does not exist for the tenant
+var ErrCustomerNotFound = errors.New("customer not found")
+
 // CustomerType represents the type of customer
 type CustomerType string
 
@@ -126,6 +131,16 @@ func (c *Customer) SetCreditLimit(limit float64) {
 	c.SetCustomAttribute("credit_limit", limit)
 }
 
+// IsDunningOptOut reports whether the customer asked not to receive dues reminders
+func (c *Customer) IsDunningOptOut() bool {
+	return c.GetCustomBool("dunning_opt_out")
+}
+
+// SetDunningOptOut sets whether the customer receives dues reminders
+func (c *Customer) SetDunningOptOut(optOut bool) {
+	c.SetCustomAttribute("dunning_opt_out", optOut)
+}
+
 // GetAddress retrieves address
 func (c *Customer) GetAddress() string {
 	return c.GetCustomString("address")
@@ -136,6 +151,33 @@ func (c *Customer) SetAddress(address string) {
 	c.SetCustomAttribute("address", address)
 }
 
+// GetStructuredAddress retrieves the structured address, or nil if only a free-form address is set
+func (c *Customer) GetStructuredAddress() *Address {
+	return decodeAddress(c.CustomAttributes[addressDetailKey])
+}
+
+// SetStructuredAddress sets the structured address and the single-line address derived from it
+func (c *Customer) SetStructuredAddress(address *Address) {
+	c.SetCustomAttribute(addressDetailKey, encodeAddress(address))
+	c.SetAddress(address.String())
+}
+
+// NormalizeAddress validates the structured address, if one is set, and refreshes the single-line address from it
+func (c *Customer) NormalizeAddress() error {
+	if _, ok := c.CustomAttributes[addressDetailKey]; !ok {
+		return nil
+	}
+	address := c.GetStructuredAddress()
+	if address == nil {
+		return fmt.Errorf("%w: district is required", ErrInvalidAddress)
+	}
+	if err := address.Normalize(); err != nil {
+		return err
+	}
+	c.SetStructuredAddress(address)
+	return nil
+}
+
 // IsActive checks if customer is active
 func (c *Customer) IsActive() bool {
 	return c.Status == CustomerStatusActive
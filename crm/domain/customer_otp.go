@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// OTPLength is the number of digits in a verification code
+	OTPLength = 6
+	// OTPTTL is how long a code can be entered after it is sent
+	OTPTTL = 5 * time.Minute
+	// OTPMaxAttempts is how many wrong codes end a challenge
+	OTPMaxAttempts = 5
+	// OTPResendInterval is the minimum time between codes sent to one customer
+	OTPResendInterval = 30 * time.Second
+	// OTPProofTTL is how long a verified phone can be used for a sale
+	OTPProofTTL = 30 * time.Minute
+)
+
+var (
+	// ErrOTPNotFound is returned when a verification challenge does not exist for the tenant
+	ErrOTPNotFound = errors.New("verification not found")
+	// ErrOTPNoRecipient is returned when the customer has no phone or email for the channel
+	ErrOTPNoRecipient = errors.New("customer has no contact for this channel")
+	// ErrOTPTooSoon is returned when a code is requested again within OTPResendInterval
+	ErrOTPTooSoon = errors.New("a code was sent moments ago; wait before requesting another")
+	// ErrOTPExpired is returned for a code entered after it expired or after too many attempts
+	ErrOTPExpired = errors.New("verification code expired; request a new one")
+	// ErrOTPInvalidCode is returned for a wrong code
+	ErrOTPInvalidCode = errors.New("incorrect verification code")
+	// ErrOTPNotVerified is returned when an unverified, stale or already used challenge is attached to an invoice
+	ErrOTPNotVerified = errors.New("customer verification is not valid for this sale")
+)
+
+// OTPChannel is how a code reaches the customer
+type OTPChannel string
+
+const (
+	OTPChannelSMS   OTPChannel = "SMS"
+	OTPChannelEmail OTPChannel = "EMAIL"
+)
+
+// CustomerOTP is a code sent to a customer's phone or email to confirm, at the
+// counter, that the buyer of a credit sale controls it
+type CustomerOTP struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	TenantID       uuid.UUID  `json:"tenantId" db:"tenant_id"`
+	CustomerID     uuid.UUID  `json:"customerId" db:"customer_id"`
+	Channel        OTPChannel `json:"channel" db:"channel"`
+	Recipient      string     `json:"-" db:"recipient"`
+	CodeHash       string     `json:"-" db:"code_hash"`
+	Attempts       int        `json:"attempts" db:"attempts"`
+	ExpiresAt      time.Time  `json:"expiresAt" db:"expires_at"`
+	VerifiedAt     *time.Time `json:"verifiedAt,omitempty" db:"verified_at"`
+	VerifiedBy     *uuid.UUID `json:"verifiedBy,omitempty" db:"verified_by"`
+	InvoiceID      *uuid.UUID `json:"invoiceId,omitempty" db:"invoice_id"` // Sale the verification was recorded on
+	NotificationID *uuid.UUID `json:"notificationId,omitempty" db:"notification_id"`
+	RequestedBy    uuid.UUID  `json:"requestedBy" db:"requested_by"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// NewCustomerOTP creates a challenge for a code; only the code's hash is kept
+func NewCustomerOTP(tenantID, customerID uuid.UUID, channel OTPChannel, recipient, code string, requestedBy uuid.UUID) *CustomerOTP {
+	now := time.Now()
+	otp := &CustomerOTP{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		CustomerID:  customerID,
+		Channel:     channel,
+		Recipient:   recipient,
+		ExpiresAt:   now.Add(OTPTTL),
+		RequestedBy: requestedBy,
+		CreatedAt:   now,
+	}
+	otp.CodeHash = otp.hash(code)
+	return otp
+}
+
+// hash binds a code to its challenge so equal codes hash differently
+func (o *CustomerOTP) hash(code string) string {
+	sum := sha256.Sum256([]byte(o.ID.String() + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// Open reports whether a code can still be entered
+func (o *CustomerOTP) Open(now time.Time) bool {
+	return o.VerifiedAt == nil && !now.After(o.ExpiresAt) && o.Attempts < OTPMaxAttempts
+}
+
+// Verify checks a code whose attempt is already counted in Attempts; a correct
+// code marks the challenge verified
+func (o *CustomerOTP) Verify(code string, userID uuid.UUID, now time.Time) error {
+	if o.VerifiedAt != nil {
+		return nil
+	}
+	if now.After(o.ExpiresAt) || o.Attempts > OTPMaxAttempts {
+		return ErrOTPExpired
+	}
+
+	code = strings.TrimSpace(code)
+	if subtle.ConstantTimeCompare([]byte(o.hash(code)), []byte(o.CodeHash)) != 1 {
+		if o.Attempts >= OTPMaxAttempts {
+			return ErrOTPExpired
+		}
+		return ErrOTPInvalidCode
+	}
+
+	o.VerifiedAt = &now
+	o.VerifiedBy = &userID
+	return nil
+}
+
+// UsableFor reports whether the verification can be recorded on a sale to the customer
+func (o *CustomerOTP) UsableFor(customerID uuid.UUID, now time.Time) bool {
+	return o.VerifiedAt != nil && o.InvoiceID == nil && o.CustomerID == customerID &&
+		now.Sub(*o.VerifiedAt) <= OTPProofTTL
+}
+
+// MaskedRecipient shows enough of the phone or email for the cashier to read it back
+func (o *CustomerOTP) MaskedRecipient() string {
+	return MaskContact(o.Recipient)
+}
+
+// MaskContact hides all but the last digits of a phone, or most of an email's local part
+func MaskContact(contact string) string {
+	if at := strings.LastIndex(contact, "@"); at > 0 {
+		local := contact[:at]
+		return local[:1] + strings.Repeat("*", len(local)-1) + contact[at:]
+	}
+	if len(contact) <= 3 {
+		return strings.Repeat("*", len(contact))
+	}
+	return strings.Repeat("*", len(contact)-3) + contact[len(contact)-3:]
+}
+
+// OTPProof is what a sale records about the customer's verification, kept on
+// the invoice to settle disputes over who took the credit
+type OTPProof struct {
+	VerificationID uuid.UUID  `json:"verificationId"`
+	CustomerID     uuid.UUID  `json:"customerId"`
+	Channel        OTPChannel `json:"channel"`
+	Recipient      string     `json:"recipient"` // Masked
+	VerifiedAt     time.Time  `json:"verifiedAt"`
+	VerifiedBy     uuid.UUID  `json:"verifiedBy"`
+}
+
+// Proof summarizes a verified challenge
+func (o *CustomerOTP) Proof() *OTPProof {
+	proof := &OTPProof{
+		VerificationID: o.ID,
+		CustomerID:     o.CustomerID,
+		Channel:        o.Channel,
+		Recipient:      o.MaskedRecipient(),
+	}
+	if o.VerifiedAt != nil {
+		proof.VerifiedAt = *o.VerifiedAt
+	}
+	if o.VerifiedBy != nil {
+		proof.VerifiedBy = *o.VerifiedBy
+	}
+	return proof
+}
@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aceextension/crm/taxid"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCustomerIDTaken is returned when a client-generated ID already belongs to another tenant's record
+	ErrCustomerIDTaken = errors.New("customer id already in use")
+	// ErrDuplicateCustomer is returned when an offline customer matches existing customers by phone or PAN
+	ErrDuplicateCustomer = errors.New("customer may already exist")
+	// ErrInvalidResolution is returned for an unknown resolution or a merge target that is not a match
+	ErrInvalidResolution = errors.New("invalid duplicate resolution")
+)
+
+// SyncResolution is how the client settles an offline customer that matched existing ones
+type SyncResolution string
+
+const (
+	// ResolutionNone reports matches back to the client instead of creating
+	ResolutionNone SyncResolution = ""
+	// ResolutionCreate creates the customer anyway
+	ResolutionCreate SyncResolution = "create"
+	// ResolutionMerge folds the offline customer into an existing one
+	ResolutionMerge SyncResolution = "merge"
+)
+
+// SyncOutcome is what happened to a synced customer
+type SyncOutcome string
+
+const (
+	SyncCreated  SyncOutcome = "created"  // A new customer was saved
+	SyncExisting SyncOutcome = "existing" // The ID was synced before; nothing changed
+	SyncMerged   SyncOutcome = "merged"   // The customer was merged into an existing one
+)
+
+// CustomerSync describes a customer created offline on a device
+type CustomerSync struct {
+	DeviceID   string         // Device that created the customer, recorded in audit
+	Resolution SyncResolution // How to settle phone/PAN matches
+	MergeInto  *uuid.UUID     // Existing customer to merge into, for ResolutionMerge
+}
+
+// Validate checks the resolution is known and a merge names its target
+func (s *CustomerSync) Validate() error {
+	switch s.Resolution {
+	case ResolutionNone, ResolutionCreate:
+		return nil
+	case ResolutionMerge:
+		if s.MergeInto == nil || *s.MergeInto == uuid.Nil {
+			return ErrInvalidResolution
+		}
+		return nil
+	}
+	return ErrInvalidResolution
+}
+
+// CustomerMatch is an existing customer that looks like the one being synced
+type CustomerMatch struct {
+	Customer  *Customer `json:"customer"`
+	MatchedOn []string  `json:"matchedOn"` // phone, pan
+}
+
+// CustomerSyncResult is the customer a sync resolved to
+type CustomerSyncResult struct {
+	Outcome  SyncOutcome
+	Customer *Customer
+}
+
+// DuplicateCustomerError carries the matches of an offline customer so the
+// client can choose to merge or create
+type DuplicateCustomerError struct {
+	Matches []CustomerMatch
+}
+
+func (e *DuplicateCustomerError) Error() string {
+	return ErrDuplicateCustomer.Error()
+}
+
+func (e *DuplicateCustomerError) Unwrap() error {
+	return ErrDuplicateCustomer
+}
+
+// NormalizePhone reduces a phone number to its digits, without Nepal's 977
+// country code, so "+977 984-1234567" and "9841234567" compare equal
+func NormalizePhone(phone string) string {
+	digits := digitsOnly(phone)
+	if len(digits) >= 13 && strings.HasPrefix(digits, "977") {
+		digits = digits[3:]
+	}
+	return digits
+}
+
+// NormalizePAN reduces a PAN/VAT number to its digits, reading Nepali digits as ASCII
+func NormalizePAN(pan string) string {
+	return digitsOnly(taxid.Normalize(pan))
+}
+
+// digitsOnly drops everything but ASCII digits, matching the repository's [^0-9] normalization
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// DedupeKeys returns the customer's normalized phone and PAN; either may be empty
+func (c *Customer) DedupeKeys() (phone, pan string) {
+	if c.Phone != nil {
+		phone = NormalizePhone(*c.Phone)
+	}
+	return phone, NormalizePAN(c.GetPANNumber())
+}
+
+// MatchedOn lists which of the keys another customer shares with this one
+func (c *Customer) MatchedOn(other *Customer) []string {
+	phone, pan := c.DedupeKeys()
+	otherPhone, otherPAN := other.DedupeKeys()
+
+	matched := []string{}
+	if phone != "" && phone == otherPhone {
+		matched = append(matched, "phone")
+	}
+	if pan != "" && pan == otherPAN {
+		matched = append(matched, "pan")
+	}
+	return matched
+}
+
+// MergeFrom fills the customer's empty fields and missing custom attributes
+// from an offline duplicate; values already on the customer win
+func (c *Customer) MergeFrom(other *Customer) {
+	if c.Email == nil && other.Email != nil {
+		c.Email = other.Email
+	}
+	if c.Phone == nil && other.Phone != nil {
+		c.Phone = other.Phone
+	}
+	for key, value := range other.CustomAttributes {
+		if _, ok := c.CustomAttributes[key]; !ok {
+			c.SetCustomAttribute(key, value)
+		}
+	}
+	c.UpdatedAt = time.Now()
+}
+
+// CustomerAlias maps the ID a device gave an offline customer to the existing
+// customer it was merged into, so re-syncing that ID resolves to the same customer
+type CustomerAlias struct {
+	AliasID    uuid.UUID `json:"aliasId" db:"alias_id"`
+	TenantID   uuid.UUID `json:"tenantId" db:"tenant_id"`
+	CustomerID uuid.UUID `json:"customerId" db:"customer_id"`
+	DeviceID   *string   `json:"deviceId,omitempty" db:"device_id"`
+	CreatedBy  uuid.UUID `json:"createdBy" db:"created_by"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,355 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrRouteNotFound is returned when a delivery route does not exist for the tenant
+	ErrRouteNotFound = errors.New("delivery route not found")
+	// ErrNoRoute is returned when queuing a delivery for a customer who is not on any route
+	ErrNoRoute = errors.New("customer is not assigned to a delivery route")
+	// ErrDeliveryNotFound is returned when a delivery does not exist for the tenant
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	// ErrDeliveryState is returned when a delivery that is no longer pending is marked again
+	ErrDeliveryState = errors.New("delivery is no longer pending")
+	// ErrNotRouteDriver is returned when someone other than the route's driver or a manager marks a delivery
+	ErrNotRouteDriver = errors.New("only the route's driver or a manager can update its deliveries")
+	// ErrInvalidDelivery is returned for a delivery with no invoice number, no lines or negative amounts
+	ErrInvalidDelivery = errors.New("invalid delivery")
+)
+
+// DeliveryManagerRoles may update any route's deliveries; other staff only those on routes they drive
+var DeliveryManagerRoles = []string{"owner", "admin", "manager"}
+
+// CanManageDeliveries reports whether role may update deliveries on any route
+func CanManageDeliveries(role string) bool {
+	for _, r := range DeliveryManagerRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryRoute is a fixed run a distributor's van makes to a set of shops on given weekdays
+type DeliveryRoute struct {
+	ID       uuid.UUID  `json:"id" db:"id"`
+	TenantID uuid.UUID  `json:"tenantId" db:"tenant_id"`
+	Code     string     `json:"code" db:"code"`
+	Name     string     `json:"name" db:"name"`
+	Weekdays []int      `json:"weekdays" db:"weekdays"`            // 0 = Sunday ... 6 = Saturday
+	DriverID *uuid.UUID `json:"driverId,omitempty" db:"driver_id"` // User who drives the route and marks its deliveries
+	Vehicle  *string    `json:"vehicle,omitempty" db:"vehicle"`
+	IsActive bool       `json:"isActive" db:"is_active"`
+
+	// Metadata
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// NewDeliveryRoute creates an active route
+func NewDeliveryRoute(tenantID uuid.UUID, code, name string, weekdays []int) *DeliveryRoute {
+	now := time.Now()
+	return &DeliveryRoute{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Code:      strings.TrimSpace(code),
+		Name:      strings.TrimSpace(name),
+		Weekdays:  normalizeWeekdays(weekdays),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate checks the code, name and weekdays
+func (r *DeliveryRoute) Validate() error {
+	if r.Code == "" || r.Name == "" {
+		return errors.New("route code and name are required")
+	}
+	for _, day := range r.Weekdays {
+		if day < 0 || day > 6 {
+			return errors.New("weekdays must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+	return nil
+}
+
+// RunsOn reports whether the route is driven on date's weekday
+func (r *DeliveryRoute) RunsOn(date time.Time) bool {
+	for _, day := range r.Weekdays {
+		if time.Weekday(day) == date.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeWeekdays sorts the weekdays and drops repeats
+func normalizeWeekdays(weekdays []int) []int {
+	seen := map[int]bool{}
+	result := []int{}
+	for _, day := range weekdays {
+		if !seen[day] {
+			seen[day] = true
+			result = append(result, day)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+// RouteStop is a customer assigned to a route, in the order the van reaches them.
+// A customer is on at most one route.
+type RouteStop struct {
+	RouteID      uuid.UUID `json:"routeId" db:"route_id"`
+	CustomerID   uuid.UUID `json:"customerId" db:"customer_id"`
+	Sequence     int       `json:"sequence" db:"sequence"`
+	CustomerCode string    `json:"customerCode" db:"customer_code"`
+	CustomerName string    `json:"customerName" db:"customer_name"`
+	Phone        *string   `json:"phone,omitempty" db:"phone"`
+	Address      string    `json:"address" db:"address"`
+}
+
+// DeliveryStatus is where an invoice is on its way to the customer
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed" // Shop closed, refused; re-queue for another day
+)
+
+// Delivery is an invoice to be delivered on a route on a given day.
+// The sales side queues it; the driver marks it delivered with what was collected.
+type Delivery struct {
+	ID              uuid.UUID      `json:"id" db:"id"`
+	TenantID        uuid.UUID      `json:"tenantId" db:"tenant_id"`
+	RouteID         uuid.UUID      `json:"routeId" db:"route_id"`
+	CustomerID      uuid.UUID      `json:"customerId" db:"customer_id"`
+	DeliveryDate    time.Time      `json:"deliveryDate" db:"delivery_date"`
+	InvoiceType     *string        `json:"invoiceType,omitempty" db:"invoice_type"`
+	InvoiceID       *uuid.UUID     `json:"invoiceId,omitempty" db:"invoice_id"`
+	InvoiceNumber   string         `json:"invoiceNumber" db:"invoice_number"`
+	InvoiceAmount   float64        `json:"invoiceAmount" db:"invoice_amount"`
+	CollectAmount   float64        `json:"collectAmount" db:"collect_amount"` // Cash due on delivery
+	OnCredit        bool           `json:"onCredit" db:"on_credit"`           // Invoice is on the customer's khata; collections are khata payments
+	Status          DeliveryStatus `json:"status" db:"status"`
+	CollectedAmount float64        `json:"collectedAmount" db:"collected_amount"`
+	ReceivedBy      *string        `json:"receivedBy,omitempty" db:"received_by"` // Who signed for it at the shop
+	Note            *string        `json:"note,omitempty" db:"note"`
+	KhataEntryID    *uuid.UUID     `json:"khataEntryId,omitempty" db:"khata_entry_id"`
+	CompletedBy     *uuid.UUID     `json:"completedBy,omitempty" db:"completed_by"`
+	CompletedAt     *time.Time     `json:"completedAt,omitempty" db:"completed_at"`
+	CreatedAt       time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updatedAt" db:"updated_at"`
+	Lines           []DeliveryLine `json:"lines"`
+}
+
+// DeliveryLine is a product and quantity to hand over; the description is printed on the sheet
+type DeliveryLine struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	DeliveryID  uuid.UUID  `json:"deliveryId" db:"delivery_id"`
+	ProductID   *uuid.UUID `json:"productId,omitempty" db:"product_id"`
+	Description string     `json:"description" db:"description"`
+	Quantity    float64    `json:"quantity" db:"quantity"`
+	Unit        string     `json:"unit" db:"unit"`
+}
+
+// NewDelivery creates a pending delivery; the route is filled in from the customer when queued
+func NewDelivery(tenantID, customerID uuid.UUID, invoiceNumber string, invoiceAmount, collectAmount float64, deliveryDate time.Time) *Delivery {
+	now := time.Now()
+	return &Delivery{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		CustomerID:    customerID,
+		DeliveryDate:  truncateDay(deliveryDate),
+		InvoiceNumber: strings.TrimSpace(invoiceNumber),
+		InvoiceAmount: roundMoney(invoiceAmount),
+		CollectAmount: roundMoney(collectAmount),
+		Status:        DeliveryPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Lines:         []DeliveryLine{},
+	}
+}
+
+// AddLine adds a product to hand over
+func (d *Delivery) AddLine(productID *uuid.UUID, description string, quantity float64, unit string) error {
+	if quantity <= 0 || strings.TrimSpace(description) == "" {
+		return ErrInvalidDelivery
+	}
+	d.Lines = append(d.Lines, DeliveryLine{
+		ID:          uuid.New(),
+		DeliveryID:  d.ID,
+		ProductID:   productID,
+		Description: strings.TrimSpace(description),
+		Quantity:    quantity,
+		Unit:        strings.TrimSpace(unit),
+	})
+	return nil
+}
+
+// Validate checks the invoice reference, amounts and lines
+func (d *Delivery) Validate() error {
+	if d.InvoiceNumber == "" || len(d.Lines) == 0 {
+		return ErrInvalidDelivery
+	}
+	if d.InvoiceAmount < 0 || d.CollectAmount < 0 || d.CollectAmount > d.InvoiceAmount {
+		return ErrInvalidDelivery
+	}
+	return nil
+}
+
+// Deliver marks the invoice handed over and records the cash collected
+func (d *Delivery) Deliver(collected float64, receivedBy, note *string, by *uuid.UUID) error {
+	if d.Status != DeliveryPending {
+		return ErrDeliveryState
+	}
+	if collected < 0 {
+		return ErrInvalidDelivery
+	}
+	now := time.Now()
+	d.Status = DeliveryDelivered
+	d.CollectedAmount = roundMoney(collected)
+	d.ReceivedBy = receivedBy
+	d.Note = note
+	d.CompletedBy = by
+	d.CompletedAt = &now
+	d.UpdatedAt = now
+	return nil
+}
+
+// Fail records that the invoice could not be delivered
+func (d *Delivery) Fail(reason string, by *uuid.UUID) error {
+	if d.Status != DeliveryPending {
+		return ErrDeliveryState
+	}
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("a reason is required")
+	}
+	now := time.Now()
+	reason = strings.TrimSpace(reason)
+	d.Status = DeliveryFailed
+	d.Note = &reason
+	d.CompletedBy = by
+	d.CompletedAt = &now
+	d.UpdatedAt = now
+	return nil
+}
+
+// DeliverySheet is a route's run for one day: the stops in order with their invoices,
+// the load to put on the van and the cash to bring back
+type DeliverySheet struct {
+	Route          *DeliveryRoute `json:"route"`
+	Date           time.Time      `json:"date"`
+	Scheduled      bool           `json:"scheduled"` // The route normally runs on this weekday
+	Stops          []*SheetStop   `json:"stops"`
+	Load           []*LoadItem    `json:"load"`
+	Invoices       int            `json:"invoices"`
+	Delivered      int            `json:"delivered"`
+	Failed         int            `json:"failed"`
+	Pending        int            `json:"pending"`
+	InvoiceTotal   float64        `json:"invoiceTotal"`
+	ToCollect      float64        `json:"toCollect"`
+	CollectedTotal float64        `json:"collectedTotal"`
+}
+
+// SheetStop is a customer on the sheet with the invoices to drop there
+type SheetStop struct {
+	RouteStop
+	Deliveries []*Delivery `json:"deliveries"`
+	ToCollect  float64     `json:"toCollect"`
+}
+
+// LoadItem is the total quantity of a product to load for the day
+type LoadItem struct {
+	ProductID   *uuid.UUID `json:"productId,omitempty"`
+	Description string     `json:"description"`
+	Unit        string     `json:"unit"`
+	Quantity    float64    `json:"quantity"`
+}
+
+// NewDeliverySheet groups a day's deliveries by stop, in route order. Customers who
+// have since left the route are kept at the end so no invoice drops off the sheet.
+func NewDeliverySheet(route *DeliveryRoute, date time.Time, stops []*RouteStop, deliveries []*Delivery) *DeliverySheet {
+	sheet := &DeliverySheet{
+		Route:     route,
+		Date:      truncateDay(date),
+		Scheduled: route.RunsOn(date),
+		Stops:     []*SheetStop{},
+		Load:      []*LoadItem{},
+	}
+
+	byCustomer := map[uuid.UUID]*SheetStop{}
+	for _, stop := range stops {
+		byCustomer[stop.CustomerID] = &SheetStop{RouteStop: *stop, Deliveries: []*Delivery{}}
+	}
+
+	load := map[string]*LoadItem{}
+	for _, delivery := range deliveries {
+		stop, ok := byCustomer[delivery.CustomerID]
+		if !ok {
+			stop = &SheetStop{
+				RouteStop:  RouteStop{RouteID: route.ID, CustomerID: delivery.CustomerID, Sequence: 1 << 30},
+				Deliveries: []*Delivery{},
+			}
+			byCustomer[delivery.CustomerID] = stop
+		}
+		stop.Deliveries = append(stop.Deliveries, delivery)
+		stop.ToCollect = roundMoney(stop.ToCollect + delivery.CollectAmount)
+
+		sheet.Invoices++
+		sheet.InvoiceTotal += delivery.InvoiceAmount
+		sheet.ToCollect += delivery.CollectAmount
+		sheet.CollectedTotal += delivery.CollectedAmount
+		switch delivery.Status {
+		case DeliveryDelivered:
+			sheet.Delivered++
+		case DeliveryFailed:
+			sheet.Failed++
+		default:
+			sheet.Pending++
+		}
+
+		for _, line := range delivery.Lines {
+			key := line.Description + "|" + line.Unit
+			if line.ProductID != nil {
+				key = line.ProductID.String() + "|" + line.Unit
+			}
+			item, ok := load[key]
+			if !ok {
+				item = &LoadItem{ProductID: line.ProductID, Description: line.Description, Unit: line.Unit}
+				load[key] = item
+				sheet.Load = append(sheet.Load, item)
+			}
+			item.Quantity += line.Quantity
+		}
+	}
+
+	for _, stop := range byCustomer {
+		if len(stop.Deliveries) > 0 {
+			sheet.Stops = append(sheet.Stops, stop)
+		}
+	}
+	sort.SliceStable(sheet.Stops, func(i, j int) bool {
+		if sheet.Stops[i].Sequence != sheet.Stops[j].Sequence {
+			return sheet.Stops[i].Sequence < sheet.Stops[j].Sequence
+		}
+		return sheet.Stops[i].CustomerCode < sheet.Stops[j].CustomerCode
+	})
+	sort.SliceStable(sheet.Load, func(i, j int) bool {
+		return sheet.Load[i].Description < sheet.Load[j].Description
+	})
+
+	sheet.InvoiceTotal = roundMoney(sheet.InvoiceTotal)
+	sheet.ToCollect = roundMoney(sheet.ToCollect)
+	sheet.CollectedTotal = roundMoney(sheet.CollectedTotal)
+	return sheet
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrDunningSequenceNotFound is returned when a dunning sequence does not exist for the tenant
+	ErrDunningSequenceNotFound = errors.New("dunning sequence not found")
+	// ErrInvalidDunningSequence is returned when a sequence has no steps or malformed steps
+	ErrInvalidDunningSequence = errors.New("invalid dunning sequence")
+)
+
+// Dunning channels; they match the notification module's channel names
+const (
+	DunningChannelSMS   = "SMS"
+	DunningChannelEmail = "EMAIL"
+)
+
+// DunningSequence is a tenant's schedule of reminders for overdue khata dues,
+// e.g. a gentle SMS at 7 days overdue and a firmer one at 30
+type DunningSequence struct {
+	ID         uuid.UUID     `json:"id" db:"id"`
+	TenantID   uuid.UUID     `json:"tenantId" db:"tenant_id"`
+	Name       string        `json:"name" db:"name"`
+	MinOverdue float64       `json:"minOverdue" db:"min_overdue"` // Customers owing less overdue are not chased
+	IsDefault  bool          `json:"isDefault" db:"is_default"`   // The sequence used by dunning runs
+	IsActive   bool          `json:"isActive" db:"is_active"`
+	Steps      []DunningStep `json:"steps"`
+
+	// Metadata
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// DunningStep is one reminder in a sequence
+type DunningStep struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	SequenceID   uuid.UUID `json:"sequenceId" db:"sequence_id"`
+	Position     int       `json:"position" db:"position"`
+	DaysOverdue  int       `json:"daysOverdue" db:"days_overdue"`   // Sent once the oldest due is this late
+	Channel      string    `json:"channel" db:"channel"`            // SMS or EMAIL
+	TemplateCode string    `json:"templateCode" db:"template_code"` // Notification template rendered for the message
+}
+
+// NewDunningSequence creates an active sequence; steps are numbered in the order given
+func NewDunningSequence(tenantID uuid.UUID, name string, minOverdue float64, steps []DunningStep) *DunningSequence {
+	now := time.Now()
+	sequence := &DunningSequence{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		Name:       name,
+		MinOverdue: minOverdue,
+		IsActive:   true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	sequence.SetSteps(steps)
+	return sequence
+}
+
+// DefaultDunningSteps is the suggested schedule: a gentle reminder at 7 days and a firmer one at 30
+func DefaultDunningSteps() []DunningStep {
+	return []DunningStep{
+		{DaysOverdue: 7, Channel: DunningChannelSMS, TemplateCode: "DUES_REMINDER_GENTLE"},
+		{DaysOverdue: 30, Channel: DunningChannelSMS, TemplateCode: "DUES_REMINDER_FIRM"},
+	}
+}
+
+// SetSteps replaces the steps, numbering them from 1
+func (s *DunningSequence) SetSteps(steps []DunningStep) {
+	s.Steps = make([]DunningStep, len(steps))
+	for i, step := range steps {
+		step.ID = uuid.New()
+		step.SequenceID = s.ID
+		step.Position = i + 1
+		step.Channel = strings.ToUpper(strings.TrimSpace(step.Channel))
+		step.TemplateCode = strings.TrimSpace(step.TemplateCode)
+		s.Steps[i] = step
+	}
+}
+
+// Validate checks the sequence has steps with increasing days overdue
+func (s *DunningSequence) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidDunningSequence)
+	}
+	if s.MinOverdue < 0 {
+		return fmt.Errorf("%w: minimum overdue cannot be negative", ErrInvalidDunningSequence)
+	}
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("%w: at least one step is required", ErrInvalidDunningSequence)
+	}
+
+	previous := 0
+	for _, step := range s.Steps {
+		if step.DaysOverdue <= previous {
+			return fmt.Errorf("%w: step days overdue must be positive and increasing", ErrInvalidDunningSequence)
+		}
+		previous = step.DaysOverdue
+
+		if step.Channel != DunningChannelSMS && step.Channel != DunningChannelEmail {
+			return fmt.Errorf("%w: channel must be SMS or EMAIL", ErrInvalidDunningSequence)
+		}
+		if step.TemplateCode == "" {
+			return fmt.Errorf("%w: template code is required", ErrInvalidDunningSequence)
+		}
+	}
+	return nil
+}
+
+// DueStep returns the latest step reached at daysOverdue that comes after lastPosition.
+// Steps passed over (e.g. a customer first found 40 days overdue) are not sent.
+func (s *DunningSequence) DueStep(daysOverdue, lastPosition int) *DunningStep {
+	var due *DunningStep
+	for i := range s.Steps {
+		if s.Steps[i].Position > lastPosition && s.Steps[i].DaysOverdue <= daysOverdue {
+			due = &s.Steps[i]
+		}
+	}
+	return due
+}
+
+// DunningState tracks how far a customer is through the sequence for their current overdue dues.
+// It is cleared once the overdue dues are paid, so the next overdue period starts from step 1.
+type DunningState struct {
+	TenantID     uuid.UUID `json:"tenantId" db:"tenant_id"`
+	CustomerID   uuid.UUID `json:"customerId" db:"customer_id"`
+	SequenceID   uuid.UUID `json:"sequenceId" db:"sequence_id"`
+	LastPosition int       `json:"lastPosition" db:"last_position"`
+	StartedAt    time.Time `json:"startedAt" db:"started_at"`
+	LastSentAt   time.Time `json:"lastSentAt" db:"last_sent_at"`
+}
+
+// DunningNoticeStatus is the outcome of a reminder
+type DunningNoticeStatus string
+
+const (
+	DunningNoticeSent    DunningNoticeStatus = "sent"
+	DunningNoticeSkipped DunningNoticeStatus = "skipped" // No phone/email on file for the channel
+	DunningNoticeFailed  DunningNoticeStatus = "failed"
+)
+
+// DunningNotice records a reminder sent (or attempted) to a customer
+type DunningNotice struct {
+	ID             uuid.UUID           `json:"id" db:"id"`
+	TenantID       uuid.UUID           `json:"tenantId" db:"tenant_id"`
+	CustomerID     uuid.UUID           `json:"customerId" db:"customer_id"`
+	SequenceID     uuid.UUID           `json:"sequenceId" db:"sequence_id"`
+	StepPosition   int                 `json:"stepPosition" db:"step_position"`
+	Channel        string              `json:"channel" db:"channel"`
+	Recipient      *string             `json:"recipient,omitempty" db:"recipient"`
+	Outstanding    float64             `json:"outstanding" db:"outstanding"`
+	Overdue        float64             `json:"overdue" db:"overdue"`
+	DaysOverdue    int                 `json:"daysOverdue" db:"days_overdue"`
+	Status         DunningNoticeStatus `json:"status" db:"status"`
+	Reason         *string             `json:"reason,omitempty" db:"reason"`
+	NotificationID *uuid.UUID          `json:"notificationId,omitempty" db:"notification_id"`
+	CreatedAt      time.Time           `json:"createdAt" db:"created_at"`
+}
+
+// NewDunningNotice creates a notice for a step sent to a customer with the given dues
+func NewDunningNotice(sequence *DunningSequence, step *DunningStep, dues *CustomerDues) *DunningNotice {
+	return &DunningNotice{
+		ID:           uuid.New(),
+		TenantID:     sequence.TenantID,
+		CustomerID:   dues.CustomerID,
+		SequenceID:   sequence.ID,
+		StepPosition: step.Position,
+		Channel:      step.Channel,
+		Outstanding:  dues.Outstanding,
+		Overdue:      dues.Overdue,
+		DaysOverdue:  dues.DaysOverdue,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// DunningRun summarizes one pass over a tenant's overdue customers
+type DunningRun struct {
+	TenantID         uuid.UUID `json:"tenantId"`
+	CustomersChecked int       `json:"customersChecked"`
+	NoticesSent      int       `json:"noticesSent"`
+	Skipped          int       `json:"skipped"`
+	Failed           int       `json:"failed"`
+	OptedOut         int       `json:"optedOut"`
+	Stopped          int       `json:"stopped"` // Sequences ended because the dues were paid
+	RanAt            time.Time `json:"ranAt"`
+}
@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// maxMIMEDepth bounds nested multipart parts (forwarded mail nests a few levels)
+const maxMIMEDepth = 5
+
+// ErrInvalidEmail is returned when a raw message cannot be parsed
+var ErrInvalidEmail = errors.New("invalid email message")
+
+// ParseRawEmail parses an RFC 5322 message, as SES delivers it, keeping the
+// headers bill capture uses and every attached file
+func ParseRawEmail(raw []byte) (*InboundEmail, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEmail, err)
+	}
+
+	decoder := new(mime.WordDecoder)
+	email := &InboundEmail{
+		MessageID:  strings.Trim(msg.Header.Get("Message-Id"), "<> "),
+		ReceivedAt: time.Now(),
+	}
+	if subject, err := decoder.DecodeHeader(msg.Header.Get("Subject")); err == nil {
+		email.Subject = subject
+	}
+	if from, err := mail.ParseAddress(msg.Header.Get("From")); err == nil {
+		email.From = from.Address
+	}
+	for _, header := range []string{"To", "Cc", "Delivered-To"} {
+		if list, err := msg.Header.AddressList(header); err == nil {
+			for _, address := range list {
+				email.To = append(email.To, address.Address)
+			}
+		}
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		email.ReceivedAt = date
+	}
+
+	if err := collectAttachments(email, msg.Header, msg.Body, 0); err != nil {
+		return nil, err
+	}
+	return email, nil
+}
+
+// partHeader is satisfied by both message and part headers
+type partHeader interface {
+	Get(key string) string
+}
+
+// collectAttachments walks a MIME entity and appends its file parts to the email
+func collectAttachments(email *InboundEmail, header partHeader, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMIMEDepth {
+			return nil
+		}
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidEmail, err)
+			}
+			if err := collectAttachments(email, part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	fileName := attachmentFileName(header, params)
+	if fileName == "" && strings.HasPrefix(mediaType, "text/") {
+		return nil // Message body
+	}
+
+	content, err := io.ReadAll(decodeTransfer(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEmail, err)
+	}
+	if fileName == "" {
+		fileName = "attachment"
+	}
+	email.Attachments = append(email.Attachments, InboundAttachment{
+		FileName:    fileName,
+		ContentType: mediaType,
+		Content:     content,
+	})
+	return nil
+}
+
+// attachmentFileName takes the name from Content-Disposition, falling back to the Content-Type name
+func attachmentFileName(header partHeader, typeParams map[string]string) string {
+	decoder := new(mime.WordDecoder)
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		if name, err := decoder.DecodeHeader(params["filename"]); err == nil {
+			return name
+		}
+		return params["filename"]
+	}
+	if name := typeParams["name"]; name != "" {
+		if decoded, err := decoder.DecodeHeader(name); err == nil {
+			return decoded
+		}
+		return name
+	}
+	return ""
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// newlineStripper drops the line breaks base64 bodies are wrapped with
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (n *newlineStripper) Read(p []byte) (int, error) {
+	for {
+		count, err := n.r.Read(p)
+		kept := 0
+		for _, b := range p[:count] {
+			if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
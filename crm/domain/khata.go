@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidKhataEntry is returned for a zero or negative amount or a due date before the entry date
+var ErrInvalidKhataEntry = errors.New("invalid khata entry")
+
+// KhataEntryKind is whether an entry adds to or settles what a customer owes
+type KhataEntryKind string
+
+const (
+	KhataCharge   KhataEntryKind = "charge"    // Goods given on credit
+	KhataPayment  KhataEntryKind = "payment"   // Money received against dues
+	KhataWriteOff KhataEntryKind = "write_off" // Dues given up as bad debt; see WriteOff
+)
+
+// KhataEntry is a line in a customer's credit ledger (khata)
+type KhataEntry struct {
+	ID            uuid.UUID      `json:"id" db:"id"`
+	TenantID      uuid.UUID      `json:"tenantId" db:"tenant_id"`
+	CustomerID    uuid.UUID      `json:"customerId" db:"customer_id"`
+	Kind          KhataEntryKind `json:"kind" db:"kind"`
+	Amount        float64        `json:"amount" db:"amount"`
+	EntryDate     time.Time      `json:"entryDate" db:"entry_date"`
+	DueDate       time.Time      `json:"dueDate" db:"due_date"` // Charges only; equals EntryDate for payments
+	ReferenceType *string        `json:"referenceType,omitempty" db:"reference_type"`
+	ReferenceID   *uuid.UUID     `json:"referenceId,omitempty" db:"reference_id"`
+	Note          *string        `json:"note,omitempty" db:"note"`
+	CreatedBy     *uuid.UUID     `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt     time.Time      `json:"createdAt" db:"created_at"`
+}
+
+// NewKhataEntry creates a credit ledger entry
+func NewKhataEntry(tenantID, customerID uuid.UUID, kind KhataEntryKind, amount float64, entryDate, dueDate time.Time) *KhataEntry {
+	return &KhataEntry{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		CustomerID: customerID,
+		Kind:       kind,
+		Amount:     roundMoney(amount),
+		EntryDate:  entryDate,
+		DueDate:    dueDate,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// Validate checks the amount and dates
+func (e *KhataEntry) Validate() error {
+	if e.Amount <= 0 {
+		return ErrInvalidKhataEntry
+	}
+	if e.Kind != KhataCharge && e.Kind != KhataPayment && e.Kind != KhataWriteOff {
+		return ErrInvalidKhataEntry
+	}
+	if e.DueDate.Before(truncateDay(e.EntryDate)) {
+		return ErrInvalidKhataEntry
+	}
+	return nil
+}
+
+// DueLater reports whether a charge falls due after the day it was entered, i.e. was given on credit
+func (e *KhataEntry) DueLater() bool {
+	return truncateDay(e.DueDate).After(truncateDay(e.EntryDate))
+}
+
+// SplitBySchedule turns a charge into one charge per installment of its payment
+// terms, so each part ages from its own due date; the first keeps the entry's ID
+func (e *KhataEntry) SplitBySchedule(schedule []DueInstallment) []*KhataEntry {
+	entries := make([]*KhataEntry, len(schedule))
+	for i, installment := range schedule {
+		part := *e
+		if i > 0 {
+			part.ID = uuid.New()
+		}
+		part.Amount = installment.Amount
+		part.DueDate = installment.DueDate
+		entries[i] = &part
+	}
+	return entries
+}
+
+// AgingBuckets splits outstanding dues by how long they are past due
+type AgingBuckets struct {
+	Current    float64 `json:"current"` // Not yet due
+	Days1To30  float64 `json:"days1To30"`
+	Days31To60 float64 `json:"days31To60"`
+	Days61To90 float64 `json:"days61To90"`
+	Over90     float64 `json:"over90"`
+}
+
+// add puts an amount in the bucket for daysOverdue
+func (b *AgingBuckets) add(amount float64, daysOverdue int) {
+	switch {
+	case daysOverdue <= 0:
+		b.Current += amount
+	case daysOverdue <= 30:
+		b.Days1To30 += amount
+	case daysOverdue <= 60:
+		b.Days31To60 += amount
+	case daysOverdue <= 90:
+		b.Days61To90 += amount
+	default:
+		b.Over90 += amount
+	}
+}
+
+func (b *AgingBuckets) round() {
+	b.Current = roundMoney(b.Current)
+	b.Days1To30 = roundMoney(b.Days1To30)
+	b.Days31To60 = roundMoney(b.Days31To60)
+	b.Days61To90 = roundMoney(b.Days61To90)
+	b.Over90 = roundMoney(b.Over90)
+}
+
+// CustomerDues is what a customer owes on khata, aged as of a date
+type CustomerDues struct {
+	CustomerID    uuid.UUID    `json:"customerId" db:"customer_id"`
+	CustomerCode  string       `json:"customerCode" db:"customer_code"`
+	CustomerName  string       `json:"customerName" db:"customer_name"`
+	Outstanding   float64      `json:"outstanding"`
+	Overdue       float64      `json:"overdue"`
+	OldestDueDate *time.Time   `json:"oldestDueDate,omitempty"` // Of the oldest unpaid charge
+	DaysOverdue   int          `json:"daysOverdue"`             // Of the oldest overdue charge
+	Aging         AgingBuckets `json:"aging"`
+	LastPaymentAt *time.Time   `json:"lastPaymentAt,omitempty"`
+	AsOf          time.Time    `json:"asOf"`
+}
+
+// AgeDues settles payments and write-offs against the oldest charges first and ages what is left as of asOf
+func AgeDues(customerID uuid.UUID, entries []*KhataEntry, asOf time.Time) *CustomerDues {
+	dues := &CustomerDues{CustomerID: customerID, AsOf: asOf}
+
+	charges := []*KhataEntry{}
+	paid := 0.0
+	for _, entry := range entries {
+		if entry.Kind != KhataCharge {
+			// Payments and write-offs both settle charges
+			paid += entry.Amount
+			if entry.Kind == KhataPayment && (dues.LastPaymentAt == nil || entry.EntryDate.After(*dues.LastPaymentAt)) {
+				entryDate := entry.EntryDate
+				dues.LastPaymentAt = &entryDate
+			}
+			continue
+		}
+		charges = append(charges, entry)
+	}
+	sort.SliceStable(charges, func(i, j int) bool { return charges[i].DueDate.Before(charges[j].DueDate) })
+
+	today := truncateDay(asOf)
+	for _, charge := range charges {
+		open := charge.Amount
+		if paid > 0 {
+			settled := min(paid, open)
+			paid -= settled
+			open -= settled
+		}
+		if open <= 0.005 {
+			continue
+		}
+
+		if dues.OldestDueDate == nil {
+			dueDate := charge.DueDate
+			dues.OldestDueDate = &dueDate
+		}
+
+		days := int(today.Sub(truncateDay(charge.DueDate)).Hours() / 24)
+		dues.Outstanding += open
+		dues.Aging.add(open, days)
+		if days > 0 {
+			dues.Overdue += open
+			if days > dues.DaysOverdue {
+				dues.DaysOverdue = days
+			}
+		}
+	}
+
+	dues.Outstanding = roundMoney(dues.Outstanding)
+	dues.Overdue = roundMoney(dues.Overdue)
+	dues.Aging.round()
+	return dues
+}
+
+// AgingReport totals dues across customers
+type AgingReport struct {
+	Customers   []*CustomerDues `json:"customers"`
+	Outstanding float64         `json:"outstanding"`
+	Overdue     float64         `json:"overdue"`
+	Aging       AgingBuckets    `json:"aging"`
+	AsOf        time.Time       `json:"asOf"`
+}
+
+// NewAgingReport sums the given customer dues
+func NewAgingReport(customers []*CustomerDues, asOf time.Time) *AgingReport {
+	report := &AgingReport{Customers: customers, AsOf: asOf}
+	for _, dues := range customers {
+		report.Outstanding += dues.Outstanding
+		report.Overdue += dues.Overdue
+		report.Aging.Current += dues.Aging.Current
+		report.Aging.Days1To30 += dues.Aging.Days1To30
+		report.Aging.Days31To60 += dues.Aging.Days31To60
+		report.Aging.Days61To90 += dues.Aging.Days61To90
+		report.Aging.Over90 += dues.Aging.Over90
+	}
+	report.Outstanding = roundMoney(report.Outstanding)
+	report.Overdue = roundMoney(report.Overdue)
+	report.Aging.round()
+	return report
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,314 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aceextension/fiscal/utils"
+)
+
+// paymentTermsKey is the custom attribute holding a customer's or supplier's default terms
+const paymentTermsKey = "payment_terms"
+
+// ErrInvalidPaymentTerms is returned for terms that cannot produce a due date
+var ErrInvalidPaymentTerms = errors.New("invalid payment terms")
+
+// PaymentTermsKind is how a due date is counted
+type PaymentTermsKind string
+
+const (
+	TermsNet          PaymentTermsKind = "net"          // NetDays after the document date; 0 is due on receipt
+	TermsEndOfMonth   PaymentTermsKind = "eom"          // NetDays after the end of the document's month
+	TermsInstallments PaymentTermsKind = "installments" // Parts of the amount, each due some days after the document date
+)
+
+// TermsCalendar is the calendar whose months end-of-month terms count from
+type TermsCalendar string
+
+const (
+	TermsCalendarBS TermsCalendar = "bs" // Bikram Sambat months, as most Nepali businesses settle
+	TermsCalendarAD TermsCalendar = "ad"
+)
+
+// PaymentTerms are when a customer or supplier settles a credit sale or bill
+type PaymentTerms struct {
+	Kind         PaymentTermsKind   `json:"kind"`
+	NetDays      int                `json:"netDays,omitempty"`
+	Calendar     TermsCalendar      `json:"calendar,omitempty"` // End-of-month terms only; BS unless set
+	Installments []TermsInstallment `json:"installments,omitempty"`
+}
+
+// TermsInstallment is one part of installment terms
+type TermsInstallment struct {
+	Percent float64 `json:"percent"` // Share of the amount
+	Days    int     `json:"days"`    // Days after the document date
+}
+
+// DueInstallment is an amount and the date it falls due, stored on invoices
+// and bills so they can be aged
+type DueInstallment struct {
+	Sequence  int       `json:"sequence"`
+	DueDate   time.Time `json:"dueDate"`
+	DueDateBS string    `json:"dueDateBs"`
+	Amount    float64   `json:"amount"`
+}
+
+// DueOnReceipt is the terms used when a customer or supplier has none
+func DueOnReceipt() *PaymentTerms {
+	return &PaymentTerms{Kind: TermsNet}
+}
+
+// NetTerms is due a number of days after the document date
+func NetTerms(days int) *PaymentTerms {
+	return &PaymentTerms{Kind: TermsNet, NetDays: days}
+}
+
+// Validate checks the terms can produce due dates
+func (t *PaymentTerms) Validate() error {
+	if t.NetDays < 0 {
+		return fmt.Errorf("%w: days cannot be negative", ErrInvalidPaymentTerms)
+	}
+	switch t.Kind {
+	case TermsNet:
+	case TermsEndOfMonth:
+		if t.Calendar != "" && t.Calendar != TermsCalendarBS && t.Calendar != TermsCalendarAD {
+			return fmt.Errorf("%w: calendar must be bs or ad", ErrInvalidPaymentTerms)
+		}
+	case TermsInstallments:
+		if len(t.Installments) < 2 {
+			return fmt.Errorf("%w: installments need at least two parts", ErrInvalidPaymentTerms)
+		}
+		total, lastDays := 0.0, -1
+		for _, installment := range t.Installments {
+			if installment.Percent <= 0 {
+				return fmt.Errorf("%w: installment percent must be positive", ErrInvalidPaymentTerms)
+			}
+			if installment.Days <= lastDays {
+				return fmt.Errorf("%w: installments must fall due in order", ErrInvalidPaymentTerms)
+			}
+			total += installment.Percent
+			lastDays = installment.Days
+		}
+		if math.Abs(total-100) > 0.001 {
+			return fmt.Errorf("%w: installment percents must add up to 100", ErrInvalidPaymentTerms)
+		}
+	default:
+		return fmt.Errorf("%w: kind must be net, eom or installments", ErrInvalidPaymentTerms)
+	}
+	return nil
+}
+
+// String describes the terms for documents, e.g. "Net 30"
+func (t *PaymentTerms) String() string {
+	switch t.Kind {
+	case TermsNet:
+		if t.NetDays == 0 {
+			return "Due on receipt"
+		}
+		return fmt.Sprintf("Net %d", t.NetDays)
+	case TermsEndOfMonth:
+		calendar := "BS"
+		if t.Calendar == TermsCalendarAD {
+			calendar = "AD"
+		}
+		if t.NetDays == 0 {
+			return "End of month (" + calendar + ")"
+		}
+		return fmt.Sprintf("%d days after end of month (%s)", t.NetDays, calendar)
+	case TermsInstallments:
+		parts := make([]string, len(t.Installments))
+		for i, installment := range t.Installments {
+			parts[i] = fmt.Sprintf("%s%% in %d days", strconv.FormatFloat(installment.Percent, 'f', -1, 64), installment.Days)
+		}
+		return strings.Join(parts, ", ")
+	}
+	return string(t.Kind)
+}
+
+// legacyTermsPattern matches the free-text terms stored before terms were
+// structured: "30_days", "30 days", "net30", "Net 30", "eom", "eom_15"
+var legacyTermsPattern = regexp.MustCompile(`^(?:(net)[ _-]?(\d+)|(\d+)[ _-]?days?|(eom)(?:[ _+-]?(\d+))?)$`)
+
+// ParsePaymentTerms reads terms written as free text
+func ParsePaymentTerms(value string) (*PaymentTerms, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "", "immediate", "cod", "cash", "due_on_receipt", "due on receipt":
+		return DueOnReceipt(), nil
+	}
+
+	match := legacyTermsPattern.FindStringSubmatch(value)
+	if match == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPaymentTerms, value)
+	}
+	switch {
+	case match[1] != "":
+		days, _ := strconv.Atoi(match[2])
+		return NetTerms(days), nil
+	case match[3] != "":
+		days, _ := strconv.Atoi(match[3])
+		return NetTerms(days), nil
+	default:
+		days := 0
+		if match[5] != "" {
+			days, _ = strconv.Atoi(match[5])
+		}
+		return &PaymentTerms{Kind: TermsEndOfMonth, NetDays: days}, nil
+	}
+}
+
+// decodePaymentTerms reads stored terms: structured (a map once loaded from JSONB) or legacy free text
+func decodePaymentTerms(value interface{}) (*PaymentTerms, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case *PaymentTerms:
+		return v, nil
+	case string:
+		return ParsePaymentTerms(v)
+	case map[string]interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPaymentTerms, err)
+		}
+		terms := &PaymentTerms{}
+		if err := json.Unmarshal(data, terms); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPaymentTerms, err)
+		}
+		return terms, nil
+	}
+	return nil, ErrInvalidPaymentTerms
+}
+
+// normalizePaymentTerms validates the terms attribute and stores it structured.
+// Free text saved before terms were structured that cannot be read is kept while unchanged.
+func normalizePaymentTerms(attrs, previous map[string]interface{}) error {
+	value, isText := attrs[paymentTermsKey].(string)
+	if isText && strings.TrimSpace(value) == "" {
+		delete(attrs, paymentTermsKey)
+		return nil
+	}
+	if old, ok := previous[paymentTermsKey].(string); isText && ok && old == value {
+		if terms, err := ParsePaymentTerms(value); err == nil {
+			attrs[paymentTermsKey] = terms
+		}
+		return nil
+	}
+
+	terms, err := decodePaymentTerms(attrs[paymentTermsKey])
+	if err != nil || terms == nil {
+		return err
+	}
+	if err := terms.Validate(); err != nil {
+		return err
+	}
+	attrs[paymentTermsKey] = terms
+	return nil
+}
+
+// Schedule splits an amount into what falls due when for a document dated date.
+// Counted due dates landing on a weekend or holiday move to the next working day.
+func (t *PaymentTerms) Schedule(date time.Time, amount float64, calendar *utils.WorkingCalendar) []DueInstallment {
+	amount = roundMoney(amount)
+
+	var parts []TermsInstallment
+	switch t.Kind {
+	case TermsInstallments:
+		parts = t.Installments
+	default:
+		parts = []TermsInstallment{{Percent: 100, Days: t.NetDays}}
+	}
+
+	schedule := make([]DueInstallment, len(parts))
+	remaining := amount
+	for i, part := range parts {
+		var due time.Time
+		if t.Kind == TermsEndOfMonth {
+			due = t.monthEnd(date).AddDate(0, 0, part.Days)
+		} else {
+			due = date.AddDate(0, 0, part.Days)
+		}
+		// Due on receipt stays on the document date, even on a closed day
+		if !due.Equal(date) {
+			due = calendar.NextWorkingDay(due)
+		}
+
+		share := remaining
+		if i < len(parts)-1 {
+			share = roundMoney(amount * part.Percent / 100)
+		}
+		remaining = roundMoney(remaining - share)
+
+		schedule[i] = DueInstallment{
+			Sequence:  i + 1,
+			DueDate:   due,
+			DueDateBS: utils.ADToBS(due).String(),
+			Amount:    share,
+		}
+	}
+	return schedule
+}
+
+// monthEnd is the last day of date's month in the terms' calendar
+func (t *PaymentTerms) monthEnd(date time.Time) time.Time {
+	if t.Calendar != TermsCalendarAD {
+		bs := utils.ADToBS(date)
+		if year, err := utils.GetCalendarYear(bs.Year); err == nil {
+			end := year.Months[bs.Month-1].EndAD
+			return time.Date(end.Year(), end.Month(), end.Day(), date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+		}
+	}
+	return time.Date(date.Year(), date.Month()+1, 0, date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+}
+
+// FinalDueDate is when the whole amount of a schedule has fallen due
+func FinalDueDate(schedule []DueInstallment) *time.Time {
+	if len(schedule) == 0 {
+		return nil
+	}
+	due := schedule[len(schedule)-1].DueDate
+	return &due
+}
+
+// NormalizePaymentTerms validates the customer's payment terms, converting free text to
+// structured terms. previous is the stored customer on update.
+func (c *Customer) NormalizePaymentTerms(previous *Customer) error {
+	if c.CustomAttributes == nil {
+		return nil
+	}
+	var attrs map[string]interface{}
+	if previous != nil {
+		attrs = previous.CustomAttributes
+	}
+	return normalizePaymentTerms(c.CustomAttributes, attrs)
+}
+
+// GetPaymentTerms retrieves the customer's default payment terms, or nil if none are set
+func (c *Customer) GetPaymentTerms() *PaymentTerms {
+	terms, _ := decodePaymentTerms(c.CustomAttributes[paymentTermsKey])
+	return terms
+}
+
+// SetPaymentTerms sets the customer's default payment terms
+func (c *Customer) SetPaymentTerms(terms *PaymentTerms) {
+	c.SetCustomAttribute(paymentTermsKey, terms)
+}
+
+// NormalizePaymentTerms validates the supplier's payment terms, converting free text to
+// structured terms. previous is the stored supplier on update.
+func (s *Supplier) NormalizePaymentTerms(previous *Supplier) error {
+	if s.CustomAttributes == nil {
+		return nil
+	}
+	var attrs map[string]interface{}
+	if previous != nil {
+		attrs = previous.CustomAttributes
+	}
+	return normalizePaymentTerms(s.CustomAttributes, attrs)
+}
@@ -0,0 +1,263 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPurchaseReturnNotFound is returned when a purchase return does not exist for the tenant
+	ErrPurchaseReturnNotFound = errors.New("purchase return not found")
+	// ErrInvalidPurchaseReturn is returned for a return with no lines, a bad quantity or an unknown reason
+	ErrInvalidPurchaseReturn = errors.New("invalid purchase return")
+	// ErrReturnExceedsReceipt is returned when more is sent back than was received and not yet returned
+	ErrReturnExceedsReceipt = errors.New("return exceeds what was received")
+	// ErrUnknownReceiptType is returned when no module handles returns against a receipt type
+	ErrUnknownReceiptType = errors.New("unknown purchase receipt type")
+	// ErrPurchaseReceiptNotFound is returned when the receipt being returned against does not exist for the tenant
+	ErrPurchaseReceiptNotFound = errors.New("purchase receipt not found")
+)
+
+// ReceiptTypePurchaseBill is a confirmed supplier bill from bill capture. Bills
+// carry totals but no lines, so returns against them are capped by amount.
+const ReceiptTypePurchaseBill = "purchase_bill"
+
+// ReturnReason is why goods go back to the supplier
+type ReturnReason string
+
+const (
+	ReturnDamaged   ReturnReason = "damaged"    // Arrived broken or spoiled
+	ReturnExpired   ReturnReason = "expired"    // Expired or too close to expiry on arrival
+	ReturnWrongItem ReturnReason = "wrong_item" // Not what was ordered
+	ReturnDefective ReturnReason = "defective"  // Does not work or fails inspection
+	ReturnExcess    ReturnReason = "excess"     // More than was ordered
+	ReturnOther     ReturnReason = "other"
+)
+
+// ReturnReasons lists the reason codes
+var ReturnReasons = []ReturnReason{ReturnDamaged, ReturnExpired, ReturnWrongItem, ReturnDefective, ReturnExcess, ReturnOther}
+
+// Valid reports whether the reason is a known code
+func (r ReturnReason) Valid() bool {
+	for _, reason := range ReturnReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// QualityIssue reports whether the reason counts against the supplier's quality;
+// excess and other returns are commercial, not the goods' fault
+func (r ReturnReason) QualityIssue() bool {
+	return r == ReturnDamaged || r == ReturnExpired || r == ReturnWrongItem || r == ReturnDefective
+}
+
+// ReturnableReceipt is a purchase receipt as the returns see it, loaded from the
+// module that owns it
+type ReturnableReceipt struct {
+	Type       string
+	ID         uuid.UUID
+	SupplierID uuid.UUID
+	Number     string
+	Payable    bool    // Whether the supplier is owed for it, so a return raises a debit note
+	Amount     float64 // Total owed including VAT; caps returns when there are no lines
+	VATRate    float64 // Percent charged on the receipt, applied to returns
+	Lines      []ReturnableLine
+}
+
+// ReturnableLine is a received product that can be sent back
+type ReturnableLine struct {
+	LineID    uuid.UUID
+	ProductID uuid.UUID
+	Quantity  float64
+	UnitCost  float64
+}
+
+// PurchaseReturn is an RMA: goods sent back to a supplier against a receipt. When
+// the receipt is payable the return carries a debit note for its total, reducing
+// what is owed to the supplier.
+type PurchaseReturn struct {
+	ID              uuid.UUID            `json:"id" db:"id"`
+	TenantID        uuid.UUID            `json:"tenantId" db:"tenant_id"`
+	SupplierID      uuid.UUID            `json:"supplierId" db:"supplier_id"`
+	ReturnNumber    string               `json:"returnNumber" db:"return_number"` // RMA-00001
+	ReceiptType     string               `json:"receiptType" db:"receipt_type"`
+	ReceiptID       uuid.UUID            `json:"receiptId" db:"receipt_id"`
+	ReceiptNumber   string               `json:"receiptNumber" db:"receipt_number"`
+	ReturnedAt      time.Time            `json:"returnedAt" db:"returned_at"`
+	Note            *string              `json:"note,omitempty" db:"note"`
+	SubTotal        float64              `json:"subTotal" db:"sub_total"`
+	VATAmount       float64              `json:"vatAmount" db:"vat_amount"`
+	TotalAmount     float64              `json:"totalAmount" db:"total_amount"`
+	DebitNoteNumber *string              `json:"debitNoteNumber,omitempty" db:"debit_note_number"` // DN-00001
+	JournalEntryID  *uuid.UUID           `json:"journalEntryId,omitempty" db:"journal_entry_id"`
+	StockPostedAt   *time.Time           `json:"stockPostedAt,omitempty" db:"stock_posted_at"`
+	CreatedBy       *uuid.UUID           `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt       time.Time            `json:"createdAt" db:"created_at"`
+	Lines           []PurchaseReturnLine `json:"lines"`
+}
+
+// PurchaseReturnLine is a product sent back and why
+type PurchaseReturnLine struct {
+	ID            uuid.UUID    `json:"id" db:"id"`
+	ReturnID      uuid.UUID    `json:"returnId" db:"return_id"`
+	ReceiptLineID *uuid.UUID   `json:"receiptLineId,omitempty" db:"receipt_line_id"`
+	ProductID     uuid.UUID    `json:"productId" db:"product_id"`
+	Quantity      float64      `json:"quantity" db:"quantity"`
+	UnitCost      float64      `json:"unitCost" db:"unit_cost"`
+	Amount        float64      `json:"amount" db:"amount"`
+	Reason        ReturnReason `json:"reason" db:"reason"`
+	Note          *string      `json:"note,omitempty" db:"note"`
+}
+
+// NewPurchaseReturn creates a return against a receipt; the supplier and totals are
+// filled in from the receipt and the numbers when it is saved
+func NewPurchaseReturn(tenantID uuid.UUID, receiptType string, receiptID uuid.UUID, returnedAt time.Time) *PurchaseReturn {
+	return &PurchaseReturn{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		ReceiptType: receiptType,
+		ReceiptID:   receiptID,
+		ReturnedAt:  returnedAt,
+		CreatedAt:   time.Now(),
+		Lines:       []PurchaseReturnLine{},
+	}
+}
+
+// AddLine adds a returned product. Lines against a receipt line may leave the
+// product and cost empty to take them from the receipt.
+func (r *PurchaseReturn) AddLine(receiptLineID *uuid.UUID, productID uuid.UUID, quantity, unitCost float64, reason ReturnReason, note *string) error {
+	if quantity <= 0 || unitCost < 0 {
+		return fmt.Errorf("%w: quantity must be positive and cost not negative", ErrInvalidPurchaseReturn)
+	}
+	if receiptLineID == nil && productID == uuid.Nil {
+		return fmt.Errorf("%w: a line needs a product or a receipt line", ErrInvalidPurchaseReturn)
+	}
+	if !reason.Valid() {
+		return fmt.Errorf("%w: unknown reason %q", ErrInvalidPurchaseReturn, reason)
+	}
+	r.Lines = append(r.Lines, PurchaseReturnLine{
+		ID:            uuid.New(),
+		ReturnID:      r.ID,
+		ReceiptLineID: receiptLineID,
+		ProductID:     productID,
+		Quantity:      quantity,
+		UnitCost:      unitCost,
+		Reason:        reason,
+		Note:          note,
+	})
+	return nil
+}
+
+// Against checks the return fits in what is left of the receipt and prices it.
+// returnedByLine and returnedAmount are what earlier returns already sent back.
+func (r *PurchaseReturn) Against(receipt *ReturnableReceipt, returnedByLine map[uuid.UUID]float64, returnedAmount float64) error {
+	if len(r.Lines) == 0 {
+		return fmt.Errorf("%w: return has no lines", ErrInvalidPurchaseReturn)
+	}
+
+	r.SupplierID = receipt.SupplierID
+	r.ReceiptNumber = receipt.Number
+
+	received := make(map[uuid.UUID]ReturnableLine, len(receipt.Lines))
+	for _, line := range receipt.Lines {
+		received[line.LineID] = line
+	}
+
+	returning := make(map[uuid.UUID]float64)
+	r.SubTotal = 0
+	for i := range r.Lines {
+		line := &r.Lines[i]
+		if len(receipt.Lines) > 0 {
+			if line.ReceiptLineID == nil {
+				return fmt.Errorf("%w: lines must name the receipt line returned", ErrInvalidPurchaseReturn)
+			}
+			receivedLine, ok := received[*line.ReceiptLineID]
+			if !ok {
+				return fmt.Errorf("%w: receipt line %s is not on %s", ErrInvalidPurchaseReturn, *line.ReceiptLineID, receipt.Number)
+			}
+			if line.ProductID != uuid.Nil && line.ProductID != receivedLine.ProductID {
+				return fmt.Errorf("%w: receipt line %s is for another product", ErrInvalidPurchaseReturn, *line.ReceiptLineID)
+			}
+			line.ProductID = receivedLine.ProductID
+			line.UnitCost = receivedLine.UnitCost
+
+			returning[receivedLine.LineID] += line.Quantity
+			left := receivedLine.Quantity - returnedByLine[receivedLine.LineID]
+			if returning[receivedLine.LineID] > left+1e-9 {
+				return fmt.Errorf("%w: %g left to return on line %s", ErrReturnExceedsReceipt, math.Max(left, 0), receivedLine.LineID)
+			}
+		} else if line.ProductID == uuid.Nil {
+			return fmt.Errorf("%w: %s has no lines; give the product returned", ErrInvalidPurchaseReturn, receipt.Number)
+		}
+
+		line.Amount = roundMoney(line.Quantity * line.UnitCost)
+		r.SubTotal += line.Amount
+	}
+
+	r.SubTotal = roundMoney(r.SubTotal)
+	r.VATAmount = roundMoney(r.SubTotal * receipt.VATRate / 100)
+	r.TotalAmount = roundMoney(r.SubTotal + r.VATAmount)
+
+	if len(receipt.Lines) == 0 && receipt.Amount > 0 {
+		if left := roundMoney(receipt.Amount - returnedAmount); r.TotalAmount > left {
+			return fmt.Errorf("%w: %.2f left to return on %s", ErrReturnExceedsReceipt, math.Max(left, 0), receipt.Number)
+		}
+	}
+	return nil
+}
+
+// RaisesDebitNote reports whether the return reduces a payable to the supplier
+func (r *PurchaseReturn) RaisesDebitNote(receipt *ReturnableReceipt) bool {
+	return receipt.Payable && r.TotalAmount > 0
+}
+
+// PurchaseReturnFilter narrows a purchase return listing
+type PurchaseReturnFilter struct {
+	SupplierID *uuid.UUID
+	ReceiptID  *uuid.UUID
+	Reason     *ReturnReason // Returns with at least one line for the reason
+	Limit      int
+	Offset     int
+}
+
+// ReturnReasonStat is what a supplier's goods were returned for over a period
+type ReturnReasonStat struct {
+	SupplierID uuid.UUID    `json:"-"`
+	Reason     ReturnReason `json:"reason"`
+	Returns    int          `json:"returns"` // Returns with a line for the reason
+	Quantity   float64      `json:"quantity"`
+	Amount     float64      `json:"amount"` // Before VAT
+}
+
+// SupplierQuality scores a supplier by what it delivered and what had to go back
+type SupplierQuality struct {
+	SupplierID      uuid.UUID          `json:"supplierId"`
+	SupplierCode    string             `json:"supplierCode"`
+	SupplierName    string             `json:"supplierName"`
+	Purchased       float64            `json:"purchased"`            // Received in the period, before VAT where known
+	Returned        float64            `json:"returned"`             // Returned for any reason, before VAT
+	QualityReturned float64            `json:"qualityReturned"`      // Returned as damaged, expired, wrong or defective
+	ReturnRate      *float64           `json:"returnRate,omitempty"` // Percent of Purchased returned for quality
+	Score           *float64           `json:"score,omitempty"`      // 100 less ReturnRate; unset without purchases
+	Reasons         []ReturnReasonStat `json:"reasons"`
+}
+
+// Rate computes the return rate and score once the totals are in
+func (q *SupplierQuality) Rate() {
+	q.Purchased = roundMoney(q.Purchased)
+	q.Returned = roundMoney(q.Returned)
+	q.QualityReturned = roundMoney(q.QualityReturned)
+	if q.Purchased <= 0 {
+		q.ReturnRate, q.Score = nil, nil
+		return
+	}
+	rate := math.Round(q.QualityReturned/q.Purchased*1000) / 10
+	score := math.Max(0, math.Round((100-rate)*10)/10)
+	q.ReturnRate, q.Score = &rate, &score
+}
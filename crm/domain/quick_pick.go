@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxRecentCustomers is how many recently viewed customers are kept per user
+const MaxRecentCustomers = 50
+
+// CustomerQuickPick represents a customer pinned or recently opened by a POS user
+type CustomerQuickPick struct {
+	Customer     *Customer  `json:"customer"`
+	UserID       uuid.UUID  `json:"userId" db:"user_id"`
+	IsFavorite   bool       `json:"isFavorite" db:"is_favorite"`
+	Position     int        `json:"position" db:"position"`
+	ViewCount    int        `json:"viewCount" db:"view_count"`
+	LastViewedAt *time.Time `json:"lastViewedAt,omitempty" db:"last_viewed_at"`
+}
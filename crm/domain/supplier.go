@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -116,14 +117,15 @@ func (s *Supplier) SetVATNumber(vat string) {
 	s.SetCustomAttribute("vat_number", vat)
 }
 
-// GetPaymentTerms retrieves payment terms
-func (s *Supplier) GetPaymentTerms() string {
-	return s.GetCustomString("payment_terms")
+// GetPaymentTerms retrieves the supplier's default payment terms, or nil if none are set
+func (s *Supplier) GetPaymentTerms() *PaymentTerms {
+	terms, _ := decodePaymentTerms(s.CustomAttributes[paymentTermsKey])
+	return terms
 }
 
-// SetPaymentTerms sets payment terms
-func (s *Supplier) SetPaymentTerms(terms string) {
-	s.SetCustomAttribute("payment_terms", terms)
+// SetPaymentTerms sets the supplier's default payment terms
+func (s *Supplier) SetPaymentTerms(terms *PaymentTerms) {
+	s.SetCustomAttribute(paymentTermsKey, terms)
 }
 
 // GetLeadTimeDays retrieves lead time in days
@@ -156,6 +158,33 @@ func (s *Supplier) SetAddress(address string) {
 	s.SetCustomAttribute("address", address)
 }
 
+// GetStructuredAddress retrieves the structured address, or nil if only a free-form address is set
+func (s *Supplier) GetStructuredAddress() *Address {
+	return decodeAddress(s.CustomAttributes[addressDetailKey])
+}
+
+// SetStructuredAddress sets the structured address and the single-line address derived from it
+func (s *Supplier) SetStructuredAddress(address *Address) {
+	s.SetCustomAttribute(addressDetailKey, encodeAddress(address))
+	s.SetAddress(address.String())
+}
+
+// NormalizeAddress validates the structured address, if one is set, and refreshes the single-line address from it
+func (s *Supplier) NormalizeAddress() error {
+	if _, ok := s.CustomAttributes[addressDetailKey]; !ok {
+		return nil
+	}
+	address := s.GetStructuredAddress()
+	if address == nil {
+		return fmt.Errorf("%w: district is required", ErrInvalidAddress)
+	}
+	if err := address.Normalize(); err != nil {
+		return err
+	}
+	s.SetStructuredAddress(address)
+	return nil
+}
+
 // IsActive checks if supplier is active
 func (s *Supplier) IsActive() bool {
 	return s.Status == SupplierStatusActive
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// SupplyLineSource is a module's ordered lines and what was received against them,
+// e.g. purchase order lines and their goods receipts. LinesSQL selects
+// `supplier_id, product_id, ordered_on, expected_on, received_on, ordered_qty,
+// received_qty, ordered_price, received_price` for tenant $1 with ordered_on in
+// [$2, $3), one row per ordered line. expected_on is null without a promised date;
+// received_on is the last receipt's date and received_price the average received
+// cost, both null until something is received.
+type SupplyLineSource struct {
+	Name     string
+	LinesSQL string
+}
+
+// SupplyStats is a supplier's ordered lines over a period as read from the sources.
+// Due lines have been received or are past their expected date; lines still
+// open and not yet expected count towards neither timing nor fill.
+type SupplyStats struct {
+	SupplierID    uuid.UUID
+	OrderLines    int
+	ReceivedLines int
+	TimedLines    int     // Due lines with an expected date
+	OnTimeLines   int     // Timed lines received in full by their expected date
+	AvgLeadDays   float64 // Order to last receipt, over received lines
+	AvgDelayDays  float64 // Expected date to last receipt, over lines received late
+	OrderedQty    float64 // On due lines
+	FilledQty     float64 // Received on due lines, at most what was ordered per line
+	OrderedValue  float64 // Received quantity at the ordered price
+	ReceivedValue float64 // Received quantity at the received price
+}
+
+// SupplierScorecard rates a supplier on delivery timing, fill rate, price
+// variance against orders and the quality of what was delivered. Rates are
+// percentages and unset when there is nothing to rate.
+type SupplierScorecard struct {
+	Rank             int       `json:"rank,omitempty"` // Position by score in a ranking, best first
+	SupplierID       uuid.UUID `json:"supplierId"`
+	SupplierCode     string    `json:"supplierCode"`
+	SupplierName     string    `json:"supplierName"`
+	OrderLines       int       `json:"orderLines"`
+	ReceivedLines    int       `json:"receivedLines"`
+	OnTimeLines      int       `json:"onTimeLines"`
+	LateLines        int       `json:"lateLines"`              // Timed lines received late, short, or not yet received
+	OnTimeRate       *float64  `json:"onTimeRate,omitempty"`   // Timed lines received in full by the expected date
+	AvgLeadDays      *float64  `json:"avgLeadDays,omitempty"`  // Order to receipt
+	AvgDelayDays     *float64  `json:"avgDelayDays,omitempty"` // Past the expected date, over late receipts
+	OrderedQty       float64   `json:"orderedQty"`
+	FilledQty        float64   `json:"filledQty"`
+	FillRate         *float64  `json:"fillRate,omitempty"` // Share of the ordered quantity received
+	OrderedValue     float64   `json:"orderedValue"`       // What was received, at the ordered prices
+	ReceivedValue    float64   `json:"receivedValue"`      // What was received, at the received prices
+	PriceVariance    float64   `json:"priceVariance"`      // ReceivedValue less OrderedValue; positive is paid over order
+	PriceVariancePct *float64  `json:"priceVariancePct,omitempty"`
+	QualityScore     *float64  `json:"qualityScore,omitempty"` // From purchase returns, across all goods
+	Score            *float64  `json:"score,omitempty"`        // Average of the component scores that are set
+}
+
+// NewSupplierScorecard rates a supplier's stats. qualityScore is the supplier's
+// purchase return score, or nil if it has none.
+func NewSupplierScorecard(stats SupplyStats, qualityScore *float64) *SupplierScorecard {
+	card := &SupplierScorecard{
+		SupplierID:    stats.SupplierID,
+		OrderLines:    stats.OrderLines,
+		ReceivedLines: stats.ReceivedLines,
+		OnTimeLines:   stats.OnTimeLines,
+		LateLines:     stats.TimedLines - stats.OnTimeLines,
+		OrderedQty:    stats.OrderedQty,
+		FilledQty:     stats.FilledQty,
+		OrderedValue:  roundMoney(stats.OrderedValue),
+		ReceivedValue: roundMoney(stats.ReceivedValue),
+		QualityScore:  qualityScore,
+	}
+	card.PriceVariance = roundMoney(card.ReceivedValue - card.OrderedValue)
+
+	if stats.TimedLines > 0 {
+		card.OnTimeRate = percent(float64(stats.OnTimeLines), float64(stats.TimedLines))
+	}
+	if stats.ReceivedLines > 0 {
+		lead := math.Round(stats.AvgLeadDays*10) / 10
+		card.AvgLeadDays = &lead
+	}
+	if stats.TimedLines > stats.OnTimeLines && stats.AvgDelayDays > 0 {
+		delay := math.Round(stats.AvgDelayDays*10) / 10
+		card.AvgDelayDays = &delay
+	}
+	if stats.OrderedQty > 0 {
+		card.FillRate = percent(stats.FilledQty, stats.OrderedQty)
+	}
+	if card.OrderedValue > 0 {
+		card.PriceVariancePct = percent(card.PriceVariance, card.OrderedValue)
+	}
+
+	card.score()
+	return card
+}
+
+// score averages on-time rate, fill rate, price adherence and quality. Price
+// adherence is 100 less the percentage paid over order; paying under order is
+// not penalised.
+func (c *SupplierScorecard) score() {
+	total, parts := 0.0, 0
+	for _, part := range []*float64{c.OnTimeRate, c.FillRate, c.QualityScore} {
+		if part != nil {
+			total += *part
+			parts++
+		}
+	}
+	if c.PriceVariancePct != nil {
+		total += math.Max(0, 100-math.Max(0, *c.PriceVariancePct))
+		parts++
+	}
+	if parts == 0 {
+		c.Score = nil
+		return
+	}
+	score := math.Round(total/float64(parts)*10) / 10
+	c.Score = &score
+}
+
+// percent returns part of whole as a percentage to one decimal
+func percent(part, whole float64) *float64 {
+	rate := math.Round(part/whole*1000) / 10
+	return &rate
+}
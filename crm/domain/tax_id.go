@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/aceextension/crm/taxid"
+)
+
+// panVerificationKey is the custom attribute holding the result of the last IRD check
+const panVerificationKey = "pan_verification"
+
+// PANStatus is the verification badge shown next to a customer's or supplier's PAN
+type PANStatus string
+
+const (
+	PANUnverified PANStatus = "unverified" // Well formed, not yet checked with IRD
+	PANVerified   PANStatus = "verified"   // IRD has a taxpayer under the PAN
+	PANNotFound   PANStatus = "not_found"  // IRD has no taxpayer under the PAN
+)
+
+// PANVerification is the outcome of checking a PAN against the IRD register
+type PANVerification struct {
+	PAN          string    `json:"pan"`
+	Status       PANStatus `json:"status"`
+	TaxpayerName string    `json:"taxpayerName,omitempty"`
+	CheckedAt    time.Time `json:"checkedAt"`
+}
+
+// TaxIDCheck is the result of validating, and optionally looking up, a PAN/VAT number
+type TaxIDCheck struct {
+	PAN      string          `json:"pan"` // Normalized
+	Valid    bool            `json:"valid"`
+	Error    string          `json:"error,omitempty"` // Why the number is invalid, or why the lookup failed
+	Status   PANStatus       `json:"status,omitempty"`
+	Taxpayer *taxid.Taxpayer `json:"taxpayer,omitempty"`
+}
+
+// normalizeTaxIDs validates the PAN and VAT number attributes, storing their
+// normalized form, and keeps the previous IRD check only while the PAN is unchanged
+func normalizeTaxIDs(attrs, previous map[string]interface{}) error {
+	for _, key := range []string{"pan_number", "vat_number"} {
+		value, ok := attrs[key].(string)
+		if !ok || value == "" {
+			continue
+		}
+		normalized, err := taxid.Validate(value)
+		if err != nil {
+			return err
+		}
+		attrs[key] = normalized
+	}
+
+	delete(attrs, panVerificationKey)
+	pan, _ := attrs["pan_number"].(string)
+	if verification := decodePANVerification(previous[panVerificationKey]); verification != nil && pan != "" && verification.PAN == pan {
+		attrs[panVerificationKey] = previous[panVerificationKey]
+	}
+	return nil
+}
+
+// panStatus reports the badge for the PAN attribute; empty without a PAN
+func panStatus(attrs map[string]interface{}) PANStatus {
+	pan, _ := attrs["pan_number"].(string)
+	if pan == "" {
+		return ""
+	}
+	if verification := decodePANVerification(attrs[panVerificationKey]); verification != nil && verification.PAN == pan {
+		return verification.Status
+	}
+	return PANUnverified
+}
+
+// decodePANVerification reads a stored check, which is a map once loaded from JSONB
+func decodePANVerification(value interface{}) *PANVerification {
+	switch v := value.(type) {
+	case *PANVerification:
+		return v
+	case map[string]interface{}:
+		verification := &PANVerification{}
+		verification.PAN, _ = v["pan"].(string)
+		status, _ := v["status"].(string)
+		verification.Status = PANStatus(status)
+		verification.TaxpayerName, _ = v["taxpayerName"].(string)
+		if checkedAt, ok := v["checkedAt"].(string); ok {
+			verification.CheckedAt, _ = time.Parse(time.RFC3339Nano, checkedAt)
+		}
+		return verification
+	}
+	return nil
+}
+
+// NormalizeTaxIDs validates the customer's PAN and VAT number. previous is the
+// stored customer on update, whose IRD check is kept if the PAN did not change.
+func (c *Customer) NormalizeTaxIDs(previous *Customer) error {
+	var attrs map[string]interface{}
+	if previous != nil {
+		attrs = previous.CustomAttributes
+	}
+	if c.CustomAttributes == nil {
+		c.CustomAttributes = make(map[string]interface{})
+	}
+	return normalizeTaxIDs(c.CustomAttributes, attrs)
+}
+
+// GetPANVerification retrieves the last IRD check, or nil if the PAN was never checked
+func (c *Customer) GetPANVerification() *PANVerification {
+	return decodePANVerification(c.CustomAttributes[panVerificationKey])
+}
+
+// SetPANVerification records an IRD check
+func (c *Customer) SetPANVerification(verification *PANVerification) {
+	c.SetCustomAttribute(panVerificationKey, verification)
+}
+
+// PANStatus reports the customer's PAN verification badge; empty without a PAN
+func (c *Customer) PANStatus() PANStatus {
+	return panStatus(c.CustomAttributes)
+}
+
+// NormalizeTaxIDs validates the supplier's PAN and VAT number. previous is the
+// stored supplier on update, whose IRD check is kept if the PAN did not change.
+func (s *Supplier) NormalizeTaxIDs(previous *Supplier) error {
+	var attrs map[string]interface{}
+	if previous != nil {
+		attrs = previous.CustomAttributes
+	}
+	if s.CustomAttributes == nil {
+		s.CustomAttributes = make(map[string]interface{})
+	}
+	return normalizeTaxIDs(s.CustomAttributes, attrs)
+}
+
+// GetPANVerification retrieves the last IRD check, or nil if the PAN was never checked
+func (s *Supplier) GetPANVerification() *PANVerification {
+	return decodePANVerification(s.CustomAttributes[panVerificationKey])
+}
+
+// SetPANVerification records an IRD check
+func (s *Supplier) SetPANVerification(verification *PANVerification) {
+	s.SetCustomAttribute(panVerificationKey, verification)
+}
+
+// PANStatus reports the supplier's PAN verification badge; empty without a PAN
+func (s *Supplier) PANStatus() PANStatus {
+	return panStatus(s.CustomAttributes)
+}
@@ -0,0 +1,324 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrVehicleNotFound is returned when a vehicle does not exist for the tenant
+	ErrVehicleNotFound = errors.New("vehicle not found")
+	// ErrVehicleInactive is returned when starting a trip or booking a cost on a retired vehicle
+	ErrVehicleInactive = errors.New("vehicle is not active")
+	// ErrTripNotFound is returned when a trip does not exist for the tenant
+	ErrTripNotFound = errors.New("trip not found")
+	// ErrTripState is returned when a closed trip is closed again
+	ErrTripState = errors.New("trip is already closed")
+	// ErrInvalidVehicleExpense is returned for an expense with no amount or an unknown kind
+	ErrInvalidVehicleExpense = errors.New("invalid vehicle expense")
+)
+
+// Vehicle is a van, truck or bike used for deliveries
+type Vehicle struct {
+	ID                 uuid.UUID `json:"id" db:"id"`
+	TenantID           uuid.UUID `json:"tenantId" db:"tenant_id"`
+	RegistrationNumber string    `json:"registrationNumber" db:"registration_number"` // e.g. "Ba 2 Pa 4512"
+	Name               string    `json:"name" db:"name"`
+	Kind               string    `json:"kind" db:"kind"` // van, truck, pickup, bike
+	CapacityKg         *float64  `json:"capacityKg,omitempty" db:"capacity_kg"`
+	OdometerKm         float64   `json:"odometerKm" db:"odometer_km"` // Last reading, advanced as trips close
+	IsActive           bool      `json:"isActive" db:"is_active"`
+
+	// Metadata
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// NewVehicle creates an active vehicle
+func NewVehicle(tenantID uuid.UUID, registrationNumber, name, kind string) *Vehicle {
+	now := time.Now()
+	return &Vehicle{
+		ID:                 uuid.New(),
+		TenantID:           tenantID,
+		RegistrationNumber: strings.TrimSpace(registrationNumber),
+		Name:               strings.TrimSpace(name),
+		Kind:               strings.TrimSpace(kind),
+		IsActive:           true,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+}
+
+// Validate checks the registration number and readings
+func (v *Vehicle) Validate() error {
+	if v.RegistrationNumber == "" {
+		return errors.New("registration number is required")
+	}
+	if v.OdometerKm < 0 || (v.CapacityKg != nil && *v.CapacityKg <= 0) {
+		return errors.New("odometer and capacity cannot be negative")
+	}
+	return nil
+}
+
+// TripStatus is whether a trip is still on the road
+type TripStatus string
+
+const (
+	TripOpen   TripStatus = "open"
+	TripClosed TripStatus = "closed"
+)
+
+// Trip is one vehicle running one route's delivery sheet on a day. Its odometer
+// readings give the distance that shared vehicle costs are allocated by.
+type Trip struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	TenantID      uuid.UUID  `json:"tenantId" db:"tenant_id"`
+	VehicleID     uuid.UUID  `json:"vehicleId" db:"vehicle_id"`
+	RouteID       uuid.UUID  `json:"routeId" db:"route_id"`
+	TripDate      time.Time  `json:"tripDate" db:"trip_date"` // The delivery sheet's date
+	DriverID      *uuid.UUID `json:"driverId,omitempty" db:"driver_id"`
+	Status        TripStatus `json:"status" db:"status"`
+	StartOdometer float64    `json:"startOdometer" db:"start_odometer"`
+	EndOdometer   *float64   `json:"endOdometer,omitempty" db:"end_odometer"`
+	Note          *string    `json:"note,omitempty" db:"note"`
+	StartedAt     time.Time  `json:"startedAt" db:"started_at"`
+	EndedAt       *time.Time `json:"endedAt,omitempty" db:"ended_at"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// NewTrip starts a trip of a vehicle on a route's sheet for a day
+func NewTrip(tenantID, vehicleID, routeID uuid.UUID, date time.Time, startOdometer float64, driverID *uuid.UUID) *Trip {
+	now := time.Now()
+	return &Trip{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		VehicleID:     vehicleID,
+		RouteID:       routeID,
+		TripDate:      truncateDay(date),
+		DriverID:      driverID,
+		Status:        TripOpen,
+		StartOdometer: startOdometer,
+		StartedAt:     now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// Close ends the trip at an odometer reading
+func (t *Trip) Close(endOdometer float64, note *string) error {
+	if t.Status != TripOpen {
+		return ErrTripState
+	}
+	if endOdometer < t.StartOdometer {
+		return errors.New("end odometer cannot be below the start reading")
+	}
+	now := time.Now()
+	t.Status = TripClosed
+	t.EndOdometer = &endOdometer
+	if note != nil {
+		t.Note = note
+	}
+	t.EndedAt = &now
+	t.UpdatedAt = now
+	return nil
+}
+
+// DistanceKm returns the distance driven, or 0 while the trip is open
+func (t *Trip) DistanceKm() float64 {
+	if t.EndOdometer == nil {
+		return 0
+	}
+	return *t.EndOdometer - t.StartOdometer
+}
+
+// VehicleExpenseKind is what a vehicle cost was for
+type VehicleExpenseKind string
+
+const (
+	VehicleFuel        VehicleExpenseKind = "fuel"
+	VehicleMaintenance VehicleExpenseKind = "maintenance" // Servicing, repairs, tyres
+	VehicleToll        VehicleExpenseKind = "toll"        // Tolls, parking, loading charges
+	VehicleOther       VehicleExpenseKind = "other"       // Insurance, bluebook renewal, permits
+)
+
+// IsValid reports whether the kind is known
+func (k VehicleExpenseKind) IsValid() bool {
+	switch k {
+	case VehicleFuel, VehicleMaintenance, VehicleToll, VehicleOther:
+		return true
+	}
+	return false
+}
+
+// VehicleExpense is a cost of running a vehicle. Linked to a trip it is charged to
+// that trip's route; otherwise it is shared across the vehicle's trips by distance.
+// It is booked to the ledger as an expense when a journal is wired in.
+type VehicleExpense struct {
+	ID             uuid.UUID          `json:"id" db:"id"`
+	TenantID       uuid.UUID          `json:"tenantId" db:"tenant_id"`
+	VehicleID      uuid.UUID          `json:"vehicleId" db:"vehicle_id"`
+	TripID         *uuid.UUID         `json:"tripId,omitempty" db:"trip_id"`
+	Kind           VehicleExpenseKind `json:"kind" db:"kind"`
+	Amount         float64            `json:"amount" db:"amount"`
+	Litres         *float64           `json:"litres,omitempty" db:"litres"` // Fuel only
+	OdometerKm     *float64           `json:"odometerKm,omitempty" db:"odometer_km"`
+	ExpenseDate    time.Time          `json:"expenseDate" db:"expense_date"`
+	Vendor         *string            `json:"vendor,omitempty" db:"vendor"`
+	BillNumber     *string            `json:"billNumber,omitempty" db:"bill_number"`
+	Note           *string            `json:"note,omitempty" db:"note"`
+	JournalEntryID *uuid.UUID         `json:"journalEntryId,omitempty" db:"journal_entry_id"`
+	CreatedBy      *uuid.UUID         `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt      time.Time          `json:"createdAt" db:"created_at"`
+}
+
+// NewVehicleExpense creates a vehicle expense
+func NewVehicleExpense(tenantID, vehicleID uuid.UUID, kind VehicleExpenseKind, amount float64, date time.Time) *VehicleExpense {
+	return &VehicleExpense{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		VehicleID:   vehicleID,
+		Kind:        kind,
+		Amount:      roundMoney(amount),
+		ExpenseDate: truncateDay(date),
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Validate checks the kind, amount and readings
+func (e *VehicleExpense) Validate() error {
+	if !e.Kind.IsValid() || e.Amount <= 0 {
+		return ErrInvalidVehicleExpense
+	}
+	if e.Litres != nil && (e.Kind != VehicleFuel || *e.Litres <= 0) {
+		return ErrInvalidVehicleExpense
+	}
+	if e.OdometerKm != nil && *e.OdometerKm < 0 {
+		return ErrInvalidVehicleExpense
+	}
+	return nil
+}
+
+// RouteDeliveryTotal is what a route delivered over a period
+type RouteDeliveryTotal struct {
+	RouteID    uuid.UUID `json:"routeId"`
+	Deliveries int       `json:"deliveries"` // Delivered invoices
+	Failed     int       `json:"failed"`
+	Revenue    float64   `json:"revenue"` // Invoice value delivered
+	Collected  float64   `json:"collected"`
+}
+
+// RouteProfitability is a route's delivered value against what it cost to run
+type RouteProfitability struct {
+	RouteID         uuid.UUID `json:"routeId"`
+	RouteCode       string    `json:"routeCode"`
+	RouteName       string    `json:"routeName"`
+	Trips           int       `json:"trips"`
+	DistanceKm      float64   `json:"distanceKm"`
+	Deliveries      int       `json:"deliveries"`
+	Failed          int       `json:"failed"`
+	Revenue         float64   `json:"revenue"`
+	Collected       float64   `json:"collected"`
+	FuelCost        float64   `json:"fuelCost"`
+	MaintenanceCost float64   `json:"maintenanceCost"`
+	OtherCost       float64   `json:"otherCost"` // Tolls and other running costs
+	TotalCost       float64   `json:"totalCost"`
+	CostPercent     float64   `json:"costPercent"` // Logistics cost as a percentage of revenue
+	CostPerDelivery float64   `json:"costPerDelivery"`
+	CostPerKm       float64   `json:"costPerKm"`
+}
+
+// NewRouteProfitability builds per-route profitability for a period. Expenses linked to
+// a trip go to its route; a vehicle's other expenses are shared across its trips in the
+// period by distance, or equally when no distances were recorded. Costs of vehicles
+// with no trips in the period are left out, as no route used them.
+func NewRouteProfitability(routes []*DeliveryRoute, trips []*Trip, expenses []*VehicleExpense, totals []*RouteDeliveryTotal) []*RouteProfitability {
+	byRoute := make(map[uuid.UUID]*RouteProfitability, len(routes))
+	report := make([]*RouteProfitability, 0, len(routes))
+	for _, route := range routes {
+		row := &RouteProfitability{RouteID: route.ID, RouteCode: route.Code, RouteName: route.Name}
+		byRoute[route.ID] = row
+		report = append(report, row)
+	}
+
+	tripByID := make(map[uuid.UUID]*Trip, len(trips))
+	vehicleTrips := make(map[uuid.UUID][]*Trip)
+	for _, trip := range trips {
+		tripByID[trip.ID] = trip
+		vehicleTrips[trip.VehicleID] = append(vehicleTrips[trip.VehicleID], trip)
+		if row, ok := byRoute[trip.RouteID]; ok {
+			row.Trips++
+			row.DistanceKm += trip.DistanceKm()
+		}
+	}
+
+	charge := func(routeID uuid.UUID, kind VehicleExpenseKind, amount float64) {
+		row, ok := byRoute[routeID]
+		if !ok {
+			return
+		}
+		switch kind {
+		case VehicleFuel:
+			row.FuelCost += amount
+		case VehicleMaintenance:
+			row.MaintenanceCost += amount
+		default:
+			row.OtherCost += amount
+		}
+	}
+
+	for _, expense := range expenses {
+		if expense.TripID != nil {
+			if trip, ok := tripByID[*expense.TripID]; ok {
+				charge(trip.RouteID, expense.Kind, expense.Amount)
+				continue
+			}
+		}
+
+		shared := vehicleTrips[expense.VehicleID]
+		if len(shared) == 0 {
+			continue
+		}
+		distance := 0.0
+		for _, trip := range shared {
+			distance += trip.DistanceKm()
+		}
+		for _, trip := range shared {
+			share := 1 / float64(len(shared))
+			if distance > 0 {
+				share = trip.DistanceKm() / distance
+			}
+			charge(trip.RouteID, expense.Kind, expense.Amount*share)
+		}
+	}
+
+	for _, total := range totals {
+		if row, ok := byRoute[total.RouteID]; ok {
+			row.Deliveries = total.Deliveries
+			row.Failed = total.Failed
+			row.Revenue = total.Revenue
+			row.Collected = total.Collected
+		}
+	}
+
+	for _, row := range report {
+		row.FuelCost = roundMoney(row.FuelCost)
+		row.MaintenanceCost = roundMoney(row.MaintenanceCost)
+		row.OtherCost = roundMoney(row.OtherCost)
+		row.TotalCost = roundMoney(row.FuelCost + row.MaintenanceCost + row.OtherCost)
+		if row.Revenue > 0 {
+			row.CostPercent = roundMoney(row.TotalCost / row.Revenue * 100)
+		}
+		if row.Deliveries > 0 {
+			row.CostPerDelivery = roundMoney(row.TotalCost / float64(row.Deliveries))
+		}
+		if row.DistanceKm > 0 {
+			row.CostPerKm = roundMoney(row.TotalCost / row.DistanceKm)
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,332 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrWarrantyNotFound is returned when no warranty exists for the tenant, ID or serial number
+	ErrWarrantyNotFound = errors.New("warranty not found")
+	// ErrWarrantyExists is returned when a serial number already has an active warranty
+	ErrWarrantyExists = errors.New("serial number already has an active warranty")
+	// ErrInvalidWarranty is returned for a warranty with no product, serial number or period
+	ErrInvalidWarranty = errors.New("invalid warranty")
+	// ErrWarrantyNotCovered is returned when claiming on a warranty that has expired, been replaced or voided
+	ErrWarrantyNotCovered = errors.New("item is not under warranty")
+	// ErrWarrantyClaimNotFound is returned when a warranty claim does not exist for the tenant
+	ErrWarrantyClaimNotFound = errors.New("warranty claim not found")
+	// ErrWarrantyClaimOpen is returned when the item already has a claim that is not closed
+	ErrWarrantyClaimOpen = errors.New("item already has an open claim")
+	// ErrWarrantyClaimState is returned when a claim cannot move to the requested status
+	ErrWarrantyClaimState = errors.New("claim cannot move to that status")
+	// ErrInvalidWarrantyClaim is returned for a claim with no issue, or an outcome missing its replacement
+	ErrInvalidWarrantyClaim = errors.New("invalid warranty claim")
+)
+
+// WarrantyStatus is what happened to the unit a warranty covers
+type WarrantyStatus string
+
+const (
+	WarrantyActive   WarrantyStatus = "active"   // Covered until ExpiresAt
+	WarrantyReplaced WarrantyStatus = "replaced" // Unit swapped under a claim; the replacement's warranty carries the cover
+	WarrantyVoid     WarrantyStatus = "void"     // Sale voided or the item returned for a refund
+)
+
+// Warranty is the cover on one serial-numbered unit sold to a customer, registered at
+// sale for the product's warranty_months
+type Warranty struct {
+	ID             uuid.UUID      `json:"id" db:"id"`
+	TenantID       uuid.UUID      `json:"tenantId" db:"tenant_id"`
+	ProductID      uuid.UUID      `json:"productId" db:"product_id"`
+	SerialNumber   string         `json:"serialNumber" db:"serial_number"`
+	CustomerID     *uuid.UUID     `json:"customerId,omitempty" db:"customer_id"` // Empty for walk-in sales
+	InvoiceID      *uuid.UUID     `json:"invoiceId,omitempty" db:"invoice_id"`
+	InvoiceNumber  *string        `json:"invoiceNumber,omitempty" db:"invoice_number"`
+	SoldAt         time.Time      `json:"soldAt" db:"sold_at"`
+	WarrantyMonths int            `json:"warrantyMonths" db:"warranty_months"`
+	ExpiresAt      time.Time      `json:"expiresAt" db:"expires_at"` // Last covered day
+	Status         WarrantyStatus `json:"status" db:"status"`
+	ReplacesID     *uuid.UUID     `json:"replacesId,omitempty" db:"replaces_id"` // Warranty of the unit this one replaced
+	ClaimID        *uuid.UUID     `json:"claimId,omitempty" db:"claim_id"`       // Claim that issued this replacement unit
+	CreatedBy      *uuid.UUID     `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt      time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time      `json:"updatedAt" db:"updated_at"`
+}
+
+// NewWarranty creates an active warranty running months from soldAt
+func NewWarranty(tenantID, productID uuid.UUID, serialNumber string, months int, soldAt time.Time) *Warranty {
+	now := time.Now()
+	return &Warranty{
+		ID:             uuid.New(),
+		TenantID:       tenantID,
+		ProductID:      productID,
+		SerialNumber:   NormalizeSerialNumber(serialNumber),
+		SoldAt:         soldAt,
+		WarrantyMonths: months,
+		ExpiresAt:      truncateDay(soldAt).AddDate(0, months, -1),
+		Status:         WarrantyActive,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// WarrantySale is a sale's serial-numbered items to put under warranty
+type WarrantySale struct {
+	TenantID      uuid.UUID          `json:"tenantId"`
+	CustomerID    *uuid.UUID         `json:"customerId,omitempty"`
+	InvoiceID     *uuid.UUID         `json:"invoiceId,omitempty"`
+	InvoiceNumber *string            `json:"invoiceNumber,omitempty"`
+	SoldAt        time.Time          `json:"soldAt"`
+	Items         []WarrantySaleItem `json:"items"`
+	CreatedBy     *uuid.UUID         `json:"createdBy,omitempty"`
+}
+
+// WarrantySaleItem is one unit sold
+type WarrantySaleItem struct {
+	ProductID      uuid.UUID `json:"productId"`
+	SerialNumber   string    `json:"serialNumber"`
+	WarrantyMonths int       `json:"warrantyMonths,omitempty"` // 0 takes the product's warranty_months
+}
+
+// NormalizeSerialNumber trims and upper-cases a serial number as printed or scanned
+func NormalizeSerialNumber(serialNumber string) string {
+	return strings.ToUpper(strings.TrimSpace(serialNumber))
+}
+
+// Validate checks the warranty can be saved
+func (w *Warranty) Validate() error {
+	if w.ProductID == uuid.Nil || w.SerialNumber == "" || w.WarrantyMonths <= 0 {
+		return ErrInvalidWarranty
+	}
+	return nil
+}
+
+// Covers reports whether the unit is under warranty on at's day
+func (w *Warranty) Covers(at time.Time) bool {
+	return w.Status == WarrantyActive && !truncateDay(at).After(truncateDay(w.ExpiresAt))
+}
+
+// DaysLeft is how many days of cover remain after at's day; 0 once expired
+func (w *Warranty) DaysLeft(at time.Time) int {
+	if !w.Covers(at) {
+		return 0
+	}
+	return int(truncateDay(w.ExpiresAt).Sub(truncateDay(at)).Hours() / 24)
+}
+
+// Coverage describes the warranty on at's day: active, expired, replaced or void
+func (w *Warranty) Coverage(at time.Time) string {
+	if w.Status == WarrantyActive && !w.Covers(at) {
+		return "expired"
+	}
+	return string(w.Status)
+}
+
+// WarrantyCheck is the answer to a serial number lookup at the service counter
+type WarrantyCheck struct {
+	Warranty  *Warranty      `json:"warranty"`
+	Coverage  string         `json:"coverage"` // active, expired, replaced or void
+	Covered   bool           `json:"covered"`
+	DaysLeft  int            `json:"daysLeft"`
+	OpenClaim *WarrantyClaim `json:"openClaim,omitempty"`
+}
+
+// WarrantyClaimType is why the item was brought back
+type WarrantyClaimType string
+
+const (
+	ClaimWarranty WarrantyClaimType = "warranty" // Fault under the manufacturer's or shop's cover; needs an active warranty
+	ClaimExchange WarrantyClaimType = "exchange" // Exchange the shop agrees to, e.g. a wrong model; cover is not checked
+)
+
+// WarrantyClaimStatus is where a claim is in its workflow:
+// received → in_service → resolved → closed, or cancelled before it is resolved
+type WarrantyClaimStatus string
+
+const (
+	ClaimReceived  WarrantyClaimStatus = "received"   // Item taken in from the customer
+	ClaimInService WarrantyClaimStatus = "in_service" // Sent to the brand's service centre
+	ClaimResolved  WarrantyClaimStatus = "resolved"   // Outcome decided; waiting for the customer
+	ClaimClosed    WarrantyClaimStatus = "closed"     // Item handed back to the customer
+	ClaimCancelled WarrantyClaimStatus = "cancelled"  // Withdrawn before it was resolved
+)
+
+// WarrantyClaimOutcome is how a claim was settled
+type WarrantyClaimOutcome string
+
+const (
+	OutcomeRepaired  WarrantyClaimOutcome = "repaired"  // Same unit returned; the warranty carries on
+	OutcomeReplaced  WarrantyClaimOutcome = "replaced"  // New unit of the same product for the rest of the cover
+	OutcomeExchanged WarrantyClaimOutcome = "exchanged" // Different product, with that product's own warranty
+	OutcomeRejected  WarrantyClaimOutcome = "rejected"  // Not covered, e.g. physical or water damage
+)
+
+// WarrantyClaim is a ticket for a sold unit brought back for repair, replacement or exchange
+type WarrantyClaim struct {
+	ID            uuid.UUID           `json:"id" db:"id"`
+	TenantID      uuid.UUID           `json:"tenantId" db:"tenant_id"`
+	ClaimNumber   string              `json:"claimNumber" db:"claim_number"`
+	WarrantyID    uuid.UUID           `json:"warrantyId" db:"warranty_id"`
+	ProductID     uuid.UUID           `json:"productId" db:"product_id"`
+	SerialNumber  string              `json:"serialNumber" db:"serial_number"`
+	CustomerID    *uuid.UUID          `json:"customerId,omitempty" db:"customer_id"`
+	Type          WarrantyClaimType   `json:"type" db:"type"`
+	Issue         string              `json:"issue" db:"issue"` // Fault as described by the customer
+	Status        WarrantyClaimStatus `json:"status" db:"status"`
+	ServiceCenter *string             `json:"serviceCenter,omitempty" db:"service_center"`
+	SentAt        *time.Time          `json:"sentAt,omitempty" db:"sent_at"`
+
+	// Set on resolution
+	Outcome               *WarrantyClaimOutcome `json:"outcome,omitempty" db:"outcome"`
+	ReplacementProductID  *uuid.UUID            `json:"replacementProductId,omitempty" db:"replacement_product_id"`
+	ReplacementSerial     *string               `json:"replacementSerial,omitempty" db:"replacement_serial"`
+	ReplacementWarrantyID *uuid.UUID            `json:"replacementWarrantyId,omitempty" db:"replacement_warranty_id"`
+	ResolutionNote        *string               `json:"resolutionNote,omitempty" db:"resolution_note"`
+	ResolvedBy            *uuid.UUID            `json:"resolvedBy,omitempty" db:"resolved_by"`
+	ResolvedAt            *time.Time            `json:"resolvedAt,omitempty" db:"resolved_at"`
+	ClosedAt              *time.Time            `json:"closedAt,omitempty" db:"closed_at"`
+
+	// Metadata
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// NewWarrantyClaim opens a claim on a warranty's unit; the number is assigned when it is saved
+func NewWarrantyClaim(warranty *Warranty, claimType WarrantyClaimType, issue string, createdBy *uuid.UUID) *WarrantyClaim {
+	now := time.Now()
+	return &WarrantyClaim{
+		ID:           uuid.New(),
+		TenantID:     warranty.TenantID,
+		WarrantyID:   warranty.ID,
+		ProductID:    warranty.ProductID,
+		SerialNumber: warranty.SerialNumber,
+		CustomerID:   warranty.CustomerID,
+		Type:         claimType,
+		Issue:        strings.TrimSpace(issue),
+		Status:       ClaimReceived,
+		CreatedBy:    createdBy,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// Validate checks a new claim
+func (c *WarrantyClaim) Validate() error {
+	if c.Type != ClaimWarranty && c.Type != ClaimExchange {
+		return ErrInvalidWarrantyClaim
+	}
+	if c.Issue == "" {
+		return ErrInvalidWarrantyClaim
+	}
+	return nil
+}
+
+// IsOpen reports whether the claim still holds the item
+func (c *WarrantyClaim) IsOpen() bool {
+	return c.Status == ClaimReceived || c.Status == ClaimInService || c.Status == ClaimResolved
+}
+
+// SendToService records the item going to a service centre
+func (c *WarrantyClaim) SendToService(serviceCenter string) error {
+	if c.Status != ClaimReceived {
+		return ErrWarrantyClaimState
+	}
+	now := time.Now()
+	center := strings.TrimSpace(serviceCenter)
+	if center != "" {
+		c.ServiceCenter = &center
+	}
+	c.SentAt = &now
+	c.Status = ClaimInService
+	c.UpdatedAt = now
+	return nil
+}
+
+// Resolve records the outcome. Replacements and exchanges need the unit given to the
+// customer; exchanges also need its product.
+func (c *WarrantyClaim) Resolve(outcome WarrantyClaimOutcome, replacementProductID *uuid.UUID, replacementSerial, note string, resolvedBy *uuid.UUID) error {
+	if c.Status != ClaimReceived && c.Status != ClaimInService {
+		return ErrWarrantyClaimState
+	}
+
+	replacementSerial = NormalizeSerialNumber(replacementSerial)
+	switch outcome {
+	case OutcomeRepaired, OutcomeRejected:
+		replacementProductID, replacementSerial = nil, ""
+	case OutcomeReplaced:
+		if replacementSerial == "" || replacementSerial == c.SerialNumber {
+			return ErrInvalidWarrantyClaim
+		}
+		replacementProductID = &c.ProductID
+	case OutcomeExchanged:
+		if replacementSerial == "" || replacementProductID == nil || *replacementProductID == uuid.Nil {
+			return ErrInvalidWarrantyClaim
+		}
+	default:
+		return ErrInvalidWarrantyClaim
+	}
+
+	now := time.Now()
+	c.Outcome = &outcome
+	c.ReplacementProductID = replacementProductID
+	if replacementSerial != "" {
+		c.ReplacementSerial = &replacementSerial
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		c.ResolutionNote = &note
+	}
+	c.ResolvedBy = resolvedBy
+	c.ResolvedAt = &now
+	c.Status = ClaimResolved
+	c.UpdatedAt = now
+	return nil
+}
+
+// HandsOverUnit reports whether the outcome gives the customer a different unit
+func (c *WarrantyClaim) HandsOverUnit() bool {
+	return c.Outcome != nil && (*c.Outcome == OutcomeReplaced || *c.Outcome == OutcomeExchanged)
+}
+
+// Close records the item handed back to the customer
+func (c *WarrantyClaim) Close() error {
+	if c.Status != ClaimResolved {
+		return ErrWarrantyClaimState
+	}
+	now := time.Now()
+	c.ClosedAt = &now
+	c.Status = ClaimClosed
+	c.UpdatedAt = now
+	return nil
+}
+
+// Cancel withdraws a claim that has not been resolved
+func (c *WarrantyClaim) Cancel() error {
+	if c.Status != ClaimReceived && c.Status != ClaimInService {
+		return ErrWarrantyClaimState
+	}
+	c.Status = ClaimCancelled
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// ReplacementWarranty is the cover on the unit a claim handed over. A replacement keeps
+// the original expiry; an exchanged product gets months of its own from the resolution.
+func (c *WarrantyClaim) ReplacementWarranty(original *Warranty, months int) *Warranty {
+	warranty := NewWarranty(c.TenantID, *c.ReplacementProductID, *c.ReplacementSerial, months, *c.ResolvedAt)
+	if *c.Outcome == OutcomeReplaced {
+		warranty.WarrantyMonths = original.WarrantyMonths
+		warranty.ExpiresAt = original.ExpiresAt
+	}
+	warranty.CustomerID = original.CustomerID
+	warranty.InvoiceID = original.InvoiceID
+	warranty.InvoiceNumber = original.InvoiceNumber
+	warranty.ReplacesID = &original.ID
+	warranty.ClaimID = &c.ID
+	warranty.CreatedBy = c.ResolvedBy
+	return warranty
+}
@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrWriteOffNotFound is returned when a write-off does not exist for the tenant
+	ErrWriteOffNotFound = errors.New("write-off not found")
+	// ErrWriteOffState is returned when a write-off has already been decided or cancelled
+	ErrWriteOffState = errors.New("write-off is no longer pending")
+	// ErrWriteOffNotPermitted is returned when the caller's role may not approve or reject write-offs
+	ErrWriteOffNotPermitted = errors.New("your role may not approve write-offs")
+	// ErrWriteOffSelfApproval is returned when the requester tries to approve their own write-off
+	ErrWriteOffSelfApproval = errors.New("a write-off must be approved by someone other than the requester")
+	// ErrWriteOffExceedsDues is returned when more is written off than the customer owes
+	ErrWriteOffExceedsDues = errors.New("write-off amount exceeds the customer's outstanding dues")
+)
+
+// WriteOffApproverRoles may approve or reject bad-debt write-offs
+var WriteOffApproverRoles = []string{"owner", "admin"}
+
+// CanApproveWriteOff reports whether a role may approve or reject write-offs
+func CanApproveWriteOff(role string) bool {
+	for _, approver := range WriteOffApproverRoles {
+		if role == approver {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteOffStatus represents where a write-off is in its approval
+type WriteOffStatus string
+
+const (
+	WriteOffPending   WriteOffStatus = "pending"
+	WriteOffApproved  WriteOffStatus = "approved" // Dues closed and booked to bad-debt expense
+	WriteOffRejected  WriteOffStatus = "rejected"
+	WriteOffCancelled WriteOffStatus = "cancelled" // Withdrawn by the requester
+)
+
+// WriteOff is a request to give up a customer's unrecoverable dues. Once approved,
+// a write_off khata entry settles the oldest open charges and the amount is booked
+// against the bad-debt expense account in the fiscal year it was approved in.
+type WriteOff struct {
+	ID             uuid.UUID      `json:"id" db:"id"`
+	TenantID       uuid.UUID      `json:"tenantId" db:"tenant_id"`
+	CustomerID     uuid.UUID      `json:"customerId" db:"customer_id"`
+	WriteOffNumber string         `json:"writeOffNumber" db:"write_off_number"`
+	Amount         float64        `json:"amount" db:"amount"`
+	Outstanding    float64        `json:"outstanding" db:"outstanding"` // Customer's dues when requested
+	Reason         string         `json:"reason" db:"reason"`
+	Status         WriteOffStatus `json:"status" db:"status"`
+
+	// Approval
+	RequestedBy  *uuid.UUID `json:"requestedBy,omitempty" db:"requested_by"`
+	ReviewedBy   *uuid.UUID `json:"reviewedBy,omitempty" db:"reviewed_by"`
+	ReviewerRole *string    `json:"reviewerRole,omitempty" db:"reviewer_role"`
+	ReviewNote   *string    `json:"reviewNote,omitempty" db:"review_note"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty" db:"reviewed_at"`
+
+	// Set on approval
+	FiscalYearID   *uuid.UUID `json:"fiscalYearId,omitempty" db:"fiscal_year_id"`
+	FiscalYearName *string    `json:"fiscalYearName,omitempty" db:"fiscal_year_name"`
+	KhataEntryID   *uuid.UUID `json:"khataEntryId,omitempty" db:"khata_entry_id"`
+	JournalEntryID *uuid.UUID `json:"journalEntryId,omitempty" db:"journal_entry_id"`
+
+	// Metadata
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// NewWriteOff creates a pending write-off; the number is assigned when it is saved
+func NewWriteOff(tenantID, customerID uuid.UUID, amount float64, reason string, requestedBy *uuid.UUID) *WriteOff {
+	now := time.Now()
+	return &WriteOff{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		CustomerID:  customerID,
+		Amount:      roundMoney(amount),
+		Reason:      strings.TrimSpace(reason),
+		Status:      WriteOffPending,
+		RequestedBy: requestedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Approve marks the write-off approved by reviewerID acting as role
+func (w *WriteOff) Approve(reviewerID uuid.UUID, role string, note *string) error {
+	if err := w.review(reviewerID, role, note); err != nil {
+		return err
+	}
+	if w.RequestedBy != nil && *w.RequestedBy == reviewerID {
+		return ErrWriteOffSelfApproval
+	}
+	w.Status = WriteOffApproved
+	return nil
+}
+
+// Reject declines the write-off; the dues stay open
+func (w *WriteOff) Reject(reviewerID uuid.UUID, role string, note *string) error {
+	if err := w.review(reviewerID, role, note); err != nil {
+		return err
+	}
+	w.Status = WriteOffRejected
+	return nil
+}
+
+// Cancel withdraws a pending write-off
+func (w *WriteOff) Cancel() error {
+	if w.Status != WriteOffPending {
+		return ErrWriteOffState
+	}
+	w.Status = WriteOffCancelled
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+func (w *WriteOff) review(reviewerID uuid.UUID, role string, note *string) error {
+	if w.Status != WriteOffPending {
+		return ErrWriteOffState
+	}
+	if !CanApproveWriteOff(role) {
+		return ErrWriteOffNotPermitted
+	}
+	now := time.Now()
+	w.ReviewedBy = &reviewerID
+	w.ReviewerRole = &role
+	w.ReviewNote = note
+	w.ReviewedAt = &now
+	w.UpdatedAt = now
+	return nil
+}
+
+// KhataEntry returns the ledger entry that closes the written-off dues
+func (w *WriteOff) KhataEntry() *KhataEntry {
+	entry := NewKhataEntry(w.TenantID, w.CustomerID, KhataWriteOff, w.Amount, *w.ReviewedAt, *w.ReviewedAt)
+	referenceType := "WRITE_OFF"
+	entry.ReferenceType = &referenceType
+	entry.ReferenceID = &w.ID
+	entry.Note = &w.Reason
+	entry.CreatedBy = w.ReviewedBy
+	return entry
+}
+
+// WriteOffYearTotal is the bad debt written off in one fiscal year
+type WriteOffYearTotal struct {
+	FiscalYearID   uuid.UUID `json:"fiscalYearId" db:"fiscal_year_id"`
+	FiscalYearName string    `json:"fiscalYearName" db:"fiscal_year_name"`
+	Count          int       `json:"count" db:"count"`
+	Amount         float64   `json:"amount" db:"amount"`
+	Customers      int       `json:"customers" db:"customers"`
+}
@@ -49,7 +49,7 @@ func main() {
 	customer.SetCreditLimit(100000.00)
 	customer.SetAddress("Thamel, Kathmandu, Nepal")
 	customer.SetCustomAttribute("loyalty_tier", "gold")
-	customer.SetCustomAttribute("payment_terms", "30_days")
+	customer.SetPaymentTerms(domain.NetTerms(30))
 
 	if err := crm.CustomerService.Create(ctx, customer); err != nil {
 		log.Fatalf("Failed to create customer: %v", err)
@@ -71,7 +71,7 @@ func main() {
 
 	// Add custom attributes
 	supplier.SetPANNumber("987654321")
-	supplier.SetPaymentTerms("15_days")
+	supplier.SetPaymentTerms(domain.NetTerms(15))
 	supplier.SetLeadTimeDays(7)
 	supplier.SetMinimumOrderValue(5000.00)
 	supplier.SetAddress("Patan, Lalitpur, Nepal")
@@ -142,13 +142,13 @@ func main() {
 
 	// Example 7: List All Customers
 	fmt.Println("7. Listing All Customers...")
-	allCustomers, err := crm.CustomerService.GetByTenantID(ctx, tenantID, 10, 0)
+	allCustomers, err := crm.CustomerService.List(ctx, tenantID, nil, db.PageRequest{Limit: 10})
 	if err != nil {
 		log.Fatalf("Failed to list customers: %v", err)
 	}
 
 	fmt.Printf("✓ Customers for tenant:\n")
-	for _, c := range allCustomers {
+	for _, c := range allCustomers.Items {
 		fmt.Printf("  - %s (%s) - %s\n", c.Name, c.CustomerCode, c.Status)
 	}
 
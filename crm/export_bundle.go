@@ -0,0 +1,81 @@
+package crm
+
+import (
+	"context"
+
+	accountingDomain "github.com/aceextension/accounting/domain"
+	accountingService "github.com/aceextension/accounting/service"
+)
+
+// registerBundleSections adds the receivables aging and purchase VAT register to
+// the accountant's year-end export bundle
+func registerBundleSections(bundles accountingService.ExportBundleService) {
+	bundles.RegisterSection(accountingService.BundleSection{
+		Name:  "ar-aging",
+		Title: "Receivables Aging",
+		PDF:   true,
+		Write: writeReceivablesAging,
+	})
+	bundles.RegisterSection(accountingService.BundleSection{
+		Name:  "purchase-register",
+		Title: "Purchase VAT Register",
+		PDF:   true,
+		Write: writePurchaseRegister,
+	})
+}
+
+// writeReceivablesAging ages customer dues as of the fiscal year end
+func writeReceivablesAging(ctx context.Context, period accountingDomain.BundlePeriod, sink accountingService.ReportSink) error {
+	report, err := KhataService.AgingReport(ctx, period.TenantID, period.End)
+	if err != nil {
+		return err
+	}
+
+	if err := sink.Table("Receivables Aging", []accountingDomain.ReportColumn{
+		{Header: "Code"}, {Header: "Customer"},
+		{Header: "Outstanding", Amount: true}, {Header: "Current", Amount: true},
+		{Header: "1-30", Amount: true}, {Header: "31-60", Amount: true},
+		{Header: "61-90", Amount: true}, {Header: "Over 90", Amount: true},
+		{Header: "Oldest Due"},
+	}); err != nil {
+		return err
+	}
+
+	for _, dues := range report.Customers {
+		if err := sink.Row(dues.CustomerCode, dues.CustomerName, dues.Outstanding,
+			dues.Aging.Current, dues.Aging.Days1To30, dues.Aging.Days31To60,
+			dues.Aging.Days61To90, dues.Aging.Over90, dues.OldestDueDate); err != nil {
+			return err
+		}
+	}
+	return sink.Row(nil, "Total", report.Outstanding,
+		report.Aging.Current, report.Aging.Days1To30, report.Aging.Days31To60,
+		report.Aging.Days61To90, report.Aging.Over90, nil)
+}
+
+// writePurchaseRegister lists the confirmed purchase bills dated in the fiscal year
+func writePurchaseRegister(ctx context.Context, period accountingDomain.BundlePeriod, sink accountingService.ReportSink) error {
+	lines, err := BillCaptureService.PurchaseRegister(ctx, period.TenantID, period.Start, period.End)
+	if err != nil {
+		return err
+	}
+
+	if err := sink.Table("Purchase VAT Register", []accountingDomain.ReportColumn{
+		{Header: "Date"}, {Header: "Bill No."}, {Header: "Supplier"}, {Header: "PAN"},
+		{Header: "Taxable", Amount: true}, {Header: "VAT", Amount: true}, {Header: "Total", Amount: true},
+	}); err != nil {
+		return err
+	}
+
+	var taxable, vat, total float64
+	for _, line := range lines {
+		if err := sink.Row(line.BillDate, line.BillNumber, line.SupplierName, line.SupplierPAN,
+			line.Taxable, line.VATAmount, line.TotalAmount); err != nil {
+			return err
+		}
+		taxable += line.Taxable
+		vat += line.VATAmount
+		total += line.TotalAmount
+	}
+	return sink.Row(nil, nil, "Total", nil, taxable, vat, total)
+}
@@ -3,9 +3,18 @@ module github.com/aceextension/crm
 go 1.24.0
 
 require (
+	github.com/aceextension/accounting v0.0.0
 	github.com/aceextension/audit v0.0.0
+	github.com/aceextension/automation v0.0.0
+	github.com/aceextension/catalog v0.0.0
+	github.com/aceextension/comments v0.0.0
 	github.com/aceextension/core v0.0.0
+	github.com/aceextension/files v0.0.0
 	github.com/aceextension/fiscal v0.0.0
+	github.com/aceextension/notification v0.0.0
+	github.com/aceextension/onboarding v0.0.0
+	github.com/aceextension/payments v0.0.0
+	github.com/aceextension/tags v0.0.0
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v5 v5.8.0
 	github.com/labstack/echo/v4 v4.15.0
@@ -30,6 +39,8 @@ require (
 	github.com/subosito/gotenv v1.6.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.2 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	go.uber.org/zap v1.27.1 // indirect
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
 	golang.org/x/crypto v0.47.0 // indirect
 	golang.org/x/net v0.48.0 // indirect
@@ -39,7 +50,17 @@ require (
 )
 
 replace (
+	github.com/aceextension/accounting => ../accounting
 	github.com/aceextension/audit => ../audit
+	github.com/aceextension/automation => ../automation
+	github.com/aceextension/catalog => ../catalog
+	github.com/aceextension/comments => ../comments
 	github.com/aceextension/core => ../core
+	github.com/aceextension/files => ../files
 	github.com/aceextension/fiscal => ../fiscal
+	github.com/aceextension/identity => ../identity
+	github.com/aceextension/notification => ../notification
+	github.com/aceextension/onboarding => ../onboarding
+	github.com/aceextension/payments => ../payments
+	github.com/aceextension/tags => ../tags
 )
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// AddressHandler handles HTTP requests for address reference data
+type AddressHandler struct{}
+
+// NewAddressHandler creates a new address handler
+func NewAddressHandler() *AddressHandler {
+	return &AddressHandler{}
+}
+
+// Divisions godoc
+// @Summary List Nepal administrative divisions
+// @Description The seven provinces and their districts, for address forms and area filters
+// @Tags addresses
+// @Produce json
+// @Success 200 {array} domain.Province
+// @Router /api/v1/addresses/divisions [get]
+// @Security BearerAuth
+func (h *AddressHandler) Divisions(c echo.Context) error {
+	return c.JSON(http.StatusOK, domain.NepalProvinces)
+}
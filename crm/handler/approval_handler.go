@@ -0,0 +1,372 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ApprovalHandler handles HTTP requests for purchase and payment approval limits
+type ApprovalHandler struct{}
+
+// NewApprovalHandler creates a new approval handler
+func NewApprovalHandler() *ApprovalHandler {
+	return &ApprovalHandler{}
+}
+
+// ApprovalLimitRequest represents a limit for a role or a single user
+type ApprovalLimitRequest struct {
+	DocumentType string     `json:"documentType" validate:"required,oneof=purchase_order purchase_bill supplier_payment"`
+	Role         *string    `json:"role,omitempty"`   // Set either role
+	UserID       *uuid.UUID `json:"userId,omitempty"` // or user
+	MaxAmount    float64    `json:"maxAmount" validate:"gte=0"`
+}
+
+// ApprovalReviewRequest represents an approver's decision
+type ApprovalReviewRequest struct {
+	Note *string `json:"note,omitempty"`
+}
+
+// SetLimit godoc
+// @Summary Set an approval limit
+// @Description Set the largest document of a type a role or user may raise without approval, e.g. purchasers up to 50000 per purchase order. A user limit replaces their role's; users with neither are not restricted. Owners and admins only.
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param limit body ApprovalLimitRequest true "Limit"
+// @Success 200 {object} domain.ApprovalLimit
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/approval-limits [put]
+// @Security BearerAuth
+func (h *ApprovalHandler) SetLimit(c echo.Context) error {
+	var req ApprovalLimitRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	limit := domain.NewApprovalLimit(tenantID, domain.ApprovalDocumentType(req.DocumentType), req.Role, req.UserID, req.MaxAmount, &userID)
+	if err := crm.ApprovalService.SetLimit(c.Request().Context(), limit, role); err != nil {
+		return approvalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, limit)
+}
+
+// ListLimits godoc
+// @Summary List approval limits
+// @Description Get the tenant's approval limits, role limits before user limits
+// @Tags approvals
+// @Produce json
+// @Param documentType query string false "purchase_order, purchase_bill or supplier_payment"
+// @Success 200 {array} domain.ApprovalLimit
+// @Router /api/v1/approval-limits [get]
+// @Security BearerAuth
+func (h *ApprovalHandler) ListLimits(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limits, err := crm.ApprovalService.ListLimits(c.Request().Context(), tenantID, documentTypeParam(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, limits)
+}
+
+// MyLimit godoc
+// @Summary Get my approval limit
+// @Description Get the limit that applies to the current user for a document type; null when they are not restricted
+// @Tags approvals
+// @Produce json
+// @Param documentType query string true "purchase_order, purchase_bill or supplier_payment"
+// @Success 200 {object} domain.ApprovalLimit
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/approval-limits/me [get]
+// @Security BearerAuth
+func (h *ApprovalHandler) MyLimit(c echo.Context) error {
+	documentType := documentTypeParam(c)
+	if documentType == nil || !documentType.Valid() {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document type"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	limit, err := crm.ApprovalService.EffectiveLimit(c.Request().Context(), tenantID, *documentType, userID, role)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, limit)
+}
+
+// DeleteLimit godoc
+// @Summary Remove an approval limit
+// @Description Remove a role or user limit. Owners and admins only.
+// @Tags approvals
+// @Param id path string true "Limit ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/approval-limits/{id} [delete]
+// @Security BearerAuth
+func (h *ApprovalHandler) DeleteLimit(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid limit ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	if err := crm.ApprovalService.DeleteLimit(c.Request().Context(), tenantID, id, userID, role); err != nil {
+		return approvalError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListRequests godoc
+// @Summary List approval requests
+// @Description Get documents held for approval, newest first, optionally filtered by status or document type
+// @Tags approvals
+// @Produce json
+// @Param status query string false "pending, approved, rejected or cancelled"
+// @Param documentType query string false "purchase_order, purchase_bill or supplier_payment"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.ApprovalRequest
+// @Router /api/v1/approvals [get]
+// @Security BearerAuth
+func (h *ApprovalHandler) ListRequests(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var status *domain.ApprovalStatus
+	if value := c.QueryParam("status"); value != "" {
+		s := domain.ApprovalStatus(value)
+		status = &s
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	requests, err := crm.ApprovalService.ListRequests(c.Request().Context(), tenantID, status, documentTypeParam(c), limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, requests)
+}
+
+// GetRequest godoc
+// @Summary Get an approval request
+// @Description Get a held document's approval request and decision
+// @Tags approvals
+// @Produce json
+// @Param id path string true "Request ID"
+// @Success 200 {object} domain.ApprovalRequest
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/approvals/{id} [get]
+// @Security BearerAuth
+func (h *ApprovalHandler) GetRequest(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	request, err := crm.ApprovalService.GetRequest(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return approvalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, request)
+}
+
+// Approve godoc
+// @Summary Approve a held document
+// @Description Approve a document over its author's limit, completing it (e.g. confirming the purchase bill). Owners, admins and managers whose own limit covers the amount; never the requester.
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path string true "Request ID"
+// @Param review body ApprovalReviewRequest false "Review note"
+// @Success 200 {object} domain.ApprovalRequest
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/approvals/{id}/approve [post]
+// @Security BearerAuth
+func (h *ApprovalHandler) Approve(c echo.Context) error {
+	return h.review(c, true)
+}
+
+// Reject godoc
+// @Summary Reject a held document
+// @Description Decline a document over its author's limit; it goes back to them for changes
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path string true "Request ID"
+// @Param review body ApprovalReviewRequest false "Review note"
+// @Success 200 {object} domain.ApprovalRequest
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/approvals/{id}/reject [post]
+// @Security BearerAuth
+func (h *ApprovalHandler) Reject(c echo.Context) error {
+	return h.review(c, false)
+}
+
+func (h *ApprovalHandler) review(c echo.Context, approve bool) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request ID"})
+	}
+
+	var req ApprovalReviewRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	reviewerID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	var request *domain.ApprovalRequest
+	if approve {
+		request, err = crm.ApprovalService.Approve(c.Request().Context(), tenantID, id, reviewerID, role, req.Note)
+	} else {
+		request, err = crm.ApprovalService.Reject(c.Request().Context(), tenantID, id, reviewerID, role, req.Note)
+	}
+	if err != nil {
+		return approvalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, request)
+}
+
+// Cancel godoc
+// @Summary Cancel an approval request
+// @Description Withdraw a pending request; the document goes back to its author. Allowed for the requester and approvers.
+// @Tags approvals
+// @Produce json
+// @Param id path string true "Request ID"
+// @Success 200 {object} domain.ApprovalRequest
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/approvals/{id}/cancel [post]
+// @Security BearerAuth
+func (h *ApprovalHandler) Cancel(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	request, err := crm.ApprovalService.Cancel(c.Request().Context(), tenantID, id, userID, role)
+	if err != nil {
+		return approvalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, request)
+}
+
+// documentTypeParam reads the optional documentType query parameter
+func documentTypeParam(c echo.Context) *domain.ApprovalDocumentType {
+	value := c.QueryParam("documentType")
+	if value == "" {
+		return nil
+	}
+	documentType := domain.ApprovalDocumentType(value)
+	return &documentType
+}
+
+// approvalError maps approval domain errors to HTTP responses
+func approvalError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrApprovalLimitNotFound), errors.Is(err, domain.ErrApprovalRequestNotFound),
+		errors.Is(err, domain.ErrBillDraftNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrApprovalNotPermitted), errors.Is(err, domain.ErrApprovalSelfApproval),
+		errors.Is(err, domain.ErrApprovalLimitNotPermitted):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrApprovalState), errors.Is(err, domain.ErrBillDraftState):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,367 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// BillCaptureHandler handles HTTP requests for the bill inbox and draft purchase bills
+type BillCaptureHandler struct{}
+
+// NewBillCaptureHandler creates a new bill capture handler
+func NewBillCaptureHandler() *BillCaptureHandler {
+	return &BillCaptureHandler{}
+}
+
+// BillDraftRequest represents a reviewer's corrections to a draft purchase bill
+type BillDraftRequest struct {
+	SupplierID  *uuid.UUID `json:"supplierId,omitempty"`
+	BillNumber  *string    `json:"billNumber,omitempty"`
+	BillDate    *string    `json:"billDate,omitempty"` // YYYY-MM-DD
+	SupplierPAN *string    `json:"supplierPan,omitempty"`
+	SubTotal    *float64   `json:"subTotal,omitempty"`
+	VATAmount   *float64   `json:"vatAmount,omitempty"`
+	TotalAmount *float64   `json:"totalAmount,omitempty"`
+	DueDate     *string    `json:"dueDate,omitempty"` // YYYY-MM-DD; defaults to the supplier's payment terms on confirm
+}
+
+// RejectBillDraftRequest represents discarding a draft
+type RejectBillDraftRequest struct {
+	Reason string `json:"reason" validate:"required"` // e.g. not a bill, duplicate
+}
+
+// GetInbox godoc
+// @Summary Get the bill inbox
+// @Description Get the tenant's email address for supplier bills. PDFs and scans sent to it become draft purchase bills. The address is created on first use.
+// @Tags bill-capture
+// @Produce json
+// @Success 200 {object} domain.BillInbox
+// @Router /api/v1/bill-inbox [get]
+// @Security BearerAuth
+func (h *BillCaptureHandler) GetInbox(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	inbox, err := crm.BillCaptureService.GetInbox(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, inbox)
+}
+
+// RotateInbox godoc
+// @Summary Rotate the bill inbox address
+// @Description Replace the bill inbox address, e.g. when it receives spam. Mail sent to the old address is no longer accepted.
+// @Tags bill-capture
+// @Produce json
+// @Success 200 {object} domain.BillInbox
+// @Router /api/v1/bill-inbox/rotate [post]
+// @Security BearerAuth
+func (h *BillCaptureHandler) RotateInbox(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+
+	inbox, err := crm.BillCaptureService.RotateInbox(c.Request().Context(), tenantID, userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, inbox)
+}
+
+// ListEmails godoc
+// @Summary List received bill emails
+// @Description List emails received at the bill inbox with how many drafts each produced, newest first
+// @Tags bill-capture
+// @Produce json
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.InboundEmailRecord
+// @Router /api/v1/bill-inbox/emails [get]
+// @Security BearerAuth
+func (h *BillCaptureHandler) ListEmails(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	emails, err := crm.BillCaptureService.ListEmails(c.Request().Context(), tenantID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, emails)
+}
+
+// ListDrafts godoc
+// @Summary List draft purchase bills
+// @Description List bills captured from email, newest first. The file is an attachment of entity type purchase_bill with the draft's ID.
+// @Tags bill-capture
+// @Produce json
+// @Param status query string false "pending_review, confirmed or rejected"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.PurchaseBillDraft
+// @Router /api/v1/bill-drafts [get]
+// @Security BearerAuth
+func (h *BillCaptureHandler) ListDrafts(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var status *domain.BillDraftStatus
+	if value := c.QueryParam("status"); value != "" {
+		s := domain.BillDraftStatus(value)
+		status = &s
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	drafts, err := crm.BillCaptureService.ListDrafts(c.Request().Context(), tenantID, status, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, drafts)
+}
+
+// GetDraft godoc
+// @Summary Get a draft purchase bill
+// @Description Get a bill captured from email with any details read from the file
+// @Tags bill-capture
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Success 200 {object} domain.PurchaseBillDraft
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/bill-drafts/{id} [get]
+// @Security BearerAuth
+func (h *BillCaptureHandler) GetDraft(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid draft ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	draft, err := crm.BillCaptureService.GetDraft(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return billCaptureError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, draft)
+}
+
+// UpdateDraft godoc
+// @Summary Correct a draft purchase bill
+// @Description Fill in or correct the supplier and bill details while reviewing. Only the fields sent are changed.
+// @Tags bill-capture
+// @Accept json
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Param draft body BillDraftRequest true "Bill details"
+// @Success 200 {object} domain.PurchaseBillDraft
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/bill-drafts/{id} [put]
+// @Security BearerAuth
+func (h *BillCaptureHandler) UpdateDraft(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid draft ID"})
+	}
+
+	var req BillDraftRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	draft, err := crm.BillCaptureService.GetDraft(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return billCaptureError(c, err)
+	}
+
+	if req.SupplierID != nil {
+		draft.SupplierID = req.SupplierID
+	}
+	if req.BillNumber != nil {
+		draft.BillNumber = req.BillNumber
+	}
+	if req.BillDate != nil {
+		billDate, err := time.Parse("2006-01-02", *req.BillDate)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid bill date, expected YYYY-MM-DD"})
+		}
+		draft.BillDate = &billDate
+	}
+	if req.SupplierPAN != nil {
+		draft.SupplierPAN = req.SupplierPAN
+	}
+	if req.SubTotal != nil {
+		draft.SubTotal = req.SubTotal
+	}
+	if req.VATAmount != nil {
+		draft.VATAmount = req.VATAmount
+	}
+	if req.TotalAmount != nil {
+		draft.TotalAmount = req.TotalAmount
+	}
+	if req.DueDate != nil {
+		dueDate, err := time.Parse("2006-01-02", *req.DueDate)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid due date, expected YYYY-MM-DD"})
+		}
+		draft.DueDate = &dueDate
+	}
+
+	if err := crm.BillCaptureService.UpdateDraft(c.Request().Context(), draft); err != nil {
+		return billCaptureError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, draft)
+}
+
+// ConfirmDraft godoc
+// @Summary Confirm a draft purchase bill
+// @Description Accept a draft after checking it against the file. Needs a supplier, bill number, bill date and total. A total over the user's purchase bill limit holds the draft (pending_approval) until an approver decides.
+// @Description Without a due date set on review, the bill's due dates (dueSchedule) come from the supplier's payment terms.
+// @Tags bill-capture
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Success 200 {object} domain.PurchaseBillDraft
+// @Success 202 {object} domain.PurchaseBillDraft
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/bill-drafts/{id}/confirm [post]
+// @Security BearerAuth
+func (h *BillCaptureHandler) ConfirmDraft(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid draft ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+
+	role, _ := db.GetRole(c.Request().Context())
+
+	draft, err := crm.BillCaptureService.Confirm(c.Request().Context(), tenantID, id, userID, role)
+	if err != nil {
+		return billCaptureError(c, err)
+	}
+
+	if draft.Status == domain.BillDraftPendingApproval {
+		return c.JSON(http.StatusAccepted, draft)
+	}
+	return c.JSON(http.StatusOK, draft)
+}
+
+// RejectDraft godoc
+// @Summary Reject a draft purchase bill
+// @Description Discard a captured email attachment that is not a bill, or a duplicate
+// @Tags bill-capture
+// @Accept json
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Param rejection body RejectBillDraftRequest true "Reason"
+// @Success 200 {object} domain.PurchaseBillDraft
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/bill-drafts/{id}/reject [post]
+// @Security BearerAuth
+func (h *BillCaptureHandler) RejectDraft(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid draft ID"})
+	}
+
+	var req RejectBillDraftRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+
+	draft, err := crm.BillCaptureService.Reject(c.Request().Context(), tenantID, id, userID, req.Reason)
+	if err != nil {
+		return billCaptureError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, draft)
+}
+
+// billCaptureError maps bill capture errors to HTTP responses
+func billCaptureError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrBillDraftNotFound), errors.Is(err, domain.ErrSupplierNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrBillDraftState):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,390 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ConsignmentHandler handles HTTP requests for supplier consignment stock
+type ConsignmentHandler struct{}
+
+// NewConsignmentHandler creates a new consignment handler
+func NewConsignmentHandler() *ConsignmentHandler {
+	return &ConsignmentHandler{}
+}
+
+// ConsignmentLineRequest represents a product received on consignment
+type ConsignmentLineRequest struct {
+	ProductID string  `json:"productId" validate:"required"`
+	Quantity  float64 `json:"quantity" validate:"gt=0"`
+	UnitCost  float64 `json:"unitCost" validate:"gte=0"`
+}
+
+// CreateConsignmentReceiptRequest represents the request body for receiving consignment stock
+type CreateConsignmentReceiptRequest struct {
+	SupplierID string                   `json:"supplierId" validate:"required"`
+	ReceivedAt *time.Time               `json:"receivedAt,omitempty"`
+	Reference  *string                  `json:"reference,omitempty" validate:"omitempty,max=100"`
+	Note       *string                  `json:"note,omitempty"`
+	Lines      []ConsignmentLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+// ConsignmentSaleRequest represents sold items to settle against consignment stock
+type ConsignmentSaleRequest struct {
+	SaleType *string                      `json:"saleType,omitempty" validate:"omitempty,max=50"`
+	SaleID   *string                      `json:"saleId,omitempty"`
+	Items    []domain.ConsignmentSaleItem `json:"items" validate:"required,min=1"`
+}
+
+// ConsignmentReturnRequest represents unsold stock sent back to the supplier
+type ConsignmentReturnRequest struct {
+	Quantity float64 `json:"quantity" validate:"gt=0"`
+}
+
+// CreateReceipt godoc
+// @Summary Receive consignment stock
+// @Description Record supplier-owned stock received on consignment. No payable is raised until it is sold.
+// @Tags consignments
+// @Accept json
+// @Produce json
+// @Param receipt body CreateConsignmentReceiptRequest true "Consignment receipt"
+// @Success 201 {object} domain.ConsignmentReceipt
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/consignments/receipts [post]
+// @Security BearerAuth
+func (h *ConsignmentHandler) CreateReceipt(c echo.Context) error {
+	var req CreateConsignmentReceiptRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	supplierID, err := uuid.Parse(req.SupplierID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid supplier ID"})
+	}
+
+	receivedAt := time.Now()
+	if req.ReceivedAt != nil {
+		receivedAt = *req.ReceivedAt
+	}
+
+	receipt := domain.NewConsignmentReceipt(tenantID, supplierID, receivedAt)
+	receipt.Reference = req.Reference
+	receipt.Note = req.Note
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		receipt.CreatedBy = &userID
+	}
+
+	for _, line := range req.Lines {
+		productID, err := uuid.Parse(line.ProductID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+		}
+		if err := receipt.AddLine(productID, line.Quantity, line.UnitCost); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+
+	if err := crm.ConsignmentService.Receive(c.Request().Context(), receipt); err != nil {
+		return consignmentError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, receipt)
+}
+
+// ListReceipts godoc
+// @Summary List consignment receipts
+// @Description Get consignment receipts, newest first, optionally for one supplier
+// @Tags consignments
+// @Produce json
+// @Param supplierId query string false "Supplier ID"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.ConsignmentReceipt
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/consignments/receipts [get]
+// @Security BearerAuth
+func (h *ConsignmentHandler) ListReceipts(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	supplierID, err := optionalSupplierID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid supplier ID"})
+	}
+
+	limit, offset := consignmentPage(c)
+	receipts, err := crm.ConsignmentService.ListReceipts(c.Request().Context(), tenantID, supplierID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, receipts)
+}
+
+// GetReceipt godoc
+// @Summary Get a consignment receipt
+// @Description Get a consignment receipt with its lines and how much of each has been sold or returned
+// @Tags consignments
+// @Produce json
+// @Param id path string true "Receipt ID"
+// @Success 200 {object} domain.ConsignmentReceipt
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/consignments/receipts/{id} [get]
+// @Security BearerAuth
+func (h *ConsignmentHandler) GetReceipt(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid receipt ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	receipt, err := crm.ConsignmentService.GetReceipt(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return consignmentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, receipt)
+}
+
+// ReturnLine godoc
+// @Summary Return consignment stock to the supplier
+// @Description Send unsold stock from a consignment receipt line back to its supplier
+// @Tags consignments
+// @Accept json
+// @Param id path string true "Consignment line ID"
+// @Param return body ConsignmentReturnRequest true "Quantity returned"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/consignments/lines/{id}/return [post]
+// @Security BearerAuth
+func (h *ConsignmentHandler) ReturnLine(c echo.Context) error {
+	lineID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid consignment line ID"})
+	}
+
+	var req ConsignmentReturnRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := crm.ConsignmentService.ReturnToSupplier(c.Request().Context(), tenantID, lineID, req.Quantity); err != nil {
+		return consignmentError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RecordSale godoc
+// @Summary Settle a sale against consignment stock
+// @Description Consume sold items from consignment stock (oldest receipt first) and generate a purchase bill per supplier. Items not on consignment are ignored.
+// @Tags consignments
+// @Accept json
+// @Produce json
+// @Param sale body ConsignmentSaleRequest true "Sold items"
+// @Success 201 {array} domain.ConsignmentBill
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/consignments/sales [post]
+// @Security BearerAuth
+func (h *ConsignmentHandler) RecordSale(c echo.Context) error {
+	var req ConsignmentSaleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var saleID *uuid.UUID
+	if req.SaleID != nil {
+		id, err := uuid.Parse(*req.SaleID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid sale ID"})
+		}
+		saleID = &id
+	}
+
+	bills, err := crm.ConsignmentService.RecordSale(c.Request().Context(), tenantID, req.Items, req.SaleType, saleID)
+	if err != nil {
+		return consignmentError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, bills)
+}
+
+// ListBills godoc
+// @Summary List consignment bills
+// @Description Get supplier bills generated from consignment sales, newest first, optionally for one supplier
+// @Tags consignments
+// @Produce json
+// @Param supplierId query string false "Supplier ID"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.ConsignmentBill
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/consignments/bills [get]
+// @Security BearerAuth
+func (h *ConsignmentHandler) ListBills(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	supplierID, err := optionalSupplierID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid supplier ID"})
+	}
+
+	limit, offset := consignmentPage(c)
+	bills, err := crm.ConsignmentService.ListBills(c.Request().Context(), tenantID, supplierID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, bills)
+}
+
+// GetBill godoc
+// @Summary Get a consignment bill
+// @Description Get a supplier bill generated from a consignment sale with its lines
+// @Tags consignments
+// @Produce json
+// @Param id path string true "Bill ID"
+// @Success 200 {object} domain.ConsignmentBill
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/consignments/bills/{id} [get]
+// @Security BearerAuth
+func (h *ConsignmentHandler) GetBill(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid bill ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	bill, err := crm.ConsignmentService.GetBill(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return consignmentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, bill)
+}
+
+// StockReport godoc
+// @Summary Consignment stock report
+// @Description Get consignment stock received, sold, returned and on hand per supplier and product
+// @Tags consignments
+// @Produce json
+// @Param supplierId query string false "Supplier ID"
+// @Success 200 {array} domain.ConsignmentStock
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/consignments/stock [get]
+// @Security BearerAuth
+func (h *ConsignmentHandler) StockReport(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	supplierID, err := optionalSupplierID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid supplier ID"})
+	}
+
+	stock, err := crm.ConsignmentService.StockReport(c.Request().Context(), tenantID, supplierID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+// optionalSupplierID parses the supplierId query parameter, if given
+func optionalSupplierID(c echo.Context) (*uuid.UUID, error) {
+	raw := c.QueryParam("supplierId")
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// consignmentPage reads limit and offset query parameters
+func consignmentPage(c echo.Context) (int, int) {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
+
+// consignmentError maps consignment domain errors to HTTP responses
+func consignmentError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrSupplierNotFound),
+		errors.Is(err, domain.ErrConsignmentReceiptNotFound),
+		errors.Is(err, domain.ErrConsignmentBillNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInsufficientConsignmentStock):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidConsignmentLine):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
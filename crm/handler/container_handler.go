@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ContainerHandler handles HTTP requests for returnable containers and deposits
+type ContainerHandler struct{}
+
+// NewContainerHandler creates a new container handler
+func NewContainerHandler() *ContainerHandler {
+	return &ContainerHandler{}
+}
+
+// CreateContainerTypeRequest represents the request body for creating a container type
+type CreateContainerTypeRequest struct {
+	Code          string  `json:"code" validate:"required,max=50"`
+	Name          string  `json:"name" validate:"required,max=255"`
+	DepositAmount float64 `json:"depositAmount" validate:"gte=0"`
+}
+
+// UpdateContainerTypeRequest represents the request body for updating a container type
+type UpdateContainerTypeRequest struct {
+	Name          string  `json:"name" validate:"required,max=255"`
+	DepositAmount float64 `json:"depositAmount" validate:"gte=0"`
+	IsActive      bool    `json:"isActive"`
+}
+
+// ContainerMovementRequest represents containers issued to or returned by a customer
+type ContainerMovementRequest struct {
+	ContainerTypeID string  `json:"containerTypeId" validate:"required"`
+	Quantity        int     `json:"quantity" validate:"required,gt=0"`
+	ReferenceType   *string `json:"referenceType,omitempty" validate:"omitempty,max=50"`
+	ReferenceID     *string `json:"referenceId,omitempty"`
+	Note            *string `json:"note,omitempty"`
+}
+
+// CreateType godoc
+// @Summary Create a container type
+// @Description Define a returnable container (crate, bottle, cylinder) and its deposit
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param containerType body CreateContainerTypeRequest true "Container type"
+// @Success 201 {object} domain.ContainerType
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/containers/types [post]
+// @Security BearerAuth
+func (h *ContainerHandler) CreateType(c echo.Context) error {
+	var req CreateContainerTypeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	containerType := domain.NewContainerType(tenantID, req.Code, req.Name, req.DepositAmount)
+	if err := crm.ContainerService.CreateType(c.Request().Context(), containerType); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, containerType)
+}
+
+// ListTypes godoc
+// @Summary List container types
+// @Description Get the tenant's returnable container types
+// @Tags containers
+// @Produce json
+// @Success 200 {array} domain.ContainerType
+// @Router /api/v1/containers/types [get]
+// @Security BearerAuth
+func (h *ContainerHandler) ListTypes(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	types, err := crm.ContainerService.ListTypes(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, types)
+}
+
+// UpdateType godoc
+// @Summary Update a container type
+// @Description Change a container type's name, deposit or active flag. A new deposit applies to future issues only.
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Container type ID"
+// @Param containerType body UpdateContainerTypeRequest true "Container type"
+// @Success 200 {object} domain.ContainerType
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/containers/types/{id} [put]
+// @Security BearerAuth
+func (h *ContainerHandler) UpdateType(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid container type ID"})
+	}
+
+	var req UpdateContainerTypeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	containerType, err := crm.ContainerService.GetType(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return containerError(c, err)
+	}
+
+	containerType.Name = req.Name
+	containerType.DepositAmount = req.DepositAmount
+	containerType.IsActive = req.IsActive
+
+	if err := crm.ContainerService.UpdateType(c.Request().Context(), containerType); err != nil {
+		return containerError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, containerType)
+}
+
+// Issue godoc
+// @Summary Issue containers to a customer
+// @Description Record containers sent out with a sale; the type's deposit is collected and added to the customer's balance
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param movement body ContainerMovementRequest true "Containers issued"
+// @Success 201 {object} domain.ContainerMovement
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/customers/{id}/containers/issue [post]
+// @Security BearerAuth
+func (h *ContainerHandler) Issue(c echo.Context) error {
+	return h.recordMovement(c, domain.ContainerIssued)
+}
+
+// Return godoc
+// @Summary Return containers from a customer
+// @Description Record containers brought back; the deposit held for them is refunded
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param movement body ContainerMovementRequest true "Containers returned"
+// @Success 201 {object} domain.ContainerMovement
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/customers/{id}/containers/return [post]
+// @Security BearerAuth
+func (h *ContainerHandler) Return(c echo.Context) error {
+	return h.recordMovement(c, domain.ContainerReturned)
+}
+
+func (h *ContainerHandler) recordMovement(c echo.Context, kind domain.ContainerMovementKind) error {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	var req ContainerMovementRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	containerTypeID, err := uuid.Parse(req.ContainerTypeID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid container type ID"})
+	}
+
+	movement := domain.NewContainerMovement(tenantID, customerID, containerTypeID, kind, req.Quantity)
+	movement.ReferenceType = req.ReferenceType
+	movement.Note = req.Note
+	if req.ReferenceID != nil {
+		referenceID, err := uuid.Parse(*req.ReferenceID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid reference ID"})
+		}
+		movement.ReferenceID = &referenceID
+	}
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		movement.CreatedBy = &userID
+	}
+
+	if kind == domain.ContainerIssued {
+		err = crm.ContainerService.Issue(c.Request().Context(), movement)
+	} else {
+		err = crm.ContainerService.Return(c.Request().Context(), movement)
+	}
+	if err != nil {
+		return containerError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, movement)
+}
+
+// CustomerBalances godoc
+// @Summary Get a customer's container balance
+// @Description Get the containers a customer holds and the deposit held for them
+// @Tags containers
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {array} domain.ContainerBalance
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/customers/{id}/containers [get]
+// @Security BearerAuth
+func (h *ContainerHandler) CustomerBalances(c echo.Context) error {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	balances, err := crm.ContainerService.CustomerBalances(c.Request().Context(), tenantID, customerID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, balances)
+}
+
+// ListMovements godoc
+// @Summary List a customer's container movements
+// @Description Get containers issued to and returned by a customer, newest first
+// @Tags containers
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.ContainerMovement
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/customers/{id}/containers/movements [get]
+// @Security BearerAuth
+func (h *ContainerHandler) ListMovements(c echo.Context) error {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	movements, err := crm.ContainerService.ListMovements(c.Request().Context(), tenantID, customerID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, movements)
+}
+
+// BalanceReport godoc
+// @Summary Container balance report
+// @Description Get every customer's outstanding containers and the total deposit liability
+// @Tags containers
+// @Produce json
+// @Success 200 {object} domain.ContainerBalanceReport
+// @Router /api/v1/containers/balances [get]
+// @Security BearerAuth
+func (h *ContainerHandler) BalanceReport(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	report, err := crm.ContainerService.BalanceReport(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// containerError maps container domain errors to HTTP responses
+func containerError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrCustomerNotFound), errors.Is(err, domain.ErrContainerTypeNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInsufficientContainers):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
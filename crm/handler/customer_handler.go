@@ -1,12 +1,19 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/aceextension/core/dataimport"
 	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/export"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/core/stream"
 	"github.com/aceextension/crm"
 	"github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/taxid"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -21,11 +28,16 @@ func NewCustomerHandler() *CustomerHandler {
 
 // CreateCustomerRequest represents the request body for creating a customer
 type CreateCustomerRequest struct {
+	ID               *uuid.UUID             `json:"id,omitempty"`                                                 // Client-generated ID of a customer created offline
+	DeviceID         string                 `json:"deviceId,omitempty" validate:"max=100"`                        // Device that created it offline; defaults to the X-Device-ID header
+	Resolution       string                 `json:"resolution,omitempty" validate:"omitempty,oneof=create merge"` // How to settle phone/PAN matches of an offline customer
+	MergeInto        *uuid.UUID             `json:"mergeInto,omitempty"`                                          // Matched customer to merge into, with resolution=merge
 	Name             string                 `json:"name" validate:"required,min=2,max=255"`
 	Email            *string                `json:"email,omitempty" validate:"omitempty,email"`
 	Phone            *string                `json:"phone,omitempty"`
 	CustomerType     string                 `json:"customerType" validate:"required,oneof=individual business"`
 	CustomAttributes map[string]interface{} `json:"customAttributes,omitempty"`
+	Address          *domain.Address        `json:"address,omitempty"` // Structured address; district is required
 }
 
 // UpdateCustomerRequest represents the request body for updating a customer
@@ -36,6 +48,7 @@ type UpdateCustomerRequest struct {
 	CustomerType     string                 `json:"customerType" validate:"required,oneof=individual business"`
 	Status           string                 `json:"status" validate:"required,oneof=active inactive blocked"`
 	CustomAttributes map[string]interface{} `json:"customAttributes,omitempty"`
+	Address          *domain.Address        `json:"address,omitempty"` // Structured address; district is required
 }
 
 // CustomerResponse represents the response for a customer
@@ -49,10 +62,24 @@ type CustomerResponse struct {
 	CustomerType     string                 `json:"customerType"`
 	Status           string                 `json:"status"`
 	CustomAttributes map[string]interface{} `json:"customAttributes"`
+	Address          *domain.Address        `json:"address,omitempty"`
+	PANStatus        domain.PANStatus       `json:"panStatus,omitempty"` // unverified, verified or not_found; empty without a PAN
 	CreatedAt        string                 `json:"createdAt"`
 	UpdatedAt        string                 `json:"updatedAt"`
 }
 
+// CustomerMatchResponse is an existing customer an offline customer may duplicate
+type CustomerMatchResponse struct {
+	Customer  *CustomerResponse `json:"customer"`
+	MatchedOn []string          `json:"matchedOn"`
+}
+
+// DuplicateCustomerResponse suggests merging an offline customer into one of its matches
+type DuplicateCustomerResponse struct {
+	Error   string                  `json:"error"`
+	Matches []CustomerMatchResponse `json:"matches"`
+}
+
 // toResponse converts domain.Customer to CustomerResponse
 func toCustomerResponse(customer *domain.Customer) *CustomerResponse {
 	return &CustomerResponse{
@@ -65,6 +92,8 @@ func toCustomerResponse(customer *domain.Customer) *CustomerResponse {
 		CustomerType:     string(customer.CustomerType),
 		Status:           string(customer.Status),
 		CustomAttributes: customer.CustomAttributes,
+		Address:          customer.GetStructuredAddress(),
+		PANStatus:        customer.PANStatus(),
 		CreatedAt:        customer.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:        customer.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -72,13 +101,19 @@ func toCustomerResponse(customer *domain.Customer) *CustomerResponse {
 
 // Create godoc
 // @Summary Create a new customer
-// @Description Create a new customer with custom attributes
+// @Description Create a new customer with custom attributes. Devices syncing customers created offline send
+// @Description the ID they generated: syncing the same ID again returns the customer it became (200), and a
+// @Description customer sharing a phone or PAN with existing ones returns 409 with the matches until it is sent
+// @Description again with resolution=create, or resolution=merge and mergeInto set to one of the matches.
 // @Tags customers
 // @Accept json
 // @Produce json
 // @Param customer body CreateCustomerRequest true "Customer data"
+// @Param X-Device-ID header string false "Device that created the customer offline"
+// @Success 200 {object} CustomerResponse "Already synced, or merged into an existing customer"
 // @Success 201 {object} CustomerResponse
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} DuplicateCustomerResponse
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/customers [post]
 // @Security BearerAuth
@@ -104,14 +139,67 @@ func (h *CustomerHandler) Create(c echo.Context) error {
 	customer.Phone = req.Phone
 	customer.CustomerType = domain.CustomerType(req.CustomerType)
 	customer.CustomAttributes = req.CustomAttributes
+	if req.Address != nil {
+		customer.SetStructuredAddress(req.Address)
+	}
+
+	if req.ID != nil && *req.ID != uuid.Nil {
+		return h.sync(c, customer, *req.ID, req)
+	}
 
 	if err := crm.CustomerService.Create(c.Request().Context(), customer); err != nil {
+		if errors.Is(err, domain.ErrInvalidAddress) || errors.Is(err, taxid.ErrInvalidPAN) || errors.Is(err, domain.ErrInvalidPaymentTerms) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusCreated, toCustomerResponse(customer))
 }
 
+// sync saves a customer created offline under the ID its device generated
+func (h *CustomerHandler) sync(c echo.Context, customer *domain.Customer, id uuid.UUID, req CreateCustomerRequest) error {
+	userID, _ := db.GetUserID(c.Request().Context())
+
+	deviceID := req.DeviceID
+	if deviceID == "" {
+		deviceID = c.Request().Header.Get("X-Device-ID")
+	}
+	if len(deviceID) > 100 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Device ID is too long"})
+	}
+
+	customer.ID = id
+	result, err := crm.CustomerService.Sync(c.Request().Context(), customer, userID, domain.CustomerSync{
+		DeviceID:   deviceID,
+		Resolution: domain.SyncResolution(req.Resolution),
+		MergeInto:  req.MergeInto,
+	})
+
+	var duplicate *domain.DuplicateCustomerError
+	switch {
+	case errors.As(err, &duplicate):
+		matches := make([]CustomerMatchResponse, len(duplicate.Matches))
+		for i, match := range duplicate.Matches {
+			matches[i] = CustomerMatchResponse{Customer: toCustomerResponse(match.Customer), MatchedOn: match.MatchedOn}
+		}
+		return c.JSON(http.StatusConflict, DuplicateCustomerResponse{Error: err.Error(), Matches: matches})
+	case errors.Is(err, domain.ErrInvalidAddress), errors.Is(err, domain.ErrInvalidResolution), errors.Is(err, taxid.ErrInvalidPAN),
+		errors.Is(err, domain.ErrInvalidPaymentTerms):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrCustomerIDTaken):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	c.Response().Header().Set("X-Sync-Outcome", string(result.Outcome))
+	if result.Outcome == domain.SyncCreated {
+		return c.JSON(http.StatusCreated, toCustomerResponse(result.Customer))
+	}
+	return c.JSON(http.StatusOK, toCustomerResponse(result.Customer))
+}
+
 // GetByID godoc
 // @Summary Get customer by ID
 // @Description Get a customer by their ID
@@ -129,22 +217,39 @@ func (h *CustomerHandler) GetByID(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
 	}
 
-	customer, err := crm.CustomerService.GetByID(c.Request().Context(), id)
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	customer, err := crm.CustomerService.GetByID(c.Request().Context(), tenantID, id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Customer not found"})
 	}
 
+	// Track for the user's recently viewed list; failures must not block the read
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		if err := crm.QuickPickService.RecordView(c.Request().Context(), tenantID, userID, customer.ID); err != nil {
+			logger.Log.Warn("Failed to record customer view: " + err.Error())
+		}
+	}
+
 	return c.JSON(http.StatusOK, toCustomerResponse(customer))
 }
 
 // List godoc
 // @Summary List customers
-// @Description Get a paginated list of customers for the current tenant
+// @Description Get the tenant's customers, newest first, a page at a time. Pass the nextCursor of a page as cursor to get the next; it is absent on the last page.
 // @Tags customers
 // @Produce json
-// @Param limit query int false "Limit" default(10)
-// @Param offset query int false "Offset" default(0)
-// @Success 200 {array} CustomerResponse
+// @Param limit query int false "Page size (max 200)" default(10)
+// @Param cursor query string false "nextCursor of the previous page"
+// @Param district query string false "Only customers with a structured address in this district, ordered by municipality, ward and tole"
+// @Param municipality query string false "Narrow the district filter to a municipality"
+// @Param ward query int false "Narrow the municipality filter to a ward"
+// @Param tags query string false "Only customers with these comma-separated tags; cannot be combined with district"
+// @Param tagMatch query string false "all (default) or any of the tags"
+// @Success 200 {object} db.Page[CustomerResponse]
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/customers [get]
@@ -155,27 +260,149 @@ func (h *CustomerHandler) List(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
 	}
 
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	if limit <= 0 {
-		limit = 10
+	page, err := db.ParsePageRequest(c.QueryParam("cursor"), c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	offset, _ := strconv.Atoi(c.QueryParam("offset"))
-	if offset < 0 {
-		offset = 0
+	tagFilter, err := tagsDomain.ParseTagFilter(c.QueryParam("tags"), c.QueryParam("tagMatch"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	customers, err := crm.CustomerService.GetByTenantID(c.Request().Context(), tenantID, limit, offset)
+	var customers db.Page[*domain.Customer]
+	district := c.QueryParam("district")
+	switch {
+	case district != "" && tagFilter != nil:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "district and tags filters cannot be combined"})
+	case district != "":
+		ward, _ := strconv.Atoi(c.QueryParam("ward"))
+		filter := domain.AreaFilter{District: district, Municipality: c.QueryParam("municipality"), Ward: ward}
+		customers, err = crm.CustomerService.ListByArea(c.Request().Context(), tenantID, filter, page)
+	default:
+		customers, err = crm.CustomerService.List(c.Request().Context(), tenantID, tagFilter, page)
+	}
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAddress) || errors.Is(err, db.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
-	responses := make([]*CustomerResponse, len(customers))
-	for i, customer := range customers {
-		responses[i] = toCustomerResponse(customer)
+	return c.JSON(http.StatusOK, db.MapPage(customers, toCustomerResponse))
+}
+
+// customerAddress is the customer's structured address, or an empty one
+func customerAddress(customer *domain.Customer) domain.Address {
+	if address := customer.GetStructuredAddress(); address != nil {
+		return *address
 	}
+	return domain.Address{}
+}
 
-	return c.JSON(http.StatusOK, responses)
+// customerExportColumns are named like the import's columns, so an exported file can be
+// edited and imported again
+var customerExportColumns = []export.Column[*domain.Customer]{
+	{Name: "customerCode", Value: func(c *domain.Customer) any { return c.CustomerCode }},
+	{Name: "name", Value: func(c *domain.Customer) any { return c.Name }},
+	{Name: "customerType", Value: func(c *domain.Customer) any { return string(c.CustomerType) }},
+	{Name: "email", Value: func(c *domain.Customer) any { return c.Email }},
+	{Name: "phone", Value: func(c *domain.Customer) any { return c.Phone }},
+	{Name: "pan", Value: func(c *domain.Customer) any { return c.GetPANNumber() }},
+	{Name: "vat", Value: func(c *domain.Customer) any { return c.GetVATNumber() }},
+	{Name: "creditLimit", Value: func(c *domain.Customer) any { return c.GetCreditLimit() }},
+	{Name: "address", Value: func(c *domain.Customer) any { return c.GetAddress() }},
+	{Name: "district", Value: func(c *domain.Customer) any { return customerAddress(c).District }},
+	{Name: "municipality", Value: func(c *domain.Customer) any { return customerAddress(c).Municipality }},
+	{Name: "ward", Value: func(c *domain.Customer) any {
+		if ward := customerAddress(c).Ward; ward > 0 {
+			return ward
+		}
+		return nil
+	}},
+	{Name: "tole", Value: func(c *domain.Customer) any { return customerAddress(c).Tole }},
+	{Name: "status", Value: func(c *domain.Customer) any { return string(c.Status) }},
+	{Name: "createdAt", Value: func(c *domain.Customer) any { return c.CreatedAt }},
+	{Name: "updatedAt", Value: func(c *domain.Customer) any { return c.UpdatedAt }},
+}
+
+// Export godoc
+// @Summary Export customers
+// @Description Download every customer for the current tenant, streamed as it is read: CSV with format=csv or an
+// @Description Excel workbook with format=xlsx, with columns named like the import's; otherwise a JSON array, or
+// @Description NDJSON (one customer per line) with format=ndjson or Accept: application/x-ndjson
+// @Tags customers
+// @Produce json
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv, xlsx, json (default) or ndjson"
+// @Success 200 {array} CustomerResponse
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/customers/export [get]
+// @Security BearerAuth
+func (h *CustomerHandler) Export(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if format, ok := export.Negotiate(c.Request()); ok {
+		out := export.NewWriter(c.Response(), format, "customers", customerExportColumns)
+		err := crm.CustomerService.Export(c.Request().Context(), tenantID, out.Write)
+		return export.Finish(c, out, err)
+	}
+	out := stream.NewWriter(c.Response(), stream.Negotiate(c.Request())).AsAttachment("customers")
+	err := crm.CustomerService.Export(c.Request().Context(), tenantID, func(customer *domain.Customer) error {
+		return out.Write(toCustomerResponse(customer))
+	})
+	return export.Finish(c, out, err)
+}
+
+// Import godoc
+// @Summary Import customers
+// @Description Create customers from a CSV or XLSX file (first sheet), one per row under a header row. Required column: name; optional: customerType, email, phone, pan, vat, creditLimit, address, and district, municipality, ward, tole for a structured address. A row whose phone or PAN repeats another row or an existing customer is an error. Rows are imported only when every row is valid; with dryRun nothing is created. The report lists each row's errors by row number, the header being row 1.
+// @Tags customers
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file (max 10 MB, 5000 rows)"
+// @Param dryRun formData bool false "Only validate"
+// @Success 200 {object} dataimport.Report
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 422 {object} dataimport.Report
+// @Router /api/v1/customers/import [post]
+// @Security BearerAuth
+func (h *CustomerHandler) Import(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+	userID, _ := db.GetUserID(c.Request().Context())
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required"})
+	}
+	table, err := dataimport.ReadUpload(fileHeader)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dataimport.ErrFileTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+	dryRun, _ := strconv.ParseBool(c.FormValue("dryRun"))
+
+	report, err := crm.CustomerService.Import(c.Request().Context(), tenantID, userID, table, dryRun)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if !report.OK() {
+		return c.JSON(http.StatusUnprocessableEntity, report)
+	}
+	return c.JSON(http.StatusOK, report)
 }
 
 // Search godoc
@@ -183,7 +410,9 @@ func (h *CustomerHandler) List(c echo.Context) error {
 // @Description Search customers by name, email, phone, or code
 // @Tags customers
 // @Produce json
-// @Param q query string true "Search query"
+// @Param q query string false "Search query; required without tags"
+// @Param tags query string false "Only customers with these comma-separated tags"
+// @Param tagMatch query string false "all (default) or any of the tags"
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} CustomerResponse
@@ -197,8 +426,13 @@ func (h *CustomerHandler) Search(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
 	}
 
+	tagFilter, err := tagsDomain.ParseTagFilter(c.QueryParam("tags"), c.QueryParam("tagMatch"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
 	query := c.QueryParam("q")
-	if query == "" {
+	if query == "" && tagFilter == nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Search query is required"})
 	}
 
@@ -212,7 +446,12 @@ func (h *CustomerHandler) Search(c echo.Context) error {
 		offset = 0
 	}
 
-	customers, err := crm.CustomerService.Search(c.Request().Context(), tenantID, query, limit, offset)
+	var customers []*domain.Customer
+	if tagFilter != nil {
+		customers, err = crm.CustomerService.ListByTags(c.Request().Context(), tenantID, *tagFilter, query, limit, offset)
+	} else {
+		customers, err = crm.CustomerService.Search(c.Request().Context(), tenantID, query, limit, offset)
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -254,8 +493,13 @@ func (h *CustomerHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
 	// Get existing customer
-	customer, err := crm.CustomerService.GetByID(c.Request().Context(), id)
+	customer, err := crm.CustomerService.GetByID(c.Request().Context(), tenantID, id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Customer not found"})
 	}
@@ -267,8 +511,17 @@ func (h *CustomerHandler) Update(c echo.Context) error {
 	customer.CustomerType = domain.CustomerType(req.CustomerType)
 	customer.Status = domain.CustomerStatus(req.Status)
 	customer.CustomAttributes = req.CustomAttributes
+	if req.Address != nil {
+		customer.SetStructuredAddress(req.Address)
+	}
 
 	if err := crm.CustomerService.Update(c.Request().Context(), customer); err != nil {
+		if errors.Is(err, domain.ErrInvalidAddress) || errors.Is(err, taxid.ErrInvalidPAN) || errors.Is(err, domain.ErrInvalidPaymentTerms) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, domain.ErrCustomerNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Customer not found"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -293,7 +546,15 @@ func (h *CustomerHandler) Delete(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
 	}
 
-	if err := crm.CustomerService.Delete(c.Request().Context(), id); err != nil {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := crm.CustomerService.Delete(c.Request().Context(), tenantID, id); err != nil {
+		if errors.Is(err, domain.ErrCustomerNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Customer not found"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// CustomerOTPHandler handles HTTP requests for customer verification codes
+type CustomerOTPHandler struct{}
+
+// NewCustomerOTPHandler creates a new customer OTP handler
+func NewCustomerOTPHandler() *CustomerOTPHandler {
+	return &CustomerOTPHandler{}
+}
+
+// SendOTPRequest represents a request to send a customer a verification code
+type SendOTPRequest struct {
+	Channel string `json:"channel" validate:"omitempty,oneof=SMS EMAIL"` // SMS by default
+}
+
+// VerifyOTPRequest represents the code the customer read out
+type VerifyOTPRequest struct {
+	Code string `json:"code" validate:"required,max=10"`
+}
+
+// CustomerOTPResponse represents a verification challenge
+type CustomerOTPResponse struct {
+	ID          uuid.UUID         `json:"id"`
+	CustomerID  uuid.UUID         `json:"customerId"`
+	Channel     domain.OTPChannel `json:"channel"`
+	Recipient   string            `json:"recipient"` // Masked, for the cashier to read back
+	ExpiresAt   time.Time         `json:"expiresAt"`
+	Verified    bool              `json:"verified"`
+	VerifiedAt  *time.Time        `json:"verifiedAt,omitempty"`
+	UsableUntil *time.Time        `json:"usableUntil,omitempty"` // Last moment the verification can be recorded on a sale
+	InvoiceID   *uuid.UUID        `json:"invoiceId,omitempty"`
+}
+
+func toCustomerOTPResponse(otp *domain.CustomerOTP) *CustomerOTPResponse {
+	response := &CustomerOTPResponse{
+		ID:         otp.ID,
+		CustomerID: otp.CustomerID,
+		Channel:    otp.Channel,
+		Recipient:  otp.MaskedRecipient(),
+		ExpiresAt:  otp.ExpiresAt,
+		Verified:   otp.VerifiedAt != nil,
+		VerifiedAt: otp.VerifiedAt,
+		InvoiceID:  otp.InvoiceID,
+	}
+	if otp.VerifiedAt != nil {
+		usableUntil := otp.VerifiedAt.Add(domain.OTPProofTTL)
+		response.UsableUntil = &usableUntil
+	}
+	return response
+}
+
+// Send godoc
+// @Summary Send a customer a verification code
+// @Description Text (or email) a short code to the customer's phone to confirm, before a credit sale, that the buyer controls it.
+// @Description A new code can be requested every 30 seconds; codes expire after 5 minutes or 5 wrong attempts.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param otp body SendOTPRequest false "Channel"
+// @Success 201 {object} CustomerOTPResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /api/v1/customers/{id}/otp [post]
+// @Security BearerAuth
+func (h *CustomerOTPHandler) Send(c echo.Context) error {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	var req SendOTPRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	channel := domain.OTPChannelSMS
+	if req.Channel != "" {
+		channel = domain.OTPChannel(req.Channel)
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+	userID, _ := db.GetUserID(c.Request().Context())
+
+	otp, err := crm.CustomerOTPService.Send(c.Request().Context(), tenantID, customerID, channel, userID)
+	if err != nil {
+		return customerOTPError(c, err)
+	}
+	return c.JSON(http.StatusCreated, toCustomerOTPResponse(otp))
+}
+
+// Verify godoc
+// @Summary Verify a customer's code
+// @Description Check the code the customer received. A verified code can be recorded on one credit sale within 30 minutes.
+// @Tags customer-otps
+// @Accept json
+// @Produce json
+// @Param id path string true "Verification ID"
+// @Param code body VerifyOTPRequest true "Code"
+// @Success 200 {object} CustomerOTPResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/customer-otps/{id}/verify [post]
+// @Security BearerAuth
+func (h *CustomerOTPHandler) Verify(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid verification ID"})
+	}
+
+	var req VerifyOTPRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+	userID, _ := db.GetUserID(c.Request().Context())
+
+	otp, err := crm.CustomerOTPService.Verify(c.Request().Context(), tenantID, id, req.Code, userID)
+	if err != nil {
+		return customerOTPError(c, err)
+	}
+	return c.JSON(http.StatusOK, toCustomerOTPResponse(otp))
+}
+
+// Get godoc
+// @Summary Get a verification
+// @Description Get whether a customer verification was completed and whether it was recorded on a sale
+// @Tags customer-otps
+// @Produce json
+// @Param id path string true "Verification ID"
+// @Success 200 {object} CustomerOTPResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/customer-otps/{id} [get]
+// @Security BearerAuth
+func (h *CustomerOTPHandler) Get(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid verification ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	otp, err := crm.CustomerOTPService.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return customerOTPError(c, err)
+	}
+	return c.JSON(http.StatusOK, toCustomerOTPResponse(otp))
+}
+
+// customerOTPError maps verification errors to HTTP responses
+func customerOTPError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrCustomerNotFound), errors.Is(err, domain.ErrOTPNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrOTPNoRecipient), errors.Is(err, domain.ErrOTPInvalidCode), errors.Is(err, domain.ErrOTPExpired):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrOTPTooSoon):
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrOTPNotVerified):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
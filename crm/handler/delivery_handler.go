@@ -0,0 +1,583 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// DeliveryHandler handles HTTP requests for delivery routes, sheets and driver updates
+type DeliveryHandler struct{}
+
+// NewDeliveryHandler creates a new delivery handler
+func NewDeliveryHandler() *DeliveryHandler {
+	return &DeliveryHandler{}
+}
+
+// DeliveryRouteRequest represents a request to create or update a delivery route
+type DeliveryRouteRequest struct {
+	Code     string     `json:"code" validate:"required"`
+	Name     string     `json:"name" validate:"required"`
+	Weekdays []int      `json:"weekdays"` // 0 = Sunday ... 6 = Saturday
+	DriverID *uuid.UUID `json:"driverId,omitempty"`
+	Vehicle  *string    `json:"vehicle,omitempty"`
+	IsActive *bool      `json:"isActive,omitempty"`
+}
+
+// RouteCustomerRequest represents a request to put a customer on a route
+type RouteCustomerRequest struct {
+	CustomerID uuid.UUID `json:"customerId" validate:"required"`
+	Sequence   int       `json:"sequence" validate:"gte=0"` // Position in the run; lower is visited first
+}
+
+// DeliveryLineRequest is a product to hand over
+type DeliveryLineRequest struct {
+	ProductID   *uuid.UUID `json:"productId,omitempty"`
+	Description string     `json:"description" validate:"required"`
+	Quantity    float64    `json:"quantity" validate:"gt=0"`
+	Unit        string     `json:"unit"`
+}
+
+// QueueDeliveryRequest represents an invoice to deliver
+type QueueDeliveryRequest struct {
+	CustomerID    uuid.UUID             `json:"customerId" validate:"required"`
+	RouteID       *uuid.UUID            `json:"routeId,omitempty"` // Defaults to the customer's route
+	DeliveryDate  string                `json:"deliveryDate"`      // YYYY-MM-DD; defaults to today
+	InvoiceType   *string               `json:"invoiceType,omitempty"`
+	InvoiceID     *uuid.UUID            `json:"invoiceId,omitempty"`
+	InvoiceNumber string                `json:"invoiceNumber" validate:"required"`
+	InvoiceAmount float64               `json:"invoiceAmount" validate:"gte=0"`
+	CollectAmount float64               `json:"collectAmount" validate:"gte=0"`
+	OnCredit      bool                  `json:"onCredit"`
+	Lines         []DeliveryLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+// DeliverRequest represents a driver marking a delivery handed over
+type DeliverRequest struct {
+	CollectedAmount float64 `json:"collectedAmount" validate:"gte=0"`
+	ReceivedBy      *string `json:"receivedBy,omitempty"`
+	Note            *string `json:"note,omitempty"`
+}
+
+// FailDeliveryRequest represents a driver marking a delivery not made
+type FailDeliveryRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// CreateRoute godoc
+// @Summary Create a delivery route
+// @Description Create a van route with the weekdays it runs and, optionally, its driver
+// @Tags deliveries
+// @Accept json
+// @Produce json
+// @Param route body DeliveryRouteRequest true "Route"
+// @Success 201 {object} domain.DeliveryRoute
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/routes [post]
+// @Security BearerAuth
+func (h *DeliveryHandler) CreateRoute(c echo.Context) error {
+	var req DeliveryRouteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	route := domain.NewDeliveryRoute(tenantID, req.Code, req.Name, req.Weekdays)
+	route.DriverID = req.DriverID
+	route.Vehicle = req.Vehicle
+	if req.IsActive != nil {
+		route.IsActive = *req.IsActive
+	}
+
+	if err := crm.DeliveryService.CreateRoute(c.Request().Context(), route); err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, route)
+}
+
+// ListRoutes godoc
+// @Summary List delivery routes
+// @Description Get the tenant's delivery routes
+// @Tags deliveries
+// @Produce json
+// @Param active query bool false "Only active routes"
+// @Success 200 {array} domain.DeliveryRoute
+// @Router /api/v1/routes [get]
+// @Security BearerAuth
+func (h *DeliveryHandler) ListRoutes(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	routes, err := crm.DeliveryService.ListRoutes(c.Request().Context(), tenantID, c.QueryParam("active") == "true")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, routes)
+}
+
+// GetRoute godoc
+// @Summary Get a delivery route
+// @Description Get a delivery route by ID
+// @Tags deliveries
+// @Produce json
+// @Param id path string true "Route ID"
+// @Success 200 {object} domain.DeliveryRoute
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/routes/{id} [get]
+// @Security BearerAuth
+func (h *DeliveryHandler) GetRoute(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid route ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	route, err := crm.DeliveryService.GetRoute(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, route)
+}
+
+// UpdateRoute godoc
+// @Summary Update a delivery route
+// @Description Update a route's name, weekdays, driver or vehicle, or deactivate it
+// @Tags deliveries
+// @Accept json
+// @Produce json
+// @Param id path string true "Route ID"
+// @Param route body DeliveryRouteRequest true "Route"
+// @Success 200 {object} domain.DeliveryRoute
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/routes/{id} [put]
+// @Security BearerAuth
+func (h *DeliveryHandler) UpdateRoute(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid route ID"})
+	}
+
+	var req DeliveryRouteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	route, err := crm.DeliveryService.GetRoute(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return deliveryError(c, err)
+	}
+
+	updated := domain.NewDeliveryRoute(tenantID, req.Code, req.Name, req.Weekdays)
+	route.Code = updated.Code
+	route.Name = updated.Name
+	route.Weekdays = updated.Weekdays
+	route.DriverID = req.DriverID
+	route.Vehicle = req.Vehicle
+	if req.IsActive != nil {
+		route.IsActive = *req.IsActive
+	}
+
+	if err := crm.DeliveryService.UpdateRoute(c.Request().Context(), route); err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, route)
+}
+
+// ListStops godoc
+// @Summary List a route's customers
+// @Description Get the customers on a route in visiting order
+// @Tags deliveries
+// @Produce json
+// @Param id path string true "Route ID"
+// @Success 200 {array} domain.RouteStop
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/routes/{id}/customers [get]
+// @Security BearerAuth
+func (h *DeliveryHandler) ListStops(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid route ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	stops, err := crm.DeliveryService.ListStops(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, stops)
+}
+
+// AssignCustomer godoc
+// @Summary Put a customer on a route
+// @Description Assign a customer to a route at a position in the run. A customer is on one route at a time; assigning moves them.
+// @Tags deliveries
+// @Accept json
+// @Produce json
+// @Param id path string true "Route ID"
+// @Param assignment body RouteCustomerRequest true "Customer and position"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/routes/{id}/customers [post]
+// @Security BearerAuth
+func (h *DeliveryHandler) AssignCustomer(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid route ID"})
+	}
+
+	var req RouteCustomerRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := crm.DeliveryService.AssignCustomer(c.Request().Context(), tenantID, id, req.CustomerID, req.Sequence); err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemoveCustomer godoc
+// @Summary Take a customer off a route
+// @Description Remove a customer from a route; their pending deliveries stay on it
+// @Tags deliveries
+// @Param id path string true "Route ID"
+// @Param customerId path string true "Customer ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/routes/{id}/customers/{customerId} [delete]
+// @Security BearerAuth
+func (h *DeliveryHandler) RemoveCustomer(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid route ID"})
+	}
+
+	customerID, err := uuid.Parse(c.Param("customerId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := crm.DeliveryService.RemoveCustomer(c.Request().Context(), tenantID, id, customerID); err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Sheet godoc
+// @Summary Get a route's delivery sheet
+// @Description Get a route's run for a day: stops in order with their invoices, the total quantity of each product to load and the cash to collect
+// @Tags deliveries
+// @Produce json
+// @Param id path string true "Route ID"
+// @Param date query string false "Delivery date (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} domain.DeliverySheet
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/routes/{id}/sheet [get]
+// @Security BearerAuth
+func (h *DeliveryHandler) Sheet(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid route ID"})
+	}
+
+	date, err := parseAsOf(c.QueryParam("date"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid date, expected YYYY-MM-DD"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	sheet, err := crm.DeliveryService.Sheet(c.Request().Context(), tenantID, id, date)
+	if err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, sheet)
+}
+
+// Queue godoc
+// @Summary Queue an invoice for delivery
+// @Description Schedule an invoice's goods for delivery on a day. Without a route the customer's route is used.
+// @Tags deliveries
+// @Accept json
+// @Produce json
+// @Param delivery body QueueDeliveryRequest true "Delivery"
+// @Success 201 {object} domain.Delivery
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/deliveries [post]
+// @Security BearerAuth
+func (h *DeliveryHandler) Queue(c echo.Context) error {
+	var req QueueDeliveryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	date, err := parseAsOf(req.DeliveryDate)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid delivery date, expected YYYY-MM-DD"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	delivery := domain.NewDelivery(tenantID, req.CustomerID, req.InvoiceNumber, req.InvoiceAmount, req.CollectAmount, date)
+	if req.RouteID != nil {
+		delivery.RouteID = *req.RouteID
+	}
+	delivery.InvoiceType = req.InvoiceType
+	delivery.InvoiceID = req.InvoiceID
+	delivery.OnCredit = req.OnCredit
+	for _, line := range req.Lines {
+		if err := delivery.AddLine(line.ProductID, line.Description, line.Quantity, line.Unit); err != nil {
+			return deliveryError(c, err)
+		}
+	}
+
+	if err := crm.DeliveryService.Queue(c.Request().Context(), delivery); err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, delivery)
+}
+
+// GetDelivery godoc
+// @Summary Get a delivery
+// @Description Get a delivery with its lines
+// @Tags deliveries
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} domain.Delivery
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/deliveries/{id} [get]
+// @Security BearerAuth
+func (h *DeliveryHandler) GetDelivery(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid delivery ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	delivery, err := crm.DeliveryService.GetDelivery(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, delivery)
+}
+
+// Deliver godoc
+// @Summary Mark a delivery handed over
+// @Description Driver endpoint: record the delivery with the cash collected and who received it. Cash collected on a credit invoice is posted to the customer's khata as a payment.
+// @Tags deliveries
+// @Accept json
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Param delivery body DeliverRequest true "Collection"
+// @Success 200 {object} domain.Delivery
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/deliveries/{id}/deliver [post]
+// @Security BearerAuth
+func (h *DeliveryHandler) Deliver(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid delivery ID"})
+	}
+
+	var req DeliverRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	delivery, err := crm.DeliveryService.Deliver(c.Request().Context(), tenantID, id, userID, role, req.CollectedAmount, req.ReceivedBy, req.Note)
+	if err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, delivery)
+}
+
+// Fail godoc
+// @Summary Mark a delivery failed
+// @Description Driver endpoint: record that a delivery could not be made, e.g. the shop was closed
+// @Tags deliveries
+// @Accept json
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Param failure body FailDeliveryRequest true "Reason"
+// @Success 200 {object} domain.Delivery
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/deliveries/{id}/fail [post]
+// @Security BearerAuth
+func (h *DeliveryHandler) Fail(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid delivery ID"})
+	}
+
+	var req FailDeliveryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	delivery, err := crm.DeliveryService.Fail(c.Request().Context(), tenantID, id, userID, role, req.Reason)
+	if err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, delivery)
+}
+
+// DriverSheets godoc
+// @Summary Get my delivery sheets
+// @Description Driver endpoint: get the day's sheets for the active routes the caller drives
+// @Tags deliveries
+// @Produce json
+// @Param date query string false "Delivery date (YYYY-MM-DD), defaults to today"
+// @Success 200 {array} domain.DeliverySheet
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/driver/sheets [get]
+// @Security BearerAuth
+func (h *DeliveryHandler) DriverSheets(c echo.Context) error {
+	date, err := parseAsOf(c.QueryParam("date"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid date, expected YYYY-MM-DD"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+
+	sheets, err := crm.DeliveryService.DriverSheets(c.Request().Context(), tenantID, userID, date)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, sheets)
+}
+
+// deliveryError maps delivery domain errors to HTTP responses
+func deliveryError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrRouteNotFound), errors.Is(err, domain.ErrDeliveryNotFound), errors.Is(err, domain.ErrCustomerNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotRouteDriver):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeliveryState), errors.Is(err, domain.ErrNoRoute):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,344 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// DunningHandler handles HTTP requests for dues reminder sequences
+type DunningHandler struct{}
+
+// NewDunningHandler creates a new dunning handler
+func NewDunningHandler() *DunningHandler {
+	return &DunningHandler{}
+}
+
+// DunningStepRequest represents one reminder in a sequence
+type DunningStepRequest struct {
+	DaysOverdue  int    `json:"daysOverdue" validate:"required,gt=0"`
+	Channel      string `json:"channel" validate:"required,oneof=SMS EMAIL"`
+	TemplateCode string `json:"templateCode" validate:"required,max=100"`
+}
+
+// DunningSequenceRequest represents the request body for creating or updating a dunning sequence.
+// Steps default to a gentle SMS at 7 days overdue and a firmer one at 30.
+type DunningSequenceRequest struct {
+	Name       string               `json:"name" validate:"required,max=255"`
+	MinOverdue float64              `json:"minOverdue" validate:"gte=0"`
+	IsDefault  bool                 `json:"isDefault"`
+	IsActive   *bool                `json:"isActive,omitempty"`
+	Steps      []DunningStepRequest `json:"steps,omitempty" validate:"dive"`
+}
+
+// DunningRunRequest represents a manual dunning run
+type DunningRunRequest struct {
+	AsOf string `json:"asOf,omitempty"` // YYYY-MM-DD, default today
+}
+
+// DunningOptOutRequest represents a customer's reminder preference
+type DunningOptOutRequest struct {
+	OptOut bool `json:"optOut"`
+}
+
+func (r *DunningSequenceRequest) steps() []domain.DunningStep {
+	if len(r.Steps) == 0 {
+		return domain.DefaultDunningSteps()
+	}
+	steps := make([]domain.DunningStep, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		steps = append(steps, domain.DunningStep{
+			DaysOverdue:  step.DaysOverdue,
+			Channel:      step.Channel,
+			TemplateCode: step.TemplateCode,
+		})
+	}
+	return steps
+}
+
+// CreateSequence godoc
+// @Summary Create a dunning sequence
+// @Description Define the reminders sent for overdue khata dues. The default sequence is the one dunning runs use.
+// @Tags dunning
+// @Accept json
+// @Produce json
+// @Param sequence body DunningSequenceRequest true "Dunning sequence"
+// @Success 201 {object} domain.DunningSequence
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/dunning/sequences [post]
+// @Security BearerAuth
+func (h *DunningHandler) CreateSequence(c echo.Context) error {
+	var req DunningSequenceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	sequence := domain.NewDunningSequence(tenantID, req.Name, req.MinOverdue, req.steps())
+	sequence.IsDefault = req.IsDefault
+	if req.IsActive != nil {
+		sequence.IsActive = *req.IsActive
+	}
+
+	if err := crm.DunningService.CreateSequence(c.Request().Context(), sequence); err != nil {
+		return dunningError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, sequence)
+}
+
+// ListSequences godoc
+// @Summary List dunning sequences
+// @Description Get the tenant's dunning sequences with their steps
+// @Tags dunning
+// @Produce json
+// @Success 200 {array} domain.DunningSequence
+// @Router /api/v1/dunning/sequences [get]
+// @Security BearerAuth
+func (h *DunningHandler) ListSequences(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	sequences, err := crm.DunningService.ListSequences(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, sequences)
+}
+
+// UpdateSequence godoc
+// @Summary Update a dunning sequence
+// @Description Change a sequence's settings and replace its steps. Customers part-way through keep their position.
+// @Tags dunning
+// @Accept json
+// @Produce json
+// @Param id path string true "Sequence ID"
+// @Param sequence body DunningSequenceRequest true "Dunning sequence"
+// @Success 200 {object} domain.DunningSequence
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dunning/sequences/{id} [put]
+// @Security BearerAuth
+func (h *DunningHandler) UpdateSequence(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid sequence ID"})
+	}
+
+	var req DunningSequenceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	sequence, err := crm.DunningService.GetSequence(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return dunningError(c, err)
+	}
+
+	sequence.Name = req.Name
+	sequence.MinOverdue = req.MinOverdue
+	sequence.IsDefault = req.IsDefault
+	if req.IsActive != nil {
+		sequence.IsActive = *req.IsActive
+	}
+	sequence.SetSteps(req.steps())
+
+	if err := crm.DunningService.UpdateSequence(c.Request().Context(), sequence); err != nil {
+		return dunningError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, sequence)
+}
+
+// DeleteSequence godoc
+// @Summary Delete a dunning sequence
+// @Description Delete a dunning sequence; customers part-way through it start over under the next default
+// @Tags dunning
+// @Param id path string true "Sequence ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dunning/sequences/{id} [delete]
+// @Security BearerAuth
+func (h *DunningHandler) DeleteSequence(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid sequence ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := crm.DunningService.DeleteSequence(c.Request().Context(), tenantID, id); err != nil {
+		return dunningError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Run godoc
+// @Summary Run dunning now
+// @Description Age every customer's dues and send the next reminder due under the default sequence. Runs also happen daily.
+// @Tags dunning
+// @Accept json
+// @Produce json
+// @Param run body DunningRunRequest false "Run options"
+// @Success 200 {object} domain.DunningRun
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/dunning/runs [post]
+// @Security BearerAuth
+func (h *DunningHandler) Run(c echo.Context) error {
+	var req DunningRunRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	asOf, err := parseAsOf(req.AsOf)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid asOf date"})
+	}
+
+	run, err := crm.DunningService.Run(c.Request().Context(), tenantID, asOf)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, run)
+}
+
+// ListNotices godoc
+// @Summary List dunning notices
+// @Description Get reminders sent, skipped or failed, newest first
+// @Tags dunning
+// @Produce json
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.DunningNotice
+// @Router /api/v1/dunning/notices [get]
+// @Security BearerAuth
+func (h *DunningHandler) ListNotices(c echo.Context) error {
+	return h.listNotices(c, nil)
+}
+
+// CustomerNotices godoc
+// @Summary List a customer's dunning notices
+// @Description Get the reminders sent to a customer, newest first
+// @Tags dunning
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.DunningNotice
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/customers/{id}/dunning-notices [get]
+// @Security BearerAuth
+func (h *DunningHandler) CustomerNotices(c echo.Context) error {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+	return h.listNotices(c, &customerID)
+}
+
+func (h *DunningHandler) listNotices(c echo.Context, customerID *uuid.UUID) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	notices, err := crm.DunningService.ListNotices(c.Request().Context(), tenantID, customerID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, notices)
+}
+
+// SetOptOut godoc
+// @Summary Set a customer's dunning opt-out
+// @Description Stop or resume dues reminders to a customer
+// @Tags dunning
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param optOut body DunningOptOutRequest true "Opt-out"
+// @Success 200 {object} domain.Customer
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/customers/{id}/dunning-opt-out [put]
+// @Security BearerAuth
+func (h *DunningHandler) SetOptOut(c echo.Context) error {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	var req DunningOptOutRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	customer, err := crm.DunningService.SetOptOut(c.Request().Context(), tenantID, customerID, req.OptOut)
+	if err != nil {
+		return dunningError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, customer)
+}
+
+// dunningError maps dunning domain errors to HTTP responses
+func dunningError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrDunningSequenceNotFound), errors.Is(err, domain.ErrCustomerNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/config"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/core/security"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// maxInboundEmailSize bounds a webhook body; the largest bill file allowed is 20 MB
+	maxInboundEmailSize = 30 << 20
+	// mailgunSignatureMaxAge rejects replayed Mailgun posts
+	mailgunSignatureMaxAge = 15 * time.Minute
+	// sesReplayWindow is how long an SNS message ID is remembered; SNS retries a
+	// delivery with the same ID
+	sesReplayWindow = 24 * time.Hour
+)
+
+// Replay claim scopes of the mail provider webhooks
+const (
+	mailgunReplayScope = "webhook:mailgun"
+	sesReplayScope     = "webhook:ses"
+)
+
+// InboundEmailHandler receives email from the mail provider's webhooks. These
+// routes are not tenant-scoped; the recipient address identifies the tenant.
+type InboundEmailHandler struct {
+	mailgunKey string
+	sesTopics  map[string]bool
+	sns        *security.SNSVerifier
+	replay     security.ReplayGuard
+	client     *http.Client
+}
+
+// NewInboundEmailHandler creates a new inbound email handler
+func NewInboundEmailHandler() *InboundEmailHandler {
+	h := &InboundEmailHandler{
+		sesTopics: make(map[string]bool),
+		sns:       security.NewSNSVerifier(),
+		replay:    security.NewPostgresReplayGuard(),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+	if config.GlobalConfig != nil {
+		h.mailgunKey = config.GlobalConfig.MailgunWebhookKey
+		for _, topic := range strings.Split(config.GlobalConfig.SESInboundTopics, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				h.sesTopics[topic] = true
+			}
+		}
+	}
+	return h
+}
+
+// InboundEmailResponse reports how many draft purchase bills an email produced
+type InboundEmailResponse struct {
+	Drafts int `json:"drafts"`
+}
+
+// Mailgun godoc
+// @Summary Receive email from Mailgun
+// @Description Webhook for a Mailgun inbound route that forwards bill inbox mail. The post is verified with the webhook signing key
+// @Description and each signed token is accepted once, since Mailgun signs the token and timestamp rather than the message.
+// @Tags bill-capture
+// @Accept mpfd
+// @Produce json
+// @Success 200 {object} InboundEmailResponse
+// @Failure 401 {object} map[string]string
+// @Failure 406 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/inbound/email/mailgun [post]
+func (h *InboundEmailHandler) Mailgun(c echo.Context) error {
+	if h.mailgunKey == "" {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "Mailgun inbound email is not configured"})
+	}
+
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxInboundEmailSize)
+	if err := c.Request().ParseMultipartForm(32 << 20); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid form"})
+	}
+
+	form := c.Request().MultipartForm
+	token := c.FormValue("token")
+	if !h.verifyMailgun(c.FormValue("timestamp"), token, c.FormValue("signature")) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid signature"})
+	}
+	// The signature does not cover the message, so a captured one must not carry a
+	// second, forged message within its window
+	ctx := c.Request().Context()
+	if err := h.claim(ctx, mailgunReplayScope, token, 2*mailgunSignatureMaxAge); err != nil {
+		return replayError(c, err)
+	}
+
+	email := &domain.InboundEmail{
+		MessageID:  strings.Trim(c.FormValue("Message-Id"), "<> "),
+		From:       c.FormValue("sender"),
+		Subject:    c.FormValue("subject"),
+		ReceivedAt: time.Now(),
+	}
+	if address, err := mail.ParseAddress(email.From); err == nil {
+		email.From = address.Address
+	}
+	for _, recipient := range strings.Split(c.FormValue("recipient"), ",") {
+		email.To = append(email.To, strings.TrimSpace(recipient))
+	}
+
+	for field, headers := range form.File {
+		if !strings.HasPrefix(field, "attachment-") {
+			continue
+		}
+		for _, header := range headers {
+			file, err := header.Open()
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid attachment"})
+			}
+			content, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid attachment"})
+			}
+			email.Attachments = append(email.Attachments, domain.InboundAttachment{
+				FileName:    header.Filename,
+				ContentType: header.Header.Get("Content-Type"),
+				Content:     content,
+			})
+		}
+	}
+
+	drafts, err := crm.BillCaptureService.Receive(ctx, email)
+	switch {
+	case errors.Is(err, domain.ErrBillInboxNotFound):
+		// 406 tells Mailgun not to retry
+		return c.JSON(http.StatusNotAcceptable, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrDuplicateInboundEmail):
+		return c.JSON(http.StatusOK, InboundEmailResponse{})
+	case err != nil:
+		// Mailgun retries with the same token
+		_ = h.replay.Release(ctx, mailgunReplayScope, token)
+		logger.Log.Error(fmt.Sprintf("Failed to capture bill email %s: %v", email.MessageID, err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to process email"})
+	}
+
+	return c.JSON(http.StatusOK, InboundEmailResponse{Drafts: len(drafts)})
+}
+
+// verifyMailgun checks the HMAC-SHA256 of timestamp+token against the signature
+func (h *InboundEmailHandler) verifyMailgun(timestamp, token, signature string) bool {
+	if token == "" {
+		return false
+	}
+	if _, err := security.CheckTimestamp(timestamp, mailgunSignatureMaxAge, time.Now()); err != nil {
+		return false
+	}
+	return security.VerifySignature([]string{h.mailgunKey}, []byte(timestamp+token), signature, security.EncodingHex) == nil
+}
+
+// claim accepts a delivery key once within ttl
+func (h *InboundEmailHandler) claim(ctx context.Context, scope, key string, ttl time.Duration) error {
+	ok, err := h.replay.Claim(ctx, scope, key, ttl)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return security.ErrReplayedRequest
+	}
+	return nil
+}
+
+// replayError maps a failed replay claim to an HTTP response
+func replayError(c echo.Context, err error) error {
+	if errors.Is(err, security.ErrReplayedRequest) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check for a replayed delivery"})
+}
+
+// sesNotification is an SES receipt rule SNS action notification
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	Content          string `json:"content"` // Raw message, Base64 or UTF-8 per the action's encoding
+	Mail             struct {
+		MessageID   string   `json:"messageId"`
+		Destination []string `json:"destination"`
+	} `json:"mail"`
+}
+
+// SES godoc
+// @Summary Receive email from Amazon SES
+// @Description Webhook for an SNS topic fed by an SES receipt rule SNS action. Messages must carry a valid SNS signature
+// @Description and come from a topic in SES_INBOUND_TOPIC_ARNS; each message ID is accepted once. SNS carries messages up to 150 KB.
+// @Tags bill-capture
+// @Accept json
+// @Produce json
+// @Success 200 {object} InboundEmailResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/inbound/email/ses [post]
+func (h *InboundEmailHandler) SES(c echo.Context) error {
+	if len(h.sesTopics) == 0 {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "SES inbound email is not configured"})
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, maxInboundEmailSize))
+	if err != nil {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "Message too large"})
+	}
+
+	// SNS posts JSON as text/plain, so the body is decoded here rather than bound
+	var envelope security.SNSMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid SNS message"})
+	}
+	// Any AWS account can sign a message from its own topic, so the topic is checked too
+	if !h.sesTopics[envelope.TopicArn] {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Unknown SNS topic"})
+	}
+	ctx := c.Request().Context()
+	if err := h.sns.Verify(ctx, &envelope); err != nil {
+		logger.Log.Warn(fmt.Sprintf("Rejected SNS message from %s: %v", c.RealIP(), err))
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid signature"})
+	}
+
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		if err := h.confirmSubscription(envelope.SubscribeURL); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to confirm SNS subscription to %s: %v", envelope.TopicArn, err))
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Subscription could not be confirmed"})
+		}
+		logger.Log.Info("Confirmed SNS subscription to " + envelope.TopicArn)
+		return c.JSON(http.StatusOK, InboundEmailResponse{})
+	case "Notification":
+	default:
+		return c.JSON(http.StatusOK, InboundEmailResponse{})
+	}
+
+	if err := h.claim(ctx, sesReplayScope, envelope.MessageID, sesReplayWindow); err != nil {
+		if errors.Is(err, security.ErrReplayedRequest) {
+			// Already taken; acknowledge so SNS stops redelivering
+			return c.JSON(http.StatusOK, InboundEmailResponse{})
+		}
+		return replayError(c, err)
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid SES notification"})
+	}
+	if notification.NotificationType != "Received" || notification.Content == "" {
+		return c.JSON(http.StatusOK, InboundEmailResponse{})
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(notification.Content)
+	if err != nil {
+		raw = []byte(notification.Content)
+	}
+	email, err := domain.ParseRawEmail(raw)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	// The envelope recipients include Bcc, which the headers do not
+	email.To = append(notification.Mail.Destination, email.To...)
+	if email.MessageID == "" {
+		email.MessageID = notification.Mail.MessageID
+	}
+
+	drafts, err := crm.BillCaptureService.Receive(ctx, email)
+	switch {
+	case errors.Is(err, domain.ErrBillInboxNotFound), errors.Is(err, domain.ErrDuplicateInboundEmail):
+		// Acknowledge so SNS does not retry
+		return c.JSON(http.StatusOK, InboundEmailResponse{})
+	case err != nil:
+		// SNS retries with the same message ID
+		_ = h.replay.Release(ctx, sesReplayScope, envelope.MessageID)
+		logger.Log.Error(fmt.Sprintf("Failed to capture bill email %s: %v", email.MessageID, err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to process email"})
+	}
+
+	return c.JSON(http.StatusOK, InboundEmailResponse{Drafts: len(drafts)})
+}
+
+// confirmSubscription visits the SNS subscribe URL, which must be on amazonaws.com
+func (h *InboundEmailHandler) confirmSubscription(subscribeURL string) error {
+	target, err := url.Parse(subscribeURL)
+	if err != nil || target.Scheme != "https" || !strings.HasSuffix(target.Hostname(), ".amazonaws.com") {
+		return fmt.Errorf("unexpected subscribe URL %q", subscribeURL)
+	}
+
+	resp, err := h.client.Get(target.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("subscribe URL returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// KhataHandler handles HTTP requests for the customer credit ledger and dues
+type KhataHandler struct{}
+
+// NewKhataHandler creates a new khata handler
+func NewKhataHandler() *KhataHandler {
+	return &KhataHandler{}
+}
+
+// KhataChargeRequest represents goods given to a customer on credit
+type KhataChargeRequest struct {
+	Amount        float64    `json:"amount" validate:"required,gt=0"`
+	EntryDate     *time.Time `json:"entryDate,omitempty"`                             // Defaults to now
+	DueDate       *time.Time `json:"dueDate,omitempty"`                               // Defaults to the customer's payment terms
+	CreditDays    *int       `json:"creditDays,omitempty" validate:"omitempty,gte=0"` // Instead of dueDate: days of credit from the entry date
+	ReferenceType *string    `json:"referenceType,omitempty" validate:"omitempty,max=50"`
+	ReferenceID   *string    `json:"referenceId,omitempty"`
+	Note          *string    `json:"note,omitempty"`
+}
+
+// KhataChargeResponse is the recorded charge; installment terms split it, listing the later installments
+type KhataChargeResponse struct {
+	*domain.KhataEntry
+	Installments []*domain.KhataEntry `json:"installments,omitempty"`
+}
+
+// KhataPaymentRequest represents money received against a customer's dues
+type KhataPaymentRequest struct {
+	Amount        float64    `json:"amount" validate:"required,gt=0"`
+	EntryDate     *time.Time `json:"entryDate,omitempty"` // Defaults to now
+	ReferenceType *string    `json:"referenceType,omitempty" validate:"omitempty,max=50"`
+	ReferenceID   *string    `json:"referenceId,omitempty"`
+	Note          *string    `json:"note,omitempty"`
+}
+
+// RecordCharge godoc
+// @Summary Record a credit charge
+// @Description Add goods given on credit to a customer's khata, due on the given date, after creditDays, or on the
+// @Description customer's payment terms. Installment terms record one charge per installment, listed under installments.
+// @Description A due date on the tenant's weekend or a holiday moves to the next working day.
+// @Tags khata
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param charge body KhataChargeRequest true "Charge"
+// @Success 201 {object} KhataChargeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/customers/{id}/khata [post]
+// @Security BearerAuth
+func (h *KhataHandler) RecordCharge(c echo.Context) error {
+	var req KhataChargeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	dueDate := req.DueDate
+	if dueDate == nil && req.CreditDays != nil {
+		due := time.Now()
+		if req.EntryDate != nil {
+			due = *req.EntryDate
+		}
+		due = due.AddDate(0, 0, *req.CreditDays)
+		dueDate = &due
+	}
+
+	entry := newKhataEntry(c, tenantID, customerID, domain.KhataCharge, req.Amount, req.EntryDate, dueDate)
+	entry.ReferenceType = req.ReferenceType
+	entry.Note = req.Note
+	if req.ReferenceID != nil {
+		referenceID, err := uuid.Parse(*req.ReferenceID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid reference ID"})
+		}
+		entry.ReferenceID = &referenceID
+	}
+
+	entries, err := crm.KhataService.RecordCharge(c.Request().Context(), entry)
+	if err != nil {
+		return khataError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, KhataChargeResponse{KhataEntry: entries[0], Installments: entries[1:]})
+}
+
+// RecordPayment godoc
+// @Summary Record a khata payment
+// @Description Record money received against a customer's dues. Payments settle the oldest charges first; once nothing is overdue, dunning reminders stop.
+// @Tags khata
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param payment body KhataPaymentRequest true "Payment"
+// @Success 201 {object} domain.KhataEntry
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/customers/{id}/khata/payments [post]
+// @Security BearerAuth
+func (h *KhataHandler) RecordPayment(c echo.Context) error {
+	var req KhataPaymentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	entry := newKhataEntry(c, tenantID, customerID, domain.KhataPayment, req.Amount, req.EntryDate, nil)
+	entry.ReferenceType = req.ReferenceType
+	entry.Note = req.Note
+	if req.ReferenceID != nil {
+		referenceID, err := uuid.Parse(*req.ReferenceID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid reference ID"})
+		}
+		entry.ReferenceID = &referenceID
+	}
+
+	if err := crm.KhataService.RecordPayment(c.Request().Context(), entry); err != nil {
+		return khataError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, entry)
+}
+
+// newKhataEntry creates an entry dated now unless the request gives dates; without a due date the service applies payment terms
+func newKhataEntry(c echo.Context, tenantID, customerID uuid.UUID, kind domain.KhataEntryKind, amount float64, entryDate, dueDate *time.Time) *domain.KhataEntry {
+	date := time.Now()
+	if entryDate != nil {
+		date = *entryDate
+	}
+	var due time.Time
+	if dueDate != nil {
+		due = *dueDate
+	}
+
+	entry := domain.NewKhataEntry(tenantID, customerID, kind, amount, date, due)
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		entry.CreatedBy = &userID
+	}
+	return entry
+}
+
+// ListEntries godoc
+// @Summary List a customer's khata
+// @Description Get a customer's credit charges and payments, newest first
+// @Tags khata
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.KhataEntry
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/customers/{id}/khata [get]
+// @Security BearerAuth
+func (h *KhataHandler) ListEntries(c echo.Context) error {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := crm.KhataService.ListEntries(c.Request().Context(), tenantID, customerID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+// CustomerDues godoc
+// @Summary Get a customer's dues
+// @Description Get what a customer owes, how much is overdue and its aging, for display when billing the customer
+// @Tags khata
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param asOf query string false "Aging date (YYYY-MM-DD), default today"
+// @Success 200 {object} domain.CustomerDues
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/customers/{id}/dues [get]
+// @Security BearerAuth
+func (h *KhataHandler) CustomerDues(c echo.Context) error {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	asOf, err := parseAsOf(c.QueryParam("asOf"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid asOf date"})
+	}
+
+	dues, err := crm.KhataService.CustomerDues(c.Request().Context(), tenantID, customerID, asOf)
+	if err != nil {
+		return khataError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dues)
+}
+
+// AgingReport godoc
+// @Summary Khata aging report
+// @Description Get every customer's dues in current, 1-30, 31-60, 61-90 and over-90-days-overdue buckets
+// @Tags khata
+// @Produce json
+// @Param asOf query string false "Aging date (YYYY-MM-DD), default today"
+// @Success 200 {object} domain.AgingReport
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/khata/aging [get]
+// @Security BearerAuth
+func (h *KhataHandler) AgingReport(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	asOf, err := parseAsOf(c.QueryParam("asOf"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid asOf date"})
+	}
+
+	report, err := crm.KhataService.AgingReport(c.Request().Context(), tenantID, asOf)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// parseAsOf parses an optional YYYY-MM-DD aging date, defaulting to now
+func parseAsOf(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// khataError maps khata domain errors to HTTP responses
+func khataError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrCustomerNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
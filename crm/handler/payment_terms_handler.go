@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// PaymentTermsHandler handles HTTP requests for payment terms due dates
+type PaymentTermsHandler struct{}
+
+// NewPaymentTermsHandler creates a new payment terms handler
+func NewPaymentTermsHandler() *PaymentTermsHandler {
+	return &PaymentTermsHandler{}
+}
+
+// PaymentScheduleRequest asks when an amount falls due, on given terms or a customer's or supplier's defaults
+type PaymentScheduleRequest struct {
+	Terms      *domain.PaymentTerms `json:"terms,omitempty"`
+	CustomerID *uuid.UUID           `json:"customerId,omitempty"` // Used when terms are not given
+	SupplierID *uuid.UUID           `json:"supplierId,omitempty"` // Used when terms and customerId are not given
+	Date       string               `json:"date,omitempty"`       // Document date in AD (YYYY-MM-DD); defaults to today
+	DateBS     string               `json:"dateBs,omitempty"`     // Document date in BS (YYYY-MM-DD); used when date is empty
+	Amount     float64              `json:"amount"`
+}
+
+// PaymentScheduleResponse is when each part of the amount falls due
+type PaymentScheduleResponse struct {
+	Terms       *domain.PaymentTerms    `json:"terms"`
+	Description string                  `json:"description"` // e.g. "Net 30", for printing on documents
+	Schedule    []domain.DueInstallment `json:"schedule"`
+}
+
+// Schedule godoc
+// @Summary Compute due dates
+// @Description Split an amount into installments under payment terms (net days, end of BS or AD month, or installments),
+// @Description or under a customer's or supplier's default terms. Due dates are given in AD and BS and move off the
+// @Description tenant's weekends and holidays. Terms are set per customer or supplier in the payment_terms custom attribute.
+// @Tags payment-terms
+// @Accept json
+// @Produce json
+// @Param request body PaymentScheduleRequest true "Terms, date and amount"
+// @Success 200 {object} PaymentScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/payment-terms/schedule [post]
+// @Security BearerAuth
+func (h *PaymentTermsHandler) Schedule(c echo.Context) error {
+	var req PaymentScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	date := time.Now()
+	switch {
+	case req.Date != "":
+		parsed, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid date, expected YYYY-MM-DD"})
+		}
+		date = parsed
+	case req.DateBS != "":
+		bs, err := utils.ParseNepaliDate(req.DateBS)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid BS date, expected YYYY-MM-DD"})
+		}
+		date = utils.BSToAD(bs)
+	}
+
+	terms := req.Terms
+	var err error
+	switch {
+	case terms != nil:
+	case req.CustomerID != nil:
+		terms, err = crm.PaymentTermsService.CustomerTerms(c.Request().Context(), tenantID, *req.CustomerID)
+	case req.SupplierID != nil:
+		terms, err = crm.PaymentTermsService.SupplierTerms(c.Request().Context(), tenantID, *req.SupplierID)
+	default:
+		terms = domain.DueOnReceipt()
+	}
+	if err != nil {
+		return paymentTermsError(c, err)
+	}
+
+	schedule, err := crm.PaymentTermsService.Schedule(c.Request().Context(), tenantID, terms, date, req.Amount)
+	if err != nil {
+		return paymentTermsError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, PaymentScheduleResponse{Terms: terms, Description: terms.String(), Schedule: schedule})
+}
+
+// paymentTermsError maps payment terms errors to HTTP responses
+func paymentTermsError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPaymentTerms):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrCustomerNotFound), errors.Is(err, domain.ErrSupplierNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// PurchaseReturnHandler handles HTTP requests for goods returned to suppliers
+type PurchaseReturnHandler struct{}
+
+// NewPurchaseReturnHandler creates a new purchase return handler
+func NewPurchaseReturnHandler() *PurchaseReturnHandler {
+	return &PurchaseReturnHandler{}
+}
+
+// PurchaseReturnRequest returns goods against a purchase receipt
+type PurchaseReturnRequest struct {
+	ReceiptType string                      `json:"receiptType,omitempty"` // purchase_bill (default), or a type registered by another module
+	ReceiptID   uuid.UUID                   `json:"receiptId" validate:"required"`
+	ReturnedAt  string                      `json:"returnedAt,omitempty"` // YYYY-MM-DD; defaults to today
+	Note        *string                     `json:"note,omitempty"`
+	Lines       []PurchaseReturnLineRequest `json:"lines" validate:"required,min=1"`
+}
+
+// PurchaseReturnLineRequest is a product sent back. Against receipts with lines, name
+// the receipt line and the product and cost are taken from it.
+type PurchaseReturnLineRequest struct {
+	ReceiptLineID *uuid.UUID          `json:"receiptLineId,omitempty"`
+	ProductID     uuid.UUID           `json:"productId"`
+	Quantity      float64             `json:"quantity" validate:"required,gt=0"`
+	UnitCost      float64             `json:"unitCost"`
+	Reason        domain.ReturnReason `json:"reason" validate:"required"` // damaged, expired, wrong_item, defective, excess or other
+	Note          *string             `json:"note,omitempty"`
+}
+
+// Create godoc
+// @Summary Return goods to a supplier
+// @Description Record an RMA against a purchase receipt with a reason per line. Quantities cannot exceed what was received
+// @Description less earlier returns. Against a payable receipt the return carries a debit note (DN-) for its total plus the
+// @Description receipt's VAT rate, posted to the supplier's payable; the goods are taken out of stock.
+// @Tags purchase-returns
+// @Accept json
+// @Produce json
+// @Param return body PurchaseReturnRequest true "Purchase return"
+// @Success 201 {object} domain.PurchaseReturn
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/purchase-returns [post]
+// @Security BearerAuth
+func (h *PurchaseReturnHandler) Create(c echo.Context) error {
+	var req PurchaseReturnRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	receiptType := req.ReceiptType
+	if receiptType == "" {
+		receiptType = domain.ReceiptTypePurchaseBill
+	}
+
+	returnedAt := time.Now()
+	if req.ReturnedAt != "" {
+		var err error
+		if returnedAt, err = time.ParseInLocation("2006-01-02", req.ReturnedAt, time.Local); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid returnedAt, expected YYYY-MM-DD"})
+		}
+	}
+
+	ret := domain.NewPurchaseReturn(tenantID, receiptType, req.ReceiptID, returnedAt)
+	ret.Note = req.Note
+	ret.CreatedBy = optionalUserID(c)
+	for _, line := range req.Lines {
+		if err := ret.AddLine(line.ReceiptLineID, line.ProductID, line.Quantity, line.UnitCost, line.Reason, line.Note); err != nil {
+			return purchaseReturnError(c, err)
+		}
+	}
+
+	if err := crm.PurchaseReturnService.Create(c.Request().Context(), ret); err != nil {
+		return purchaseReturnError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, ret)
+}
+
+// List godoc
+// @Summary List purchase returns
+// @Description Get returns to suppliers, newest first, optionally for a supplier, a receipt or a reason
+// @Tags purchase-returns
+// @Produce json
+// @Param supplierId query string false "Supplier ID"
+// @Param receiptId query string false "Receipt ID"
+// @Param reason query string false "Reason"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.PurchaseReturn
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/purchase-returns [get]
+// @Security BearerAuth
+func (h *PurchaseReturnHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	filter := domain.PurchaseReturnFilter{}
+	filter.Limit, filter.Offset = warrantyPage(c)
+	if value := c.QueryParam("supplierId"); value != "" {
+		supplierID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid supplier ID"})
+		}
+		filter.SupplierID = &supplierID
+	}
+	if value := c.QueryParam("receiptId"); value != "" {
+		receiptID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid receipt ID"})
+		}
+		filter.ReceiptID = &receiptID
+	}
+	if value := c.QueryParam("reason"); value != "" {
+		reason := domain.ReturnReason(value)
+		if !reason.Valid() {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid reason"})
+		}
+		filter.Reason = &reason
+	}
+
+	returns, err := crm.PurchaseReturnService.List(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return purchaseReturnError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, returns)
+}
+
+// Get godoc
+// @Summary Get a purchase return
+// @Description Get a return to a supplier with its lines and reasons
+// @Tags purchase-returns
+// @Produce json
+// @Param id path string true "Purchase return ID"
+// @Success 200 {object} domain.PurchaseReturn
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/purchase-returns/{id} [get]
+// @Security BearerAuth
+func (h *PurchaseReturnHandler) Get(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid purchase return ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	ret, err := crm.PurchaseReturnService.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return purchaseReturnError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+// QualityReport godoc
+// @Summary Supplier quality scores
+// @Description Score suppliers by the share of what was purchased from them that came back damaged, expired, wrong or
+// @Description defective (score = 100 - returnRate), with returns broken down by reason. Worst suppliers first; suppliers
+// @Description with returns but no purchases in the period have no score and are listed last.
+// @Tags purchase-returns
+// @Produce json
+// @Param from query string false "From date (YYYY-MM-DD); defaults to 30 days before to"
+// @Param to query string false "To date (YYYY-MM-DD); defaults to today"
+// @Success 200 {array} domain.SupplierQuality
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/purchase-returns/quality [get]
+// @Security BearerAuth
+func (h *PurchaseReturnHandler) QualityReport(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	report, err := crm.PurchaseReturnService.QualityReport(c.Request().Context(), tenantID, from, to)
+	if err != nil {
+		return purchaseReturnError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// purchaseReturnError maps purchase return domain errors to HTTP responses
+func purchaseReturnError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrPurchaseReturnNotFound), errors.Is(err, domain.ErrPurchaseReceiptNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrReturnExceedsReceipt):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidPurchaseReturn), errors.Is(err, domain.ErrUnknownReceiptType):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
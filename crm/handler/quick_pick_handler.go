@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// PinCustomerRequest represents the request body for pinning a customer
+type PinCustomerRequest struct {
+	Position int `json:"position" validate:"gte=0"`
+}
+
+// CustomerQuickPickResponse represents a customer in the POS quick-pick list
+type CustomerQuickPickResponse struct {
+	Customer     *CustomerResponse `json:"customer"`
+	IsFavorite   bool              `json:"isFavorite"`
+	Position     int               `json:"position"`
+	ViewCount    int               `json:"viewCount"`
+	LastViewedAt *string           `json:"lastViewedAt,omitempty"`
+}
+
+// ListQuickPicks godoc
+// @Summary List quick-pick customers
+// @Description Get the current user's pinned and recently viewed customers
+// @Tags customers
+// @Produce json
+// @Success 200 {array} CustomerQuickPickResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/customers/quick-picks [get]
+// @Security BearerAuth
+func (h *CustomerHandler) ListQuickPicks(c echo.Context) error {
+	tenantID, userID, ok := quickPickIdentity(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant or user not found"})
+	}
+
+	picks, err := crm.QuickPickService.List(c.Request().Context(), tenantID, userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	responses := make([]*CustomerQuickPickResponse, len(picks))
+	for i, pick := range picks {
+		responses[i] = toCustomerQuickPickResponse(pick)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+// Pin godoc
+// @Summary Pin customer
+// @Description Pin a customer to the current user's favorites
+// @Tags customers
+// @Accept json
+// @Param id path string true "Customer ID"
+// @Param request body PinCustomerRequest false "List position"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/customers/{id}/favorite [post]
+// @Security BearerAuth
+func (h *CustomerHandler) Pin(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	var req PinCustomerRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tenantID, userID, ok := quickPickIdentity(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant or user not found"})
+	}
+
+	if err := crm.QuickPickService.Pin(c.Request().Context(), tenantID, userID, id, req.Position); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Customer not found"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Unpin godoc
+// @Summary Unpin customer
+// @Description Remove a customer from the current user's favorites
+// @Tags customers
+// @Param id path string true "Customer ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/customers/{id}/favorite [delete]
+// @Security BearerAuth
+func (h *CustomerHandler) Unpin(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	tenantID, userID, ok := quickPickIdentity(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant or user not found"})
+	}
+
+	if err := crm.QuickPickService.Unpin(c.Request().Context(), tenantID, userID, id); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// quickPickIdentity returns the tenant and user the quick-pick list belongs to
+func quickPickIdentity(c echo.Context) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tenantID, userID, true
+}
+
+// toCustomerQuickPickResponse converts domain.CustomerQuickPick to CustomerQuickPickResponse
+func toCustomerQuickPickResponse(pick *domain.CustomerQuickPick) *CustomerQuickPickResponse {
+	resp := &CustomerQuickPickResponse{
+		Customer:   toCustomerResponse(pick.Customer),
+		IsFavorite: pick.IsFavorite,
+		Position:   pick.Position,
+		ViewCount:  pick.ViewCount,
+	}
+
+	if pick.LastViewedAt != nil {
+		lastViewed := pick.LastViewedAt.Format("2006-01-02T15:04:05Z07:00")
+		resp.LastViewedAt = &lastViewed
+	}
+
+	return resp
+}
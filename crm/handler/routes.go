@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"github.com/aceextension/core/jobs"
 	"github.com/aceextension/core/middleware"
 	"github.com/labstack/echo/v4"
 )
@@ -10,6 +11,30 @@ func RegisterRoutes(e *echo.Echo) {
 	// Create handlers
 	customerHandler := NewCustomerHandler()
 	supplierHandler := NewSupplierHandler()
+	containerHandler := NewContainerHandler()
+	consignmentHandler := NewConsignmentHandler()
+	purchaseReturnHandler := NewPurchaseReturnHandler()
+	supplierScorecardHandler := NewSupplierScorecardHandler()
+	khataHandler := NewKhataHandler()
+	dunningHandler := NewDunningHandler()
+	writeOffHandler := NewWriteOffHandler()
+	addressHandler := NewAddressHandler()
+	deliveryHandler := NewDeliveryHandler()
+	vehicleHandler := NewVehicleHandler()
+	billCaptureHandler := NewBillCaptureHandler()
+	inboundEmailHandler := NewInboundEmailHandler()
+	approvalHandler := NewApprovalHandler()
+	taxIDHandler := NewTaxIDHandler()
+	customerOTPHandler := NewCustomerOTPHandler()
+	paymentTermsHandler := NewPaymentTermsHandler()
+	warrantyHandler := NewWarrantyHandler()
+
+	// Mail provider webhooks; the recipient address identifies the tenant
+	inbound := e.Group("/api/v1/inbound/email")
+	{
+		inbound.POST("/mailgun", inboundEmailHandler.Mailgun)
+		inbound.POST("/ses", inboundEmailHandler.SES)
+	}
 
 	// API v1 group
 	v1 := e.Group("/api/v1")
@@ -23,19 +48,219 @@ func RegisterRoutes(e *echo.Echo) {
 		customers.POST("", customerHandler.Create)
 		customers.GET("", customerHandler.List)
 		customers.GET("/search", customerHandler.Search)
+		customers.GET("/export", customerHandler.Export, jobs.Async)
+		customers.POST("/import", customerHandler.Import)
+		customers.GET("/quick-picks", customerHandler.ListQuickPicks)
+		customers.POST("/:id/favorite", customerHandler.Pin)
+		customers.DELETE("/:id/favorite", customerHandler.Unpin)
+		customers.GET("/:id/containers", containerHandler.CustomerBalances)
+		customers.GET("/:id/containers/movements", containerHandler.ListMovements)
+		customers.POST("/:id/containers/issue", containerHandler.Issue)
+		customers.POST("/:id/containers/return", containerHandler.Return)
+		customers.GET("/:id/dues", khataHandler.CustomerDues)
+		customers.GET("/:id/khata", khataHandler.ListEntries)
+		customers.POST("/:id/khata", khataHandler.RecordCharge)
+		customers.POST("/:id/khata/payments", khataHandler.RecordPayment)
+		customers.PUT("/:id/dunning-opt-out", dunningHandler.SetOptOut)
+		customers.GET("/:id/dunning-notices", dunningHandler.CustomerNotices)
+		customers.POST("/:id/write-offs", writeOffHandler.Request)
+		customers.POST("/:id/verify-pan", taxIDHandler.VerifyCustomer)
+		customers.POST("/:id/otp", customerOTPHandler.Send)
 		customers.GET("/:id", customerHandler.GetByID)
 		customers.PUT("/:id", customerHandler.Update)
 		customers.DELETE("/:id", customerHandler.Delete)
 	}
 
+	// Returnable container routes
+	containers := v1.Group("/containers")
+	{
+		containers.POST("/types", containerHandler.CreateType)
+		containers.GET("/types", containerHandler.ListTypes)
+		containers.PUT("/types/:id", containerHandler.UpdateType)
+		containers.GET("/balances", containerHandler.BalanceReport)
+	}
+
 	// Supplier routes
 	suppliers := v1.Group("/suppliers")
 	{
 		suppliers.POST("", supplierHandler.Create)
 		suppliers.GET("", supplierHandler.List)
 		suppliers.GET("/search", supplierHandler.Search)
+		suppliers.GET("/scorecards", supplierScorecardHandler.Ranking)
+		suppliers.POST("/:id/verify-pan", taxIDHandler.VerifySupplier)
+		suppliers.GET("/:id/scorecard", supplierScorecardHandler.Scorecard)
 		suppliers.GET("/:id", supplierHandler.GetByID)
 		suppliers.PUT("/:id", supplierHandler.Update)
 		suppliers.DELETE("/:id", supplierHandler.Delete)
 	}
+
+	// Customer verification code routes
+	customerOTPs := v1.Group("/customer-otps")
+	{
+		customerOTPs.GET("/:id", customerOTPHandler.Get)
+		customerOTPs.POST("/:id/verify", customerOTPHandler.Verify)
+	}
+
+	// PAN/VAT validation routes
+	taxIDs := v1.Group("/tax-ids")
+	{
+		taxIDs.GET("/:pan", taxIDHandler.Check)
+	}
+
+	// Payment terms routes
+	v1.POST("/payment-terms/schedule", paymentTermsHandler.Schedule)
+
+	// Warranty routes
+	warranties := v1.Group("/warranties")
+	{
+		warranties.POST("", warrantyHandler.RegisterSale)
+		warranties.GET("", warrantyHandler.ListWarranties)
+		warranties.GET("/check", warrantyHandler.Check)
+		warranties.POST("/invoices/:id/void", warrantyHandler.VoidInvoice)
+		warranties.GET("/:id", warrantyHandler.GetWarranty)
+	}
+
+	warrantyClaims := v1.Group("/warranty-claims")
+	{
+		warrantyClaims.POST("", warrantyHandler.OpenClaim)
+		warrantyClaims.GET("", warrantyHandler.ListClaims)
+		warrantyClaims.GET("/:id", warrantyHandler.GetClaim)
+		warrantyClaims.POST("/:id/send", warrantyHandler.SendToService)
+		warrantyClaims.POST("/:id/resolve", warrantyHandler.Resolve)
+		warrantyClaims.POST("/:id/close", warrantyHandler.CloseClaim)
+		warrantyClaims.POST("/:id/cancel", warrantyHandler.CancelClaim)
+	}
+
+	// Supplier consignment stock routes
+	consignments := v1.Group("/consignments")
+	{
+		consignments.POST("/receipts", consignmentHandler.CreateReceipt)
+		consignments.GET("/receipts", consignmentHandler.ListReceipts)
+		consignments.GET("/receipts/:id", consignmentHandler.GetReceipt)
+		consignments.POST("/lines/:id/return", consignmentHandler.ReturnLine)
+		consignments.POST("/sales", consignmentHandler.RecordSale)
+		consignments.GET("/bills", consignmentHandler.ListBills)
+		consignments.GET("/bills/:id", consignmentHandler.GetBill)
+		consignments.GET("/stock", consignmentHandler.StockReport)
+	}
+
+	// Purchase return (RMA) routes
+	purchaseReturns := v1.Group("/purchase-returns")
+	{
+		purchaseReturns.POST("", purchaseReturnHandler.Create)
+		purchaseReturns.GET("", purchaseReturnHandler.List)
+		purchaseReturns.GET("/quality", purchaseReturnHandler.QualityReport)
+		purchaseReturns.GET("/:id", purchaseReturnHandler.Get)
+	}
+
+	// Customer credit (khata) routes
+	khata := v1.Group("/khata")
+	{
+		khata.GET("/aging", khataHandler.AgingReport)
+	}
+
+	// Dues reminder routes
+	dunning := v1.Group("/dunning")
+	{
+		dunning.POST("/sequences", dunningHandler.CreateSequence)
+		dunning.GET("/sequences", dunningHandler.ListSequences)
+		dunning.PUT("/sequences/:id", dunningHandler.UpdateSequence)
+		dunning.DELETE("/sequences/:id", dunningHandler.DeleteSequence)
+		dunning.POST("/runs", dunningHandler.Run)
+		dunning.GET("/notices", dunningHandler.ListNotices)
+	}
+
+	// Bad-debt write-off routes
+	writeOffs := v1.Group("/write-offs")
+	{
+		writeOffs.GET("", writeOffHandler.List)
+		writeOffs.GET("/report", writeOffHandler.YearReport)
+		writeOffs.GET("/:id", writeOffHandler.GetByID)
+		writeOffs.POST("/:id/approve", writeOffHandler.Approve)
+		writeOffs.POST("/:id/reject", writeOffHandler.Reject)
+		writeOffs.POST("/:id/cancel", writeOffHandler.Cancel)
+	}
+
+	// Approval limit routes
+	approvalLimits := v1.Group("/approval-limits")
+	{
+		approvalLimits.GET("", approvalHandler.ListLimits)
+		approvalLimits.PUT("", approvalHandler.SetLimit)
+		approvalLimits.GET("/me", approvalHandler.MyLimit)
+		approvalLimits.DELETE("/:id", approvalHandler.DeleteLimit)
+	}
+
+	// Approval request routes
+	approvals := v1.Group("/approvals")
+	{
+		approvals.GET("", approvalHandler.ListRequests)
+		approvals.GET("/:id", approvalHandler.GetRequest)
+		approvals.POST("/:id/approve", approvalHandler.Approve)
+		approvals.POST("/:id/reject", approvalHandler.Reject)
+		approvals.POST("/:id/cancel", approvalHandler.Cancel)
+	}
+
+	// Distributor delivery route routes
+	routes := v1.Group("/routes")
+	{
+		routes.POST("", deliveryHandler.CreateRoute)
+		routes.GET("", deliveryHandler.ListRoutes)
+		routes.GET("/profitability", vehicleHandler.RouteProfitability)
+		routes.GET("/:id", deliveryHandler.GetRoute)
+		routes.PUT("/:id", deliveryHandler.UpdateRoute)
+		routes.GET("/:id/customers", deliveryHandler.ListStops)
+		routes.POST("/:id/customers", deliveryHandler.AssignCustomer)
+		routes.DELETE("/:id/customers/:customerId", deliveryHandler.RemoveCustomer)
+		routes.GET("/:id/sheet", deliveryHandler.Sheet)
+	}
+
+	deliveries := v1.Group("/deliveries")
+	{
+		deliveries.POST("", deliveryHandler.Queue)
+		deliveries.GET("/:id", deliveryHandler.GetDelivery)
+		deliveries.POST("/:id/deliver", deliveryHandler.Deliver)
+		deliveries.POST("/:id/fail", deliveryHandler.Fail)
+	}
+
+	// Vehicle and trip routes
+	vehicles := v1.Group("/vehicles")
+	{
+		vehicles.POST("", vehicleHandler.CreateVehicle)
+		vehicles.GET("", vehicleHandler.ListVehicles)
+		vehicles.GET("/:id", vehicleHandler.GetVehicle)
+		vehicles.PUT("/:id", vehicleHandler.UpdateVehicle)
+		vehicles.GET("/:id/expenses", vehicleHandler.ListExpenses)
+		vehicles.POST("/:id/expenses", vehicleHandler.RecordExpense)
+	}
+
+	trips := v1.Group("/trips")
+	{
+		trips.POST("", vehicleHandler.StartTrip)
+		trips.GET("", vehicleHandler.ListTrips)
+		trips.GET("/:id", vehicleHandler.GetTrip)
+		trips.POST("/:id/close", vehicleHandler.CloseTrip)
+	}
+
+	// Supplier bill capture routes
+	billInbox := v1.Group("/bill-inbox")
+	{
+		billInbox.GET("", billCaptureHandler.GetInbox)
+		billInbox.POST("/rotate", billCaptureHandler.RotateInbox)
+		billInbox.GET("/emails", billCaptureHandler.ListEmails)
+	}
+
+	billDrafts := v1.Group("/bill-drafts")
+	{
+		billDrafts.GET("", billCaptureHandler.ListDrafts)
+		billDrafts.GET("/:id", billCaptureHandler.GetDraft)
+		billDrafts.PUT("/:id", billCaptureHandler.UpdateDraft)
+		billDrafts.POST("/:id/confirm", billCaptureHandler.ConfirmDraft)
+		billDrafts.POST("/:id/reject", billCaptureHandler.RejectDraft)
+	}
+
+	// Driver mobile app routes
+	v1.GET("/driver/sheets", deliveryHandler.DriverSheets)
+
+	// Address reference data
+	v1.GET("/addresses/divisions", addressHandler.Divisions)
 }
@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/aceextension/core/db"
 	"github.com/aceextension/crm"
 	"github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/taxid"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -26,6 +29,7 @@ type CreateSupplierRequest struct {
 	Phone            *string                `json:"phone,omitempty"`
 	SupplierType     string                 `json:"supplierType" validate:"required,oneof=local international"`
 	CustomAttributes map[string]interface{} `json:"customAttributes,omitempty"`
+	Address          *domain.Address        `json:"address,omitempty"` // Structured address; district is required
 }
 
 // UpdateSupplierRequest represents the request body for updating a supplier
@@ -36,6 +40,7 @@ type UpdateSupplierRequest struct {
 	SupplierType     string                 `json:"supplierType" validate:"required,oneof=local international"`
 	Status           string                 `json:"status" validate:"required,oneof=active inactive blocked"`
 	CustomAttributes map[string]interface{} `json:"customAttributes,omitempty"`
+	Address          *domain.Address        `json:"address,omitempty"` // Structured address; district is required
 }
 
 // SupplierResponse represents the response for a supplier
@@ -49,6 +54,8 @@ type SupplierResponse struct {
 	SupplierType     string                 `json:"supplierType"`
 	Status           string                 `json:"status"`
 	CustomAttributes map[string]interface{} `json:"customAttributes"`
+	Address          *domain.Address        `json:"address,omitempty"`
+	PANStatus        domain.PANStatus       `json:"panStatus,omitempty"` // unverified, verified or not_found; empty without a PAN
 	CreatedAt        string                 `json:"createdAt"`
 	UpdatedAt        string                 `json:"updatedAt"`
 }
@@ -65,6 +72,8 @@ func toSupplierResponse(supplier *domain.Supplier) *SupplierResponse {
 		SupplierType:     string(supplier.SupplierType),
 		Status:           string(supplier.Status),
 		CustomAttributes: supplier.CustomAttributes,
+		Address:          supplier.GetStructuredAddress(),
+		PANStatus:        supplier.PANStatus(),
 		CreatedAt:        supplier.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:        supplier.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -104,8 +113,14 @@ func (h *SupplierHandler) Create(c echo.Context) error {
 	supplier.Phone = req.Phone
 	supplier.SupplierType = domain.SupplierType(req.SupplierType)
 	supplier.CustomAttributes = req.CustomAttributes
+	if req.Address != nil {
+		supplier.SetStructuredAddress(req.Address)
+	}
 
 	if err := crm.SupplierService.Create(c.Request().Context(), supplier); err != nil {
+		if errors.Is(err, domain.ErrInvalidAddress) || errors.Is(err, taxid.ErrInvalidPAN) || errors.Is(err, domain.ErrInvalidPaymentTerms) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -129,7 +144,12 @@ func (h *SupplierHandler) GetByID(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid supplier ID"})
 	}
 
-	supplier, err := crm.SupplierService.GetByID(c.Request().Context(), id)
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	supplier, err := crm.SupplierService.GetByID(c.Request().Context(), tenantID, id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Supplier not found"})
 	}
@@ -139,12 +159,17 @@ func (h *SupplierHandler) GetByID(c echo.Context) error {
 
 // List godoc
 // @Summary List suppliers
-// @Description Get a paginated list of suppliers for the current tenant
+// @Description Get the tenant's suppliers, newest first, a page at a time. Pass the nextCursor of a page as cursor to get the next; it is absent on the last page.
 // @Tags suppliers
 // @Produce json
-// @Param limit query int false "Limit" default(10)
-// @Param offset query int false "Offset" default(0)
-// @Success 200 {array} SupplierResponse
+// @Param limit query int false "Page size (max 200)" default(10)
+// @Param cursor query string false "nextCursor of the previous page"
+// @Param district query string false "Only suppliers with a structured address in this district, ordered by municipality, ward and tole"
+// @Param municipality query string false "Narrow the district filter to a municipality"
+// @Param ward query int false "Narrow the municipality filter to a ward"
+// @Param tags query string false "Only suppliers with these comma-separated tags; cannot be combined with district"
+// @Param tagMatch query string false "all (default) or any of the tags"
+// @Success 200 {object} db.Page[SupplierResponse]
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/suppliers [get]
@@ -155,27 +180,36 @@ func (h *SupplierHandler) List(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
 	}
 
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	if limit <= 0 {
-		limit = 10
+	page, err := db.ParsePageRequest(c.QueryParam("cursor"), c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	offset, _ := strconv.Atoi(c.QueryParam("offset"))
-	if offset < 0 {
-		offset = 0
+	tagFilter, err := tagsDomain.ParseTagFilter(c.QueryParam("tags"), c.QueryParam("tagMatch"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	suppliers, err := crm.SupplierService.GetByTenantID(c.Request().Context(), tenantID, limit, offset)
+	var suppliers db.Page[*domain.Supplier]
+	district := c.QueryParam("district")
+	switch {
+	case district != "" && tagFilter != nil:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "district and tags filters cannot be combined"})
+	case district != "":
+		ward, _ := strconv.Atoi(c.QueryParam("ward"))
+		filter := domain.AreaFilter{District: district, Municipality: c.QueryParam("municipality"), Ward: ward}
+		suppliers, err = crm.SupplierService.ListByArea(c.Request().Context(), tenantID, filter, page)
+	default:
+		suppliers, err = crm.SupplierService.List(c.Request().Context(), tenantID, tagFilter, page)
+	}
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAddress) || errors.Is(err, db.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
-	responses := make([]*SupplierResponse, len(suppliers))
-	for i, supplier := range suppliers {
-		responses[i] = toSupplierResponse(supplier)
-	}
-
-	return c.JSON(http.StatusOK, responses)
+	return c.JSON(http.StatusOK, db.MapPage(suppliers, toSupplierResponse))
 }
 
 // Search godoc
@@ -183,7 +217,9 @@ func (h *SupplierHandler) List(c echo.Context) error {
 // @Description Search suppliers by name, email, phone, or code
 // @Tags suppliers
 // @Produce json
-// @Param q query string true "Search query"
+// @Param q query string false "Search query; required without tags"
+// @Param tags query string false "Only suppliers with these comma-separated tags"
+// @Param tagMatch query string false "all (default) or any of the tags"
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} SupplierResponse
@@ -197,8 +233,13 @@ func (h *SupplierHandler) Search(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
 	}
 
+	tagFilter, err := tagsDomain.ParseTagFilter(c.QueryParam("tags"), c.QueryParam("tagMatch"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
 	query := c.QueryParam("q")
-	if query == "" {
+	if query == "" && tagFilter == nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Search query is required"})
 	}
 
@@ -212,7 +253,12 @@ func (h *SupplierHandler) Search(c echo.Context) error {
 		offset = 0
 	}
 
-	suppliers, err := crm.SupplierService.Search(c.Request().Context(), tenantID, query, limit, offset)
+	var suppliers []*domain.Supplier
+	if tagFilter != nil {
+		suppliers, err = crm.SupplierService.ListByTags(c.Request().Context(), tenantID, *tagFilter, query, limit, offset)
+	} else {
+		suppliers, err = crm.SupplierService.Search(c.Request().Context(), tenantID, query, limit, offset)
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -254,8 +300,13 @@ func (h *SupplierHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
 	// Get existing supplier
-	supplier, err := crm.SupplierService.GetByID(c.Request().Context(), id)
+	supplier, err := crm.SupplierService.GetByID(c.Request().Context(), tenantID, id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Supplier not found"})
 	}
@@ -267,8 +318,17 @@ func (h *SupplierHandler) Update(c echo.Context) error {
 	supplier.SupplierType = domain.SupplierType(req.SupplierType)
 	supplier.Status = domain.SupplierStatus(req.Status)
 	supplier.CustomAttributes = req.CustomAttributes
+	if req.Address != nil {
+		supplier.SetStructuredAddress(req.Address)
+	}
 
 	if err := crm.SupplierService.Update(c.Request().Context(), supplier); err != nil {
+		if errors.Is(err, domain.ErrInvalidAddress) || errors.Is(err, taxid.ErrInvalidPAN) || errors.Is(err, domain.ErrInvalidPaymentTerms) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, domain.ErrSupplierNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Supplier not found"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -293,7 +353,15 @@ func (h *SupplierHandler) Delete(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid supplier ID"})
 	}
 
-	if err := crm.SupplierService.Delete(c.Request().Context(), id); err != nil {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := crm.SupplierService.Delete(c.Request().Context(), tenantID, id); err != nil {
+		if errors.Is(err, domain.ErrSupplierNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Supplier not found"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// SupplierScorecardHandler handles HTTP requests for supplier performance scorecards
+type SupplierScorecardHandler struct{}
+
+// NewSupplierScorecardHandler creates a new supplier scorecard handler
+func NewSupplierScorecardHandler() *SupplierScorecardHandler {
+	return &SupplierScorecardHandler{}
+}
+
+// Scorecard godoc
+// @Summary Supplier scorecard
+// @Description Rate a supplier on the lines ordered in the period: on-time rate (lines received in full by their expected
+// @Description date), average lead time and delay, fill rate (share of the ordered quantity received), price variance of
+// @Description received costs against ordered prices, and the purchase return quality score. The score averages on-time
+// @Description rate, fill rate, quality and price adherence (100 less the percentage paid over order), where set.
+// @Tags suppliers
+// @Produce json
+// @Param id path string true "Supplier ID"
+// @Param from query string false "Ordered from (YYYY-MM-DD); defaults to 30 days before to"
+// @Param to query string false "Ordered to (YYYY-MM-DD); defaults to today"
+// @Success 200 {object} domain.SupplierScorecard
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/suppliers/{id}/scorecard [get]
+// @Security BearerAuth
+func (h *SupplierScorecardHandler) Scorecard(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid supplier ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	card, err := crm.SupplierScorecardService.Scorecard(c.Request().Context(), tenantID, id, from, to)
+	if err != nil {
+		return supplierScorecardError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, card)
+}
+
+// Ranking godoc
+// @Summary Rank suppliers
+// @Description Scorecards of every supplier with lines ordered in the period, best score first. With categoryId only
+// @Description lines of products in the category count, ranking suppliers within it; the quality score is always
+// @Description across all goods. Suppliers with nothing to score are listed last without a rank.
+// @Tags suppliers
+// @Produce json
+// @Param categoryId query string false "Product category ID"
+// @Param from query string false "Ordered from (YYYY-MM-DD); defaults to 30 days before to"
+// @Param to query string false "Ordered to (YYYY-MM-DD); defaults to today"
+// @Success 200 {array} domain.SupplierScorecard
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/suppliers/scorecards [get]
+// @Security BearerAuth
+func (h *SupplierScorecardHandler) Ranking(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	var categoryID *uuid.UUID
+	if value := c.QueryParam("categoryId"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid category ID"})
+		}
+		categoryID = &id
+	}
+
+	cards, err := crm.SupplierScorecardService.Ranking(c.Request().Context(), tenantID, from, to, categoryID)
+	if err != nil {
+		return supplierScorecardError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, cards)
+}
+
+// supplierScorecardError maps scorecard domain errors to HTTP responses
+func supplierScorecardError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrSupplierNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/taxid"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// TaxIDHandler handles HTTP requests for PAN/VAT validation and IRD verification
+type TaxIDHandler struct{}
+
+// NewTaxIDHandler creates a new tax ID handler
+func NewTaxIDHandler() *TaxIDHandler {
+	return &TaxIDHandler{}
+}
+
+// Check godoc
+// @Summary Validate a PAN/VAT number
+// @Description Check a PAN/VAT number's format before saving it; with verify=true it is also looked up in the IRD
+// @Description register (answers are cached for a day). An invalid number is reported with valid=false.
+// @Tags tax-ids
+// @Produce json
+// @Param pan path string true "PAN/VAT number; spaces, dashes and Nepali digits are accepted"
+// @Param verify query bool false "Look the PAN up with IRD"
+// @Success 200 {object} domain.TaxIDCheck
+// @Failure 503 {object} map[string]string
+// @Router /api/v1/tax-ids/{pan} [get]
+// @Security BearerAuth
+func (h *TaxIDHandler) Check(c echo.Context) error {
+	check, err := crm.TaxIDService.Check(c.Request().Context(), c.Param("pan"), c.QueryParam("verify") == "true")
+	if err != nil {
+		return taxIDError(c, err)
+	}
+	return c.JSON(http.StatusOK, check)
+}
+
+// VerifyCustomer godoc
+// @Summary Verify a customer's PAN with IRD
+// @Description Look the customer's PAN up in the IRD register and record the result as its panStatus badge
+// @Tags customers
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} CustomerResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /api/v1/customers/{id}/verify-pan [post]
+// @Security BearerAuth
+func (h *TaxIDHandler) VerifyCustomer(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+	userID, _ := db.GetUserID(c.Request().Context())
+
+	customer, err := crm.TaxIDService.VerifyCustomer(c.Request().Context(), tenantID, id, userID)
+	if err != nil {
+		return taxIDError(c, err)
+	}
+	return c.JSON(http.StatusOK, toCustomerResponse(customer))
+}
+
+// VerifySupplier godoc
+// @Summary Verify a supplier's PAN with IRD
+// @Description Look the supplier's PAN up in the IRD register and record the result as its panStatus badge
+// @Tags suppliers
+// @Produce json
+// @Param id path string true "Supplier ID"
+// @Success 200 {object} SupplierResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /api/v1/suppliers/{id}/verify-pan [post]
+// @Security BearerAuth
+func (h *TaxIDHandler) VerifySupplier(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid supplier ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+	userID, _ := db.GetUserID(c.Request().Context())
+
+	supplier, err := crm.TaxIDService.VerifySupplier(c.Request().Context(), tenantID, id, userID)
+	if err != nil {
+		return taxIDError(c, err)
+	}
+	return c.JSON(http.StatusOK, toSupplierResponse(supplier))
+}
+
+// taxIDError maps tax ID errors to HTTP responses
+func taxIDError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, taxid.ErrInvalidPAN):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrCustomerNotFound), errors.Is(err, domain.ErrSupplierNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, taxid.ErrLookupUnavailable):
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
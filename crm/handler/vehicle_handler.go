@@ -0,0 +1,536 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultReportDays is the period reports cover when no from date is given
+const defaultReportDays = 30
+
+// VehicleHandler handles HTTP requests for vehicles, trips and logistics costs
+type VehicleHandler struct{}
+
+// NewVehicleHandler creates a new vehicle handler
+func NewVehicleHandler() *VehicleHandler {
+	return &VehicleHandler{}
+}
+
+// VehicleRequest represents a request to create or update a vehicle
+type VehicleRequest struct {
+	RegistrationNumber string   `json:"registrationNumber" validate:"required"`
+	Name               string   `json:"name"`
+	Kind               string   `json:"kind"` // van, truck, pickup, bike
+	CapacityKg         *float64 `json:"capacityKg,omitempty"`
+	OdometerKm         float64  `json:"odometerKm" validate:"gte=0"`
+	IsActive           *bool    `json:"isActive,omitempty"`
+}
+
+// StartTripRequest represents a vehicle setting out on a route's delivery sheet
+type StartTripRequest struct {
+	VehicleID     uuid.UUID  `json:"vehicleId" validate:"required"`
+	RouteID       uuid.UUID  `json:"routeId" validate:"required"`
+	TripDate      string     `json:"tripDate"`      // YYYY-MM-DD; defaults to today
+	StartOdometer float64    `json:"startOdometer"` // Defaults to the vehicle's last reading
+	DriverID      *uuid.UUID `json:"driverId,omitempty"`
+}
+
+// CloseTripRequest represents a vehicle back from its trip
+type CloseTripRequest struct {
+	EndOdometer float64 `json:"endOdometer" validate:"gte=0"`
+	Note        *string `json:"note,omitempty"`
+}
+
+// VehicleExpenseRequest represents a fuel, maintenance or other vehicle cost
+type VehicleExpenseRequest struct {
+	TripID      *uuid.UUID `json:"tripId,omitempty"` // Charges the cost to the trip's route
+	Kind        string     `json:"kind" validate:"required,oneof=fuel maintenance toll other"`
+	Amount      float64    `json:"amount" validate:"gt=0"`
+	Litres      *float64   `json:"litres,omitempty"`
+	OdometerKm  *float64   `json:"odometerKm,omitempty"`
+	ExpenseDate string     `json:"expenseDate"` // YYYY-MM-DD; defaults to today
+	Vendor      *string    `json:"vendor,omitempty"`
+	BillNumber  *string    `json:"billNumber,omitempty"`
+	Note        *string    `json:"note,omitempty"`
+}
+
+// CreateVehicle godoc
+// @Summary Create a vehicle
+// @Description Register a delivery vehicle
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Param vehicle body VehicleRequest true "Vehicle"
+// @Success 201 {object} domain.Vehicle
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/vehicles [post]
+// @Security BearerAuth
+func (h *VehicleHandler) CreateVehicle(c echo.Context) error {
+	var req VehicleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	vehicle := domain.NewVehicle(tenantID, req.RegistrationNumber, req.Name, req.Kind)
+	vehicle.CapacityKg = req.CapacityKg
+	vehicle.OdometerKm = req.OdometerKm
+	if req.IsActive != nil {
+		vehicle.IsActive = *req.IsActive
+	}
+
+	if err := crm.VehicleService.CreateVehicle(c.Request().Context(), vehicle); err != nil {
+		return vehicleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, vehicle)
+}
+
+// ListVehicles godoc
+// @Summary List vehicles
+// @Description Get the tenant's vehicles
+// @Tags vehicles
+// @Produce json
+// @Param active query bool false "Only active vehicles"
+// @Success 200 {array} domain.Vehicle
+// @Router /api/v1/vehicles [get]
+// @Security BearerAuth
+func (h *VehicleHandler) ListVehicles(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	vehicles, err := crm.VehicleService.ListVehicles(c.Request().Context(), tenantID, c.QueryParam("active") == "true")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, vehicles)
+}
+
+// GetVehicle godoc
+// @Summary Get a vehicle
+// @Description Get a vehicle by ID
+// @Tags vehicles
+// @Produce json
+// @Param id path string true "Vehicle ID"
+// @Success 200 {object} domain.Vehicle
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/vehicles/{id} [get]
+// @Security BearerAuth
+func (h *VehicleHandler) GetVehicle(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid vehicle ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	vehicle, err := crm.VehicleService.GetVehicle(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return vehicleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, vehicle)
+}
+
+// UpdateVehicle godoc
+// @Summary Update a vehicle
+// @Description Update a vehicle's details or retire it
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Param id path string true "Vehicle ID"
+// @Param vehicle body VehicleRequest true "Vehicle"
+// @Success 200 {object} domain.Vehicle
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/vehicles/{id} [put]
+// @Security BearerAuth
+func (h *VehicleHandler) UpdateVehicle(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid vehicle ID"})
+	}
+
+	var req VehicleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	vehicle, err := crm.VehicleService.GetVehicle(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return vehicleError(c, err)
+	}
+
+	updated := domain.NewVehicle(tenantID, req.RegistrationNumber, req.Name, req.Kind)
+	vehicle.RegistrationNumber = updated.RegistrationNumber
+	vehicle.Name = updated.Name
+	vehicle.Kind = updated.Kind
+	vehicle.CapacityKg = req.CapacityKg
+	vehicle.OdometerKm = req.OdometerKm
+	if req.IsActive != nil {
+		vehicle.IsActive = *req.IsActive
+	}
+
+	if err := crm.VehicleService.UpdateVehicle(c.Request().Context(), vehicle); err != nil {
+		return vehicleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, vehicle)
+}
+
+// RecordExpense godoc
+// @Summary Record a vehicle expense
+// @Description Record fuel, maintenance, tolls or other running costs of a vehicle. Linked to a trip the cost is charged to that trip's route; otherwise it is shared across the vehicle's trips by distance. The expense is booked to the ledger.
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Param id path string true "Vehicle ID"
+// @Param expense body VehicleExpenseRequest true "Expense"
+// @Success 201 {object} domain.VehicleExpense
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/vehicles/{id}/expenses [post]
+// @Security BearerAuth
+func (h *VehicleHandler) RecordExpense(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid vehicle ID"})
+	}
+
+	var req VehicleExpenseRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	date, err := parseAsOf(req.ExpenseDate)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid expense date, expected YYYY-MM-DD"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	expense := domain.NewVehicleExpense(tenantID, id, domain.VehicleExpenseKind(req.Kind), req.Amount, date)
+	expense.TripID = req.TripID
+	expense.Litres = req.Litres
+	expense.OdometerKm = req.OdometerKm
+	expense.Vendor = req.Vendor
+	expense.BillNumber = req.BillNumber
+	expense.Note = req.Note
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		expense.CreatedBy = &userID
+	}
+
+	if err := crm.VehicleService.RecordExpense(c.Request().Context(), expense); err != nil {
+		return vehicleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, expense)
+}
+
+// ListExpenses godoc
+// @Summary List a vehicle's expenses
+// @Description Get a vehicle's expenses over a period, oldest first
+// @Tags vehicles
+// @Produce json
+// @Param id path string true "Vehicle ID"
+// @Param from query string false "From date (YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "To date (YYYY-MM-DD), defaults to today"
+// @Success 200 {array} domain.VehicleExpense
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/vehicles/{id}/expenses [get]
+// @Security BearerAuth
+func (h *VehicleHandler) ListExpenses(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid vehicle ID"})
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	expenses, err := crm.VehicleService.ListExpenses(c.Request().Context(), tenantID, &id, from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, expenses)
+}
+
+// StartTrip godoc
+// @Summary Start a trip
+// @Description Record a vehicle setting out on a route's delivery sheet for a day
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Param trip body StartTripRequest true "Trip"
+// @Success 201 {object} domain.Trip
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/trips [post]
+// @Security BearerAuth
+func (h *VehicleHandler) StartTrip(c echo.Context) error {
+	var req StartTripRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	date, err := parseAsOf(req.TripDate)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid trip date, expected YYYY-MM-DD"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var userID *uuid.UUID
+	if id, ok := db.GetUserID(c.Request().Context()); ok {
+		userID = &id
+	}
+
+	trip := domain.NewTrip(tenantID, req.VehicleID, req.RouteID, date, req.StartOdometer, req.DriverID)
+	if err := crm.VehicleService.StartTrip(c.Request().Context(), trip, userID); err != nil {
+		return vehicleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, trip)
+}
+
+// ListTrips godoc
+// @Summary List trips
+// @Description Get trips over a period, optionally for one vehicle or route
+// @Tags vehicles
+// @Produce json
+// @Param vehicleId query string false "Vehicle ID"
+// @Param routeId query string false "Route ID"
+// @Param from query string false "From date (YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "To date (YYYY-MM-DD), defaults to today"
+// @Success 200 {array} domain.Trip
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/trips [get]
+// @Security BearerAuth
+func (h *VehicleHandler) ListTrips(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	var vehicleID, routeID *uuid.UUID
+	if value := c.QueryParam("vehicleId"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid vehicle ID"})
+		}
+		vehicleID = &id
+	}
+	if value := c.QueryParam("routeId"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid route ID"})
+		}
+		routeID = &id
+	}
+
+	trips, err := crm.VehicleService.ListTrips(c.Request().Context(), tenantID, vehicleID, routeID, from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, trips)
+}
+
+// GetTrip godoc
+// @Summary Get a trip
+// @Description Get a trip by ID
+// @Tags vehicles
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Success 200 {object} domain.Trip
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/trips/{id} [get]
+// @Security BearerAuth
+func (h *VehicleHandler) GetTrip(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid trip ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	trip, err := crm.VehicleService.GetTrip(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return vehicleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, trip)
+}
+
+// CloseTrip godoc
+// @Summary Close a trip
+// @Description Record the end odometer reading when the vehicle is back. Only the trip's driver or a manager can close it.
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param trip body CloseTripRequest true "End reading"
+// @Success 200 {object} domain.Trip
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/trips/{id}/close [post]
+// @Security BearerAuth
+func (h *VehicleHandler) CloseTrip(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid trip ID"})
+	}
+
+	var req CloseTripRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	trip, err := crm.VehicleService.CloseTrip(c.Request().Context(), tenantID, id, userID, role, req.EndOdometer, req.Note)
+	if err != nil {
+		return vehicleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, trip)
+}
+
+// RouteProfitability godoc
+// @Summary Route profitability
+// @Description Compare each route's delivered invoice value with its fuel, maintenance and other vehicle costs over a period
+// @Tags vehicles
+// @Produce json
+// @Param from query string false "From date (YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "To date (YYYY-MM-DD), defaults to today"
+// @Success 200 {array} domain.RouteProfitability
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/routes/profitability [get]
+// @Security BearerAuth
+func (h *VehicleHandler) RouteProfitability(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	report, err := crm.VehicleService.RouteProfitability(c.Request().Context(), tenantID, from, to)
+	if err != nil {
+		return vehicleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// parseDateRange reads the from and to query dates; to defaults to today and
+// from to defaultReportDays before it
+func parseDateRange(c echo.Context) (time.Time, time.Time, error) {
+	to, err := parseAsOf(c.QueryParam("to"))
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid to date, expected YYYY-MM-DD")
+	}
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location())
+
+	from := to.AddDate(0, 0, -defaultReportDays)
+	if value := c.QueryParam("from"); value != "" {
+		if from, err = parseAsOf(value); err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from date, expected YYYY-MM-DD")
+		}
+	}
+
+	return from, to, nil
+}
+
+// vehicleError maps vehicle domain errors to HTTP responses
+func vehicleError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrVehicleNotFound), errors.Is(err, domain.ErrTripNotFound), errors.Is(err, domain.ErrRouteNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotRouteDriver):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrTripState), errors.Is(err, domain.ErrVehicleInactive):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,509 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// WarrantyHandler handles HTTP requests for warranties and warranty claims
+type WarrantyHandler struct{}
+
+// NewWarrantyHandler creates a new warranty handler
+func NewWarrantyHandler() *WarrantyHandler {
+	return &WarrantyHandler{}
+}
+
+// WarrantySaleRequest registers the serial-numbered items of a sale
+type WarrantySaleRequest struct {
+	CustomerID    *uuid.UUID                `json:"customerId,omitempty"`
+	InvoiceID     *uuid.UUID                `json:"invoiceId,omitempty"`
+	InvoiceNumber *string                   `json:"invoiceNumber,omitempty"`
+	SoldAt        string                    `json:"soldAt,omitempty"` // YYYY-MM-DD; defaults to today
+	Items         []domain.WarrantySaleItem `json:"items" validate:"required,min=1"`
+}
+
+// WarrantyClaimRequest takes a unit in for repair, replacement or exchange
+type WarrantyClaimRequest struct {
+	SerialNumber string                   `json:"serialNumber" validate:"required"`
+	Type         domain.WarrantyClaimType `json:"type"` // warranty (default) or exchange
+	Issue        string                   `json:"issue" validate:"required"`
+}
+
+// SendToServiceRequest names the service centre a unit is sent to
+type SendToServiceRequest struct {
+	ServiceCenter string `json:"serviceCenter"`
+}
+
+// ResolveClaimRequest records how a claim was settled
+type ResolveClaimRequest struct {
+	Outcome              domain.WarrantyClaimOutcome `json:"outcome" validate:"required"`
+	ReplacementProductID *uuid.UUID                  `json:"replacementProductId,omitempty"` // Exchanges only
+	ReplacementSerial    string                      `json:"replacementSerial,omitempty"`    // Replacements and exchanges
+	Note                 string                      `json:"note,omitempty"`
+}
+
+// RegisterSale godoc
+// @Summary Register warranties for a sale
+// @Description Put the serial-numbered items of a sale under warranty. Items without warrantyMonths take the product's
+// @Description warranty_months; items whose product has no warranty are skipped. A serial number can have one active warranty.
+// @Tags warranties
+// @Accept json
+// @Produce json
+// @Param sale body WarrantySaleRequest true "Sale"
+// @Success 201 {array} domain.Warranty
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/warranties [post]
+// @Security BearerAuth
+func (h *WarrantyHandler) RegisterSale(c echo.Context) error {
+	var req WarrantySaleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	sale := &domain.WarrantySale{
+		TenantID:      tenantID,
+		CustomerID:    req.CustomerID,
+		InvoiceID:     req.InvoiceID,
+		InvoiceNumber: req.InvoiceNumber,
+		Items:         req.Items,
+		CreatedBy:     optionalUserID(c),
+	}
+	if req.SoldAt != "" {
+		soldAt, err := time.Parse("2006-01-02", req.SoldAt)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid soldAt, expected YYYY-MM-DD"})
+		}
+		sale.SoldAt = soldAt
+	}
+
+	warranties, err := crm.WarrantyService.RegisterSale(c.Request().Context(), sale)
+	if err != nil {
+		return warrantyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, warranties)
+}
+
+// ListWarranties godoc
+// @Summary List warranties
+// @Description Get warranties, newest first, optionally for a customer or serial number
+// @Tags warranties
+// @Produce json
+// @Param customerId query string false "Customer ID"
+// @Param serial query string false "Serial number"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.Warranty
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/warranties [get]
+// @Security BearerAuth
+func (h *WarrantyHandler) ListWarranties(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var customerID *uuid.UUID
+	if value := c.QueryParam("customerId"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+		}
+		customerID = &id
+	}
+
+	var serialNumber *string
+	if value := c.QueryParam("serial"); value != "" {
+		serialNumber = &value
+	}
+
+	limit, offset := warrantyPage(c)
+	warranties, err := crm.WarrantyService.ListWarranties(c.Request().Context(), tenantID, customerID, serialNumber, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, warranties)
+}
+
+// Check godoc
+// @Summary Check a serial number's warranty
+// @Description Look a unit up by serial number at the service counter: coverage (active, expired, replaced or void), days left and any open claim
+// @Tags warranties
+// @Produce json
+// @Param serial query string true "Serial number"
+// @Success 200 {object} domain.WarrantyCheck
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/warranties/check [get]
+// @Security BearerAuth
+func (h *WarrantyHandler) Check(c echo.Context) error {
+	serialNumber := c.QueryParam("serial")
+	if serialNumber == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "serial is required"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	check, err := crm.WarrantyService.Check(c.Request().Context(), tenantID, serialNumber)
+	if err != nil {
+		return warrantyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, check)
+}
+
+// GetWarranty godoc
+// @Summary Get a warranty
+// @Description Get a warranty by ID
+// @Tags warranties
+// @Produce json
+// @Param id path string true "Warranty ID"
+// @Success 200 {object} domain.Warranty
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/warranties/{id} [get]
+// @Security BearerAuth
+func (h *WarrantyHandler) GetWarranty(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warranty ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	warranty, err := crm.WarrantyService.GetWarranty(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return warrantyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, warranty)
+}
+
+// VoidInvoice godoc
+// @Summary Void an invoice's warranties
+// @Description End the cover on an invoice's items when the sale is voided or the items are refunded
+// @Tags warranties
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} map[string]int
+// @Router /api/v1/warranties/invoices/{id}/void [post]
+// @Security BearerAuth
+func (h *WarrantyHandler) VoidInvoice(c echo.Context) error {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid invoice ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	voided, err := crm.WarrantyService.VoidInvoice(c.Request().Context(), tenantID, invoiceID, optionalUserID(c))
+	if err != nil {
+		return warrantyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]int{"voided": voided})
+}
+
+// OpenClaim godoc
+// @Summary Open a warranty claim
+// @Description Take a unit in by serial number. Warranty claims need the unit to be covered today; exchanges only need
+// @Description an active warranty. A unit can have one open claim at a time.
+// @Tags warranty-claims
+// @Accept json
+// @Produce json
+// @Param claim body WarrantyClaimRequest true "Claim"
+// @Success 201 {object} domain.WarrantyClaim
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/warranty-claims [post]
+// @Security BearerAuth
+func (h *WarrantyHandler) OpenClaim(c echo.Context) error {
+	var req WarrantyClaimRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if req.Type == "" {
+		req.Type = domain.ClaimWarranty
+	}
+
+	claim, err := crm.WarrantyService.OpenClaim(c.Request().Context(), tenantID, req.SerialNumber, req.Type, req.Issue, optionalUserID(c))
+	if err != nil {
+		return warrantyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, claim)
+}
+
+// ListClaims godoc
+// @Summary List warranty claims
+// @Description Get warranty claims, newest first, optionally by status or serial number
+// @Tags warranty-claims
+// @Produce json
+// @Param status query string false "received, in_service, resolved, closed or cancelled"
+// @Param serial query string false "Serial number"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.WarrantyClaim
+// @Router /api/v1/warranty-claims [get]
+// @Security BearerAuth
+func (h *WarrantyHandler) ListClaims(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var status *domain.WarrantyClaimStatus
+	if value := c.QueryParam("status"); value != "" {
+		s := domain.WarrantyClaimStatus(value)
+		status = &s
+	}
+
+	var serialNumber *string
+	if value := c.QueryParam("serial"); value != "" {
+		serialNumber = &value
+	}
+
+	limit, offset := warrantyPage(c)
+	claims, err := crm.WarrantyService.ListClaims(c.Request().Context(), tenantID, status, serialNumber, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, claims)
+}
+
+// GetClaim godoc
+// @Summary Get a warranty claim
+// @Description Get a warranty claim and its outcome
+// @Tags warranty-claims
+// @Produce json
+// @Param id path string true "Claim ID"
+// @Success 200 {object} domain.WarrantyClaim
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/warranty-claims/{id} [get]
+// @Security BearerAuth
+func (h *WarrantyHandler) GetClaim(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid claim ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	claim, err := crm.WarrantyService.GetClaim(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return warrantyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+// SendToService godoc
+// @Summary Send a claimed unit for service
+// @Description Record a received unit going to the brand's service centre
+// @Tags warranty-claims
+// @Accept json
+// @Produce json
+// @Param id path string true "Claim ID"
+// @Param request body SendToServiceRequest false "Service centre"
+// @Success 200 {object} domain.WarrantyClaim
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/warranty-claims/{id}/send [post]
+// @Security BearerAuth
+func (h *WarrantyHandler) SendToService(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid claim ID"})
+	}
+
+	var req SendToServiceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	claim, err := crm.WarrantyService.SendToService(c.Request().Context(), tenantID, id, req.ServiceCenter, optionalUserID(c))
+	if err != nil {
+		return warrantyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+// Resolve godoc
+// @Summary Resolve a warranty claim
+// @Description Record the outcome: repaired, replaced (new unit of the same product for the rest of the cover),
+// @Description exchanged (different product with its own warranty) or rejected. Replacements and exchanges take
+// @Description the new unit out of stock, the customer's unit in as defective, and move the warranty to the new serial number.
+// @Tags warranty-claims
+// @Accept json
+// @Produce json
+// @Param id path string true "Claim ID"
+// @Param request body ResolveClaimRequest true "Outcome"
+// @Success 200 {object} domain.WarrantyClaim
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/warranty-claims/{id}/resolve [post]
+// @Security BearerAuth
+func (h *WarrantyHandler) Resolve(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid claim ID"})
+	}
+
+	var req ResolveClaimRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	claim, err := crm.WarrantyService.Resolve(c.Request().Context(), tenantID, id, req.Outcome, req.ReplacementProductID, req.ReplacementSerial, req.Note, optionalUserID(c))
+	if err != nil {
+		return warrantyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+// CloseClaim godoc
+// @Summary Close a warranty claim
+// @Description Record the unit handed back to the customer after the claim was resolved
+// @Tags warranty-claims
+// @Produce json
+// @Param id path string true "Claim ID"
+// @Success 200 {object} domain.WarrantyClaim
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/warranty-claims/{id}/close [post]
+// @Security BearerAuth
+func (h *WarrantyHandler) CloseClaim(c echo.Context) error {
+	return h.changeClaim(c, crm.WarrantyService.Close)
+}
+
+// CancelClaim godoc
+// @Summary Cancel a warranty claim
+// @Description Withdraw a claim that has not been resolved
+// @Tags warranty-claims
+// @Produce json
+// @Param id path string true "Claim ID"
+// @Success 200 {object} domain.WarrantyClaim
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/warranty-claims/{id}/cancel [post]
+// @Security BearerAuth
+func (h *WarrantyHandler) CancelClaim(c echo.Context) error {
+	return h.changeClaim(c, crm.WarrantyService.Cancel)
+}
+
+func (h *WarrantyHandler) changeClaim(c echo.Context, change func(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*domain.WarrantyClaim, error)) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid claim ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	claim, err := change(c.Request().Context(), tenantID, id, optionalUserID(c))
+	if err != nil {
+		return warrantyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+// warrantyPage reads limit (default 50) and offset query parameters
+func warrantyPage(c echo.Context) (int, int) {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// optionalUserID returns the calling user, if known
+func optionalUserID(c echo.Context) *uuid.UUID {
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		return &userID
+	}
+	return nil
+}
+
+// warrantyError maps warranty domain errors to HTTP responses
+func warrantyError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrWarrantyNotFound), errors.Is(err, domain.ErrWarrantyClaimNotFound), errors.Is(err, domain.ErrCustomerNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrWarrantyExists), errors.Is(err, domain.ErrWarrantyNotCovered),
+		errors.Is(err, domain.ErrWarrantyClaimOpen), errors.Is(err, domain.ErrWarrantyClaimState):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidWarranty), errors.Is(err, domain.ErrInvalidWarrantyClaim):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// WriteOffHandler handles HTTP requests for bad-debt write-offs
+type WriteOffHandler struct{}
+
+// NewWriteOffHandler creates a new write-off handler
+func NewWriteOffHandler() *WriteOffHandler {
+	return &WriteOffHandler{}
+}
+
+// WriteOffRequest represents a request to write off a customer's dues
+type WriteOffRequest struct {
+	Amount float64 `json:"amount" validate:"gte=0"` // 0 writes off everything outstanding
+	Reason string  `json:"reason" validate:"required"`
+}
+
+// WriteOffReviewRequest represents an approver's decision
+type WriteOffReviewRequest struct {
+	Note *string `json:"note,omitempty"`
+}
+
+// Request godoc
+// @Summary Request a write-off
+// @Description Ask for a customer's unrecoverable dues to be written off as bad debt. The write-off stays pending until an owner or admin approves it.
+// @Tags write-offs
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param writeOff body WriteOffRequest true "Write-off"
+// @Success 201 {object} domain.WriteOff
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/customers/{id}/write-offs [post]
+// @Security BearerAuth
+func (h *WriteOffHandler) Request(c echo.Context) error {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+	}
+
+	var req WriteOffRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var requestedBy *uuid.UUID
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		requestedBy = &userID
+	}
+
+	writeOff := domain.NewWriteOff(tenantID, customerID, req.Amount, req.Reason, requestedBy)
+	if err := crm.WriteOffService.Request(c.Request().Context(), writeOff); err != nil {
+		return writeOffError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, writeOff)
+}
+
+// List godoc
+// @Summary List write-offs
+// @Description Get write-offs, newest first, optionally filtered by status or customer
+// @Tags write-offs
+// @Produce json
+// @Param status query string false "pending, approved, rejected or cancelled"
+// @Param customerId query string false "Customer ID"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.WriteOff
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/write-offs [get]
+// @Security BearerAuth
+func (h *WriteOffHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var status *domain.WriteOffStatus
+	if value := c.QueryParam("status"); value != "" {
+		s := domain.WriteOffStatus(value)
+		status = &s
+	}
+
+	var customerID *uuid.UUID
+	if value := c.QueryParam("customerId"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+		}
+		customerID = &id
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	writeOffs, err := crm.WriteOffService.List(c.Request().Context(), tenantID, status, customerID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, writeOffs)
+}
+
+// GetByID godoc
+// @Summary Get a write-off
+// @Description Get a write-off and its approval
+// @Tags write-offs
+// @Produce json
+// @Param id path string true "Write-off ID"
+// @Success 200 {object} domain.WriteOff
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/write-offs/{id} [get]
+// @Security BearerAuth
+func (h *WriteOffHandler) GetByID(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid write-off ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	writeOff, err := crm.WriteOffService.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return writeOffError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, writeOff)
+}
+
+// Approve godoc
+// @Summary Approve a write-off
+// @Description Close the customer's dues and book them to bad-debt expense in the current fiscal year. Owners and admins only; the requester cannot approve their own write-off.
+// @Tags write-offs
+// @Accept json
+// @Produce json
+// @Param id path string true "Write-off ID"
+// @Param review body WriteOffReviewRequest false "Review note"
+// @Success 200 {object} domain.WriteOff
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/write-offs/{id}/approve [post]
+// @Security BearerAuth
+func (h *WriteOffHandler) Approve(c echo.Context) error {
+	return h.review(c, true)
+}
+
+// Reject godoc
+// @Summary Reject a write-off
+// @Description Decline a write-off; the dues stay open. Owners and admins only.
+// @Tags write-offs
+// @Accept json
+// @Produce json
+// @Param id path string true "Write-off ID"
+// @Param review body WriteOffReviewRequest false "Review note"
+// @Success 200 {object} domain.WriteOff
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/write-offs/{id}/reject [post]
+// @Security BearerAuth
+func (h *WriteOffHandler) Reject(c echo.Context) error {
+	return h.review(c, false)
+}
+
+func (h *WriteOffHandler) review(c echo.Context, approve bool) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid write-off ID"})
+	}
+
+	var req WriteOffReviewRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	reviewerID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	var writeOff *domain.WriteOff
+	if approve {
+		writeOff, err = crm.WriteOffService.Approve(c.Request().Context(), tenantID, id, reviewerID, role, req.Note)
+	} else {
+		writeOff, err = crm.WriteOffService.Reject(c.Request().Context(), tenantID, id, reviewerID, role, req.Note)
+	}
+	if err != nil {
+		return writeOffError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, writeOff)
+}
+
+// Cancel godoc
+// @Summary Cancel a write-off
+// @Description Withdraw a pending write-off. Allowed for the requester and approvers.
+// @Tags write-offs
+// @Produce json
+// @Param id path string true "Write-off ID"
+// @Success 200 {object} domain.WriteOff
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/write-offs/{id}/cancel [post]
+// @Security BearerAuth
+func (h *WriteOffHandler) Cancel(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid write-off ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User not found"})
+	}
+	role, _ := db.GetRole(c.Request().Context())
+
+	writeOff, err := crm.WriteOffService.Cancel(c.Request().Context(), tenantID, id, userID, role)
+	if err != nil {
+		return writeOffError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, writeOff)
+}
+
+// YearReport godoc
+// @Summary Write-offs per fiscal year
+// @Description Get the bad debt written off in each fiscal year: number of write-offs, customers and total amount
+// @Tags write-offs
+// @Produce json
+// @Success 200 {array} domain.WriteOffYearTotal
+// @Router /api/v1/write-offs/report [get]
+// @Security BearerAuth
+func (h *WriteOffHandler) YearReport(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	totals, err := crm.WriteOffService.YearReport(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, totals)
+}
+
+// writeOffError maps write-off domain errors to HTTP responses
+func writeOffError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrWriteOffNotFound), errors.Is(err, domain.ErrCustomerNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrWriteOffNotPermitted), errors.Is(err, domain.ErrWriteOffSelfApproval):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrWriteOffState), errors.Is(err, domain.ErrWriteOffExceedsDues):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
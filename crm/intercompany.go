@@ -0,0 +1,35 @@
+package crm
+
+import (
+	"context"
+	"fmt"
+
+	accountingDomain "github.com/aceextension/accounting/domain"
+	"github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/service"
+	"github.com/google/uuid"
+)
+
+// interCompanyPurchases mirrors a linked company's sale as a draft purchase bill in
+// the buyer's bill review queue, so the buyer confirms it like any captured bill
+type interCompanyPurchases struct {
+	bills service.BillCaptureService
+}
+
+func (m interCompanyPurchases) MirrorSale(ctx context.Context, sale *accountingDomain.InterCompanyTransaction, seller accountingDomain.Company) (uuid.UUID, error) {
+	draft := domain.NewPurchaseBillDraft(sale.PartnerTenantID, "",
+		fmt.Sprintf("Inter-company sale %s from %s", sale.DocumentNumber, seller.Name), "")
+	draft.BillNumber = &sale.DocumentNumber
+	draft.BillDate = &sale.Date
+	draft.SubTotal = &sale.SubTotal
+	draft.VATAmount = &sale.VATAmount
+	draft.TotalAmount = &sale.TotalAmount
+	if seller.PAN != "" {
+		draft.SupplierPAN = &seller.PAN
+	}
+
+	if err := m.bills.CreateDraft(ctx, draft); err != nil {
+		return uuid.Nil, err
+	}
+	return draft.ID, nil
+}
@@ -0,0 +1,96 @@
+package crm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/accounting"
+	accountingDomain "github.com/aceextension/accounting/domain"
+	"github.com/aceextension/crm/domain"
+	"github.com/aceextension/fiscal"
+	fiscalDomain "github.com/aceextension/fiscal/domain"
+	"github.com/google/uuid"
+)
+
+// accountingJournal posts confirmed purchase bills and consignment bills with
+// accounting's automatic posting
+type accountingJournal struct{}
+
+// PostPurchaseBill books the bill on the tenant's posting accounts
+func (accountingJournal) PostPurchaseBill(ctx context.Context, draft *domain.PurchaseBillDraft) error {
+	_, err := accounting.Service.PostFromReference(ctx, draft.TenantID, accountingDomain.ReferencePurchaseBill, draft.ID)
+	return err
+}
+
+// PostConsignmentBill books the bill on the tenant's posting accounts
+func (accountingJournal) PostConsignmentBill(ctx context.Context, bill *domain.ConsignmentBill) (uuid.UUID, error) {
+	entry, err := accounting.Service.PostFromReference(ctx, bill.TenantID, accountingDomain.ReferenceConsignmentBill, bill.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return entry.ID, nil
+}
+
+// postPurchaseBill reads a confirmed bill for posting: the purchase, the VAT that
+// can be claimed back and the supplier's round-off against the total due to them
+func postPurchaseBill(ctx context.Context, tenantID, id uuid.UUID) (*accountingDomain.PostingDocument, error) {
+	draft, err := BillCaptureService.GetDraft(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != domain.BillDraftConfirmed || draft.BillNumber == nil || draft.BillDate == nil || draft.TotalAmount == nil {
+		return nil, fmt.Errorf("%w: purchase bill is %s", accountingDomain.ErrReferenceNotPostable, draft.Status)
+	}
+
+	supplier := draft.SenderEmail
+	if draft.SupplierID != nil {
+		if s, err := SupplierService.GetByID(ctx, tenantID, *draft.SupplierID); err == nil {
+			supplier = s.Name
+		}
+	}
+	vat := 0.0
+	if draft.VATAmount != nil {
+		vat = *draft.VATAmount
+	}
+
+	doc := &accountingDomain.PostingDocument{
+		TenantID:    tenantID,
+		Date:        *draft.BillDate,
+		Description: fmt.Sprintf("Purchase bill %s - %s", *draft.BillNumber, supplier),
+		CreatedBy:   draft.ReviewedBy,
+	}
+	rule, err := fiscal.RoundingRule(ctx, tenantID, fiscalDomain.DocumentPurchase)
+	if err != nil {
+		return nil, err
+	}
+	roundOff := draft.RoundOff(rule.Increment)
+
+	doc.Debit(accountingDomain.RolePurchases, *draft.TotalAmount-vat-roundOff, "")
+	doc.Debit(accountingDomain.RoleInputVAT, vat, "")
+	doc.RoundOff(-roundOff)
+	doc.Credit(accountingDomain.RolePayable, *draft.TotalAmount, supplier)
+	return doc, nil
+}
+
+// postConsignmentBill reads a consignment bill for posting: consigned goods sold are
+// bought from the supplier at their consignment cost
+func postConsignmentBill(ctx context.Context, tenantID, id uuid.UUID) (*accountingDomain.PostingDocument, error) {
+	bill, err := ConsignmentService.GetBill(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	supplier := ""
+	if s, err := SupplierService.GetByID(ctx, tenantID, bill.SupplierID); err == nil {
+		supplier = s.Name
+	}
+
+	doc := &accountingDomain.PostingDocument{
+		TenantID:    tenantID,
+		Date:        bill.CreatedAt,
+		Description: fmt.Sprintf("Consignment bill %s - %s", bill.BillNumber, supplier),
+	}
+	doc.Debit(accountingDomain.RolePurchases, bill.TotalAmount, "")
+	doc.Credit(accountingDomain.RolePayable, bill.TotalAmount, supplier)
+	return doc, nil
+}
@@ -0,0 +1,26 @@
+-- CRM Module: Per-user Favorite and Recently Viewed Customers
+-- Migration: 002_create_customer_quick_picks.sql
+
+CREATE TABLE IF NOT EXISTS customer_quick_picks (
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    is_favorite BOOLEAN NOT NULL DEFAULT false,
+    position INTEGER NOT NULL DEFAULT 0,
+    view_count INTEGER NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP,
+    PRIMARY KEY (user_id, customer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_quick_picks_list
+    ON customer_quick_picks(tenant_id, user_id, is_favorite DESC, position, last_viewed_at DESC);
+
+ALTER TABLE customer_quick_picks ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON customer_quick_picks
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE customer_quick_picks IS 'POS quick-pick list: pinned favorites and the last 50 viewed customers per user';
@@ -0,0 +1,75 @@
+-- CRM Module: Returnable Container Deposits (crates, bottles, cylinders)
+-- Migration: 003_create_container_deposits.sql
+
+CREATE TABLE IF NOT EXISTS container_types (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    deposit_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_container_types_code UNIQUE (tenant_id, code)
+);
+
+CREATE TABLE IF NOT EXISTS container_movements (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    container_type_id UUID NOT NULL REFERENCES container_types(id),
+    kind VARCHAR(10) NOT NULL,
+    quantity INTEGER NOT NULL,
+    deposit_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    reference_type VARCHAR(50),
+    reference_id UUID,
+    note TEXT,
+    journal_entry_id UUID,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_container_movements_kind CHECK (kind IN ('issue', 'return')),
+    CONSTRAINT chk_container_movements_quantity CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_container_movements_customer ON container_movements(tenant_id, customer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_container_movements_reference ON container_movements(reference_type, reference_id) WHERE reference_id IS NOT NULL;
+
+-- Running balance per customer and container type, kept in step with movements
+CREATE TABLE IF NOT EXISTS customer_container_balances (
+    tenant_id UUID NOT NULL,
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    container_type_id UUID NOT NULL REFERENCES container_types(id),
+    quantity INTEGER NOT NULL DEFAULT 0,
+    deposit_held DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (customer_id, container_type_id),
+    CONSTRAINT chk_customer_container_balances_quantity CHECK (quantity >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_container_balances_tenant ON customer_container_balances(tenant_id) WHERE quantity > 0;
+
+ALTER TABLE container_types ENABLE ROW LEVEL SECURITY;
+ALTER TABLE container_movements ENABLE ROW LEVEL SECURITY;
+ALTER TABLE customer_container_balances ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON container_types
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON container_movements
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON customer_container_balances
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE container_types IS 'Returnable containers that carry a deposit (crates, bottles, kegs, cylinders)';
+COMMENT ON TABLE container_movements IS 'Containers issued with sales and returned by customers, with deposit collected or refunded';
+COMMENT ON TABLE customer_container_balances IS 'Containers held per customer and the deposit liability owed back to them';
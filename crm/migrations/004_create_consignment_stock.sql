@@ -0,0 +1,89 @@
+-- CRM Module: Supplier Consignment Stock
+-- Migration: 004_create_consignment_stock.sql
+
+CREATE TABLE IF NOT EXISTS consignment_receipts (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id),
+    receipt_number VARCHAR(50) NOT NULL,
+    received_at TIMESTAMP NOT NULL,
+    reference VARCHAR(100),
+    note TEXT,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_consignment_receipts_number UNIQUE (tenant_id, receipt_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_consignment_receipts_supplier ON consignment_receipts(tenant_id, supplier_id, received_at DESC);
+
+CREATE TABLE IF NOT EXISTS consignment_lines (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    receipt_id UUID NOT NULL REFERENCES consignment_receipts(id) ON DELETE CASCADE,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id),
+    product_id UUID NOT NULL,
+    quantity DECIMAL(15, 3) NOT NULL,
+    unit_cost DECIMAL(15, 2) NOT NULL,
+    sold_qty DECIMAL(15, 3) NOT NULL DEFAULT 0,
+    returned_qty DECIMAL(15, 3) NOT NULL DEFAULT 0,
+    received_at TIMESTAMP NOT NULL,
+    CONSTRAINT chk_consignment_lines_qty CHECK (quantity > 0 AND sold_qty + returned_qty <= quantity)
+);
+
+-- Sales settle the oldest open consignment stock of a product first
+CREATE INDEX IF NOT EXISTS idx_consignment_lines_open
+    ON consignment_lines(tenant_id, product_id, received_at)
+    WHERE sold_qty + returned_qty < quantity;
+
+CREATE TABLE IF NOT EXISTS consignment_bills (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id),
+    bill_number VARCHAR(50) NOT NULL,
+    sale_type VARCHAR(50),
+    sale_id UUID,
+    total_amount DECIMAL(15, 2) NOT NULL,
+    journal_entry_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_consignment_bills_number UNIQUE (tenant_id, bill_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_consignment_bills_supplier ON consignment_bills(tenant_id, supplier_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS consignment_bill_lines (
+    id UUID PRIMARY KEY,
+    bill_id UUID NOT NULL REFERENCES consignment_bills(id) ON DELETE CASCADE,
+    line_id UUID NOT NULL REFERENCES consignment_lines(id),
+    product_id UUID NOT NULL,
+    quantity DECIMAL(15, 3) NOT NULL,
+    unit_cost DECIMAL(15, 2) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_consignment_bill_lines_bill ON consignment_bill_lines(bill_id);
+
+ALTER TABLE consignment_receipts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE consignment_lines ENABLE ROW LEVEL SECURITY;
+ALTER TABLE consignment_bills ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON consignment_receipts
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON consignment_lines
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON consignment_bills
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE consignment_receipts IS 'Supplier-owned stock received on consignment; raises no payable until sold';
+COMMENT ON TABLE consignment_lines IS 'Products per consignment receipt with quantities sold and returned to the supplier';
+COMMENT ON TABLE consignment_bills IS 'Supplier purchase bills generated automatically when consignment stock is sold';
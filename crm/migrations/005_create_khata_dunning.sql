@@ -0,0 +1,109 @@
+-- CRM Module: Customer Khata (Credit) Ledger and Dunning Sequences
+-- Migration: 005_create_khata_dunning.sql
+
+CREATE TABLE IF NOT EXISTS khata_entries (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    kind VARCHAR(20) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    entry_date TIMESTAMP NOT NULL,
+    due_date TIMESTAMP NOT NULL,
+    reference_type VARCHAR(50),
+    reference_id UUID,
+    note TEXT,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_khata_entries_kind CHECK (kind IN ('charge', 'payment')),
+    CONSTRAINT chk_khata_entries_amount CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_khata_entries_customer ON khata_entries(tenant_id, customer_id, entry_date);
+
+CREATE TABLE IF NOT EXISTS dunning_sequences (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    min_overdue DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- One default sequence per tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dunning_sequences_default ON dunning_sequences(tenant_id) WHERE is_default;
+
+CREATE TABLE IF NOT EXISTS dunning_steps (
+    id UUID PRIMARY KEY,
+    sequence_id UUID NOT NULL REFERENCES dunning_sequences(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    days_overdue INTEGER NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    template_code VARCHAR(100) NOT NULL,
+    CONSTRAINT uq_dunning_steps_position UNIQUE (sequence_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS dunning_states (
+    tenant_id UUID NOT NULL,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    sequence_id UUID NOT NULL REFERENCES dunning_sequences(id) ON DELETE CASCADE,
+    last_position INTEGER NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    last_sent_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, customer_id)
+);
+
+CREATE TABLE IF NOT EXISTS dunning_notices (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    sequence_id UUID NOT NULL,
+    step_position INTEGER NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    recipient VARCHAR(255),
+    outstanding DECIMAL(15, 2) NOT NULL,
+    overdue DECIMAL(15, 2) NOT NULL,
+    days_overdue INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reason TEXT,
+    notification_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dunning_notices_customer ON dunning_notices(tenant_id, customer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_dunning_notices_created ON dunning_notices(tenant_id, created_at DESC);
+
+ALTER TABLE khata_entries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE dunning_sequences ENABLE ROW LEVEL SECURITY;
+ALTER TABLE dunning_states ENABLE ROW LEVEL SECURITY;
+ALTER TABLE dunning_notices ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON khata_entries
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON dunning_sequences
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON dunning_states
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON dunning_notices
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE khata_entries IS 'Customer credit ledger: goods given on credit (charge) and money received (payment)';
+COMMENT ON TABLE dunning_sequences IS 'Reminder schedules for overdue khata dues';
+COMMENT ON TABLE dunning_states IS 'How far each overdue customer is through the dunning sequence; cleared on payment';
+COMMENT ON TABLE dunning_notices IS 'Dues reminders sent, skipped or failed per customer';
@@ -0,0 +1,45 @@
+-- CRM Module: Bad Debt Write-offs
+-- Migration: 006_create_write_offs.sql
+
+ALTER TABLE khata_entries DROP CONSTRAINT IF EXISTS chk_khata_entries_kind;
+ALTER TABLE khata_entries ADD CONSTRAINT chk_khata_entries_kind CHECK (kind IN ('charge', 'payment', 'write_off'));
+
+CREATE TABLE IF NOT EXISTS write_offs (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    write_off_number VARCHAR(50) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    outstanding DECIMAL(15, 2) NOT NULL,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by UUID,
+    reviewed_by UUID,
+    reviewer_role VARCHAR(50),
+    review_note TEXT,
+    reviewed_at TIMESTAMP,
+    fiscal_year_id UUID,
+    fiscal_year_name VARCHAR(50),
+    khata_entry_id UUID REFERENCES khata_entries(id),
+    journal_entry_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_write_offs_number UNIQUE (tenant_id, write_off_number),
+    CONSTRAINT chk_write_offs_status CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    CONSTRAINT chk_write_offs_amount CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_write_offs_status ON write_offs(tenant_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_write_offs_customer ON write_offs(tenant_id, customer_id);
+CREATE INDEX IF NOT EXISTS idx_write_offs_fiscal_year ON write_offs(tenant_id, fiscal_year_id) WHERE status = 'approved';
+
+ALTER TABLE write_offs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON write_offs
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE write_offs IS 'Bad-debt write-off requests; approved ones close khata dues against the bad-debt expense account';
+COMMENT ON COLUMN write_offs.fiscal_year_id IS 'Fiscal year the write-off was approved in, for per-year bad-debt reporting';
@@ -0,0 +1,15 @@
+-- Migration: Structured address area indexes
+-- Structured addresses live in custom_attributes->'address_detail' (district, municipality, ward, tole, ...).
+-- These indexes back the district/municipality/ward filters used for delivery route planning.
+
+CREATE INDEX IF NOT EXISTS idx_customers_address_area ON customers (
+    tenant_id,
+    (custom_attributes->'address_detail'->>'district'),
+    (lower(custom_attributes->'address_detail'->>'municipality'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_suppliers_address_area ON suppliers (
+    tenant_id,
+    (custom_attributes->'address_detail'->>'district'),
+    (lower(custom_attributes->'address_detail'->>'municipality'))
+);
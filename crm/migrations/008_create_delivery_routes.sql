@@ -0,0 +1,93 @@
+-- CRM Module: Distributor delivery routes
+-- Migration: 008_create_delivery_routes.sql
+
+CREATE TABLE IF NOT EXISTS delivery_routes (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    weekdays SMALLINT[] NOT NULL DEFAULT '{}',   -- 0 = Sunday ... 6 = Saturday
+    driver_id UUID,
+    vehicle VARCHAR(100),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_delivery_routes_code UNIQUE (tenant_id, code)
+);
+
+-- A customer is on at most one route; sequence is the order the van reaches them
+CREATE TABLE IF NOT EXISTS route_customers (
+    tenant_id UUID NOT NULL,
+    route_id UUID NOT NULL REFERENCES delivery_routes(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, customer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_route_customers_route ON route_customers(tenant_id, route_id, sequence);
+
+CREATE TABLE IF NOT EXISTS deliveries (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    route_id UUID NOT NULL REFERENCES delivery_routes(id),
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    delivery_date DATE NOT NULL,
+    invoice_type VARCHAR(50),
+    invoice_id UUID,
+    invoice_number VARCHAR(50) NOT NULL,
+    invoice_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    collect_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    on_credit BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    collected_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    received_by VARCHAR(255),
+    note TEXT,
+    khata_entry_id UUID REFERENCES khata_entries(id),
+    completed_by UUID,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_deliveries_status CHECK (status IN ('pending', 'delivered', 'failed')),
+    CONSTRAINT chk_deliveries_amounts CHECK (invoice_amount >= 0 AND collect_amount >= 0 AND collected_amount >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_deliveries_sheet ON deliveries(tenant_id, route_id, delivery_date);
+CREATE INDEX IF NOT EXISTS idx_deliveries_invoice ON deliveries(tenant_id, invoice_number);
+
+CREATE TABLE IF NOT EXISTS delivery_lines (
+    id UUID PRIMARY KEY,
+    delivery_id UUID NOT NULL REFERENCES deliveries(id) ON DELETE CASCADE,
+    product_id UUID,
+    description VARCHAR(255) NOT NULL,
+    quantity DECIMAL(15, 3) NOT NULL,
+    unit VARCHAR(20) NOT NULL DEFAULT '',
+    CONSTRAINT chk_delivery_lines_quantity CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_lines_delivery ON delivery_lines(delivery_id);
+
+ALTER TABLE delivery_routes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE route_customers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE deliveries ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON delivery_routes
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON route_customers
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON deliveries
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE delivery_routes IS 'Fixed distributor routes driven on set weekdays';
+COMMENT ON TABLE deliveries IS 'Invoices queued for delivery on a route and day; drivers mark them delivered with cash collected';
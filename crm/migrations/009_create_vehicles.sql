@@ -0,0 +1,87 @@
+-- CRM Module: Delivery vehicles, trips and running costs
+-- Migration: 009_create_vehicles.sql
+
+CREATE TABLE IF NOT EXISTS vehicles (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    registration_number VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    kind VARCHAR(50) NOT NULL DEFAULT '',
+    capacity_kg DECIMAL(10, 2),
+    odometer_km DECIMAL(12, 1) NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_vehicles_registration UNIQUE (tenant_id, registration_number)
+);
+
+-- A trip is a vehicle running a route's delivery sheet (route + date)
+CREATE TABLE IF NOT EXISTS vehicle_trips (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    vehicle_id UUID NOT NULL REFERENCES vehicles(id),
+    route_id UUID NOT NULL REFERENCES delivery_routes(id),
+    trip_date DATE NOT NULL,
+    driver_id UUID,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    start_odometer DECIMAL(12, 1) NOT NULL DEFAULT 0,
+    end_odometer DECIMAL(12, 1),
+    note TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_vehicle_trips_status CHECK (status IN ('open', 'closed')),
+    CONSTRAINT chk_vehicle_trips_odometer CHECK (end_odometer IS NULL OR end_odometer >= start_odometer)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vehicle_trips_date ON vehicle_trips(tenant_id, trip_date);
+CREATE INDEX IF NOT EXISTS idx_vehicle_trips_sheet ON vehicle_trips(tenant_id, route_id, trip_date);
+
+CREATE TABLE IF NOT EXISTS vehicle_expenses (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    vehicle_id UUID NOT NULL REFERENCES vehicles(id),
+    trip_id UUID REFERENCES vehicle_trips(id),
+    kind VARCHAR(20) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    litres DECIMAL(10, 2),
+    odometer_km DECIMAL(12, 1),
+    expense_date DATE NOT NULL,
+    vendor VARCHAR(255),
+    bill_number VARCHAR(100),
+    note TEXT,
+    journal_entry_id UUID,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_vehicle_expenses_kind CHECK (kind IN ('fuel', 'maintenance', 'toll', 'other')),
+    CONSTRAINT chk_vehicle_expenses_amount CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vehicle_expenses_vehicle ON vehicle_expenses(tenant_id, vehicle_id, expense_date);
+CREATE INDEX IF NOT EXISTS idx_vehicle_expenses_date ON vehicle_expenses(tenant_id, expense_date);
+
+ALTER TABLE vehicles ENABLE ROW LEVEL SECURITY;
+ALTER TABLE vehicle_trips ENABLE ROW LEVEL SECURITY;
+ALTER TABLE vehicle_expenses ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON vehicles
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON vehicle_trips
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON vehicle_expenses
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE vehicle_trips IS 'Vehicle runs of a route delivery sheet, with odometer readings for cost allocation';
+COMMENT ON TABLE vehicle_expenses IS 'Fuel, maintenance and other vehicle costs; journal_entry_id links the expense booked in accounting';
@@ -0,0 +1,87 @@
+-- CRM Module: Supplier bill capture from email
+-- Migration: 010_create_bill_capture.sql
+
+-- One ingest address per tenant: <token>@<BILL_INBOX_DOMAIN>
+CREATE TABLE IF NOT EXISTS bill_inboxes (
+    tenant_id UUID PRIMARY KEY,
+    token VARCHAR(64) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_bill_inboxes_token UNIQUE (token)
+);
+
+CREATE TABLE IF NOT EXISTS inbound_emails (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    message_id VARCHAR(500) NOT NULL,
+    sender VARCHAR(255) NOT NULL DEFAULT '',
+    recipient VARCHAR(255) NOT NULL DEFAULT '',
+    subject TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    attachments INTEGER NOT NULL DEFAULT 0,
+    drafts INTEGER NOT NULL DEFAULT 0,
+    reason TEXT,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_inbound_emails_status CHECK (status IN ('processed', 'ignored')),
+    -- Providers retry deliveries; the message ID makes them idempotent
+    CONSTRAINT uq_inbound_emails_message UNIQUE (tenant_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbound_emails_received ON inbound_emails(tenant_id, received_at DESC);
+
+CREATE TABLE IF NOT EXISTS purchase_bill_drafts (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    inbound_email_id UUID REFERENCES inbound_emails(id),
+    supplier_id UUID REFERENCES suppliers(id),
+    sender_email VARCHAR(255) NOT NULL DEFAULT '',
+    subject TEXT NOT NULL DEFAULT '',
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    attachment_id UUID,
+    bill_number VARCHAR(100),
+    bill_date DATE,
+    supplier_pan VARCHAR(20),
+    sub_total DECIMAL(15, 2),
+    vat_amount DECIMAL(15, 2),
+    total_amount DECIMAL(15, 2),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending_review',
+    extraction_status VARCHAR(20) NOT NULL DEFAULT 'skipped',
+    extraction_confidence DECIMAL(4, 3),
+    extraction_error TEXT,
+    reviewed_by UUID,
+    reviewed_at TIMESTAMP,
+    reject_reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_purchase_bill_drafts_status CHECK (status IN ('pending_review', 'confirmed', 'rejected')),
+    CONSTRAINT chk_purchase_bill_drafts_extraction CHECK (extraction_status IN ('pending', 'extracted', 'failed', 'skipped'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_bill_drafts_status ON purchase_bill_drafts(tenant_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_purchase_bill_drafts_extraction ON purchase_bill_drafts(extraction_status, created_at)
+    WHERE extraction_status = 'pending';
+
+ALTER TABLE bill_inboxes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE inbound_emails ENABLE ROW LEVEL SECURITY;
+ALTER TABLE purchase_bill_drafts ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON bill_inboxes
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON inbound_emails
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON purchase_bill_drafts
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE purchase_bill_drafts IS 'Supplier bills captured from email, awaiting review; files are attachments of entity type purchase_bill';
@@ -0,0 +1,66 @@
+-- CRM Module: Purchase and Payment Approval Limits
+-- Migration: 011_create_approval_limits.sql
+
+CREATE TABLE IF NOT EXISTS approval_limits (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    document_type VARCHAR(30) NOT NULL,
+    role VARCHAR(50),
+    user_id UUID,
+    max_amount DECIMAL(15, 2) NOT NULL,
+    updated_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_approval_limits_document CHECK (document_type IN ('purchase_order', 'purchase_bill', 'supplier_payment')),
+    CONSTRAINT chk_approval_limits_subject CHECK ((role IS NULL) <> (user_id IS NULL)),
+    CONSTRAINT chk_approval_limits_amount CHECK (max_amount >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_approval_limits_role ON approval_limits(tenant_id, document_type, role) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_approval_limits_user ON approval_limits(tenant_id, document_type, user_id) WHERE user_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS approval_requests (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    document_type VARCHAR(30) NOT NULL,
+    document_id UUID NOT NULL,
+    document_number VARCHAR(100),
+    amount DECIMAL(15, 2) NOT NULL,
+    limit_amount DECIMAL(15, 2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by UUID NOT NULL,
+    requester_role VARCHAR(50) NOT NULL,
+    reviewed_by UUID,
+    reviewer_role VARCHAR(50),
+    review_note TEXT,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_approval_requests_status CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_requests_status ON approval_requests(tenant_id, status, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_approval_requests_pending ON approval_requests(tenant_id, document_type, document_id) WHERE status = 'pending';
+
+-- Draft purchase bills over the confirmer's limit wait for approval
+ALTER TABLE purchase_bill_drafts DROP CONSTRAINT IF EXISTS chk_purchase_bill_drafts_status;
+ALTER TABLE purchase_bill_drafts ADD CONSTRAINT chk_purchase_bill_drafts_status
+    CHECK (status IN ('pending_review', 'pending_approval', 'confirmed', 'rejected'));
+
+ALTER TABLE approval_limits ENABLE ROW LEVEL SECURITY;
+ALTER TABLE approval_requests ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON approval_limits
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON approval_requests
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE approval_limits IS 'Largest purchase or payment document a role or user may raise without approval; a user limit replaces the role limit';
+COMMENT ON TABLE approval_requests IS 'Documents over their author''s limit, held until an approver with a high enough limit decides';
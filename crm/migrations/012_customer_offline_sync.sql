@@ -0,0 +1,35 @@
+-- CRM Module: Offline customer creation
+-- Migration: 012_customer_offline_sync.sql
+
+-- IDs devices gave offline customers that were merged into an existing customer
+CREATE TABLE IF NOT EXISTS customer_aliases (
+    alias_id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    device_id VARCHAR(100),
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_aliases_customer ON customer_aliases(customer_id);
+
+-- Duplicate lookups compare phone and PAN as digits only
+CREATE INDEX IF NOT EXISTS idx_customers_phone_digits ON customers (
+    tenant_id,
+    (regexp_replace(regexp_replace(COALESCE(phone, ''), '[^0-9]', '', 'g'), '^977([0-9]{10,})$', '\1'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_customers_pan_digits ON customers (
+    tenant_id,
+    (regexp_replace(COALESCE(custom_attributes->>'pan_number', ''), '[^0-9]', '', 'g'))
+);
+
+ALTER TABLE customer_aliases ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON customer_aliases
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE customer_aliases IS 'Client-generated customer IDs merged into an existing customer during offline sync; re-syncing the ID returns that customer';
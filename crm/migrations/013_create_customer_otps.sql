@@ -0,0 +1,33 @@
+-- CRM Module: Customer verification codes for credit sales
+-- Migration: 013_create_customer_otps.sql
+
+CREATE TABLE IF NOT EXISTS customer_otps (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    channel VARCHAR(10) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    verified_at TIMESTAMP,
+    verified_by UUID,
+    invoice_id UUID,
+    notification_id UUID,
+    requested_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_customer_otps_channel CHECK (channel IN ('SMS', 'EMAIL'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_otps_customer ON customer_otps(tenant_id, customer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_customer_otps_invoice ON customer_otps(invoice_id) WHERE invoice_id IS NOT NULL;
+
+ALTER TABLE customer_otps ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON customer_otps
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE customer_otps IS 'One-time codes sent to a customer at the counter; a verified code is recorded on the credit sale it was taken for';
@@ -0,0 +1,16 @@
+-- CRM Module: Due dates from payment terms on supplier bills
+-- Migration: 014_payment_terms_due_dates.sql
+
+-- Customers' and suppliers' payment_terms custom attribute now holds structured terms,
+-- e.g. {"kind": "net", "netDays": 30}. Free text such as "30_days" is still read and is
+-- converted the next time the record is saved.
+
+ALTER TABLE purchase_bill_drafts ADD COLUMN IF NOT EXISTS due_date DATE;
+ALTER TABLE purchase_bill_drafts ADD COLUMN IF NOT EXISTS due_schedule JSONB;
+
+COMMENT ON COLUMN purchase_bill_drafts.due_date IS 'When the whole bill falls due, from the supplier''s payment terms unless set by the reviewer';
+COMMENT ON COLUMN purchase_bill_drafts.due_schedule IS 'Installments of the bill: sequence, dueDate, dueDateBs and amount';
+
+-- Payables aging reads confirmed bills by due date
+CREATE INDEX IF NOT EXISTS idx_purchase_bill_drafts_due ON purchase_bill_drafts(tenant_id, due_date)
+    WHERE status = 'confirmed';
@@ -0,0 +1,84 @@
+-- CRM Module: Warranties and claims on serial-numbered items
+-- Migration: 015_create_warranties.sql
+
+CREATE TABLE IF NOT EXISTS warranties (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    product_id UUID NOT NULL,
+    serial_number VARCHAR(100) NOT NULL,
+    customer_id UUID REFERENCES customers(id),
+    invoice_id UUID,
+    invoice_number VARCHAR(50),
+    sold_at TIMESTAMP NOT NULL,
+    warranty_months INT NOT NULL,
+    expires_at DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    replaces_id UUID REFERENCES warranties(id),
+    claim_id UUID,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_warranties_status CHECK (status IN ('active', 'replaced', 'void')),
+    CONSTRAINT chk_warranties_months CHECK (warranty_months > 0)
+);
+
+-- A unit is under one active warranty at a time
+CREATE UNIQUE INDEX IF NOT EXISTS uq_warranties_active_serial ON warranties(tenant_id, serial_number) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_warranties_serial ON warranties(tenant_id, serial_number, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_warranties_customer ON warranties(tenant_id, customer_id) WHERE customer_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_warranties_invoice ON warranties(tenant_id, invoice_id) WHERE invoice_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS warranty_claims (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    claim_number VARCHAR(50) NOT NULL,
+    warranty_id UUID NOT NULL REFERENCES warranties(id),
+    product_id UUID NOT NULL,
+    serial_number VARCHAR(100) NOT NULL,
+    customer_id UUID REFERENCES customers(id),
+    type VARCHAR(20) NOT NULL,
+    issue TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'received',
+    service_center VARCHAR(255),
+    sent_at TIMESTAMP,
+    outcome VARCHAR(20),
+    replacement_product_id UUID,
+    replacement_serial VARCHAR(100),
+    replacement_warranty_id UUID REFERENCES warranties(id),
+    resolution_note TEXT,
+    resolved_by UUID,
+    resolved_at TIMESTAMP,
+    closed_at TIMESTAMP,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_warranty_claims_number UNIQUE (tenant_id, claim_number),
+    CONSTRAINT chk_warranty_claims_type CHECK (type IN ('warranty', 'exchange')),
+    CONSTRAINT chk_warranty_claims_status CHECK (status IN ('received', 'in_service', 'resolved', 'closed', 'cancelled')),
+    CONSTRAINT chk_warranty_claims_outcome CHECK (outcome IS NULL OR outcome IN ('repaired', 'replaced', 'exchanged', 'rejected'))
+);
+
+-- One claim at a time per unit
+CREATE UNIQUE INDEX IF NOT EXISTS uq_warranty_claims_open ON warranty_claims(warranty_id) WHERE status IN ('received', 'in_service', 'resolved');
+CREATE INDEX IF NOT EXISTS idx_warranty_claims_status ON warranty_claims(tenant_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_warranty_claims_serial ON warranty_claims(tenant_id, serial_number);
+
+ALTER TABLE warranties ENABLE ROW LEVEL SECURITY;
+ALTER TABLE warranty_claims ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON warranties
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON warranty_claims
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+COMMENT ON TABLE warranties IS 'Warranty cover on serial-numbered units, registered at sale for the product''s warranty_months';
+COMMENT ON COLUMN warranties.expires_at IS 'Last covered day';
+COMMENT ON COLUMN warranties.replaces_id IS 'Warranty of the unit this one replaced under a claim; replacements keep the original expiry';
+COMMENT ON TABLE warranty_claims IS 'Repair, replacement and exchange tickets for sold units';
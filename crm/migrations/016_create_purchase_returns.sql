@@ -0,0 +1,71 @@
+-- CRM Module: Purchase Returns (RMA)
+-- Migration: 016_create_purchase_returns.sql
+-- Goods sent back to suppliers against a purchase receipt, with a reason per line.
+-- Returns against a payable receipt carry a debit note reducing what the supplier is owed.
+
+CREATE TABLE IF NOT EXISTS purchase_returns (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id),
+    return_number VARCHAR(50) NOT NULL,              -- RMA-00001
+    receipt_type VARCHAR(50) NOT NULL,               -- purchase_bill, or a receipt type registered by another module
+    receipt_id UUID NOT NULL,
+    receipt_number VARCHAR(100) NOT NULL DEFAULT '',
+    returned_at TIMESTAMP NOT NULL,
+    note TEXT,
+    sub_total DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    vat_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    debit_note_number VARCHAR(50),                   -- DN-00001, only against payable receipts
+    journal_entry_id UUID,
+    stock_posted_at TIMESTAMP,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_purchase_returns_number UNIQUE (tenant_id, return_number),
+    CONSTRAINT uq_purchase_returns_debit_note UNIQUE (tenant_id, debit_note_number),
+    CONSTRAINT chk_purchase_returns_amounts CHECK (sub_total >= 0 AND vat_amount >= 0 AND total_amount >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_returns_supplier ON purchase_returns(tenant_id, supplier_id, returned_at DESC);
+CREATE INDEX IF NOT EXISTS idx_purchase_returns_receipt ON purchase_returns(tenant_id, receipt_type, receipt_id);
+
+CREATE TABLE IF NOT EXISTS purchase_return_lines (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    return_id UUID NOT NULL REFERENCES purchase_returns(id) ON DELETE CASCADE,
+    receipt_line_id UUID,                            -- Line returned, when the receipt has lines
+    product_id UUID NOT NULL,
+    quantity DECIMAL(15, 3) NOT NULL,
+    unit_cost DECIMAL(15, 2) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    note TEXT,
+    CONSTRAINT chk_purchase_return_lines_qty CHECK (quantity > 0 AND unit_cost >= 0),
+    CONSTRAINT chk_purchase_return_lines_reason CHECK (
+        reason IN ('damaged', 'expired', 'wrong_item', 'defective', 'excess', 'other')
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_return_lines_return ON purchase_return_lines(return_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_return_lines_receipt_line ON purchase_return_lines(receipt_line_id)
+    WHERE receipt_line_id IS NOT NULL;
+
+COMMENT ON TABLE purchase_returns IS 'Goods returned to suppliers against a purchase receipt';
+COMMENT ON COLUMN purchase_returns.debit_note_number IS 'Debit note raised against the supplier payable; NULL when the receipt was not payable';
+COMMENT ON COLUMN purchase_returns.stock_posted_at IS 'When inventory recorded the goods leaving stock';
+COMMENT ON COLUMN purchase_return_lines.reason IS 'damaged, expired, wrong_item and defective count against supplier quality';
+
+ALTER TABLE purchase_returns ENABLE ROW LEVEL SECURITY;
+ALTER TABLE purchase_return_lines ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON purchase_returns
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON purchase_return_lines
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
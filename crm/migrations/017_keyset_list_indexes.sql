@@ -0,0 +1,7 @@
+-- Migration: Keyset pagination indexes
+-- Customer and supplier lists page by (created_at, id) instead of OFFSET, so each page
+-- is an index range scan however deep it is.
+
+CREATE INDEX IF NOT EXISTS idx_customers_tenant_created ON customers(tenant_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_suppliers_tenant_created ON suppliers(tenant_id, created_at DESC, id DESC);
@@ -0,0 +1,134 @@
+package crm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/crm/domain"
+	paymentsDomain "github.com/aceextension/payments/domain"
+	"github.com/google/uuid"
+)
+
+// Khata reference types of entries made for payments
+const (
+	khataReferencePayment     = "payment"
+	khataReferencePaymentVoid = "payment_void"
+)
+
+// openBillsLimit bounds how many of a supplier's bills automatic allocation looks at
+const openBillsLimit = 500
+
+// crmParties names the customers and suppliers payments are made with
+type crmParties struct{}
+
+// PartyName returns the customer's or supplier's name
+func (crmParties) PartyName(ctx context.Context, tenantID uuid.UUID, partyType string, partyID uuid.UUID) (string, error) {
+	switch partyType {
+	case paymentsDomain.PartyCustomer:
+		customer, err := CustomerService.GetByID(ctx, tenantID, partyID)
+		if err != nil {
+			return "", fmt.Errorf("%w: customer %s", paymentsDomain.ErrPartyNotFound, partyID)
+		}
+		return customer.Name, nil
+	case paymentsDomain.PartySupplier:
+		supplier, err := SupplierService.GetByID(ctx, tenantID, partyID)
+		if err != nil {
+			return "", fmt.Errorf("%w: supplier %s", paymentsDomain.ErrPartyNotFound, partyID)
+		}
+		return supplier.Name, nil
+	}
+	return "", fmt.Errorf("%w: unknown party type %q", paymentsDomain.ErrPartyNotFound, partyType)
+}
+
+// khataReceipts records customer receipts on their khata, so dues and dunning
+// follow payments
+type khataReceipts struct{}
+
+// RecordReceipt records the receipt as money received
+func (khataReceipts) RecordReceipt(ctx context.Context, payment *paymentsDomain.Payment) error {
+	entry := domain.NewKhataEntry(payment.TenantID, payment.PartyID, domain.KhataPayment, payment.Amount, payment.PaymentDate, payment.PaymentDate)
+	referenceType := khataReferencePayment
+	note := fmt.Sprintf("Receipt by %s", payment.Method)
+	if payment.Reference != nil {
+		note += " (" + *payment.Reference + ")"
+	}
+	entry.ReferenceType = &referenceType
+	entry.ReferenceID = &payment.ID
+	entry.Note = &note
+	entry.CreatedBy = payment.CreatedBy
+
+	return KhataService.RecordPayment(ctx, entry)
+}
+
+// ReverseReceipt charges a voided receipt back to the customer, due at once
+func (khataReceipts) ReverseReceipt(ctx context.Context, payment *paymentsDomain.Payment) error {
+	voidedAt := time.Now()
+	if payment.VoidedAt != nil {
+		voidedAt = *payment.VoidedAt
+	}
+	entry := domain.NewKhataEntry(payment.TenantID, payment.PartyID, domain.KhataCharge, payment.Amount, voidedAt, voidedAt)
+	referenceType := khataReferencePaymentVoid
+	note := "Void of receipt"
+	if payment.VoidReason != nil {
+		note += ": " + *payment.VoidReason
+	}
+	entry.ReferenceType = &referenceType
+	entry.ReferenceID = &payment.ID
+	entry.Note = &note
+	entry.CreatedBy = payment.VoidedBy
+
+	_, err := KhataService.RecordCharge(ctx, entry)
+	return err
+}
+
+// paymentBill reads a confirmed purchase bill as a document paid to its supplier
+func paymentBill(ctx context.Context, tenantID, id uuid.UUID) (*paymentsDomain.Document, error) {
+	draft, err := BillCaptureService.GetDraft(ctx, tenantID, id)
+	if errors.Is(err, domain.ErrBillDraftNotFound) {
+		return nil, fmt.Errorf("%w: purchase bill %s", paymentsDomain.ErrDocumentNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != domain.BillDraftConfirmed || draft.TotalAmount == nil {
+		return nil, fmt.Errorf("%w: purchase bill is %s", paymentsDomain.ErrDocumentNotPayable, draft.Status)
+	}
+	return toPaymentDocument(draft), nil
+}
+
+// openPaymentBills lists the supplier's confirmed bills
+func openPaymentBills(ctx context.Context, tenantID, supplierID uuid.UUID) ([]*paymentsDomain.Document, error) {
+	drafts, err := BillCaptureService.SupplierBills(ctx, tenantID, supplierID, openBillsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]*paymentsDomain.Document, 0, len(drafts))
+	for _, draft := range drafts {
+		if draft.TotalAmount != nil {
+			docs = append(docs, toPaymentDocument(draft))
+		}
+	}
+	return docs, nil
+}
+
+// toPaymentDocument copies what payments need from a confirmed bill
+func toPaymentDocument(draft *domain.PurchaseBillDraft) *paymentsDomain.Document {
+	doc := &paymentsDomain.Document{
+		ID:        draft.ID,
+		Number:    draft.FileName,
+		PartyID:   draft.SupplierID,
+		PartyName: draft.SenderEmail,
+		Date:      draft.CreatedAt,
+		Total:     *draft.TotalAmount,
+	}
+	if draft.BillNumber != nil {
+		doc.Number = *draft.BillNumber
+	}
+	if draft.BillDate != nil {
+		doc.Date = *draft.BillDate
+	}
+	return doc
+}
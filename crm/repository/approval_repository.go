@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// ApprovalRepository defines the interface for approval limits and requests
+type ApprovalRepository interface {
+	// UpsertLimit saves a limit, replacing the one for the same document type and role or user
+	UpsertLimit(ctx context.Context, limit *domain.ApprovalLimit) error
+	GetLimit(ctx context.Context, tenantID, id uuid.UUID) (*domain.ApprovalLimit, error)
+	ListLimits(ctx context.Context, tenantID uuid.UUID, documentType *domain.ApprovalDocumentType) ([]*domain.ApprovalLimit, error)
+	DeleteLimit(ctx context.Context, tenantID, id uuid.UUID) error
+	// EffectiveLimit returns the user's own limit for a document type, else their role's, else nil
+	EffectiveLimit(ctx context.Context, tenantID uuid.UUID, documentType domain.ApprovalDocumentType, userID uuid.UUID, role string) (*domain.ApprovalLimit, error)
+
+	CreateRequest(ctx context.Context, request *domain.ApprovalRequest) error
+	GetRequest(ctx context.Context, tenantID, id uuid.UUID) (*domain.ApprovalRequest, error)
+	// GetPendingRequest returns the pending request for a document, ErrApprovalRequestNotFound if there is none
+	GetPendingRequest(ctx context.Context, tenantID uuid.UUID, documentType domain.ApprovalDocumentType, documentID uuid.UUID) (*domain.ApprovalRequest, error)
+	ListRequests(ctx context.Context, tenantID uuid.UUID, status *domain.ApprovalStatus, documentType *domain.ApprovalDocumentType, limit, offset int) ([]*domain.ApprovalRequest, error)
+	// UpdateRequest saves a decision or cancellation; ErrApprovalState if it was decided concurrently
+	UpdateRequest(ctx context.Context, request *domain.ApprovalRequest) error
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// BillCaptureRepository defines the interface for bill inboxes, received emails and draft purchase bills
+type BillCaptureRepository interface {
+	// GetInbox retrieves a tenant's inbox
+	GetInbox(ctx context.Context, tenantID uuid.UUID) (*domain.BillInbox, error)
+	// GetInboxByToken finds the inbox an email was sent to, across tenants
+	GetInboxByToken(ctx context.Context, token string) (*domain.BillInbox, error)
+	// SaveInbox creates the tenant's inbox or replaces its token
+	SaveInbox(ctx context.Context, inbox *domain.BillInbox) error
+
+	// RecordEmail logs a received email; ErrDuplicateInboundEmail if its message ID was seen before
+	RecordEmail(ctx context.Context, record *domain.InboundEmailRecord) error
+	UpdateEmail(ctx context.Context, record *domain.InboundEmailRecord) error
+	ListEmails(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.InboundEmailRecord, error)
+
+	CreateDraft(ctx context.Context, draft *domain.PurchaseBillDraft) error
+	GetDraft(ctx context.Context, tenantID, id uuid.UUID) (*domain.PurchaseBillDraft, error)
+	// UpdateDraft saves a draft still pending review; ErrBillDraftState once it was reviewed
+	UpdateDraft(ctx context.Context, draft *domain.PurchaseBillDraft) error
+	// SaveReview saves a draft's review moving it on from status from; ErrBillDraftState
+	// if it was reviewed concurrently
+	SaveReview(ctx context.Context, draft *domain.PurchaseBillDraft, from domain.BillDraftStatus) error
+	ListDrafts(ctx context.Context, tenantID uuid.UUID, status *domain.BillDraftStatus, limit, offset int) ([]*domain.PurchaseBillDraft, error)
+	// SupplierBills lists a supplier's confirmed bills, oldest bill date first
+	SupplierBills(ctx context.Context, tenantID, supplierID uuid.UUID, limit int) ([]*domain.PurchaseBillDraft, error)
+	// DraftExists reports whether a draft belongs to the tenant, for attachment checks
+	DraftExists(ctx context.Context, tenantID, id uuid.UUID) (bool, error)
+	// PurchaseRegister lists confirmed bills dated from..to, oldest first
+	PurchaseRegister(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.PurchaseRegisterLine, error)
+	// ConfirmedTotalsBySupplier sums confirmed bills dated from..to per supplier, before VAT
+	ConfirmedTotalsBySupplier(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (map[uuid.UUID]float64, error)
+	// PendingExtractions retrieves drafts waiting for extraction, oldest first, across tenants
+	PendingExtractions(ctx context.Context, limit int) ([]*domain.PurchaseBillDraft, error)
+	// SaveExtraction stores extraction results, whatever the review status
+	SaveExtraction(ctx context.Context, draft *domain.PurchaseBillDraft) error
+
+	// MatchSupplier finds the supplier with this email address, or else the only
+	// supplier on the sender's (non-webmail) domain; nil when there is no match
+	MatchSupplier(ctx context.Context, tenantID uuid.UUID, email string) (*uuid.UUID, error)
+	// MatchSupplierByPAN finds the supplier with this PAN; nil when there is no match
+	MatchSupplierByPAN(ctx context.Context, tenantID uuid.UUID, pan string) (*uuid.UUID, error)
+}
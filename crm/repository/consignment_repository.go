@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// ConsignmentRepository defines the interface for consignment stock data access
+type ConsignmentRepository interface {
+	// CreateReceipt saves a receipt with its lines, assigning the next receipt number
+	CreateReceipt(ctx context.Context, receipt *domain.ConsignmentReceipt) error
+	GetReceipt(ctx context.Context, tenantID, id uuid.UUID) (*domain.ConsignmentReceipt, error)
+	ListReceipts(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, limit, offset int) ([]*domain.ConsignmentReceipt, error)
+
+	// Settle consumes sold items from open consignment stock (oldest first) and saves
+	// one bill per supplier whose stock was sold. Items not on consignment are ignored.
+	Settle(ctx context.Context, tenantID uuid.UUID, items []domain.ConsignmentSaleItem, saleType *string, saleID *uuid.UUID) ([]*domain.ConsignmentBill, error)
+	// ReturnToSupplier marks unsold stock on a line as sent back
+	ReturnToSupplier(ctx context.Context, tenantID, lineID uuid.UUID, quantity float64) error
+
+	GetBill(ctx context.Context, tenantID, id uuid.UUID) (*domain.ConsignmentBill, error)
+	ListBills(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, limit, offset int) ([]*domain.ConsignmentBill, error)
+	SetBillJournalEntry(ctx context.Context, billID, journalEntryID uuid.UUID) error
+
+	// StockReport aggregates consignment stock per supplier and product
+	StockReport(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID) ([]*domain.ConsignmentStock, error)
+}
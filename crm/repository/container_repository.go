@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// ContainerRepository defines the interface for returnable container data access
+type ContainerRepository interface {
+	CreateType(ctx context.Context, containerType *domain.ContainerType) error
+	GetTypeByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.ContainerType, error)
+	ListTypes(ctx context.Context, tenantID uuid.UUID) ([]*domain.ContainerType, error)
+	UpdateType(ctx context.Context, containerType *domain.ContainerType) error
+
+	// Issue records containers going to a customer and adds the deposit to their balance
+	Issue(ctx context.Context, movement *domain.ContainerMovement) error
+	// Return records containers coming back, setting the refund from the deposit held.
+	// Returns ErrInsufficientContainers if the customer holds fewer than returned.
+	Return(ctx context.Context, movement *domain.ContainerMovement) error
+	SetJournalEntry(ctx context.Context, movementID, journalEntryID uuid.UUID) error
+	ListMovements(ctx context.Context, tenantID, customerID uuid.UUID, limit, offset int) ([]*domain.ContainerMovement, error)
+
+	// ListBalances returns non-zero balances, for one customer when customerID is set
+	ListBalances(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID) ([]*domain.ContainerBalance, error)
+}
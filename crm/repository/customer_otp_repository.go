@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// CustomerOTPRepository defines the interface for customer verification code data access
+type CustomerOTPRepository interface {
+	Create(ctx context.Context, otp *domain.CustomerOTP) error
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.CustomerOTP, error)
+
+	// Latest retrieves the customer's most recent challenge, or nil if none was sent
+	Latest(ctx context.Context, tenantID, customerID uuid.UUID) (*domain.CustomerOTP, error)
+
+	// CountAttempt adds an attempt to a challenge still open for codes and returns the
+	// new count, in one statement so concurrent guesses are all counted. It fails with
+	// ErrOTPExpired once the challenge is verified or out of attempts.
+	CountAttempt(ctx context.Context, tenantID, id uuid.UUID) (int, error)
+	// SaveVerified records who verified the challenge
+	SaveVerified(ctx context.Context, otp *domain.CustomerOTP) error
+	SetNotification(ctx context.Context, id, notificationID uuid.UUID) error
+
+	// AttachInvoice records the sale a verification was used for. It fails with
+	// ErrOTPNotVerified if the verification was used concurrently.
+	AttachInvoice(ctx context.Context, tenantID, id, invoiceID uuid.UUID) error
+}
@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 
+	"github.com/aceextension/core/db"
 	"github.com/aceextension/crm/domain"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 )
 
@@ -12,20 +14,23 @@ type CustomerRepository interface {
 	// Create creates a new customer
 	Create(ctx context.Context, customer *domain.Customer) error
 
-	// GetByID retrieves a customer by ID
-	GetByID(ctx context.Context, id uuid.UUID) (*domain.Customer, error)
+	// GetByID retrieves a tenant's customer by ID; another tenant's customer is ErrCustomerNotFound
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Customer, error)
 
 	// GetByCode retrieves a customer by customer code
 	GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.Customer, error)
 
-	// GetByTenantID retrieves all customers for a tenant
-	GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Customer, error)
+	// List retrieves a page of customers, newest first, with the total; a tags filter keeps customers carrying them
+	List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) ([]*domain.Customer, int64, error)
 
-	// Update updates a customer
+	// StreamByTenantID passes every customer for a tenant to fn as it is read
+	StreamByTenantID(ctx context.Context, tenantID uuid.UUID, fn func(*domain.Customer) error) error
+
+	// Update updates a customer of customer.TenantID
 	Update(ctx context.Context, customer *domain.Customer) error
 
-	// Delete deletes a customer
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete deletes a tenant's customer
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
 
 	// Search searches customers by name, email, or phone
 	Search(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) ([]*domain.Customer, error)
@@ -33,9 +38,29 @@ type CustomerRepository interface {
 	// SearchByCustomAttribute searches customers by custom attribute
 	SearchByCustomAttribute(ctx context.Context, tenantID uuid.UUID, key, value string) ([]*domain.Customer, error)
 
+	// ListByArea retrieves customers with a structured address in the given district (and municipality/ward),
+	// ordered for walking a delivery route
+	ListByArea(ctx context.Context, tenantID uuid.UUID, filter domain.AreaFilter, page db.PageRequest) ([]*domain.Customer, int64, error)
+
+	// ListByTags retrieves customers carrying the filter's tags, newest first; a non-empty query
+	// also matches name, email, phone or code
+	ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*domain.Customer, error)
+
 	// Count returns total number of customers for a tenant
 	Count(ctx context.Context, tenantID uuid.UUID) (int64, error)
 
+	// CountByIDs returns how many of the given customers belong to the tenant
+	CountByIDs(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (int, error)
+
 	// GetNextCustomerNumber gets the next customer number for code generation
 	GetNextCustomerNumber(ctx context.Context, tenantID uuid.UUID) (int, error)
+
+	// FindDuplicates retrieves customers whose normalized phone or PAN equals the given one; empty keys are ignored
+	FindDuplicates(ctx context.Context, tenantID uuid.UUID, phone, pan string, limit int) ([]*domain.Customer, error)
+
+	// GetAlias returns the customer a client-generated ID was merged into, or nil if it was not
+	GetAlias(ctx context.Context, tenantID, aliasID uuid.UUID) (*uuid.UUID, error)
+
+	// MergeOffline saves the merged customer and records the offline ID as its alias in one transaction
+	MergeOffline(ctx context.Context, customer *domain.Customer, alias *domain.CustomerAlias) error
 }
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// DeliveryRepository defines the interface for delivery routes, route assignments and deliveries
+type DeliveryRepository interface {
+	CreateRoute(ctx context.Context, route *domain.DeliveryRoute) error
+	UpdateRoute(ctx context.Context, route *domain.DeliveryRoute) error
+	GetRoute(ctx context.Context, tenantID, id uuid.UUID) (*domain.DeliveryRoute, error)
+	// ListRoutes retrieves routes by code; driverID limits them to one driver's routes
+	ListRoutes(ctx context.Context, tenantID uuid.UUID, driverID *uuid.UUID, activeOnly bool) ([]*domain.DeliveryRoute, error)
+
+	// AssignCustomer puts a customer on a route at sequence, moving them off any other route
+	AssignCustomer(ctx context.Context, tenantID, routeID, customerID uuid.UUID, sequence int) error
+	RemoveCustomer(ctx context.Context, tenantID, routeID, customerID uuid.UUID) error
+	// ListStops retrieves a route's customers in visiting order
+	ListStops(ctx context.Context, tenantID, routeID uuid.UUID) ([]*domain.RouteStop, error)
+	// CustomerRoute returns the route a customer is on, or nil
+	CustomerRoute(ctx context.Context, tenantID, customerID uuid.UUID) (*uuid.UUID, error)
+
+	// CreateDelivery saves a delivery with its lines
+	CreateDelivery(ctx context.Context, delivery *domain.Delivery) error
+	GetDelivery(ctx context.Context, tenantID, id uuid.UUID) (*domain.Delivery, error)
+	// ListDeliveries retrieves a route's deliveries for a day, with their lines
+	ListDeliveries(ctx context.Context, tenantID, routeID uuid.UUID, date time.Time) ([]*domain.Delivery, error)
+	// Complete saves a delivered or failed delivery, and the khata payment for money
+	// collected against credit, in one transaction. It fails with ErrDeliveryState if
+	// the delivery was completed concurrently.
+	Complete(ctx context.Context, delivery *domain.Delivery, payment *domain.KhataEntry) error
+}
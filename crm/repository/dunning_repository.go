@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// DunningRepository defines the interface for dunning sequences, progress and notices
+type DunningRepository interface {
+	// Sequences; saving a default sequence clears the flag on the tenant's others
+	CreateSequence(ctx context.Context, sequence *domain.DunningSequence) error
+	GetSequence(ctx context.Context, tenantID, id uuid.UUID) (*domain.DunningSequence, error)
+	// GetDefaultSequence returns the tenant's active default sequence, or nil
+	GetDefaultSequence(ctx context.Context, tenantID uuid.UUID) (*domain.DunningSequence, error)
+	ListSequences(ctx context.Context, tenantID uuid.UUID) ([]*domain.DunningSequence, error)
+	// UpdateSequence saves the sequence's settings and replaces its steps
+	UpdateSequence(ctx context.Context, sequence *domain.DunningSequence) error
+	DeleteSequence(ctx context.Context, tenantID, id uuid.UUID) error
+	// ListTenantsWithSequences returns tenants with an active default sequence
+	ListTenantsWithSequences(ctx context.Context) ([]uuid.UUID, error)
+
+	// Per-customer progress
+	ListStates(ctx context.Context, tenantID uuid.UUID) (map[uuid.UUID]*domain.DunningState, error)
+	SaveState(ctx context.Context, state *domain.DunningState) error
+	ClearState(ctx context.Context, tenantID, customerID uuid.UUID) error
+
+	// Notices
+	CreateNotice(ctx context.Context, notice *domain.DunningNotice) error
+	ListNotices(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, limit, offset int) ([]*domain.DunningNotice, error)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// KhataRepository defines the interface for customer credit ledger data access
+type KhataRepository interface {
+	CreateEntry(ctx context.Context, entry *domain.KhataEntry) error
+	// CreateEntries creates the installments of one charge together
+	CreateEntries(ctx context.Context, entries []*domain.KhataEntry) error
+	// ListEntries returns a customer's ledger, newest first
+	ListEntries(ctx context.Context, tenantID, customerID uuid.UUID, limit, offset int) ([]*domain.KhataEntry, error)
+	// CustomerEntries returns every entry of a customer, for aging
+	CustomerEntries(ctx context.Context, tenantID, customerID uuid.UUID) ([]*domain.KhataEntry, error)
+	// ListDebtors returns customers with a positive balance (code and name only, not yet aged)
+	ListDebtors(ctx context.Context, tenantID uuid.UUID) ([]*domain.CustomerDues, error)
+	// OpenEntries returns every entry of customers with a positive balance, grouped by customer
+	OpenEntries(ctx context.Context, tenantID uuid.UUID) (map[uuid.UUID][]*domain.KhataEntry, error)
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresApprovalRepository implements ApprovalRepository using PostgreSQL
+type PostgresApprovalRepository struct{}
+
+// NewPostgresApprovalRepository creates a new PostgreSQL approval repository
+func NewPostgresApprovalRepository() *PostgresApprovalRepository {
+	return &PostgresApprovalRepository{}
+}
+
+const approvalLimitColumns = `id, tenant_id, document_type, role, user_id, max_amount, updated_by, created_at, updated_at`
+
+const approvalRequestColumns = `id, tenant_id, document_type, document_id, document_number, amount, limit_amount, status,
+	requested_by, requester_role, reviewed_by, reviewer_role, review_note, reviewed_at, created_at, updated_at`
+
+// UpsertLimit saves a role or user limit. Role and user limits have separate
+// unique indexes, so the conflict target depends on which one is set.
+func (r *PostgresApprovalRepository) UpsertLimit(ctx context.Context, limit *domain.ApprovalLimit) error {
+	conflict := `(tenant_id, document_type, role) WHERE user_id IS NULL`
+	if limit.UserID != nil {
+		conflict = `(tenant_id, document_type, user_id) WHERE user_id IS NOT NULL`
+	}
+
+	query := `
+		INSERT INTO approval_limits (` + approvalLimitColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT ` + conflict + ` DO UPDATE
+		SET max_amount = EXCLUDED.max_amount, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+
+	err := db.MainPool.QueryRow(ctx, query,
+		limit.ID, limit.TenantID, limit.DocumentType, limit.Role, limit.UserID, limit.MaxAmount,
+		limit.UpdatedBy, limit.CreatedAt, limit.UpdatedAt,
+	).Scan(&limit.ID, &limit.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save approval limit: %w", err)
+	}
+
+	return nil
+}
+
+// GetLimit retrieves an approval limit
+func (r *PostgresApprovalRepository) GetLimit(ctx context.Context, tenantID, id uuid.UUID) (*domain.ApprovalLimit, error) {
+	query := `SELECT ` + approvalLimitColumns + ` FROM approval_limits WHERE tenant_id = $1 AND id = $2`
+
+	limit, err := scanApprovalLimit(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrApprovalLimitNotFound
+		}
+		return nil, fmt.Errorf("failed to get approval limit: %w", err)
+	}
+
+	return limit, nil
+}
+
+// ListLimits retrieves limits by document type, role limits before user limits
+func (r *PostgresApprovalRepository) ListLimits(ctx context.Context, tenantID uuid.UUID, documentType *domain.ApprovalDocumentType) ([]*domain.ApprovalLimit, error) {
+	query := `
+		SELECT ` + approvalLimitColumns + `
+		FROM approval_limits
+		WHERE tenant_id = $1 AND ($2::varchar IS NULL OR document_type = $2)
+		ORDER BY document_type, user_id NULLS FIRST, role, max_amount
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, documentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval limits: %w", err)
+	}
+	defer rows.Close()
+
+	limits := []*domain.ApprovalLimit{}
+	for rows.Next() {
+		limit, err := scanApprovalLimit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan approval limit: %w", err)
+		}
+		limits = append(limits, limit)
+	}
+
+	return limits, rows.Err()
+}
+
+// DeleteLimit removes a limit; its role or user is no longer restricted by it
+func (r *PostgresApprovalRepository) DeleteLimit(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := db.MainPool.Exec(ctx, `DELETE FROM approval_limits WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete approval limit: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrApprovalLimitNotFound
+	}
+
+	return nil
+}
+
+// EffectiveLimit returns the user's limit, else the role's, else nil
+func (r *PostgresApprovalRepository) EffectiveLimit(ctx context.Context, tenantID uuid.UUID, documentType domain.ApprovalDocumentType, userID uuid.UUID, role string) (*domain.ApprovalLimit, error) {
+	query := `
+		SELECT ` + approvalLimitColumns + `
+		FROM approval_limits
+		WHERE tenant_id = $1 AND document_type = $2 AND (user_id = $3 OR (user_id IS NULL AND role = $4))
+		ORDER BY user_id NULLS LAST
+		LIMIT 1
+	`
+
+	limit, err := scanApprovalLimit(db.MainPool.QueryRow(ctx, query, tenantID, documentType, userID, role))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get approval limit: %w", err)
+	}
+
+	return limit, nil
+}
+
+// CreateRequest saves a pending approval request
+func (r *PostgresApprovalRepository) CreateRequest(ctx context.Context, request *domain.ApprovalRequest) error {
+	query := `INSERT INTO approval_requests (` + approvalRequestColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		request.ID, request.TenantID, request.DocumentType, request.DocumentID, request.DocumentNumber,
+		request.Amount, request.LimitAmount, request.Status,
+		request.RequestedBy, request.RequesterRole, request.ReviewedBy, request.ReviewerRole, request.ReviewNote, request.ReviewedAt,
+		request.CreatedAt, request.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create approval request: %w", err)
+	}
+
+	return nil
+}
+
+// GetRequest retrieves an approval request
+func (r *PostgresApprovalRepository) GetRequest(ctx context.Context, tenantID, id uuid.UUID) (*domain.ApprovalRequest, error) {
+	query := `SELECT ` + approvalRequestColumns + ` FROM approval_requests WHERE tenant_id = $1 AND id = $2`
+	return r.getRequest(ctx, query, tenantID, id)
+}
+
+// GetPendingRequest retrieves the pending request for a document
+func (r *PostgresApprovalRepository) GetPendingRequest(ctx context.Context, tenantID uuid.UUID, documentType domain.ApprovalDocumentType, documentID uuid.UUID) (*domain.ApprovalRequest, error) {
+	query := `SELECT ` + approvalRequestColumns + ` FROM approval_requests
+		WHERE tenant_id = $1 AND document_type = $2 AND document_id = $3 AND status = 'pending'`
+	return r.getRequest(ctx, query, tenantID, documentType, documentID)
+}
+
+func (r *PostgresApprovalRepository) getRequest(ctx context.Context, query string, args ...any) (*domain.ApprovalRequest, error) {
+	request, err := scanApprovalRequest(db.MainPool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrApprovalRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get approval request: %w", err)
+	}
+
+	return request, nil
+}
+
+// ListRequests retrieves approval requests, newest first, optionally by status and document type
+func (r *PostgresApprovalRepository) ListRequests(ctx context.Context, tenantID uuid.UUID, status *domain.ApprovalStatus, documentType *domain.ApprovalDocumentType, limit, offset int) ([]*domain.ApprovalRequest, error) {
+	query := `
+		SELECT ` + approvalRequestColumns + `
+		FROM approval_requests
+		WHERE tenant_id = $1
+		  AND ($2::varchar IS NULL OR status = $2)
+		  AND ($3::varchar IS NULL OR document_type = $3)
+		ORDER BY created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, status, documentType, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []*domain.ApprovalRequest{}
+	for rows.Next() {
+		request, err := scanApprovalRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan approval request: %w", err)
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, rows.Err()
+}
+
+// UpdateRequest saves the decision or cancellation of a pending request
+func (r *PostgresApprovalRepository) UpdateRequest(ctx context.Context, request *domain.ApprovalRequest) error {
+	query := `
+		UPDATE approval_requests
+		SET status = $1, reviewed_by = $2, reviewer_role = $3, review_note = $4, reviewed_at = $5, updated_at = $6
+		WHERE tenant_id = $7 AND id = $8 AND status = 'pending'
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		request.Status, request.ReviewedBy, request.ReviewerRole, request.ReviewNote, request.ReviewedAt, request.UpdatedAt,
+		request.TenantID, request.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update approval request: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrApprovalState
+	}
+
+	return nil
+}
+
+func scanApprovalLimit(row pgx.Row) (*domain.ApprovalLimit, error) {
+	var limit domain.ApprovalLimit
+	err := row.Scan(
+		&limit.ID, &limit.TenantID, &limit.DocumentType, &limit.Role, &limit.UserID, &limit.MaxAmount,
+		&limit.UpdatedBy, &limit.CreatedAt, &limit.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+func scanApprovalRequest(row pgx.Row) (*domain.ApprovalRequest, error) {
+	var request domain.ApprovalRequest
+	err := row.Scan(
+		&request.ID, &request.TenantID, &request.DocumentType, &request.DocumentID, &request.DocumentNumber,
+		&request.Amount, &request.LimitAmount, &request.Status,
+		&request.RequestedBy, &request.RequesterRole, &request.ReviewedBy, &request.ReviewerRole, &request.ReviewNote, &request.ReviewedAt,
+		&request.CreatedAt, &request.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
@@ -0,0 +1,477 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresBillCaptureRepository implements BillCaptureRepository using PostgreSQL
+type PostgresBillCaptureRepository struct{}
+
+// NewPostgresBillCaptureRepository creates a new PostgreSQL bill capture repository
+func NewPostgresBillCaptureRepository() *PostgresBillCaptureRepository {
+	return &PostgresBillCaptureRepository{}
+}
+
+const inboundEmailColumns = `id, tenant_id, message_id, sender, recipient, subject, status, attachments, drafts, reason, received_at`
+
+const billDraftColumns = `id, tenant_id, inbound_email_id, supplier_id, sender_email, subject, file_name, attachment_id,
+	bill_number, bill_date, supplier_pan, sub_total, vat_amount, total_amount, due_date, due_schedule,
+	status, extraction_status, extraction_confidence, extraction_error,
+	reviewed_by, reviewed_at, reject_reason, created_at, updated_at`
+
+// webmailDomains are shared mail providers; a sender on one of these only matches a supplier by full address
+var webmailDomains = map[string]bool{
+	"gmail.com": true, "yahoo.com": true, "hotmail.com": true, "outlook.com": true, "live.com": true,
+	"icloud.com": true, "proton.me": true, "protonmail.com": true, "wlink.com.np": true, "ntc.net.np": true,
+}
+
+// GetInbox retrieves a tenant's inbox
+func (r *PostgresBillCaptureRepository) GetInbox(ctx context.Context, tenantID uuid.UUID) (*domain.BillInbox, error) {
+	query := `SELECT tenant_id, token, is_active, created_at, updated_at FROM bill_inboxes WHERE tenant_id = $1`
+
+	inbox, err := scanBillInbox(db.MainPool.QueryRow(ctx, query, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBillInboxNotFound
+		}
+		return nil, fmt.Errorf("failed to get bill inbox: %w", err)
+	}
+
+	return inbox, nil
+}
+
+// GetInboxByToken finds an active inbox by its token
+func (r *PostgresBillCaptureRepository) GetInboxByToken(ctx context.Context, token string) (*domain.BillInbox, error) {
+	query := `SELECT tenant_id, token, is_active, created_at, updated_at FROM bill_inboxes WHERE token = $1 AND is_active`
+
+	inbox, err := scanBillInbox(db.MainPool.QueryRow(ctx, query, token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBillInboxNotFound
+		}
+		return nil, fmt.Errorf("failed to get bill inbox: %w", err)
+	}
+
+	return inbox, nil
+}
+
+// SaveInbox creates or replaces a tenant's inbox
+func (r *PostgresBillCaptureRepository) SaveInbox(ctx context.Context, inbox *domain.BillInbox) error {
+	query := `
+		INSERT INTO bill_inboxes (tenant_id, token, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET token = EXCLUDED.token, is_active = EXCLUDED.is_active, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, inbox.TenantID, inbox.Token, inbox.IsActive, inbox.CreatedAt, inbox.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save bill inbox: %w", err)
+	}
+
+	return nil
+}
+
+// RecordEmail logs a received email once per message ID
+func (r *PostgresBillCaptureRepository) RecordEmail(ctx context.Context, record *domain.InboundEmailRecord) error {
+	query := `INSERT INTO inbound_emails (` + inboundEmailColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (tenant_id, message_id) DO NOTHING`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		record.ID, record.TenantID, record.MessageID, record.Sender, record.Recipient, record.Subject,
+		record.Status, record.Attachments, record.Drafts, record.Reason, record.ReceivedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record inbound email: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDuplicateInboundEmail
+	}
+
+	return nil
+}
+
+// UpdateEmail updates the outcome of a received email
+func (r *PostgresBillCaptureRepository) UpdateEmail(ctx context.Context, record *domain.InboundEmailRecord) error {
+	_, err := db.MainPool.Exec(ctx,
+		`UPDATE inbound_emails SET status = $1, drafts = $2, reason = $3 WHERE tenant_id = $4 AND id = $5`,
+		record.Status, record.Drafts, record.Reason, record.TenantID, record.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update inbound email: %w", err)
+	}
+
+	return nil
+}
+
+// ListEmails retrieves received emails, newest first
+func (r *PostgresBillCaptureRepository) ListEmails(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.InboundEmailRecord, error) {
+	query := `
+		SELECT ` + inboundEmailColumns + `
+		FROM inbound_emails
+		WHERE tenant_id = $1
+		ORDER BY received_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inbound emails: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*domain.InboundEmailRecord{}
+	for rows.Next() {
+		var record domain.InboundEmailRecord
+		err := rows.Scan(
+			&record.ID, &record.TenantID, &record.MessageID, &record.Sender, &record.Recipient, &record.Subject,
+			&record.Status, &record.Attachments, &record.Drafts, &record.Reason, &record.ReceivedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan inbound email: %w", err)
+		}
+		records = append(records, &record)
+	}
+
+	return records, rows.Err()
+}
+
+// CreateDraft creates a draft purchase bill
+func (r *PostgresBillCaptureRepository) CreateDraft(ctx context.Context, draft *domain.PurchaseBillDraft) error {
+	query := `INSERT INTO purchase_bill_drafts (` + billDraftColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		draft.ID, draft.TenantID, draft.InboundEmailID, draft.SupplierID, draft.SenderEmail, draft.Subject,
+		draft.FileName, draft.AttachmentID,
+		draft.BillNumber, draft.BillDate, draft.SupplierPAN, draft.SubTotal, draft.VATAmount, draft.TotalAmount,
+		draft.DueDate, draft.DueSchedule,
+		draft.Status, draft.ExtractionStatus, draft.ExtractionConfidence, draft.ExtractionError,
+		draft.ReviewedBy, draft.ReviewedAt, draft.RejectReason, draft.CreatedAt, draft.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create bill draft: %w", err)
+	}
+
+	return nil
+}
+
+// GetDraft retrieves a draft purchase bill
+func (r *PostgresBillCaptureRepository) GetDraft(ctx context.Context, tenantID, id uuid.UUID) (*domain.PurchaseBillDraft, error) {
+	query := `SELECT ` + billDraftColumns + ` FROM purchase_bill_drafts WHERE tenant_id = $1 AND id = $2`
+
+	draft, err := scanBillDraft(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBillDraftNotFound
+		}
+		return nil, fmt.Errorf("failed to get bill draft: %w", err)
+	}
+
+	return draft, nil
+}
+
+// UpdateDraft saves a reviewer's edits to a pending draft
+func (r *PostgresBillCaptureRepository) UpdateDraft(ctx context.Context, draft *domain.PurchaseBillDraft) error {
+	query := `
+		UPDATE purchase_bill_drafts
+		SET supplier_id = $1, attachment_id = $2, bill_number = $3, bill_date = $4, supplier_pan = $5,
+		    sub_total = $6, vat_amount = $7, total_amount = $8, due_date = $9, updated_at = $10
+		WHERE tenant_id = $11 AND id = $12 AND status = 'pending_review'
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		draft.SupplierID, draft.AttachmentID, draft.BillNumber, draft.BillDate, draft.SupplierPAN,
+		draft.SubTotal, draft.VATAmount, draft.TotalAmount, draft.DueDate, draft.UpdatedAt,
+		draft.TenantID, draft.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update bill draft: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBillDraftState
+	}
+
+	return nil
+}
+
+// SaveReview saves the confirmation, rejection or approval hold of a draft still in status from
+func (r *PostgresBillCaptureRepository) SaveReview(ctx context.Context, draft *domain.PurchaseBillDraft, from domain.BillDraftStatus) error {
+	query := `
+		UPDATE purchase_bill_drafts
+		SET status = $1, reviewed_by = $2, reviewed_at = $3, reject_reason = $4, due_date = $5, due_schedule = $6,
+		    updated_at = $7
+		WHERE tenant_id = $8 AND id = $9 AND status = $10
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		draft.Status, draft.ReviewedBy, draft.ReviewedAt, draft.RejectReason, draft.DueDate, draft.DueSchedule,
+		draft.UpdatedAt, draft.TenantID, draft.ID, from,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to review bill draft: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBillDraftState
+	}
+
+	return nil
+}
+
+// ListDrafts retrieves drafts, newest first, optionally by status
+func (r *PostgresBillCaptureRepository) ListDrafts(ctx context.Context, tenantID uuid.UUID, status *domain.BillDraftStatus, limit, offset int) ([]*domain.PurchaseBillDraft, error) {
+	query := `
+		SELECT ` + billDraftColumns + `
+		FROM purchase_bill_drafts
+		WHERE tenant_id = $1 AND ($2::text IS NULL OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bill drafts: %w", err)
+	}
+	defer rows.Close()
+
+	return collectBillDrafts(rows)
+}
+
+// SupplierBills retrieves a supplier's confirmed bills by bill date
+func (r *PostgresBillCaptureRepository) SupplierBills(ctx context.Context, tenantID, supplierID uuid.UUID, limit int) ([]*domain.PurchaseBillDraft, error) {
+	query := `
+		SELECT ` + billDraftColumns + `
+		FROM purchase_bill_drafts
+		WHERE tenant_id = $1 AND supplier_id = $2 AND status = 'confirmed'
+		ORDER BY bill_date, created_at
+		LIMIT $3
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, supplierID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query supplier bills: %w", err)
+	}
+	defer rows.Close()
+
+	return collectBillDrafts(rows)
+}
+
+// DraftExists reports whether a draft belongs to the tenant
+func (r *PostgresBillCaptureRepository) DraftExists(ctx context.Context, tenantID, id uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM purchase_bill_drafts WHERE id = $1 AND tenant_id = $2)`
+	if err := db.MainPool.QueryRow(ctx, query, id, tenantID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check bill draft: %w", err)
+	}
+	return exists, nil
+}
+
+// PurchaseRegister lists confirmed bills dated in the range with their supplier
+func (r *PostgresBillCaptureRepository) PurchaseRegister(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.PurchaseRegisterLine, error) {
+	query := `
+		SELECT d.id, d.bill_date, COALESCE(d.bill_number, ''),
+		       COALESCE(s.name, d.sender_email),
+		       COALESCE(d.supplier_pan, s.custom_attributes->>'pan_number', ''),
+		       COALESCE(d.sub_total, 0), COALESCE(d.vat_amount, 0), COALESCE(d.total_amount, 0)
+		FROM purchase_bill_drafts d
+		LEFT JOIN suppliers s ON s.id = d.supplier_id AND s.tenant_id = d.tenant_id
+		WHERE d.tenant_id = $1 AND d.status = 'confirmed'
+		  AND d.bill_date >= $2 AND d.bill_date <= $3
+		ORDER BY d.bill_date, d.bill_number
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query purchase register: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []*domain.PurchaseRegisterLine{}
+	for rows.Next() {
+		var line domain.PurchaseRegisterLine
+		if err := rows.Scan(&line.DraftID, &line.BillDate, &line.BillNumber, &line.SupplierName,
+			&line.SupplierPAN, &line.Taxable, &line.VATAmount, &line.TotalAmount); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase register line: %w", err)
+		}
+		lines = append(lines, &line)
+	}
+	return lines, rows.Err()
+}
+
+// ConfirmedTotalsBySupplier sums confirmed bills per supplier; bills without a
+// sub-total count at their total
+func (r *PostgresBillCaptureRepository) ConfirmedTotalsBySupplier(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (map[uuid.UUID]float64, error) {
+	query := `
+		SELECT supplier_id, SUM(COALESCE(sub_total, total_amount, 0))
+		FROM purchase_bill_drafts
+		WHERE tenant_id = $1 AND status = 'confirmed' AND supplier_id IS NOT NULL
+		  AND bill_date >= $2 AND bill_date <= $3
+		GROUP BY supplier_id
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum purchase bills: %w", err)
+	}
+	defer rows.Close()
+
+	totals := map[uuid.UUID]float64{}
+	for rows.Next() {
+		var supplierID uuid.UUID
+		var total float64
+		if err := rows.Scan(&supplierID, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase bill total: %w", err)
+		}
+		totals[supplierID] = total
+	}
+	return totals, rows.Err()
+}
+
+// PendingExtractions retrieves drafts waiting for extraction
+func (r *PostgresBillCaptureRepository) PendingExtractions(ctx context.Context, limit int) ([]*domain.PurchaseBillDraft, error) {
+	query := `
+		SELECT ` + billDraftColumns + `
+		FROM purchase_bill_drafts
+		WHERE extraction_status = 'pending'
+		ORDER BY created_at
+		LIMIT $1
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending extractions: %w", err)
+	}
+	defer rows.Close()
+
+	return collectBillDrafts(rows)
+}
+
+// SaveExtraction stores what was read from a draft's file
+func (r *PostgresBillCaptureRepository) SaveExtraction(ctx context.Context, draft *domain.PurchaseBillDraft) error {
+	query := `
+		UPDATE purchase_bill_drafts
+		SET supplier_id = $1, bill_number = $2, bill_date = $3, supplier_pan = $4, sub_total = $5, vat_amount = $6,
+		    total_amount = $7, extraction_status = $8, extraction_confidence = $9, extraction_error = $10, updated_at = $11
+		WHERE tenant_id = $12 AND id = $13
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		draft.SupplierID, draft.BillNumber, draft.BillDate, draft.SupplierPAN, draft.SubTotal, draft.VATAmount,
+		draft.TotalAmount, draft.ExtractionStatus, draft.ExtractionConfidence, draft.ExtractionError, draft.UpdatedAt,
+		draft.TenantID, draft.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save bill extraction: %w", err)
+	}
+
+	return nil
+}
+
+// MatchSupplier finds a supplier by email address, then by company mail domain
+func (r *PostgresBillCaptureRepository) MatchSupplier(ctx context.Context, tenantID uuid.UUID, email string) (*uuid.UUID, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, nil
+	}
+
+	ids, err := r.matchSuppliers(ctx,
+		`SELECT id FROM suppliers WHERE tenant_id = $1 AND LOWER(email) = $2 LIMIT 2`, tenantID, email)
+	if err != nil || len(ids) > 0 {
+		return singleID(ids), err // Two suppliers sharing an address is ambiguous
+	}
+
+	_, mailDomain, _ := strings.Cut(email, "@")
+	if mailDomain == "" || webmailDomains[mailDomain] {
+		return nil, nil
+	}
+	ids, err = r.matchSuppliers(ctx,
+		`SELECT id FROM suppliers WHERE tenant_id = $1 AND LOWER(email) LIKE '%@' || $2 LIMIT 2`, tenantID, mailDomain)
+	if err != nil {
+		return nil, err
+	}
+	return singleID(ids), nil
+}
+
+// MatchSupplierByPAN finds the supplier registered with a PAN
+func (r *PostgresBillCaptureRepository) MatchSupplierByPAN(ctx context.Context, tenantID uuid.UUID, pan string) (*uuid.UUID, error) {
+	ids, err := r.matchSuppliers(ctx,
+		`SELECT id FROM suppliers WHERE tenant_id = $1 AND custom_attributes->>'pan_number' = $2 LIMIT 2`,
+		tenantID, strings.TrimSpace(pan))
+	if err != nil {
+		return nil, err
+	}
+	return singleID(ids), nil
+}
+
+func (r *PostgresBillCaptureRepository) matchSuppliers(ctx context.Context, query string, args ...any) ([]uuid.UUID, error) {
+	rows, err := db.MainPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match supplier: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan supplier: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func singleID(ids []uuid.UUID) *uuid.UUID {
+	if len(ids) != 1 {
+		return nil
+	}
+	return &ids[0]
+}
+
+func collectBillDrafts(rows pgx.Rows) ([]*domain.PurchaseBillDraft, error) {
+	drafts := []*domain.PurchaseBillDraft{}
+	for rows.Next() {
+		draft, err := scanBillDraft(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bill draft: %w", err)
+		}
+		drafts = append(drafts, draft)
+	}
+
+	return drafts, rows.Err()
+}
+
+func scanBillInbox(row pgx.Row) (*domain.BillInbox, error) {
+	var inbox domain.BillInbox
+	if err := row.Scan(&inbox.TenantID, &inbox.Token, &inbox.IsActive, &inbox.CreatedAt, &inbox.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &inbox, nil
+}
+
+func scanBillDraft(row pgx.Row) (*domain.PurchaseBillDraft, error) {
+	var draft domain.PurchaseBillDraft
+	err := row.Scan(
+		&draft.ID, &draft.TenantID, &draft.InboundEmailID, &draft.SupplierID, &draft.SenderEmail, &draft.Subject,
+		&draft.FileName, &draft.AttachmentID,
+		&draft.BillNumber, &draft.BillDate, &draft.SupplierPAN, &draft.SubTotal, &draft.VATAmount, &draft.TotalAmount,
+		&draft.DueDate, &draft.DueSchedule,
+		&draft.Status, &draft.ExtractionStatus, &draft.ExtractionConfidence, &draft.ExtractionError,
+		&draft.ReviewedBy, &draft.ReviewedAt, &draft.RejectReason, &draft.CreatedAt, &draft.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
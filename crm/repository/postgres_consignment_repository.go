@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresConsignmentRepository implements ConsignmentRepository using PostgreSQL
+type PostgresConsignmentRepository struct{}
+
+// NewPostgresConsignmentRepository creates a new PostgreSQL consignment repository
+func NewPostgresConsignmentRepository() *PostgresConsignmentRepository {
+	return &PostgresConsignmentRepository{}
+}
+
+// CreateReceipt creates a consignment receipt and its lines
+func (r *PostgresConsignmentRepository) CreateReceipt(ctx context.Context, receipt *domain.ConsignmentReceipt) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var next int64
+		err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) + 1 FROM consignment_receipts WHERE tenant_id = $1`, receipt.TenantID,
+		).Scan(&next)
+		if err != nil {
+			return fmt.Errorf("failed to number consignment receipt: %w", err)
+		}
+		receipt.ReceiptNumber = fmt.Sprintf("CSR-%05d", next)
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO consignment_receipts (
+				id, tenant_id, supplier_id, receipt_number, received_at, reference, note, created_by, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`,
+			receipt.ID, receipt.TenantID, receipt.SupplierID, receipt.ReceiptNumber, receipt.ReceivedAt,
+			receipt.Reference, receipt.Note, receipt.CreatedBy, receipt.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create consignment receipt: %w", err)
+		}
+
+		for _, line := range receipt.Lines {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO consignment_lines (
+					id, tenant_id, receipt_id, supplier_id, product_id, quantity, unit_cost,
+					sold_qty, returned_qty, received_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			`,
+				line.ID, receipt.TenantID, receipt.ID, receipt.SupplierID, line.ProductID, line.Quantity, line.UnitCost,
+				line.SoldQty, line.ReturnedQty, receipt.ReceivedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create consignment line: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetReceipt retrieves a consignment receipt with its lines
+func (r *PostgresConsignmentRepository) GetReceipt(ctx context.Context, tenantID, id uuid.UUID) (*domain.ConsignmentReceipt, error) {
+	query := `
+		SELECT id, tenant_id, supplier_id, receipt_number, received_at, reference, note, created_by, created_at
+		FROM consignment_receipts
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	var receipt domain.ConsignmentReceipt
+	err := db.MainPool.QueryRow(ctx, query, tenantID, id).Scan(
+		&receipt.ID, &receipt.TenantID, &receipt.SupplierID, &receipt.ReceiptNumber, &receipt.ReceivedAt,
+		&receipt.Reference, &receipt.Note, &receipt.CreatedBy, &receipt.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrConsignmentReceiptNotFound
+		}
+		return nil, fmt.Errorf("failed to get consignment receipt: %w", err)
+	}
+
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT id, receipt_id, supplier_id, product_id, quantity, unit_cost, sold_qty, returned_qty
+		FROM consignment_lines
+		WHERE receipt_id = $1
+		ORDER BY product_id
+	`, receipt.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consignment lines: %w", err)
+	}
+	defer rows.Close()
+
+	receipt.Lines = []domain.ConsignmentLine{}
+	for rows.Next() {
+		line, err := scanConsignmentLine(rows)
+		if err != nil {
+			return nil, err
+		}
+		receipt.Lines = append(receipt.Lines, *line)
+	}
+
+	return &receipt, rows.Err()
+}
+
+// ListReceipts retrieves consignment receipts (without lines), newest first
+func (r *PostgresConsignmentRepository) ListReceipts(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, limit, offset int) ([]*domain.ConsignmentReceipt, error) {
+	query := `
+		SELECT id, tenant_id, supplier_id, receipt_number, received_at, reference, note, created_by, created_at
+		FROM consignment_receipts
+		WHERE tenant_id = $1 AND ($2::uuid IS NULL OR supplier_id = $2)
+		ORDER BY received_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, supplierID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consignment receipts: %w", err)
+	}
+	defer rows.Close()
+
+	receipts := []*domain.ConsignmentReceipt{}
+	for rows.Next() {
+		var receipt domain.ConsignmentReceipt
+		if err := rows.Scan(
+			&receipt.ID, &receipt.TenantID, &receipt.SupplierID, &receipt.ReceiptNumber, &receipt.ReceivedAt,
+			&receipt.Reference, &receipt.Note, &receipt.CreatedBy, &receipt.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan consignment receipt: %w", err)
+		}
+		receipts = append(receipts, &receipt)
+	}
+
+	return receipts, rows.Err()
+}
+
+// Settle consumes sold quantities from open consignment lines and writes supplier bills
+func (r *PostgresConsignmentRepository) Settle(ctx context.Context, tenantID uuid.UUID, items []domain.ConsignmentSaleItem, saleType *string, saleID *uuid.UUID) ([]*domain.ConsignmentBill, error) {
+	var bills []*domain.ConsignmentBill
+
+	err := db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		bySupplier := map[uuid.UUID][]domain.ConsignmentBillLine{}
+		suppliers := []uuid.UUID{}
+
+		for _, item := range items {
+			rows, err := tx.Query(ctx, `
+				SELECT id, receipt_id, supplier_id, product_id, quantity, unit_cost, sold_qty, returned_qty
+				FROM consignment_lines
+				WHERE tenant_id = $1 AND product_id = $2 AND sold_qty + returned_qty < quantity
+				ORDER BY received_at, id
+				FOR UPDATE
+			`, tenantID, item.ProductID)
+			if err != nil {
+				return fmt.Errorf("failed to lock consignment stock: %w", err)
+			}
+
+			lines := []*domain.ConsignmentLine{}
+			for rows.Next() {
+				line, err := scanConsignmentLine(rows)
+				if err != nil {
+					rows.Close()
+					return err
+				}
+				lines = append(lines, line)
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("row iteration error: %w", err)
+			}
+
+			supplierOf := map[uuid.UUID]uuid.UUID{}
+			for _, line := range lines {
+				supplierOf[line.ID] = line.SupplierID
+			}
+
+			for _, billLine := range domain.Consume(lines, item.Quantity) {
+				_, err := tx.Exec(ctx,
+					`UPDATE consignment_lines SET sold_qty = sold_qty + $1 WHERE id = $2`,
+					billLine.Quantity, billLine.LineID,
+				)
+				if err != nil {
+					return fmt.Errorf("failed to settle consignment line: %w", err)
+				}
+
+				supplierID := supplierOf[billLine.LineID]
+				if _, ok := bySupplier[supplierID]; !ok {
+					suppliers = append(suppliers, supplierID)
+				}
+				bySupplier[supplierID] = append(bySupplier[supplierID], billLine)
+			}
+		}
+
+		for _, supplierID := range suppliers {
+			bill := domain.NewConsignmentBill(tenantID, supplierID, saleType, saleID, bySupplier[supplierID])
+			if err := r.insertBill(ctx, tx, bill); err != nil {
+				return err
+			}
+			bills = append(bills, bill)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bills, nil
+}
+
+// ReturnToSupplier records unsold stock sent back on a consignment line
+func (r *PostgresConsignmentRepository) ReturnToSupplier(ctx context.Context, tenantID, lineID uuid.UUID, quantity float64) error {
+	query := `
+		UPDATE consignment_lines
+		SET returned_qty = returned_qty + $1
+		WHERE tenant_id = $2 AND id = $3 AND quantity - sold_qty - returned_qty >= $1
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query, quantity, tenantID, lineID)
+	if err != nil {
+		return fmt.Errorf("failed to return consignment stock: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInsufficientConsignmentStock
+	}
+
+	return nil
+}
+
+// GetBill retrieves a consignment bill with its lines
+func (r *PostgresConsignmentRepository) GetBill(ctx context.Context, tenantID, id uuid.UUID) (*domain.ConsignmentBill, error) {
+	query := `
+		SELECT id, tenant_id, supplier_id, bill_number, sale_type, sale_id, total_amount, journal_entry_id, created_at
+		FROM consignment_bills
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	var bill domain.ConsignmentBill
+	err := db.MainPool.QueryRow(ctx, query, tenantID, id).Scan(
+		&bill.ID, &bill.TenantID, &bill.SupplierID, &bill.BillNumber, &bill.SaleType, &bill.SaleID,
+		&bill.TotalAmount, &bill.JournalEntryID, &bill.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrConsignmentBillNotFound
+		}
+		return nil, fmt.Errorf("failed to get consignment bill: %w", err)
+	}
+
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT id, bill_id, line_id, product_id, quantity, unit_cost, amount
+		FROM consignment_bill_lines
+		WHERE bill_id = $1
+	`, bill.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consignment bill lines: %w", err)
+	}
+	defer rows.Close()
+
+	bill.Lines = []domain.ConsignmentBillLine{}
+	for rows.Next() {
+		var line domain.ConsignmentBillLine
+		if err := rows.Scan(
+			&line.ID, &line.BillID, &line.LineID, &line.ProductID, &line.Quantity, &line.UnitCost, &line.Amount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan consignment bill line: %w", err)
+		}
+		bill.Lines = append(bill.Lines, line)
+	}
+
+	return &bill, rows.Err()
+}
+
+// ListBills retrieves consignment bills (without lines), newest first
+func (r *PostgresConsignmentRepository) ListBills(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, limit, offset int) ([]*domain.ConsignmentBill, error) {
+	query := `
+		SELECT id, tenant_id, supplier_id, bill_number, sale_type, sale_id, total_amount, journal_entry_id, created_at
+		FROM consignment_bills
+		WHERE tenant_id = $1 AND ($2::uuid IS NULL OR supplier_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, supplierID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consignment bills: %w", err)
+	}
+	defer rows.Close()
+
+	bills := []*domain.ConsignmentBill{}
+	for rows.Next() {
+		var bill domain.ConsignmentBill
+		if err := rows.Scan(
+			&bill.ID, &bill.TenantID, &bill.SupplierID, &bill.BillNumber, &bill.SaleType, &bill.SaleID,
+			&bill.TotalAmount, &bill.JournalEntryID, &bill.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan consignment bill: %w", err)
+		}
+		bills = append(bills, &bill)
+	}
+
+	return bills, rows.Err()
+}
+
+// SetBillJournalEntry links a bill to the journal entry that booked its payable
+func (r *PostgresConsignmentRepository) SetBillJournalEntry(ctx context.Context, billID, journalEntryID uuid.UUID) error {
+	query := `UPDATE consignment_bills SET journal_entry_id = $1 WHERE id = $2`
+
+	if _, err := db.MainPool.Exec(ctx, query, journalEntryID, billID); err != nil {
+		return fmt.Errorf("failed to link consignment bill journal: %w", err)
+	}
+
+	return nil
+}
+
+// StockReport aggregates received, sold, returned and on-hand consignment stock per supplier and product
+func (r *PostgresConsignmentRepository) StockReport(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID) ([]*domain.ConsignmentStock, error) {
+	query := `
+		SELECT l.supplier_id, s.supplier_code, s.name, l.product_id,
+		       SUM(l.quantity), SUM(l.sold_qty), SUM(l.returned_qty),
+		       SUM(l.quantity - l.sold_qty - l.returned_qty),
+		       SUM((l.quantity - l.sold_qty - l.returned_qty) * l.unit_cost),
+		       SUM(l.sold_qty * l.unit_cost)
+		FROM consignment_lines l
+		JOIN suppliers s ON s.id = l.supplier_id
+		WHERE l.tenant_id = $1 AND ($2::uuid IS NULL OR l.supplier_id = $2)
+		GROUP BY l.supplier_id, s.supplier_code, s.name, l.product_id
+		ORDER BY s.name, l.product_id
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build consignment stock report: %w", err)
+	}
+	defer rows.Close()
+
+	stock := []*domain.ConsignmentStock{}
+	for rows.Next() {
+		var s domain.ConsignmentStock
+		if err := rows.Scan(
+			&s.SupplierID, &s.SupplierCode, &s.SupplierName, &s.ProductID,
+			&s.Received, &s.Sold, &s.Returned, &s.OnHand, &s.OnHandValue, &s.SoldValue,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan consignment stock: %w", err)
+		}
+		stock = append(stock, &s)
+	}
+
+	return stock, rows.Err()
+}
+
+func (r *PostgresConsignmentRepository) insertBill(ctx context.Context, tx pgx.Tx, bill *domain.ConsignmentBill) error {
+	var next int64
+	err := tx.QueryRow(ctx,
+		`SELECT COUNT(*) + 1 FROM consignment_bills WHERE tenant_id = $1`, bill.TenantID,
+	).Scan(&next)
+	if err != nil {
+		return fmt.Errorf("failed to number consignment bill: %w", err)
+	}
+	bill.BillNumber = fmt.Sprintf("CSB-%05d", next)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO consignment_bills (
+			id, tenant_id, supplier_id, bill_number, sale_type, sale_id, total_amount, journal_entry_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		bill.ID, bill.TenantID, bill.SupplierID, bill.BillNumber, bill.SaleType, bill.SaleID,
+		bill.TotalAmount, bill.JournalEntryID, bill.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create consignment bill: %w", err)
+	}
+
+	for _, line := range bill.Lines {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO consignment_bill_lines (id, bill_id, line_id, product_id, quantity, unit_cost, amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, line.ID, bill.ID, line.LineID, line.ProductID, line.Quantity, line.UnitCost, line.Amount)
+		if err != nil {
+			return fmt.Errorf("failed to create consignment bill line: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// scanConsignmentLine scans a single consignment line row
+func scanConsignmentLine(row pgx.Row) (*domain.ConsignmentLine, error) {
+	var line domain.ConsignmentLine
+	err := row.Scan(
+		&line.ID, &line.ReceiptID, &line.SupplierID, &line.ProductID,
+		&line.Quantity, &line.UnitCost, &line.SoldQty, &line.ReturnedQty,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan consignment line: %w", err)
+	}
+	return &line, nil
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresContainerRepository implements ContainerRepository using PostgreSQL
+type PostgresContainerRepository struct{}
+
+// NewPostgresContainerRepository creates a new PostgreSQL container repository
+func NewPostgresContainerRepository() *PostgresContainerRepository {
+	return &PostgresContainerRepository{}
+}
+
+// CreateType creates a new container type
+func (r *PostgresContainerRepository) CreateType(ctx context.Context, containerType *domain.ContainerType) error {
+	query := `
+		INSERT INTO container_types (
+			id, tenant_id, code, name, deposit_amount, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		containerType.ID, containerType.TenantID, containerType.Code, containerType.Name,
+		containerType.DepositAmount, containerType.IsActive, containerType.CreatedAt, containerType.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create container type: %w", err)
+	}
+
+	return nil
+}
+
+// GetTypeByID retrieves a tenant's container type
+func (r *PostgresContainerRepository) GetTypeByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.ContainerType, error) {
+	query := `
+		SELECT id, tenant_id, code, name, deposit_amount, is_active, created_at, updated_at
+		FROM container_types
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	var t domain.ContainerType
+	err := db.MainPool.QueryRow(ctx, query, tenantID, id).Scan(
+		&t.ID, &t.TenantID, &t.Code, &t.Name, &t.DepositAmount, &t.IsActive, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrContainerTypeNotFound
+		}
+		return nil, fmt.Errorf("failed to get container type: %w", err)
+	}
+
+	return &t, nil
+}
+
+// ListTypes retrieves a tenant's container types
+func (r *PostgresContainerRepository) ListTypes(ctx context.Context, tenantID uuid.UUID) ([]*domain.ContainerType, error) {
+	query := `
+		SELECT id, tenant_id, code, name, deposit_amount, is_active, created_at, updated_at
+		FROM container_types
+		WHERE tenant_id = $1
+		ORDER BY code
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list container types: %w", err)
+	}
+	defer rows.Close()
+
+	types := []*domain.ContainerType{}
+	for rows.Next() {
+		var t domain.ContainerType
+		if err := rows.Scan(
+			&t.ID, &t.TenantID, &t.Code, &t.Name, &t.DepositAmount, &t.IsActive, &t.CreatedAt, &t.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan container type: %w", err)
+		}
+		types = append(types, &t)
+	}
+
+	return types, rows.Err()
+}
+
+// UpdateType updates a container type
+func (r *PostgresContainerRepository) UpdateType(ctx context.Context, containerType *domain.ContainerType) error {
+	query := `
+		UPDATE container_types
+		SET name = $1, deposit_amount = $2, is_active = $3, updated_at = $4
+		WHERE tenant_id = $5 AND id = $6
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		containerType.Name, containerType.DepositAmount, containerType.IsActive, containerType.UpdatedAt,
+		containerType.TenantID, containerType.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update container type: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrContainerTypeNotFound
+	}
+
+	return nil
+}
+
+// Issue records an issue movement and adds it to the customer's balance
+func (r *PostgresContainerRepository) Issue(ctx context.Context, movement *domain.ContainerMovement) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if err := r.insertMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO customer_container_balances (
+				tenant_id, customer_id, container_type_id, quantity, deposit_held, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (customer_id, container_type_id) DO UPDATE
+			SET quantity = customer_container_balances.quantity + EXCLUDED.quantity,
+			    deposit_held = customer_container_balances.deposit_held + EXCLUDED.deposit_held,
+			    updated_at = EXCLUDED.updated_at
+		`
+
+		_, err := tx.Exec(ctx, query,
+			movement.TenantID, movement.CustomerID, movement.ContainerTypeID,
+			movement.Quantity, movement.DepositAmount, movement.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update container balance: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Return records a return movement, refunding from and reducing the customer's balance
+func (r *PostgresContainerRepository) Return(ctx context.Context, movement *domain.ContainerMovement) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var balance domain.ContainerBalance
+		err := tx.QueryRow(ctx, `
+			SELECT quantity, deposit_held
+			FROM customer_container_balances
+			WHERE customer_id = $1 AND container_type_id = $2
+			FOR UPDATE
+		`, movement.CustomerID, movement.ContainerTypeID).Scan(&balance.Quantity, &balance.DepositHeld)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to lock container balance: %w", err)
+		}
+		if balance.Quantity < movement.Quantity {
+			return domain.ErrInsufficientContainers
+		}
+
+		movement.DepositAmount = balance.RefundFor(movement.Quantity)
+		if err := r.insertMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE customer_container_balances
+			SET quantity = quantity - $1, deposit_held = deposit_held - $2, updated_at = $3
+			WHERE customer_id = $4 AND container_type_id = $5
+		`, movement.Quantity, movement.DepositAmount, movement.CreatedAt, movement.CustomerID, movement.ContainerTypeID)
+		if err != nil {
+			return fmt.Errorf("failed to update container balance: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// SetJournalEntry links a movement to the journal entry that booked its deposit
+func (r *PostgresContainerRepository) SetJournalEntry(ctx context.Context, movementID, journalEntryID uuid.UUID) error {
+	query := `UPDATE container_movements SET journal_entry_id = $1 WHERE id = $2`
+
+	if _, err := db.MainPool.Exec(ctx, query, journalEntryID, movementID); err != nil {
+		return fmt.Errorf("failed to link container movement journal: %w", err)
+	}
+
+	return nil
+}
+
+// ListMovements retrieves a customer's container movements, newest first
+func (r *PostgresContainerRepository) ListMovements(ctx context.Context, tenantID, customerID uuid.UUID, limit, offset int) ([]*domain.ContainerMovement, error) {
+	query := `
+		SELECT id, tenant_id, customer_id, container_type_id, kind, quantity, deposit_amount,
+		       reference_type, reference_id, note, journal_entry_id, created_by, created_at
+		FROM container_movements
+		WHERE tenant_id = $1 AND customer_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, customerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list container movements: %w", err)
+	}
+	defer rows.Close()
+
+	movements := []*domain.ContainerMovement{}
+	for rows.Next() {
+		var m domain.ContainerMovement
+		if err := rows.Scan(
+			&m.ID, &m.TenantID, &m.CustomerID, &m.ContainerTypeID, &m.Kind, &m.Quantity, &m.DepositAmount,
+			&m.ReferenceType, &m.ReferenceID, &m.Note, &m.JournalEntryID, &m.CreatedBy, &m.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan container movement: %w", err)
+		}
+		movements = append(movements, &m)
+	}
+
+	return movements, rows.Err()
+}
+
+// ListBalances retrieves outstanding container balances with customer and container names
+func (r *PostgresContainerRepository) ListBalances(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID) ([]*domain.ContainerBalance, error) {
+	query := `
+		SELECT b.tenant_id, b.customer_id, c.customer_code, c.name,
+		       b.container_type_id, t.code, t.name, b.quantity, b.deposit_held, b.updated_at
+		FROM customer_container_balances b
+		JOIN customers c ON c.id = b.customer_id
+		JOIN container_types t ON t.id = b.container_type_id
+		WHERE b.tenant_id = $1 AND b.quantity > 0
+		  AND ($2::uuid IS NULL OR b.customer_id = $2)
+		ORDER BY c.name, t.code
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list container balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := []*domain.ContainerBalance{}
+	for rows.Next() {
+		var b domain.ContainerBalance
+		if err := rows.Scan(
+			&b.TenantID, &b.CustomerID, &b.CustomerCode, &b.CustomerName,
+			&b.ContainerTypeID, &b.ContainerCode, &b.ContainerName, &b.Quantity, &b.DepositHeld, &b.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan container balance: %w", err)
+		}
+		balances = append(balances, &b)
+	}
+
+	return balances, rows.Err()
+}
+
+func (r *PostgresContainerRepository) insertMovement(ctx context.Context, tx pgx.Tx, movement *domain.ContainerMovement) error {
+	query := `
+		INSERT INTO container_movements (
+			id, tenant_id, customer_id, container_type_id, kind, quantity, deposit_amount,
+			reference_type, reference_id, note, journal_entry_id, created_by, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := tx.Exec(ctx, query,
+		movement.ID, movement.TenantID, movement.CustomerID, movement.ContainerTypeID,
+		movement.Kind, movement.Quantity, movement.DepositAmount,
+		movement.ReferenceType, movement.ReferenceID, movement.Note,
+		movement.JournalEntryID, movement.CreatedBy, movement.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record container movement: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresCustomerOTPRepository implements CustomerOTPRepository using PostgreSQL
+type PostgresCustomerOTPRepository struct{}
+
+// NewPostgresCustomerOTPRepository creates a new PostgreSQL customer OTP repository
+func NewPostgresCustomerOTPRepository() *PostgresCustomerOTPRepository {
+	return &PostgresCustomerOTPRepository{}
+}
+
+const customerOTPColumns = `id, tenant_id, customer_id, channel, recipient, code_hash, attempts, expires_at,
+	verified_at, verified_by, invoice_id, notification_id, requested_by, created_at`
+
+// Create saves a new challenge
+func (r *PostgresCustomerOTPRepository) Create(ctx context.Context, otp *domain.CustomerOTP) error {
+	query := `INSERT INTO customer_otps (` + customerOTPColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+	_, err := db.MainPool.Exec(ctx, query,
+		otp.ID, otp.TenantID, otp.CustomerID, otp.Channel, otp.Recipient, otp.CodeHash, otp.Attempts, otp.ExpiresAt,
+		otp.VerifiedAt, otp.VerifiedBy, otp.InvoiceID, otp.NotificationID, otp.RequestedBy, otp.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create customer OTP: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a challenge
+func (r *PostgresCustomerOTPRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.CustomerOTP, error) {
+	query := `SELECT ` + customerOTPColumns + ` FROM customer_otps WHERE tenant_id = $1 AND id = $2`
+
+	otp, err := scanCustomerOTP(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrOTPNotFound
+		}
+		return nil, fmt.Errorf("failed to get customer OTP: %w", err)
+	}
+	return otp, nil
+}
+
+// Latest retrieves the customer's newest challenge
+func (r *PostgresCustomerOTPRepository) Latest(ctx context.Context, tenantID, customerID uuid.UUID) (*domain.CustomerOTP, error) {
+	query := `SELECT ` + customerOTPColumns + ` FROM customer_otps
+		WHERE tenant_id = $1 AND customer_id = $2
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	otp, err := scanCustomerOTP(db.MainPool.QueryRow(ctx, query, tenantID, customerID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest customer OTP: %w", err)
+	}
+	return otp, nil
+}
+
+// CountAttempt increments the attempt count of an unverified challenge with attempts left
+func (r *PostgresCustomerOTPRepository) CountAttempt(ctx context.Context, tenantID, id uuid.UUID) (int, error) {
+	var attempts int
+	err := db.MainPool.QueryRow(ctx,
+		`UPDATE customer_otps SET attempts = attempts + 1
+		 WHERE tenant_id = $1 AND id = $2 AND verified_at IS NULL AND attempts < $3
+		 RETURNING attempts`,
+		tenantID, id, domain.OTPMaxAttempts,
+	).Scan(&attempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrOTPExpired
+		}
+		return 0, fmt.Errorf("failed to count customer OTP attempt: %w", err)
+	}
+	return attempts, nil
+}
+
+// SaveVerified marks a challenge verified unless it already is
+func (r *PostgresCustomerOTPRepository) SaveVerified(ctx context.Context, otp *domain.CustomerOTP) error {
+	_, err := db.MainPool.Exec(ctx,
+		`UPDATE customer_otps SET verified_at = $1, verified_by = $2
+		 WHERE tenant_id = $3 AND id = $4 AND verified_at IS NULL`,
+		otp.VerifiedAt, otp.VerifiedBy, otp.TenantID, otp.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save customer OTP verification: %w", err)
+	}
+	return nil
+}
+
+// SetNotification links the challenge to the message that carried the code
+func (r *PostgresCustomerOTPRepository) SetNotification(ctx context.Context, id, notificationID uuid.UUID) error {
+	_, err := db.MainPool.Exec(ctx, `UPDATE customer_otps SET notification_id = $1 WHERE id = $2`, notificationID, id)
+	if err != nil {
+		return fmt.Errorf("failed to link customer OTP notification: %w", err)
+	}
+	return nil
+}
+
+// AttachInvoice records the sale on a verification not used before
+func (r *PostgresCustomerOTPRepository) AttachInvoice(ctx context.Context, tenantID, id, invoiceID uuid.UUID) error {
+	tag, err := db.MainPool.Exec(ctx,
+		`UPDATE customer_otps SET invoice_id = $1
+		 WHERE tenant_id = $2 AND id = $3 AND verified_at IS NOT NULL AND invoice_id IS NULL`,
+		invoiceID, tenantID, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to attach customer OTP to invoice: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrOTPNotVerified
+	}
+	return nil
+}
+
+func scanCustomerOTP(row pgx.Row) (*domain.CustomerOTP, error) {
+	var otp domain.CustomerOTP
+	err := row.Scan(
+		&otp.ID, &otp.TenantID, &otp.CustomerID, &otp.Channel, &otp.Recipient, &otp.CodeHash, &otp.Attempts, &otp.ExpiresAt,
+		&otp.VerifiedAt, &otp.VerifiedBy, &otp.InvoiceID, &otp.NotificationID, &otp.RequestedBy, &otp.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &otp, nil
+}
@@ -3,12 +3,16 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aceextension/core/db"
 	"github.com/aceextension/crm/domain"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 // PostgresCustomerRepository implements CustomerRepository using PostgreSQL
@@ -41,6 +45,10 @@ func (r *PostgresCustomerRepository) Create(ctx context.Context, customer *domai
 		customer.CreatedAt, customer.UpdatedAt,
 	)
 
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "customers_pkey" {
+		return domain.ErrCustomerIDTaken
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create customer: %w", err)
 	}
@@ -48,16 +56,20 @@ func (r *PostgresCustomerRepository) Create(ctx context.Context, customer *domai
 	return nil
 }
 
-// GetByID retrieves a customer by ID
-func (r *PostgresCustomerRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
+// GetByID retrieves a tenant's customer by ID
+func (r *PostgresCustomerRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Customer, error) {
 	query := `
 		SELECT id, tenant_id, customer_code, name, email, phone,
 		       customer_type, status, custom_attributes, created_at, updated_at
 		FROM customers
-		WHERE id = $1
+		WHERE tenant_id = $1 AND id = $2
 	`
 
-	return r.scanCustomer(db.MainPool.QueryRow(ctx, query, id))
+	customer, err := r.scanCustomer(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrCustomerNotFound
+	}
+	return customer, err
 }
 
 // GetByCode retrieves a customer by customer code
@@ -72,24 +84,97 @@ func (r *PostgresCustomerRepository) GetByCode(ctx context.Context, tenantID uui
 	return r.scanCustomer(db.MainPool.QueryRow(ctx, query, tenantID, code))
 }
 
-// GetByTenantID retrieves all customers for a tenant
-func (r *PostgresCustomerRepository) GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Customer, error) {
+// customerListFilter selects the customers List pages through and counts: the tenant's
+// customers, only those carrying the tags in $3 when it is not null
+const customerListFilter = `
+	tenant_id = $1
+	AND ($3::text[] IS NULL OR id IN (
+		SELECT ta.entity_id
+		FROM tag_assignments ta
+		JOIN tags t ON t.id = ta.tag_id
+		WHERE ta.tenant_id = $1 AND ta.entity_type = $2 AND t.name = ANY($3)
+		GROUP BY ta.entity_id
+		HAVING COUNT(*) >= $4
+	))
+`
+
+// List retrieves a page of a tenant's customers, newest first, and how many there are in all.
+// Rows are read by keyset, so deep pages cost the same as the first.
+func (r *PostgresCustomerRepository) List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) ([]*domain.Customer, int64, error) {
+	var names []string
+	minMatches := 0
+	if tags != nil {
+		names, minMatches = tags.Names, tags.MinMatches()
+	}
+	var afterAt *time.Time
+	var afterID *uuid.UUID
+	if page.After != nil {
+		afterAt, afterID = new(time.Time), new(uuid.UUID)
+		if err := page.After.Scan(afterAt, afterID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM customers WHERE ` + customerListFilter
+	if err := db.MainPool.QueryRow(ctx, countQuery, tenantID, tagsDomain.EntityCustomer, names, minMatches).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count customers: %w", err)
+	}
+
+	listQuery := `
+		SELECT id, tenant_id, customer_code, name, email, phone,
+		       customer_type, status, custom_attributes, created_at, updated_at
+		FROM customers
+		WHERE ` + customerListFilter + `
+		AND ($5::timestamp IS NULL OR (created_at, id) < ($5, $6))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7
+	`
+
+	rows, err := db.MainPool.Query(ctx, listQuery, tenantID, tagsDomain.EntityCustomer, names, minMatches, afterAt, afterID, page.Fetch())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query customers: %w", err)
+	}
+	defer rows.Close()
+
+	customers, err := r.scanCustomers(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return customers, total, nil
+}
+
+// StreamByTenantID passes every customer for a tenant to fn as it is read, oldest first
+func (r *PostgresCustomerRepository) StreamByTenantID(ctx context.Context, tenantID uuid.UUID, fn func(*domain.Customer) error) error {
 	query := `
 		SELECT id, tenant_id, customer_code, name, email, phone,
 		       customer_type, status, custom_attributes, created_at, updated_at
 		FROM customers
 		WHERE tenant_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		ORDER BY created_at, id
 	`
 
-	rows, err := db.MainPool.Query(ctx, query, tenantID, limit, offset)
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query customers: %w", err)
+		return fmt.Errorf("failed to query customers: %w", err)
 	}
 	defer rows.Close()
 
-	return r.scanCustomers(rows)
+	for rows.Next() {
+		customer, err := r.scanCustomer(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(customer); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return nil
 }
 
 // Update updates a customer
@@ -104,29 +189,35 @@ func (r *PostgresCustomerRepository) Update(ctx context.Context, customer *domai
 		UPDATE customers
 		SET name = $1, email = $2, phone = $3, customer_type = $4,
 		    status = $5, custom_attributes = $6, updated_at = $7
-		WHERE id = $8
+		WHERE tenant_id = $8 AND id = $9
 	`
 
-	_, err = db.MainPool.Exec(ctx, query,
+	tag, err := db.MainPool.Exec(ctx, query,
 		customer.Name, customer.Email, customer.Phone, customer.CustomerType,
-		customer.Status, attrsJSON, customer.UpdatedAt, customer.ID,
+		customer.Status, attrsJSON, customer.UpdatedAt, customer.TenantID, customer.ID,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to update customer: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCustomerNotFound
+	}
 
 	return nil
 }
 
-// Delete deletes a customer
-func (r *PostgresCustomerRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM customers WHERE id = $1`
+// Delete deletes a tenant's customer
+func (r *PostgresCustomerRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	query := `DELETE FROM customers WHERE tenant_id = $1 AND id = $2`
 
-	_, err := db.MainPool.Exec(ctx, query, id)
+	tag, err := db.MainPool.Exec(ctx, query, tenantID, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete customer: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCustomerNotFound
+	}
 
 	return nil
 }
@@ -178,6 +269,101 @@ func (r *PostgresCustomerRepository) SearchByCustomAttribute(ctx context.Context
 	return r.scanCustomers(rows)
 }
 
+// customerAreaFilter selects the customers ListByArea pages through and counts
+const customerAreaFilter = `
+	tenant_id = $1
+	AND custom_attributes->'address_detail'->>'district' = $2
+	AND ($3 = '' OR lower(custom_attributes->'address_detail'->>'municipality') = lower($3))
+	AND ($4 = 0 OR (custom_attributes->'address_detail'->>'ward')::int = $4)
+`
+
+// customerAreaOrder is ListByArea's sort key; missing address parts sort as empty, like
+// their zero values in a cursor
+const customerAreaOrder = `
+	COALESCE(custom_attributes->'address_detail'->>'municipality', ''),
+	COALESCE((custom_attributes->'address_detail'->>'ward')::int, 0),
+	COALESCE(custom_attributes->'address_detail'->>'tole', ''),
+	name, id
+`
+
+// ListByArea retrieves a page of customers with a structured address in an area, grouped by
+// municipality, ward and tole, and how many there are in all
+func (r *PostgresCustomerRepository) ListByArea(ctx context.Context, tenantID uuid.UUID, filter domain.AreaFilter, page db.PageRequest) ([]*domain.Customer, int64, error) {
+	var afterMunicipality, afterTole, afterName *string
+	var afterWard *int
+	var afterID *uuid.UUID
+	if page.After != nil {
+		afterMunicipality, afterWard, afterTole, afterName, afterID = new(string), new(int), new(string), new(string), new(uuid.UUID)
+		if err := page.After.Scan(afterMunicipality, afterWard, afterTole, afterName, afterID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM customers WHERE ` + customerAreaFilter
+	if err := db.MainPool.QueryRow(ctx, countQuery, tenantID, filter.District, filter.Municipality, filter.Ward).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count customers by area: %w", err)
+	}
+
+	query := `
+		SELECT id, tenant_id, customer_code, name, email, phone,
+		       customer_type, status, custom_attributes, created_at, updated_at
+		FROM customers
+		WHERE ` + customerAreaFilter + `
+		AND ($5::text IS NULL OR (` + customerAreaOrder + `) > ($5, $6, $7, $8, $9))
+		ORDER BY ` + customerAreaOrder + `
+		LIMIT $10
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.District, filter.Municipality, filter.Ward,
+		afterMunicipality, afterWard, afterTole, afterName, afterID, page.Fetch())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list customers by area: %w", err)
+	}
+	defer rows.Close()
+
+	customers, err := r.scanCustomers(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return customers, total, nil
+}
+
+// ListByTags retrieves customers carrying all (or any) of the filter's tags
+func (r *PostgresCustomerRepository) ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*domain.Customer, error) {
+	listQuery := `
+		SELECT id, tenant_id, customer_code, name, email, phone,
+		       customer_type, status, custom_attributes, created_at, updated_at
+		FROM customers
+		WHERE tenant_id = $1
+		AND id IN (
+			SELECT ta.entity_id
+			FROM tag_assignments ta
+			JOIN tags t ON t.id = ta.tag_id
+			WHERE ta.tenant_id = $1 AND ta.entity_type = $2 AND t.name = ANY($3)
+			GROUP BY ta.entity_id
+			HAVING COUNT(*) >= $4
+		)
+		AND (
+			$5 = ''
+			OR name ILIKE '%' || $5 || '%'
+			OR email ILIKE '%' || $5 || '%'
+			OR phone ILIKE '%' || $5 || '%'
+			OR customer_code ILIKE '%' || $5 || '%'
+		)
+		ORDER BY created_at DESC
+		LIMIT $6 OFFSET $7
+	`
+
+	rows, err := db.MainPool.Query(ctx, listQuery, tenantID, tagsDomain.EntityCustomer, filter.Names, filter.MinMatches(), query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customers by tags: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanCustomers(rows)
+}
+
 // Count returns total number of customers for a tenant
 func (r *PostgresCustomerRepository) Count(ctx context.Context, tenantID uuid.UUID) (int64, error) {
 	var count int64
@@ -192,6 +378,18 @@ func (r *PostgresCustomerRepository) Count(ctx context.Context, tenantID uuid.UU
 	return count, nil
 }
 
+// CountByIDs counts the tenant's customers among ids
+func (r *PostgresCustomerRepository) CountByIDs(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (int, error) {
+	var count int
+
+	query := `SELECT COUNT(*) FROM customers WHERE tenant_id = $1 AND id = ANY($2)`
+	if err := db.MainPool.QueryRow(ctx, query, tenantID, ids).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count customers: %w", err)
+	}
+
+	return count, nil
+}
+
 // GetNextCustomerNumber gets the next customer number for code generation
 func (r *PostgresCustomerRepository) GetNextCustomerNumber(ctx context.Context, tenantID uuid.UUID) (int, error) {
 	var count int
@@ -206,6 +404,77 @@ func (r *PostgresCustomerRepository) GetNextCustomerNumber(ctx context.Context,
 	return count + 1, nil
 }
 
+// FindDuplicates retrieves customers sharing a normalized phone (digits, without a 977 prefix) or PAN
+func (r *PostgresCustomerRepository) FindDuplicates(ctx context.Context, tenantID uuid.UUID, phone, pan string, limit int) ([]*domain.Customer, error) {
+	query := `
+		SELECT id, tenant_id, customer_code, name, email, phone,
+		       customer_type, status, custom_attributes, created_at, updated_at
+		FROM customers
+		WHERE tenant_id = $1
+		AND (
+			($2 <> '' AND regexp_replace(regexp_replace(COALESCE(phone, ''), '[^0-9]', '', 'g'), '^977([0-9]{10,})$', '\1') = $2)
+			OR ($3 <> '' AND regexp_replace(COALESCE(custom_attributes->>'pan_number', ''), '[^0-9]', '', 'g') = $3)
+		)
+		ORDER BY created_at
+		LIMIT $4
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, phone, pan, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate customers: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanCustomers(rows)
+}
+
+// GetAlias returns the customer an offline ID was merged into, or nil
+func (r *PostgresCustomerRepository) GetAlias(ctx context.Context, tenantID, aliasID uuid.UUID) (*uuid.UUID, error) {
+	query := `SELECT customer_id FROM customer_aliases WHERE tenant_id = $1 AND alias_id = $2`
+
+	var customerID uuid.UUID
+	err := db.MainPool.QueryRow(ctx, query, tenantID, aliasID).Scan(&customerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer alias: %w", err)
+	}
+	return &customerID, nil
+}
+
+// MergeOffline updates the merged customer and records the alias; re-merging the same alias is a no-op
+func (r *PostgresCustomerRepository) MergeOffline(ctx context.Context, customer *domain.Customer, alias *domain.CustomerAlias) error {
+	attrsJSON, err := json.Marshal(customer.CustomAttributes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal custom attributes: %w", err)
+	}
+
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO customer_aliases (alias_id, tenant_id, customer_id, device_id, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (alias_id) DO NOTHING
+		`, alias.AliasID, alias.TenantID, alias.CustomerID, alias.DeviceID, alias.CreatedBy, alias.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to record customer alias: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE customers
+			SET email = $1, phone = $2, custom_attributes = $3, updated_at = $4
+			WHERE id = $5 AND tenant_id = $6
+		`, customer.Email, customer.Phone, attrsJSON, customer.UpdatedAt, customer.ID, customer.TenantID)
+		if err != nil {
+			return fmt.Errorf("failed to merge customer: %w", err)
+		}
+		return nil
+	})
+}
+
 // scanCustomer scans a single customer row
 func (r *PostgresCustomerRepository) scanCustomer(row pgx.Row) (*domain.Customer, error) {
 	var customer domain.Customer
@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresDeliveryRepository implements DeliveryRepository using PostgreSQL
+type PostgresDeliveryRepository struct{}
+
+// NewPostgresDeliveryRepository creates a new PostgreSQL delivery repository
+func NewPostgresDeliveryRepository() *PostgresDeliveryRepository {
+	return &PostgresDeliveryRepository{}
+}
+
+const deliveryRouteColumns = `id, tenant_id, code, name, weekdays, driver_id, vehicle, is_active, created_at, updated_at`
+
+const deliveryColumns = `id, tenant_id, route_id, customer_id, delivery_date, invoice_type, invoice_id, invoice_number,
+	invoice_amount, collect_amount, on_credit, status, collected_amount, received_by, note,
+	khata_entry_id, completed_by, completed_at, created_at, updated_at`
+
+// CreateRoute creates a delivery route
+func (r *PostgresDeliveryRepository) CreateRoute(ctx context.Context, route *domain.DeliveryRoute) error {
+	query := `INSERT INTO delivery_routes (` + deliveryRouteColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		route.ID, route.TenantID, route.Code, route.Name, route.Weekdays, route.DriverID, route.Vehicle,
+		route.IsActive, route.CreatedAt, route.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create delivery route: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateRoute updates a delivery route
+func (r *PostgresDeliveryRepository) UpdateRoute(ctx context.Context, route *domain.DeliveryRoute) error {
+	query := `
+		UPDATE delivery_routes
+		SET code = $1, name = $2, weekdays = $3, driver_id = $4, vehicle = $5, is_active = $6, updated_at = $7
+		WHERE tenant_id = $8 AND id = $9
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		route.Code, route.Name, route.Weekdays, route.DriverID, route.Vehicle, route.IsActive, route.UpdatedAt,
+		route.TenantID, route.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update delivery route: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRouteNotFound
+	}
+
+	return nil
+}
+
+// GetRoute retrieves a delivery route
+func (r *PostgresDeliveryRepository) GetRoute(ctx context.Context, tenantID, id uuid.UUID) (*domain.DeliveryRoute, error) {
+	query := `SELECT ` + deliveryRouteColumns + ` FROM delivery_routes WHERE tenant_id = $1 AND id = $2`
+
+	route, err := scanDeliveryRoute(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrRouteNotFound
+		}
+		return nil, fmt.Errorf("failed to get delivery route: %w", err)
+	}
+
+	return route, nil
+}
+
+// ListRoutes retrieves routes ordered by code
+func (r *PostgresDeliveryRepository) ListRoutes(ctx context.Context, tenantID uuid.UUID, driverID *uuid.UUID, activeOnly bool) ([]*domain.DeliveryRoute, error) {
+	query := `
+		SELECT ` + deliveryRouteColumns + `
+		FROM delivery_routes
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR driver_id = $2)
+		  AND (NOT $3 OR is_active)
+		ORDER BY code
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, driverID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query delivery routes: %w", err)
+	}
+	defer rows.Close()
+
+	routes := []*domain.DeliveryRoute{}
+	for rows.Next() {
+		route, err := scanDeliveryRoute(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delivery route: %w", err)
+		}
+		routes = append(routes, route)
+	}
+
+	return routes, rows.Err()
+}
+
+// AssignCustomer puts a customer on a route, replacing any earlier assignment
+func (r *PostgresDeliveryRepository) AssignCustomer(ctx context.Context, tenantID, routeID, customerID uuid.UUID, sequence int) error {
+	query := `
+		INSERT INTO route_customers (tenant_id, route_id, customer_id, sequence)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, customer_id)
+		DO UPDATE SET route_id = EXCLUDED.route_id, sequence = EXCLUDED.sequence
+	`
+
+	if _, err := db.MainPool.Exec(ctx, query, tenantID, routeID, customerID, sequence); err != nil {
+		return fmt.Errorf("failed to assign customer to route: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveCustomer takes a customer off a route
+func (r *PostgresDeliveryRepository) RemoveCustomer(ctx context.Context, tenantID, routeID, customerID uuid.UUID) error {
+	_, err := db.MainPool.Exec(ctx,
+		`DELETE FROM route_customers WHERE tenant_id = $1 AND route_id = $2 AND customer_id = $3`,
+		tenantID, routeID, customerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove customer from route: %w", err)
+	}
+
+	return nil
+}
+
+// ListStops retrieves a route's customers in visiting order
+func (r *PostgresDeliveryRepository) ListStops(ctx context.Context, tenantID, routeID uuid.UUID) ([]*domain.RouteStop, error) {
+	query := `
+		SELECT rc.route_id, rc.customer_id, rc.sequence, c.customer_code, c.name, c.phone,
+		       COALESCE(c.custom_attributes->>'address', '')
+		FROM route_customers rc
+		JOIN customers c ON c.id = rc.customer_id
+		WHERE rc.tenant_id = $1 AND rc.route_id = $2
+		ORDER BY rc.sequence, c.customer_code
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, routeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query route stops: %w", err)
+	}
+	defer rows.Close()
+
+	stops := []*domain.RouteStop{}
+	for rows.Next() {
+		var stop domain.RouteStop
+		err := rows.Scan(&stop.RouteID, &stop.CustomerID, &stop.Sequence, &stop.CustomerCode,
+			&stop.CustomerName, &stop.Phone, &stop.Address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan route stop: %w", err)
+		}
+		stops = append(stops, &stop)
+	}
+
+	return stops, rows.Err()
+}
+
+// CustomerRoute returns the route a customer is assigned to
+func (r *PostgresDeliveryRepository) CustomerRoute(ctx context.Context, tenantID, customerID uuid.UUID) (*uuid.UUID, error) {
+	var routeID uuid.UUID
+	err := db.MainPool.QueryRow(ctx,
+		`SELECT route_id FROM route_customers WHERE tenant_id = $1 AND customer_id = $2`,
+		tenantID, customerID,
+	).Scan(&routeID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get customer route: %w", err)
+	}
+
+	return &routeID, nil
+}
+
+// CreateDelivery saves a delivery and its lines
+func (r *PostgresDeliveryRepository) CreateDelivery(ctx context.Context, delivery *domain.Delivery) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := `INSERT INTO deliveries (` + deliveryColumns + `)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`
+		_, err := tx.Exec(ctx, query,
+			delivery.ID, delivery.TenantID, delivery.RouteID, delivery.CustomerID, delivery.DeliveryDate,
+			delivery.InvoiceType, delivery.InvoiceID, delivery.InvoiceNumber,
+			delivery.InvoiceAmount, delivery.CollectAmount, delivery.OnCredit, delivery.Status, delivery.CollectedAmount,
+			delivery.ReceivedBy, delivery.Note, delivery.KhataEntryID, delivery.CompletedBy, delivery.CompletedAt,
+			delivery.CreatedAt, delivery.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create delivery: %w", err)
+		}
+
+		for _, line := range delivery.Lines {
+			_, err := tx.Exec(ctx,
+				`INSERT INTO delivery_lines (id, delivery_id, product_id, description, quantity, unit) VALUES ($1, $2, $3, $4, $5, $6)`,
+				line.ID, delivery.ID, line.ProductID, line.Description, line.Quantity, line.Unit,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create delivery line: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetDelivery retrieves a delivery with its lines
+func (r *PostgresDeliveryRepository) GetDelivery(ctx context.Context, tenantID, id uuid.UUID) (*domain.Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM deliveries WHERE tenant_id = $1 AND id = $2`
+
+	delivery, err := scanDelivery(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+
+	if err := r.loadLines(ctx, []*domain.Delivery{delivery}); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// ListDeliveries retrieves a route's deliveries for a day
+func (r *PostgresDeliveryRepository) ListDeliveries(ctx context.Context, tenantID, routeID uuid.UUID, date time.Time) ([]*domain.Delivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM deliveries
+		WHERE tenant_id = $1 AND route_id = $2 AND delivery_date = $3
+		ORDER BY created_at
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, routeID, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*domain.Delivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadLines(ctx, deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Complete saves the outcome of a pending delivery and any khata payment collected
+func (r *PostgresDeliveryRepository) Complete(ctx context.Context, delivery *domain.Delivery, payment *domain.KhataEntry) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if payment != nil {
+			_, err := tx.Exec(ctx, `INSERT INTO khata_entries (`+khataEntryColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+				payment.ID, payment.TenantID, payment.CustomerID, payment.Kind, payment.Amount, payment.EntryDate, payment.DueDate,
+				payment.ReferenceType, payment.ReferenceID, payment.Note, payment.CreatedBy, payment.CreatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create delivery khata payment: %w", err)
+			}
+			delivery.KhataEntryID = &payment.ID
+		}
+
+		query := `
+			UPDATE deliveries
+			SET status = $1, collected_amount = $2, received_by = $3, note = $4, khata_entry_id = $5,
+			    completed_by = $6, completed_at = $7, updated_at = $8
+			WHERE tenant_id = $9 AND id = $10 AND status = 'pending'
+		`
+		tag, err := tx.Exec(ctx, query,
+			delivery.Status, delivery.CollectedAmount, delivery.ReceivedBy, delivery.Note, delivery.KhataEntryID,
+			delivery.CompletedBy, delivery.CompletedAt, delivery.UpdatedAt,
+			delivery.TenantID, delivery.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to complete delivery: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrDeliveryState
+		}
+
+		return nil
+	})
+}
+
+// loadLines attaches their lines to the deliveries
+func (r *PostgresDeliveryRepository) loadLines(ctx context.Context, deliveries []*domain.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(deliveries))
+	byID := make(map[uuid.UUID]*domain.Delivery, len(deliveries))
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+		byID[delivery.ID] = delivery
+		delivery.Lines = []domain.DeliveryLine{}
+	}
+
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT id, delivery_id, product_id, description, quantity, unit
+		FROM delivery_lines
+		WHERE delivery_id = ANY($1)
+		ORDER BY description
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query delivery lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line domain.DeliveryLine
+		if err := rows.Scan(&line.ID, &line.DeliveryID, &line.ProductID, &line.Description, &line.Quantity, &line.Unit); err != nil {
+			return fmt.Errorf("failed to scan delivery line: %w", err)
+		}
+		byID[line.DeliveryID].Lines = append(byID[line.DeliveryID].Lines, line)
+	}
+
+	return rows.Err()
+}
+
+func scanDeliveryRoute(row pgx.Row) (*domain.DeliveryRoute, error) {
+	var route domain.DeliveryRoute
+	err := row.Scan(
+		&route.ID, &route.TenantID, &route.Code, &route.Name, &route.Weekdays, &route.DriverID, &route.Vehicle,
+		&route.IsActive, &route.CreatedAt, &route.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+func scanDelivery(row pgx.Row) (*domain.Delivery, error) {
+	var delivery domain.Delivery
+	err := row.Scan(
+		&delivery.ID, &delivery.TenantID, &delivery.RouteID, &delivery.CustomerID, &delivery.DeliveryDate,
+		&delivery.InvoiceType, &delivery.InvoiceID, &delivery.InvoiceNumber,
+		&delivery.InvoiceAmount, &delivery.CollectAmount, &delivery.OnCredit, &delivery.Status, &delivery.CollectedAmount,
+		&delivery.ReceivedBy, &delivery.Note, &delivery.KhataEntryID, &delivery.CompletedBy, &delivery.CompletedAt,
+		&delivery.CreatedAt, &delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresDunningRepository implements DunningRepository using PostgreSQL
+type PostgresDunningRepository struct{}
+
+// NewPostgresDunningRepository creates a new PostgreSQL dunning repository
+func NewPostgresDunningRepository() *PostgresDunningRepository {
+	return &PostgresDunningRepository{}
+}
+
+const dunningSequenceColumns = `id, tenant_id, name, min_overdue, is_default, is_active, created_at, updated_at`
+
+const dunningNoticeColumns = `id, tenant_id, customer_id, sequence_id, step_position, channel, recipient,
+	outstanding, overdue, days_overdue, status, reason, notification_id, created_at`
+
+// CreateSequence creates a dunning sequence with its steps
+func (r *PostgresDunningRepository) CreateSequence(ctx context.Context, sequence *domain.DunningSequence) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if err := r.clearDefault(ctx, tx, sequence); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO dunning_sequences (` + dunningSequenceColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		_, err := tx.Exec(ctx, query,
+			sequence.ID, sequence.TenantID, sequence.Name, sequence.MinOverdue, sequence.IsDefault,
+			sequence.IsActive, sequence.CreatedAt, sequence.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create dunning sequence: %w", err)
+		}
+
+		return r.insertSteps(ctx, tx, sequence)
+	})
+}
+
+// GetSequence retrieves a dunning sequence with its steps
+func (r *PostgresDunningRepository) GetSequence(ctx context.Context, tenantID, id uuid.UUID) (*domain.DunningSequence, error) {
+	query := `SELECT ` + dunningSequenceColumns + ` FROM dunning_sequences WHERE tenant_id = $1 AND id = $2`
+
+	sequence, err := r.scanSequence(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDunningSequenceNotFound
+		}
+		return nil, fmt.Errorf("failed to get dunning sequence: %w", err)
+	}
+
+	if err := r.loadSteps(ctx, sequence); err != nil {
+		return nil, err
+	}
+	return sequence, nil
+}
+
+// GetDefaultSequence retrieves the tenant's active default sequence
+func (r *PostgresDunningRepository) GetDefaultSequence(ctx context.Context, tenantID uuid.UUID) (*domain.DunningSequence, error) {
+	query := `SELECT ` + dunningSequenceColumns + ` FROM dunning_sequences WHERE tenant_id = $1 AND is_default AND is_active`
+
+	sequence, err := r.scanSequence(db.MainPool.QueryRow(ctx, query, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get default dunning sequence: %w", err)
+	}
+
+	if err := r.loadSteps(ctx, sequence); err != nil {
+		return nil, err
+	}
+	return sequence, nil
+}
+
+// ListSequences retrieves a tenant's dunning sequences with their steps
+func (r *PostgresDunningRepository) ListSequences(ctx context.Context, tenantID uuid.UUID) ([]*domain.DunningSequence, error) {
+	query := `SELECT ` + dunningSequenceColumns + ` FROM dunning_sequences WHERE tenant_id = $1 ORDER BY is_default DESC, name`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dunning sequences: %w", err)
+	}
+	defer rows.Close()
+
+	sequences := []*domain.DunningSequence{}
+	for rows.Next() {
+		sequence, err := r.scanSequence(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dunning sequence: %w", err)
+		}
+		sequences = append(sequences, sequence)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	rows.Close()
+
+	for _, sequence := range sequences {
+		if err := r.loadSteps(ctx, sequence); err != nil {
+			return nil, err
+		}
+	}
+
+	return sequences, nil
+}
+
+// UpdateSequence updates a dunning sequence and replaces its steps
+func (r *PostgresDunningRepository) UpdateSequence(ctx context.Context, sequence *domain.DunningSequence) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if err := r.clearDefault(ctx, tx, sequence); err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE dunning_sequences
+			SET name = $1, min_overdue = $2, is_default = $3, is_active = $4, updated_at = $5
+			WHERE tenant_id = $6 AND id = $7
+		`
+		tag, err := tx.Exec(ctx, query,
+			sequence.Name, sequence.MinOverdue, sequence.IsDefault, sequence.IsActive, sequence.UpdatedAt,
+			sequence.TenantID, sequence.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update dunning sequence: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrDunningSequenceNotFound
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM dunning_steps WHERE sequence_id = $1`, sequence.ID); err != nil {
+			return fmt.Errorf("failed to clear dunning steps: %w", err)
+		}
+
+		return r.insertSteps(ctx, tx, sequence)
+	})
+}
+
+// DeleteSequence deletes a dunning sequence; customers part-way through it start over
+func (r *PostgresDunningRepository) DeleteSequence(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := db.MainPool.Exec(ctx, `DELETE FROM dunning_sequences WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete dunning sequence: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDunningSequenceNotFound
+	}
+
+	return nil
+}
+
+// ListTenantsWithSequences retrieves tenants with an active default dunning sequence
+func (r *PostgresDunningRepository) ListTenantsWithSequences(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := db.MainPool.Query(ctx, `SELECT tenant_id FROM dunning_sequences WHERE is_default AND is_active`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenants with dunning sequences: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, id)
+	}
+
+	return tenants, rows.Err()
+}
+
+// ListStates retrieves the tenant's customers part-way through dunning, keyed by customer
+func (r *PostgresDunningRepository) ListStates(ctx context.Context, tenantID uuid.UUID) (map[uuid.UUID]*domain.DunningState, error) {
+	query := `
+		SELECT tenant_id, customer_id, sequence_id, last_position, started_at, last_sent_at
+		FROM dunning_states
+		WHERE tenant_id = $1
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dunning states: %w", err)
+	}
+	defer rows.Close()
+
+	states := map[uuid.UUID]*domain.DunningState{}
+	for rows.Next() {
+		var state domain.DunningState
+		if err := rows.Scan(
+			&state.TenantID, &state.CustomerID, &state.SequenceID, &state.LastPosition, &state.StartedAt, &state.LastSentAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan dunning state: %w", err)
+		}
+		states[state.CustomerID] = &state
+	}
+
+	return states, rows.Err()
+}
+
+// SaveState upserts a customer's dunning progress
+func (r *PostgresDunningRepository) SaveState(ctx context.Context, state *domain.DunningState) error {
+	query := `
+		INSERT INTO dunning_states (tenant_id, customer_id, sequence_id, last_position, started_at, last_sent_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id, customer_id) DO UPDATE
+		SET sequence_id = EXCLUDED.sequence_id, last_position = EXCLUDED.last_position,
+		    last_sent_at = EXCLUDED.last_sent_at
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		state.TenantID, state.CustomerID, state.SequenceID, state.LastPosition, state.StartedAt, state.LastSentAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save dunning state: %w", err)
+	}
+
+	return nil
+}
+
+// ClearState ends a customer's dunning sequence
+func (r *PostgresDunningRepository) ClearState(ctx context.Context, tenantID, customerID uuid.UUID) error {
+	_, err := db.MainPool.Exec(ctx, `DELETE FROM dunning_states WHERE tenant_id = $1 AND customer_id = $2`, tenantID, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to clear dunning state: %w", err)
+	}
+
+	return nil
+}
+
+// CreateNotice records a dunning notice
+func (r *PostgresDunningRepository) CreateNotice(ctx context.Context, notice *domain.DunningNotice) error {
+	query := `INSERT INTO dunning_notices (` + dunningNoticeColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		notice.ID, notice.TenantID, notice.CustomerID, notice.SequenceID, notice.StepPosition, notice.Channel,
+		notice.Recipient, notice.Outstanding, notice.Overdue, notice.DaysOverdue, notice.Status, notice.Reason,
+		notice.NotificationID, notice.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create dunning notice: %w", err)
+	}
+
+	return nil
+}
+
+// ListNotices retrieves dunning notices, newest first, optionally for one customer
+func (r *PostgresDunningRepository) ListNotices(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, limit, offset int) ([]*domain.DunningNotice, error) {
+	query := `
+		SELECT ` + dunningNoticeColumns + `
+		FROM dunning_notices
+		WHERE tenant_id = $1 AND ($2::uuid IS NULL OR customer_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, customerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dunning notices: %w", err)
+	}
+	defer rows.Close()
+
+	notices := []*domain.DunningNotice{}
+	for rows.Next() {
+		var notice domain.DunningNotice
+		if err := rows.Scan(
+			&notice.ID, &notice.TenantID, &notice.CustomerID, &notice.SequenceID, &notice.StepPosition, &notice.Channel,
+			&notice.Recipient, &notice.Outstanding, &notice.Overdue, &notice.DaysOverdue, &notice.Status, &notice.Reason,
+			&notice.NotificationID, &notice.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan dunning notice: %w", err)
+		}
+		notices = append(notices, &notice)
+	}
+
+	return notices, rows.Err()
+}
+
+// clearDefault unsets the tenant's other default sequence before this one takes the flag
+func (r *PostgresDunningRepository) clearDefault(ctx context.Context, tx pgx.Tx, sequence *domain.DunningSequence) error {
+	if !sequence.IsDefault {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx,
+		`UPDATE dunning_sequences SET is_default = false WHERE tenant_id = $1 AND id <> $2 AND is_default`,
+		sequence.TenantID, sequence.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to clear default dunning sequence: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresDunningRepository) insertSteps(ctx context.Context, tx pgx.Tx, sequence *domain.DunningSequence) error {
+	query := `
+		INSERT INTO dunning_steps (id, sequence_id, position, days_overdue, channel, template_code)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	for _, step := range sequence.Steps {
+		_, err := tx.Exec(ctx, query, step.ID, sequence.ID, step.Position, step.DaysOverdue, step.Channel, step.TemplateCode)
+		if err != nil {
+			return fmt.Errorf("failed to create dunning step: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *PostgresDunningRepository) loadSteps(ctx context.Context, sequence *domain.DunningSequence) error {
+	query := `
+		SELECT id, sequence_id, position, days_overdue, channel, template_code
+		FROM dunning_steps
+		WHERE sequence_id = $1
+		ORDER BY position
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, sequence.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query dunning steps: %w", err)
+	}
+	defer rows.Close()
+
+	sequence.Steps = []domain.DunningStep{}
+	for rows.Next() {
+		var step domain.DunningStep
+		if err := rows.Scan(&step.ID, &step.SequenceID, &step.Position, &step.DaysOverdue, &step.Channel, &step.TemplateCode); err != nil {
+			return fmt.Errorf("failed to scan dunning step: %w", err)
+		}
+		sequence.Steps = append(sequence.Steps, step)
+	}
+
+	return rows.Err()
+}
+
+func (r *PostgresDunningRepository) scanSequence(row pgx.Row) (*domain.DunningSequence, error) {
+	sequence := &domain.DunningSequence{}
+	err := row.Scan(
+		&sequence.ID, &sequence.TenantID, &sequence.Name, &sequence.MinOverdue, &sequence.IsDefault,
+		&sequence.IsActive, &sequence.CreatedAt, &sequence.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return sequence, nil
+}
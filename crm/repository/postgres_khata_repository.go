@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresKhataRepository implements KhataRepository using PostgreSQL
+type PostgresKhataRepository struct{}
+
+// NewPostgresKhataRepository creates a new PostgreSQL khata repository
+func NewPostgresKhataRepository() *PostgresKhataRepository {
+	return &PostgresKhataRepository{}
+}
+
+const khataEntryColumns = `id, tenant_id, customer_id, kind, amount, entry_date, due_date,
+	reference_type, reference_id, note, created_by, created_at`
+
+// debtorsQuery selects customers whose charges exceed their payments
+const debtorsQuery = `
+	SELECT customer_id
+	FROM khata_entries
+	WHERE tenant_id = $1
+	GROUP BY customer_id
+	HAVING SUM(CASE WHEN kind = 'charge' THEN amount ELSE -amount END) > 0
+`
+
+// CreateEntry creates a khata entry
+func (r *PostgresKhataRepository) CreateEntry(ctx context.Context, entry *domain.KhataEntry) error {
+	query := `INSERT INTO khata_entries (` + khataEntryColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		entry.ID, entry.TenantID, entry.CustomerID, entry.Kind, entry.Amount, entry.EntryDate, entry.DueDate,
+		entry.ReferenceType, entry.ReferenceID, entry.Note, entry.CreatedBy, entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create khata entry: %w", err)
+	}
+
+	return nil
+}
+
+// CreateEntries creates several khata entries in one transaction
+func (r *PostgresKhataRepository) CreateEntries(ctx context.Context, entries []*domain.KhataEntry) error {
+	query := `INSERT INTO khata_entries (` + khataEntryColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	err := db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		for _, entry := range entries {
+			if _, err := tx.Exec(ctx, query,
+				entry.ID, entry.TenantID, entry.CustomerID, entry.Kind, entry.Amount, entry.EntryDate, entry.DueDate,
+				entry.ReferenceType, entry.ReferenceID, entry.Note, entry.CreatedBy, entry.CreatedAt,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create khata entries: %w", err)
+	}
+
+	return nil
+}
+
+// ListEntries retrieves a customer's khata entries, newest first
+func (r *PostgresKhataRepository) ListEntries(ctx context.Context, tenantID, customerID uuid.UUID, limit, offset int) ([]*domain.KhataEntry, error) {
+	query := `
+		SELECT ` + khataEntryColumns + `
+		FROM khata_entries
+		WHERE tenant_id = $1 AND customer_id = $2
+		ORDER BY entry_date DESC, created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	return r.queryEntries(ctx, query, tenantID, customerID, limit, offset)
+}
+
+// CustomerEntries retrieves all of a customer's khata entries in date order
+func (r *PostgresKhataRepository) CustomerEntries(ctx context.Context, tenantID, customerID uuid.UUID) ([]*domain.KhataEntry, error) {
+	query := `
+		SELECT ` + khataEntryColumns + `
+		FROM khata_entries
+		WHERE tenant_id = $1 AND customer_id = $2
+		ORDER BY entry_date, created_at
+	`
+
+	return r.queryEntries(ctx, query, tenantID, customerID)
+}
+
+// ListDebtors retrieves the customers that owe money on khata
+func (r *PostgresKhataRepository) ListDebtors(ctx context.Context, tenantID uuid.UUID) ([]*domain.CustomerDues, error) {
+	query := `
+		SELECT c.id, c.customer_code, c.name
+		FROM customers c
+		WHERE c.id IN (` + debtorsQuery + `)
+		ORDER BY c.name
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query khata debtors: %w", err)
+	}
+	defer rows.Close()
+
+	debtors := []*domain.CustomerDues{}
+	for rows.Next() {
+		var dues domain.CustomerDues
+		if err := rows.Scan(&dues.CustomerID, &dues.CustomerCode, &dues.CustomerName); err != nil {
+			return nil, fmt.Errorf("failed to scan khata debtor: %w", err)
+		}
+		debtors = append(debtors, &dues)
+	}
+
+	return debtors, rows.Err()
+}
+
+// OpenEntries retrieves the entries of every customer that owes money, keyed by customer
+func (r *PostgresKhataRepository) OpenEntries(ctx context.Context, tenantID uuid.UUID) (map[uuid.UUID][]*domain.KhataEntry, error) {
+	query := `
+		SELECT ` + khataEntryColumns + `
+		FROM khata_entries
+		WHERE tenant_id = $1 AND customer_id IN (` + debtorsQuery + `)
+		ORDER BY customer_id, entry_date, created_at
+	`
+
+	entries, err := r.queryEntries(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	byCustomer := map[uuid.UUID][]*domain.KhataEntry{}
+	for _, entry := range entries {
+		byCustomer[entry.CustomerID] = append(byCustomer[entry.CustomerID], entry)
+	}
+
+	return byCustomer, nil
+}
+
+func (r *PostgresKhataRepository) queryEntries(ctx context.Context, query string, args ...interface{}) ([]*domain.KhataEntry, error) {
+	rows, err := db.MainPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query khata entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*domain.KhataEntry{}
+	for rows.Next() {
+		entry, err := r.scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *PostgresKhataRepository) scanEntry(row pgx.Row) (*domain.KhataEntry, error) {
+	var entry domain.KhataEntry
+	err := row.Scan(
+		&entry.ID, &entry.TenantID, &entry.CustomerID, &entry.Kind, &entry.Amount, &entry.EntryDate, &entry.DueDate,
+		&entry.ReferenceType, &entry.ReferenceID, &entry.Note, &entry.CreatedBy, &entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan khata entry: %w", err)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresPurchaseReturnRepository implements PurchaseReturnRepository using PostgreSQL
+type PostgresPurchaseReturnRepository struct{}
+
+// NewPostgresPurchaseReturnRepository creates a new PostgreSQL purchase return repository
+func NewPostgresPurchaseReturnRepository() *PostgresPurchaseReturnRepository {
+	return &PostgresPurchaseReturnRepository{}
+}
+
+const purchaseReturnColumns = `id, tenant_id, supplier_id, return_number, receipt_type, receipt_id, receipt_number,
+	returned_at, note, sub_total, vat_amount, total_amount, debit_note_number, journal_entry_id, stock_posted_at,
+	created_by, created_at`
+
+// Create checks a return against earlier returns on the receipt and saves it with its lines
+func (r *PostgresPurchaseReturnRepository) Create(ctx context.Context, ret *domain.PurchaseReturn, check ReturnCheck) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		// Serialise returns against the same receipt until this one commits
+		_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`,
+			ret.TenantID.String()+"|"+ret.ReceiptType+"|"+ret.ReceiptID.String())
+		if err != nil {
+			return fmt.Errorf("failed to lock purchase receipt: %w", err)
+		}
+
+		returnedByLine, returnedAmount, err := r.returned(ctx, tx, ret)
+		if err != nil {
+			return err
+		}
+
+		debitNote, err := check(returnedByLine, returnedAmount)
+		if err != nil {
+			return err
+		}
+
+		var next int64
+		err = tx.QueryRow(ctx,
+			`SELECT COUNT(*) + 1 FROM purchase_returns WHERE tenant_id = $1`, ret.TenantID,
+		).Scan(&next)
+		if err != nil {
+			return fmt.Errorf("failed to number purchase return: %w", err)
+		}
+		ret.ReturnNumber = fmt.Sprintf("RMA-%05d", next)
+
+		ret.DebitNoteNumber = nil
+		if debitNote {
+			err = tx.QueryRow(ctx,
+				`SELECT COUNT(debit_note_number) + 1 FROM purchase_returns WHERE tenant_id = $1`, ret.TenantID,
+			).Scan(&next)
+			if err != nil {
+				return fmt.Errorf("failed to number debit note: %w", err)
+			}
+			number := fmt.Sprintf("DN-%05d", next)
+			ret.DebitNoteNumber = &number
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO purchase_returns (`+purchaseReturnColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		`,
+			ret.ID, ret.TenantID, ret.SupplierID, ret.ReturnNumber, ret.ReceiptType, ret.ReceiptID, ret.ReceiptNumber,
+			ret.ReturnedAt, ret.Note, ret.SubTotal, ret.VATAmount, ret.TotalAmount, ret.DebitNoteNumber,
+			ret.JournalEntryID, ret.StockPostedAt, ret.CreatedBy, ret.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create purchase return: %w", err)
+		}
+
+		for _, line := range ret.Lines {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO purchase_return_lines (
+					id, tenant_id, return_id, receipt_line_id, product_id, quantity, unit_cost, amount, reason, note
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			`,
+				line.ID, ret.TenantID, ret.ID, line.ReceiptLineID, line.ProductID, line.Quantity, line.UnitCost,
+				line.Amount, line.Reason, line.Note,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create purchase return line: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// returned sums what earlier returns sent back on the receipt, per receipt line and in total
+func (r *PostgresPurchaseReturnRepository) returned(ctx context.Context, tx pgx.Tx, ret *domain.PurchaseReturn) (map[uuid.UUID]float64, float64, error) {
+	var returnedAmount float64
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(total_amount), 0)
+		FROM purchase_returns
+		WHERE tenant_id = $1 AND receipt_type = $2 AND receipt_id = $3
+	`, ret.TenantID, ret.ReceiptType, ret.ReceiptID).Scan(&returnedAmount)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to sum purchase returns: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT l.receipt_line_id, SUM(l.quantity)
+		FROM purchase_return_lines l
+		JOIN purchase_returns p ON p.id = l.return_id
+		WHERE p.tenant_id = $1 AND p.receipt_type = $2 AND p.receipt_id = $3 AND l.receipt_line_id IS NOT NULL
+		GROUP BY l.receipt_line_id
+	`, ret.TenantID, ret.ReceiptType, ret.ReceiptID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to sum purchase return lines: %w", err)
+	}
+	defer rows.Close()
+
+	returnedByLine := map[uuid.UUID]float64{}
+	for rows.Next() {
+		var lineID uuid.UUID
+		var quantity float64
+		if err := rows.Scan(&lineID, &quantity); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan purchase return line total: %w", err)
+		}
+		returnedByLine[lineID] = quantity
+	}
+	return returnedByLine, returnedAmount, rows.Err()
+}
+
+// Get retrieves a purchase return with its lines
+func (r *PostgresPurchaseReturnRepository) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.PurchaseReturn, error) {
+	query := `SELECT ` + purchaseReturnColumns + ` FROM purchase_returns WHERE tenant_id = $1 AND id = $2`
+
+	ret, err := scanPurchaseReturn(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPurchaseReturnNotFound
+		}
+		return nil, fmt.Errorf("failed to get purchase return: %w", err)
+	}
+
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT id, return_id, receipt_line_id, product_id, quantity, unit_cost, amount, reason, note
+		FROM purchase_return_lines
+		WHERE return_id = $1
+		ORDER BY product_id
+	`, ret.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchase return lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line domain.PurchaseReturnLine
+		if err := rows.Scan(
+			&line.ID, &line.ReturnID, &line.ReceiptLineID, &line.ProductID, &line.Quantity, &line.UnitCost,
+			&line.Amount, &line.Reason, &line.Note,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase return line: %w", err)
+		}
+		ret.Lines = append(ret.Lines, line)
+	}
+
+	return ret, rows.Err()
+}
+
+// List retrieves purchase returns (without lines), newest first
+func (r *PostgresPurchaseReturnRepository) List(ctx context.Context, tenantID uuid.UUID, filter domain.PurchaseReturnFilter) ([]*domain.PurchaseReturn, error) {
+	query := `
+		SELECT ` + purchaseReturnColumns + `
+		FROM purchase_returns p
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR supplier_id = $2)
+		  AND ($3::uuid IS NULL OR receipt_id = $3)
+		  AND ($4::varchar IS NULL OR EXISTS (
+		      SELECT 1 FROM purchase_return_lines l WHERE l.return_id = p.id AND l.reason = $4
+		  ))
+		ORDER BY returned_at DESC, return_number DESC
+		LIMIT $5 OFFSET $6
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.SupplierID, filter.ReceiptID, filter.Reason, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchase returns: %w", err)
+	}
+	defer rows.Close()
+
+	returns := []*domain.PurchaseReturn{}
+	for rows.Next() {
+		ret, err := scanPurchaseReturn(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan purchase return: %w", err)
+		}
+		returns = append(returns, ret)
+	}
+
+	return returns, rows.Err()
+}
+
+// SetJournalEntry links a return to the journal entry of its debit note
+func (r *PostgresPurchaseReturnRepository) SetJournalEntry(ctx context.Context, returnID, journalEntryID uuid.UUID) error {
+	_, err := db.MainPool.Exec(ctx,
+		`UPDATE purchase_returns SET journal_entry_id = $2 WHERE id = $1`, returnID, journalEntryID)
+	if err != nil {
+		return fmt.Errorf("failed to link purchase return journal entry: %w", err)
+	}
+	return nil
+}
+
+// SetStockPosted records when the returned goods left stock
+func (r *PostgresPurchaseReturnRepository) SetStockPosted(ctx context.Context, returnID uuid.UUID, postedAt time.Time) error {
+	_, err := db.MainPool.Exec(ctx,
+		`UPDATE purchase_returns SET stock_posted_at = $2 WHERE id = $1`, returnID, postedAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark purchase return stock posted: %w", err)
+	}
+	return nil
+}
+
+// ReasonStats sums returned lines per supplier and reason
+func (r *PostgresPurchaseReturnRepository) ReasonStats(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.ReturnReasonStat, error) {
+	query := `
+		SELECT p.supplier_id, l.reason, COUNT(DISTINCT p.id), SUM(l.quantity), SUM(l.amount)
+		FROM purchase_return_lines l
+		JOIN purchase_returns p ON p.id = l.return_id
+		WHERE p.tenant_id = $1 AND p.returned_at >= $2 AND p.returned_at < $3 + INTERVAL '1 day'
+		GROUP BY p.supplier_id, l.reason
+		ORDER BY p.supplier_id, SUM(l.amount) DESC
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query purchase return reasons: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []*domain.ReturnReasonStat{}
+	for rows.Next() {
+		var stat domain.ReturnReasonStat
+		if err := rows.Scan(&stat.SupplierID, &stat.Reason, &stat.Returns, &stat.Quantity, &stat.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase return reason: %w", err)
+		}
+		stats = append(stats, &stat)
+	}
+	return stats, rows.Err()
+}
+
+// scanPurchaseReturn scans a purchase_returns row in purchaseReturnColumns order
+func scanPurchaseReturn(row pgx.Row) (*domain.PurchaseReturn, error) {
+	ret := domain.PurchaseReturn{Lines: []domain.PurchaseReturnLine{}}
+	err := row.Scan(
+		&ret.ID, &ret.TenantID, &ret.SupplierID, &ret.ReturnNumber, &ret.ReceiptType, &ret.ReceiptID, &ret.ReceiptNumber,
+		&ret.ReturnedAt, &ret.Note, &ret.SubTotal, &ret.VATAmount, &ret.TotalAmount, &ret.DebitNoteNumber,
+		&ret.JournalEntryID, &ret.StockPostedAt, &ret.CreatedBy, &ret.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// PostgresQuickPickRepository implements QuickPickRepository using PostgreSQL
+type PostgresQuickPickRepository struct{}
+
+// NewPostgresQuickPickRepository creates a new PostgreSQL quick-pick repository
+func NewPostgresQuickPickRepository() *PostgresQuickPickRepository {
+	return &PostgresQuickPickRepository{}
+}
+
+// RecordView records that a user opened a customer
+func (r *PostgresQuickPickRepository) RecordView(ctx context.Context, tenantID, userID, customerID uuid.UUID) error {
+	query := `
+		INSERT INTO customer_quick_picks (tenant_id, user_id, customer_id, view_count, last_viewed_at)
+		VALUES ($1, $2, $3, 1, NOW())
+		ON CONFLICT (user_id, customer_id) DO UPDATE
+		SET view_count = customer_quick_picks.view_count + 1, last_viewed_at = NOW()
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, tenantID, userID, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to record customer view: %w", err)
+	}
+
+	return nil
+}
+
+// SetFavorite pins or unpins a customer for a user
+func (r *PostgresQuickPickRepository) SetFavorite(ctx context.Context, tenantID, userID, customerID uuid.UUID, favorite bool, position int) error {
+	query := `
+		INSERT INTO customer_quick_picks (tenant_id, user_id, customer_id, is_favorite, position)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, customer_id) DO UPDATE
+		SET is_favorite = EXCLUDED.is_favorite, position = EXCLUDED.position
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, tenantID, userID, customerID, favorite, position)
+	if err != nil {
+		return fmt.Errorf("failed to set customer favorite: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves a user's quick-pick customers with customer details
+func (r *PostgresQuickPickRepository) List(ctx context.Context, tenantID, userID uuid.UUID, limit int) ([]*domain.CustomerQuickPick, error) {
+	query := `
+		SELECT c.id, c.tenant_id, c.customer_code, c.name, c.email, c.phone,
+		       c.customer_type, c.status, c.custom_attributes, c.created_at, c.updated_at,
+		       q.user_id, q.is_favorite, q.position, q.view_count, q.last_viewed_at
+		FROM customer_quick_picks q
+		JOIN customers c ON c.id = q.customer_id
+		WHERE q.tenant_id = $1 AND q.user_id = $2 AND c.status = 'active'
+		ORDER BY q.is_favorite DESC, q.position, q.last_viewed_at DESC NULLS LAST
+		LIMIT $3
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quick picks: %w", err)
+	}
+	defer rows.Close()
+
+	picks := []*domain.CustomerQuickPick{}
+	for rows.Next() {
+		var customer domain.Customer
+		var pick domain.CustomerQuickPick
+		var attrsJSON []byte
+
+		err := rows.Scan(
+			&customer.ID, &customer.TenantID, &customer.CustomerCode,
+			&customer.Name, &customer.Email, &customer.Phone,
+			&customer.CustomerType, &customer.Status, &attrsJSON,
+			&customer.CreatedAt, &customer.UpdatedAt,
+			&pick.UserID, &pick.IsFavorite, &pick.Position, &pick.ViewCount, &pick.LastViewedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quick pick: %w", err)
+		}
+
+		// Unmarshal custom attributes
+		if len(attrsJSON) > 0 {
+			if err := json.Unmarshal(attrsJSON, &customer.CustomAttributes); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal custom attributes: %w", err)
+			}
+		}
+		if customer.CustomAttributes == nil {
+			customer.CustomAttributes = make(map[string]interface{})
+		}
+
+		pick.Customer = &customer
+		picks = append(picks, &pick)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return picks, nil
+}
+
+// TrimRecent deletes a user's oldest non-favorite views beyond keep
+func (r *PostgresQuickPickRepository) TrimRecent(ctx context.Context, tenantID, userID uuid.UUID, keep int) error {
+	query := `
+		DELETE FROM customer_quick_picks
+		WHERE tenant_id = $1 AND user_id = $2 AND is_favorite = false
+		AND customer_id NOT IN (
+			SELECT customer_id FROM customer_quick_picks
+			WHERE tenant_id = $1 AND user_id = $2 AND is_favorite = false
+			ORDER BY last_viewed_at DESC NULLS LAST
+			LIMIT $3
+		)
+	`
+
+	_, err := db.MainPool.Exec(ctx, query, tenantID, userID, keep)
+	if err != nil {
+		return fmt.Errorf("failed to trim recent customers: %w", err)
+	}
+
+	return nil
+}
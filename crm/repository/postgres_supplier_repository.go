@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aceextension/core/db"
 	"github.com/aceextension/crm/domain"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
@@ -47,16 +50,20 @@ func (r *PostgresSupplierRepository) Create(ctx context.Context, supplier *domai
 	return nil
 }
 
-// GetByID retrieves a supplier by ID
-func (r *PostgresSupplierRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Supplier, error) {
+// GetByID retrieves a tenant's supplier by ID
+func (r *PostgresSupplierRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Supplier, error) {
 	query := `
 		SELECT id, tenant_id, supplier_code, name, email, phone,
 		       supplier_type, status, custom_attributes, created_at, updated_at
 		FROM suppliers
-		WHERE id = $1
+		WHERE tenant_id = $1 AND id = $2
 	`
 
-	return r.scanSupplier(db.MainPool.QueryRow(ctx, query, id))
+	supplier, err := r.scanSupplier(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSupplierNotFound
+	}
+	return supplier, err
 }
 
 // GetByCode retrieves a supplier by supplier code
@@ -71,24 +78,64 @@ func (r *PostgresSupplierRepository) GetByCode(ctx context.Context, tenantID uui
 	return r.scanSupplier(db.MainPool.QueryRow(ctx, query, tenantID, code))
 }
 
-// GetByTenantID retrieves all suppliers for a tenant
-func (r *PostgresSupplierRepository) GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Supplier, error) {
-	query := `
+// supplierListFilter selects the suppliers List pages through and counts: the tenant's
+// suppliers, only those carrying the tags in $3 when it is not null
+const supplierListFilter = `
+	tenant_id = $1
+	AND ($3::text[] IS NULL OR id IN (
+		SELECT ta.entity_id
+		FROM tag_assignments ta
+		JOIN tags t ON t.id = ta.tag_id
+		WHERE ta.tenant_id = $1 AND ta.entity_type = $2 AND t.name = ANY($3)
+		GROUP BY ta.entity_id
+		HAVING COUNT(*) >= $4
+	))
+`
+
+// List retrieves a page of a tenant's suppliers, newest first, and how many there are in all.
+// Rows are read by keyset, so deep pages cost the same as the first.
+func (r *PostgresSupplierRepository) List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) ([]*domain.Supplier, int64, error) {
+	var names []string
+	minMatches := 0
+	if tags != nil {
+		names, minMatches = tags.Names, tags.MinMatches()
+	}
+	var afterAt *time.Time
+	var afterID *uuid.UUID
+	if page.After != nil {
+		afterAt, afterID = new(time.Time), new(uuid.UUID)
+		if err := page.After.Scan(afterAt, afterID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM suppliers WHERE ` + supplierListFilter
+	if err := db.MainPool.QueryRow(ctx, countQuery, tenantID, tagsDomain.EntitySupplier, names, minMatches).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count suppliers: %w", err)
+	}
+
+	listQuery := `
 		SELECT id, tenant_id, supplier_code, name, email, phone,
 		       supplier_type, status, custom_attributes, created_at, updated_at
 		FROM suppliers
-		WHERE tenant_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		WHERE ` + supplierListFilter + `
+		AND ($5::timestamp IS NULL OR (created_at, id) < ($5, $6))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7
 	`
 
-	rows, err := db.MainPool.Query(ctx, query, tenantID, limit, offset)
+	rows, err := db.MainPool.Query(ctx, listQuery, tenantID, tagsDomain.EntitySupplier, names, minMatches, afterAt, afterID, page.Fetch())
 	if err != nil {
-		return nil, fmt.Errorf("failed to query suppliers: %w", err)
+		return nil, 0, fmt.Errorf("failed to query suppliers: %w", err)
 	}
 	defer rows.Close()
 
-	return r.scanSuppliers(rows)
+	suppliers, err := r.scanSuppliers(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return suppliers, total, nil
 }
 
 // Update updates a supplier
@@ -102,29 +149,35 @@ func (r *PostgresSupplierRepository) Update(ctx context.Context, supplier *domai
 		UPDATE suppliers
 		SET name = $1, email = $2, phone = $3, supplier_type = $4,
 		    status = $5, custom_attributes = $6, updated_at = $7
-		WHERE id = $8
+		WHERE tenant_id = $8 AND id = $9
 	`
 
-	_, err = db.MainPool.Exec(ctx, query,
+	tag, err := db.MainPool.Exec(ctx, query,
 		supplier.Name, supplier.Email, supplier.Phone, supplier.SupplierType,
-		supplier.Status, attrsJSON, supplier.UpdatedAt, supplier.ID,
+		supplier.Status, attrsJSON, supplier.UpdatedAt, supplier.TenantID, supplier.ID,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to update supplier: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSupplierNotFound
+	}
 
 	return nil
 }
 
-// Delete deletes a supplier
-func (r *PostgresSupplierRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM suppliers WHERE id = $1`
+// Delete deletes a tenant's supplier
+func (r *PostgresSupplierRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	query := `DELETE FROM suppliers WHERE tenant_id = $1 AND id = $2`
 
-	_, err := db.MainPool.Exec(ctx, query, id)
+	tag, err := db.MainPool.Exec(ctx, query, tenantID, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete supplier: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSupplierNotFound
+	}
 
 	return nil
 }
@@ -176,6 +229,101 @@ func (r *PostgresSupplierRepository) SearchByCustomAttribute(ctx context.Context
 	return r.scanSuppliers(rows)
 }
 
+// supplierAreaFilter selects the suppliers ListByArea pages through and counts
+const supplierAreaFilter = `
+	tenant_id = $1
+	AND custom_attributes->'address_detail'->>'district' = $2
+	AND ($3 = '' OR lower(custom_attributes->'address_detail'->>'municipality') = lower($3))
+	AND ($4 = 0 OR (custom_attributes->'address_detail'->>'ward')::int = $4)
+`
+
+// supplierAreaOrder is ListByArea's sort key; missing address parts sort as empty, like
+// their zero values in a cursor
+const supplierAreaOrder = `
+	COALESCE(custom_attributes->'address_detail'->>'municipality', ''),
+	COALESCE((custom_attributes->'address_detail'->>'ward')::int, 0),
+	COALESCE(custom_attributes->'address_detail'->>'tole', ''),
+	name, id
+`
+
+// ListByArea retrieves a page of suppliers with a structured address in an area, grouped by
+// municipality, ward and tole, and how many there are in all
+func (r *PostgresSupplierRepository) ListByArea(ctx context.Context, tenantID uuid.UUID, filter domain.AreaFilter, page db.PageRequest) ([]*domain.Supplier, int64, error) {
+	var afterMunicipality, afterTole, afterName *string
+	var afterWard *int
+	var afterID *uuid.UUID
+	if page.After != nil {
+		afterMunicipality, afterWard, afterTole, afterName, afterID = new(string), new(int), new(string), new(string), new(uuid.UUID)
+		if err := page.After.Scan(afterMunicipality, afterWard, afterTole, afterName, afterID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM suppliers WHERE ` + supplierAreaFilter
+	if err := db.MainPool.QueryRow(ctx, countQuery, tenantID, filter.District, filter.Municipality, filter.Ward).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count suppliers by area: %w", err)
+	}
+
+	query := `
+		SELECT id, tenant_id, supplier_code, name, email, phone,
+		       supplier_type, status, custom_attributes, created_at, updated_at
+		FROM suppliers
+		WHERE ` + supplierAreaFilter + `
+		AND ($5::text IS NULL OR (` + supplierAreaOrder + `) > ($5, $6, $7, $8, $9))
+		ORDER BY ` + supplierAreaOrder + `
+		LIMIT $10
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.District, filter.Municipality, filter.Ward,
+		afterMunicipality, afterWard, afterTole, afterName, afterID, page.Fetch())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list suppliers by area: %w", err)
+	}
+	defer rows.Close()
+
+	suppliers, err := r.scanSuppliers(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return suppliers, total, nil
+}
+
+// ListByTags retrieves suppliers carrying all (or any) of the filter's tags
+func (r *PostgresSupplierRepository) ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*domain.Supplier, error) {
+	listQuery := `
+		SELECT id, tenant_id, supplier_code, name, email, phone,
+		       supplier_type, status, custom_attributes, created_at, updated_at
+		FROM suppliers
+		WHERE tenant_id = $1
+		AND id IN (
+			SELECT ta.entity_id
+			FROM tag_assignments ta
+			JOIN tags t ON t.id = ta.tag_id
+			WHERE ta.tenant_id = $1 AND ta.entity_type = $2 AND t.name = ANY($3)
+			GROUP BY ta.entity_id
+			HAVING COUNT(*) >= $4
+		)
+		AND (
+			$5 = ''
+			OR name ILIKE '%' || $5 || '%'
+			OR email ILIKE '%' || $5 || '%'
+			OR phone ILIKE '%' || $5 || '%'
+			OR supplier_code ILIKE '%' || $5 || '%'
+		)
+		ORDER BY created_at DESC
+		LIMIT $6 OFFSET $7
+	`
+
+	rows, err := db.MainPool.Query(ctx, listQuery, tenantID, tagsDomain.EntitySupplier, filter.Names, filter.MinMatches(), query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suppliers by tags: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanSuppliers(rows)
+}
+
 // Count returns total number of suppliers for a tenant
 func (r *PostgresSupplierRepository) Count(ctx context.Context, tenantID uuid.UUID) (int64, error) {
 	var count int64
@@ -190,6 +338,18 @@ func (r *PostgresSupplierRepository) Count(ctx context.Context, tenantID uuid.UU
 	return count, nil
 }
 
+// CountByIDs counts the tenant's suppliers among ids
+func (r *PostgresSupplierRepository) CountByIDs(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (int, error) {
+	var count int
+
+	query := `SELECT COUNT(*) FROM suppliers WHERE tenant_id = $1 AND id = ANY($2)`
+	if err := db.MainPool.QueryRow(ctx, query, tenantID, ids).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count suppliers: %w", err)
+	}
+
+	return count, nil
+}
+
 // GetNextSupplierNumber gets the next supplier number for code generation
 func (r *PostgresSupplierRepository) GetNextSupplierNumber(ctx context.Context, tenantID uuid.UUID) (int, error) {
 	var count int
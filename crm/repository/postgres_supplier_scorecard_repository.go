@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// PostgresSupplierScorecardRepository implements SupplierScorecardRepository using PostgreSQL
+type PostgresSupplierScorecardRepository struct{}
+
+// NewPostgresSupplierScorecardRepository creates a new PostgreSQL supplier scorecard repository
+func NewPostgresSupplierScorecardRepository() *PostgresSupplierScorecardRepository {
+	return &PostgresSupplierScorecardRepository{}
+}
+
+// SupplyStats aggregates the sources' order lines per supplier in one query
+func (r *PostgresSupplierScorecardRepository) SupplyStats(ctx context.Context, sources []domain.SupplyLineSource, tenantID uuid.UUID, from, to, asOf time.Time, supplierID, categoryID *uuid.UUID) ([]domain.SupplyStats, error) {
+	if len(sources) == 0 {
+		return []domain.SupplyStats{}, nil
+	}
+
+	selects := make([]string, len(sources))
+	for i, source := range sources {
+		selects[i] = fmt.Sprintf(`
+			SELECT supplier_id, product_id, ordered_on::date AS ordered_on, expected_on::date AS expected_on,
+			       received_on::date AS received_on, ordered_qty, COALESCE(received_qty, 0) AS received_qty,
+			       ordered_price, received_price
+			FROM (%s) s%d`, source.LinesSQL, i)
+	}
+
+	query := fmt.Sprintf(`
+		WITH ordered AS (%s),
+		lines AS (
+			SELECT o.*,
+			       (o.received_on IS NOT NULL OR o.expected_on < $4) AS due
+			FROM ordered o
+			LEFT JOIN products p ON p.id = o.product_id AND p.tenant_id = $1
+			WHERE ($5::uuid IS NULL OR o.supplier_id = $5)
+			  AND ($6::uuid IS NULL OR p.category_id = $6)
+		)
+		SELECT supplier_id,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE received_on IS NOT NULL),
+		       COUNT(*) FILTER (WHERE due AND expected_on IS NOT NULL),
+		       COUNT(*) FILTER (WHERE due AND received_qty >= ordered_qty AND received_on <= expected_on),
+		       COALESCE(AVG(received_on - ordered_on) FILTER (WHERE received_on IS NOT NULL), 0)::float8,
+		       COALESCE(AVG(received_on - expected_on) FILTER (WHERE received_on > expected_on), 0)::float8,
+		       COALESCE(SUM(ordered_qty) FILTER (WHERE due), 0)::float8,
+		       COALESCE(SUM(LEAST(received_qty, ordered_qty)) FILTER (WHERE due), 0)::float8,
+		       COALESCE(SUM(received_qty * ordered_price) FILTER (WHERE received_price IS NOT NULL), 0)::float8,
+		       COALESCE(SUM(received_qty * received_price) FILTER (WHERE received_price IS NOT NULL), 0)::float8
+		FROM lines
+		WHERE supplier_id IS NOT NULL
+		GROUP BY supplier_id
+	`, strings.Join(selects, " UNION ALL "))
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, from, to.AddDate(0, 0, 1), asOf, supplierID, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query supply stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []domain.SupplyStats{}
+	for rows.Next() {
+		var s domain.SupplyStats
+		if err := rows.Scan(
+			&s.SupplierID, &s.OrderLines, &s.ReceivedLines, &s.TimedLines, &s.OnTimeLines,
+			&s.AvgLeadDays, &s.AvgDelayDays, &s.OrderedQty, &s.FilledQty, &s.OrderedValue, &s.ReceivedValue,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan supply stats: %w", err)
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return stats, nil
+}
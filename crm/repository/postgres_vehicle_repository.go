@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresVehicleRepository implements VehicleRepository using PostgreSQL
+type PostgresVehicleRepository struct{}
+
+// NewPostgresVehicleRepository creates a new PostgreSQL vehicle repository
+func NewPostgresVehicleRepository() *PostgresVehicleRepository {
+	return &PostgresVehicleRepository{}
+}
+
+const vehicleColumns = `id, tenant_id, registration_number, name, kind, capacity_kg, odometer_km, is_active, created_at, updated_at`
+
+const tripColumns = `id, tenant_id, vehicle_id, route_id, trip_date, driver_id, status, start_odometer, end_odometer,
+	note, started_at, ended_at, created_at, updated_at`
+
+const vehicleExpenseColumns = `id, tenant_id, vehicle_id, trip_id, kind, amount, litres, odometer_km, expense_date,
+	vendor, bill_number, note, journal_entry_id, created_by, created_at`
+
+// CreateVehicle creates a vehicle
+func (r *PostgresVehicleRepository) CreateVehicle(ctx context.Context, vehicle *domain.Vehicle) error {
+	query := `INSERT INTO vehicles (` + vehicleColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		vehicle.ID, vehicle.TenantID, vehicle.RegistrationNumber, vehicle.Name, vehicle.Kind, vehicle.CapacityKg,
+		vehicle.OdometerKm, vehicle.IsActive, vehicle.CreatedAt, vehicle.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create vehicle: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateVehicle updates a vehicle
+func (r *PostgresVehicleRepository) UpdateVehicle(ctx context.Context, vehicle *domain.Vehicle) error {
+	query := `
+		UPDATE vehicles
+		SET registration_number = $1, name = $2, kind = $3, capacity_kg = $4, odometer_km = $5, is_active = $6, updated_at = $7
+		WHERE tenant_id = $8 AND id = $9
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		vehicle.RegistrationNumber, vehicle.Name, vehicle.Kind, vehicle.CapacityKg, vehicle.OdometerKm,
+		vehicle.IsActive, vehicle.UpdatedAt, vehicle.TenantID, vehicle.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update vehicle: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrVehicleNotFound
+	}
+
+	return nil
+}
+
+// GetVehicle retrieves a vehicle
+func (r *PostgresVehicleRepository) GetVehicle(ctx context.Context, tenantID, id uuid.UUID) (*domain.Vehicle, error) {
+	query := `SELECT ` + vehicleColumns + ` FROM vehicles WHERE tenant_id = $1 AND id = $2`
+
+	vehicle, err := scanVehicle(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrVehicleNotFound
+		}
+		return nil, fmt.Errorf("failed to get vehicle: %w", err)
+	}
+
+	return vehicle, nil
+}
+
+// ListVehicles retrieves vehicles ordered by registration number
+func (r *PostgresVehicleRepository) ListVehicles(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*domain.Vehicle, error) {
+	query := `
+		SELECT ` + vehicleColumns + `
+		FROM vehicles
+		WHERE tenant_id = $1 AND (NOT $2 OR is_active)
+		ORDER BY registration_number
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vehicles: %w", err)
+	}
+	defer rows.Close()
+
+	vehicles := []*domain.Vehicle{}
+	for rows.Next() {
+		vehicle, err := scanVehicle(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan vehicle: %w", err)
+		}
+		vehicles = append(vehicles, vehicle)
+	}
+
+	return vehicles, rows.Err()
+}
+
+// CreateTrip starts a trip
+func (r *PostgresVehicleRepository) CreateTrip(ctx context.Context, trip *domain.Trip) error {
+	query := `INSERT INTO vehicle_trips (` + tripColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		trip.ID, trip.TenantID, trip.VehicleID, trip.RouteID, trip.TripDate, trip.DriverID, trip.Status,
+		trip.StartOdometer, trip.EndOdometer, trip.Note, trip.StartedAt, trip.EndedAt, trip.CreatedAt, trip.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create trip: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrip retrieves a trip
+func (r *PostgresVehicleRepository) GetTrip(ctx context.Context, tenantID, id uuid.UUID) (*domain.Trip, error) {
+	query := `SELECT ` + tripColumns + ` FROM vehicle_trips WHERE tenant_id = $1 AND id = $2`
+
+	trip, err := scanTrip(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTripNotFound
+		}
+		return nil, fmt.Errorf("failed to get trip: %w", err)
+	}
+
+	return trip, nil
+}
+
+// CloseTrip closes an open trip and moves the vehicle's odometer forward to its end reading
+func (r *PostgresVehicleRepository) CloseTrip(ctx context.Context, trip *domain.Trip) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := `
+			UPDATE vehicle_trips
+			SET status = $1, end_odometer = $2, note = $3, ended_at = $4, updated_at = $5
+			WHERE tenant_id = $6 AND id = $7 AND status = 'open'
+		`
+		tag, err := tx.Exec(ctx, query,
+			trip.Status, trip.EndOdometer, trip.Note, trip.EndedAt, trip.UpdatedAt, trip.TenantID, trip.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to close trip: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrTripState
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE vehicles SET odometer_km = GREATEST(odometer_km, $1), updated_at = $2
+			WHERE tenant_id = $3 AND id = $4
+		`, trip.EndOdometer, trip.UpdatedAt, trip.TenantID, trip.VehicleID)
+		if err != nil {
+			return fmt.Errorf("failed to update vehicle odometer: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ListTrips retrieves trips in a date range, oldest first
+func (r *PostgresVehicleRepository) ListTrips(ctx context.Context, tenantID uuid.UUID, vehicleID, routeID *uuid.UUID, from, to time.Time) ([]*domain.Trip, error) {
+	query := `
+		SELECT ` + tripColumns + `
+		FROM vehicle_trips
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR vehicle_id = $2)
+		  AND ($3::uuid IS NULL OR route_id = $3)
+		  AND trip_date BETWEEN $4 AND $5
+		ORDER BY trip_date, started_at
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, vehicleID, routeID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trips: %w", err)
+	}
+	defer rows.Close()
+
+	trips := []*domain.Trip{}
+	for rows.Next() {
+		trip, err := scanTrip(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trip: %w", err)
+		}
+		trips = append(trips, trip)
+	}
+
+	return trips, rows.Err()
+}
+
+// CreateExpense records a vehicle expense
+func (r *PostgresVehicleRepository) CreateExpense(ctx context.Context, expense *domain.VehicleExpense) error {
+	query := `INSERT INTO vehicle_expenses (` + vehicleExpenseColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		expense.ID, expense.TenantID, expense.VehicleID, expense.TripID, expense.Kind, expense.Amount, expense.Litres,
+		expense.OdometerKm, expense.ExpenseDate, expense.Vendor, expense.BillNumber, expense.Note,
+		expense.JournalEntryID, expense.CreatedBy, expense.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create vehicle expense: %w", err)
+	}
+
+	return nil
+}
+
+// SetExpenseJournal links an expense to the journal entry that booked it
+func (r *PostgresVehicleRepository) SetExpenseJournal(ctx context.Context, expenseID, journalEntryID uuid.UUID) error {
+	_, err := db.MainPool.Exec(ctx,
+		`UPDATE vehicle_expenses SET journal_entry_id = $1 WHERE id = $2`,
+		journalEntryID, expenseID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to link vehicle expense to journal: %w", err)
+	}
+
+	return nil
+}
+
+// ListExpenses retrieves expenses in a date range, oldest first
+func (r *PostgresVehicleRepository) ListExpenses(ctx context.Context, tenantID uuid.UUID, vehicleID *uuid.UUID, from, to time.Time) ([]*domain.VehicleExpense, error) {
+	query := `
+		SELECT ` + vehicleExpenseColumns + `
+		FROM vehicle_expenses
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR vehicle_id = $2)
+		  AND expense_date BETWEEN $3 AND $4
+		ORDER BY expense_date, created_at
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, vehicleID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vehicle expenses: %w", err)
+	}
+	defer rows.Close()
+
+	expenses := []*domain.VehicleExpense{}
+	for rows.Next() {
+		expense, err := scanVehicleExpense(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan vehicle expense: %w", err)
+		}
+		expenses = append(expenses, expense)
+	}
+
+	return expenses, rows.Err()
+}
+
+// RouteDeliveryTotals sums delivered and failed deliveries per route over a date range
+func (r *PostgresVehicleRepository) RouteDeliveryTotals(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.RouteDeliveryTotal, error) {
+	query := `
+		SELECT route_id,
+		       COUNT(*) FILTER (WHERE status = 'delivered'),
+		       COUNT(*) FILTER (WHERE status = 'failed'),
+		       COALESCE(SUM(invoice_amount) FILTER (WHERE status = 'delivered'), 0),
+		       COALESCE(SUM(collected_amount), 0)
+		FROM deliveries
+		WHERE tenant_id = $1 AND delivery_date BETWEEN $2 AND $3 AND status <> 'pending'
+		GROUP BY route_id
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query route delivery totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []*domain.RouteDeliveryTotal{}
+	for rows.Next() {
+		var total domain.RouteDeliveryTotal
+		if err := rows.Scan(&total.RouteID, &total.Deliveries, &total.Failed, &total.Revenue, &total.Collected); err != nil {
+			return nil, fmt.Errorf("failed to scan route delivery total: %w", err)
+		}
+		totals = append(totals, &total)
+	}
+
+	return totals, rows.Err()
+}
+
+func scanVehicle(row pgx.Row) (*domain.Vehicle, error) {
+	var vehicle domain.Vehicle
+	err := row.Scan(
+		&vehicle.ID, &vehicle.TenantID, &vehicle.RegistrationNumber, &vehicle.Name, &vehicle.Kind, &vehicle.CapacityKg,
+		&vehicle.OdometerKm, &vehicle.IsActive, &vehicle.CreatedAt, &vehicle.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &vehicle, nil
+}
+
+func scanTrip(row pgx.Row) (*domain.Trip, error) {
+	var trip domain.Trip
+	err := row.Scan(
+		&trip.ID, &trip.TenantID, &trip.VehicleID, &trip.RouteID, &trip.TripDate, &trip.DriverID, &trip.Status,
+		&trip.StartOdometer, &trip.EndOdometer, &trip.Note, &trip.StartedAt, &trip.EndedAt, &trip.CreatedAt, &trip.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &trip, nil
+}
+
+func scanVehicleExpense(row pgx.Row) (*domain.VehicleExpense, error) {
+	var expense domain.VehicleExpense
+	err := row.Scan(
+		&expense.ID, &expense.TenantID, &expense.VehicleID, &expense.TripID, &expense.Kind, &expense.Amount, &expense.Litres,
+		&expense.OdometerKm, &expense.ExpenseDate, &expense.Vendor, &expense.BillNumber, &expense.Note,
+		&expense.JournalEntryID, &expense.CreatedBy, &expense.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresWarrantyRepository implements WarrantyRepository using PostgreSQL
+type PostgresWarrantyRepository struct{}
+
+// NewPostgresWarrantyRepository creates a new PostgreSQL warranty repository
+func NewPostgresWarrantyRepository() *PostgresWarrantyRepository {
+	return &PostgresWarrantyRepository{}
+}
+
+const warrantyColumns = `id, tenant_id, product_id, serial_number, customer_id, invoice_id, invoice_number,
+	sold_at, warranty_months, expires_at, status, replaces_id, claim_id, created_by, created_at, updated_at`
+
+const warrantyClaimColumns = `id, tenant_id, claim_number, warranty_id, product_id, serial_number, customer_id,
+	type, issue, status, service_center, sent_at,
+	outcome, replacement_product_id, replacement_serial, replacement_warranty_id, resolution_note, resolved_by, resolved_at, closed_at,
+	created_by, created_at, updated_at`
+
+// CreateWarranties creates the warranties of a sale
+func (r *PostgresWarrantyRepository) CreateWarranties(ctx context.Context, warranties []*domain.Warranty) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		for _, warranty := range warranties {
+			if err := insertWarranty(ctx, tx, warranty); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func insertWarranty(ctx context.Context, tx pgx.Tx, warranty *domain.Warranty) error {
+	query := `INSERT INTO warranties (` + warrantyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+	_, err := tx.Exec(ctx, query,
+		warranty.ID, warranty.TenantID, warranty.ProductID, warranty.SerialNumber, warranty.CustomerID,
+		warranty.InvoiceID, warranty.InvoiceNumber, warranty.SoldAt, warranty.WarrantyMonths, warranty.ExpiresAt,
+		warranty.Status, warranty.ReplacesID, warranty.ClaimID, warranty.CreatedBy, warranty.CreatedAt, warranty.UpdatedAt,
+	)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uq_warranties_active_serial" {
+		return fmt.Errorf("%w: %s", domain.ErrWarrantyExists, warranty.SerialNumber)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create warranty: %w", err)
+	}
+
+	return nil
+}
+
+// GetWarranty retrieves a warranty
+func (r *PostgresWarrantyRepository) GetWarranty(ctx context.Context, tenantID, id uuid.UUID) (*domain.Warranty, error) {
+	query := `SELECT ` + warrantyColumns + ` FROM warranties WHERE tenant_id = $1 AND id = $2`
+
+	warranty, err := scanWarranty(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWarrantyNotFound
+		}
+		return nil, fmt.Errorf("failed to get warranty: %w", err)
+	}
+
+	return warranty, nil
+}
+
+// FindBySerial retrieves the active warranty on a serial number, or the latest one
+func (r *PostgresWarrantyRepository) FindBySerial(ctx context.Context, tenantID uuid.UUID, serialNumber string) (*domain.Warranty, error) {
+	query := `
+		SELECT ` + warrantyColumns + `
+		FROM warranties
+		WHERE tenant_id = $1 AND serial_number = $2
+		ORDER BY (status = 'active') DESC, created_at DESC
+		LIMIT 1
+	`
+
+	warranty, err := scanWarranty(db.MainPool.QueryRow(ctx, query, tenantID, serialNumber))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWarrantyNotFound
+		}
+		return nil, fmt.Errorf("failed to find warranty: %w", err)
+	}
+
+	return warranty, nil
+}
+
+// ListWarranties retrieves warranties, newest first, optionally by customer and serial number
+func (r *PostgresWarrantyRepository) ListWarranties(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, serialNumber *string, limit, offset int) ([]*domain.Warranty, error) {
+	query := `
+		SELECT ` + warrantyColumns + `
+		FROM warranties
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR customer_id = $2)
+		  AND ($3::varchar IS NULL OR serial_number = $3)
+		ORDER BY created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, customerID, serialNumber, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query warranties: %w", err)
+	}
+	defer rows.Close()
+
+	warranties := []*domain.Warranty{}
+	for rows.Next() {
+		warranty, err := scanWarranty(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan warranty: %w", err)
+		}
+		warranties = append(warranties, warranty)
+	}
+
+	return warranties, rows.Err()
+}
+
+// VoidInvoice voids the active warranties of an invoice
+func (r *PostgresWarrantyRepository) VoidInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (int, error) {
+	tag, err := db.MainPool.Exec(ctx,
+		`UPDATE warranties SET status = 'void', updated_at = NOW() WHERE tenant_id = $1 AND invoice_id = $2 AND status = 'active'`,
+		tenantID, invoiceID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to void warranties: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// CreateClaim creates a warranty claim
+func (r *PostgresWarrantyRepository) CreateClaim(ctx context.Context, claim *domain.WarrantyClaim) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var next int64
+		err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) + 1 FROM warranty_claims WHERE tenant_id = $1`, claim.TenantID,
+		).Scan(&next)
+		if err != nil {
+			return fmt.Errorf("failed to number warranty claim: %w", err)
+		}
+		claim.ClaimNumber = fmt.Sprintf("WC-%05d", next)
+
+		query := `INSERT INTO warranty_claims (` + warrantyClaimColumns + `)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)`
+		_, err = tx.Exec(ctx, query,
+			claim.ID, claim.TenantID, claim.ClaimNumber, claim.WarrantyID, claim.ProductID, claim.SerialNumber, claim.CustomerID,
+			claim.Type, claim.Issue, claim.Status, claim.ServiceCenter, claim.SentAt,
+			claim.Outcome, claim.ReplacementProductID, claim.ReplacementSerial, claim.ReplacementWarrantyID,
+			claim.ResolutionNote, claim.ResolvedBy, claim.ResolvedAt, claim.ClosedAt,
+			claim.CreatedBy, claim.CreatedAt, claim.UpdatedAt,
+		)
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uq_warranty_claims_open" {
+			return domain.ErrWarrantyClaimOpen
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create warranty claim: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetClaim retrieves a warranty claim
+func (r *PostgresWarrantyRepository) GetClaim(ctx context.Context, tenantID, id uuid.UUID) (*domain.WarrantyClaim, error) {
+	query := `SELECT ` + warrantyClaimColumns + ` FROM warranty_claims WHERE tenant_id = $1 AND id = $2`
+
+	claim, err := scanWarrantyClaim(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWarrantyClaimNotFound
+		}
+		return nil, fmt.Errorf("failed to get warranty claim: %w", err)
+	}
+
+	return claim, nil
+}
+
+// OpenClaim retrieves the claim that is not yet closed on a warranty's unit
+func (r *PostgresWarrantyRepository) OpenClaim(ctx context.Context, tenantID, warrantyID uuid.UUID) (*domain.WarrantyClaim, error) {
+	query := `
+		SELECT ` + warrantyClaimColumns + `
+		FROM warranty_claims
+		WHERE tenant_id = $1 AND warranty_id = $2 AND status IN ('received', 'in_service', 'resolved')
+	`
+
+	claim, err := scanWarrantyClaim(db.MainPool.QueryRow(ctx, query, tenantID, warrantyID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get open warranty claim: %w", err)
+	}
+
+	return claim, nil
+}
+
+// ListClaims retrieves warranty claims, newest first, optionally by status and serial number
+func (r *PostgresWarrantyRepository) ListClaims(ctx context.Context, tenantID uuid.UUID, status *domain.WarrantyClaimStatus, serialNumber *string, limit, offset int) ([]*domain.WarrantyClaim, error) {
+	query := `
+		SELECT ` + warrantyClaimColumns + `
+		FROM warranty_claims
+		WHERE tenant_id = $1
+		  AND ($2::varchar IS NULL OR status = $2)
+		  AND ($3::varchar IS NULL OR serial_number = $3)
+		ORDER BY created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, status, serialNumber, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query warranty claims: %w", err)
+	}
+	defer rows.Close()
+
+	claims := []*domain.WarrantyClaim{}
+	for rows.Next() {
+		claim, err := scanWarrantyClaim(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan warranty claim: %w", err)
+		}
+		claims = append(claims, claim)
+	}
+
+	return claims, rows.Err()
+}
+
+// UpdateClaim saves a claim's status change
+func (r *PostgresWarrantyRepository) UpdateClaim(ctx context.Context, claim *domain.WarrantyClaim, previous domain.WarrantyClaimStatus) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return updateClaim(ctx, tx, claim, previous)
+	})
+}
+
+// ResolveClaim saves a claim's outcome and the replacement unit's warranty
+func (r *PostgresWarrantyRepository) ResolveClaim(ctx context.Context, claim *domain.WarrantyClaim, previous domain.WarrantyClaimStatus, replacement *domain.Warranty) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if claim.HandsOverUnit() {
+			tag, err := tx.Exec(ctx,
+				`UPDATE warranties SET status = 'replaced', updated_at = $1 WHERE tenant_id = $2 AND id = $3 AND status = 'active'`,
+				claim.UpdatedAt, claim.TenantID, claim.WarrantyID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to mark warranty replaced: %w", err)
+			}
+			if tag.RowsAffected() == 0 {
+				return domain.ErrWarrantyNotCovered
+			}
+		}
+		if replacement != nil {
+			if err := insertWarranty(ctx, tx, replacement); err != nil {
+				return err
+			}
+			claim.ReplacementWarrantyID = &replacement.ID
+		}
+
+		return updateClaim(ctx, tx, claim, previous)
+	})
+}
+
+func updateClaim(ctx context.Context, tx pgx.Tx, claim *domain.WarrantyClaim, previous domain.WarrantyClaimStatus) error {
+	query := `
+		UPDATE warranty_claims
+		SET status = $1, service_center = $2, sent_at = $3,
+		    outcome = $4, replacement_product_id = $5, replacement_serial = $6, replacement_warranty_id = $7,
+		    resolution_note = $8, resolved_by = $9, resolved_at = $10, closed_at = $11, updated_at = $12
+		WHERE tenant_id = $13 AND id = $14 AND status = $15
+	`
+
+	tag, err := tx.Exec(ctx, query,
+		claim.Status, claim.ServiceCenter, claim.SentAt,
+		claim.Outcome, claim.ReplacementProductID, claim.ReplacementSerial, claim.ReplacementWarrantyID,
+		claim.ResolutionNote, claim.ResolvedBy, claim.ResolvedAt, claim.ClosedAt, claim.UpdatedAt,
+		claim.TenantID, claim.ID, previous,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update warranty claim: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWarrantyClaimState
+	}
+
+	return nil
+}
+
+func scanWarranty(row pgx.Row) (*domain.Warranty, error) {
+	var warranty domain.Warranty
+	err := row.Scan(
+		&warranty.ID, &warranty.TenantID, &warranty.ProductID, &warranty.SerialNumber, &warranty.CustomerID,
+		&warranty.InvoiceID, &warranty.InvoiceNumber, &warranty.SoldAt, &warranty.WarrantyMonths, &warranty.ExpiresAt,
+		&warranty.Status, &warranty.ReplacesID, &warranty.ClaimID, &warranty.CreatedBy, &warranty.CreatedAt, &warranty.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &warranty, nil
+}
+
+func scanWarrantyClaim(row pgx.Row) (*domain.WarrantyClaim, error) {
+	var claim domain.WarrantyClaim
+	err := row.Scan(
+		&claim.ID, &claim.TenantID, &claim.ClaimNumber, &claim.WarrantyID, &claim.ProductID, &claim.SerialNumber, &claim.CustomerID,
+		&claim.Type, &claim.Issue, &claim.Status, &claim.ServiceCenter, &claim.SentAt,
+		&claim.Outcome, &claim.ReplacementProductID, &claim.ReplacementSerial, &claim.ReplacementWarrantyID,
+		&claim.ResolutionNote, &claim.ResolvedBy, &claim.ResolvedAt, &claim.ClosedAt,
+		&claim.CreatedBy, &claim.CreatedAt, &claim.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
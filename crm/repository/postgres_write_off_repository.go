@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresWriteOffRepository implements WriteOffRepository using PostgreSQL
+type PostgresWriteOffRepository struct{}
+
+// NewPostgresWriteOffRepository creates a new PostgreSQL write-off repository
+func NewPostgresWriteOffRepository() *PostgresWriteOffRepository {
+	return &PostgresWriteOffRepository{}
+}
+
+const writeOffColumns = `id, tenant_id, customer_id, write_off_number, amount, outstanding, reason, status,
+	requested_by, reviewed_by, reviewer_role, review_note, reviewed_at,
+	fiscal_year_id, fiscal_year_name, khata_entry_id, journal_entry_id, created_at, updated_at`
+
+// Create creates a write-off
+func (r *PostgresWriteOffRepository) Create(ctx context.Context, writeOff *domain.WriteOff) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var next int64
+		err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) + 1 FROM write_offs WHERE tenant_id = $1`, writeOff.TenantID,
+		).Scan(&next)
+		if err != nil {
+			return fmt.Errorf("failed to number write-off: %w", err)
+		}
+		writeOff.WriteOffNumber = fmt.Sprintf("WO-%05d", next)
+
+		query := `INSERT INTO write_offs (` + writeOffColumns + `)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`
+		_, err = tx.Exec(ctx, query,
+			writeOff.ID, writeOff.TenantID, writeOff.CustomerID, writeOff.WriteOffNumber, writeOff.Amount,
+			writeOff.Outstanding, writeOff.Reason, writeOff.Status,
+			writeOff.RequestedBy, writeOff.ReviewedBy, writeOff.ReviewerRole, writeOff.ReviewNote, writeOff.ReviewedAt,
+			writeOff.FiscalYearID, writeOff.FiscalYearName, writeOff.KhataEntryID, writeOff.JournalEntryID,
+			writeOff.CreatedAt, writeOff.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create write-off: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetByID retrieves a write-off
+func (r *PostgresWriteOffRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.WriteOff, error) {
+	query := `SELECT ` + writeOffColumns + ` FROM write_offs WHERE tenant_id = $1 AND id = $2`
+
+	writeOff, err := scanWriteOff(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWriteOffNotFound
+		}
+		return nil, fmt.Errorf("failed to get write-off: %w", err)
+	}
+
+	return writeOff, nil
+}
+
+// List retrieves write-offs, newest first, optionally by status and customer
+func (r *PostgresWriteOffRepository) List(ctx context.Context, tenantID uuid.UUID, status *domain.WriteOffStatus, customerID *uuid.UUID, limit, offset int) ([]*domain.WriteOff, error) {
+	query := `
+		SELECT ` + writeOffColumns + `
+		FROM write_offs
+		WHERE tenant_id = $1
+		  AND ($2::varchar IS NULL OR status = $2)
+		  AND ($3::uuid IS NULL OR customer_id = $3)
+		ORDER BY created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, status, customerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query write-offs: %w", err)
+	}
+	defer rows.Close()
+
+	writeOffs := []*domain.WriteOff{}
+	for rows.Next() {
+		writeOff, err := scanWriteOff(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan write-off: %w", err)
+		}
+		writeOffs = append(writeOffs, writeOff)
+	}
+
+	return writeOffs, rows.Err()
+}
+
+// Approve saves the approval and the closing khata entry
+func (r *PostgresWriteOffRepository) Approve(ctx context.Context, writeOff *domain.WriteOff, entry *domain.KhataEntry) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO khata_entries (`+khataEntryColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			entry.ID, entry.TenantID, entry.CustomerID, entry.Kind, entry.Amount, entry.EntryDate, entry.DueDate,
+			entry.ReferenceType, entry.ReferenceID, entry.Note, entry.CreatedBy, entry.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create write-off khata entry: %w", err)
+		}
+
+		query := `
+			UPDATE write_offs
+			SET status = $1, reviewed_by = $2, reviewer_role = $3, review_note = $4, reviewed_at = $5,
+			    fiscal_year_id = $6, fiscal_year_name = $7, khata_entry_id = $8, updated_at = $9
+			WHERE tenant_id = $10 AND id = $11 AND status = 'pending'
+		`
+		tag, err := tx.Exec(ctx, query,
+			writeOff.Status, writeOff.ReviewedBy, writeOff.ReviewerRole, writeOff.ReviewNote, writeOff.ReviewedAt,
+			writeOff.FiscalYearID, writeOff.FiscalYearName, entry.ID, writeOff.UpdatedAt,
+			writeOff.TenantID, writeOff.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to approve write-off: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrWriteOffState
+		}
+
+		writeOff.KhataEntryID = &entry.ID
+		return nil
+	})
+}
+
+// UpdateStatus saves a rejection or cancellation
+func (r *PostgresWriteOffRepository) UpdateStatus(ctx context.Context, writeOff *domain.WriteOff) error {
+	query := `
+		UPDATE write_offs
+		SET status = $1, reviewed_by = $2, reviewer_role = $3, review_note = $4, reviewed_at = $5, updated_at = $6
+		WHERE tenant_id = $7 AND id = $8 AND status = 'pending'
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		writeOff.Status, writeOff.ReviewedBy, writeOff.ReviewerRole, writeOff.ReviewNote, writeOff.ReviewedAt,
+		writeOff.UpdatedAt, writeOff.TenantID, writeOff.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update write-off: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWriteOffState
+	}
+
+	return nil
+}
+
+// SetJournalEntry links a write-off to its bad-debt journal entry
+func (r *PostgresWriteOffRepository) SetJournalEntry(ctx context.Context, id, journalEntryID uuid.UUID) error {
+	_, err := db.MainPool.Exec(ctx, `UPDATE write_offs SET journal_entry_id = $1 WHERE id = $2`, journalEntryID, id)
+	if err != nil {
+		return fmt.Errorf("failed to link write-off journal entry: %w", err)
+	}
+
+	return nil
+}
+
+// YearTotals sums approved write-offs per fiscal year
+func (r *PostgresWriteOffRepository) YearTotals(ctx context.Context, tenantID uuid.UUID) ([]*domain.WriteOffYearTotal, error) {
+	query := `
+		SELECT fiscal_year_id, MAX(fiscal_year_name), COUNT(*), COALESCE(SUM(amount), 0), COUNT(DISTINCT customer_id)
+		FROM write_offs
+		WHERE tenant_id = $1 AND status = 'approved' AND fiscal_year_id IS NOT NULL
+		GROUP BY fiscal_year_id
+		ORDER BY MAX(reviewed_at) DESC
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query write-off totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []*domain.WriteOffYearTotal{}
+	for rows.Next() {
+		var total domain.WriteOffYearTotal
+		if err := rows.Scan(&total.FiscalYearID, &total.FiscalYearName, &total.Count, &total.Amount, &total.Customers); err != nil {
+			return nil, fmt.Errorf("failed to scan write-off total: %w", err)
+		}
+		totals = append(totals, &total)
+	}
+
+	return totals, rows.Err()
+}
+
+func scanWriteOff(row pgx.Row) (*domain.WriteOff, error) {
+	var writeOff domain.WriteOff
+	err := row.Scan(
+		&writeOff.ID, &writeOff.TenantID, &writeOff.CustomerID, &writeOff.WriteOffNumber, &writeOff.Amount,
+		&writeOff.Outstanding, &writeOff.Reason, &writeOff.Status,
+		&writeOff.RequestedBy, &writeOff.ReviewedBy, &writeOff.ReviewerRole, &writeOff.ReviewNote, &writeOff.ReviewedAt,
+		&writeOff.FiscalYearID, &writeOff.FiscalYearName, &writeOff.KhataEntryID, &writeOff.JournalEntryID,
+		&writeOff.CreatedAt, &writeOff.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &writeOff, nil
+}
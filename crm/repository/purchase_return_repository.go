@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// ReturnCheck validates a return against what earlier returns already sent back on
+// the same receipt, returning whether the return raises a debit note
+type ReturnCheck func(returnedByLine map[uuid.UUID]float64, returnedAmount float64) (debitNote bool, err error)
+
+// PurchaseReturnRepository defines the interface for purchase return data access
+type PurchaseReturnRepository interface {
+	// Create saves a return with its lines, numbering it and its debit note. The
+	// receipt is locked while check runs so concurrent returns cannot both fit.
+	Create(ctx context.Context, ret *domain.PurchaseReturn, check ReturnCheck) error
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.PurchaseReturn, error)
+	// List retrieves returns (without lines), newest first
+	List(ctx context.Context, tenantID uuid.UUID, filter domain.PurchaseReturnFilter) ([]*domain.PurchaseReturn, error)
+	SetJournalEntry(ctx context.Context, returnID, journalEntryID uuid.UUID) error
+	SetStockPosted(ctx context.Context, returnID uuid.UUID, postedAt time.Time) error
+
+	// ReasonStats sums returned lines per supplier and reason for returns dated from through the day of to
+	ReasonStats(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.ReturnReasonStat, error)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// QuickPickRepository defines the interface for per-user favorite and recent customers
+type QuickPickRepository interface {
+	// RecordView bumps the view count and timestamp for a customer
+	RecordView(ctx context.Context, tenantID, userID, customerID uuid.UUID) error
+
+	// SetFavorite pins or unpins a customer
+	SetFavorite(ctx context.Context, tenantID, userID, customerID uuid.UUID, favorite bool, position int) error
+
+	// List returns favorites first, then recently viewed customers
+	List(ctx context.Context, tenantID, userID uuid.UUID, limit int) ([]*domain.CustomerQuickPick, error)
+
+	// TrimRecent keeps only the most recent non-favorite views for a user
+	TrimRecent(ctx context.Context, tenantID, userID uuid.UUID, keep int) error
+}
@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 
+	"github.com/aceextension/core/db"
 	"github.com/aceextension/crm/domain"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 )
 
@@ -12,20 +14,20 @@ type SupplierRepository interface {
 	// Create creates a new supplier
 	Create(ctx context.Context, supplier *domain.Supplier) error
 
-	// GetByID retrieves a supplier by ID
-	GetByID(ctx context.Context, id uuid.UUID) (*domain.Supplier, error)
+	// GetByID retrieves a tenant's supplier by ID; another tenant's supplier is ErrSupplierNotFound
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Supplier, error)
 
 	// GetByCode retrieves a supplier by supplier code
 	GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.Supplier, error)
 
-	// GetByTenantID retrieves all suppliers for a tenant
-	GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Supplier, error)
+	// List retrieves a page of suppliers, newest first, with the total; a tags filter keeps suppliers carrying them
+	List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) ([]*domain.Supplier, int64, error)
 
-	// Update updates a supplier
+	// Update updates a supplier of supplier.TenantID
 	Update(ctx context.Context, supplier *domain.Supplier) error
 
-	// Delete deletes a supplier
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete deletes a tenant's supplier
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
 
 	// Search searches suppliers by name, email, or phone
 	Search(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) ([]*domain.Supplier, error)
@@ -33,9 +35,20 @@ type SupplierRepository interface {
 	// SearchByCustomAttribute searches suppliers by custom attribute
 	SearchByCustomAttribute(ctx context.Context, tenantID uuid.UUID, key, value string) ([]*domain.Supplier, error)
 
+	// ListByArea retrieves suppliers with a structured address in the given district (and municipality/ward),
+	// ordered for walking a delivery route
+	ListByArea(ctx context.Context, tenantID uuid.UUID, filter domain.AreaFilter, page db.PageRequest) ([]*domain.Supplier, int64, error)
+
+	// ListByTags retrieves suppliers carrying the filter's tags, newest first; a non-empty query
+	// also matches name, email, phone or code
+	ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*domain.Supplier, error)
+
 	// Count returns total number of suppliers for a tenant
 	Count(ctx context.Context, tenantID uuid.UUID) (int64, error)
 
+	// CountByIDs returns how many of the given suppliers belong to the tenant
+	CountByIDs(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (int, error)
+
 	// GetNextSupplierNumber gets the next supplier number for code generation
 	GetNextSupplierNumber(ctx context.Context, tenantID uuid.UUID) (int, error)
 }
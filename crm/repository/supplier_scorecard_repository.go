@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// SupplierScorecardRepository defines the interface for supplier delivery statistics
+type SupplierScorecardRepository interface {
+	// SupplyStats reads lines ordered from through the day of to from the sources,
+	// per supplier, optionally for one supplier or one product category. Lines
+	// are due when received or expected before asOf.
+	SupplyStats(ctx context.Context, sources []domain.SupplyLineSource, tenantID uuid.UUID, from, to, asOf time.Time, supplierID, categoryID *uuid.UUID) ([]domain.SupplyStats, error)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// VehicleRepository defines the interface for delivery vehicles, trips and their costs
+type VehicleRepository interface {
+	CreateVehicle(ctx context.Context, vehicle *domain.Vehicle) error
+	UpdateVehicle(ctx context.Context, vehicle *domain.Vehicle) error
+	GetVehicle(ctx context.Context, tenantID, id uuid.UUID) (*domain.Vehicle, error)
+	ListVehicles(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*domain.Vehicle, error)
+
+	CreateTrip(ctx context.Context, trip *domain.Trip) error
+	GetTrip(ctx context.Context, tenantID, id uuid.UUID) (*domain.Trip, error)
+	// CloseTrip saves a closed trip and advances the vehicle's odometer in one transaction.
+	// It fails with ErrTripState if the trip was closed concurrently.
+	CloseTrip(ctx context.Context, trip *domain.Trip) error
+	// ListTrips retrieves trips dated from..to inclusive, optionally for one vehicle or route
+	ListTrips(ctx context.Context, tenantID uuid.UUID, vehicleID, routeID *uuid.UUID, from, to time.Time) ([]*domain.Trip, error)
+
+	CreateExpense(ctx context.Context, expense *domain.VehicleExpense) error
+	SetExpenseJournal(ctx context.Context, expenseID, journalEntryID uuid.UUID) error
+	// ListExpenses retrieves expenses dated from..to inclusive, optionally for one vehicle
+	ListExpenses(ctx context.Context, tenantID uuid.UUID, vehicleID *uuid.UUID, from, to time.Time) ([]*domain.VehicleExpense, error)
+	// RouteDeliveryTotals sums each route's completed deliveries dated from..to inclusive
+	RouteDeliveryTotals(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.RouteDeliveryTotal, error)
+}
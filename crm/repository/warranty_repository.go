@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// WarrantyRepository defines the interface for warranty and warranty claim data access
+type WarrantyRepository interface {
+	// CreateWarranties saves the warranties of a sale in one transaction. It fails with
+	// ErrWarrantyExists if a serial number already has an active warranty.
+	CreateWarranties(ctx context.Context, warranties []*domain.Warranty) error
+	GetWarranty(ctx context.Context, tenantID, id uuid.UUID) (*domain.Warranty, error)
+	// FindBySerial returns the active warranty on a serial number, or else its latest one
+	FindBySerial(ctx context.Context, tenantID uuid.UUID, serialNumber string) (*domain.Warranty, error)
+	ListWarranties(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, serialNumber *string, limit, offset int) ([]*domain.Warranty, error)
+	// VoidInvoice voids the active warranties registered for an invoice and returns how many
+	VoidInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (int, error)
+
+	// CreateClaim saves a received claim, assigning the next claim number. It fails with
+	// ErrWarrantyClaimOpen if the unit already has a claim that is not closed.
+	CreateClaim(ctx context.Context, claim *domain.WarrantyClaim) error
+	GetClaim(ctx context.Context, tenantID, id uuid.UUID) (*domain.WarrantyClaim, error)
+	// OpenClaim returns the claim holding a warranty's unit, or nil
+	OpenClaim(ctx context.Context, tenantID, warrantyID uuid.UUID) (*domain.WarrantyClaim, error)
+	ListClaims(ctx context.Context, tenantID uuid.UUID, status *domain.WarrantyClaimStatus, serialNumber *string, limit, offset int) ([]*domain.WarrantyClaim, error)
+	// UpdateClaim saves a status change, failing with ErrWarrantyClaimState if the claim
+	// is no longer in the previous status
+	UpdateClaim(ctx context.Context, claim *domain.WarrantyClaim, previous domain.WarrantyClaimStatus) error
+	// ResolveClaim saves the outcome and, when a unit was handed over, marks the original
+	// warranty replaced and creates the replacement's (if any), in one transaction
+	ResolveClaim(ctx context.Context, claim *domain.WarrantyClaim, previous domain.WarrantyClaimStatus, replacement *domain.Warranty) error
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/crm/domain"
+	"github.com/google/uuid"
+)
+
+// WriteOffRepository defines the interface for bad-debt write-off data access
+type WriteOffRepository interface {
+	// Create saves a pending write-off, assigning the next write-off number
+	Create(ctx context.Context, writeOff *domain.WriteOff) error
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.WriteOff, error)
+	List(ctx context.Context, tenantID uuid.UUID, status *domain.WriteOffStatus, customerID *uuid.UUID, limit, offset int) ([]*domain.WriteOff, error)
+
+	// Approve saves the approval and the khata entry closing the dues in one transaction.
+	// It fails with ErrWriteOffState if the write-off was decided concurrently.
+	Approve(ctx context.Context, writeOff *domain.WriteOff, entry *domain.KhataEntry) error
+	// UpdateStatus saves a rejection or cancellation of a pending write-off
+	UpdateStatus(ctx context.Context, writeOff *domain.WriteOff) error
+	SetJournalEntry(ctx context.Context, id, journalEntryID uuid.UUID) error
+
+	// YearTotals sums approved write-offs per fiscal year, latest first
+	YearTotals(ctx context.Context, tenantID uuid.UUID) ([]*domain.WriteOffYearTotal, error)
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/logger"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/google/uuid"
+)
+
+// ApprovalService defines the interface for per-user and per-role document approval limits
+type ApprovalService interface {
+	// SetLimit saves a role or user limit, replacing the existing one for the same
+	// document type. Only ApprovalLimitManagerRoles may change limits.
+	SetLimit(ctx context.Context, limit *crmDomain.ApprovalLimit, role string) error
+	ListLimits(ctx context.Context, tenantID uuid.UUID, documentType *crmDomain.ApprovalDocumentType) ([]*crmDomain.ApprovalLimit, error)
+	DeleteLimit(ctx context.Context, tenantID, id, userID uuid.UUID, role string) error
+	// EffectiveLimit returns the limit that applies to a user, nil when they are not restricted
+	EffectiveLimit(ctx context.Context, tenantID uuid.UUID, documentType crmDomain.ApprovalDocumentType, userID uuid.UUID, role string) (*crmDomain.ApprovalLimit, error)
+
+	// Route returns nil when the document is within the requester's limit and can go
+	// ahead; otherwise it returns the pending request the document must wait on
+	Route(ctx context.Context, doc crmDomain.ApprovalDocument, requestedBy uuid.UUID, role string) (*crmDomain.ApprovalRequest, error)
+
+	GetRequest(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.ApprovalRequest, error)
+	ListRequests(ctx context.Context, tenantID uuid.UUID, status *crmDomain.ApprovalStatus, documentType *crmDomain.ApprovalDocumentType, limit, offset int) ([]*crmDomain.ApprovalRequest, error)
+	// Approve completes the held document. Only ApprovalApproverRoles whose own limit
+	// covers the amount may approve, and never the requester.
+	Approve(ctx context.Context, tenantID, id, reviewerID uuid.UUID, role string, note *string) (*crmDomain.ApprovalRequest, error)
+	// Reject returns the document to its author
+	Reject(ctx context.Context, tenantID, id, reviewerID uuid.UUID, role string, note *string) (*crmDomain.ApprovalRequest, error)
+	// Cancel withdraws a pending request; allowed for the requester and approvers
+	Cancel(ctx context.Context, tenantID, id, userID uuid.UUID, role string) (*crmDomain.ApprovalRequest, error)
+
+	// RegisterDocumentType sets the module that completes or releases held documents of a type
+	RegisterDocumentType(documentType crmDomain.ApprovalDocumentType, decider ApprovalDecider)
+}
+
+// ApprovalDecider is implemented by the module owning a document type. It is called
+// once a request is approved, rejected or cancelled, before the decision is saved;
+// an error leaves the request pending.
+type ApprovalDecider interface {
+	ApprovalDecided(ctx context.Context, request *crmDomain.ApprovalRequest) error
+}
+
+// approvalService implements ApprovalService
+type approvalService struct {
+	repo     repository.ApprovalRepository
+	mu       sync.RWMutex
+	deciders map[crmDomain.ApprovalDocumentType]ApprovalDecider
+}
+
+// NewApprovalService creates a new approval service
+func NewApprovalService(repo repository.ApprovalRepository) ApprovalService {
+	return &approvalService{
+		repo:     repo,
+		deciders: make(map[crmDomain.ApprovalDocumentType]ApprovalDecider),
+	}
+}
+
+// RegisterDocumentType sets the decider for a document type
+func (s *approvalService) RegisterDocumentType(documentType crmDomain.ApprovalDocumentType, decider ApprovalDecider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deciders[documentType] = decider
+}
+
+func (s *approvalService) decider(documentType crmDomain.ApprovalDocumentType) (ApprovalDecider, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	decider, ok := s.deciders[documentType]
+	if !ok {
+		return nil, crmDomain.ErrUnknownApprovalDocument
+	}
+	return decider, nil
+}
+
+// SetLimit saves a limit
+func (s *approvalService) SetLimit(ctx context.Context, limit *crmDomain.ApprovalLimit, role string) error {
+	if !crmDomain.CanManageApprovalLimits(role) {
+		return crmDomain.ErrApprovalLimitNotPermitted
+	}
+	if err := limit.Validate(); err != nil {
+		return err
+	}
+	if err := s.repo.UpsertLimit(ctx, limit); err != nil {
+		return err
+	}
+
+	s.auditLimit(ctx, "SET_APPROVAL_LIMIT", limit, limit.UpdatedBy)
+	return nil
+}
+
+// ListLimits retrieves the tenant's limits
+func (s *approvalService) ListLimits(ctx context.Context, tenantID uuid.UUID, documentType *crmDomain.ApprovalDocumentType) ([]*crmDomain.ApprovalLimit, error) {
+	return s.repo.ListLimits(ctx, tenantID, documentType)
+}
+
+// DeleteLimit removes a limit
+func (s *approvalService) DeleteLimit(ctx context.Context, tenantID, id, userID uuid.UUID, role string) error {
+	if !crmDomain.CanManageApprovalLimits(role) {
+		return crmDomain.ErrApprovalLimitNotPermitted
+	}
+	limit, err := s.repo.GetLimit(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteLimit(ctx, tenantID, id); err != nil {
+		return err
+	}
+
+	s.auditLimit(ctx, "DELETE_APPROVAL_LIMIT", limit, &userID)
+	return nil
+}
+
+// EffectiveLimit returns the limit that applies to a user
+func (s *approvalService) EffectiveLimit(ctx context.Context, tenantID uuid.UUID, documentType crmDomain.ApprovalDocumentType, userID uuid.UUID, role string) (*crmDomain.ApprovalLimit, error) {
+	return s.repo.EffectiveLimit(ctx, tenantID, documentType, userID, role)
+}
+
+// Route checks a document against its requester's limit and raises a request when it is over
+func (s *approvalService) Route(ctx context.Context, doc crmDomain.ApprovalDocument, requestedBy uuid.UUID, role string) (*crmDomain.ApprovalRequest, error) {
+	if _, err := s.decider(doc.Type); err != nil {
+		return nil, err
+	}
+
+	limit, err := s.repo.EffectiveLimit(ctx, doc.TenantID, doc.Type, requestedBy, role)
+	if err != nil {
+		return nil, err
+	}
+	if limit == nil || limit.Allows(doc.Amount) {
+		return nil, nil
+	}
+
+	if pending, err := s.repo.GetPendingRequest(ctx, doc.TenantID, doc.Type, doc.ID); err == nil {
+		return pending, nil
+	}
+
+	request := crmDomain.NewApprovalRequest(doc, limit, requestedBy, role)
+	if err := s.repo.CreateRequest(ctx, request); err != nil {
+		return nil, err
+	}
+
+	s.auditRequest(ctx, "REQUEST_APPROVAL", request, &requestedBy)
+	return request, nil
+}
+
+// GetRequest retrieves an approval request
+func (s *approvalService) GetRequest(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.ApprovalRequest, error) {
+	return s.repo.GetRequest(ctx, tenantID, id)
+}
+
+// ListRequests retrieves approval requests
+func (s *approvalService) ListRequests(ctx context.Context, tenantID uuid.UUID, status *crmDomain.ApprovalStatus, documentType *crmDomain.ApprovalDocumentType, limit, offset int) ([]*crmDomain.ApprovalRequest, error) {
+	return s.repo.ListRequests(ctx, tenantID, status, documentType, limit, offset)
+}
+
+// Approve approves a request within the reviewer's own limit
+func (s *approvalService) Approve(ctx context.Context, tenantID, id, reviewerID uuid.UUID, role string, note *string) (*crmDomain.ApprovalRequest, error) {
+	request, err := s.repo.GetRequest(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	reviewerLimit, err := s.repo.EffectiveLimit(ctx, tenantID, request.DocumentType, reviewerID, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := request.Approve(reviewerID, role, reviewerLimit, note); err != nil {
+		return nil, err
+	}
+	if err := s.decide(ctx, request); err != nil {
+		return nil, err
+	}
+
+	s.auditRequest(ctx, "APPROVE_DOCUMENT", request, &reviewerID)
+	return request, nil
+}
+
+// Reject declines a request
+func (s *approvalService) Reject(ctx context.Context, tenantID, id, reviewerID uuid.UUID, role string, note *string) (*crmDomain.ApprovalRequest, error) {
+	request, err := s.repo.GetRequest(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := request.Reject(reviewerID, role, note); err != nil {
+		return nil, err
+	}
+	if err := s.decide(ctx, request); err != nil {
+		return nil, err
+	}
+
+	s.auditRequest(ctx, "REJECT_DOCUMENT", request, &reviewerID)
+	return request, nil
+}
+
+// Cancel withdraws a pending request
+func (s *approvalService) Cancel(ctx context.Context, tenantID, id, userID uuid.UUID, role string) (*crmDomain.ApprovalRequest, error) {
+	request, err := s.repo.GetRequest(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequestedBy != userID && !crmDomain.CanApproveDocuments(role) {
+		return nil, crmDomain.ErrApprovalNotPermitted
+	}
+	if err := request.Cancel(); err != nil {
+		return nil, err
+	}
+	if err := s.decide(ctx, request); err != nil {
+		return nil, err
+	}
+
+	s.auditRequest(ctx, "CANCEL_APPROVAL", request, &userID)
+	return request, nil
+}
+
+// decide hands the decision to the document's owner and then saves it
+func (s *approvalService) decide(ctx context.Context, request *crmDomain.ApprovalRequest) error {
+	decider, err := s.decider(request.DocumentType)
+	if err != nil {
+		return err
+	}
+	if err := decider.ApprovalDecided(ctx, request); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateRequest(ctx, request); err != nil {
+		logger.Log.Error(fmt.Sprintf("Approval request %s was applied to %s %s but not saved: %v",
+			request.ID, request.DocumentType, request.DocumentID, err))
+		return err
+	}
+	return nil
+}
+
+func (s *approvalService) auditLimit(ctx context.Context, action string, limit *crmDomain.ApprovalLimit, userID *uuid.UUID) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &limit.TenantID,
+	}
+
+	entityIDStr := limit.ID.String()
+	audit.Service.Log(ctx, action, "ApprovalLimit", &entityIDStr, map[string]interface{}{
+		"document_type": limit.DocumentType,
+		"role":          limit.Role,
+		"user_id":       limit.UserID,
+		"max_amount":    limit.MaxAmount,
+	}, auditCtx)
+}
+
+func (s *approvalService) auditRequest(ctx context.Context, action string, request *crmDomain.ApprovalRequest, userID *uuid.UUID) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &request.TenantID,
+	}
+
+	entityIDStr := request.ID.String()
+	audit.Service.Log(ctx, action, "ApprovalRequest", &entityIDStr, map[string]interface{}{
+		"document_type":   request.DocumentType,
+		"document_id":     request.DocumentID,
+		"document_number": request.DocumentNumber,
+		"amount":          request.Amount,
+		"limit_amount":    request.LimitAmount,
+		"status":          request.Status,
+	}, auditCtx)
+}
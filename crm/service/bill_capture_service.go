@@ -0,0 +1,565 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/logger"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	filesDomain "github.com/aceextension/files/domain"
+	filesService "github.com/aceextension/files/service"
+	"github.com/aceextension/fiscal"
+	"github.com/google/uuid"
+)
+
+const (
+	// extractionBatchSize is how many drafts one extraction run reads
+	extractionBatchSize = 20
+	// minBillImageSize skips the logos and signature images most emails carry
+	minBillImageSize = 10 << 10
+)
+
+// BillCaptureService defines the interface for capturing supplier bills sent by email
+type BillCaptureService interface {
+	// GetInbox returns the tenant's ingest address, creating it on first use
+	GetInbox(ctx context.Context, tenantID uuid.UUID) (*crmDomain.BillInbox, error)
+	// RotateInbox replaces the ingest address; mail to the old one is no longer accepted
+	RotateInbox(ctx context.Context, tenantID, userID uuid.UUID) (*crmDomain.BillInbox, error)
+
+	// Receive creates a draft purchase bill for every PDF or image attached to an
+	// email. ErrBillInboxNotFound if no recipient is an inbox, ErrDuplicateInboundEmail
+	// if the provider delivered the message before.
+	Receive(ctx context.Context, email *crmDomain.InboundEmail) ([]*crmDomain.PurchaseBillDraft, error)
+	// CreateDraft saves a draft filled in by another module, such as a linked
+	// company's sale, matching the supplier by its PAN
+	CreateDraft(ctx context.Context, draft *crmDomain.PurchaseBillDraft) error
+	ListEmails(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*crmDomain.InboundEmailRecord, error)
+
+	GetDraft(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.PurchaseBillDraft, error)
+	ListDrafts(ctx context.Context, tenantID uuid.UUID, status *crmDomain.BillDraftStatus, limit, offset int) ([]*crmDomain.PurchaseBillDraft, error)
+	// SupplierBills lists a supplier's confirmed bills, oldest first, for paying them
+	SupplierBills(ctx context.Context, tenantID, supplierID uuid.UUID, limit int) ([]*crmDomain.PurchaseBillDraft, error)
+	// PurchaseRegister lists confirmed bills dated from..to for the purchase VAT register
+	PurchaseRegister(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*crmDomain.PurchaseRegisterLine, error)
+	// UpdateDraft saves the reviewer's corrections to a pending draft
+	UpdateDraft(ctx context.Context, draft *crmDomain.PurchaseBillDraft) error
+	// Confirm accepts a reviewed draft, or holds it for approval when the total is over
+	// the user's purchase bill limit
+	Confirm(ctx context.Context, tenantID, id, userID uuid.UUID, role string) (*crmDomain.PurchaseBillDraft, error)
+	Reject(ctx context.Context, tenantID, id, userID uuid.UUID, reason string) (*crmDomain.PurchaseBillDraft, error)
+
+	// ProcessExtractions reads bill details from drafts waiting for extraction.
+	// Does nothing unless an extractor and file store are set.
+	ProcessExtractions(ctx context.Context) error
+
+	SetFileStore(store BillFileStore)
+	SetExtractor(extractor BillExtractor)
+	// SetApprovals turns on approval limits for confirming drafts
+	SetApprovals(approvals ApprovalService)
+	SetJournal(journal PurchaseBillJournal)
+
+	ApprovalDecider
+}
+
+// BillFileStore stores the captured bill files. Satisfied by the files module's AttachmentService.
+type BillFileStore interface {
+	Upload(ctx context.Context, input filesService.UploadInput) (*filesDomain.Attachment, error)
+	Open(ctx context.Context, tenantID, id uuid.UUID) (*filesDomain.Attachment, io.ReadCloser, error)
+}
+
+// BillExtractor reads bill number, date, PAN and totals from a bill file (OCR or a
+// document AI service). Fields it cannot read are left nil.
+type BillExtractor interface {
+	ExtractBill(ctx context.Context, contentType string, content []byte) (*crmDomain.BillExtraction, error)
+}
+
+// PurchaseBillJournal books confirmed bills in the general ledger. Init uses
+// accounting's automatic posting when accounting.Init has run first.
+type PurchaseBillJournal interface {
+	PostPurchaseBill(ctx context.Context, draft *crmDomain.PurchaseBillDraft) error
+}
+
+// billCaptureService implements BillCaptureService
+type billCaptureService struct {
+	repo          repository.BillCaptureRepository
+	supplierRepo  repository.SupplierRepository
+	inboundDomain string
+	files         BillFileStore
+	extractor     BillExtractor
+	approvals     ApprovalService
+	journal       PurchaseBillJournal
+}
+
+// NewBillCaptureService creates a new bill capture service. inboundDomain is the mail
+// domain routed to the inbound webhook; addresses are shown empty until it is set.
+func NewBillCaptureService(repo repository.BillCaptureRepository, supplierRepo repository.SupplierRepository, inboundDomain string) BillCaptureService {
+	return &billCaptureService{
+		repo:          repo,
+		supplierRepo:  supplierRepo,
+		inboundDomain: inboundDomain,
+	}
+}
+
+// SetFileStore sets where bill files are kept
+func (s *billCaptureService) SetFileStore(store BillFileStore) {
+	s.files = store
+}
+
+// SetExtractor sets the reader for bill details
+func (s *billCaptureService) SetExtractor(extractor BillExtractor) {
+	s.extractor = extractor
+}
+
+// SetApprovals sets the approval limits drafts are confirmed under
+func (s *billCaptureService) SetApprovals(approvals ApprovalService) {
+	s.approvals = approvals
+}
+
+// SetJournal sets where confirmed bills are booked
+func (s *billCaptureService) SetJournal(journal PurchaseBillJournal) {
+	s.journal = journal
+}
+
+// GetInbox retrieves or creates the tenant's inbox
+func (s *billCaptureService) GetInbox(ctx context.Context, tenantID uuid.UUID) (*crmDomain.BillInbox, error) {
+	inbox, err := s.repo.GetInbox(ctx, tenantID)
+	if err == crmDomain.ErrBillInboxNotFound {
+		inbox = crmDomain.NewBillInbox(tenantID)
+		err = s.repo.SaveInbox(ctx, inbox)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	inbox.SetAddress(s.inboundDomain)
+	return inbox, nil
+}
+
+// RotateInbox gives the tenant a new ingest address
+func (s *billCaptureService) RotateInbox(ctx context.Context, tenantID, userID uuid.UUID) (*crmDomain.BillInbox, error) {
+	inbox, err := s.GetInbox(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := inbox.Address
+	inbox.Token = crmDomain.NewBillInboxToken()
+	inbox.UpdatedAt = time.Now()
+	if err := s.repo.SaveInbox(ctx, inbox); err != nil {
+		return nil, err
+	}
+	inbox.SetAddress(s.inboundDomain)
+
+	audit.Service.Log(ctx, "ROTATE_BILL_INBOX", "BillInbox", nil, map[string]interface{}{
+		"previous_address": previous,
+		"address":          inbox.Address,
+	}, &auditDomain.AuditContext{UserID: &userID, TenantID: &tenantID})
+
+	return inbox, nil
+}
+
+// Receive turns a received email into draft purchase bills
+func (s *billCaptureService) Receive(ctx context.Context, email *crmDomain.InboundEmail) ([]*crmDomain.PurchaseBillDraft, error) {
+	inbox, recipient, err := s.findInbox(ctx, email.To)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &crmDomain.InboundEmailRecord{
+		ID:          uuid.New(),
+		TenantID:    inbox.TenantID,
+		MessageID:   email.MessageID,
+		Sender:      strings.ToLower(email.From),
+		Recipient:   recipient,
+		Subject:     email.Subject,
+		Status:      crmDomain.InboundIgnored,
+		Attachments: len(email.Attachments),
+		ReceivedAt:  time.Now(),
+	}
+	if record.MessageID == "" {
+		record.MessageID = fallbackMessageID(email)
+	}
+	if err := s.repo.RecordEmail(ctx, record); err != nil {
+		return nil, err
+	}
+
+	supplierID, err := s.repo.MatchSupplier(ctx, inbox.TenantID, email.From)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to match supplier for bill email from %s: %v", email.From, err))
+	}
+
+	var drafts []*crmDomain.PurchaseBillDraft
+	for _, attachment := range email.Attachments {
+		if !isBillFile(attachment.Content) {
+			continue
+		}
+
+		draft := crmDomain.NewPurchaseBillDraft(inbox.TenantID, email.From, email.Subject, attachment.FileName)
+		draft.InboundEmailID = &record.ID
+		draft.SupplierID = supplierID
+		if s.extractor != nil && s.files != nil {
+			draft.ExtractionStatus = crmDomain.ExtractionPending
+		}
+		if err := s.repo.CreateDraft(ctx, draft); err != nil {
+			return drafts, err
+		}
+		s.storeFile(ctx, draft, attachment)
+
+		drafts = append(drafts, draft)
+		s.audit(ctx, "CAPTURE_BILL", draft, nil)
+	}
+
+	record.Drafts = len(drafts)
+	if len(drafts) > 0 {
+		record.Status = crmDomain.InboundProcessed
+	} else {
+		reason := "no PDF or image bill attached"
+		record.Reason = &reason
+	}
+	if err := s.repo.UpdateEmail(ctx, record); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to update inbound email %s: %v", record.ID, err))
+	}
+
+	return drafts, nil
+}
+
+// findInbox returns the first recipient that is an active inbox
+func (s *billCaptureService) findInbox(ctx context.Context, recipients []string) (*crmDomain.BillInbox, string, error) {
+	for _, recipient := range recipients {
+		token := crmDomain.BillInboxToken(recipient)
+		if token == "" {
+			continue
+		}
+		inbox, err := s.repo.GetInboxByToken(ctx, token)
+		if err == crmDomain.ErrBillInboxNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if inbox.IsActive {
+			return inbox, strings.ToLower(recipient), nil
+		}
+	}
+	return nil, "", crmDomain.ErrBillInboxNotFound
+}
+
+// storeFile attaches the bill file to its draft. The draft is already saved, so a
+// storage failure is logged and the reviewer sees the draft without its file.
+func (s *billCaptureService) storeFile(ctx context.Context, draft *crmDomain.PurchaseBillDraft, attachment crmDomain.InboundAttachment) {
+	if s.files == nil {
+		return
+	}
+
+	description := "Received from " + draft.SenderEmail
+	stored, err := s.files.Upload(ctx, filesService.UploadInput{
+		TenantID:    draft.TenantID,
+		EntityType:  filesDomain.EntityPurchaseBill,
+		EntityID:    draft.ID,
+		FileName:    attachment.FileName,
+		Description: &description,
+		Content:     bytes.NewReader(attachment.Content),
+	})
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to store bill file %q for draft %s: %v", attachment.FileName, draft.ID, err))
+		draft.FailExtraction("bill file could not be stored")
+		if err := s.repo.SaveExtraction(ctx, draft); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to update bill draft %s: %v", draft.ID, err))
+		}
+		return
+	}
+
+	draft.AttachmentID = &stored.ID
+	draft.UpdatedAt = time.Now()
+	if err := s.repo.UpdateDraft(ctx, draft); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to link bill draft %s to attachment %s: %v", draft.ID, stored.ID, err))
+	}
+}
+
+// isBillFile reports whether an attachment is a PDF or a scan of a bill
+func isBillFile(content []byte) bool {
+	if len(content) == 0 || len(content) > filesDomain.MaxAttachmentSize {
+		return false
+	}
+	contentType := sniffContentType(content)
+	if !filesDomain.AllowedContentTypes[contentType] {
+		return false
+	}
+	return contentType == "application/pdf" || len(content) >= minBillImageSize
+}
+
+// sniffContentType detects the content type the way the files module does
+func sniffContentType(content []byte) string {
+	contentType := http.DetectContentType(content)
+	if idx := strings.Index(contentType, ";"); idx != -1 {
+		contentType = contentType[:idx]
+	}
+	return contentType
+}
+
+// fallbackMessageID identifies an email without a Message-Id header so retries are still recognised
+func fallbackMessageID(email *crmDomain.InboundEmail) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s\n", email.From, email.Subject, email.ReceivedAt.UTC().Format(time.RFC3339))
+	for _, attachment := range email.Attachments {
+		fmt.Fprintf(hash, "%s:%d\n", attachment.FileName, len(attachment.Content))
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// CreateDraft saves a pre-filled draft for review
+func (s *billCaptureService) CreateDraft(ctx context.Context, draft *crmDomain.PurchaseBillDraft) error {
+	if err := draft.Validate(); err != nil {
+		return err
+	}
+	if draft.SupplierID == nil && draft.SupplierPAN != nil {
+		supplierID, err := s.repo.MatchSupplierByPAN(ctx, draft.TenantID, *draft.SupplierPAN)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to match supplier PAN for bill draft %s: %v", draft.ID, err))
+		}
+		draft.SupplierID = supplierID
+	}
+	if err := s.repo.CreateDraft(ctx, draft); err != nil {
+		return err
+	}
+
+	s.audit(ctx, "CAPTURE_BILL", draft, nil)
+	return nil
+}
+
+// ListEmails retrieves emails received at the tenant's inbox
+func (s *billCaptureService) ListEmails(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*crmDomain.InboundEmailRecord, error) {
+	return s.repo.ListEmails(ctx, tenantID, limit, offset)
+}
+
+// GetDraft retrieves a draft purchase bill
+func (s *billCaptureService) GetDraft(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.PurchaseBillDraft, error) {
+	return s.repo.GetDraft(ctx, tenantID, id)
+}
+
+// ListDrafts retrieves draft purchase bills, newest first
+func (s *billCaptureService) ListDrafts(ctx context.Context, tenantID uuid.UUID, status *crmDomain.BillDraftStatus, limit, offset int) ([]*crmDomain.PurchaseBillDraft, error) {
+	return s.repo.ListDrafts(ctx, tenantID, status, limit, offset)
+}
+
+// SupplierBills lists a supplier's confirmed bills
+func (s *billCaptureService) SupplierBills(ctx context.Context, tenantID, supplierID uuid.UUID, limit int) ([]*crmDomain.PurchaseBillDraft, error) {
+	return s.repo.SupplierBills(ctx, tenantID, supplierID, limit)
+}
+
+// PurchaseRegister lists confirmed bills in a date range
+func (s *billCaptureService) PurchaseRegister(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*crmDomain.PurchaseRegisterLine, error) {
+	return s.repo.PurchaseRegister(ctx, tenantID, from, to)
+}
+
+// UpdateDraft saves corrections to a draft
+func (s *billCaptureService) UpdateDraft(ctx context.Context, draft *crmDomain.PurchaseBillDraft) error {
+	if draft.Status != crmDomain.BillDraftPendingReview {
+		return crmDomain.ErrBillDraftState
+	}
+	if err := draft.Validate(); err != nil {
+		return err
+	}
+	if draft.SupplierID != nil {
+		if _, err := s.supplierRepo.GetByID(ctx, draft.TenantID, *draft.SupplierID); err != nil {
+			return crmDomain.ErrSupplierNotFound
+		}
+	}
+
+	draft.UpdatedAt = time.Now()
+	return s.repo.UpdateDraft(ctx, draft)
+}
+
+// Confirm accepts a reviewed draft, or holds it until a draft over the user's
+// limit is approved
+func (s *billCaptureService) Confirm(ctx context.Context, tenantID, id, userID uuid.UUID, role string) (*crmDomain.PurchaseBillDraft, error) {
+	draft, err := s.repo.GetDraft(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := draft.Ready(); err != nil {
+		return nil, err
+	}
+	s.applyTerms(ctx, draft)
+
+	if s.approvals != nil {
+		request, err := s.approvals.Route(ctx, crmDomain.ApprovalDocument{
+			TenantID: tenantID,
+			Type:     crmDomain.ApprovalPurchaseBill,
+			ID:       draft.ID,
+			Number:   draft.BillNumber,
+			Amount:   *draft.TotalAmount,
+		}, userID, role)
+		if err != nil {
+			return nil, err
+		}
+		if request != nil {
+			if err := draft.AwaitApproval(); err != nil {
+				return nil, err
+			}
+			if err := s.repo.SaveReview(ctx, draft, crmDomain.BillDraftPendingReview); err != nil {
+				return nil, err
+			}
+			s.audit(ctx, "HOLD_BILL_DRAFT", draft, &userID)
+			return draft, nil
+		}
+	}
+
+	if err := draft.Confirm(&userID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveReview(ctx, draft, crmDomain.BillDraftPendingReview); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "CONFIRM_BILL_DRAFT", draft, &userID)
+	s.postBill(ctx, draft)
+	return draft, nil
+}
+
+// postBill books a confirmed bill. The bill is already saved, so a failure is
+// logged for follow-up.
+func (s *billCaptureService) postBill(ctx context.Context, draft *crmDomain.PurchaseBillDraft) {
+	if s.journal == nil {
+		return
+	}
+	if err := s.journal.PostPurchaseBill(ctx, draft); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to post purchase bill %s to the journal: %v", draft.ID, err))
+	}
+}
+
+// applyTerms dates the bill's installments from the supplier's payment terms
+func (s *billCaptureService) applyTerms(ctx context.Context, draft *crmDomain.PurchaseBillDraft) {
+	terms := crmDomain.DueOnReceipt()
+	if supplier, err := s.supplierRepo.GetByID(ctx, draft.TenantID, *draft.SupplierID); err == nil && supplier.GetPaymentTerms() != nil {
+		terms = supplier.GetPaymentTerms()
+	}
+	draft.ApplyTerms(terms, fiscal.WorkingCalendar(ctx, draft.TenantID))
+}
+
+// ApprovalDecided confirms a held draft once approved, or returns it for review
+func (s *billCaptureService) ApprovalDecided(ctx context.Context, request *crmDomain.ApprovalRequest) error {
+	draft, err := s.repo.GetDraft(ctx, request.TenantID, request.DocumentID)
+	if err != nil {
+		return err
+	}
+	approved := request.Status == crmDomain.ApprovalApproved
+	if err := draft.ApprovalDecided(approved, request.ReviewedBy); err != nil {
+		return err
+	}
+	if err := s.repo.SaveReview(ctx, draft, crmDomain.BillDraftPendingApproval); err != nil {
+		return err
+	}
+
+	action := "RELEASE_BILL_DRAFT"
+	if approved {
+		action = "CONFIRM_BILL_DRAFT"
+	}
+	s.audit(ctx, action, draft, request.ReviewedBy)
+	if approved {
+		s.postBill(ctx, draft)
+	}
+	return nil
+}
+
+// Reject discards a draft
+func (s *billCaptureService) Reject(ctx context.Context, tenantID, id, userID uuid.UUID, reason string) (*crmDomain.PurchaseBillDraft, error) {
+	draft, err := s.repo.GetDraft(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := draft.Reject(reason, &userID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveReview(ctx, draft, crmDomain.BillDraftPendingReview); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "REJECT_BILL_DRAFT", draft, &userID)
+	return draft, nil
+}
+
+// ProcessExtractions runs the extractor over pending drafts
+func (s *billCaptureService) ProcessExtractions(ctx context.Context) error {
+	if s.extractor == nil || s.files == nil {
+		return nil
+	}
+
+	drafts, err := s.repo.PendingExtractions(ctx, extractionBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, draft := range drafts {
+		s.extract(ctx, draft)
+		if err := s.repo.SaveExtraction(ctx, draft); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to save extraction for bill draft %s: %v", draft.ID, err))
+		}
+	}
+
+	return nil
+}
+
+// extract reads one draft's file, recording the result or the failure on the draft
+func (s *billCaptureService) extract(ctx context.Context, draft *crmDomain.PurchaseBillDraft) {
+	if draft.AttachmentID == nil {
+		draft.FailExtraction("bill file was not stored")
+		return
+	}
+
+	attachment, reader, err := s.files.Open(ctx, draft.TenantID, *draft.AttachmentID)
+	if err != nil {
+		draft.FailExtraction("bill file could not be opened")
+		logger.Log.Error(fmt.Sprintf("Failed to open bill file for draft %s: %v", draft.ID, err))
+		return
+	}
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		draft.FailExtraction("bill file could not be read")
+		logger.Log.Error(fmt.Sprintf("Failed to read bill file for draft %s: %v", draft.ID, err))
+		return
+	}
+
+	extraction, err := s.extractor.ExtractBill(ctx, attachment.ContentType, content)
+	if err != nil {
+		draft.FailExtraction(err.Error())
+		return
+	}
+	draft.ApplyExtraction(extraction)
+
+	if draft.SupplierID == nil && draft.SupplierPAN != nil {
+		supplierID, err := s.repo.MatchSupplierByPAN(ctx, draft.TenantID, *draft.SupplierPAN)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to match supplier PAN for bill draft %s: %v", draft.ID, err))
+		}
+		draft.SupplierID = supplierID
+	}
+}
+
+func (s *billCaptureService) audit(ctx context.Context, action string, draft *crmDomain.PurchaseBillDraft, userID *uuid.UUID) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &draft.TenantID,
+	}
+
+	entityIDStr := draft.ID.String()
+	audit.Service.Log(ctx, action, "PurchaseBillDraft", &entityIDStr, map[string]interface{}{
+		"sender":       draft.SenderEmail,
+		"file_name":    draft.FileName,
+		"supplier_id":  draft.SupplierID,
+		"bill_number":  draft.BillNumber,
+		"total_amount": draft.TotalAmount,
+		"status":       draft.Status,
+	}, auditCtx)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/core/logger"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/google/uuid"
+)
+
+// ConsignmentService defines the interface for supplier-owned consignment stock
+type ConsignmentService interface {
+	// Receive records stock held on consignment; no payable is raised
+	Receive(ctx context.Context, receipt *crmDomain.ConsignmentReceipt) error
+	GetReceipt(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.ConsignmentReceipt, error)
+	ListReceipts(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, limit, offset int) ([]*crmDomain.ConsignmentReceipt, error)
+
+	// RecordSale settles sold items against consignment stock and raises a purchase bill
+	// per supplier. Sales call this with every line; products not on consignment are skipped.
+	RecordSale(ctx context.Context, tenantID uuid.UUID, items []crmDomain.ConsignmentSaleItem, saleType *string, saleID *uuid.UUID) ([]*crmDomain.ConsignmentBill, error)
+	// ReturnToSupplier sends unsold stock from a receipt line back to the supplier
+	ReturnToSupplier(ctx context.Context, tenantID, lineID uuid.UUID, quantity float64) error
+
+	GetBill(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.ConsignmentBill, error)
+	ListBills(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, limit, offset int) ([]*crmDomain.ConsignmentBill, error)
+
+	// StockReport returns consignment stock per supplier and product, optionally for one supplier
+	StockReport(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID) ([]*crmDomain.ConsignmentStock, error)
+
+	SetBillJournal(journal BillJournal)
+}
+
+// BillJournal books consignment bills in the general ledger as a purchase payable to the
+// supplier. Implemented outside CRM by the process that wires accounting in; it returns
+// the journal entry ID it created.
+type BillJournal interface {
+	PostConsignmentBill(ctx context.Context, bill *crmDomain.ConsignmentBill) (uuid.UUID, error)
+}
+
+// consignmentService implements ConsignmentService
+type consignmentService struct {
+	repo         repository.ConsignmentRepository
+	supplierRepo repository.SupplierRepository
+	journal      BillJournal
+}
+
+// NewConsignmentService creates a new consignment service
+func NewConsignmentService(repo repository.ConsignmentRepository, supplierRepo repository.SupplierRepository) ConsignmentService {
+	return &consignmentService{
+		repo:         repo,
+		supplierRepo: supplierRepo,
+	}
+}
+
+// SetBillJournal sets the ledger that consignment bills are posted to
+func (s *consignmentService) SetBillJournal(journal BillJournal) {
+	s.journal = journal
+}
+
+// Receive records a consignment receipt for one of the tenant's suppliers
+func (s *consignmentService) Receive(ctx context.Context, receipt *crmDomain.ConsignmentReceipt) error {
+	if _, err := s.supplierRepo.GetByID(ctx, receipt.TenantID, receipt.SupplierID); err != nil {
+		return crmDomain.ErrSupplierNotFound
+	}
+	if len(receipt.Lines) == 0 {
+		return fmt.Errorf("%w: receipt has no lines", crmDomain.ErrInvalidConsignmentLine)
+	}
+
+	return s.repo.CreateReceipt(ctx, receipt)
+}
+
+// GetReceipt retrieves a consignment receipt with its lines
+func (s *consignmentService) GetReceipt(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.ConsignmentReceipt, error) {
+	return s.repo.GetReceipt(ctx, tenantID, id)
+}
+
+// ListReceipts returns consignment receipts, newest first
+func (s *consignmentService) ListReceipts(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, limit, offset int) ([]*crmDomain.ConsignmentReceipt, error) {
+	return s.repo.ListReceipts(ctx, tenantID, supplierID, limit, offset)
+}
+
+// RecordSale generates supplier bills for consignment stock consumed by a sale
+func (s *consignmentService) RecordSale(ctx context.Context, tenantID uuid.UUID, items []crmDomain.ConsignmentSaleItem, saleType *string, saleID *uuid.UUID) ([]*crmDomain.ConsignmentBill, error) {
+	for _, item := range items {
+		if item.ProductID == uuid.Nil || item.Quantity <= 0 {
+			return nil, crmDomain.ErrInvalidConsignmentLine
+		}
+	}
+
+	bills, err := s.repo.Settle(ctx, tenantID, items, saleType, saleID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bill := range bills {
+		s.postBill(ctx, bill)
+	}
+
+	return bills, nil
+}
+
+// postBill books the bill in the ledger. The bill is already committed, so a ledger
+// failure is logged and leaves the bill unlinked for follow-up.
+func (s *consignmentService) postBill(ctx context.Context, bill *crmDomain.ConsignmentBill) {
+	if s.journal == nil || bill.TotalAmount == 0 {
+		return
+	}
+
+	entryID, err := s.journal.PostConsignmentBill(ctx, bill)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to post consignment bill %s to ledger: %v", bill.BillNumber, err))
+		return
+	}
+
+	bill.JournalEntryID = &entryID
+	if err := s.repo.SetBillJournalEntry(ctx, bill.ID, entryID); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to link consignment bill %s to journal %s: %v", bill.BillNumber, entryID, err))
+	}
+}
+
+// ReturnToSupplier records unsold consignment stock sent back to its supplier
+func (s *consignmentService) ReturnToSupplier(ctx context.Context, tenantID, lineID uuid.UUID, quantity float64) error {
+	if quantity <= 0 {
+		return crmDomain.ErrInvalidConsignmentLine
+	}
+	return s.repo.ReturnToSupplier(ctx, tenantID, lineID, quantity)
+}
+
+// GetBill retrieves a consignment bill with its lines
+func (s *consignmentService) GetBill(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.ConsignmentBill, error) {
+	return s.repo.GetBill(ctx, tenantID, id)
+}
+
+// ListBills returns consignment bills, newest first
+func (s *consignmentService) ListBills(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, limit, offset int) ([]*crmDomain.ConsignmentBill, error) {
+	return s.repo.ListBills(ctx, tenantID, supplierID, limit, offset)
+}
+
+// StockReport returns received, sold, returned and on-hand consignment stock
+func (s *consignmentService) StockReport(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID) ([]*crmDomain.ConsignmentStock, error) {
+	return s.repo.StockReport(ctx, tenantID, supplierID)
+}
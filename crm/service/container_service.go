@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/logger"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/google/uuid"
+)
+
+// ContainerService defines the interface for returnable container deposit tracking
+type ContainerService interface {
+	CreateType(ctx context.Context, containerType *crmDomain.ContainerType) error
+	GetType(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.ContainerType, error)
+	ListTypes(ctx context.Context, tenantID uuid.UUID) ([]*crmDomain.ContainerType, error)
+	UpdateType(ctx context.Context, containerType *crmDomain.ContainerType) error
+
+	// Issue sends containers out with a sale and collects the type's deposit
+	Issue(ctx context.Context, movement *crmDomain.ContainerMovement) error
+	// Return takes containers back (e.g. on a sales return) and refunds the deposit held
+	Return(ctx context.Context, movement *crmDomain.ContainerMovement) error
+	ListMovements(ctx context.Context, tenantID, customerID uuid.UUID, limit, offset int) ([]*crmDomain.ContainerMovement, error)
+
+	// CustomerBalances returns what a customer holds
+	CustomerBalances(ctx context.Context, tenantID, customerID uuid.UUID) ([]*crmDomain.ContainerBalance, error)
+	// BalanceReport returns every outstanding balance with the total deposit liability
+	BalanceReport(ctx context.Context, tenantID uuid.UUID) (*crmDomain.ContainerBalanceReport, error)
+
+	SetDepositJournal(journal DepositJournal)
+}
+
+// DepositJournal books container deposits in the general ledger: an issue credits the
+// deposit liability account and a return debits it. Implemented outside CRM by the
+// process that wires accounting in; it returns the journal entry ID it created.
+type DepositJournal interface {
+	PostContainerDeposit(ctx context.Context, movement *crmDomain.ContainerMovement, containerType *crmDomain.ContainerType) (uuid.UUID, error)
+}
+
+// containerService implements ContainerService
+type containerService struct {
+	repo         repository.ContainerRepository
+	customerRepo repository.CustomerRepository
+	journal      DepositJournal
+}
+
+// NewContainerService creates a new container service
+func NewContainerService(repo repository.ContainerRepository, customerRepo repository.CustomerRepository) ContainerService {
+	return &containerService{
+		repo:         repo,
+		customerRepo: customerRepo,
+	}
+}
+
+// SetDepositJournal sets the ledger that deposit movements are posted to
+func (s *containerService) SetDepositJournal(journal DepositJournal) {
+	s.journal = journal
+}
+
+// CreateType creates a container type
+func (s *containerService) CreateType(ctx context.Context, containerType *crmDomain.ContainerType) error {
+	containerType.Code = strings.ToUpper(strings.TrimSpace(containerType.Code))
+	if containerType.Code == "" {
+		return fmt.Errorf("container code is required")
+	}
+	if containerType.DepositAmount < 0 {
+		return fmt.Errorf("deposit amount cannot be negative")
+	}
+
+	return s.repo.CreateType(ctx, containerType)
+}
+
+// GetType retrieves a container type
+func (s *containerService) GetType(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.ContainerType, error) {
+	return s.repo.GetTypeByID(ctx, tenantID, id)
+}
+
+// ListTypes returns the tenant's container types
+func (s *containerService) ListTypes(ctx context.Context, tenantID uuid.UUID) ([]*crmDomain.ContainerType, error) {
+	return s.repo.ListTypes(ctx, tenantID)
+}
+
+// UpdateType updates a container type; a new deposit applies to future issues only
+func (s *containerService) UpdateType(ctx context.Context, containerType *crmDomain.ContainerType) error {
+	if containerType.DepositAmount < 0 {
+		return fmt.Errorf("deposit amount cannot be negative")
+	}
+
+	containerType.UpdatedAt = time.Now()
+	return s.repo.UpdateType(ctx, containerType)
+}
+
+// Issue records containers given to a customer at the current deposit
+func (s *containerService) Issue(ctx context.Context, movement *crmDomain.ContainerMovement) error {
+	containerType, err := s.prepare(ctx, movement)
+	if err != nil {
+		return err
+	}
+	if !containerType.IsActive {
+		return fmt.Errorf("container type %s is inactive", containerType.Code)
+	}
+
+	movement.Kind = crmDomain.ContainerIssued
+	movement.DepositAmount = containerType.DepositAmount * float64(movement.Quantity)
+	if err := s.repo.Issue(ctx, movement); err != nil {
+		return err
+	}
+
+	s.postDeposit(ctx, movement, containerType)
+	return nil
+}
+
+// Return records containers brought back; the refund is set from the deposit held
+func (s *containerService) Return(ctx context.Context, movement *crmDomain.ContainerMovement) error {
+	containerType, err := s.prepare(ctx, movement)
+	if err != nil {
+		return err
+	}
+
+	movement.Kind = crmDomain.ContainerReturned
+	if err := s.repo.Return(ctx, movement); err != nil {
+		return err
+	}
+
+	s.postDeposit(ctx, movement, containerType)
+	return nil
+}
+
+// prepare validates the movement's quantity, customer and container type
+func (s *containerService) prepare(ctx context.Context, movement *crmDomain.ContainerMovement) (*crmDomain.ContainerType, error) {
+	if movement.Quantity <= 0 {
+		return nil, crmDomain.ErrInvalidContainerMovement
+	}
+
+	if _, err := s.customerRepo.GetByID(ctx, movement.TenantID, movement.CustomerID); err != nil {
+		return nil, crmDomain.ErrCustomerNotFound
+	}
+
+	return s.repo.GetTypeByID(ctx, movement.TenantID, movement.ContainerTypeID)
+}
+
+// postDeposit books the deposit in the ledger. The movement and balance are already
+// committed, so a ledger failure is logged and leaves the movement unlinked for follow-up.
+func (s *containerService) postDeposit(ctx context.Context, movement *crmDomain.ContainerMovement, containerType *crmDomain.ContainerType) {
+	if s.journal == nil || movement.DepositAmount == 0 {
+		return
+	}
+
+	entryID, err := s.journal.PostContainerDeposit(ctx, movement, containerType)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to post container deposit %s to ledger: %v", movement.ID, err))
+		return
+	}
+
+	movement.JournalEntryID = &entryID
+	if err := s.repo.SetJournalEntry(ctx, movement.ID, entryID); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to link container movement %s to journal %s: %v", movement.ID, entryID, err))
+	}
+}
+
+// ListMovements returns a customer's container history, newest first
+func (s *containerService) ListMovements(ctx context.Context, tenantID, customerID uuid.UUID, limit, offset int) ([]*crmDomain.ContainerMovement, error) {
+	return s.repo.ListMovements(ctx, tenantID, customerID, limit, offset)
+}
+
+// CustomerBalances returns the containers a customer currently holds
+func (s *containerService) CustomerBalances(ctx context.Context, tenantID, customerID uuid.UUID) ([]*crmDomain.ContainerBalance, error) {
+	return s.repo.ListBalances(ctx, tenantID, &customerID)
+}
+
+// BalanceReport returns all outstanding container balances and the deposit liability
+func (s *containerService) BalanceReport(ctx context.Context, tenantID uuid.UUID) (*crmDomain.ContainerBalanceReport, error) {
+	balances, err := s.repo.ListBalances(ctx, tenantID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return crmDomain.NewContainerBalanceReport(balances), nil
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/aceextension/core/dataimport"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/taxid"
+	"github.com/google/uuid"
+)
+
+// Customer import columns; only name is required. District, municipality, ward and
+// tole make a structured address, which needs at least the district.
+const (
+	importColName         = "name"
+	importColCustomerType = "customerType" // individual (default) or business
+	importColEmail        = "email"
+	importColPhone        = "phone"
+	importColPAN          = "pan"
+	importColVAT          = "vat"
+	importColCreditLimit  = "creditLimit"
+	importColAddress      = "address" // Single-line address, when there is no district
+	importColDistrict     = "district"
+	importColMunicipality = "municipality"
+	importColWard         = "ward"
+	importColTole         = "tole"
+)
+
+// Import validates a customer upload row by row and, unless it is a dry run, creates
+// the customers when every row is valid. Rows sharing a phone or PAN with each other
+// or with an existing customer are errors, so an upload cannot create duplicates.
+// Each customer is created like one added by hand, so a failure partway leaves the
+// earlier rows created; the report counts them and gives the failed row's error.
+func (s *customerService) Import(ctx context.Context, tenantID, userID uuid.UUID, table *dataimport.Table, dryRun bool) (*dataimport.Report, error) {
+	report := dataimport.NewReport(table, dryRun)
+	if missing := table.Missing(importColName); missing != nil {
+		report.FailMissing(missing)
+		return report, nil
+	}
+
+	phones := map[string]int{}
+	pans := map[string]int{}
+	customers := make([]*crmDomain.Customer, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		before := len(report.Errors)
+		customer := importCustomerRow(tenantID, row, report)
+		if customer == nil {
+			continue
+		}
+
+		phone, pan := customer.DedupeKeys()
+		if first, dup := phones[phone]; phone != "" && dup {
+			report.Fail(row.Number, importColPhone, fmt.Sprintf("phone repeats row %d", first))
+		} else if phone != "" {
+			phones[phone] = row.Number
+		}
+		if first, dup := pans[pan]; pan != "" && dup {
+			report.Fail(row.Number, importColPAN, fmt.Sprintf("PAN repeats row %d", first))
+		} else if pan != "" {
+			pans[pan] = row.Number
+		}
+		if phone != "" || pan != "" {
+			existing, err := s.repo.FindDuplicates(ctx, tenantID, phone, pan, 1)
+			if err != nil {
+				return nil, err
+			}
+			if len(existing) > 0 {
+				report.Fail(row.Number, "", fmt.Sprintf("customer %s already has this phone or PAN", existing[0].CustomerCode))
+			}
+		}
+
+		if len(report.Errors) == before {
+			report.Valid++
+			customers = append(customers, customer)
+		}
+	}
+
+	if dryRun || !report.OK() {
+		return report, nil
+	}
+	// Every row is valid, so customers line up with the table's rows
+	for i, customer := range customers {
+		if err := s.create(ctx, customer, userID, map[string]interface{}{"source": "import"}); err != nil {
+			return nil, fmt.Errorf("row %d: %w", table.Rows[i].Number, err)
+		}
+		report.Imported++
+	}
+	return report, nil
+}
+
+// importCustomerRow reads and validates one row; problems go to the report and give nil
+func importCustomerRow(tenantID uuid.UUID, row dataimport.Row, report *dataimport.Report) *crmDomain.Customer {
+	ok := true
+	fail := func(column, message string) {
+		report.Fail(row.Number, column, message)
+		ok = false
+	}
+
+	name := row.Get(importColName)
+	if len(name) < 2 || len(name) > 255 {
+		fail(importColName, "name must be 2 to 255 characters")
+	}
+	customer := crmDomain.NewCustomer(tenantID, name)
+
+	switch customerType := strings.ToLower(row.Get(importColCustomerType)); customerType {
+	case "":
+	case string(crmDomain.CustomerTypeIndividual), string(crmDomain.CustomerTypeBusiness):
+		customer.CustomerType = crmDomain.CustomerType(customerType)
+	default:
+		fail(importColCustomerType, "customer type must be individual or business")
+	}
+	if email := row.Optional(importColEmail); email != nil {
+		if _, err := mail.ParseAddress(*email); err != nil {
+			fail(importColEmail, "invalid email address")
+		}
+		customer.Email = email
+	}
+	customer.Phone = row.Optional(importColPhone)
+	if pan := row.Get(importColPAN); pan != "" {
+		if _, err := taxid.Validate(pan); err != nil {
+			fail(importColPAN, err.Error())
+		}
+		customer.SetPANNumber(pan)
+	}
+	if vat := row.Get(importColVAT); vat != "" {
+		if _, err := taxid.Validate(vat); err != nil {
+			fail(importColVAT, err.Error())
+		}
+		customer.SetVATNumber(vat)
+	}
+	if limit, set, err := row.Float(importColCreditLimit); err != nil {
+		fail(importColCreditLimit, err.Error())
+	} else if limit < 0 {
+		fail(importColCreditLimit, "credit limit cannot be negative")
+	} else if set {
+		customer.SetCreditLimit(limit)
+	}
+
+	if district := row.Get(importColDistrict); district != "" {
+		address := &crmDomain.Address{
+			District:     district,
+			Municipality: row.Get(importColMunicipality),
+			Tole:         row.Get(importColTole),
+			Line1:        row.Get(importColAddress),
+		}
+		if ward := row.Get(importColWard); ward != "" {
+			n, err := strconv.Atoi(ward)
+			if err != nil {
+				fail(importColWard, fmt.Sprintf("%q is not a ward number", ward))
+			}
+			address.Ward = n
+		}
+		customer.SetStructuredAddress(address)
+	} else if address := row.Get(importColAddress); address != "" {
+		customer.SetAddress(address)
+	}
+	if !ok {
+		return nil
+	}
+
+	if err := customer.NormalizeAddress(); err != nil {
+		fail(importColDistrict, err.Error())
+	}
+	if err := customer.NormalizeTaxIDs(nil); err != nil {
+		fail(importColPAN, err.Error())
+	}
+	if !ok {
+		return nil
+	}
+	return customer
+}
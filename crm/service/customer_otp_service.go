@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/logger"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/aceextension/notification"
+	notificationDomain "github.com/aceextension/notification/domain"
+	notificationService "github.com/aceextension/notification/service"
+	"github.com/google/uuid"
+)
+
+// customerOTPTemplate is the notification template tenants may define to word the code message
+const customerOTPTemplate = "CUSTOMER_OTP"
+
+// CustomerOTPService sends verification codes to customers at the point of sale
+type CustomerOTPService interface {
+	// Send texts or emails a new code to the customer
+	Send(ctx context.Context, tenantID, customerID uuid.UUID, channel crmDomain.OTPChannel, requestedBy uuid.UUID) (*crmDomain.CustomerOTP, error)
+	// Verify checks the code the customer read out
+	Verify(ctx context.Context, tenantID, id uuid.UUID, code string, userID uuid.UUID) (*crmDomain.CustomerOTP, error)
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.CustomerOTP, error)
+	// AttachToInvoice records a verification on the credit sale it was taken for and
+	// returns the proof to keep on the invoice; each verification covers one sale. Sales
+	// calls it while creating the invoice, so the invoice is the one being issued.
+	AttachToInvoice(ctx context.Context, tenantID, id, customerID, invoiceID uuid.UUID) (*crmDomain.OTPProof, error)
+}
+
+// customerOTPService implements CustomerOTPService
+type customerOTPService struct {
+	repo         repository.CustomerOTPRepository
+	customerRepo repository.CustomerRepository
+}
+
+// NewCustomerOTPService creates a new customer OTP service; codes are delivered through the notification module
+func NewCustomerOTPService(repo repository.CustomerOTPRepository, customerRepo repository.CustomerRepository) CustomerOTPService {
+	return &customerOTPService{
+		repo:         repo,
+		customerRepo: customerRepo,
+	}
+}
+
+// Send creates a challenge and delivers its code at high priority
+func (s *customerOTPService) Send(ctx context.Context, tenantID, customerID uuid.UUID, channel crmDomain.OTPChannel, requestedBy uuid.UUID) (*crmDomain.CustomerOTP, error) {
+	customer, err := s.customerRepo.GetByID(ctx, tenantID, customerID)
+	if err != nil {
+		return nil, crmDomain.ErrCustomerNotFound
+	}
+
+	recipient := customer.Phone
+	if channel == crmDomain.OTPChannelEmail {
+		recipient = customer.Email
+	}
+	if recipient == nil || strings.TrimSpace(*recipient) == "" {
+		return nil, crmDomain.ErrOTPNoRecipient
+	}
+
+	latest, err := s.repo.Latest(ctx, tenantID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < crmDomain.OTPResendInterval {
+		return nil, crmDomain.ErrOTPTooSoon
+	}
+
+	if notification.Service == nil {
+		return nil, fmt.Errorf("notification module is not initialized")
+	}
+
+	code, err := generateOTPCode()
+	if err != nil {
+		return nil, err
+	}
+	otp := crmDomain.NewCustomerOTP(tenantID, customerID, channel, strings.TrimSpace(*recipient), code, requestedBy)
+	if err := s.repo.Create(ctx, otp); err != nil {
+		return nil, err
+	}
+
+	minutes := int(crmDomain.OTPTTL / time.Minute)
+	referenceType := "CUSTOMER_OTP"
+	req := notificationService.SendRequest{
+		TenantID:      tenantID,
+		UserID:        &requestedBy,
+		Channel:       notificationDomain.ChannelType(channel),
+		Recipient:     otp.Recipient,
+		Content:       fmt.Sprintf("Your verification code is {{secret.code}}. It expires in %d minutes. Share it only with the shop you are buying from.", minutes),
+		Variables:     map[string]interface{}{"code": "{{secret.code}}", "customerName": customer.Name, "minutes": minutes},
+		Secrets:       map[string]string{"code": code},
+		Priority:      notificationDomain.PriorityHigh,
+		CustomerID:    &customer.ID,
+		ReferenceType: &referenceType,
+		ReferenceID:   &otp.ID,
+	}
+	if template, err := notification.TemplateRepo.GetByCode(ctx, tenantID, customerOTPTemplate, req.Channel); err == nil && template.IsActive {
+		req.TemplateID = &template.ID
+	}
+
+	sent, err := notification.Service.Send(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send verification code: %w", err)
+	}
+	otp.NotificationID = &sent.ID
+	if err := s.repo.SetNotification(ctx, otp.ID, sent.ID); err != nil {
+		logger.Log.Warn("Failed to link customer OTP notification: " + err.Error())
+	}
+
+	return otp, nil
+}
+
+// Verify checks a code; every attempt is counted before the code is compared, so
+// wrong guesses count even when sent concurrently
+func (s *customerOTPService) Verify(ctx context.Context, tenantID, id uuid.UUID, code string, userID uuid.UUID) (*crmDomain.CustomerOTP, error) {
+	otp, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if otp.VerifiedAt != nil {
+		return otp, nil
+	}
+	now := time.Now()
+	if !otp.Open(now) {
+		return nil, crmDomain.ErrOTPExpired
+	}
+
+	attempts, err := s.repo.CountAttempt(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	otp.Attempts = attempts
+	if err := otp.Verify(code, userID, now); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveVerified(ctx, otp); err != nil {
+		return nil, err
+	}
+
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   &userID,
+		TenantID: &tenantID,
+	}
+	entityIDStr := otp.CustomerID.String()
+	audit.Service.Log(ctx, "VERIFY_CUSTOMER_OTP", "Customer", &entityIDStr, map[string]interface{}{
+		"verification_id": otp.ID.String(),
+		"channel":         otp.Channel,
+		"recipient":       otp.MaskedRecipient(),
+		"attempts":        otp.Attempts,
+	}, auditCtx)
+
+	return otp, nil
+}
+
+// Get retrieves a challenge
+func (s *customerOTPService) Get(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.CustomerOTP, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// AttachToInvoice records a fresh verification of the invoice's customer on the invoice
+func (s *customerOTPService) AttachToInvoice(ctx context.Context, tenantID, id, customerID, invoiceID uuid.UUID) (*crmDomain.OTPProof, error) {
+	otp, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !otp.UsableFor(customerID, time.Now()) {
+		return nil, crmDomain.ErrOTPNotVerified
+	}
+	if err := s.repo.AttachInvoice(ctx, tenantID, id, invoiceID); err != nil {
+		return nil, err
+	}
+	otp.InvoiceID = &invoiceID
+	return otp.Proof(), nil
+}
+
+// generateOTPCode returns OTPLength random digits
+func generateOTPCode() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(crmDomain.OTPLength), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", crmDomain.OTPLength, n), nil
+}
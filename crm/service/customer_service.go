@@ -2,29 +2,46 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/aceextension/audit"
 	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/dataimport"
+	"github.com/aceextension/core/db"
 	crmDomain "github.com/aceextension/crm/domain"
 	"github.com/aceextension/crm/repository"
 	"github.com/aceextension/fiscal"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 )
 
 // CustomerService defines the interface for customer operations
 type CustomerService interface {
 	Create(ctx context.Context, customer *crmDomain.Customer) error
-	GetByID(ctx context.Context, id uuid.UUID) (*crmDomain.Customer, error)
+	Sync(ctx context.Context, customer *crmDomain.Customer, userID uuid.UUID, sync crmDomain.CustomerSync) (*crmDomain.CustomerSyncResult, error)
+	// GetByID, Update and Delete only reach the tenant's own customers; others are ErrCustomerNotFound
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Customer, error)
 	GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*crmDomain.Customer, error)
-	GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*crmDomain.Customer, error)
+	// List returns a page of customers, newest first; a tags filter keeps customers carrying them
+	List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) (db.Page[*crmDomain.Customer], error)
+	Export(ctx context.Context, tenantID uuid.UUID, fn func(*crmDomain.Customer) error) error
+	// Import validates an uploaded table of customers and creates them when every row is valid
+	Import(ctx context.Context, tenantID, userID uuid.UUID, table *dataimport.Table, dryRun bool) (*dataimport.Report, error)
 	Update(ctx context.Context, customer *crmDomain.Customer) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
 	Search(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) ([]*crmDomain.Customer, error)
+	ListByArea(ctx context.Context, tenantID uuid.UUID, filter crmDomain.AreaFilter, page db.PageRequest) (db.Page[*crmDomain.Customer], error)
+	// ListByTags retrieves customers carrying the filter's tags, optionally narrowed by a search query
+	ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*crmDomain.Customer, error)
 	Count(ctx context.Context, tenantID uuid.UUID) (int64, error)
 }
 
+// maxCustomerMatches bounds the existing customers reported for one offline customer
+const maxCustomerMatches = 5
+
 // customerService implements CustomerService
 type customerService struct {
 	repo repository.CustomerRepository
@@ -39,6 +56,22 @@ func NewCustomerService(repo repository.CustomerRepository) CustomerService {
 
 // Create creates a new customer
 func (s *customerService) Create(ctx context.Context, customer *crmDomain.Customer) error {
+	return s.create(ctx, customer, uuid.Nil, nil)
+}
+
+// create saves a customer and logs its creation with any extra audit details. The
+// nil userID leaves the audit entry to the request's user.
+func (s *customerService) create(ctx context.Context, customer *crmDomain.Customer, userID uuid.UUID, extra map[string]interface{}) error {
+	if err := customer.NormalizeAddress(); err != nil {
+		return err
+	}
+	if err := customer.NormalizeTaxIDs(nil); err != nil {
+		return err
+	}
+	if err := customer.NormalizePaymentTerms(nil); err != nil {
+		return err
+	}
+
 	// Generate customer code if not provided
 	if customer.CustomerCode == "" {
 		code, err := s.generateCustomerCode(ctx, customer.TenantID)
@@ -50,29 +83,156 @@ func (s *customerService) Create(ctx context.Context, customer *crmDomain.Custom
 
 	// Create customer
 	if err := s.repo.Create(ctx, customer); err != nil {
+		if errors.Is(err, crmDomain.ErrCustomerIDTaken) {
+			return err
+		}
 		return fmt.Errorf("failed to create customer: %w", err)
 	}
 
 	// Audit log
-	userID := uuid.Nil
 	auditCtx := &auditDomain.AuditContext{
-		UserID:   &userID, // TODO: Get from context
+		UserID:   &userID,
 		TenantID: &customer.TenantID,
 	}
 
-	entityIDStr := customer.ID.String()
-	audit.Service.Log(ctx, "CREATE_CUSTOMER", "Customer", &entityIDStr, map[string]interface{}{
+	details := map[string]interface{}{
 		"customer_code": customer.CustomerCode,
 		"name":          customer.Name,
 		"email":         customer.Email,
-	}, auditCtx)
+	}
+	for key, value := range extra {
+		details[key] = value
+	}
+
+	entityIDStr := customer.ID.String()
+	audit.Service.Log(ctx, "CREATE_CUSTOMER", "Customer", &entityIDStr, details, auditCtx)
 
 	return nil
 }
 
-// GetByID retrieves a customer by ID
-func (s *customerService) GetByID(ctx context.Context, id uuid.UUID) (*crmDomain.Customer, error) {
-	return s.repo.GetByID(ctx, id)
+// Sync saves a customer created offline under its client-generated ID. Syncing
+// the same ID again returns the customer it became. A customer sharing a phone
+// or PAN with existing ones is not created unless the client resolves the
+// match: ResolutionCreate saves it anyway and ResolutionMerge folds it into one
+// of the matches, remembering its ID for later syncs.
+func (s *customerService) Sync(ctx context.Context, customer *crmDomain.Customer, userID uuid.UUID, sync crmDomain.CustomerSync) (*crmDomain.CustomerSyncResult, error) {
+	if err := sync.Validate(); err != nil {
+		return nil, err
+	}
+
+	// An ID taken by another tenant's customer fails on create with ErrCustomerIDTaken
+	if existing, err := s.repo.GetByID(ctx, customer.TenantID, customer.ID); err == nil {
+		return &crmDomain.CustomerSyncResult{Outcome: crmDomain.SyncExisting, Customer: existing}, nil
+	}
+
+	mergedInto, err := s.repo.GetAlias(ctx, customer.TenantID, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	if mergedInto != nil {
+		existing, err := s.repo.GetByID(ctx, customer.TenantID, *mergedInto)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get merged customer: %w", err)
+		}
+		return &crmDomain.CustomerSyncResult{Outcome: crmDomain.SyncExisting, Customer: existing}, nil
+	}
+
+	extra := map[string]interface{}{"offline": true, "device_id": sync.DeviceID}
+	if sync.Resolution == crmDomain.ResolutionCreate {
+		if err := s.create(ctx, customer, userID, extra); err != nil {
+			return nil, err
+		}
+		return &crmDomain.CustomerSyncResult{Outcome: crmDomain.SyncCreated, Customer: customer}, nil
+	}
+
+	if err := customer.NormalizeTaxIDs(nil); err != nil {
+		return nil, err
+	}
+	if err := customer.NormalizePaymentTerms(nil); err != nil {
+		return nil, err
+	}
+	matches, err := s.findMatches(ctx, customer)
+	if err != nil {
+		return nil, err
+	}
+
+	if sync.Resolution == crmDomain.ResolutionMerge {
+		for _, match := range matches {
+			if match.Customer.ID == *sync.MergeInto {
+				return s.merge(ctx, match, customer, userID, sync.DeviceID)
+			}
+		}
+		return nil, crmDomain.ErrInvalidResolution
+	}
+
+	if len(matches) > 0 {
+		return nil, &crmDomain.DuplicateCustomerError{Matches: matches}
+	}
+	if err := s.create(ctx, customer, userID, extra); err != nil {
+		return nil, err
+	}
+	return &crmDomain.CustomerSyncResult{Outcome: crmDomain.SyncCreated, Customer: customer}, nil
+}
+
+// findMatches retrieves the customers sharing the offline customer's phone or PAN
+func (s *customerService) findMatches(ctx context.Context, customer *crmDomain.Customer) ([]crmDomain.CustomerMatch, error) {
+	phone, pan := customer.DedupeKeys()
+	if phone == "" && pan == "" {
+		return nil, nil
+	}
+
+	duplicates, err := s.repo.FindDuplicates(ctx, customer.TenantID, phone, pan, maxCustomerMatches)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]crmDomain.CustomerMatch, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		matches = append(matches, crmDomain.CustomerMatch{Customer: duplicate, MatchedOn: customer.MatchedOn(duplicate)})
+	}
+	return matches, nil
+}
+
+// merge folds an offline customer into a match and records its ID as an alias
+func (s *customerService) merge(ctx context.Context, match crmDomain.CustomerMatch, offline *crmDomain.Customer, userID uuid.UUID, deviceID string) (*crmDomain.CustomerSyncResult, error) {
+	target := match.Customer
+	target.MergeFrom(offline)
+
+	alias := &crmDomain.CustomerAlias{
+		AliasID:    offline.ID,
+		TenantID:   target.TenantID,
+		CustomerID: target.ID,
+		CreatedBy:  userID,
+		CreatedAt:  time.Now(),
+	}
+	if deviceID != "" {
+		alias.DeviceID = &deviceID
+	}
+
+	if err := s.repo.MergeOffline(ctx, target, alias); err != nil {
+		return nil, err
+	}
+
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   &userID,
+		TenantID: &target.TenantID,
+	}
+
+	entityIDStr := target.ID.String()
+	audit.Service.Log(ctx, "MERGE_CUSTOMER", "Customer", &entityIDStr, map[string]interface{}{
+		"alias_id":   offline.ID.String(),
+		"name":       offline.Name,
+		"matched_on": match.MatchedOn,
+		"offline":    true,
+		"device_id":  deviceID,
+	}, auditCtx)
+
+	return &crmDomain.CustomerSyncResult{Outcome: crmDomain.SyncMerged, Customer: target}, nil
+}
+
+// GetByID retrieves a tenant's customer by ID
+func (s *customerService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Customer, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
 }
 
 // GetByCode retrieves a customer by customer code
@@ -80,29 +240,48 @@ func (s *customerService) GetByCode(ctx context.Context, tenantID uuid.UUID, cod
 	return s.repo.GetByCode(ctx, tenantID, code)
 }
 
-// GetByTenantID retrieves all customers for a tenant
-func (s *customerService) GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*crmDomain.Customer, error) {
-	return s.repo.GetByTenantID(ctx, tenantID, limit, offset)
+// List returns a page of a tenant's customers, newest first
+func (s *customerService) List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) (db.Page[*crmDomain.Customer], error) {
+	customers, total, err := s.repo.List(ctx, tenantID, tags, page)
+	if err != nil {
+		return db.Page[*crmDomain.Customer]{}, err
+	}
+	return db.NewPage(customers, total, page, func(c *crmDomain.Customer) db.Cursor {
+		return db.NewCursor(c.CreatedAt, c.ID)
+	}), nil
+}
+
+// Export passes every customer for a tenant to fn as it is read from the database
+func (s *customerService) Export(ctx context.Context, tenantID uuid.UUID, fn func(*crmDomain.Customer) error) error {
+	return s.repo.StreamByTenantID(ctx, tenantID, fn)
 }
 
 // Update updates a customer
 func (s *customerService) Update(ctx context.Context, customer *crmDomain.Customer) error {
+	if err := customer.NormalizeAddress(); err != nil {
+		return err
+	}
+
 	// Get old customer for audit
-	oldCustomer, err := s.repo.GetByID(ctx, customer.ID)
+	oldCustomer, err := s.repo.GetByID(ctx, customer.TenantID, customer.ID)
 	if err != nil {
-		return fmt.Errorf("failed to get old customer: %w", err)
+		return err
+	}
+	if err := customer.NormalizeTaxIDs(oldCustomer); err != nil {
+		return err
+	}
+	if err := customer.NormalizePaymentTerms(oldCustomer); err != nil {
+		return err
 	}
 
 	// Update customer
 	if err := s.repo.Update(ctx, customer); err != nil {
-		return fmt.Errorf("failed to update customer: %w", err)
+		return err
 	}
 
 	// Audit log
-	userID := uuid.Nil
 	auditCtx := &auditDomain.AuditContext{
-		UserID:   &userID, // TODO: Get from context
-		TenantID: &customer.TenantID,
+		TenantID: &customer.TenantID, // The user is the request's
 	}
 
 	entityIDStr := customer.ID.String()
@@ -116,24 +295,22 @@ func (s *customerService) Update(ctx context.Context, customer *crmDomain.Custom
 	return nil
 }
 
-// Delete deletes a customer
-func (s *customerService) Delete(ctx context.Context, id uuid.UUID) error {
+// Delete deletes a tenant's customer
+func (s *customerService) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
 	// Get customer for audit
-	customer, err := s.repo.GetByID(ctx, id)
+	customer, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
-		return fmt.Errorf("failed to get customer: %w", err)
+		return err
 	}
 
 	// Delete customer
-	if err := s.repo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete customer: %w", err)
+	if err := s.repo.Delete(ctx, tenantID, id); err != nil {
+		return err
 	}
 
 	// Audit log
-	userID := uuid.Nil
 	auditCtx := &auditDomain.AuditContext{
-		UserID:   &userID, // TODO: Get from context
-		TenantID: &customer.TenantID,
+		TenantID: &customer.TenantID, // The user is the request's
 	}
 
 	entityIDStr := id.String()
@@ -150,6 +327,30 @@ func (s *customerService) Search(ctx context.Context, tenantID uuid.UUID, query
 	return s.repo.Search(ctx, tenantID, query, limit, offset)
 }
 
+// ListByArea retrieves customers in a district, optionally narrowed to a municipality and ward
+func (s *customerService) ListByArea(ctx context.Context, tenantID uuid.UUID, filter crmDomain.AreaFilter, page db.PageRequest) (db.Page[*crmDomain.Customer], error) {
+	if err := filter.Normalize(); err != nil {
+		return db.Page[*crmDomain.Customer]{}, err
+	}
+	customers, total, err := s.repo.ListByArea(ctx, tenantID, filter, page)
+	if err != nil {
+		return db.Page[*crmDomain.Customer]{}, err
+	}
+	return db.NewPage(customers, total, page, func(c *crmDomain.Customer) db.Cursor {
+		// Matches the repository's sort, where a missing address part is empty
+		var address crmDomain.Address
+		if a := c.GetStructuredAddress(); a != nil {
+			address = *a
+		}
+		return db.NewCursor(address.Municipality, address.Ward, address.Tole, c.Name, c.ID)
+	}), nil
+}
+
+// ListByTags retrieves customers by tag
+func (s *customerService) ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*crmDomain.Customer, error) {
+	return s.repo.ListByTags(ctx, tenantID, filter, query, limit, offset)
+}
+
 // Count returns total number of customers
 func (s *customerService) Count(ctx context.Context, tenantID uuid.UUID) (int64, error) {
 	return s.repo.Count(ctx, tenantID)
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/logger"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/google/uuid"
+)
+
+// DeliveryService defines the interface for distributor routes and deliveries
+type DeliveryService interface {
+	CreateRoute(ctx context.Context, route *crmDomain.DeliveryRoute) error
+	UpdateRoute(ctx context.Context, route *crmDomain.DeliveryRoute) error
+	GetRoute(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.DeliveryRoute, error)
+	ListRoutes(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*crmDomain.DeliveryRoute, error)
+
+	// AssignCustomer puts a customer on a route at a position, moving them off any other route
+	AssignCustomer(ctx context.Context, tenantID, routeID, customerID uuid.UUID, sequence int) error
+	RemoveCustomer(ctx context.Context, tenantID, routeID, customerID uuid.UUID) error
+	ListStops(ctx context.Context, tenantID, routeID uuid.UUID) ([]*crmDomain.RouteStop, error)
+
+	// Queue schedules an invoice for delivery; without a route it goes on the customer's route
+	Queue(ctx context.Context, delivery *crmDomain.Delivery) error
+	GetDelivery(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Delivery, error)
+	// Sheet returns a route's delivery sheet for a day: invoices by stop and the van load
+	Sheet(ctx context.Context, tenantID, routeID uuid.UUID, date time.Time) (*crmDomain.DeliverySheet, error)
+	// DriverSheets returns the day's sheets of the active routes a user drives
+	DriverSheets(ctx context.Context, tenantID, driverID uuid.UUID, date time.Time) ([]*crmDomain.DeliverySheet, error)
+
+	// Deliver marks a delivery handed over with the cash collected. Money collected on a
+	// credit invoice is recorded as a khata payment. Only the route's driver or
+	// DeliveryManagerRoles may mark deliveries.
+	Deliver(ctx context.Context, tenantID, id, userID uuid.UUID, role string, collected float64, receivedBy, note *string) (*crmDomain.Delivery, error)
+	// Fail records that a delivery could not be made
+	Fail(ctx context.Context, tenantID, id, userID uuid.UUID, role string, reason string) (*crmDomain.Delivery, error)
+}
+
+// deliveryService implements DeliveryService
+type deliveryService struct {
+	repo         repository.DeliveryRepository
+	customerRepo repository.CustomerRepository
+	khataRepo    repository.KhataRepository
+	dunningRepo  repository.DunningRepository
+}
+
+// NewDeliveryService creates a new delivery service
+func NewDeliveryService(repo repository.DeliveryRepository, customerRepo repository.CustomerRepository, khataRepo repository.KhataRepository, dunningRepo repository.DunningRepository) DeliveryService {
+	return &deliveryService{
+		repo:         repo,
+		customerRepo: customerRepo,
+		khataRepo:    khataRepo,
+		dunningRepo:  dunningRepo,
+	}
+}
+
+// CreateRoute creates a delivery route
+func (s *deliveryService) CreateRoute(ctx context.Context, route *crmDomain.DeliveryRoute) error {
+	if err := route.Validate(); err != nil {
+		return err
+	}
+	return s.repo.CreateRoute(ctx, route)
+}
+
+// UpdateRoute updates a delivery route
+func (s *deliveryService) UpdateRoute(ctx context.Context, route *crmDomain.DeliveryRoute) error {
+	if err := route.Validate(); err != nil {
+		return err
+	}
+	route.UpdatedAt = time.Now()
+	return s.repo.UpdateRoute(ctx, route)
+}
+
+// GetRoute retrieves a delivery route
+func (s *deliveryService) GetRoute(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.DeliveryRoute, error) {
+	return s.repo.GetRoute(ctx, tenantID, id)
+}
+
+// ListRoutes retrieves the tenant's routes
+func (s *deliveryService) ListRoutes(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*crmDomain.DeliveryRoute, error) {
+	return s.repo.ListRoutes(ctx, tenantID, nil, activeOnly)
+}
+
+// AssignCustomer puts a customer on a route
+func (s *deliveryService) AssignCustomer(ctx context.Context, tenantID, routeID, customerID uuid.UUID, sequence int) error {
+	if _, err := s.repo.GetRoute(ctx, tenantID, routeID); err != nil {
+		return err
+	}
+	if err := s.checkCustomer(ctx, tenantID, customerID); err != nil {
+		return err
+	}
+	if sequence < 0 {
+		return fmt.Errorf("sequence cannot be negative")
+	}
+	return s.repo.AssignCustomer(ctx, tenantID, routeID, customerID, sequence)
+}
+
+// RemoveCustomer takes a customer off a route
+func (s *deliveryService) RemoveCustomer(ctx context.Context, tenantID, routeID, customerID uuid.UUID) error {
+	return s.repo.RemoveCustomer(ctx, tenantID, routeID, customerID)
+}
+
+// ListStops retrieves a route's customers in visiting order
+func (s *deliveryService) ListStops(ctx context.Context, tenantID, routeID uuid.UUID) ([]*crmDomain.RouteStop, error) {
+	if _, err := s.repo.GetRoute(ctx, tenantID, routeID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListStops(ctx, tenantID, routeID)
+}
+
+// Queue schedules an invoice on its route
+func (s *deliveryService) Queue(ctx context.Context, delivery *crmDomain.Delivery) error {
+	if err := delivery.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkCustomer(ctx, delivery.TenantID, delivery.CustomerID); err != nil {
+		return err
+	}
+
+	if delivery.RouteID == uuid.Nil {
+		routeID, err := s.repo.CustomerRoute(ctx, delivery.TenantID, delivery.CustomerID)
+		if err != nil {
+			return err
+		}
+		if routeID == nil {
+			return crmDomain.ErrNoRoute
+		}
+		delivery.RouteID = *routeID
+	} else if _, err := s.repo.GetRoute(ctx, delivery.TenantID, delivery.RouteID); err != nil {
+		return err
+	}
+
+	return s.repo.CreateDelivery(ctx, delivery)
+}
+
+// GetDelivery retrieves a delivery with its lines
+func (s *deliveryService) GetDelivery(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Delivery, error) {
+	return s.repo.GetDelivery(ctx, tenantID, id)
+}
+
+// Sheet builds a route's delivery sheet for a day
+func (s *deliveryService) Sheet(ctx context.Context, tenantID, routeID uuid.UUID, date time.Time) (*crmDomain.DeliverySheet, error) {
+	route, err := s.repo.GetRoute(ctx, tenantID, routeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.sheet(ctx, route, date)
+}
+
+// DriverSheets builds the day's sheets for every active route the user drives
+func (s *deliveryService) DriverSheets(ctx context.Context, tenantID, driverID uuid.UUID, date time.Time) ([]*crmDomain.DeliverySheet, error) {
+	routes, err := s.repo.ListRoutes(ctx, tenantID, &driverID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets := []*crmDomain.DeliverySheet{}
+	for _, route := range routes {
+		sheet, err := s.sheet(ctx, route, date)
+		if err != nil {
+			return nil, err
+		}
+		if sheet.Scheduled || sheet.Invoices > 0 {
+			sheets = append(sheets, sheet)
+		}
+	}
+
+	return sheets, nil
+}
+
+// sheet loads a route's stops and the day's deliveries. Customers moved off the
+// route since their invoice was queued are looked up so the stop is still named.
+func (s *deliveryService) sheet(ctx context.Context, route *crmDomain.DeliveryRoute, date time.Time) (*crmDomain.DeliverySheet, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	stops, err := s.repo.ListStops(ctx, route.TenantID, route.ID)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := s.repo.ListDeliveries(ctx, route.TenantID, route.ID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	onRoute := make(map[uuid.UUID]bool, len(stops))
+	for _, stop := range stops {
+		onRoute[stop.CustomerID] = true
+	}
+	for _, delivery := range deliveries {
+		if onRoute[delivery.CustomerID] {
+			continue
+		}
+		onRoute[delivery.CustomerID] = true
+		customer, err := s.customerRepo.GetByID(ctx, route.TenantID, delivery.CustomerID)
+		if err != nil {
+			continue
+		}
+		stops = append(stops, &crmDomain.RouteStop{
+			RouteID:      route.ID,
+			CustomerID:   customer.ID,
+			Sequence:     1 << 30, // After the route's own stops
+			CustomerCode: customer.CustomerCode,
+			CustomerName: customer.Name,
+			Phone:        customer.Phone,
+			Address:      customer.GetAddress(),
+		})
+	}
+
+	return crmDomain.NewDeliverySheet(route, day, stops, deliveries), nil
+}
+
+// Deliver marks a delivery handed over
+func (s *deliveryService) Deliver(ctx context.Context, tenantID, id, userID uuid.UUID, role string, collected float64, receivedBy, note *string) (*crmDomain.Delivery, error) {
+	delivery, err := s.authorized(ctx, tenantID, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := delivery.Deliver(collected, receivedBy, note, &userID); err != nil {
+		return nil, err
+	}
+
+	// Cash collected against a credit invoice settles khata dues
+	var payment *crmDomain.KhataEntry
+	if delivery.OnCredit && delivery.CollectedAmount > 0 {
+		now := time.Now()
+		payment = crmDomain.NewKhataEntry(tenantID, delivery.CustomerID, crmDomain.KhataPayment, delivery.CollectedAmount, now, now)
+		referenceType := "delivery"
+		paymentNote := "Collected on delivery of " + delivery.InvoiceNumber
+		payment.ReferenceType = &referenceType
+		payment.ReferenceID = &delivery.ID
+		payment.Note = &paymentNote
+		payment.CreatedBy = &userID
+	}
+
+	if err := s.repo.Complete(ctx, delivery, payment); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "DELIVER_INVOICE", delivery, &userID)
+	if payment != nil {
+		s.stopDunning(ctx, tenantID, delivery.CustomerID)
+	}
+	return delivery, nil
+}
+
+// Fail records a failed delivery
+func (s *deliveryService) Fail(ctx context.Context, tenantID, id, userID uuid.UUID, role string, reason string) (*crmDomain.Delivery, error) {
+	delivery, err := s.authorized(ctx, tenantID, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := delivery.Fail(reason, &userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Complete(ctx, delivery, nil); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "FAIL_DELIVERY", delivery, &userID)
+	return delivery, nil
+}
+
+// authorized loads a delivery the user may update: managers any, drivers only on their routes
+func (s *deliveryService) authorized(ctx context.Context, tenantID, id, userID uuid.UUID, role string) (*crmDomain.Delivery, error) {
+	delivery, err := s.repo.GetDelivery(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if crmDomain.CanManageDeliveries(role) {
+		return delivery, nil
+	}
+
+	route, err := s.repo.GetRoute(ctx, tenantID, delivery.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	if route.DriverID == nil || *route.DriverID != userID {
+		return nil, crmDomain.ErrNotRouteDriver
+	}
+
+	return delivery, nil
+}
+
+// checkCustomer checks that the customer belongs to the tenant
+func (s *deliveryService) checkCustomer(ctx context.Context, tenantID, customerID uuid.UUID) error {
+	if _, err := s.customerRepo.GetByID(ctx, tenantID, customerID); err != nil {
+		return crmDomain.ErrCustomerNotFound
+	}
+	return nil
+}
+
+// stopDunning ends reminders once a collection leaves nothing overdue
+func (s *deliveryService) stopDunning(ctx context.Context, tenantID, customerID uuid.UUID) {
+	entries, err := s.khataRepo.CustomerEntries(ctx, tenantID, customerID)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to re-age dues of customer %s after delivery collection: %v", customerID, err))
+		return
+	}
+	if crmDomain.AgeDues(customerID, entries, time.Now()).Overdue > 0 {
+		return
+	}
+	if err := s.dunningRepo.ClearState(ctx, tenantID, customerID); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to stop dunning for customer %s: %v", customerID, err))
+	}
+}
+
+func (s *deliveryService) audit(ctx context.Context, action string, delivery *crmDomain.Delivery, userID *uuid.UUID) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &delivery.TenantID,
+	}
+
+	entityIDStr := delivery.ID.String()
+	audit.Service.Log(ctx, action, "Delivery", &entityIDStr, map[string]interface{}{
+		"invoice_number":   delivery.InvoiceNumber,
+		"customer_id":      delivery.CustomerID,
+		"route_id":         delivery.RouteID,
+		"collected_amount": delivery.CollectedAmount,
+		"status":           delivery.Status,
+	}, auditCtx)
+}
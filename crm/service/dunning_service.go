@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/aceextension/fiscal"
+	"github.com/aceextension/notification"
+	notificationDomain "github.com/aceextension/notification/domain"
+	notificationService "github.com/aceextension/notification/service"
+	"github.com/google/uuid"
+)
+
+// DunningService defines the interface for reminding customers of overdue khata dues
+type DunningService interface {
+	CreateSequence(ctx context.Context, sequence *crmDomain.DunningSequence) error
+	GetSequence(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.DunningSequence, error)
+	ListSequences(ctx context.Context, tenantID uuid.UUID) ([]*crmDomain.DunningSequence, error)
+	UpdateSequence(ctx context.Context, sequence *crmDomain.DunningSequence) error
+	DeleteSequence(ctx context.Context, tenantID, id uuid.UUID) error
+
+	// Run ages every debtor and sends the next due step of the default sequence
+	Run(ctx context.Context, tenantID uuid.UUID, asOf time.Time) (*crmDomain.DunningRun, error)
+	// RunScheduled runs dunning for every tenant with a default sequence
+	RunScheduled(ctx context.Context) error
+
+	ListNotices(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, limit, offset int) ([]*crmDomain.DunningNotice, error)
+	// SetOptOut stops (or resumes) reminders to a customer
+	SetOptOut(ctx context.Context, tenantID, customerID uuid.UUID, optOut bool) (*crmDomain.Customer, error)
+}
+
+// dunningService implements DunningService
+type dunningService struct {
+	repo         repository.DunningRepository
+	khataRepo    repository.KhataRepository
+	customerRepo repository.CustomerRepository
+}
+
+// NewDunningService creates a new dunning service
+func NewDunningService(repo repository.DunningRepository, khataRepo repository.KhataRepository, customerRepo repository.CustomerRepository) DunningService {
+	return &dunningService{
+		repo:         repo,
+		khataRepo:    khataRepo,
+		customerRepo: customerRepo,
+	}
+}
+
+// CreateSequence creates a dunning sequence
+func (s *dunningService) CreateSequence(ctx context.Context, sequence *crmDomain.DunningSequence) error {
+	if err := sequence.Validate(); err != nil {
+		return err
+	}
+	return s.repo.CreateSequence(ctx, sequence)
+}
+
+// GetSequence retrieves a dunning sequence
+func (s *dunningService) GetSequence(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.DunningSequence, error) {
+	return s.repo.GetSequence(ctx, tenantID, id)
+}
+
+// ListSequences returns the tenant's dunning sequences
+func (s *dunningService) ListSequences(ctx context.Context, tenantID uuid.UUID) ([]*crmDomain.DunningSequence, error) {
+	return s.repo.ListSequences(ctx, tenantID)
+}
+
+// UpdateSequence updates a dunning sequence and its steps
+func (s *dunningService) UpdateSequence(ctx context.Context, sequence *crmDomain.DunningSequence) error {
+	if err := sequence.Validate(); err != nil {
+		return err
+	}
+	sequence.UpdatedAt = time.Now()
+	return s.repo.UpdateSequence(ctx, sequence)
+}
+
+// DeleteSequence deletes a dunning sequence
+func (s *dunningService) DeleteSequence(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.DeleteSequence(ctx, tenantID, id)
+}
+
+// Run checks every customer with a balance against the default sequence. Customers whose
+// overdue dues have been paid (or fall under the sequence minimum) have their dunning stopped.
+func (s *dunningService) Run(ctx context.Context, tenantID uuid.UUID, asOf time.Time) (*crmDomain.DunningRun, error) {
+	run := &crmDomain.DunningRun{TenantID: tenantID, RanAt: time.Now()}
+
+	sequence, err := s.repo.GetDefaultSequence(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if sequence == nil {
+		return run, nil
+	}
+
+	entries, err := s.khataRepo.OpenEntries(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := s.repo.ListStates(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Customers who have paid off their balance entirely no longer appear among open entries
+	for customerID := range states {
+		if _, ok := entries[customerID]; !ok {
+			s.stop(ctx, tenantID, customerID, run)
+		}
+	}
+
+	for customerID, customerEntries := range entries {
+		run.CustomersChecked++
+		dues := crmDomain.AgeDues(customerID, customerEntries, asOf)
+		state := states[customerID]
+
+		if dues.Overdue <= 0 || dues.Overdue < sequence.MinOverdue {
+			if state != nil {
+				s.stop(ctx, tenantID, customerID, run)
+			}
+			continue
+		}
+
+		lastPosition := 0
+		if state != nil && state.SequenceID == sequence.ID {
+			lastPosition = state.LastPosition
+		}
+		step := sequence.DueStep(dues.DaysOverdue, lastPosition)
+		if step == nil {
+			continue
+		}
+
+		customer, err := s.customerRepo.GetByID(ctx, tenantID, customerID)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Dunning: failed to load customer %s: %v", customerID, err))
+			run.Failed++
+			continue
+		}
+		if customer.IsDunningOptOut() {
+			run.OptedOut++
+			continue
+		}
+		dues.CustomerCode = customer.CustomerCode
+		dues.CustomerName = customer.Name
+
+		notice := crmDomain.NewDunningNotice(sequence, step, dues)
+		s.send(ctx, customer, step, dues, notice)
+		if err := s.repo.CreateNotice(ctx, notice); err != nil {
+			logger.Log.Error(fmt.Sprintf("Dunning: failed to record notice for customer %s: %v", customerID, err))
+		}
+
+		switch notice.Status {
+		case crmDomain.DunningNoticeSent:
+			run.NoticesSent++
+		case crmDomain.DunningNoticeSkipped:
+			run.Skipped++
+		default:
+			// Retried on the next run
+			run.Failed++
+			continue
+		}
+
+		// A skipped step still advances, so a customer with no phone is not re-checked daily for it
+		if state == nil || state.SequenceID != sequence.ID {
+			state = &crmDomain.DunningState{
+				TenantID:   tenantID,
+				CustomerID: customerID,
+				SequenceID: sequence.ID,
+				StartedAt:  notice.CreatedAt,
+			}
+		}
+		state.LastPosition = step.Position
+		state.LastSentAt = notice.CreatedAt
+		if err := s.repo.SaveState(ctx, state); err != nil {
+			logger.Log.Error(fmt.Sprintf("Dunning: failed to save progress for customer %s: %v", customerID, err))
+		}
+	}
+
+	return run, nil
+}
+
+// send delivers the step's message through the notification module and records the outcome on the notice
+func (s *dunningService) send(ctx context.Context, customer *crmDomain.Customer, step *crmDomain.DunningStep, dues *crmDomain.CustomerDues, notice *crmDomain.DunningNotice) {
+	recipient := customer.Phone
+	if step.Channel == crmDomain.DunningChannelEmail {
+		recipient = customer.Email
+	}
+	if recipient == nil || strings.TrimSpace(*recipient) == "" {
+		reason := fmt.Sprintf("customer has no %s contact", strings.ToLower(step.Channel))
+		notice.Status = crmDomain.DunningNoticeSkipped
+		notice.Reason = &reason
+		return
+	}
+	notice.Recipient = recipient
+
+	fail := func(err error) {
+		reason := err.Error()
+		notice.Status = crmDomain.DunningNoticeFailed
+		notice.Reason = &reason
+		logger.Log.Error(fmt.Sprintf("Dunning: failed to send step %d to customer %s: %v", step.Position, customer.ID, err))
+	}
+
+	if notification.Service == nil {
+		fail(fmt.Errorf("notification module is not initialized"))
+		return
+	}
+
+	channel := notificationDomain.ChannelType(step.Channel)
+	template, err := notification.TemplateRepo.GetByCode(ctx, customer.TenantID, step.TemplateCode, channel)
+	if err != nil {
+		fail(fmt.Errorf("template %s: %w", step.TemplateCode, err))
+		return
+	}
+
+	oldestDueDate := ""
+	if dues.OldestDueDate != nil {
+		oldestDueDate = dues.OldestDueDate.Format("2006-01-02")
+	}
+	referenceType := "DUNNING"
+	sent, err := notification.Service.Send(ctx, notificationService.SendRequest{
+		TenantID:   customer.TenantID,
+		Channel:    channel,
+		Recipient:  *recipient,
+		TemplateID: &template.ID,
+		Variables: map[string]interface{}{
+			"customerName":  customer.Name,
+			"outstanding":   fmt.Sprintf("%.2f", dues.Outstanding),
+			"overdue":       fmt.Sprintf("%.2f", dues.Overdue),
+			"daysOverdue":   dues.DaysOverdue,
+			"oldestDueDate": oldestDueDate,
+		},
+		Priority:      notificationDomain.PriorityLow,
+		CustomerID:    &customer.ID,
+		ReferenceType: &referenceType,
+		ReferenceID:   &notice.ID,
+	})
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	notice.Status = crmDomain.DunningNoticeSent
+	notice.NotificationID = &sent.ID
+}
+
+// stop ends a customer's dunning sequence
+func (s *dunningService) stop(ctx context.Context, tenantID, customerID uuid.UUID, run *crmDomain.DunningRun) {
+	if err := s.repo.ClearState(ctx, tenantID, customerID); err != nil {
+		logger.Log.Error(fmt.Sprintf("Dunning: failed to stop sequence for customer %s: %v", customerID, err))
+		return
+	}
+	run.Stopped++
+}
+
+// RunScheduled runs dunning for each tenant with a default sequence; one tenant's failure does not stop the rest.
+// Tenants closed today for their weekend or a holiday are skipped, so reminders wait for the next working day.
+func (s *dunningService) RunScheduled(ctx context.Context) error {
+	tenants, err := s.repo.ListTenantsWithSequences(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, tenantID := range tenants {
+		tenantCtx := db.WithTenantID(ctx, tenantID)
+		if !fiscal.WorkingCalendar(tenantCtx, tenantID).IsWorkingDay(now) {
+			continue
+		}
+
+		run, err := s.Run(tenantCtx, tenantID, now)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Dunning run failed for tenant %s: %v", tenantID, err))
+			continue
+		}
+		logger.Log.Info(fmt.Sprintf("Dunning run for tenant %s: %d checked, %d sent, %d skipped, %d failed, %d opted out, %d stopped",
+			tenantID, run.CustomersChecked, run.NoticesSent, run.Skipped, run.Failed, run.OptedOut, run.Stopped))
+	}
+
+	return nil
+}
+
+// ListNotices returns dunning notices, newest first
+func (s *dunningService) ListNotices(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, limit, offset int) ([]*crmDomain.DunningNotice, error) {
+	return s.repo.ListNotices(ctx, tenantID, customerID, limit, offset)
+}
+
+// SetOptOut records whether a customer should receive dues reminders
+func (s *dunningService) SetOptOut(ctx context.Context, tenantID, customerID uuid.UUID, optOut bool) (*crmDomain.Customer, error) {
+	customer, err := s.customerRepo.GetByID(ctx, tenantID, customerID)
+	if err != nil {
+		return nil, crmDomain.ErrCustomerNotFound
+	}
+
+	customer.SetDunningOptOut(optOut)
+	customer.UpdatedAt = time.Now()
+	if err := s.customerRepo.Update(ctx, customer); err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/core/logger"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/aceextension/fiscal"
+	"github.com/google/uuid"
+)
+
+// KhataService defines the interface for the customer credit ledger (khata) and dues aging
+type KhataService interface {
+	// RecordCharge adds goods given on credit; sales post their credit portion here.
+	// Without a due date the customer's payment terms apply; it returns the charges recorded.
+	RecordCharge(ctx context.Context, entry *crmDomain.KhataEntry) ([]*crmDomain.KhataEntry, error)
+	// RecordPayment records money received; once nothing is overdue the customer's dunning stops
+	RecordPayment(ctx context.Context, entry *crmDomain.KhataEntry) error
+	ListEntries(ctx context.Context, tenantID, customerID uuid.UUID, limit, offset int) ([]*crmDomain.KhataEntry, error)
+
+	// CustomerDues returns what a customer owes, aged as of asOf, for display at the counter
+	CustomerDues(ctx context.Context, tenantID, customerID uuid.UUID, asOf time.Time) (*crmDomain.CustomerDues, error)
+	// AgingReport ages every customer with a balance
+	AgingReport(ctx context.Context, tenantID uuid.UUID, asOf time.Time) (*crmDomain.AgingReport, error)
+}
+
+// khataService implements KhataService
+type khataService struct {
+	repo         repository.KhataRepository
+	customerRepo repository.CustomerRepository
+	dunningRepo  repository.DunningRepository
+}
+
+// NewKhataService creates a new khata service
+func NewKhataService(repo repository.KhataRepository, customerRepo repository.CustomerRepository, dunningRepo repository.DunningRepository) KhataService {
+	return &khataService{
+		repo:         repo,
+		customerRepo: customerRepo,
+		dunningRepo:  dunningRepo,
+	}
+}
+
+// RecordCharge records a credit sale against the customer. A charge without a due date
+// falls due on the customer's payment terms; installment terms record one charge per installment.
+func (s *khataService) RecordCharge(ctx context.Context, entry *crmDomain.KhataEntry) ([]*crmDomain.KhataEntry, error) {
+	entry.Kind = crmDomain.KhataCharge
+	onTerms := entry.DueDate.IsZero()
+	if onTerms {
+		entry.DueDate = entry.EntryDate
+	}
+	customer, err := s.prepare(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := fiscal.WorkingCalendar(ctx, entry.TenantID)
+	if onTerms {
+		terms := customer.GetPaymentTerms()
+		if terms == nil {
+			terms = crmDomain.DueOnReceipt()
+		}
+		entries := entry.SplitBySchedule(terms.Schedule(entry.EntryDate, entry.Amount, calendar))
+		if err := s.repo.CreateEntries(ctx, entries); err != nil {
+			return nil, err
+		}
+		return entries, nil
+	}
+
+	// Credit falling due on a weekend or holiday is due on the next working day
+	if entry.DueLater() {
+		entry.DueDate = calendar.NextWorkingDay(entry.DueDate)
+	}
+
+	if err := s.repo.CreateEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+	return []*crmDomain.KhataEntry{entry}, nil
+}
+
+// RecordPayment records a payment and ends the customer's dunning once their overdue dues are cleared
+func (s *khataService) RecordPayment(ctx context.Context, entry *crmDomain.KhataEntry) error {
+	entry.Kind = crmDomain.KhataPayment
+	entry.DueDate = entry.EntryDate
+	if _, err := s.prepare(ctx, entry); err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateEntry(ctx, entry); err != nil {
+		return err
+	}
+
+	entries, err := s.repo.CustomerEntries(ctx, entry.TenantID, entry.CustomerID)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to re-age dues of customer %s after payment: %v", entry.CustomerID, err))
+		return nil
+	}
+	if crmDomain.AgeDues(entry.CustomerID, entries, time.Now()).Overdue == 0 {
+		if err := s.dunningRepo.ClearState(ctx, entry.TenantID, entry.CustomerID); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to stop dunning for customer %s: %v", entry.CustomerID, err))
+		}
+	}
+
+	return nil
+}
+
+// prepare validates the entry and that the customer belongs to the tenant
+func (s *khataService) prepare(ctx context.Context, entry *crmDomain.KhataEntry) (*crmDomain.Customer, error) {
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+
+	customer, err := s.customerRepo.GetByID(ctx, entry.TenantID, entry.CustomerID)
+	if err != nil {
+		return nil, crmDomain.ErrCustomerNotFound
+	}
+
+	return customer, nil
+}
+
+// ListEntries returns a customer's khata, newest first
+func (s *khataService) ListEntries(ctx context.Context, tenantID, customerID uuid.UUID, limit, offset int) ([]*crmDomain.KhataEntry, error) {
+	return s.repo.ListEntries(ctx, tenantID, customerID, limit, offset)
+}
+
+// CustomerDues ages a customer's open charges
+func (s *khataService) CustomerDues(ctx context.Context, tenantID, customerID uuid.UUID, asOf time.Time) (*crmDomain.CustomerDues, error) {
+	customer, err := s.customerRepo.GetByID(ctx, tenantID, customerID)
+	if err != nil {
+		return nil, crmDomain.ErrCustomerNotFound
+	}
+
+	entries, err := s.repo.CustomerEntries(ctx, tenantID, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	dues := crmDomain.AgeDues(customerID, entries, asOf)
+	dues.CustomerCode = customer.CustomerCode
+	dues.CustomerName = customer.Name
+	return dues, nil
+}
+
+// AgingReport ages the dues of every customer with a balance
+func (s *khataService) AgingReport(ctx context.Context, tenantID uuid.UUID, asOf time.Time) (*crmDomain.AgingReport, error) {
+	debtors, err := s.repo.ListDebtors(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.OpenEntries(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	customers := make([]*crmDomain.CustomerDues, 0, len(debtors))
+	for _, debtor := range debtors {
+		dues := crmDomain.AgeDues(debtor.CustomerID, entries[debtor.CustomerID], asOf)
+		dues.CustomerCode = debtor.CustomerCode
+		dues.CustomerName = debtor.CustomerName
+		customers = append(customers, dues)
+	}
+
+	return crmDomain.NewAgingReport(customers, asOf), nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	crmDomain "github.com/aceextension/crm/domain"
+	filesDomain "github.com/aceextension/files/domain"
+	"github.com/aceextension/files/ocr"
+)
+
+// OCRBillExtractor implements BillExtractor with the files module's OCR engine
+// and bill field rules
+type OCRBillExtractor struct {
+	engine ocr.Engine
+}
+
+// NewOCRBillExtractor creates a bill extractor for an OCR engine
+func NewOCRBillExtractor(engine ocr.Engine) *OCRBillExtractor {
+	return &OCRBillExtractor{engine: engine}
+}
+
+// ExtractBill recognises the bill's text and reads its fields. The confidence is
+// that of the least certain field, since a reviewer checks the draft as a whole.
+func (e *OCRBillExtractor) ExtractBill(ctx context.Context, contentType string, content []byte) (*crmDomain.BillExtraction, error) {
+	result, err := e.engine.Recognize(ctx, contentType, content)
+	if err != nil {
+		return nil, err
+	}
+
+	extraction := &crmDomain.BillExtraction{Confidence: result.Confidence}
+	for _, field := range ocr.BillFields(result) {
+		value := field.Value
+		switch field.Name {
+		case filesDomain.FieldBillNumber:
+			extraction.BillNumber = &value
+		case filesDomain.FieldBillDate:
+			if date, err := time.Parse("2006-01-02", value); err == nil {
+				extraction.BillDate = &date
+			}
+		case filesDomain.FieldPANNumber:
+			extraction.SupplierPAN = &value
+		case filesDomain.FieldSubTotal:
+			extraction.SubTotal = parseAmount(value)
+		case filesDomain.FieldVATAmount:
+			extraction.VATAmount = parseAmount(value)
+		case filesDomain.FieldTotalAmount:
+			extraction.TotalAmount = parseAmount(value)
+		}
+		if field.Confidence < extraction.Confidence {
+			extraction.Confidence = field.Confidence
+		}
+	}
+
+	return extraction, nil
+}
+
+func parseAmount(value string) *float64 {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &amount
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/aceextension/fiscal"
+	"github.com/google/uuid"
+)
+
+// PaymentTermsService resolves payment terms and dates what falls due under them
+type PaymentTermsService interface {
+	// CustomerTerms returns the customer's default terms; due on receipt when none are set
+	CustomerTerms(ctx context.Context, tenantID, customerID uuid.UUID) (*crmDomain.PaymentTerms, error)
+	// SupplierTerms returns the supplier's default terms; due on receipt when none are set
+	SupplierTerms(ctx context.Context, tenantID, supplierID uuid.UUID) (*crmDomain.PaymentTerms, error)
+	// Schedule splits an amount dated date into installments under the terms,
+	// moving due dates off the tenant's weekends and holidays
+	Schedule(ctx context.Context, tenantID uuid.UUID, terms *crmDomain.PaymentTerms, date time.Time, amount float64) ([]crmDomain.DueInstallment, error)
+}
+
+// paymentTermsService implements PaymentTermsService
+type paymentTermsService struct {
+	customerRepo repository.CustomerRepository
+	supplierRepo repository.SupplierRepository
+}
+
+// NewPaymentTermsService creates a new payment terms service
+func NewPaymentTermsService(customerRepo repository.CustomerRepository, supplierRepo repository.SupplierRepository) PaymentTermsService {
+	return &paymentTermsService{
+		customerRepo: customerRepo,
+		supplierRepo: supplierRepo,
+	}
+}
+
+// CustomerTerms returns the customer's default terms
+func (s *paymentTermsService) CustomerTerms(ctx context.Context, tenantID, customerID uuid.UUID) (*crmDomain.PaymentTerms, error) {
+	customer, err := s.customerRepo.GetByID(ctx, tenantID, customerID)
+	if err != nil {
+		return nil, crmDomain.ErrCustomerNotFound
+	}
+	if terms := customer.GetPaymentTerms(); terms != nil {
+		return terms, nil
+	}
+	return crmDomain.DueOnReceipt(), nil
+}
+
+// SupplierTerms returns the supplier's default terms
+func (s *paymentTermsService) SupplierTerms(ctx context.Context, tenantID, supplierID uuid.UUID) (*crmDomain.PaymentTerms, error) {
+	supplier, err := s.supplierRepo.GetByID(ctx, tenantID, supplierID)
+	if err != nil {
+		return nil, crmDomain.ErrSupplierNotFound
+	}
+	if terms := supplier.GetPaymentTerms(); terms != nil {
+		return terms, nil
+	}
+	return crmDomain.DueOnReceipt(), nil
+}
+
+// Schedule dates the installments of an amount
+func (s *paymentTermsService) Schedule(ctx context.Context, tenantID uuid.UUID, terms *crmDomain.PaymentTerms, date time.Time, amount float64) ([]crmDomain.DueInstallment, error) {
+	if err := terms.Validate(); err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", crmDomain.ErrInvalidPaymentTerms)
+	}
+
+	return terms.Schedule(date, amount, fiscal.WorkingCalendar(ctx, tenantID)), nil
+}
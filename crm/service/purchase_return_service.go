@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aceextension/core/logger"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/google/uuid"
+)
+
+// PurchaseReturnService defines the interface for goods returned to suppliers (RMAs)
+type PurchaseReturnService interface {
+	// Create checks a return against its receipt and what was already returned on it,
+	// then saves it. A return against a payable receipt carries a debit note, which is
+	// posted to the ledger; the goods are taken out of stock.
+	Create(ctx context.Context, ret *crmDomain.PurchaseReturn) error
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.PurchaseReturn, error)
+	List(ctx context.Context, tenantID uuid.UUID, filter crmDomain.PurchaseReturnFilter) ([]*crmDomain.PurchaseReturn, error)
+
+	// QualityReport scores each supplier by the share of purchases from..to returned
+	// as damaged, expired, wrong or defective, worst first
+	QualityReport(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*crmDomain.SupplierQuality, error)
+
+	// RegisterReceiptType lets a module's receipts be returned against
+	RegisterReceiptType(receiptType string, receipts ReturnReceipts)
+	SetDebitNoteJournal(journal DebitNoteJournal)
+	SetReturnStock(stock ReturnStock)
+}
+
+// ReturnReceipts loads one kind of purchase receipt for returns. Confirmed purchase
+// bills are registered by Init; goods receipts register from the purchasing module.
+type ReturnReceipts interface {
+	// ReturnableReceipt loads a receipt; ErrPurchaseReceiptNotFound if the tenant has none
+	ReturnableReceipt(ctx context.Context, tenantID, receiptID uuid.UUID) (*crmDomain.ReturnableReceipt, error)
+	// PurchasedBySupplier sums receipts dated from..to per supplier, before VAT
+	PurchasedBySupplier(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (map[uuid.UUID]float64, error)
+}
+
+// DebitNoteJournal books a return's debit note in the general ledger, reducing the
+// supplier payable. Implemented outside CRM by the process that wires accounting in;
+// it returns the journal entry ID it created.
+type DebitNoteJournal interface {
+	PostDebitNote(ctx context.Context, ret *crmDomain.PurchaseReturn) (uuid.UUID, error)
+}
+
+// ReturnStock takes returned goods out of stock. Implemented by the inventory side
+// and set after Init.
+type ReturnStock interface {
+	IssueReturn(ctx context.Context, ret *crmDomain.PurchaseReturn) error
+}
+
+// purchaseReturnService implements PurchaseReturnService
+type purchaseReturnService struct {
+	repo         repository.PurchaseReturnRepository
+	supplierRepo repository.SupplierRepository
+	journal      DebitNoteJournal
+	stock        ReturnStock
+
+	mu       sync.RWMutex
+	receipts map[string]ReturnReceipts
+}
+
+// NewPurchaseReturnService creates a new purchase return service
+func NewPurchaseReturnService(repo repository.PurchaseReturnRepository, supplierRepo repository.SupplierRepository) PurchaseReturnService {
+	return &purchaseReturnService{
+		repo:         repo,
+		supplierRepo: supplierRepo,
+		receipts:     map[string]ReturnReceipts{},
+	}
+}
+
+// RegisterReceiptType sets how receipts of a type are loaded
+func (s *purchaseReturnService) RegisterReceiptType(receiptType string, receipts ReturnReceipts) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.receipts[receiptType] = receipts
+}
+
+// SetDebitNoteJournal sets the ledger that debit notes are posted to
+func (s *purchaseReturnService) SetDebitNoteJournal(journal DebitNoteJournal) {
+	s.journal = journal
+}
+
+// SetReturnStock sets where returned goods are taken out of stock
+func (s *purchaseReturnService) SetReturnStock(stock ReturnStock) {
+	s.stock = stock
+}
+
+// receiptType returns the loader registered for a receipt type
+func (s *purchaseReturnService) receiptType(receiptType string) (ReturnReceipts, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	receipts, ok := s.receipts[receiptType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", crmDomain.ErrUnknownReceiptType, receiptType)
+	}
+	return receipts, nil
+}
+
+// Create validates and saves a return, then posts its debit note and stock movement
+func (s *purchaseReturnService) Create(ctx context.Context, ret *crmDomain.PurchaseReturn) error {
+	receipts, err := s.receiptType(ret.ReceiptType)
+	if err != nil {
+		return err
+	}
+
+	receipt, err := receipts.ReturnableReceipt(ctx, ret.TenantID, ret.ReceiptID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Create(ctx, ret, func(returnedByLine map[uuid.UUID]float64, returnedAmount float64) (bool, error) {
+		if err := ret.Against(receipt, returnedByLine, returnedAmount); err != nil {
+			return false, err
+		}
+		return ret.RaisesDebitNote(receipt), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.postDebitNote(ctx, ret)
+	s.issueStock(ctx, ret)
+	return nil
+}
+
+// postDebitNote books the debit note in the ledger. The return is already committed,
+// so a ledger failure is logged and leaves the return unlinked for follow-up.
+func (s *purchaseReturnService) postDebitNote(ctx context.Context, ret *crmDomain.PurchaseReturn) {
+	if s.journal == nil || ret.DebitNoteNumber == nil {
+		return
+	}
+
+	entryID, err := s.journal.PostDebitNote(ctx, ret)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to post debit note %s to ledger: %v", *ret.DebitNoteNumber, err))
+		return
+	}
+
+	ret.JournalEntryID = &entryID
+	if err := s.repo.SetJournalEntry(ctx, ret.ID, entryID); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to link debit note %s to journal %s: %v", *ret.DebitNoteNumber, entryID, err))
+	}
+}
+
+// issueStock takes the returned goods out of stock. A failure is logged and leaves
+// the return without StockPostedAt for follow-up.
+func (s *purchaseReturnService) issueStock(ctx context.Context, ret *crmDomain.PurchaseReturn) {
+	if s.stock == nil {
+		return
+	}
+
+	if err := s.stock.IssueReturn(ctx, ret); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to issue stock for purchase return %s: %v", ret.ReturnNumber, err))
+		return
+	}
+
+	postedAt := time.Now()
+	ret.StockPostedAt = &postedAt
+	if err := s.repo.SetStockPosted(ctx, ret.ID, postedAt); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to mark purchase return %s stock posted: %v", ret.ReturnNumber, err))
+	}
+}
+
+// Get retrieves a purchase return with its lines
+func (s *purchaseReturnService) Get(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.PurchaseReturn, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// List returns purchase returns, newest first
+func (s *purchaseReturnService) List(ctx context.Context, tenantID uuid.UUID, filter crmDomain.PurchaseReturnFilter) ([]*crmDomain.PurchaseReturn, error) {
+	return s.repo.List(ctx, tenantID, filter)
+}
+
+// QualityReport combines purchases from every registered receipt type with the
+// reasons goods were returned
+func (s *purchaseReturnService) QualityReport(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*crmDomain.SupplierQuality, error) {
+	qualities := map[uuid.UUID]*crmDomain.SupplierQuality{}
+	quality := func(supplierID uuid.UUID) *crmDomain.SupplierQuality {
+		q, ok := qualities[supplierID]
+		if !ok {
+			q = &crmDomain.SupplierQuality{SupplierID: supplierID, Reasons: []crmDomain.ReturnReasonStat{}}
+			qualities[supplierID] = q
+		}
+		return q
+	}
+
+	s.mu.RLock()
+	loaders := make([]ReturnReceipts, 0, len(s.receipts))
+	for _, receipts := range s.receipts {
+		loaders = append(loaders, receipts)
+	}
+	s.mu.RUnlock()
+
+	for _, receipts := range loaders {
+		purchased, err := receipts.PurchasedBySupplier(ctx, tenantID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for supplierID, amount := range purchased {
+			quality(supplierID).Purchased += amount
+		}
+	}
+
+	stats, err := s.repo.ReasonStats(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range stats {
+		q := quality(stat.SupplierID)
+		q.Returned += stat.Amount
+		if stat.Reason.QualityIssue() {
+			q.QualityReturned += stat.Amount
+		}
+		q.Reasons = append(q.Reasons, *stat)
+	}
+
+	report := make([]*crmDomain.SupplierQuality, 0, len(qualities))
+	for _, q := range qualities {
+		if supplier, err := s.supplierRepo.GetByID(ctx, tenantID, q.SupplierID); err == nil {
+			q.SupplierCode, q.SupplierName = supplier.SupplierCode, supplier.Name
+		}
+		q.Rate()
+		report = append(report, q)
+	}
+
+	// Worst score first; suppliers with returns but no purchases in the period last
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if (a.Score == nil) != (b.Score == nil) {
+			return b.Score == nil
+		}
+		if a.Score != nil && *a.Score != *b.Score {
+			return *a.Score < *b.Score
+		}
+		return a.SupplierName < b.SupplierName
+	})
+
+	return report, nil
+}
+
+// purchaseBillReceipts returns goods against confirmed purchase bills from bill capture
+type purchaseBillReceipts struct {
+	repo repository.BillCaptureRepository
+}
+
+// NewPurchaseBillReceipts makes confirmed purchase bills returnable. Bills carry no
+// lines, so returns name the products and costs and are capped by the bill total.
+func NewPurchaseBillReceipts(repo repository.BillCaptureRepository) ReturnReceipts {
+	return purchaseBillReceipts{repo: repo}
+}
+
+// ReturnableReceipt loads a confirmed bill with a supplier
+func (r purchaseBillReceipts) ReturnableReceipt(ctx context.Context, tenantID, receiptID uuid.UUID) (*crmDomain.ReturnableReceipt, error) {
+	draft, err := r.repo.GetDraft(ctx, tenantID, receiptID)
+	if err != nil || draft.Status != crmDomain.BillDraftConfirmed || draft.SupplierID == nil {
+		return nil, crmDomain.ErrPurchaseReceiptNotFound
+	}
+
+	receipt := &crmDomain.ReturnableReceipt{
+		Type:       crmDomain.ReceiptTypePurchaseBill,
+		ID:         draft.ID,
+		SupplierID: *draft.SupplierID,
+		Payable:    true,
+	}
+	if draft.BillNumber != nil {
+		receipt.Number = *draft.BillNumber
+	}
+	if draft.TotalAmount != nil {
+		receipt.Amount = *draft.TotalAmount
+	}
+	if draft.SubTotal != nil && draft.VATAmount != nil && *draft.SubTotal > 0 {
+		receipt.VATRate = *draft.VATAmount / *draft.SubTotal * 100
+	}
+	return receipt, nil
+}
+
+// PurchasedBySupplier sums confirmed bills per supplier
+func (r purchaseBillReceipts) PurchasedBySupplier(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (map[uuid.UUID]float64, error) {
+	return r.repo.ConfirmedTotalsBySupplier(ctx, tenantID, from, to)
+}
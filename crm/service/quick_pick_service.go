@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/core/cache"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// quickPickListSize is the number of customers shown in the POS quick-pick list
+	quickPickListSize = 20
+	quickPickCacheTTL = 5 * time.Minute
+)
+
+// QuickPickService defines the interface for per-user favorite and recently viewed customers
+type QuickPickService interface {
+	RecordView(ctx context.Context, tenantID, userID, customerID uuid.UUID) error
+	Pin(ctx context.Context, tenantID, userID, customerID uuid.UUID, position int) error
+	Unpin(ctx context.Context, tenantID, userID, customerID uuid.UUID) error
+	List(ctx context.Context, tenantID, userID uuid.UUID) ([]*crmDomain.CustomerQuickPick, error)
+}
+
+// quickPickKey identifies a user's list within a tenant
+type quickPickKey struct {
+	tenantID uuid.UUID
+	userID   uuid.UUID
+}
+
+// quickPickService implements QuickPickService
+type quickPickService struct {
+	repo         repository.QuickPickRepository
+	customerRepo repository.CustomerRepository
+	cache        *cache.TTLCache[quickPickKey, []*crmDomain.CustomerQuickPick]
+}
+
+// NewQuickPickService creates a new quick-pick service
+func NewQuickPickService(repo repository.QuickPickRepository, customerRepo repository.CustomerRepository) QuickPickService {
+	return &quickPickService{
+		repo:         repo,
+		customerRepo: customerRepo,
+		cache:        cache.New[quickPickKey, []*crmDomain.CustomerQuickPick](quickPickCacheTTL, 10000),
+	}
+}
+
+// RecordView records a customer view and keeps the recent list bounded
+func (s *quickPickService) RecordView(ctx context.Context, tenantID, userID, customerID uuid.UUID) error {
+	if err := s.repo.RecordView(ctx, tenantID, userID, customerID); err != nil {
+		return err
+	}
+	if err := s.repo.TrimRecent(ctx, tenantID, userID, crmDomain.MaxRecentCustomers); err != nil {
+		return err
+	}
+
+	s.cache.Delete(quickPickKey{tenantID, userID})
+	return nil
+}
+
+// Pin adds a customer to the user's favorites
+func (s *quickPickService) Pin(ctx context.Context, tenantID, userID, customerID uuid.UUID, position int) error {
+	if _, err := s.customerRepo.GetByID(ctx, tenantID, customerID); err != nil {
+		return err
+	}
+
+	if err := s.repo.SetFavorite(ctx, tenantID, userID, customerID, true, position); err != nil {
+		return err
+	}
+
+	s.cache.Delete(quickPickKey{tenantID, userID})
+	return nil
+}
+
+// Unpin removes a customer from the user's favorites
+func (s *quickPickService) Unpin(ctx context.Context, tenantID, userID, customerID uuid.UUID) error {
+	if err := s.repo.SetFavorite(ctx, tenantID, userID, customerID, false, 0); err != nil {
+		return err
+	}
+
+	s.cache.Delete(quickPickKey{tenantID, userID})
+	return nil
+}
+
+// List returns the user's quick-pick customers
+func (s *quickPickService) List(ctx context.Context, tenantID, userID uuid.UUID) ([]*crmDomain.CustomerQuickPick, error) {
+	key := quickPickKey{tenantID, userID}
+	if picks, ok := s.cache.Get(key); ok {
+		return picks, nil
+	}
+
+	picks, err := s.repo.List(ctx, tenantID, userID, quickPickListSize)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(key, picks)
+	return picks, nil
+}
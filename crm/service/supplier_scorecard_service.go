@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/google/uuid"
+)
+
+// SupplierScorecardService defines the interface for supplier performance scorecards
+type SupplierScorecardService interface {
+	// Scorecard rates one supplier on lines ordered from..to; ErrSupplierNotFound if
+	// the tenant has no such supplier
+	Scorecard(ctx context.Context, tenantID, supplierID uuid.UUID, from, to time.Time) (*crmDomain.SupplierScorecard, error)
+	// Ranking rates every supplier with lines ordered from..to, best score first,
+	// optionally only on lines of products in one category
+	Ranking(ctx context.Context, tenantID uuid.UUID, from, to time.Time, categoryID *uuid.UUID) ([]*crmDomain.SupplierScorecard, error)
+
+	// RegisterSource makes a module's order lines count towards scorecards
+	RegisterSource(source crmDomain.SupplyLineSource)
+}
+
+// supplierScorecardService implements SupplierScorecardService
+type supplierScorecardService struct {
+	repo         repository.SupplierScorecardRepository
+	supplierRepo repository.SupplierRepository
+	returns      PurchaseReturnService
+
+	mu      sync.RWMutex
+	sources map[string]crmDomain.SupplyLineSource
+}
+
+// NewSupplierScorecardService creates a new supplier scorecard service. Scorecards
+// stay empty until a module registers a source of order lines; quality scores come
+// from purchase returns.
+func NewSupplierScorecardService(repo repository.SupplierScorecardRepository, supplierRepo repository.SupplierRepository, returns PurchaseReturnService) SupplierScorecardService {
+	return &supplierScorecardService{
+		repo:         repo,
+		supplierRepo: supplierRepo,
+		returns:      returns,
+		sources:      make(map[string]crmDomain.SupplyLineSource),
+	}
+}
+
+// RegisterSource adds a source of order lines, replacing one of the same name
+func (s *supplierScorecardService) RegisterSource(source crmDomain.SupplyLineSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[source.Name] = source
+}
+
+// Scorecard rates one supplier. A supplier without orders in the period gets an
+// empty scorecard with only its quality score.
+func (s *supplierScorecardService) Scorecard(ctx context.Context, tenantID, supplierID uuid.UUID, from, to time.Time) (*crmDomain.SupplierScorecard, error) {
+	supplier, err := s.supplierRepo.GetByID(ctx, tenantID, supplierID)
+	if err != nil {
+		return nil, crmDomain.ErrSupplierNotFound
+	}
+
+	cards, err := s.scorecards(ctx, tenantID, from, to, &supplierID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(cards) > 0 {
+		return cards[0], nil
+	}
+
+	quality, err := s.qualityScores(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	card := crmDomain.NewSupplierScorecard(crmDomain.SupplyStats{SupplierID: supplierID}, quality[supplierID])
+	card.SupplierCode, card.SupplierName = supplier.SupplierCode, supplier.Name
+	return card, nil
+}
+
+// Ranking rates and ranks suppliers; suppliers with nothing to score are listed last, unranked
+func (s *supplierScorecardService) Ranking(ctx context.Context, tenantID uuid.UUID, from, to time.Time, categoryID *uuid.UUID) ([]*crmDomain.SupplierScorecard, error) {
+	cards, err := s.scorecards(ctx, tenantID, from, to, nil, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(cards, func(i, j int) bool {
+		a, b := cards[i], cards[j]
+		if (a.Score == nil) != (b.Score == nil) {
+			return b.Score == nil
+		}
+		if a.Score != nil && *a.Score != *b.Score {
+			return *a.Score > *b.Score
+		}
+		return a.SupplierName < b.SupplierName
+	})
+	for i, card := range cards {
+		if card.Score != nil {
+			card.Rank = i + 1
+		}
+	}
+
+	return cards, nil
+}
+
+// scorecards reads the sources and rates each supplier found in them
+func (s *supplierScorecardService) scorecards(ctx context.Context, tenantID uuid.UUID, from, to time.Time, supplierID, categoryID *uuid.UUID) ([]*crmDomain.SupplierScorecard, error) {
+	s.mu.RLock()
+	sources := make([]crmDomain.SupplyLineSource, 0, len(s.sources))
+	for _, source := range s.sources {
+		sources = append(sources, source)
+	}
+	s.mu.RUnlock()
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	stats, err := s.repo.SupplyStats(ctx, sources, tenantID, from, to, today, supplierID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return []*crmDomain.SupplierScorecard{}, nil
+	}
+
+	quality, err := s.qualityScores(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := make([]*crmDomain.SupplierScorecard, 0, len(stats))
+	for _, stat := range stats {
+		card := crmDomain.NewSupplierScorecard(stat, quality[stat.SupplierID])
+		if supplier, err := s.supplierRepo.GetByID(ctx, tenantID, stat.SupplierID); err == nil {
+			card.SupplierCode, card.SupplierName = supplier.SupplierCode, supplier.Name
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// qualityScores returns each supplier's purchase return score for the period
+func (s *supplierScorecardService) qualityScores(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (map[uuid.UUID]*float64, error) {
+	scores := map[uuid.UUID]*float64{}
+	if s.returns == nil {
+		return scores, nil
+	}
+
+	report, err := s.returns.QualityReport(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, quality := range report {
+		scores[quality.SupplierID] = quality.Score
+	}
+	return scores, nil
+}
@@ -7,21 +7,28 @@ import (
 
 	"github.com/aceextension/audit"
 	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/db"
 	crmDomain "github.com/aceextension/crm/domain"
 	"github.com/aceextension/crm/repository"
 	"github.com/aceextension/fiscal"
+	tagsDomain "github.com/aceextension/tags/domain"
 	"github.com/google/uuid"
 )
 
 // SupplierService defines the interface for supplier operations
 type SupplierService interface {
 	Create(ctx context.Context, supplier *crmDomain.Supplier) error
-	GetByID(ctx context.Context, id uuid.UUID) (*crmDomain.Supplier, error)
+	// GetByID, Update and Delete only reach the tenant's own suppliers; others are ErrSupplierNotFound
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Supplier, error)
 	GetByCode(ctx context.Context, tenantID uuid.UUID, code string) (*crmDomain.Supplier, error)
-	GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*crmDomain.Supplier, error)
+	// List returns a page of suppliers, newest first; a tags filter keeps suppliers carrying them
+	List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) (db.Page[*crmDomain.Supplier], error)
 	Update(ctx context.Context, supplier *crmDomain.Supplier) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
 	Search(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) ([]*crmDomain.Supplier, error)
+	ListByArea(ctx context.Context, tenantID uuid.UUID, filter crmDomain.AreaFilter, page db.PageRequest) (db.Page[*crmDomain.Supplier], error)
+	// ListByTags retrieves suppliers carrying the filter's tags, optionally narrowed by a search query
+	ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*crmDomain.Supplier, error)
 	Count(ctx context.Context, tenantID uuid.UUID) (int64, error)
 }
 
@@ -39,6 +46,16 @@ func NewSupplierService(repo repository.SupplierRepository) SupplierService {
 
 // Create creates a new supplier
 func (s *supplierService) Create(ctx context.Context, supplier *crmDomain.Supplier) error {
+	if err := supplier.NormalizeAddress(); err != nil {
+		return err
+	}
+	if err := supplier.NormalizeTaxIDs(nil); err != nil {
+		return err
+	}
+	if err := supplier.NormalizePaymentTerms(nil); err != nil {
+		return err
+	}
+
 	// Generate supplier code if not provided
 	if supplier.SupplierCode == "" {
 		code, err := s.generateSupplierCode(ctx, supplier.TenantID)
@@ -54,10 +71,8 @@ func (s *supplierService) Create(ctx context.Context, supplier *crmDomain.Suppli
 	}
 
 	// Audit log
-	userID := uuid.Nil
 	auditCtx := &auditDomain.AuditContext{
-		UserID:   &userID, // TODO: Get from context
-		TenantID: &supplier.TenantID,
+		TenantID: &supplier.TenantID, // The user is the request's
 	}
 
 	entityIDStr := supplier.ID.String()
@@ -70,9 +85,9 @@ func (s *supplierService) Create(ctx context.Context, supplier *crmDomain.Suppli
 	return nil
 }
 
-// GetByID retrieves a supplier by ID
-func (s *supplierService) GetByID(ctx context.Context, id uuid.UUID) (*crmDomain.Supplier, error) {
-	return s.repo.GetByID(ctx, id)
+// GetByID retrieves a tenant's supplier by ID
+func (s *supplierService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Supplier, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
 }
 
 // GetByCode retrieves a supplier by supplier code
@@ -80,29 +95,43 @@ func (s *supplierService) GetByCode(ctx context.Context, tenantID uuid.UUID, cod
 	return s.repo.GetByCode(ctx, tenantID, code)
 }
 
-// GetByTenantID retrieves all suppliers for a tenant
-func (s *supplierService) GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*crmDomain.Supplier, error) {
-	return s.repo.GetByTenantID(ctx, tenantID, limit, offset)
+// List returns a page of a tenant's suppliers, newest first
+func (s *supplierService) List(ctx context.Context, tenantID uuid.UUID, tags *tagsDomain.TagFilter, page db.PageRequest) (db.Page[*crmDomain.Supplier], error) {
+	suppliers, total, err := s.repo.List(ctx, tenantID, tags, page)
+	if err != nil {
+		return db.Page[*crmDomain.Supplier]{}, err
+	}
+	return db.NewPage(suppliers, total, page, func(s *crmDomain.Supplier) db.Cursor {
+		return db.NewCursor(s.CreatedAt, s.ID)
+	}), nil
 }
 
 // Update updates a supplier
 func (s *supplierService) Update(ctx context.Context, supplier *crmDomain.Supplier) error {
+	if err := supplier.NormalizeAddress(); err != nil {
+		return err
+	}
+
 	// Get old supplier for audit
-	oldSupplier, err := s.repo.GetByID(ctx, supplier.ID)
+	oldSupplier, err := s.repo.GetByID(ctx, supplier.TenantID, supplier.ID)
 	if err != nil {
-		return fmt.Errorf("failed to get old supplier: %w", err)
+		return err
+	}
+	if err := supplier.NormalizeTaxIDs(oldSupplier); err != nil {
+		return err
+	}
+	if err := supplier.NormalizePaymentTerms(oldSupplier); err != nil {
+		return err
 	}
 
 	// Update supplier
 	if err := s.repo.Update(ctx, supplier); err != nil {
-		return fmt.Errorf("failed to update supplier: %w", err)
+		return err
 	}
 
 	// Audit log
-	userID := uuid.Nil
 	auditCtx := &auditDomain.AuditContext{
-		UserID:   &userID, // TODO: Get from context
-		TenantID: &supplier.TenantID,
+		TenantID: &supplier.TenantID, // The user is the request's
 	}
 
 	entityIDStr := supplier.ID.String()
@@ -116,24 +145,22 @@ func (s *supplierService) Update(ctx context.Context, supplier *crmDomain.Suppli
 	return nil
 }
 
-// Delete deletes a supplier
-func (s *supplierService) Delete(ctx context.Context, id uuid.UUID) error {
+// Delete deletes a tenant's supplier
+func (s *supplierService) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
 	// Get supplier for audit
-	supplier, err := s.repo.GetByID(ctx, id)
+	supplier, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
-		return fmt.Errorf("failed to get supplier: %w", err)
+		return err
 	}
 
 	// Delete supplier
-	if err := s.repo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete supplier: %w", err)
+	if err := s.repo.Delete(ctx, tenantID, id); err != nil {
+		return err
 	}
 
 	// Audit log
-	userID := uuid.Nil
 	auditCtx := &auditDomain.AuditContext{
-		UserID:   &userID, // TODO: Get from context
-		TenantID: &supplier.TenantID,
+		TenantID: &supplier.TenantID, // The user is the request's
 	}
 
 	entityIDStr := id.String()
@@ -150,6 +177,30 @@ func (s *supplierService) Search(ctx context.Context, tenantID uuid.UUID, query
 	return s.repo.Search(ctx, tenantID, query, limit, offset)
 }
 
+// ListByArea retrieves suppliers in a district, optionally narrowed to a municipality and ward
+func (s *supplierService) ListByArea(ctx context.Context, tenantID uuid.UUID, filter crmDomain.AreaFilter, page db.PageRequest) (db.Page[*crmDomain.Supplier], error) {
+	if err := filter.Normalize(); err != nil {
+		return db.Page[*crmDomain.Supplier]{}, err
+	}
+	suppliers, total, err := s.repo.ListByArea(ctx, tenantID, filter, page)
+	if err != nil {
+		return db.Page[*crmDomain.Supplier]{}, err
+	}
+	return db.NewPage(suppliers, total, page, func(s *crmDomain.Supplier) db.Cursor {
+		// Matches the repository's sort, where a missing address part is empty
+		var address crmDomain.Address
+		if a := s.GetStructuredAddress(); a != nil {
+			address = *a
+		}
+		return db.NewCursor(address.Municipality, address.Ward, address.Tole, s.Name, s.ID)
+	}), nil
+}
+
+// ListByTags retrieves suppliers by tag
+func (s *supplierService) ListByTags(ctx context.Context, tenantID uuid.UUID, filter tagsDomain.TagFilter, query string, limit, offset int) ([]*crmDomain.Supplier, error) {
+	return s.repo.ListByTags(ctx, tenantID, filter, query, limit, offset)
+}
+
 // Count returns total number of suppliers
 func (s *supplierService) Count(ctx context.Context, tenantID uuid.UUID) (int64, error) {
 	return s.repo.Count(ctx, tenantID)
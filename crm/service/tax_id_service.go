@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/cache"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/aceextension/crm/taxid"
+	"github.com/google/uuid"
+)
+
+const (
+	// taxpayerCacheTTL is how long an IRD answer is reused; registrations rarely change
+	taxpayerCacheTTL = 24 * time.Hour
+	// taxpayerCacheSize bounds the cached IRD answers
+	taxpayerCacheSize = 10000
+)
+
+// TaxIDService validates PAN/VAT numbers and verifies them against the IRD register
+type TaxIDService interface {
+	// SetLookup enables live verification; without one only the format is checked
+	SetLookup(lookup taxid.Lookup)
+	// Check validates a PAN and, when live, looks it up with IRD
+	Check(ctx context.Context, pan string, live bool) (*crmDomain.TaxIDCheck, error)
+	// VerifyCustomer looks up a customer's PAN and records the result as its badge
+	VerifyCustomer(ctx context.Context, tenantID, customerID, userID uuid.UUID) (*crmDomain.Customer, error)
+	// VerifySupplier looks up a supplier's PAN and records the result as its badge
+	VerifySupplier(ctx context.Context, tenantID, supplierID, userID uuid.UUID) (*crmDomain.Supplier, error)
+}
+
+// taxpayerAnswer is a cached IRD answer; a nil taxpayer means the PAN is not registered
+type taxpayerAnswer struct {
+	taxpayer *taxid.Taxpayer
+}
+
+// taxIDService implements TaxIDService
+type taxIDService struct {
+	customerRepo repository.CustomerRepository
+	supplierRepo repository.SupplierRepository
+	lookup       taxid.Lookup
+	answers      *cache.TTLCache[string, taxpayerAnswer]
+}
+
+// NewTaxIDService creates a tax ID service without live verification
+func NewTaxIDService(customerRepo repository.CustomerRepository, supplierRepo repository.SupplierRepository) TaxIDService {
+	return &taxIDService{
+		customerRepo: customerRepo,
+		supplierRepo: supplierRepo,
+		answers:      cache.New[string, taxpayerAnswer](taxpayerCacheTTL, taxpayerCacheSize),
+	}
+}
+
+// SetLookup sets the IRD register lookup
+func (s *taxIDService) SetLookup(lookup taxid.Lookup) {
+	s.lookup = lookup
+}
+
+// Check validates a PAN; an invalid PAN is reported in the result, not as an
+// error. A failed lookup is returned as taxid.ErrLookupUnavailable.
+func (s *taxIDService) Check(ctx context.Context, pan string, live bool) (*crmDomain.TaxIDCheck, error) {
+	normalized, err := taxid.Validate(pan)
+	check := &crmDomain.TaxIDCheck{PAN: normalized, Valid: err == nil}
+	if err != nil {
+		check.Error = err.Error()
+		return check, nil
+	}
+
+	check.Status = crmDomain.PANUnverified
+	if !live {
+		return check, nil
+	}
+
+	verification, taxpayer, err := s.verify(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	check.Status = verification.Status
+	check.Taxpayer = taxpayer
+	return check, nil
+}
+
+// verify looks a valid PAN up with IRD, answering from the cache when it can
+func (s *taxIDService) verify(ctx context.Context, pan string) (*crmDomain.PANVerification, *taxid.Taxpayer, error) {
+	answer, ok := s.answers.Get(pan)
+	if !ok {
+		if s.lookup == nil {
+			return nil, nil, taxid.ErrLookupUnavailable
+		}
+		taxpayer, err := s.lookup.Lookup(ctx, pan)
+		if err != nil && !errors.Is(err, taxid.ErrNotFound) {
+			return nil, nil, err
+		}
+		answer = taxpayerAnswer{taxpayer: taxpayer}
+		s.answers.Set(pan, answer)
+	}
+
+	verification := &crmDomain.PANVerification{PAN: pan, Status: crmDomain.PANNotFound, CheckedAt: time.Now()}
+	if answer.taxpayer != nil {
+		verification.Status = crmDomain.PANVerified
+		verification.TaxpayerName = answer.taxpayer.Name
+	}
+	return verification, answer.taxpayer, nil
+}
+
+// VerifyCustomer records the IRD check of a customer's PAN
+func (s *taxIDService) VerifyCustomer(ctx context.Context, tenantID, customerID, userID uuid.UUID) (*crmDomain.Customer, error) {
+	customer, err := s.customerRepo.GetByID(ctx, tenantID, customerID)
+	if err != nil {
+		return nil, crmDomain.ErrCustomerNotFound
+	}
+
+	pan, err := taxid.Validate(customer.GetPANNumber())
+	if err != nil {
+		return nil, err
+	}
+	verification, _, err := s.verify(ctx, pan)
+	if err != nil {
+		return nil, err
+	}
+
+	customer.SetPANNumber(pan)
+	customer.SetPANVerification(verification)
+	if err := s.customerRepo.Update(ctx, customer); err != nil {
+		return nil, fmt.Errorf("failed to save PAN verification: %w", err)
+	}
+
+	s.logVerification(ctx, "Customer", customer.ID, tenantID, userID, verification)
+	return customer, nil
+}
+
+// VerifySupplier records the IRD check of a supplier's PAN
+func (s *taxIDService) VerifySupplier(ctx context.Context, tenantID, supplierID, userID uuid.UUID) (*crmDomain.Supplier, error) {
+	supplier, err := s.supplierRepo.GetByID(ctx, tenantID, supplierID)
+	if err != nil {
+		return nil, crmDomain.ErrSupplierNotFound
+	}
+
+	pan, err := taxid.Validate(supplier.GetPANNumber())
+	if err != nil {
+		return nil, err
+	}
+	verification, _, err := s.verify(ctx, pan)
+	if err != nil {
+		return nil, err
+	}
+
+	supplier.SetPANNumber(pan)
+	supplier.SetPANVerification(verification)
+	if err := s.supplierRepo.Update(ctx, supplier); err != nil {
+		return nil, fmt.Errorf("failed to save PAN verification: %w", err)
+	}
+
+	s.logVerification(ctx, "Supplier", supplier.ID, tenantID, userID, verification)
+	return supplier, nil
+}
+
+// logVerification audits an IRD check
+func (s *taxIDService) logVerification(ctx context.Context, entity string, entityID, tenantID, userID uuid.UUID, verification *crmDomain.PANVerification) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   &userID,
+		TenantID: &tenantID,
+	}
+
+	entityIDStr := entityID.String()
+	audit.Service.Log(ctx, "VERIFY_PAN", entity, &entityIDStr, map[string]interface{}{
+		"pan":           verification.PAN,
+		"status":        verification.Status,
+		"taxpayer_name": verification.TaxpayerName,
+	}, auditCtx)
+}
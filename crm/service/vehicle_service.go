@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/logger"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/google/uuid"
+)
+
+// VehicleService defines the interface for delivery vehicles, trips and logistics costs
+type VehicleService interface {
+	CreateVehicle(ctx context.Context, vehicle *crmDomain.Vehicle) error
+	UpdateVehicle(ctx context.Context, vehicle *crmDomain.Vehicle) error
+	GetVehicle(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Vehicle, error)
+	ListVehicles(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*crmDomain.Vehicle, error)
+
+	// StartTrip starts a vehicle on a route's delivery sheet; the start reading defaults to the vehicle's odometer
+	StartTrip(ctx context.Context, trip *crmDomain.Trip, userID *uuid.UUID) error
+	GetTrip(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Trip, error)
+	// CloseTrip records the end odometer reading. Only the trip's driver or DeliveryManagerRoles may close it.
+	CloseTrip(ctx context.Context, tenantID, id, userID uuid.UUID, role string, endOdometer float64, note *string) (*crmDomain.Trip, error)
+	ListTrips(ctx context.Context, tenantID uuid.UUID, vehicleID, routeID *uuid.UUID, from, to time.Time) ([]*crmDomain.Trip, error)
+
+	// RecordExpense records a fuel, maintenance or other vehicle cost and books it to the ledger
+	RecordExpense(ctx context.Context, expense *crmDomain.VehicleExpense) error
+	ListExpenses(ctx context.Context, tenantID uuid.UUID, vehicleID *uuid.UUID, from, to time.Time) ([]*crmDomain.VehicleExpense, error)
+
+	// RouteProfitability compares each route's delivered invoices with its vehicle costs over from..to
+	RouteProfitability(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*crmDomain.RouteProfitability, error)
+
+	SetExpenseJournal(journal VehicleExpenseJournal)
+}
+
+// VehicleExpenseJournal books a vehicle expense in the general ledger: debit the vehicle
+// running expense account for its kind and credit cash or the vendor. Implemented outside
+// CRM by the process that wires accounting in; it returns the journal entry ID it created.
+type VehicleExpenseJournal interface {
+	PostVehicleExpense(ctx context.Context, expense *crmDomain.VehicleExpense, vehicle *crmDomain.Vehicle) (uuid.UUID, error)
+}
+
+// vehicleService implements VehicleService
+type vehicleService struct {
+	repo         repository.VehicleRepository
+	deliveryRepo repository.DeliveryRepository
+	journal      VehicleExpenseJournal
+}
+
+// NewVehicleService creates a new vehicle service
+func NewVehicleService(repo repository.VehicleRepository, deliveryRepo repository.DeliveryRepository) VehicleService {
+	return &vehicleService{
+		repo:         repo,
+		deliveryRepo: deliveryRepo,
+	}
+}
+
+// SetExpenseJournal sets the ledger that vehicle expenses are posted to
+func (s *vehicleService) SetExpenseJournal(journal VehicleExpenseJournal) {
+	s.journal = journal
+}
+
+// CreateVehicle creates a vehicle
+func (s *vehicleService) CreateVehicle(ctx context.Context, vehicle *crmDomain.Vehicle) error {
+	if err := vehicle.Validate(); err != nil {
+		return err
+	}
+	return s.repo.CreateVehicle(ctx, vehicle)
+}
+
+// UpdateVehicle updates a vehicle
+func (s *vehicleService) UpdateVehicle(ctx context.Context, vehicle *crmDomain.Vehicle) error {
+	if err := vehicle.Validate(); err != nil {
+		return err
+	}
+	vehicle.UpdatedAt = time.Now()
+	return s.repo.UpdateVehicle(ctx, vehicle)
+}
+
+// GetVehicle retrieves a vehicle
+func (s *vehicleService) GetVehicle(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Vehicle, error) {
+	return s.repo.GetVehicle(ctx, tenantID, id)
+}
+
+// ListVehicles retrieves the tenant's vehicles
+func (s *vehicleService) ListVehicles(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*crmDomain.Vehicle, error) {
+	return s.repo.ListVehicles(ctx, tenantID, activeOnly)
+}
+
+// StartTrip starts a trip
+func (s *vehicleService) StartTrip(ctx context.Context, trip *crmDomain.Trip, userID *uuid.UUID) error {
+	vehicle, err := s.repo.GetVehicle(ctx, trip.TenantID, trip.VehicleID)
+	if err != nil {
+		return err
+	}
+	if !vehicle.IsActive {
+		return crmDomain.ErrVehicleInactive
+	}
+
+	route, err := s.deliveryRepo.GetRoute(ctx, trip.TenantID, trip.RouteID)
+	if err != nil {
+		return err
+	}
+	if trip.DriverID == nil {
+		trip.DriverID = route.DriverID
+	}
+
+	if trip.StartOdometer == 0 {
+		trip.StartOdometer = vehicle.OdometerKm
+	}
+	if trip.StartOdometer < 0 {
+		return fmt.Errorf("start odometer cannot be negative")
+	}
+
+	if err := s.repo.CreateTrip(ctx, trip); err != nil {
+		return err
+	}
+
+	s.audit(ctx, "START_TRIP", "Trip", trip.ID, trip.TenantID, userID, map[string]interface{}{
+		"vehicle_id":     trip.VehicleID,
+		"route_id":       trip.RouteID,
+		"trip_date":      trip.TripDate.Format("2006-01-02"),
+		"driver_id":      trip.DriverID,
+		"start_odometer": trip.StartOdometer,
+	})
+	return nil
+}
+
+// GetTrip retrieves a trip
+func (s *vehicleService) GetTrip(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Trip, error) {
+	return s.repo.GetTrip(ctx, tenantID, id)
+}
+
+// CloseTrip ends a trip at an odometer reading
+func (s *vehicleService) CloseTrip(ctx context.Context, tenantID, id, userID uuid.UUID, role string, endOdometer float64, note *string) (*crmDomain.Trip, error) {
+	trip, err := s.repo.GetTrip(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !crmDomain.CanManageDeliveries(role) && (trip.DriverID == nil || *trip.DriverID != userID) {
+		return nil, crmDomain.ErrNotRouteDriver
+	}
+
+	if err := trip.Close(endOdometer, note); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CloseTrip(ctx, trip); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "CLOSE_TRIP", "Trip", trip.ID, tenantID, &userID, map[string]interface{}{
+		"vehicle_id":   trip.VehicleID,
+		"route_id":     trip.RouteID,
+		"end_odometer": endOdometer,
+		"distance_km":  trip.DistanceKm(),
+	})
+	return trip, nil
+}
+
+// ListTrips retrieves trips in a date range
+func (s *vehicleService) ListTrips(ctx context.Context, tenantID uuid.UUID, vehicleID, routeID *uuid.UUID, from, to time.Time) ([]*crmDomain.Trip, error) {
+	return s.repo.ListTrips(ctx, tenantID, vehicleID, routeID, from, to)
+}
+
+// RecordExpense records a vehicle expense
+func (s *vehicleService) RecordExpense(ctx context.Context, expense *crmDomain.VehicleExpense) error {
+	if err := expense.Validate(); err != nil {
+		return err
+	}
+
+	vehicle, err := s.repo.GetVehicle(ctx, expense.TenantID, expense.VehicleID)
+	if err != nil {
+		return err
+	}
+	if expense.TripID != nil {
+		trip, err := s.repo.GetTrip(ctx, expense.TenantID, *expense.TripID)
+		if err != nil {
+			return err
+		}
+		if trip.VehicleID != expense.VehicleID {
+			return fmt.Errorf("%w: trip belongs to another vehicle", crmDomain.ErrInvalidVehicleExpense)
+		}
+	}
+
+	if err := s.repo.CreateExpense(ctx, expense); err != nil {
+		return err
+	}
+
+	s.audit(ctx, "RECORD_VEHICLE_EXPENSE", "VehicleExpense", expense.ID, expense.TenantID, expense.CreatedBy, map[string]interface{}{
+		"vehicle_id":   expense.VehicleID,
+		"kind":         expense.Kind,
+		"amount":       expense.Amount,
+		"expense_date": expense.ExpenseDate.Format("2006-01-02"),
+	})
+	s.postExpense(ctx, expense, vehicle)
+	return nil
+}
+
+// ListExpenses retrieves vehicle expenses in a date range
+func (s *vehicleService) ListExpenses(ctx context.Context, tenantID uuid.UUID, vehicleID *uuid.UUID, from, to time.Time) ([]*crmDomain.VehicleExpense, error) {
+	return s.repo.ListExpenses(ctx, tenantID, vehicleID, from, to)
+}
+
+// RouteProfitability builds the per-route profitability report
+func (s *vehicleService) RouteProfitability(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*crmDomain.RouteProfitability, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("from must not be after to")
+	}
+
+	routes, err := s.deliveryRepo.ListRoutes(ctx, tenantID, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	trips, err := s.repo.ListTrips(ctx, tenantID, nil, nil, from, to)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.repo.ListExpenses(ctx, tenantID, nil, from, to)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.repo.RouteDeliveryTotals(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return crmDomain.NewRouteProfitability(routes, trips, expenses, totals), nil
+}
+
+// postExpense books the expense in the ledger. The expense is already saved, so a
+// ledger failure is logged and leaves it unlinked for follow-up.
+func (s *vehicleService) postExpense(ctx context.Context, expense *crmDomain.VehicleExpense, vehicle *crmDomain.Vehicle) {
+	if s.journal == nil {
+		return
+	}
+
+	entryID, err := s.journal.PostVehicleExpense(ctx, expense, vehicle)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to post %s expense of vehicle %s to ledger: %v", expense.Kind, vehicle.RegistrationNumber, err))
+		return
+	}
+
+	expense.JournalEntryID = &entryID
+	if err := s.repo.SetExpenseJournal(ctx, expense.ID, entryID); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to link vehicle expense %s to journal %s: %v", expense.ID, entryID, err))
+	}
+}
+
+func (s *vehicleService) audit(ctx context.Context, action, entity string, id, tenantID uuid.UUID, userID *uuid.UUID, changes map[string]interface{}) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &tenantID,
+	}
+
+	entityIDStr := id.String()
+	audit.Service.Log(ctx, action, entity, &entityIDStr, changes, auditCtx)
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/google/uuid"
+)
+
+// WarrantyService defines the interface for warranties on sold items and claims against them
+type WarrantyService interface {
+	// RegisterSale puts a sale's serial-numbered items under warranty. Items without
+	// warrantyMonths take their product's; items whose product has no warranty are skipped.
+	RegisterSale(ctx context.Context, sale *crmDomain.WarrantySale) ([]*crmDomain.Warranty, error)
+	GetWarranty(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Warranty, error)
+	ListWarranties(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, serialNumber *string, limit, offset int) ([]*crmDomain.Warranty, error)
+	// Check looks a serial number up: whether it is covered, for how long, and any open claim
+	Check(ctx context.Context, tenantID uuid.UUID, serialNumber string) (*crmDomain.WarrantyCheck, error)
+	// VoidInvoice ends the cover on an invoice's items when the sale is voided or refunded
+	VoidInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID, userID *uuid.UUID) (int, error)
+
+	// OpenClaim takes a unit in by serial number. Warranty claims need the unit to be
+	// covered today; exchanges only need an active warranty.
+	OpenClaim(ctx context.Context, tenantID uuid.UUID, serialNumber string, claimType crmDomain.WarrantyClaimType, issue string, userID *uuid.UUID) (*crmDomain.WarrantyClaim, error)
+	GetClaim(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.WarrantyClaim, error)
+	ListClaims(ctx context.Context, tenantID uuid.UUID, status *crmDomain.WarrantyClaimStatus, serialNumber *string, limit, offset int) ([]*crmDomain.WarrantyClaim, error)
+	SendToService(ctx context.Context, tenantID, id uuid.UUID, serviceCenter string, userID *uuid.UUID) (*crmDomain.WarrantyClaim, error)
+	// Resolve records the outcome. A replacement or exchange moves stock, ends the
+	// original warranty and puts the unit handed over under warranty.
+	Resolve(ctx context.Context, tenantID, id uuid.UUID, outcome crmDomain.WarrantyClaimOutcome, replacementProductID *uuid.UUID, replacementSerial, note string, userID *uuid.UUID) (*crmDomain.WarrantyClaim, error)
+	Close(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*crmDomain.WarrantyClaim, error)
+	Cancel(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*crmDomain.WarrantyClaim, error)
+
+	SetProducts(products WarrantyProducts)
+	SetStockLedger(stock WarrantyStock)
+}
+
+// WarrantyProducts reads how many months of warranty a product is sold with.
+// Init uses the catalog's products when catalog.Init has run first.
+type WarrantyProducts interface {
+	WarrantyMonths(ctx context.Context, tenantID, productID uuid.UUID) (int, error)
+}
+
+// WarrantyStock moves stock for replacements and exchanges. Implemented by the inventory
+// side and set after Init; an error stops the claim from being resolved.
+type WarrantyStock interface {
+	// IssueReplacement takes the unit handed to the customer out of stock
+	IssueReplacement(ctx context.Context, claim *crmDomain.WarrantyClaim) error
+	// ReceiveDefective takes the customer's unit into stock as defective, for return to the supplier
+	ReceiveDefective(ctx context.Context, claim *crmDomain.WarrantyClaim) error
+}
+
+// warrantyService implements WarrantyService
+type warrantyService struct {
+	repo         repository.WarrantyRepository
+	customerRepo repository.CustomerRepository
+	products     WarrantyProducts
+	stock        WarrantyStock
+}
+
+// NewWarrantyService creates a new warranty service
+func NewWarrantyService(repo repository.WarrantyRepository, customerRepo repository.CustomerRepository) WarrantyService {
+	return &warrantyService{
+		repo:         repo,
+		customerRepo: customerRepo,
+	}
+}
+
+// SetProducts sets where product warranty periods are read from
+func (s *warrantyService) SetProducts(products WarrantyProducts) {
+	s.products = products
+}
+
+// SetStockLedger sets where replacement and defective stock movements are recorded
+func (s *warrantyService) SetStockLedger(stock WarrantyStock) {
+	s.stock = stock
+}
+
+// RegisterSale creates the warranties of a sale
+func (s *warrantyService) RegisterSale(ctx context.Context, sale *crmDomain.WarrantySale) ([]*crmDomain.Warranty, error) {
+	if sale.CustomerID != nil {
+		if _, err := s.customerRepo.GetByID(ctx, sale.TenantID, *sale.CustomerID); err != nil {
+			return nil, crmDomain.ErrCustomerNotFound
+		}
+	}
+	if sale.SoldAt.IsZero() {
+		sale.SoldAt = time.Now()
+	}
+
+	warranties := []*crmDomain.Warranty{}
+	seen := make(map[string]bool)
+	for _, item := range sale.Items {
+		months := item.WarrantyMonths
+		if months == 0 {
+			var err error
+			if months, err = s.warrantyMonths(ctx, sale.TenantID, item.ProductID); err != nil {
+				return nil, err
+			}
+			if months == 0 {
+				continue
+			}
+		}
+
+		warranty := crmDomain.NewWarranty(sale.TenantID, item.ProductID, item.SerialNumber, months, sale.SoldAt)
+		if err := warranty.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: serial %q", err, item.SerialNumber)
+		}
+		if seen[warranty.SerialNumber] {
+			return nil, fmt.Errorf("%w: %s appears twice", crmDomain.ErrInvalidWarranty, warranty.SerialNumber)
+		}
+		seen[warranty.SerialNumber] = true
+
+		warranty.CustomerID = sale.CustomerID
+		warranty.InvoiceID = sale.InvoiceID
+		warranty.InvoiceNumber = sale.InvoiceNumber
+		warranty.CreatedBy = sale.CreatedBy
+		warranties = append(warranties, warranty)
+	}
+	if len(warranties) == 0 {
+		return warranties, nil
+	}
+
+	if err := s.repo.CreateWarranties(ctx, warranties); err != nil {
+		return nil, err
+	}
+
+	for _, warranty := range warranties {
+		s.auditWarranty(ctx, "REGISTER_WARRANTY", warranty, sale.CreatedBy)
+	}
+	return warranties, nil
+}
+
+// warrantyMonths reads a product's warranty period; without a product source nothing is covered by default
+func (s *warrantyService) warrantyMonths(ctx context.Context, tenantID, productID uuid.UUID) (int, error) {
+	if s.products == nil {
+		return 0, nil
+	}
+	months, err := s.products.WarrantyMonths(ctx, tenantID, productID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", crmDomain.ErrInvalidWarranty, err)
+	}
+	return months, nil
+}
+
+// GetWarranty retrieves a warranty
+func (s *warrantyService) GetWarranty(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.Warranty, error) {
+	return s.repo.GetWarranty(ctx, tenantID, id)
+}
+
+// ListWarranties returns warranties, newest first
+func (s *warrantyService) ListWarranties(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, serialNumber *string, limit, offset int) ([]*crmDomain.Warranty, error) {
+	if serialNumber != nil {
+		normalized := crmDomain.NormalizeSerialNumber(*serialNumber)
+		serialNumber = &normalized
+	}
+	return s.repo.ListWarranties(ctx, tenantID, customerID, serialNumber, limit, offset)
+}
+
+// Check looks a serial number up for the service counter
+func (s *warrantyService) Check(ctx context.Context, tenantID uuid.UUID, serialNumber string) (*crmDomain.WarrantyCheck, error) {
+	warranty, err := s.repo.FindBySerial(ctx, tenantID, crmDomain.NormalizeSerialNumber(serialNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	claim, err := s.repo.OpenClaim(ctx, tenantID, warranty.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &crmDomain.WarrantyCheck{
+		Warranty:  warranty,
+		Coverage:  warranty.Coverage(now),
+		Covered:   warranty.Covers(now),
+		DaysLeft:  warranty.DaysLeft(now),
+		OpenClaim: claim,
+	}, nil
+}
+
+// VoidInvoice voids an invoice's warranties
+func (s *warrantyService) VoidInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID, userID *uuid.UUID) (int, error) {
+	voided, err := s.repo.VoidInvoice(ctx, tenantID, invoiceID)
+	if err != nil || voided == 0 {
+		return voided, err
+	}
+
+	invoiceIDStr := invoiceID.String()
+	audit.Service.Log(ctx, "VOID_WARRANTIES", "Invoice", &invoiceIDStr, map[string]interface{}{
+		"voided": voided,
+	}, &auditDomain.AuditContext{UserID: userID, TenantID: &tenantID})
+	return voided, nil
+}
+
+// OpenClaim takes a unit in under a new claim
+func (s *warrantyService) OpenClaim(ctx context.Context, tenantID uuid.UUID, serialNumber string, claimType crmDomain.WarrantyClaimType, issue string, userID *uuid.UUID) (*crmDomain.WarrantyClaim, error) {
+	warranty, err := s.repo.FindBySerial(ctx, tenantID, crmDomain.NormalizeSerialNumber(serialNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if warranty.Status != crmDomain.WarrantyActive || (claimType == crmDomain.ClaimWarranty && !warranty.Covers(now)) {
+		return nil, fmt.Errorf("%w: %s", crmDomain.ErrWarrantyNotCovered, warranty.Coverage(now))
+	}
+
+	claim := crmDomain.NewWarrantyClaim(warranty, claimType, issue, userID)
+	if err := claim.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateClaim(ctx, claim); err != nil {
+		return nil, err
+	}
+
+	s.auditClaim(ctx, "OPEN_WARRANTY_CLAIM", claim, userID)
+	return claim, nil
+}
+
+// GetClaim retrieves a warranty claim
+func (s *warrantyService) GetClaim(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.WarrantyClaim, error) {
+	return s.repo.GetClaim(ctx, tenantID, id)
+}
+
+// ListClaims returns warranty claims, newest first
+func (s *warrantyService) ListClaims(ctx context.Context, tenantID uuid.UUID, status *crmDomain.WarrantyClaimStatus, serialNumber *string, limit, offset int) ([]*crmDomain.WarrantyClaim, error) {
+	if serialNumber != nil {
+		normalized := crmDomain.NormalizeSerialNumber(*serialNumber)
+		serialNumber = &normalized
+	}
+	return s.repo.ListClaims(ctx, tenantID, status, serialNumber, limit, offset)
+}
+
+// SendToService records the unit going to a service centre
+func (s *warrantyService) SendToService(ctx context.Context, tenantID, id uuid.UUID, serviceCenter string, userID *uuid.UUID) (*crmDomain.WarrantyClaim, error) {
+	return s.transition(ctx, tenantID, id, "SEND_WARRANTY_CLAIM", userID, func(claim *crmDomain.WarrantyClaim) error {
+		return claim.SendToService(serviceCenter)
+	})
+}
+
+// Resolve records a claim's outcome
+func (s *warrantyService) Resolve(ctx context.Context, tenantID, id uuid.UUID, outcome crmDomain.WarrantyClaimOutcome, replacementProductID *uuid.UUID, replacementSerial, note string, userID *uuid.UUID) (*crmDomain.WarrantyClaim, error) {
+	claim, err := s.repo.GetClaim(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	previous := claim.Status
+	if err := claim.Resolve(outcome, replacementProductID, replacementSerial, note, userID); err != nil {
+		return nil, err
+	}
+
+	var replacement *crmDomain.Warranty
+	if claim.HandsOverUnit() {
+		if replacement, err = s.replacementWarranty(ctx, claim); err != nil {
+			return nil, err
+		}
+
+		if s.stock != nil {
+			if err := s.stock.IssueReplacement(ctx, claim); err != nil {
+				return nil, fmt.Errorf("failed to issue replacement: %w", err)
+			}
+			if err := s.stock.ReceiveDefective(ctx, claim); err != nil {
+				return nil, fmt.Errorf("failed to receive defective unit: %w", err)
+			}
+		}
+	}
+
+	if err := s.repo.ResolveClaim(ctx, claim, previous, replacement); err != nil {
+		return nil, err
+	}
+
+	s.auditClaim(ctx, "RESOLVE_WARRANTY_CLAIM", claim, userID)
+	return claim, nil
+}
+
+// replacementWarranty builds the cover on the unit a claim hands over; nil when an
+// exchanged product is sold without warranty
+func (s *warrantyService) replacementWarranty(ctx context.Context, claim *crmDomain.WarrantyClaim) (*crmDomain.Warranty, error) {
+	original, err := s.repo.GetWarranty(ctx, claim.TenantID, claim.WarrantyID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.FindBySerial(ctx, claim.TenantID, *claim.ReplacementSerial)
+	if err != nil && !errors.Is(err, crmDomain.ErrWarrantyNotFound) {
+		return nil, err
+	}
+	if existing != nil && existing.Status == crmDomain.WarrantyActive {
+		return nil, fmt.Errorf("%w: %s", crmDomain.ErrWarrantyExists, existing.SerialNumber)
+	}
+
+	months := original.WarrantyMonths
+	if *claim.Outcome == crmDomain.OutcomeExchanged {
+		if months, err = s.warrantyMonths(ctx, claim.TenantID, *claim.ReplacementProductID); err != nil {
+			return nil, err
+		}
+		if months == 0 {
+			return nil, nil
+		}
+	}
+	return claim.ReplacementWarranty(original, months), nil
+}
+
+// Close records the unit handed back to the customer
+func (s *warrantyService) Close(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*crmDomain.WarrantyClaim, error) {
+	return s.transition(ctx, tenantID, id, "CLOSE_WARRANTY_CLAIM", userID, (*crmDomain.WarrantyClaim).Close)
+}
+
+// Cancel withdraws a claim that has not been resolved
+func (s *warrantyService) Cancel(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*crmDomain.WarrantyClaim, error) {
+	return s.transition(ctx, tenantID, id, "CANCEL_WARRANTY_CLAIM", userID, (*crmDomain.WarrantyClaim).Cancel)
+}
+
+// transition applies a status change that moves no stock and saves it
+func (s *warrantyService) transition(ctx context.Context, tenantID, id uuid.UUID, action string, userID *uuid.UUID, change func(*crmDomain.WarrantyClaim) error) (*crmDomain.WarrantyClaim, error) {
+	claim, err := s.repo.GetClaim(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	previous := claim.Status
+	if err := change(claim); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateClaim(ctx, claim, previous); err != nil {
+		return nil, err
+	}
+
+	s.auditClaim(ctx, action, claim, userID)
+	return claim, nil
+}
+
+func (s *warrantyService) auditWarranty(ctx context.Context, action string, warranty *crmDomain.Warranty, userID *uuid.UUID) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &warranty.TenantID,
+	}
+
+	entityIDStr := warranty.ID.String()
+	audit.Service.Log(ctx, action, "Warranty", &entityIDStr, map[string]interface{}{
+		"serial_number":   warranty.SerialNumber,
+		"product_id":      warranty.ProductID,
+		"invoice_id":      warranty.InvoiceID,
+		"warranty_months": warranty.WarrantyMonths,
+		"expires_at":      warranty.ExpiresAt.Format("2006-01-02"),
+	}, auditCtx)
+}
+
+func (s *warrantyService) auditClaim(ctx context.Context, action string, claim *crmDomain.WarrantyClaim, userID *uuid.UUID) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &claim.TenantID,
+	}
+
+	entityIDStr := claim.ID.String()
+	audit.Service.Log(ctx, action, "WarrantyClaim", &entityIDStr, map[string]interface{}{
+		"claim_number":       claim.ClaimNumber,
+		"serial_number":      claim.SerialNumber,
+		"type":               claim.Type,
+		"status":             claim.Status,
+		"outcome":            claim.Outcome,
+		"replacement_serial": claim.ReplacementSerial,
+	}, auditCtx)
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/logger"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/crm/repository"
+	"github.com/aceextension/fiscal"
+	"github.com/google/uuid"
+)
+
+// WriteOffService defines the interface for writing off bad debts
+type WriteOffService interface {
+	// Request raises a pending write-off; an amount of zero writes off everything outstanding
+	Request(ctx context.Context, writeOff *crmDomain.WriteOff) error
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.WriteOff, error)
+	List(ctx context.Context, tenantID uuid.UUID, status *crmDomain.WriteOffStatus, customerID *uuid.UUID, limit, offset int) ([]*crmDomain.WriteOff, error)
+
+	// Approve closes the dues and books them to bad-debt expense. Only WriteOffApproverRoles
+	// may approve, and never the user who requested the write-off.
+	Approve(ctx context.Context, tenantID, id, reviewerID uuid.UUID, role string, note *string) (*crmDomain.WriteOff, error)
+	Reject(ctx context.Context, tenantID, id, reviewerID uuid.UUID, role string, note *string) (*crmDomain.WriteOff, error)
+	// Cancel withdraws a pending write-off; allowed for the requester and approvers
+	Cancel(ctx context.Context, tenantID, id, userID uuid.UUID, role string) (*crmDomain.WriteOff, error)
+
+	// YearReport returns the bad debt written off per fiscal year
+	YearReport(ctx context.Context, tenantID uuid.UUID) ([]*crmDomain.WriteOffYearTotal, error)
+
+	SetWriteOffJournal(journal WriteOffJournal)
+}
+
+// WriteOffJournal books an approved write-off in the general ledger: debit the bad-debt
+// expense account and credit accounts receivable. Implemented outside CRM by the process
+// that wires accounting in; it returns the journal entry ID it created.
+type WriteOffJournal interface {
+	PostBadDebtWriteOff(ctx context.Context, writeOff *crmDomain.WriteOff) (uuid.UUID, error)
+}
+
+// writeOffService implements WriteOffService
+type writeOffService struct {
+	repo         repository.WriteOffRepository
+	khataRepo    repository.KhataRepository
+	customerRepo repository.CustomerRepository
+	dunningRepo  repository.DunningRepository
+	journal      WriteOffJournal
+}
+
+// NewWriteOffService creates a new write-off service
+func NewWriteOffService(repo repository.WriteOffRepository, khataRepo repository.KhataRepository, customerRepo repository.CustomerRepository, dunningRepo repository.DunningRepository) WriteOffService {
+	return &writeOffService{
+		repo:         repo,
+		khataRepo:    khataRepo,
+		customerRepo: customerRepo,
+		dunningRepo:  dunningRepo,
+	}
+}
+
+// SetWriteOffJournal sets the ledger that approved write-offs are posted to
+func (s *writeOffService) SetWriteOffJournal(journal WriteOffJournal) {
+	s.journal = journal
+}
+
+// Request raises a write-off for up to the customer's outstanding dues
+func (s *writeOffService) Request(ctx context.Context, writeOff *crmDomain.WriteOff) error {
+	if writeOff.Reason == "" {
+		return fmt.Errorf("a reason is required to write off dues")
+	}
+	if writeOff.Amount < 0 {
+		return fmt.Errorf("write-off amount cannot be negative")
+	}
+
+	if _, err := s.customerRepo.GetByID(ctx, writeOff.TenantID, writeOff.CustomerID); err != nil {
+		return crmDomain.ErrCustomerNotFound
+	}
+
+	dues, err := s.dues(ctx, writeOff.TenantID, writeOff.CustomerID)
+	if err != nil {
+		return err
+	}
+	if dues.Outstanding <= 0 {
+		return fmt.Errorf("customer has no outstanding dues")
+	}
+	if writeOff.Amount == 0 {
+		writeOff.Amount = dues.Outstanding
+	}
+	if writeOff.Amount > dues.Outstanding {
+		return crmDomain.ErrWriteOffExceedsDues
+	}
+	writeOff.Outstanding = dues.Outstanding
+
+	if err := s.repo.Create(ctx, writeOff); err != nil {
+		return err
+	}
+
+	s.audit(ctx, "REQUEST_WRITE_OFF", writeOff, writeOff.RequestedBy)
+	return nil
+}
+
+// Get retrieves a write-off
+func (s *writeOffService) Get(ctx context.Context, tenantID, id uuid.UUID) (*crmDomain.WriteOff, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// List returns write-offs, newest first
+func (s *writeOffService) List(ctx context.Context, tenantID uuid.UUID, status *crmDomain.WriteOffStatus, customerID *uuid.UUID, limit, offset int) ([]*crmDomain.WriteOff, error) {
+	return s.repo.List(ctx, tenantID, status, customerID, limit, offset)
+}
+
+// Approve writes off the dues in the fiscal year of approval, which must be open
+func (s *writeOffService) Approve(ctx context.Context, tenantID, id, reviewerID uuid.UUID, role string, note *string) (*crmDomain.WriteOff, error) {
+	writeOff, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeOff.Approve(reviewerID, role, note); err != nil {
+		return nil, err
+	}
+
+	// Payments since the request may have reduced what is left to write off
+	dues, err := s.dues(ctx, tenantID, writeOff.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if writeOff.Amount > dues.Outstanding {
+		return nil, crmDomain.ErrWriteOffExceedsDues
+	}
+
+	fiscalYearID, fiscalYearName, err := s.fiscalYear(ctx, tenantID, *writeOff.ReviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	writeOff.FiscalYearID = &fiscalYearID
+	writeOff.FiscalYearName = &fiscalYearName
+
+	if err := s.repo.Approve(ctx, writeOff, writeOff.KhataEntry()); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "APPROVE_WRITE_OFF", writeOff, &reviewerID)
+	s.stopDunning(ctx, writeOff)
+	s.postWriteOff(ctx, writeOff)
+	return writeOff, nil
+}
+
+// Reject declines a write-off
+func (s *writeOffService) Reject(ctx context.Context, tenantID, id, reviewerID uuid.UUID, role string, note *string) (*crmDomain.WriteOff, error) {
+	writeOff, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeOff.Reject(reviewerID, role, note); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateStatus(ctx, writeOff); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "REJECT_WRITE_OFF", writeOff, &reviewerID)
+	return writeOff, nil
+}
+
+// Cancel withdraws a pending write-off
+func (s *writeOffService) Cancel(ctx context.Context, tenantID, id, userID uuid.UUID, role string) (*crmDomain.WriteOff, error) {
+	writeOff, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	isRequester := writeOff.RequestedBy != nil && *writeOff.RequestedBy == userID
+	if !isRequester && !crmDomain.CanApproveWriteOff(role) {
+		return nil, crmDomain.ErrWriteOffNotPermitted
+	}
+	if err := writeOff.Cancel(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateStatus(ctx, writeOff); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "CANCEL_WRITE_OFF", writeOff, &userID)
+	return writeOff, nil
+}
+
+// YearReport returns approved write-off totals per fiscal year
+func (s *writeOffService) YearReport(ctx context.Context, tenantID uuid.UUID) ([]*crmDomain.WriteOffYearTotal, error) {
+	return s.repo.YearTotals(ctx, tenantID)
+}
+
+func (s *writeOffService) dues(ctx context.Context, tenantID, customerID uuid.UUID) (*crmDomain.CustomerDues, error) {
+	entries, err := s.khataRepo.CustomerEntries(ctx, tenantID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return crmDomain.AgeDues(customerID, entries, time.Now()), nil
+}
+
+// fiscalYear finds the tenant's fiscal year containing at; closed years cannot take new write-offs
+func (s *writeOffService) fiscalYear(ctx context.Context, tenantID uuid.UUID, at time.Time) (uuid.UUID, string, error) {
+	years, err := fiscal.Service.GetByTenantID(ctx, tenantID)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("failed to load fiscal years: %w", err)
+	}
+
+	for _, year := range years {
+		if at.Before(year.StartDate) || !at.Before(year.EndDate.AddDate(0, 0, 1)) {
+			continue
+		}
+		if year.IsClosed {
+			return uuid.Nil, "", fmt.Errorf("fiscal year %s is closed", year.Name)
+		}
+		return year.ID, year.Name, nil
+	}
+
+	return uuid.Nil, "", fmt.Errorf("no fiscal year covers %s", at.Format("2006-01-02"))
+}
+
+// stopDunning ends reminders once nothing is left overdue
+func (s *writeOffService) stopDunning(ctx context.Context, writeOff *crmDomain.WriteOff) {
+	dues, err := s.dues(ctx, writeOff.TenantID, writeOff.CustomerID)
+	if err != nil || dues.Overdue > 0 {
+		return
+	}
+	if err := s.dunningRepo.ClearState(ctx, writeOff.TenantID, writeOff.CustomerID); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to stop dunning for customer %s: %v", writeOff.CustomerID, err))
+	}
+}
+
+// postWriteOff books the bad debt in the ledger. The write-off is already committed,
+// so a ledger failure is logged and leaves it unlinked for follow-up.
+func (s *writeOffService) postWriteOff(ctx context.Context, writeOff *crmDomain.WriteOff) {
+	if s.journal == nil {
+		return
+	}
+
+	entryID, err := s.journal.PostBadDebtWriteOff(ctx, writeOff)
+	if err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to post write-off %s to ledger: %v", writeOff.WriteOffNumber, err))
+		return
+	}
+
+	writeOff.JournalEntryID = &entryID
+	if err := s.repo.SetJournalEntry(ctx, writeOff.ID, entryID); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to link write-off %s to journal %s: %v", writeOff.ID, entryID, err))
+	}
+}
+
+func (s *writeOffService) audit(ctx context.Context, action string, writeOff *crmDomain.WriteOff, userID *uuid.UUID) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &writeOff.TenantID,
+	}
+
+	entityIDStr := writeOff.ID.String()
+	audit.Service.Log(ctx, action, "WriteOff", &entityIDStr, map[string]interface{}{
+		"write_off_number": writeOff.WriteOffNumber,
+		"customer_id":      writeOff.CustomerID,
+		"amount":           writeOff.Amount,
+		"status":           writeOff.Status,
+	}, auditCtx)
+}
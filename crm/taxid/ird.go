@@ -0,0 +1,91 @@
+package taxid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// lookupTimeout bounds one IRD request; verification is interactive
+const lookupTimeout = 10 * time.Second
+
+// IRDLookup queries an IRD taxpayer search endpoint. IRD's public PAN search
+// sits behind a captcha, so deployments point it at a relay with access to the
+// register that answers GET ?pan=<pan> with IRD's panDetails JSON.
+type IRDLookup struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewIRDLookup creates a lookup against endpoint
+func NewIRDLookup(endpoint string) *IRDLookup {
+	return &IRDLookup{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: lookupTimeout},
+	}
+}
+
+// irdResponse is the part of IRD's PAN search response read here
+type irdResponse struct {
+	PANDetails []struct {
+		PAN           json.Number `json:"pan"`
+		TradeNameEng  string      `json:"trade_Name_Eng"`
+		TradeNameNep  string      `json:"trade_Name_Nep"`
+		OfficeName    string      `json:"office_Name"`
+		AccountStatus string      `json:"account_Status"`
+	} `json:"panDetails"`
+}
+
+// Lookup fetches the taxpayer registered under pan
+func (l *IRDLookup) Lookup(ctx context.Context, pan string) (*Taxpayer, error) {
+	u, err := url.Parse(l.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLookupUnavailable, err)
+	}
+	query := u.Query()
+	query.Set("pan", pan)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLookupUnavailable, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLookupUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: IRD responded %d", ErrLookupUnavailable, resp.StatusCode)
+	}
+
+	var body irdResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: unreadable response: %v", ErrLookupUnavailable, err)
+	}
+
+	for _, detail := range body.PANDetails {
+		if detail.PAN.String() != pan {
+			continue
+		}
+		return &Taxpayer{
+			PAN:        pan,
+			Name:       strings.TrimSpace(detail.TradeNameEng),
+			NameNepali: strings.TrimSpace(detail.TradeNameNep),
+			Office:     strings.TrimSpace(detail.OfficeName),
+			Status:     strings.TrimSpace(detail.AccountStatus),
+		}, nil
+	}
+	return nil, ErrNotFound
+}
@@ -0,0 +1,80 @@
+// Package taxid validates Nepali PAN/VAT numbers and looks taxpayers up in the
+// Inland Revenue Department (IRD) register.
+//
+// A PAN is nine digits and a VAT registrant's VAT number is its PAN. IRD does
+// not publish a check digit, so Validate catches the typos a format check can:
+// wrong length, stray letters, a leading zero and numbers of one repeated digit.
+// Live lookup is what confirms a number was actually issued.
+package taxid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Length is the number of digits in a PAN
+const Length = 9
+
+var (
+	// ErrInvalidPAN is returned for a PAN/VAT number that cannot be a real one
+	ErrInvalidPAN = errors.New("invalid PAN/VAT number")
+	// ErrNotFound is returned by a Lookup when IRD has no taxpayer with the PAN
+	ErrNotFound = errors.New("PAN not registered with IRD")
+	// ErrLookupUnavailable is returned when no lookup is configured or IRD cannot be reached
+	ErrLookupUnavailable = errors.New("IRD lookup unavailable")
+)
+
+// devanagariDigits maps Nepali digits to ASCII, as PANs are often typed in either
+var devanagariDigits = strings.NewReplacer(
+	"०", "0", "१", "1", "२", "2", "३", "3", "४", "4",
+	"५", "5", "६", "6", "७", "7", "८", "8", "९", "9",
+)
+
+// Normalize converts Nepali digits and drops the spaces, dashes and dots PANs are written with
+func Normalize(pan string) string {
+	pan = devanagariDigits.Replace(strings.TrimSpace(pan))
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '/':
+			return -1
+		}
+		return r
+	}, pan)
+}
+
+// Validate normalizes a PAN/VAT number and checks it is well formed
+func Validate(pan string) (string, error) {
+	pan = Normalize(pan)
+	if len(pan) != Length {
+		return pan, fmt.Errorf("%w: must be %d digits", ErrInvalidPAN, Length)
+	}
+	for _, r := range pan {
+		if r < '0' || r > '9' {
+			return pan, fmt.Errorf("%w: must contain only digits", ErrInvalidPAN)
+		}
+	}
+	if pan[0] == '0' {
+		return pan, fmt.Errorf("%w: cannot start with 0", ErrInvalidPAN)
+	}
+	if strings.Count(pan, pan[:1]) == Length {
+		return pan, fmt.Errorf("%w: repeated digits", ErrInvalidPAN)
+	}
+	return pan, nil
+}
+
+// Taxpayer is what IRD has on record for a PAN
+type Taxpayer struct {
+	PAN        string `json:"pan"`
+	Name       string `json:"name"`                 // Trade name in English
+	NameNepali string `json:"nameNepali,omitempty"` // Trade name in Nepali
+	Office     string `json:"office,omitempty"`     // Tax office the taxpayer files with
+	Status     string `json:"status,omitempty"`     // Account status as IRD reports it
+}
+
+// Lookup fetches a taxpayer from the IRD register; it returns ErrNotFound for
+// an unissued PAN and ErrLookupUnavailable when the register cannot be reached
+type Lookup interface {
+	Lookup(ctx context.Context, pan string) (*Taxpayer, error)
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity types that accept attachments
+const (
+	EntityJournalEntry = "journal_entry"
+	EntityInvoice      = "invoice"
+	EntityPurchaseBill = "purchase_bill"
+)
+
+// MaxAttachmentSize limits a single upload (20 MB covers multi-page scanned bills)
+const MaxAttachmentSize = 20 << 20
+
+// AllowedContentTypes are the file types accepted as supporting documents
+var AllowedContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+}
+
+var (
+	// ErrUnknownEntityType is returned for entity types no module has registered
+	ErrUnknownEntityType = errors.New("attachments are not supported for this entity type")
+	// ErrEntityNotFound is returned when the target record does not exist for the tenant
+	ErrEntityNotFound = errors.New("entity not found")
+	// ErrAttachmentNotFound is returned when an attachment does not exist for the tenant
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrFileTooLarge is returned when an upload exceeds MaxAttachmentSize
+	ErrFileTooLarge = errors.New("file exceeds the 20 MB attachment limit")
+	// ErrUnsupportedFileType is returned for content types outside AllowedContentTypes
+	ErrUnsupportedFileType = errors.New("only PDF and image files can be attached")
+)
+
+// Attachment is a supporting document linked to a business record
+// (journal voucher, sales invoice, purchase bill).
+type Attachment struct {
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	EntityType  string
+	EntityID    uuid.UUID
+	FileName    string
+	ContentType string
+	SizeBytes   int64
+	Checksum    string // SHA-256 hex of the content
+	StorageKey  string
+	Description *string
+	UploadedBy  *uuid.UUID
+
+	// Metadata
+	CreatedAt time.Time
+	DeletedAt *time.Time // Soft delete; the stored file is retained for audit
+}
+
+// NewAttachment creates attachment metadata with its storage key
+func NewAttachment(tenantID uuid.UUID, entityType string, entityID uuid.UUID, fileName string) *Attachment {
+	id := uuid.New()
+	return &Attachment{
+		ID:         id,
+		TenantID:   tenantID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		FileName:   SanitizeFileName(fileName),
+		StorageKey: tenantID.String() + "/" + entityType + "/" + id.String(),
+		CreatedAt:  time.Now(),
+	}
+}
+
+// SanitizeFileName strips directories and control characters from a client-supplied name
+func SanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 32 || r == 127 || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		ext := filepath.Ext(name)
+		name = name[:255-len(ext)] + ext
+	}
+	return name
+}
@@ -0,0 +1,290 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BackupFrequency is how often a scheduled backup runs
+type BackupFrequency string
+
+const (
+	BackupDaily  BackupFrequency = "daily"
+	BackupWeekly BackupFrequency = "weekly"
+)
+
+// BackupMode is what a backup run exports
+type BackupMode string
+
+const (
+	BackupFull  BackupMode = "full"  // Every row of the tenant
+	BackupDelta BackupMode = "delta" // Rows created or updated since the last completed run
+)
+
+// BackupDestination is where a backup archive is delivered
+type BackupDestination string
+
+const (
+	DestinationMinio BackupDestination = "minio" // The platform's export store, under the schedule's path
+	DestinationS3    BackupDestination = "s3"    // The tenant's own bucket, from its backup_s3 integration
+	DestinationEmail BackupDestination = "email" // The platform's export store, with a download link emailed out
+)
+
+// BackupRunStatus is where a backup run is
+type BackupRunStatus string
+
+const (
+	BackupRunning   BackupRunStatus = "running"
+	BackupCompleted BackupRunStatus = "completed"
+	BackupFailed    BackupRunStatus = "failed"
+)
+
+// BackupTrigger is what started a backup run
+type BackupTrigger string
+
+const (
+	TriggerSchedule BackupTrigger = "schedule"
+	TriggerManual   BackupTrigger = "manual"
+)
+
+// BackupLinkTTL is how long an emailed backup link opens the archive
+const BackupLinkTTL = 7 * 24 * time.Hour
+
+// maxBackupEmails is how many addresses one schedule emails its link to
+const maxBackupEmails = 10
+
+var (
+	// ErrBackupScheduleNotFound is returned when a schedule does not exist for the tenant
+	ErrBackupScheduleNotFound = errors.New("backup schedule not found")
+	// ErrBackupRunNotFound is returned when a run does not exist for the tenant
+	ErrBackupRunNotFound = errors.New("backup run not found")
+	// ErrInvalidBackupSchedule is returned for schedules that fail validation
+	ErrInvalidBackupSchedule = errors.New("invalid backup schedule")
+	// ErrBackupRunning is returned when a schedule is run while its previous run has not finished
+	ErrBackupRunning = errors.New("a backup of this schedule is already running")
+	// ErrBackupNotDownloadable is returned for runs whose archive is not in the platform's store
+	ErrBackupNotDownloadable = errors.New("backup archive is not stored on the platform")
+)
+
+// BackupSchedule exports a tenant's data on a daily or weekly schedule
+type BackupSchedule struct {
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	Name        string
+	Frequency   BackupFrequency
+	Weekday     int // 0 (Sunday) to 6; weekly schedules only
+	Hour        int // 0-23, UTC
+	Mode        BackupMode
+	Destination BackupDestination
+	Path        string   // Folder under the destination's root, e.g. "weekly"
+	Emails      []string // Recipients of the link for the email destination
+	IsActive    bool
+	NextRunAt   *time.Time
+	LastRunAt   *time.Time
+	CreatedBy   *uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// BackupScheduleInput holds the settings of a new or updated schedule
+type BackupScheduleInput struct {
+	Name        string
+	Frequency   BackupFrequency
+	Weekday     int
+	Hour        int
+	Mode        BackupMode
+	Destination BackupDestination
+	Path        string
+	Emails      []string
+	IsActive    bool
+}
+
+// NewBackupSchedule creates a schedule whose first run is the next slot after now
+func NewBackupSchedule(tenantID uuid.UUID, input BackupScheduleInput, createdBy *uuid.UUID, now time.Time) (*BackupSchedule, error) {
+	s := &BackupSchedule{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+	}
+	if err := s.Update(input, now); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Update replaces the schedule's settings and recomputes the next run
+func (s *BackupSchedule) Update(input BackupScheduleInput, now time.Time) error {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Path = strings.Trim(strings.TrimSpace(input.Path), "/")
+
+	switch {
+	case input.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidBackupSchedule)
+	case input.Frequency != BackupDaily && input.Frequency != BackupWeekly:
+		return fmt.Errorf("%w: frequency must be daily or weekly", ErrInvalidBackupSchedule)
+	case input.Frequency == BackupWeekly && (input.Weekday < 0 || input.Weekday > 6):
+		return fmt.Errorf("%w: weekday must be 0 (Sunday) to 6", ErrInvalidBackupSchedule)
+	case input.Hour < 0 || input.Hour > 23:
+		return fmt.Errorf("%w: hour must be 0 to 23", ErrInvalidBackupSchedule)
+	case input.Mode != BackupFull && input.Mode != BackupDelta:
+		return fmt.Errorf("%w: mode must be full or delta", ErrInvalidBackupSchedule)
+	case strings.Contains(input.Path, ".."):
+		return fmt.Errorf("%w: path must not contain ..", ErrInvalidBackupSchedule)
+	}
+
+	switch input.Destination {
+	case DestinationMinio, DestinationS3:
+		input.Emails = nil
+	case DestinationEmail:
+		emails, err := normalizeEmails(input.Emails)
+		if err != nil {
+			return err
+		}
+		input.Emails = emails
+	default:
+		return fmt.Errorf("%w: destination must be minio, s3 or email", ErrInvalidBackupSchedule)
+	}
+	if input.Frequency == BackupDaily {
+		input.Weekday = 0
+	}
+
+	s.Name = input.Name
+	s.Frequency = input.Frequency
+	s.Weekday = input.Weekday
+	s.Hour = input.Hour
+	s.Mode = input.Mode
+	s.Destination = input.Destination
+	s.Path = input.Path
+	s.Emails = input.Emails
+	s.IsActive = input.IsActive
+	s.UpdatedAt = now
+
+	s.NextRunAt = nil
+	if s.IsActive {
+		next := s.NextAfter(now)
+		s.NextRunAt = &next
+	}
+	return nil
+}
+
+// NextAfter returns the first scheduled slot strictly after t
+func (s *BackupSchedule) NextAfter(t time.Time) time.Time {
+	t = t.UTC()
+	next := time.Date(t.Year(), t.Month(), t.Day(), s.Hour, 0, 0, 0, time.UTC)
+	if s.Frequency == BackupWeekly {
+		next = next.AddDate(0, 0, (s.Weekday-int(next.Weekday())+7)%7)
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// normalizeEmails trims, deduplicates and checks the link recipients
+func normalizeEmails(emails []string) ([]string, error) {
+	seen := make(map[string]bool, len(emails))
+	out := make([]string, 0, len(emails))
+	for _, email := range emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" || seen[email] {
+			continue
+		}
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return nil, fmt.Errorf("%w: %q is not an email address", ErrInvalidBackupSchedule, email)
+		}
+		seen[email] = true
+		out = append(out, email)
+	}
+	switch {
+	case len(out) == 0:
+		return nil, fmt.Errorf("%w: the email destination needs at least one address", ErrInvalidBackupSchedule)
+	case len(out) > maxBackupEmails:
+		return nil, fmt.Errorf("%w: at most %d addresses", ErrInvalidBackupSchedule, maxBackupEmails)
+	}
+	return out, nil
+}
+
+// BackupTable is how many rows of one table a run exported
+type BackupTable struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// BackupRun is one export of a schedule, kept as the schedule's history
+type BackupRun struct {
+	ID          uuid.UUID
+	ScheduleID  uuid.UUID
+	TenantID    uuid.UUID
+	Trigger     BackupTrigger
+	Mode        BackupMode // Delta runs without an earlier completed run export everything
+	Since       *time.Time // Rows changed after this were exported; nil for full runs
+	Until       time.Time  // Snapshot time; the next delta run starts here
+	Status      BackupRunStatus
+	Destination BackupDestination
+	Location    *string // Object key in the destination
+	SizeBytes   *int64
+	Checksum    *string // SHA-256 of the archive, hex
+	Tables      []BackupTable
+	Error       *string
+	TriggeredBy *uuid.UUID
+	StartedAt   time.Time
+	FinishedAt  *time.Time
+}
+
+// NewBackupRun starts a run of the schedule. since is the last completed run's
+// snapshot time; without one a delta run falls back to a full export.
+func NewBackupRun(schedule *BackupSchedule, trigger BackupTrigger, since *time.Time, triggeredBy *uuid.UUID, now time.Time) *BackupRun {
+	run := &BackupRun{
+		ID:          uuid.New(),
+		ScheduleID:  schedule.ID,
+		TenantID:    schedule.TenantID,
+		Trigger:     trigger,
+		Mode:        schedule.Mode,
+		Until:       now,
+		Status:      BackupRunning,
+		Destination: schedule.Destination,
+		Tables:      []BackupTable{},
+		TriggeredBy: triggeredBy,
+		StartedAt:   now,
+	}
+	if run.Mode == BackupDelta {
+		if since == nil {
+			run.Mode = BackupFull
+		} else {
+			run.Since = since
+		}
+	}
+	return run
+}
+
+// Complete records where the archive went, its size and checksum
+func (r *BackupRun) Complete(location string, size int64, checksum string, now time.Time) {
+	r.Status = BackupCompleted
+	r.Location = &location
+	r.SizeBytes = &size
+	r.Checksum = &checksum
+	r.Error = nil
+	r.FinishedAt = &now
+}
+
+// Fail records why the run did not finish
+func (r *BackupRun) Fail(reason string, now time.Time) {
+	r.Status = BackupFailed
+	r.Error = &reason
+	r.FinishedAt = &now
+}
+
+// Downloadable reports whether the archive is in the platform's store
+func (r *BackupRun) Downloadable() bool {
+	return r.Status == BackupCompleted && r.Location != nil && r.Destination != DestinationS3
+}
@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DocumentKind is what an uploaded document is, which decides the fields read from it
+type DocumentKind string
+
+const (
+	DocumentPANCertificate DocumentKind = "pan_certificate" // IRD PAN/VAT registration certificate
+	DocumentBill           DocumentKind = "bill"            // Supplier tax invoice or bill
+)
+
+// Fields read from each document kind
+const (
+	FieldPANNumber    = "pan_number"
+	FieldTaxpayerName = "taxpayer_name"
+	FieldBillNumber   = "bill_number"
+	FieldBillDate     = "bill_date" // YYYY-MM-DD
+	FieldSubTotal     = "sub_total"
+	FieldVATAmount    = "vat_amount"
+	FieldTotalAmount  = "total_amount"
+)
+
+// RequiredFields are the fields a document kind must yield before its extraction
+// can be used without review
+var RequiredFields = map[DocumentKind][]string{
+	DocumentPANCertificate: {FieldPANNumber, FieldTaxpayerName},
+	DocumentBill:           {FieldBillNumber, FieldBillDate, FieldTotalAmount},
+}
+
+// DefaultConfidenceThreshold is the field confidence below which a person must confirm the value
+const DefaultConfidenceThreshold = 0.85
+
+// MaxExtractionAttempts is how often an extraction is retried after engine errors
+const MaxExtractionAttempts = 3
+
+// ExtractionStatus is where a document extraction is in the queue
+type ExtractionStatus string
+
+const (
+	ExtractionPending     ExtractionStatus = "pending"
+	ExtractionNeedsReview ExtractionStatus = "needs_review" // A field is missing or below the confidence threshold
+	ExtractionCompleted   ExtractionStatus = "completed"    // Every required field is above the threshold
+	ExtractionConfirmed   ExtractionStatus = "confirmed"    // A person checked the values
+	ExtractionFailed      ExtractionStatus = "failed"
+)
+
+var (
+	// ErrExtractionNotFound is returned when an extraction does not exist for the tenant
+	ErrExtractionNotFound = errors.New("extraction not found")
+	// ErrExtractionState is returned when confirming an extraction that has not finished or was confirmed
+	ErrExtractionState = errors.New("extraction is not awaiting confirmation")
+	// ErrUnknownDocumentKind is returned for kinds without field rules
+	ErrUnknownDocumentKind = errors.New("unknown document kind")
+	// ErrOCRUnavailable is returned when no OCR engine is configured
+	ErrOCRUnavailable = errors.New("document extraction is not configured")
+)
+
+// ExtractedField is one value read from a document
+type ExtractedField struct {
+	Name        string  `json:"name"`
+	Value       string  `json:"value"`
+	Confidence  float64 `json:"confidence"` // 0-1; 1 once confirmed by a person
+	NeedsReview bool    `json:"needsReview"`
+}
+
+// Extraction is the structured data read from an attachment by OCR
+type Extraction struct {
+	ID           uuid.UUID
+	TenantID     uuid.UUID
+	AttachmentID uuid.UUID
+	Kind         DocumentKind
+	Status       ExtractionStatus
+	Fields       []ExtractedField
+	Confidence   float64 // OCR engine's mean word confidence
+	Text         string  // Recognised text, kept for reviewers
+	Error        *string
+	Attempts     int
+	RequestedBy  *uuid.UUID
+	ReviewedBy   *uuid.UUID
+	ReviewedAt   *time.Time
+
+	// Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewExtraction queues an attachment for extraction
+func NewExtraction(tenantID, attachmentID uuid.UUID, kind DocumentKind, requestedBy *uuid.UUID) (*Extraction, error) {
+	if _, ok := RequiredFields[kind]; !ok {
+		return nil, ErrUnknownDocumentKind
+	}
+	now := time.Now()
+	return &Extraction{
+		ID:           uuid.New(),
+		TenantID:     tenantID,
+		AttachmentID: attachmentID,
+		Kind:         kind,
+		Status:       ExtractionPending,
+		RequestedBy:  requestedBy,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+// Field returns the value read for a field, if any
+func (e *Extraction) Field(name string) (string, bool) {
+	for _, field := range e.Fields {
+		if field.Name == name {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
+// Complete records the fields read. Fields below the threshold are flagged, and the
+// extraction needs review when any is flagged or a required field is missing.
+func (e *Extraction) Complete(text string, confidence float64, fields []ExtractedField, threshold float64) {
+	e.Text = text
+	e.Confidence = confidence
+	e.Fields = fields
+	e.Error = nil
+	e.Status = ExtractionCompleted
+
+	for i := range e.Fields {
+		if e.Fields[i].Confidence < threshold {
+			e.Fields[i].NeedsReview = true
+			e.Status = ExtractionNeedsReview
+		}
+	}
+	for _, name := range RequiredFields[e.Kind] {
+		if _, ok := e.Field(name); !ok {
+			e.Status = ExtractionNeedsReview
+		}
+	}
+	e.UpdatedAt = time.Now()
+}
+
+// Fail records an engine error; the extraction is retried until MaxExtractionAttempts
+func (e *Extraction) Fail(reason string) {
+	e.Attempts++
+	e.Error = &reason
+	if e.Attempts >= MaxExtractionAttempts {
+		e.Status = ExtractionFailed
+	}
+	e.UpdatedAt = time.Now()
+}
+
+// Abandon fails the extraction without retrying, for errors a retry cannot fix
+func (e *Extraction) Abandon(reason string) {
+	e.Attempts++
+	e.Error = &reason
+	e.Status = ExtractionFailed
+	e.UpdatedAt = time.Now()
+}
+
+// Confirm accepts the fields after a person checked them against the document.
+// Corrections replace or add values; blank corrections are ignored.
+func (e *Extraction) Confirm(corrections map[string]string, by uuid.UUID) error {
+	if e.Status != ExtractionNeedsReview && e.Status != ExtractionCompleted {
+		return ErrExtractionState
+	}
+
+	for name, value := range corrections {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		found := false
+		for i := range e.Fields {
+			if e.Fields[i].Name == name {
+				e.Fields[i].Value = value
+				found = true
+			}
+		}
+		if !found {
+			e.Fields = append(e.Fields, ExtractedField{Name: name, Value: value})
+		}
+	}
+	for i := range e.Fields {
+		e.Fields[i].Confidence = 1
+		e.Fields[i].NeedsReview = false
+	}
+
+	now := time.Now()
+	e.Status = ExtractionConfirmed
+	e.ReviewedBy = &by
+	e.ReviewedAt = &now
+	e.UpdatedAt = now
+	return nil
+}
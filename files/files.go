@@ -0,0 +1,187 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/config"
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/files/domain"
+	"github.com/aceextension/files/ocr"
+	"github.com/aceextension/files/repository"
+	"github.com/aceextension/files/service"
+	"github.com/aceextension/files/storage"
+	"github.com/aceextension/notification"
+	notificationDomain "github.com/aceextension/notification/domain"
+	notificationService "github.com/aceextension/notification/service"
+	"github.com/google/uuid"
+)
+
+// extractionInterval is how often the OCR queue is worked
+const extractionInterval = 30 * time.Second
+
+// backupInterval is how often due backup schedules are looked for; schedules run on the hour
+const backupInterval = time.Minute
+
+// Module-level service instances
+var (
+	AttachmentService service.AttachmentService
+	ExtractionService service.ExtractionService
+	BackupService     service.BackupService
+
+	// OCREngine is the configured OCR engine, nil when OCR is off. Other modules
+	// use it to read their own documents (e.g. bills captured from email).
+	OCREngine ocr.Engine
+
+	// ExportStore holds generated exports (e.g. year-end report bundles) for download.
+	// It is MinIO when configured, which also hands out presigned links.
+	ExportStore storage.Storage
+)
+
+// Init initializes the files module.
+// Journal entries are registered here; invoice and purchase bill owners
+// register their entity types through AttachmentService.RegisterEntityType.
+func Init() {
+	storagePath := "./data/files"
+	if config.GlobalConfig != nil && config.GlobalConfig.FileStoragePath != "" {
+		storagePath = config.GlobalConfig.FileStoragePath
+	}
+
+	store, err := storage.NewLocalStorage(storagePath)
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+
+	ExportStore = newExportStore(storagePath)
+
+	attachmentRepo := repository.NewPostgresAttachmentRepository()
+	AttachmentService = service.NewAttachmentService(attachmentRepo, store)
+
+	AttachmentService.RegisterEntityType(domain.EntityJournalEntry, journalEntryExists)
+
+	// OCR is off unless a Tesseract binary is configured; hosts using a cloud
+	// engine set OCREngine and call ExtractionService.SetEngine before other modules' Init
+	ExtractionService = service.NewExtractionService(repository.NewPostgresExtractionRepository(), attachmentRepo, store)
+	if config.GlobalConfig != nil {
+		if config.GlobalConfig.OCRTesseractPath != "" {
+			OCREngine = ocr.NewTesseractEngine(config.GlobalConfig.OCRTesseractPath, config.GlobalConfig.OCRLanguages)
+			ExtractionService.SetEngine(OCREngine)
+		}
+		if config.GlobalConfig.OCRConfidenceThreshold > 0 {
+			ExtractionService.SetConfidenceThreshold(config.GlobalConfig.OCRConfidenceThreshold)
+		}
+	}
+
+	// Backups to the platform store or by email link go to ExportStore
+	BackupService = service.NewBackupService(repository.NewPostgresBackupRepository(), repository.NewPostgresBackupDataRepository(), AttachmentService, ExportStore)
+	BackupService.SetMailer(notificationBackupMailer{})
+}
+
+// StartExtractionWorker works the OCR queue every extractionInterval. Call after Init.
+func StartExtractionWorker() {
+	go func() {
+		ticker := time.NewTicker(extractionInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := ExtractionService.ProcessPending(context.Background()); err != nil {
+				logger.Log.Error("Document extraction worker error: " + err.Error())
+			}
+		}
+	}()
+}
+
+// StartBackupScheduler runs due backup schedules every backupInterval. Call after Init.
+func StartBackupScheduler() {
+	go func() {
+		ticker := time.NewTicker(backupInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := BackupService.RunDue(context.Background()); err != nil {
+				logger.Log.Error("Backup scheduler error: " + err.Error())
+			}
+		}
+	}()
+}
+
+// notificationBackupMailer emails backup download links through the notification queue
+type notificationBackupMailer struct{}
+
+func (notificationBackupMailer) SendBackupLink(ctx context.Context, schedule *domain.BackupSchedule, run *domain.BackupRun, link string, expiresAt time.Time) error {
+	if notification.Service == nil {
+		return errors.New("notification module is not initialized")
+	}
+	// Links to the download endpoint are relative; make them absolute for the email
+	if strings.HasPrefix(link, "/") && config.GlobalConfig != nil {
+		base := config.GlobalConfig.PublicAPIURL
+		if base == "" {
+			base = config.GlobalConfig.AppURL
+		}
+		link = strings.TrimRight(base, "/") + link
+	}
+
+	content := fmt.Sprintf(
+		"Your %s backup \"%s\" is ready.\n\nTaken: %s\nSize: %d bytes\nSHA-256: %s\n\nDownload: %s\nThe link expires on %s.\n",
+		run.Mode, schedule.Name, run.Until.UTC().Format("2006-01-02 15:04 MST"), *run.SizeBytes, *run.Checksum,
+		link, expiresAt.UTC().Format("2006-01-02 15:04 MST"),
+	)
+	referenceType := "BACKUP_RUN"
+
+	var errs []error
+	for _, email := range schedule.Emails {
+		_, err := notification.Service.Send(ctx, notificationService.SendRequest{
+			TenantID:      schedule.TenantID,
+			Channel:       notificationDomain.ChannelEmail,
+			Recipient:     email,
+			Subject:       fmt.Sprintf("Backup ready: %s", schedule.Name),
+			Content:       content,
+			Priority:      notificationDomain.PriorityLow,
+			ReferenceType: &referenceType,
+			ReferenceID:   &run.ID,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", email, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newExportStore uses MinIO when its endpoint and keys are configured, else the local exports directory
+func newExportStore(storagePath string) storage.Storage {
+	cfg := config.GlobalConfig
+	if cfg != nil && cfg.MinioEndpoint != "" && cfg.MinioAccessKey != "" && cfg.MinioSecretKey != "" {
+		store, err := storage.NewMinioStorage(storage.MinioConfig{
+			Endpoint:       cfg.MinioEndpoint,
+			PublicEndpoint: cfg.MinioPublicEndpoint,
+			AccessKey:      cfg.MinioAccessKey,
+			SecretKey:      cfg.MinioSecretKey,
+			Bucket:         cfg.MinioBucket,
+			Region:         cfg.MinioRegion,
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize export storage: %v", err)
+		}
+		return store
+	}
+
+	store, err := storage.NewLocalStorage(filepath.Join(storagePath, "exports"))
+	if err != nil {
+		log.Fatalf("Failed to initialize export storage: %v", err)
+	}
+	return store
+}
+
+// journalEntryExists checks that a journal entry belongs to the tenant
+func journalEntryExists(ctx context.Context, tenantID, entityID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM journal_entries WHERE id = $1 AND tenant_id = $2)`
+	if err := db.MainPool.QueryRow(ctx, query, entityID, tenantID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check journal entry: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/files/domain"
+	"github.com/aceextension/files/service"
+	"github.com/aceextension/files/storage"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type AttachmentHandler struct {
+	service service.AttachmentService
+}
+
+func NewAttachmentHandler(service service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{service: service}
+}
+
+// AttachmentResponse is the API representation of an attachment
+type AttachmentResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	EntityType  string     `json:"entityType"`
+	EntityID    uuid.UUID  `json:"entityId"`
+	FileName    string     `json:"fileName"`
+	ContentType string     `json:"contentType"`
+	SizeBytes   int64      `json:"sizeBytes"`
+	Checksum    string     `json:"sha256"`
+	Description *string    `json:"description"`
+	UploadedBy  *uuid.UUID `json:"uploadedBy"`
+	CreatedAt   string     `json:"createdAt"`
+}
+
+func toAttachmentResponse(a *domain.Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:          a.ID,
+		EntityType:  a.EntityType,
+		EntityID:    a.EntityID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		SizeBytes:   a.SizeBytes,
+		Checksum:    a.Checksum,
+		Description: a.Description,
+		UploadedBy:  a.UploadedBy,
+		CreatedAt:   a.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// UploadAttachment attaches a file to a record
+// @Summary Upload Attachment
+// @Description Attach a PDF or image to a journal entry, invoice or purchase bill
+// @Tags Files
+// @Accept multipart/form-data
+// @Produce json
+// @Param entityType formData string true "Entity type (journal_entry, invoice, purchase_bill)"
+// @Param entityId formData string true "Entity ID"
+// @Param description formData string false "Description"
+// @Param file formData file true "File (max 20 MB)"
+// @Success 201 {object} AttachmentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/files/attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	entityID, err := uuid.Parse(c.FormValue("entityId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid entityId"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required"})
+	}
+	if fileHeader.Size > domain.MaxAttachmentSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": domain.ErrFileTooLarge.Error()})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read file"})
+	}
+	defer file.Close()
+
+	var description *string
+	if d := strings.TrimSpace(c.FormValue("description")); d != "" {
+		description = &d
+	}
+
+	attachment, err := h.service.Upload(c.Request().Context(), service.UploadInput{
+		TenantID:    tenantID,
+		UserID:      &userID,
+		EntityType:  c.FormValue("entityType"),
+		EntityID:    entityID,
+		FileName:    fileHeader.Filename,
+		Description: description,
+		Content:     file,
+	})
+	if err != nil {
+		return attachmentError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toAttachmentResponse(attachment))
+}
+
+// ListAttachments lists the attachments of a record
+// @Summary List Attachments
+// @Description List attachments of a journal entry, invoice or purchase bill
+// @Tags Files
+// @Produce json
+// @Param entityType query string true "Entity type"
+// @Param entityId query string true "Entity ID"
+// @Success 200 {array} AttachmentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/files/attachments [get]
+func (h *AttachmentHandler) ListAttachments(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	entityID, err := uuid.Parse(c.QueryParam("entityId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid entityId"})
+	}
+
+	attachments, err := h.service.List(c.Request().Context(), tenantID, c.QueryParam("entityType"), entityID)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+
+	response := make([]AttachmentResponse, 0, len(attachments))
+	for _, a := range attachments {
+		response = append(response, toAttachmentResponse(a))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// DownloadAttachment streams an attachment's content
+// @Summary Download Attachment
+// @Description Download an attachment
+// @Tags Files
+// @Produce octet-stream
+// @Param id path string true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/files/attachments/{id}/download [get]
+func (h *AttachmentHandler) DownloadAttachment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid attachment ID"})
+	}
+
+	attachment, content, err := h.service.Open(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	defer content.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", attachment.FileName))
+	c.Response().Header().Set(echo.HeaderContentLength, fmt.Sprint(attachment.SizeBytes))
+	return c.Stream(http.StatusOK, attachment.ContentType, content)
+}
+
+// DeleteAttachment removes an attachment from its record
+// @Summary Delete Attachment
+// @Description Soft-delete an attachment
+// @Tags Files
+// @Param id path string true "Attachment ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/files/attachments/{id} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid attachment ID"})
+	}
+
+	if err := h.service.Delete(c.Request().Context(), tenantID, id); err != nil {
+		return attachmentError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ExportAttachments downloads all of the tenant's attachments as a zip archive
+// @Summary Export Attachments
+// @Description Download every attachment with a manifest, for tenant data exports
+// @Tags Files
+// @Produce application/zip
+// @Success 200 {file} file
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/files/attachments/export [get]
+func (h *AttachmentHandler) ExportAttachments(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="attachments.zip"`)
+	c.Response().WriteHeader(http.StatusOK)
+
+	// Headers are already sent, so a failure can only truncate the archive
+	archive := zip.NewWriter(c.Response())
+	if err := h.service.WriteExport(c.Request().Context(), tenantID, archive); err != nil {
+		c.Logger().Errorf("attachment export failed: %v", err)
+		return nil
+	}
+	return archive.Close()
+}
+
+// attachmentError maps service errors to HTTP responses
+func attachmentError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrUnknownEntityType), errors.Is(err, domain.ErrUnsupportedFileType):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrFileTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrEntityNotFound), errors.Is(err, domain.ErrAttachmentNotFound), errors.Is(err, storage.ErrObjectNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Upload was interrupted"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
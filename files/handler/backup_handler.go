@@ -0,0 +1,472 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/security"
+	"github.com/aceextension/files/domain"
+	"github.com/aceextension/files/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// backupDownloadExpiry is how long the link a download redirects to stays valid
+const backupDownloadExpiry = 15 * time.Minute
+
+type BackupHandler struct {
+	service service.BackupService
+}
+
+func NewBackupHandler(service service.BackupService) *BackupHandler {
+	return &BackupHandler{service: service}
+}
+
+// BackupScheduleRequest holds the settings of a backup schedule
+type BackupScheduleRequest struct {
+	Name        string   `json:"name"`
+	Frequency   string   `json:"frequency"`   // daily, weekly
+	Weekday     int      `json:"weekday"`     // 0 (Sunday) to 6, weekly only
+	Hour        int      `json:"hour"`        // 0-23, UTC
+	Mode        string   `json:"mode"`        // full, delta
+	Destination string   `json:"destination"` // minio, s3, email
+	Path        string   `json:"path"`
+	Emails      []string `json:"emails"` // Link recipients for the email destination
+	IsActive    *bool    `json:"isActive"`
+}
+
+func (r BackupScheduleRequest) input() domain.BackupScheduleInput {
+	active := true
+	if r.IsActive != nil {
+		active = *r.IsActive
+	}
+	return domain.BackupScheduleInput{
+		Name:        r.Name,
+		Frequency:   domain.BackupFrequency(r.Frequency),
+		Weekday:     r.Weekday,
+		Hour:        r.Hour,
+		Mode:        domain.BackupMode(r.Mode),
+		Destination: domain.BackupDestination(r.Destination),
+		Path:        r.Path,
+		Emails:      r.Emails,
+		IsActive:    active,
+	}
+}
+
+// BackupScheduleResponse is the API representation of a backup schedule
+type BackupScheduleResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Frequency   string     `json:"frequency"`
+	Weekday     int        `json:"weekday"`
+	Hour        int        `json:"hour"`
+	Mode        string     `json:"mode"`
+	Destination string     `json:"destination"`
+	Path        string     `json:"path"`
+	Emails      []string   `json:"emails"`
+	IsActive    bool       `json:"isActive"`
+	NextRunAt   *string    `json:"nextRunAt"`
+	LastRunAt   *string    `json:"lastRunAt"`
+	CreatedBy   *uuid.UUID `json:"createdBy"`
+	CreatedAt   string     `json:"createdAt"`
+	UpdatedAt   string     `json:"updatedAt"`
+}
+
+// BackupRunResponse is one entry of a schedule's run history
+type BackupRunResponse struct {
+	ID          uuid.UUID            `json:"id"`
+	ScheduleID  uuid.UUID            `json:"scheduleId"`
+	Trigger     string               `json:"trigger"`
+	Mode        string               `json:"mode"`
+	Since       *string              `json:"since"`
+	Until       string               `json:"until"`
+	Status      string               `json:"status"`
+	Destination string               `json:"destination"`
+	Location    *string              `json:"location"`
+	SizeBytes   *int64               `json:"sizeBytes"`
+	Checksum    *string              `json:"checksum"` // SHA-256, hex
+	Tables      []domain.BackupTable `json:"tables"`
+	Error       *string              `json:"error"`
+	TriggeredBy *uuid.UUID           `json:"triggeredBy"`
+	StartedAt   string               `json:"startedAt"`
+	FinishedAt  *string              `json:"finishedAt"`
+}
+
+func toBackupScheduleResponse(s *domain.BackupSchedule) BackupScheduleResponse {
+	emails := s.Emails
+	if emails == nil {
+		emails = []string{}
+	}
+	return BackupScheduleResponse{
+		ID:          s.ID,
+		Name:        s.Name,
+		Frequency:   string(s.Frequency),
+		Weekday:     s.Weekday,
+		Hour:        s.Hour,
+		Mode:        string(s.Mode),
+		Destination: string(s.Destination),
+		Path:        s.Path,
+		Emails:      emails,
+		IsActive:    s.IsActive,
+		NextRunAt:   formatTime(s.NextRunAt),
+		LastRunAt:   formatTime(s.LastRunAt),
+		CreatedBy:   s.CreatedBy,
+		CreatedAt:   s.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:   s.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func toBackupRunResponse(r *domain.BackupRun) BackupRunResponse {
+	tables := r.Tables
+	if tables == nil {
+		tables = []domain.BackupTable{}
+	}
+	return BackupRunResponse{
+		ID:          r.ID,
+		ScheduleID:  r.ScheduleID,
+		Trigger:     string(r.Trigger),
+		Mode:        string(r.Mode),
+		Since:       formatTime(r.Since),
+		Until:       r.Until.Format("2006-01-02T15:04:05Z07:00"),
+		Status:      string(r.Status),
+		Destination: string(r.Destination),
+		Location:    r.Location,
+		SizeBytes:   r.SizeBytes,
+		Checksum:    r.Checksum,
+		Tables:      tables,
+		Error:       r.Error,
+		TriggeredBy: r.TriggeredBy,
+		StartedAt:   r.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
+		FinishedAt:  formatTime(r.FinishedAt),
+	}
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format("2006-01-02T15:04:05Z07:00")
+	return &s
+}
+
+// ListSchedules lists the tenant's backup schedules
+// @Summary List Backup Schedules
+// @Description List the tenant's scheduled data backups with their next and last run
+// @Tags Files
+// @Produce json
+// @Success 200 {array} BackupScheduleResponse
+// @Router /api/v1/files/backups/schedules [get]
+func (h *BackupHandler) ListSchedules(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	schedules, err := h.service.ListSchedules(c.Request().Context(), tenantID)
+	if err != nil {
+		return backupError(c, err)
+	}
+
+	resp := make([]BackupScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		resp[i] = toBackupScheduleResponse(schedule)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// CreateSchedule creates a backup schedule
+// @Summary Create Backup Schedule
+// @Description Schedule a daily or weekly export of the tenant's data, full or only rows changed since the last completed run, to the platform store (minio), the tenant's bucket (s3, configured as the backup_s3 integration) or as an emailed link
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Param request body BackupScheduleRequest true "Schedule settings"
+// @Success 201 {object} BackupScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/files/backups/schedules [post]
+func (h *BackupHandler) CreateSchedule(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	var req BackupScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	var createdBy *uuid.UUID
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		createdBy = &userID
+	}
+
+	schedule, err := h.service.CreateSchedule(c.Request().Context(), tenantID, req.input(), createdBy)
+	if err != nil {
+		return backupError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toBackupScheduleResponse(schedule))
+}
+
+// GetSchedule retrieves a backup schedule
+// @Summary Get Backup Schedule
+// @Tags Files
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} BackupScheduleResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/files/backups/schedules/{id} [get]
+func (h *BackupHandler) GetSchedule(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid schedule ID"})
+	}
+
+	schedule, err := h.service.GetSchedule(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return backupError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBackupScheduleResponse(schedule))
+}
+
+// UpdateSchedule replaces a backup schedule's settings
+// @Summary Update Backup Schedule
+// @Description Replace a schedule's settings; the next run is recomputed. Changing the mode to delta starts from the last completed run.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param request body BackupScheduleRequest true "Schedule settings"
+// @Success 200 {object} BackupScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/files/backups/schedules/{id} [put]
+func (h *BackupHandler) UpdateSchedule(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid schedule ID"})
+	}
+
+	var req BackupScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	schedule, err := h.service.UpdateSchedule(c.Request().Context(), tenantID, id, req.input())
+	if err != nil {
+		return backupError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBackupScheduleResponse(schedule))
+}
+
+// DeleteSchedule removes a backup schedule
+// @Summary Delete Backup Schedule
+// @Description Remove a schedule and its run history. Archives already delivered are kept in their destination.
+// @Tags Files
+// @Param id path string true "Schedule ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/files/backups/schedules/{id} [delete]
+func (h *BackupHandler) DeleteSchedule(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid schedule ID"})
+	}
+
+	if err := h.service.DeleteSchedule(c.Request().Context(), tenantID, id); err != nil {
+		return backupError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RunSchedule starts a backup outside the schedule
+// @Summary Run Backup Now
+// @Description Start a run of the schedule now. The archive is built in the background; poll the run for its status.
+// @Tags Files
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 202 {object} BackupRunResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/files/backups/schedules/{id}/run [post]
+func (h *BackupHandler) RunSchedule(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid schedule ID"})
+	}
+
+	var triggeredBy *uuid.UUID
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		triggeredBy = &userID
+	}
+
+	run, err := h.service.RunNow(c.Request().Context(), tenantID, id, triggeredBy)
+	if err != nil {
+		return backupError(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, toBackupRunResponse(run))
+}
+
+// ListRuns lists a schedule's run history
+// @Summary List Backup Runs
+// @Description List a schedule's runs, newest first, with archive size, SHA-256 checksum and rows per table
+// @Tags Files
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset"
+// @Success 200 {array} BackupRunResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/files/backups/schedules/{id}/runs [get]
+func (h *BackupHandler) ListRuns(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid schedule ID"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	runs, err := h.service.ListRuns(c.Request().Context(), tenantID, id, limit, offset)
+	if err != nil {
+		return backupError(c, err)
+	}
+
+	resp := make([]BackupRunResponse, len(runs))
+	for i, run := range runs {
+		resp[i] = toBackupRunResponse(run)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetRun retrieves a backup run
+// @Summary Get Backup Run
+// @Tags Files
+// @Produce json
+// @Param id path string true "Run ID"
+// @Success 200 {object} BackupRunResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/files/backups/runs/{id} [get]
+func (h *BackupHandler) GetRun(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid run ID"})
+	}
+
+	run, err := h.service.GetRun(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return backupError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toBackupRunResponse(run))
+}
+
+// DownloadRun downloads a backup archive
+// @Summary Download Backup
+// @Description Download a completed run's archive. Redirects to a short-lived link when the store can presign; archives delivered to the tenant's own bucket are not downloadable here.
+// @Tags Files
+// @Produce application/zip
+// @Param id path string true "Run ID"
+// @Success 200 {file} binary
+// @Success 302
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/files/backups/runs/{id}/download [get]
+func (h *BackupHandler) DownloadRun(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid run ID"})
+	}
+
+	run, err := h.service.GetRun(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return backupError(c, err)
+	}
+
+	link, err := h.service.DownloadURL(c.Request().Context(), run, backupDownloadExpiry)
+	if err != nil {
+		return backupError(c, err)
+	}
+	if link != "" {
+		return c.Redirect(http.StatusFound, link)
+	}
+
+	content, err := h.service.Open(c.Request().Context(), run)
+	if err != nil {
+		return backupError(c, err)
+	}
+	defer content.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", path.Base(*run.Location)))
+	if run.SizeBytes != nil {
+		c.Response().Header().Set(echo.HeaderContentLength, fmt.Sprint(*run.SizeBytes))
+	}
+	return c.Stream(http.StatusOK, "application/zip", content)
+}
+
+// backupError maps backup errors to HTTP statuses
+func backupError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidBackupSchedule):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrBackupScheduleNotFound), errors.Is(err, domain.ErrBackupRunNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrBackupRunning), errors.Is(err, domain.ErrBackupNotDownloadable):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, security.ErrNoCredentials):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "the backup_s3 integration is not configured"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
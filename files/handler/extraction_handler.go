@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/files/domain"
+	"github.com/aceextension/files/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ExtractionHandler struct {
+	service service.ExtractionService
+}
+
+func NewExtractionHandler(service service.ExtractionService) *ExtractionHandler {
+	return &ExtractionHandler{service: service}
+}
+
+// EnqueueExtractionRequest asks for a document to be read
+type EnqueueExtractionRequest struct {
+	Kind string `json:"kind"` // pan_certificate, bill
+}
+
+// ConfirmExtractionRequest carries the reviewer's corrections, keyed by field name
+type ConfirmExtractionRequest struct {
+	Fields map[string]string `json:"fields"`
+}
+
+// ExtractionResponse is the API representation of a document extraction
+type ExtractionResponse struct {
+	ID           uuid.UUID               `json:"id"`
+	AttachmentID uuid.UUID               `json:"attachmentId"`
+	Kind         string                  `json:"kind"`
+	Status       string                  `json:"status"`
+	Fields       []domain.ExtractedField `json:"fields"`
+	Confidence   float64                 `json:"confidence"`
+	Text         string                  `json:"text,omitempty"`
+	Error        *string                 `json:"error"`
+	Attempts     int                     `json:"attempts"`
+	RequestedBy  *uuid.UUID              `json:"requestedBy"`
+	ReviewedBy   *uuid.UUID              `json:"reviewedBy"`
+	ReviewedAt   *string                 `json:"reviewedAt"`
+	CreatedAt    string                  `json:"createdAt"`
+	UpdatedAt    string                  `json:"updatedAt"`
+}
+
+func toExtractionResponse(e *domain.Extraction) ExtractionResponse {
+	fields := e.Fields
+	if fields == nil {
+		fields = []domain.ExtractedField{}
+	}
+	resp := ExtractionResponse{
+		ID:           e.ID,
+		AttachmentID: e.AttachmentID,
+		Kind:         string(e.Kind),
+		Status:       string(e.Status),
+		Fields:       fields,
+		Confidence:   e.Confidence,
+		Text:         e.Text,
+		Error:        e.Error,
+		Attempts:     e.Attempts,
+		RequestedBy:  e.RequestedBy,
+		ReviewedBy:   e.ReviewedBy,
+		CreatedAt:    e.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:    e.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if e.ReviewedAt != nil {
+		reviewedAt := e.ReviewedAt.Format("2006-01-02T15:04:05Z07:00")
+		resp.ReviewedAt = &reviewedAt
+	}
+	return resp
+}
+
+// EnqueueExtraction queues an attachment for OCR
+// @Summary Extract Document Data
+// @Description Queue an attachment (PAN certificate or bill) to have its fields read by OCR. Fields below the confidence threshold wait for confirmation.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Param id path string true "Attachment ID"
+// @Param request body EnqueueExtractionRequest true "Document kind"
+// @Success 202 {object} ExtractionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /api/v1/files/attachments/{id}/extractions [post]
+func (h *ExtractionHandler) EnqueueExtraction(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	attachmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid attachment ID"})
+	}
+
+	var req EnqueueExtractionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	extraction, err := h.service.Enqueue(c.Request().Context(), tenantID, attachmentID, domain.DocumentKind(req.Kind), &userID)
+	if err != nil {
+		return extractionError(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, toExtractionResponse(extraction))
+}
+
+// ListAttachmentExtractions lists the extractions of an attachment
+// @Summary List Document Extractions
+// @Description List the OCR results of an attachment, newest first
+// @Tags Files
+// @Produce json
+// @Param id path string true "Attachment ID"
+// @Success 200 {array} ExtractionResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/files/attachments/{id}/extractions [get]
+func (h *ExtractionHandler) ListAttachmentExtractions(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	attachmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid attachment ID"})
+	}
+
+	extractions, err := h.service.ListByAttachment(c.Request().Context(), tenantID, attachmentID)
+	if err != nil {
+		return extractionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toExtractionResponses(extractions))
+}
+
+// ListExtractions lists extractions by status
+// @Summary List Extractions By Status
+// @Description List the tenant's extractions in a status, oldest first. needs_review (the default) is the queue of values waiting for confirmation.
+// @Tags Files
+// @Produce json
+// @Param status query string false "pending, needs_review, completed, confirmed or failed" default(needs_review)
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} ExtractionResponse
+// @Router /api/v1/files/extractions [get]
+func (h *ExtractionHandler) ListExtractions(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	status := domain.ExtractionNeedsReview
+	if value := c.QueryParam("status"); value != "" {
+		status = domain.ExtractionStatus(value)
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	extractions, err := h.service.ListByStatus(c.Request().Context(), tenantID, status, limit, offset)
+	if err != nil {
+		return extractionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toExtractionResponses(extractions))
+}
+
+// GetExtraction retrieves an extraction
+// @Summary Get Extraction
+// @Description Get the fields read from a document with their confidence and the recognised text
+// @Tags Files
+// @Produce json
+// @Param id path string true "Extraction ID"
+// @Success 200 {object} ExtractionResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/files/extractions/{id} [get]
+func (h *ExtractionHandler) GetExtraction(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid extraction ID"})
+	}
+
+	extraction, err := h.service.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return extractionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toExtractionResponse(extraction))
+}
+
+// ConfirmExtraction accepts an extraction's fields
+// @Summary Confirm Extraction
+// @Description Confirm the fields read from a document after checking them, correcting any that are wrong
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Param id path string true "Extraction ID"
+// @Param request body ConfirmExtractionRequest false "Corrected values by field name"
+// @Success 200 {object} ExtractionResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/files/extractions/{id}/confirm [post]
+func (h *ExtractionHandler) ConfirmExtraction(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid extraction ID"})
+	}
+
+	var req ConfirmExtractionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	extraction, err := h.service.Confirm(c.Request().Context(), tenantID, id, userID, req.Fields)
+	if err != nil {
+		return extractionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toExtractionResponse(extraction))
+}
+
+func toExtractionResponses(extractions []*domain.Extraction) []ExtractionResponse {
+	resp := make([]ExtractionResponse, len(extractions))
+	for i, e := range extractions {
+		resp[i] = toExtractionResponse(e)
+	}
+	return resp
+}
+
+func extractionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrUnknownDocumentKind):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrAttachmentNotFound), errors.Is(err, domain.ErrExtractionNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrExtractionState):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrOCRUnavailable):
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(e *echo.Group, attachmentHandler *AttachmentHandler, extractionHandler *ExtractionHandler) {
+	filesGroup := e.Group("/files")
+
+	// Attachments
+	filesGroup.GET("/attachments", attachmentHandler.ListAttachments)
+	filesGroup.POST("/attachments", attachmentHandler.UploadAttachment)
+	filesGroup.GET("/attachments/export", attachmentHandler.ExportAttachments)
+	filesGroup.GET("/attachments/:id/download", attachmentHandler.DownloadAttachment)
+	filesGroup.DELETE("/attachments/:id", attachmentHandler.DeleteAttachment)
+
+	// OCR extraction
+	filesGroup.POST("/attachments/:id/extractions", extractionHandler.EnqueueExtraction)
+	filesGroup.GET("/attachments/:id/extractions", extractionHandler.ListAttachmentExtractions)
+	filesGroup.GET("/extractions", extractionHandler.ListExtractions)
+	filesGroup.GET("/extractions/:id", extractionHandler.GetExtraction)
+	filesGroup.POST("/extractions/:id/confirm", extractionHandler.ConfirmExtraction)
+}
+
+// RegisterBackupRoutes mounts backup schedules and their run history on g. The group
+// must restrict access to tenant owners and admins (e.g. /api/v1/files/backups behind
+// the JWT middleware and RequireRole("owner", "admin")), since archives hold all the tenant's data.
+func RegisterBackupRoutes(g *echo.Group, backupHandler *BackupHandler) {
+	g.GET("/schedules", backupHandler.ListSchedules)
+	g.POST("/schedules", backupHandler.CreateSchedule)
+	g.GET("/schedules/:id", backupHandler.GetSchedule)
+	g.PUT("/schedules/:id", backupHandler.UpdateSchedule)
+	g.DELETE("/schedules/:id", backupHandler.DeleteSchedule)
+	g.POST("/schedules/:id/run", backupHandler.RunSchedule)
+	g.GET("/schedules/:id/runs", backupHandler.ListRuns)
+	g.GET("/runs/:id", backupHandler.GetRun)
+	g.GET("/runs/:id/download", backupHandler.DownloadRun)
+}
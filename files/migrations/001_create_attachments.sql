@@ -0,0 +1,39 @@
+-- ============================================================================
+-- ATTACHMENTS
+-- Supporting documents (scanned bills, receipts) linked to journal entries,
+-- invoices and purchase bills. File content lives in object storage.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    entity_type VARCHAR(50) NOT NULL, -- journal_entry, invoice, purchase_bill
+    entity_id UUID NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL, -- SHA-256 hex
+    storage_key VARCHAR(255) NOT NULL,
+    description TEXT,
+    uploaded_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+
+    CONSTRAINT fk_attachment_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_entity
+    ON attachments(tenant_id, entity_type, entity_id) WHERE deleted_at IS NULL;
+
+-- ============================================================================
+-- ROW LEVEL SECURITY
+-- ============================================================================
+
+ALTER TABLE attachments ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON attachments;
+CREATE POLICY tenant_isolation ON attachments
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,47 @@
+-- ============================================================================
+-- DOCUMENT EXTRACTIONS
+-- OCR queue for uploaded documents (PAN certificates, bills). Each row holds
+-- the fields read from one attachment with their confidence; fields below the
+-- threshold wait for a person to confirm them.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS document_extractions (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    attachment_id UUID NOT NULL REFERENCES attachments(id),
+    kind VARCHAR(30) NOT NULL, -- pan_certificate, bill
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    fields JSONB NOT NULL DEFAULT '[]', -- [{name, value, confidence, needsReview}]
+    confidence DECIMAL(4, 3) NOT NULL DEFAULT 0,
+    text TEXT NOT NULL DEFAULT '',
+    error TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    requested_by UUID,
+    reviewed_by UUID,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT fk_document_extraction_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT chk_document_extraction_status CHECK (status IN ('pending', 'needs_review', 'completed', 'confirmed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_extractions_attachment
+    ON document_extractions(tenant_id, attachment_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_document_extractions_status
+    ON document_extractions(tenant_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_document_extractions_pending
+    ON document_extractions(created_at) WHERE status = 'pending';
+
+-- ============================================================================
+-- ROW LEVEL SECURITY
+-- ============================================================================
+
+ALTER TABLE document_extractions ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON document_extractions;
+CREATE POLICY tenant_isolation ON document_extractions
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,86 @@
+-- ============================================================================
+-- BACKUP SCHEDULES
+-- Daily or weekly exports of a tenant's data, either full or only the rows
+-- changed since the last completed run, delivered to the platform's export
+-- store, the tenant's own S3 bucket or as an emailed download link.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS backup_schedules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    frequency VARCHAR(10) NOT NULL, -- daily, weekly
+    weekday SMALLINT NOT NULL DEFAULT 0, -- 0 (Sunday) to 6, weekly only
+    hour SMALLINT NOT NULL DEFAULT 0, -- UTC
+    mode VARCHAR(10) NOT NULL, -- full, delta
+    destination VARCHAR(10) NOT NULL, -- minio, s3, email
+    path VARCHAR(200) NOT NULL DEFAULT '',
+    emails TEXT[] NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ,
+    last_run_at TIMESTAMPTZ,
+    created_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT fk_backup_schedule_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT chk_backup_schedule_frequency CHECK (frequency IN ('daily', 'weekly')),
+    CONSTRAINT chk_backup_schedule_mode CHECK (mode IN ('full', 'delta')),
+    CONSTRAINT chk_backup_schedule_destination CHECK (destination IN ('minio', 's3', 'email')),
+    CONSTRAINT chk_backup_schedule_slot CHECK (weekday BETWEEN 0 AND 6 AND hour BETWEEN 0 AND 23)
+);
+
+CREATE INDEX IF NOT EXISTS idx_backup_schedules_tenant
+    ON backup_schedules(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_backup_schedules_due
+    ON backup_schedules(next_run_at) WHERE is_active;
+
+CREATE TABLE IF NOT EXISTS backup_runs (
+    id UUID PRIMARY KEY,
+    schedule_id UUID NOT NULL REFERENCES backup_schedules(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    trigger VARCHAR(10) NOT NULL, -- schedule, manual
+    mode VARCHAR(10) NOT NULL,
+    since TIMESTAMPTZ, -- Delta runs export rows changed after this
+    until TIMESTAMPTZ NOT NULL, -- Snapshot time, the next delta run's since
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    destination VARCHAR(10) NOT NULL,
+    location TEXT,
+    size_bytes BIGINT,
+    checksum VARCHAR(64), -- SHA-256 of the archive, hex
+    tables JSONB NOT NULL DEFAULT '[]', -- [{name, rows}]
+    error TEXT,
+    triggered_by UUID,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+
+    CONSTRAINT fk_backup_run_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT chk_backup_run_status CHECK (status IN ('running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_backup_runs_schedule
+    ON backup_runs(tenant_id, schedule_id, started_at DESC);
+-- One run of a schedule at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_backup_runs_running
+    ON backup_runs(schedule_id) WHERE status = 'running';
+
+-- ============================================================================
+-- ROW LEVEL SECURITY
+-- ============================================================================
+
+ALTER TABLE backup_schedules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE backup_runs ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON backup_schedules;
+CREATE POLICY tenant_isolation ON backup_schedules
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+DROP POLICY IF EXISTS tenant_isolation ON backup_runs;
+CREATE POLICY tenant_isolation ON backup_runs
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
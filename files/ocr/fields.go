@@ -0,0 +1,207 @@
+package ocr
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aceextension/files/domain"
+	"github.com/aceextension/fiscal/utils"
+)
+
+// Field confidence is the OCR confidence scaled by how the value was found
+const (
+	labelledWeight     = 1.0 // Value follows its label, e.g. "Invoice No: 123"
+	unlabelledWeight   = 0.6 // Best guess without a label
+	inconsistentWeight = 0.7 // Sub-total plus VAT does not add up to the total
+)
+
+// minBSYear separates Bikram Sambat years (2060 and later) from Gregorian ones on bills
+const minBSYear = 2060
+
+var (
+	panPattern        = regexp.MustCompile(`(?i)\b(?:pan|vat)\)?\s*(?:no|number|#)?\.?\s*[:\-]?\s*(\d{9})\b`)
+	nineDigitsPattern = regexp.MustCompile(`\b\d{9}\b`)
+	billNumberPattern = regexp.MustCompile(`(?i)\b(?:invoice|bill)\s*(?:no|number|#)\.?\s*[:\-]?\s*([A-Z0-9][A-Z0-9/\-]*)`)
+	billDatePattern   = regexp.MustCompile(`(?i)\b(?:date|miti)\b[^0-9\n]{0,12}(\d{1,4}[./-]\d{1,2}[./-]\d{1,4})`)
+	anyDatePattern    = regexp.MustCompile(`\b(\d{4}[./-]\d{1,2}[./-]\d{1,2}|\d{1,2}[./-]\d{1,2}[./-]\d{4})\b`)
+	amountPattern     = regexp.MustCompile(`\d[\d,]*(?:\.\d{1,2})?`)
+	subTotalLabel     = regexp.MustCompile(`(?i)\b(?:sub\s*-?\s*total|taxable\s+amount)\b`)
+	vatLabel          = regexp.MustCompile(`(?i)\bvat\b`)
+	grandTotalLabel   = regexp.MustCompile(`(?i)\b(?:grand\s+total|net\s+amount|total\s+amount|net\s+total|amount\s+payable)\b`)
+	totalLabel        = regexp.MustCompile(`(?i)\btotal\b`)
+	taxpayerLabel     = regexp.MustCompile(`(?i)(?:name\s+of\s+(?:the\s+)?(?:taxpayer|business|firm)|taxpayer'?s?\s+name|business\s+name|करदाताको\s+नाम)\s*[:\-]?\s*(.+)`)
+)
+
+// devanagariDigits maps Nepali numerals to ASCII so the patterns match either script
+var devanagariDigits = strings.NewReplacer(
+	"०", "0", "१", "1", "२", "2", "३", "3", "४", "4",
+	"५", "5", "६", "6", "७", "7", "८", "8", "९", "9",
+)
+
+// Fields reads the fields of a document kind from recognised text
+func Fields(kind domain.DocumentKind, result *Result) ([]domain.ExtractedField, error) {
+	switch kind {
+	case domain.DocumentBill:
+		return BillFields(result), nil
+	case domain.DocumentPANCertificate:
+		return PANCertificateFields(result), nil
+	default:
+		return nil, domain.ErrUnknownDocumentKind
+	}
+}
+
+// BillFields reads bill number, date, supplier PAN and amounts from a bill.
+// The first PAN on a bill is taken as the seller's; Bikram Sambat dates are
+// converted to AD.
+func BillFields(result *Result) []domain.ExtractedField {
+	text := devanagariDigits.Replace(result.Text)
+	var fields []domain.ExtractedField
+	add := func(name, value string, weight float64) {
+		fields = append(fields, domain.ExtractedField{
+			Name:       name,
+			Value:      value,
+			Confidence: math.Round(result.Confidence*weight*1000) / 1000,
+		})
+	}
+
+	if match := billNumberPattern.FindStringSubmatch(text); match != nil {
+		add(domain.FieldBillNumber, match[1], labelledWeight)
+	}
+
+	if match := billDatePattern.FindStringSubmatch(text); match != nil {
+		if date, ok := parseBillDate(match[1]); ok {
+			add(domain.FieldBillDate, date, labelledWeight)
+		}
+	} else if match := anyDatePattern.FindStringSubmatch(text); match != nil {
+		if date, ok := parseBillDate(match[1]); ok {
+			add(domain.FieldBillDate, date, unlabelledWeight)
+		}
+	}
+
+	if match := panPattern.FindStringSubmatch(text); match != nil {
+		add(domain.FieldPANNumber, match[1], labelledWeight)
+	}
+
+	subTotal, hasSubTotal := labelledAmount(text, subTotalLabel, nil)
+	vat, hasVAT := labelledAmount(text, vatLabel, panPattern) // Not the "VAT No" line
+	total, hasTotal := labelledAmount(text, grandTotalLabel, nil)
+	if !hasTotal {
+		// A plain "Total" line, but not the sub-total one
+		total, hasTotal = labelledAmount(text, totalLabel, subTotalLabel)
+	}
+
+	amountWeight := labelledWeight
+	if hasSubTotal && hasVAT && hasTotal && math.Abs(subTotal+vat-total) > 1 {
+		amountWeight = inconsistentWeight
+	}
+	if hasSubTotal {
+		add(domain.FieldSubTotal, formatAmount(subTotal), amountWeight)
+	}
+	if hasVAT {
+		add(domain.FieldVATAmount, formatAmount(vat), amountWeight)
+	}
+	if hasTotal {
+		add(domain.FieldTotalAmount, formatAmount(total), amountWeight)
+	}
+
+	return fields
+}
+
+// PANCertificateFields reads the PAN and taxpayer name from an IRD registration certificate
+func PANCertificateFields(result *Result) []domain.ExtractedField {
+	text := devanagariDigits.Replace(result.Text)
+	var fields []domain.ExtractedField
+	add := func(name, value string, weight float64) {
+		fields = append(fields, domain.ExtractedField{
+			Name:       name,
+			Value:      value,
+			Confidence: math.Round(result.Confidence*weight*1000) / 1000,
+		})
+	}
+
+	if match := panPattern.FindStringSubmatch(text); match != nil {
+		add(domain.FieldPANNumber, match[1], labelledWeight)
+	} else if matches := nineDigitsPattern.FindAllString(text, -1); len(matches) == 1 {
+		add(domain.FieldPANNumber, matches[0], unlabelledWeight)
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if match := taxpayerLabel.FindStringSubmatch(line); match != nil {
+			if name := strings.Trim(strings.TrimSpace(match[1]), ":-"); name != "" {
+				add(domain.FieldTaxpayerName, strings.TrimSpace(name), labelledWeight)
+				break
+			}
+		}
+	}
+
+	return fields
+}
+
+// labelledAmount returns the last amount on the first line carrying the label
+// and not the excluded label
+func labelledAmount(text string, label, exclude *regexp.Regexp) (float64, bool) {
+	for _, line := range strings.Split(text, "\n") {
+		loc := label.FindStringIndex(line)
+		if loc == nil || (exclude != nil && exclude.MatchString(line)) {
+			continue
+		}
+		rest := line[loc[1]:]
+		rest = strings.NewReplacer("13%", "", "13 %", "").Replace(rest) // VAT rate, not an amount
+		amounts := amountPattern.FindAllString(rest, -1)
+		if len(amounts) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.ReplaceAll(amounts[len(amounts)-1], ",", ""), 64)
+		if err != nil {
+			continue
+		}
+		return value, true
+	}
+	return 0, false
+}
+
+// parseBillDate reads YYYY-MM-DD (AD or BS) or DD/MM/YYYY (AD) and returns the AD date as YYYY-MM-DD
+func parseBillDate(value string) (string, bool) {
+	parts := strings.FieldsFunc(value, func(r rune) bool { return r == '-' || r == '/' || r == '.' })
+	if len(parts) != 3 {
+		return "", false
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return "", false
+		}
+		numbers[i] = n
+	}
+
+	year, month, day := numbers[0], numbers[1], numbers[2]
+	if len(parts[2]) == 4 {
+		day, month, year = numbers[0], numbers[1], numbers[2]
+	}
+	if month < 1 || month > 12 || day < 1 || day > 32 {
+		return "", false
+	}
+
+	if year >= minBSYear {
+		bs, err := utils.ParseNepaliDate(fmt.Sprintf("%04d-%02d-%02d", year, month, day))
+		if err != nil {
+			return "", false
+		}
+		return utils.BSToAD(bs).Format("2006-01-02"), true
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return "", false
+	}
+	return date.Format("2006-01-02"), true
+}
+
+func formatAmount(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
@@ -0,0 +1,22 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnsupportedContent is returned when an engine cannot read a file type
+var ErrUnsupportedContent = errors.New("OCR engine cannot read this file type")
+
+// Result is the text recognised in a document
+type Result struct {
+	Text       string  // Lines separated by newlines, in reading order
+	Confidence float64 // Mean word confidence, 0-1
+}
+
+// Engine recognises the text in a scanned document. Implementations wrap a
+// Tesseract install or a cloud OCR API; the engine only returns text, fields
+// are read from it by BillFields and PANCertificateFields.
+type Engine interface {
+	Recognize(ctx context.Context, contentType string, content []byte) (*Result, error)
+}
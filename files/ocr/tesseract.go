@@ -0,0 +1,101 @@
+package ocr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// tesseractImageTypes are the formats Tesseract reads (through Leptonica); PDFs need a cloud engine
+var tesseractImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// TesseractEngine runs the tesseract command-line tool, installed in the API
+// image or a container sharing its filesystem
+type TesseractEngine struct {
+	path      string
+	languages string
+}
+
+// NewTesseractEngine creates an engine for the tesseract binary at path.
+// languages is a Tesseract language list such as "eng+nep".
+func NewTesseractEngine(path, languages string) *TesseractEngine {
+	if languages == "" {
+		languages = "eng"
+	}
+	return &TesseractEngine{path: path, languages: languages}
+}
+
+// Recognize pipes the image through tesseract and reads its TSV output for word confidences
+func (t *TesseractEngine) Recognize(ctx context.Context, contentType string, content []byte) (*Result, error) {
+	if !tesseractImageTypes[contentType] {
+		return nil, ErrUnsupportedContent
+	}
+
+	cmd := exec.CommandContext(ctx, t.path, "stdin", "stdout", "-l", t.languages, "tsv")
+	cmd.Stdin = bytes.NewReader(content)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("tesseract failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseTesseractTSV(&stdout)
+}
+
+// parseTesseractTSV joins the recognised words into lines and averages their confidence.
+// Columns: level page_num block_num par_num line_num word_num left top width height conf text
+func parseTesseractTSV(output *bytes.Buffer) (*Result, error) {
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var (
+		lines     []string
+		current   []string
+		lineKey   string
+		total     float64
+		wordCount int
+	)
+	for scanner.Scan() {
+		columns := strings.Split(scanner.Text(), "\t")
+		if len(columns) < 12 || columns[0] != "5" { // 5 = word
+			continue
+		}
+		word := strings.TrimSpace(columns[11])
+		confidence, err := strconv.ParseFloat(columns[10], 64)
+		if word == "" || err != nil || confidence < 0 {
+			continue
+		}
+
+		key := strings.Join(columns[1:5], ".") // page.block.paragraph.line
+		if key != lineKey && len(current) > 0 {
+			lines = append(lines, strings.Join(current, " "))
+			current = nil
+		}
+		lineKey = key
+		current = append(current, word)
+		total += confidence
+		wordCount++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tesseract output: %w", err)
+	}
+	if len(current) > 0 {
+		lines = append(lines, strings.Join(current, " "))
+	}
+
+	result := &Result{Text: strings.Join(lines, "\n")}
+	if wordCount > 0 {
+		result.Confidence = total / float64(wordCount) / 100
+	}
+	return result, nil
+}
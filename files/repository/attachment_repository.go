@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/files/domain"
+	"github.com/google/uuid"
+)
+
+// AttachmentRepository defines the interface for attachment metadata access
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *domain.Attachment) error
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Attachment, error)
+	// ListByEntity returns live attachments of one record, oldest first
+	ListByEntity(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) ([]*domain.Attachment, error)
+	// ListByTenant returns every live attachment of a tenant (for data exports)
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.Attachment, error)
+	// CountByEntities returns live attachment counts keyed by entity ID; entities without attachments are omitted
+	CountByEntities(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	SoftDelete(ctx context.Context, tenantID, id uuid.UUID) error
+}
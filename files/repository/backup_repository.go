@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/aceextension/files/domain"
+	"github.com/google/uuid"
+)
+
+// BackupRepository defines the interface for backup schedules and their run history
+type BackupRepository interface {
+	CreateSchedule(ctx context.Context, schedule *domain.BackupSchedule) error
+	GetSchedule(ctx context.Context, tenantID, id uuid.UUID) (*domain.BackupSchedule, error)
+	ListSchedules(ctx context.Context, tenantID uuid.UUID) ([]*domain.BackupSchedule, error)
+	UpdateSchedule(ctx context.Context, schedule *domain.BackupSchedule) error
+	DeleteSchedule(ctx context.Context, tenantID, id uuid.UUID) error
+	// ClaimDue takes an active schedule due at now across tenants and moves its next
+	// run to the following slot, skipping schedules another worker has locked; nil when none is due
+	ClaimDue(ctx context.Context, now time.Time) (*domain.BackupSchedule, error)
+
+	// CreateRun returns ErrBackupRunning while another run of the schedule is running
+	CreateRun(ctx context.Context, run *domain.BackupRun) error
+	GetRun(ctx context.Context, tenantID, id uuid.UUID) (*domain.BackupRun, error)
+	// ListRuns returns a schedule's runs, newest first
+	ListRuns(ctx context.Context, tenantID, scheduleID uuid.UUID, limit, offset int) ([]*domain.BackupRun, error)
+	FinishRun(ctx context.Context, run *domain.BackupRun) error
+	// LastCompletedUntil returns the snapshot time of the schedule's last completed run, nil when none
+	LastCompletedUntil(ctx context.Context, tenantID, scheduleID uuid.UUID) (*time.Time, error)
+	// FailStale marks runs still running since before the cutoff as failed, e.g. after a crash
+	FailStale(ctx context.Context, before time.Time, reason string) (int64, error)
+}
+
+// BackupSource is a table exported into backups. Tables with a tenant_id column are
+// filtered on it; child tables without one are reached through their parent.
+type BackupSource struct {
+	Table           string
+	Parent          string // Tenant table the rows belong to; empty for tenant tables
+	ForeignKey      string // Column of Table referencing Parent
+	ParentKey       string // Column of Parent it references
+	ChangedColumn   string // Timestamp delta runs filter on; empty exports every row
+	ChangedOnParent bool   // ChangedColumn is the parent's, for children without timestamps
+}
+
+// BackupDataRepository reads a tenant's rows for backup archives
+type BackupDataRepository interface {
+	// Sources discovers the tables holding tenant data, leaving out the given tables
+	Sources(ctx context.Context, exclude map[string]bool) ([]BackupSource, error)
+	// Dump writes each source's rows as JSON lines to the writer open returns for it,
+	// all from one snapshot. With since set, only rows changed in (since, until] are
+	// written, except from sources without a changed column.
+	Dump(ctx context.Context, tenantID uuid.UUID, sources []BackupSource, since *time.Time, until time.Time, open func(source BackupSource) (io.Writer, error)) ([]domain.BackupTable, error)
+}
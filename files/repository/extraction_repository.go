@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/files/domain"
+	"github.com/google/uuid"
+)
+
+// ExtractionRepository defines the interface for the document extraction queue
+type ExtractionRepository interface {
+	Create(ctx context.Context, extraction *domain.Extraction) error
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Extraction, error)
+	// ListByAttachment returns the extractions of one attachment, newest first
+	ListByAttachment(ctx context.Context, tenantID, attachmentID uuid.UUID) ([]*domain.Extraction, error)
+	// ListByStatus returns the tenant's extractions in a status, oldest first (the review queue)
+	ListByStatus(ctx context.Context, tenantID uuid.UUID, status domain.ExtractionStatus, limit, offset int) ([]*domain.Extraction, error)
+	// ListPending returns pending extractions across tenants, oldest first
+	ListPending(ctx context.Context, limit int) ([]*domain.Extraction, error)
+	Update(ctx context.Context, extraction *domain.Extraction) error
+}
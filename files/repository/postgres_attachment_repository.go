@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/files/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresAttachmentRepository implements AttachmentRepository using PostgreSQL
+type PostgresAttachmentRepository struct{}
+
+// NewPostgresAttachmentRepository creates a new PostgreSQL attachment repository
+func NewPostgresAttachmentRepository() *PostgresAttachmentRepository {
+	return &PostgresAttachmentRepository{}
+}
+
+const attachmentColumns = `
+	id, tenant_id, entity_type, entity_id, file_name, content_type, size_bytes,
+	checksum, storage_key, description, uploaded_by, created_at, deleted_at`
+
+// Create stores attachment metadata
+func (r *PostgresAttachmentRepository) Create(ctx context.Context, a *domain.Attachment) error {
+	query := `
+		INSERT INTO attachments (` + attachmentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		a.ID, a.TenantID, a.EntityType, a.EntityID, a.FileName, a.ContentType, a.SizeBytes,
+		a.Checksum, a.StorageKey, a.Description, a.UploadedBy, a.CreatedAt, a.DeletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a live attachment of the tenant
+func (r *PostgresAttachmentRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`
+	return r.scanAttachment(db.MainPool.QueryRow(ctx, query, id, tenantID))
+}
+
+// ListByEntity retrieves the live attachments of one record
+func (r *PostgresAttachmentRepository) ListByEntity(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) ([]*domain.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3 AND deleted_at IS NULL
+		ORDER BY created_at
+	`
+	return r.queryAttachments(ctx, query, tenantID, entityType, entityID)
+}
+
+// ListByTenant retrieves all live attachments of the tenant
+func (r *PostgresAttachmentRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		ORDER BY entity_type, entity_id, created_at
+	`
+	return r.queryAttachments(ctx, query, tenantID)
+}
+
+// CountByEntities counts live attachments per entity
+func (r *PostgresAttachmentRepository) CountByEntities(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int)
+	if len(entityIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT entity_id, COUNT(*)
+		FROM attachments
+		WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = ANY($3) AND deleted_at IS NULL
+		GROUP BY entity_id
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, entityType, entityIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count attachments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment count: %w", err)
+		}
+		counts[id] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return counts, nil
+}
+
+// SoftDelete hides an attachment while keeping the record for audit
+func (r *PostgresAttachmentRepository) SoftDelete(ctx context.Context, tenantID, id uuid.UUID) error {
+	query := `UPDATE attachments SET deleted_at = NOW() WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`
+
+	tag, err := db.MainPool.Exec(ctx, query, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresAttachmentRepository) queryAttachments(ctx context.Context, query string, args ...any) ([]*domain.Attachment, error) {
+	rows, err := db.MainPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []*domain.Attachment{}
+	for rows.Next() {
+		attachment, err := r.scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// scanAttachment scans a single attachment row
+func (r *PostgresAttachmentRepository) scanAttachment(row pgx.Row) (*domain.Attachment, error) {
+	var a domain.Attachment
+
+	err := row.Scan(
+		&a.ID, &a.TenantID, &a.EntityType, &a.EntityID, &a.FileName, &a.ContentType, &a.SizeBytes,
+		&a.Checksum, &a.StorageKey, &a.Description, &a.UploadedBy, &a.CreatedAt, &a.DeletedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("failed to scan attachment: %w", err)
+	}
+
+	return &a, nil
+}
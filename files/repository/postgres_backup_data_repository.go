@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/files/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresBackupDataRepository implements BackupDataRepository over the public schema
+type PostgresBackupDataRepository struct{}
+
+// NewPostgresBackupDataRepository creates a new PostgreSQL backup data repository
+func NewPostgresBackupDataRepository() *PostgresBackupDataRepository {
+	return &PostgresBackupDataRepository{}
+}
+
+// backupTableInfo is what the catalog says about one table
+type backupTableInfo struct {
+	tenant  bool
+	changed string
+}
+
+// Sources finds the tables with a tenant_id column, and tables without one that
+// reference a tenant table through a single-column foreign key (e.g. invoice lines)
+func (r *PostgresBackupDataRepository) Sources(ctx context.Context, exclude map[string]bool) ([]BackupSource, error) {
+	tablesQuery := `
+		SELECT c.table_name,
+		       BOOL_OR(c.column_name = 'tenant_id'),
+		       BOOL_OR(c.column_name = 'updated_at' AND c.data_type LIKE 'timestamp%'),
+		       BOOL_OR(c.column_name = 'created_at' AND c.data_type LIKE 'timestamp%')
+		FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = 'public' AND t.table_type = 'BASE TABLE'
+		GROUP BY c.table_name
+	`
+
+	rows, err := db.MainPool.Query(ctx, tablesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	tables := map[string]backupTableInfo{}
+	for rows.Next() {
+		var name string
+		var tenant, updated, created bool
+		if err := rows.Scan(&name, &tenant, &updated, &created); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan table: %w", err)
+		}
+		info := backupTableInfo{tenant: tenant}
+		switch {
+		case updated:
+			info.changed = "updated_at"
+		case created:
+			info.changed = "created_at"
+		}
+		tables[name] = info
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	var sources []BackupSource
+	for name, info := range tables {
+		if info.tenant && !exclude[name] {
+			sources = append(sources, BackupSource{Table: name, ChangedColumn: info.changed})
+		}
+	}
+
+	// The first foreign key (by constraint name) to a tenant table decides a child's parent
+	keysQuery := `
+		SELECT c.relname, p.relname, a.attname, pa.attname
+		FROM pg_constraint con
+		JOIN pg_class c ON c.oid = con.conrelid
+		JOIN pg_class p ON p.oid = con.confrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = con.conkey[1]
+		JOIN pg_attribute pa ON pa.attrelid = con.confrelid AND pa.attnum = con.confkey[1]
+		WHERE con.contype = 'f' AND n.nspname = 'public' AND CARDINALITY(con.conkey) = 1
+		ORDER BY c.relname, con.conname
+	`
+
+	rows, err = db.MainPool.Query(ctx, keysQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	children := map[string]bool{}
+	for rows.Next() {
+		var child, parent, foreignKey, parentKey string
+		if err := rows.Scan(&child, &parent, &foreignKey, &parentKey); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+		childInfo, ok := tables[child]
+		if !ok || childInfo.tenant || children[child] || exclude[child] {
+			continue
+		}
+		parentInfo, ok := tables[parent]
+		if !ok || !parentInfo.tenant || exclude[parent] {
+			continue
+		}
+
+		source := BackupSource{Table: child, Parent: parent, ForeignKey: foreignKey, ParentKey: parentKey, ChangedColumn: childInfo.changed}
+		if source.ChangedColumn == "" && parentInfo.changed != "" {
+			source.ChangedColumn, source.ChangedOnParent = parentInfo.changed, true
+		}
+		sources = append(sources, source)
+		children[child] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Table < sources[j].Table })
+	return sources, nil
+}
+
+// Dump reads every source in one read-only repeatable-read transaction, so the
+// archive is a consistent snapshot and until is a safe watermark for the next delta
+func (r *PostgresBackupDataRepository) Dump(ctx context.Context, tenantID uuid.UUID, sources []BackupSource, since *time.Time, until time.Time, open func(source BackupSource) (io.Writer, error)) ([]domain.BackupTable, error) {
+	tx, err := db.MainPool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin backup snapshot: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY`); err != nil {
+		return nil, fmt.Errorf("failed to start backup snapshot: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.current_tenant_id', $1, true)`, tenantID.String()); err != nil {
+		return nil, fmt.Errorf("failed to set backup tenant: %w", err)
+	}
+
+	tables := make([]domain.BackupTable, 0, len(sources))
+	for _, source := range sources {
+		w, err := open(source)
+		if err != nil {
+			return nil, err
+		}
+		count, err := dumpSource(ctx, tx, tenantID, source, since, until, w)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", source.Table, err)
+		}
+		tables = append(tables, domain.BackupTable{Name: source.Table, Rows: count})
+	}
+
+	return tables, nil
+}
+
+// dumpSource writes one table's rows as JSON lines
+func dumpSource(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID, source BackupSource, since *time.Time, until time.Time, w io.Writer) (int64, error) {
+	table := pgx.Identifier{source.Table}.Sanitize()
+	query := `SELECT row_to_json(t)::text FROM ` + table + ` t WHERE t.tenant_id = $1`
+	changed := "t"
+	if source.Parent != "" {
+		query = `SELECT row_to_json(t)::text FROM ` + table + ` t JOIN ` + pgx.Identifier{source.Parent}.Sanitize() + ` p ON p.` +
+			pgx.Identifier{source.ParentKey}.Sanitize() + ` = t.` + pgx.Identifier{source.ForeignKey}.Sanitize() + ` WHERE p.tenant_id = $1`
+		if source.ChangedOnParent {
+			changed = "p"
+		}
+	}
+
+	args := []any{tenantID}
+	if since != nil && source.ChangedColumn != "" {
+		column := changed + "." + pgx.Identifier{source.ChangedColumn}.Sanitize()
+		query += ` AND ` + column + ` > $2 AND ` + column + ` <= $3`
+		args = append(args, *since, until)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var count int64
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return count, err
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/files/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresBackupRepository implements BackupRepository using PostgreSQL
+type PostgresBackupRepository struct{}
+
+// NewPostgresBackupRepository creates a new PostgreSQL backup repository
+func NewPostgresBackupRepository() *PostgresBackupRepository {
+	return &PostgresBackupRepository{}
+}
+
+const backupScheduleColumns = `
+	id, tenant_id, name, frequency, weekday, hour, mode, destination, path, emails,
+	is_active, next_run_at, last_run_at, created_by, created_at, updated_at`
+
+const backupRunColumns = `
+	id, schedule_id, tenant_id, trigger, mode, since, until, status, destination, location,
+	size_bytes, checksum, tables, error, triggered_by, started_at, finished_at`
+
+// CreateSchedule saves a new schedule
+func (r *PostgresBackupRepository) CreateSchedule(ctx context.Context, s *domain.BackupSchedule) error {
+	query := `
+		INSERT INTO backup_schedules (` + backupScheduleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		s.ID, s.TenantID, s.Name, s.Frequency, s.Weekday, s.Hour, s.Mode, s.Destination, s.Path, emailList(s.Emails),
+		s.IsActive, s.NextRunAt, s.LastRunAt, s.CreatedBy, s.CreatedAt, s.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create backup schedule: %w", err)
+	}
+
+	return nil
+}
+
+// GetSchedule retrieves a schedule of the tenant
+func (r *PostgresBackupRepository) GetSchedule(ctx context.Context, tenantID, id uuid.UUID) (*domain.BackupSchedule, error) {
+	query := `SELECT ` + backupScheduleColumns + ` FROM backup_schedules WHERE id = $1 AND tenant_id = $2`
+	return r.scanSchedule(db.MainPool.QueryRow(ctx, query, id, tenantID))
+}
+
+// ListSchedules retrieves the tenant's schedules, oldest first
+func (r *PostgresBackupRepository) ListSchedules(ctx context.Context, tenantID uuid.UUID) ([]*domain.BackupSchedule, error) {
+	query := `
+		SELECT ` + backupScheduleColumns + `
+		FROM backup_schedules
+		WHERE tenant_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backup schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []*domain.BackupSchedule{}
+	for rows.Next() {
+		schedule, err := r.scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// UpdateSchedule saves a schedule's settings
+func (r *PostgresBackupRepository) UpdateSchedule(ctx context.Context, s *domain.BackupSchedule) error {
+	query := `
+		UPDATE backup_schedules
+		SET name = $1, frequency = $2, weekday = $3, hour = $4, mode = $5, destination = $6,
+		    path = $7, emails = $8, is_active = $9, next_run_at = $10, updated_at = $11
+		WHERE id = $12 AND tenant_id = $13
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		s.Name, s.Frequency, s.Weekday, s.Hour, s.Mode, s.Destination,
+		s.Path, emailList(s.Emails), s.IsActive, s.NextRunAt, s.UpdatedAt,
+		s.ID, s.TenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update backup schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBackupScheduleNotFound
+	}
+
+	return nil
+}
+
+// DeleteSchedule removes a schedule with its run history. Archives already
+// delivered are left in their destination.
+func (r *PostgresBackupRepository) DeleteSchedule(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := db.MainPool.Exec(ctx, `DELETE FROM backup_schedules WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete backup schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBackupScheduleNotFound
+	}
+	return nil
+}
+
+// ClaimDue takes the most overdue schedule. Its next run steps forward by whole days
+// or weeks past now, so slots missed while the worker was down are not run one by one.
+func (r *PostgresBackupRepository) ClaimDue(ctx context.Context, now time.Time) (*domain.BackupSchedule, error) {
+	query := `
+		WITH due AS (
+			SELECT id, next_run_at,
+			       CASE WHEN frequency = 'weekly' THEN INTERVAL '7 days' ELSE INTERVAL '1 day' END AS step
+			FROM backup_schedules
+			WHERE is_active AND next_run_at <= $1
+			ORDER BY next_run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE backup_schedules s
+		SET next_run_at = due.next_run_at + due.step * (FLOOR(EXTRACT(EPOCH FROM $1 - due.next_run_at) / EXTRACT(EPOCH FROM due.step)) + 1),
+		    last_run_at = $1
+		FROM due
+		WHERE s.id = due.id
+		RETURNING ` + prefixColumns("s", backupScheduleColumns)
+
+	schedule, err := r.scanSchedule(db.MainPool.QueryRow(ctx, query, now))
+	if errors.Is(err, domain.ErrBackupScheduleNotFound) {
+		return nil, nil
+	}
+	return schedule, err
+}
+
+// CreateRun saves a running run
+func (r *PostgresBackupRepository) CreateRun(ctx context.Context, run *domain.BackupRun) error {
+	tables, err := json.Marshal(run.Tables)
+	if err != nil {
+		return fmt.Errorf("failed to encode backup tables: %w", err)
+	}
+
+	query := `
+		INSERT INTO backup_runs (` + backupRunColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+
+	_, err = db.MainPool.Exec(ctx, query,
+		run.ID, run.ScheduleID, run.TenantID, run.Trigger, run.Mode, run.Since, run.Until, run.Status, run.Destination, run.Location,
+		run.SizeBytes, run.Checksum, tables, run.Error, run.TriggeredBy, run.StartedAt, run.FinishedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_backup_runs_running" {
+			return domain.ErrBackupRunning
+		}
+		return fmt.Errorf("failed to create backup run: %w", err)
+	}
+
+	return nil
+}
+
+// GetRun retrieves a run of the tenant
+func (r *PostgresBackupRepository) GetRun(ctx context.Context, tenantID, id uuid.UUID) (*domain.BackupRun, error) {
+	query := `SELECT ` + backupRunColumns + ` FROM backup_runs WHERE id = $1 AND tenant_id = $2`
+	return r.scanRun(db.MainPool.QueryRow(ctx, query, id, tenantID))
+}
+
+// ListRuns retrieves a schedule's run history
+func (r *PostgresBackupRepository) ListRuns(ctx context.Context, tenantID, scheduleID uuid.UUID, limit, offset int) ([]*domain.BackupRun, error) {
+	query := `
+		SELECT ` + backupRunColumns + `
+		FROM backup_runs
+		WHERE tenant_id = $1 AND schedule_id = $2
+		ORDER BY started_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, scheduleID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backup runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*domain.BackupRun{}
+	for rows.Next() {
+		run, err := r.scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return runs, nil
+}
+
+// FinishRun saves a run's outcome
+func (r *PostgresBackupRepository) FinishRun(ctx context.Context, run *domain.BackupRun) error {
+	tables, err := json.Marshal(run.Tables)
+	if err != nil {
+		return fmt.Errorf("failed to encode backup tables: %w", err)
+	}
+
+	query := `
+		UPDATE backup_runs
+		SET status = $1, location = $2, size_bytes = $3, checksum = $4, tables = $5, error = $6, finished_at = $7
+		WHERE id = $8 AND tenant_id = $9
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		run.Status, run.Location, run.SizeBytes, run.Checksum, tables, run.Error, run.FinishedAt,
+		run.ID, run.TenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update backup run: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBackupRunNotFound
+	}
+
+	return nil
+}
+
+// LastCompletedUntil returns the delta watermark of a schedule
+func (r *PostgresBackupRepository) LastCompletedUntil(ctx context.Context, tenantID, scheduleID uuid.UUID) (*time.Time, error) {
+	query := `
+		SELECT MAX(until) FROM backup_runs
+		WHERE tenant_id = $1 AND schedule_id = $2 AND status = 'completed'
+	`
+
+	var until *time.Time
+	if err := db.MainPool.QueryRow(ctx, query, tenantID, scheduleID).Scan(&until); err != nil {
+		return nil, fmt.Errorf("failed to find last backup run: %w", err)
+	}
+	return until, nil
+}
+
+// FailStale fails runs left running by a crashed worker
+func (r *PostgresBackupRepository) FailStale(ctx context.Context, before time.Time, reason string) (int64, error) {
+	query := `
+		UPDATE backup_runs SET status = 'failed', error = $2, finished_at = NOW()
+		WHERE status = 'running' AND started_at < $1
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query, before, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail stale backup runs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// scanSchedule scans a single schedule row
+func (r *PostgresBackupRepository) scanSchedule(row pgx.Row) (*domain.BackupSchedule, error) {
+	var s domain.BackupSchedule
+
+	err := row.Scan(
+		&s.ID, &s.TenantID, &s.Name, &s.Frequency, &s.Weekday, &s.Hour, &s.Mode, &s.Destination, &s.Path, &s.Emails,
+		&s.IsActive, &s.NextRunAt, &s.LastRunAt, &s.CreatedBy, &s.CreatedAt, &s.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBackupScheduleNotFound
+		}
+		return nil, fmt.Errorf("failed to scan backup schedule: %w", err)
+	}
+
+	return &s, nil
+}
+
+// scanRun scans a single run row
+func (r *PostgresBackupRepository) scanRun(row pgx.Row) (*domain.BackupRun, error) {
+	var run domain.BackupRun
+	var tables []byte
+
+	err := row.Scan(
+		&run.ID, &run.ScheduleID, &run.TenantID, &run.Trigger, &run.Mode, &run.Since, &run.Until, &run.Status, &run.Destination, &run.Location,
+		&run.SizeBytes, &run.Checksum, &tables, &run.Error, &run.TriggeredBy, &run.StartedAt, &run.FinishedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBackupRunNotFound
+		}
+		return nil, fmt.Errorf("failed to scan backup run: %w", err)
+	}
+
+	if err := json.Unmarshal(tables, &run.Tables); err != nil {
+		return nil, fmt.Errorf("failed to decode backup tables: %w", err)
+	}
+
+	return &run, nil
+}
+
+// emailList stores a nil list as an empty array, which the column requires
+func emailList(emails []string) []string {
+	if emails == nil {
+		return []string{}
+	}
+	return emails
+}
+
+// prefixColumns qualifies a column list with a table alias, for RETURNING after a join
+func prefixColumns(alias, columns string) string {
+	fields := strings.Split(columns, ",")
+	for i, field := range fields {
+		fields[i] = alias + "." + strings.TrimSpace(field)
+	}
+	return strings.Join(fields, ", ")
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/files/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresExtractionRepository implements ExtractionRepository using PostgreSQL
+type PostgresExtractionRepository struct{}
+
+// NewPostgresExtractionRepository creates a new PostgreSQL extraction repository
+func NewPostgresExtractionRepository() *PostgresExtractionRepository {
+	return &PostgresExtractionRepository{}
+}
+
+const extractionColumns = `
+	id, tenant_id, attachment_id, kind, status, fields, confidence, text, error,
+	attempts, requested_by, reviewed_by, reviewed_at, created_at, updated_at`
+
+// Create queues an extraction
+func (r *PostgresExtractionRepository) Create(ctx context.Context, e *domain.Extraction) error {
+	fields, err := marshalFields(e.Fields)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO document_extractions (` + extractionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+
+	_, err = db.MainPool.Exec(ctx, query,
+		e.ID, e.TenantID, e.AttachmentID, e.Kind, e.Status, fields, e.Confidence, e.Text, e.Error,
+		e.Attempts, e.RequestedBy, e.ReviewedBy, e.ReviewedAt, e.CreatedAt, e.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create extraction: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an extraction of the tenant
+func (r *PostgresExtractionRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Extraction, error) {
+	query := `SELECT ` + extractionColumns + ` FROM document_extractions WHERE id = $1 AND tenant_id = $2`
+	return r.scanExtraction(db.MainPool.QueryRow(ctx, query, id, tenantID))
+}
+
+// ListByAttachment retrieves the extractions of one attachment
+func (r *PostgresExtractionRepository) ListByAttachment(ctx context.Context, tenantID, attachmentID uuid.UUID) ([]*domain.Extraction, error) {
+	query := `
+		SELECT ` + extractionColumns + `
+		FROM document_extractions
+		WHERE tenant_id = $1 AND attachment_id = $2
+		ORDER BY created_at DESC
+	`
+	return r.queryExtractions(ctx, query, tenantID, attachmentID)
+}
+
+// ListByStatus retrieves the tenant's extractions in a status
+func (r *PostgresExtractionRepository) ListByStatus(ctx context.Context, tenantID uuid.UUID, status domain.ExtractionStatus, limit, offset int) ([]*domain.Extraction, error) {
+	query := `
+		SELECT ` + extractionColumns + `
+		FROM document_extractions
+		WHERE tenant_id = $1 AND status = $2
+		ORDER BY created_at
+		LIMIT $3 OFFSET $4
+	`
+	return r.queryExtractions(ctx, query, tenantID, status, limit, offset)
+}
+
+// ListPending retrieves queued extractions across tenants
+func (r *PostgresExtractionRepository) ListPending(ctx context.Context, limit int) ([]*domain.Extraction, error) {
+	query := `
+		SELECT ` + extractionColumns + `
+		FROM document_extractions
+		WHERE status = 'pending'
+		ORDER BY created_at
+		LIMIT $1
+	`
+	return r.queryExtractions(ctx, query, limit)
+}
+
+// Update saves an extraction's results or review
+func (r *PostgresExtractionRepository) Update(ctx context.Context, e *domain.Extraction) error {
+	fields, err := marshalFields(e.Fields)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE document_extractions
+		SET status = $1, fields = $2, confidence = $3, text = $4, error = $5, attempts = $6,
+		    reviewed_by = $7, reviewed_at = $8, updated_at = $9
+		WHERE id = $10 AND tenant_id = $11
+	`
+
+	tag, err := db.MainPool.Exec(ctx, query,
+		e.Status, fields, e.Confidence, e.Text, e.Error, e.Attempts,
+		e.ReviewedBy, e.ReviewedAt, e.UpdatedAt,
+		e.ID, e.TenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update extraction: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrExtractionNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresExtractionRepository) queryExtractions(ctx context.Context, query string, args ...any) ([]*domain.Extraction, error) {
+	rows, err := db.MainPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extractions: %w", err)
+	}
+	defer rows.Close()
+
+	extractions := []*domain.Extraction{}
+	for rows.Next() {
+		extraction, err := r.scanExtraction(rows)
+		if err != nil {
+			return nil, err
+		}
+		extractions = append(extractions, extraction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return extractions, nil
+}
+
+// scanExtraction scans a single extraction row
+func (r *PostgresExtractionRepository) scanExtraction(row pgx.Row) (*domain.Extraction, error) {
+	var e domain.Extraction
+	var fields []byte
+
+	err := row.Scan(
+		&e.ID, &e.TenantID, &e.AttachmentID, &e.Kind, &e.Status, &fields, &e.Confidence, &e.Text, &e.Error,
+		&e.Attempts, &e.RequestedBy, &e.ReviewedBy, &e.ReviewedAt, &e.CreatedAt, &e.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrExtractionNotFound
+		}
+		return nil, fmt.Errorf("failed to scan extraction: %w", err)
+	}
+
+	if err := json.Unmarshal(fields, &e.Fields); err != nil {
+		return nil, fmt.Errorf("failed to decode extraction fields: %w", err)
+	}
+
+	return &e, nil
+}
+
+func marshalFields(fields []domain.ExtractedField) ([]byte, error) {
+	if fields == nil {
+		fields = []domain.ExtractedField{}
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode extraction fields: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aceextension/files/domain"
+	"github.com/aceextension/files/repository"
+	"github.com/aceextension/files/storage"
+	"github.com/google/uuid"
+)
+
+// attachmentService implements AttachmentService
+type attachmentService struct {
+	repo  repository.AttachmentRepository
+	store storage.Storage
+
+	mu          sync.RWMutex
+	entityTypes map[string]EntityExistsFunc
+}
+
+// NewAttachmentService creates a new attachment service
+func NewAttachmentService(repo repository.AttachmentRepository, store storage.Storage) AttachmentService {
+	return &attachmentService{
+		repo:        repo,
+		store:       store,
+		entityTypes: make(map[string]EntityExistsFunc),
+	}
+}
+
+// RegisterEntityType enables attachments for an entity type
+func (s *attachmentService) RegisterEntityType(entityType string, exists EntityExistsFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entityTypes[entityType] = exists
+}
+
+// Upload validates and stores a file, then records its metadata
+func (s *attachmentService) Upload(ctx context.Context, input UploadInput) (*domain.Attachment, error) {
+	if err := s.checkEntity(ctx, input.TenantID, input.EntityType, input.EntityID); err != nil {
+		return nil, err
+	}
+
+	// Buffer the upload to enforce the size limit and hash it before storing
+	content, err := io.ReadAll(io.LimitReader(input.Content, domain.MaxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if len(content) > domain.MaxAttachmentSize {
+		return nil, domain.ErrFileTooLarge
+	}
+
+	contentType := detectContentType(content)
+	if !domain.AllowedContentTypes[contentType] {
+		return nil, domain.ErrUnsupportedFileType
+	}
+
+	sum := sha256.Sum256(content)
+
+	attachment := domain.NewAttachment(input.TenantID, input.EntityType, input.EntityID, input.FileName)
+	attachment.ContentType = contentType
+	attachment.SizeBytes = int64(len(content))
+	attachment.Checksum = hex.EncodeToString(sum[:])
+	attachment.Description = input.Description
+	attachment.UploadedBy = input.UserID
+
+	if err := s.store.Put(ctx, attachment.StorageKey, bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, attachment); err != nil {
+		// Don't leave an orphaned object behind
+		_ = s.store.Delete(ctx, attachment.StorageKey)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// List retrieves the attachments of a record
+func (s *attachmentService) List(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) ([]*domain.Attachment, error) {
+	if !s.isRegistered(entityType) {
+		return nil, domain.ErrUnknownEntityType
+	}
+	return s.repo.ListByEntity(ctx, tenantID, entityType, entityID)
+}
+
+// Open retrieves an attachment with its content
+func (s *attachmentService) Open(ctx context.Context, tenantID, id uuid.UUID) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.store.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// Delete soft-deletes an attachment; the stored file is kept for audit
+func (s *attachmentService) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.SoftDelete(ctx, tenantID, id)
+}
+
+// CountByEntities returns attachment counts keyed by entity ID
+func (s *attachmentService) CountByEntities(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	return s.repo.CountByEntities(ctx, tenantID, entityType, entityIDs)
+}
+
+// exportManifestEntry describes one attachment in the export manifest
+type exportManifestEntry struct {
+	ID          uuid.UUID `json:"id"`
+	EntityType  string    `json:"entityType"`
+	EntityID    uuid.UUID `json:"entityId"`
+	FileName    string    `json:"fileName"`
+	Path        string    `json:"path"`
+	ContentType string    `json:"contentType"`
+	SizeBytes   int64     `json:"sizeBytes"`
+	Checksum    string    `json:"sha256"`
+	Description *string   `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Missing     bool      `json:"missing,omitempty"`
+}
+
+// WriteExport adds the tenant's attachments to an export archive
+func (s *attachmentService) WriteExport(ctx context.Context, tenantID uuid.UUID, archive *zip.Writer) error {
+	attachments, err := s.repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	manifest := make([]exportManifestEntry, 0, len(attachments))
+	for _, a := range attachments {
+		// Prefix with the ID so two files with the same name on one record don't collide
+		entryPath := path.Join("attachments", a.EntityType, a.EntityID.String(), a.ID.String()[:8]+"-"+a.FileName)
+		entry := exportManifestEntry{
+			ID:          a.ID,
+			EntityType:  a.EntityType,
+			EntityID:    a.EntityID,
+			FileName:    a.FileName,
+			Path:        entryPath,
+			ContentType: a.ContentType,
+			SizeBytes:   a.SizeBytes,
+			Checksum:    a.Checksum,
+			Description: a.Description,
+			CreatedAt:   a.CreatedAt,
+		}
+
+		content, err := s.store.Open(ctx, a.StorageKey)
+		if err != nil {
+			// Record the gap instead of failing the whole export
+			entry.Missing = true
+			entry.Path = ""
+			manifest = append(manifest, entry)
+			continue
+		}
+
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: entryPath, Method: zip.Store, Modified: a.CreatedAt})
+		if err == nil {
+			_, err = io.Copy(w, content)
+		}
+		content.Close()
+		if err != nil {
+			return fmt.Errorf("failed to add attachment %s to export: %w", a.ID, err)
+		}
+
+		manifest = append(manifest, entry)
+	}
+
+	w, err := archive.Create("attachments/manifest.json")
+	if err != nil {
+		return fmt.Errorf("failed to add attachment manifest: %w", err)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(manifest)
+}
+
+// checkEntity verifies the entity type is registered and the record belongs to the tenant
+func (s *attachmentService) checkEntity(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	s.mu.RLock()
+	exists, ok := s.entityTypes[entityType]
+	s.mu.RUnlock()
+	if !ok {
+		return domain.ErrUnknownEntityType
+	}
+
+	found, err := exists(ctx, tenantID, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to check %s: %w", entityType, err)
+	}
+	if !found {
+		return domain.ErrEntityNotFound
+	}
+	return nil
+}
+
+func (s *attachmentService) isRegistered(entityType string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.entityTypes[entityType]
+	return ok
+}
+
+// detectContentType sniffs the content; the client-declared type is not trusted
+func detectContentType(content []byte) string {
+	contentType := http.DetectContentType(content)
+	if idx := strings.Index(contentType, ";"); idx != -1 {
+		contentType = contentType[:idx]
+	}
+	return contentType
+}
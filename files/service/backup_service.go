@@ -0,0 +1,384 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/security"
+	"github.com/aceextension/files/domain"
+	"github.com/aceextension/files/repository"
+	"github.com/aceextension/files/storage"
+	"github.com/google/uuid"
+)
+
+// staleBackupAfter is how long a run may stay running before the worker gives up on it
+const staleBackupAfter = 6 * time.Hour
+
+// backupExcludedTables hold the backup history itself; platform tables are left out too
+var backupExcludedTables = []string{"backup_schedules", "backup_runs"}
+
+// backupService implements BackupService
+type backupService struct {
+	repo        repository.BackupRepository
+	data        repository.BackupDataRepository
+	attachments AttachmentService
+	store       storage.Storage
+	mailer      BackupMailer
+}
+
+// NewBackupService creates a new backup service. Archives for the minio and email
+// destinations are saved to store; attachments adds the files of full backups.
+func NewBackupService(repo repository.BackupRepository, data repository.BackupDataRepository, attachments AttachmentService, store storage.Storage) BackupService {
+	return &backupService{repo: repo, data: data, attachments: attachments, store: store}
+}
+
+// SetMailer sets how download links of the email destination are sent
+func (s *backupService) SetMailer(mailer BackupMailer) {
+	s.mailer = mailer
+}
+
+// CreateSchedule saves a new schedule
+func (s *backupService) CreateSchedule(ctx context.Context, tenantID uuid.UUID, input domain.BackupScheduleInput, userID *uuid.UUID) (*domain.BackupSchedule, error) {
+	schedule, err := domain.NewBackupSchedule(tenantID, input, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDestination(ctx, schedule); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// GetSchedule retrieves a schedule
+func (s *backupService) GetSchedule(ctx context.Context, tenantID, id uuid.UUID) (*domain.BackupSchedule, error) {
+	return s.repo.GetSchedule(ctx, tenantID, id)
+}
+
+// ListSchedules retrieves the tenant's schedules
+func (s *backupService) ListSchedules(ctx context.Context, tenantID uuid.UUID) ([]*domain.BackupSchedule, error) {
+	return s.repo.ListSchedules(ctx, tenantID)
+}
+
+// UpdateSchedule replaces a schedule's settings
+func (s *backupService) UpdateSchedule(ctx context.Context, tenantID, id uuid.UUID, input domain.BackupScheduleInput) (*domain.BackupSchedule, error) {
+	schedule, err := s.repo.GetSchedule(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := schedule.Update(input, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := checkDestination(ctx, schedule); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule removes a schedule and its history
+func (s *backupService) DeleteSchedule(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.DeleteSchedule(ctx, tenantID, id)
+}
+
+// RunNow starts a run of the schedule in the background and returns it running
+func (s *backupService) RunNow(ctx context.Context, tenantID, scheduleID uuid.UUID, userID *uuid.UUID) (*domain.BackupRun, error) {
+	schedule, err := s.repo.GetSchedule(ctx, tenantID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	run, err := s.start(ctx, schedule, domain.TriggerManual, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The archive can take minutes; the caller polls the run
+	started := *run
+	go func() {
+		runCtx := db.WithTenantID(context.Background(), schedule.TenantID)
+		if err := s.execute(runCtx, schedule, &started); err != nil {
+			log.Printf("Backup run %s of schedule %s failed: %v", started.ID, schedule.ID, err)
+		}
+	}()
+	return run, nil
+}
+
+// GetRun retrieves a run
+func (s *backupService) GetRun(ctx context.Context, tenantID, id uuid.UUID) (*domain.BackupRun, error) {
+	return s.repo.GetRun(ctx, tenantID, id)
+}
+
+// ListRuns retrieves a schedule's run history
+func (s *backupService) ListRuns(ctx context.Context, tenantID, scheduleID uuid.UUID, limit, offset int) ([]*domain.BackupRun, error) {
+	if _, err := s.repo.GetSchedule(ctx, tenantID, scheduleID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRuns(ctx, tenantID, scheduleID, limit, offset)
+}
+
+// DownloadURL returns a presigned link to a run's archive, or "" when the store
+// cannot presign and the archive must be streamed with Open
+func (s *backupService) DownloadURL(ctx context.Context, run *domain.BackupRun, expiry time.Duration) (string, error) {
+	if !run.Downloadable() {
+		return "", domain.ErrBackupNotDownloadable
+	}
+	presigner, ok := s.store.(storage.Presigner)
+	if !ok {
+		return "", nil
+	}
+	return presigner.PresignGet(ctx, *run.Location, path.Base(*run.Location), expiry)
+}
+
+// Open returns a run's archive
+func (s *backupService) Open(ctx context.Context, run *domain.BackupRun) (io.ReadCloser, error) {
+	if !run.Downloadable() {
+		return nil, domain.ErrBackupNotDownloadable
+	}
+	return s.store.Open(ctx, *run.Location)
+}
+
+// RunDue runs the schedules due now, one at a time. A failed run is recorded in
+// the schedule's history and does not stop the rest.
+func (s *backupService) RunDue(ctx context.Context) error {
+	now := time.Now()
+	if n, err := s.repo.FailStale(ctx, now.Add(-staleBackupAfter), "the backup did not finish"); err != nil {
+		return err
+	} else if n > 0 {
+		log.Printf("Backup worker: failed %d stale runs", n)
+	}
+
+	for {
+		schedule, err := s.repo.ClaimDue(ctx, now)
+		if err != nil {
+			return err
+		}
+		if schedule == nil {
+			return nil
+		}
+
+		runCtx := db.WithTenantID(ctx, schedule.TenantID)
+		run, err := s.start(runCtx, schedule, domain.TriggerSchedule, nil)
+		if errors.Is(err, domain.ErrBackupRunning) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.execute(runCtx, schedule, run); err != nil {
+			log.Printf("Backup run %s of schedule %s failed: %v", run.ID, schedule.ID, err)
+		}
+	}
+}
+
+// start records a running run, with the last completed run's snapshot as the delta watermark
+func (s *backupService) start(ctx context.Context, schedule *domain.BackupSchedule, trigger domain.BackupTrigger, userID *uuid.UUID) (*domain.BackupRun, error) {
+	since, err := s.repo.LastCompletedUntil(ctx, schedule.TenantID, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	run := domain.NewBackupRun(schedule, trigger, since, userID, time.Now())
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// execute builds and delivers the archive, then saves the run's outcome
+func (s *backupService) execute(ctx context.Context, schedule *domain.BackupSchedule, run *domain.BackupRun) error {
+	err := s.backup(ctx, schedule, run)
+	if err != nil {
+		run.Fail(err.Error(), time.Now())
+	}
+	if saveErr := s.repo.FinishRun(ctx, run); saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+	return err
+}
+
+// backup writes the tenant's rows into a zip in a temp file, hashing it as it is
+// written, and hands it to the schedule's destination
+func (s *backupService) backup(ctx context.Context, schedule *domain.BackupSchedule, run *domain.BackupRun) error {
+	exclude := map[string]bool{}
+	for table := range db.GlobalTables {
+		exclude[table] = true
+	}
+	for _, table := range backupExcludedTables {
+		exclude[table] = true
+	}
+	sources, err := s.data.Sources(ctx, exclude)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "backup-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	hash := sha256.New()
+	zw := zip.NewWriter(io.MultiWriter(tmp, hash))
+
+	tables, err := s.data.Dump(ctx, run.TenantID, sources, run.Since, run.Until, func(source repository.BackupSource) (io.Writer, error) {
+		return createBackupEntry(zw, "tables/"+source.Table+".ndjson")
+	})
+	if err != nil {
+		return err
+	}
+	run.Tables = tables
+
+	// Files are only in full backups; a delta carries their attachment rows
+	if run.Mode == domain.BackupFull && s.attachments != nil {
+		if err := s.attachments.WriteExport(ctx, run.TenantID, zw); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := createBackupEntry(zw, "manifest.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(manifest)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(map[string]any{
+		"tenantId":   run.TenantID,
+		"scheduleId": schedule.ID,
+		"runId":      run.ID,
+		"mode":       run.Mode,
+		"since":      run.Since,
+		"until":      run.Until.UTC(),
+		"tables":     run.Tables,
+		"format":     "One JSON object per line per table. Delta backups hold rows created or updated in (since, until]; deletions are not included.",
+	}); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.zip", run.Until.UTC().Format("20060102T150405Z"), run.Mode)
+	location, err := s.upload(ctx, schedule, name, tmp)
+	if err != nil {
+		return err
+	}
+	run.Complete(location, info.Size(), hex.EncodeToString(hash.Sum(nil)), time.Now())
+
+	if schedule.Destination == domain.DestinationEmail {
+		return s.sendLink(ctx, schedule, run)
+	}
+	return nil
+}
+
+// upload saves the archive to the schedule's destination and returns its key there
+func (s *backupService) upload(ctx context.Context, schedule *domain.BackupSchedule, name string, archive io.Reader) (string, error) {
+	if schedule.Destination == domain.DestinationS3 {
+		bucket, prefix, err := tenantBucket(ctx, schedule.TenantID)
+		if err != nil {
+			return "", err
+		}
+		key := joinKey(prefix, schedule.Path, name)
+		if err := bucket.Put(ctx, key, archive); err != nil {
+			return "", fmt.Errorf("failed to upload backup to the tenant's bucket: %w", err)
+		}
+		return key, nil
+	}
+
+	if s.store == nil {
+		return "", errors.New("no export storage is configured")
+	}
+	key := joinKey(fmt.Sprintf("backups/%s", schedule.TenantID), schedule.Path, name)
+	if err := s.store.Put(ctx, key, archive); err != nil {
+		return "", fmt.Errorf("failed to save backup: %w", err)
+	}
+	return key, nil
+}
+
+// sendLink emails a completed run's download link. Stores that cannot presign get
+// a link to the download endpoint, which asks the recipient to log in.
+func (s *backupService) sendLink(ctx context.Context, schedule *domain.BackupSchedule, run *domain.BackupRun) error {
+	if s.mailer == nil {
+		return errors.New("no backup mailer is configured")
+	}
+	link, err := s.DownloadURL(ctx, run, domain.BackupLinkTTL)
+	if err != nil {
+		return err
+	}
+	if link == "" {
+		link = "/api/v1/files/backups/runs/" + run.ID.String() + "/download"
+	}
+	if err := s.mailer.SendBackupLink(ctx, schedule, run, link, time.Now().Add(domain.BackupLinkTTL)); err != nil {
+		return fmt.Errorf("failed to email backup link: %w", err)
+	}
+	return nil
+}
+
+// checkDestination makes sure a schedule delivering to the tenant's bucket has one configured
+func checkDestination(ctx context.Context, schedule *domain.BackupSchedule) error {
+	if schedule.Destination != domain.DestinationS3 {
+		return nil
+	}
+	_, _, err := tenantBucket(ctx, schedule.TenantID)
+	return err
+}
+
+// tenantBucket opens the S3 bucket the tenant configured as its backup_s3 integration
+func tenantBucket(ctx context.Context, tenantID uuid.UUID) (storage.Storage, string, error) {
+	creds, err := security.TenantCredentials(ctx, tenantID, security.IntegrationBackupS3)
+	if err != nil {
+		return nil, "", err
+	}
+	bucket, err := storage.NewMinioStorage(storage.MinioConfig{
+		Endpoint:  creds.Get("endpoint"),
+		AccessKey: creds.Get("access_key"),
+		SecretKey: creds.Get("secret_key"),
+		Bucket:    creds.Get("bucket"),
+		Region:    creds.Get("region"),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid backup bucket settings: %w", err)
+	}
+	return bucket, creds.Get("prefix"), nil
+}
+
+// joinKey joins non-empty key parts with slashes
+func joinKey(parts ...string) string {
+	kept := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.Trim(part, "/"); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "/")
+}
+
+// createBackupEntry starts a compressed zip entry dated now
+func createBackupEntry(zw *zip.Writer, name string) (io.Writer, error) {
+	return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aceextension/files/domain"
+	"github.com/aceextension/files/ocr"
+	"github.com/aceextension/files/repository"
+	"github.com/aceextension/files/storage"
+	"github.com/google/uuid"
+)
+
+// extractionBatchSize is how many queued documents one worker run reads
+const extractionBatchSize = 10
+
+// extractionService implements ExtractionService
+type extractionService struct {
+	repo           repository.ExtractionRepository
+	attachmentRepo repository.AttachmentRepository
+	store          storage.Storage
+	engine         ocr.Engine
+	threshold      float64
+}
+
+// NewExtractionService creates a new extraction service. Extraction is off until SetEngine is called.
+func NewExtractionService(repo repository.ExtractionRepository, attachmentRepo repository.AttachmentRepository, store storage.Storage) ExtractionService {
+	return &extractionService{
+		repo:           repo,
+		attachmentRepo: attachmentRepo,
+		store:          store,
+		threshold:      domain.DefaultConfidenceThreshold,
+	}
+}
+
+// SetEngine sets the OCR engine documents are read with
+func (s *extractionService) SetEngine(engine ocr.Engine) {
+	s.engine = engine
+}
+
+// SetConfidenceThreshold sets the review threshold
+func (s *extractionService) SetConfidenceThreshold(threshold float64) {
+	s.threshold = threshold
+}
+
+// Enqueue queues an attachment for extraction
+func (s *extractionService) Enqueue(ctx context.Context, tenantID, attachmentID uuid.UUID, kind domain.DocumentKind, userID *uuid.UUID) (*domain.Extraction, error) {
+	if s.engine == nil {
+		return nil, domain.ErrOCRUnavailable
+	}
+	if _, err := s.attachmentRepo.GetByID(ctx, tenantID, attachmentID); err != nil {
+		return nil, err
+	}
+
+	extraction, err := domain.NewExtraction(tenantID, attachmentID, kind, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, extraction); err != nil {
+		return nil, err
+	}
+	return extraction, nil
+}
+
+// Get retrieves an extraction
+func (s *extractionService) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Extraction, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// ListByAttachment retrieves the extractions of an attachment
+func (s *extractionService) ListByAttachment(ctx context.Context, tenantID, attachmentID uuid.UUID) ([]*domain.Extraction, error) {
+	return s.repo.ListByAttachment(ctx, tenantID, attachmentID)
+}
+
+// ListByStatus retrieves the tenant's extractions in a status
+func (s *extractionService) ListByStatus(ctx context.Context, tenantID uuid.UUID, status domain.ExtractionStatus, limit, offset int) ([]*domain.Extraction, error) {
+	return s.repo.ListByStatus(ctx, tenantID, status, limit, offset)
+}
+
+// Confirm accepts an extraction's fields
+func (s *extractionService) Confirm(ctx context.Context, tenantID, id, userID uuid.UUID, corrections map[string]string) (*domain.Extraction, error) {
+	extraction, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := extraction.Confirm(corrections, userID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, extraction); err != nil {
+		return nil, err
+	}
+	return extraction, nil
+}
+
+// ProcessPending reads queued documents. A failed document is retried on later
+// runs until MaxExtractionAttempts; one failure does not stop the batch.
+func (s *extractionService) ProcessPending(ctx context.Context) error {
+	if s.engine == nil {
+		return nil
+	}
+
+	extractions, err := s.repo.ListPending(ctx, extractionBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, extraction := range extractions {
+		err := s.extract(ctx, extraction)
+		switch {
+		case errors.Is(err, domain.ErrAttachmentNotFound), errors.Is(err, ocr.ErrUnsupportedContent):
+			// Deleted since it was queued, or a file the engine cannot read; retrying will not help
+			extraction.Abandon(err.Error())
+		case err != nil:
+			extraction.Fail(err.Error())
+		}
+		if err := s.repo.Update(ctx, extraction); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// extract runs one document through the engine and the field rules for its kind
+func (s *extractionService) extract(ctx context.Context, extraction *domain.Extraction) error {
+	attachment, err := s.attachmentRepo.GetByID(ctx, extraction.TenantID, extraction.AttachmentID)
+	if err != nil {
+		return err
+	}
+
+	reader, err := s.store.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		return err
+	}
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", attachment.FileName, err)
+	}
+
+	result, err := s.engine.Recognize(ctx, attachment.ContentType, content)
+	if err != nil {
+		return err
+	}
+
+	fields, err := ocr.Fields(extraction.Kind, result)
+	if err != nil {
+		return err
+	}
+	extraction.Complete(result.Text, result.Confidence, fields, s.threshold)
+	return nil
+}
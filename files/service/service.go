@@ -0,0 +1,92 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"time"
+
+	"github.com/aceextension/files/domain"
+	"github.com/aceextension/files/ocr"
+	"github.com/google/uuid"
+)
+
+// EntityExistsFunc reports whether a record of a registered entity type exists for the tenant
+type EntityExistsFunc func(ctx context.Context, tenantID, entityID uuid.UUID) (bool, error)
+
+// UploadInput describes a file being attached to a record
+type UploadInput struct {
+	TenantID    uuid.UUID
+	UserID      *uuid.UUID
+	EntityType  string
+	EntityID    uuid.UUID
+	FileName    string
+	Description *string
+	Content     io.Reader
+}
+
+// AttachmentService defines the interface for attachment business logic
+type AttachmentService interface {
+	// RegisterEntityType enables attachments for an entity type owned by another module
+	RegisterEntityType(entityType string, exists EntityExistsFunc)
+
+	Upload(ctx context.Context, input UploadInput) (*domain.Attachment, error)
+	List(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) ([]*domain.Attachment, error)
+	// Open returns attachment metadata and its content; the caller closes the reader
+	Open(ctx context.Context, tenantID, id uuid.UUID) (*domain.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+	// CountByEntities returns attachment counts for list views, keyed by entity ID
+	CountByEntities(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID) (map[uuid.UUID]int, error)
+
+	// WriteExport adds every attachment of the tenant to a data export archive under
+	// attachments/<entity type>/<entity id>/, plus attachments/manifest.json
+	WriteExport(ctx context.Context, tenantID uuid.UUID, archive *zip.Writer) error
+}
+
+// ExtractionService defines the interface for reading structured data from
+// uploaded documents with OCR
+type ExtractionService interface {
+	// Enqueue queues an attachment for extraction; ErrOCRUnavailable when no engine is set
+	Enqueue(ctx context.Context, tenantID, attachmentID uuid.UUID, kind domain.DocumentKind, userID *uuid.UUID) (*domain.Extraction, error)
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Extraction, error)
+	ListByAttachment(ctx context.Context, tenantID, attachmentID uuid.UUID) ([]*domain.Extraction, error)
+	// ListByStatus lists the tenant's extractions in a status; needs_review is the confirmation queue
+	ListByStatus(ctx context.Context, tenantID uuid.UUID, status domain.ExtractionStatus, limit, offset int) ([]*domain.Extraction, error)
+	// Confirm records that a person checked the fields, applying their corrections
+	Confirm(ctx context.Context, tenantID, id, userID uuid.UUID, corrections map[string]string) (*domain.Extraction, error)
+
+	// ProcessPending runs queued extractions through the OCR engine
+	ProcessPending(ctx context.Context) error
+
+	SetEngine(engine ocr.Engine)
+	// SetConfidenceThreshold sets the field confidence below which a person must confirm the value
+	SetConfidenceThreshold(threshold float64)
+}
+
+// BackupMailer sends the download link of a backup delivered by email
+type BackupMailer interface {
+	SendBackupLink(ctx context.Context, schedule *domain.BackupSchedule, run *domain.BackupRun, link string, expiresAt time.Time) error
+}
+
+// BackupService defines the interface for scheduled exports of a tenant's data
+type BackupService interface {
+	CreateSchedule(ctx context.Context, tenantID uuid.UUID, input domain.BackupScheduleInput, userID *uuid.UUID) (*domain.BackupSchedule, error)
+	GetSchedule(ctx context.Context, tenantID, id uuid.UUID) (*domain.BackupSchedule, error)
+	ListSchedules(ctx context.Context, tenantID uuid.UUID) ([]*domain.BackupSchedule, error)
+	UpdateSchedule(ctx context.Context, tenantID, id uuid.UUID, input domain.BackupScheduleInput) (*domain.BackupSchedule, error)
+	DeleteSchedule(ctx context.Context, tenantID, id uuid.UUID) error
+
+	// RunNow starts a run outside the schedule; ErrBackupRunning while one is running
+	RunNow(ctx context.Context, tenantID, scheduleID uuid.UUID, userID *uuid.UUID) (*domain.BackupRun, error)
+	GetRun(ctx context.Context, tenantID, id uuid.UUID) (*domain.BackupRun, error)
+	// ListRuns returns a schedule's run history, newest first
+	ListRuns(ctx context.Context, tenantID, scheduleID uuid.UUID, limit, offset int) ([]*domain.BackupRun, error)
+	// DownloadURL returns a presigned link to the archive, or "" when it must be streamed with Open
+	DownloadURL(ctx context.Context, run *domain.BackupRun, expiry time.Duration) (string, error)
+	Open(ctx context.Context, run *domain.BackupRun) (io.ReadCloser, error)
+
+	// RunDue runs every schedule whose time has come
+	RunDue(ctx context.Context) error
+
+	SetMailer(mailer BackupMailer)
+}
@@ -0,0 +1,317 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPresignExpiry is the longest lifetime S3 signature v4 allows on a presigned URL
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// unsignedPayload skips hashing request bodies; MinIO and S3 accept it over TLS or a private network
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// MinioConfig locates a bucket on MinIO or another S3-compatible server
+type MinioConfig struct {
+	Endpoint       string // e.g. http://minio:9000
+	PublicEndpoint string // Origin browsers reach the server on, for presigned links; defaults to Endpoint
+	AccessKey      string
+	SecretKey      string
+	Bucket         string
+	Region         string // Defaults to us-east-1, which MinIO uses unless configured otherwise
+}
+
+// MinioStorage stores objects in an S3-compatible bucket, signing requests with
+// AWS signature v4 over net/http. Objects are addressed path-style
+// (<endpoint>/<bucket>/<key>), which MinIO serves without DNS per bucket.
+type MinioStorage struct {
+	cfg    MinioConfig
+	client *http.Client
+}
+
+// NewMinioStorage creates a store for the configured bucket. The bucket must exist.
+func NewMinioStorage(cfg MinioConfig) (*MinioStorage, error) {
+	if cfg.Endpoint == "" || cfg.AccessKey == "" || cfg.SecretKey == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("minio endpoint, credentials and bucket are required")
+	}
+	for _, endpoint := range []string{cfg.Endpoint, cfg.PublicEndpoint} {
+		if endpoint == "" {
+			continue
+		}
+		if u, err := url.Parse(endpoint); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, fmt.Errorf("invalid minio endpoint: %q", endpoint)
+		}
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	cfg.PublicEndpoint = strings.TrimRight(cfg.PublicEndpoint, "/")
+	if cfg.PublicEndpoint == "" {
+		cfg.PublicEndpoint = cfg.Endpoint
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &MinioStorage{cfg: cfg, client: &http.Client{Timeout: 10 * time.Minute}}, nil
+}
+
+// Put uploads an object. Uploads need a length up front, so readers that are not
+// files are spooled to a temp file first.
+func (s *MinioStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	body, size, cleanup, err := sizedBody(r)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	req, err := s.request(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload object: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to upload object: %s", responseError(resp))
+	}
+	return nil
+}
+
+// Open downloads an object; the caller closes the reader
+func (s *MinioStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open object: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrObjectNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("failed to open object: %s", responseError(resp))
+	}
+}
+
+// Delete removes an object; missing objects are not an error
+func (s *MinioStorage) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete object: %s", responseError(resp))
+	}
+	return nil
+}
+
+// PresignGet returns a link that downloads the object as fileName until expiry
+// passes, without credentials. Expiry is capped at seven days.
+func (s *MinioStorage) PresignGet(ctx context.Context, key, fileName string, expiry time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	if expiry <= 0 {
+		return "", fmt.Errorf("presigned link expiry must be positive")
+	}
+	expiry = min(expiry, maxPresignExpiry)
+
+	endpoint, _ := url.Parse(s.cfg.PublicEndpoint)
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.cfg.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if fileName != "" {
+		query.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	}
+
+	path := s.objectPath(key)
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		path,
+		canonicalQuery(query),
+		"host:" + endpoint.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	signature := s.sign(now, canonical)
+
+	return s.cfg.PublicEndpoint + path + "?" + canonicalQuery(query) + "&X-Amz-Signature=" + signature, nil
+}
+
+// request builds a header-signed request for an object
+func (s *MinioStorage) request(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	path := s.objectPath(key)
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.Endpoint+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		method,
+		path,
+		"",
+		"host:" + req.URL.Host + "\nx-amz-content-sha256:" + unsignedPayload + "\nx-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+	signature := s.sign(now, canonical)
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, s.scope(now), signedHeaders, signature))
+	return req, nil
+}
+
+// sign computes the v4 signature of a canonical request
+func (s *MinioStorage) sign(now time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + s.scope(now) + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func (s *MinioStorage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+// objectPath is the URI-encoded path of a key in the bucket
+func (s *MinioStorage) objectPath(key string) string {
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return "/" + uriEncode(s.cfg.Bucket) + "/" + strings.Join(segments, "/")
+}
+
+// canonicalQuery sorts parameters and encodes them the way signature v4 expects
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, uriEncode(k)+"="+uriEncode(values.Get(k)))
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything except unreserved characters (RFC 3986)
+func uriEncode(text string) string {
+	var b strings.Builder
+	for _, c := range []byte(text) {
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sizedBody returns r with its length, spooling it to a temp file when the length is unknown
+func sizedBody(r io.Reader) (io.Reader, int64, func(), error) {
+	noop := func() {}
+	if f, ok := r.(*os.File); ok {
+		info, err := f.Stat()
+		if err == nil && info.Mode().IsRegular() {
+			offset, err := f.Seek(0, io.SeekCurrent)
+			if err == nil {
+				return f, info.Size() - offset, noop, nil
+			}
+		}
+	}
+	if l, ok := r.(interface{ Len() int }); ok {
+		return r, int64(l.Len()), noop, nil
+	}
+
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, 0, noop, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	size, err := io.Copy(tmp, r)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		cleanup()
+		return nil, 0, noop, fmt.Errorf("failed to buffer upload: %w", err)
+	}
+	return tmp, size, cleanup, nil
+}
+
+// validKey refuses empty keys and keys that climb out of their prefix
+func validKey(key string) error {
+	if strings.Trim(key, "/") == "" || strings.Contains(key, "..") {
+		return fmt.Errorf("invalid storage key: %q", key)
+	}
+	return nil
+}
+
+// responseError summarizes an S3 error response
+func responseError(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if len(body) == 0 {
+		return resp.Status
+	}
+	return resp.Status + ": " + strings.TrimSpace(string(body))
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrObjectNotFound is returned when a key does not exist in the store
+var ErrObjectNotFound = errors.New("stored object not found")
+
+// Storage is the blob store behind attachments.
+// Keys are slash-separated ("<tenant>/<entity type>/<attachment id>").
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Presigner is implemented by stores that can hand out time-limited download links,
+// so large files go straight from the store to the browser
+type Presigner interface {
+	PresignGet(ctx context.Context, key, fileName string, expiry time.Duration) (string, error)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidDateDisplay is returned for a date display other than ad_primary or bs_primary
+var ErrInvalidDateDisplay = errors.New("invalid date display: must be ad_primary or bs_primary")
+
+// DatePreference is which calendar a tenant's documents and reports print first.
+// Both calendars are always printed; the preference only orders them.
+type DatePreference struct {
+	TenantID  uuid.UUID         `json:"tenantId" db:"tenant_id"`
+	Display   utils.DateDisplay `json:"display" db:"display"`
+	UpdatedAt *time.Time        `json:"updatedAt,omitempty" db:"updated_at"` // Nil for the default
+}
+
+// DefaultDatePreference prints AD dates first
+func DefaultDatePreference(tenantID uuid.UUID) *DatePreference {
+	return &DatePreference{TenantID: tenantID, Display: utils.DefaultDateDisplay}
+}
+
+// NewDatePreference creates a tenant's date preference
+func NewDatePreference(tenantID uuid.UUID, display utils.DateDisplay) (*DatePreference, error) {
+	if !display.Valid() {
+		return nil, ErrInvalidDateDisplay
+	}
+
+	now := time.Now()
+	return &DatePreference{TenantID: tenantID, Display: display, UpdatedAt: &now}, nil
+}
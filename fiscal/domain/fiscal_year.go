@@ -57,13 +57,22 @@ func NewFiscalYear(tenantID uuid.UUID, name string, startDate, endDate time.Time
 // generatePrefix creates a prefix from fiscal year name
 // e.g., "2082/83" -> "INV-8283-"
 func generatePrefix(docType, fiscalYearName string) string {
-	// Extract year numbers (e.g., "2082/83" -> "8283")
-	yearCode := fiscalYearName
+	return docType + "-" + yearCode(fiscalYearName) + "-"
+}
+
+// yearCode extracts the short year code from a fiscal year name
+// e.g., "2082/83" -> "8283"
+func yearCode(fiscalYearName string) string {
 	if len(fiscalYearName) >= 7 {
 		// Remove "20" prefix and "/" separator
-		yearCode = fiscalYearName[2:4] + fiscalYearName[5:7]
+		return fiscalYearName[2:4] + fiscalYearName[5:7]
 	}
-	return docType + "-" + yearCode + "-"
+	return fiscalYearName
+}
+
+// Code returns the short year code used in document numbers (e.g., "8283")
+func (fy *FiscalYear) Code() string {
+	return yearCode(fy.Name)
 }
 
 // IsActive checks if fiscal year is currently active
@@ -105,3 +114,27 @@ func (fy *FiscalYear) UnsetAsCurrent() {
 	fy.IsCurrent = false
 	fy.UpdatedAt = time.Now()
 }
+
+// Prefix returns the number prefix of a document type (e.g., "INV-8283-")
+func (fy *FiscalYear) Prefix(documentType DocumentType) string {
+	switch documentType {
+	case DocumentPurchase:
+		return fy.PurchasePrefix
+	case DocumentVoucher:
+		return fy.VoucherPrefix
+	default:
+		return fy.InvoicePrefix
+	}
+}
+
+// Counter returns the last number handed out for a document type
+func (fy *FiscalYear) Counter(documentType DocumentType) int {
+	switch documentType {
+	case DocumentPurchase:
+		return fy.LastPurchaseNum
+	case DocumentVoucher:
+		return fy.LastVoucherNum
+	default:
+		return fy.LastInvoiceNum
+	}
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrHolidayNotFound is returned when a holiday does not exist for the tenant
+	ErrHolidayNotFound = errors.New("holiday not found")
+	// ErrHolidayExists is returned when the tenant already has a holiday on the date
+	ErrHolidayExists = errors.New("a holiday is already set on this date")
+	// ErrInvalidHoliday is returned for a holiday without a name or date
+	ErrInvalidHoliday = errors.New("holiday needs a date and a name")
+	// ErrInvalidWeekend is returned for weekend days outside Sunday (0) to Saturday (6), or a week with no working day
+	ErrInvalidWeekend = errors.New("weekend days must be 0 (Sunday) to 6 (Saturday) and leave at least one working day")
+)
+
+// HolidaySource tells seeded public holidays from the tenant's own
+type HolidaySource string
+
+const (
+	HolidayPublic HolidaySource = "public" // Seeded from the national public holidays
+	HolidayCustom HolidaySource = "custom" // Added by the tenant, e.g. a local jatra or a stock-take day
+)
+
+// Holiday is a day a tenant's business is closed; due dates falling on it roll
+// forward and dunning does not run
+type Holiday struct {
+	ID        uuid.UUID     `json:"id" db:"id"`
+	TenantID  uuid.UUID     `json:"tenantId" db:"tenant_id"`
+	Date      time.Time     `json:"date" db:"date"`      // AD
+	DateBS    string        `json:"dateBs" db:"date_bs"` // YYYY-MM-DD
+	Name      string        `json:"name" db:"name"`
+	Source    HolidaySource `json:"source" db:"source"`
+	CreatedAt time.Time     `json:"createdAt" db:"created_at"`
+}
+
+// NewHoliday creates a holiday on an AD date
+func NewHoliday(tenantID uuid.UUID, date time.Time, dateBS, name string, source HolidaySource) *Holiday {
+	return &Holiday{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Date:      time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+		DateBS:    dateBS,
+		Name:      strings.TrimSpace(name),
+		Source:    source,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Validate checks the holiday has a date and a name
+func (h *Holiday) Validate() error {
+	if h.Date.IsZero() || h.Name == "" {
+		return ErrInvalidHoliday
+	}
+	return nil
+}
+
+// WorkWeek is a tenant's weekly closing days; Saturday alone unless set
+type WorkWeek struct {
+	TenantID    uuid.UUID      `json:"tenantId" db:"tenant_id"`
+	WeekendDays []time.Weekday `json:"weekendDays" db:"weekend_days"` // 0 = Sunday ... 6 = Saturday
+	UpdatedAt   time.Time      `json:"updatedAt" db:"updated_at"`
+}
+
+// Validate checks the weekend days are real weekdays and leave a working day
+func (w *WorkWeek) Validate() error {
+	seen := make(map[time.Weekday]bool, len(w.WeekendDays))
+	for _, day := range w.WeekendDays {
+		if day < time.Sunday || day > time.Saturday {
+			return ErrInvalidWeekend
+		}
+		seen[day] = true
+	}
+	if len(seen) == 7 {
+		return ErrInvalidWeekend
+	}
+	return nil
+}
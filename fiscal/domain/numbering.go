@@ -0,0 +1,212 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrFiscalYearNotFound is returned when a fiscal year does not exist for the tenant
+	ErrFiscalYearNotFound = errors.New("fiscal year not found")
+	// ErrInvalidDocumentType is returned for a document type that is not numbered
+	ErrInvalidDocumentType = errors.New("invalid document type")
+	// ErrNumberNotIssued is returned when a number is not in the tenant's ledger
+	ErrNumberNotIssued = errors.New("document number was not issued")
+	// ErrNumberAlreadyUsed is returned when a number is already attached to another document
+	ErrNumberAlreadyUsed = errors.New("document number is already used by another document")
+)
+
+// DocumentType is a series of document numbers kept per fiscal year
+type DocumentType string
+
+const (
+	DocumentInvoice  DocumentType = "invoice"  // INV-8283-0001
+	DocumentPurchase DocumentType = "purchase" // PUR-8283-0001
+	DocumentVoucher  DocumentType = "voucher"  // JV-8283-0001
+)
+
+// DocumentTypes lists the numbered document types
+var DocumentTypes = []DocumentType{DocumentInvoice, DocumentPurchase, DocumentVoucher}
+
+// Valid reports whether the document type is numbered
+func (t DocumentType) Valid() bool {
+	for _, documentType := range DocumentTypes {
+		if t == documentType {
+			return true
+		}
+	}
+	return false
+}
+
+// NumberIssue is a document number as it was issued. Rows are never updated
+// except to attach the document once, and never deleted. Each row's hash covers
+// the row and the hash before it in the same fiscal year and document type, so
+// an edited, removed or reordered row breaks the chain.
+type NumberIssue struct {
+	ID           uuid.UUID    `json:"id"`
+	TenantID     uuid.UUID    `json:"tenantId"`
+	FiscalYearID uuid.UUID    `json:"fiscalYearId"`
+	DocumentType DocumentType `json:"documentType"`
+	Sequence     int          `json:"sequence"` // Counter value, 1 for the first number of the year
+	Number       string       `json:"number"`   // e.g., "INV-8283-0001"
+	IssuedAt     time.Time    `json:"issuedAt"`
+	IssuedBy     *uuid.UUID   `json:"issuedBy,omitempty"`
+	DocumentID   *uuid.UUID   `json:"documentId,omitempty"` // Set once the document is saved
+	AttachedAt   *time.Time   `json:"attachedAt,omitempty"`
+	PrevHash     string       `json:"prevHash"`
+	Hash         string       `json:"hash"`
+}
+
+// NewNumberIssue creates a ledger row chained to prevHash, empty for the first
+// number of the series
+func NewNumberIssue(tenantID, fiscalYearID uuid.UUID, documentType DocumentType, sequence int, number string, issuedBy *uuid.UUID, prevHash string) *NumberIssue {
+	issue := &NumberIssue{
+		ID:           uuid.New(),
+		TenantID:     tenantID,
+		FiscalYearID: fiscalYearID,
+		DocumentType: documentType,
+		Sequence:     sequence,
+		Number:       number,
+		// Stored without a zone at microsecond precision; keep it that way so the
+		// hash can be recomputed from the stored row
+		IssuedAt: time.Now().UTC().Truncate(time.Microsecond),
+		IssuedBy: issuedBy,
+		PrevHash: prevHash,
+	}
+	issue.Hash = issue.ComputeHash()
+	return issue
+}
+
+// ComputeHash hashes the issued fields with the previous hash. The document is
+// attached later and is not covered.
+func (i *NumberIssue) ComputeHash() string {
+	issuedBy := ""
+	if i.IssuedBy != nil {
+		issuedBy = i.IssuedBy.String()
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%d|%s|%s|%s",
+		i.PrevHash, i.TenantID, i.FiscalYearID, i.DocumentType, i.Sequence, i.Number,
+		i.IssuedAt.UTC().Format(time.RFC3339Nano), issuedBy)))
+	return hex.EncodeToString(sum[:])
+}
+
+// FormatDocumentNumber builds a document number from the series prefix and counter
+func FormatDocumentNumber(prefix string, sequence int) string {
+	return fmt.Sprintf("%s%04d", prefix, sequence)
+}
+
+// NumberGap is a run of counter values with no ledger row
+type NumberGap struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// NumberDuplicate is a document number issued more than once
+type NumberDuplicate struct {
+	Number    string `json:"number"`
+	Sequences []int  `json:"sequences"`
+}
+
+// NumberingReport is the integrity check of one document series for inspection
+type NumberingReport struct {
+	FiscalYearID   uuid.UUID         `json:"fiscalYearId"`
+	FiscalYearName string            `json:"fiscalYearName"`
+	DocumentType   DocumentType      `json:"documentType"`
+	Counter        int               `json:"counter"`   // Last number handed out by the fiscal year
+	Untracked      int               `json:"untracked"` // Numbers handed out before the ledger was kept
+	FirstSequence  int               `json:"firstSequence"`
+	LastSequence   int               `json:"lastSequence"`
+	Issued         int               `json:"issued"` // Ledger rows
+	Unused         int               `json:"unused"` // Issued numbers with no document attached
+	Gaps           []NumberGap       `json:"gaps"`
+	Duplicates     []NumberDuplicate `json:"duplicates"`
+	ChainValid     bool              `json:"chainValid"`
+	BrokenAt       *int              `json:"brokenAt,omitempty"` // First sequence whose hash does not match
+	CheckedAt      time.Time         `json:"checkedAt"`
+}
+
+// Clean reports whether the series is sequential, without duplicates and unedited
+func (r *NumberingReport) Clean() bool {
+	return r.ChainValid && len(r.Gaps) == 0 && len(r.Duplicates) == 0
+}
+
+// NumberingAudit builds a NumberingReport from ledger rows read in sequence order
+type NumberingAudit struct {
+	report   *NumberingReport
+	prevHash string
+	numbers  map[string][]int
+	order    []string
+}
+
+// NewNumberingAudit starts checking the series of fy for documentType
+func NewNumberingAudit(fy *FiscalYear, documentType DocumentType) *NumberingAudit {
+	return &NumberingAudit{
+		report: &NumberingReport{
+			FiscalYearID:   fy.ID,
+			FiscalYearName: fy.Name,
+			DocumentType:   documentType,
+			Counter:        fy.Counter(documentType),
+			Gaps:           []NumberGap{},
+			Duplicates:     []NumberDuplicate{},
+			ChainValid:     true,
+		},
+		numbers: map[string][]int{},
+	}
+}
+
+// Add checks the next ledger row
+func (a *NumberingAudit) Add(issue *NumberIssue) {
+	r := a.report
+	if r.Issued == 0 {
+		// The first row starts the chain with an empty previous hash; one that
+		// points back at an earlier row means rows before it are missing
+		r.FirstSequence = issue.Sequence
+	} else if issue.Sequence > r.LastSequence+1 {
+		r.Gaps = append(r.Gaps, NumberGap{From: r.LastSequence + 1, To: issue.Sequence - 1})
+	}
+
+	if r.ChainValid && (issue.PrevHash != a.prevHash || issue.ComputeHash() != issue.Hash) {
+		r.ChainValid = false
+		sequence := issue.Sequence
+		r.BrokenAt = &sequence
+	}
+	a.prevHash = issue.Hash
+
+	if _, seen := a.numbers[issue.Number]; !seen {
+		a.order = append(a.order, issue.Number)
+	}
+	a.numbers[issue.Number] = append(a.numbers[issue.Number], issue.Sequence)
+
+	if issue.DocumentID == nil {
+		r.Unused++
+	}
+	r.Issued++
+	r.LastSequence = issue.Sequence
+}
+
+// Report finishes the check. Counter values before the first ledger row predate
+// the ledger; those after the last row were handed out without being recorded
+// and count as a gap.
+func (a *NumberingAudit) Report() *NumberingReport {
+	r := a.report
+	if r.Issued == 0 {
+		r.Untracked = r.Counter
+	} else {
+		r.Untracked = r.FirstSequence - 1
+		if r.Counter > r.LastSequence {
+			r.Gaps = append(r.Gaps, NumberGap{From: r.LastSequence + 1, To: r.Counter})
+		}
+	}
+	for _, number := range a.order {
+		if sequences := a.numbers[number]; len(sequences) > 1 {
+			r.Duplicates = append(r.Duplicates, NumberDuplicate{Number: number, Sequences: sequences})
+		}
+	}
+	r.CheckedAt = time.Now()
+	return r
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRoundingRule is returned for a rounding rule with an unknown document type, increment or mode
+var ErrInvalidRoundingRule = errors.New("invalid rounding rule")
+
+// RoundingMode is how a total halfway between two increments is rounded
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half_up"   // Halves round away from zero
+	RoundHalfEven RoundingMode = "half_even" // Halves round to the even increment (bankers' rounding)
+)
+
+// RoundingIncrements are what document totals can be rounded to: paisa, ten paisa or rupees
+var RoundingIncrements = []float64{0.01, 0.1, 1}
+
+// RoundedDocumentTypes are the documents whose totals are rounded
+var RoundedDocumentTypes = []DocumentType{DocumentInvoice, DocumentPurchase}
+
+// RoundingRule is how a tenant rounds a document type's total. The difference
+// between the lines' total and the rounded total is shown on the document as its
+// round-off and posted to the round-off account.
+type RoundingRule struct {
+	TenantID     uuid.UUID    `json:"tenantId" db:"tenant_id"`
+	DocumentType DocumentType `json:"documentType" db:"document_type"`
+	Increment    float64      `json:"increment" db:"increment"` // 0.01, 0.1 or 1
+	Mode         RoundingMode `json:"mode" db:"mode"`
+	UpdatedAt    *time.Time   `json:"updatedAt,omitempty" db:"updated_at"` // Nil for the default rule
+}
+
+// DefaultRoundingRule rounds to the paisa, so documents have no round-off
+func DefaultRoundingRule(tenantID uuid.UUID, documentType DocumentType) *RoundingRule {
+	return &RoundingRule{TenantID: tenantID, DocumentType: documentType, Increment: 0.01, Mode: RoundHalfUp}
+}
+
+// NewRoundingRule creates a tenant's rounding rule for a document type
+func NewRoundingRule(tenantID uuid.UUID, documentType DocumentType, increment float64, mode RoundingMode) (*RoundingRule, error) {
+	if !documentType.Rounded() {
+		return nil, fmt.Errorf("%w: %q totals are not rounded", ErrInvalidRoundingRule, documentType)
+	}
+	valid := false
+	for _, allowed := range RoundingIncrements {
+		if math.Abs(increment-allowed) < 1e-9 {
+			increment, valid = allowed, true
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("%w: increment must be 0.01, 0.1 or 1", ErrInvalidRoundingRule)
+	}
+	if mode != RoundHalfUp && mode != RoundHalfEven {
+		return nil, fmt.Errorf("%w: mode must be half_up or half_even", ErrInvalidRoundingRule)
+	}
+
+	now := time.Now()
+	return &RoundingRule{TenantID: tenantID, DocumentType: documentType, Increment: increment, Mode: mode, UpdatedAt: &now}, nil
+}
+
+// Rounded reports whether the document type's totals are rounded
+func (t DocumentType) Rounded() bool {
+	for _, documentType := range RoundedDocumentTypes {
+		if t == documentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Round rounds an amount to the rule's increment
+func (r *RoundingRule) Round(amount float64) float64 {
+	// Count in paisa, so every increment is a whole number of steps and halves are exact
+	paisa := math.Round(amount * 100)
+	step := math.Round(r.Increment * 100)
+	if step < 1 {
+		step = 1
+	}
+	steps := paisa / step
+	if r.Mode == RoundHalfEven {
+		steps = math.RoundToEven(steps)
+	} else {
+		steps = math.Round(steps)
+	}
+	return steps * step / 100
+}
+
+// RoundOff returns a total rounded by the rule and the round-off added to reach
+// it, negative when the total was rounded down
+func (r *RoundingRule) RoundOff(total float64) (rounded, roundOff float64) {
+	rounded = r.Round(total)
+	return rounded, math.Round((rounded-total)*100) / 100
+}
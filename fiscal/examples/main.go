@@ -15,19 +15,17 @@ import (
 
 func main() {
 	// Load configuration
-	config.Load()
+	cfg := config.Load()
 
 	// Initialize database connections
-	mainConnStr := config.Get("DATABASE_URL")
-	auditConnStr := config.Get("AUDIT_DATABASE_URL")
-	db.Init(mainConnStr, auditConnStr)
+	db.Init(cfg.DatabaseURL, cfg.AuditDatabaseURL)
 	defer db.Close()
 
 	// Initialize fiscal module
 	fiscal.Init()
 
 	fmt.Println("📅 Fiscal Year Management Example")
-	fmt.Println("===================================\n")
+	fmt.Print("===================================\n\n")
 
 	ctx := context.Background()
 	tenantID := uuid.New()
@@ -74,7 +72,7 @@ func main() {
 
 		// Generate invoice numbers
 		for i := 1; i <= 5; i++ {
-			invoiceNum, err := fiscal.Service.GenerateInvoiceNumber(ctx, fy.ID)
+			invoiceNum, err := fiscal.Service.GenerateInvoiceNumber(ctx, tenantID, fy.ID)
 			if err != nil {
 				log.Printf("Error generating invoice number: %v", err)
 				break
@@ -86,7 +84,7 @@ func main() {
 		// Generate purchase numbers
 		fmt.Println("Purchase Numbers:")
 		for i := 1; i <= 3; i++ {
-			purchaseNum, err := fiscal.Service.GeneratePurchaseNumber(ctx, fy.ID)
+			purchaseNum, err := fiscal.Service.GeneratePurchaseNumber(ctx, tenantID, fy.ID)
 			if err != nil {
 				log.Printf("Error generating purchase number: %v", err)
 				break
@@ -98,7 +96,7 @@ func main() {
 		// Generate voucher numbers
 		fmt.Println("Voucher Numbers:")
 		for i := 1; i <= 3; i++ {
-			voucherNum, err := fiscal.Service.GenerateVoucherNumber(ctx, fy.ID)
+			voucherNum, err := fiscal.Service.GenerateVoucherNumber(ctx, tenantID, fy.ID)
 			if err != nil {
 				log.Printf("Error generating voucher number: %v", err)
 				break
@@ -129,7 +127,7 @@ func main() {
 	if fy != nil {
 		// Close fiscal year
 		closedBy := uuid.New()
-		err = fiscal.Service.Close(ctx, fy.ID, closedBy)
+		err = fiscal.Service.Close(ctx, tenantID, fy.ID, closedBy)
 		if err != nil {
 			log.Printf("Error closing fiscal year: %v", err)
 		} else {
@@ -137,13 +135,13 @@ func main() {
 		}
 
 		// Try to generate invoice number (should fail)
-		_, err = fiscal.Service.GenerateInvoiceNumber(ctx, fy.ID)
+		_, err = fiscal.Service.GenerateInvoiceNumber(ctx, tenantID, fy.ID)
 		if err != nil {
 			fmt.Printf("❌ Cannot generate invoice for closed fiscal year: %v\n", err)
 		}
 
 		// Reopen fiscal year
-		err = fiscal.Service.Reopen(ctx, fy.ID)
+		err = fiscal.Service.Reopen(ctx, tenantID, fy.ID)
 		if err != nil {
 			log.Printf("Error reopening fiscal year: %v", err)
 		} else {
@@ -151,7 +149,7 @@ func main() {
 		}
 
 		// Now can generate invoice
-		invoiceNum, err := fiscal.Service.GenerateInvoiceNumber(ctx, fy.ID)
+		invoiceNum, err := fiscal.Service.GenerateInvoiceNumber(ctx, tenantID, fy.ID)
 		if err != nil {
 			log.Printf("Error generating invoice: %v", err)
 		} else {
@@ -1,15 +1,194 @@
 package fiscal
 
 import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/fiscal/domain"
 	"github.com/aceextension/fiscal/repository"
 	"github.com/aceextension/fiscal/service"
+	"github.com/aceextension/fiscal/utils"
+	"github.com/aceextension/onboarding"
+	onboardingDomain "github.com/aceextension/onboarding/domain"
+	"github.com/google/uuid"
 )
 
 // Global fiscal year service instance
 var Service service.FiscalYearService
 
+// Global document number ledger service instance
+var NumberingService service.NumberingService
+
+// Global working calendar service instance
+var WorkCalendarService service.WorkCalendarService
+
+// Global document rounding rule service instance
+var RoundingService service.RoundingService
+
+// Global date display preference service instance
+var DatePreferenceService service.DatePreferenceService
+
 // Init initializes the fiscal module
 func Init() {
 	repo := repository.NewPostgresFiscalYearRepository()
-	Service = service.NewFiscalYearService(repo)
+	ledger := repository.NewPostgresNumberLedgerRepository()
+	Service = service.NewFiscalYearService(repo, ledger)
+	NumberingService = service.NewNumberingService(ledger, repo)
+	WorkCalendarService = service.NewWorkCalendarService(repository.NewPostgresHolidayRepository())
+	RoundingService = service.NewRoundingService(repository.NewPostgresRoundingRuleRepository())
+	DatePreferenceService = service.NewDatePreferenceService(repository.NewPostgresDatePreferenceRepository())
+
+	// Setting up a fiscal year is the first setup step; call onboarding.Init first
+	if onboarding.ChecklistService != nil {
+		onboarding.ChecklistService.RegisterStep(onboardingDomain.Step{Key: onboardingDomain.StepFiscalYear, Title: "Create your fiscal year"}, hasFiscalYear)
+	}
+}
+
+// hasFiscalYear reports whether the tenant has set up a fiscal year
+func hasFiscalYear(ctx context.Context, tenantID uuid.UUID) (bool, error) {
+	years, err := Service.GetByTenantID(ctx, tenantID)
+	return len(years) > 0, err
+}
+
+// GetActiveFiscalYear returns the current fiscal year for a tenant,
+// or nil if the module is not initialized or no fiscal year is set
+func GetActiveFiscalYear(ctx context.Context, tenantID uuid.UUID) *domain.FiscalYear {
+	if Service == nil {
+		return nil
+	}
+
+	fy, err := Service.GetCurrent(ctx, tenantID)
+	if err != nil {
+		return nil
+	}
+
+	return fy
+}
+
+// FiscalYearOn returns the tenant's fiscal year a date falls in, or nil if the module
+// is not initialized or no fiscal year covers the date
+func FiscalYearOn(ctx context.Context, tenantID uuid.UUID, date time.Time) (*domain.FiscalYear, error) {
+	if Service == nil {
+		return nil, nil
+	}
+	years, err := Service.GetByTenantID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, fy := range years {
+		// The end date is the year's last day, so any time on it counts
+		if !date.Before(fy.StartDate) && date.Before(fy.EndDate.AddDate(0, 0, 1)) {
+			return fy, nil
+		}
+	}
+	return nil, nil
+}
+
+// RecordDocument attaches a saved document to the number it was issued, so the
+// number ledger shows which numbers were used. It is a no-op without the module.
+func RecordDocument(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType, number string, documentID uuid.UUID) error {
+	if NumberingService == nil {
+		return nil
+	}
+	return NumberingService.RecordDocument(ctx, tenantID, documentType, number, documentID)
+}
+
+// RoundingRule returns how a tenant rounds a document type's total. Without the
+// module, totals are rounded to the paisa.
+func RoundingRule(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType) (*domain.RoundingRule, error) {
+	if RoundingService == nil {
+		return domain.DefaultRoundingRule(tenantID, documentType), nil
+	}
+	return RoundingService.Rule(ctx, tenantID, documentType)
+}
+
+// DateDisplay returns which calendar a tenant's documents and reports print first.
+// Without the module, or if the preference cannot be loaded, AD dates come first.
+func DateDisplay(ctx context.Context, tenantID uuid.UUID) utils.DateDisplay {
+	if DatePreferenceService == nil {
+		return utils.DefaultDateDisplay
+	}
+
+	pref, err := DatePreferenceService.Preference(ctx, tenantID)
+	if err != nil {
+		logger.Log.Error("Date preference error for tenant " + tenantID.String() + ": " + err.Error())
+		return utils.DefaultDateDisplay
+	}
+
+	return pref.Display
+}
+
+// WorkingCalendar returns a tenant's working days and holidays for due-date
+// calculations. Without the module, or if the tenant's holidays cannot be
+// loaded, it falls back to Saturdays and the built-in public holidays.
+func WorkingCalendar(ctx context.Context, tenantID uuid.UUID) *utils.WorkingCalendar {
+	if WorkCalendarService == nil {
+		return utils.DefaultWorkingCalendar()
+	}
+
+	cal, err := WorkCalendarService.Calendar(ctx, tenantID)
+	if err != nil {
+		logger.Log.Error("Working calendar error for tenant " + tenantID.String() + ": " + err.Error())
+		return utils.DefaultWorkingCalendar()
+	}
+
+	return cal
+}
+
+// WatchCalendarFile loads Nepali calendar data from path and reloads it when the
+// file changes, checked every interval. Conversion tables are only rebuilt when
+// the month lengths actually differ from those loaded.
+func WatchCalendarFile(path string, interval time.Duration) {
+	watchFile(path, interval, "Calendar", func() {
+		changed, err := utils.LoadCalendarFile(path)
+		if err != nil {
+			logger.Log.Error("Calendar reload error: " + err.Error())
+			return
+		}
+		if changed {
+			logger.Log.Info("Loaded Nepali calendar data version " + utils.CalendarVersion())
+		}
+	})
+}
+
+// WatchHolidayFile loads the dates of lunar festivals (Dashain, Tihar...) from
+// path and reloads them when the file changes, checked every interval. Years
+// already seeded for a tenant pick up new festivals through RestorePublicHolidays.
+func WatchHolidayFile(path string, interval time.Duration) {
+	watchFile(path, interval, "Holiday", func() {
+		if err := utils.LoadHolidayFile(path); err != nil {
+			logger.Log.Error("Holiday reload error: " + err.Error())
+			return
+		}
+		logger.Log.Info("Loaded public holiday data from " + path)
+	})
+}
+
+// watchFile runs load now and again whenever the file's modification time advances
+func watchFile(path string, interval time.Duration, name string, load func()) {
+	var lastModified time.Time
+
+	reload := func() {
+		info, err := os.Stat(path)
+		if err != nil {
+			logger.Log.Error(name + " file error: " + err.Error())
+			return
+		}
+		if !info.ModTime().After(lastModified) {
+			return
+		}
+		lastModified = info.ModTime()
+		load()
+	}
+
+	reload()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			reload()
+		}
+	}()
 }
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/fiscal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// CalendarHandler handles HTTP requests for Nepali calendar metadata
+type CalendarHandler struct{}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler() *CalendarHandler {
+	return &CalendarHandler{}
+}
+
+// GetYear godoc
+// @Summary Get Nepali calendar year
+// @Description Month lengths, AD spans and the fiscal year starting in Shrawan of a BS year.
+// @Description Responses carry an ETag of the calendar data version; send If-None-Match to get 304 until the data is reloaded.
+// @Tags fiscal
+// @Produce json
+// @Param year path int true "BS year (e.g. 2082)"
+// @Success 200 {object} utils.CalendarYear
+// @Success 304
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/fiscal/calendar/{year} [get]
+func (h *CalendarHandler) GetYear(c echo.Context) error {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid year"})
+	}
+
+	calendarYear, err := utils.GetCalendarYear(year)
+	if err != nil {
+		if errors.Is(err, utils.ErrYearNotInCalendar) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// The data only changes on reload, so its version makes a strong validator
+	etag := `"` + calendarYear.Version + `-` + strconv.Itoa(year) + `"`
+	c.Response().Header().Set("ETag", etag)
+	c.Response().Header().Set("Cache-Control", "public, max-age=3600")
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSON(http.StatusOK, calendarYear)
+}
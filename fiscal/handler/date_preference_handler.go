@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/fiscal"
+	"github.com/aceextension/fiscal/domain"
+	"github.com/aceextension/fiscal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// DatePreferenceHandler handles HTTP requests for which calendar a tenant prints first
+type DatePreferenceHandler struct{}
+
+// NewDatePreferenceHandler creates a new date preference handler
+func NewDatePreferenceHandler() *DatePreferenceHandler {
+	return &DatePreferenceHandler{}
+}
+
+// DatePreferenceRequest sets which calendar is printed first
+type DatePreferenceRequest struct {
+	Display utils.DateDisplay `json:"display"` // ad_primary or bs_primary
+}
+
+// GetDatePreference godoc
+// @Summary Get the date display
+// @Description Which calendar invoices, ledgers, trial balances, statements and export PDFs print first.
+// @Description Both the AD and BS dates are always printed. Tenants that never set it print AD first.
+// @Tags fiscal
+// @Produce json
+// @Success 200 {object} domain.DatePreference
+// @Router /api/v1/fiscal/date-display [get]
+// @Security BearerAuth
+func (h *DatePreferenceHandler) GetDatePreference(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	pref, err := fiscal.DatePreferenceService.Preference(c.Request().Context(), tenantID)
+	if err != nil {
+		return datePreferenceError(c, err)
+	}
+	return c.JSON(http.StatusOK, pref)
+}
+
+// SetDatePreference godoc
+// @Summary Set the date display
+// @Description Print BS dates first (bs_primary), e.g. "2082-06-30 BS (2025-10-16)", or AD dates
+// @Description first (ad_primary), e.g. "2025-10-16 (2082-06-30 BS)", on documents and reports from now on.
+// @Tags fiscal
+// @Accept json
+// @Produce json
+// @Param request body DatePreferenceRequest true "Date display"
+// @Success 200 {object} domain.DatePreference
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/fiscal/date-display [put]
+// @Security BearerAuth
+func (h *DatePreferenceHandler) SetDatePreference(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var req DatePreferenceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	pref, err := fiscal.DatePreferenceService.SetPreference(c.Request().Context(), tenantID, req.Display)
+	if err != nil {
+		return datePreferenceError(c, err)
+	}
+	return c.JSON(http.StatusOK, pref)
+}
+
+// datePreferenceError maps date preference errors to HTTP statuses
+func datePreferenceError(c echo.Context, err error) error {
+	if errors.Is(err, domain.ErrInvalidDateDisplay) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
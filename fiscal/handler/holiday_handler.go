@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/fiscal"
+	"github.com/aceextension/fiscal/domain"
+	"github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// HolidayHandler handles HTTP requests for a tenant's working days and holidays
+type HolidayHandler struct{}
+
+// NewHolidayHandler creates a new holiday handler
+func NewHolidayHandler() *HolidayHandler {
+	return &HolidayHandler{}
+}
+
+// WorkWeekRequest sets the weekly closing days
+type WorkWeekRequest struct {
+	WeekendDays []time.Weekday `json:"weekendDays"` // 0 = Sunday ... 6 = Saturday
+}
+
+// HolidayRequest adds a holiday on an AD or BS date
+type HolidayRequest struct {
+	Date   string `json:"date,omitempty"`   // AD, YYYY-MM-DD
+	DateBS string `json:"dateBs,omitempty"` // BS, YYYY-MM-DD; used when date is empty
+	Name   string `json:"name"`
+}
+
+// DueDateResponse is a date computed against the tenant's working calendar
+type DueDateResponse struct {
+	DueDate   string `json:"dueDate"`   // AD, YYYY-MM-DD
+	DueDateBS string `json:"dueDateBs"` // BS, YYYY-MM-DD
+}
+
+// GetWorkWeek godoc
+// @Summary Get weekly closing days
+// @Description The tenant's weekend days (0 = Sunday ... 6 = Saturday); Saturday unless set
+// @Tags fiscal
+// @Produce json
+// @Success 200 {object} domain.WorkWeek
+// @Router /api/v1/fiscal/work-week [get]
+// @Security BearerAuth
+func (h *HolidayHandler) GetWorkWeek(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	week, err := fiscal.WorkCalendarService.GetWorkWeek(c.Request().Context(), tenantID)
+	if err != nil {
+		return holidayError(c, err)
+	}
+	return c.JSON(http.StatusOK, week)
+}
+
+// SetWorkWeek godoc
+// @Summary Set weekly closing days
+// @Description Replace the tenant's weekend days, e.g. [0, 6] for businesses closed on Sunday too
+// @Tags fiscal
+// @Accept json
+// @Produce json
+// @Param request body WorkWeekRequest true "Weekend days"
+// @Success 200 {object} domain.WorkWeek
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/fiscal/work-week [put]
+// @Security BearerAuth
+func (h *HolidayHandler) SetWorkWeek(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var req WorkWeekRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	week, err := fiscal.WorkCalendarService.SetWorkWeek(c.Request().Context(), tenantID, req.WeekendDays)
+	if err != nil {
+		return holidayError(c, err)
+	}
+	return c.JSON(http.StatusOK, week)
+}
+
+// ListHolidays godoc
+// @Summary List holidays
+// @Description The tenant's holidays in a BS year. Nepali public holidays are copied in the first time a year is
+// @Description listed; the tenant can then delete those it stays open on and add its own.
+// @Tags fiscal
+// @Produce json
+// @Param year query int false "BS year; defaults to the current one"
+// @Success 200 {array} domain.Holiday
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/fiscal/holidays [get]
+// @Security BearerAuth
+func (h *HolidayHandler) ListHolidays(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	year, err := yearParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid year"})
+	}
+
+	holidays, err := fiscal.WorkCalendarService.ListHolidays(c.Request().Context(), tenantID, year)
+	if err != nil {
+		return holidayError(c, err)
+	}
+	return c.JSON(http.StatusOK, holidays)
+}
+
+// AddHoliday godoc
+// @Summary Add a holiday
+// @Description Close the tenant on a date given in AD or BS, e.g. for a local jatra
+// @Tags fiscal
+// @Accept json
+// @Produce json
+// @Param request body HolidayRequest true "Holiday"
+// @Success 201 {object} domain.Holiday
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/fiscal/holidays [post]
+// @Security BearerAuth
+func (h *HolidayHandler) AddHoliday(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var req HolidayRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	var date time.Time
+	var err error
+	if req.Date != "" {
+		date, err = time.Parse("2006-01-02", req.Date)
+	} else {
+		var bs utils.NepaliDate
+		if bs, err = utils.ParseNepaliDate(req.DateBS); err == nil {
+			date = utils.BSToAD(bs)
+		}
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid date"})
+	}
+
+	holiday, err := fiscal.WorkCalendarService.AddHoliday(c.Request().Context(), tenantID, date, req.Name)
+	if err != nil {
+		return holidayError(c, err)
+	}
+	return c.JSON(http.StatusCreated, holiday)
+}
+
+// DeleteHoliday godoc
+// @Summary Delete a holiday
+// @Description Reopen the tenant on a holiday, public or its own
+// @Tags fiscal
+// @Param id path string true "Holiday ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/fiscal/holidays/{id} [delete]
+// @Security BearerAuth
+func (h *HolidayHandler) DeleteHoliday(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid holiday ID"})
+	}
+
+	if err := fiscal.WorkCalendarService.DeleteHoliday(c.Request().Context(), tenantID, id); err != nil {
+		return holidayError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RestorePublicHolidays godoc
+// @Summary Restore public holidays
+// @Description Add back the public holidays of a BS year the tenant does not have, including festival dates published after the year was first listed
+// @Tags fiscal
+// @Produce json
+// @Param year query int false "BS year; defaults to the current one"
+// @Success 200 {object} map[string]int
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/fiscal/holidays/restore [post]
+// @Security BearerAuth
+func (h *HolidayHandler) RestorePublicHolidays(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	year, err := yearParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid year"})
+	}
+
+	added, err := fiscal.WorkCalendarService.RestorePublicHolidays(c.Request().Context(), tenantID, year)
+	if err != nil {
+		return holidayError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]int{"added": added})
+}
+
+// GetDueDate godoc
+// @Summary Compute a due date
+// @Description Add days to a date against the tenant's working calendar. By default days are calendar days, as in
+// @Description "net 30", and a result on a weekend or holiday rolls forward; with working=true only working days count.
+// @Tags fiscal
+// @Produce json
+// @Param from query string false "AD start date (YYYY-MM-DD); defaults to today"
+// @Param days query int false "Days to add"
+// @Param working query bool false "Count working days only"
+// @Success 200 {object} DueDateResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/fiscal/due-date [get]
+// @Security BearerAuth
+func (h *HolidayHandler) GetDueDate(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	from := time.Now()
+	if value := c.QueryParam("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from date"})
+		}
+		from = parsed
+	}
+	days := 0
+	if value := c.QueryParam("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid days"})
+		}
+		days = parsed
+	}
+
+	cal := fiscal.WorkingCalendar(c.Request().Context(), tenantID)
+	var due time.Time
+	if c.QueryParam("working") == "true" {
+		due = cal.AddWorkingDays(from, days)
+	} else {
+		due = cal.DueDate(from, days)
+	}
+
+	return c.JSON(http.StatusOK, DueDateResponse{
+		DueDate:   due.Format("2006-01-02"),
+		DueDateBS: utils.ADToBS(due).String(),
+	})
+}
+
+// yearParam reads the BS year query parameter, defaulting to the current year
+func yearParam(c echo.Context) (int, error) {
+	if value := c.QueryParam("year"); value != "" {
+		return strconv.Atoi(value)
+	}
+	return utils.GetCurrentNepaliDate().Year, nil
+}
+
+// holidayError maps working calendar errors to HTTP responses
+func holidayError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidHoliday), errors.Is(err, domain.ErrInvalidWeekend):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrHolidayNotFound), errors.Is(err, utils.ErrYearNotInCalendar):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrHolidayExists):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/fiscal"
+	"github.com/aceextension/fiscal/domain"
+	"github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// NumberingHandler handles HTTP requests for the document number ledger
+type NumberingHandler struct{}
+
+// NewNumberingHandler creates a new numbering handler
+func NewNumberingHandler() *NumberingHandler {
+	return &NumberingHandler{}
+}
+
+// numberingExportHeader is the column layout of the number register handed to tax officers
+var numberingExportHeader = []string{
+	"Fiscal Year", "Document Type", "S.N.", "Document Number", "Issued Date (BS)", "Issued Date (AD)",
+	"Issued Time", "Issued By", "Document ID", "Status", "Hash",
+}
+
+// GetReport godoc
+// @Summary Check document numbering
+// @Description Walks a fiscal year's invoice, purchase or voucher numbers in order and reports gaps,
+// @Description duplicates, numbers never used on a document and whether the hash chain shows an edit
+// @Tags fiscal
+// @Produce json
+// @Param fiscalYearId query string false "Fiscal year ID; defaults to the current one"
+// @Param type query string false "invoice (default), purchase or voucher"
+// @Success 200 {object} domain.NumberingReport
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/fiscal/numbering/report [get]
+// @Security BearerAuth
+func (h *NumberingHandler) GetReport(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	fy, documentType, err := h.series(c, tenantID)
+	if err != nil {
+		return numberingError(c, err)
+	}
+
+	report, err := fiscal.NumberingService.Report(c.Request().Context(), tenantID, fy.ID, documentType)
+	if err != nil {
+		return numberingError(c, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// Export godoc
+// @Summary Export the document number register
+// @Description Every number issued in a fiscal year's series as CSV, in order: issue date in BS and AD, the user
+// @Description who took it, the document it was used on (or UNUSED) and the row hash
+// @Tags fiscal
+// @Produce text/csv
+// @Param fiscalYearId query string false "Fiscal year ID; defaults to the current one"
+// @Param type query string false "invoice (default), purchase or voucher"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/fiscal/numbering/export [get]
+// @Security BearerAuth
+func (h *NumberingHandler) Export(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	fy, documentType, err := h.series(c, tenantID)
+	if err != nil {
+		return numberingError(c, err)
+	}
+
+	// Headers go out with the first row so a failed read can still answer in JSON
+	var out *csv.Writer
+	rows := 0
+	start := func() error {
+		res := c.Response()
+		res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+		res.Header().Set(echo.HeaderContentDisposition,
+			fmt.Sprintf(`attachment; filename="%s-numbers-%s.csv"`, documentType, fy.Code()))
+		res.WriteHeader(http.StatusOK)
+		out = csv.NewWriter(res)
+		return out.Write(numberingExportHeader)
+	}
+
+	err = fiscal.NumberingService.Export(c.Request().Context(), tenantID, fy.ID, documentType, func(issue *domain.NumberIssue) error {
+		if out == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		rows++
+		if err := out.Write(numberingExportRow(fy, issue)); err != nil {
+			return err
+		}
+		if rows%500 == 0 {
+			out.Flush()
+			c.Response().Flush()
+		}
+		return out.Error()
+	})
+	if err != nil && out == nil {
+		return numberingError(c, err)
+	}
+	if err != nil {
+		c.Logger().Errorf("numbering export failed after %d rows: %v", rows, err)
+		out.Flush()
+		return nil
+	}
+
+	// No numbers issued yet: an empty register
+	if out == nil {
+		if err := start(); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// series reads the fiscal year and document type of the request
+func (h *NumberingHandler) series(c echo.Context, tenantID uuid.UUID) (*domain.FiscalYear, domain.DocumentType, error) {
+	documentType := domain.DocumentInvoice
+	if value := c.QueryParam("type"); value != "" {
+		documentType = domain.DocumentType(value)
+	}
+	if !documentType.Valid() {
+		return nil, "", domain.ErrInvalidDocumentType
+	}
+
+	if value := c.QueryParam("fiscalYearId"); value != "" {
+		fiscalYearID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, "", errInvalidFiscalYearID
+		}
+		fy, err := fiscal.Service.GetByID(c.Request().Context(), tenantID, fiscalYearID)
+		if err != nil {
+			return nil, "", domain.ErrFiscalYearNotFound
+		}
+		return fy, documentType, nil
+	}
+
+	fy := fiscal.GetActiveFiscalYear(c.Request().Context(), tenantID)
+	if fy == nil {
+		return nil, "", domain.ErrFiscalYearNotFound
+	}
+	return fy, documentType, nil
+}
+
+// errInvalidFiscalYearID is returned for a fiscalYearId that is not a UUID
+var errInvalidFiscalYearID = errors.New("Invalid fiscal year ID")
+
+// numberingExportRow formats a ledger row in numberingExportHeader order
+func numberingExportRow(fy *domain.FiscalYear, issue *domain.NumberIssue) []string {
+	issuedBy, documentID, status := "", "", "UNUSED"
+	if issue.IssuedBy != nil {
+		issuedBy = issue.IssuedBy.String()
+	}
+	if issue.DocumentID != nil {
+		documentID, status = issue.DocumentID.String(), "USED"
+	}
+
+	// Issue times are stored in UTC; the register reads in local time
+	issuedAt := issue.IssuedAt.Local()
+	return []string{
+		fy.Name,
+		string(issue.DocumentType),
+		strconv.Itoa(issue.Sequence),
+		issue.Number,
+		utils.ADToBS(issuedAt).String(),
+		issuedAt.Format("2006-01-02"),
+		issuedAt.Format("15:04:05"),
+		issuedBy,
+		documentID,
+		status,
+		issue.Hash,
+	}
+}
+
+// numberingError maps numbering errors to HTTP statuses
+func numberingError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDocumentType), errors.Is(err, errInvalidFiscalYearID):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrFiscalYearNotFound), errors.Is(err, domain.ErrNumberNotIssued):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrNumberAlreadyUsed):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
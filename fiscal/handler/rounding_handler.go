@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/fiscal"
+	"github.com/aceextension/fiscal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// RoundingHandler handles HTTP requests for a tenant's document rounding rules
+type RoundingHandler struct{}
+
+// NewRoundingHandler creates a new rounding handler
+func NewRoundingHandler() *RoundingHandler {
+	return &RoundingHandler{}
+}
+
+// RoundingRuleRequest sets how a document type's total is rounded
+type RoundingRuleRequest struct {
+	Increment float64             `json:"increment"` // 0.01, 0.1 or 1
+	Mode      domain.RoundingMode `json:"mode"`      // half_up or half_even
+}
+
+// ListRoundingRules godoc
+// @Summary List rounding rules
+// @Description How invoice and purchase totals are rounded. Types without a rule of their own show the
+// @Description default, to the paisa with halves rounded up, which leaves no round-off.
+// @Tags fiscal
+// @Produce json
+// @Success 200 {array} domain.RoundingRule
+// @Router /api/v1/fiscal/rounding [get]
+// @Security BearerAuth
+func (h *RoundingHandler) ListRoundingRules(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rules, err := fiscal.RoundingService.Rules(c.Request().Context(), tenantID)
+	if err != nil {
+		return roundingError(c, err)
+	}
+	return c.JSON(http.StatusOK, rules)
+}
+
+// SetRoundingRule godoc
+// @Summary Set a rounding rule
+// @Description Round a document type's total to 0.01, 0.1 or 1 rupee, halves up or to even. The difference is
+// @Description added to new documents as their round-off and posted to the round_off posting account.
+// @Tags fiscal
+// @Accept json
+// @Produce json
+// @Param type path string true "invoice or purchase"
+// @Param request body RoundingRuleRequest true "Rounding rule"
+// @Success 200 {object} domain.RoundingRule
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/fiscal/rounding/{type} [put]
+// @Security BearerAuth
+func (h *RoundingHandler) SetRoundingRule(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var req RoundingRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	rule, err := fiscal.RoundingService.SetRule(c.Request().Context(), tenantID, domain.DocumentType(c.Param("type")), req.Increment, req.Mode)
+	if err != nil {
+		return roundingError(c, err)
+	}
+	return c.JSON(http.StatusOK, rule)
+}
+
+// ResetRoundingRule godoc
+// @Summary Reset a rounding rule
+// @Description Go back to rounding the document type's total to the paisa
+// @Tags fiscal
+// @Produce json
+// @Param type path string true "invoice or purchase"
+// @Success 200 {object} domain.RoundingRule
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/fiscal/rounding/{type} [delete]
+// @Security BearerAuth
+func (h *RoundingHandler) ResetRoundingRule(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	rule, err := fiscal.RoundingService.ResetRule(c.Request().Context(), tenantID, domain.DocumentType(c.Param("type")))
+	if err != nil {
+		return roundingError(c, err)
+	}
+	return c.JSON(http.StatusOK, rule)
+}
+
+// roundingError maps rounding rule errors to HTTP statuses
+func roundingError(c echo.Context, err error) error {
+	if errors.Is(err, domain.ErrInvalidRoundingRule) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
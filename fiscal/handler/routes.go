@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"github.com/aceextension/core/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes registers the fiscal module routes
+func RegisterRoutes(e *echo.Echo) {
+	calendarHandler := NewCalendarHandler()
+	holidayHandler := NewHolidayHandler()
+	numberingHandler := NewNumberingHandler()
+	roundingHandler := NewRoundingHandler()
+	datePreferenceHandler := NewDatePreferenceHandler()
+
+	// Calendar data is the same for every tenant, so no tenant middleware
+	v1 := e.Group("/api/v1/fiscal")
+
+	v1.GET("/calendar/:year", calendarHandler.GetYear)
+
+	// Working days and holidays are set per tenant
+	tenant := e.Group("/api/v1/fiscal", middleware.TenantMiddleware)
+
+	tenant.GET("/work-week", holidayHandler.GetWorkWeek)
+	tenant.PUT("/work-week", holidayHandler.SetWorkWeek)
+	tenant.GET("/holidays", holidayHandler.ListHolidays)
+	tenant.POST("/holidays", holidayHandler.AddHoliday)
+	tenant.POST("/holidays/restore", holidayHandler.RestorePublicHolidays)
+	tenant.DELETE("/holidays/:id", holidayHandler.DeleteHoliday)
+	tenant.GET("/due-date", holidayHandler.GetDueDate)
+
+	// Document number ledger for tax inspection
+	tenant.GET("/numbering/report", numberingHandler.GetReport)
+	tenant.GET("/numbering/export", numberingHandler.Export)
+
+	// How invoice and purchase totals are rounded
+	tenant.GET("/rounding", roundingHandler.ListRoundingRules)
+	tenant.PUT("/rounding/:type", roundingHandler.SetRoundingRule)
+	tenant.DELETE("/rounding/:type", roundingHandler.ResetRoundingRule)
+
+	// Which calendar documents and reports print first
+	tenant.GET("/date-display", datePreferenceHandler.GetDatePreference)
+	tenant.PUT("/date-display", datePreferenceHandler.SetDatePreference)
+}
@@ -0,0 +1,69 @@
+-- Migration: Create tenant working calendar tables
+-- Weekly closing days and holidays, used to roll due dates forward and pause dunning
+
+CREATE TABLE IF NOT EXISTS work_weeks (
+    tenant_id UUID PRIMARY KEY,
+    weekend_days SMALLINT[] NOT NULL DEFAULT '{6}', -- 0 = Sunday ... 6 = Saturday
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS holidays (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    date DATE NOT NULL,                           -- Gregorian date
+    date_bs VARCHAR(15) NOT NULL,                 -- Bikram Sambat date (YYYY-MM-DD)
+    name VARCHAR(100) NOT NULL,
+    source VARCHAR(10) NOT NULL DEFAULT 'custom', -- public (seeded) or custom
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT unique_holiday_date UNIQUE (tenant_id, date),
+    CONSTRAINT check_holiday_source CHECK (source IN ('public', 'custom'))
+);
+
+-- BS years whose public holidays were copied to the tenant, so a holiday the
+-- tenant deleted is not seeded again
+CREATE TABLE IF NOT EXISTS holiday_seeds (
+    tenant_id UUID NOT NULL,
+    bs_year INTEGER NOT NULL,
+    seeded_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, bs_year),
+    CONSTRAINT fk_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+-- Comments
+COMMENT ON TABLE work_weeks IS 'Weekly closing days of each tenant; Saturday when unset';
+COMMENT ON TABLE holidays IS 'Days a tenant is closed: seeded Nepali public holidays and its own';
+COMMENT ON TABLE holiday_seeds IS 'BS years whose public holidays have been seeded for a tenant';
+COMMENT ON COLUMN holidays.date_bs IS 'Holiday date in Bikram Sambat (YYYY-MM-DD)';
+COMMENT ON COLUMN holidays.source IS 'public when seeded from the national holidays, custom when added by the tenant';
+
+-- Enable RLS
+ALTER TABLE work_weeks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE holidays ENABLE ROW LEVEL SECURITY;
+ALTER TABLE holiday_seeds ENABLE ROW LEVEL SECURITY;
+
+-- Create tenant isolation policies
+DROP POLICY IF EXISTS tenant_isolation ON work_weeks;
+CREATE POLICY tenant_isolation ON work_weeks
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+DROP POLICY IF EXISTS tenant_isolation ON holidays;
+CREATE POLICY tenant_isolation ON holidays
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+DROP POLICY IF EXISTS tenant_isolation ON holiday_seeds;
+CREATE POLICY tenant_isolation ON holiday_seeds
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
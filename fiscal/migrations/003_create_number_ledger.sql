@@ -0,0 +1,76 @@
+-- Migration: Create number_ledger table
+-- Every invoice, purchase and voucher number as it was issued, for IRD inspection.
+-- Rows are hash-chained per fiscal year and document type and cannot be edited or deleted.
+
+CREATE TABLE IF NOT EXISTS number_ledger (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    fiscal_year_id UUID NOT NULL,
+    document_type VARCHAR(20) NOT NULL,           -- invoice, purchase or voucher
+    sequence INTEGER NOT NULL,                    -- Counter value, 1 for the first number of the year
+    number VARCHAR(50) NOT NULL,                  -- e.g., "INV-8283-0001"
+    issued_at TIMESTAMP NOT NULL,
+    issued_by UUID,
+    document_id UUID,                             -- Attached once the document is saved
+    attached_at TIMESTAMP,
+    prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+    hash VARCHAR(64) NOT NULL,
+
+    -- Constraints
+    CONSTRAINT fk_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT fk_fiscal_year FOREIGN KEY (fiscal_year_id) REFERENCES fiscal_years(id) ON DELETE CASCADE,
+    CONSTRAINT chk_number_ledger_document_type CHECK (document_type IN ('invoice', 'purchase', 'voucher')),
+    CONSTRAINT chk_number_ledger_sequence CHECK (sequence > 0),
+    -- The number itself is not unique so a duplicate shows up in the report instead of failing silently
+    CONSTRAINT uq_number_ledger_sequence UNIQUE (fiscal_year_id, document_type, sequence)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_number_ledger_number ON number_ledger(tenant_id, document_type, number);
+CREATE INDEX IF NOT EXISTS idx_number_ledger_document ON number_ledger(document_id) WHERE document_id IS NOT NULL;
+
+-- Rows may only change to attach the document once, and may only be deleted
+-- together with their tenant
+CREATE OR REPLACE FUNCTION protect_number_ledger()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        IF EXISTS (SELECT 1 FROM tenants WHERE id = OLD.tenant_id) THEN
+            RAISE EXCEPTION 'number ledger rows cannot be deleted';
+        END IF;
+        RETURN OLD;
+    END IF;
+
+    IF OLD.document_id IS NOT NULL
+       OR (NEW.id, NEW.tenant_id, NEW.fiscal_year_id, NEW.document_type, NEW.sequence, NEW.number,
+           NEW.issued_at, NEW.issued_by, NEW.prev_hash, NEW.hash)
+          IS DISTINCT FROM
+          (OLD.id, OLD.tenant_id, OLD.fiscal_year_id, OLD.document_type, OLD.sequence, OLD.number,
+           OLD.issued_at, OLD.issued_by, OLD.prev_hash, OLD.hash) THEN
+        RAISE EXCEPTION 'number ledger rows cannot be edited';
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS protect_number_ledger ON number_ledger;
+CREATE TRIGGER protect_number_ledger BEFORE UPDATE OR DELETE ON number_ledger
+    FOR EACH ROW EXECUTE FUNCTION protect_number_ledger();
+
+-- Comments
+COMMENT ON TABLE number_ledger IS 'Append-only record of every document number issued, for IRD inspection';
+COMMENT ON COLUMN number_ledger.sequence IS 'Fiscal year counter value the number was formatted from';
+COMMENT ON COLUMN number_ledger.document_id IS 'Document the number was used on; set once';
+COMMENT ON COLUMN number_ledger.prev_hash IS 'Hash of the previous row in the fiscal year and document type, empty for the first';
+COMMENT ON COLUMN number_ledger.hash IS 'SHA-256 over prev_hash and the issued fields';
+
+-- Enable RLS for number_ledger
+ALTER TABLE number_ledger ENABLE ROW LEVEL SECURITY;
+
+-- Create tenant isolation policy
+DROP POLICY IF EXISTS tenant_isolation ON number_ledger;
+CREATE POLICY tenant_isolation ON number_ledger
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,30 @@
+-- Migration: Create document rounding rules
+-- How each tenant rounds invoice and purchase totals; documents without a rule are
+-- rounded to the paisa and carry no round-off
+
+CREATE TABLE IF NOT EXISTS rounding_rules (
+    tenant_id UUID NOT NULL,
+    document_type VARCHAR(20) NOT NULL,  -- invoice, purchase
+    increment DECIMAL(4, 2) NOT NULL,    -- 0.01, 0.10 or 1.00
+    mode VARCHAR(10) NOT NULL,           -- half_up, half_even
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, document_type),
+    CONSTRAINT fk_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT check_rounding_document_type CHECK (document_type IN ('invoice', 'purchase')),
+    CONSTRAINT check_rounding_increment CHECK (increment IN (0.01, 0.10, 1.00)),
+    CONSTRAINT check_rounding_mode CHECK (mode IN ('half_up', 'half_even'))
+);
+
+COMMENT ON TABLE rounding_rules IS 'Rounding of document totals per tenant; the difference is the document''s round-off';
+
+-- Enable RLS
+ALTER TABLE rounding_rules ENABLE ROW LEVEL SECURITY;
+
+-- Create tenant isolation policy
+DROP POLICY IF EXISTS tenant_isolation ON rounding_rules;
+CREATE POLICY tenant_isolation ON rounding_rules
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
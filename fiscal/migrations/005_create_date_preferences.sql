@@ -0,0 +1,25 @@
+-- Migration: Create date display preferences
+-- Which calendar each tenant's documents and reports print first; tenants without a
+-- preference print AD dates first, with the BS date alongside
+
+CREATE TABLE IF NOT EXISTS date_preferences (
+    tenant_id UUID PRIMARY KEY,
+    display VARCHAR(20) NOT NULL,  -- ad_primary, bs_primary
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT check_date_display CHECK (display IN ('ad_primary', 'bs_primary'))
+);
+
+COMMENT ON TABLE date_preferences IS 'Calendar printed first on documents and reports per tenant';
+
+-- Enable RLS
+ALTER TABLE date_preferences ENABLE ROW LEVEL SECURITY;
+
+-- Create tenant isolation policy
+DROP POLICY IF EXISTS tenant_isolation ON date_preferences;
+CREATE POLICY tenant_isolation ON date_preferences
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/fiscal/domain"
+	"github.com/google/uuid"
+)
+
+// DatePreferenceRepository defines the interface for tenants' date display preferences
+type DatePreferenceRepository interface {
+	// Get retrieves the tenant's preference, or nil if never set
+	Get(ctx context.Context, tenantID uuid.UUID) (*domain.DatePreference, error)
+
+	// Save creates or replaces the tenant's preference
+	Save(ctx context.Context, pref *domain.DatePreference) error
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/fiscal/domain"
+	"github.com/google/uuid"
+)
+
+// HolidayRepository defines the interface for tenant working calendar data access
+type HolidayRepository interface {
+	// GetWorkWeek retrieves the tenant's weekly closing days, or nil if never set
+	GetWorkWeek(ctx context.Context, tenantID uuid.UUID) (*domain.WorkWeek, error)
+
+	// SaveWorkWeek creates or replaces the tenant's weekly closing days
+	SaveWorkWeek(ctx context.Context, week *domain.WorkWeek) error
+
+	// Create creates a holiday; ErrHolidayExists if the date is taken
+	Create(ctx context.Context, holiday *domain.Holiday) error
+
+	// GetByID retrieves a holiday of the tenant
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Holiday, error)
+
+	// ListBetween retrieves the tenant's holidays from one AD date to another, inclusive
+	ListBetween(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.Holiday, error)
+
+	// Delete deletes a holiday of the tenant
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+
+	// IsSeeded reports whether the public holidays of a BS year were seeded for the tenant
+	IsSeeded(ctx context.Context, tenantID uuid.UUID, bsYear int) (bool, error)
+
+	// Seed copies public holidays of a BS year once; dates the tenant already
+	// has are kept. It reports whether the year was seeded by this call.
+	Seed(ctx context.Context, tenantID uuid.UUID, bsYear int, holidays []*domain.Holiday) (bool, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/fiscal/domain"
+	"github.com/google/uuid"
+)
+
+// NumberLedgerRepository defines the interface for issuing document numbers and
+// reading them back for inspection
+type NumberLedgerRepository interface {
+	// IssueNumber increments the fiscal year's counter for the document type and
+	// records the number in the ledger, in one transaction
+	IssueNumber(ctx context.Context, fiscalYearID uuid.UUID, documentType domain.DocumentType, issuedBy *uuid.UUID) (*domain.NumberIssue, error)
+
+	// GetByNumber retrieves the latest issue of a number in a tenant's ledger
+	GetByNumber(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType, number string) (*domain.NumberIssue, error)
+
+	// AttachDocument records the document a number was used on, if none is yet
+	AttachDocument(ctx context.Context, issueID, documentID uuid.UUID) (bool, error)
+
+	// StreamIssues passes a fiscal year's issues of a document type to fn in
+	// sequence order, without collecting them
+	StreamIssues(ctx context.Context, fiscalYearID uuid.UUID, documentType domain.DocumentType, fn func(*domain.NumberIssue) error) error
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/fiscal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresDatePreferenceRepository implements DatePreferenceRepository using PostgreSQL
+type PostgresDatePreferenceRepository struct{}
+
+// NewPostgresDatePreferenceRepository creates a new PostgreSQL date preference repository
+func NewPostgresDatePreferenceRepository() *PostgresDatePreferenceRepository {
+	return &PostgresDatePreferenceRepository{}
+}
+
+// Get retrieves the tenant's preference, or nil if never set
+func (r *PostgresDatePreferenceRepository) Get(ctx context.Context, tenantID uuid.UUID) (*domain.DatePreference, error) {
+	pref := &domain.DatePreference{TenantID: tenantID}
+	err := db.MainPool.QueryRow(ctx, `
+		SELECT display, updated_at FROM date_preferences WHERE tenant_id = $1
+	`, tenantID).Scan(&pref.Display, &pref.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get date preference: %w", err)
+	}
+	return pref, nil
+}
+
+// Save creates or replaces the tenant's preference
+func (r *PostgresDatePreferenceRepository) Save(ctx context.Context, pref *domain.DatePreference) error {
+	_, err := db.MainPool.Exec(ctx, `
+		INSERT INTO date_preferences (tenant_id, display, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			display = EXCLUDED.display, updated_at = EXCLUDED.updated_at
+	`, pref.TenantID, pref.Display, pref.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save date preference: %w", err)
+	}
+	return nil
+}
@@ -42,7 +42,7 @@ func (r *PostgresFiscalYearRepository) Create(ctx context.Context, fy *domain.Fi
 }
 
 // GetByID retrieves a fiscal year by ID
-func (r *PostgresFiscalYearRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.FiscalYear, error) {
+func (r *PostgresFiscalYearRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.FiscalYear, error) {
 	query := `
 		SELECT id, tenant_id, name, start_date, end_date, start_date_bs, end_date_bs,
 		       is_current, is_closed, closed_at, closed_by,
@@ -50,11 +50,11 @@ func (r *PostgresFiscalYearRepository) GetByID(ctx context.Context, id uuid.UUID
 		       last_invoice_num, last_purchase_num, last_voucher_num,
 		       created_at, updated_at
 		FROM fiscal_years
-		WHERE id = $1
+		WHERE id = $1 AND tenant_id = $2
 	`
 
 	var fy domain.FiscalYear
-	err := db.MainPool.QueryRow(ctx, query, id).Scan(
+	err := db.MainPool.QueryRow(ctx, query, id, tenantID).Scan(
 		&fy.ID, &fy.TenantID, &fy.Name, &fy.StartDate, &fy.EndDate, &fy.StartDateBS, &fy.EndDateBS,
 		&fy.IsCurrent, &fy.IsClosed, &fy.ClosedAt, &fy.ClosedBy,
 		&fy.InvoicePrefix, &fy.PurchasePrefix, &fy.VoucherPrefix,
@@ -149,24 +149,23 @@ func (r *PostgresFiscalYearRepository) GetByName(ctx context.Context, tenantID u
 	return &fy, nil
 }
 
-// Update updates a fiscal year
+// Update updates a fiscal year. The number counters are left alone: they only
+// move forward through the number ledger, so a stale copy cannot wind them back.
 func (r *PostgresFiscalYearRepository) Update(ctx context.Context, fy *domain.FiscalYear) error {
 	query := `
 		UPDATE fiscal_years
 		SET name = $1, start_date = $2, end_date = $3, start_date_bs = $4, end_date_bs = $5,
 		    is_current = $6, is_closed = $7, closed_at = $8, closed_by = $9,
 		    invoice_prefix = $10, purchase_prefix = $11, voucher_prefix = $12,
-		    last_invoice_num = $13, last_purchase_num = $14, last_voucher_num = $15,
-		    updated_at = $16
-		WHERE id = $17
+		    updated_at = $13
+		WHERE id = $14 AND tenant_id = $15
 	`
 
 	_, err := db.MainPool.Exec(ctx, query,
 		fy.Name, fy.StartDate, fy.EndDate, fy.StartDateBS, fy.EndDateBS,
 		fy.IsCurrent, fy.IsClosed, fy.ClosedAt, fy.ClosedBy,
 		fy.InvoicePrefix, fy.PurchasePrefix, fy.VoucherPrefix,
-		fy.LastInvoiceNum, fy.LastPurchaseNum, fy.LastVoucherNum,
-		fy.UpdatedAt, fy.ID,
+		fy.UpdatedAt, fy.ID, fy.TenantID,
 	)
 
 	if err != nil {
@@ -176,11 +175,11 @@ func (r *PostgresFiscalYearRepository) Update(ctx context.Context, fy *domain.Fi
 	return nil
 }
 
-// Delete deletes a fiscal year
-func (r *PostgresFiscalYearRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM fiscal_years WHERE id = $1`
+// Delete deletes a tenant's fiscal year
+func (r *PostgresFiscalYearRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	query := `DELETE FROM fiscal_years WHERE id = $1 AND tenant_id = $2`
 
-	_, err := db.MainPool.Exec(ctx, query, id)
+	_, err := db.MainPool.Exec(ctx, query, id, tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to delete fiscal year: %w", err)
 	}
@@ -215,63 +214,6 @@ func (r *PostgresFiscalYearRepository) SetAsCurrent(ctx context.Context, tenantI
 	})
 }
 
-// IncrementInvoiceNumber increments and returns the next invoice number
-func (r *PostgresFiscalYearRepository) IncrementInvoiceNumber(ctx context.Context, fiscalYearID uuid.UUID) (int, error) {
-	var nextNum int
-
-	query := `
-		UPDATE fiscal_years
-		SET last_invoice_num = last_invoice_num + 1, updated_at = NOW()
-		WHERE id = $1
-		RETURNING last_invoice_num
-	`
-
-	err := db.MainPool.QueryRow(ctx, query, fiscalYearID).Scan(&nextNum)
-	if err != nil {
-		return 0, fmt.Errorf("failed to increment invoice number: %w", err)
-	}
-
-	return nextNum, nil
-}
-
-// IncrementPurchaseNumber increments and returns the next purchase number
-func (r *PostgresFiscalYearRepository) IncrementPurchaseNumber(ctx context.Context, fiscalYearID uuid.UUID) (int, error) {
-	var nextNum int
-
-	query := `
-		UPDATE fiscal_years
-		SET last_purchase_num = last_purchase_num + 1, updated_at = NOW()
-		WHERE id = $1
-		RETURNING last_purchase_num
-	`
-
-	err := db.MainPool.QueryRow(ctx, query, fiscalYearID).Scan(&nextNum)
-	if err != nil {
-		return 0, fmt.Errorf("failed to increment purchase number: %w", err)
-	}
-
-	return nextNum, nil
-}
-
-// IncrementVoucherNumber increments and returns the next voucher number
-func (r *PostgresFiscalYearRepository) IncrementVoucherNumber(ctx context.Context, fiscalYearID uuid.UUID) (int, error) {
-	var nextNum int
-
-	query := `
-		UPDATE fiscal_years
-		SET last_voucher_num = last_voucher_num + 1, updated_at = NOW()
-		WHERE id = $1
-		RETURNING last_voucher_num
-	`
-
-	err := db.MainPool.QueryRow(ctx, query, fiscalYearID).Scan(&nextNum)
-	if err != nil {
-		return 0, fmt.Errorf("failed to increment voucher number: %w", err)
-	}
-
-	return nextNum, nil
-}
-
 // scanRows is a helper function to scan multiple rows
 func (r *PostgresFiscalYearRepository) scanRows(rows interface {
 	Next() bool
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/fiscal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresHolidayRepository implements HolidayRepository using PostgreSQL
+type PostgresHolidayRepository struct{}
+
+// NewPostgresHolidayRepository creates a new PostgreSQL holiday repository
+func NewPostgresHolidayRepository() *PostgresHolidayRepository {
+	return &PostgresHolidayRepository{}
+}
+
+const holidayColumns = `id, tenant_id, date, date_bs, name, source, created_at`
+
+func scanHoliday(row pgx.Row) (*domain.Holiday, error) {
+	var h domain.Holiday
+	err := row.Scan(&h.ID, &h.TenantID, &h.Date, &h.DateBS, &h.Name, &h.Source, &h.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// GetWorkWeek retrieves the tenant's weekly closing days, or nil if never set
+func (r *PostgresHolidayRepository) GetWorkWeek(ctx context.Context, tenantID uuid.UUID) (*domain.WorkWeek, error) {
+	week := &domain.WorkWeek{TenantID: tenantID}
+	var days []int16
+	err := db.MainPool.QueryRow(ctx, `
+		SELECT weekend_days, updated_at FROM work_weeks WHERE tenant_id = $1
+	`, tenantID).Scan(&days, &week.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work week: %w", err)
+	}
+
+	week.WeekendDays = make([]time.Weekday, len(days))
+	for i, day := range days {
+		week.WeekendDays[i] = time.Weekday(day)
+	}
+	return week, nil
+}
+
+// SaveWorkWeek creates or replaces the tenant's weekly closing days
+func (r *PostgresHolidayRepository) SaveWorkWeek(ctx context.Context, week *domain.WorkWeek) error {
+	days := make([]int16, len(week.WeekendDays))
+	for i, day := range week.WeekendDays {
+		days[i] = int16(day)
+	}
+
+	_, err := db.MainPool.Exec(ctx, `
+		INSERT INTO work_weeks (tenant_id, weekend_days, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE SET weekend_days = EXCLUDED.weekend_days, updated_at = EXCLUDED.updated_at
+	`, week.TenantID, days, week.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save work week: %w", err)
+	}
+	return nil
+}
+
+// Create creates a holiday
+func (r *PostgresHolidayRepository) Create(ctx context.Context, holiday *domain.Holiday) error {
+	_, err := db.MainPool.Exec(ctx, `
+		INSERT INTO holidays (`+holidayColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, holiday.ID, holiday.TenantID, holiday.Date, holiday.DateBS, holiday.Name, holiday.Source, holiday.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.ErrHolidayExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create holiday: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a holiday of the tenant
+func (r *PostgresHolidayRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Holiday, error) {
+	holiday, err := scanHoliday(db.MainPool.QueryRow(ctx, `
+		SELECT `+holidayColumns+` FROM holidays WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrHolidayNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holiday: %w", err)
+	}
+	return holiday, nil
+}
+
+// ListBetween retrieves the tenant's holidays from one AD date to another, inclusive
+func (r *PostgresHolidayRepository) ListBetween(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.Holiday, error) {
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT `+holidayColumns+` FROM holidays
+		WHERE tenant_id = $1 AND date BETWEEN $2 AND $3
+		ORDER BY date
+	`, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query holidays: %w", err)
+	}
+	defer rows.Close()
+
+	var holidays []*domain.Holiday
+	for rows.Next() {
+		holiday, err := scanHoliday(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan holiday: %w", err)
+		}
+		holidays = append(holidays, holiday)
+	}
+	return holidays, rows.Err()
+}
+
+// Delete deletes a holiday of the tenant
+func (r *PostgresHolidayRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := db.MainPool.Exec(ctx, `DELETE FROM holidays WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete holiday: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrHolidayNotFound
+	}
+	return nil
+}
+
+// IsSeeded reports whether the public holidays of a BS year were seeded for the tenant
+func (r *PostgresHolidayRepository) IsSeeded(ctx context.Context, tenantID uuid.UUID, bsYear int) (bool, error) {
+	var seeded bool
+	err := db.MainPool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM holiday_seeds WHERE tenant_id = $1 AND bs_year = $2)
+	`, tenantID, bsYear).Scan(&seeded)
+	if err != nil {
+		return false, fmt.Errorf("failed to check holiday seed: %w", err)
+	}
+	return seeded, nil
+}
+
+// Seed copies public holidays of a BS year once, in one transaction with the seed marker
+func (r *PostgresHolidayRepository) Seed(ctx context.Context, tenantID uuid.UUID, bsYear int, holidays []*domain.Holiday) (bool, error) {
+	seeded := false
+	err := db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO holiday_seeds (tenant_id, bs_year) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, tenantID, bsYear)
+		if err != nil {
+			return err
+		}
+		// Seeded concurrently by another request
+		if tag.RowsAffected() == 0 {
+			return nil
+		}
+
+		for _, h := range holidays {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO holidays (`+holidayColumns+`)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+				ON CONFLICT (tenant_id, date) DO NOTHING
+			`, h.ID, h.TenantID, h.Date, h.DateBS, h.Name, h.Source, h.CreatedAt); err != nil {
+				return err
+			}
+		}
+		seeded = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to seed holidays: %w", err)
+	}
+	return seeded, nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/fiscal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// counterColumns maps each document type to its fiscal_years counter and prefix
+var counterColumns = map[domain.DocumentType][2]string{
+	domain.DocumentInvoice:  {"last_invoice_num", "invoice_prefix"},
+	domain.DocumentPurchase: {"last_purchase_num", "purchase_prefix"},
+	domain.DocumentVoucher:  {"last_voucher_num", "voucher_prefix"},
+}
+
+// PostgresNumberLedgerRepository implements NumberLedgerRepository using PostgreSQL
+type PostgresNumberLedgerRepository struct{}
+
+// NewPostgresNumberLedgerRepository creates a new PostgreSQL number ledger repository
+func NewPostgresNumberLedgerRepository() *PostgresNumberLedgerRepository {
+	return &PostgresNumberLedgerRepository{}
+}
+
+const numberIssueColumns = `
+	id, tenant_id, fiscal_year_id, document_type, sequence, number, issued_at, issued_by,
+	document_id, attached_at, prev_hash, hash
+`
+
+// IssueNumber increments the counter and records the number. The counter row
+// stays locked until commit, so issues of a series are chained one at a time.
+func (r *PostgresNumberLedgerRepository) IssueNumber(ctx context.Context, fiscalYearID uuid.UUID, documentType domain.DocumentType, issuedBy *uuid.UUID) (*domain.NumberIssue, error) {
+	columns, ok := counterColumns[documentType]
+	if !ok {
+		return nil, domain.ErrInvalidDocumentType
+	}
+
+	var issue *domain.NumberIssue
+	err := db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var tenantID uuid.UUID
+		var sequence int
+		var prefix string
+		err := tx.QueryRow(ctx, fmt.Sprintf(`
+			UPDATE fiscal_years
+			SET %[1]s = %[1]s + 1, updated_at = NOW()
+			WHERE id = $1 AND is_closed = false
+			RETURNING tenant_id, %[1]s, %[2]s
+		`, columns[0], columns[1]), fiscalYearID).Scan(&tenantID, &sequence, &prefix)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("fiscal year not found or closed")
+			}
+			return fmt.Errorf("failed to increment %s number: %w", documentType, err)
+		}
+
+		var prevHash string
+		err = tx.QueryRow(ctx, `
+			SELECT hash FROM number_ledger
+			WHERE fiscal_year_id = $1 AND document_type = $2
+			ORDER BY sequence DESC
+			LIMIT 1
+		`, fiscalYearID, documentType).Scan(&prevHash)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to get previous ledger hash: %w", err)
+		}
+
+		issue = domain.NewNumberIssue(tenantID, fiscalYearID, documentType, sequence,
+			domain.FormatDocumentNumber(prefix, sequence), issuedBy, prevHash)
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO number_ledger (
+				id, tenant_id, fiscal_year_id, document_type, sequence, number, issued_at, issued_by, prev_hash, hash
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, issue.ID, issue.TenantID, issue.FiscalYearID, issue.DocumentType, issue.Sequence, issue.Number,
+			issue.IssuedAt, issue.IssuedBy, issue.PrevHash, issue.Hash)
+		if err != nil {
+			return fmt.Errorf("failed to record %s number: %w", documentType, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return issue, nil
+}
+
+// GetByNumber retrieves the latest issue of a number in a tenant's ledger
+func (r *PostgresNumberLedgerRepository) GetByNumber(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType, number string) (*domain.NumberIssue, error) {
+	query := `SELECT ` + numberIssueColumns + `
+		FROM number_ledger
+		WHERE tenant_id = $1 AND document_type = $2 AND number = $3
+		ORDER BY issued_at DESC
+		LIMIT 1
+	`
+
+	issue, err := scanNumberIssue(db.MainPool.QueryRow(ctx, query, tenantID, documentType, number))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNumberNotIssued
+		}
+		return nil, fmt.Errorf("failed to get document number: %w", err)
+	}
+
+	return issue, nil
+}
+
+// AttachDocument records the document a number was used on. It reports false
+// when the number already has a document.
+func (r *PostgresNumberLedgerRepository) AttachDocument(ctx context.Context, issueID, documentID uuid.UUID) (bool, error) {
+	tag, err := db.MainPool.Exec(ctx, `
+		UPDATE number_ledger
+		SET document_id = $2, attached_at = NOW()
+		WHERE id = $1 AND document_id IS NULL
+	`, issueID, documentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to attach document: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// StreamIssues passes a series' issues to fn in sequence order, without collecting them
+func (r *PostgresNumberLedgerRepository) StreamIssues(ctx context.Context, fiscalYearID uuid.UUID, documentType domain.DocumentType, fn func(*domain.NumberIssue) error) error {
+	query := `SELECT ` + numberIssueColumns + `
+		FROM number_ledger
+		WHERE fiscal_year_id = $1 AND document_type = $2
+		ORDER BY sequence
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, fiscalYearID, documentType)
+	if err != nil {
+		return fmt.Errorf("failed to query number ledger: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		issue, err := scanNumberIssue(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan number issue: %w", err)
+		}
+		if err := fn(issue); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return nil
+}
+
+// scanNumberIssue scans a number_ledger row selected with numberIssueColumns
+func scanNumberIssue(row pgx.Row) (*domain.NumberIssue, error) {
+	var issue domain.NumberIssue
+	err := row.Scan(
+		&issue.ID, &issue.TenantID, &issue.FiscalYearID, &issue.DocumentType, &issue.Sequence, &issue.Number,
+		&issue.IssuedAt, &issue.IssuedBy, &issue.DocumentID, &issue.AttachedAt, &issue.PrevHash, &issue.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
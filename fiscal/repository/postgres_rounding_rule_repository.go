@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/fiscal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresRoundingRuleRepository implements RoundingRuleRepository using PostgreSQL
+type PostgresRoundingRuleRepository struct{}
+
+// NewPostgresRoundingRuleRepository creates a new PostgreSQL rounding rule repository
+func NewPostgresRoundingRuleRepository() *PostgresRoundingRuleRepository {
+	return &PostgresRoundingRuleRepository{}
+}
+
+const roundingRuleColumns = `tenant_id, document_type, increment, mode, updated_at`
+
+func scanRoundingRule(row pgx.Row) (*domain.RoundingRule, error) {
+	var r domain.RoundingRule
+	if err := row.Scan(&r.TenantID, &r.DocumentType, &r.Increment, &r.Mode, &r.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// List retrieves the rules the tenant has set
+func (r *PostgresRoundingRuleRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*domain.RoundingRule, error) {
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT `+roundingRuleColumns+` FROM rounding_rules WHERE tenant_id = $1 ORDER BY document_type
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rounding rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*domain.RoundingRule
+	for rows.Next() {
+		rule, err := scanRoundingRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rounding rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// Get retrieves the tenant's rule for a document type, or nil if never set
+func (r *PostgresRoundingRuleRepository) Get(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType) (*domain.RoundingRule, error) {
+	rule, err := scanRoundingRule(db.MainPool.QueryRow(ctx, `
+		SELECT `+roundingRuleColumns+` FROM rounding_rules WHERE tenant_id = $1 AND document_type = $2
+	`, tenantID, documentType))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rounding rule: %w", err)
+	}
+	return rule, nil
+}
+
+// Save creates or replaces the tenant's rule for a document type
+func (r *PostgresRoundingRuleRepository) Save(ctx context.Context, rule *domain.RoundingRule) error {
+	_, err := db.MainPool.Exec(ctx, `
+		INSERT INTO rounding_rules (`+roundingRuleColumns+`)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, document_type) DO UPDATE SET
+			increment = EXCLUDED.increment, mode = EXCLUDED.mode, updated_at = EXCLUDED.updated_at
+	`, rule.TenantID, rule.DocumentType, rule.Increment, rule.Mode, rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save rounding rule: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the tenant's rule for a document type, if any
+func (r *PostgresRoundingRuleRepository) Delete(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType) error {
+	_, err := db.MainPool.Exec(ctx, `
+		DELETE FROM rounding_rules WHERE tenant_id = $1 AND document_type = $2
+	`, tenantID, documentType)
+	if err != nil {
+		return fmt.Errorf("failed to delete rounding rule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/fiscal/domain"
+	"github.com/google/uuid"
+)
+
+// RoundingRuleRepository defines the interface for tenants' document rounding rules
+type RoundingRuleRepository interface {
+	// List retrieves the rules the tenant has set
+	List(ctx context.Context, tenantID uuid.UUID) ([]*domain.RoundingRule, error)
+
+	// Get retrieves the tenant's rule for a document type, or nil if never set
+	Get(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType) (*domain.RoundingRule, error)
+
+	// Save creates or replaces the tenant's rule for a document type
+	Save(ctx context.Context, rule *domain.RoundingRule) error
+
+	// Delete removes the tenant's rule for a document type, if any
+	Delete(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType) error
+}
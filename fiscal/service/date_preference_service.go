@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/core/cache"
+	"github.com/aceextension/fiscal/domain"
+	"github.com/aceextension/fiscal/repository"
+	"github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+)
+
+// datePreferenceTTL bounds how long a tenant's preference is reused; every printed
+// document and report looks it up
+const datePreferenceTTL = 10 * time.Minute
+
+// DatePreferenceService defines the interface for which calendar tenants print first
+type DatePreferenceService interface {
+	// Preference returns the tenant's preference; AD first unless set
+	Preference(ctx context.Context, tenantID uuid.UUID) (*domain.DatePreference, error)
+
+	// SetPreference replaces the tenant's preference. It applies to documents and
+	// reports printed from then on.
+	SetPreference(ctx context.Context, tenantID uuid.UUID, display utils.DateDisplay) (*domain.DatePreference, error)
+}
+
+// datePreferenceService implements DatePreferenceService
+type datePreferenceService struct {
+	repo  repository.DatePreferenceRepository
+	prefs *cache.TTLCache[uuid.UUID, *domain.DatePreference]
+}
+
+// NewDatePreferenceService creates a new date preference service
+func NewDatePreferenceService(repo repository.DatePreferenceRepository) DatePreferenceService {
+	return &datePreferenceService{
+		repo:  repo,
+		prefs: cache.New[uuid.UUID, *domain.DatePreference](datePreferenceTTL, 10000),
+	}
+}
+
+// Preference returns the tenant's preference
+func (s *datePreferenceService) Preference(ctx context.Context, tenantID uuid.UUID) (*domain.DatePreference, error) {
+	if pref, ok := s.prefs.Get(tenantID); ok {
+		return pref, nil
+	}
+
+	pref, err := s.repo.Get(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if pref == nil {
+		pref = domain.DefaultDatePreference(tenantID)
+	}
+	s.prefs.Set(tenantID, pref)
+	return pref, nil
+}
+
+// SetPreference replaces the tenant's preference
+func (s *datePreferenceService) SetPreference(ctx context.Context, tenantID uuid.UUID, display utils.DateDisplay) (*domain.DatePreference, error) {
+	pref, err := domain.NewDatePreference(tenantID, display)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, pref); err != nil {
+		return nil, err
+	}
+	s.prefs.Set(tenantID, pref)
+	return pref, nil
+}
@@ -3,8 +3,12 @@ package service
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/db"
 	"github.com/aceextension/fiscal/domain"
 	"github.com/aceextension/fiscal/repository"
 	"github.com/aceextension/fiscal/utils"
@@ -19,8 +23,8 @@ type FiscalYearService interface {
 	// CreateFromNepaliDate creates a fiscal year from Nepali date
 	CreateFromNepaliDate(ctx context.Context, tenantID uuid.UUID, fiscalYearName string) (*domain.FiscalYear, error)
 
-	// GetByID retrieves a fiscal year by ID
-	GetByID(ctx context.Context, id uuid.UUID) (*domain.FiscalYear, error)
+	// GetByID retrieves a tenant's fiscal year by ID
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.FiscalYear, error)
 
 	// GetByTenantID retrieves all fiscal years for a tenant
 	GetByTenantID(ctx context.Context, tenantID uuid.UUID) ([]*domain.FiscalYear, error)
@@ -31,34 +35,46 @@ type FiscalYearService interface {
 	// SetAsCurrent sets a fiscal year as current
 	SetAsCurrent(ctx context.Context, tenantID, fiscalYearID uuid.UUID) error
 
-	// Close closes a fiscal year
-	Close(ctx context.Context, fiscalYearID, closedBy uuid.UUID) error
+	// Close closes a fiscal year after running the registered close hooks
+	Close(ctx context.Context, tenantID, fiscalYearID, closedBy uuid.UUID) error
+
+	// RegisterCloseHook adds work done when a year is closed, such as closing the ledger
+	RegisterCloseHook(hook CloseHook)
 
 	// Reopen reopens a closed fiscal year
-	Reopen(ctx context.Context, fiscalYearID uuid.UUID) error
+	Reopen(ctx context.Context, tenantID, fiscalYearID uuid.UUID) error
 
 	// Delete deletes a fiscal year
-	Delete(ctx context.Context, fiscalYearID uuid.UUID) error
+	Delete(ctx context.Context, tenantID, fiscalYearID uuid.UUID) error
 
 	// GenerateInvoiceNumber generates the next invoice number
-	GenerateInvoiceNumber(ctx context.Context, fiscalYearID uuid.UUID) (string, error)
+	GenerateInvoiceNumber(ctx context.Context, tenantID, fiscalYearID uuid.UUID) (string, error)
 
 	// GeneratePurchaseNumber generates the next purchase number
-	GeneratePurchaseNumber(ctx context.Context, fiscalYearID uuid.UUID) (string, error)
+	GeneratePurchaseNumber(ctx context.Context, tenantID, fiscalYearID uuid.UUID) (string, error)
 
 	// GenerateVoucherNumber generates the next voucher number
-	GenerateVoucherNumber(ctx context.Context, fiscalYearID uuid.UUID) (string, error)
+	GenerateVoucherNumber(ctx context.Context, tenantID, fiscalYearID uuid.UUID) (string, error)
 }
 
+// CloseHook runs while a fiscal year is being closed, before it is marked closed;
+// an error keeps the year open. Hooks may run again if a year is reopened and closed.
+type CloseHook func(ctx context.Context, fy *domain.FiscalYear, closedBy uuid.UUID) error
+
 // fiscalYearService implements FiscalYearService
 type fiscalYearService struct {
-	repo repository.FiscalYearRepository
+	repo   repository.FiscalYearRepository
+	ledger repository.NumberLedgerRepository
+
+	hooksMu    sync.RWMutex
+	closeHooks []CloseHook
 }
 
 // NewFiscalYearService creates a new fiscal year service
-func NewFiscalYearService(repo repository.FiscalYearRepository) FiscalYearService {
+func NewFiscalYearService(repo repository.FiscalYearRepository, ledger repository.NumberLedgerRepository) FiscalYearService {
 	return &fiscalYearService{
-		repo: repo,
+		repo:   repo,
+		ledger: ledger,
 	}
 }
 
@@ -95,9 +111,9 @@ func (s *fiscalYearService) CreateFromNepaliDate(ctx context.Context, tenantID u
 	return fy, nil
 }
 
-// GetByID retrieves a fiscal year by ID
-func (s *fiscalYearService) GetByID(ctx context.Context, id uuid.UUID) (*domain.FiscalYear, error) {
-	return s.repo.GetByID(ctx, id)
+// GetByID retrieves a tenant's fiscal year by ID
+func (s *fiscalYearService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.FiscalYear, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
 }
 
 // GetByTenantID retrieves all fiscal years for a tenant
@@ -116,9 +132,9 @@ func (s *fiscalYearService) SetAsCurrent(ctx context.Context, tenantID, fiscalYe
 }
 
 // Close closes a fiscal year
-func (s *fiscalYearService) Close(ctx context.Context, fiscalYearID, closedBy uuid.UUID) error {
+func (s *fiscalYearService) Close(ctx context.Context, tenantID, fiscalYearID, closedBy uuid.UUID) error {
 	// Get fiscal year
-	fy, err := s.repo.GetByID(ctx, fiscalYearID)
+	fy, err := s.repo.GetByID(ctx, tenantID, fiscalYearID)
 	if err != nil {
 		return fmt.Errorf("failed to get fiscal year: %w", err)
 	}
@@ -128,6 +144,16 @@ func (s *fiscalYearService) Close(ctx context.Context, fiscalYearID, closedBy uu
 		return fmt.Errorf("fiscal year is already closed")
 	}
 
+	// Other modules finish the year first, while it is still open
+	s.hooksMu.RLock()
+	hooks := s.closeHooks
+	s.hooksMu.RUnlock()
+	for _, hook := range hooks {
+		if err := hook(ctx, fy, closedBy); err != nil {
+			return fmt.Errorf("failed to close fiscal year: %w", err)
+		}
+	}
+
 	// Close it
 	fy.Close(closedBy)
 
@@ -136,13 +162,30 @@ func (s *fiscalYearService) Close(ctx context.Context, fiscalYearID, closedBy uu
 		return fmt.Errorf("failed to close fiscal year: %w", err)
 	}
 
+	// Closing a year is critical and reaches the owners' daily digest
+	if audit.Service != nil {
+		entityIDStr := fy.ID.String()
+		audit.Service.Log(ctx, "CLOSE_FISCAL_YEAR", "FiscalYear", &entityIDStr, map[string]interface{}{
+			"name":       fy.Name,
+			"start_date": fy.StartDate.Format("2006-01-02"),
+			"end_date":   fy.EndDate.Format("2006-01-02"),
+		}, &auditDomain.AuditContext{TenantID: &fy.TenantID, UserID: &closedBy})
+	}
+
 	return nil
 }
 
+// RegisterCloseHook adds a hook run by Close
+func (s *fiscalYearService) RegisterCloseHook(hook CloseHook) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.closeHooks = append(s.closeHooks, hook)
+}
+
 // Reopen reopens a closed fiscal year
-func (s *fiscalYearService) Reopen(ctx context.Context, fiscalYearID uuid.UUID) error {
+func (s *fiscalYearService) Reopen(ctx context.Context, tenantID, fiscalYearID uuid.UUID) error {
 	// Get fiscal year
-	fy, err := s.repo.GetByID(ctx, fiscalYearID)
+	fy, err := s.repo.GetByID(ctx, tenantID, fiscalYearID)
 	if err != nil {
 		return fmt.Errorf("failed to get fiscal year: %w", err)
 	}
@@ -164,9 +207,9 @@ func (s *fiscalYearService) Reopen(ctx context.Context, fiscalYearID uuid.UUID)
 }
 
 // Delete deletes a fiscal year
-func (s *fiscalYearService) Delete(ctx context.Context, fiscalYearID uuid.UUID) error {
+func (s *fiscalYearService) Delete(ctx context.Context, tenantID, fiscalYearID uuid.UUID) error {
 	// Get fiscal year
-	fy, err := s.repo.GetByID(ctx, fiscalYearID)
+	fy, err := s.repo.GetByID(ctx, tenantID, fiscalYearID)
 	if err != nil {
 		return fmt.Errorf("failed to get fiscal year: %w", err)
 	}
@@ -181,79 +224,57 @@ func (s *fiscalYearService) Delete(ctx context.Context, fiscalYearID uuid.UUID)
 		return fmt.Errorf("cannot delete closed fiscal year")
 	}
 
+	// Issued numbers stay on record for inspection
+	if fy.LastInvoiceNum > 0 || fy.LastPurchaseNum > 0 || fy.LastVoucherNum > 0 {
+		return fmt.Errorf("cannot delete fiscal year with issued document numbers")
+	}
+
 	// Delete
-	if err := s.repo.Delete(ctx, fiscalYearID); err != nil {
+	if err := s.repo.Delete(ctx, tenantID, fiscalYearID); err != nil {
 		return fmt.Errorf("failed to delete fiscal year: %w", err)
 	}
 
 	return nil
 }
 
-// GenerateInvoiceNumber generates the next invoice number
-func (s *fiscalYearService) GenerateInvoiceNumber(ctx context.Context, fiscalYearID uuid.UUID) (string, error) {
-	// Get fiscal year
-	fy, err := s.repo.GetByID(ctx, fiscalYearID)
-	if err != nil {
-		return "", fmt.Errorf("failed to get fiscal year: %w", err)
-	}
-
-	// Check if closed
-	if fy.IsClosed {
-		return "", fmt.Errorf("cannot generate invoice number for closed fiscal year")
-	}
+// GenerateInvoiceNumber generates the next invoice number (e.g., "INV-8283-0001")
+func (s *fiscalYearService) GenerateInvoiceNumber(ctx context.Context, tenantID, fiscalYearID uuid.UUID) (string, error) {
+	return s.issueNumber(ctx, tenantID, fiscalYearID, domain.DocumentInvoice)
+}
 
-	// Increment number
-	nextNum, err := s.repo.IncrementInvoiceNumber(ctx, fiscalYearID)
-	if err != nil {
-		return "", fmt.Errorf("failed to increment invoice number: %w", err)
-	}
+// GeneratePurchaseNumber generates the next purchase number (e.g., "PUR-8283-0001")
+func (s *fiscalYearService) GeneratePurchaseNumber(ctx context.Context, tenantID, fiscalYearID uuid.UUID) (string, error) {
+	return s.issueNumber(ctx, tenantID, fiscalYearID, domain.DocumentPurchase)
+}
 
-	// Generate full number (e.g., "INV-8283-0001")
-	return fmt.Sprintf("%s%04d", fy.InvoicePrefix, nextNum), nil
+// GenerateVoucherNumber generates the next voucher number (e.g., "JV-8283-0001")
+func (s *fiscalYearService) GenerateVoucherNumber(ctx context.Context, tenantID, fiscalYearID uuid.UUID) (string, error) {
+	return s.issueNumber(ctx, tenantID, fiscalYearID, domain.DocumentVoucher)
 }
 
-// GeneratePurchaseNumber generates the next purchase number
-func (s *fiscalYearService) GeneratePurchaseNumber(ctx context.Context, fiscalYearID uuid.UUID) (string, error) {
+// issueNumber takes the next number of a series and records it in the number
+// ledger with the user issuing it
+func (s *fiscalYearService) issueNumber(ctx context.Context, tenantID, fiscalYearID uuid.UUID, documentType domain.DocumentType) (string, error) {
 	// Get fiscal year
-	fy, err := s.repo.GetByID(ctx, fiscalYearID)
+	fy, err := s.repo.GetByID(ctx, tenantID, fiscalYearID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get fiscal year: %w", err)
 	}
 
 	// Check if closed
 	if fy.IsClosed {
-		return "", fmt.Errorf("cannot generate purchase number for closed fiscal year")
+		return "", fmt.Errorf("cannot generate %s number for closed fiscal year", documentType)
 	}
 
-	// Increment number
-	nextNum, err := s.repo.IncrementPurchaseNumber(ctx, fiscalYearID)
-	if err != nil {
-		return "", fmt.Errorf("failed to increment purchase number: %w", err)
-	}
-
-	// Generate full number (e.g., "PUR-8283-0001")
-	return fmt.Sprintf("%s%04d", fy.PurchasePrefix, nextNum), nil
-}
-
-// GenerateVoucherNumber generates the next voucher number
-func (s *fiscalYearService) GenerateVoucherNumber(ctx context.Context, fiscalYearID uuid.UUID) (string, error) {
-	// Get fiscal year
-	fy, err := s.repo.GetByID(ctx, fiscalYearID)
-	if err != nil {
-		return "", fmt.Errorf("failed to get fiscal year: %w", err)
-	}
-
-	// Check if closed
-	if fy.IsClosed {
-		return "", fmt.Errorf("cannot generate voucher number for closed fiscal year")
+	var issuedBy *uuid.UUID
+	if userID, ok := db.GetUserID(ctx); ok {
+		issuedBy = &userID
 	}
 
-	// Increment number
-	nextNum, err := s.repo.IncrementVoucherNumber(ctx, fiscalYearID)
+	issue, err := s.ledger.IssueNumber(ctx, fiscalYearID, documentType, issuedBy)
 	if err != nil {
-		return "", fmt.Errorf("failed to increment voucher number: %w", err)
+		return "", fmt.Errorf("failed to issue %s number: %w", documentType, err)
 	}
 
-	// Generate full number (e.g., "JV-8283-0001")
-	return fmt.Sprintf("%s%04d", fy.VoucherPrefix, nextNum), nil
+	return issue.Number, nil
 }
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/fiscal/domain"
+	"github.com/aceextension/fiscal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// NumberingService defines the interface for the document number ledger
+type NumberingService interface {
+	// RecordDocument attaches the saved document to the number it was given.
+	// Recording the same document again is a no-op.
+	RecordDocument(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType, number string, documentID uuid.UUID) error
+
+	// Report checks a fiscal year's series for gaps, duplicates and edited rows
+	Report(ctx context.Context, tenantID, fiscalYearID uuid.UUID, documentType domain.DocumentType) (*domain.NumberingReport, error)
+
+	// Export passes a fiscal year's issued numbers to fn in sequence order
+	Export(ctx context.Context, tenantID, fiscalYearID uuid.UUID, documentType domain.DocumentType, fn func(*domain.NumberIssue) error) error
+}
+
+// numberingService implements NumberingService
+type numberingService struct {
+	repo   repository.NumberLedgerRepository
+	fyRepo repository.FiscalYearRepository
+}
+
+// NewNumberingService creates a new numbering service
+func NewNumberingService(repo repository.NumberLedgerRepository, fyRepo repository.FiscalYearRepository) NumberingService {
+	return &numberingService{
+		repo:   repo,
+		fyRepo: fyRepo,
+	}
+}
+
+// RecordDocument attaches the saved document to the number it was given
+func (s *numberingService) RecordDocument(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType, number string, documentID uuid.UUID) error {
+	if !documentType.Valid() {
+		return domain.ErrInvalidDocumentType
+	}
+
+	issue, err := s.repo.GetByNumber(ctx, tenantID, documentType, number)
+	if err != nil {
+		return err
+	}
+
+	if issue.DocumentID != nil {
+		if *issue.DocumentID == documentID {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", domain.ErrNumberAlreadyUsed, number)
+	}
+
+	attached, err := s.repo.AttachDocument(ctx, issue.ID, documentID)
+	if err != nil {
+		return err
+	}
+	if !attached {
+		// Attached concurrently; fine only if it was the same document
+		issue, err = s.repo.GetByNumber(ctx, tenantID, documentType, number)
+		if err != nil {
+			return err
+		}
+		if issue.DocumentID == nil || *issue.DocumentID != documentID {
+			return fmt.Errorf("%w: %s", domain.ErrNumberAlreadyUsed, number)
+		}
+	}
+
+	return nil
+}
+
+// Report walks the series in sequence order, re-hashing every row
+func (s *numberingService) Report(ctx context.Context, tenantID, fiscalYearID uuid.UUID, documentType domain.DocumentType) (*domain.NumberingReport, error) {
+	fy, err := s.fiscalYear(ctx, tenantID, fiscalYearID, documentType)
+	if err != nil {
+		return nil, err
+	}
+
+	audit := domain.NewNumberingAudit(fy, documentType)
+	err = s.repo.StreamIssues(ctx, fiscalYearID, documentType, func(issue *domain.NumberIssue) error {
+		audit.Add(issue)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return audit.Report(), nil
+}
+
+// Export passes a fiscal year's issued numbers to fn in sequence order
+func (s *numberingService) Export(ctx context.Context, tenantID, fiscalYearID uuid.UUID, documentType domain.DocumentType, fn func(*domain.NumberIssue) error) error {
+	if _, err := s.fiscalYear(ctx, tenantID, fiscalYearID, documentType); err != nil {
+		return err
+	}
+
+	return s.repo.StreamIssues(ctx, fiscalYearID, documentType, fn)
+}
+
+// fiscalYear loads the tenant's fiscal year after checking the document type
+func (s *numberingService) fiscalYear(ctx context.Context, tenantID, fiscalYearID uuid.UUID, documentType domain.DocumentType) (*domain.FiscalYear, error) {
+	if !documentType.Valid() {
+		return nil, domain.ErrInvalidDocumentType
+	}
+
+	fy, err := s.fyRepo.GetByID(ctx, tenantID, fiscalYearID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrFiscalYearNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fiscal year: %w", err)
+	}
+
+	return fy, nil
+}
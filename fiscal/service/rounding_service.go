@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/fiscal/domain"
+	"github.com/aceextension/fiscal/repository"
+	"github.com/google/uuid"
+)
+
+// RoundingService defines the interface for tenants' document rounding rules
+type RoundingService interface {
+	// Rules returns the tenant's rule for every rounded document type, defaults included
+	Rules(ctx context.Context, tenantID uuid.UUID) ([]*domain.RoundingRule, error)
+
+	// Rule returns the tenant's rule for a document type; to the paisa unless set
+	Rule(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType) (*domain.RoundingRule, error)
+
+	// SetRule replaces the tenant's rule for a document type. Saved documents keep
+	// the round-off they were issued with.
+	SetRule(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType, increment float64, mode domain.RoundingMode) (*domain.RoundingRule, error)
+
+	// ResetRule goes back to the default rule for a document type
+	ResetRule(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType) (*domain.RoundingRule, error)
+}
+
+// roundingService implements RoundingService
+type roundingService struct {
+	repo repository.RoundingRuleRepository
+}
+
+// NewRoundingService creates a new rounding service
+func NewRoundingService(repo repository.RoundingRuleRepository) RoundingService {
+	return &roundingService{repo: repo}
+}
+
+// Rules returns the tenant's rule for every rounded document type
+func (s *roundingService) Rules(ctx context.Context, tenantID uuid.UUID) ([]*domain.RoundingRule, error) {
+	saved, err := s.repo.List(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*domain.RoundingRule, 0, len(domain.RoundedDocumentTypes))
+	for _, documentType := range domain.RoundedDocumentTypes {
+		rule := domain.DefaultRoundingRule(tenantID, documentType)
+		for _, r := range saved {
+			if r.DocumentType == documentType {
+				rule = r
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Rule returns the tenant's rule for a document type
+func (s *roundingService) Rule(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType) (*domain.RoundingRule, error) {
+	if !documentType.Rounded() {
+		return nil, fmt.Errorf("%w: %q totals are not rounded", domain.ErrInvalidRoundingRule, documentType)
+	}
+	rule, err := s.repo.Get(ctx, tenantID, documentType)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		rule = domain.DefaultRoundingRule(tenantID, documentType)
+	}
+	return rule, nil
+}
+
+// SetRule replaces the tenant's rule for a document type
+func (s *roundingService) SetRule(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType, increment float64, mode domain.RoundingMode) (*domain.RoundingRule, error) {
+	rule, err := domain.NewRoundingRule(tenantID, documentType, increment, mode)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// ResetRule goes back to the default rule for a document type
+func (s *roundingService) ResetRule(ctx context.Context, tenantID uuid.UUID, documentType domain.DocumentType) (*domain.RoundingRule, error) {
+	if !documentType.Rounded() {
+		return nil, fmt.Errorf("%w: %q totals are not rounded", domain.ErrInvalidRoundingRule, documentType)
+	}
+	if err := s.repo.Delete(ctx, tenantID, documentType); err != nil {
+		return nil, err
+	}
+	return domain.DefaultRoundingRule(tenantID, documentType), nil
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/core/cache"
+	"github.com/aceextension/fiscal/domain"
+	"github.com/aceextension/fiscal/repository"
+	"github.com/aceextension/fiscal/utils"
+	"github.com/google/uuid"
+)
+
+// workingCalendarTTL bounds how long a tenant's calendar is reused before holidays are re-read
+const workingCalendarTTL = 10 * time.Minute
+
+// WorkCalendarService defines the interface for tenants' working days and holidays
+type WorkCalendarService interface {
+	// GetWorkWeek returns the tenant's weekly closing days; Saturday unless set
+	GetWorkWeek(ctx context.Context, tenantID uuid.UUID) (*domain.WorkWeek, error)
+
+	// SetWorkWeek replaces the tenant's weekly closing days
+	SetWorkWeek(ctx context.Context, tenantID uuid.UUID, weekendDays []time.Weekday) (*domain.WorkWeek, error)
+
+	// ListHolidays returns the tenant's holidays in a BS year, seeding the public holidays on first use
+	ListHolidays(ctx context.Context, tenantID uuid.UUID, bsYear int) ([]*domain.Holiday, error)
+
+	// AddHoliday closes the tenant on an AD date
+	AddHoliday(ctx context.Context, tenantID uuid.UUID, date time.Time, name string) (*domain.Holiday, error)
+
+	// DeleteHoliday reopens the tenant on a holiday, public or its own
+	DeleteHoliday(ctx context.Context, tenantID, id uuid.UUID) error
+
+	// RestorePublicHolidays adds back public holidays of a BS year the tenant
+	// deleted, or festivals published after the year was seeded; it returns how many were added
+	RestorePublicHolidays(ctx context.Context, tenantID uuid.UUID, bsYear int) (int, error)
+
+	// Calendar returns the tenant's working calendar covering the BS years
+	// around today, for due-date calculations
+	Calendar(ctx context.Context, tenantID uuid.UUID) (*utils.WorkingCalendar, error)
+}
+
+// workCalendarService implements WorkCalendarService
+type workCalendarService struct {
+	repo      repository.HolidayRepository
+	calendars *cache.TTLCache[uuid.UUID, *utils.WorkingCalendar]
+}
+
+// NewWorkCalendarService creates a new work calendar service
+func NewWorkCalendarService(repo repository.HolidayRepository) WorkCalendarService {
+	return &workCalendarService{
+		repo:      repo,
+		calendars: cache.New[uuid.UUID, *utils.WorkingCalendar](workingCalendarTTL, 10000),
+	}
+}
+
+// GetWorkWeek returns the tenant's weekly closing days
+func (s *workCalendarService) GetWorkWeek(ctx context.Context, tenantID uuid.UUID) (*domain.WorkWeek, error) {
+	week, err := s.repo.GetWorkWeek(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if week == nil {
+		week = &domain.WorkWeek{TenantID: tenantID, WeekendDays: utils.DefaultWeekend}
+	}
+	return week, nil
+}
+
+// SetWorkWeek replaces the tenant's weekly closing days
+func (s *workCalendarService) SetWorkWeek(ctx context.Context, tenantID uuid.UUID, weekendDays []time.Weekday) (*domain.WorkWeek, error) {
+	week := &domain.WorkWeek{TenantID: tenantID, WeekendDays: weekendDays, UpdatedAt: time.Now()}
+	if err := week.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveWorkWeek(ctx, week); err != nil {
+		return nil, err
+	}
+
+	s.calendars.Delete(tenantID)
+	return week, nil
+}
+
+// ListHolidays returns the tenant's holidays in a BS year
+func (s *workCalendarService) ListHolidays(ctx context.Context, tenantID uuid.UUID, bsYear int) ([]*domain.Holiday, error) {
+	calendarYear, err := utils.GetCalendarYear(bsYear)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.seed(ctx, tenantID, bsYear); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListBetween(ctx, tenantID, calendarYear.StartAD, calendarYear.EndAD)
+}
+
+// seed copies the public holidays of a BS year to the tenant the first time the year is used
+func (s *workCalendarService) seed(ctx context.Context, tenantID uuid.UUID, bsYear int) error {
+	seeded, err := s.repo.IsSeeded(ctx, tenantID, bsYear)
+	if err != nil || seeded {
+		return err
+	}
+
+	holidays, err := publicHolidays(tenantID, bsYear)
+	if err != nil {
+		return err
+	}
+	_, err = s.repo.Seed(ctx, tenantID, bsYear, holidays)
+	return err
+}
+
+// publicHolidays builds the tenant's copies of a BS year's public holidays
+func publicHolidays(tenantID uuid.UUID, bsYear int) ([]*domain.Holiday, error) {
+	public, err := utils.PublicHolidays(bsYear)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays := make([]*domain.Holiday, len(public))
+	for i, h := range public {
+		holidays[i] = domain.NewHoliday(tenantID, h.DateAD, h.DateBS.String(), h.Name, domain.HolidayPublic)
+	}
+	return holidays, nil
+}
+
+// AddHoliday closes the tenant on an AD date
+func (s *workCalendarService) AddHoliday(ctx context.Context, tenantID uuid.UUID, date time.Time, name string) (*domain.Holiday, error) {
+	holiday := domain.NewHoliday(tenantID, date, "", name, domain.HolidayCustom)
+	if err := holiday.Validate(); err != nil {
+		return nil, err
+	}
+	holiday.DateBS = utils.ADToBS(holiday.Date).String()
+
+	if err := s.repo.Create(ctx, holiday); err != nil {
+		return nil, err
+	}
+
+	s.calendars.Delete(tenantID)
+	return holiday, nil
+}
+
+// DeleteHoliday reopens the tenant on a holiday
+func (s *workCalendarService) DeleteHoliday(ctx context.Context, tenantID, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, tenantID, id); err != nil {
+		return err
+	}
+
+	s.calendars.Delete(tenantID)
+	return nil
+}
+
+// RestorePublicHolidays adds the public holidays of a BS year the tenant does not have
+func (s *workCalendarService) RestorePublicHolidays(ctx context.Context, tenantID uuid.UUID, bsYear int) (int, error) {
+	if err := s.seed(ctx, tenantID, bsYear); err != nil {
+		return 0, err
+	}
+	holidays, err := publicHolidays(tenantID, bsYear)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, holiday := range holidays {
+		err := s.repo.Create(ctx, holiday)
+		if errors.Is(err, domain.ErrHolidayExists) {
+			continue
+		}
+		if err != nil {
+			return added, err
+		}
+		added++
+	}
+
+	if added > 0 {
+		s.calendars.Delete(tenantID)
+	}
+	return added, nil
+}
+
+// Calendar returns the tenant's working calendar for the previous, current and next BS years
+func (s *workCalendarService) Calendar(ctx context.Context, tenantID uuid.UUID) (*utils.WorkingCalendar, error) {
+	if cal, ok := s.calendars.Get(tenantID); ok {
+		return cal, nil
+	}
+
+	week, err := s.GetWorkWeek(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays := make(map[time.Time]string)
+	year := utils.GetCurrentNepaliDate().Year
+	for y := year - 1; y <= year+1; y++ {
+		list, err := s.ListHolidays(ctx, tenantID, y)
+		if errors.Is(err, utils.ErrYearNotInCalendar) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load holidays of %d: %w", y, err)
+		}
+		for _, holiday := range list {
+			holidays[holiday.Date] = holiday.Name
+		}
+	}
+
+	cal := utils.NewWorkingCalendar(week.WeekendDays, holidays)
+	s.calendars.Set(tenantID, cal)
+	return cal, nil
+}
@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// ErrYearNotInCalendar is returned for a BS year outside the loaded calendar data
+var ErrYearNotInCalendar = errors.New("year is not in the calendar data")
+
+// calendar is an immutable set of BS month tables with everything needed for
+// conversion precomputed: day numbers count days from Baishakh 1 of firstYear
+// (startAD), so both directions are a couple of array lookups.
+type calendar struct {
+	version    string // Digest of the month tables; changes only when the data does
+	firstYear  int
+	lastYear   int
+	startAD    time.Time
+	monthDays  [][12]int     // [year-firstYear][month-1]
+	yearStart  []int         // Day number of Baishakh 1 of each year
+	monthStart [][13]int     // Day offset of each month within its year; [12] is the year length
+	days       []NepaliDate  // Day number -> BS date
+	fiscal     []FiscalBound // Fiscal year starting in Shrawan of each year, when it ends inside the data
+}
+
+// FiscalBound is the start and end of a fiscal year (Shrawan 1 to the last day of Ashad)
+type FiscalBound struct {
+	Name    string     `json:"name"`
+	StartBS NepaliDate `json:"startBs"`
+	EndBS   NepaliDate `json:"endBs"`
+	StartAD time.Time  `json:"startAd"`
+	EndAD   time.Time  `json:"endAd"`
+}
+
+// current is the calendar in use; swapped atomically when new data is loaded
+var current atomic.Pointer[calendar]
+
+func init() {
+	cal, err := buildCalendar(nepaliMonthDays)
+	if err != nil {
+		panic("fiscal: built-in calendar data is invalid: " + err.Error())
+	}
+	current.Store(cal)
+}
+
+// buildCalendar validates the month tables and precomputes the lookups.
+// Years must be contiguous and include the reference year.
+func buildCalendar(monthDays map[int][]int) (*calendar, error) {
+	if len(monthDays) == 0 {
+		return nil, errors.New("no calendar years")
+	}
+
+	years := make([]int, 0, len(monthDays))
+	for year := range monthDays {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+
+	cal := &calendar{firstYear: years[0], lastYear: years[len(years)-1]}
+	if cal.lastYear-cal.firstYear+1 != len(years) {
+		return nil, errors.New("calendar years must be contiguous")
+	}
+	if referenceBS.Year < cal.firstYear || referenceBS.Year > cal.lastYear {
+		return nil, fmt.Errorf("calendar must include the reference year %d", referenceBS.Year)
+	}
+
+	digest := sha256.New()
+	cal.monthDays = make([][12]int, len(years))
+	cal.yearStart = make([]int, len(years))
+	cal.monthStart = make([][13]int, len(years))
+
+	dayNumber := 0
+	for i, year := range years {
+		months := monthDays[year]
+		if len(months) != 12 {
+			return nil, fmt.Errorf("year %d: expected 12 months, got %d", year, len(months))
+		}
+		fmt.Fprintf(digest, "%d:%v;", year, months)
+
+		cal.yearStart[i] = dayNumber
+		offset := 0
+		for m, days := range months {
+			if days < 29 || days > 32 {
+				return nil, fmt.Errorf("year %d month %d: %d days is out of range", year, m+1, days)
+			}
+			cal.monthDays[i][m] = days
+			cal.monthStart[i][m] = offset
+			offset += days
+		}
+		cal.monthStart[i][12] = offset
+		dayNumber += offset
+	}
+	cal.version = hex.EncodeToString(digest.Sum(nil))[:16]
+
+	cal.days = make([]NepaliDate, 0, dayNumber)
+	for i := range years {
+		for m := 0; m < 12; m++ {
+			for d := 1; d <= cal.monthDays[i][m]; d++ {
+				cal.days = append(cal.days, NepaliDate{Year: years[i], Month: m + 1, Day: d})
+			}
+		}
+	}
+
+	// Anchor day 0 to AD through the reference date
+	referenceDay := cal.yearStart[referenceBS.Year-cal.firstYear]
+	cal.startAD = referenceAD.AddDate(0, 0, -referenceDay)
+
+	cal.fiscal = make([]FiscalBound, len(years))
+	for i, year := range years[:len(years)-1] {
+		startBS := NepaliDate{Year: year, Month: 4, Day: 1}
+		endBS := NepaliDate{Year: year + 1, Month: 3, Day: cal.monthDays[i+1][2]}
+		cal.fiscal[i] = FiscalBound{
+			Name:    fmt.Sprintf("%d/%02d", year, (year+1)%100),
+			StartBS: startBS,
+			EndBS:   endBS,
+			StartAD: cal.toAD(startBS),
+			EndAD:   cal.toAD(endBS),
+		}
+	}
+
+	return cal, nil
+}
+
+// contains reports whether the year has month tables
+func (c *calendar) contains(year int) bool {
+	return year >= c.firstYear && year <= c.lastYear
+}
+
+// toAD converts a BS date in a loaded year; days past the month end roll forward
+func (c *calendar) toAD(bs NepaliDate) time.Time {
+	i := bs.Year - c.firstYear
+	return c.startAD.AddDate(0, 0, c.yearStart[i]+c.monthStart[i][bs.Month-1]+bs.Day-1)
+}
+
+// toBS converts an AD date, reporting false when it falls outside the loaded years
+func (c *calendar) toBS(ad time.Time) (NepaliDate, bool) {
+	day := time.Date(ad.Year(), ad.Month(), ad.Day(), 0, 0, 0, 0, time.UTC)
+	n := int(day.Sub(c.startAD).Hours() / 24)
+	if n < 0 || n >= len(c.days) {
+		return NepaliDate{}, false
+	}
+	return c.days[n], true
+}
+
+// fiscalBound returns the precomputed fiscal year starting in Shrawan of year
+func (c *calendar) fiscalBound(year int) (FiscalBound, bool) {
+	if year < c.firstYear || year >= c.lastYear {
+		return FiscalBound{}, false
+	}
+	return c.fiscal[year-c.firstYear], true
+}
+
+// CalendarVersion identifies the calendar data in use; it changes only when different data is loaded
+func CalendarVersion() string {
+	return current.Load().version
+}
+
+// CalendarRange returns the first and last BS years with calendar data
+func CalendarRange() (firstYear, lastYear int) {
+	cal := current.Load()
+	return cal.firstYear, cal.lastYear
+}
+
+// LoadCalendar replaces the calendar data, e.g. when the government publishes
+// month lengths for new years. The tables are only rebuilt when the data differs
+// from what is loaded; it reports whether anything changed.
+func LoadCalendar(monthDays map[int][]int) (bool, error) {
+	cal, err := buildCalendar(monthDays)
+	if err != nil {
+		return false, err
+	}
+	if cal.version == CalendarVersion() {
+		return false, nil
+	}
+	current.Store(cal)
+	return true, nil
+}
+
+// LoadCalendarFile loads calendar data from a JSON file of BS year to its
+// twelve month lengths, e.g. {"2090": [31, 31, 32, ...]}
+func LoadCalendarFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read calendar file: %w", err)
+	}
+
+	var raw map[string][]int
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return false, fmt.Errorf("failed to parse calendar file: %w", err)
+	}
+
+	monthDays := make(map[int][]int, len(raw))
+	for key, months := range raw {
+		year, err := strconv.Atoi(key)
+		if err != nil {
+			return false, fmt.Errorf("invalid calendar year %q", key)
+		}
+		monthDays[year] = months
+	}
+
+	return LoadCalendar(monthDays)
+}
+
+// CalendarMonth is one month of a BS year with its AD span
+type CalendarMonth struct {
+	Month   int       `json:"month"`
+	Name    string    `json:"name"`
+	Days    int       `json:"days"`
+	StartAD time.Time `json:"startAd"`
+	EndAD   time.Time `json:"endAd"`
+}
+
+// CalendarYear is the calendar metadata of a BS year
+type CalendarYear struct {
+	Year       int             `json:"year"`
+	TotalDays  int             `json:"totalDays"`
+	StartAD    time.Time       `json:"startAd"`
+	EndAD      time.Time       `json:"endAd"`
+	Months     []CalendarMonth `json:"months"`
+	FiscalYear *FiscalBound    `json:"fiscalYear,omitempty"` // Starting in Shrawan of this year
+	Version    string          `json:"version"`
+}
+
+// GetCalendarYear returns month lengths, AD spans and the fiscal year of a BS year
+func GetCalendarYear(year int) (*CalendarYear, error) {
+	cal := current.Load()
+	if !cal.contains(year) {
+		return nil, ErrYearNotInCalendar
+	}
+
+	i := year - cal.firstYear
+	result := &CalendarYear{
+		Year:      year,
+		TotalDays: cal.monthStart[i][12],
+		StartAD:   cal.toAD(NepaliDate{Year: year, Month: 1, Day: 1}),
+		EndAD:     cal.toAD(NepaliDate{Year: year, Month: 12, Day: cal.monthDays[i][11]}),
+		Months:    make([]CalendarMonth, 12),
+		Version:   cal.version,
+	}
+	for m := 1; m <= 12; m++ {
+		days := cal.monthDays[i][m-1]
+		result.Months[m-1] = CalendarMonth{
+			Month:   m,
+			Name:    NepaliDate{Month: m}.NepaliMonthName(),
+			Days:    days,
+			StartAD: cal.toAD(NepaliDate{Year: year, Month: m, Day: 1}),
+			EndAD:   cal.toAD(NepaliDate{Year: year, Month: m, Day: days}),
+		}
+	}
+	if bound, ok := cal.fiscalBound(year); ok {
+		result.FiscalYear = &bound
+	}
+
+	return result, nil
+}
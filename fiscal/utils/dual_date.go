@@ -0,0 +1,44 @@
+package utils
+
+import "time"
+
+// DateDisplay is which calendar printed dates show first; the other follows in brackets
+type DateDisplay string
+
+const (
+	DisplayADPrimary DateDisplay = "ad_primary" // 2025-10-16 (2082-06-30 BS)
+	DisplayBSPrimary DateDisplay = "bs_primary" // 2082-06-30 BS (2025-10-16)
+)
+
+// DefaultDateDisplay keeps AD dates first, as documents printed before BS dates were added
+const DefaultDateDisplay = DisplayADPrimary
+
+// Valid reports whether the display is a known one
+func (d DateDisplay) Valid() bool {
+	return d == DisplayADPrimary || d == DisplayBSPrimary
+}
+
+// BSDate returns an AD date's BS date as YYYY-MM-DD
+func BSDate(ad time.Time) string {
+	return ADToBS(ad).String()
+}
+
+// FormatDualDate prints a date in both calendars, the display's calendar first, e.g.
+// "2082-06-30 BS (2025-10-16)". BS dates are always marked; unknown displays print AD first.
+func FormatDualDate(ad time.Time, display DateDisplay) string {
+	if display == DisplayBSPrimary {
+		return BSDate(ad) + " BS (" + ad.Format("2006-01-02") + ")"
+	}
+	return ad.Format("2006-01-02") + " (" + BSDate(ad) + " BS)"
+}
+
+// FormatDualRange prints a date range in both calendars, e.g.
+// "2082-04-01 to 2082-06-30 BS (2025-07-17 to 2025-10-16)"
+func FormatDualRange(from, to time.Time, display DateDisplay) string {
+	ad := from.Format("2006-01-02") + " to " + to.Format("2006-01-02")
+	bs := BSDate(from) + " to " + BSDate(to)
+	if display == DisplayBSPrimary {
+		return bs + " BS (" + ad + ")"
+	}
+	return ad + " (" + bs + " BS)"
+}
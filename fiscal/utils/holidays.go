@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// PublicHoliday is a national public holiday of Nepal
+type PublicHoliday struct {
+	DateBS     NepaliDate `json:"dateBs"`
+	DateAD     time.Time  `json:"dateAd"`
+	Name       string     `json:"name"`
+	NameNepali string     `json:"nameNepali,omitempty"`
+}
+
+// fixedHoliday is a holiday observed on the same day every year
+type fixedHoliday struct {
+	Month, Day int
+	Name       string
+	NameNepali string
+}
+
+// fixedBSHolidays fall on the same BS date every year
+var fixedBSHolidays = []fixedHoliday{
+	{1, 1, "Nepali New Year", "नयाँ वर्ष"},
+	{1, 11, "Loktantra Diwas", "लोकतन्त्र दिवस"},
+	{2, 15, "Republic Day", "गणतन्त्र दिवस"},
+	{6, 3, "Constitution Day", "संविधान दिवस"},
+	{9, 27, "Prithvi Jayanti", "पृथ्वी जयन्ती"},
+	{10, 1, "Maghe Sankranti", "माघे संक्रान्ति"},
+	{10, 16, "Martyrs' Day", "शहीद दिवस"},
+	{11, 7, "Democracy Day", "प्रजातन्त्र दिवस"},
+}
+
+// fixedADHolidays fall on the same AD date every year (Month is the AD month)
+var fixedADHolidays = []fixedHoliday{
+	{5, 1, "Labour Day", "मजदुर दिवस"},
+	{12, 25, "Christmas", "क्रिसमस"},
+}
+
+// lunarHolidays are festivals that follow the lunar calendar (Dashain, Tihar,
+// Holi, Buddha Jayanti...), whose dates the government announces each year.
+// They are loaded from data like the month lengths; BS year -> holidays.
+var lunarHolidays atomic.Pointer[map[int][]PublicHoliday]
+
+// PublicHolidays returns the public holidays of a BS year, in date order: the
+// fixed-date holidays plus the lunar festivals loaded for the year
+func PublicHolidays(year int) ([]PublicHoliday, error) {
+	cal := current.Load()
+	if !cal.contains(year) {
+		return nil, ErrYearNotInCalendar
+	}
+
+	i := year - cal.firstYear
+	holidays := make([]PublicHoliday, 0, len(fixedBSHolidays)+len(fixedADHolidays))
+	for _, h := range fixedBSHolidays {
+		bs := NepaliDate{Year: year, Month: h.Month, Day: h.Day}
+		holidays = append(holidays, PublicHoliday{DateBS: bs, DateAD: cal.toAD(bs), Name: h.Name, NameNepali: h.NameNepali})
+	}
+
+	// An AD date falls once in the BS year, in whichever AD year the BS year covers it
+	start := cal.toAD(NepaliDate{Year: year, Month: 1, Day: 1})
+	end := cal.toAD(NepaliDate{Year: year, Month: 12, Day: cal.monthDays[i][11]})
+	for _, h := range fixedADHolidays {
+		for adYear := start.Year(); adYear <= end.Year(); adYear++ {
+			ad := time.Date(adYear, time.Month(h.Month), h.Day, 0, 0, 0, 0, time.UTC)
+			if ad.Before(start) || ad.After(end) {
+				continue
+			}
+			bs, _ := cal.toBS(ad)
+			holidays = append(holidays, PublicHoliday{DateBS: bs, DateAD: ad, Name: h.Name, NameNepali: h.NameNepali})
+		}
+	}
+
+	if lunar := lunarHolidays.Load(); lunar != nil {
+		for _, h := range (*lunar)[year] {
+			h.DateAD = cal.toAD(h.DateBS)
+			holidays = append(holidays, h)
+		}
+	}
+
+	sort.SliceStable(holidays, func(a, b int) bool { return holidays[a].DateAD.Before(holidays[b].DateAD) })
+	return holidays, nil
+}
+
+// LoadHolidays replaces the lunar festival dates, keyed by BS year
+func LoadHolidays(holidays map[int][]PublicHoliday) error {
+	for year, list := range holidays {
+		for _, h := range list {
+			if h.DateBS.Year != year || h.DateBS.Month < 1 || h.DateBS.Month > 12 || h.DateBS.Day < 1 || h.DateBS.Day > 32 {
+				return fmt.Errorf("year %d: invalid holiday date %s", year, h.DateBS)
+			}
+			if h.Name == "" {
+				return fmt.Errorf("year %d: holiday on %s has no name", year, h.DateBS)
+			}
+		}
+	}
+	lunarHolidays.Store(&holidays)
+	return nil
+}
+
+// LoadHolidayFile loads lunar festival dates from a JSON file of BS year to its
+// holidays, e.g. {"2082": [{"date": "2082-06-17", "name": "Vijaya Dashami"}]}
+func LoadHolidayFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read holiday file: %w", err)
+	}
+
+	var raw map[string][]struct {
+		Date       string `json:"date"`
+		Name       string `json:"name"`
+		NameNepali string `json:"nameNepali"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse holiday file: %w", err)
+	}
+
+	holidays := make(map[int][]PublicHoliday, len(raw))
+	for key, list := range raw {
+		year, err := strconv.Atoi(key)
+		if err != nil {
+			return fmt.Errorf("invalid holiday year %q", key)
+		}
+		for _, h := range list {
+			bs, err := ParseNepaliDate(h.Date)
+			if err != nil {
+				return fmt.Errorf("year %d: invalid holiday date %q", year, h.Date)
+			}
+			holidays[year] = append(holidays[year], PublicHoliday{DateBS: bs, Name: h.Name, NameNepali: h.NameNepali})
+		}
+	}
+
+	return LoadHolidays(holidays)
+}
@@ -45,8 +45,8 @@ func (nd NepaliDate) EnglishMonthName() string {
 	return "Unknown"
 }
 
-// Days in each Nepali month for years 2070-2090 BS
-// Note: This is a simplified mapping. In production, use a complete calendar library
+// Days in each Nepali month for years 2080-2089 BS, the built-in calendar data.
+// Lookup tables are precomputed from it at startup; see LoadCalendar to replace it.
 var nepaliMonthDays = map[int][]int{
 	2080: {31, 32, 31, 32, 31, 30, 30, 29, 30, 29, 30, 30}, // 2023-2024 AD
 	2081: {31, 31, 32, 31, 31, 31, 30, 29, 30, 29, 30, 30}, // 2024-2025 AD
@@ -67,7 +67,11 @@ var referenceAD = time.Date(2023, 4, 14, 0, 0, 0, 0, time.UTC)
 
 // ADToBS converts Gregorian (AD) date to Bikram Sambat (BS)
 func ADToBS(ad time.Time) NepaliDate {
-	// Calculate days difference from reference
+	if bs, ok := current.Load().toBS(ad); ok {
+		return bs
+	}
+
+	// Outside the calendar data: walk months from the reference date
 	daysDiff := int(ad.Sub(referenceAD).Hours() / 24)
 
 	// Start from reference BS date
@@ -104,6 +108,10 @@ func ADToBS(ad time.Time) NepaliDate {
 
 // BSToAD converts Bikram Sambat (BS) date to Gregorian (AD)
 func BSToAD(bs NepaliDate) time.Time {
+	if cal := current.Load(); cal.contains(bs.Year) && bs.Month >= 1 && bs.Month <= 12 {
+		return cal.toAD(bs)
+	}
+
 	// Calculate total days from reference BS to target BS
 	totalDays := 0
 
@@ -138,8 +146,8 @@ func BSToAD(bs NepaliDate) time.Time {
 
 // getDaysInMonth returns the number of days in a Nepali month
 func getDaysInMonth(year, month int) int {
-	if monthDays, ok := nepaliMonthDays[year]; ok && month >= 1 && month <= 12 {
-		return monthDays[month-1]
+	if cal := current.Load(); cal.contains(year) && month >= 1 && month <= 12 {
+		return cal.monthDays[year-cal.firstYear][month-1]
 	}
 	// Default fallback
 	return 30
@@ -176,11 +184,16 @@ func GetFiscalYearDates(fiscalYearName string) (startBS NepaliDate, endBS Nepali
 	var year int
 	fmt.Sscanf(fiscalYearName, "%d/", &year)
 
+	// Boundaries are precomputed for every year in the calendar data
+	if bound, ok := current.Load().fiscalBound(year); ok {
+		return bound.StartBS, bound.EndBS, bound.StartAD, bound.EndAD
+	}
+
 	// Fiscal year starts on Shrawan 1 (month 4, day 1)
 	startBS = NepaliDate{Year: year, Month: 4, Day: 1}
 
-	// Fiscal year ends on Ashad 32 (month 3, day 32 of next year)
-	endBS = NepaliDate{Year: year + 1, Month: 3, Day: 32}
+	// Fiscal year ends on the last day of Ashad (month 3 of next year, 31 or 32 days)
+	endBS = NepaliDate{Year: year + 1, Month: 3, Day: getDaysInMonth(year+1, 3)}
 
 	// Convert to AD
 	startAD = BSToAD(startBS)
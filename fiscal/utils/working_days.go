@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"time"
+)
+
+// DefaultWeekend is Nepal's weekly holiday; offices and most businesses close on Saturday only
+var DefaultWeekend = []time.Weekday{time.Saturday}
+
+// maxClosedRun bounds the search for a working day, so a calendar closed every
+// day cannot loop forever; no real calendar closes for a whole year
+const maxClosedRun = 366
+
+// WorkingCalendar tells working days from weekends and holidays for due-date
+// calculations. Dates are compared by their calendar day, ignoring time of day.
+type WorkingCalendar struct {
+	weekend  [7]bool
+	holidays map[string]string // AD date (YYYY-MM-DD) -> holiday name
+}
+
+// NewWorkingCalendar creates a calendar closed on the weekend days and the given holidays
+func NewWorkingCalendar(weekend []time.Weekday, holidays map[time.Time]string) *WorkingCalendar {
+	cal := &WorkingCalendar{holidays: make(map[string]string, len(holidays))}
+	for _, day := range weekend {
+		cal.weekend[day] = true
+	}
+	for date, name := range holidays {
+		cal.holidays[dayKey(date)] = name
+	}
+	return cal
+}
+
+// DefaultWorkingCalendar is closed on Saturdays and the built-in public holidays
+// of the BS years around the current one
+func DefaultWorkingCalendar() *WorkingCalendar {
+	holidays := make(map[time.Time]string)
+	year := GetCurrentNepaliDate().Year
+	for y := year - 1; y <= year+1; y++ {
+		list, err := PublicHolidays(y)
+		if err != nil {
+			continue
+		}
+		for _, holiday := range list {
+			holidays[holiday.DateAD] = holiday.Name
+		}
+	}
+	return NewWorkingCalendar(DefaultWeekend, holidays)
+}
+
+// dayKey identifies the calendar day of t
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// Holiday returns the name of the holiday on t, if any
+func (c *WorkingCalendar) Holiday(t time.Time) (string, bool) {
+	name, ok := c.holidays[dayKey(t)]
+	return name, ok
+}
+
+// IsWorkingDay reports whether t falls on neither a weekend day nor a holiday
+func (c *WorkingCalendar) IsWorkingDay(t time.Time) bool {
+	if c.weekend[t.Weekday()] {
+		return false
+	}
+	_, holiday := c.holidays[dayKey(t)]
+	return !holiday
+}
+
+// NextWorkingDay returns t if it is a working day, otherwise the first working
+// day after it. Due dates landing on a weekend or holiday roll forward this way.
+func (c *WorkingCalendar) NextWorkingDay(t time.Time) time.Time {
+	for i := 0; i < maxClosedRun && !c.IsWorkingDay(t); i++ {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// AddWorkingDays returns the date n working days after t, e.g. "within 3
+// working days". With n <= 0 it is NextWorkingDay.
+func (c *WorkingCalendar) AddWorkingDays(t time.Time, n int) time.Time {
+	t = c.NextWorkingDay(t)
+	for ; n > 0; n-- {
+		t = c.NextWorkingDay(t.AddDate(0, 0, 1))
+	}
+	return t
+}
+
+// DueDate adds calendar days to t, as "net 30" terms do, rolling the result
+// forward to a working day
+func (c *WorkingCalendar) DueDate(t time.Time, days int) time.Time {
+	return c.NextWorkingDay(t.AddDate(0, 0, days))
+}
+
+// WorkingDaysBetween counts the working days after from up to and including to;
+// zero when to is not after from
+func (c *WorkingCalendar) WorkingDaysBetween(from, to time.Time) int {
+	count := 0
+	for day := from.AddDate(0, 0, 1); dayKey(day) <= dayKey(to); day = day.AddDate(0, 0, 1) {
+		if c.IsWorkingDay(day) {
+			count++
+		}
+	}
+	return count
+}
@@ -12,11 +12,15 @@ type RegisterTenantDTO struct {
 	Phone      string  `json:"phone" validate:"required,min=10,max=15"`
 	Email      *string `json:"email" validate:"omitempty,email"`
 	Password   string  `json:"password" validate:"required,min=6,max=50"`
+	// Data region to store the tenant's data in, e.g. "np"; defaults to the home region
+	DataRegion string `json:"dataRegion" validate:"omitempty,max=20"`
 }
 
 type LoginDTO struct {
 	Phone    string `json:"phone" validate:"required"`
 	Password string `json:"password" validate:"required"`
+	// Limits the session's tokens to these scopes, e.g. ["read:*"] for a read-only session
+	Scopes []string `json:"scopes"`
 }
 
 type VerifyOTPDTO struct {
@@ -45,6 +49,9 @@ type TokenPayload struct {
 	UserID   uuid.UUID  `json:"userId"`
 	TenantID *uuid.UUID `json:"tenantId"`
 	Role     string     `json:"role"`
+	Scopes   []string   `json:"scopes,omitempty"`
+	// SupportSessionID marks a read-only support shadowing token
+	SupportSessionID *uuid.UUID `json:"supportSessionId,omitempty"`
 }
 
 type ForgotPasswordDTO struct {
@@ -93,3 +100,175 @@ type Pagination struct {
 	CurrentPage int `json:"currentPage"`
 	Limit       int `json:"limit"`
 }
+
+type AddTenantDomainDTO struct {
+	Domain string `json:"domain" validate:"required,min=3,max=253"`
+}
+
+type TenantDomainResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Domain     string     `json:"domain"`
+	Kind       string     `json:"kind"`
+	IsVerified bool       `json:"isVerified"`
+	VerifiedAt *time.Time `json:"verifiedAt"`
+	// TXT record the tenant must publish for custom domains
+	VerificationRecord *DNSRecord `json:"verificationRecord,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+}
+
+type DNSRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type UpdateBrandingDTO struct {
+	DisplayName    *string `json:"displayName" validate:"omitempty,max=150"`
+	LogoURL        *string `json:"logoUrl" validate:"omitempty,url,max=500"`
+	FaviconURL     *string `json:"faviconUrl" validate:"omitempty,url,max=500"`
+	PrimaryColor   string  `json:"primaryColor" validate:"omitempty"`
+	SecondaryColor string  `json:"secondaryColor" validate:"omitempty"`
+	AccentColor    string  `json:"accentColor" validate:"omitempty"`
+	DocumentHeader *string `json:"documentHeader" validate:"omitempty,max=2000"`
+	DocumentFooter *string `json:"documentFooter" validate:"omitempty,max=2000"`
+	EmailFromName  *string `json:"emailFromName" validate:"omitempty,max=100"`
+}
+
+// PublicBrandingResponse is the unauthenticated view used by the portal login page
+type PublicBrandingResponse struct {
+	DisplayName    string  `json:"displayName"`
+	LogoURL        *string `json:"logoUrl"`
+	FaviconURL     *string `json:"faviconUrl"`
+	PrimaryColor   string  `json:"primaryColor"`
+	SecondaryColor string  `json:"secondaryColor"`
+	AccentColor    string  `json:"accentColor"`
+}
+
+type SwitchTenantDTO struct {
+	TenantID uuid.UUID `json:"tenantId" validate:"required"`
+}
+
+type TenantMembershipResponse struct {
+	TenantID   uuid.UUID `json:"tenantId"`
+	TenantName string    `json:"tenantName"`
+	Role       string    `json:"role"`
+	IsHome     bool      `json:"isHome"`
+	IsCurrent  bool      `json:"isCurrent"`
+}
+
+type GrantGuestAccessDTO struct {
+	// Phone or email of an existing user account
+	Identifier  string     `json:"identifier" validate:"required"`
+	Permissions []string   `json:"permissions" validate:"required,min=1"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+	// Used when expiresAt is not given
+	ExpiresInDays int `json:"expiresInDays" validate:"omitempty,min=1,max=365"`
+}
+
+type GuestAccessResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"userId"`
+	Name        string     `json:"name"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+	GrantedBy   *uuid.UUID `json:"grantedBy"`
+	RevokedAt   *time.Time `json:"revokedAt"`
+	Status      string     `json:"status"` // active, expired, revoked
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+type SetSupportAccessDTO struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+type SupportAccessResponse struct {
+	Enabled   bool       `json:"enabled"`
+	ChangedAt *time.Time `json:"changedAt"`
+	ChangedBy *uuid.UUID `json:"changedBy"`
+}
+
+type StartSupportSessionDTO struct {
+	// Why support needs to look, shown to the tenant with the session
+	Reason          string `json:"reason" validate:"required,max=500"`
+	DurationMinutes int    `json:"durationMinutes" validate:"omitempty,min=5,max=60"`
+}
+
+type SupportSessionResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	TenantID        uuid.UUID  `json:"tenantId"`
+	SupportUserID   uuid.UUID  `json:"supportUserId"`
+	SupportUserName string     `json:"supportUserName"`
+	Reason          string     `json:"reason"`
+	ExpiresAt       time.Time  `json:"expiresAt"`
+	EndedAt         *time.Time `json:"endedAt"`
+	EndedBy         *uuid.UUID `json:"endedBy"`
+	Status          string     `json:"status"` // active, expired, ended
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+type SupportSessionTokenResponse struct {
+	// Read-only access token for the tenant; there is no refresh token
+	AccessToken string                 `json:"accessToken"`
+	Session     SupportSessionResponse `json:"session"`
+}
+
+type SetIntegrationCredentialsDTO struct {
+	// Fields left out keep their current value; an empty string clears an optional field
+	Values map[string]string `json:"values" validate:"required,min=1"`
+	// How long values replaced by a secret change stay accepted, default 24
+	PreviousValidHours *int `json:"previousValidHours" validate:"omitempty,min=0,max=168"`
+}
+
+type IntegrationFieldResponse struct {
+	Key      string `json:"key"`
+	Label    string `json:"label"`
+	Secret   bool   `json:"secret"`
+	Required bool   `json:"required"`
+	IsSet    bool   `json:"isSet"`
+	// Secrets are masked to their last four characters
+	Value string `json:"value"`
+}
+
+type IntegrationCredentialResponse struct {
+	Integration       string                     `json:"integration"`
+	Label             string                     `json:"label"`
+	Configured        bool                       `json:"configured"`
+	Fields            []IntegrationFieldResponse `json:"fields"`
+	Version           int                        `json:"version"`
+	RotatedAt         *time.Time                 `json:"rotatedAt"`
+	PreviousExpiresAt *time.Time                 `json:"previousExpiresAt"`
+	UpdatedBy         *uuid.UUID                 `json:"updatedBy"`
+	UpdatedAt         *time.Time                 `json:"updatedAt"`
+}
+
+type CreateAPIKeyDTO struct {
+	Name   string   `json:"name" validate:"required,min=2,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1"`
+	// Never expires when omitted
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type APIKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Role       string     `json:"role"`
+	CreatedBy  uuid.UUID  `json:"createdBy"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+	Status     string     `json:"status"` // active, expired, revoked
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CreatedAPIKeyResponse carries the key itself, shown only once
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type ScopeResponse struct {
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+}
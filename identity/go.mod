@@ -9,6 +9,7 @@ replace github.com/aceextension/core => ../core
 toolchain go1.24.12
 
 require (
+	github.com/aceextension/audit v0.0.0
 	github.com/aceextension/core v0.0.0-00010101000000-000000000000
 	github.com/golang-jwt/jwt/v5 v5.3.1
 	github.com/google/uuid v1.6.0
@@ -42,3 +43,7 @@ require (
 	golang.org/x/sys v0.40.0 // indirect
 	golang.org/x/text v0.33.0 // indirect
 )
+
+replace github.com/aceextension/audit => ../audit
+
+replace github.com/aceextension/notification => ../notification
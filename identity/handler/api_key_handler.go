@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/middleware"
+	"github.com/aceextension/identity/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateKey godoc
+// @Summary Create API key
+// @Description Issue an API key acting as the caller, limited to the selected scopes (e.g. ["read:catalog","write:sales"], or ["read:*"] for read-only). The key is returned only once; send it as "Authorization: Bearer ak_..."
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAPIKeyDTO true "API key"
+// @Success 201 {object} dto.CreatedAPIKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/api-keys [post]
+func (h *APIKeyHandler) CreateKey(c echo.Context) error {
+	var req dto.CreateAPIKeyDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+	actorID, _ := uuid.Parse(user.UserID)
+
+	res, err := h.apiKeyService.CreateKey(c.Request().Context(), tenantID, actorID, user.Role, user.Scopes, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrScopeNotGranted):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidScope), errors.Is(err, service.ErrAPIKeyExpiryInvalid):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// ListKeys godoc
+// @Summary List API keys
+// @Description List the tenant's active, expired and revoked API keys; keys themselves are never shown again
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} dto.APIKeyResponse
+// @Security BearerAuth
+// @Router /tenant/api-keys [get]
+func (h *APIKeyHandler) ListKeys(c echo.Context) error {
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	res, err := h.apiKeyService.ListKeys(c.Request().Context(), tenantID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// RevokeKey godoc
+// @Summary Revoke API key
+// @Description Revoke an API key; requests with it are refused from then on
+// @Tags api-keys
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeKey(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid api key id"})
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	if err := h.apiKeyService.RevokeKey(c.Request().Context(), tenantID, id); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListScopes godoc
+// @Summary List token scopes
+// @Description List the scopes API keys and scoped sessions can be limited to. A scope is read:<area> or write:<area>; write includes read and the area * covers every area
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} dto.ScopeResponse
+// @Router /auth/scopes [get]
+func (h *APIKeyHandler) ListScopes(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.apiKeyService.ListScopes())
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/aceextension/core/db"
 	"github.com/aceextension/identity/dto"
 	"github.com/aceextension/identity/middleware"
 	"github.com/aceextension/identity/service"
@@ -43,6 +45,9 @@ func (h *AuthHandler) RegisterTenant(c echo.Context) error {
 
 	res, err := h.authService.RegisterTenant(c.Request().Context(), req)
 	if err != nil {
+		if errors.Is(err, db.ErrUnknownRegion) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -83,7 +88,7 @@ func (h *AuthHandler) VerifyOTP(c echo.Context) error {
 
 // Login godoc
 // @Summary User Login
-// @Description Login with phone or email and password
+// @Description Login with phone or email and password. Optional scopes (e.g. ["read:*"]) limit the session's tokens
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -104,6 +109,9 @@ func (h *AuthHandler) Login(c echo.Context) error {
 
 	res, err := h.authService.Login(c.Request().Context(), req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidScope) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
 
@@ -315,3 +323,70 @@ func (h *AuthHandler) GetMe(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, res)
 }
+
+// ListTenants godoc
+// @Summary List accessible tenants
+// @Description List every tenant the authenticated user belongs to, with the role held in each
+// @Tags auth
+// @Produce json
+// @Success 200 {array} dto.TenantMembershipResponse
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/tenants [get]
+func (h *AuthHandler) ListTenants(c echo.Context) error {
+	authUser := c.Get("user").(middleware.AuthUser)
+	userID, err := uuid.Parse(authUser.UserID)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user id"})
+	}
+
+	var currentTenantID *uuid.UUID
+	if tenantID, err := uuid.Parse(authUser.TenantID); err == nil {
+		currentTenantID = &tenantID
+	}
+
+	res, err := h.authService.ListTenants(c.Request().Context(), userID, currentTenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// SwitchTenant godoc
+// @Summary Switch active tenant
+// @Description Issue a new token pair scoped to another tenant the user belongs to
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.SwitchTenantDTO true "Target tenant"
+// @Success 200 {object} dto.AuthResponse
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/switch-tenant [post]
+func (h *AuthHandler) SwitchTenant(c echo.Context) error {
+	var req dto.SwitchTenantDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	authUser := c.Get("user").(middleware.AuthUser)
+	userID, err := uuid.Parse(authUser.UserID)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user id"})
+	}
+
+	res, err := h.authService.SwitchTenant(c.Request().Context(), userID, req.TenantID, authUser.Scopes)
+	if err != nil {
+		if errors.Is(err, service.ErrNoTenantAccess) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
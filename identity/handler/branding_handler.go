@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/middleware"
+	"github.com/aceextension/identity/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type BrandingHandler struct {
+	brandingService service.BrandingService
+}
+
+func NewBrandingHandler(brandingService service.BrandingService) *BrandingHandler {
+	return &BrandingHandler{
+		brandingService: brandingService,
+	}
+}
+
+// GetBranding godoc
+// @Summary Get tenant branding
+// @Description Get the tenant's white-label settings with platform defaults filled in
+// @Tags branding
+// @Produce json
+// @Success 200 {object} models.TenantBranding
+// @Security BearerAuth
+// @Router /tenant/branding [get]
+func (h *BrandingHandler) GetBranding(c echo.Context) error {
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	branding, err := h.brandingService.GetBranding(c.Request().Context(), tenantID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, branding)
+}
+
+// UpdateBranding godoc
+// @Summary Update tenant branding
+// @Description Replace the tenant's logo, color palette, document header/footer and email sender name
+// @Tags branding
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateBrandingDTO true "Branding"
+// @Success 200 {object} models.TenantBranding
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/branding [put]
+func (h *BrandingHandler) UpdateBranding(c echo.Context) error {
+	var req dto.UpdateBrandingDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	branding, err := h.brandingService.UpdateBranding(c.Request().Context(), tenantID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidColor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, branding)
+}
+
+// GetPublicBranding godoc
+// @Summary Get branding for a portal domain
+// @Description Public endpoint for the login page; resolves the tenant from the domain query parameter or the request host
+// @Tags branding
+// @Produce json
+// @Param domain query string false "Portal host, defaults to the request host"
+// @Success 200 {object} dto.PublicBrandingResponse
+// @Failure 404 {object} map[string]string
+// @Router /public/branding [get]
+func (h *BrandingHandler) GetPublicBranding(c echo.Context) error {
+	host := c.QueryParam("domain")
+	if host == "" {
+		host = c.Request().Host
+	}
+	if idx := strings.Index(host, ":"); idx != -1 {
+		host = host[:idx]
+	}
+
+	branding, err := h.brandingService.GetPublicBranding(c.Request().Context(), host)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownHost) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "no tenant is configured for this domain"})
+		}
+		return err
+	}
+
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, branding)
+}
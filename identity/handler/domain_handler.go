@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/middleware"
+	"github.com/aceextension/identity/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type DomainHandler struct {
+	domainService service.DomainService
+}
+
+func NewDomainHandler(domainService service.DomainService) *DomainHandler {
+	return &DomainHandler{
+		domainService: domainService,
+	}
+}
+
+// ListDomains godoc
+// @Summary List tenant domains
+// @Description List the subdomain and custom domains configured for the tenant
+// @Tags tenant-domains
+// @Produce json
+// @Success 200 {array} dto.TenantDomainResponse
+// @Security BearerAuth
+// @Router /tenant/domains [get]
+func (h *DomainHandler) ListDomains(c echo.Context) error {
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	domains, err := h.domainService.ListDomains(c.Request().Context(), tenantID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, domains)
+}
+
+// AddDomain godoc
+// @Summary Add a tenant domain
+// @Description Claim a subdomain ("acme") or a custom domain ("shop.acme.com"). Custom domains must be verified via a DNS TXT record.
+// @Tags tenant-domains
+// @Accept json
+// @Produce json
+// @Param request body dto.AddTenantDomainDTO true "Domain"
+// @Success 201 {object} dto.TenantDomainResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/domains [post]
+func (h *DomainHandler) AddDomain(c echo.Context) error {
+	var req dto.AddTenantDomainDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	domain, err := h.domainService.AddDomain(c.Request().Context(), tenantID, req.Domain)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDomainTaken):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidDomain), errors.Is(err, service.ErrReservedSubdomain):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, domain)
+}
+
+// VerifyDomain godoc
+// @Summary Verify a custom domain
+// @Description Check the DNS TXT record for a custom domain and activate it
+// @Tags tenant-domains
+// @Produce json
+// @Param id path string true "Domain ID"
+// @Success 200 {object} dto.TenantDomainResponse
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/domains/{id}/verify [post]
+func (h *DomainHandler) VerifyDomain(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid domain id"})
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	domain, err := h.domainService.VerifyDomain(c.Request().Context(), tenantID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDomainNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrDomainUnverified):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, domain)
+}
+
+// RemoveDomain godoc
+// @Summary Remove a tenant domain
+// @Tags tenant-domains
+// @Param id path string true "Domain ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/domains/{id} [delete]
+func (h *DomainHandler) RemoveDomain(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid domain id"})
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	if err := h.domainService.RemoveDomain(c.Request().Context(), tenantID, id); err != nil {
+		if errors.Is(err, service.ErrDomainNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/middleware"
+	"github.com/aceextension/identity/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type GuestHandler struct {
+	guestService service.GuestAccessService
+}
+
+func NewGuestHandler(guestService service.GuestAccessService) *GuestHandler {
+	return &GuestHandler{
+		guestService: guestService,
+	}
+}
+
+// GrantAccess godoc
+// @Summary Grant guest access
+// @Description Give an external accountant or auditor time-boxed, read-only access to selected modules
+// @Tags guest-access
+// @Accept json
+// @Produce json
+// @Param request body dto.GrantGuestAccessDTO true "Guest access"
+// @Success 201 {object} dto.GuestAccessResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/guests [post]
+func (h *GuestHandler) GrantAccess(c echo.Context) error {
+	var req dto.GrantGuestAccessDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	actorID, _ := uuid.Parse(user.UserID)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	res, err := h.guestService.GrantAccess(c.Request().Context(), tenantID, actorID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrGuestIsMember) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// ListGuests godoc
+// @Summary List guest access grants
+// @Description List active, expired and revoked guest access for the tenant
+// @Tags guest-access
+// @Produce json
+// @Success 200 {array} dto.GuestAccessResponse
+// @Security BearerAuth
+// @Router /tenant/guests [get]
+func (h *GuestHandler) ListGuests(c echo.Context) error {
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	res, err := h.guestService.ListGuests(c.Request().Context(), tenantID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// RevokeAccess godoc
+// @Summary Revoke guest access
+// @Description Immediately revoke a guest's access; their open tokens stop working on the next request
+// @Tags guest-access
+// @Param id path string true "Guest access ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/guests/{id} [delete]
+func (h *GuestHandler) RevokeAccess(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid guest id"})
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	if err := h.guestService.RevokeAccess(c.Request().Context(), tenantID, id); err != nil {
+		if errors.Is(err, service.ErrGuestNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListActivity godoc
+// @Summary Guest activity trail
+// @Description Everything the guest viewed, exported or was denied, newest first
+// @Tags guest-access
+// @Produce json
+// @Param id path string true "Guest access ID"
+// @Param limit query int false "Items per page (max 500)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.GuestAccessLog
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/guests/{id}/activity [get]
+func (h *GuestHandler) ListActivity(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid guest id"})
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	res, err := h.guestService.ListActivity(c.Request().Context(), tenantID, id, limit, offset)
+	if err != nil {
+		if errors.Is(err, service.ErrGuestNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
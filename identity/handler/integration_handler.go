@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/middleware"
+	"github.com/aceextension/identity/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type IntegrationHandler struct {
+	integrationService service.IntegrationService
+}
+
+func NewIntegrationHandler(integrationService service.IntegrationService) *IntegrationHandler {
+	return &IntegrationHandler{
+		integrationService: integrationService,
+	}
+}
+
+// ListIntegrations godoc
+// @Summary List integration credentials
+// @Description List every third-party integration (CBMS, SMS gateway, eSewa, Khalti) with the tenant's settings; secrets are masked
+// @Tags integrations
+// @Produce json
+// @Success 200 {array} dto.IntegrationCredentialResponse
+// @Security BearerAuth
+// @Router /tenant/integrations [get]
+func (h *IntegrationHandler) ListIntegrations(c echo.Context) error {
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	integrations, err := h.integrationService.ListIntegrations(c.Request().Context(), tenantID)
+	if err != nil {
+		return integrationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, integrations)
+}
+
+// GetIntegration godoc
+// @Summary Get integration credentials
+// @Description Get the tenant's settings for one integration; secrets are masked
+// @Tags integrations
+// @Produce json
+// @Param name path string true "Integration (cbms, sms, esewa, khalti)"
+// @Success 200 {object} dto.IntegrationCredentialResponse
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/integrations/{name} [get]
+func (h *IntegrationHandler) GetIntegration(c echo.Context) error {
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	integration, err := h.integrationService.GetIntegration(c.Request().Context(), tenantID, c.Param("name"))
+	if err != nil {
+		return integrationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, integration)
+}
+
+// SetIntegration godoc
+// @Summary Set integration credentials
+// @Description Set some or all of an integration's fields; omitted fields keep their value. Values replaced by a secret change stay accepted for previousValidHours (default 24)
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param name path string true "Integration (cbms, sms, esewa, khalti)"
+// @Param request body dto.SetIntegrationCredentialsDTO true "Values"
+// @Success 200 {object} dto.IntegrationCredentialResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/integrations/{name} [put]
+func (h *IntegrationHandler) SetIntegration(c echo.Context) error {
+	var req dto.SetIntegrationCredentialsDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+	userID, _ := uuid.Parse(user.UserID)
+
+	integration, err := h.integrationService.SetIntegration(c.Request().Context(), tenantID, userID, c.Param("name"), req)
+	if err != nil {
+		return integrationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, integration)
+}
+
+// RemoveIntegration godoc
+// @Summary Remove integration credentials
+// @Description Delete the tenant's settings for an integration; connectors fall back to platform defaults
+// @Tags integrations
+// @Param name path string true "Integration (cbms, sms, esewa, khalti)"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/integrations/{name} [delete]
+func (h *IntegrationHandler) RemoveIntegration(c echo.Context) error {
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+	userID, _ := uuid.Parse(user.UserID)
+
+	if err := h.integrationService.RemoveIntegration(c.Request().Context(), tenantID, userID, c.Param("name")); err != nil {
+		return integrationError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func integrationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnknownIntegration), errors.Is(err, service.ErrIntegrationNotConfigured):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCredentials):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrCredentialsChanged):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrCredentialEncryptionMissing):
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+	}
+	return err
+}
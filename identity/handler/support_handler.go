@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/middleware"
+	"github.com/aceextension/identity/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type SupportHandler struct {
+	supportService service.SupportSessionService
+}
+
+func NewSupportHandler(supportService service.SupportSessionService) *SupportHandler {
+	return &SupportHandler{
+		supportService: supportService,
+	}
+}
+
+// GetSupportAccess godoc
+// @Summary Get support access consent
+// @Description Whether platform support may open read-only sessions on the tenant
+// @Tags support-access
+// @Produce json
+// @Success 200 {object} dto.SupportAccessResponse
+// @Security BearerAuth
+// @Router /tenant/support-access [get]
+func (h *SupportHandler) GetSupportAccess(c echo.Context) error {
+	user := c.Get("user").(middleware.AuthUser)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	res, err := h.supportService.GetConsent(c.Request().Context(), tenantID)
+	if err != nil {
+		return supportError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// SetSupportAccess godoc
+// @Summary Allow or refuse support access
+// @Description Turn support shadowing on or off. Turning it off ends open support sessions immediately.
+// @Tags support-access
+// @Accept json
+// @Produce json
+// @Param request body dto.SetSupportAccessDTO true "Consent"
+// @Success 200 {object} dto.SupportAccessResponse
+// @Security BearerAuth
+// @Router /tenant/support-access [put]
+func (h *SupportHandler) SetSupportAccess(c echo.Context) error {
+	var req dto.SetSupportAccessDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	actorID, _ := uuid.Parse(user.UserID)
+	tenantID, _ := uuid.Parse(user.TenantID)
+
+	res, err := h.supportService.SetConsent(c.Request().Context(), tenantID, actorID, *req.Enabled)
+	if err != nil {
+		return supportError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// StartSession godoc
+// @Summary Start a support session
+// @Description Issue a read-only access token for the tenant (Super Admin only). The tenant must have allowed support access; every request in the session is recorded and writes are refused.
+// @Tags support-access
+// @Accept json
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Param request body dto.StartSupportSessionDTO true "Session"
+// @Success 201 {object} dto.SupportSessionTokenResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /v1/admin/tenants/{tenantId}/support-sessions [post]
+func (h *SupportHandler) StartSession(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Param("tenantId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid tenant id"})
+	}
+
+	var req dto.StartSupportSessionDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	supportUserID, _ := uuid.Parse(user.UserID)
+
+	res, err := h.supportService.StartSession(c.Request().Context(), tenantID, supportUserID, req)
+	if err != nil {
+		return supportError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// ListSessions godoc
+// @Summary List support sessions
+// @Description Support sessions opened on the tenant, newest first. Tenants see their own; super admins pass the tenant in the path.
+// @Tags support-access
+// @Produce json
+// @Param limit query int false "Items per page (max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} dto.SupportSessionResponse
+// @Security BearerAuth
+// @Router /tenant/support-access/sessions [get]
+// @Router /v1/admin/tenants/{tenantId}/support-sessions [get]
+func (h *SupportHandler) ListSessions(c echo.Context) error {
+	tenantID, err := supportTenantID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid tenant id"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	res, err := h.supportService.ListSessions(c.Request().Context(), tenantID, limit, offset)
+	if err != nil {
+		return supportError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// EndSession godoc
+// @Summary End a support session
+// @Description End an open support session; its token stops working on the next request
+// @Tags support-access
+// @Param id path string true "Support session ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/support-access/sessions/{id}/end [post]
+// @Router /v1/admin/tenants/{tenantId}/support-sessions/{id}/end [post]
+func (h *SupportHandler) EndSession(c echo.Context) error {
+	tenantID, err := supportTenantID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid tenant id"})
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid support session id"})
+	}
+
+	user := c.Get("user").(middleware.AuthUser)
+	actorID, _ := uuid.Parse(user.UserID)
+
+	if err := h.supportService.EndSession(c.Request().Context(), tenantID, id, actorID); err != nil {
+		return supportError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListActivity godoc
+// @Summary Support session activity trail
+// @Description Everything viewed in the session, and every write that was blocked, newest first
+// @Tags support-access
+// @Produce json
+// @Param id path string true "Support session ID"
+// @Param limit query int false "Items per page (max 500)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.SupportAccessLog
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /tenant/support-access/sessions/{id}/activity [get]
+// @Router /v1/admin/tenants/{tenantId}/support-sessions/{id}/activity [get]
+func (h *SupportHandler) ListActivity(c echo.Context) error {
+	tenantID, err := supportTenantID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid tenant id"})
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid support session id"})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	res, err := h.supportService.ListActivity(c.Request().Context(), tenantID, id, limit, offset)
+	if err != nil {
+		return supportError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// supportTenantID is the tenant in the path on super admin routes, otherwise the caller's own
+func supportTenantID(c echo.Context) (uuid.UUID, error) {
+	if raw := c.Param("tenantId"); raw != "" {
+		return uuid.Parse(raw)
+	}
+	user := c.Get("user").(middleware.AuthUser)
+	return uuid.Parse(user.TenantID)
+}
+
+func supportError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrSupportTenantNotFound), errors.Is(err, service.ErrSupportSessionNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrSupportAccessDisabled):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	default:
+		return err
+	}
+}
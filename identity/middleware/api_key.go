@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyPrefix starts every API key, telling keys apart from access tokens
+const APIKeyPrefix = "ak_"
+
+// APIKeyAuthenticator resolves an API key to the caller it acts as; implemented by service.APIKeyService
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*AuthUser, error)
+}
+
+var apiKeyAuthenticator APIKeyAuthenticator
+
+// SetAPIKeyAuthenticator registers the authenticator JWTMiddleware uses for API keys.
+// Without one, API keys are rejected.
+func SetAPIKeyAuthenticator(authenticator APIKeyAuthenticator) {
+	apiKeyAuthenticator = authenticator
+}
+
+// IsAPIKey reports whether a bearer token is an API key rather than an access token
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// RequireSession rejects API keys on routes that act on the user's own account or
+// session (password, logout, tenant switching), which a key must not reach
+func RequireSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user, ok := c.Get("user").(AuthUser)
+		if !ok {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		}
+		if user.APIKeyID != "" {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "api keys cannot be used here"})
+		}
+		return next(c)
+	}
+}
@@ -1,12 +1,15 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/aceextension/core/config"
+	"github.com/aceextension/core/db"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
@@ -14,6 +17,13 @@ type AuthUser struct {
 	UserID   string `json:"userId"`
 	TenantID string `json:"tenantId"`
 	Role     string `json:"role"`
+	// Scopes limit the token to areas of the API (read:catalog, write:sales); nil is
+	// an unscoped user session, limited only by role
+	Scopes []string `json:"scopes,omitempty"`
+	// APIKeyID is set when the caller authenticated with an API key
+	APIKeyID string `json:"apiKeyId,omitempty"`
+	// SupportSessionID is set on the read-only tokens of support shadowing sessions
+	SupportSessionID string `json:"supportSessionId,omitempty"`
 }
 
 func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
@@ -28,36 +38,97 @@ func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			tokenString = strings.TrimSpace(authHeader)
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		var user AuthUser
+		if IsAPIKey(tokenString) {
+			if apiKeyAuthenticator == nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "api keys are not enabled"})
+			}
+			keyUser, err := apiKeyAuthenticator.AuthenticateAPIKey(c.Request().Context(), tokenString)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+			}
+			user = *keyUser
+		} else {
+			claims, err := parseToken(tokenString)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 			}
-			return []byte(config.GlobalConfig.JWTSecret), nil
-		})
 
-		if err != nil || !token.Valid {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid or expired token"})
+			// Inject user info into context
+			user = AuthUser{
+				UserID: claims["userId"].(string),
+				Role:   claims["role"].(string),
+				Scopes: claimScopes(claims),
+			}
+			if tenantID, ok := claims["tenantId"].(string); ok {
+				user.TenantID = tenantID
+			}
+			if sessionID, ok := claims["supportSessionId"].(string); ok {
+				user.SupportSessionID = sessionID
+			}
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token claims"})
-		}
+		c.Set("user", user)
 
-		// Inject user info into context
-		user := AuthUser{
-			UserID: claims["userId"].(string),
-			Role:   claims["role"].(string),
+		// Expose the caller to services that only see the request context
+		ctx := db.WithRole(c.Request().Context(), user.Role)
+		if userID, err := uuid.Parse(user.UserID); err == nil {
+			ctx = db.WithUserID(ctx, userID)
 		}
-		if tenantID, ok := claims["tenantId"].(string); ok {
-			user.TenantID = tenantID
+		// The caller's own tenant wins over one resolved earlier from the host or the
+		// X-Tenant-ID header, so a header cannot reach another tenant's data
+		if tenantID, err := uuid.Parse(user.TenantID); err == nil {
+			c.Set("tenant_id", tenantID)
+			ctx = db.WithTenantID(ctx, tenantID)
 		}
+		c.SetRequest(c.Request().WithContext(ctx))
 
-		c.Set("user", user)
+		if user.SupportSessionID != "" {
+			return guardSupport(c, user, next)
+		}
+		if user.Role == RoleGuest {
+			return guardGuest(c, user, next)
+		}
 		return next(c)
 	}
 }
 
+// parseToken verifies a token's signature and expiry and returns its claims
+func parseToken(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(config.GlobalConfig.JWTSecret), nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+
+	return claims, nil
+}
+
+// claimScopes reads the token's "scopes" claim; tokens without one are unscoped
+func claimScopes(claims jwt.MapClaims) []string {
+	raw, ok := claims["scopes"].([]interface{})
+	if !ok {
+		return nil
+	}
+	scopes := make([]string, 0, len(raw))
+	for _, scope := range raw {
+		if s, ok := scope.(string); ok {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
 func RequireRole(roles ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aceextension/identity/models"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// RoleGuest is the token role of delegated guest access (external accountants/auditors)
+const RoleGuest = "guest"
+
+// GuestGuard authorizes and records guest requests; implemented by service.GuestAccessService
+type GuestGuard interface {
+	AuthorizeGuest(ctx context.Context, userID, tenantID uuid.UUID, method, path, rawQuery string) (uuid.UUID, string, error)
+	RecordGuestAccess(ctx context.Context, entry *models.GuestAccessLog)
+}
+
+var guestGuard GuestGuard
+
+// SetGuestGuard registers the guard applied to every guest token by JWTMiddleware
+func SetGuestGuard(guard GuestGuard) {
+	guestGuard = guard
+}
+
+// guardGuest enforces expiry and the permission set on each guest request and
+// writes every viewed, exported or denied request to the guest access trail.
+// Without a registered guard, guest tokens are rejected.
+func guardGuest(c echo.Context, user AuthUser, next echo.HandlerFunc) error {
+	if guestGuard == nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "guest access is not enabled"})
+	}
+
+	userID, err := uuid.Parse(user.UserID)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user id"})
+	}
+	tenantID, err := uuid.Parse(user.TenantID)
+	if err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "guest token has no tenant"})
+	}
+
+	req := c.Request()
+	membershipID, action, err := guestGuard.AuthorizeGuest(req.Context(), userID, tenantID, req.Method, req.URL.Path, req.URL.RawQuery)
+	if err != nil {
+		if membershipID != uuid.Nil {
+			recordGuestRequest(c, userID, tenantID, membershipID, "denied", http.StatusForbidden)
+		}
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+
+	handlerErr := next(c)
+
+	status := c.Response().Status
+	if handlerErr != nil {
+		if he, ok := handlerErr.(*echo.HTTPError); ok {
+			status = he.Code
+		} else {
+			status = http.StatusInternalServerError
+		}
+	}
+	recordGuestRequest(c, userID, tenantID, membershipID, action, status)
+
+	return handlerErr
+}
+
+func recordGuestRequest(c echo.Context, userID, tenantID, membershipID uuid.UUID, action string, status int) {
+	req := c.Request()
+	entry := &models.GuestAccessLog{
+		TenantID:     tenantID,
+		MembershipID: membershipID,
+		UserID:       userID,
+		Action:       action,
+		Method:       req.Method,
+		Path:         req.URL.Path,
+		StatusCode:   status,
+	}
+	if req.URL.RawQuery != "" {
+		query := req.URL.RawQuery
+		entry.Query = &query
+	}
+	if ip := c.RealIP(); ip != "" {
+		entry.IPAddress = &ip
+	}
+	if ua := req.UserAgent(); ua != "" {
+		entry.UserAgent = &ua
+	}
+
+	guestGuard.RecordGuestAccess(context.WithoutCancel(req.Context()), entry)
+}
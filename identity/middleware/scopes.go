@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Scope actions. A scope is "<action>:<area>", e.g. read:catalog or write:sales;
+// write includes read, and the area "*" covers every area (read:* is a read-only token)
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAll   = "*"
+)
+
+// ScopeAreas are the API areas a scope can name
+var ScopeAreas = map[string]string{
+	"catalog":       "Products, categories, units, taxes and pricing",
+	"sales":         "Invoices",
+	"crm":           "Customers, suppliers and purchase bills",
+	"purchasing":    "Purchase orders and goods receipts",
+	"inventory":     "Warehouses and stock",
+	"accounting":    "Chart of accounts and journals",
+	"fiscal":        "Fiscal years and document numbering",
+	"analytics":     "Reports and dashboards",
+	"users":         "Users and invitations",
+	"settings":      "Domains, branding and guest access",
+	"integrations":  "Integration credentials",
+	"api_keys":      "API keys",
+	"subscriptions": "Plan, subscription and billing invoices",
+	"audit":         "Audit trail and digest",
+	"notifications": "Notifications, templates and failover policies",
+	"presence":      "Who is viewing and editing records, and edit locks",
+	"jobs":          "Status and results of background exports and reports",
+	"admin":         "Platform administration, for super admins",
+}
+
+// ScopeNames lists every grantable scope in a stable order
+func ScopeNames() []string {
+	areas := make([]string, 0, len(ScopeAreas))
+	for area := range ScopeAreas {
+		areas = append(areas, area)
+	}
+	sort.Strings(areas)
+
+	names := make([]string, 0, 2*len(areas)+2)
+	names = append(names, ScopeRead+":"+ScopeAll, ScopeWrite+":"+ScopeAll)
+	for _, area := range areas {
+		names = append(names, ScopeRead+":"+area, ScopeWrite+":"+area)
+	}
+	return names
+}
+
+// ValidScope reports whether the scope names a known action and area
+func ValidScope(scope string) bool {
+	action, area, ok := strings.Cut(scope, ":")
+	if !ok || (action != ScopeRead && action != ScopeWrite) {
+		return false
+	}
+	if area == ScopeAll {
+		return true
+	}
+	_, ok = ScopeAreas[area]
+	return ok
+}
+
+// ScopesAllow reports whether the granted scopes allow the action on the area
+func ScopesAllow(granted []string, action, area string) bool {
+	for _, scope := range granted {
+		grantedAction, grantedArea, _ := strings.Cut(scope, ":")
+		if grantedArea != area && grantedArea != ScopeAll {
+			continue
+		}
+		if grantedAction == action || grantedAction == ScopeWrite {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopesCover reports whether the granted scopes allow everything the requested ones do
+func ScopesCover(granted, requested []string) bool {
+	for _, scope := range requested {
+		action, area, _ := strings.Cut(scope, ":")
+		if area == ScopeAll {
+			// Only a wildcard grant covers a wildcard
+			if !ScopesAllow(wildcards(granted), action, ScopeAll) {
+				return false
+			}
+			continue
+		}
+		if !ScopesAllow(granted, action, area) {
+			return false
+		}
+	}
+	return true
+}
+
+func wildcards(scopes []string) []string {
+	var all []string
+	for _, scope := range scopes {
+		if strings.HasSuffix(scope, ":"+ScopeAll) {
+			all = append(all, scope)
+		}
+	}
+	return all
+}
+
+// Allows reports whether the caller's token may take the action on the area.
+// Tokens without scopes are limited only by role.
+func (u AuthUser) Allows(action, area string) bool {
+	return u.Scopes == nil || ScopesAllow(u.Scopes, action, area)
+}
+
+// RequireScope checks the caller's token scopes for the area: safe methods need
+// read:<area>, everything else write:<area>. It runs after JWTMiddleware and
+// RequireRole, narrowing what the role already permits.
+func RequireScope(area string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userInterface := c.Get("user")
+			if userInterface == nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			}
+
+			action := ScopeWrite
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				action = ScopeRead
+			}
+
+			if !userInterface.(AuthUser).Allows(action, area) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "token is missing scope " + action + ":" + area})
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aceextension/identity/models"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// SupportGuard authorizes and records requests made in support sessions;
+// implemented by service.SupportSessionService
+type SupportGuard interface {
+	AuthorizeSupport(ctx context.Context, sessionID, userID, tenantID uuid.UUID) error
+	RecordSupportAccess(ctx context.Context, entry *models.SupportAccessLog)
+}
+
+var supportGuard SupportGuard
+
+// SetSupportGuard registers the guard applied to every support session token by JWTMiddleware
+func SetSupportGuard(guard SupportGuard) {
+	supportGuard = guard
+}
+
+// guardSupport keeps support sessions read-only: each request is checked against the
+// session and the tenant's consent, reads are recorded as viewed and anything else is
+// refused and recorded as blocked. Without a registered guard, support tokens are rejected.
+func guardSupport(c echo.Context, user AuthUser, next echo.HandlerFunc) error {
+	if supportGuard == nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "support sessions are not enabled"})
+	}
+
+	sessionID, err := uuid.Parse(user.SupportSessionID)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid support session"})
+	}
+	userID, err := uuid.Parse(user.UserID)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid user id"})
+	}
+	tenantID, err := uuid.Parse(user.TenantID)
+	if err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "support token has no tenant"})
+	}
+
+	req := c.Request()
+	if err := supportGuard.AuthorizeSupport(req.Context(), sessionID, userID, tenantID); err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		recordSupportRequest(c, sessionID, userID, tenantID, "blocked", http.StatusForbidden)
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "support sessions are read-only"})
+	}
+
+	handlerErr := next(c)
+
+	status := c.Response().Status
+	if handlerErr != nil {
+		if he, ok := handlerErr.(*echo.HTTPError); ok {
+			status = he.Code
+		} else {
+			status = http.StatusInternalServerError
+		}
+	}
+	recordSupportRequest(c, sessionID, userID, tenantID, "view", status)
+
+	return handlerErr
+}
+
+func recordSupportRequest(c echo.Context, sessionID, userID, tenantID uuid.UUID, action string, status int) {
+	req := c.Request()
+	entry := &models.SupportAccessLog{
+		SessionID:     sessionID,
+		TenantID:      tenantID,
+		SupportUserID: userID,
+		Action:        action,
+		Method:        req.Method,
+		Path:          req.URL.Path,
+		StatusCode:    status,
+	}
+	if req.URL.RawQuery != "" {
+		query := req.URL.RawQuery
+		entry.Query = &query
+	}
+	if ip := c.RealIP(); ip != "" {
+		entry.IPAddress = &ip
+	}
+	if ua := req.UserAgent(); ua != "" {
+		entry.UserAgent = &ua
+	}
+
+	supportGuard.RecordSupportAccess(context.WithoutCancel(req.Context()), entry)
+}
@@ -0,0 +1,21 @@
+-- Tenant Domains
+-- Maps subdomains (stored as the bare label) and custom domains (full hostname) to tenants.
+-- Not covered by RLS: the lookup runs before the tenant is known.
+-- Tenant-facing queries always filter by tenant_id explicitly.
+CREATE TABLE IF NOT EXISTS tenant_domains (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    domain VARCHAR(253) NOT NULL,
+    kind VARCHAR(20) NOT NULL, -- subdomain, custom
+    verification_token VARCHAR(64) NOT NULL,
+    is_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT fk_tenant_domain_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT chk_tenant_domain_kind CHECK (kind IN ('subdomain', 'custom')),
+    CONSTRAINT uq_tenant_domain UNIQUE (domain)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_domains_tenant ON tenant_domains(tenant_id);
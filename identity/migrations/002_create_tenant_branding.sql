@@ -0,0 +1,18 @@
+-- Tenant Branding (white-label settings)
+-- Read by the public branding endpoint before login, so lookups are keyed by tenant_id explicitly.
+CREATE TABLE IF NOT EXISTS tenant_branding (
+    tenant_id UUID PRIMARY KEY,
+    display_name VARCHAR(150),
+    logo_url VARCHAR(500),
+    favicon_url VARCHAR(500),
+    primary_color VARCHAR(7) NOT NULL DEFAULT '#1E40AF',
+    secondary_color VARCHAR(7) NOT NULL DEFAULT '#64748B',
+    accent_color VARCHAR(7) NOT NULL DEFAULT '#F59E0B',
+    document_header TEXT,
+    document_footer TEXT,
+    email_from_name VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT fk_tenant_branding_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
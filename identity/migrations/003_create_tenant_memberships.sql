@@ -0,0 +1,26 @@
+-- Tenant Memberships
+-- Lets one user (e.g. an accountant) work in several tenants with a role per tenant.
+-- users.tenant_id stays the home tenant used at login.
+CREATE TABLE IF NOT EXISTS tenant_memberships (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    tenant_id UUID NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT fk_membership_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_membership_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT uq_membership_user_tenant UNIQUE (user_id, tenant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_memberships_tenant ON tenant_memberships(tenant_id);
+
+-- Backfill home tenants so every user has a membership row
+INSERT INTO tenant_memberships (user_id, tenant_id, role)
+SELECT id, tenant_id, role FROM users WHERE tenant_id IS NOT NULL
+ON CONFLICT (user_id, tenant_id) DO NOTHING;
+
+-- Sessions remember the tenant they were switched to so refresh keeps the scope
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS tenant_id UUID;
@@ -0,0 +1,30 @@
+-- Guest (delegated) access
+-- Time-boxed memberships for external accountants/auditors with a restricted permission set.
+ALTER TABLE tenant_memberships ADD COLUMN IF NOT EXISTS is_guest BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tenant_memberships ADD COLUMN IF NOT EXISTS permissions JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE tenant_memberships ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tenant_memberships ADD COLUMN IF NOT EXISTS granted_by UUID;
+ALTER TABLE tenant_memberships ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_tenant_memberships_guest_expiry
+    ON tenant_memberships(expires_at) WHERE is_guest = true AND is_active = true;
+
+-- Everything a guest viewed or exported (append-only)
+CREATE TABLE IF NOT EXISTS guest_access_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    membership_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL, -- view, export, denied
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    query TEXT,
+    status_code INT NOT NULL,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT fk_guest_access_membership FOREIGN KEY (membership_id) REFERENCES tenant_memberships(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_guest_access_logs_membership ON guest_access_logs(tenant_id, membership_id, created_at DESC);
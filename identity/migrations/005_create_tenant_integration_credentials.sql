@@ -0,0 +1,25 @@
+-- Tenant Integration Credentials
+-- One row per tenant and third-party integration (cbms, sms, esewa, khalti). The field
+-- values are a JSON object sealed with AES-256-GCM under a CREDENTIAL_KEYS key; key_id
+-- records which key so rows can be re-sealed when the key is rotated.
+-- Not covered by RLS: connectors read credentials outside tenant-scoped transactions.
+-- Tenant-facing queries always filter by tenant_id explicitly.
+CREATE TABLE IF NOT EXISTS tenant_integration_credentials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    integration VARCHAR(50) NOT NULL,
+    sealed_values TEXT NOT NULL,
+    sealed_previous_values TEXT,
+    previous_expires_at TIMESTAMP WITH TIME ZONE,
+    key_id VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    rotated_at TIMESTAMP WITH TIME ZONE,
+    updated_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT fk_tenant_integration_credential_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT uq_tenant_integration_credential UNIQUE (tenant_id, integration)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_integration_credentials_key ON tenant_integration_credentials(key_id);
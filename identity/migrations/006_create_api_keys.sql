@@ -0,0 +1,29 @@
+-- API Keys and Token Scopes
+-- Sessions remember the scopes a login asked for so refreshed tokens keep them;
+-- NULL is an unscoped session.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS scopes JSONB;
+
+-- Tenant API keys. Only the SHA-256 hash of a key is stored; the key acts as its
+-- creator with the role the creator had, limited to its scopes (read:catalog, write:*).
+-- Not covered by RLS: keys are looked up by hash before the tenant is known.
+-- Tenant-facing queries always filter by tenant_id explicitly.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    scopes JSONB NOT NULL DEFAULT '[]'::jsonb,
+    role VARCHAR(50) NOT NULL,
+    created_by UUID NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT fk_api_key_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT fk_api_key_creator FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT uq_api_key_hash UNIQUE (key_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys(tenant_id, created_at DESC);
@@ -0,0 +1,47 @@
+-- Support shadowing
+-- Platform support staff can view a tenant as its owner does, read-only, once the
+-- tenant has allowed it. Turning consent off ends open sessions on their next request.
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS support_access_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS support_access_changed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS support_access_changed_by UUID;
+
+-- Read-only sessions opened by super admins. Not covered by RLS: the session is
+-- checked before the tenant context is trusted, and tenant-facing queries filter
+-- by tenant_id explicitly.
+CREATE TABLE IF NOT EXISTS support_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    support_user_id UUID NOT NULL,
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    ended_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT fk_support_session_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT fk_support_session_user FOREIGN KEY (support_user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_support_sessions_tenant ON support_sessions(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_support_sessions_open ON support_sessions(tenant_id) WHERE ended_at IS NULL;
+
+-- Every request made in a support session (append-only): reads as view,
+-- mutation attempts as blocked
+CREATE TABLE IF NOT EXISTS support_access_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL,
+    tenant_id UUID NOT NULL,
+    support_user_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL, -- view, blocked
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    query TEXT,
+    status_code INT NOT NULL,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT fk_support_access_session FOREIGN KEY (session_id) REFERENCES support_sessions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_support_access_logs_session ON support_access_logs(tenant_id, session_id, created_at DESC);
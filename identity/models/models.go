@@ -26,6 +26,7 @@ type Tenant struct {
 	KybDocumentURL     *string    `json:"kybDocumentUrl" db:"kyb_document_url"`
 	VerifiedAt         *time.Time `json:"verifiedAt" db:"verified_at"`
 	IsActive           bool       `json:"isActive" db:"is_active"`
+	DataRegion         string     `json:"dataRegion" db:"data_region"` // Where the tenant's data is stored; fixed at registration
 	CreatedAt          time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt          time.Time  `json:"updatedAt" db:"updated_at"`
 }
@@ -50,13 +51,15 @@ type User struct {
 
 // Session represents the sessions table
 type Session struct {
-	ID                uuid.UUID `json:"id" db:"id"`
-	UserID            uuid.UUID `json:"userId" db:"user_id"`
-	RefreshToken      string    `json:"refreshToken" db:"refresh_token"`
-	DeviceFingerprint *string   `json:"deviceFingerprint" db:"device_fingerprint"`
-	IPAddress         *string   `json:"ipAddress" db:"ip_address"`
-	ExpiresAt         time.Time `json:"expiresAt" db:"expires_at"`
-	CreatedAt         time.Time `json:"createdAt" db:"created_at"`
+	ID                uuid.UUID  `json:"id" db:"id"`
+	UserID            uuid.UUID  `json:"userId" db:"user_id"`
+	TenantID          *uuid.UUID `json:"tenantId" db:"tenant_id"` // Tenant the session was switched to; nil means the user's home tenant
+	RefreshToken      string     `json:"refreshToken" db:"refresh_token"`
+	DeviceFingerprint *string    `json:"deviceFingerprint" db:"device_fingerprint"`
+	IPAddress         *string    `json:"ipAddress" db:"ip_address"`
+	Scopes            []string   `json:"scopes" db:"scopes"` // Scopes the session's tokens are limited to; nil is unscoped
+	ExpiresAt         time.Time  `json:"expiresAt" db:"expires_at"`
+	CreatedAt         time.Time  `json:"createdAt" db:"created_at"`
 }
 
 // Invitation represents the invitations table
@@ -71,3 +74,164 @@ type Invitation struct {
 	Status    string    `json:"status" db:"status"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
+
+// TenantDomain represents the tenant_domains table.
+// Subdomains are stored as the bare label ("acme" for acme.<platform domain>),
+// custom domains as the full hostname ("shop.acme.com").
+type TenantDomain struct {
+	ID                uuid.UUID  `json:"id" db:"id"`
+	TenantID          uuid.UUID  `json:"tenantId" db:"tenant_id"`
+	Domain            string     `json:"domain" db:"domain"`
+	Kind              string     `json:"kind" db:"kind"` // subdomain, custom
+	VerificationToken string     `json:"verificationToken" db:"verification_token"`
+	IsVerified        bool       `json:"isVerified" db:"is_verified"`
+	VerifiedAt        *time.Time `json:"verifiedAt" db:"verified_at"`
+	CreatedAt         time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// TenantBranding represents the tenant_branding table (white-label settings)
+type TenantBranding struct {
+	TenantID       uuid.UUID `json:"tenantId" db:"tenant_id"`
+	DisplayName    *string   `json:"displayName" db:"display_name"`
+	LogoURL        *string   `json:"logoUrl" db:"logo_url"`
+	FaviconURL     *string   `json:"faviconUrl" db:"favicon_url"`
+	PrimaryColor   string    `json:"primaryColor" db:"primary_color"`
+	SecondaryColor string    `json:"secondaryColor" db:"secondary_color"`
+	AccentColor    string    `json:"accentColor" db:"accent_color"`
+	DocumentHeader *string   `json:"documentHeader" db:"document_header"`
+	DocumentFooter *string   `json:"documentFooter" db:"document_footer"`
+	EmailFromName  *string   `json:"emailFromName" db:"email_from_name"`
+	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// TenantMembership represents the tenant_memberships table.
+// A user's home tenant is users.tenant_id; memberships grant access to additional tenants.
+type TenantMembership struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	UserID     uuid.UUID `json:"userId" db:"user_id"`
+	TenantID   uuid.UUID `json:"tenantId" db:"tenant_id"`
+	TenantName string    `json:"tenantName" db:"tenant_name"` // Joined from tenants
+	Role       string    `json:"role" db:"role"`
+	IsActive   bool      `json:"isActive" db:"is_active"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" db:"updated_at"`
+
+	// Guest (delegated) access, e.g. an external auditor with read-only access for a month
+	IsGuest     bool       `json:"isGuest" db:"is_guest"`
+	Permissions []string   `json:"permissions" db:"permissions"` // Module scopes plus "export"
+	ExpiresAt   *time.Time `json:"expiresAt" db:"expires_at"`
+	GrantedBy   *uuid.UUID `json:"grantedBy" db:"granted_by"`
+	RevokedAt   *time.Time `json:"revokedAt" db:"revoked_at"`
+}
+
+// IsUsable reports whether the membership currently grants access
+func (m *TenantMembership) IsUsable(now time.Time) bool {
+	if !m.IsActive || m.RevokedAt != nil {
+		return false
+	}
+	return m.ExpiresAt == nil || now.Before(*m.ExpiresAt)
+}
+
+// GuestAccessLog represents the guest_access_logs table: every request a guest made
+type GuestAccessLog struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	TenantID     uuid.UUID `json:"tenantId" db:"tenant_id"`
+	MembershipID uuid.UUID `json:"membershipId" db:"membership_id"`
+	UserID       uuid.UUID `json:"userId" db:"user_id"`
+	Action       string    `json:"action" db:"action"` // view, export, denied
+	Method       string    `json:"method" db:"method"`
+	Path         string    `json:"path" db:"path"`
+	Query        *string   `json:"query" db:"query"`
+	StatusCode   int       `json:"statusCode" db:"status_code"`
+	IPAddress    *string   `json:"ipAddress" db:"ip_address"`
+	UserAgent    *string   `json:"userAgent" db:"user_agent"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// TenantIntegrationCredential represents the tenant_integration_credentials table.
+// Values are a JSON object of the integration's fields sealed with the credential cipher.
+type TenantIntegrationCredential struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	TenantID    uuid.UUID `json:"tenantId" db:"tenant_id"`
+	Integration string    `json:"integration" db:"integration"`
+	Values      string    `json:"-" db:"sealed_values"`
+	// Values replaced by the last rotation, accepted until PreviousExpiresAt
+	PreviousValues    *string    `json:"-" db:"sealed_previous_values"`
+	PreviousExpiresAt *time.Time `json:"previousExpiresAt" db:"previous_expires_at"`
+	KeyID             string     `json:"keyId" db:"key_id"` // Encryption key the values are sealed under
+	Version           int        `json:"version" db:"version"`
+	RotatedAt         *time.Time `json:"rotatedAt" db:"rotated_at"`
+	UpdatedBy         *uuid.UUID `json:"updatedBy" db:"updated_by"`
+	CreatedAt         time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// APIKey represents the api_keys table. Only the SHA-256 hash of the key is stored;
+// the key acts as its creator with the creator's role, limited to its scopes.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	TenantID   uuid.UUID  `json:"tenantId" db:"tenant_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"key_prefix"` // Start of the key, to recognise it
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	Role       string     `json:"role" db:"role"`
+	CreatedBy  uuid.UUID  `json:"createdBy" db:"created_by"`
+	ExpiresAt  *time.Time `json:"expiresAt" db:"expires_at"`
+	LastUsedAt *time.Time `json:"lastUsedAt" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revokedAt" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// IsUsable reports whether the key is neither revoked nor expired
+func (k *APIKey) IsUsable(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// SupportConsent is a tenant's support_access_* columns: whether platform support
+// may open read-only sessions on the tenant
+type SupportConsent struct {
+	TenantID  uuid.UUID  `json:"tenantId" db:"id"`
+	Enabled   bool       `json:"enabled" db:"support_access_enabled"`
+	ChangedAt *time.Time `json:"changedAt" db:"support_access_changed_at"`
+	ChangedBy *uuid.UUID `json:"changedBy" db:"support_access_changed_by"`
+}
+
+// SupportSession represents the support_sessions table: a super admin viewing a
+// tenant read-only, with the tenant's consent
+type SupportSession struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	TenantID      uuid.UUID  `json:"tenantId" db:"tenant_id"`
+	SupportUserID uuid.UUID  `json:"supportUserId" db:"support_user_id"`
+	Reason        string     `json:"reason" db:"reason"`
+	ExpiresAt     time.Time  `json:"expiresAt" db:"expires_at"`
+	EndedAt       *time.Time `json:"endedAt" db:"ended_at"`
+	EndedBy       *uuid.UUID `json:"endedBy" db:"ended_by"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// IsOpen reports whether the session is neither ended nor expired
+func (s *SupportSession) IsOpen(now time.Time) bool {
+	return s.EndedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// SupportAccessLog represents the support_access_logs table: every request made in a support session
+type SupportAccessLog struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	SessionID     uuid.UUID `json:"sessionId" db:"session_id"`
+	TenantID      uuid.UUID `json:"tenantId" db:"tenant_id"`
+	SupportUserID uuid.UUID `json:"supportUserId" db:"support_user_id"`
+	Action        string    `json:"action" db:"action"` // view, blocked
+	Method        string    `json:"method" db:"method"`
+	Path          string    `json:"path" db:"path"`
+	Query         *string   `json:"query" db:"query"`
+	StatusCode    int       `json:"statusCode" db:"status_code"`
+	IPAddress     *string   `json:"ipAddress" db:"ip_address"`
+	UserAgent     *string   `json:"userAgent" db:"user_agent"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+}
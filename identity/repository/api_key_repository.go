@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/identity/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type APIKeyRepository interface {
+	CreateKey(ctx context.Context, key *models.APIKey) error
+	GetKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	ListKeys(ctx context.Context, tenantID uuid.UUID) ([]models.APIKey, error)
+	// RevokeKey revokes an unrevoked key; false means there is no such key
+	RevokeKey(ctx context.Context, tenantID, id uuid.UUID, revokedAt time.Time) (bool, error)
+	// TouchKey records a use of the key, at most once a minute
+	TouchKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+}
+
+type pgAPIKeyRepository struct{}
+
+func NewAPIKeyRepository() APIKeyRepository {
+	return &pgAPIKeyRepository{}
+}
+
+const apiKeyColumns = `id, tenant_id, name, key_prefix, key_hash, scopes, role, created_by, expires_at, last_used_at, revoked_at, created_at`
+
+func (r *pgAPIKeyRepository) CreateKey(ctx context.Context, k *models.APIKey) error {
+	scopesJSON, err := json.Marshal(k.Scopes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO api_keys (tenant_id, name, key_prefix, key_hash, scopes, role, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+
+	return db.MainPool.QueryRow(ctx, query,
+		k.TenantID, k.Name, k.Prefix, k.KeyHash, scopesJSON, k.Role, k.CreatedBy, k.ExpiresAt,
+	).Scan(&k.ID, &k.CreatedAt)
+}
+
+func (r *pgAPIKeyRepository) GetKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+	return r.scanKey(db.MainPool.QueryRow(ctx, query, keyHash))
+}
+
+func (r *pgAPIKeyRepository) ListKeys(ctx context.Context, tenantID uuid.UUID) ([]models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE tenant_id = $1 ORDER BY created_at DESC`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		k, err := r.scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *pgAPIKeyRepository) RevokeKey(ctx context.Context, tenantID, id uuid.UUID, revokedAt time.Time) (bool, error) {
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND tenant_id = $3 AND revoked_at IS NULL`
+	tag, err := db.MainPool.Exec(ctx, query, revokedAt, id, tenantID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *pgAPIKeyRepository) TouchKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	query := `
+		UPDATE api_keys SET last_used_at = $1
+		WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $1 - INTERVAL '1 minute')`
+	_, err := db.MainPool.Exec(ctx, query, usedAt, id)
+	return err
+}
+
+func (r *pgAPIKeyRepository) scanKey(row pgx.Row) (*models.APIKey, error) {
+	var k models.APIKey
+	var scopesJSON []byte
+	err := row.Scan(
+		&k.ID, &k.TenantID, &k.Name, &k.Prefix, &k.KeyHash, &scopesJSON, &k.Role, &k.CreatedBy,
+		&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopesJSON, &k.Scopes); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
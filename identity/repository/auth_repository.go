@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/aceextension/core/db"
@@ -175,13 +176,21 @@ func (r *pgAuthRepository) GetUsersByTenantID(ctx context.Context, tenantID uuid
 }
 
 func (r *pgAuthRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	var scopesJSON []byte
+	if session.Scopes != nil {
+		var err error
+		if scopesJSON, err = json.Marshal(session.Scopes); err != nil {
+			return err
+		}
+	}
+
 	query := `
-		INSERT INTO sessions (user_id, refresh_token, device_fingerprint, ip_address, expires_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO sessions (user_id, tenant_id, refresh_token, device_fingerprint, ip_address, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at`
 	return r.getExecutor().QueryRow(ctx, query,
-		session.UserID, session.RefreshToken, session.DeviceFingerprint,
-		session.IPAddress, session.ExpiresAt,
+		session.UserID, session.TenantID, session.RefreshToken, session.DeviceFingerprint,
+		session.IPAddress, scopesJSON, session.ExpiresAt,
 	).Scan(&session.ID, &session.CreatedAt)
 }
 
@@ -192,15 +201,21 @@ func (r *pgAuthRepository) DeleteSession(ctx context.Context, userID uuid.UUID,
 }
 
 func (r *pgAuthRepository) GetSessionByToken(ctx context.Context, refreshToken string) (*models.Session, error) {
-	query := `SELECT id, user_id, refresh_token, device_fingerprint, ip_address, expires_at, created_at FROM sessions WHERE refresh_token = $1`
+	query := `SELECT id, user_id, tenant_id, refresh_token, device_fingerprint, ip_address, scopes, expires_at, created_at FROM sessions WHERE refresh_token = $1`
 	var session models.Session
+	var scopesJSON []byte
 	err := r.getExecutor().QueryRow(ctx, query, refreshToken).Scan(
-		&session.ID, &session.UserID, &session.RefreshToken, &session.DeviceFingerprint,
-		&session.IPAddress, &session.ExpiresAt, &session.CreatedAt,
+		&session.ID, &session.UserID, &session.TenantID, &session.RefreshToken, &session.DeviceFingerprint,
+		&session.IPAddress, &scopesJSON, &session.ExpiresAt, &session.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if scopesJSON != nil {
+		if err := json.Unmarshal(scopesJSON, &session.Scopes); err != nil {
+			return nil, err
+		}
+	}
 	return &session, nil
 }
 
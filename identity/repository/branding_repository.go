@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/identity/models"
+	"github.com/google/uuid"
+)
+
+type BrandingRepository interface {
+	GetBranding(ctx context.Context, tenantID uuid.UUID) (*models.TenantBranding, error)
+	UpsertBranding(ctx context.Context, branding *models.TenantBranding) error
+}
+
+type pgBrandingRepository struct{}
+
+func NewBrandingRepository() BrandingRepository {
+	return &pgBrandingRepository{}
+}
+
+func (r *pgBrandingRepository) GetBranding(ctx context.Context, tenantID uuid.UUID) (*models.TenantBranding, error) {
+	query := `
+		SELECT tenant_id, display_name, logo_url, favicon_url, primary_color, secondary_color, accent_color,
+		       document_header, document_footer, email_from_name, created_at, updated_at
+		FROM tenant_branding WHERE tenant_id = $1`
+	var b models.TenantBranding
+	err := db.MainPool.QueryRow(ctx, query, tenantID).Scan(
+		&b.TenantID, &b.DisplayName, &b.LogoURL, &b.FaviconURL, &b.PrimaryColor, &b.SecondaryColor, &b.AccentColor,
+		&b.DocumentHeader, &b.DocumentFooter, &b.EmailFromName, &b.CreatedAt, &b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *pgBrandingRepository) UpsertBranding(ctx context.Context, b *models.TenantBranding) error {
+	query := `
+		INSERT INTO tenant_branding (
+			tenant_id, display_name, logo_url, favicon_url, primary_color, secondary_color, accent_color,
+			document_header, document_footer, email_from_name
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			display_name = EXCLUDED.display_name,
+			logo_url = EXCLUDED.logo_url,
+			favicon_url = EXCLUDED.favicon_url,
+			primary_color = EXCLUDED.primary_color,
+			secondary_color = EXCLUDED.secondary_color,
+			accent_color = EXCLUDED.accent_color,
+			document_header = EXCLUDED.document_header,
+			document_footer = EXCLUDED.document_footer,
+			email_from_name = EXCLUDED.email_from_name,
+			updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	return db.MainPool.QueryRow(ctx, query,
+		b.TenantID, b.DisplayName, b.LogoURL, b.FaviconURL, b.PrimaryColor, b.SecondaryColor, b.AccentColor,
+		b.DocumentHeader, b.DocumentFooter, b.EmailFromName,
+	).Scan(&b.CreatedAt, &b.UpdatedAt)
+}
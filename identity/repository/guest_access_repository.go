@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/identity/models"
+	"github.com/google/uuid"
+)
+
+type GuestAccessRepository interface {
+	RecordAccess(ctx context.Context, entry *models.GuestAccessLog) error
+	ListAccess(ctx context.Context, tenantID, membershipID uuid.UUID, limit, offset int) ([]models.GuestAccessLog, error)
+}
+
+type pgGuestAccessRepository struct{}
+
+func NewGuestAccessRepository() GuestAccessRepository {
+	return &pgGuestAccessRepository{}
+}
+
+func (r *pgGuestAccessRepository) RecordAccess(ctx context.Context, e *models.GuestAccessLog) error {
+	query := `
+		INSERT INTO guest_access_logs (tenant_id, membership_id, user_id, action, method, path, query, status_code, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`
+
+	return db.MainPool.QueryRow(ctx, query,
+		e.TenantID, e.MembershipID, e.UserID, e.Action, e.Method, e.Path, e.Query, e.StatusCode, e.IPAddress, e.UserAgent,
+	).Scan(&e.ID, &e.CreatedAt)
+}
+
+func (r *pgGuestAccessRepository) ListAccess(ctx context.Context, tenantID, membershipID uuid.UUID, limit, offset int) ([]models.GuestAccessLog, error) {
+	query := `
+		SELECT id, tenant_id, membership_id, user_id, action, method, path, query, status_code, ip_address, user_agent, created_at
+		FROM guest_access_logs
+		WHERE tenant_id = $1 AND membership_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, membershipID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.GuestAccessLog{}
+	for rows.Next() {
+		var e models.GuestAccessLog
+		if err := rows.Scan(
+			&e.ID, &e.TenantID, &e.MembershipID, &e.UserID, &e.Action, &e.Method, &e.Path, &e.Query,
+			&e.StatusCode, &e.IPAddress, &e.UserAgent, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/identity/models"
+	"github.com/google/uuid"
+)
+
+type IntegrationCredentialRepository interface {
+	GetCredential(ctx context.Context, tenantID uuid.UUID, integration string) (*models.TenantIntegrationCredential, error)
+	ListCredentials(ctx context.Context, tenantID uuid.UUID) ([]models.TenantIntegrationCredential, error)
+	// SaveCredential inserts the credential when expectedVersion is 0, otherwise updates it
+	// only if it is still at expectedVersion; pgx.ErrNoRows means it changed meanwhile
+	SaveCredential(ctx context.Context, credential *models.TenantIntegrationCredential, expectedVersion int) error
+	DeleteCredential(ctx context.Context, tenantID uuid.UUID, integration string) (bool, error)
+	// ListCredentialsNotSealedWith returns credentials sealed under a key other than keyID, by ID after the given one
+	ListCredentialsNotSealedWith(ctx context.Context, keyID string, after uuid.UUID, limit int) ([]models.TenantIntegrationCredential, error)
+	// ResealCredential replaces the sealed values without changing the version, if it is unchanged
+	ResealCredential(ctx context.Context, credential *models.TenantIntegrationCredential) (bool, error)
+}
+
+type pgIntegrationCredentialRepository struct{}
+
+func NewIntegrationCredentialRepository() IntegrationCredentialRepository {
+	return &pgIntegrationCredentialRepository{}
+}
+
+const integrationCredentialColumns = `id, tenant_id, integration, sealed_values, sealed_previous_values, previous_expires_at,
+	key_id, version, rotated_at, updated_by, created_at, updated_at`
+
+type credentialScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanIntegrationCredential(row credentialScanner) (*models.TenantIntegrationCredential, error) {
+	var c models.TenantIntegrationCredential
+	err := row.Scan(
+		&c.ID, &c.TenantID, &c.Integration, &c.Values, &c.PreviousValues, &c.PreviousExpiresAt,
+		&c.KeyID, &c.Version, &c.RotatedAt, &c.UpdatedBy, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *pgIntegrationCredentialRepository) GetCredential(ctx context.Context, tenantID uuid.UUID, integration string) (*models.TenantIntegrationCredential, error) {
+	query := `SELECT ` + integrationCredentialColumns + ` FROM tenant_integration_credentials WHERE tenant_id = $1 AND integration = $2`
+	return scanIntegrationCredential(db.MainPool.QueryRow(ctx, query, tenantID, integration))
+}
+
+func (r *pgIntegrationCredentialRepository) ListCredentials(ctx context.Context, tenantID uuid.UUID) ([]models.TenantIntegrationCredential, error) {
+	query := `SELECT ` + integrationCredentialColumns + ` FROM tenant_integration_credentials WHERE tenant_id = $1 ORDER BY integration`
+	return r.list(ctx, query, tenantID)
+}
+
+func (r *pgIntegrationCredentialRepository) SaveCredential(ctx context.Context, c *models.TenantIntegrationCredential, expectedVersion int) error {
+	if expectedVersion == 0 {
+		query := `
+			INSERT INTO tenant_integration_credentials (
+				tenant_id, integration, sealed_values, sealed_previous_values, previous_expires_at,
+				key_id, version, rotated_at, updated_by
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (tenant_id, integration) DO NOTHING
+			RETURNING id, created_at, updated_at`
+
+		return db.MainPool.QueryRow(ctx, query,
+			c.TenantID, c.Integration, c.Values, c.PreviousValues, c.PreviousExpiresAt,
+			c.KeyID, c.Version, c.RotatedAt, c.UpdatedBy,
+		).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	}
+
+	query := `
+		UPDATE tenant_integration_credentials SET
+			sealed_values = $1, sealed_previous_values = $2, previous_expires_at = $3,
+			key_id = $4, version = $5, rotated_at = $6, updated_by = $7, updated_at = NOW()
+		WHERE tenant_id = $8 AND integration = $9 AND version = $10
+		RETURNING id, created_at, updated_at`
+
+	return db.MainPool.QueryRow(ctx, query,
+		c.Values, c.PreviousValues, c.PreviousExpiresAt, c.KeyID, c.Version, c.RotatedAt, c.UpdatedBy,
+		c.TenantID, c.Integration, expectedVersion,
+	).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+}
+
+func (r *pgIntegrationCredentialRepository) DeleteCredential(ctx context.Context, tenantID uuid.UUID, integration string) (bool, error) {
+	query := `DELETE FROM tenant_integration_credentials WHERE tenant_id = $1 AND integration = $2`
+	tag, err := db.MainPool.Exec(ctx, query, tenantID, integration)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *pgIntegrationCredentialRepository) ListCredentialsNotSealedWith(ctx context.Context, keyID string, after uuid.UUID, limit int) ([]models.TenantIntegrationCredential, error) {
+	query := `SELECT ` + integrationCredentialColumns + ` FROM tenant_integration_credentials WHERE key_id <> $1 AND id > $2 ORDER BY id LIMIT $3`
+	return r.list(ctx, query, keyID, after, limit)
+}
+
+func (r *pgIntegrationCredentialRepository) ResealCredential(ctx context.Context, c *models.TenantIntegrationCredential) (bool, error) {
+	query := `
+		UPDATE tenant_integration_credentials SET sealed_values = $1, sealed_previous_values = $2, key_id = $3
+		WHERE id = $4 AND version = $5`
+	tag, err := db.MainPool.Exec(ctx, query, c.Values, c.PreviousValues, c.KeyID, c.ID, c.Version)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *pgIntegrationCredentialRepository) list(ctx context.Context, query string, args ...any) ([]models.TenantIntegrationCredential, error) {
+	rows, err := db.MainPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credentials := []models.TenantIntegrationCredential{}
+	for rows.Next() {
+		c, err := scanIntegrationCredential(rows)
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, *c)
+	}
+	return credentials, rows.Err()
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/identity/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type MembershipRepository interface {
+	ListMembershipsByUser(ctx context.Context, userID uuid.UUID) ([]models.TenantMembership, error)
+	GetMembership(ctx context.Context, userID, tenantID uuid.UUID) (*models.TenantMembership, error)
+	GetMembershipByID(ctx context.Context, id uuid.UUID) (*models.TenantMembership, error)
+	UpsertMembership(ctx context.Context, membership *models.TenantMembership) error
+
+	// Guest access
+	ListGuestsByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.TenantMembership, error)
+	RevokeMembership(ctx context.Context, tenantID, id uuid.UUID, revokedAt time.Time) error
+	// DeactivateExpiredGuests switches off guest memberships past their expiry and returns how many
+	DeactivateExpiredGuests(ctx context.Context, now time.Time) (int64, error)
+}
+
+type pgMembershipRepository struct{}
+
+func NewMembershipRepository() MembershipRepository {
+	return &pgMembershipRepository{}
+}
+
+const membershipSelect = `
+	SELECT m.id, m.user_id, m.tenant_id, COALESCE(t.business_name, t.name), m.role, m.is_active, m.created_at, m.updated_at,
+	       m.is_guest, m.permissions, m.expires_at, m.granted_by, m.revoked_at
+	FROM tenant_memberships m
+	JOIN tenants t ON t.id = m.tenant_id`
+
+func (r *pgMembershipRepository) ListMembershipsByUser(ctx context.Context, userID uuid.UUID) ([]models.TenantMembership, error) {
+	query := membershipSelect + `
+		WHERE m.user_id = $1 AND m.is_active = true AND m.revoked_at IS NULL
+		  AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND t.is_active = true
+		ORDER BY COALESCE(t.business_name, t.name)`
+
+	return r.queryMemberships(ctx, query, userID)
+}
+
+func (r *pgMembershipRepository) GetMembership(ctx context.Context, userID, tenantID uuid.UUID) (*models.TenantMembership, error) {
+	query := membershipSelect + ` WHERE m.user_id = $1 AND m.tenant_id = $2`
+	return r.scanMembership(db.MainPool.QueryRow(ctx, query, userID, tenantID))
+}
+
+func (r *pgMembershipRepository) GetMembershipByID(ctx context.Context, id uuid.UUID) (*models.TenantMembership, error) {
+	query := membershipSelect + ` WHERE m.id = $1`
+	return r.scanMembership(db.MainPool.QueryRow(ctx, query, id))
+}
+
+func (r *pgMembershipRepository) UpsertMembership(ctx context.Context, m *models.TenantMembership) error {
+	if m.Permissions == nil {
+		m.Permissions = []string{}
+	}
+	permissionsJSON, err := json.Marshal(m.Permissions)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO tenant_memberships (user_id, tenant_id, role, is_active, is_guest, permissions, expires_at, granted_by, revoked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULL)
+		ON CONFLICT (user_id, tenant_id) DO UPDATE SET
+			role = EXCLUDED.role,
+			is_active = EXCLUDED.is_active,
+			is_guest = EXCLUDED.is_guest,
+			permissions = EXCLUDED.permissions,
+			expires_at = EXCLUDED.expires_at,
+			granted_by = EXCLUDED.granted_by,
+			revoked_at = NULL,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at`
+
+	return db.MainPool.QueryRow(ctx, query,
+		m.UserID, m.TenantID, m.Role, m.IsActive, m.IsGuest, permissionsJSON, m.ExpiresAt, m.GrantedBy,
+	).Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt)
+}
+
+func (r *pgMembershipRepository) ListGuestsByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.TenantMembership, error) {
+	query := membershipSelect + ` WHERE m.tenant_id = $1 AND m.is_guest = true ORDER BY m.created_at DESC`
+	return r.queryMemberships(ctx, query, tenantID)
+}
+
+func (r *pgMembershipRepository) RevokeMembership(ctx context.Context, tenantID, id uuid.UUID, revokedAt time.Time) error {
+	query := `UPDATE tenant_memberships SET is_active = false, revoked_at = $1, updated_at = NOW() WHERE id = $2 AND tenant_id = $3`
+	_, err := db.MainPool.Exec(ctx, query, revokedAt, id, tenantID)
+	return err
+}
+
+func (r *pgMembershipRepository) DeactivateExpiredGuests(ctx context.Context, now time.Time) (int64, error) {
+	query := `UPDATE tenant_memberships SET is_active = false, updated_at = NOW() WHERE is_guest = true AND is_active = true AND expires_at <= $1`
+	tag, err := db.MainPool.Exec(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *pgMembershipRepository) queryMemberships(ctx context.Context, query string, args ...any) ([]models.TenantMembership, error) {
+	rows, err := db.MainPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []models.TenantMembership{}
+	for rows.Next() {
+		m, err := r.scanMembership(rows)
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, *m)
+	}
+	return memberships, rows.Err()
+}
+
+func (r *pgMembershipRepository) scanMembership(row pgx.Row) (*models.TenantMembership, error) {
+	var m models.TenantMembership
+	var permissionsJSON []byte
+	err := row.Scan(
+		&m.ID, &m.UserID, &m.TenantID, &m.TenantName, &m.Role, &m.IsActive, &m.CreatedAt, &m.UpdatedAt,
+		&m.IsGuest, &permissionsJSON, &m.ExpiresAt, &m.GrantedBy, &m.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(permissionsJSON, &m.Permissions); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
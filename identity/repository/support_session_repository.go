@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/identity/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type SupportSessionRepository interface {
+	GetConsent(ctx context.Context, tenantID uuid.UUID) (*models.SupportConsent, error)
+	// SetConsent records the tenant's consent; turning it off also ends the tenant's open sessions
+	SetConsent(ctx context.Context, consent *models.SupportConsent) error
+
+	CreateSession(ctx context.Context, session *models.SupportSession) error
+	GetSession(ctx context.Context, id uuid.UUID) (*models.SupportSession, error)
+	ListSessions(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.SupportSession, error)
+	// EndSession ends an open session; false means there is no open session with that id
+	EndSession(ctx context.Context, tenantID, id, endedBy uuid.UUID, endedAt time.Time) (bool, error)
+
+	RecordAccess(ctx context.Context, entry *models.SupportAccessLog) error
+	ListAccess(ctx context.Context, tenantID, sessionID uuid.UUID, limit, offset int) ([]models.SupportAccessLog, error)
+}
+
+type pgSupportSessionRepository struct{}
+
+func NewSupportSessionRepository() SupportSessionRepository {
+	return &pgSupportSessionRepository{}
+}
+
+const supportSessionColumns = `id, tenant_id, support_user_id, reason, expires_at, ended_at, ended_by, created_at`
+
+func (r *pgSupportSessionRepository) GetConsent(ctx context.Context, tenantID uuid.UUID) (*models.SupportConsent, error) {
+	query := `SELECT id, support_access_enabled, support_access_changed_at, support_access_changed_by FROM tenants WHERE id = $1`
+
+	var c models.SupportConsent
+	if err := db.MainPool.QueryRow(ctx, query, tenantID).Scan(&c.TenantID, &c.Enabled, &c.ChangedAt, &c.ChangedBy); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *pgSupportSessionRepository) SetConsent(ctx context.Context, c *models.SupportConsent) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := `
+			UPDATE tenants
+			SET support_access_enabled = $1, support_access_changed_at = $2, support_access_changed_by = $3, updated_at = NOW()
+			WHERE id = $4`
+		tag, err := tx.Exec(ctx, query, c.Enabled, c.ChangedAt, c.ChangedBy, c.TenantID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+
+		if c.Enabled {
+			return nil
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE support_sessions SET ended_at = $1, ended_by = $2
+			WHERE tenant_id = $3 AND ended_at IS NULL`,
+			c.ChangedAt, c.ChangedBy, c.TenantID)
+		return err
+	})
+}
+
+func (r *pgSupportSessionRepository) CreateSession(ctx context.Context, s *models.SupportSession) error {
+	query := `
+		INSERT INTO support_sessions (tenant_id, support_user_id, reason, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	return db.MainPool.QueryRow(ctx, query,
+		s.TenantID, s.SupportUserID, s.Reason, s.ExpiresAt,
+	).Scan(&s.ID, &s.CreatedAt)
+}
+
+func (r *pgSupportSessionRepository) GetSession(ctx context.Context, id uuid.UUID) (*models.SupportSession, error) {
+	query := `SELECT ` + supportSessionColumns + ` FROM support_sessions WHERE id = $1`
+	return r.scanSession(db.MainPool.QueryRow(ctx, query, id))
+}
+
+func (r *pgSupportSessionRepository) ListSessions(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.SupportSession, error) {
+	query := `
+		SELECT ` + supportSessionColumns + `
+		FROM support_sessions
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.SupportSession{}
+	for rows.Next() {
+		s, err := r.scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *pgSupportSessionRepository) EndSession(ctx context.Context, tenantID, id, endedBy uuid.UUID, endedAt time.Time) (bool, error) {
+	query := `UPDATE support_sessions SET ended_at = $1, ended_by = $2 WHERE id = $3 AND tenant_id = $4 AND ended_at IS NULL`
+	tag, err := db.MainPool.Exec(ctx, query, endedAt, endedBy, id, tenantID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *pgSupportSessionRepository) RecordAccess(ctx context.Context, e *models.SupportAccessLog) error {
+	query := `
+		INSERT INTO support_access_logs (session_id, tenant_id, support_user_id, action, method, path, query, status_code, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`
+
+	return db.MainPool.QueryRow(ctx, query,
+		e.SessionID, e.TenantID, e.SupportUserID, e.Action, e.Method, e.Path, e.Query, e.StatusCode, e.IPAddress, e.UserAgent,
+	).Scan(&e.ID, &e.CreatedAt)
+}
+
+func (r *pgSupportSessionRepository) ListAccess(ctx context.Context, tenantID, sessionID uuid.UUID, limit, offset int) ([]models.SupportAccessLog, error) {
+	query := `
+		SELECT id, session_id, tenant_id, support_user_id, action, method, path, query, status_code, ip_address, user_agent, created_at
+		FROM support_access_logs
+		WHERE tenant_id = $1 AND session_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, sessionID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.SupportAccessLog{}
+	for rows.Next() {
+		var e models.SupportAccessLog
+		if err := rows.Scan(
+			&e.ID, &e.SessionID, &e.TenantID, &e.SupportUserID, &e.Action, &e.Method, &e.Path, &e.Query,
+			&e.StatusCode, &e.IPAddress, &e.UserAgent, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *pgSupportSessionRepository) scanSession(row pgx.Row) (*models.SupportSession, error) {
+	var s models.SupportSession
+	err := row.Scan(
+		&s.ID, &s.TenantID, &s.SupportUserID, &s.Reason, &s.ExpiresAt, &s.EndedAt, &s.EndedBy, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
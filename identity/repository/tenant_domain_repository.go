@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/identity/models"
+	"github.com/google/uuid"
+)
+
+type TenantDomainRepository interface {
+	CreateDomain(ctx context.Context, domain *models.TenantDomain) error
+	GetDomainByID(ctx context.Context, id uuid.UUID) (*models.TenantDomain, error)
+	GetDomainByName(ctx context.Context, domain string) (*models.TenantDomain, error)
+	ListDomainsByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.TenantDomain, error)
+	MarkDomainVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error
+	DeleteDomain(ctx context.Context, tenantID, id uuid.UUID) error
+}
+
+type pgTenantDomainRepository struct{}
+
+func NewTenantDomainRepository() TenantDomainRepository {
+	return &pgTenantDomainRepository{}
+}
+
+const tenantDomainColumns = `id, tenant_id, domain, kind, verification_token, is_verified, verified_at, created_at, updated_at`
+
+func (r *pgTenantDomainRepository) CreateDomain(ctx context.Context, domain *models.TenantDomain) error {
+	query := `
+		INSERT INTO tenant_domains (tenant_id, domain, kind, verification_token, is_verified, verified_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	return db.MainPool.QueryRow(ctx, query,
+		domain.TenantID, domain.Domain, domain.Kind, domain.VerificationToken, domain.IsVerified, domain.VerifiedAt,
+	).Scan(&domain.ID, &domain.CreatedAt, &domain.UpdatedAt)
+}
+
+func (r *pgTenantDomainRepository) GetDomainByID(ctx context.Context, id uuid.UUID) (*models.TenantDomain, error) {
+	query := `SELECT ` + tenantDomainColumns + ` FROM tenant_domains WHERE id = $1`
+	var d models.TenantDomain
+	err := db.MainPool.QueryRow(ctx, query, id).Scan(
+		&d.ID, &d.TenantID, &d.Domain, &d.Kind, &d.VerificationToken, &d.IsVerified, &d.VerifiedAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *pgTenantDomainRepository) GetDomainByName(ctx context.Context, domain string) (*models.TenantDomain, error) {
+	query := `SELECT ` + tenantDomainColumns + ` FROM tenant_domains WHERE domain = $1`
+	var d models.TenantDomain
+	err := db.MainPool.QueryRow(ctx, query, domain).Scan(
+		&d.ID, &d.TenantID, &d.Domain, &d.Kind, &d.VerificationToken, &d.IsVerified, &d.VerifiedAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *pgTenantDomainRepository) ListDomainsByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.TenantDomain, error) {
+	query := `SELECT ` + tenantDomainColumns + ` FROM tenant_domains WHERE tenant_id = $1 ORDER BY created_at`
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	domains := []models.TenantDomain{}
+	for rows.Next() {
+		var d models.TenantDomain
+		if err := rows.Scan(
+			&d.ID, &d.TenantID, &d.Domain, &d.Kind, &d.VerificationToken, &d.IsVerified, &d.VerifiedAt, &d.CreatedAt, &d.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+	return domains, rows.Err()
+}
+
+func (r *pgTenantDomainRepository) MarkDomainVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error {
+	query := `UPDATE tenant_domains SET is_verified = true, verified_at = $1, updated_at = NOW() WHERE id = $2`
+	_, err := db.MainPool.Exec(ctx, query, verifiedAt, id)
+	return err
+}
+
+func (r *pgTenantDomainRepository) DeleteDomain(ctx context.Context, tenantID, id uuid.UUID) error {
+	query := `DELETE FROM tenant_domains WHERE id = $1 AND tenant_id = $2`
+	_, err := db.MainPool.Exec(ctx, query, id, tenantID)
+	return err
+}
@@ -45,22 +45,22 @@ func (r *pgTenantRepository) getExecutor() db.QueryExecutor {
 
 func (r *pgTenantRepository) CreateTenant(ctx context.Context, tenant *models.Tenant) error {
 	query := `
-		INSERT INTO tenants (name, business_name, status, fiscal_year_start, fiscal_year_end)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO tenants (name, business_name, status, fiscal_year_start, fiscal_year_end, data_region)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created_at, updated_at`
 
 	return r.getExecutor().QueryRow(ctx, query,
-		tenant.Name, tenant.BusinessName, tenant.Status, tenant.FiscalYearStart, tenant.FiscalYearEnd,
+		tenant.Name, tenant.BusinessName, tenant.Status, tenant.FiscalYearStart, tenant.FiscalYearEnd, tenant.DataRegion,
 	).Scan(&tenant.ID, &tenant.CreatedAt, &tenant.UpdatedAt)
 }
 
 func (r *pgTenantRepository) GetTenantByID(ctx context.Context, id uuid.UUID) (*models.Tenant, error) {
-	query := `SELECT id, name, business_name, status, max_users, fiscal_year_start, fiscal_year_end, kyb_status, kyb_document_url, verified_at, is_active, created_at, updated_at FROM tenants WHERE id = $1`
+	query := `SELECT id, name, business_name, status, max_users, fiscal_year_start, fiscal_year_end, kyb_status, kyb_document_url, verified_at, is_active, data_region, created_at, updated_at FROM tenants WHERE id = $1`
 	var tenant models.Tenant
 	err := r.getExecutor().QueryRow(ctx, query, id).Scan(
 		&tenant.ID, &tenant.Name, &tenant.BusinessName, &tenant.Status, &tenant.MaxUsers,
 		&tenant.FiscalYearStart, &tenant.FiscalYearEnd, &tenant.KybStatus, &tenant.KybDocumentURL,
-		&tenant.VerifiedAt, &tenant.IsActive, &tenant.CreatedAt, &tenant.UpdatedAt,
+		&tenant.VerifiedAt, &tenant.IsActive, &tenant.DataRegion, &tenant.CreatedAt, &tenant.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -69,12 +69,12 @@ func (r *pgTenantRepository) GetTenantByID(ctx context.Context, id uuid.UUID) (*
 }
 
 func (r *pgTenantRepository) GetTenantByName(ctx context.Context, name string) (*models.Tenant, error) {
-	query := `SELECT id, name, business_name, status, max_users, fiscal_year_start, fiscal_year_end, kyb_status, kyb_document_url, verified_at, is_active, created_at, updated_at FROM tenants WHERE name = $1`
+	query := `SELECT id, name, business_name, status, max_users, fiscal_year_start, fiscal_year_end, kyb_status, kyb_document_url, verified_at, is_active, data_region, created_at, updated_at FROM tenants WHERE name = $1`
 	var tenant models.Tenant
 	err := r.getExecutor().QueryRow(ctx, query, name).Scan(
 		&tenant.ID, &tenant.Name, &tenant.BusinessName, &tenant.Status, &tenant.MaxUsers,
 		&tenant.FiscalYearStart, &tenant.FiscalYearEnd, &tenant.KybStatus, &tenant.KybDocumentURL,
-		&tenant.VerifiedAt, &tenant.IsActive, &tenant.CreatedAt, &tenant.UpdatedAt,
+		&tenant.VerifiedAt, &tenant.IsActive, &tenant.DataRegion, &tenant.CreatedAt, &tenant.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/middleware"
+	"github.com/aceextension/identity/models"
+	"github.com/aceextension/identity/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	apiKeyBytes     = 32
+	apiKeyPrefixLen = 12 // "ak_" and the first characters, shown to recognise a key
+)
+
+var (
+	ErrInvalidScope        = errors.New("unknown scope")
+	ErrScopeNotGranted     = errors.New("a key cannot have scopes beyond the caller's")
+	ErrAPIKeyExpiryInvalid = errors.New("api key expiry must be in the future")
+	ErrAPIKeyNotFound      = errors.New("api key not found")
+	ErrAPIKeyInvalid       = errors.New("invalid api key")
+	ErrAPIKeyExpired       = errors.New("api key has expired or was revoked")
+)
+
+type APIKeyService interface {
+	// CreateKey issues a key acting as the caller with the caller's role; a scoped
+	// caller can only hand out scopes it holds
+	CreateKey(ctx context.Context, tenantID, createdBy uuid.UUID, role string, callerScopes []string, data dto.CreateAPIKeyDTO) (*dto.CreatedAPIKeyResponse, error)
+	ListKeys(ctx context.Context, tenantID uuid.UUID) ([]dto.APIKeyResponse, error)
+	RevokeKey(ctx context.Context, tenantID, id uuid.UUID) error
+	// ListScopes describes every grantable scope
+	ListScopes() []dto.ScopeResponse
+
+	// AuthenticateAPIKey resolves a key for JWTMiddleware
+	AuthenticateAPIKey(ctx context.Context, key string) (*middleware.AuthUser, error)
+}
+
+type apiKeyService struct {
+	authRepo repository.AuthRepository
+	keyRepo  repository.APIKeyRepository
+}
+
+func NewAPIKeyService(authRepo repository.AuthRepository, keyRepo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{
+		authRepo: authRepo,
+		keyRepo:  keyRepo,
+	}
+}
+
+func (s *apiKeyService) CreateKey(ctx context.Context, tenantID, createdBy uuid.UUID, role string, callerScopes []string, data dto.CreateAPIKeyDTO) (*dto.CreatedAPIKeyResponse, error) {
+	scopes, err := normalizeScopes(data.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if callerScopes != nil && !middleware.ScopesCover(callerScopes, scopes) {
+		return nil, ErrScopeNotGranted
+	}
+
+	now := time.Now()
+	if data.ExpiresAt != nil && !data.ExpiresAt.After(now) {
+		return nil, ErrAPIKeyExpiryInvalid
+	}
+
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	key := middleware.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := &models.APIKey{
+		TenantID:  tenantID,
+		Name:      strings.TrimSpace(data.Name),
+		Prefix:    key[:apiKeyPrefixLen],
+		KeyHash:   hashAPIKey(key),
+		Scopes:    scopes,
+		Role:      role,
+		CreatedBy: createdBy,
+		ExpiresAt: data.ExpiresAt,
+	}
+	if err := s.keyRepo.CreateKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreatedAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(apiKey, now),
+		Key:            key,
+	}, nil
+}
+
+func (s *apiKeyService) ListKeys(ctx context.Context, tenantID uuid.UUID) ([]dto.APIKeyResponse, error) {
+	keys, err := s.keyRepo.ListKeys(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res := make([]dto.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		res = append(res, toAPIKeyResponse(&keys[i], now))
+	}
+	return res, nil
+}
+
+func (s *apiKeyService) RevokeKey(ctx context.Context, tenantID, id uuid.UUID) error {
+	revoked, err := s.keyRepo.RevokeKey(ctx, tenantID, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (s *apiKeyService) ListScopes() []dto.ScopeResponse {
+	names := middleware.ScopeNames()
+	res := make([]dto.ScopeResponse, 0, len(names))
+	for _, name := range names {
+		action, area, _ := strings.Cut(name, ":")
+		description := "Everything"
+		if area != middleware.ScopeAll {
+			description = middleware.ScopeAreas[area]
+		}
+		if action == middleware.ScopeRead {
+			description = "View: " + description
+		} else {
+			description = "View and change: " + description
+		}
+		res = append(res, dto.ScopeResponse{Scope: name, Description: description})
+	}
+	return res
+}
+
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*middleware.AuthUser, error) {
+	apiKey, err := s.keyRepo.GetKeyByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAPIKeyInvalid
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !apiKey.IsUsable(now) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	// The key stops working with its creator's account
+	creator, err := s.authRepo.GetUserByID(ctx, apiKey.CreatedBy)
+	if err != nil || !creator.IsActive {
+		return nil, ErrAPIKeyExpired
+	}
+
+	if err := s.keyRepo.TouchKey(ctx, apiKey.ID, now); err != nil {
+		log.Printf("Failed to record use of api key %s: %v", apiKey.ID, err)
+	}
+
+	return &middleware.AuthUser{
+		UserID:   apiKey.CreatedBy.String(),
+		TenantID: apiKey.TenantID.String(),
+		Role:     apiKey.Role,
+		Scopes:   apiKey.Scopes,
+		APIKeyID: apiKey.ID.String(),
+	}, nil
+}
+
+// normalizeScopes validates, lowercases and de-duplicates requested scopes; none
+// means an unscoped token
+func normalizeScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, nil
+	}
+
+	seen := map[string]bool{}
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !middleware.ValidScope(scope) {
+			return nil, fmt.Errorf("%w %q", ErrInvalidScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes, nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func toAPIKeyResponse(k *models.APIKey, now time.Time) dto.APIKeyResponse {
+	status := "active"
+	if k.RevokedAt != nil {
+		status = "revoked"
+	} else if !k.IsUsable(now) {
+		status = "expired"
+	}
+
+	return dto.APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		Role:       k.Role,
+		CreatedBy:  k.CreatedBy,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		Status:     status,
+		CreatedAt:  k.CreatedAt,
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
+	"strings"
 	"time"
 
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
 	"github.com/aceextension/identity/dto"
 	"github.com/aceextension/identity/models"
 	"github.com/aceextension/identity/repository"
@@ -24,21 +26,49 @@ type AuthService interface {
 	ResetPassword(ctx context.Context, data dto.ResetPasswordDTO) error
 	Impersonate(ctx context.Context, tenantID uuid.UUID, adminUserID uuid.UUID) (*dto.AuthResponse, error)
 	GetMe(ctx context.Context, userID uuid.UUID) (*dto.UserResponse, error)
+
+	// Multi-tenant access
+	ListTenants(ctx context.Context, userID uuid.UUID, currentTenantID *uuid.UUID) ([]dto.TenantMembershipResponse, error)
+	// SwitchTenant keeps the scopes of the caller's session
+	SwitchTenant(ctx context.Context, userID, tenantID uuid.UUID, scopes []string) (*dto.AuthResponse, error)
+
+	// SetOTPSender sets how registration and password reset codes reach users; they
+	// are only logged until it is called
+	SetOTPSender(sender OTPSender)
 }
 
+var ErrNoTenantAccess = errors.New("user is not a member of this tenant")
+
 type authService struct {
-	authRepo   repository.AuthRepository
-	tenantRepo repository.TenantRepository
+	authRepo       repository.AuthRepository
+	tenantRepo     repository.TenantRepository
+	membershipRepo repository.MembershipRepository
+	otpSender      OTPSender
 }
 
-func NewAuthService(authRepo repository.AuthRepository, tenantRepo repository.TenantRepository) AuthService {
+func NewAuthService(authRepo repository.AuthRepository, tenantRepo repository.TenantRepository, membershipRepo repository.MembershipRepository) AuthService {
 	return &authService{
-		authRepo:   authRepo,
-		tenantRepo: tenantRepo,
+		authRepo:       authRepo,
+		tenantRepo:     tenantRepo,
+		membershipRepo: membershipRepo,
+		otpSender:      LogOTPSender{},
 	}
 }
 
+func (s *authService) SetOTPSender(sender OTPSender) {
+	s.otpSender = sender
+}
+
 func (s *authService) RegisterTenant(ctx context.Context, data dto.RegisterTenantDTO) (*dto.UserResponse, error) {
+	// A tenant's data only goes to a region this deployment stores data in
+	region := data.DataRegion
+	if region == "" {
+		region = db.MainPool.HomeRegion()
+	}
+	if !db.MainPool.HasRegion(region) {
+		return nil, fmt.Errorf("%w: %s", db.ErrUnknownRegion, region)
+	}
+
 	// 1. Hash Password
 	passwordHash, err := HashPassword(data.Password)
 	if err != nil {
@@ -46,7 +76,10 @@ func (s *authService) RegisterTenant(ctx context.Context, data dto.RegisterTenan
 	}
 
 	// 2. Generate OTP
-	otp := "123456" // Default for dev as per Bun implementation
+	otp, err := generateOTP()
+	if err != nil {
+		return nil, err
+	}
 	otpExpiresAt := time.Now().Add(10 * time.Minute)
 
 	var user models.User
@@ -58,6 +91,7 @@ func (s *authService) RegisterTenant(ctx context.Context, data dto.RegisterTenan
 			Name:         data.TenantName,
 			BusinessName: &data.TenantName,
 			Status:       "trial",
+			DataRegion:   region,
 		}
 
 		// Set fiscal year defaults
@@ -96,7 +130,18 @@ func (s *authService) RegisterTenant(ctx context.Context, data dto.RegisterTenan
 		return nil, err
 	}
 
-	fmt.Printf("📱 OTP for %s: %s (expires in 10 minutes)\n", data.Phone, otp)
+	// The account exists either way; a code that fails to send can be requested again
+	// through forgot password
+	if err := s.otpSender.SendOTP(ctx, OTP{
+		TenantID:  *user.TenantID,
+		UserID:    user.ID,
+		Phone:     user.Phone,
+		Code:      otp,
+		Purpose:   OTPPurposeRegistration,
+		ExpiresAt: otpExpiresAt,
+	}); err != nil {
+		logger.Log.Error("Failed to send registration OTP to user " + user.ID.String() + ": " + err.Error())
+	}
 
 	return &dto.UserResponse{
 		ID:       user.ID,
@@ -200,6 +245,11 @@ func (s *authService) Login(ctx context.Context, data dto.LoginDTO) (*dto.AuthRe
 		return nil, errors.New("invalid credentials")
 	}
 
+	scopes, err := normalizeScopes(data.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update last login
 	_ = s.authRepo.UpdateLastLogin(ctx, user.ID)
 
@@ -208,6 +258,7 @@ func (s *authService) Login(ctx context.Context, data dto.LoginDTO) (*dto.AuthRe
 		UserID:   user.ID,
 		TenantID: user.TenantID,
 		Role:     user.Role,
+		Scopes:   scopes,
 	}
 
 	accessToken, _ := GenerateAccessToken(payload)
@@ -217,6 +268,7 @@ func (s *authService) Login(ctx context.Context, data dto.LoginDTO) (*dto.AuthRe
 	session := models.Session{
 		UserID:       user.ID,
 		RefreshToken: refreshToken,
+		Scopes:       scopes,
 		ExpiresAt:    time.Now().Add(7 * 24 * time.Hour),
 	}
 	_ = s.authRepo.CreateSession(ctx, &session)
@@ -255,10 +307,22 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*d
 		return nil, err
 	}
 
+	// Keep the tenant the session was switched to, as long as the membership is still valid
+	tenantID, role := user.TenantID, user.Role
+	if session.TenantID != nil {
+		role, err = s.tenantRole(ctx, user, *session.TenantID)
+		if err != nil {
+			_ = s.authRepo.DeleteSession(ctx, session.UserID, refreshToken)
+			return nil, errors.New("tenant access revoked")
+		}
+		tenantID = session.TenantID
+	}
+
 	payload := dto.TokenPayload{
 		UserID:   user.ID,
-		TenantID: user.TenantID,
-		Role:     user.Role,
+		TenantID: tenantID,
+		Role:     role,
+		Scopes:   session.Scopes,
 	}
 
 	newAccessToken, _ := GenerateAccessToken(payload)
@@ -268,7 +332,9 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*d
 	_ = s.authRepo.DeleteSession(ctx, session.UserID, refreshToken)
 	newSession := models.Session{
 		UserID:       user.ID,
+		TenantID:     session.TenantID,
 		RefreshToken: newRefreshToken,
+		Scopes:       session.Scopes,
 		ExpiresAt:    time.Now().Add(7 * 24 * time.Hour),
 	}
 	_ = s.authRepo.CreateSession(ctx, &newSession)
@@ -281,8 +347,8 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*d
 			Name:     user.Name,
 			Email:    user.Email,
 			Phone:    user.Phone,
-			Role:     user.Role,
-			TenantID: user.TenantID,
+			Role:     role,
+			TenantID: tenantID,
 		},
 	}, nil
 }
@@ -311,15 +377,31 @@ func (s *authService) ForgotPassword(ctx context.Context, data dto.ForgotPasswor
 		return errors.New("user not found")
 	}
 
-	otp := "123456" // Default for dev
+	otp, err := generateOTP()
+	if err != nil {
+		return err
+	}
 	expiresAt := time.Now().Add(15 * time.Minute)
 
 	if err := s.authRepo.UpdateOTP(ctx, user.ID, &otp, &expiresAt); err != nil {
 		return err
 	}
 
-	fmt.Printf("📧 Password Reset OTP for %s: %s\n", data.Identifier, otp)
-	return nil
+	// The code goes to where the user asked from: their email or their phone
+	message := OTP{
+		UserID:    user.ID,
+		Phone:     user.Phone,
+		Code:      otp,
+		Purpose:   OTPPurposePasswordReset,
+		ExpiresAt: expiresAt,
+	}
+	if user.TenantID != nil {
+		message.TenantID = *user.TenantID
+	}
+	if user.Email != nil && strings.EqualFold(strings.TrimSpace(data.Identifier), *user.Email) {
+		message.Email = *user.Email
+	}
+	return s.otpSender.SendOTP(ctx, message)
 }
 
 func (s *authService) ResetPassword(ctx context.Context, data dto.ResetPasswordDTO) error {
@@ -408,7 +490,116 @@ func (s *authService) GetMe(ctx context.Context, userID uuid.UUID) (*dto.UserRes
 	}, nil
 }
 
-func generateRandomOTP() string {
-	// ... existing implementation ...
-	return fmt.Sprintf("%06d", rand.Intn(1000000))
+func (s *authService) ListTenants(ctx context.Context, userID uuid.UUID, currentTenantID *uuid.UUID) ([]dto.TenantMembershipResponse, error) {
+	user, err := s.authRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	memberships, err := s.membershipRepo.ListMembershipsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.TenantMembershipResponse, 0, len(memberships)+1)
+	homeListed := false
+	for _, m := range memberships {
+		isHome := user.TenantID != nil && *user.TenantID == m.TenantID
+		role := m.Role
+		if isHome {
+			// users.role is authoritative for the home tenant
+			role = user.Role
+			homeListed = true
+		}
+		res = append(res, dto.TenantMembershipResponse{
+			TenantID:   m.TenantID,
+			TenantName: m.TenantName,
+			Role:       role,
+			IsHome:     isHome,
+			IsCurrent:  currentTenantID != nil && *currentTenantID == m.TenantID,
+		})
+	}
+
+	// Users created before memberships existed may have no row for their home tenant
+	if !homeListed && user.TenantID != nil {
+		tenant, err := s.tenantRepo.GetTenantByID(ctx, *user.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		name := tenant.Name
+		if tenant.BusinessName != nil {
+			name = *tenant.BusinessName
+		}
+		res = append([]dto.TenantMembershipResponse{{
+			TenantID:   tenant.ID,
+			TenantName: name,
+			Role:       user.Role,
+			IsHome:     true,
+			IsCurrent:  currentTenantID != nil && *currentTenantID == tenant.ID,
+		}}, res...)
+	}
+
+	return res, nil
+}
+
+func (s *authService) SwitchTenant(ctx context.Context, userID, tenantID uuid.UUID, scopes []string) (*dto.AuthResponse, error) {
+	user, err := s.authRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is inactive")
+	}
+
+	role, err := s.tenantRole(ctx, user, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := dto.TokenPayload{
+		UserID:   user.ID,
+		TenantID: &tenantID,
+		Role:     role,
+		Scopes:   scopes,
+	}
+
+	accessToken, _ := GenerateAccessToken(payload)
+	refreshToken, _ := GenerateRefreshToken(payload)
+
+	session := models.Session{
+		UserID:       user.ID,
+		TenantID:     &tenantID,
+		RefreshToken: refreshToken,
+		Scopes:       scopes,
+		ExpiresAt:    time.Now().Add(7 * 24 * time.Hour),
+	}
+	_ = s.authRepo.CreateSession(ctx, &session)
+
+	return &dto.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		User: dto.UserResponse{
+			ID:       user.ID,
+			Name:     user.Name,
+			Email:    user.Email,
+			Phone:    user.Phone,
+			Role:     role,
+			TenantID: &tenantID,
+		},
+	}, nil
+}
+
+// tenantRole returns the user's role in a tenant: the user's own role for the home tenant,
+// otherwise the role of an active, unexpired membership (guests get RoleGuest)
+func (s *authService) tenantRole(ctx context.Context, user *models.User, tenantID uuid.UUID) (string, error) {
+	if user.TenantID != nil && *user.TenantID == tenantID {
+		return user.Role, nil
+	}
+
+	membership, err := s.membershipRepo.GetMembership(ctx, user.ID, tenantID)
+	if err != nil || !membership.IsUsable(time.Now()) {
+		return "", ErrNoTenantAccess
+	}
+	return membership.Role, nil
 }
@@ -94,6 +94,12 @@ func GenerateAccessToken(payload dto.TokenPayload) (string, error) {
 	if payload.TenantID != nil {
 		claims["tenantId"] = payload.TenantID.String()
 	}
+	if payload.Scopes != nil {
+		claims["scopes"] = payload.Scopes
+	}
+	if payload.SupportSessionID != nil {
+		claims["supportSessionId"] = payload.SupportSessionID.String()
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(config.GlobalConfig.JWTSecret))
@@ -110,6 +116,9 @@ func GenerateRefreshToken(payload dto.TokenPayload) (string, error) {
 	if payload.TenantID != nil {
 		claims["tenantId"] = payload.TenantID.String()
 	}
+	if payload.Scopes != nil {
+		claims["scopes"] = payload.Scopes
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(config.GlobalConfig.JWTSecret))
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/cache"
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/models"
+	"github.com/aceextension/identity/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Platform defaults used until a tenant customises its branding
+const (
+	DefaultPrimaryColor   = "#1E40AF"
+	DefaultSecondaryColor = "#64748B"
+	DefaultAccentColor    = "#F59E0B"
+
+	brandingCacheTTL = 5 * time.Minute
+)
+
+var ErrInvalidColor = errors.New("colors must be 6-digit hex values like #1E40AF")
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type BrandingService interface {
+	// GetBranding returns the tenant's branding with platform defaults filled in
+	GetBranding(ctx context.Context, tenantID uuid.UUID) (*models.TenantBranding, error)
+	UpdateBranding(ctx context.Context, tenantID uuid.UUID, data dto.UpdateBrandingDTO) (*models.TenantBranding, error)
+	// GetPublicBranding resolves branding for a portal host (subdomain or custom domain)
+	GetPublicBranding(ctx context.Context, host string) (*dto.PublicBrandingResponse, error)
+	// TemplateVariables exposes branding to notification and document templates
+	TemplateVariables(ctx context.Context, tenantID uuid.UUID) (map[string]interface{}, error)
+}
+
+type brandingService struct {
+	brandingRepo  repository.BrandingRepository
+	tenantRepo    repository.TenantRepository
+	domainService DomainService
+
+	brandings *cache.TTLCache[uuid.UUID, *models.TenantBranding]
+}
+
+func NewBrandingService(brandingRepo repository.BrandingRepository, tenantRepo repository.TenantRepository, domainService DomainService) BrandingService {
+	return &brandingService{
+		brandingRepo:  brandingRepo,
+		tenantRepo:    tenantRepo,
+		domainService: domainService,
+		brandings:     cache.New[uuid.UUID, *models.TenantBranding](brandingCacheTTL, 10000),
+	}
+}
+
+func (s *brandingService) GetBranding(ctx context.Context, tenantID uuid.UUID) (*models.TenantBranding, error) {
+	if branding, ok := s.brandings.Get(tenantID); ok {
+		return branding, nil
+	}
+
+	branding, err := s.brandingRepo.GetBranding(ctx, tenantID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		branding = &models.TenantBranding{
+			TenantID:       tenantID,
+			PrimaryColor:   DefaultPrimaryColor,
+			SecondaryColor: DefaultSecondaryColor,
+			AccentColor:    DefaultAccentColor,
+		}
+	}
+
+	// Fall back to the registered business name
+	if branding.DisplayName == nil {
+		tenant, err := s.tenantRepo.GetTenantByID(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		name := tenant.Name
+		if tenant.BusinessName != nil && *tenant.BusinessName != "" {
+			name = *tenant.BusinessName
+		}
+		branding.DisplayName = &name
+	}
+
+	s.brandings.Set(tenantID, branding)
+	return branding, nil
+}
+
+func (s *brandingService) UpdateBranding(ctx context.Context, tenantID uuid.UUID, data dto.UpdateBrandingDTO) (*models.TenantBranding, error) {
+	branding := &models.TenantBranding{
+		TenantID:       tenantID,
+		DisplayName:    emptyToNil(data.DisplayName),
+		LogoURL:        emptyToNil(data.LogoURL),
+		FaviconURL:     emptyToNil(data.FaviconURL),
+		PrimaryColor:   DefaultPrimaryColor,
+		SecondaryColor: DefaultSecondaryColor,
+		AccentColor:    DefaultAccentColor,
+		DocumentHeader: emptyToNil(data.DocumentHeader),
+		DocumentFooter: emptyToNil(data.DocumentFooter),
+		EmailFromName:  emptyToNil(data.EmailFromName),
+	}
+
+	colors := []struct {
+		value  string
+		target *string
+	}{
+		{data.PrimaryColor, &branding.PrimaryColor},
+		{data.SecondaryColor, &branding.SecondaryColor},
+		{data.AccentColor, &branding.AccentColor},
+	}
+	for _, color := range colors {
+		if color.value == "" {
+			continue
+		}
+		if !hexColorPattern.MatchString(color.value) {
+			return nil, ErrInvalidColor
+		}
+		*color.target = strings.ToUpper(color.value)
+	}
+
+	if err := s.brandingRepo.UpsertBranding(ctx, branding); err != nil {
+		return nil, err
+	}
+
+	s.brandings.Delete(tenantID)
+	return s.GetBranding(ctx, tenantID)
+}
+
+func (s *brandingService) GetPublicBranding(ctx context.Context, host string) (*dto.PublicBrandingResponse, error) {
+	tenantID, err := s.domainService.ResolveHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	branding, err := s.GetBranding(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PublicBrandingResponse{
+		DisplayName:    *branding.DisplayName,
+		LogoURL:        branding.LogoURL,
+		FaviconURL:     branding.FaviconURL,
+		PrimaryColor:   branding.PrimaryColor,
+		SecondaryColor: branding.SecondaryColor,
+		AccentColor:    branding.AccentColor,
+	}, nil
+}
+
+func (s *brandingService) TemplateVariables(ctx context.Context, tenantID uuid.UUID) (map[string]interface{}, error) {
+	branding, err := s.GetBranding(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromName := *branding.DisplayName
+	if branding.EmailFromName != nil {
+		fromName = *branding.EmailFromName
+	}
+
+	return map[string]interface{}{
+		"brandName":           *branding.DisplayName,
+		"brandLogoUrl":        stringOrEmpty(branding.LogoURL),
+		"brandPrimaryColor":   branding.PrimaryColor,
+		"brandSecondaryColor": branding.SecondaryColor,
+		"brandAccentColor":    branding.AccentColor,
+		"documentHeader":      stringOrEmpty(branding.DocumentHeader),
+		"documentFooter":      stringOrEmpty(branding.DocumentFooter),
+		"emailFromName":       fromName,
+	}, nil
+}
+
+func emptyToNil(value *string) *string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	return &trimmed
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
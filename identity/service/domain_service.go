@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/cache"
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/models"
+	"github.com/aceextension/identity/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	DomainKindSubdomain = "subdomain"
+	DomainKindCustom    = "custom"
+
+	// verificationRecordPrefix is the DNS name prefix tenants publish the TXT token under
+	verificationRecordPrefix = "_aceextension-verify."
+	verificationValuePrefix  = "aceextension-verification="
+
+	domainCacheTTL = 5 * time.Minute
+)
+
+var (
+	ErrInvalidDomain     = errors.New("invalid domain name")
+	ErrReservedSubdomain = errors.New("subdomain is reserved")
+	ErrDomainTaken       = errors.New("domain is already registered")
+	ErrDomainNotFound    = errors.New("domain not found")
+	ErrDomainUnverified  = errors.New("verification TXT record not found")
+	ErrUnknownHost       = errors.New("host is not mapped to a tenant")
+)
+
+var (
+	domainLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+	reservedSubdomains = map[string]bool{
+		"www": true, "api": true, "admin": true, "app": true, "mail": true,
+		"static": true, "cdn": true, "docs": true, "status": true, "support": true,
+	}
+)
+
+type DomainService interface {
+	AddDomain(ctx context.Context, tenantID uuid.UUID, domain string) (*dto.TenantDomainResponse, error)
+	ListDomains(ctx context.Context, tenantID uuid.UUID) ([]dto.TenantDomainResponse, error)
+	VerifyDomain(ctx context.Context, tenantID, id uuid.UUID) (*dto.TenantDomainResponse, error)
+	RemoveDomain(ctx context.Context, tenantID, id uuid.UUID) error
+
+	// ResolveHost maps a request host to its tenant; satisfies core middleware.DomainResolver
+	ResolveHost(ctx context.Context, host string) (uuid.UUID, error)
+}
+
+type domainService struct {
+	domainRepo repository.TenantDomainRepository
+	baseDomain string
+	lookupTXT  func(ctx context.Context, name string) ([]string, error)
+
+	// Host -> tenant, including misses (uuid.Nil) so unknown hosts don't hit the database every request
+	hosts *cache.TTLCache[string, uuid.UUID]
+}
+
+// NewDomainService creates the tenant domain service.
+// baseDomain is the platform domain tenant subdomains live under; empty accepts any parent domain.
+func NewDomainService(domainRepo repository.TenantDomainRepository, baseDomain string) DomainService {
+	return &domainService{
+		domainRepo: domainRepo,
+		baseDomain: strings.Trim(strings.ToLower(baseDomain), "."),
+		lookupTXT:  net.DefaultResolver.LookupTXT,
+		hosts:      cache.New[string, uuid.UUID](domainCacheTTL, 10000),
+	}
+}
+
+func (s *domainService) AddDomain(ctx context.Context, tenantID uuid.UUID, domain string) (*dto.TenantDomainResponse, error) {
+	name, kind, err := normalizeTenantDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	// Hosts under the platform domain can only be claimed as subdomains
+	if kind == DomainKindCustom && s.baseDomain != "" && (name == s.baseDomain || strings.HasSuffix(name, "."+s.baseDomain)) {
+		return nil, ErrInvalidDomain
+	}
+
+	existing, err := s.domainRepo.GetDomainByName(ctx, name)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrDomainTaken
+	}
+
+	token, err := generateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.TenantDomain{
+		TenantID:          tenantID,
+		Domain:            name,
+		Kind:              kind,
+		VerificationToken: token,
+	}
+
+	// Subdomains live in the platform's own zone, so there is nothing to prove
+	if kind == DomainKindSubdomain {
+		now := time.Now()
+		record.IsVerified = true
+		record.VerifiedAt = &now
+	}
+
+	if err := s.domainRepo.CreateDomain(ctx, record); err != nil {
+		return nil, err
+	}
+
+	s.hosts.Delete(s.hostFor(record))
+	res := s.toResponse(record)
+	return &res, nil
+}
+
+func (s *domainService) ListDomains(ctx context.Context, tenantID uuid.UUID) ([]dto.TenantDomainResponse, error) {
+	domains, err := s.domainRepo.ListDomainsByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.TenantDomainResponse, 0, len(domains))
+	for i := range domains {
+		res = append(res, s.toResponse(&domains[i]))
+	}
+	return res, nil
+}
+
+func (s *domainService) VerifyDomain(ctx context.Context, tenantID, id uuid.UUID) (*dto.TenantDomainResponse, error) {
+	record, err := s.getOwnedDomain(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !record.IsVerified {
+		records, err := s.lookupTXT(ctx, verificationRecordPrefix+record.Domain)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDomainUnverified, err)
+		}
+
+		expected := verificationValuePrefix + record.VerificationToken
+		found := false
+		for _, txt := range records {
+			if strings.TrimSpace(txt) == expected {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrDomainUnverified
+		}
+
+		now := time.Now()
+		if err := s.domainRepo.MarkDomainVerified(ctx, record.ID, now); err != nil {
+			return nil, err
+		}
+		record.IsVerified = true
+		record.VerifiedAt = &now
+		s.hosts.Delete(s.hostFor(record))
+	}
+
+	res := s.toResponse(record)
+	return &res, nil
+}
+
+func (s *domainService) RemoveDomain(ctx context.Context, tenantID, id uuid.UUID) error {
+	record, err := s.getOwnedDomain(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.domainRepo.DeleteDomain(ctx, tenantID, id); err != nil {
+		return err
+	}
+
+	s.hosts.Delete(s.hostFor(record))
+	return nil
+}
+
+func (s *domainService) ResolveHost(ctx context.Context, host string) (uuid.UUID, error) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if tenantID, ok := s.hosts.Get(host); ok {
+		if tenantID == uuid.Nil {
+			return uuid.Nil, ErrUnknownHost
+		}
+		return tenantID, nil
+	}
+
+	tenantID, err := s.lookupHost(ctx, host)
+	if err != nil {
+		if !errors.Is(err, ErrUnknownHost) {
+			return uuid.Nil, err
+		}
+		tenantID = uuid.Nil
+	}
+
+	s.hosts.Set(host, tenantID)
+	if tenantID == uuid.Nil {
+		return uuid.Nil, ErrUnknownHost
+	}
+	return tenantID, nil
+}
+
+// lookupHost checks custom domains first, then the platform subdomain
+func (s *domainService) lookupHost(ctx context.Context, host string) (uuid.UUID, error) {
+	record, err := s.domainRepo.GetDomainByName(ctx, host)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, err
+	}
+	if record != nil && record.Kind == DomainKindCustom && record.IsVerified {
+		return record.TenantID, nil
+	}
+
+	label, ok := s.subdomainLabel(host)
+	if !ok {
+		return uuid.Nil, ErrUnknownHost
+	}
+
+	record, err = s.domainRepo.GetDomainByName(ctx, label)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrUnknownHost
+		}
+		return uuid.Nil, err
+	}
+	if record.Kind != DomainKindSubdomain {
+		return uuid.Nil, ErrUnknownHost
+	}
+	return record.TenantID, nil
+}
+
+// subdomainLabel extracts "acme" from acme.<base domain>
+func (s *domainService) subdomainLabel(host string) (string, bool) {
+	if s.baseDomain != "" {
+		if !strings.HasSuffix(host, "."+s.baseDomain) {
+			return "", false
+		}
+		label := strings.TrimSuffix(host, "."+s.baseDomain)
+		if strings.Contains(label, ".") {
+			return "", false
+		}
+		return label, true
+	}
+
+	parts := strings.Split(host, ".")
+	if len(parts) < 3 {
+		return "", false
+	}
+	return parts[0], true
+}
+
+// hostFor returns the host key a domain record is cached under
+func (s *domainService) hostFor(record *models.TenantDomain) string {
+	if record.Kind == DomainKindSubdomain && s.baseDomain != "" {
+		return record.Domain + "." + s.baseDomain
+	}
+	return record.Domain
+}
+
+func (s *domainService) getOwnedDomain(ctx context.Context, tenantID, id uuid.UUID) (*models.TenantDomain, error) {
+	record, err := s.domainRepo.GetDomainByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDomainNotFound
+		}
+		return nil, err
+	}
+	if record.TenantID != tenantID {
+		return nil, ErrDomainNotFound
+	}
+	return record, nil
+}
+
+func (s *domainService) toResponse(record *models.TenantDomain) dto.TenantDomainResponse {
+	res := dto.TenantDomainResponse{
+		ID:         record.ID,
+		Domain:     s.hostFor(record),
+		Kind:       record.Kind,
+		IsVerified: record.IsVerified,
+		VerifiedAt: record.VerifiedAt,
+		CreatedAt:  record.CreatedAt,
+	}
+	if record.Kind == DomainKindCustom && !record.IsVerified {
+		res.VerificationRecord = &dto.DNSRecord{
+			Type:  "TXT",
+			Name:  verificationRecordPrefix + record.Domain,
+			Value: verificationValuePrefix + record.VerificationToken,
+		}
+	}
+	return res
+}
+
+// normalizeTenantDomain validates a domain and classifies it.
+// A single label ("acme") is a platform subdomain; anything with a dot is a custom domain.
+func normalizeTenantDomain(domain string) (string, string, error) {
+	name := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "https://"), "http://")
+	if name == "" || len(name) > 253 {
+		return "", "", ErrInvalidDomain
+	}
+
+	labels := strings.Split(name, ".")
+	for _, label := range labels {
+		if !domainLabelPattern.MatchString(label) {
+			return "", "", ErrInvalidDomain
+		}
+	}
+
+	if len(labels) == 1 {
+		if len(name) < 3 {
+			return "", "", ErrInvalidDomain
+		}
+		if reservedSubdomains[name] {
+			return "", "", ErrReservedSubdomain
+		}
+		return name, DomainKindSubdomain, nil
+	}
+
+	return name, DomainKindCustom, nil
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/middleware"
+	"github.com/aceextension/identity/models"
+	"github.com/aceextension/identity/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// RoleGuest is the token role for delegated, read-only access
+	RoleGuest = middleware.RoleGuest
+
+	// GuestPermissionExport allows downloading exports in addition to viewing
+	GuestPermissionExport = "export"
+
+	defaultGuestAccessDays = 30
+	maxGuestAccessDays     = 365
+)
+
+// GuestScopes are the module areas a guest can be granted view access to
+var GuestScopes = map[string]bool{
+	"accounting":          true,
+	"fiscal":              true,
+	"reports":             true,
+	"catalog":             true,
+	"crm":                 true,
+	"audit":               true,
+	GuestPermissionExport: true,
+}
+
+// guestWritePaths are the only non-GET requests a guest may make
+var guestWritePaths = map[string]bool{
+	"/api/auth/switch-tenant": true,
+	"/api/auth/logout":        true,
+}
+
+var (
+	ErrInvalidGuestPermission = errors.New("unknown guest permission")
+	ErrGuestIsMember          = errors.New("user is already a full member of this tenant")
+	ErrGuestExpiryInvalid     = errors.New("guest access must expire within a year")
+	ErrGuestNotFound          = errors.New("guest access not found")
+	ErrGuestAccessDenied      = errors.New("guest access does not allow this request")
+	ErrGuestAccessExpired     = errors.New("guest access has expired or was revoked")
+)
+
+type GuestAccessService interface {
+	GrantAccess(ctx context.Context, tenantID, grantedBy uuid.UUID, data dto.GrantGuestAccessDTO) (*dto.GuestAccessResponse, error)
+	ListGuests(ctx context.Context, tenantID uuid.UUID) ([]dto.GuestAccessResponse, error)
+	RevokeAccess(ctx context.Context, tenantID, membershipID uuid.UUID) error
+	ListActivity(ctx context.Context, tenantID, membershipID uuid.UUID, limit, offset int) ([]models.GuestAccessLog, error)
+
+	// AuthorizeGuest checks a guest request against expiry and the permission set.
+	// It returns the membership and the audit action (view/export) to record.
+	AuthorizeGuest(ctx context.Context, userID, tenantID uuid.UUID, method, path, rawQuery string) (uuid.UUID, string, error)
+	RecordGuestAccess(ctx context.Context, entry *models.GuestAccessLog)
+	// ExpireLapsed deactivates guest memberships past their expiry (called by a worker)
+	ExpireLapsed(ctx context.Context) (int64, error)
+}
+
+type guestAccessService struct {
+	authRepo       repository.AuthRepository
+	membershipRepo repository.MembershipRepository
+	accessRepo     repository.GuestAccessRepository
+}
+
+func NewGuestAccessService(authRepo repository.AuthRepository, membershipRepo repository.MembershipRepository, accessRepo repository.GuestAccessRepository) GuestAccessService {
+	return &guestAccessService{
+		authRepo:       authRepo,
+		membershipRepo: membershipRepo,
+		accessRepo:     accessRepo,
+	}
+}
+
+func (s *guestAccessService) GrantAccess(ctx context.Context, tenantID, grantedBy uuid.UUID, data dto.GrantGuestAccessDTO) (*dto.GuestAccessResponse, error) {
+	permissions := make([]string, 0, len(data.Permissions))
+	seen := map[string]bool{}
+	for _, p := range data.Permissions {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if !GuestScopes[p] {
+			return nil, ErrInvalidGuestPermission
+		}
+		if !seen[p] {
+			seen[p] = true
+			permissions = append(permissions, p)
+		}
+	}
+
+	now := time.Now()
+	expiresAt := now.AddDate(0, 0, defaultGuestAccessDays)
+	if data.ExpiresAt != nil {
+		expiresAt = *data.ExpiresAt
+	} else if data.ExpiresInDays > 0 {
+		expiresAt = now.AddDate(0, 0, data.ExpiresInDays)
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.AddDate(0, 0, maxGuestAccessDays)) {
+		return nil, ErrGuestExpiryInvalid
+	}
+
+	user, err := s.authRepo.GetUserByIdentifier(ctx, data.Identifier)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TenantID != nil && *user.TenantID == tenantID {
+		return nil, ErrGuestIsMember
+	}
+	if existing, err := s.membershipRepo.GetMembership(ctx, user.ID, tenantID); err == nil && !existing.IsGuest && existing.IsUsable(now) {
+		return nil, ErrGuestIsMember
+	}
+
+	membership := &models.TenantMembership{
+		UserID:      user.ID,
+		TenantID:    tenantID,
+		Role:        RoleGuest,
+		IsActive:    true,
+		IsGuest:     true,
+		Permissions: permissions,
+		ExpiresAt:   &expiresAt,
+		GrantedBy:   &grantedBy,
+	}
+	if err := s.membershipRepo.UpsertMembership(ctx, membership); err != nil {
+		return nil, err
+	}
+
+	res := toGuestResponse(membership, user.Name, now)
+	return &res, nil
+}
+
+func (s *guestAccessService) ListGuests(ctx context.Context, tenantID uuid.UUID) ([]dto.GuestAccessResponse, error) {
+	memberships, err := s.membershipRepo.ListGuestsByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res := make([]dto.GuestAccessResponse, 0, len(memberships))
+	for i := range memberships {
+		name := ""
+		if user, err := s.authRepo.GetUserByID(ctx, memberships[i].UserID); err == nil {
+			name = user.Name
+		}
+		res = append(res, toGuestResponse(&memberships[i], name, now))
+	}
+	return res, nil
+}
+
+func (s *guestAccessService) RevokeAccess(ctx context.Context, tenantID, membershipID uuid.UUID) error {
+	membership, err := s.getGuest(ctx, tenantID, membershipID)
+	if err != nil {
+		return err
+	}
+	if membership.RevokedAt != nil {
+		return nil
+	}
+
+	if err := s.membershipRepo.RevokeMembership(ctx, tenantID, membershipID, time.Now()); err != nil {
+		return err
+	}
+
+	// Refresh tokens scoped to this tenant stop working on next refresh;
+	// access tokens are rejected immediately by the guest guard.
+	return nil
+}
+
+func (s *guestAccessService) ListActivity(ctx context.Context, tenantID, membershipID uuid.UUID, limit, offset int) ([]models.GuestAccessLog, error) {
+	if _, err := s.getGuest(ctx, tenantID, membershipID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.accessRepo.ListAccess(ctx, tenantID, membershipID, limit, offset)
+}
+
+func (s *guestAccessService) AuthorizeGuest(ctx context.Context, userID, tenantID uuid.UUID, method, path, rawQuery string) (uuid.UUID, string, error) {
+	membership, err := s.membershipRepo.GetMembership(ctx, userID, tenantID)
+	if err != nil || !membership.IsGuest {
+		return uuid.Nil, "", ErrGuestAccessExpired
+	}
+	if !membership.IsUsable(time.Now()) {
+		return membership.ID, "", ErrGuestAccessExpired
+	}
+
+	if guestWritePaths[path] {
+		return membership.ID, "view", nil
+	}
+	if method != "GET" && method != "HEAD" {
+		return membership.ID, "", ErrGuestAccessDenied
+	}
+
+	granted := map[string]bool{}
+	for _, p := range membership.Permissions {
+		granted[p] = true
+	}
+
+	scope := guestScopeForPath(path)
+	if scope != "auth" && !granted[scope] {
+		return membership.ID, "", ErrGuestAccessDenied
+	}
+
+	if isExportRequest(path, rawQuery) {
+		if !granted[GuestPermissionExport] {
+			return membership.ID, "", ErrGuestAccessDenied
+		}
+		return membership.ID, "export", nil
+	}
+
+	return membership.ID, "view", nil
+}
+
+func (s *guestAccessService) RecordGuestAccess(ctx context.Context, entry *models.GuestAccessLog) {
+	if err := s.accessRepo.RecordAccess(ctx, entry); err != nil {
+		log.Printf("Failed to record guest access for membership %s: %v", entry.MembershipID, err)
+	}
+}
+
+func (s *guestAccessService) ExpireLapsed(ctx context.Context) (int64, error) {
+	return s.membershipRepo.DeactivateExpiredGuests(ctx, time.Now())
+}
+
+func (s *guestAccessService) getGuest(ctx context.Context, tenantID, membershipID uuid.UUID) (*models.TenantMembership, error) {
+	membership, err := s.membershipRepo.GetMembershipByID(ctx, membershipID)
+	if err != nil || membership.TenantID != tenantID || !membership.IsGuest {
+		return nil, ErrGuestNotFound
+	}
+	return membership, nil
+}
+
+// guestScopeForPath maps /api/v1/accounting/... or /api/catalog/... to its module scope
+func guestScopeForPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, segment := range segments {
+		if segment == "api" || segment == "v1" || segment == "" {
+			continue
+		}
+		return segment
+	}
+	return ""
+}
+
+// isExportRequest detects downloads: /export paths or an explicit file format
+func isExportRequest(path, rawQuery string) bool {
+	if strings.Contains(path, "/export") {
+		return true
+	}
+	for _, format := range []string{"format=csv", "format=xlsx", "format=pdf", "format=ndjson"} {
+		if strings.Contains(rawQuery, format) {
+			return true
+		}
+	}
+	return false
+}
+
+func toGuestResponse(m *models.TenantMembership, name string, now time.Time) dto.GuestAccessResponse {
+	status := "active"
+	if m.RevokedAt != nil {
+		status = "revoked"
+	} else if !m.IsUsable(now) {
+		status = "expired"
+	}
+
+	return dto.GuestAccessResponse{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		Name:        name,
+		Permissions: m.Permissions,
+		ExpiresAt:   m.ExpiresAt,
+		GrantedBy:   m.GrantedBy,
+		RevokedAt:   m.RevokedAt,
+		Status:      status,
+		CreatedAt:   m.CreatedAt,
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/aceextension/core/security"
+)
+
+const maxCredentialValueLength = 2000
+
+// CredentialField describes one setting of an integration
+type CredentialField struct {
+	Key      string
+	Label    string
+	Secret   bool // Masked when read back and rotated with a grace period
+	Required bool
+	Pattern  *regexp.Regexp
+	Hint     string // Shown when the value does not match Pattern
+}
+
+// IntegrationSchema is the typed set of fields a tenant configures for an integration
+type IntegrationSchema struct {
+	Name   string
+	Label  string
+	Fields []CredentialField
+}
+
+// Field returns the schema's field with the given key
+func (s IntegrationSchema) Field(key string) (CredentialField, bool) {
+	for _, f := range s.Fields {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return CredentialField{}, false
+}
+
+// validate checks a complete set of values against the schema
+func (s IntegrationSchema) validate(values map[string]string) error {
+	for key, value := range values {
+		field, ok := s.Field(key)
+		if !ok {
+			return fmt.Errorf("%w: %s has no field %q", ErrInvalidCredentials, s.Name, key)
+		}
+		if len(value) > maxCredentialValueLength {
+			return fmt.Errorf("%w: %s is too long", ErrInvalidCredentials, field.Label)
+		}
+		if field.Pattern != nil && !field.Pattern.MatchString(value) {
+			return fmt.Errorf("%w: %s %s", ErrInvalidCredentials, field.Label, field.Hint)
+		}
+	}
+	for _, field := range s.Fields {
+		if field.Required && values[field.Key] == "" {
+			return fmt.Errorf("%w: %s is required", ErrInvalidCredentials, field.Label)
+		}
+	}
+	return nil
+}
+
+var (
+	schemaMu           sync.RWMutex
+	integrationSchemas = map[string]IntegrationSchema{
+		security.IntegrationCBMS: {
+			Name:  security.IntegrationCBMS,
+			Label: "IRD CBMS",
+			Fields: []CredentialField{
+				{Key: "username", Label: "Username", Required: true},
+				{Key: "password", Label: "Password", Secret: true, Required: true},
+				{Key: "seller_pan", Label: "Seller PAN", Required: true, Pattern: regexp.MustCompile(`^\d{9}$`), Hint: "must be 9 digits"},
+			},
+		},
+		security.IntegrationSMS: {
+			Name:  security.IntegrationSMS,
+			Label: "SMS Gateway",
+			Fields: []CredentialField{
+				{Key: "provider", Label: "Provider", Required: true, Pattern: regexp.MustCompile(`^(sparrow|aakash|twilio)$`), Hint: "must be sparrow, aakash or twilio"},
+				{Key: "token", Label: "API Token", Secret: true, Required: true},
+				{Key: "account_sid", Label: "Account SID", Pattern: regexp.MustCompile(`^(AC[0-9a-f]{32})?$`), Hint: "must be a Twilio account SID"},
+				{Key: "sender_id", Label: "Sender ID", Pattern: regexp.MustCompile(`^([A-Za-z0-9_]{0,11}|\+[0-9]{7,15}|MG[0-9a-f]{32})$`), Hint: "must be up to 11 letters or digits, a +country number or a messaging service SID"},
+			},
+		},
+		security.IntegrationEmail: {
+			Name:  security.IntegrationEmail,
+			Label: "Email Provider",
+			Fields: []CredentialField{
+				{Key: "provider", Label: "Provider", Required: true, Pattern: regexp.MustCompile(`^(smtp|sendgrid)$`), Hint: "must be smtp or sendgrid"},
+				{Key: "from", Label: "From Address", Required: true, Pattern: regexp.MustCompile(`^[^@\s]+@[^@\s]+$`), Hint: "must be an email address"},
+				{Key: "from_name", Label: "From Name"},
+				{Key: "host", Label: "SMTP Host"},
+				{Key: "port", Label: "SMTP Port", Pattern: regexp.MustCompile(`^([0-9]{1,5})?$`), Hint: "must be a port number"},
+				{Key: "username", Label: "SMTP Username"},
+				{Key: "password", Label: "SMTP Password", Secret: true},
+				{Key: "api_key", Label: "SendGrid API Key", Secret: true},
+			},
+		},
+		security.IntegrationESewa: {
+			Name:  security.IntegrationESewa,
+			Label: "eSewa",
+			Fields: []CredentialField{
+				{Key: "merchant_code", Label: "Merchant Code", Required: true},
+				{Key: "secret_key", Label: "Secret Key", Secret: true, Required: true},
+			},
+		},
+		security.IntegrationKhalti: {
+			Name:  security.IntegrationKhalti,
+			Label: "Khalti",
+			Fields: []CredentialField{
+				{Key: "public_key", Label: "Public Key", Required: true},
+				{Key: "secret_key", Label: "Secret Key", Secret: true, Required: true},
+			},
+		},
+		security.IntegrationBackupS3: {
+			Name:  security.IntegrationBackupS3,
+			Label: "Backup Bucket (S3)",
+			Fields: []CredentialField{
+				{Key: "endpoint", Label: "Endpoint", Required: true, Pattern: regexp.MustCompile(`^https?://[^/\s]+/?$`), Hint: "must be an http(s) origin, e.g. https://s3.ap-south-1.amazonaws.com"},
+				{Key: "bucket", Label: "Bucket", Required: true, Pattern: regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`), Hint: "must be a valid bucket name"},
+				{Key: "region", Label: "Region"},
+				{Key: "prefix", Label: "Folder"},
+				{Key: "access_key", Label: "Access Key ID", Required: true},
+				{Key: "secret_key", Label: "Secret Access Key", Secret: true, Required: true},
+			},
+		},
+	}
+)
+
+// RegisterIntegration adds or replaces the schema of an integration
+func RegisterIntegration(schema IntegrationSchema) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	integrationSchemas[schema.Name] = schema
+}
+
+func lookupIntegration(name string) (IntegrationSchema, bool) {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+	schema, ok := integrationSchemas[name]
+	return schema, ok
+}
+
+// integrationList returns the registered schemas sorted by name
+func integrationList() []IntegrationSchema {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+	schemas := make([]IntegrationSchema, 0, len(integrationSchemas))
+	for _, schema := range integrationSchemas {
+		schemas = append(schemas, schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+	return schemas
+}
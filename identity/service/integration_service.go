@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/cache"
+	"github.com/aceextension/core/security"
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/models"
+	"github.com/aceextension/identity/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// Replaced secrets stay accepted this long unless the request says otherwise
+	defaultPreviousValidHours = 24
+
+	credentialCacheTTL = time.Minute
+	resealBatchSize    = 100
+)
+
+var (
+	ErrUnknownIntegration          = errors.New("unknown integration")
+	ErrInvalidCredentials          = errors.New("invalid integration credentials")
+	ErrIntegrationNotConfigured    = errors.New("integration is not configured")
+	ErrCredentialsChanged          = errors.New("integration credentials were changed by another request, reload and retry")
+	ErrCredentialEncryptionMissing = errors.New("credential encryption is not configured")
+)
+
+type IntegrationService interface {
+	// ListIntegrations returns every integration with the tenant's masked settings
+	ListIntegrations(ctx context.Context, tenantID uuid.UUID) ([]dto.IntegrationCredentialResponse, error)
+	GetIntegration(ctx context.Context, tenantID uuid.UUID, name string) (*dto.IntegrationCredentialResponse, error)
+	// SetIntegration merges the given values into the tenant's settings. Changing a secret
+	// keeps the replaced values accepted for a grace period.
+	SetIntegration(ctx context.Context, tenantID, actorID uuid.UUID, name string, data dto.SetIntegrationCredentialsDTO) (*dto.IntegrationCredentialResponse, error)
+	RemoveIntegration(ctx context.Context, tenantID, actorID uuid.UUID, name string) error
+	// Credentials hands connectors the decrypted settings (security.CredentialStore)
+	Credentials(ctx context.Context, tenantID uuid.UUID, integration string) (*security.Credentials, error)
+	// ResealAll re-encrypts credentials sealed under an older key with the current one
+	ResealAll(ctx context.Context) (int, error)
+}
+
+type integrationService struct {
+	repo   repository.IntegrationCredentialRepository
+	cipher *security.Cipher // nil when CREDENTIAL_KEYS is not set
+
+	// Decrypted credentials by tenant|integration; nil when not configured
+	credentials *cache.TTLCache[string, *security.Credentials]
+}
+
+func NewIntegrationService(repo repository.IntegrationCredentialRepository, cipher *security.Cipher) IntegrationService {
+	return &integrationService{
+		repo:        repo,
+		cipher:      cipher,
+		credentials: cache.New[string, *security.Credentials](credentialCacheTTL, 10000),
+	}
+}
+
+func (s *integrationService) ListIntegrations(ctx context.Context, tenantID uuid.UUID) ([]dto.IntegrationCredentialResponse, error) {
+	stored, err := s.repo.ListCredentials(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.TenantIntegrationCredential, len(stored))
+	for i := range stored {
+		byName[stored[i].Integration] = &stored[i]
+	}
+
+	responses := []dto.IntegrationCredentialResponse{}
+	for _, schema := range integrationList() {
+		var values map[string]string
+		credential := byName[schema.Name]
+		if credential != nil {
+			if values, _, err = s.open(credential); err != nil {
+				return nil, err
+			}
+		}
+		responses = append(responses, integrationResponse(schema, credential, values))
+	}
+	return responses, nil
+}
+
+func (s *integrationService) GetIntegration(ctx context.Context, tenantID uuid.UUID, name string) (*dto.IntegrationCredentialResponse, error) {
+	schema, ok := lookupIntegration(name)
+	if !ok {
+		return nil, ErrUnknownIntegration
+	}
+
+	credential, err := s.repo.GetCredential(ctx, tenantID, name)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	var values map[string]string
+	if err == nil {
+		if values, _, err = s.open(credential); err != nil {
+			return nil, err
+		}
+	} else {
+		credential = nil
+	}
+
+	response := integrationResponse(schema, credential, values)
+	return &response, nil
+}
+
+func (s *integrationService) SetIntegration(ctx context.Context, tenantID, actorID uuid.UUID, name string, data dto.SetIntegrationCredentialsDTO) (*dto.IntegrationCredentialResponse, error) {
+	schema, ok := lookupIntegration(name)
+	if !ok {
+		return nil, ErrUnknownIntegration
+	}
+	if s.cipher == nil {
+		return nil, ErrCredentialEncryptionMissing
+	}
+
+	existing, err := s.repo.GetCredential(ctx, tenantID, name)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		existing = nil
+	}
+
+	current := map[string]string{}
+	var previous map[string]string
+	expectedVersion := 0
+	if existing != nil {
+		if current, previous, err = s.open(existing); err != nil {
+			return nil, err
+		}
+		expectedVersion = existing.Version
+	}
+
+	// Merge: omitted fields keep their value, empty ones are cleared
+	values := make(map[string]string, len(current))
+	for key, value := range current {
+		values[key] = value
+	}
+	fields := make([]string, 0, len(data.Values))
+	for key, value := range data.Values {
+		if _, ok := schema.Field(key); !ok {
+			return nil, fmt.Errorf("%w: %s has no field %q", ErrInvalidCredentials, name, key)
+		}
+		fields = append(fields, key)
+		if value = strings.TrimSpace(value); value == "" {
+			delete(values, key)
+		} else {
+			values[key] = value
+		}
+	}
+	sort.Strings(fields)
+	if err := schema.validate(values); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	credential := &models.TenantIntegrationCredential{
+		TenantID:    tenantID,
+		Integration: name,
+		KeyID:       s.cipher.CurrentKey(),
+		Version:     expectedVersion + 1,
+		UpdatedBy:   &actorID,
+	}
+	if existing != nil {
+		credential.RotatedAt = existing.RotatedAt
+		credential.PreviousExpiresAt = existing.PreviousExpiresAt
+	}
+
+	rotated := false
+	if existing != nil {
+		for _, field := range schema.Fields {
+			if field.Secret && values[field.Key] != current[field.Key] {
+				rotated = true
+				break
+			}
+		}
+	}
+	switch {
+	case rotated:
+		credential.RotatedAt = &now
+		previous, credential.PreviousExpiresAt = nil, nil
+		hours := defaultPreviousValidHours
+		if data.PreviousValidHours != nil {
+			hours = *data.PreviousValidHours
+		}
+		if hours > 0 {
+			expires := now.Add(time.Duration(hours) * time.Hour)
+			previous, credential.PreviousExpiresAt = current, &expires
+		}
+	case credential.PreviousExpiresAt != nil && !now.Before(*credential.PreviousExpiresAt):
+		// The grace period is over; drop the old values while rewriting
+		previous, credential.PreviousExpiresAt = nil, nil
+	}
+
+	if credential.Values, err = s.seal(tenantID, name, values); err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		sealed, err := s.seal(tenantID, name, previous)
+		if err != nil {
+			return nil, err
+		}
+		credential.PreviousValues = &sealed
+	}
+
+	if err := s.repo.SaveCredential(ctx, credential, expectedVersion); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCredentialsChanged
+		}
+		return nil, err
+	}
+	s.credentials.Delete(credentialKey(tenantID, name))
+
+	if audit.Service != nil {
+		entityIDStr := credential.ID.String()
+		// Field names only, never values
+		audit.Service.Log(ctx, "SET_INTEGRATION_CREDENTIALS", "IntegrationCredential", &entityIDStr, map[string]interface{}{
+			"integration": name,
+			"fields":      fields,
+			"rotated":     rotated,
+			"version":     credential.Version,
+		}, &auditDomain.AuditContext{TenantID: &tenantID, UserID: &actorID})
+	}
+
+	response := integrationResponse(schema, credential, values)
+	return &response, nil
+}
+
+func (s *integrationService) RemoveIntegration(ctx context.Context, tenantID, actorID uuid.UUID, name string) error {
+	if _, ok := lookupIntegration(name); !ok {
+		return ErrUnknownIntegration
+	}
+
+	deleted, err := s.repo.DeleteCredential(ctx, tenantID, name)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrIntegrationNotConfigured
+	}
+	s.credentials.Delete(credentialKey(tenantID, name))
+
+	if audit.Service != nil {
+		audit.Service.Log(ctx, "DELETE_INTEGRATION_CREDENTIALS", "IntegrationCredential", nil, map[string]interface{}{
+			"integration": name,
+		}, &auditDomain.AuditContext{TenantID: &tenantID, UserID: &actorID})
+	}
+	return nil
+}
+
+func (s *integrationService) Credentials(ctx context.Context, tenantID uuid.UUID, integration string) (*security.Credentials, error) {
+	key := credentialKey(tenantID, integration)
+	if credentials, ok := s.credentials.Get(key); ok {
+		if credentials == nil {
+			return nil, security.ErrNoCredentials
+		}
+		return credentials, nil
+	}
+
+	credential, err := s.repo.GetCredential(ctx, tenantID, integration)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.credentials.Set(key, nil)
+			return nil, security.ErrNoCredentials
+		}
+		return nil, err
+	}
+
+	values, previous, err := s.open(credential)
+	if err != nil {
+		return nil, err
+	}
+	credentials := &security.Credentials{Integration: integration, Values: values}
+	if previous != nil && credential.PreviousExpiresAt != nil && time.Now().Before(*credential.PreviousExpiresAt) {
+		credentials.Previous = previous
+	}
+
+	s.credentials.Set(key, credentials)
+	return credentials, nil
+}
+
+func (s *integrationService) ResealAll(ctx context.Context) (int, error) {
+	if s.cipher == nil {
+		return 0, nil
+	}
+
+	resealed := 0
+	after := uuid.Nil
+	for {
+		stale, err := s.repo.ListCredentialsNotSealedWith(ctx, s.cipher.CurrentKey(), after, resealBatchSize)
+		if err != nil {
+			return resealed, err
+		}
+
+		for i := range stale {
+			credential := &stale[i]
+			after = credential.ID
+			ok, err := s.reseal(ctx, credential)
+			if err != nil {
+				// Sealed under a key no longer configured; leave it for an operator
+				log.Printf("Failed to reseal %s credentials of tenant %s: %v", credential.Integration, credential.TenantID, err)
+				continue
+			}
+			if ok {
+				resealed++
+			}
+		}
+
+		if len(stale) < resealBatchSize {
+			return resealed, nil
+		}
+	}
+}
+
+// reseal rewrites one credential under the current key; false when it changed meanwhile
+func (s *integrationService) reseal(ctx context.Context, credential *models.TenantIntegrationCredential) (bool, error) {
+	values, previous, err := s.open(credential)
+	if err != nil {
+		return false, err
+	}
+
+	if credential.Values, err = s.seal(credential.TenantID, credential.Integration, values); err != nil {
+		return false, err
+	}
+	if previous != nil {
+		sealed, err := s.seal(credential.TenantID, credential.Integration, previous)
+		if err != nil {
+			return false, err
+		}
+		credential.PreviousValues = &sealed
+	}
+	credential.KeyID = s.cipher.CurrentKey()
+
+	return s.repo.ResealCredential(ctx, credential)
+}
+
+// seal encrypts values bound to the tenant and integration, so a sealed value
+// copied to another row does not open
+func (s *integrationService) seal(tenantID uuid.UUID, integration string, values map[string]string) (string, error) {
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return s.cipher.Seal(plaintext, []byte(credentialKey(tenantID, integration)))
+}
+
+// open decrypts a credential's current and previous values
+func (s *integrationService) open(credential *models.TenantIntegrationCredential) (map[string]string, map[string]string, error) {
+	if s.cipher == nil {
+		return nil, nil, ErrCredentialEncryptionMissing
+	}
+
+	associatedData := []byte(credentialKey(credential.TenantID, credential.Integration))
+	openValues := func(sealed string) (map[string]string, error) {
+		plaintext, err := s.cipher.Open(sealed, associatedData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s credentials: %w", credential.Integration, err)
+		}
+		values := map[string]string{}
+		if err := json.Unmarshal(plaintext, &values); err != nil {
+			return nil, fmt.Errorf("failed to decode %s credentials: %w", credential.Integration, err)
+		}
+		return values, nil
+	}
+
+	values, err := openValues(credential.Values)
+	if err != nil {
+		return nil, nil, err
+	}
+	if credential.PreviousValues == nil {
+		return values, nil, nil
+	}
+	previous, err := openValues(*credential.PreviousValues)
+	if err != nil {
+		return nil, nil, err
+	}
+	return values, previous, nil
+}
+
+func credentialKey(tenantID uuid.UUID, integration string) string {
+	return tenantID.String() + "|" + integration
+}
+
+// integrationResponse describes the schema with the tenant's values, secrets masked
+func integrationResponse(schema IntegrationSchema, credential *models.TenantIntegrationCredential, values map[string]string) dto.IntegrationCredentialResponse {
+	response := dto.IntegrationCredentialResponse{
+		Integration: schema.Name,
+		Label:       schema.Label,
+		Configured:  credential != nil,
+		Fields:      make([]dto.IntegrationFieldResponse, 0, len(schema.Fields)),
+	}
+	for _, field := range schema.Fields {
+		value := values[field.Key]
+		if field.Secret {
+			value = maskSecret(value)
+		}
+		response.Fields = append(response.Fields, dto.IntegrationFieldResponse{
+			Key:      field.Key,
+			Label:    field.Label,
+			Secret:   field.Secret,
+			Required: field.Required,
+			IsSet:    values[field.Key] != "",
+			Value:    value,
+		})
+	}
+	if credential != nil {
+		response.Version = credential.Version
+		response.RotatedAt = credential.RotatedAt
+		if credential.PreviousExpiresAt != nil && time.Now().Before(*credential.PreviousExpiresAt) {
+			response.PreviousExpiresAt = credential.PreviousExpiresAt
+		}
+		response.UpdatedBy = credential.UpdatedBy
+		response.UpdatedAt = &credential.UpdatedAt
+	}
+	return response
+}
+
+// maskSecret shows the last four characters of secrets long enough not to give them away
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	runes := []rune(value)
+	if len(runes) < 12 {
+		return "••••"
+	}
+	return "••••" + string(runes[len(runes)-4:])
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/aceextension/core/logger"
+	"github.com/google/uuid"
+)
+
+// OTPPurpose is what a one-time code is for
+type OTPPurpose string
+
+const (
+	OTPPurposeRegistration  OTPPurpose = "REGISTRATION"
+	OTPPurposePasswordReset OTPPurpose = "PASSWORD_RESET"
+)
+
+// OTP is a one-time code on its way to a user
+type OTP struct {
+	TenantID  uuid.UUID // uuid.Nil for a user without a home tenant
+	UserID    uuid.UUID
+	Phone     string
+	Email     string // Set when the code should go by email instead of SMS
+	Code      string
+	Purpose   OTPPurpose
+	ExpiresAt time.Time
+}
+
+// OTPSender delivers one-time codes to users
+type OTPSender interface {
+	SendOTP(ctx context.Context, otp OTP) error
+}
+
+// LogOTPSender writes codes to the log instead of sending them, for development
+type LogOTPSender struct{}
+
+func (LogOTPSender) SendOTP(ctx context.Context, otp OTP) error {
+	to := otp.Phone
+	if otp.Email != "" {
+		to = otp.Email
+	}
+	logger.Log.Info(fmt.Sprintf("%s OTP for %s: %s (expires %s)", otp.Purpose, to, otp.Code, otp.ExpiresAt.Format(time.Kitchen)))
+	return nil
+}
+
+// generateOTP returns a 6-digit code from a cryptographically secure source
+func generateOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate OTP: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
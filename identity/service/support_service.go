@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/identity/dto"
+	"github.com/aceextension/identity/middleware"
+	"github.com/aceextension/identity/models"
+	"github.com/aceextension/identity/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// supportSessionRole is the role a support session views the tenant with: what
+	// the owner sees, limited to reads by the read:* scope and the support guard
+	supportSessionRole = "owner"
+
+	defaultSupportSessionMinutes = 30
+)
+
+// supportSessionScopes keep the session token read-only in every area
+var supportSessionScopes = []string{middleware.ScopeRead + ":" + middleware.ScopeAll}
+
+var (
+	ErrSupportTenantNotFound  = errors.New("tenant not found")
+	ErrSupportAccessDisabled  = errors.New("the tenant has not allowed support access")
+	ErrSupportSessionNotFound = errors.New("support session not found")
+	ErrSupportSessionClosed   = errors.New("support session has ended or expired")
+)
+
+type SupportSessionService interface {
+	// GetConsent and SetConsent are the tenant's toggle for support access
+	GetConsent(ctx context.Context, tenantID uuid.UUID) (*dto.SupportAccessResponse, error)
+	SetConsent(ctx context.Context, tenantID, actorID uuid.UUID, enabled bool) (*dto.SupportAccessResponse, error)
+
+	// StartSession issues a read-only token for the tenant to a super admin
+	StartSession(ctx context.Context, tenantID, supportUserID uuid.UUID, data dto.StartSupportSessionDTO) (*dto.SupportSessionTokenResponse, error)
+	EndSession(ctx context.Context, tenantID, sessionID, actorID uuid.UUID) error
+	ListSessions(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]dto.SupportSessionResponse, error)
+	ListActivity(ctx context.Context, tenantID, sessionID uuid.UUID, limit, offset int) ([]models.SupportAccessLog, error)
+
+	// AuthorizeSupport checks a support request against the session and the tenant's consent
+	AuthorizeSupport(ctx context.Context, sessionID, userID, tenantID uuid.UUID) error
+	// RecordSupportAccess writes the request to the session's trail; blocked
+	// mutation attempts are also flagged in the tenant's audit trail
+	RecordSupportAccess(ctx context.Context, entry *models.SupportAccessLog)
+}
+
+type supportSessionService struct {
+	authRepo    repository.AuthRepository
+	supportRepo repository.SupportSessionRepository
+}
+
+func NewSupportSessionService(authRepo repository.AuthRepository, supportRepo repository.SupportSessionRepository) SupportSessionService {
+	return &supportSessionService{
+		authRepo:    authRepo,
+		supportRepo: supportRepo,
+	}
+}
+
+func (s *supportSessionService) GetConsent(ctx context.Context, tenantID uuid.UUID) (*dto.SupportAccessResponse, error) {
+	consent, err := s.getConsent(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return toSupportAccessResponse(consent), nil
+}
+
+func (s *supportSessionService) SetConsent(ctx context.Context, tenantID, actorID uuid.UUID, enabled bool) (*dto.SupportAccessResponse, error) {
+	consent, err := s.getConsent(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if consent.Enabled == enabled {
+		return toSupportAccessResponse(consent), nil
+	}
+
+	now := time.Now()
+	consent.Enabled = enabled
+	consent.ChangedAt = &now
+	consent.ChangedBy = &actorID
+	if err := s.supportRepo.SetConsent(ctx, consent); err != nil {
+		return nil, err
+	}
+
+	action := "DISABLE_SUPPORT_ACCESS"
+	if enabled {
+		action = "ENABLE_SUPPORT_ACCESS"
+	}
+	s.audit(ctx, action, "Tenant", tenantID.String(), nil, tenantID, actorID)
+
+	return toSupportAccessResponse(consent), nil
+}
+
+func (s *supportSessionService) StartSession(ctx context.Context, tenantID, supportUserID uuid.UUID, data dto.StartSupportSessionDTO) (*dto.SupportSessionTokenResponse, error) {
+	consent, err := s.getConsent(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !consent.Enabled {
+		return nil, ErrSupportAccessDisabled
+	}
+
+	minutes := data.DurationMinutes
+	if minutes <= 0 {
+		minutes = defaultSupportSessionMinutes
+	}
+	session := &models.SupportSession{
+		TenantID:      tenantID,
+		SupportUserID: supportUserID,
+		Reason:        data.Reason,
+		ExpiresAt:     time.Now().Add(time.Duration(minutes) * time.Minute),
+	}
+	if err := s.supportRepo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := GenerateAccessToken(dto.TokenPayload{
+		UserID:           supportUserID,
+		TenantID:         &tenantID,
+		Role:             supportSessionRole,
+		Scopes:           supportSessionScopes,
+		SupportSessionID: &session.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, "START_SUPPORT_SESSION", "SupportSession", session.ID.String(), map[string]interface{}{
+		"reason":     session.Reason,
+		"expires_at": session.ExpiresAt,
+	}, tenantID, supportUserID)
+
+	return &dto.SupportSessionTokenResponse{
+		AccessToken: accessToken,
+		Session:     s.toSessionResponse(ctx, session, time.Now()),
+	}, nil
+}
+
+func (s *supportSessionService) EndSession(ctx context.Context, tenantID, sessionID, actorID uuid.UUID) error {
+	ended, err := s.supportRepo.EndSession(ctx, tenantID, sessionID, actorID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ended {
+		return ErrSupportSessionNotFound
+	}
+
+	s.audit(ctx, "END_SUPPORT_SESSION", "SupportSession", sessionID.String(), nil, tenantID, actorID)
+	return nil
+}
+
+func (s *supportSessionService) ListSessions(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]dto.SupportSessionResponse, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	sessions, err := s.supportRepo.ListSessions(ctx, tenantID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res := make([]dto.SupportSessionResponse, 0, len(sessions))
+	for i := range sessions {
+		res = append(res, s.toSessionResponse(ctx, &sessions[i], now))
+	}
+	return res, nil
+}
+
+func (s *supportSessionService) ListActivity(ctx context.Context, tenantID, sessionID uuid.UUID, limit, offset int) ([]models.SupportAccessLog, error) {
+	session, err := s.supportRepo.GetSession(ctx, sessionID)
+	if err != nil || session.TenantID != tenantID {
+		return nil, ErrSupportSessionNotFound
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.supportRepo.ListAccess(ctx, tenantID, sessionID, limit, offset)
+}
+
+func (s *supportSessionService) AuthorizeSupport(ctx context.Context, sessionID, userID, tenantID uuid.UUID) error {
+	session, err := s.supportRepo.GetSession(ctx, sessionID)
+	if err != nil || session.SupportUserID != userID || session.TenantID != tenantID {
+		return ErrSupportSessionClosed
+	}
+	if !session.IsOpen(time.Now()) {
+		return ErrSupportSessionClosed
+	}
+
+	// Consent is read on every request so turning it off takes effect at once
+	consent, err := s.supportRepo.GetConsent(ctx, tenantID)
+	if err != nil || !consent.Enabled {
+		return ErrSupportAccessDisabled
+	}
+	return nil
+}
+
+func (s *supportSessionService) RecordSupportAccess(ctx context.Context, entry *models.SupportAccessLog) {
+	if err := s.supportRepo.RecordAccess(ctx, entry); err != nil {
+		log.Printf("Failed to record support access for session %s: %v", entry.SessionID, err)
+	}
+
+	if entry.Action == "blocked" {
+		s.audit(ctx, "SUPPORT_WRITE_BLOCKED", "SupportSession", entry.SessionID.String(), map[string]interface{}{
+			"method": entry.Method,
+			"path":   entry.Path,
+		}, entry.TenantID, entry.SupportUserID)
+	}
+}
+
+func (s *supportSessionService) getConsent(ctx context.Context, tenantID uuid.UUID) (*models.SupportConsent, error) {
+	consent, err := s.supportRepo.GetConsent(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSupportTenantNotFound
+		}
+		return nil, err
+	}
+	return consent, nil
+}
+
+// audit records support activity in the tenant's own audit trail, so owners see
+// who looked and when
+func (s *supportSessionService) audit(ctx context.Context, action, entity, entityID string, details map[string]interface{}, tenantID, userID uuid.UUID) {
+	if audit.Service == nil {
+		return
+	}
+	audit.Service.Log(ctx, action, entity, &entityID, details, &auditDomain.AuditContext{TenantID: &tenantID, UserID: &userID})
+}
+
+func (s *supportSessionService) toSessionResponse(ctx context.Context, session *models.SupportSession, now time.Time) dto.SupportSessionResponse {
+	status := "active"
+	if session.EndedAt != nil {
+		status = "ended"
+	} else if !session.IsOpen(now) {
+		status = "expired"
+	}
+
+	name := ""
+	if user, err := s.authRepo.GetUserByID(ctx, session.SupportUserID); err == nil {
+		name = user.Name
+	}
+
+	return dto.SupportSessionResponse{
+		ID:              session.ID,
+		TenantID:        session.TenantID,
+		SupportUserID:   session.SupportUserID,
+		SupportUserName: name,
+		Reason:          session.Reason,
+		ExpiresAt:       session.ExpiresAt,
+		EndedAt:         session.EndedAt,
+		EndedBy:         session.EndedBy,
+		Status:          status,
+		CreatedAt:       session.CreatedAt,
+	}
+}
+
+func toSupportAccessResponse(consent *models.SupportConsent) *dto.SupportAccessResponse {
+	return &dto.SupportAccessResponse{
+		Enabled:   consent.Enabled,
+		ChangedAt: consent.ChangedAt,
+		ChangedBy: consent.ChangedBy,
+	}
+}
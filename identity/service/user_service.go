@@ -9,6 +9,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
 	"github.com/aceextension/core/db"
 	"github.com/aceextension/identity/dto"
 	"github.com/aceextension/identity/models"
@@ -120,6 +122,15 @@ func (s *userService) InviteUser(ctx context.Context, actorID uuid.UUID, tenantI
 		return nil, err
 	}
 
+	if audit.Service != nil {
+		entityIDStr := invite.ID.String()
+		audit.Service.Log(ctx, "INVITE_USER", "Invitation", &entityIDStr, map[string]interface{}{
+			"role":  invite.Role,
+			"email": invite.Email,
+			"phone": invite.Phone,
+		}, &auditDomain.AuditContext{TenantID: &tenantID, UserID: &actorID})
+	}
+
 	// In real app: Send notification (email/sms)
 	return invite, nil
 }
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockPolicy is how a tenant treats issues beyond what is on hand. Warehouses follow
+// it unless they set their own.
+type StockPolicy struct {
+	TenantID           uuid.UUID  `json:"tenantId" db:"tenant_id"`
+	AllowNegativeStock bool       `json:"allowNegativeStock" db:"allow_negative_stock"`
+	UpdatedAt          *time.Time `json:"updatedAt,omitempty" db:"updated_at"` // Nil until the tenant sets a policy
+}
+
+// DefaultStockPolicy lets levels go negative until the goods are received, as they
+// did before tenants could forbid it
+func DefaultStockPolicy(tenantID uuid.UUID) *StockPolicy {
+	return &StockPolicy{TenantID: tenantID, AllowNegativeStock: true}
+}
+
+// NewStockPolicy creates the tenant's policy
+func NewStockPolicy(tenantID uuid.UUID, allowNegativeStock bool) *StockPolicy {
+	now := time.Now()
+	return &StockPolicy{TenantID: tenantID, AllowNegativeStock: allowNegativeStock, UpdatedAt: &now}
+}
+
+// AllowsNegative reports whether a level in the warehouse may go below zero: the
+// warehouse's own setting, else the tenant's
+func (p *StockPolicy) AllowsNegative(warehouse *Warehouse) bool {
+	if warehouse.AllowNegativeStock != nil {
+		return *warehouse.AllowNegativeStock
+	}
+	return p.AllowNegativeStock
+}
+
+// ShortStockError is the ErrInsufficientStock of taking quantity of a product out of
+// a warehouse holding onHand
+func ShortStockError(productID uuid.UUID, onHand, quantity float64) error {
+	return fmt.Errorf("%w: product %s has %s on hand in the warehouse, %s to take out", ErrInsufficientStock,
+		productID, strconv.FormatFloat(onHand, 'f', -1, 64), strconv.FormatFloat(quantity, 'f', -1, 64))
+}
+
+// NegativeStockException sums the issues that left a product's level below zero in
+// a warehouse
+type NegativeStockException struct {
+	ProductID     uuid.UUID `json:"productId"`
+	WarehouseID   uuid.UUID `json:"warehouseId"`
+	Occurrences   int       `json:"occurrences"`   // Issues that left the level below zero
+	LowestBalance float64   `json:"lowestBalance"` // The furthest below zero it went
+	FirstAt       time.Time `json:"firstAt"`       // MovedAt of the first such issue
+	LastAt        time.Time `json:"lastAt"`        // MovedAt of the latest
+	Quantity      float64   `json:"quantity"`      // On hand now
+}
+
+// NegativeStockFilter selects negative stock exceptions
+type NegativeStockFilter struct {
+	ProductID   *uuid.UUID
+	WarehouseID *uuid.UUID
+	From        *time.Time // Issues moved on or after
+	To          *time.Time // Issues moved before
+	OpenOnly    bool       // Only levels still below zero
+	Limit       int
+	Offset      int
+}
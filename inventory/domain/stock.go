@@ -0,0 +1,220 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidMovement is returned for a movement with no product, a zero quantity or
+	// a quantity of the wrong sign for its type
+	ErrInvalidMovement = errors.New("invalid stock movement")
+	// ErrProductNotFound is returned when the product does not exist for the tenant
+	ErrProductNotFound = errors.New("product not found")
+	// ErrInsufficientStock is returned when a movement would take a level below zero
+	// in a warehouse whose policy forbids negative stock
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+// MainWarehouse stands for the tenant's default warehouse. Movements made with it,
+// as documents without a warehouse make them, are recorded in the default warehouse,
+// which is created on first use.
+var MainWarehouse = uuid.Nil
+
+// MovementType is the kind of stock movement
+type MovementType string
+
+const (
+	MovementIn         MovementType = "in"         // Goods received: purchases, production, returns from customers
+	MovementOut        MovementType = "out"        // Goods issued: sales, consumption, returns to suppliers
+	MovementAdjustment MovementType = "adjustment" // Corrections from stock counts, damage or loss, either way
+)
+
+// Valid reports whether the type is known
+func (t MovementType) Valid() bool {
+	return t == MovementIn || t == MovementOut || t == MovementAdjustment
+}
+
+// Reference types of the documents that move stock
+const (
+	ReferenceGoodsReceipt   = "goods_receipt"
+	ReferencePurchaseReturn = "purchase_return"
+	ReferenceInvoice        = "invoice"
+	ReferenceInvoiceVoid    = "invoice_void"
+	ReferenceAssemblyOrder  = "assembly_order"
+	ReferenceWarrantyClaim  = "warranty_claim"
+	ReferenceStockTransfer  = "stock_transfer"
+)
+
+// StockMovement is one entry of the stock ledger. Quantity is the signed change to
+// the stock level: positive in, negative out, either for an adjustment. A level is
+// always the sum of its movements.
+type StockMovement struct {
+	ID              uuid.UUID    `json:"id" db:"id"`
+	TenantID        uuid.UUID    `json:"tenantId" db:"tenant_id"`
+	ProductID       uuid.UUID    `json:"productId" db:"product_id"`
+	WarehouseID     uuid.UUID    `json:"warehouseId" db:"warehouse_id"`
+	Type            MovementType `json:"type" db:"type"`
+	Quantity        float64      `json:"quantity" db:"quantity"`
+	UnitCost        *float64     `json:"unitCost,omitempty" db:"unit_cost"`
+	BalanceAfter    float64      `json:"balanceAfter" db:"balance_after"`         // Level of the product in the warehouse after this movement
+	AverageCost     *float64     `json:"averageCost,omitempty" db:"average_cost"` // Moving average unit cost of the level after this movement
+	ReferenceType   *string      `json:"referenceType,omitempty" db:"reference_type"`
+	ReferenceID     *uuid.UUID   `json:"referenceId,omitempty" db:"reference_id"`
+	ReferenceNumber *string      `json:"referenceNumber,omitempty" db:"reference_number"`
+	Note            *string      `json:"note,omitempty" db:"note"`
+	MovedAt         time.Time    `json:"movedAt" db:"moved_at"`
+	CreatedBy       *uuid.UUID   `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt       time.Time    `json:"createdAt" db:"created_at"`
+}
+
+// NewStockMovement creates a movement of quantity, given as a positive amount for
+// receipts and issues and as the signed change for adjustments
+func NewStockMovement(tenantID, productID uuid.UUID, movementType MovementType, quantity float64) (*StockMovement, error) {
+	quantity = roundQuantity(quantity)
+	if productID == uuid.Nil || !movementType.Valid() || quantity == 0 || math.IsNaN(quantity) || math.IsInf(quantity, 0) {
+		return nil, ErrInvalidMovement
+	}
+	if movementType != MovementAdjustment && quantity < 0 {
+		return nil, ErrInvalidMovement
+	}
+	if movementType == MovementOut {
+		quantity = -quantity
+	}
+
+	now := time.Now()
+	return &StockMovement{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		ProductID:   productID,
+		WarehouseID: MainWarehouse,
+		Type:        movementType,
+		Quantity:    quantity,
+		MovedAt:     now,
+		CreatedAt:   now,
+	}, nil
+}
+
+// SetReference records the document that moved the stock
+func (m *StockMovement) SetReference(referenceType string, referenceID uuid.UUID, number string) {
+	m.ReferenceType = &referenceType
+	m.ReferenceID = &referenceID
+	if number != "" {
+		m.ReferenceNumber = &number
+	}
+}
+
+// StockLevel is how much of a product is on hand in a warehouse
+type StockLevel struct {
+	TenantID    uuid.UUID `json:"tenantId" db:"tenant_id"`
+	ProductID   uuid.UUID `json:"productId" db:"product_id"`
+	WarehouseID uuid.UUID `json:"warehouseId" db:"warehouse_id"`
+	Quantity    float64   `json:"quantity" db:"quantity"`                  // Negative when more was issued than received
+	AverageCost *float64  `json:"averageCost,omitempty" db:"average_cost"` // Nil until goods are received at a cost
+	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// Apply moves the level by a movement and sets the movement's balance and average
+// cost after it. Goods received at a cost are averaged into what is on hand; a level
+// at or below zero takes the cost of what comes in. Issues, and receipts without a
+// cost, go at the average and leave it as it is.
+func (l *StockLevel) Apply(m *StockMovement) {
+	if m.Quantity > 0 && m.UnitCost != nil {
+		cost := *m.UnitCost
+		if l.Quantity > 0 && l.AverageCost != nil {
+			cost = (l.Quantity**l.AverageCost + m.Quantity**m.UnitCost) / (l.Quantity + m.Quantity)
+		}
+		cost = roundCost(cost)
+		l.AverageCost = &cost
+	}
+	l.Quantity = roundQuantity(l.Quantity + m.Quantity)
+
+	m.BalanceAfter = l.Quantity
+	m.AverageCost = nil
+	if l.AverageCost != nil {
+		cost := *l.AverageCost
+		m.AverageCost = &cost
+	}
+}
+
+// StockCorrection is a backdated adjustment and the later movements it restated
+type StockCorrection struct {
+	Movement *StockMovement `json:"movement"`
+	Restated int            `json:"restated"` // Movements whose balance or average cost changed
+}
+
+// Availability is what of a product can still be promised: on hand over all
+// warehouses, less what sales orders and quotes have reserved. For one warehouse,
+// OnHand is what is there and Available is capped by it.
+type Availability struct {
+	ProductID   uuid.UUID    `json:"productId"`
+	WarehouseID *uuid.UUID   `json:"warehouseId,omitempty"`
+	OnHand      float64      `json:"onHand"`
+	Reserved    float64      `json:"reserved"`
+	Available   float64      `json:"available"` // Never below zero
+	Levels      []StockLevel `json:"levels"`
+}
+
+// NewAvailability sums a product's levels and takes off the reserved quantity
+func NewAvailability(productID uuid.UUID, levels []StockLevel, reserved float64) *Availability {
+	availability := &Availability{ProductID: productID, Reserved: reserved, Levels: levels}
+	for _, level := range levels {
+		availability.OnHand += level.Quantity
+	}
+	availability.OnHand = roundQuantity(availability.OnHand)
+	availability.Available = math.Max(roundQuantity(availability.OnHand-reserved), 0)
+	return availability
+}
+
+// NewWarehouseAvailability is what of a product can be promised from one warehouse.
+// Reservations are not held per warehouse, so they come off the total: the warehouse
+// can give no more than it has, nor more than is unreserved over all warehouses.
+func NewWarehouseAvailability(productID, warehouseID uuid.UUID, levels []StockLevel, reserved float64) *Availability {
+	total := NewAvailability(productID, levels, reserved)
+
+	availability := &Availability{ProductID: productID, WarehouseID: &warehouseID, Reserved: reserved, Levels: []StockLevel{}}
+	for _, level := range levels {
+		if level.WarehouseID == warehouseID {
+			availability.OnHand = roundQuantity(level.Quantity)
+			availability.Levels = append(availability.Levels, level)
+		}
+	}
+	availability.Available = math.Max(math.Min(availability.OnHand, total.Available), 0)
+	return availability
+}
+
+// StockLevelFilter selects stock levels
+type StockLevelFilter struct {
+	ProductID   *uuid.UUID
+	WarehouseID *uuid.UUID
+	NonZero     bool // Skip levels that have come back to zero
+	Limit       int
+	Offset      int
+}
+
+// StockMovementFilter selects ledger entries
+type StockMovementFilter struct {
+	ProductID     *uuid.UUID
+	WarehouseID   *uuid.UUID
+	Type          *MovementType
+	ReferenceType *string
+	ReferenceID   *uuid.UUID
+	From          *time.Time // MovedAt on or after
+	To            *time.Time // MovedAt before
+	BelowZero     bool       // Only issues that left the level below zero
+	Limit         int
+	Offset        int
+}
+
+// roundQuantity rounds to the three decimals quantities are stored with
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
+
+// roundCost rounds to the four decimals unit costs are stored with
+func roundCost(cost float64) float64 {
+	return math.Round(cost*10000) / 10000
+}
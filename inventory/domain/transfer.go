@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidTransfer is returned for a transfer within one warehouse, without lines
+	// or with a line of no quantity
+	ErrInvalidTransfer = errors.New("invalid stock transfer")
+	// ErrTransferNotFound is returned when a transfer does not exist for the tenant
+	ErrTransferNotFound = errors.New("stock transfer not found")
+)
+
+// MaxTransferLines is how many products one transfer can move
+const MaxTransferLines = 500
+
+// StockTransfer moves products from one warehouse to another. It is posted as an
+// out movement from the source and an in movement to the destination per line,
+// together, so stock is never in neither or both.
+type StockTransfer struct {
+	ID              uuid.UUID      `json:"id" db:"id"`
+	TenantID        uuid.UUID      `json:"tenantId" db:"tenant_id"`
+	TransferNumber  string         `json:"transferNumber" db:"transfer_number"` // TRF-00001
+	FromWarehouseID uuid.UUID      `json:"fromWarehouseId" db:"from_warehouse_id"`
+	ToWarehouseID   uuid.UUID      `json:"toWarehouseId" db:"to_warehouse_id"`
+	Note            *string        `json:"note,omitempty" db:"note"`
+	TransferredAt   time.Time      `json:"transferredAt" db:"transferred_at"`
+	CreatedBy       *uuid.UUID     `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt       time.Time      `json:"createdAt" db:"created_at"`
+	Lines           []TransferLine `json:"lines"`
+}
+
+// TransferLine is a quantity of a product moved
+type TransferLine struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	ProductID uuid.UUID `json:"productId" db:"product_id"`
+	Quantity  float64   `json:"quantity" db:"quantity"`
+}
+
+// NewStockTransfer creates a transfer between two warehouses
+func NewStockTransfer(tenantID, fromWarehouseID, toWarehouseID uuid.UUID, transferredAt time.Time) *StockTransfer {
+	if transferredAt.IsZero() {
+		transferredAt = time.Now()
+	}
+	return &StockTransfer{
+		ID:              uuid.New(),
+		TenantID:        tenantID,
+		FromWarehouseID: fromWarehouseID,
+		ToWarehouseID:   toWarehouseID,
+		TransferredAt:   transferredAt,
+		CreatedAt:       time.Now(),
+		Lines:           []TransferLine{},
+	}
+}
+
+// AddLine moves a positive quantity of a product; a product listed twice is moved once
+// with the quantities combined
+func (t *StockTransfer) AddLine(productID uuid.UUID, quantity float64) error {
+	quantity = roundQuantity(quantity)
+	if productID == uuid.Nil || quantity <= 0 || math.IsNaN(quantity) || math.IsInf(quantity, 0) {
+		return ErrInvalidTransfer
+	}
+	for i := range t.Lines {
+		if t.Lines[i].ProductID == productID {
+			t.Lines[i].Quantity = roundQuantity(t.Lines[i].Quantity + quantity)
+			return nil
+		}
+	}
+	t.Lines = append(t.Lines, TransferLine{ID: uuid.New(), ProductID: productID, Quantity: quantity})
+	return nil
+}
+
+// Validate checks the transfer moves something between two different warehouses
+func (t *StockTransfer) Validate() error {
+	if t.FromWarehouseID == t.ToWarehouseID || len(t.Lines) == 0 || len(t.Lines) > MaxTransferLines {
+		return ErrInvalidTransfer
+	}
+	return nil
+}
+
+// Movements returns the ledger entries of the transfer: each line out of the source
+// and into the destination, referencing the transfer
+func (t *StockTransfer) Movements() ([]*StockMovement, error) {
+	movements := make([]*StockMovement, 0, 2*len(t.Lines))
+	for _, line := range t.Lines {
+		for _, leg := range []struct {
+			movementType MovementType
+			warehouseID  uuid.UUID
+		}{{MovementOut, t.FromWarehouseID}, {MovementIn, t.ToWarehouseID}} {
+			movement, err := NewStockMovement(t.TenantID, line.ProductID, leg.movementType, line.Quantity)
+			if err != nil {
+				return nil, err
+			}
+			movement.WarehouseID = leg.warehouseID
+			movement.MovedAt = t.TransferredAt
+			movement.Note = t.Note
+			movement.CreatedBy = t.CreatedBy
+			movement.SetReference(ReferenceStockTransfer, t.ID, t.TransferNumber)
+			movements = append(movements, movement)
+		}
+	}
+	return movements, nil
+}
+
+// StockTransferFilter selects transfers
+type StockTransferFilter struct {
+	WarehouseID *uuid.UUID // From or to
+	From        *time.Time // TransferredAt on or after
+	To          *time.Time // TransferredAt before
+	Limit       int
+	Offset      int
+}
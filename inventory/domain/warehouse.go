@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrWarehouseNotFound is returned when a warehouse does not exist for the tenant
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	// ErrInvalidWarehouse is returned for a warehouse without a code or name
+	ErrInvalidWarehouse = errors.New("invalid warehouse")
+	// ErrWarehouseCodeExists is returned when another of the tenant's warehouses has the code
+	ErrWarehouseCodeExists = errors.New("warehouse code already exists")
+	// ErrWarehouseInactive is returned when moving stock in or out of a deactivated warehouse
+	ErrWarehouseInactive = errors.New("warehouse is inactive")
+	// ErrWarehouseNotEmpty is returned when deactivating a warehouse that still holds stock
+	ErrWarehouseNotEmpty = errors.New("warehouse still holds stock")
+	// ErrDefaultWarehouse is returned when deactivating the tenant's default warehouse
+	ErrDefaultWarehouse = errors.New("the default warehouse cannot be deactivated")
+)
+
+// DefaultWarehouseCode is the code of the default warehouse created on first use
+const DefaultWarehouseCode = "MAIN"
+
+// maxWarehouseCodeLength matches the warehouses.code column
+const maxWarehouseCodeLength = 20
+
+// Warehouse is a place stock is kept: an outlet, a store room or a godown. Every
+// tenant has one default warehouse that documents without a warehouse move stock in.
+type Warehouse struct {
+	ID                 uuid.UUID `json:"id" db:"id"`
+	TenantID           uuid.UUID `json:"tenantId" db:"tenant_id"`
+	Code               string    `json:"code" db:"code"` // Unique per tenant, stored upper case
+	Name               string    `json:"name" db:"name"`
+	Address            *string   `json:"address,omitempty" db:"address"`
+	IsDefault          bool      `json:"isDefault" db:"is_default"`
+	IsActive           bool      `json:"isActive" db:"is_active"`
+	AllowNegativeStock *bool     `json:"allowNegativeStock,omitempty" db:"allow_negative_stock"` // Overrides the tenant's stock policy; nil follows it
+	CreatedAt          time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt          time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// NewWarehouse creates an active warehouse
+func NewWarehouse(tenantID uuid.UUID, code, name string) *Warehouse {
+	now := time.Now()
+	return &Warehouse{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Code:      NormalizeWarehouseCode(code),
+		Name:      strings.TrimSpace(name),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NormalizeWarehouseCode trims and upper-cases a code so "ktm-1" and "KTM-1" are one warehouse
+func NormalizeWarehouseCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks the code and name
+func (w *Warehouse) Validate() error {
+	if w.Code == "" || len(w.Code) > maxWarehouseCodeLength {
+		return fmt.Errorf("%w: code is required, at most %d characters", ErrInvalidWarehouse, maxWarehouseCodeLength)
+	}
+	if w.Name == "" || len(w.Name) > 255 {
+		return fmt.Errorf("%w: name is required", ErrInvalidWarehouse)
+	}
+	return nil
+}
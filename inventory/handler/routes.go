@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"github.com/aceextension/core/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes registers all inventory routes
+func RegisterRoutes(e *echo.Echo) {
+	// Create handlers
+	stockHandler := NewStockHandler()
+	warehouseHandler := NewWarehouseHandler()
+	policyHandler := NewStockPolicyHandler()
+
+	// API v1 group
+	v1 := e.Group("/api/v1")
+
+	// Apply tenant middleware
+	v1.Use(middleware.TenantMiddleware)
+
+	// Stock routes
+	inventory := v1.Group("/inventory")
+	{
+		inventory.GET("/stock", stockHandler.ListLevels)
+		inventory.GET("/stock/:productId", stockHandler.GetAvailable)
+		inventory.GET("/movements", stockHandler.ListMovements)
+		inventory.POST("/receipts", stockHandler.Receive)
+		inventory.POST("/issues", stockHandler.Issue)
+		inventory.POST("/adjustments", stockHandler.Adjust)
+		inventory.POST("/corrections", stockHandler.Correct)
+		inventory.POST("/transfers", stockHandler.Transfer)
+		inventory.GET("/transfers", stockHandler.ListTransfers)
+		inventory.GET("/transfers/:id", stockHandler.GetTransfer)
+		inventory.GET("/policy", policyHandler.GetPolicy)
+		inventory.PUT("/policy", policyHandler.SetPolicy)
+		inventory.GET("/negative-stock", policyHandler.ListNegativeStock)
+	}
+
+	// Warehouse routes
+	warehouses := inventory.Group("/warehouses")
+	{
+		warehouses.POST("", warehouseHandler.Create)
+		warehouses.GET("", warehouseHandler.List)
+		warehouses.GET("/:id", warehouseHandler.Get)
+		warehouses.PUT("/:id", warehouseHandler.Update)
+		warehouses.GET("/:id/stock", warehouseHandler.ListStock)
+	}
+}
@@ -0,0 +1,512 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/inventory"
+	"github.com/aceextension/inventory/domain"
+	"github.com/aceextension/inventory/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// StockHandler handles HTTP requests for stock levels and the stock ledger
+type StockHandler struct{}
+
+// NewStockHandler creates a new stock handler
+func NewStockHandler() *StockHandler {
+	return &StockHandler{}
+}
+
+// StockMovementRequest moves stock by hand
+type StockMovementRequest struct {
+	ProductID   uuid.UUID  `json:"productId" validate:"required"`
+	WarehouseID *uuid.UUID `json:"warehouseId,omitempty"`        // Defaults to the default warehouse
+	Quantity    float64    `json:"quantity" validate:"required"` // Positive; signed for adjustments
+	UnitCost    *float64   `json:"unitCost,omitempty"`
+	MovedAt     string     `json:"movedAt,omitempty"` // YYYY-MM-DD; defaults to now
+	Note        *string    `json:"note,omitempty"`
+}
+
+// StockTransferRequest moves products between warehouses
+type StockTransferRequest struct {
+	FromWarehouseID uuid.UUID                  `json:"fromWarehouseId" validate:"required"`
+	ToWarehouseID   uuid.UUID                  `json:"toWarehouseId" validate:"required"`
+	TransferredAt   string                     `json:"transferredAt,omitempty"` // YYYY-MM-DD; defaults to now
+	Note            *string                    `json:"note,omitempty"`
+	Lines           []StockTransferLineRequest `json:"lines" validate:"required,min=1"`
+}
+
+// StockTransferLineRequest is a quantity of a product moved
+type StockTransferLineRequest struct {
+	ProductID uuid.UUID `json:"productId" validate:"required"`
+	Quantity  float64   `json:"quantity" validate:"required,gt=0"`
+}
+
+// ListLevels godoc
+// @Summary List stock levels
+// @Description Get the quantity on hand per product and warehouse
+// @Tags inventory
+// @Produce json
+// @Param productId query string false "Product ID"
+// @Param warehouseId query string false "Warehouse ID"
+// @Param nonZero query bool false "Skip levels at zero"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.StockLevel
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/inventory/stock [get]
+// @Security BearerAuth
+func (h *StockHandler) ListLevels(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	filter := domain.StockLevelFilter{NonZero: c.QueryParam("nonZero") == "true"}
+	filter.Limit, filter.Offset = page(c)
+
+	if value := c.QueryParam("productId"); value != "" {
+		productID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid productId"})
+		}
+		filter.ProductID = &productID
+	}
+	if value := c.QueryParam("warehouseId"); value != "" {
+		warehouseID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warehouseId"})
+		}
+		filter.WarehouseID = &warehouseID
+	}
+
+	levels, err := inventory.StockService.Levels(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, levels)
+}
+
+// GetAvailable godoc
+// @Summary Get product availability
+// @Description Get a product's stock on hand per warehouse, what is reserved for orders and quotes, and what is available.
+// @Description With a warehouse, what is on hand there and what of it can be promised.
+// @Tags inventory
+// @Produce json
+// @Param productId path string true "Product ID"
+// @Param warehouseId query string false "Warehouse ID"
+// @Success 200 {object} domain.Availability
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/inventory/stock/{productId} [get]
+// @Security BearerAuth
+func (h *StockHandler) GetAvailable(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if value := c.QueryParam("warehouseId"); value != "" {
+		warehouseID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warehouseId"})
+		}
+		availability, err := inventory.StockService.GetAvailableAt(c.Request().Context(), tenantID, productID, warehouseID)
+		if err != nil {
+			return stockError(c, err)
+		}
+		return c.JSON(http.StatusOK, availability)
+	}
+
+	availability, err := inventory.StockService.GetAvailable(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, availability)
+}
+
+// ListMovements godoc
+// @Summary List stock movements
+// @Description Get the stock ledger, newest first, optionally for a product, a warehouse, a movement type, a document or a date range
+// @Tags inventory
+// @Produce json
+// @Param productId query string false "Product ID"
+// @Param warehouseId query string false "Warehouse ID"
+// @Param type query string false "in, out or adjustment"
+// @Param referenceType query string false "goods_receipt, invoice, invoice_void, purchase_return, assembly_order, warranty_claim or stock_transfer"
+// @Param referenceId query string false "Document ID"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date, inclusive (YYYY-MM-DD)"
+// @Param belowZero query bool false "Only issues that left the level below zero"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.StockMovement
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/inventory/movements [get]
+// @Security BearerAuth
+func (h *StockHandler) ListMovements(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	filter := domain.StockMovementFilter{BelowZero: c.QueryParam("belowZero") == "true"}
+	filter.Limit, filter.Offset = page(c)
+
+	if value := c.QueryParam("productId"); value != "" {
+		productID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid productId"})
+		}
+		filter.ProductID = &productID
+	}
+	if value := c.QueryParam("warehouseId"); value != "" {
+		warehouseID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warehouseId"})
+		}
+		filter.WarehouseID = &warehouseID
+	}
+	if value := c.QueryParam("type"); value != "" {
+		movementType := domain.MovementType(value)
+		if !movementType.Valid() {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid type, expected in, out or adjustment"})
+		}
+		filter.Type = &movementType
+	}
+	if value := c.QueryParam("referenceType"); value != "" {
+		filter.ReferenceType = &value
+	}
+	if value := c.QueryParam("referenceId"); value != "" {
+		referenceID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid referenceId"})
+		}
+		filter.ReferenceID = &referenceID
+	}
+	if value := c.QueryParam("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from, expected YYYY-MM-DD"})
+		}
+		filter.From = &from
+	}
+	if value := c.QueryParam("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to, expected YYYY-MM-DD"})
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	movements, err := inventory.StockService.Movements(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, movements)
+}
+
+// Receive godoc
+// @Summary Receive stock
+// @Description Put a quantity of a product into stock, e.g. opening stock
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param movement body StockMovementRequest true "Quantity received"
+// @Success 201 {object} domain.StockMovement
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/inventory/receipts [post]
+// @Security BearerAuth
+func (h *StockHandler) Receive(c echo.Context) error {
+	return move(c, inventory.StockService.Receive)
+}
+
+// Issue godoc
+// @Summary Issue stock
+// @Description Take a quantity of a product out of stock, e.g. for internal use. Refused for a short level where the
+// @Description warehouse's stock policy forbids negative stock.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param movement body StockMovementRequest true "Quantity issued"
+// @Success 201 {object} domain.StockMovement
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/inventory/issues [post]
+// @Security BearerAuth
+func (h *StockHandler) Issue(c echo.Context) error {
+	return move(c, inventory.StockService.Issue)
+}
+
+// Adjust godoc
+// @Summary Adjust stock
+// @Description Correct a product's stock by a signed quantity, e.g. after a count or for damage
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param movement body StockMovementRequest true "Signed quantity"
+// @Success 201 {object} domain.StockMovement
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/inventory/adjustments [post]
+// @Security BearerAuth
+func (h *StockHandler) Adjust(c echo.Context) error {
+	return move(c, inventory.StockService.Adjust)
+}
+
+// Correct godoc
+// @Summary Correct stock as of a past date
+// @Description Adjust a product's stock by a signed quantity as of movedAt, e.g. for a receipt or count missed at the
+// @Description time. The product's ledger in the warehouse is replayed in date order and the balance and moving
+// @Description average cost of every later movement restated. Audit logged as CORRECT_STOCK.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param movement body StockMovementRequest true "Signed quantity and the past movedAt"
+// @Success 201 {object} domain.StockCorrection
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/inventory/corrections [post]
+// @Security BearerAuth
+func (h *StockHandler) Correct(c echo.Context) error {
+	return move(c, inventory.StockService.Correct)
+}
+
+// move binds a movement request and records it with record
+func move[T any](c echo.Context, record func(ctx context.Context, input service.MovementInput) (T, error)) error {
+	var req StockMovementRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	input := service.MovementInput{
+		TenantID:  tenantID,
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		UnitCost:  req.UnitCost,
+		Note:      req.Note,
+		CreatedBy: optionalUserID(c),
+	}
+	if req.WarehouseID != nil {
+		input.WarehouseID = *req.WarehouseID
+	}
+	if req.MovedAt != "" {
+		movedAt, err := time.ParseInLocation("2006-01-02", req.MovedAt, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid movedAt, expected YYYY-MM-DD"})
+		}
+		input.MovedAt = movedAt
+	}
+
+	recorded, err := record(c.Request().Context(), input)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, recorded)
+}
+
+// Transfer godoc
+// @Summary Transfer stock
+// @Description Move products from one warehouse to another. Each line goes out of the source and into the destination
+// @Description together; the transfer is numbered TRF-00001 onwards.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param transfer body StockTransferRequest true "Stock transfer"
+// @Success 201 {object} domain.StockTransfer
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/inventory/transfers [post]
+// @Security BearerAuth
+func (h *StockHandler) Transfer(c echo.Context) error {
+	var req StockTransferRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var transferredAt time.Time
+	if req.TransferredAt != "" {
+		var err error
+		transferredAt, err = time.ParseInLocation("2006-01-02", req.TransferredAt, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid transferredAt, expected YYYY-MM-DD"})
+		}
+	}
+
+	transfer := domain.NewStockTransfer(tenantID, req.FromWarehouseID, req.ToWarehouseID, transferredAt)
+	transfer.Note = req.Note
+	transfer.CreatedBy = optionalUserID(c)
+	for _, line := range req.Lines {
+		if err := transfer.AddLine(line.ProductID, line.Quantity); err != nil {
+			return stockError(c, err)
+		}
+	}
+
+	if err := inventory.StockService.Transfer(c.Request().Context(), transfer); err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, transfer)
+}
+
+// ListTransfers godoc
+// @Summary List stock transfers
+// @Description Get transfers without their lines, newest first, optionally from or to a warehouse or in a date range
+// @Tags inventory
+// @Produce json
+// @Param warehouseId query string false "Warehouse moved from or to"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date, inclusive (YYYY-MM-DD)"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.StockTransfer
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/inventory/transfers [get]
+// @Security BearerAuth
+func (h *StockHandler) ListTransfers(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	filter := domain.StockTransferFilter{}
+	filter.Limit, filter.Offset = page(c)
+
+	if value := c.QueryParam("warehouseId"); value != "" {
+		warehouseID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warehouseId"})
+		}
+		filter.WarehouseID = &warehouseID
+	}
+	if value := c.QueryParam("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from, expected YYYY-MM-DD"})
+		}
+		filter.From = &from
+	}
+	if value := c.QueryParam("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to, expected YYYY-MM-DD"})
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	transfers, err := inventory.StockService.ListTransfers(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, transfers)
+}
+
+// GetTransfer godoc
+// @Summary Get a stock transfer
+// @Description Get a transfer with its lines
+// @Tags inventory
+// @Produce json
+// @Param id path string true "Stock transfer ID"
+// @Success 200 {object} domain.StockTransfer
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/inventory/transfers/{id} [get]
+// @Security BearerAuth
+func (h *StockHandler) GetTransfer(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid stock transfer ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	transfer, err := inventory.StockService.GetTransfer(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// page reads limit (default 50) and offset from the query string
+func page(c echo.Context) (int, int) {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// optionalUserID returns the calling user, if known
+func optionalUserID(c echo.Context) *uuid.UUID {
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		return &userID
+	}
+	return nil
+}
+
+// stockError maps inventory domain errors to HTTP responses
+func stockError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, domain.ErrWarehouseNotFound),
+		errors.Is(err, domain.ErrTransferNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrWarehouseCodeExists), errors.Is(err, domain.ErrWarehouseInactive),
+		errors.Is(err, domain.ErrWarehouseNotEmpty), errors.Is(err, domain.ErrDefaultWarehouse),
+		errors.Is(err, domain.ErrInsufficientStock):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidMovement), errors.Is(err, domain.ErrInvalidTransfer),
+		errors.Is(err, domain.ErrInvalidWarehouse):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
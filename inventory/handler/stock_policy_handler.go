@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/inventory"
+	"github.com/aceextension/inventory/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// StockPolicyHandler handles HTTP requests for the negative stock policy and its exceptions
+type StockPolicyHandler struct{}
+
+// NewStockPolicyHandler creates a new stock policy handler
+func NewStockPolicyHandler() *StockPolicyHandler {
+	return &StockPolicyHandler{}
+}
+
+// StockPolicyRequest sets the tenant's stock policy
+type StockPolicyRequest struct {
+	AllowNegativeStock bool `json:"allowNegativeStock"`
+}
+
+// GetPolicy godoc
+// @Summary Get the stock policy
+// @Description Whether issues may take stock below zero. Warehouses follow it unless they set allowNegativeStock
+// @Description themselves; tenants that never set it allow negative stock.
+// @Tags inventory
+// @Produce json
+// @Success 200 {object} domain.StockPolicy
+// @Router /api/v1/inventory/policy [get]
+// @Security BearerAuth
+func (h *StockPolicyHandler) GetPolicy(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	policy, err := inventory.StockService.Policy(c.Request().Context(), tenantID)
+	if err != nil {
+		return stockError(c, err)
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+// SetPolicy godoc
+// @Summary Set the stock policy
+// @Description Allow or forbid negative stock for the tenant. Where it is forbidden, issues, transfers and invoices
+// @Description that would take a level below zero are refused; levels already below zero stay so until goods are
+// @Description received. Audit logged as SET_STOCK_POLICY.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param policy body StockPolicyRequest true "Stock policy"
+// @Success 200 {object} domain.StockPolicy
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/inventory/policy [put]
+// @Security BearerAuth
+func (h *StockPolicyHandler) SetPolicy(c echo.Context) error {
+	var req StockPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	policy, err := inventory.StockService.SetPolicy(c.Request().Context(), tenantID, req.AllowNegativeStock, optionalUserID(c))
+	if err != nil {
+		return stockError(c, err)
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+// ListNegativeStock godoc
+// @Summary List negative stock exceptions
+// @Description Per product and warehouse, the issues that left the level below zero: how many, how far below zero it
+// @Description went, when, and what is on hand now; latest first. The issues themselves are in the stock ledger with
+// @Description belowZero=true.
+// @Tags inventory
+// @Produce json
+// @Param productId query string false "Product ID"
+// @Param warehouseId query string false "Warehouse ID"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date, inclusive (YYYY-MM-DD)"
+// @Param openOnly query bool false "Only levels still below zero"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.NegativeStockException
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/inventory/negative-stock [get]
+// @Security BearerAuth
+func (h *StockPolicyHandler) ListNegativeStock(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	filter := domain.NegativeStockFilter{OpenOnly: c.QueryParam("openOnly") == "true"}
+	filter.Limit, filter.Offset = page(c)
+
+	if value := c.QueryParam("productId"); value != "" {
+		productID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid productId"})
+		}
+		filter.ProductID = &productID
+	}
+	if value := c.QueryParam("warehouseId"); value != "" {
+		warehouseID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warehouseId"})
+		}
+		filter.WarehouseID = &warehouseID
+	}
+	if value := c.QueryParam("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from, expected YYYY-MM-DD"})
+		}
+		filter.From = &from
+	}
+	if value := c.QueryParam("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to, expected YYYY-MM-DD"})
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	exceptions, err := inventory.StockService.NegativeExceptions(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return stockError(c, err)
+	}
+	return c.JSON(http.StatusOK, exceptions)
+}
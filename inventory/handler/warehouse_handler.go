@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/inventory"
+	"github.com/aceextension/inventory/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// WarehouseHandler handles HTTP requests for warehouses
+type WarehouseHandler struct{}
+
+// NewWarehouseHandler creates a new warehouse handler
+func NewWarehouseHandler() *WarehouseHandler {
+	return &WarehouseHandler{}
+}
+
+// WarehouseRequest creates or updates a warehouse
+type WarehouseRequest struct {
+	Code     string  `json:"code" validate:"required,max=20"`
+	Name     string  `json:"name" validate:"required,max=255"`
+	Address  *string `json:"address,omitempty"`
+	IsActive *bool   `json:"isActive,omitempty"` // Updates only; defaults to active
+	// AllowNegativeStock overrides the tenant's stock policy in the warehouse; leave
+	// it out to follow the tenant's
+	AllowNegativeStock *bool `json:"allowNegativeStock,omitempty"`
+}
+
+// Create godoc
+// @Summary Create a warehouse
+// @Description Add a stock location such as an outlet or a godown. The tenant's default warehouse (MAIN) is created
+// @Description first if it has none.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param warehouse body WarehouseRequest true "Warehouse"
+// @Success 201 {object} domain.Warehouse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/inventory/warehouses [post]
+// @Security BearerAuth
+func (h *WarehouseHandler) Create(c echo.Context) error {
+	var req WarehouseRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	warehouse := domain.NewWarehouse(tenantID, req.Code, req.Name)
+	warehouse.Address = req.Address
+	warehouse.AllowNegativeStock = req.AllowNegativeStock
+
+	if err := inventory.WarehouseService.Create(c.Request().Context(), warehouse, optionalUserID(c)); err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, warehouse)
+}
+
+// List godoc
+// @Summary List warehouses
+// @Description Get the tenant's warehouses, the default first
+// @Tags inventory
+// @Produce json
+// @Param activeOnly query bool false "Only active warehouses"
+// @Success 200 {array} domain.Warehouse
+// @Router /api/v1/inventory/warehouses [get]
+// @Security BearerAuth
+func (h *WarehouseHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	warehouses, err := inventory.WarehouseService.List(c.Request().Context(), tenantID, c.QueryParam("activeOnly") == "true")
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, warehouses)
+}
+
+// Get godoc
+// @Summary Get a warehouse
+// @Description Get a warehouse by ID
+// @Tags inventory
+// @Produce json
+// @Param id path string true "Warehouse ID"
+// @Success 200 {object} domain.Warehouse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/inventory/warehouses/{id} [get]
+// @Security BearerAuth
+func (h *WarehouseHandler) Get(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warehouse ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	warehouse, err := inventory.WarehouseService.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, warehouse)
+}
+
+// Update godoc
+// @Summary Update a warehouse
+// @Description Change a warehouse's code, name, address or negative stock override, or deactivate it. The default
+// @Description warehouse and warehouses still holding stock cannot be deactivated.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path string true "Warehouse ID"
+// @Param warehouse body WarehouseRequest true "Warehouse"
+// @Success 200 {object} domain.Warehouse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/inventory/warehouses/{id} [put]
+// @Security BearerAuth
+func (h *WarehouseHandler) Update(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warehouse ID"})
+	}
+
+	var req WarehouseRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	warehouse, err := inventory.WarehouseService.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return stockError(c, err)
+	}
+	warehouse.Code = req.Code
+	warehouse.Name = req.Name
+	warehouse.Address = req.Address
+	warehouse.AllowNegativeStock = req.AllowNegativeStock
+	if req.IsActive != nil {
+		warehouse.IsActive = *req.IsActive
+	}
+
+	if err := inventory.WarehouseService.Update(c.Request().Context(), warehouse, optionalUserID(c)); err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, warehouse)
+}
+
+// ListStock godoc
+// @Summary List a warehouse's stock
+// @Description Get the quantity on hand of each product in a warehouse
+// @Tags inventory
+// @Produce json
+// @Param id path string true "Warehouse ID"
+// @Param nonZero query bool false "Skip levels at zero"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.StockLevel
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/inventory/warehouses/{id}/stock [get]
+// @Security BearerAuth
+func (h *WarehouseHandler) ListStock(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid warehouse ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	warehouse, err := inventory.WarehouseService.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	filter := domain.StockLevelFilter{WarehouseID: &warehouse.ID, NonZero: c.QueryParam("nonZero") == "true"}
+	filter.Limit, filter.Offset = page(c)
+
+	levels, err := inventory.StockService.Levels(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return stockError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, levels)
+}
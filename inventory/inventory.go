@@ -0,0 +1,292 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/catalog"
+	catalogDomain "github.com/aceextension/catalog/domain"
+	"github.com/aceextension/crm"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/inventory/domain"
+	"github.com/aceextension/inventory/repository"
+	"github.com/aceextension/inventory/service"
+	"github.com/aceextension/purchasing"
+	purchasingDomain "github.com/aceextension/purchasing/domain"
+	"github.com/aceextension/sales"
+	salesDomain "github.com/aceextension/sales/domain"
+	"github.com/google/uuid"
+)
+
+// Global service instances
+var (
+	StockService     service.StockService
+	WarehouseService service.WarehouseService
+)
+
+// Init initializes the inventory module and takes over the stock hooks of the
+// modules that move goods. Call catalog.Init, crm.Init, purchasing.Init and sales.Init first.
+func Init() {
+	WarehouseService = service.NewWarehouseService(repository.NewPostgresWarehouseRepository())
+	StockService = service.NewStockService(repository.NewPostgresStockRepository(), repository.NewPostgresStockPolicyRepository(),
+		WarehouseService, catalogProducts{})
+
+	if catalog.ReservationService != nil {
+		StockService.SetReservations(catalogReservations{})
+		// Reservations and alerts count on-hand stock from the ledger instead of bins
+		catalog.ReservationService.SetStockLevels(stockLevels{})
+	}
+	if catalog.BinService != nil {
+		catalog.BinService.SetWarehouses(binWarehouses{})
+	}
+	if catalog.AssemblyService != nil {
+		catalog.AssemblyService.SetStockLedger(assemblyStock{})
+	}
+	if crm.PurchaseReturnService != nil {
+		crm.PurchaseReturnService.SetReturnStock(returnStock{})
+	}
+	if crm.WarrantyService != nil {
+		crm.WarrantyService.SetStockLedger(warrantyStock{})
+	}
+	if purchasing.PurchaseOrderService != nil {
+		purchasing.PurchaseOrderService.SetStock(goodsStock{})
+	}
+	if sales.InvoiceService != nil {
+		sales.InvoiceService.SetStock(invoiceStock{})
+	}
+}
+
+// stockDocument collects the movements of one document so they are posted together
+type stockDocument struct {
+	tenantID      uuid.UUID
+	warehouseID   uuid.UUID // MainWarehouse for the tenant's default warehouse
+	referenceType string
+	referenceID   uuid.UUID
+	number        string
+	movedAt       time.Time
+	createdBy     *uuid.UUID
+	movements     []*domain.StockMovement
+}
+
+// add moves a positive quantity of a product in or out
+func (d *stockDocument) add(productID uuid.UUID, movementType domain.MovementType, quantity float64, unitCost *float64) error {
+	movement, err := domain.NewStockMovement(d.tenantID, productID, movementType, quantity)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", d.referenceType, d.number, err)
+	}
+	movement.WarehouseID = d.warehouseID
+	movement.UnitCost = unitCost
+	movement.MovedAt = d.movedAt
+	movement.CreatedBy = d.createdBy
+	movement.SetReference(d.referenceType, d.referenceID, d.number)
+	d.movements = append(d.movements, movement)
+	return nil
+}
+
+// post records the document's movements
+func (d *stockDocument) post(ctx context.Context) error {
+	return StockService.Post(ctx, d.movements)
+}
+
+// catalogProducts checks stocked products against the catalog
+type catalogProducts struct{}
+
+// Exists returns ErrProductNotFound unless the tenant has the product
+func (catalogProducts) Exists(ctx context.Context, tenantID, productID uuid.UUID) error {
+	if catalog.ProductService == nil {
+		return domain.ErrProductNotFound
+	}
+	if _, err := catalog.ProductService.GetByID(ctx, tenantID, productID); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrProductNotFound, productID)
+	}
+	return nil
+}
+
+// documentWarehouse is the warehouse a document moves stock in
+func documentWarehouse(warehouseID *uuid.UUID) uuid.UUID {
+	if warehouseID == nil {
+		return domain.MainWarehouse
+	}
+	return *warehouseID
+}
+
+// checkWarehouse checks a document's warehouse can move stock
+func checkWarehouse(ctx context.Context, tenantID, warehouseID uuid.UUID) error {
+	_, err := WarehouseService.Resolve(ctx, tenantID, warehouseID)
+	return err
+}
+
+// binWarehouses places catalog bins in warehouses
+type binWarehouses struct{}
+
+// ResolveWarehouse returns the active warehouse, or the default one for nil
+func (binWarehouses) ResolveWarehouse(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID) (uuid.UUID, error) {
+	return WarehouseService.Resolve(ctx, tenantID, documentWarehouse(warehouseID))
+}
+
+// catalogReservations reads what catalog reservations hold of a product
+type catalogReservations struct{}
+
+// Reserved returns the quantity held for the product
+func (catalogReservations) Reserved(ctx context.Context, tenantID, productID uuid.UUID) (float64, error) {
+	availability, err := catalog.ReservationService.Availability(ctx, tenantID, []uuid.UUID{productID})
+	if err != nil || len(availability) == 0 {
+		return 0, err
+	}
+	return availability[0].Reserved, nil
+}
+
+// stockLevels gives catalog reservations the ledger's on-hand quantities
+type stockLevels struct{}
+
+// OnHand sums each product's stock over all warehouses
+func (stockLevels) OnHand(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	return StockService.OnHand(ctx, tenantID, productIDs)
+}
+
+// assemblyStock consumes components and receives finished goods of assembly orders
+type assemblyStock struct{}
+
+// ConsumeComponents takes the order's components out of stock
+func (assemblyStock) ConsumeComponents(ctx context.Context, order *catalogDomain.AssemblyOrder) error {
+	doc := stockDocument{tenantID: order.TenantID, referenceType: domain.ReferenceAssemblyOrder, referenceID: order.ID,
+		number: order.OrderNumber, movedAt: time.Now(), createdBy: order.CreatedBy}
+	for _, component := range order.Components {
+		unitCost := component.UnitCost
+		if err := doc.add(component.ComponentID, domain.MovementOut, component.Quantity, &unitCost); err != nil {
+			return err
+		}
+	}
+	return doc.post(ctx)
+}
+
+// ReceiveFinished puts the produced quantity into stock at the order's unit cost
+func (assemblyStock) ReceiveFinished(ctx context.Context, order *catalogDomain.AssemblyOrder) error {
+	doc := stockDocument{tenantID: order.TenantID, referenceType: domain.ReferenceAssemblyOrder, referenceID: order.ID,
+		number: order.OrderNumber, movedAt: time.Now(), createdBy: order.CreatedBy}
+	unitCost := order.UnitCost
+	if err := doc.add(order.ProductID, domain.MovementIn, order.ProducedQty, &unitCost); err != nil {
+		return err
+	}
+	return doc.post(ctx)
+}
+
+// returnStock takes goods returned to suppliers out of stock
+type returnStock struct{}
+
+// IssueReturn takes the returned lines out of stock at their cost
+func (returnStock) IssueReturn(ctx context.Context, ret *crmDomain.PurchaseReturn) error {
+	doc := stockDocument{tenantID: ret.TenantID, referenceType: domain.ReferencePurchaseReturn, referenceID: ret.ID,
+		number: ret.ReturnNumber, movedAt: ret.ReturnedAt, createdBy: ret.CreatedBy}
+	for _, line := range ret.Lines {
+		unitCost := line.UnitCost
+		if err := doc.add(line.ProductID, domain.MovementOut, line.Quantity, &unitCost); err != nil {
+			return err
+		}
+	}
+	return doc.post(ctx)
+}
+
+// warrantyStock moves the units of warranty replacements and exchanges
+type warrantyStock struct{}
+
+// IssueReplacement takes the unit handed to the customer out of stock
+func (warrantyStock) IssueReplacement(ctx context.Context, claim *crmDomain.WarrantyClaim) error {
+	productID := claim.ProductID
+	if claim.ReplacementProductID != nil {
+		productID = *claim.ReplacementProductID
+	}
+	doc := stockDocument{tenantID: claim.TenantID, referenceType: domain.ReferenceWarrantyClaim, referenceID: claim.ID,
+		number: claim.ClaimNumber, movedAt: time.Now(), createdBy: claim.ResolvedBy}
+	if err := doc.add(productID, domain.MovementOut, 1, nil); err != nil {
+		return err
+	}
+	return doc.post(ctx)
+}
+
+// ReceiveDefective takes the customer's unit back into stock until it goes to the supplier
+func (warrantyStock) ReceiveDefective(ctx context.Context, claim *crmDomain.WarrantyClaim) error {
+	doc := stockDocument{tenantID: claim.TenantID, referenceType: domain.ReferenceWarrantyClaim, referenceID: claim.ID,
+		number: claim.ClaimNumber, movedAt: time.Now(), createdBy: claim.ResolvedBy}
+	if err := doc.add(claim.ProductID, domain.MovementIn, 1, nil); err != nil {
+		return err
+	}
+	note := "Defective unit " + claim.SerialNumber
+	doc.movements[0].Note = &note
+	return doc.post(ctx)
+}
+
+// goodsStock takes purchase order goods receipts into stock
+type goodsStock struct{}
+
+// CheckWarehouse checks goods can be received into the warehouse
+func (goodsStock) CheckWarehouse(ctx context.Context, tenantID, warehouseID uuid.UUID) error {
+	return checkWarehouse(ctx, tenantID, warehouseID)
+}
+
+// ReceiveGoods puts the received lines into stock at their received cost
+func (goodsStock) ReceiveGoods(ctx context.Context, receipt *purchasingDomain.GoodsReceipt) error {
+	doc := stockDocument{tenantID: receipt.TenantID, warehouseID: documentWarehouse(receipt.WarehouseID), referenceType: domain.ReferenceGoodsReceipt, referenceID: receipt.ID,
+		number: receipt.ReceiptNumber, movedAt: receipt.ReceivedAt, createdBy: receipt.CreatedBy}
+	for _, line := range receipt.Lines {
+		if err := doc.add(line.ProductID, domain.MovementIn, line.Quantity, line.UnitCost); err != nil {
+			return err
+		}
+	}
+	return doc.post(ctx)
+}
+
+// invoiceStock takes sold goods out of stock
+type invoiceStock struct{}
+
+// CheckWarehouse checks goods can be sold from the warehouse
+func (invoiceStock) CheckWarehouse(ctx context.Context, tenantID, warehouseID uuid.UUID) error {
+	return checkWarehouse(ctx, tenantID, warehouseID)
+}
+
+// CheckStock checks the invoice's warehouse may give its lines under its stock policy
+func (invoiceStock) CheckStock(ctx context.Context, invoice *salesDomain.Invoice) error {
+	doc, err := invoiceIssue(invoice)
+	if err != nil {
+		return err
+	}
+	return StockService.Check(ctx, doc.movements)
+}
+
+// IssueInvoice takes the invoice's lines out of its warehouse
+func (invoiceStock) IssueInvoice(ctx context.Context, invoice *salesDomain.Invoice) error {
+	doc, err := invoiceIssue(invoice)
+	if err != nil {
+		return err
+	}
+	return doc.post(ctx)
+}
+
+// invoiceIssue builds the out movements of an invoice's lines
+func invoiceIssue(invoice *salesDomain.Invoice) (*stockDocument, error) {
+	doc := &stockDocument{tenantID: invoice.TenantID, warehouseID: documentWarehouse(invoice.WarehouseID), referenceType: domain.ReferenceInvoice, referenceID: invoice.ID,
+		number: invoice.InvoiceNumber, movedAt: invoice.InvoiceDate, createdBy: invoice.CreatedBy}
+	for _, line := range invoice.Lines {
+		if err := doc.add(line.ProductID, domain.MovementOut, line.Quantity, nil); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// ReturnVoided puts a voided invoice's lines back into the warehouse they left
+func (invoiceStock) ReturnVoided(ctx context.Context, invoice *salesDomain.Invoice) error {
+	movedAt := time.Now()
+	if invoice.VoidedAt != nil {
+		movedAt = *invoice.VoidedAt
+	}
+	doc := stockDocument{tenantID: invoice.TenantID, warehouseID: documentWarehouse(invoice.WarehouseID), referenceType: domain.ReferenceInvoiceVoid, referenceID: invoice.ID,
+		number: invoice.InvoiceNumber, movedAt: movedAt, createdBy: invoice.VoidedBy}
+	for _, line := range invoice.Lines {
+		if err := doc.add(line.ProductID, domain.MovementIn, line.Quantity, nil); err != nil {
+			return err
+		}
+	}
+	return doc.post(ctx)
+}
@@ -0,0 +1,61 @@
+-- Inventory Module: Stock Ledger
+-- Migration: 001_create_stock_ledger.sql
+-- Every change to a product's stock is a movement in the ledger; stock levels hold
+-- the running quantity per product and warehouse and are updated with each movement.
+
+CREATE TABLE IF NOT EXISTS stock_levels (
+    tenant_id UUID NOT NULL,
+    product_id UUID NOT NULL,
+    warehouse_id UUID NOT NULL,                      -- Nil UUID: the tenant's main location
+    quantity DECIMAL(15, 3) NOT NULL DEFAULT 0,      -- Negative when more was issued than received
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, product_id, warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_levels_warehouse ON stock_levels(tenant_id, warehouse_id);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    product_id UUID NOT NULL,
+    warehouse_id UUID NOT NULL,
+    type VARCHAR(20) NOT NULL,                       -- in, out, adjustment
+    quantity DECIMAL(15, 3) NOT NULL,                -- Signed change to the level
+    unit_cost DECIMAL(15, 4),
+    balance_after DECIMAL(15, 3) NOT NULL,
+    reference_type VARCHAR(30),                      -- goods_receipt, invoice, purchase_return, ...
+    reference_id UUID,
+    reference_number VARCHAR(50),
+    note TEXT,
+    moved_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_stock_movements_type CHECK (type IN ('in', 'out', 'adjustment')),
+    CONSTRAINT chk_stock_movements_quantity CHECK (
+        (type = 'in' AND quantity > 0) OR (type = 'out' AND quantity < 0) OR (type = 'adjustment' AND quantity <> 0)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements(tenant_id, product_id, moved_at DESC);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_date ON stock_movements(tenant_id, moved_at DESC);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_reference ON stock_movements(tenant_id, reference_type, reference_id)
+    WHERE reference_id IS NOT NULL;
+
+COMMENT ON TABLE stock_levels IS 'Quantity on hand per product and warehouse; the sum of its stock movements';
+COMMENT ON TABLE stock_movements IS 'Stock ledger: every receipt, issue and adjustment of stock';
+COMMENT ON COLUMN stock_movements.balance_after IS 'Level of the product in the warehouse after the movement';
+
+ALTER TABLE stock_levels ENABLE ROW LEVEL SECURITY;
+ALTER TABLE stock_movements ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON stock_levels
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON stock_movements
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
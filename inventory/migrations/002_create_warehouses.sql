@@ -0,0 +1,103 @@
+-- Inventory Module: Warehouses and Stock Transfers
+-- Migration: 002_create_warehouses.sql
+-- Stock is kept per warehouse: an outlet, a store room or a godown. Every tenant has
+-- one default warehouse that documents without a warehouse move stock in; stock held
+-- in the main location (the nil UUID) before warehouses is moved into it.
+-- Run after catalog's 015_add_bin_warehouses.sql, whose bins are placed here too.
+
+CREATE TABLE IF NOT EXISTS warehouses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    code VARCHAR(20) NOT NULL,                       -- Upper case, e.g. MAIN, KTM-1
+    name VARCHAR(255) NOT NULL,
+    address TEXT,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_warehouses_code UNIQUE (tenant_id, code),
+    CONSTRAINT chk_warehouses_default_active CHECK (NOT is_default OR is_active)
+);
+
+-- One default warehouse per tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_default ON warehouses(tenant_id) WHERE is_default;
+
+-- Tenants already holding stock or bins get their default warehouse now
+INSERT INTO warehouses (tenant_id, code, name, is_default)
+SELECT t.tenant_id, 'MAIN', 'Main', true
+FROM (
+    SELECT tenant_id FROM stock_levels
+    UNION
+    SELECT tenant_id FROM bins
+) t
+ON CONFLICT DO NOTHING;
+
+UPDATE stock_levels l SET warehouse_id = w.id
+FROM warehouses w
+WHERE w.tenant_id = l.tenant_id AND w.is_default AND l.warehouse_id = '00000000-0000-0000-0000-000000000000';
+
+UPDATE stock_movements m SET warehouse_id = w.id
+FROM warehouses w
+WHERE w.tenant_id = m.tenant_id AND w.is_default AND m.warehouse_id = '00000000-0000-0000-0000-000000000000';
+
+UPDATE bins b SET warehouse_id = w.id
+FROM warehouses w
+WHERE w.tenant_id = b.tenant_id AND w.is_default AND b.warehouse_id IS NULL;
+
+ALTER TABLE stock_levels ADD CONSTRAINT fk_stock_levels_warehouse
+    FOREIGN KEY (warehouse_id) REFERENCES warehouses(id);
+ALTER TABLE stock_movements ADD CONSTRAINT fk_stock_movements_warehouse
+    FOREIGN KEY (warehouse_id) REFERENCES warehouses(id);
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    transfer_number VARCHAR(20) NOT NULL,            -- TRF-00001
+    from_warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+    to_warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+    note TEXT,
+    transferred_at TIMESTAMP NOT NULL,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_stock_transfers_number UNIQUE (tenant_id, transfer_number),
+    CONSTRAINT chk_stock_transfers_warehouses CHECK (from_warehouse_id <> to_warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_date ON stock_transfers(tenant_id, transferred_at DESC);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_lines (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    transfer_id UUID NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    line_no INTEGER NOT NULL,
+    product_id UUID NOT NULL,
+    quantity DECIMAL(15, 3) NOT NULL,
+    CONSTRAINT uq_stock_transfer_lines_no UNIQUE (transfer_id, line_no),
+    CONSTRAINT chk_stock_transfer_lines_quantity CHECK (quantity > 0)
+);
+
+COMMENT ON TABLE warehouses IS 'Stock locations of a tenant; one is the default for documents without a warehouse';
+COMMENT ON TABLE stock_transfers IS 'Stock moved between warehouses, posted as an out and an in movement per line';
+COMMENT ON COLUMN stock_levels.warehouse_id IS 'Warehouse holding the stock';
+
+ALTER TABLE warehouses ENABLE ROW LEVEL SECURITY;
+ALTER TABLE stock_transfers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE stock_transfer_lines ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON warehouses
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON stock_transfers
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON stock_transfer_lines
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,36 @@
+-- Inventory Module: Negative Stock Policy and Moving Average Cost
+-- Migration: 003_add_negative_stock_policy.sql
+-- Tenants choose whether issues may take stock below zero; a warehouse can override
+-- the tenant's choice. Levels and movements carry the moving average unit cost, which
+-- backdated corrections restate along with the balances.
+
+CREATE TABLE IF NOT EXISTS stock_policies (
+    tenant_id UUID PRIMARY KEY,
+    allow_negative_stock BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS allow_negative_stock BOOLEAN;   -- NULL: the tenant's policy
+ALTER TABLE stock_levels ADD COLUMN IF NOT EXISTS average_cost DECIMAL(15, 4);
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS average_cost DECIMAL(15, 4);
+
+-- Replaying a product's ledger in a warehouse, oldest moved first
+CREATE INDEX IF NOT EXISTS idx_stock_movements_ledger
+    ON stock_movements(tenant_id, product_id, warehouse_id, moved_at, created_at);
+
+-- Issues that left a level below zero, for the exceptions report
+CREATE INDEX IF NOT EXISTS idx_stock_movements_below_zero ON stock_movements(tenant_id, moved_at DESC)
+    WHERE quantity < 0 AND balance_after < 0;
+
+COMMENT ON TABLE stock_policies IS 'Whether issues may take a tenant''s stock below zero; allowed for tenants without a row';
+COMMENT ON COLUMN warehouses.allow_negative_stock IS 'Overrides the tenant''s stock policy in the warehouse; NULL follows it';
+COMMENT ON COLUMN stock_levels.average_cost IS 'Moving average unit cost of the stock on hand; NULL until received at a cost';
+COMMENT ON COLUMN stock_movements.average_cost IS 'Moving average unit cost of the level after the movement';
+
+ALTER TABLE stock_policies ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON stock_policies
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
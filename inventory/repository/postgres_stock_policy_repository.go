@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/inventory/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresStockPolicyRepository implements StockPolicyRepository using PostgreSQL
+type PostgresStockPolicyRepository struct{}
+
+// NewPostgresStockPolicyRepository creates a new PostgreSQL stock policy repository
+func NewPostgresStockPolicyRepository() *PostgresStockPolicyRepository {
+	return &PostgresStockPolicyRepository{}
+}
+
+// Get retrieves the tenant's policy, or nil if never set
+func (r *PostgresStockPolicyRepository) Get(ctx context.Context, tenantID uuid.UUID) (*domain.StockPolicy, error) {
+	var p domain.StockPolicy
+	err := db.MainPool.QueryRow(ctx, `
+		SELECT tenant_id, allow_negative_stock, updated_at FROM stock_policies WHERE tenant_id = $1
+	`, tenantID).Scan(&p.TenantID, &p.AllowNegativeStock, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock policy: %w", err)
+	}
+	return &p, nil
+}
+
+// Save creates or replaces the tenant's policy
+func (r *PostgresStockPolicyRepository) Save(ctx context.Context, policy *domain.StockPolicy) error {
+	_, err := db.MainPool.Exec(ctx, `
+		INSERT INTO stock_policies (tenant_id, allow_negative_stock, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			allow_negative_stock = EXCLUDED.allow_negative_stock, updated_at = EXCLUDED.updated_at
+	`, policy.TenantID, policy.AllowNegativeStock, policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save stock policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,474 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/inventory/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresStockRepository implements StockRepository using PostgreSQL
+type PostgresStockRepository struct{}
+
+// NewPostgresStockRepository creates a new PostgreSQL stock repository
+func NewPostgresStockRepository() *PostgresStockRepository {
+	return &PostgresStockRepository{}
+}
+
+const stockMovementColumns = `id, tenant_id, product_id, warehouse_id, type, quantity, unit_cost, balance_after,
+	average_cost, reference_type, reference_id, reference_number, note, moved_at, created_by, created_at`
+
+const stockLevelColumns = `tenant_id, product_id, warehouse_id, quantity, average_cost, updated_at`
+
+const stockTransferColumns = `id, tenant_id, transfer_number, from_warehouse_id, to_warehouse_id, note,
+	transferred_at, created_by, created_at`
+
+// Record applies the movements to their levels and saves them
+func (r *PostgresStockRepository) Record(ctx context.Context, movements []*domain.StockMovement) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return recordMovements(ctx, tx, movements)
+	})
+}
+
+// Correct records a backdated movement and restates its product's ledger in the warehouse
+func (r *PostgresStockRepository) Correct(ctx context.Context, movement *domain.StockMovement) (int, error) {
+	var restated int
+	err := db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if err := recordMovements(ctx, tx, []*domain.StockMovement{movement}); err != nil {
+			return err
+		}
+		var err error
+		restated, err = restateLedger(ctx, tx, movement)
+		return err
+	})
+	return restated, err
+}
+
+// RecordTransfer numbers and saves a transfer with its movements
+func (r *PostgresStockRepository) RecordTransfer(ctx context.Context, transfer *domain.StockTransfer, movements []*domain.StockMovement) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "stock_transfers|"+transfer.TenantID.String())
+		if err != nil {
+			return fmt.Errorf("failed to lock stock transfer numbering: %w", err)
+		}
+		var next int64
+		err = tx.QueryRow(ctx,
+			`SELECT COUNT(*) + 1 FROM stock_transfers WHERE tenant_id = $1`, transfer.TenantID,
+		).Scan(&next)
+		if err != nil {
+			return fmt.Errorf("failed to number stock transfer: %w", err)
+		}
+		transfer.TransferNumber = fmt.Sprintf("TRF-%05d", next)
+		for _, m := range movements {
+			m.ReferenceNumber = &transfer.TransferNumber
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO stock_transfers (`+stockTransferColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`,
+			transfer.ID, transfer.TenantID, transfer.TransferNumber, transfer.FromWarehouseID, transfer.ToWarehouseID,
+			transfer.Note, transfer.TransferredAt, transfer.CreatedBy, transfer.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create stock transfer: %w", err)
+		}
+
+		for i, line := range transfer.Lines {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO stock_transfer_lines (id, tenant_id, transfer_id, line_no, product_id, quantity)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, line.ID, transfer.TenantID, transfer.ID, i+1, line.ProductID, line.Quantity)
+			if err != nil {
+				return fmt.Errorf("failed to create stock transfer line: %w", err)
+			}
+		}
+
+		return recordMovements(ctx, tx, movements)
+	})
+}
+
+// GetTransfer retrieves a transfer with its lines
+func (r *PostgresStockRepository) GetTransfer(ctx context.Context, tenantID, id uuid.UUID) (*domain.StockTransfer, error) {
+	query := `SELECT ` + stockTransferColumns + ` FROM stock_transfers WHERE tenant_id = $1 AND id = $2`
+
+	var t domain.StockTransfer
+	err := db.MainPool.QueryRow(ctx, query, tenantID, id).Scan(
+		&t.ID, &t.TenantID, &t.TransferNumber, &t.FromWarehouseID, &t.ToWarehouseID,
+		&t.Note, &t.TransferredAt, &t.CreatedBy, &t.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, domain.ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock transfer: %w", err)
+	}
+
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT id, product_id, quantity FROM stock_transfer_lines WHERE transfer_id = $1 ORDER BY line_no
+	`, t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock transfer lines: %w", err)
+	}
+	defer rows.Close()
+
+	t.Lines = []domain.TransferLine{}
+	for rows.Next() {
+		var line domain.TransferLine
+		if err := rows.Scan(&line.ID, &line.ProductID, &line.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan stock transfer line: %w", err)
+		}
+		t.Lines = append(t.Lines, line)
+	}
+	return &t, rows.Err()
+}
+
+// ListTransfers retrieves transfers without their lines, newest first
+func (r *PostgresStockRepository) ListTransfers(ctx context.Context, tenantID uuid.UUID, filter domain.StockTransferFilter) ([]*domain.StockTransfer, error) {
+	query := `
+		SELECT ` + stockTransferColumns + `
+		FROM stock_transfers
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR from_warehouse_id = $2 OR to_warehouse_id = $2)
+		  AND ($3::timestamp IS NULL OR transferred_at >= $3)
+		  AND ($4::timestamp IS NULL OR transferred_at < $4)
+		ORDER BY transferred_at DESC, created_at DESC
+		LIMIT $5 OFFSET $6
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.WarehouseID, filter.From, filter.To, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := []*domain.StockTransfer{}
+	for rows.Next() {
+		var t domain.StockTransfer
+		err := rows.Scan(
+			&t.ID, &t.TenantID, &t.TransferNumber, &t.FromWarehouseID, &t.ToWarehouseID,
+			&t.Note, &t.TransferredAt, &t.CreatedBy, &t.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stock transfer: %w", err)
+		}
+		t.Lines = []domain.TransferLine{}
+		transfers = append(transfers, &t)
+	}
+	return transfers, rows.Err()
+}
+
+// recordMovements applies movements to their levels and saves them within tx
+func recordMovements(ctx context.Context, tx pgx.Tx, movements []*domain.StockMovement) error {
+	// Levels are locked in a fixed order so documents moving the same products
+	// concurrently cannot deadlock
+	ordered := make([]*domain.StockMovement, len(movements))
+	copy(ordered, movements)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if c := bytes.Compare(ordered[i].ProductID[:], ordered[j].ProductID[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(ordered[i].WarehouseID[:], ordered[j].WarehouseID[:]) < 0
+	})
+
+	for _, m := range ordered {
+		level, err := lockLevel(ctx, tx, m.TenantID, m.ProductID, m.WarehouseID)
+		if err != nil {
+			return err
+		}
+		onHand := level.Quantity
+		level.Apply(m)
+
+		if m.Quantity < 0 && m.BalanceAfter < 0 {
+			allowed, err := allowsNegative(ctx, tx, m.TenantID, m.WarehouseID)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return domain.ShortStockError(m.ProductID, onHand, -m.Quantity)
+			}
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE stock_levels SET quantity = $4, average_cost = $5, updated_at = $6
+			WHERE tenant_id = $1 AND product_id = $2 AND warehouse_id = $3
+		`, m.TenantID, m.ProductID, m.WarehouseID, level.Quantity, level.AverageCost, m.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to update stock level: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO stock_movements (`+stockMovementColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		`,
+			m.ID, m.TenantID, m.ProductID, m.WarehouseID, m.Type, m.Quantity, m.UnitCost, m.BalanceAfter,
+			m.AverageCost, m.ReferenceType, m.ReferenceID, m.ReferenceNumber, m.Note, m.MovedAt, m.CreatedBy, m.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record stock movement: %w", err)
+		}
+	}
+	return nil
+}
+
+// lockLevel reads a level for update within tx, creating it at zero on first use so
+// concurrent first movements queue on the row too
+func lockLevel(ctx context.Context, tx pgx.Tx, tenantID, productID, warehouseID uuid.UUID) (*domain.StockLevel, error) {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO stock_levels (tenant_id, product_id, warehouse_id, quantity)
+		VALUES ($1, $2, $3, 0)
+		ON CONFLICT (tenant_id, product_id, warehouse_id) DO NOTHING
+	`, tenantID, productID, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stock level: %w", err)
+	}
+
+	level := &domain.StockLevel{TenantID: tenantID, ProductID: productID, WarehouseID: warehouseID}
+	err = tx.QueryRow(ctx, `
+		SELECT quantity, average_cost, updated_at FROM stock_levels
+		WHERE tenant_id = $1 AND product_id = $2 AND warehouse_id = $3
+		FOR UPDATE
+	`, tenantID, productID, warehouseID).Scan(&level.Quantity, &level.AverageCost, &level.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock stock level: %w", err)
+	}
+	return level, nil
+}
+
+// allowsNegative reads within tx whether a level in the warehouse may go below zero:
+// the warehouse's own setting, else the tenant's policy, else allowed as by
+// domain.DefaultStockPolicy
+func allowsNegative(ctx context.Context, tx pgx.Tx, tenantID, warehouseID uuid.UUID) (bool, error) {
+	var allowed bool
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(w.allow_negative_stock, p.allow_negative_stock, true)
+		FROM warehouses w
+		LEFT JOIN stock_policies p ON p.tenant_id = w.tenant_id
+		WHERE w.tenant_id = $1 AND w.id = $2
+	`, tenantID, warehouseID).Scan(&allowed)
+	if err != nil {
+		return false, fmt.Errorf("failed to read stock policy: %w", err)
+	}
+	return allowed, nil
+}
+
+// restateLedger replays the ledger of the movement's product in its warehouse from
+// zero, oldest moved first, and saves the balances and average costs that changed.
+// The level is already locked by recordMovements.
+func restateLedger(ctx context.Context, tx pgx.Tx, movement *domain.StockMovement) (int, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT id, quantity, unit_cost, balance_after, average_cost
+		FROM stock_movements
+		WHERE tenant_id = $1 AND product_id = $2 AND warehouse_id = $3
+		ORDER BY moved_at, created_at, id
+	`, movement.TenantID, movement.ProductID, movement.WarehouseID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read stock ledger: %w", err)
+	}
+
+	level := &domain.StockLevel{TenantID: movement.TenantID, ProductID: movement.ProductID, WarehouseID: movement.WarehouseID}
+	var changed []*domain.StockMovement
+	for rows.Next() {
+		var m domain.StockMovement
+		var balance float64
+		var cost *float64
+		if err := rows.Scan(&m.ID, &m.Quantity, &m.UnitCost, &balance, &cost); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan stock movement: %w", err)
+		}
+		level.Apply(&m)
+		if m.BalanceAfter != balance || !sameCost(m.AverageCost, cost) {
+			changed = append(changed, &m)
+		}
+		if m.ID == movement.ID {
+			movement.BalanceAfter, movement.AverageCost = m.BalanceAfter, m.AverageCost
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read stock ledger: %w", err)
+	}
+
+	for _, m := range changed {
+		_, err := tx.Exec(ctx, `
+			UPDATE stock_movements SET balance_after = $2, average_cost = $3 WHERE id = $1
+		`, m.ID, m.BalanceAfter, m.AverageCost)
+		if err != nil {
+			return 0, fmt.Errorf("failed to restate stock movement: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE stock_levels SET quantity = $4, average_cost = $5
+		WHERE tenant_id = $1 AND product_id = $2 AND warehouse_id = $3
+	`, movement.TenantID, movement.ProductID, movement.WarehouseID, level.Quantity, level.AverageCost)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restate stock level: %w", err)
+	}
+	return len(changed), nil
+}
+
+// sameCost compares two optional costs
+func sameCost(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// Levels retrieves stock levels, by product
+func (r *PostgresStockRepository) Levels(ctx context.Context, tenantID uuid.UUID, filter domain.StockLevelFilter) ([]domain.StockLevel, error) {
+	query := `
+		SELECT ` + stockLevelColumns + `
+		FROM stock_levels
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR product_id = $2)
+		  AND ($3::uuid IS NULL OR warehouse_id = $3)
+		  AND (NOT $4 OR quantity <> 0)
+		ORDER BY product_id, warehouse_id
+		LIMIT $5 OFFSET $6
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.ProductID, filter.WarehouseID, filter.NonZero, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock levels: %w", err)
+	}
+	return scanStockLevels(rows)
+}
+
+// ProductLevels retrieves a product's levels
+func (r *PostgresStockRepository) ProductLevels(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.StockLevel, error) {
+	query := `SELECT ` + stockLevelColumns + ` FROM stock_levels WHERE tenant_id = $1 AND product_id = $2 ORDER BY warehouse_id`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock levels: %w", err)
+	}
+	return scanStockLevels(rows)
+}
+
+// OnHand sums levels per product
+func (r *PostgresStockRepository) OnHand(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	query := `
+		SELECT product_id, SUM(quantity)
+		FROM stock_levels
+		WHERE tenant_id = $1 AND product_id = ANY($2)
+		GROUP BY product_id
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum stock levels: %w", err)
+	}
+	defer rows.Close()
+
+	onHand := make(map[uuid.UUID]float64, len(productIDs))
+	for rows.Next() {
+		var productID uuid.UUID
+		var quantity float64
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan stock level: %w", err)
+		}
+		onHand[productID] = quantity
+	}
+	return onHand, rows.Err()
+}
+
+// Movements retrieves ledger entries, newest first
+func (r *PostgresStockRepository) Movements(ctx context.Context, tenantID uuid.UUID, filter domain.StockMovementFilter) ([]*domain.StockMovement, error) {
+	query := `
+		SELECT ` + stockMovementColumns + `
+		FROM stock_movements
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR product_id = $2)
+		  AND ($3::uuid IS NULL OR warehouse_id = $3)
+		  AND ($4::varchar IS NULL OR type = $4)
+		  AND ($5::varchar IS NULL OR reference_type = $5)
+		  AND ($6::uuid IS NULL OR reference_id = $6)
+		  AND ($7::timestamp IS NULL OR moved_at >= $7)
+		  AND ($8::timestamp IS NULL OR moved_at < $8)
+		  AND (NOT $9 OR (quantity < 0 AND balance_after < 0))
+		ORDER BY moved_at DESC, created_at DESC
+		LIMIT $10 OFFSET $11
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.ProductID, filter.WarehouseID, filter.Type,
+		filter.ReferenceType, filter.ReferenceID, filter.From, filter.To, filter.BelowZero, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	movements := []*domain.StockMovement{}
+	for rows.Next() {
+		var m domain.StockMovement
+		err := rows.Scan(
+			&m.ID, &m.TenantID, &m.ProductID, &m.WarehouseID, &m.Type, &m.Quantity, &m.UnitCost, &m.BalanceAfter,
+			&m.AverageCost, &m.ReferenceType, &m.ReferenceID, &m.ReferenceNumber, &m.Note, &m.MovedAt, &m.CreatedBy, &m.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stock movement: %w", err)
+		}
+		movements = append(movements, &m)
+	}
+
+	return movements, rows.Err()
+}
+
+// NegativeExceptions groups the issues that left levels below zero, latest first
+func (r *PostgresStockRepository) NegativeExceptions(ctx context.Context, tenantID uuid.UUID, filter domain.NegativeStockFilter) ([]domain.NegativeStockException, error) {
+	query := `
+		SELECT m.product_id, m.warehouse_id, COUNT(*), MIN(m.balance_after), MIN(m.moved_at), MAX(m.moved_at),
+			COALESCE(l.quantity, 0)
+		FROM stock_movements m
+		LEFT JOIN stock_levels l
+			ON l.tenant_id = m.tenant_id AND l.product_id = m.product_id AND l.warehouse_id = m.warehouse_id
+		WHERE m.tenant_id = $1 AND m.quantity < 0 AND m.balance_after < 0
+		  AND ($2::uuid IS NULL OR m.product_id = $2)
+		  AND ($3::uuid IS NULL OR m.warehouse_id = $3)
+		  AND ($4::timestamp IS NULL OR m.moved_at >= $4)
+		  AND ($5::timestamp IS NULL OR m.moved_at < $5)
+		  AND (NOT $6 OR l.quantity < 0)
+		GROUP BY m.product_id, m.warehouse_id, l.quantity
+		ORDER BY MAX(m.moved_at) DESC, m.product_id, m.warehouse_id
+		LIMIT $7 OFFSET $8
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.ProductID, filter.WarehouseID, filter.From, filter.To,
+		filter.OpenOnly, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list negative stock exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	exceptions := []domain.NegativeStockException{}
+	for rows.Next() {
+		var e domain.NegativeStockException
+		err := rows.Scan(&e.ProductID, &e.WarehouseID, &e.Occurrences, &e.LowestBalance, &e.FirstAt, &e.LastAt, &e.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan negative stock exception: %w", err)
+		}
+		exceptions = append(exceptions, e)
+	}
+	return exceptions, rows.Err()
+}
+
+// scanStockLevels reads stock_levels rows in stockLevelColumns order
+func scanStockLevels(rows pgx.Rows) ([]domain.StockLevel, error) {
+	defer rows.Close()
+
+	levels := []domain.StockLevel{}
+	for rows.Next() {
+		var level domain.StockLevel
+		if err := rows.Scan(&level.TenantID, &level.ProductID, &level.WarehouseID, &level.Quantity, &level.AverageCost, &level.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan stock level: %w", err)
+		}
+		levels = append(levels, level)
+	}
+	return levels, rows.Err()
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/inventory/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresWarehouseRepository implements WarehouseRepository using PostgreSQL
+type PostgresWarehouseRepository struct{}
+
+// NewPostgresWarehouseRepository creates a new PostgreSQL warehouse repository
+func NewPostgresWarehouseRepository() *PostgresWarehouseRepository {
+	return &PostgresWarehouseRepository{}
+}
+
+const warehouseColumns = `id, tenant_id, code, name, address, is_default, is_active, allow_negative_stock,
+	created_at, updated_at`
+
+// Create inserts a warehouse
+func (r *PostgresWarehouseRepository) Create(ctx context.Context, w *domain.Warehouse) error {
+	_, err := db.MainPool.Exec(ctx, `
+		INSERT INTO warehouses (`+warehouseColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, w.ID, w.TenantID, w.Code, w.Name, w.Address, w.IsDefault, w.IsActive, w.AllowNegativeStock, w.CreatedAt, w.UpdatedAt)
+	if isWarehouseCodeConflict(err) {
+		return fmt.Errorf("%w: %s", domain.ErrWarehouseCodeExists, w.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create warehouse: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a warehouse
+func (r *PostgresWarehouseRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Warehouse, error) {
+	query := `SELECT ` + warehouseColumns + ` FROM warehouses WHERE tenant_id = $1 AND id = $2`
+	return scanWarehouse(db.MainPool.QueryRow(ctx, query, tenantID, id))
+}
+
+// List retrieves the tenant's warehouses
+func (r *PostgresWarehouseRepository) List(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*domain.Warehouse, error) {
+	query := `
+		SELECT ` + warehouseColumns + `
+		FROM warehouses
+		WHERE tenant_id = $1 AND (NOT $2 OR is_active)
+		ORDER BY is_default DESC, code
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warehouses: %w", err)
+	}
+	defer rows.Close()
+
+	warehouses := []*domain.Warehouse{}
+	for rows.Next() {
+		warehouse, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+	return warehouses, rows.Err()
+}
+
+// Update saves a warehouse's code, name, address, active flag and stock policy
+func (r *PostgresWarehouseRepository) Update(ctx context.Context, w *domain.Warehouse) error {
+	tag, err := db.MainPool.Exec(ctx, `
+		UPDATE warehouses SET code = $3, name = $4, address = $5, is_active = $6, allow_negative_stock = $7, updated_at = $8
+		WHERE tenant_id = $1 AND id = $2
+	`, w.TenantID, w.ID, w.Code, w.Name, w.Address, w.IsActive, w.AllowNegativeStock, w.UpdatedAt)
+	if isWarehouseCodeConflict(err) {
+		return fmt.Errorf("%w: %s", domain.ErrWarehouseCodeExists, w.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update warehouse: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWarehouseNotFound
+	}
+	return nil
+}
+
+// Default retrieves the default warehouse, inserting MAIN when the tenant has none.
+// Concurrent first uses both insert; the unique default index keeps one.
+func (r *PostgresWarehouseRepository) Default(ctx context.Context, tenantID uuid.UUID) (*domain.Warehouse, error) {
+	query := `SELECT ` + warehouseColumns + ` FROM warehouses WHERE tenant_id = $1 AND is_default`
+	warehouse, err := scanWarehouse(db.MainPool.QueryRow(ctx, query, tenantID))
+	if !errors.Is(err, domain.ErrWarehouseNotFound) {
+		return warehouse, err
+	}
+
+	main := domain.NewWarehouse(tenantID, domain.DefaultWarehouseCode, "Main")
+	main.IsDefault = true
+	_, err = db.MainPool.Exec(ctx, `
+		INSERT INTO warehouses (`+warehouseColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT DO NOTHING
+	`, main.ID, main.TenantID, main.Code, main.Name, main.Address, main.IsDefault, main.IsActive, main.AllowNegativeStock,
+		main.CreatedAt, main.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default warehouse: %w", err)
+	}
+
+	return scanWarehouse(db.MainPool.QueryRow(ctx, query, tenantID))
+}
+
+// HasStock checks the warehouse's levels
+func (r *PostgresWarehouseRepository) HasStock(ctx context.Context, tenantID, id uuid.UUID) (bool, error) {
+	var held bool
+	err := db.MainPool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM stock_levels WHERE tenant_id = $1 AND warehouse_id = $2 AND quantity <> 0)
+	`, tenantID, id).Scan(&held)
+	if err != nil {
+		return false, fmt.Errorf("failed to check warehouse stock: %w", err)
+	}
+	return held, nil
+}
+
+// scanWarehouse reads a warehouses row in warehouseColumns order
+func scanWarehouse(row pgx.Row) (*domain.Warehouse, error) {
+	var w domain.Warehouse
+	err := row.Scan(&w.ID, &w.TenantID, &w.Code, &w.Name, &w.Address, &w.IsDefault, &w.IsActive, &w.AllowNegativeStock,
+		&w.CreatedAt, &w.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, domain.ErrWarehouseNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan warehouse: %w", err)
+	}
+	return &w, nil
+}
+
+// isWarehouseCodeConflict reports whether err is the unique violation on the tenant's codes
+func isWarehouseCodeConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uq_warehouses_code"
+}
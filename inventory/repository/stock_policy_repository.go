@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/inventory/domain"
+	"github.com/google/uuid"
+)
+
+// StockPolicyRepository defines the interface for tenants' stock policies
+type StockPolicyRepository interface {
+	// Get retrieves the tenant's policy, or nil if never set
+	Get(ctx context.Context, tenantID uuid.UUID) (*domain.StockPolicy, error)
+	// Save creates or replaces the tenant's policy
+	Save(ctx context.Context, policy *domain.StockPolicy) error
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/inventory/domain"
+	"github.com/google/uuid"
+)
+
+// StockRepository defines the interface for stock ledger data access
+type StockRepository interface {
+	// Record saves movements in one transaction, applying each to its stock level and
+	// setting its BalanceAfter and AverageCost. ErrInsufficientStock, and nothing saved,
+	// if an issue would take a level below zero where the warehouse's policy forbids it.
+	Record(ctx context.Context, movements []*domain.StockMovement) error
+	// Correct records a backdated movement like Record, then replays its product's
+	// ledger in the warehouse in MovedAt order, restating the balance and average cost
+	// of each movement and of the level. Returns how many movements changed.
+	Correct(ctx context.Context, movement *domain.StockMovement) (int, error)
+	// RecordTransfer numbers the transfer (TRF-00001) and saves it with its lines and
+	// movements in one transaction
+	RecordTransfer(ctx context.Context, transfer *domain.StockTransfer, movements []*domain.StockMovement) error
+	// GetTransfer retrieves a transfer with its lines; ErrTransferNotFound if there is none
+	GetTransfer(ctx context.Context, tenantID, id uuid.UUID) (*domain.StockTransfer, error)
+	// ListTransfers retrieves transfers without their lines, newest first
+	ListTransfers(ctx context.Context, tenantID uuid.UUID, filter domain.StockTransferFilter) ([]*domain.StockTransfer, error)
+	// Levels retrieves stock levels by product and warehouse
+	Levels(ctx context.Context, tenantID uuid.UUID, filter domain.StockLevelFilter) ([]domain.StockLevel, error)
+	// ProductLevels retrieves a product's level in each warehouse that has held it
+	ProductLevels(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.StockLevel, error)
+	// OnHand sums the products' levels over all warehouses; products never stocked are left out
+	OnHand(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]float64, error)
+	// Movements retrieves ledger entries, newest first
+	Movements(ctx context.Context, tenantID uuid.UUID, filter domain.StockMovementFilter) ([]*domain.StockMovement, error)
+	// NegativeExceptions sums the issues that left levels below zero per product and
+	// warehouse, latest first
+	NegativeExceptions(ctx context.Context, tenantID uuid.UUID, filter domain.NegativeStockFilter) ([]domain.NegativeStockException, error)
+}
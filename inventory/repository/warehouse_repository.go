@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/inventory/domain"
+	"github.com/google/uuid"
+)
+
+// WarehouseRepository defines the interface for warehouse data access
+type WarehouseRepository interface {
+	// Create saves a warehouse; ErrWarehouseCodeExists if the tenant has the code
+	Create(ctx context.Context, warehouse *domain.Warehouse) error
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Warehouse, error)
+	// List retrieves the tenant's warehouses, the default first, then by code
+	List(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*domain.Warehouse, error)
+	Update(ctx context.Context, warehouse *domain.Warehouse) error
+	// Default retrieves the tenant's default warehouse, creating it on first use
+	Default(ctx context.Context, tenantID uuid.UUID) (*domain.Warehouse, error)
+	// HasStock reports whether any product's level in the warehouse is not zero
+	HasStock(ctx context.Context, tenantID, id uuid.UUID) (bool, error)
+}
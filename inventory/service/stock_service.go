@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/inventory/domain"
+	"github.com/aceextension/inventory/repository"
+	"github.com/google/uuid"
+)
+
+// StockService defines the interface for the stock ledger
+type StockService interface {
+	// Receive puts a quantity of a product into stock
+	Receive(ctx context.Context, input MovementInput) (*domain.StockMovement, error)
+	// Issue takes a quantity of a product out of stock. A short level goes negative
+	// until the goods are received, unless the warehouse's stock policy forbids it:
+	// then ErrInsufficientStock.
+	Issue(ctx context.Context, input MovementInput) (*domain.StockMovement, error)
+	// Adjust corrects a product's stock by a signed quantity, e.g. after a count
+	Adjust(ctx context.Context, input MovementInput) (*domain.StockMovement, error)
+	// Correct adjusts a product's stock as of a past MovedAt, e.g. for a receipt
+	// missed at the time, and restates the balance and average cost of the
+	// movements after it
+	Correct(ctx context.Context, input MovementInput) (*domain.StockCorrection, error)
+	// Post records the movements of one document together, all or none. Movements in
+	// MainWarehouse are recorded in the tenant's default warehouse.
+	Post(ctx context.Context, movements []*domain.StockMovement) error
+	// Check returns ErrInsufficientStock if posting the movements would take a level
+	// below zero where the warehouse's policy forbids it, so documents can be refused
+	// before they are saved
+	Check(ctx context.Context, movements []*domain.StockMovement) error
+	// Transfer moves the transfer's lines from one warehouse to the other, numbering
+	// and saving it with its movements together
+	Transfer(ctx context.Context, transfer *domain.StockTransfer) error
+	GetTransfer(ctx context.Context, tenantID, id uuid.UUID) (*domain.StockTransfer, error)
+	ListTransfers(ctx context.Context, tenantID uuid.UUID, filter domain.StockTransferFilter) ([]*domain.StockTransfer, error)
+
+	// GetAvailable returns a product's stock on hand per warehouse and what of it is not reserved
+	GetAvailable(ctx context.Context, tenantID, productID uuid.UUID) (*domain.Availability, error)
+	// GetAvailableAt returns what of a product can be promised from one warehouse
+	GetAvailableAt(ctx context.Context, tenantID, productID, warehouseID uuid.UUID) (*domain.Availability, error)
+	// OnHand sums each product's stock over all warehouses
+	OnHand(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]float64, error)
+	Levels(ctx context.Context, tenantID uuid.UUID, filter domain.StockLevelFilter) ([]domain.StockLevel, error)
+	Movements(ctx context.Context, tenantID uuid.UUID, filter domain.StockMovementFilter) ([]*domain.StockMovement, error)
+	// NegativeExceptions reports, per product and warehouse, the issues that left the level below zero
+	NegativeExceptions(ctx context.Context, tenantID uuid.UUID, filter domain.NegativeStockFilter) ([]domain.NegativeStockException, error)
+
+	// Policy returns the tenant's stock policy; negative stock is allowed unless set
+	Policy(ctx context.Context, tenantID uuid.UUID) (*domain.StockPolicy, error)
+	// SetPolicy replaces the tenant's stock policy. Levels already below zero stay so
+	// until goods are received.
+	SetPolicy(ctx context.Context, tenantID uuid.UUID, allowNegativeStock bool, userID *uuid.UUID) (*domain.StockPolicy, error)
+
+	SetReservations(reservations Reservations)
+}
+
+// MovementInput is a quantity of a product moved by hand rather than by a document
+type MovementInput struct {
+	TenantID    uuid.UUID
+	ProductID   uuid.UUID
+	WarehouseID uuid.UUID // MainWarehouse for the tenant's default warehouse
+	Quantity    float64   // Positive; signed for adjustments
+	UnitCost    *float64
+	MovedAt     time.Time // Defaults to now; required, and past, for corrections
+	Note        *string
+	CreatedBy   *uuid.UUID
+}
+
+// ProductCatalog checks that stocked products exist. Init uses catalog products.
+type ProductCatalog interface {
+	// Exists returns ErrProductNotFound if the tenant has no such product
+	Exists(ctx context.Context, tenantID, productID uuid.UUID) error
+}
+
+// Reservations reports the stock held for sales orders and quotes. Init uses catalog
+// reservations; without it nothing is reserved.
+type Reservations interface {
+	Reserved(ctx context.Context, tenantID, productID uuid.UUID) (float64, error)
+}
+
+// stockService implements StockService
+type stockService struct {
+	repo         repository.StockRepository
+	policies     repository.StockPolicyRepository
+	warehouses   WarehouseService
+	products     ProductCatalog
+	reservations Reservations
+}
+
+// NewStockService creates a new stock service
+func NewStockService(repo repository.StockRepository, policies repository.StockPolicyRepository, warehouses WarehouseService, products ProductCatalog) StockService {
+	return &stockService{
+		repo:       repo,
+		policies:   policies,
+		warehouses: warehouses,
+		products:   products,
+	}
+}
+
+// SetReservations sets where reserved quantities come from
+func (s *stockService) SetReservations(reservations Reservations) {
+	s.reservations = reservations
+}
+
+// Receive records an in movement
+func (s *stockService) Receive(ctx context.Context, input MovementInput) (*domain.StockMovement, error) {
+	return s.move(ctx, "RECEIVE_STOCK", domain.MovementIn, input)
+}
+
+// Issue records an out movement
+func (s *stockService) Issue(ctx context.Context, input MovementInput) (*domain.StockMovement, error) {
+	return s.move(ctx, "ISSUE_STOCK", domain.MovementOut, input)
+}
+
+// Adjust records an adjustment
+func (s *stockService) Adjust(ctx context.Context, input MovementInput) (*domain.StockMovement, error) {
+	return s.move(ctx, "ADJUST_STOCK", domain.MovementAdjustment, input)
+}
+
+// Correct records and audits a backdated adjustment
+func (s *stockService) Correct(ctx context.Context, input MovementInput) (*domain.StockCorrection, error) {
+	if input.MovedAt.IsZero() || !input.MovedAt.Before(time.Now()) {
+		return nil, fmt.Errorf("%w: a correction is dated in the past", domain.ErrInvalidMovement)
+	}
+	movement, err := s.newMovement(ctx, domain.MovementAdjustment, input)
+	if err != nil {
+		return nil, err
+	}
+
+	restated, err := s.repo.Correct(ctx, movement)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditMovement(ctx, "CORRECT_STOCK", movement, map[string]interface{}{
+		"moved_at": movement.MovedAt,
+		"restated": restated,
+	})
+	return &domain.StockCorrection{Movement: movement, Restated: restated}, nil
+}
+
+// move validates, records and audits a movement made by hand
+func (s *stockService) move(ctx context.Context, action string, movementType domain.MovementType, input MovementInput) (*domain.StockMovement, error) {
+	movement, err := s.newMovement(ctx, movementType, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Record(ctx, []*domain.StockMovement{movement}); err != nil {
+		return nil, err
+	}
+
+	s.auditMovement(ctx, action, movement, nil)
+	return movement, nil
+}
+
+// newMovement validates a movement made by hand
+func (s *stockService) newMovement(ctx context.Context, movementType domain.MovementType, input MovementInput) (*domain.StockMovement, error) {
+	if err := s.products.Exists(ctx, input.TenantID, input.ProductID); err != nil {
+		return nil, err
+	}
+
+	warehouseID, err := s.warehouses.Resolve(ctx, input.TenantID, input.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	movement, err := domain.NewStockMovement(input.TenantID, input.ProductID, movementType, input.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	movement.WarehouseID = warehouseID
+	movement.UnitCost = input.UnitCost
+	movement.Note = input.Note
+	movement.CreatedBy = input.CreatedBy
+	if !input.MovedAt.IsZero() {
+		movement.MovedAt = input.MovedAt
+	}
+	return movement, nil
+}
+
+// auditMovement logs a movement made by hand with any extra details
+func (s *stockService) auditMovement(ctx context.Context, action string, movement *domain.StockMovement, extra map[string]interface{}) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   movement.CreatedBy,
+		TenantID: &movement.TenantID,
+	}
+	details := map[string]interface{}{
+		"product_id":    movement.ProductID,
+		"warehouse_id":  movement.WarehouseID,
+		"quantity":      movement.Quantity,
+		"balance_after": movement.BalanceAfter,
+		"note":          movement.Note,
+	}
+	for key, value := range extra {
+		details[key] = value
+	}
+	entityIDStr := movement.ID.String()
+	audit.Service.Log(ctx, action, "StockMovement", &entityIDStr, details, auditCtx)
+}
+
+// Post records a document's movements; the document's own module audits it
+func (s *stockService) Post(ctx context.Context, movements []*domain.StockMovement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+	if err := s.place(ctx, movements); err != nil {
+		return err
+	}
+	return s.repo.Record(ctx, movements)
+}
+
+// Check nets the movements per product and warehouse against the levels on hand.
+// The ledger checks again when the movements are recorded; this only refuses early.
+func (s *stockService) Check(ctx context.Context, movements []*domain.StockMovement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+	if err := s.place(ctx, movements); err != nil {
+		return err
+	}
+	tenantID := movements[0].TenantID
+
+	type key struct{ productID, warehouseID uuid.UUID }
+	net := map[key]float64{}
+	var keys []key
+	for _, movement := range movements {
+		k := key{movement.ProductID, movement.WarehouseID}
+		if _, ok := net[k]; !ok {
+			keys = append(keys, k)
+		}
+		net[k] += movement.Quantity
+	}
+
+	policy, err := s.Policy(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	allowed := map[uuid.UUID]bool{}
+	for _, k := range keys {
+		if net[k] >= 0 {
+			continue
+		}
+		allows, ok := allowed[k.warehouseID]
+		if !ok {
+			warehouse, err := s.warehouses.Get(ctx, tenantID, k.warehouseID)
+			if err != nil {
+				return err
+			}
+			allows = policy.AllowsNegative(warehouse)
+			allowed[k.warehouseID] = allows
+		}
+		if allows {
+			continue
+		}
+
+		levels, err := s.repo.ProductLevels(ctx, tenantID, k.productID)
+		if err != nil {
+			return err
+		}
+		var onHand float64
+		for _, level := range levels {
+			if level.WarehouseID == k.warehouseID {
+				onHand = level.Quantity
+			}
+		}
+		// Compared in thousandths, as quantities are kept
+		if math.Round((onHand+net[k])*1000) < 0 {
+			return domain.ShortStockError(k.productID, onHand, -net[k])
+		}
+	}
+	return nil
+}
+
+// place resolves the warehouse of each movement, once per warehouse
+func (s *stockService) place(ctx context.Context, movements []*domain.StockMovement) error {
+	resolved := map[uuid.UUID]uuid.UUID{}
+	for _, movement := range movements {
+		warehouseID, ok := resolved[movement.WarehouseID]
+		if !ok {
+			var err error
+			if warehouseID, err = s.warehouses.Resolve(ctx, movement.TenantID, movement.WarehouseID); err != nil {
+				return err
+			}
+			resolved[movement.WarehouseID] = warehouseID
+		}
+		movement.WarehouseID = warehouseID
+	}
+	return nil
+}
+
+// Transfer validates, records and audits a transfer
+func (s *stockService) Transfer(ctx context.Context, transfer *domain.StockTransfer) error {
+	var err error
+	if transfer.FromWarehouseID, err = s.warehouses.Resolve(ctx, transfer.TenantID, transfer.FromWarehouseID); err != nil {
+		return err
+	}
+	if transfer.ToWarehouseID, err = s.warehouses.Resolve(ctx, transfer.TenantID, transfer.ToWarehouseID); err != nil {
+		return err
+	}
+	if err := transfer.Validate(); err != nil {
+		return err
+	}
+	for _, line := range transfer.Lines {
+		if err := s.products.Exists(ctx, transfer.TenantID, line.ProductID); err != nil {
+			return err
+		}
+	}
+
+	movements, err := transfer.Movements()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RecordTransfer(ctx, transfer, movements); err != nil {
+		return err
+	}
+
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   transfer.CreatedBy,
+		TenantID: &transfer.TenantID,
+	}
+	entityIDStr := transfer.ID.String()
+	audit.Service.Log(ctx, "TRANSFER_STOCK", "StockTransfer", &entityIDStr, map[string]interface{}{
+		"transfer_number":   transfer.TransferNumber,
+		"from_warehouse_id": transfer.FromWarehouseID,
+		"to_warehouse_id":   transfer.ToWarehouseID,
+		"lines":             len(transfer.Lines),
+	}, auditCtx)
+	return nil
+}
+
+// GetTransfer retrieves a transfer with its lines
+func (s *stockService) GetTransfer(ctx context.Context, tenantID, id uuid.UUID) (*domain.StockTransfer, error) {
+	return s.repo.GetTransfer(ctx, tenantID, id)
+}
+
+// ListTransfers returns transfers, newest first
+func (s *stockService) ListTransfers(ctx context.Context, tenantID uuid.UUID, filter domain.StockTransferFilter) ([]*domain.StockTransfer, error) {
+	return s.repo.ListTransfers(ctx, tenantID, filter)
+}
+
+// GetAvailable combines a product's levels with its reservations
+func (s *stockService) GetAvailable(ctx context.Context, tenantID, productID uuid.UUID) (*domain.Availability, error) {
+	levels, reserved, err := s.availability(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewAvailability(productID, levels, reserved), nil
+}
+
+// GetAvailableAt combines a product's levels with its reservations for one warehouse
+func (s *stockService) GetAvailableAt(ctx context.Context, tenantID, productID, warehouseID uuid.UUID) (*domain.Availability, error) {
+	warehouse, err := s.warehouses.Get(ctx, tenantID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	levels, reserved, err := s.availability(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewWarehouseAvailability(productID, warehouse.ID, levels, reserved), nil
+}
+
+// availability reads a product's levels and reserved quantity
+func (s *stockService) availability(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.StockLevel, float64, error) {
+	if err := s.products.Exists(ctx, tenantID, productID); err != nil {
+		return nil, 0, err
+	}
+
+	levels, err := s.repo.ProductLevels(ctx, tenantID, productID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var reserved float64
+	if s.reservations != nil {
+		if reserved, err = s.reservations.Reserved(ctx, tenantID, productID); err != nil {
+			return nil, 0, fmt.Errorf("failed to read reserved stock: %w", err)
+		}
+	}
+	return levels, reserved, nil
+}
+
+// OnHand sums stock levels per product
+func (s *stockService) OnHand(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	return s.repo.OnHand(ctx, tenantID, productIDs)
+}
+
+// Levels returns stock levels
+func (s *stockService) Levels(ctx context.Context, tenantID uuid.UUID, filter domain.StockLevelFilter) ([]domain.StockLevel, error) {
+	return s.repo.Levels(ctx, tenantID, filter)
+}
+
+// Movements returns the stock ledger, newest first
+func (s *stockService) Movements(ctx context.Context, tenantID uuid.UUID, filter domain.StockMovementFilter) ([]*domain.StockMovement, error) {
+	return s.repo.Movements(ctx, tenantID, filter)
+}
+
+// NegativeExceptions returns the negative stock exceptions, latest first
+func (s *stockService) NegativeExceptions(ctx context.Context, tenantID uuid.UUID, filter domain.NegativeStockFilter) ([]domain.NegativeStockException, error) {
+	return s.repo.NegativeExceptions(ctx, tenantID, filter)
+}
+
+// Policy returns the tenant's stock policy
+func (s *stockService) Policy(ctx context.Context, tenantID uuid.UUID) (*domain.StockPolicy, error) {
+	policy, err := s.policies.Get(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = domain.DefaultStockPolicy(tenantID)
+	}
+	return policy, nil
+}
+
+// SetPolicy saves and audits the tenant's stock policy
+func (s *stockService) SetPolicy(ctx context.Context, tenantID uuid.UUID, allowNegativeStock bool, userID *uuid.UUID) (*domain.StockPolicy, error) {
+	policy := domain.NewStockPolicy(tenantID, allowNegativeStock)
+	if err := s.policies.Save(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &tenantID,
+	}
+	entityIDStr := tenantID.String()
+	audit.Service.Log(ctx, "SET_STOCK_POLICY", "StockPolicy", &entityIDStr, map[string]interface{}{
+		"allow_negative_stock": allowNegativeStock,
+	}, auditCtx)
+	return policy, nil
+}
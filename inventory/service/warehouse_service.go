@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/inventory/domain"
+	"github.com/aceextension/inventory/repository"
+	"github.com/google/uuid"
+)
+
+// WarehouseService defines the interface for the tenant's stock locations
+type WarehouseService interface {
+	// Create saves a warehouse, creating the tenant's default warehouse first if needed
+	Create(ctx context.Context, warehouse *domain.Warehouse, userID *uuid.UUID) error
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Warehouse, error)
+	// List returns the tenant's warehouses, the default first
+	List(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*domain.Warehouse, error)
+	// Update saves the code, name, address, active flag and stock policy override. The
+	// default warehouse and warehouses holding stock cannot be deactivated.
+	Update(ctx context.Context, warehouse *domain.Warehouse, userID *uuid.UUID) error
+	// Default returns the tenant's default warehouse, creating it on first use
+	Default(ctx context.Context, tenantID uuid.UUID) (*domain.Warehouse, error)
+	// Resolve returns the ID of the tenant's active warehouse, or of the default
+	// warehouse for MainWarehouse
+	Resolve(ctx context.Context, tenantID, warehouseID uuid.UUID) (uuid.UUID, error)
+}
+
+// warehouseService implements WarehouseService
+type warehouseService struct {
+	repo repository.WarehouseRepository
+}
+
+// NewWarehouseService creates a new warehouse service
+func NewWarehouseService(repo repository.WarehouseRepository) WarehouseService {
+	return &warehouseService{repo: repo}
+}
+
+// Create validates and saves a warehouse
+func (s *warehouseService) Create(ctx context.Context, warehouse *domain.Warehouse, userID *uuid.UUID) error {
+	if err := warehouse.Validate(); err != nil {
+		return err
+	}
+	// The default claims MAIN before the tenant's own warehouses do
+	if _, err := s.repo.Default(ctx, warehouse.TenantID); err != nil {
+		return err
+	}
+	warehouse.IsDefault = false
+
+	if err := s.repo.Create(ctx, warehouse); err != nil {
+		return err
+	}
+
+	s.audit(ctx, "CREATE_WAREHOUSE", warehouse, userID)
+	return nil
+}
+
+// Get retrieves a warehouse
+func (s *warehouseService) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Warehouse, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// List returns the tenant's warehouses; a tenant that has none gets its default one
+func (s *warehouseService) List(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]*domain.Warehouse, error) {
+	if _, err := s.repo.Default(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, tenantID, activeOnly)
+}
+
+// Update validates and saves a warehouse
+func (s *warehouseService) Update(ctx context.Context, warehouse *domain.Warehouse, userID *uuid.UUID) error {
+	existing, err := s.repo.GetByID(ctx, warehouse.TenantID, warehouse.ID)
+	if err != nil {
+		return err
+	}
+	warehouse.Code = domain.NormalizeWarehouseCode(warehouse.Code)
+	if err := warehouse.Validate(); err != nil {
+		return err
+	}
+
+	if existing.IsActive && !warehouse.IsActive {
+		if existing.IsDefault {
+			return domain.ErrDefaultWarehouse
+		}
+		held, err := s.repo.HasStock(ctx, warehouse.TenantID, warehouse.ID)
+		if err != nil {
+			return err
+		}
+		if held {
+			return domain.ErrWarehouseNotEmpty
+		}
+	}
+
+	warehouse.IsDefault = existing.IsDefault
+	warehouse.CreatedAt = existing.CreatedAt
+	warehouse.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, warehouse); err != nil {
+		return err
+	}
+
+	s.audit(ctx, "UPDATE_WAREHOUSE", warehouse, userID)
+	return nil
+}
+
+// Default returns the default warehouse
+func (s *warehouseService) Default(ctx context.Context, tenantID uuid.UUID) (*domain.Warehouse, error) {
+	return s.repo.Default(ctx, tenantID)
+}
+
+// Resolve checks the warehouse stock is moved in
+func (s *warehouseService) Resolve(ctx context.Context, tenantID, warehouseID uuid.UUID) (uuid.UUID, error) {
+	if warehouseID == domain.MainWarehouse {
+		warehouse, err := s.repo.Default(ctx, tenantID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return warehouse.ID, nil
+	}
+
+	warehouse, err := s.repo.GetByID(ctx, tenantID, warehouseID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !warehouse.IsActive {
+		return uuid.Nil, domain.ErrWarehouseInactive
+	}
+	return warehouse.ID, nil
+}
+
+func (s *warehouseService) audit(ctx context.Context, action string, warehouse *domain.Warehouse, userID *uuid.UUID) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &warehouse.TenantID,
+	}
+	entityIDStr := warehouse.ID.String()
+	audit.Service.Log(ctx, action, "Warehouse", &entityIDStr, map[string]interface{}{
+		"code":                 warehouse.Code,
+		"name":                 warehouse.Name,
+		"is_active":            warehouse.IsActive,
+		"allow_negative_stock": warehouse.AllowNegativeStock,
+	}, auditCtx)
+}
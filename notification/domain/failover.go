@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidFailoverPolicy is returned when a failover chain is malformed
+var ErrInvalidFailoverPolicy = errors.New("invalid failover policy")
+
+// MaxFailoverAttempts is the most attempts a notification may fail before failing over;
+// notifications are not retried beyond it
+const MaxFailoverAttempts = 3
+
+// FailoverPolicy is a tenant's chain of channels for one event, e.g. SMS, then EMAIL,
+// then VOICE for CUSTOMER_OTP. The event is the notification's reference type. Once a
+// notification on a channel of the chain has failed AfterAttempts times, the worker
+// sends it again on the next channel.
+type FailoverPolicy struct {
+	ID            uuid.UUID     `json:"id"`
+	TenantID      uuid.UUID     `json:"tenantId"`
+	Event         string        `json:"event"`
+	Channels      []ChannelType `json:"channels"`
+	AfterAttempts int           `json:"afterAttempts"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	UpdatedAt     time.Time     `json:"updatedAt"`
+}
+
+// NewFailoverPolicy creates a failover policy, checking the chain
+func NewFailoverPolicy(tenantID uuid.UUID, event string, channels []ChannelType, afterAttempts int) (*FailoverPolicy, error) {
+	event = strings.ToUpper(strings.TrimSpace(event))
+	if event == "" {
+		return nil, fmt.Errorf("%w: event is required", ErrInvalidFailoverPolicy)
+	}
+	if len(channels) < 2 {
+		return nil, fmt.Errorf("%w: a chain needs at least two channels", ErrInvalidFailoverPolicy)
+	}
+	seen := make(map[ChannelType]bool, len(channels))
+	for i, channel := range channels {
+		channel = ChannelType(strings.ToUpper(string(channel)))
+		switch channel {
+		case ChannelSMS, ChannelEmail, ChannelWhatsApp, ChannelVoice:
+		default:
+			return nil, fmt.Errorf("%w: channel %q cannot be in a chain", ErrInvalidFailoverPolicy, channel)
+		}
+		if seen[channel] {
+			return nil, fmt.Errorf("%w: channel %s is repeated", ErrInvalidFailoverPolicy, channel)
+		}
+		seen[channel] = true
+		channels[i] = channel
+	}
+	if afterAttempts == 0 {
+		afterAttempts = 1
+	}
+	if afterAttempts < 1 || afterAttempts > MaxFailoverAttempts {
+		return nil, fmt.Errorf("%w: afterAttempts must be 1 to %d", ErrInvalidFailoverPolicy, MaxFailoverAttempts)
+	}
+
+	now := time.Now()
+	return &FailoverPolicy{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		Event:         event,
+		Channels:      channels,
+		AfterAttempts: afterAttempts,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// Next returns the channels to try after a failure on channel, in order. A channel
+// outside the chain fails over to the start of it.
+func (p *FailoverPolicy) Next(channel ChannelType) []ChannelType {
+	for i, c := range p.Channels {
+		if c == channel {
+			return p.Channels[i+1:]
+		}
+	}
+	return p.Channels
+}
+
+// FailoverEvent records a failed notification being handed to the next channel, or
+// the chain running out. Channels skipped for want of a recipient address are listed.
+type FailoverEvent struct {
+	At              time.Time     `json:"at"`
+	FromChannel     ChannelType   `json:"fromChannel"`
+	ToChannel       ChannelType   `json:"toChannel,omitempty"` // Empty when no channel was left
+	NotificationID  *uuid.UUID    `json:"notificationId,omitempty"`
+	Reason          string        `json:"reason,omitempty"` // The last delivery error
+	SkippedChannels []ChannelType `json:"skippedChannels,omitempty"`
+}
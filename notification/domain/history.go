@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HistoryFilter selects notifications for a communication history view.
+// At least one of CustomerID or Recipient must be set.
+type HistoryFilter struct {
+	TenantID   uuid.UUID
+	CustomerID *uuid.UUID // Matches notifications linked to the customer or sent to their email/phone
+	Recipient  *string    // Raw email address or phone number
+	Channel    *ChannelType
+	Status     *NotificationStatus
+	From       *time.Time
+	To         *time.Time
+}
+
+// DefaultHistoryLimit is the communication history page size when none is asked for
+const DefaultHistoryLimit = 50
+
+// CommunicationRecord is a notification joined with the entities it relates to
+type CommunicationRecord struct {
+	Notification
+
+	TemplateCode  *string `json:"templateCode,omitempty"`
+	CustomerName  *string `json:"customerName,omitempty"`
+	DeliveryState string  `json:"deliveryState"` // Human-readable status for support staff
+}
+
+// DeliveryStateLabel describes a notification status for support staff
+func DeliveryStateLabel(status NotificationStatus, nextAttemptAt *time.Time) string {
+	switch status {
+	case StatusSent:
+		return "Delivered to provider"
+	case StatusFailed:
+		if nextAttemptAt == nil {
+			return "Failed permanently"
+		}
+		return "Failed, will retry"
+	case StatusProcessing:
+		return "Sending"
+	default:
+		return "Queued"
+	}
+}
+
+// FailoverStateLabel describes a notification that was failed over, for support staff
+func FailoverStateLabel(n *Notification) string {
+	if len(n.FailoverEvents) > 0 {
+		if to := n.FailoverEvents[len(n.FailoverEvents)-1].ToChannel; to != "" {
+			return "Failed, resent by " + string(to)
+		}
+	}
+	return "Failed permanently"
+}
@@ -43,9 +43,32 @@ type Notification struct {
 	Status       NotificationStatus `json:"status"`
 	RetryCount   int                `json:"retryCount"`
 	ErrorMessage *string            `json:"errorMessage,omitempty"`
-	SentAt       *time.Time         `json:"sentAt,omitempty"`
-	TemplateID   *uuid.UUID         `json:"templateId,omitempty"`
-	CreatedAt    time.Time          `json:"createdAt"`
+	// When a failed notification is sent again, per its provider's retry policy;
+	// nil once it will not be
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	SentAt        *time.Time `json:"sentAt,omitempty"`
+	TemplateID    *uuid.UUID `json:"templateId,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+
+	// Related entities, used to build per-customer communication history
+	CustomerID    *uuid.UUID `json:"customerId,omitempty"`
+	ReferenceType *string    `json:"referenceType,omitempty"` // e.g. "INVOICE", "PAYMENT"
+	ReferenceID   *uuid.UUID `json:"referenceId,omitempty"`
+
+	// Variables the template was rendered with, kept to render it again for a failover channel
+	Variables map[string]interface{} `json:"-"`
+
+	// Secrets are the sealed values of the content's {{secret.*}} placeholders, such as
+	// OTP codes, so the stored content never carries them. SecretValues holds them opened
+	// while the notification is in memory; it is never stored.
+	Secrets      *string           `json:"-"`
+	SecretValues map[string]string `json:"-"`
+
+	// Failover: the notification this one replaces, the one that replaced it and what happened
+	FailoverFromID         *uuid.UUID      `json:"failoverFromId,omitempty"`
+	FailoverNotificationID *uuid.UUID      `json:"failoverNotificationId,omitempty"`
+	FailedOverAt           *time.Time      `json:"failedOverAt,omitempty"`
+	FailoverEvents         []FailoverEvent `json:"failoverEvents,omitempty"`
 }
 
 // NewNotification creates a new notification
@@ -18,8 +18,16 @@ const (
 	ChannelWhatsApp ChannelType = "WHATSAPP"
 	// InApp channel
 	ChannelInApp ChannelType = "IN_APP"
+	// Voice channel, a call reading the message out
+	ChannelVoice ChannelType = "VOICE"
 )
 
+// PhoneBased reports whether the channel reaches a phone number, so its recipient
+// can be reused by the other phone channels
+func (c ChannelType) PhoneBased() bool {
+	return c == ChannelSMS || c == ChannelWhatsApp || c == ChannelVoice
+}
+
 // Template represents a notification template
 type Template struct {
 	ID        uuid.UUID   `json:"id"`
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/notification/domain"
+	"github.com/aceextension/notification/service"
+	"github.com/labstack/echo/v4"
+)
+
+// FailoverHandler handles failover policy requests
+type FailoverHandler struct {
+	service service.NotificationService
+}
+
+// NewFailoverHandler creates a new failover handler
+func NewFailoverHandler(service service.NotificationService) *FailoverHandler {
+	return &FailoverHandler{service: service}
+}
+
+// SetFailoverPolicyRequest request body
+type SetFailoverPolicyRequest struct {
+	Channels      []string `json:"channels" validate:"required,min=2"`
+	AfterAttempts int      `json:"afterAttempts"`
+}
+
+// Set sets the failover chain of an event
+// @Summary Set a failover policy
+// @Description Set the chain of channels a notification event fails over along, e.g. SMS, EMAIL, VOICE for CUSTOMER_OTP. The event is the notification's reference type. Once a notification has failed afterAttempts times (1-3, default 1) the worker sends it on the next channel the recipient can be reached on, with the event's template for that channel, and records the failover on the failed notification.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param event path string true "Event (reference type), e.g. CUSTOMER_OTP"
+// @Param request body SetFailoverPolicyRequest true "Failover chain"
+// @Success 200 {object} domain.FailoverPolicy
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/notifications/failover-policies/{event} [put]
+// @Security BearerAuth
+func (h *FailoverHandler) Set(c echo.Context) error {
+	var req SetFailoverPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	channels := make([]domain.ChannelType, len(req.Channels))
+	for i, channel := range req.Channels {
+		channels[i] = domain.ChannelType(strings.ToUpper(channel))
+	}
+
+	policy, err := h.service.SetFailoverPolicy(c.Request().Context(), tenantID, c.Param("event"), channels, req.AfterAttempts)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidFailoverPolicy) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, policy)
+}
+
+// List lists failover policies
+// @Summary List failover policies
+// @Description List the tenant's failover chains by event
+// @Tags notifications
+// @Produce json
+// @Success 200 {array} domain.FailoverPolicy
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/notifications/failover-policies [get]
+// @Security BearerAuth
+func (h *FailoverHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	policies, err := h.service.GetFailoverPolicies(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, policies)
+}
+
+// Delete removes the failover chain of an event
+// @Summary Delete a failover policy
+// @Description Stop failing over notifications of an event; they are only retried on their own channel
+// @Tags notifications
+// @Param event path string true "Event (reference type)"
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/notifications/failover-policies/{event} [delete]
+// @Security BearerAuth
+func (h *FailoverHandler) Delete(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if err := h.service.DeleteFailoverPolicy(c.Request().Context(), tenantID, c.Param("event")); err != nil {
+		if errors.Is(err, service.ErrFailoverPolicyNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
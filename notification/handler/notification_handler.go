@@ -1,7 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aceextension/core/db"
 	"github.com/aceextension/notification/domain"
@@ -29,6 +33,10 @@ type SendNotificationRequest struct {
 	Content    string                 `json:"content"`
 	Priority   string                 `json:"priority"`
 	Variables  map[string]interface{} `json:"variables"`
+
+	CustomerID    *string `json:"customerId"`
+	ReferenceType *string `json:"referenceType"`
+	ReferenceID   *string `json:"referenceId"`
 }
 
 // Send sends a notification
@@ -82,6 +90,21 @@ func (h *NotificationHandler) Send(c echo.Context) error {
 		}
 	}
 
+	if req.CustomerID != nil {
+		cid, err := uuid.Parse(*req.CustomerID)
+		if err == nil {
+			serviceReq.CustomerID = &cid
+		}
+	}
+
+	if req.ReferenceID != nil {
+		rid, err := uuid.Parse(*req.ReferenceID)
+		if err == nil {
+			serviceReq.ReferenceID = &rid
+			serviceReq.ReferenceType = req.ReferenceType
+		}
+	}
+
 	notification, err := h.service.Send(c.Request().Context(), serviceReq)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
@@ -95,7 +118,7 @@ func (h *NotificationHandler) Send(c echo.Context) error {
 
 // GetQueue retrieves pending notifications
 // @Summary Get notification queue
-// @Description Get pending notifications in the queue
+// @Description Get the tenant's pending notifications in the queue. Message secrets such as OTP codes are never included.
 // @Tags notifications
 // @Produce json
 // @Success 200 {array} domain.Notification
@@ -103,9 +126,98 @@ func (h *NotificationHandler) Send(c echo.Context) error {
 // @Router /api/v1/notifications/queue [get]
 // @Security BearerAuth
 func (h *NotificationHandler) GetQueue(c echo.Context) error {
-	notifications, err := h.service.GetPendingNotifications(c.Request().Context())
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	notifications, err := h.service.GetPendingNotifications(c.Request().Context(), tenantID)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, notifications)
 }
+
+// GetHistory retrieves the communication history of a customer or address
+// @Summary Get communication history
+// @Description List SMS/emails sent to a customer (by customerId) or a raw email/phone (by recipient), newest first.
+// @Description Pass the nextCursor of a page as cursor to get the next; it is absent on the last page.
+// @Description Owners and admins only; the tenant is the caller's own, never one named in a header.
+// @Tags notifications
+// @Produce json
+// @Param customerId query string false "Customer ID"
+// @Param recipient query string false "Email address or phone number"
+// @Param channel query string false "Channel (SMS, EMAIL, WHATSAPP, IN_APP, VOICE)"
+// @Param status query string false "Status (PENDING, PROCESSING, SENT, FAILED)"
+// @Param from query string false "From date (YYYY-MM-DD, inclusive)"
+// @Param to query string false "To date (YYYY-MM-DD, inclusive)"
+// @Param limit query int false "Page size (max 200)" default(50)
+// @Param cursor query string false "nextCursor of the previous page"
+// @Success 200 {object} db.Page[domain.CommunicationRecord]
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/notifications/history [get]
+// @Security BearerAuth
+func (h *NotificationHandler) GetHistory(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	filter := domain.HistoryFilter{TenantID: tenantID}
+
+	if v := c.QueryParam("customerId"); v != "" {
+		cid, err := uuid.Parse(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customerId"})
+		}
+		filter.CustomerID = &cid
+	}
+	if v := strings.TrimSpace(c.QueryParam("recipient")); v != "" {
+		filter.Recipient = &v
+	}
+	if v := c.QueryParam("channel"); v != "" {
+		channel := domain.ChannelType(strings.ToUpper(v))
+		filter.Channel = &channel
+	}
+	if v := c.QueryParam("status"); v != "" {
+		status := domain.NotificationStatus(strings.ToUpper(v))
+		filter.Status = &status
+	}
+	if v := c.QueryParam("from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from date, expected YYYY-MM-DD"})
+		}
+		filter.From = &from
+	}
+	if v := c.QueryParam("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to date, expected YYYY-MM-DD"})
+		}
+		// Make the end date inclusive
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	limit := c.QueryParam("limit")
+	if limit == "" {
+		limit = strconv.Itoa(domain.DefaultHistoryLimit)
+	}
+	page, err := db.ParsePageRequest(c.QueryParam("cursor"), limit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	records, err := h.service.GetCommunicationHistory(c.Request().Context(), filter, page)
+	if err != nil {
+		if errors.Is(err, service.ErrHistoryRecipientRequired) || errors.Is(err, service.ErrInvalidHistoryRange) ||
+			errors.Is(err, db.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, records)
+}
@@ -116,8 +116,9 @@ func (h *TemplateHandler) List(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
-// RegisterRoutes registers routes
-func RegisterRoutes(e *echo.Echo) {
+// RegisterRoutes registers the notification routes on v1, which the caller mounts at
+// /api/v1/notifications behind its authentication and role checks
+func RegisterRoutes(v1 *echo.Group) {
 	// Ensure service is initialized if not already
 	if notification.Service == nil {
 		notification.Init()
@@ -127,13 +128,16 @@ func RegisterRoutes(e *echo.Echo) {
 
 	nHandler := NewNotificationHandler(svc)
 	tHandler := NewTemplateHandler(svc)
+	fHandler := NewFailoverHandler(svc)
 
-	v1 := e.Group("/api/v1/notifications")
-	// Add TenantMiddleware to ensure tenant context is present
-	v1.Use(middleware.TenantMiddleware)
+	v1.Use(middleware.RequireFeature(middleware.FeatureNotifications))
 
 	v1.POST("/send", nHandler.Send)
 	v1.GET("/queue", nHandler.GetQueue)
+	v1.GET("/history", nHandler.GetHistory)
 	v1.POST("/templates", tHandler.Create)
 	v1.GET("/templates", tHandler.List)
+	v1.GET("/failover-policies", fHandler.List)
+	v1.PUT("/failover-policies/:event", fHandler.Set)
+	v1.DELETE("/failover-policies/:event", fHandler.Delete)
 }
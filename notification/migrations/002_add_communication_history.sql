@@ -0,0 +1,12 @@
+-- Link notifications to the customer and business record they were sent for
+ALTER TABLE notifications
+    ADD COLUMN customer_id UUID,
+    ADD COLUMN reference_type VARCHAR(50),
+    ADD COLUMN reference_id UUID;
+
+-- Communication history lookups: by customer, or by raw address for older rows
+CREATE INDEX idx_notifications_customer_history ON notifications(tenant_id, customer_id, created_at DESC)
+    WHERE customer_id IS NOT NULL;
+CREATE INDEX idx_notifications_recipient_history ON notifications(tenant_id, recipient, created_at DESC);
+CREATE INDEX idx_notifications_reference ON notifications(tenant_id, reference_type, reference_id)
+    WHERE reference_id IS NOT NULL;
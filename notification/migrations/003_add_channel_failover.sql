@@ -0,0 +1,30 @@
+-- Per-tenant failover chains: when a notification for an event fails on one channel,
+-- the worker sends it again on the next channel of the chain
+CREATE TABLE notification_failover_policies (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    channels VARCHAR(20)[] NOT NULL,
+    after_attempts INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE(tenant_id, event)
+);
+
+ALTER TABLE notification_failover_policies ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_notification_failover_policies ON notification_failover_policies
+    USING (tenant_id = current_setting('app.current_tenant')::uuid);
+
+-- Template variables are kept so a fallback channel's template can be rendered later;
+-- failover_events records each hand-over on the notification that failed
+ALTER TABLE notifications
+    ADD COLUMN variables JSONB,
+    ADD COLUMN failover_from_id UUID REFERENCES notifications(id),
+    ADD COLUMN failover_notification_id UUID,
+    ADD COLUMN failed_over_at TIMESTAMPTZ,
+    ADD COLUMN failover_events JSONB NOT NULL DEFAULT '[]';
+
+-- Failed notifications the worker has not yet failed over
+CREATE INDEX idx_notifications_failover_due ON notifications(tenant_id, reference_type)
+    WHERE status = 'FAILED' AND failed_over_at IS NULL;
@@ -0,0 +1,2 @@
+-- Communication history pages by (created_at, id) instead of OFFSET
+CREATE INDEX idx_notifications_tenant_created ON notifications(tenant_id, created_at DESC, id DESC);
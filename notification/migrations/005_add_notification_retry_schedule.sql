@@ -0,0 +1,10 @@
+-- Failed notifications are retried on their provider's backoff schedule rather than
+-- on every worker tick; next_attempt_at is NULL once one will not be retried
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ;
+
+-- Notifications the old fixed limit of 3 attempts would still have retried
+UPDATE notifications SET next_attempt_at = NOW()
+WHERE status = 'FAILED' AND retry_count < 3 AND failed_over_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_retry_due ON notifications(next_attempt_at)
+    WHERE status = 'FAILED' AND failed_over_at IS NULL;
@@ -0,0 +1,3 @@
+-- Values such as OTP codes are kept out of the stored content: it holds {{secret.*}}
+-- placeholders and the values are sealed here, filled in only when the message is sent
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS secrets TEXT;
@@ -1,6 +1,12 @@
 package notification
 
 import (
+	"strconv"
+	"strings"
+
+	"github.com/aceextension/core/config"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/notification/domain"
 	"github.com/aceextension/notification/repository"
 	"github.com/aceextension/notification/service"
 )
@@ -10,6 +16,8 @@ var (
 	TemplateRepo repository.TemplateRepository
 	// NotificationRepo instance
 	NotificationRepo repository.NotificationRepository
+	// FailoverRepo instance
+	FailoverRepo repository.FailoverPolicyRepository
 	// Service instance
 	Service service.NotificationService
 )
@@ -18,5 +26,58 @@ var (
 func Init() {
 	TemplateRepo = repository.NewPostgresTemplateRepository()
 	NotificationRepo = repository.NewPostgresNotificationRepository()
-	Service = service.NewNotificationService(NotificationRepo, TemplateRepo)
+	FailoverRepo = repository.NewPostgresFailoverPolicyRepository()
+	Service = service.NewNotificationService(NotificationRepo, TemplateRepo, FailoverRepo)
+	for channel, sender := range platformSenders() {
+		Service.SetSender(channel, sender)
+	}
+}
+
+// platformSenders builds the platform providers from SMS_PROVIDER and EMAIL_PROVIDER.
+// Outside production, messages on a channel with no provider are logged, so OTPs
+// can be read from the console.
+func platformSenders() map[domain.ChannelType]service.Sender {
+	cfg := config.GlobalConfig
+	if cfg == nil {
+		return nil
+	}
+
+	smsToken := cfg.SparrowSMSToken
+	if strings.EqualFold(cfg.SMSProvider, "twilio") {
+		smsToken = cfg.TwilioAuthToken
+	}
+	providers := map[domain.ChannelType]string{
+		domain.ChannelSMS:   cfg.SMSProvider,
+		domain.ChannelEmail: cfg.EmailProvider,
+	}
+	settings := map[domain.ChannelType]map[string]string{
+		domain.ChannelSMS: {
+			"token":       smsToken,
+			"sender_id":   cfg.SMSFrom,
+			"account_sid": cfg.TwilioAccountSID,
+		},
+		domain.ChannelEmail: {
+			"from":      cfg.EmailFrom,
+			"from_name": cfg.EmailFromName,
+			"host":      cfg.SMTPHost,
+			"port":      strconv.Itoa(cfg.SMTPPort),
+			"username":  cfg.SMTPUsername,
+			"password":  cfg.SMTPPassword,
+			"api_key":   cfg.SendGridAPIKey,
+		},
+	}
+
+	senders := map[domain.ChannelType]service.Sender{}
+	for _, channel := range []domain.ChannelType{domain.ChannelSMS, domain.ChannelEmail, domain.ChannelWhatsApp, domain.ChannelVoice} {
+		provider := providers[channel]
+		if provider == "" && cfg.Env != "production" {
+			provider = "log"
+		}
+		sender, err := service.NewSender(channel, provider, settings[channel])
+		if err != nil {
+			logger.Log.Fatal("Invalid " + string(channel) + " provider settings: " + err.Error())
+		}
+		senders[channel] = sender
+	}
+	return senders
 }
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/notification/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresFailoverPolicyRepository implements FailoverPolicyRepository
+type PostgresFailoverPolicyRepository struct{}
+
+// NewPostgresFailoverPolicyRepository creates a new PostgreSQL failover policy repository
+func NewPostgresFailoverPolicyRepository() *PostgresFailoverPolicyRepository {
+	return &PostgresFailoverPolicyRepository{}
+}
+
+// Upsert saving the policy for its tenant and event
+func (r *PostgresFailoverPolicyRepository) Upsert(ctx context.Context, p *domain.FailoverPolicy) error {
+	query := `
+		INSERT INTO notification_failover_policies (
+			id, tenant_id, event, channels, after_attempts, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id, event) DO UPDATE SET
+			channels = EXCLUDED.channels,
+			after_attempts = EXCLUDED.after_attempts,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+	err := db.MainPool.QueryRow(ctx, query,
+		p.ID, p.TenantID, p.Event, channelStrings(p.Channels), p.AfterAttempts, p.CreatedAt, p.UpdatedAt,
+	).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save failover policy: %w", err)
+	}
+	return nil
+}
+
+// Get retrieving the policy for an event
+func (r *PostgresFailoverPolicyRepository) Get(ctx context.Context, tenantID uuid.UUID, event string) (*domain.FailoverPolicy, error) {
+	query := `
+		SELECT id, tenant_id, event, channels, after_attempts, created_at, updated_at
+		FROM notification_failover_policies WHERE tenant_id = $1 AND event = $2
+	`
+	p, err := r.scanPolicy(db.MainPool.QueryRow(ctx, query, tenantID, event))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return p, err
+}
+
+// List retrieving all policies of a tenant
+func (r *PostgresFailoverPolicyRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*domain.FailoverPolicy, error) {
+	query := `
+		SELECT id, tenant_id, event, channels, after_attempts, created_at, updated_at
+		FROM notification_failover_policies WHERE tenant_id = $1 ORDER BY event
+	`
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failover policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []*domain.FailoverPolicy{}
+	for rows.Next() {
+		p, err := r.scanPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// Delete deleting the policy for an event
+func (r *PostgresFailoverPolicyRepository) Delete(ctx context.Context, tenantID uuid.UUID, event string) (bool, error) {
+	tag, err := db.MainPool.Exec(ctx,
+		"DELETE FROM notification_failover_policies WHERE tenant_id = $1 AND event = $2",
+		tenantID, event,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete failover policy: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *PostgresFailoverPolicyRepository) scanPolicy(row pgx.Row) (*domain.FailoverPolicy, error) {
+	var p domain.FailoverPolicy
+	var channels []string
+	err := row.Scan(&p.ID, &p.TenantID, &p.Event, &channels, &p.AfterAttempts, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range channels {
+		p.Channels = append(p.Channels, domain.ChannelType(c))
+	}
+	return &p, nil
+}
+
+func channelStrings(channels []domain.ChannelType) []string {
+	out := make([]string, len(channels))
+	for i, c := range channels {
+		out[i] = string(c)
+	}
+	return out
+}
@@ -2,7 +2,11 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aceextension/core/db"
 	"github.com/aceextension/notification/domain"
@@ -35,13 +39,13 @@ func (r *PostgresTemplateRepository) Create(ctx context.Context, template *domai
 	return nil
 }
 
-// GetByID retrieving template by ID
-func (r *PostgresTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Template, error) {
+// GetByID retrieving a tenant's template by ID
+func (r *PostgresTemplateRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Template, error) {
 	query := `
 		SELECT id, tenant_id, code, channel, subject, body, is_active, created_at, updated_at
-		FROM templates WHERE id = $1
+		FROM templates WHERE id = $1 AND tenant_id = $2
 	`
-	return r.scanTemplate(db.MainPool.QueryRow(ctx, query, id))
+	return r.scanTemplate(db.MainPool.QueryRow(ctx, query, id, tenantID))
 }
 
 // GetByCode retrieving template by code and channel for a tenant
@@ -93,8 +97,8 @@ func (r *PostgresTemplateRepository) Update(ctx context.Context, template *domai
 }
 
 // Delete deleting template
-func (r *PostgresTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	_, err := db.MainPool.Exec(ctx, "DELETE FROM templates WHERE id = $1", id)
+func (r *PostgresTemplateRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	_, err := db.MainPool.Exec(ctx, "DELETE FROM templates WHERE id = $1 AND tenant_id = $2", id, tenantID)
 	return err
 }
 
@@ -135,38 +139,145 @@ func (r *PostgresNotificationRepository) Create(ctx context.Context, n *domain.N
 	query := `
 		INSERT INTO notifications (
 			id, tenant_id, user_id, channel, recipient, subject, content,
-			priority, status, retry_count, error_message, sent_at, template_id, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			priority, status, retry_count, error_message, sent_at, template_id, created_at,
+			customer_id, reference_type, reference_id, variables, failover_from_id, secrets
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 	`
-	_, err := db.MainPool.Exec(ctx, query,
-		n.ID, n.TenantID, n.UserID, n.Channel, n.Recipient, n.Subject, n.Content,
-		n.Priority, n.Status, n.RetryCount, n.ErrorMessage, n.SentAt, n.TemplateID, n.CreatedAt,
-	)
+	_, err := db.MainPool.Exec(ctx, query, notificationArgs(n)...)
 	if err != nil {
 		return fmt.Errorf("failed to create notification: %w", err)
 	}
 	return nil
 }
 
-// GetByID retrieving notification by ID
-func (r *PostgresNotificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Notification, error) {
+// CreateFailover inserts the fallback notification and marks the failed one as failed
+// over, with the event, in one transaction. Another worker having handled the failed
+// notification first returns ErrFailoverHandled and creates nothing.
+func (r *PostgresNotificationRepository) CreateFailover(ctx context.Context, failed *domain.Notification, event domain.FailoverEvent, fallback *domain.Notification) error {
+	events, err := json.Marshal([]domain.FailoverEvent{event})
+	if err != nil {
+		return err
+	}
+
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var fallbackID *uuid.UUID
+		if fallback != nil {
+			fallbackID = &fallback.ID
+		}
+		tag, err := tx.Exec(ctx, `
+			UPDATE notifications SET
+				failed_over_at = NOW(), failover_notification_id = $1,
+				failover_events = failover_events || $2::jsonb
+			WHERE id = $3 AND failed_over_at IS NULL
+		`, fallbackID, events, failed.ID)
+		if err != nil {
+			return fmt.Errorf("failed to record failover: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrFailoverHandled
+		}
+
+		if fallback == nil {
+			return nil
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO notifications (
+				id, tenant_id, user_id, channel, recipient, subject, content,
+				priority, status, retry_count, error_message, sent_at, template_id, created_at,
+				customer_id, reference_type, reference_id, variables, failover_from_id, secrets
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		`, notificationArgs(fallback)...); err != nil {
+			return fmt.Errorf("failed to create failover notification: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetFailoverDue returns failed notifications whose event has a failover policy and
+// that have failed as often as the policy waits for, or will not be retried, oldest first
+func (r *PostgresNotificationRepository) GetFailoverDue(ctx context.Context, limit int) ([]*domain.Notification, error) {
+	query := `
+		SELECT n.id, n.tenant_id, n.user_id, n.channel, n.recipient, n.subject, n.content,
+		       n.priority, n.status, n.retry_count, n.error_message, n.sent_at, n.template_id, n.created_at,
+		       n.customer_id, n.reference_type, n.reference_id,
+		       n.variables, n.failover_from_id, n.failover_notification_id, n.failed_over_at, n.failover_events, n.next_attempt_at, n.secrets
+		FROM notifications n
+		JOIN notification_failover_policies p ON p.tenant_id = n.tenant_id AND p.event = n.reference_type
+		WHERE n.status = 'FAILED' AND n.failed_over_at IS NULL
+		  AND (n.retry_count >= p.after_attempts OR n.next_attempt_at IS NULL)
+		ORDER BY n.priority DESC, n.created_at ASC
+		LIMIT $1
+	`
+	rows, err := db.MainPool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications due for failover: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		n, err := r.scanNotificationRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// GetCustomerAddresses returns a customer's email and phone, either of which may be nil
+func (r *PostgresNotificationRepository) GetCustomerAddresses(ctx context.Context, tenantID, customerID uuid.UUID) (email, phone *string, err error) {
+	err = db.MainPool.QueryRow(ctx,
+		"SELECT email, phone FROM customers WHERE id = $1 AND tenant_id = $2",
+		customerID, tenantID,
+	).Scan(&email, &phone)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil
+	}
+	return email, phone, err
+}
+
+func notificationArgs(n *domain.Notification) []interface{} {
+	return []interface{}{
+		n.ID, n.TenantID, n.UserID, n.Channel, n.Recipient, n.Subject, n.Content,
+		n.Priority, n.Status, n.RetryCount, n.ErrorMessage, n.SentAt, n.TemplateID, n.CreatedAt,
+		n.CustomerID, n.ReferenceType, n.ReferenceID, n.Variables, n.FailoverFromID, n.Secrets,
+	}
+}
+
+// GetByID retrieving a tenant's notification by ID
+func (r *PostgresNotificationRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Notification, error) {
 	query := `
 		SELECT id, tenant_id, user_id, channel, recipient, subject, content,
-		       priority, status, retry_count, error_message, sent_at, template_id, created_at
-		FROM notifications WHERE id = $1
+		       priority, status, retry_count, error_message, sent_at, template_id, created_at,
+		       customer_id, reference_type, reference_id,
+		       variables, failover_from_id, failover_notification_id, failed_over_at, failover_events, next_attempt_at, secrets
+		FROM notifications WHERE id = $1 AND tenant_id = $2
 	`
-	return r.scanNotification(db.MainPool.QueryRow(ctx, query, id))
+	return r.scanNotification(db.MainPool.QueryRow(ctx, query, id, tenantID))
 }
 
-// GetByTenantID retrieving notifications for a tenant
-func (r *PostgresNotificationRepository) GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Notification, error) {
+// GetByTenantID retrieving a page of notifications for a tenant, newest first
+func (r *PostgresNotificationRepository) GetByTenantID(ctx context.Context, tenantID uuid.UUID, page db.PageRequest) ([]*domain.Notification, error) {
+	var afterAt *time.Time
+	var afterID *uuid.UUID
+	if page.After != nil {
+		afterAt, afterID = new(time.Time), new(uuid.UUID)
+		if err := page.After.Scan(afterAt, afterID); err != nil {
+			return nil, err
+		}
+	}
+
 	query := `
 		SELECT id, tenant_id, user_id, channel, recipient, subject, content,
-		       priority, status, retry_count, error_message, sent_at, template_id, created_at
+		       priority, status, retry_count, error_message, sent_at, template_id, created_at,
+		       customer_id, reference_type, reference_id,
+		       variables, failover_from_id, failover_notification_id, failed_over_at, failover_events, next_attempt_at, secrets
 		FROM notifications WHERE tenant_id = $1
-		ORDER BY created_at DESC LIMIT $2 OFFSET $3
+		AND ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3))
+		ORDER BY created_at DESC, id DESC LIMIT $4
 	`
-	rows, err := db.MainPool.Query(ctx, query, tenantID, limit, offset)
+	rows, err := db.MainPool.Query(ctx, query, tenantID, afterAt, afterID, page.Fetch())
 	if err != nil {
 		return nil, fmt.Errorf("failed to list notifications: %w", err)
 	}
@@ -187,11 +298,11 @@ func (r *PostgresNotificationRepository) GetByTenantID(ctx context.Context, tena
 func (r *PostgresNotificationRepository) Update(ctx context.Context, n *domain.Notification) error {
 	query := `
 		UPDATE notifications SET
-			status = $1, retry_count = $2, error_message = $3, sent_at = $4
-		WHERE id = $5
+			status = $1, retry_count = $2, error_message = $3, sent_at = $4, next_attempt_at = $5
+		WHERE id = $6
 	`
 	_, err := db.MainPool.Exec(ctx, query,
-		n.Status, n.RetryCount, n.ErrorMessage, n.SentAt, n.ID,
+		n.Status, n.RetryCount, n.ErrorMessage, n.SentAt, n.NextAttemptAt, n.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update notification: %w", err)
@@ -203,9 +314,12 @@ func (r *PostgresNotificationRepository) Update(ctx context.Context, n *domain.N
 func (r *PostgresNotificationRepository) GetPending(ctx context.Context, limit int) ([]*domain.Notification, error) {
 	query := `
 		SELECT id, tenant_id, user_id, channel, recipient, subject, content,
-		       priority, status, retry_count, error_message, sent_at, template_id, created_at
+		       priority, status, retry_count, error_message, sent_at, template_id, created_at,
+		       customer_id, reference_type, reference_id,
+		       variables, failover_from_id, failover_notification_id, failed_over_at, failover_events, next_attempt_at, secrets
 		FROM notifications
-		WHERE status IN ('PENDING', 'FAILED') AND retry_count < 3
+		WHERE (status = 'PENDING' OR (status = 'FAILED' AND next_attempt_at <= NOW()))
+		  AND failed_over_at IS NULL
 		ORDER BY priority DESC, created_at ASC
 		LIMIT $1
 	`
@@ -226,11 +340,44 @@ func (r *PostgresNotificationRepository) GetPending(ctx context.Context, limit i
 	return notifications, nil
 }
 
+// GetQueued retrieving a tenant's pending notifications, in the order the worker sends them
+func (r *PostgresNotificationRepository) GetQueued(ctx context.Context, tenantID uuid.UUID, limit int) ([]*domain.Notification, error) {
+	query := `
+		SELECT id, tenant_id, user_id, channel, recipient, subject, content,
+		       priority, status, retry_count, error_message, sent_at, template_id, created_at,
+		       customer_id, reference_type, reference_id,
+		       variables, failover_from_id, failover_notification_id, failed_over_at, failover_events, next_attempt_at, secrets
+		FROM notifications
+		WHERE tenant_id = $1
+		  AND (status = 'PENDING' OR (status = 'FAILED' AND next_attempt_at IS NOT NULL))
+		  AND failed_over_at IS NULL
+		ORDER BY priority DESC, created_at ASC
+		LIMIT $2
+	`
+	rows, err := db.MainPool.Query(ctx, query, tenantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queued notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		n, err := r.scanNotificationRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
 func (r *PostgresNotificationRepository) scanNotification(row pgx.Row) (*domain.Notification, error) {
 	var n domain.Notification
 	err := row.Scan(
 		&n.ID, &n.TenantID, &n.UserID, &n.Channel, &n.Recipient, &n.Subject, &n.Content,
 		&n.Priority, &n.Status, &n.RetryCount, &n.ErrorMessage, &n.SentAt, &n.TemplateID, &n.CreatedAt,
+		&n.CustomerID, &n.ReferenceType, &n.ReferenceID,
+		&n.Variables, &n.FailoverFromID, &n.FailoverNotificationID, &n.FailedOverAt, &n.FailoverEvents, &n.NextAttemptAt, &n.Secrets,
 	)
 	if err != nil {
 		return nil, err
@@ -243,9 +390,112 @@ func (r *PostgresNotificationRepository) scanNotificationRow(rows pgx.Rows) (*do
 	err := rows.Scan(
 		&n.ID, &n.TenantID, &n.UserID, &n.Channel, &n.Recipient, &n.Subject, &n.Content,
 		&n.Priority, &n.Status, &n.RetryCount, &n.ErrorMessage, &n.SentAt, &n.TemplateID, &n.CreatedAt,
+		&n.CustomerID, &n.ReferenceType, &n.ReferenceID,
+		&n.Variables, &n.FailoverFromID, &n.FailoverNotificationID, &n.FailedOverAt, &n.FailoverEvents, &n.NextAttemptAt, &n.Secrets,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &n, nil
 }
+
+// GetHistory retrieving communication history joined with templates and customers
+func (r *PostgresNotificationRepository) GetHistory(ctx context.Context, filter domain.HistoryFilter, page db.PageRequest) ([]*domain.CommunicationRecord, int64, error) {
+	conditions := []string{"n.tenant_id = $1"}
+	args := []interface{}{filter.TenantID}
+
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// Older notifications have no customer_id, so also match the customer's current addresses
+	var recipientMatch []string
+	if filter.CustomerID != nil {
+		p := addArg(*filter.CustomerID)
+		recipientMatch = append(recipientMatch,
+			"n.customer_id = "+p,
+			"n.recipient IN (SELECT x FROM customers c, LATERAL (VALUES (c.email), (c.phone)) v(x) WHERE c.id = "+p+" AND c.tenant_id = n.tenant_id AND x IS NOT NULL)",
+		)
+	}
+	if filter.Recipient != nil {
+		recipientMatch = append(recipientMatch, "n.recipient = "+addArg(*filter.Recipient))
+	}
+	if len(recipientMatch) > 0 {
+		conditions = append(conditions, "("+strings.Join(recipientMatch, " OR ")+")")
+	}
+
+	if filter.Channel != nil {
+		conditions = append(conditions, "n.channel = "+addArg(*filter.Channel))
+	}
+	if filter.Status != nil {
+		conditions = append(conditions, "n.status = "+addArg(*filter.Status))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "n.created_at >= "+addArg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "n.created_at < "+addArg(*filter.To))
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int64
+	if err := db.MainPool.QueryRow(ctx, "SELECT COUNT(*) FROM notifications n WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count communication history: %w", err)
+	}
+
+	query := `
+		SELECT n.id, n.tenant_id, n.user_id, n.channel, n.recipient, n.subject, n.content,
+		       n.priority, n.status, n.retry_count, n.error_message, n.sent_at, n.template_id, n.created_at,
+		       n.customer_id, n.reference_type, n.reference_id,
+		       n.variables, n.failover_from_id, n.failover_notification_id, n.failed_over_at, n.failover_events, n.next_attempt_at,
+		       t.code, c.name
+		FROM notifications n
+		LEFT JOIN templates t ON t.id = n.template_id
+		LEFT JOIN customers c ON c.id = n.customer_id AND c.tenant_id = n.tenant_id
+		WHERE ` + where
+	// The total counts every match; the page starts after the cursor's row
+	if page.After != nil {
+		var afterAt time.Time
+		var afterID uuid.UUID
+		if err := page.After.Scan(&afterAt, &afterID); err != nil {
+			return nil, 0, err
+		}
+		query += " AND (n.created_at, n.id) < (" + addArg(afterAt) + ", " + addArg(afterID) + ")"
+	}
+	query += `
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT ` + addArg(page.Fetch())
+
+	rows, err := db.MainPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query communication history: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*domain.CommunicationRecord{}
+	for rows.Next() {
+		var rec domain.CommunicationRecord
+		n := &rec.Notification
+		if err := rows.Scan(
+			&n.ID, &n.TenantID, &n.UserID, &n.Channel, &n.Recipient, &n.Subject, &n.Content,
+			&n.Priority, &n.Status, &n.RetryCount, &n.ErrorMessage, &n.SentAt, &n.TemplateID, &n.CreatedAt,
+			&n.CustomerID, &n.ReferenceType, &n.ReferenceID,
+			&n.Variables, &n.FailoverFromID, &n.FailoverNotificationID, &n.FailedOverAt, &n.FailoverEvents, &n.NextAttemptAt,
+			&rec.TemplateCode, &rec.CustomerName,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan communication record: %w", err)
+		}
+		rec.DeliveryState = domain.DeliveryStateLabel(n.Status, n.NextAttemptAt)
+		if n.FailedOverAt != nil {
+			rec.DeliveryState = domain.FailoverStateLabel(n)
+		}
+		records = append(records, &rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return records, total, nil
+}
@@ -2,27 +2,54 @@ package repository
 
 import (
 	"context"
+	"errors"
 
+	"github.com/aceextension/core/db"
 	"github.com/aceextension/notification/domain"
 	"github.com/google/uuid"
 )
 
+// ErrFailoverHandled is returned when a failed notification was already failed over
+var ErrFailoverHandled = errors.New("notification was already failed over")
+
 // TemplateRepository defines the interface for template data access
 type TemplateRepository interface {
 	Create(ctx context.Context, template *domain.Template) error
-	GetByID(ctx context.Context, id uuid.UUID) (*domain.Template, error)
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Template, error)
 	GetByCode(ctx context.Context, tenantID uuid.UUID, code string, channel domain.ChannelType) (*domain.Template, error)
 	GetByTenantID(ctx context.Context, tenantID uuid.UUID) ([]*domain.Template, error)
 	Update(ctx context.Context, template *domain.Template) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
 }
 
 // NotificationRepository defines the interface for notification data access
 type NotificationRepository interface {
 	Create(ctx context.Context, notification *domain.Notification) error
-	GetByID(ctx context.Context, id uuid.UUID) (*domain.Notification, error)
-	GetByTenantID(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*domain.Notification, error)
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Notification, error)
+	GetByTenantID(ctx context.Context, tenantID uuid.UUID, page db.PageRequest) ([]*domain.Notification, error)
 	Update(ctx context.Context, notification *domain.Notification) error
 	// GetPending returns notifications that are pending or failed (with retries left)
 	GetPending(ctx context.Context, limit int) ([]*domain.Notification, error)
+	// GetQueued returns a tenant's notifications the worker has still to send
+	GetQueued(ctx context.Context, tenantID uuid.UUID, limit int) ([]*domain.Notification, error)
+	// GetHistory returns matching notifications, newest first, with the total match count
+	GetHistory(ctx context.Context, filter domain.HistoryFilter, page db.PageRequest) ([]*domain.CommunicationRecord, int64, error)
+	// GetFailoverDue returns failed notifications their event's failover policy hands on
+	GetFailoverDue(ctx context.Context, limit int) ([]*domain.Notification, error)
+	// CreateFailover records the event on the failed notification and creates the
+	// fallback, if any; ErrFailoverHandled when it was already failed over
+	CreateFailover(ctx context.Context, failed *domain.Notification, event domain.FailoverEvent, fallback *domain.Notification) error
+	// GetCustomerAddresses returns a customer's email and phone for failover channels
+	GetCustomerAddresses(ctx context.Context, tenantID, customerID uuid.UUID) (email, phone *string, err error)
+}
+
+// FailoverPolicyRepository defines the interface for failover policy data access
+type FailoverPolicyRepository interface {
+	// Upsert saves the tenant's policy for the event, replacing any earlier one
+	Upsert(ctx context.Context, policy *domain.FailoverPolicy) error
+	// Get returns the tenant's policy for an event, nil when there is none
+	Get(ctx context.Context, tenantID uuid.UUID, event string) (*domain.FailoverPolicy, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]*domain.FailoverPolicy, error)
+	// Delete removes the policy for an event, reporting whether there was one
+	Delete(ctx context.Context, tenantID uuid.UUID, event string) (bool, error)
 }
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newEmailSender creates an email driver from tenant integration settings
+func newEmailSender(provider string, settings map[string]string) (Sender, error) {
+	from := mail.Address{Name: settings["from_name"], Address: settings["from"]}
+	if _, err := mail.ParseAddress(from.Address); err != nil {
+		return nil, fmt.Errorf("%s email needs a valid from address", provider)
+	}
+
+	switch provider {
+	case "smtp":
+		if settings["host"] == "" {
+			return nil, errors.New("smtp email needs a host")
+		}
+		port := 587
+		if settings["port"] != "" {
+			parsed, err := strconv.Atoi(settings["port"])
+			if err != nil || parsed <= 0 || parsed > 65535 {
+				return nil, fmt.Errorf("invalid smtp port %q", settings["port"])
+			}
+			port = parsed
+		}
+		return NewSMTPEmail(settings["host"], port, settings["username"], settings["password"], from), nil
+	case "sendgrid":
+		if settings["api_key"] == "" {
+			return nil, errors.New("sendgrid email needs an API key")
+		}
+		return NewSendGridEmail(settings["api_key"], from), nil
+	}
+	return nil, fmt.Errorf("unknown email provider %q", provider)
+}
+
+// SMTPEmail sends through an SMTP relay. Port 465 is implicit TLS; on other ports the
+// connection is upgraded with STARTTLS when the server offers it.
+type SMTPEmail struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     mail.Address
+}
+
+// NewSMTPEmail creates an SMTP driver, authenticating when username is set
+func NewSMTPEmail(host string, port int, username, password string, from mail.Address) *SMTPEmail {
+	return &SMTPEmail{host: host, port: port, username: username, password: password, from: from}
+}
+
+func (s *SMTPEmail) Name() string {
+	return "smtp"
+}
+
+// Send delivers the message to the relay in one SMTP session
+func (s *SMTPEmail) Send(ctx context.Context, msg Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return permanent(fmt.Errorf("smtp: invalid recipient %q", msg.To))
+	}
+
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	tlsConfig := &tls.Config{ServerName: s.host}
+	dialer := &net.Dialer{Timeout: senderTimeout}
+	var conn net.Conn
+	if s.port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	deadline := time.Now().Add(senderTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return smtpFailure(err)
+	}
+	defer client.Close()
+
+	if s.port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return smtpFailure(err)
+			}
+		}
+	}
+	// PlainAuth refuses to send the password over a connection that is not encrypted
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return smtpFailure(err)
+		}
+	}
+	if err := client.Mail(s.from.Address); err != nil {
+		return smtpFailure(err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return smtpFailure(err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return smtpFailure(err)
+	}
+	if _, err := w.Write(composeEmail(s.from, *to, msg)); err != nil {
+		return smtpFailure(err)
+	}
+	if err := w.Close(); err != nil {
+		return smtpFailure(err)
+	}
+	// The relay has accepted the message; a failed QUIT does not undo that
+	_ = client.Quit()
+	return nil
+}
+
+// smtpFailure marks 5xx replies, which the relay would give again, as permanent
+func smtpFailure(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return permanent(fmt.Errorf("smtp: %w", err))
+	}
+	return fmt.Errorf("smtp: %w", err)
+}
+
+// composeEmail writes a plain-text UTF-8 message with its headers
+func composeEmail(from, to mail.Address, msg Message) []byte {
+	var b bytes.Buffer
+	header := func(name, value string) {
+		b.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", singleLine(msg.Subject)))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+uuid.NewString()+"@"+from.Address[strings.LastIndex(from.Address, "@")+1:]+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	b.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&b)
+	_, _ = body.Write([]byte(msg.Body))
+	_ = body.Close()
+	return b.Bytes()
+}
+
+// SendGridEmail sends through SendGrid's v3 Mail Send API
+type SendGridEmail struct {
+	apiKey   string
+	from     mail.Address
+	endpoint string
+	client   *http.Client
+}
+
+// NewSendGridEmail creates a SendGrid driver
+func NewSendGridEmail(apiKey string, from mail.Address) *SendGridEmail {
+	return &SendGridEmail{
+		apiKey:   apiKey,
+		from:     from,
+		endpoint: "https://api.sendgrid.com/v3/mail/send",
+		client:   &http.Client{Timeout: senderTimeout},
+	}
+}
+
+func (s *SendGridEmail) Name() string {
+	return "sendgrid"
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+// Send queues the message with SendGrid, which answers 202 Accepted
+func (s *SendGridEmail) Send(ctx context.Context, msg Message) error {
+	payload := sendGridMail{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: msg.To}}}},
+		From:             sendGridAddress{Email: s.from.Address, Name: s.from.Name},
+		Subject:          singleLine(msg.Subject),
+		Content:          []sendGridContent{{Type: "text/plain", Value: msg.Body}},
+	}
+
+	var body struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	status, err := postJSON(ctx, s.client, s.endpoint, payload, func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}, &body)
+	if err != nil {
+		return fmt.Errorf("sendgrid: %w", err)
+	}
+	if status != http.StatusAccepted && status != http.StatusOK {
+		var messages []string
+		for _, e := range body.Errors {
+			messages = append(messages, e.Message)
+		}
+		return httpFailure("sendgrid", status, strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// singleLine joins a header value's lines, so it cannot inject other headers
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -2,39 +2,69 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/security"
 	"github.com/aceextension/notification/domain"
 	"github.com/aceextension/notification/repository"
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrHistoryRecipientRequired is returned when history is requested without a customer or address
+	ErrHistoryRecipientRequired = errors.New("customerId or recipient is required")
+	// ErrInvalidHistoryRange is returned when the date range is empty or reversed
+	ErrInvalidHistoryRange = errors.New("from must be before to")
+	// ErrFailoverPolicyNotFound is returned when the tenant has no failover policy for an event
+	ErrFailoverPolicyNotFound = errors.New("failover policy not found")
+	// ErrSecretsUnavailable is returned when a message's secrets cannot be opened to send it
+	ErrSecretsUnavailable = errors.New("message secrets are no longer available; request a new message")
+)
+
+// secretPlaceholder starts the placeholders filled from SendRequest.Secrets
+const secretPlaceholder = "{{secret."
+
 type notificationService struct {
 	repo         repository.NotificationRepository
 	templateRepo repository.TemplateRepository
+	failoverRepo repository.FailoverPolicyRepository
+	branding     BrandingProvider
+	cipher       *security.Cipher              // Seals message secrets; nil keeps them in memory only
+	senders      map[domain.ChannelType]Sender // Platform providers, for tenants without their own
 }
 
 // NewNotificationService creates a new notification service
-func NewNotificationService(repo repository.NotificationRepository, templateRepo repository.TemplateRepository) NotificationService {
+func NewNotificationService(repo repository.NotificationRepository, templateRepo repository.TemplateRepository, failoverRepo repository.FailoverPolicyRepository) NotificationService {
 	return &notificationService{
 		repo:         repo,
 		templateRepo: templateRepo,
+		failoverRepo: failoverRepo,
+		senders:      map[domain.ChannelType]Sender{},
 	}
 }
 
 // Send sends a notification
 func (s *notificationService) Send(ctx context.Context, req SendRequest) (*domain.Notification, error) {
-	content := req.Content
+	content, subject := req.Content, req.Subject
 
 	// Render template if ID is provided
 	if req.TemplateID != nil {
-		template, err := s.templateRepo.GetByID(ctx, *req.TemplateID)
+		template, err := s.templateRepo.GetByID(ctx, req.TenantID, *req.TemplateID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get template: %w", err)
 		}
-		content = renderTemplate(template.Body, req.Variables)
+		variables := s.templateVariables(ctx, req)
+		content = renderTemplate(template.Body, variables)
+		subject = ""
+		if template.Subject != nil {
+			subject = renderTemplate(*template.Subject, variables)
+		}
 	}
 
 	// Create notification record
@@ -42,7 +72,27 @@ func (s *notificationService) Send(ctx context.Context, req SendRequest) (*domai
 	notification.UserID = req.UserID
 	notification.Priority = req.Priority
 	notification.TemplateID = req.TemplateID
-	notification.Subject = getSubject(req, content)
+	notification.Variables = req.Variables
+	notification.CustomerID = req.CustomerID
+	notification.ReferenceType = req.ReferenceType
+	notification.ReferenceID = req.ReferenceID
+	if subject != "" {
+		notification.Subject = &subject
+	}
+	if len(req.Secrets) > 0 {
+		notification.SecretValues = req.Secrets
+		if s.cipher != nil {
+			data, err := json.Marshal(req.Secrets)
+			if err != nil {
+				return nil, err
+			}
+			sealed, err := s.cipher.Seal(data, req.TenantID[:])
+			if err != nil {
+				return nil, fmt.Errorf("failed to seal notification secrets: %w", err)
+			}
+			notification.Secrets = &sealed
+		}
+	}
 
 	if err := s.repo.Create(ctx, notification); err != nil {
 		return nil, fmt.Errorf("failed to create notification record: %w", err)
@@ -68,6 +118,18 @@ func (s *notificationService) Send(ctx context.Context, req SendRequest) (*domai
 
 // ProcessPending processes pending notifications
 func (s *notificationService) ProcessPending(ctx context.Context) error {
+	// Hand notifications that failed often enough to the next channel of their event's
+	// chain first, so they are not retried on the channel that failed
+	due, err := s.repo.GetFailoverDue(ctx, 10)
+	if err != nil {
+		return fmt.Errorf("failed to get notifications due for failover: %w", err)
+	}
+	for _, n := range due {
+		if err := s.failover(ctx, n); err != nil {
+			log.Printf("Failed to fail over notification %s: %v", n.ID, err)
+		}
+	}
+
 	// Fetch pending notifications
 	notifications, err := s.repo.GetPending(ctx, 10) // Process batch of 10
 	if err != nil {
@@ -83,6 +145,118 @@ func (s *notificationService) ProcessPending(ctx context.Context) error {
 	return nil
 }
 
+// failover sends a failed notification again on the next channel of its event's chain
+// that the recipient can be reached on, re-rendering the template for that channel.
+// The hand-over, or the chain running out, is recorded on the failed notification.
+func (s *notificationService) failover(ctx context.Context, n *domain.Notification) error {
+	if n.ReferenceType == nil {
+		return nil
+	}
+	policy, err := s.failoverRepo.Get(ctx, n.TenantID, *n.ReferenceType)
+	if err != nil {
+		return fmt.Errorf("failed to get failover policy: %w", err)
+	}
+	if policy == nil { // Removed since the notification was picked up
+		return nil
+	}
+
+	event := domain.FailoverEvent{At: time.Now(), FromChannel: n.Channel}
+	if n.ErrorMessage != nil {
+		event.Reason = *n.ErrorMessage
+	}
+
+	var fallback *domain.Notification
+	for _, channel := range policy.Next(n.Channel) {
+		recipient, err := s.failoverRecipient(ctx, n, channel)
+		if err != nil {
+			return err
+		}
+		if recipient == "" {
+			event.SkippedChannels = append(event.SkippedChannels, channel)
+			continue
+		}
+		fallback = s.failoverNotification(ctx, n, channel, recipient)
+		event.ToChannel = channel
+		event.NotificationID = &fallback.ID
+		break
+	}
+
+	if err := s.repo.CreateFailover(ctx, n, event, fallback); err != nil {
+		if errors.Is(err, repository.ErrFailoverHandled) {
+			return nil
+		}
+		return err
+	}
+	if fallback == nil {
+		log.Printf("Notification %s failed on %s with no failover channel left", n.ID, n.Channel)
+		return nil
+	}
+	return s.sendInstant(ctx, fallback)
+}
+
+// failoverRecipient finds the failed notification's recipient on another channel:
+// phone channels share the number, otherwise the linked customer's address is used.
+// Empty when the recipient cannot be reached on the channel.
+func (s *notificationService) failoverRecipient(ctx context.Context, n *domain.Notification, channel domain.ChannelType) (string, error) {
+	if channel.PhoneBased() && n.Channel.PhoneBased() {
+		return n.Recipient, nil
+	}
+	if n.CustomerID == nil {
+		return "", nil
+	}
+
+	email, phone, err := s.repo.GetCustomerAddresses(ctx, n.TenantID, *n.CustomerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get customer addresses: %w", err)
+	}
+	address := email
+	if channel.PhoneBased() {
+		address = phone
+	}
+	if address == nil {
+		return "", nil
+	}
+	return strings.TrimSpace(*address), nil
+}
+
+// failoverNotification copies a failed notification onto another channel. Content
+// comes from the tenant's template of the same code for that channel, rendered with the
+// original variables, or else is the original content.
+func (s *notificationService) failoverNotification(ctx context.Context, n *domain.Notification, channel domain.ChannelType, recipient string) *domain.Notification {
+	fallback := domain.NewNotification(n.TenantID, channel, recipient, n.Content)
+	fallback.UserID = n.UserID
+	fallback.Priority = n.Priority
+	fallback.Subject = n.Subject
+	fallback.Variables = n.Variables
+	fallback.CustomerID = n.CustomerID
+	fallback.ReferenceType = n.ReferenceType
+	fallback.ReferenceID = n.ReferenceID
+	fallback.Secrets = n.Secrets
+	fallback.SecretValues = n.SecretValues
+	fallback.FailoverFromID = &n.ID
+
+	if n.TemplateID == nil {
+		return fallback
+	}
+	original, err := s.templateRepo.GetByID(ctx, n.TenantID, *n.TemplateID)
+	if err != nil {
+		return fallback
+	}
+	template, err := s.templateRepo.GetByCode(ctx, n.TenantID, original.Code, channel)
+	if err != nil || !template.IsActive {
+		return fallback
+	}
+
+	variables := s.templateVariables(ctx, SendRequest{TenantID: n.TenantID, Variables: n.Variables})
+	fallback.Content = renderTemplate(template.Body, variables)
+	fallback.TemplateID = &template.ID
+	if template.Subject != nil {
+		subject := renderTemplate(*template.Subject, variables)
+		fallback.Subject = &subject
+	}
+	return fallback
+}
+
 // sendInstant actually sends the notification via provider
 func (s *notificationService) sendInstant(ctx context.Context, n *domain.Notification) error {
 	// Update status to PROCESSING
@@ -91,12 +265,27 @@ func (s *notificationService) sendInstant(ctx context.Context, n *domain.Notific
 		return fmt.Errorf("failed to update status to processing: %w", err)
 	}
 
-	// Mock Sending (Replace with real provider logic later)
-	log.Printf("SENDING [%s] to %s: %s", n.Channel, n.Recipient, n.Content)
+	if provider, err := s.deliver(ctx, n); err != nil {
+		message := err.Error()
+		n.Status = domain.StatusFailed
+		n.RetryCount++
+		n.ErrorMessage = &message
+		n.NextAttemptAt = nil
+		if policy := RetryPolicyFor(provider); !IsPermanent(err) && n.RetryCount < policy.MaxAttempts {
+			next := time.Now().Add(policy.Delay(n.RetryCount))
+			n.NextAttemptAt = &next
+		}
+		if updateErr := s.repo.Update(ctx, n); updateErr != nil {
+			return fmt.Errorf("failed to update status to failed: %w", updateErr)
+		}
+		return err
+	}
 
-	// Simulate success
+	now := time.Now()
 	n.Status = domain.StatusSent
-	// n.SentAt = time.Now() // Need to handle *time.Time
+	n.SentAt = &now
+	n.ErrorMessage = nil
+	n.NextAttemptAt = nil
 
 	if err := s.repo.Update(ctx, n); err != nil {
 		return fmt.Errorf("failed to update status to sent: %w", err)
@@ -105,6 +294,92 @@ func (s *notificationService) sendInstant(ctx context.Context, n *domain.Notific
 	return nil
 }
 
+// deliver hands a notification to its channel's provider, returning the provider's
+// name. In-app notifications are read from the notification list, so storing them
+// is their delivery.
+func (s *notificationService) deliver(ctx context.Context, n *domain.Notification) (string, error) {
+	if n.Channel == domain.ChannelInApp {
+		return "in_app", nil
+	}
+
+	sender, err := s.sender(ctx, n.TenantID, n.Channel)
+	if err != nil {
+		return "", err
+	}
+	body, err := s.fillSecrets(n)
+	if err != nil {
+		return sender.Name(), err
+	}
+	msg := Message{To: n.Recipient, Body: body}
+	if n.Channel == domain.ChannelEmail {
+		msg.Subject = emailSubject(n)
+	}
+	return sender.Name(), sender.Send(ctx, msg)
+}
+
+// fillSecrets returns the content with its {{secret.*}} placeholders filled in, opening
+// the sealed secrets when the notification was loaded from the queue
+func (s *notificationService) fillSecrets(n *domain.Notification) (string, error) {
+	if !strings.Contains(n.Content, secretPlaceholder) {
+		return n.Content, nil
+	}
+	values := n.SecretValues
+	if values == nil && n.Secrets != nil && s.cipher != nil {
+		data, err := s.cipher.Open(*n.Secrets, n.TenantID[:])
+		if err != nil {
+			return "", permanent(fmt.Errorf("%w: %v", ErrSecretsUnavailable, err))
+		}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return "", permanent(fmt.Errorf("%w: %v", ErrSecretsUnavailable, err))
+		}
+	}
+	if values == nil {
+		return "", permanent(ErrSecretsUnavailable)
+	}
+
+	content := n.Content
+	for name, value := range values {
+		content = strings.ReplaceAll(content, secretPlaceholder+name+"}}", value)
+	}
+	return content, nil
+}
+
+// tenantIntegrations are the channels tenants can send on through their own provider accounts
+var tenantIntegrations = map[domain.ChannelType]string{
+	domain.ChannelSMS:   security.IntegrationSMS,
+	domain.ChannelEmail: security.IntegrationEmail,
+}
+
+// sender returns the provider a tenant's messages on a channel go out through: its
+// own account when it has configured one, the platform's otherwise
+func (s *notificationService) sender(ctx context.Context, tenantID uuid.UUID, channel domain.ChannelType) (Sender, error) {
+	if integration, ok := tenantIntegrations[channel]; ok {
+		credentials, err := security.TenantCredentials(ctx, tenantID, integration)
+		if err == nil {
+			return NewSender(channel, credentials.Get("provider"), credentials.Values)
+		}
+		if !errors.Is(err, security.ErrNoCredentials) {
+			return nil, fmt.Errorf("failed to read %s provider credentials: %w", channel, err)
+		}
+	}
+	if sender, ok := s.senders[channel]; ok {
+		return sender, nil
+	}
+	return unconfiguredSender{channel: channel}, nil
+}
+
+// emailSubject is the notification's subject, or else the first line of its content
+func emailSubject(n *domain.Notification) string {
+	if n.Subject != nil && strings.TrimSpace(*n.Subject) != "" {
+		return *n.Subject
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(n.Content), "\n")
+	if runes := []rune(strings.TrimSpace(line)); len(runes) > 78 {
+		return string(runes[:77]) + "…"
+	}
+	return strings.TrimSpace(line)
+}
+
 func (s *notificationService) GetTemplates(ctx context.Context, tenantID uuid.UUID) ([]*domain.Template, error) {
 	return s.templateRepo.GetByTenantID(ctx, tenantID)
 }
@@ -113,6 +388,66 @@ func (s *notificationService) CreateTemplate(ctx context.Context, template *doma
 	return s.templateRepo.Create(ctx, template)
 }
 
+// SetFailoverPolicy validates and saves the tenant's failover chain for an event
+func (s *notificationService) SetFailoverPolicy(ctx context.Context, tenantID uuid.UUID, event string, channels []domain.ChannelType, afterAttempts int) (*domain.FailoverPolicy, error) {
+	policy, err := domain.NewFailoverPolicy(tenantID, event, channels, afterAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.failoverRepo.Upsert(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *notificationService) GetFailoverPolicies(ctx context.Context, tenantID uuid.UUID) ([]*domain.FailoverPolicy, error) {
+	return s.failoverRepo.List(ctx, tenantID)
+}
+
+func (s *notificationService) DeleteFailoverPolicy(ctx context.Context, tenantID uuid.UUID, event string) error {
+	deleted, err := s.failoverRepo.Delete(ctx, tenantID, strings.ToUpper(event))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrFailoverPolicyNotFound
+	}
+	return nil
+}
+
+// SetSender sets the platform's provider for a channel
+func (s *notificationService) SetSender(channel domain.ChannelType, sender Sender) {
+	s.senders[channel] = sender
+}
+
+// SetCipher sets the cipher message secrets are sealed with
+func (s *notificationService) SetCipher(cipher *security.Cipher) {
+	s.cipher = cipher
+}
+
+// SetBrandingProvider registers the branding source
+func (s *notificationService) SetBrandingProvider(provider BrandingProvider) {
+	s.branding = provider
+}
+
+// templateVariables merges tenant branding with request variables; request values win
+func (s *notificationService) templateVariables(ctx context.Context, req SendRequest) map[string]interface{} {
+	if s.branding == nil {
+		return req.Variables
+	}
+
+	variables, err := s.branding.TemplateVariables(ctx, req.TenantID)
+	if err != nil {
+		log.Printf("Failed to load branding for tenant %s: %v", req.TenantID, err)
+		return req.Variables
+	}
+
+	for k, v := range req.Variables {
+		variables[k] = v
+	}
+	return variables
+}
+
 // Simple template renderer {{key}} -> value
 func renderTemplate(body string, variables map[string]interface{}) string {
 	for k, v := range variables {
@@ -122,13 +457,25 @@ func renderTemplate(body string, variables map[string]interface{}) string {
 	return body
 }
 
-func (s *notificationService) GetPendingNotifications(ctx context.Context) ([]*domain.Notification, error) {
+func (s *notificationService) GetPendingNotifications(ctx context.Context, tenantID uuid.UUID) ([]*domain.Notification, error) {
 	// For inspection, just get first 50 pending items
-	return s.repo.GetPending(ctx, 50)
+	return s.repo.GetQueued(ctx, tenantID, 50)
 }
 
-func getSubject(req SendRequest, content string) *string {
-	// Logic to extract subject, e.g., from template or request
-	// For now, return nil or a pointer to a string if provided
-	return nil
+// GetCommunicationHistory retrieves a customer's or address's communication history
+func (s *notificationService) GetCommunicationHistory(ctx context.Context, filter domain.HistoryFilter, page db.PageRequest) (db.Page[*domain.CommunicationRecord], error) {
+	if filter.CustomerID == nil && (filter.Recipient == nil || *filter.Recipient == "") {
+		return db.Page[*domain.CommunicationRecord]{}, ErrHistoryRecipientRequired
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return db.Page[*domain.CommunicationRecord]{}, ErrInvalidHistoryRange
+	}
+
+	records, total, err := s.repo.GetHistory(ctx, filter, page)
+	if err != nil {
+		return db.Page[*domain.CommunicationRecord]{}, err
+	}
+	return db.NewPage(records, total, page, func(r *domain.CommunicationRecord) db.Cursor {
+		return db.NewCursor(r.CreatedAt, r.ID)
+	}), nil
 }
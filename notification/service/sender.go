@@ -0,0 +1,203 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/notification/domain"
+)
+
+// senderTimeout bounds one request to a provider
+const senderTimeout = 15 * time.Second
+
+// ErrNotConfigured is returned when a message is sent on a channel with no provider configured
+var ErrNotConfigured = errors.New("no provider configured")
+
+// Message is one message handed to a provider
+type Message struct {
+	To      string // Phone number or email address
+	Subject string // Email only
+	Body    string
+}
+
+// Sender delivers messages on one channel through a provider
+type Sender interface {
+	// Name identifies the provider in logs and retry policies, e.g. sparrow
+	Name() string
+	// Send hands a message to the provider. Errors that retrying cannot fix are
+	// marked permanent; see IsPermanent.
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender creates the driver for a channel's provider from its settings, which use
+// the keys of the tenant integration schemas:
+//   - SMS: sparrow (token, sender_id) or twilio (account_sid, token, sender_id)
+//   - EMAIL: smtp (host, port, username, password, from, from_name) or sendgrid
+//     (api_key, from, from_name)
+//
+// log writes messages to the log instead. An empty provider has none, so messages fail.
+func NewSender(channel domain.ChannelType, provider string, settings map[string]string) (Sender, error) {
+	provider = strings.ToLower(provider)
+	switch {
+	case provider == "log":
+		return LogSender{Channel: channel}, nil
+	case provider == "":
+		return unconfiguredSender{channel: channel}, nil
+	case channel == domain.ChannelSMS:
+		return newSMSSender(provider, settings)
+	case channel == domain.ChannelEmail:
+		return newEmailSender(provider, settings)
+	}
+	return nil, fmt.Errorf("unknown %s provider %q", channel, provider)
+}
+
+// LogSender writes messages to the log instead of sending them, for development
+type LogSender struct {
+	Channel domain.ChannelType
+}
+
+func (LogSender) Name() string {
+	return "log"
+}
+
+func (l LogSender) Send(ctx context.Context, msg Message) error {
+	if msg.Subject != "" {
+		logger.Log.Info(fmt.Sprintf("%s to %s: %s\n%s", l.Channel, msg.To, msg.Subject, msg.Body))
+		return nil
+	}
+	logger.Log.Info(fmt.Sprintf("%s to %s: %s", l.Channel, msg.To, msg.Body))
+	return nil
+}
+
+// unconfiguredSender fails every message, so they are failed over
+type unconfiguredSender struct {
+	channel domain.ChannelType
+}
+
+func (unconfiguredSender) Name() string {
+	return "none"
+}
+
+func (u unconfiguredSender) Send(ctx context.Context, msg Message) error {
+	return permanent(fmt.Errorf("%w for %s", ErrNotConfigured, u.channel))
+}
+
+// RetryPolicy is how often and how soon a provider's failed messages are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Including the first
+	BaseDelay   time.Duration // Before the second attempt, doubling for each one after
+	MaxDelay    time.Duration
+}
+
+// Delay returns how long to wait after the given number of failed attempts
+func (p RetryPolicy) Delay(failures int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// defaultRetryPolicy applies to providers without their own, and to failures before
+// a provider was picked
+var defaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 15 * time.Minute}
+
+// retryPolicies are per provider. SMS carry OTPs that expire within minutes, so they
+// are retried sooner and give up earlier than email.
+var retryPolicies = map[string]RetryPolicy{
+	"sparrow":  {MaxAttempts: 4, BaseDelay: 30 * time.Second, MaxDelay: 5 * time.Minute},
+	"twilio":   {MaxAttempts: 4, BaseDelay: 30 * time.Second, MaxDelay: 5 * time.Minute},
+	"smtp":     {MaxAttempts: 6, BaseDelay: time.Minute, MaxDelay: time.Hour},
+	"sendgrid": {MaxAttempts: 6, BaseDelay: time.Minute, MaxDelay: time.Hour},
+}
+
+// RetryPolicyFor returns a provider's retry policy
+func RetryPolicyFor(provider string) RetryPolicy {
+	if policy, ok := retryPolicies[provider]; ok {
+		return policy
+	}
+	return defaultRetryPolicy
+}
+
+// permanentError marks a failure that sending the same message again cannot fix,
+// such as an invalid recipient
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+func permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether a send failed in a way retrying cannot fix
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// httpFailure describes a provider's error response. 4xx rejections other than
+// timeouts and rate limits are permanent; 5xx are worth retrying.
+func httpFailure(provider string, status int, detail string) error {
+	err := fmt.Errorf("%s responded %d: %s", provider, status, detail)
+	if status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests {
+		return permanent(err)
+	}
+	return err
+}
+
+// postForm posts a form and decodes the JSON response into out, returning the HTTP status
+func postForm(ctx context.Context, client *http.Client, endpoint string, form url.Values, prepare func(*http.Request), out any) (int, error) {
+	return post(ctx, client, endpoint, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()), prepare, out)
+}
+
+// postJSON posts a JSON body and decodes the JSON response into out, returning the HTTP status
+func postJSON(ctx context.Context, client *http.Client, endpoint string, payload any, prepare func(*http.Request), out any) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	return post(ctx, client, endpoint, "application/json", bytes.NewReader(body), prepare, out)
+}
+
+func post(ctx context.Context, client *http.Client, endpoint, contentType string, body io.Reader, prepare func(*http.Request), out any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	if prepare != nil {
+		prepare(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Error responses are not always JSON, and successes may have no body; the
+	// status still tells what happened
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+	return resp.StatusCode, nil
+}
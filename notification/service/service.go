@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/security"
 	"github.com/aceextension/notification/domain"
 	"github.com/google/uuid"
 )
@@ -15,20 +17,51 @@ type SendRequest struct {
 	Recipient  string
 	TemplateID *uuid.UUID
 	Content    string // Used if TemplateID is nil
+	Subject    string // Email subject, used if TemplateID is nil
 	Variables  map[string]interface{}
 	Priority   domain.Priority
+	// Secrets fill {{secret.<name>}} placeholders in Content, e.g. an OTP code. They are
+	// sealed at rest and filled in only when the message is sent, so they never appear in
+	// the stored content, the queue or the communication history.
+	Secrets map[string]string
+
+	// Optional links to the customer and record this message is about
+	CustomerID    *uuid.UUID
+	ReferenceType *string
+	ReferenceID   *uuid.UUID
 }
 
 // NotificationService defines the interface for notification service
 type NotificationService interface {
 	// Send sends a notification (instant or queued based on priority)
 	Send(ctx context.Context, req SendRequest) (*domain.Notification, error)
-	// ProcessPending processes pending notifications (called by worker)
+	// ProcessPending processes pending notifications and fails over those that failed
+	// under a failover policy (called by worker)
 	ProcessPending(ctx context.Context) error
 	// GetTemplates retrieves templates for a tenant
 	GetTemplates(ctx context.Context, tenantID uuid.UUID) ([]*domain.Template, error)
 	// CreateTemplate creates a new template
 	CreateTemplate(ctx context.Context, template *domain.Template) error
-	// GetPendingNotifications returns pending notifications for inspection
-	GetPendingNotifications(ctx context.Context) ([]*domain.Notification, error)
+	// GetPendingNotifications returns a tenant's pending notifications for inspection
+	GetPendingNotifications(ctx context.Context, tenantID uuid.UUID) ([]*domain.Notification, error)
+	// GetCommunicationHistory returns the messages sent to a customer or address
+	GetCommunicationHistory(ctx context.Context, filter domain.HistoryFilter, page db.PageRequest) (db.Page[*domain.CommunicationRecord], error)
+	// SetFailoverPolicy sets the tenant's chain of channels for an event (a reference
+	// type such as CUSTOMER_OTP), replacing any earlier one
+	SetFailoverPolicy(ctx context.Context, tenantID uuid.UUID, event string, channels []domain.ChannelType, afterAttempts int) (*domain.FailoverPolicy, error)
+	GetFailoverPolicies(ctx context.Context, tenantID uuid.UUID) ([]*domain.FailoverPolicy, error)
+	DeleteFailoverPolicy(ctx context.Context, tenantID uuid.UUID, event string) error
+	// SetSender sets the platform's provider for a channel, used for tenants without their own
+	SetSender(channel domain.ChannelType, sender Sender)
+	// SetBrandingProvider registers the source of tenant branding template variables
+	SetBrandingProvider(provider BrandingProvider)
+	// SetCipher sets the cipher message secrets are sealed with. Without one they are kept
+	// in memory only, so a message that has to be retried or failed over cannot be.
+	SetCipher(cipher *security.Cipher)
+}
+
+// BrandingProvider supplies tenant white-label variables (brandName, brandLogoUrl,
+// documentFooter, emailFromName, ...) that every template can reference
+type BrandingProvider interface {
+	TemplateVariables(ctx context.Context, tenantID uuid.UUID) (map[string]interface{}, error)
 }
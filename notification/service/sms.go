@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// newSMSSender creates an SMS driver from tenant integration settings
+func newSMSSender(provider string, settings map[string]string) (Sender, error) {
+	switch provider {
+	case "sparrow":
+		if settings["token"] == "" {
+			return nil, errors.New("sparrow SMS needs a token")
+		}
+		return NewSparrowSMS(settings["token"], settings["sender_id"]), nil
+	case "twilio":
+		if settings["account_sid"] == "" || settings["token"] == "" || settings["sender_id"] == "" {
+			return nil, errors.New("twilio SMS needs an account SID, auth token and sender number")
+		}
+		return NewTwilioSMS(settings["account_sid"], settings["token"], settings["sender_id"]), nil
+	}
+	return nil, fmt.Errorf("unknown SMS provider %q", provider)
+}
+
+// SparrowSMS sends through Sparrow SMS, which takes Nepali numbers without the country code
+type SparrowSMS struct {
+	token    string
+	from     string // Sender identity registered with Sparrow
+	endpoint string
+	client   *http.Client
+}
+
+// NewSparrowSMS creates a Sparrow SMS driver
+func NewSparrowSMS(token, from string) *SparrowSMS {
+	return &SparrowSMS{
+		token:    token,
+		from:     from,
+		endpoint: "https://api.sparrowsms.com/v2/sms/",
+		client:   &http.Client{Timeout: senderTimeout},
+	}
+}
+
+func (s *SparrowSMS) Name() string {
+	return "sparrow"
+}
+
+// Send posts the message to Sparrow's send API
+func (s *SparrowSMS) Send(ctx context.Context, msg Message) error {
+	form := url.Values{}
+	form.Set("token", s.token)
+	form.Set("from", s.from)
+	form.Set("to", localNumber(msg.To))
+	form.Set("text", msg.Body)
+
+	var body struct {
+		ResponseCode int    `json:"response_code"`
+		Response     string `json:"response"`
+	}
+	status, err := postForm(ctx, s.client, s.endpoint, form, nil, &body)
+	if err != nil {
+		return fmt.Errorf("sparrow SMS: %w", err)
+	}
+	if status != http.StatusOK {
+		return httpFailure("sparrow SMS", status, body.Response)
+	}
+	if body.ResponseCode != http.StatusOK {
+		// Sparrow can also report a failure in the body of a 200 response
+		return httpFailure("sparrow SMS", body.ResponseCode, body.Response)
+	}
+	return nil
+}
+
+// TwilioSMS sends through Twilio's Messages API, which takes E.164 numbers
+type TwilioSMS struct {
+	accountSID string
+	authToken  string
+	from       string
+	endpoint   string
+	client     *http.Client
+}
+
+// NewTwilioSMS creates a Twilio driver sending from a Twilio number or messaging service
+func NewTwilioSMS(accountSID, authToken, from string) *TwilioSMS {
+	return &TwilioSMS{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		endpoint:   "https://api.twilio.com/2010-04-01/Accounts/" + url.PathEscape(accountSID) + "/Messages.json",
+		client:     &http.Client{Timeout: senderTimeout},
+	}
+}
+
+func (t *TwilioSMS) Name() string {
+	return "twilio"
+}
+
+// Send creates a message resource
+func (t *TwilioSMS) Send(ctx context.Context, msg Message) error {
+	form := url.Values{}
+	form.Set("To", internationalNumber(msg.To))
+	form.Set("Body", msg.Body)
+	if strings.HasPrefix(t.from, "MG") {
+		form.Set("MessagingServiceSid", t.from)
+	} else {
+		form.Set("From", t.from)
+	}
+
+	var body struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	status, err := postForm(ctx, t.client, t.endpoint, form, func(req *http.Request) {
+		req.SetBasicAuth(t.accountSID, t.authToken)
+	}, &body)
+	if err != nil {
+		return fmt.Errorf("twilio SMS: %w", err)
+	}
+	if status != http.StatusCreated && status != http.StatusOK {
+		return httpFailure("twilio SMS", status, fmt.Sprintf("%d %s", body.Code, body.Message))
+	}
+	return nil
+}
+
+// localNumber drops Nepal's +977 country code from a phone number
+func localNumber(phone string) string {
+	digits := digitsOnly(phone)
+	if len(digits) == 13 && strings.HasPrefix(digits, "977") {
+		return digits[3:]
+	}
+	return digits
+}
+
+// internationalNumber writes a phone number in E.164, taking a 10-digit number as Nepali
+func internationalNumber(phone string) string {
+	phone = strings.TrimSpace(phone)
+	if strings.HasPrefix(phone, "+") {
+		return "+" + digitsOnly(phone)
+	}
+	digits := digitsOnly(phone)
+	if len(digits) == 10 {
+		return "+977" + digits
+	}
+	return "+" + digits
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// Checklist steps registered by the modules that own the data completing them
+const (
+	StepFiscalYear    = "create_fiscal_year"
+	StepFirstProduct  = "add_first_product"
+	StepFirstCustomer = "add_first_customer"
+	StepFirstSupplier = "add_first_supplier"
+	StepFirstInvoice  = "issue_first_invoice"
+)
+
+// TourChecklist is the tour key the frontend uses for the setup checklist panel,
+// so a user can dismiss the checklist like any tour
+const TourChecklist = "checklist"
+
+var (
+	// ErrInvalidTourKey is returned for a tour key that is empty, too long or not lowercase
+	ErrInvalidTourKey = errors.New("invalid tour key: use 1-64 lowercase letters, digits, '.', '_' or '-'")
+	// ErrInvalidTourStatus is returned for a status other than completed or dismissed
+	ErrInvalidTourStatus = errors.New("invalid tour status: use completed or dismissed")
+)
+
+var tourKeyPattern = regexp.MustCompile(`^[a-z0-9._-]{1,64}$`)
+
+// Step is one item of a tenant's setup checklist
+type Step struct {
+	Key   string
+	Title string // Shown when the frontend has no text of its own for the key
+}
+
+// StepState is a checklist step and whether the tenant has done it. A step stays
+// completed once done, even if the data that completed it is later removed.
+type StepState struct {
+	Key         string     `json:"key"`
+	Title       string     `json:"title"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// Checklist is the tenant's setup checklist, in registration order
+type Checklist struct {
+	Steps     []StepState `json:"steps"`
+	Completed int         `json:"completed"`
+	Total     int         `json:"total"`
+	Done      bool        `json:"done"`
+}
+
+// TourStatus is how a user finished with a tour
+type TourStatus string
+
+const (
+	TourCompleted TourStatus = "completed" // Watched to the end
+	TourDismissed TourStatus = "dismissed" // Closed early; not shown again
+)
+
+// Valid reports whether the status is known
+func (s TourStatus) Valid() bool {
+	return s == TourCompleted || s == TourDismissed
+}
+
+// Tour is a user's flag for one product tour. Tours without a flag have not been
+// seen; their keys are chosen by the frontend.
+type Tour struct {
+	Key       string     `json:"key"`
+	Status    TourStatus `json:"status"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// ValidateTourKey checks a frontend tour key
+func ValidateTourKey(key string) error {
+	if !tourKeyPattern.MatchString(key) {
+		return ErrInvalidTourKey
+	}
+	return nil
+}
+
+// State is what the frontend needs to decide which checklists and tours to show a user
+type State struct {
+	Checklist Checklist `json:"checklist"`
+	Tours     []Tour    `json:"tours"`
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/onboarding/domain"
+	"github.com/aceextension/onboarding/service"
+	"github.com/labstack/echo/v4"
+)
+
+type OnboardingHandler struct {
+	service service.OnboardingService
+}
+
+func NewOnboardingHandler(service service.OnboardingService) *OnboardingHandler {
+	return &OnboardingHandler{service: service}
+}
+
+// TourRequest is the body for flagging a tour
+type TourRequest struct {
+	Status domain.TourStatus `json:"status"`
+}
+
+// GetState returns the tenant's setup checklist and the caller's tour flags
+// @Summary Get Onboarding State
+// @Description The setup checklist, derived from the tenant's data, and the tours the caller has completed or dismissed
+// @Tags Onboarding
+// @Produce json
+// @Success 200 {object} domain.State
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/onboarding [get]
+func (h *OnboardingHandler) GetState(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	state, err := h.service.State(c.Request().Context(), tenantID, userID)
+	if err != nil {
+		return onboardingError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, state)
+}
+
+// SetTour flags a tour for the caller
+// @Summary Complete or Dismiss Tour
+// @Description Mark a product tour completed or dismissed so it is not shown to the caller again
+// @Tags Onboarding
+// @Accept json
+// @Produce json
+// @Param key path string true "Tour key (lowercase letters, digits, '.', '_', '-')"
+// @Param request body TourRequest true "completed or dismissed"
+// @Success 200 {object} domain.Tour
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/onboarding/tours/{key} [put]
+func (h *OnboardingHandler) SetTour(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	var req TourRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tour, err := h.service.SetTour(c.Request().Context(), tenantID, userID, c.Param("key"), req.Status)
+	if err != nil {
+		return onboardingError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tour)
+}
+
+// ResetTour clears the caller's flag for a tour
+// @Summary Reset Tour
+// @Description Show a product tour to the caller again
+// @Tags Onboarding
+// @Param key path string true "Tour key"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/onboarding/tours/{key} [delete]
+func (h *OnboardingHandler) ResetTour(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	if err := h.service.ResetTour(c.Request().Context(), tenantID, userID, c.Param("key")); err != nil {
+		return onboardingError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func onboardingError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTourKey), errors.Is(err, domain.ErrInvalidTourStatus):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
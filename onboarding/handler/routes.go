@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(e *echo.Group, onboardingHandler *OnboardingHandler) {
+	onboardingGroup := e.Group("/onboarding")
+
+	// Checklist and the caller's tour flags
+	onboardingGroup.GET("", onboardingHandler.GetState)
+
+	// Tour flags
+	onboardingGroup.PUT("/tours/:key", onboardingHandler.SetTour)
+	onboardingGroup.DELETE("/tours/:key", onboardingHandler.ResetTour)
+}
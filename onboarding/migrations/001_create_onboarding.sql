@@ -0,0 +1,39 @@
+-- Onboarding Module: Setup Checklist and Product Tours
+-- Migration: 001_create_onboarding.sql
+-- Checklist steps are derived from each tenant's data; the first time a step is seen
+-- done it is recorded here so it stays checked. Tour flags are per user.
+
+CREATE TABLE IF NOT EXISTS onboarding_steps (
+    tenant_id UUID NOT NULL,
+    step_key VARCHAR(64) NOT NULL,                   -- add_first_product, create_fiscal_year, ...
+    completed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, step_key)
+);
+
+CREATE TABLE IF NOT EXISTS onboarding_tours (
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    tour_key VARCHAR(64) NOT NULL,                   -- Chosen by the frontend
+    status VARCHAR(20) NOT NULL,                     -- completed, dismissed
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, user_id, tour_key),
+    CONSTRAINT chk_onboarding_tours_status CHECK (status IN ('completed', 'dismissed'))
+);
+
+COMMENT ON TABLE onboarding_steps IS 'Setup checklist steps each tenant has completed, recorded the first time they are seen done';
+COMMENT ON TABLE onboarding_tours IS 'Product tours each user has completed or dismissed';
+
+ALTER TABLE onboarding_steps ENABLE ROW LEVEL SECURITY;
+ALTER TABLE onboarding_tours ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON onboarding_steps
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON onboarding_tours
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,18 @@
+package onboarding
+
+import (
+	"github.com/aceextension/onboarding/repository"
+	"github.com/aceextension/onboarding/service"
+)
+
+// Module-level service instances
+var (
+	ChecklistService service.OnboardingService
+)
+
+// Init initializes the onboarding module.
+// Modules owning setup data (fiscal years, catalog products, crm customers and suppliers,
+// sales invoices) register their checklist steps through ChecklistService.RegisterStep.
+func Init() {
+	ChecklistService = service.NewOnboardingService(repository.NewPostgresOnboardingRepository())
+}
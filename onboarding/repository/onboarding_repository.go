@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/onboarding/domain"
+	"github.com/google/uuid"
+)
+
+// OnboardingRepository defines the interface for onboarding state data access
+type OnboardingRepository interface {
+	// CompletedSteps returns when each recorded step was completed, by key
+	CompletedSteps(ctx context.Context, tenantID uuid.UUID) (map[string]time.Time, error)
+	// RecordSteps records steps as completed at; steps already recorded keep their time
+	RecordSteps(ctx context.Context, tenantID uuid.UUID, keys []string, at time.Time) error
+
+	// Tours returns a user's tour flags by key
+	Tours(ctx context.Context, tenantID, userID uuid.UUID) ([]domain.Tour, error)
+	// SaveTour sets a user's flag for a tour
+	SaveTour(ctx context.Context, tenantID, userID uuid.UUID, tour domain.Tour) error
+	// DeleteTour clears a user's flag so the tour shows again
+	DeleteTour(ctx context.Context, tenantID, userID uuid.UUID, key string) error
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/onboarding/domain"
+	"github.com/google/uuid"
+)
+
+// PostgresOnboardingRepository implements OnboardingRepository using PostgreSQL
+type PostgresOnboardingRepository struct{}
+
+// NewPostgresOnboardingRepository creates a new PostgreSQL onboarding repository
+func NewPostgresOnboardingRepository() *PostgresOnboardingRepository {
+	return &PostgresOnboardingRepository{}
+}
+
+// CompletedSteps retrieves the tenant's recorded steps
+func (r *PostgresOnboardingRepository) CompletedSteps(ctx context.Context, tenantID uuid.UUID) (map[string]time.Time, error) {
+	query := `SELECT step_key, completed_at FROM onboarding_steps WHERE tenant_id = $1`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get onboarding steps: %w", err)
+	}
+	defer rows.Close()
+
+	steps := make(map[string]time.Time)
+	for rows.Next() {
+		var key string
+		var completedAt time.Time
+		if err := rows.Scan(&key, &completedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan onboarding step: %w", err)
+		}
+		steps[key] = completedAt
+	}
+	return steps, rows.Err()
+}
+
+// RecordSteps inserts the steps not yet recorded
+func (r *PostgresOnboardingRepository) RecordSteps(ctx context.Context, tenantID uuid.UUID, keys []string, at time.Time) error {
+	query := `
+		INSERT INTO onboarding_steps (tenant_id, step_key, completed_at)
+		SELECT $1, key, $3 FROM UNNEST($2::text[]) AS key
+		ON CONFLICT (tenant_id, step_key) DO NOTHING
+	`
+	if _, err := db.MainPool.Exec(ctx, query, tenantID, keys, at); err != nil {
+		return fmt.Errorf("failed to record onboarding steps: %w", err)
+	}
+	return nil
+}
+
+// Tours retrieves a user's tour flags
+func (r *PostgresOnboardingRepository) Tours(ctx context.Context, tenantID, userID uuid.UUID) ([]domain.Tour, error) {
+	query := `
+		SELECT tour_key, status, updated_at FROM onboarding_tours
+		WHERE tenant_id = $1 AND user_id = $2
+		ORDER BY tour_key
+	`
+	rows, err := db.MainPool.Query(ctx, query, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tours: %w", err)
+	}
+	defer rows.Close()
+
+	tours := []domain.Tour{}
+	for rows.Next() {
+		var tour domain.Tour
+		if err := rows.Scan(&tour.Key, &tour.Status, &tour.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tour: %w", err)
+		}
+		tours = append(tours, tour)
+	}
+	return tours, rows.Err()
+}
+
+// SaveTour inserts or replaces a user's tour flag
+func (r *PostgresOnboardingRepository) SaveTour(ctx context.Context, tenantID, userID uuid.UUID, tour domain.Tour) error {
+	query := `
+		INSERT INTO onboarding_tours (tenant_id, user_id, tour_key, status, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, user_id, tour_key) DO UPDATE SET
+			status = EXCLUDED.status,
+			updated_at = EXCLUDED.updated_at
+	`
+	if _, err := db.MainPool.Exec(ctx, query, tenantID, userID, tour.Key, tour.Status, tour.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save tour: %w", err)
+	}
+	return nil
+}
+
+// DeleteTour removes a user's tour flag; removing a missing flag is not an error
+func (r *PostgresOnboardingRepository) DeleteTour(ctx context.Context, tenantID, userID uuid.UUID, key string) error {
+	query := `DELETE FROM onboarding_tours WHERE tenant_id = $1 AND user_id = $2 AND tour_key = $3`
+	if _, err := db.MainPool.Exec(ctx, query, tenantID, userID, key); err != nil {
+		return fmt.Errorf("failed to delete tour: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/onboarding/domain"
+	"github.com/aceextension/onboarding/repository"
+	"github.com/google/uuid"
+)
+
+// registeredStep is a checklist step and how to tell it is done
+type registeredStep struct {
+	step domain.Step
+	done StepDoneFunc
+}
+
+// onboardingService implements OnboardingService
+type onboardingService struct {
+	repo  repository.OnboardingRepository
+	mu    sync.RWMutex
+	steps []registeredStep
+}
+
+// NewOnboardingService creates a new onboarding service
+func NewOnboardingService(repo repository.OnboardingRepository) OnboardingService {
+	return &onboardingService{repo: repo}
+}
+
+// RegisterStep adds or replaces a checklist step
+func (s *onboardingService) RegisterStep(step domain.Step, done StepDoneFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.steps {
+		if s.steps[i].step.Key == step.Key {
+			s.steps[i] = registeredStep{step: step, done: done}
+			return
+		}
+	}
+	s.steps = append(s.steps, registeredStep{step: step, done: done})
+}
+
+// State builds the checklist, recording steps seen done for the first time
+func (s *onboardingService) State(ctx context.Context, tenantID, userID uuid.UUID) (*domain.State, error) {
+	s.mu.RLock()
+	steps := make([]registeredStep, len(s.steps))
+	copy(steps, s.steps)
+	s.mu.RUnlock()
+
+	recorded, err := s.repo.CompletedSteps(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only steps not yet recorded are checked against the tenant's data
+	now := time.Now()
+	var newlyDone []string
+	for _, rs := range steps {
+		if _, ok := recorded[rs.step.Key]; ok {
+			continue
+		}
+		done, err := rs.done(ctx, tenantID)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to check onboarding step %s for tenant %s: %v", rs.step.Key, tenantID, err))
+			continue
+		}
+		if done {
+			newlyDone = append(newlyDone, rs.step.Key)
+			recorded[rs.step.Key] = now
+		}
+	}
+	if len(newlyDone) > 0 {
+		if err := s.repo.RecordSteps(ctx, tenantID, newlyDone, now); err != nil {
+			// Still shown as done; they are checked and recorded again next time
+			logger.Log.Error(fmt.Sprintf("Failed to record onboarding steps for tenant %s: %v", tenantID, err))
+		}
+	}
+
+	checklist := domain.Checklist{
+		Steps: make([]domain.StepState, 0, len(steps)),
+		Total: len(steps),
+	}
+	for _, rs := range steps {
+		state := domain.StepState{Key: rs.step.Key, Title: rs.step.Title}
+		if completedAt, ok := recorded[rs.step.Key]; ok {
+			state.Completed = true
+			state.CompletedAt = &completedAt
+			checklist.Completed++
+		}
+		checklist.Steps = append(checklist.Steps, state)
+	}
+	checklist.Done = checklist.Completed == checklist.Total
+
+	tours, err := s.repo.Tours(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.State{Checklist: checklist, Tours: tours}, nil
+}
+
+// SetTour saves the user's tour flag
+func (s *onboardingService) SetTour(ctx context.Context, tenantID, userID uuid.UUID, key string, status domain.TourStatus) (*domain.Tour, error) {
+	if err := domain.ValidateTourKey(key); err != nil {
+		return nil, err
+	}
+	if !status.Valid() {
+		return nil, domain.ErrInvalidTourStatus
+	}
+
+	tour := domain.Tour{Key: key, Status: status, UpdatedAt: time.Now()}
+	if err := s.repo.SaveTour(ctx, tenantID, userID, tour); err != nil {
+		return nil, err
+	}
+	return &tour, nil
+}
+
+// ResetTour deletes the user's tour flag
+func (s *onboardingService) ResetTour(ctx context.Context, tenantID, userID uuid.UUID, key string) error {
+	if err := domain.ValidateTourKey(key); err != nil {
+		return err
+	}
+	return s.repo.DeleteTour(ctx, tenantID, userID, key)
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"github.com/aceextension/onboarding/domain"
+	"github.com/google/uuid"
+)
+
+// StepDoneFunc reports whether the tenant's data shows a checklist step is done
+type StepDoneFunc func(ctx context.Context, tenantID uuid.UUID) (bool, error)
+
+// DoneByCount adapts a tenant record count, such as a repository's Count, to a StepDoneFunc
+// that is done once the tenant has any records
+func DoneByCount(count func(ctx context.Context, tenantID uuid.UUID) (int64, error)) StepDoneFunc {
+	return func(ctx context.Context, tenantID uuid.UUID) (bool, error) {
+		n, err := count(ctx, tenantID)
+		return n > 0, err
+	}
+}
+
+// OnboardingService defines the interface for setup checklists and product tour state
+type OnboardingService interface {
+	// RegisterStep adds a checklist step owned by another module. Steps are listed in
+	// registration order; registering a key again replaces its check in place.
+	RegisterStep(step domain.Step, done StepDoneFunc)
+
+	// State returns the tenant's checklist and the user's tour flags. Steps are checked
+	// against the tenant's data; a step whose check fails is shown as not done.
+	State(ctx context.Context, tenantID, userID uuid.UUID) (*domain.State, error)
+	// SetTour marks a tour completed or dismissed for the user
+	SetTour(ctx context.Context, tenantID, userID uuid.UUID, key string, status domain.TourStatus) (*domain.Tour, error)
+	// ResetTour clears the user's flag so the tour shows again
+	ResetTour(ctx context.Context, tenantID, userID uuid.UUID, key string) error
+}
@@ -0,0 +1,316 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPaymentNotFound is returned when a payment does not exist for the tenant
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrInvalidPayment is returned for a bad direction, method, amount or allocation
+	ErrInvalidPayment = errors.New("invalid payment")
+	// ErrPaymentVoid is returned when allocating or voiding a payment that is already void
+	ErrPaymentVoid = errors.New("payment is void")
+	// ErrPartyNotFound is returned when the customer or supplier does not exist for the tenant
+	ErrPartyNotFound = errors.New("customer or supplier not found")
+	// ErrUnknownDocumentType is returned for document types no module has registered
+	ErrUnknownDocumentType = errors.New("payments are not supported for this document type")
+	// ErrDocumentNotFound is returned when the paid document does not exist for the tenant
+	ErrDocumentNotFound = errors.New("document not found")
+	// ErrDocumentNotPayable is returned for a void document, one paid in the other
+	// direction, or one belonging to another party
+	ErrDocumentNotPayable = errors.New("document cannot be paid by this payment")
+	// ErrOverAllocation is returned when an allocation is more than the document's
+	// outstanding amount or the payment's unallocated amount
+	ErrOverAllocation = errors.New("allocation exceeds the amount outstanding")
+)
+
+// Direction is which way money moves
+type Direction string
+
+const (
+	DirectionReceipt      Direction = "receipt"      // Received from a customer
+	DirectionDisbursement Direction = "disbursement" // Paid to a supplier
+)
+
+// Valid reports whether the direction is known
+func (d Direction) Valid() bool {
+	return d == DirectionReceipt || d == DirectionDisbursement
+}
+
+// PartyType is who the other side of a payment is
+func (d Direction) PartyType() string {
+	if d == DirectionDisbursement {
+		return PartySupplier
+	}
+	return PartyCustomer
+}
+
+// Parties payments are made with
+const (
+	PartyCustomer = "customer"
+	PartySupplier = "supplier"
+)
+
+// Method is how a payment was made
+type Method string
+
+const (
+	MethodCash   Method = "cash"
+	MethodBank   Method = "bank"   // Cheque, deposit or transfer
+	MethodEsewa  Method = "esewa"  // eSewa wallet
+	MethodKhalti Method = "khalti" // Khalti wallet
+)
+
+// Valid reports whether the method is known
+func (m Method) Valid() bool {
+	switch m {
+	case MethodCash, MethodBank, MethodEsewa, MethodKhalti:
+		return true
+	}
+	return false
+}
+
+// Wallet reports whether the method is a digital wallet
+func (m Method) Wallet() bool {
+	return m == MethodEsewa || m == MethodKhalti
+}
+
+// Status is where a payment is in its life
+type Status string
+
+const (
+	StatusPosted Status = "posted"
+	StatusVoid   Status = "void" // Reversed; its allocations no longer count
+)
+
+// Document types payments are allocated to; they match the accounting reference
+// types the documents are posted under
+const (
+	DocumentInvoice      = "INVOICE"       // Sales invoice, paid by customers
+	DocumentPurchaseBill = "PURCHASE_BILL" // Confirmed supplier bill, paid to suppliers
+)
+
+// MaxPageSize bounds one page of a payment listing
+const MaxPageSize = 100
+
+// Payment is money received from a customer or paid to a supplier. It is allocated
+// to the party's invoices or bills; what is not allocated stays on account as an
+// advance and can be allocated later.
+type Payment struct {
+	ID          uuid.UUID    `json:"id"`
+	TenantID    uuid.UUID    `json:"tenantId"`
+	Direction   Direction    `json:"direction"`
+	PartyType   string       `json:"partyType"`
+	PartyID     uuid.UUID    `json:"partyId"` // The customer or supplier
+	PartyName   string       `json:"partyName"`
+	Method      Method       `json:"method"`
+	Amount      float64      `json:"amount"`
+	Allocated   float64      `json:"allocated"`
+	PaymentDate time.Time    `json:"paymentDate"`
+	Reference   *string      `json:"reference,omitempty"` // Cheque number or bank or wallet transaction ID
+	Note        *string      `json:"note,omitempty"`
+	Status      Status       `json:"status"`
+	VoidReason  *string      `json:"voidReason,omitempty"`
+	VoidedAt    *time.Time   `json:"voidedAt,omitempty"`
+	VoidedBy    *uuid.UUID   `json:"voidedBy,omitempty"`
+	CreatedBy   *uuid.UUID   `json:"createdBy,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+	Allocations []Allocation `json:"allocations"`
+}
+
+// Allocation is the part of a payment that settles one document
+type Allocation struct {
+	ID             uuid.UUID `json:"id"`
+	PaymentID      uuid.UUID `json:"paymentId"`
+	DocumentType   string    `json:"documentType"`
+	DocumentID     uuid.UUID `json:"documentId"`
+	DocumentNumber string    `json:"documentNumber"`
+	Amount         float64   `json:"amount"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// NewPayment creates a posted payment; allocations are added before it is saved
+func NewPayment(tenantID uuid.UUID, direction Direction, method Method, amount float64, paymentDate time.Time) *Payment {
+	now := time.Now()
+	return &Payment{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		Direction:   direction,
+		PartyType:   direction.PartyType(),
+		Method:      method,
+		Amount:      roundMoney(amount),
+		PaymentDate: paymentDate,
+		Status:      StatusPosted,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Allocations: []Allocation{},
+	}
+}
+
+// Validate checks the direction, method and amount
+func (p *Payment) Validate() error {
+	if !p.Direction.Valid() {
+		return fmt.Errorf("%w: unknown direction %q", ErrInvalidPayment, p.Direction)
+	}
+	if !p.Method.Valid() {
+		return fmt.Errorf("%w: unknown method %q", ErrInvalidPayment, p.Method)
+	}
+	if p.Amount <= 0 {
+		return fmt.Errorf("%w: amount must be more than zero", ErrInvalidPayment)
+	}
+	if p.PartyID == uuid.Nil {
+		return fmt.Errorf("%w: a payment needs a %s", ErrInvalidPayment, p.PartyType)
+	}
+	if p.Method != MethodCash && (p.Reference == nil || strings.TrimSpace(*p.Reference) == "") {
+		return fmt.Errorf("%w: a %s payment needs its transaction reference", ErrInvalidPayment, p.Method)
+	}
+	return nil
+}
+
+// Unallocated is what is left of the payment on account
+func (p *Payment) Unallocated() float64 {
+	return math.Max(roundMoney(p.Amount-p.Allocated), 0)
+}
+
+// Allocate settles amount of the document with the payment. The amount may not be
+// more than the document's outstanding amount or what is left of the payment.
+func (p *Payment) Allocate(doc *Document, amount float64) error {
+	if p.Status == StatusVoid {
+		return ErrPaymentVoid
+	}
+	amount = roundMoney(amount)
+	if amount <= 0 {
+		return fmt.Errorf("%w: allocation to %s must be more than zero", ErrInvalidPayment, doc.Number)
+	}
+	if err := doc.PayableBy(p); err != nil {
+		return err
+	}
+	if amount > doc.Outstanding() {
+		return fmt.Errorf("%w: %s has %.2f outstanding", ErrOverAllocation, doc.Number, doc.Outstanding())
+	}
+	if amount > p.Unallocated() {
+		return fmt.Errorf("%w: the payment has %.2f left to allocate", ErrOverAllocation, p.Unallocated())
+	}
+
+	allocation := Allocation{
+		ID:             uuid.New(),
+		PaymentID:      p.ID,
+		DocumentType:   doc.Type,
+		DocumentID:     doc.ID,
+		DocumentNumber: doc.Number,
+		Amount:         amount,
+		CreatedAt:      time.Now(),
+	}
+	p.Allocations = append(p.Allocations, allocation)
+	p.Allocated = roundMoney(p.Allocated + amount)
+	doc.Paid = roundMoney(doc.Paid + amount)
+	p.UpdatedAt = allocation.CreatedAt
+	return nil
+}
+
+// Void reverses the payment; its allocations stop counting towards the documents
+func (p *Payment) Void(reason string, userID *uuid.UUID) error {
+	if p.Status == StatusVoid {
+		return ErrPaymentVoid
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("%w: a void needs a reason", ErrInvalidPayment)
+	}
+
+	now := time.Now()
+	p.Status = StatusVoid
+	p.VoidReason = &reason
+	p.VoidedAt = &now
+	p.VoidedBy = userID
+	p.UpdatedAt = now
+	return nil
+}
+
+// Document is an invoice or bill as payments see it, read from the module that owns it
+type Document struct {
+	Type      string     `json:"type"`
+	ID        uuid.UUID  `json:"id"`
+	Number    string     `json:"number"`
+	Direction Direction  `json:"direction"`
+	PartyID   *uuid.UUID `json:"partyId,omitempty"`
+	PartyName string     `json:"partyName"`
+	Date      time.Time  `json:"date"`
+	Total     float64    `json:"total"`
+	Settled   float64    `json:"settled"` // Paid outside payments, such as cash sales paid at the counter
+	Paid      float64    `json:"paid"`    // Allocated from posted payments
+	Void      bool       `json:"void"`
+}
+
+// Outstanding is what is still to be paid on the document
+func (d *Document) Outstanding() float64 {
+	if d.Void {
+		return 0
+	}
+	return math.Max(roundMoney(d.Total-d.Settled-d.Paid), 0)
+}
+
+// PaymentStatus is unpaid, partial or paid, as the owning modules show it
+func (d *Document) PaymentStatus() string {
+	switch paid := d.AmountPaid(); {
+	case paid >= d.Total:
+		return "paid"
+	case paid > 0:
+		return "partial"
+	default:
+		return "unpaid"
+	}
+}
+
+// AmountPaid is the total paid on the document, inside and outside payments
+func (d *Document) AmountPaid() float64 {
+	return roundMoney(d.Settled + d.Paid)
+}
+
+// PayableBy checks the payment may settle the document
+func (d *Document) PayableBy(p *Payment) error {
+	if d.Void {
+		return fmt.Errorf("%w: %s is void", ErrDocumentNotPayable, d.Number)
+	}
+	if d.Direction != p.Direction {
+		return fmt.Errorf("%w: %s is not paid by a %s", ErrDocumentNotPayable, d.Number, p.Direction)
+	}
+	if d.PartyID == nil || *d.PartyID != p.PartyID {
+		return fmt.Errorf("%w: %s belongs to another %s", ErrDocumentNotPayable, d.Number, p.PartyType)
+	}
+	return nil
+}
+
+// PaymentFilter narrows a payment listing
+type PaymentFilter struct {
+	Direction *Direction
+	PartyID   *uuid.UUID
+	Method    *Method
+	Status    *Status
+	From      *time.Time // Payment dates from this day
+	To        *time.Time // Payment dates up to and including this day
+	Limit     int
+	Offset    int
+}
+
+// DocumentPayment is an allocation to a document with the payment it came from
+type DocumentPayment struct {
+	PaymentID   uuid.UUID `json:"paymentId"`
+	PaymentDate time.Time `json:"paymentDate"`
+	Method      Method    `json:"method"`
+	Reference   *string   `json:"reference,omitempty"`
+	Amount      float64   `json:"amount"`
+	Status      Status    `json:"status"`
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,23 @@
+module github.com/aceextension/payments
+
+go 1.24.0
+
+require (
+	github.com/aceextension/accounting v0.0.0
+	github.com/aceextension/audit v0.0.0
+	github.com/aceextension/core v0.0.0
+	github.com/google/uuid v1.6.0
+	github.com/jackc/pgx/v5 v5.8.0
+	github.com/labstack/echo/v4 v4.15.0
+)
+
+replace (
+	github.com/aceextension/accounting => ../accounting
+	github.com/aceextension/audit => ../audit
+	github.com/aceextension/core => ../core
+	github.com/aceextension/files => ../files
+	github.com/aceextension/fiscal => ../fiscal
+	github.com/aceextension/identity => ../identity
+	github.com/aceextension/notification => ../notification
+	github.com/aceextension/onboarding => ../onboarding
+)
@@ -0,0 +1,420 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/payments/domain"
+	"github.com/aceextension/payments/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type PaymentHandler struct {
+	service service.PaymentService
+}
+
+func NewPaymentHandler(service service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{service: service}
+}
+
+// PaymentRequest records money received from a customer or paid to a supplier
+type PaymentRequest struct {
+	Direction    domain.Direction    `json:"direction"` // receipt or disbursement
+	PartyID      uuid.UUID           `json:"partyId"`   // Customer for receipts, supplier for disbursements
+	Method       domain.Method       `json:"method"`    // cash, bank, esewa or khalti
+	Amount       float64             `json:"amount"`
+	PaymentDate  string              `json:"paymentDate,omitempty"` // YYYY-MM-DD; defaults to today
+	Reference    *string             `json:"reference,omitempty"`   // Required except for cash
+	Note         *string             `json:"note,omitempty"`
+	Allocations  []AllocationRequest `json:"allocations,omitempty"`
+	AutoAllocate bool                `json:"autoAllocate,omitempty"` // Without allocations, settle the oldest open documents first
+}
+
+// AllocationRequest settles amount of a document
+type AllocationRequest struct {
+	DocumentType string    `json:"documentType"` // INVOICE or PURCHASE_BILL
+	DocumentID   uuid.UUID `json:"documentId"`
+	Amount       float64   `json:"amount"`
+}
+
+// AllocateRequest allocates what is left of a payment; without allocations the
+// party's oldest open documents are settled first
+type AllocateRequest struct {
+	Allocations []AllocationRequest `json:"allocations,omitempty"`
+}
+
+// VoidPaymentRequest gives the reason a payment is reversed
+type VoidPaymentRequest struct {
+	Reason string `json:"reason"`
+}
+
+// DocumentResponse is a document with its outstanding amount
+type DocumentResponse struct {
+	*domain.Document
+	AmountPaid    float64 `json:"amountPaid"`
+	Outstanding   float64 `json:"outstanding"`
+	PaymentStatus string  `json:"paymentStatus"` // unpaid, partial or paid
+}
+
+// DocumentStatusResponse is what has been paid on a document and by which payments
+type DocumentStatusResponse struct {
+	DocumentResponse
+	Payments []*domain.DocumentPayment `json:"payments"`
+}
+
+func toDocumentResponse(doc *domain.Document) DocumentResponse {
+	return DocumentResponse{
+		Document:      doc,
+		AmountPaid:    doc.AmountPaid(),
+		Outstanding:   doc.Outstanding(),
+		PaymentStatus: doc.PaymentStatus(),
+	}
+}
+
+func toAllocationInputs(requests []AllocationRequest) []service.AllocationInput {
+	inputs := make([]service.AllocationInput, 0, len(requests))
+	for _, req := range requests {
+		inputs = append(inputs, service.AllocationInput{DocumentType: req.DocumentType, DocumentID: req.DocumentID, Amount: req.Amount})
+	}
+	return inputs
+}
+
+// RecordPayment records a receipt or supplier payment
+// @Summary Record Payment
+// @Description Record money received from a customer or paid to a supplier, allocated to their invoices or bills; what is not allocated stays on account. Posted to the journal, and receipts to the customer's khata.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param request body PaymentRequest true "Payment"
+// @Success 201 {object} domain.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/payments [post]
+func (h *PaymentHandler) RecordPayment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	var req PaymentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	paymentDate := time.Now()
+	if req.PaymentDate != "" {
+		date, err := time.ParseInLocation("2006-01-02", req.PaymentDate, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid payment date, expected YYYY-MM-DD"})
+		}
+		paymentDate = date
+	}
+
+	payment, err := h.service.Record(c.Request().Context(), service.PaymentInput{
+		TenantID:     tenantID,
+		Direction:    req.Direction,
+		PartyID:      req.PartyID,
+		Method:       req.Method,
+		Amount:       req.Amount,
+		PaymentDate:  paymentDate,
+		Reference:    req.Reference,
+		Note:         req.Note,
+		Allocations:  toAllocationInputs(req.Allocations),
+		AutoAllocate: req.AutoAllocate,
+		CreatedBy:    optionalUserID(c),
+	})
+	if err != nil {
+		return paymentError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, payment)
+}
+
+// ListPayments lists payments
+// @Summary List Payments
+// @Description Payments, newest first, optionally by direction, party, method, status or date range
+// @Tags Payments
+// @Produce json
+// @Param direction query string false "receipt or disbursement"
+// @Param partyId query string false "Customer or supplier ID"
+// @Param method query string false "cash, bank, esewa or khalti"
+// @Param status query string false "posted or void"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} domain.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/payments [get]
+func (h *PaymentHandler) ListPayments(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	filter := domain.PaymentFilter{}
+	filter.Limit, _ = strconv.Atoi(c.QueryParam("limit"))
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Limit > domain.MaxPageSize {
+		filter.Limit = domain.MaxPageSize
+	}
+	filter.Offset, _ = strconv.Atoi(c.QueryParam("offset"))
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	if value := c.QueryParam("direction"); value != "" {
+		direction := domain.Direction(value)
+		if !direction.Valid() {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid direction"})
+		}
+		filter.Direction = &direction
+	}
+	if value := c.QueryParam("partyId"); value != "" {
+		partyID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid party ID"})
+		}
+		filter.PartyID = &partyID
+	}
+	if value := c.QueryParam("method"); value != "" {
+		method := domain.Method(value)
+		if !method.Valid() {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid method"})
+		}
+		filter.Method = &method
+	}
+	if value := c.QueryParam("status"); value != "" {
+		status := domain.Status(value)
+		if status != domain.StatusPosted && status != domain.StatusVoid {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+		}
+		filter.Status = &status
+	}
+	if value := c.QueryParam("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from date, expected YYYY-MM-DD"})
+		}
+		filter.From = &from
+	}
+	if value := c.QueryParam("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to date, expected YYYY-MM-DD"})
+		}
+		filter.To = &to
+	}
+
+	payments, err := h.service.List(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return paymentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, payments)
+}
+
+// GetPayment returns a payment with its allocations
+// @Summary Get Payment
+// @Description A payment with the documents it was allocated to
+// @Tags Payments
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} domain.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/payments/{id} [get]
+func (h *PaymentHandler) GetPayment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid payment ID"})
+	}
+
+	payment, err := h.service.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return paymentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, payment)
+}
+
+// AllocatePayment allocates what is left of a payment
+// @Summary Allocate Payment
+// @Description Settle invoices or bills with the unallocated part of a payment, such as an advance; without allocations the party's oldest open documents are settled first
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param request body AllocateRequest false "Allocations"
+// @Success 200 {object} domain.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/payments/{id}/allocations [post]
+func (h *PaymentHandler) AllocatePayment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid payment ID"})
+	}
+
+	var req AllocateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	payment, err := h.service.Allocate(c.Request().Context(), tenantID, id, toAllocationInputs(req.Allocations), optionalUserID(c))
+	if err != nil {
+		return paymentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, payment)
+}
+
+// VoidPayment reverses a payment
+// @Summary Void Payment
+// @Description Reverse a payment with a reason; the documents it paid are due again and its journal entry is reversed
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param request body VoidPaymentRequest true "Reason"
+// @Success 200 {object} domain.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/payments/{id}/void [post]
+func (h *PaymentHandler) VoidPayment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid payment ID"})
+	}
+
+	var req VoidPaymentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	payment, err := h.service.Void(c.Request().Context(), tenantID, id, req.Reason, optionalUserID(c))
+	if err != nil {
+		return paymentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, payment)
+}
+
+// ListOpenDocuments lists a party's documents still due
+// @Summary List Open Documents
+// @Description A customer's invoices or a supplier's bills with an amount outstanding, oldest first, in the order automatic allocation settles them
+// @Tags Payments
+// @Produce json
+// @Param direction query string true "receipt or disbursement"
+// @Param partyId query string true "Customer or supplier ID"
+// @Success 200 {array} DocumentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/payments/open-documents [get]
+func (h *PaymentHandler) ListOpenDocuments(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	partyID, err := uuid.Parse(c.QueryParam("partyId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid party ID"})
+	}
+
+	docs, err := h.service.OpenDocuments(c.Request().Context(), tenantID, domain.Direction(c.QueryParam("direction")), partyID)
+	if err != nil {
+		return paymentError(c, err)
+	}
+
+	response := make([]DocumentResponse, 0, len(docs))
+	for _, doc := range docs {
+		response = append(response, toDocumentResponse(doc))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetDocumentStatus returns what has been paid on a document
+// @Summary Get Document Payments
+// @Description An invoice's or bill's amount paid, outstanding amount and payment status, with the payments allocated to it
+// @Tags Payments
+// @Produce json
+// @Param type path string true "Document type (INVOICE, PURCHASE_BILL)"
+// @Param id path string true "Document ID"
+// @Success 200 {object} DocumentStatusResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/payments/documents/{type}/{id} [get]
+func (h *PaymentHandler) GetDocumentStatus(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	status, err := h.service.DocumentStatus(c.Request().Context(), tenantID, c.Param("type"), id)
+	if err != nil {
+		return paymentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, DocumentStatusResponse{
+		DocumentResponse: toDocumentResponse(status.Document),
+		Payments:         status.Payments,
+	})
+}
+
+// optionalUserID returns the calling user, if known
+func optionalUserID(c echo.Context) *uuid.UUID {
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		return &userID
+	}
+	return nil
+}
+
+// paymentError maps payment errors to HTTP responses
+func paymentError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrPaymentNotFound), errors.Is(err, domain.ErrDocumentNotFound), errors.Is(err, domain.ErrPartyNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrPaymentVoid), errors.Is(err, domain.ErrOverAllocation), errors.Is(err, domain.ErrDocumentNotPayable):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidPayment), errors.Is(err, domain.ErrUnknownDocumentType):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(e *echo.Group, paymentHandler *PaymentHandler) {
+	paymentsGroup := e.Group("/payments")
+
+	paymentsGroup.POST("", paymentHandler.RecordPayment)
+	paymentsGroup.GET("", paymentHandler.ListPayments)
+
+	// What a party owes and what was paid on a document
+	paymentsGroup.GET("/open-documents", paymentHandler.ListOpenDocuments)
+	paymentsGroup.GET("/documents/:type/:id", paymentHandler.GetDocumentStatus)
+
+	paymentsGroup.GET("/:id", paymentHandler.GetPayment)
+	paymentsGroup.POST("/:id/allocations", paymentHandler.AllocatePayment)
+	paymentsGroup.POST("/:id/void", paymentHandler.VoidPayment)
+}
@@ -0,0 +1,106 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/accounting"
+	accountingDomain "github.com/aceextension/accounting/domain"
+	"github.com/aceextension/payments/domain"
+	"github.com/google/uuid"
+)
+
+// accountingJournal posts payments with accounting's automatic posting
+type accountingJournal struct{}
+
+// PostPayment books the payment on the tenant's posting accounts
+func (accountingJournal) PostPayment(ctx context.Context, payment *domain.Payment) error {
+	_, err := accounting.Service.PostFromReference(ctx, payment.TenantID, accountingDomain.ReferencePayment, payment.ID)
+	return err
+}
+
+// ReverseVoided books the payment's entry the other way round. A payment that was
+// never posted has nothing to reverse.
+func (accountingJournal) ReverseVoided(ctx context.Context, payment *domain.Payment) error {
+	_, err := accounting.Service.PostFromReference(ctx, payment.TenantID, accountingDomain.ReferencePaymentVoid, payment.ID)
+	if errors.Is(err, accountingDomain.ErrReferenceNotPosted) {
+		return nil
+	}
+	return err
+}
+
+// methodRoles is the posting role money of each method is held in
+var methodRoles = map[domain.Method]accountingDomain.PostingRole{
+	domain.MethodCash:   accountingDomain.RoleCash,
+	domain.MethodBank:   accountingDomain.RoleBank,
+	domain.MethodEsewa:  accountingDomain.RoleWallet,
+	domain.MethodKhalti: accountingDomain.RoleWallet,
+}
+
+// postPayment reads a posted payment for posting: a receipt brings money in against
+// what the customer owes, a supplier payment takes it out against what is owed to them.
+// Unallocated amounts are booked the same way and show as advances on the party's account.
+func postPayment(ctx context.Context, tenantID, id uuid.UUID) (*accountingDomain.PostingDocument, error) {
+	payment, err := Service.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != domain.StatusPosted {
+		return nil, fmt.Errorf("%w: payment is %s", accountingDomain.ErrReferenceNotPostable, payment.Status)
+	}
+
+	doc := &accountingDomain.PostingDocument{
+		TenantID:    tenantID,
+		Date:        payment.PaymentDate,
+		Description: paymentDescription(payment),
+		CreatedBy:   payment.CreatedBy,
+	}
+	money := methodRoles[payment.Method]
+	if payment.Direction == domain.DirectionReceipt {
+		doc.Debit(money, payment.Amount, string(payment.Method))
+		doc.Credit(accountingDomain.RoleReceivable, payment.Amount, payment.PartyName)
+	} else {
+		doc.Debit(accountingDomain.RolePayable, payment.Amount, payment.PartyName)
+		doc.Credit(money, payment.Amount, string(payment.Method))
+	}
+	return doc, nil
+}
+
+// postPaymentVoid reverses a voided payment's entry on the day it was voided
+func postPaymentVoid(ctx context.Context, tenantID, id uuid.UUID) (*accountingDomain.PostingDocument, error) {
+	payment, err := Service.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != domain.StatusVoid || payment.VoidedAt == nil {
+		return nil, fmt.Errorf("%w: payment is not void", accountingDomain.ErrReferenceNotPostable)
+	}
+
+	year, month, day := payment.VoidedAt.Date()
+	description := "Void of " + paymentDescription(payment)
+	if payment.VoidReason != nil {
+		description += ": " + *payment.VoidReason
+	}
+	return &accountingDomain.PostingDocument{
+		TenantID:    tenantID,
+		Date:        time.Date(year, month, day, 0, 0, 0, 0, payment.VoidedAt.Location()),
+		Description: description,
+		CreatedBy:   payment.VoidedBy,
+		Reverses:    accountingDomain.ReferencePayment,
+	}, nil
+}
+
+// paymentDescription names the payment in journal entries
+func paymentDescription(payment *domain.Payment) string {
+	description := "Receipt from " + payment.PartyName
+	if payment.Direction == domain.DirectionDisbursement {
+		description = "Payment to " + payment.PartyName
+	}
+	description += " by " + string(payment.Method)
+	if payment.Reference != nil {
+		description += " (" + *payment.Reference + ")"
+	}
+	return description
+}
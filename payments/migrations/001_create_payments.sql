@@ -0,0 +1,76 @@
+-- ============================================================================
+-- PAYMENTS
+-- Money received from customers and paid to suppliers, allocated to their
+-- invoices and bills. What is not allocated stays on account as an advance.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    direction VARCHAR(20) NOT NULL, -- receipt, disbursement
+    party_type VARCHAR(20) NOT NULL, -- customer, supplier
+    party_id UUID NOT NULL,
+    party_name VARCHAR(255) NOT NULL, -- copied at payment time
+    method VARCHAR(20) NOT NULL, -- cash, bank, esewa, khalti
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    allocated DECIMAL(15, 2) NOT NULL DEFAULT 0, -- sum of the allocations
+    payment_date DATE NOT NULL,
+    reference VARCHAR(100), -- cheque number or bank or wallet transaction ID
+    note TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'posted', -- posted, void
+    void_reason TEXT,
+    voided_at TIMESTAMPTZ,
+    voided_by UUID,
+    created_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT fk_payment_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT chk_payment_allocated CHECK (allocated >= 0 AND allocated <= amount)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_date
+    ON payments(tenant_id, payment_date DESC);
+
+-- A party's payments, and their advances still to allocate
+CREATE INDEX IF NOT EXISTS idx_payments_party
+    ON payments(tenant_id, party_type, party_id, payment_date);
+
+CREATE TABLE IF NOT EXISTS payment_allocations (
+    id UUID PRIMARY KEY,
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    document_type VARCHAR(50) NOT NULL, -- INVOICE, PURCHASE_BILL
+    document_id UUID NOT NULL,
+    document_number VARCHAR(100) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_allocations_payment
+    ON payment_allocations(payment_id);
+
+-- What has been paid on a document
+CREATE INDEX IF NOT EXISTS idx_payment_allocations_document
+    ON payment_allocations(tenant_id, document_type, document_id);
+
+-- ============================================================================
+-- ROW LEVEL SECURITY
+-- ============================================================================
+
+ALTER TABLE payments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE payment_allocations ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON payments;
+CREATE POLICY tenant_isolation ON payments
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+DROP POLICY IF EXISTS tenant_isolation ON payment_allocations;
+CREATE POLICY tenant_isolation ON payment_allocations
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,28 @@
+package payments
+
+import (
+	"github.com/aceextension/accounting"
+	accountingDomain "github.com/aceextension/accounting/domain"
+	"github.com/aceextension/payments/repository"
+	"github.com/aceextension/payments/service"
+)
+
+// Module-level service instances
+var (
+	Service service.PaymentService
+)
+
+// Init initializes the payments module. Call accounting.Init first so payments are
+// posted to the journal. Modules owning invoices and bills (sales, crm) register
+// their document types through Service.RegisterDocumentType; crm also sets the
+// party names and the customers' khata.
+func Init() {
+	Service = service.NewPaymentService(repository.NewPostgresPaymentRepository())
+
+	// Receipts and supplier payments are posted against the receivable and payable
+	if accounting.Service != nil {
+		accounting.Service.RegisterPostingSource(accountingDomain.ReferencePayment, postPayment)
+		accounting.Service.RegisterPostingSource(accountingDomain.ReferencePaymentVoid, postPaymentVoid)
+		Service.SetJournal(accountingJournal{})
+	}
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/payments/domain"
+	"github.com/google/uuid"
+)
+
+// PaymentRepository defines the interface for payment data access.
+//
+// limits caps, per document ID, what posted payments may allocate to the document
+// in total. Allocations are checked against it with the document locked, so two
+// payments saved at once cannot pay a document twice; ErrOverAllocation when one would.
+type PaymentRepository interface {
+	// Create saves a payment with its allocations
+	Create(ctx context.Context, payment *domain.Payment, limits map[uuid.UUID]float64) error
+	// Get retrieves a payment with its allocations
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Payment, error)
+	// List retrieves payments (without allocations), newest first
+	List(ctx context.Context, tenantID uuid.UUID, filter domain.PaymentFilter) ([]*domain.Payment, error)
+	// AddAllocations saves allocations added to a posted payment; ErrPaymentVoid if
+	// it was voided meanwhile
+	AddAllocations(ctx context.Context, payment *domain.Payment, allocations []domain.Allocation, limits map[uuid.UUID]float64) error
+	// SaveVoid saves a void; ErrPaymentVoid if it was voided concurrently
+	SaveVoid(ctx context.Context, payment *domain.Payment) error
+
+	// PaidByDocuments sums what posted payments allocated to each of the documents
+	PaidByDocuments(ctx context.Context, tenantID uuid.UUID, documentType string, ids []uuid.UUID) (map[uuid.UUID]float64, error)
+	// ListByDocument lists the allocations to a document with their payments, oldest first
+	ListByDocument(ctx context.Context, tenantID uuid.UUID, documentType string, id uuid.UUID) ([]*domain.DocumentPayment, error)
+}
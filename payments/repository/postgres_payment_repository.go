@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/payments/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresPaymentRepository implements PaymentRepository using PostgreSQL
+type PostgresPaymentRepository struct{}
+
+// NewPostgresPaymentRepository creates a new PostgreSQL payment repository
+func NewPostgresPaymentRepository() *PostgresPaymentRepository {
+	return &PostgresPaymentRepository{}
+}
+
+const paymentColumns = `id, tenant_id, direction, party_type, party_id, party_name, method, amount, allocated,
+	payment_date, reference, note, status, void_reason, voided_at, voided_by, created_by, created_at, updated_at`
+
+const allocationColumns = `id, payment_id, document_type, document_id, document_number, amount, created_at`
+
+// allocationTolerance absorbs float rounding when comparing money sums
+const allocationTolerance = 0.005
+
+// Create saves a payment and its allocations in one transaction
+func (r *PostgresPaymentRepository) Create(ctx context.Context, payment *domain.Payment, limits map[uuid.UUID]float64) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if err := r.checkLimits(ctx, tx, payment.TenantID, payment.Allocations, limits); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO payments (`+paymentColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		`,
+			payment.ID, payment.TenantID, payment.Direction, payment.PartyType, payment.PartyID, payment.PartyName,
+			payment.Method, payment.Amount, payment.Allocated, payment.PaymentDate, payment.Reference, payment.Note,
+			payment.Status, payment.VoidReason, payment.VoidedAt, payment.VoidedBy, payment.CreatedBy,
+			payment.CreatedAt, payment.UpdatedAt,
+		)
+		if err != nil {
+			return paymentWriteError("failed to create payment", err)
+		}
+
+		return r.insertAllocations(ctx, tx, payment.TenantID, payment.Allocations)
+	})
+}
+
+// AddAllocations locks the payment, checks it is still posted and saves the allocations
+func (r *PostgresPaymentRepository) AddAllocations(ctx context.Context, payment *domain.Payment, allocations []domain.Allocation, limits map[uuid.UUID]float64) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var status domain.Status
+		err := tx.QueryRow(ctx,
+			`SELECT status FROM payments WHERE tenant_id = $1 AND id = $2 FOR UPDATE`,
+			payment.TenantID, payment.ID,
+		).Scan(&status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrPaymentNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock payment: %w", err)
+		}
+		if status == domain.StatusVoid {
+			return domain.ErrPaymentVoid
+		}
+
+		if err := r.checkLimits(ctx, tx, payment.TenantID, allocations, limits); err != nil {
+			return err
+		}
+
+		// allocated is summed in SQL so allocations saved in between are not lost;
+		// the table's check keeps it within the amount
+		var added float64
+		for _, allocation := range allocations {
+			added += allocation.Amount
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE payments SET allocated = allocated + $3, updated_at = $4
+			WHERE tenant_id = $1 AND id = $2
+		`, payment.TenantID, payment.ID, added, payment.UpdatedAt)
+		if err != nil {
+			return paymentWriteError("failed to update payment", err)
+		}
+
+		return r.insertAllocations(ctx, tx, payment.TenantID, allocations)
+	})
+}
+
+// checkLimits locks each allocated document until the transaction ends and checks
+// what posted payments already allocated to it leaves room for the new allocations
+func (r *PostgresPaymentRepository) checkLimits(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID, allocations []domain.Allocation, limits map[uuid.UUID]float64) error {
+	adding := map[uuid.UUID]float64{}
+	types := map[uuid.UUID]string{}
+	for _, allocation := range allocations {
+		adding[allocation.DocumentID] += allocation.Amount
+		types[allocation.DocumentID] = allocation.DocumentType
+	}
+
+	// Lock in a fixed order so two payments for the same documents cannot deadlock
+	ids := make([]uuid.UUID, 0, len(adding))
+	for id := range adding {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+
+	for _, id := range ids {
+		_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`,
+			"payment_allocations|"+tenantID.String()+"|"+types[id]+"|"+id.String())
+		if err != nil {
+			return fmt.Errorf("failed to lock document: %w", err)
+		}
+
+		var paid float64
+		err = tx.QueryRow(ctx, `
+			SELECT COALESCE(SUM(a.amount), 0)
+			FROM payment_allocations a
+			JOIN payments p ON p.id = a.payment_id
+			WHERE a.tenant_id = $1 AND a.document_type = $2 AND a.document_id = $3 AND p.status = 'posted'
+		`, tenantID, types[id], id).Scan(&paid)
+		if err != nil {
+			return fmt.Errorf("failed to sum document payments: %w", err)
+		}
+
+		limit, ok := limits[id]
+		if !ok || paid+adding[id] > limit+allocationTolerance {
+			return fmt.Errorf("%w: %.2f is already paid of %.2f", domain.ErrOverAllocation, paid, limit)
+		}
+	}
+	return nil
+}
+
+// insertAllocations saves allocations inside a transaction
+func (r *PostgresPaymentRepository) insertAllocations(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID, allocations []domain.Allocation) error {
+	for _, allocation := range allocations {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO payment_allocations (tenant_id, `+allocationColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`,
+			tenantID, allocation.ID, allocation.PaymentID, allocation.DocumentType, allocation.DocumentID,
+			allocation.DocumentNumber, allocation.Amount, allocation.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create payment allocation: %w", err)
+		}
+	}
+	return nil
+}
+
+// Get retrieves a payment with its allocations
+func (r *PostgresPaymentRepository) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE tenant_id = $1 AND id = $2`
+
+	payment, err := scanPayment(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT `+allocationColumns+`
+		FROM payment_allocations
+		WHERE payment_id = $1
+		ORDER BY created_at, document_number
+	`, payment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment allocations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var allocation domain.Allocation
+		if err := rows.Scan(
+			&allocation.ID, &allocation.PaymentID, &allocation.DocumentType, &allocation.DocumentID,
+			&allocation.DocumentNumber, &allocation.Amount, &allocation.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan payment allocation: %w", err)
+		}
+		payment.Allocations = append(payment.Allocations, allocation)
+	}
+
+	return payment, rows.Err()
+}
+
+// List retrieves payments (without allocations), newest first
+func (r *PostgresPaymentRepository) List(ctx context.Context, tenantID uuid.UUID, filter domain.PaymentFilter) ([]*domain.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE tenant_id = $1
+		  AND ($2::varchar IS NULL OR direction = $2)
+		  AND ($3::uuid IS NULL OR party_id = $3)
+		  AND ($4::varchar IS NULL OR method = $4)
+		  AND ($5::varchar IS NULL OR status = $5)
+		  AND ($6::date IS NULL OR payment_date >= $6)
+		  AND ($7::date IS NULL OR payment_date <= $7)
+		ORDER BY payment_date DESC, created_at DESC
+		LIMIT $8 OFFSET $9
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.Direction, filter.PartyID, filter.Method, filter.Status,
+		filter.From, filter.To, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []*domain.Payment{}
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+// SaveVoid marks a posted payment void
+func (r *PostgresPaymentRepository) SaveVoid(ctx context.Context, payment *domain.Payment) error {
+	tag, err := db.MainPool.Exec(ctx, `
+		UPDATE payments
+		SET status = $3, void_reason = $4, voided_at = $5, voided_by = $6, updated_at = $7
+		WHERE tenant_id = $1 AND id = $2 AND status = 'posted'
+	`, payment.TenantID, payment.ID, payment.Status, payment.VoidReason, payment.VoidedAt, payment.VoidedBy, payment.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to void payment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPaymentVoid
+	}
+	return nil
+}
+
+// PaidByDocuments sums posted allocations per document; documents without any are left out
+func (r *PostgresPaymentRepository) PaidByDocuments(ctx context.Context, tenantID uuid.UUID, documentType string, ids []uuid.UUID) (map[uuid.UUID]float64, error) {
+	paid := map[uuid.UUID]float64{}
+	if len(ids) == 0 {
+		return paid, nil
+	}
+
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT a.document_id, SUM(a.amount)
+		FROM payment_allocations a
+		JOIN payments p ON p.id = a.payment_id
+		WHERE a.tenant_id = $1 AND a.document_type = $2 AND a.document_id = ANY($3) AND p.status = 'posted'
+		GROUP BY a.document_id
+	`, tenantID, documentType, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum document payments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var amount float64
+		if err := rows.Scan(&id, &amount); err != nil {
+			return nil, fmt.Errorf("failed to scan document payment: %w", err)
+		}
+		paid[id] = amount
+	}
+	return paid, rows.Err()
+}
+
+// ListByDocument lists a document's allocations, voided payments included
+func (r *PostgresPaymentRepository) ListByDocument(ctx context.Context, tenantID uuid.UUID, documentType string, id uuid.UUID) ([]*domain.DocumentPayment, error) {
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT p.id, p.payment_date, p.method, p.reference, a.amount, p.status
+		FROM payment_allocations a
+		JOIN payments p ON p.id = a.payment_id
+		WHERE a.tenant_id = $1 AND a.document_type = $2 AND a.document_id = $3
+		ORDER BY p.payment_date, a.created_at
+	`, tenantID, documentType, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []*domain.DocumentPayment{}
+	for rows.Next() {
+		var payment domain.DocumentPayment
+		if err := rows.Scan(&payment.PaymentID, &payment.PaymentDate, &payment.Method, &payment.Reference, &payment.Amount, &payment.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan document payment: %w", err)
+		}
+		payments = append(payments, &payment)
+	}
+	return payments, rows.Err()
+}
+
+// paymentWriteError maps the allocated-within-amount check to ErrOverAllocation
+func paymentWriteError(message string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23514" && pgErr.ConstraintName == "chk_payment_allocated" {
+		return fmt.Errorf("%w: the payment has too little left to allocate", domain.ErrOverAllocation)
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// scanPayment scans a payments row in paymentColumns order
+func scanPayment(row pgx.Row) (*domain.Payment, error) {
+	payment := domain.Payment{Allocations: []domain.Allocation{}}
+	err := row.Scan(
+		&payment.ID, &payment.TenantID, &payment.Direction, &payment.PartyType, &payment.PartyID, &payment.PartyName,
+		&payment.Method, &payment.Amount, &payment.Allocated, &payment.PaymentDate, &payment.Reference, &payment.Note,
+		&payment.Status, &payment.VoidReason, &payment.VoidedAt, &payment.VoidedBy, &payment.CreatedBy,
+		&payment.CreatedAt, &payment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
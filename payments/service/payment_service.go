@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/payments/domain"
+	"github.com/aceextension/payments/repository"
+	"github.com/google/uuid"
+)
+
+// paymentService implements PaymentService
+type paymentService struct {
+	repo    repository.PaymentRepository
+	parties PartyDirectory
+	journal PaymentJournal
+	ledger  CustomerLedger
+
+	mu      sync.RWMutex
+	sources map[string]DocumentSource
+}
+
+// NewPaymentService creates a new payment service
+func NewPaymentService(repo repository.PaymentRepository) PaymentService {
+	return &paymentService{
+		repo:    repo,
+		sources: make(map[string]DocumentSource),
+	}
+}
+
+// RegisterDocumentType enables payments against a document type
+func (s *paymentService) RegisterDocumentType(documentType string, source DocumentSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[documentType] = source
+}
+
+// SetParties sets where party names come from
+func (s *paymentService) SetParties(parties PartyDirectory) {
+	s.parties = parties
+}
+
+// SetJournal sets the journal payments are posted to
+func (s *paymentService) SetJournal(journal PaymentJournal) {
+	s.journal = journal
+}
+
+// SetCustomerLedger sets the ledger receipts are recorded on
+func (s *paymentService) SetCustomerLedger(ledger CustomerLedger) {
+	s.ledger = ledger
+}
+
+// Record validates a payment, allocates it and saves it, then posts it
+func (s *paymentService) Record(ctx context.Context, input PaymentInput) (*domain.Payment, error) {
+	payment := domain.NewPayment(input.TenantID, input.Direction, input.Method, input.Amount, input.PaymentDate)
+	payment.PartyID = input.PartyID
+	payment.Reference = trimmed(input.Reference)
+	payment.Note = trimmed(input.Note)
+	payment.CreatedBy = input.CreatedBy
+	if err := payment.Validate(); err != nil {
+		return nil, err
+	}
+
+	if s.parties == nil {
+		return nil, domain.ErrPartyNotFound
+	}
+	name, err := s.parties.PartyName(ctx, payment.TenantID, payment.PartyType, payment.PartyID)
+	if err != nil {
+		return nil, err
+	}
+	payment.PartyName = name
+
+	docs, err := s.allocate(ctx, payment, input.Allocations, len(input.Allocations) == 0 && input.AutoAllocate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, payment, allocationLimits(docs)); err != nil {
+		return nil, err
+	}
+
+	s.syncPaid(ctx, payment.TenantID, docs)
+	if s.journal != nil {
+		if err := s.journal.PostPayment(ctx, payment); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to post payment %s to the journal: %v", payment.ID, err))
+		}
+	}
+	if s.ledger != nil && payment.Direction == domain.DirectionReceipt {
+		if err := s.ledger.RecordReceipt(ctx, payment); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to record payment %s on the customer's khata: %v", payment.ID, err))
+		}
+	}
+	s.audit(ctx, "RECORD_PAYMENT", payment, payment.CreatedBy)
+	return payment, nil
+}
+
+// Get retrieves a payment with its allocations
+func (s *paymentService) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Payment, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// List returns payments, newest first
+func (s *paymentService) List(ctx context.Context, tenantID uuid.UUID, filter domain.PaymentFilter) ([]*domain.Payment, error) {
+	return s.repo.List(ctx, tenantID, filter)
+}
+
+// Allocate settles documents with the unallocated part of a posted payment
+func (s *paymentService) Allocate(ctx context.Context, tenantID, id uuid.UUID, allocations []AllocationInput, userID *uuid.UUID) (*domain.Payment, error) {
+	payment, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == domain.StatusVoid {
+		return nil, domain.ErrPaymentVoid
+	}
+
+	before := len(payment.Allocations)
+	docs, err := s.allocate(ctx, payment, allocations, len(allocations) == 0)
+	if err != nil {
+		return nil, err
+	}
+	added := payment.Allocations[before:]
+	if len(added) == 0 {
+		return payment, nil
+	}
+
+	if err := s.repo.AddAllocations(ctx, payment, added, allocationLimits(docs)); err != nil {
+		return nil, err
+	}
+
+	s.syncPaid(ctx, tenantID, docs)
+	s.audit(ctx, "ALLOCATE_PAYMENT", payment, userID)
+	return payment, nil
+}
+
+// Void reverses a posted payment and frees the documents it paid
+func (s *paymentService) Void(ctx context.Context, tenantID, id uuid.UUID, reason string, userID *uuid.UUID) (*domain.Payment, error) {
+	payment, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := payment.Void(reason, userID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveVoid(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	// The documents are read after the void so their paid amounts leave it out
+	docs := map[string]*domain.Document{}
+	for _, allocation := range payment.Allocations {
+		key := allocation.DocumentType + "|" + allocation.DocumentID.String()
+		if _, ok := docs[key]; ok {
+			continue
+		}
+		doc, err := s.document(ctx, tenantID, allocation.DocumentType, allocation.DocumentID)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to read %s %s paid by voided payment %s: %v", allocation.DocumentType, allocation.DocumentNumber, payment.ID, err))
+			continue
+		}
+		docs[key] = doc
+	}
+	s.syncPaid(ctx, tenantID, docs)
+
+	if s.journal != nil {
+		if err := s.journal.ReverseVoided(ctx, payment); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to reverse voided payment %s in the journal: %v", payment.ID, err))
+		}
+	}
+	if s.ledger != nil && payment.Direction == domain.DirectionReceipt {
+		if err := s.ledger.ReverseReceipt(ctx, payment); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to reverse voided payment %s on the customer's khata: %v", payment.ID, err))
+		}
+	}
+
+	// Analytics counts VOID_ actions per user for its voids anomaly metric
+	s.audit(ctx, "VOID_PAYMENT", payment, userID)
+	return payment, nil
+}
+
+// DocumentStatus reads a document with what has been paid on it
+func (s *paymentService) DocumentStatus(ctx context.Context, tenantID uuid.UUID, documentType string, id uuid.UUID) (*DocumentStatus, error) {
+	doc, err := s.document(ctx, tenantID, documentType, id)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.repo.ListByDocument(ctx, tenantID, documentType, id)
+	if err != nil {
+		return nil, err
+	}
+	return &DocumentStatus{Document: doc, Payments: payments}, nil
+}
+
+// OpenDocuments gathers the party's documents of every type paid in the direction
+// that still have an amount outstanding, oldest first
+func (s *paymentService) OpenDocuments(ctx context.Context, tenantID uuid.UUID, direction domain.Direction, partyID uuid.UUID) ([]*domain.Document, error) {
+	if !direction.Valid() {
+		return nil, fmt.Errorf("%w: unknown direction %q", domain.ErrInvalidPayment, direction)
+	}
+
+	s.mu.RLock()
+	types := make([]string, 0, len(s.sources))
+	for documentType, source := range s.sources {
+		if source.Direction == direction && source.Open != nil {
+			types = append(types, documentType)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Strings(types)
+
+	open := []*domain.Document{}
+	for _, documentType := range types {
+		source, _ := s.source(documentType)
+		docs, err := source.Open(ctx, tenantID, partyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list open %s documents: %w", documentType, err)
+		}
+		if err := s.fillPaid(ctx, tenantID, documentType, docs); err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			if doc.Outstanding() > 0 {
+				open = append(open, doc)
+			}
+		}
+	}
+
+	sort.SliceStable(open, func(i, j int) bool {
+		if !open[i].Date.Equal(open[j].Date) {
+			return open[i].Date.Before(open[j].Date)
+		}
+		return open[i].Number < open[j].Number
+	})
+	return open, nil
+}
+
+// allocate adds the requested allocations to the payment, or with auto settles the
+// party's open documents oldest first until the payment runs out. It returns the
+// documents allocated to, keyed by type and ID.
+func (s *paymentService) allocate(ctx context.Context, payment *domain.Payment, inputs []AllocationInput, auto bool) (map[string]*domain.Document, error) {
+	docs := map[string]*domain.Document{}
+
+	if auto {
+		open, err := s.OpenDocuments(ctx, payment.TenantID, payment.Direction, payment.PartyID)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range open {
+			if payment.Unallocated() <= 0 {
+				break
+			}
+			if doc.PayableBy(payment) != nil {
+				continue
+			}
+			if err := payment.Allocate(doc, math.Min(doc.Outstanding(), payment.Unallocated())); err != nil {
+				return nil, err
+			}
+			docs[doc.Type+"|"+doc.ID.String()] = doc
+		}
+		return docs, nil
+	}
+
+	for _, input := range inputs {
+		key := input.DocumentType + "|" + input.DocumentID.String()
+		doc, ok := docs[key]
+		if !ok {
+			var err error
+			doc, err = s.document(ctx, payment.TenantID, input.DocumentType, input.DocumentID)
+			if err != nil {
+				return nil, err
+			}
+			docs[key] = doc
+		}
+		if err := payment.Allocate(doc, input.Amount); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// document reads a document from its source with what payments have paid on it
+func (s *paymentService) document(ctx context.Context, tenantID uuid.UUID, documentType string, id uuid.UUID) (*domain.Document, error) {
+	source, ok := s.source(documentType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownDocumentType, documentType)
+	}
+	doc, err := source.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	doc.Type, doc.Direction = documentType, source.Direction
+	if err := s.fillPaid(ctx, tenantID, documentType, []*domain.Document{doc}); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// fillPaid sets what posted payments have paid on each document
+func (s *paymentService) fillPaid(ctx context.Context, tenantID uuid.UUID, documentType string, docs []*domain.Document) error {
+	ids := make([]uuid.UUID, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	paid, err := s.repo.PaidByDocuments(ctx, tenantID, documentType, ids)
+	if err != nil {
+		return err
+	}
+	source, _ := s.source(documentType)
+	for _, doc := range docs {
+		doc.Type, doc.Direction = documentType, source.Direction
+		doc.Paid = paid[doc.ID]
+	}
+	return nil
+}
+
+// syncPaid tells the owning modules what is now paid on the documents. The
+// payment is already committed, so failures are logged for follow-up.
+func (s *paymentService) syncPaid(ctx context.Context, tenantID uuid.UUID, docs map[string]*domain.Document) {
+	for _, doc := range docs {
+		source, ok := s.source(doc.Type)
+		if !ok || source.Paid == nil {
+			continue
+		}
+		paid, err := s.repo.PaidByDocuments(ctx, tenantID, doc.Type, []uuid.UUID{doc.ID})
+		if err == nil {
+			doc.Paid = paid[doc.ID]
+			err = source.Paid(ctx, tenantID, doc.ID, doc.AmountPaid())
+		}
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to update the amount paid on %s %s: %v", doc.Type, doc.Number, err))
+		}
+	}
+}
+
+// source returns a registered document type
+func (s *paymentService) source(documentType string) (DocumentSource, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	source, ok := s.sources[documentType]
+	return source, ok
+}
+
+// allocationLimits caps what payments may allocate to each document at what was
+// not settled outside payments
+func allocationLimits(docs map[string]*domain.Document) map[uuid.UUID]float64 {
+	limits := make(map[uuid.UUID]float64, len(docs))
+	for _, doc := range docs {
+		limits[doc.ID] = doc.Total - doc.Settled
+	}
+	return limits
+}
+
+// trimmed drops blank optional text
+func trimmed(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	text := strings.TrimSpace(*value)
+	if text == "" {
+		return nil
+	}
+	return &text
+}
+
+// audit logs a payment action
+func (s *paymentService) audit(ctx context.Context, action string, payment *domain.Payment, userID *uuid.UUID) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &payment.TenantID,
+	}
+
+	allocations := make([]map[string]interface{}, 0, len(payment.Allocations))
+	for _, allocation := range payment.Allocations {
+		allocations = append(allocations, map[string]interface{}{
+			"document_type":   allocation.DocumentType,
+			"document_number": allocation.DocumentNumber,
+			"amount":          allocation.Amount,
+		})
+	}
+
+	entityIDStr := payment.ID.String()
+	audit.Service.Log(ctx, action, "Payment", &entityIDStr, map[string]interface{}{
+		"direction":   payment.Direction,
+		"party_id":    payment.PartyID,
+		"method":      payment.Method,
+		"amount":      payment.Amount,
+		"allocated":   payment.Allocated,
+		"reference":   payment.Reference,
+		"status":      payment.Status,
+		"void_reason": payment.VoidReason,
+		"allocations": allocations,
+	}, auditCtx)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/payments/domain"
+	"github.com/google/uuid"
+)
+
+// DocumentSource reads the invoices or bills of a document type owned by another module
+type DocumentSource struct {
+	// Direction is how the documents are paid: receipts for sales, disbursements for purchases
+	Direction domain.Direction
+	// Get reads a document; ErrDocumentNotFound if the tenant has no such document
+	Get func(ctx context.Context, tenantID, id uuid.UUID) (*domain.Document, error)
+	// Open lists the party's documents that may still be due, for automatic allocation
+	Open func(ctx context.Context, tenantID, partyID uuid.UUID) ([]*domain.Document, error)
+	// Paid, when set, tells the owning module the total now paid on a document,
+	// counting what was settled outside payments
+	Paid func(ctx context.Context, tenantID, id uuid.UUID, amount float64) error
+}
+
+// PartyDirectory names the customers and suppliers payments are made with. Init of crm sets it.
+type PartyDirectory interface {
+	// PartyName returns ErrPartyNotFound if the tenant has no such customer or supplier
+	PartyName(ctx context.Context, tenantID uuid.UUID, partyType string, partyID uuid.UUID) (string, error)
+}
+
+// PaymentJournal posts payments to the accounts receivable and payable. Init sets
+// accounting's automatic posting.
+type PaymentJournal interface {
+	PostPayment(ctx context.Context, payment *domain.Payment) error
+	ReverseVoided(ctx context.Context, payment *domain.Payment) error
+}
+
+// CustomerLedger keeps customers' running balances in step with receipts. Init of
+// crm sets the khata.
+type CustomerLedger interface {
+	RecordReceipt(ctx context.Context, payment *domain.Payment) error
+	ReverseReceipt(ctx context.Context, payment *domain.Payment) error
+}
+
+// PaymentInput is money received or paid. Without allocations and with
+// AutoAllocate, the party's oldest open documents are settled first.
+type PaymentInput struct {
+	TenantID     uuid.UUID
+	Direction    domain.Direction
+	PartyID      uuid.UUID
+	Method       domain.Method
+	Amount       float64
+	PaymentDate  time.Time
+	Reference    *string
+	Note         *string
+	Allocations  []AllocationInput
+	AutoAllocate bool
+	CreatedBy    *uuid.UUID
+}
+
+// AllocationInput settles amount of a document
+type AllocationInput struct {
+	DocumentType string
+	DocumentID   uuid.UUID
+	Amount       float64
+}
+
+// DocumentStatus is what has been paid on a document and by which payments
+type DocumentStatus struct {
+	Document *domain.Document
+	Payments []*domain.DocumentPayment
+}
+
+// PaymentService defines the interface for payment business logic
+type PaymentService interface {
+	// RegisterDocumentType enables payments against a document type owned by another module
+	RegisterDocumentType(documentType string, source DocumentSource)
+
+	// Record saves a payment with its allocations and posts it to the journal;
+	// receipts also go on the customer's khata
+	Record(ctx context.Context, input PaymentInput) (*domain.Payment, error)
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Payment, error)
+	List(ctx context.Context, tenantID uuid.UUID, filter domain.PaymentFilter) ([]*domain.Payment, error)
+	// Allocate settles documents with what is left of a payment; without allocations
+	// the party's oldest open documents are settled first
+	Allocate(ctx context.Context, tenantID, id uuid.UUID, allocations []AllocationInput, userID *uuid.UUID) (*domain.Payment, error)
+	// Void reverses a payment with a reason: its allocations stop counting and its
+	// journal entry and khata receipt are reversed
+	Void(ctx context.Context, tenantID, id uuid.UUID, reason string, userID *uuid.UUID) (*domain.Payment, error)
+
+	// DocumentStatus returns what has been paid on a document
+	DocumentStatus(ctx context.Context, tenantID uuid.UUID, documentType string, id uuid.UUID) (*DocumentStatus, error)
+	// OpenDocuments lists a party's documents with an amount outstanding, oldest first
+	OpenDocuments(ctx context.Context, tenantID uuid.UUID, direction domain.Direction, partyID uuid.UUID) ([]*domain.Document, error)
+
+	SetParties(parties PartyDirectory)
+	SetJournal(journal PaymentJournal)
+	SetCustomerLedger(ledger CustomerLedger)
+}
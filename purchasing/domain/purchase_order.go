@@ -0,0 +1,328 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPurchaseOrderNotFound is returned when a purchase order does not exist for the tenant
+	ErrPurchaseOrderNotFound = errors.New("purchase order not found")
+	// ErrInvalidPurchaseOrder is returned for an order with no lines, a bad quantity or cost
+	ErrInvalidPurchaseOrder = errors.New("invalid purchase order")
+	// ErrPurchaseOrderClosed is returned when receiving against or cancelling an order that no longer takes goods
+	ErrPurchaseOrderClosed = errors.New("purchase order is not open")
+	// ErrPurchaseOrderReceived is returned when cancelling an order that already has receipts
+	ErrPurchaseOrderReceived = errors.New("purchase order has goods received against it")
+	// ErrGoodsReceiptNotFound is returned when a goods receipt does not exist for the tenant
+	ErrGoodsReceiptNotFound = errors.New("goods receipt not found")
+	// ErrInvalidGoodsReceipt is returned for a receipt with no lines or a line not on the order
+	ErrInvalidGoodsReceipt = errors.New("invalid goods receipt")
+	// ErrReceiptExceedsOrder is returned when a receipt brings in more than is still outstanding on a line
+	ErrReceiptExceedsOrder = errors.New("receipt is more than is outstanding on the order")
+	// ErrNoFiscalYear is returned when the tenant has no current fiscal year to number orders in
+	ErrNoFiscalYear = errors.New("no current fiscal year; create one before ordering")
+	// ErrSupplierNotFound is returned when the supplier does not exist for the tenant
+	ErrSupplierNotFound = errors.New("supplier not found")
+	// ErrSupplierBlocked is returned when ordering from a blocked supplier
+	ErrSupplierBlocked = errors.New("supplier is blocked")
+	// ErrProductNotFound is returned when a line's product does not exist for the tenant
+	ErrProductNotFound = errors.New("product not found")
+)
+
+// OrderStatus is where a purchase order is in its life
+type OrderStatus string
+
+const (
+	OrderOpen              OrderStatus = "open"               // Placed, nothing received yet
+	OrderPartiallyReceived OrderStatus = "partially_received" // Some lines outstanding
+	OrderReceived          OrderStatus = "received"           // Everything ordered has arrived
+	OrderCancelled         OrderStatus = "cancelled"          // Withdrawn before anything arrived; the number stays used
+)
+
+// Receivable reports whether goods can still be received against the order
+func (s OrderStatus) Receivable() bool {
+	return s == OrderOpen || s == OrderPartiallyReceived
+}
+
+// PurchaseOrder is an order placed with a supplier. Its number comes from the
+// fiscal year's purchase series. Amounts are before VAT, which is charged on the
+// supplier's bill.
+type PurchaseOrder struct {
+	ID           uuid.UUID           `json:"id" db:"id"`
+	TenantID     uuid.UUID           `json:"tenantId" db:"tenant_id"`
+	FiscalYearID uuid.UUID           `json:"fiscalYearId" db:"fiscal_year_id"`
+	OrderNumber  string              `json:"orderNumber" db:"order_number"` // PUR-8283-0001
+	SupplierID   uuid.UUID           `json:"supplierId" db:"supplier_id"`
+	SupplierName string              `json:"supplierName" db:"supplier_name"`
+	OrderDate    time.Time           `json:"orderDate" db:"order_date"`
+	ExpectedDate *time.Time          `json:"expectedDate,omitempty" db:"expected_date"` // Delivery date promised by the supplier
+	Status       OrderStatus         `json:"status" db:"status"`
+	TotalAmount  float64             `json:"totalAmount" db:"total_amount"`
+	RoundOff     float64             `json:"roundOff" db:"round_off"` // Added to reach the rounded total; negative when rounded down
+	Note         *string             `json:"note,omitempty" db:"note"`
+	Locale       *string             `json:"locale,omitempty" db:"locale"` // Language product names were copied in; nil for the catalog's own
+	CancelledAt  *time.Time          `json:"cancelledAt,omitempty" db:"cancelled_at"`
+	CreatedBy    *uuid.UUID          `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt    time.Time           `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time           `json:"updatedAt" db:"updated_at"`
+	Lines        []PurchaseOrderLine `json:"lines"`
+}
+
+// PurchaseOrderLine is a product ordered. Product details are copied at order time.
+type PurchaseOrderLine struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	OrderID     uuid.UUID `json:"orderId" db:"order_id"`
+	LineNo      int       `json:"lineNo" db:"line_no"`
+	ProductID   uuid.UUID `json:"productId" db:"product_id"`
+	ProductCode string    `json:"productCode" db:"product_code"`
+	ProductName string    `json:"productName" db:"product_name"`
+	Unit        string    `json:"unit" db:"unit"`
+	Quantity    float64   `json:"quantity" db:"quantity"`
+	UnitCost    float64   `json:"unitCost" db:"unit_cost"` // Agreed cost before VAT
+	Amount      float64   `json:"amount" db:"amount"`
+	ReceivedQty float64   `json:"receivedQty" db:"received_qty"`
+}
+
+// Outstanding is what is still to be received on the line
+func (l *PurchaseOrderLine) Outstanding() float64 {
+	return math.Max(0, l.Quantity-l.ReceivedQty)
+}
+
+// NewPurchaseOrder creates an order with a supplier; the number and lines are
+// filled in before it is saved
+func NewPurchaseOrder(tenantID, supplierID uuid.UUID, orderDate time.Time) *PurchaseOrder {
+	now := time.Now()
+	return &PurchaseOrder{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		SupplierID: supplierID,
+		OrderDate:  orderDate,
+		Status:     OrderOpen,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Lines:      []PurchaseOrderLine{},
+	}
+}
+
+// AddLine orders a quantity of a product at a unit cost before VAT
+func (o *PurchaseOrder) AddLine(productID uuid.UUID, quantity, unitCost float64) (*PurchaseOrderLine, error) {
+	if productID == uuid.Nil {
+		return nil, fmt.Errorf("%w: line %d has no product", ErrInvalidPurchaseOrder, len(o.Lines)+1)
+	}
+	if quantity <= 0 || unitCost < 0 {
+		return nil, fmt.Errorf("%w: line %d needs a positive quantity and a cost not below zero", ErrInvalidPurchaseOrder, len(o.Lines)+1)
+	}
+
+	o.Lines = append(o.Lines, PurchaseOrderLine{
+		ID:        uuid.New(),
+		OrderID:   o.ID,
+		LineNo:    len(o.Lines) + 1,
+		ProductID: productID,
+		Quantity:  quantity,
+		UnitCost:  unitCost,
+		Amount:    roundMoney(quantity * unitCost),
+	})
+	return &o.Lines[len(o.Lines)-1], nil
+}
+
+// Calculate totals the lines and checks the expected date
+func (o *PurchaseOrder) Calculate() error {
+	if len(o.Lines) == 0 {
+		return fmt.Errorf("%w: order has no lines", ErrInvalidPurchaseOrder)
+	}
+	if o.ExpectedDate != nil && o.ExpectedDate.Before(o.OrderDate) {
+		return fmt.Errorf("%w: expected date is before the order date", ErrInvalidPurchaseOrder)
+	}
+
+	total := 0.0
+	for _, line := range o.Lines {
+		total += line.Amount
+	}
+	o.TotalAmount = roundMoney(total)
+	o.RoundOff = 0
+	return nil
+}
+
+// RoundTotal rounds the calculated total, keeping the difference as the round-off
+func (o *PurchaseOrder) RoundTotal(rounded float64) {
+	total := roundMoney(o.TotalAmount - o.RoundOff)
+	o.TotalAmount = roundMoney(rounded)
+	o.RoundOff = roundMoney(o.TotalAmount - total)
+}
+
+// Cancel withdraws an order that nothing has been received against
+func (o *PurchaseOrder) Cancel() error {
+	if o.Status == OrderPartiallyReceived || o.Status == OrderReceived {
+		return ErrPurchaseOrderReceived
+	}
+	if o.Status != OrderOpen {
+		return ErrPurchaseOrderClosed
+	}
+
+	now := time.Now()
+	o.Status = OrderCancelled
+	o.CancelledAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// Receive checks a receipt against what is outstanding, fills in its lines from
+// the order and adds the quantities to the order's lines
+func (o *PurchaseOrder) Receive(receipt *GoodsReceipt) error {
+	if !o.Status.Receivable() {
+		return ErrPurchaseOrderClosed
+	}
+	if len(receipt.Lines) == 0 {
+		return fmt.Errorf("%w: receipt has no lines", ErrInvalidGoodsReceipt)
+	}
+
+	lines := make(map[uuid.UUID]*PurchaseOrderLine, len(o.Lines))
+	for i := range o.Lines {
+		lines[o.Lines[i].ID] = &o.Lines[i]
+	}
+
+	// Check every line before changing any, so a bad line leaves the order untouched
+	receiving := map[uuid.UUID]float64{}
+	for _, line := range receipt.Lines {
+		orderLine, ok := lines[line.OrderLineID]
+		if !ok {
+			return fmt.Errorf("%w: line %s is not on order %s", ErrInvalidGoodsReceipt, line.OrderLineID, o.OrderNumber)
+		}
+		receiving[line.OrderLineID] += line.Quantity
+		if receiving[line.OrderLineID] > orderLine.Outstanding()+quantityTolerance {
+			return fmt.Errorf("%w: %s has %g outstanding", ErrReceiptExceedsOrder, orderLine.ProductName, orderLine.Outstanding())
+		}
+	}
+
+	receipt.OrderID, receipt.OrderNumber, receipt.SupplierID = o.ID, o.OrderNumber, o.SupplierID
+	total := 0.0
+	for i := range receipt.Lines {
+		line := &receipt.Lines[i]
+		orderLine := lines[line.OrderLineID]
+		line.ProductID = orderLine.ProductID
+		line.ProductName = orderLine.ProductName
+		if line.UnitCost == nil {
+			cost := orderLine.UnitCost
+			line.UnitCost = &cost
+		}
+		line.Amount = roundMoney(line.Quantity * *line.UnitCost)
+		total += line.Amount
+		orderLine.ReceivedQty += line.Quantity
+	}
+	receipt.TotalAmount = roundMoney(total)
+
+	o.Status = OrderReceived
+	for _, line := range o.Lines {
+		if line.Outstanding() > quantityTolerance {
+			o.Status = OrderPartiallyReceived
+			break
+		}
+	}
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+// GoodsReceipt (GRN) records goods arriving against a purchase order. Received
+// costs may differ from the order; the product's cost price follows them.
+type GoodsReceipt struct {
+	ID            uuid.UUID          `json:"id" db:"id"`
+	TenantID      uuid.UUID          `json:"tenantId" db:"tenant_id"`
+	ReceiptNumber string             `json:"receiptNumber" db:"receipt_number"` // GRN-00001
+	OrderID       uuid.UUID          `json:"orderId" db:"order_id"`
+	OrderNumber   string             `json:"orderNumber" db:"order_number"`
+	SupplierID    uuid.UUID          `json:"supplierId" db:"supplier_id"`
+	WarehouseID   *uuid.UUID         `json:"warehouseId,omitempty" db:"warehouse_id"` // Where the goods went; nil for the default warehouse
+	ReceivedAt    time.Time          `json:"receivedAt" db:"received_at"`
+	SupplierRef   *string            `json:"supplierRef,omitempty" db:"supplier_ref"` // Supplier's delivery note or challan number
+	Note          *string            `json:"note,omitempty" db:"note"`
+	TotalAmount   float64            `json:"totalAmount" db:"total_amount"` // Before VAT
+	CreatedBy     *uuid.UUID         `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt     time.Time          `json:"createdAt" db:"created_at"`
+	Lines         []GoodsReceiptLine `json:"lines"`
+}
+
+// GoodsReceiptLine is a quantity received against an order line
+type GoodsReceiptLine struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	ReceiptID   uuid.UUID `json:"receiptId" db:"receipt_id"`
+	OrderLineID uuid.UUID `json:"orderLineId" db:"order_line_id"`
+	ProductID   uuid.UUID `json:"productId" db:"product_id"`
+	ProductName string    `json:"productName" db:"product_name"`
+	Quantity    float64   `json:"quantity" db:"quantity"`
+	UnitCost    *float64  `json:"unitCost" db:"unit_cost"` // Before VAT; the ordered cost when not given
+	Amount      float64   `json:"amount" db:"amount"`
+}
+
+// NewGoodsReceipt creates a receipt of goods arriving on receivedAt
+func NewGoodsReceipt(tenantID uuid.UUID, receivedAt time.Time) *GoodsReceipt {
+	return &GoodsReceipt{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		ReceivedAt: receivedAt,
+		CreatedAt:  time.Now(),
+		Lines:      []GoodsReceiptLine{},
+	}
+}
+
+// AddLine receives a quantity against an order line, at unitCost or the ordered cost when nil
+func (r *GoodsReceipt) AddLine(orderLineID uuid.UUID, quantity float64, unitCost *float64) error {
+	if quantity <= 0 {
+		return fmt.Errorf("%w: line %d needs a positive quantity", ErrInvalidGoodsReceipt, len(r.Lines)+1)
+	}
+	if unitCost != nil && *unitCost < 0 {
+		return fmt.Errorf("%w: line %d cost is below zero", ErrInvalidGoodsReceipt, len(r.Lines)+1)
+	}
+
+	r.Lines = append(r.Lines, GoodsReceiptLine{
+		ID:          uuid.New(),
+		ReceiptID:   r.ID,
+		OrderLineID: orderLineID,
+		Quantity:    quantity,
+		UnitCost:    unitCost,
+	})
+	return nil
+}
+
+// PurchaseOrderFilter narrows a purchase order listing
+type PurchaseOrderFilter struct {
+	SupplierID *uuid.UUID
+	Status     *OrderStatus
+	Limit      int
+	Offset     int
+}
+
+// GoodsReceiptFilter narrows a goods receipt listing
+type GoodsReceiptFilter struct {
+	OrderID    *uuid.UUID
+	SupplierID *uuid.UUID
+	Limit      int
+	Offset     int
+}
+
+// SupplierInfo is a supplier as an order is placed with them
+type SupplierInfo struct {
+	ID      uuid.UUID
+	Name    string
+	Blocked bool
+}
+
+// OrderProduct is a catalog product as it is ordered
+type OrderProduct struct {
+	ID        uuid.UUID
+	Code      string
+	Name      string
+	Unit      string
+	CostPrice float64 // Last cost, used when a line gives none
+}
+
+// quantityTolerance absorbs float error when comparing received and ordered quantities
+const quantityTolerance = 1e-9
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
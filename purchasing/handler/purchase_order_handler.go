@@ -0,0 +1,408 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/purchasing"
+	"github.com/aceextension/purchasing/domain"
+	"github.com/aceextension/purchasing/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// PurchaseOrderHandler handles HTTP requests for purchase orders and goods receipts
+type PurchaseOrderHandler struct{}
+
+// NewPurchaseOrderHandler creates a new purchase order handler
+func NewPurchaseOrderHandler() *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{}
+}
+
+// PurchaseOrderRequest places an order with a supplier
+type PurchaseOrderRequest struct {
+	SupplierID   uuid.UUID                  `json:"supplierId" validate:"required"`
+	OrderDate    string                     `json:"orderDate,omitempty"`    // YYYY-MM-DD; defaults to today
+	ExpectedDate string                     `json:"expectedDate,omitempty"` // YYYY-MM-DD; promised delivery date
+	Note         *string                    `json:"note,omitempty"`
+	Locale       *string                    `json:"locale,omitempty"` // Language to print product names in; defaults to the tenant's purchase order language
+	Lines        []PurchaseOrderLineRequest `json:"lines" validate:"required,min=1"`
+}
+
+// PurchaseOrderLineRequest is a product ordered
+type PurchaseOrderLineRequest struct {
+	ProductID uuid.UUID `json:"productId" validate:"required"`
+	Quantity  float64   `json:"quantity" validate:"required,gt=0"`
+	UnitCost  *float64  `json:"unitCost,omitempty"` // Before VAT; defaults to the product's cost price
+}
+
+// GoodsReceiptRequest records goods arriving against an order
+type GoodsReceiptRequest struct {
+	WarehouseID *uuid.UUID                `json:"warehouseId,omitempty"` // Defaults to the default warehouse
+	ReceivedAt  string                    `json:"receivedAt,omitempty"`  // YYYY-MM-DD; defaults to today
+	SupplierRef *string                   `json:"supplierRef,omitempty"` // Delivery note or challan number
+	Note        *string                   `json:"note,omitempty"`
+	Lines       []GoodsReceiptLineRequest `json:"lines" validate:"required,min=1"`
+}
+
+// GoodsReceiptLineRequest is a quantity received against an order line
+type GoodsReceiptLineRequest struct {
+	OrderLineID uuid.UUID `json:"orderLineId" validate:"required"`
+	Quantity    float64   `json:"quantity" validate:"required,gt=0"`
+	UnitCost    *float64  `json:"unitCost,omitempty"` // Before VAT; defaults to the ordered cost
+}
+
+// CreateOrder godoc
+// @Summary Create a purchase order
+// @Description Place an order with a supplier. Lines without a unit cost take the product's cost price. The number is
+// @Description the next in the current fiscal year's purchase series. Product names are copied in the order's locale
+// @Description where the product is translated into it. The total is rounded by the tenant's purchase rounding rule,
+// @Description with the difference as roundOff.
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Param order body PurchaseOrderRequest true "Purchase order"
+// @Success 201 {object} domain.PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/purchase-orders [post]
+// @Security BearerAuth
+func (h *PurchaseOrderHandler) CreateOrder(c echo.Context) error {
+	var req PurchaseOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	orderDate, err := parseDate(req.OrderDate)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid orderDate, expected YYYY-MM-DD"})
+	}
+
+	order := domain.NewPurchaseOrder(tenantID, req.SupplierID, orderDate)
+	if req.ExpectedDate != "" {
+		expected, err := time.ParseInLocation("2006-01-02", req.ExpectedDate, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid expectedDate, expected YYYY-MM-DD"})
+		}
+		order.ExpectedDate = &expected
+	}
+	order.Note = req.Note
+	order.Locale = req.Locale
+	order.CreatedBy = optionalUserID(c)
+
+	lines := make([]service.OrderLineInput, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, service.OrderLineInput{
+			ProductID: line.ProductID,
+			Quantity:  line.Quantity,
+			UnitCost:  line.UnitCost,
+		})
+	}
+
+	if err := purchasing.PurchaseOrderService.CreateOrder(c.Request().Context(), order, lines); err != nil {
+		return purchaseOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, order)
+}
+
+// ListOrders godoc
+// @Summary List purchase orders
+// @Description Get purchase orders, newest first, optionally for a supplier or a status
+// @Tags purchasing
+// @Produce json
+// @Param supplierId query string false "Supplier ID"
+// @Param status query string false "open, partially_received, received or cancelled"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/purchase-orders [get]
+// @Security BearerAuth
+func (h *PurchaseOrderHandler) ListOrders(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	filter := domain.PurchaseOrderFilter{}
+	filter.Limit, filter.Offset = page(c)
+
+	if value := c.QueryParam("supplierId"); value != "" {
+		supplierID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid supplier ID"})
+		}
+		filter.SupplierID = &supplierID
+	}
+	if value := c.QueryParam("status"); value != "" {
+		status := domain.OrderStatus(value)
+		switch status {
+		case domain.OrderOpen, domain.OrderPartiallyReceived, domain.OrderReceived, domain.OrderCancelled:
+		default:
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+		}
+		filter.Status = &status
+	}
+
+	orders, err := purchasing.PurchaseOrderService.ListOrders(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return purchaseOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, orders)
+}
+
+// GetOrder godoc
+// @Summary Get a purchase order
+// @Description Get a purchase order with its lines and the quantities received on them
+// @Tags purchasing
+// @Produce json
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} domain.PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/purchase-orders/{id} [get]
+// @Security BearerAuth
+func (h *PurchaseOrderHandler) GetOrder(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid purchase order ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	order, err := purchasing.PurchaseOrderService.GetOrder(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return purchaseOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// CancelOrder godoc
+// @Summary Cancel a purchase order
+// @Description Withdraw an order nothing has been received against. The number stays used.
+// @Tags purchasing
+// @Produce json
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} domain.PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/purchase-orders/{id}/cancel [post]
+// @Security BearerAuth
+func (h *PurchaseOrderHandler) CancelOrder(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid purchase order ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	order, err := purchasing.PurchaseOrderService.CancelOrder(c.Request().Context(), tenantID, id, optionalUserID(c))
+	if err != nil {
+		return purchaseOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// Receive godoc
+// @Summary Receive goods
+// @Description Record a goods receipt (GRN) against an order, up to what is outstanding on each line. Lines without a
+// @Description unit cost are received at the ordered cost; each product's cost price becomes its received cost.
+// @Description Goods go into the given inventory warehouse, or the default one.
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Param id path string true "Purchase order ID"
+// @Param receipt body GoodsReceiptRequest true "Goods receipt"
+// @Success 201 {object} domain.GoodsReceipt
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/purchase-orders/{id}/receipts [post]
+// @Security BearerAuth
+func (h *PurchaseOrderHandler) Receive(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid purchase order ID"})
+	}
+
+	var req GoodsReceiptRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	receivedAt, err := parseDate(req.ReceivedAt)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid receivedAt, expected YYYY-MM-DD"})
+	}
+
+	receipt := domain.NewGoodsReceipt(tenantID, receivedAt)
+	receipt.WarehouseID = req.WarehouseID
+	receipt.SupplierRef = req.SupplierRef
+	receipt.Note = req.Note
+	receipt.CreatedBy = optionalUserID(c)
+	for _, line := range req.Lines {
+		if err := receipt.AddLine(line.OrderLineID, line.Quantity, line.UnitCost); err != nil {
+			return purchaseOrderError(c, err)
+		}
+	}
+
+	if err := purchasing.PurchaseOrderService.Receive(c.Request().Context(), id, receipt); err != nil {
+		return purchaseOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, receipt)
+}
+
+// ListReceipts godoc
+// @Summary List goods receipts
+// @Description Get goods receipts, newest first, optionally for an order or a supplier
+// @Tags purchasing
+// @Produce json
+// @Param orderId query string false "Purchase order ID"
+// @Param supplierId query string false "Supplier ID"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.GoodsReceipt
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/goods-receipts [get]
+// @Security BearerAuth
+func (h *PurchaseOrderHandler) ListReceipts(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	filter := domain.GoodsReceiptFilter{}
+	filter.Limit, filter.Offset = page(c)
+
+	if value := c.QueryParam("orderId"); value != "" {
+		orderID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid purchase order ID"})
+		}
+		filter.OrderID = &orderID
+	}
+	if value := c.QueryParam("supplierId"); value != "" {
+		supplierID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid supplier ID"})
+		}
+		filter.SupplierID = &supplierID
+	}
+
+	receipts, err := purchasing.PurchaseOrderService.ListReceipts(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return purchaseOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, receipts)
+}
+
+// GetReceipt godoc
+// @Summary Get a goods receipt
+// @Description Get a goods receipt with its lines
+// @Tags purchasing
+// @Produce json
+// @Param id path string true "Goods receipt ID"
+// @Success 200 {object} domain.GoodsReceipt
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/goods-receipts/{id} [get]
+// @Security BearerAuth
+func (h *PurchaseOrderHandler) GetReceipt(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid goods receipt ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	receipt, err := purchasing.PurchaseOrderService.GetReceipt(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return purchaseOrderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, receipt)
+}
+
+// parseDate parses a YYYY-MM-DD date, today when empty
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local), nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// page reads limit (default 50) and offset from the query string
+func page(c echo.Context) (int, int) {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// optionalUserID returns the calling user, if known
+func optionalUserID(c echo.Context) *uuid.UUID {
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		return &userID
+	}
+	return nil
+}
+
+// purchaseOrderError maps purchasing domain errors to HTTP responses
+func purchaseOrderError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrPurchaseOrderNotFound), errors.Is(err, domain.ErrGoodsReceiptNotFound),
+		errors.Is(err, domain.ErrSupplierNotFound), errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrPurchaseOrderClosed), errors.Is(err, domain.ErrPurchaseOrderReceived),
+		errors.Is(err, domain.ErrReceiptExceedsOrder), errors.Is(err, domain.ErrSupplierBlocked), errors.Is(err, domain.ErrNoFiscalYear):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidPurchaseOrder), errors.Is(err, domain.ErrInvalidGoodsReceipt):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/aceextension/core/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes registers all purchasing routes
+func RegisterRoutes(e *echo.Echo) {
+	// Create handlers
+	purchaseOrderHandler := NewPurchaseOrderHandler()
+
+	// API v1 group
+	v1 := e.Group("/api/v1")
+
+	// Apply tenant middleware
+	v1.Use(middleware.TenantMiddleware)
+
+	// Purchase order routes
+	orders := v1.Group("/purchase-orders")
+	{
+		orders.POST("", purchaseOrderHandler.CreateOrder)
+		orders.GET("", purchaseOrderHandler.ListOrders)
+		orders.GET("/:id", purchaseOrderHandler.GetOrder)
+		orders.POST("/:id/cancel", purchaseOrderHandler.CancelOrder)
+		orders.POST("/:id/receipts", purchaseOrderHandler.Receive)
+	}
+
+	// Goods receipt routes
+	receipts := v1.Group("/goods-receipts")
+	{
+		receipts.GET("", purchaseOrderHandler.ListReceipts)
+		receipts.GET("/:id", purchaseOrderHandler.GetReceipt)
+	}
+}
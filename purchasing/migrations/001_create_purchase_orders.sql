@@ -0,0 +1,120 @@
+-- Purchasing Module: Purchase Orders and Goods Receipts
+-- Migration: 001_create_purchase_orders.sql
+-- Orders placed with suppliers, numbered from the fiscal year's purchase series, and
+-- the goods receipts (GRNs) recording what arrived against them.
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    fiscal_year_id UUID NOT NULL REFERENCES fiscal_years(id),
+    order_number VARCHAR(50) NOT NULL,               -- PUR-8283-0001
+    supplier_id UUID NOT NULL REFERENCES suppliers(id),
+    supplier_name VARCHAR(255) NOT NULL,
+    order_date DATE NOT NULL,
+    expected_date DATE,                              -- Delivery date promised by the supplier
+    status VARCHAR(20) NOT NULL DEFAULT 'open',      -- open, partially_received, received, cancelled
+    total_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,  -- Before VAT
+    note TEXT,
+    cancelled_at TIMESTAMP,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_purchase_orders_number UNIQUE (tenant_id, order_number),
+    CONSTRAINT chk_purchase_orders_status CHECK (status IN ('open', 'partially_received', 'received', 'cancelled')),
+    CONSTRAINT chk_purchase_orders_expected CHECK (expected_date IS NULL OR expected_date >= order_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_date ON purchase_orders(tenant_id, order_date DESC);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier ON purchase_orders(tenant_id, supplier_id, order_date DESC);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_open ON purchase_orders(tenant_id, expected_date)
+    WHERE status IN ('open', 'partially_received');
+
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    order_id UUID NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    line_no INTEGER NOT NULL,
+    product_id UUID NOT NULL,
+    product_code VARCHAR(50) NOT NULL DEFAULT '',
+    product_name VARCHAR(255) NOT NULL,
+    unit VARCHAR(20) NOT NULL DEFAULT '',
+    quantity DECIMAL(15, 3) NOT NULL,
+    unit_cost DECIMAL(15, 2) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    received_qty DECIMAL(15, 3) NOT NULL DEFAULT 0,
+    CONSTRAINT uq_purchase_order_lines_no UNIQUE (order_id, line_no),
+    CONSTRAINT chk_purchase_order_lines_qty CHECK (quantity > 0 AND unit_cost >= 0 AND received_qty >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_order ON purchase_order_lines(order_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_product ON purchase_order_lines(tenant_id, product_id);
+
+CREATE TABLE IF NOT EXISTS goods_receipts (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    receipt_number VARCHAR(50) NOT NULL,             -- GRN-00001
+    order_id UUID NOT NULL REFERENCES purchase_orders(id),
+    order_number VARCHAR(50) NOT NULL,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id),
+    received_at DATE NOT NULL,
+    supplier_ref VARCHAR(100),                       -- Supplier's delivery note or challan number
+    note TEXT,
+    total_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,  -- Before VAT
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_goods_receipts_number UNIQUE (tenant_id, receipt_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goods_receipts_order ON goods_receipts(order_id);
+CREATE INDEX IF NOT EXISTS idx_goods_receipts_supplier ON goods_receipts(tenant_id, supplier_id, received_at DESC);
+
+CREATE TABLE IF NOT EXISTS goods_receipt_lines (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    receipt_id UUID NOT NULL REFERENCES goods_receipts(id) ON DELETE CASCADE,
+    order_line_id UUID NOT NULL REFERENCES purchase_order_lines(id),
+    product_id UUID NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    quantity DECIMAL(15, 3) NOT NULL,
+    unit_cost DECIMAL(15, 2) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    CONSTRAINT chk_goods_receipt_lines_qty CHECK (quantity > 0 AND unit_cost >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goods_receipt_lines_receipt ON goods_receipt_lines(receipt_id);
+CREATE INDEX IF NOT EXISTS idx_goods_receipt_lines_order_line ON goods_receipt_lines(order_line_id);
+
+COMMENT ON TABLE purchase_orders IS 'Orders placed with suppliers, numbered from the fiscal year purchase series';
+COMMENT ON COLUMN purchase_orders.expected_date IS 'Promised delivery date; supplier scorecards measure on-time delivery against it';
+COMMENT ON COLUMN purchase_order_lines.received_qty IS 'Sum of goods receipt lines against this line';
+COMMENT ON TABLE goods_receipts IS 'Goods received notes (GRNs) against purchase orders';
+COMMENT ON COLUMN goods_receipt_lines.unit_cost IS 'Cost received at, before VAT; becomes the product cost price';
+
+ALTER TABLE purchase_orders ENABLE ROW LEVEL SECURITY;
+ALTER TABLE purchase_order_lines ENABLE ROW LEVEL SECURITY;
+ALTER TABLE goods_receipts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE goods_receipt_lines ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON purchase_orders
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON purchase_order_lines
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON goods_receipts
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON goods_receipt_lines
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
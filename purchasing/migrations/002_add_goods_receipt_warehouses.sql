@@ -0,0 +1,8 @@
+-- Purchasing Module: Goods Receipt Warehouses
+-- Migration: 002_add_goods_receipt_warehouses.sql
+-- Tenants with several outlets receive goods where they are needed. A receipt without
+-- a warehouse takes its goods into the tenant's default inventory warehouse.
+
+ALTER TABLE goods_receipts ADD COLUMN IF NOT EXISTS warehouse_id UUID;
+
+COMMENT ON COLUMN goods_receipts.warehouse_id IS 'Inventory warehouse the goods were received into; NULL for the default warehouse';
@@ -0,0 +1,8 @@
+-- Purchasing Module: Purchase Order Locale
+-- Migration: 003_add_purchase_order_locale.sql
+-- Product names on an order are copied in the language it is printed in, where the
+-- product is translated into it. Orders without one carry the catalog's own names.
+
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS locale VARCHAR(3);
+
+COMMENT ON COLUMN purchase_orders.locale IS 'Language product names were copied in; NULL for the catalog''s own names';
@@ -0,0 +1,8 @@
+-- Purchasing Module: Purchase Order Round-off
+-- Migration: 004_add_purchase_order_round_off.sql
+-- Order totals are rounded by the tenant's purchase rounding rule; the difference
+-- is shown on the order.
+
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS round_off DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN purchase_orders.round_off IS 'Added to the lines'' total to reach total_amount; negative when rounded down';
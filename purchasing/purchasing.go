@@ -0,0 +1,205 @@
+package purchasing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/catalog"
+	catalogDomain "github.com/aceextension/catalog/domain"
+	"github.com/aceextension/crm"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/fiscal"
+	fiscalDomain "github.com/aceextension/fiscal/domain"
+	"github.com/aceextension/purchasing/domain"
+	"github.com/aceextension/purchasing/repository"
+	"github.com/aceextension/purchasing/service"
+	"github.com/google/uuid"
+)
+
+// ReceiptTypeGoodsReceipt is the purchase return receipt type of goods receipts
+const ReceiptTypeGoodsReceipt = "goods_receipt"
+
+// orderLinesSQL is one row per line of orders not cancelled, with what was received
+// against it, for supplier scorecards
+const orderLinesSQL = `
+	SELECT o.supplier_id, l.product_id, o.order_date AS ordered_on, o.expected_date AS expected_on,
+	       r.received_on, l.quantity AS ordered_qty, r.received_qty,
+	       l.unit_cost AS ordered_price, r.received_price
+	FROM purchase_orders o
+	JOIN purchase_order_lines l ON l.order_id = o.id
+	LEFT JOIN LATERAL (
+		SELECT MAX(g.received_at) AS received_on, SUM(gl.quantity) AS received_qty,
+		       SUM(gl.quantity * gl.unit_cost) / NULLIF(SUM(gl.quantity), 0) AS received_price
+		FROM goods_receipt_lines gl
+		JOIN goods_receipts g ON g.id = gl.receipt_id
+		WHERE gl.order_line_id = l.id
+	) r ON true
+	WHERE o.tenant_id = $1 AND o.status <> 'cancelled' AND o.order_date >= $2 AND o.order_date < $3`
+
+// Global service instances
+var (
+	PurchaseOrderService service.PurchaseOrderService
+)
+
+// Init initializes the purchasing module. Call fiscal.Init, catalog.Init and crm.Init first.
+func Init() {
+	orderRepo := repository.NewPostgresPurchaseOrderRepository()
+
+	PurchaseOrderService = service.NewPurchaseOrderService(orderRepo, crmSuppliers{}, catalogProducts{}, fiscalNumbers{}, fiscalRounding{})
+
+	// Goods can go back to the supplier against a receipt, and orders feed scorecards
+	if crm.PurchaseReturnService != nil {
+		crm.PurchaseReturnService.RegisterReceiptType(ReceiptTypeGoodsReceipt, goodsReceipts{repo: orderRepo})
+	}
+	if crm.SupplierScorecardService != nil {
+		crm.SupplierScorecardService.RegisterSource(crmDomain.SupplyLineSource{Name: "purchase_orders", LinesSQL: orderLinesSQL})
+	}
+}
+
+// crmSuppliers places orders with crm suppliers
+type crmSuppliers struct{}
+
+// Supplier returns the supplier's name and whether it is blocked
+func (crmSuppliers) Supplier(ctx context.Context, tenantID, supplierID uuid.UUID) (*domain.SupplierInfo, error) {
+	if crm.SupplierService == nil {
+		return nil, domain.ErrSupplierNotFound
+	}
+	supplier, err := crm.SupplierService.GetByID(ctx, tenantID, supplierID)
+	if err != nil {
+		return nil, domain.ErrSupplierNotFound
+	}
+
+	return &domain.SupplierInfo{
+		ID:      supplier.ID,
+		Name:    supplier.Name,
+		Blocked: supplier.Status == crmDomain.SupplierStatusBlocked,
+	}, nil
+}
+
+// catalogProducts orders catalog products and keeps their cost prices
+type catalogProducts struct{}
+
+// OrderLocale checks the requested language, or looks up the tenant's purchase order language
+func (catalogProducts) OrderLocale(ctx context.Context, tenantID uuid.UUID, requested *string) (string, error) {
+	if requested != nil {
+		locale, err := catalogDomain.NormalizeLocale(*requested)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", domain.ErrInvalidPurchaseOrder, err.Error())
+		}
+		return locale, nil
+	}
+	if catalog.LocalizationService == nil {
+		return "", nil
+	}
+	return catalog.LocalizationService.DocumentLocale(ctx, tenantID, catalogDomain.DocumentPurchaseOrder)
+}
+
+// OrderProduct returns the product's code, name in locale, unit and cost price
+func (catalogProducts) OrderProduct(ctx context.Context, tenantID, productID uuid.UUID, locale string) (*domain.OrderProduct, error) {
+	if catalog.ProductService == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	product, err := catalog.ProductService.GetByID(ctx, tenantID, productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrProductNotFound, productID)
+	}
+	if locale != "" && catalog.LocalizationService != nil {
+		if err := catalog.LocalizationService.LocalizeProducts(ctx, tenantID, []string{locale}, product); err != nil {
+			return nil, err
+		}
+	}
+
+	return &domain.OrderProduct{
+		ID:        product.ID,
+		Code:      product.ProductCode,
+		Name:      product.Name,
+		Unit:      product.Unit,
+		CostPrice: product.CostPrice,
+	}, nil
+}
+
+// SetCostPrice saves the product with the cost it was last received at
+func (catalogProducts) SetCostPrice(ctx context.Context, tenantID, productID uuid.UUID, cost float64) error {
+	product, err := catalog.ProductService.GetByID(ctx, tenantID, productID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrProductNotFound, productID)
+	}
+	if product.CostPrice == cost {
+		return nil
+	}
+	product.CostPrice = cost
+	product.UpdatedAt = time.Now()
+	return catalog.ProductService.Update(ctx, product)
+}
+
+// fiscalRounding rounds order totals by the tenant's purchase rounding rule
+type fiscalRounding struct{}
+
+// RoundTotal rounds an order total
+func (fiscalRounding) RoundTotal(ctx context.Context, tenantID uuid.UUID, total float64) (float64, error) {
+	rule, err := fiscal.RoundingRule(ctx, tenantID, fiscalDomain.DocumentPurchase)
+	if err != nil {
+		return 0, err
+	}
+	return rule.Round(total), nil
+}
+
+// fiscalNumbers numbers orders from the current fiscal year's purchase series
+type fiscalNumbers struct{}
+
+// Issue takes the next purchase number of the current fiscal year
+func (fiscalNumbers) Issue(ctx context.Context, tenantID uuid.UUID) (uuid.UUID, string, error) {
+	fy := fiscal.GetActiveFiscalYear(ctx, tenantID)
+	if fy == nil {
+		return uuid.Nil, "", domain.ErrNoFiscalYear
+	}
+	number, err := fiscal.Service.GeneratePurchaseNumber(ctx, tenantID, fy.ID)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("failed to issue purchase number: %w", err)
+	}
+	return fy.ID, number, nil
+}
+
+// Record attaches the order to its number in the number ledger
+func (fiscalNumbers) Record(ctx context.Context, order *domain.PurchaseOrder) error {
+	return fiscal.RecordDocument(ctx, order.TenantID, fiscalDomain.DocumentPurchase, order.OrderNumber, order.ID)
+}
+
+// goodsReceipts makes goods receipts returnable to the supplier
+type goodsReceipts struct {
+	repo repository.PurchaseOrderRepository
+}
+
+// ReturnableReceipt loads a goods receipt with its lines. Receipts are not payable;
+// the supplier is owed on their bill, so returns against a receipt carry no debit note.
+func (r goodsReceipts) ReturnableReceipt(ctx context.Context, tenantID, receiptID uuid.UUID) (*crmDomain.ReturnableReceipt, error) {
+	receipt, err := r.repo.GetReceipt(ctx, tenantID, receiptID)
+	if err != nil {
+		return nil, crmDomain.ErrPurchaseReceiptNotFound
+	}
+
+	returnable := &crmDomain.ReturnableReceipt{
+		Type:       ReceiptTypeGoodsReceipt,
+		ID:         receipt.ID,
+		SupplierID: receipt.SupplierID,
+		Number:     receipt.ReceiptNumber,
+		Amount:     receipt.TotalAmount,
+		Lines:      make([]crmDomain.ReturnableLine, 0, len(receipt.Lines)),
+	}
+	for _, line := range receipt.Lines {
+		returnable.Lines = append(returnable.Lines, crmDomain.ReturnableLine{
+			LineID:    line.ID,
+			ProductID: line.ProductID,
+			Quantity:  line.Quantity,
+			UnitCost:  *line.UnitCost,
+		})
+	}
+	return returnable, nil
+}
+
+// PurchasedBySupplier reports nothing: purchases are measured from supplier bills,
+// which follow the goods, and counting receipts too would count them twice
+func (goodsReceipts) PurchasedBySupplier(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (map[uuid.UUID]float64, error) {
+	return map[uuid.UUID]float64{}, nil
+}
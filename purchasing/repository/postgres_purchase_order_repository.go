@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/purchasing/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresPurchaseOrderRepository implements PurchaseOrderRepository using PostgreSQL
+type PostgresPurchaseOrderRepository struct{}
+
+// NewPostgresPurchaseOrderRepository creates a new PostgreSQL purchase order repository
+func NewPostgresPurchaseOrderRepository() *PostgresPurchaseOrderRepository {
+	return &PostgresPurchaseOrderRepository{}
+}
+
+const purchaseOrderColumns = `id, tenant_id, fiscal_year_id, order_number, supplier_id, supplier_name,
+	order_date, expected_date, status, total_amount, note, cancelled_at, created_by, created_at, updated_at, locale, round_off`
+
+const purchaseOrderLineColumns = `id, order_id, line_no, product_id, product_code, product_name, unit,
+	quantity, unit_cost, amount, received_qty`
+
+const goodsReceiptColumns = `id, tenant_id, receipt_number, order_id, order_number, supplier_id,
+	warehouse_id, received_at, supplier_ref, note, total_amount, created_by, created_at`
+
+// CreateOrder creates an order and its lines
+func (r *PostgresPurchaseOrderRepository) CreateOrder(ctx context.Context, order *domain.PurchaseOrder) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO purchase_orders (`+purchaseOrderColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		`,
+			order.ID, order.TenantID, order.FiscalYearID, order.OrderNumber, order.SupplierID, order.SupplierName,
+			order.OrderDate, order.ExpectedDate, order.Status, order.TotalAmount, order.Note, order.CancelledAt,
+			order.CreatedBy, order.CreatedAt, order.UpdatedAt, order.Locale, order.RoundOff,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create purchase order: %w", err)
+		}
+
+		for _, line := range order.Lines {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO purchase_order_lines (tenant_id, `+purchaseOrderLineColumns+`)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			`,
+				order.TenantID, line.ID, order.ID, line.LineNo, line.ProductID, line.ProductCode, line.ProductName, line.Unit,
+				line.Quantity, line.UnitCost, line.Amount, line.ReceivedQty,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create purchase order line: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetOrder retrieves an order with its lines
+func (r *PostgresPurchaseOrderRepository) GetOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.PurchaseOrder, error) {
+	query := `SELECT ` + purchaseOrderColumns + ` FROM purchase_orders WHERE tenant_id = $1 AND id = $2`
+
+	order, err := scanPurchaseOrder(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPurchaseOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get purchase order: %w", err)
+	}
+
+	if order.Lines, err = orderLines(ctx, db.MainPool, order.ID); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ListOrders retrieves orders (without lines), newest first
+func (r *PostgresPurchaseOrderRepository) ListOrders(ctx context.Context, tenantID uuid.UUID, filter domain.PurchaseOrderFilter) ([]*domain.PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR supplier_id = $2)
+		  AND ($3::varchar IS NULL OR status = $3)
+		ORDER BY order_date DESC, order_number DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.SupplierID, filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchase orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []*domain.PurchaseOrder{}
+	for rows.Next() {
+		order, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan purchase order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// SaveCancel marks an open order cancelled
+func (r *PostgresPurchaseOrderRepository) SaveCancel(ctx context.Context, order *domain.PurchaseOrder) error {
+	tag, err := db.MainPool.Exec(ctx, `
+		UPDATE purchase_orders
+		SET status = $3, cancelled_at = $4, updated_at = $5
+		WHERE tenant_id = $1 AND id = $2 AND status = 'open'
+	`, order.TenantID, order.ID, order.Status, order.CancelledAt, order.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to cancel purchase order: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPurchaseOrderClosed
+	}
+	return nil
+}
+
+// CreateReceipt receives goods against a locked order and saves the receipt
+func (r *PostgresPurchaseOrderRepository) CreateReceipt(ctx context.Context, receipt *domain.GoodsReceipt, orderID uuid.UUID, receive ReceiveFunc) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		// Lock the order so concurrent receipts cannot both fit in what is outstanding
+		query := `SELECT ` + purchaseOrderColumns + ` FROM purchase_orders WHERE tenant_id = $1 AND id = $2 FOR UPDATE`
+		order, err := scanPurchaseOrder(tx.QueryRow(ctx, query, receipt.TenantID, orderID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrPurchaseOrderNotFound
+			}
+			return fmt.Errorf("failed to lock purchase order: %w", err)
+		}
+		if order.Lines, err = orderLines(ctx, tx, order.ID); err != nil {
+			return err
+		}
+
+		if err := receive(order); err != nil {
+			return err
+		}
+
+		// Receipts are numbered per tenant; the order lock does not cover other orders
+		_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "goods_receipts|"+receipt.TenantID.String())
+		if err != nil {
+			return fmt.Errorf("failed to lock goods receipt numbering: %w", err)
+		}
+		var next int64
+		err = tx.QueryRow(ctx,
+			`SELECT COUNT(*) + 1 FROM goods_receipts WHERE tenant_id = $1`, receipt.TenantID,
+		).Scan(&next)
+		if err != nil {
+			return fmt.Errorf("failed to number goods receipt: %w", err)
+		}
+		receipt.ReceiptNumber = fmt.Sprintf("GRN-%05d", next)
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO goods_receipts (`+goodsReceiptColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		`,
+			receipt.ID, receipt.TenantID, receipt.ReceiptNumber, receipt.OrderID, receipt.OrderNumber, receipt.SupplierID,
+			receipt.WarehouseID, receipt.ReceivedAt, receipt.SupplierRef, receipt.Note, receipt.TotalAmount, receipt.CreatedBy, receipt.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create goods receipt: %w", err)
+		}
+
+		for _, line := range receipt.Lines {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO goods_receipt_lines (
+					id, tenant_id, receipt_id, order_line_id, product_id, product_name, quantity, unit_cost, amount
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			`,
+				line.ID, receipt.TenantID, receipt.ID, line.OrderLineID, line.ProductID, line.ProductName,
+				line.Quantity, line.UnitCost, line.Amount,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create goods receipt line: %w", err)
+			}
+		}
+
+		for _, line := range order.Lines {
+			_, err := tx.Exec(ctx,
+				`UPDATE purchase_order_lines SET received_qty = $2 WHERE id = $1`, line.ID, line.ReceivedQty,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to update received quantity: %w", err)
+			}
+		}
+
+		_, err = tx.Exec(ctx,
+			`UPDATE purchase_orders SET status = $2, updated_at = $3 WHERE id = $1`, order.ID, order.Status, order.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update purchase order status: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetReceipt retrieves a goods receipt with its lines
+func (r *PostgresPurchaseOrderRepository) GetReceipt(ctx context.Context, tenantID, id uuid.UUID) (*domain.GoodsReceipt, error) {
+	query := `SELECT ` + goodsReceiptColumns + ` FROM goods_receipts WHERE tenant_id = $1 AND id = $2`
+
+	receipt, err := scanGoodsReceipt(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrGoodsReceiptNotFound
+		}
+		return nil, fmt.Errorf("failed to get goods receipt: %w", err)
+	}
+
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT id, receipt_id, order_line_id, product_id, product_name, quantity, unit_cost, amount
+		FROM goods_receipt_lines
+		WHERE receipt_id = $1
+		ORDER BY product_name, id
+	`, receipt.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goods receipt lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line domain.GoodsReceiptLine
+		if err := rows.Scan(
+			&line.ID, &line.ReceiptID, &line.OrderLineID, &line.ProductID, &line.ProductName,
+			&line.Quantity, &line.UnitCost, &line.Amount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan goods receipt line: %w", err)
+		}
+		receipt.Lines = append(receipt.Lines, line)
+	}
+
+	return receipt, rows.Err()
+}
+
+// ListReceipts retrieves receipts (without lines), newest first
+func (r *PostgresPurchaseOrderRepository) ListReceipts(ctx context.Context, tenantID uuid.UUID, filter domain.GoodsReceiptFilter) ([]*domain.GoodsReceipt, error) {
+	query := `
+		SELECT ` + goodsReceiptColumns + `
+		FROM goods_receipts
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR order_id = $2)
+		  AND ($3::uuid IS NULL OR supplier_id = $3)
+		ORDER BY received_at DESC, receipt_number DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.OrderID, filter.SupplierID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goods receipts: %w", err)
+	}
+	defer rows.Close()
+
+	receipts := []*domain.GoodsReceipt{}
+	for rows.Next() {
+		receipt, err := scanGoodsReceipt(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan goods receipt: %w", err)
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, rows.Err()
+}
+
+// querier is satisfied by the pool and by a transaction
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// orderLines loads an order's lines in line order
+func orderLines(ctx context.Context, q querier, orderID uuid.UUID) ([]domain.PurchaseOrderLine, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+purchaseOrderLineColumns+`
+		FROM purchase_order_lines
+		WHERE order_id = $1
+		ORDER BY line_no
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchase order lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.PurchaseOrderLine{}
+	for rows.Next() {
+		var line domain.PurchaseOrderLine
+		if err := rows.Scan(
+			&line.ID, &line.OrderID, &line.LineNo, &line.ProductID, &line.ProductCode, &line.ProductName, &line.Unit,
+			&line.Quantity, &line.UnitCost, &line.Amount, &line.ReceivedQty,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase order line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// scanPurchaseOrder scans a purchase_orders row in purchaseOrderColumns order
+func scanPurchaseOrder(row pgx.Row) (*domain.PurchaseOrder, error) {
+	order := domain.PurchaseOrder{Lines: []domain.PurchaseOrderLine{}}
+	err := row.Scan(
+		&order.ID, &order.TenantID, &order.FiscalYearID, &order.OrderNumber, &order.SupplierID, &order.SupplierName,
+		&order.OrderDate, &order.ExpectedDate, &order.Status, &order.TotalAmount, &order.Note, &order.CancelledAt,
+		&order.CreatedBy, &order.CreatedAt, &order.UpdatedAt, &order.Locale, &order.RoundOff,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// scanGoodsReceipt scans a goods_receipts row in goodsReceiptColumns order
+func scanGoodsReceipt(row pgx.Row) (*domain.GoodsReceipt, error) {
+	receipt := domain.GoodsReceipt{Lines: []domain.GoodsReceiptLine{}}
+	err := row.Scan(
+		&receipt.ID, &receipt.TenantID, &receipt.ReceiptNumber, &receipt.OrderID, &receipt.OrderNumber, &receipt.SupplierID,
+		&receipt.WarehouseID, &receipt.ReceivedAt, &receipt.SupplierRef, &receipt.Note, &receipt.TotalAmount, &receipt.CreatedBy, &receipt.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/purchasing/domain"
+	"github.com/google/uuid"
+)
+
+// ReceiveFunc checks a receipt against the order it is for and applies it to the
+// order's lines. It runs with the order locked.
+type ReceiveFunc func(order *domain.PurchaseOrder) error
+
+// PurchaseOrderRepository defines the interface for purchase order and goods receipt data access
+type PurchaseOrderRepository interface {
+	// CreateOrder saves an order with its lines; the number is issued beforehand
+	CreateOrder(ctx context.Context, order *domain.PurchaseOrder) error
+	GetOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.PurchaseOrder, error)
+	// ListOrders retrieves orders (without lines), newest first
+	ListOrders(ctx context.Context, tenantID uuid.UUID, filter domain.PurchaseOrderFilter) ([]*domain.PurchaseOrder, error)
+	// SaveCancel saves a cancellation; ErrPurchaseOrderClosed if the order stopped being open concurrently
+	SaveCancel(ctx context.Context, order *domain.PurchaseOrder) error
+
+	// CreateReceipt locks the receipt's order, runs receive on it, then numbers and
+	// saves the receipt and the order's received quantities together
+	CreateReceipt(ctx context.Context, receipt *domain.GoodsReceipt, orderID uuid.UUID, receive ReceiveFunc) error
+	GetReceipt(ctx context.Context, tenantID, id uuid.UUID) (*domain.GoodsReceipt, error)
+	// ListReceipts retrieves receipts (without lines), newest first
+	ListReceipts(ctx context.Context, tenantID uuid.UUID, filter domain.GoodsReceiptFilter) ([]*domain.GoodsReceipt, error)
+}
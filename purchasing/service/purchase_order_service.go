@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/purchasing/domain"
+	"github.com/aceextension/purchasing/repository"
+	"github.com/google/uuid"
+)
+
+// PurchaseOrderService defines the interface for purchase orders and goods receipts
+type PurchaseOrderService interface {
+	// CreateOrder costs the lines from the catalog where no cost is given, rounds
+	// the total, numbers the order from the current fiscal year and saves it
+	CreateOrder(ctx context.Context, order *domain.PurchaseOrder, lines []OrderLineInput) error
+	GetOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.PurchaseOrder, error)
+	ListOrders(ctx context.Context, tenantID uuid.UUID, filter domain.PurchaseOrderFilter) ([]*domain.PurchaseOrder, error)
+	// CancelOrder withdraws an order nothing has been received against; its number stays used
+	CancelOrder(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*domain.PurchaseOrder, error)
+
+	// Receive records goods arriving against an order. The products' cost prices
+	// follow the received costs and the goods are taken into stock.
+	Receive(ctx context.Context, orderID uuid.UUID, receipt *domain.GoodsReceipt) error
+	GetReceipt(ctx context.Context, tenantID, id uuid.UUID) (*domain.GoodsReceipt, error)
+	ListReceipts(ctx context.Context, tenantID uuid.UUID, filter domain.GoodsReceiptFilter) ([]*domain.GoodsReceipt, error)
+
+	SetStock(stock GoodsStock)
+}
+
+// OrderLineInput is a product ordered. Without a unit cost the product's cost price applies.
+type OrderLineInput struct {
+	ProductID uuid.UUID
+	Quantity  float64
+	UnitCost  *float64
+}
+
+// SupplierDirectory finds who an order is placed with. Init uses crm suppliers.
+type SupplierDirectory interface {
+	// Supplier returns ErrSupplierNotFound if the tenant has no such supplier
+	Supplier(ctx context.Context, tenantID, supplierID uuid.UUID) (*domain.SupplierInfo, error)
+}
+
+// ProductCatalog looks up ordered products and keeps their cost prices. Init uses catalog products.
+type ProductCatalog interface {
+	// OrderLocale returns the language to copy product names in: the requested one, or
+	// the tenant's purchase order language; empty for the catalog's own names
+	OrderLocale(ctx context.Context, tenantID uuid.UUID, requested *string) (string, error)
+	// OrderProduct returns ErrProductNotFound if the tenant has no such product. The name
+	// is in locale where the product is translated into it.
+	OrderProduct(ctx context.Context, tenantID, productID uuid.UUID, locale string) (*domain.OrderProduct, error)
+	// SetCostPrice records the cost a product was last received at
+	SetCostPrice(ctx context.Context, tenantID, productID uuid.UUID, cost float64) error
+}
+
+// PurchaseNumbers issues order numbers. Init uses the fiscal year's purchase series.
+type PurchaseNumbers interface {
+	// Issue takes the next number of the tenant's current fiscal year; ErrNoFiscalYear without one
+	Issue(ctx context.Context, tenantID uuid.UUID) (uuid.UUID, string, error)
+	// Record marks the number used by the saved order
+	Record(ctx context.Context, order *domain.PurchaseOrder) error
+}
+
+// PurchaseRounding rounds order totals. Init uses the tenant's fiscal rounding rule for purchases.
+type PurchaseRounding interface {
+	// RoundTotal returns an order total rounded by the tenant's rule
+	RoundTotal(ctx context.Context, tenantID uuid.UUID, total float64) (float64, error)
+}
+
+// GoodsStock takes received goods into stock. Implemented by the inventory side
+// and set after Init.
+type GoodsStock interface {
+	// CheckWarehouse checks the tenant has the active warehouse goods are received into
+	CheckWarehouse(ctx context.Context, tenantID, warehouseID uuid.UUID) error
+	ReceiveGoods(ctx context.Context, receipt *domain.GoodsReceipt) error
+}
+
+// purchaseOrderService implements PurchaseOrderService
+type purchaseOrderService struct {
+	repo      repository.PurchaseOrderRepository
+	suppliers SupplierDirectory
+	products  ProductCatalog
+	numbers   PurchaseNumbers
+	rounding  PurchaseRounding
+	stock     GoodsStock
+}
+
+// NewPurchaseOrderService creates a new purchase order service
+func NewPurchaseOrderService(repo repository.PurchaseOrderRepository, suppliers SupplierDirectory, products ProductCatalog, numbers PurchaseNumbers, rounding PurchaseRounding) PurchaseOrderService {
+	return &purchaseOrderService{
+		repo:      repo,
+		suppliers: suppliers,
+		products:  products,
+		numbers:   numbers,
+		rounding:  rounding,
+	}
+}
+
+// SetStock sets where received goods are taken into stock
+func (s *purchaseOrderService) SetStock(stock GoodsStock) {
+	s.stock = stock
+}
+
+// CreateOrder builds, numbers and saves an order
+func (s *purchaseOrderService) CreateOrder(ctx context.Context, order *domain.PurchaseOrder, lines []OrderLineInput) error {
+	supplier, err := s.suppliers.Supplier(ctx, order.TenantID, order.SupplierID)
+	if err != nil {
+		return err
+	}
+	if supplier.Blocked {
+		return domain.ErrSupplierBlocked
+	}
+	order.SupplierName = supplier.Name
+
+	locale, err := s.products.OrderLocale(ctx, order.TenantID, order.Locale)
+	if err != nil {
+		return err
+	}
+	order.Locale = nil
+	if locale != "" {
+		order.Locale = &locale
+	}
+
+	for _, input := range lines {
+		product, err := s.products.OrderProduct(ctx, order.TenantID, input.ProductID, locale)
+		if err != nil {
+			return err
+		}
+
+		cost := product.CostPrice
+		if input.UnitCost != nil {
+			cost = *input.UnitCost
+		}
+		line, err := order.AddLine(product.ID, input.Quantity, cost)
+		if err != nil {
+			return err
+		}
+		line.ProductCode, line.ProductName, line.Unit = product.Code, product.Name, product.Unit
+	}
+	if err := order.Calculate(); err != nil {
+		return err
+	}
+	rounded, err := s.rounding.RoundTotal(ctx, order.TenantID, order.TotalAmount)
+	if err != nil {
+		return fmt.Errorf("failed to round order total: %w", err)
+	}
+	order.RoundTotal(rounded)
+
+	// The number is taken before saving; if the save fails it shows as unused in
+	// the number ledger rather than leaving a gap
+	fiscalYearID, number, err := s.numbers.Issue(ctx, order.TenantID)
+	if err != nil {
+		return err
+	}
+	order.FiscalYearID, order.OrderNumber = fiscalYearID, number
+
+	if err := s.repo.CreateOrder(ctx, order); err != nil {
+		return err
+	}
+
+	if err := s.numbers.Record(ctx, order); err != nil {
+		logger.Log.Error(fmt.Sprintf("Failed to record purchase order %s in the number ledger: %v", order.OrderNumber, err))
+	}
+	s.auditOrder(ctx, "CREATE_PURCHASE_ORDER", order, order.CreatedBy)
+	return nil
+}
+
+// GetOrder retrieves an order with its lines
+func (s *purchaseOrderService) GetOrder(ctx context.Context, tenantID, id uuid.UUID) (*domain.PurchaseOrder, error) {
+	return s.repo.GetOrder(ctx, tenantID, id)
+}
+
+// ListOrders returns orders, newest first
+func (s *purchaseOrderService) ListOrders(ctx context.Context, tenantID uuid.UUID, filter domain.PurchaseOrderFilter) ([]*domain.PurchaseOrder, error) {
+	return s.repo.ListOrders(ctx, tenantID, filter)
+}
+
+// CancelOrder cancels an open order
+func (s *purchaseOrderService) CancelOrder(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*domain.PurchaseOrder, error) {
+	order, err := s.repo.GetOrder(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := order.Cancel(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveCancel(ctx, order); err != nil {
+		return nil, err
+	}
+
+	s.auditOrder(ctx, "CANCEL_PURCHASE_ORDER", order, userID)
+	return order, nil
+}
+
+// Receive saves a goods receipt against its order, then updates cost prices and stock
+func (s *purchaseOrderService) Receive(ctx context.Context, orderID uuid.UUID, receipt *domain.GoodsReceipt) error {
+	if receipt.WarehouseID != nil {
+		if s.stock == nil {
+			return fmt.Errorf("%w: warehouses are not available", domain.ErrInvalidGoodsReceipt)
+		}
+		if err := s.stock.CheckWarehouse(ctx, receipt.TenantID, *receipt.WarehouseID); err != nil {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidGoodsReceipt, err.Error())
+		}
+	}
+
+	err := s.repo.CreateReceipt(ctx, receipt, orderID, func(order *domain.PurchaseOrder) error {
+		return order.Receive(receipt)
+	})
+	if err != nil {
+		return err
+	}
+
+	// The receipt is committed; follow-up failures are logged rather than undoing it
+	for _, line := range receipt.Lines {
+		if err := s.products.SetCostPrice(ctx, receipt.TenantID, line.ProductID, *line.UnitCost); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to update cost price of %s from %s: %v", line.ProductName, receipt.ReceiptNumber, err))
+		}
+	}
+	if s.stock != nil {
+		if err := s.stock.ReceiveGoods(ctx, receipt); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to take %s into stock: %v", receipt.ReceiptNumber, err))
+		}
+	}
+
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   receipt.CreatedBy,
+		TenantID: &receipt.TenantID,
+	}
+	entityIDStr := receipt.ID.String()
+	audit.Service.Log(ctx, "RECEIVE_GOODS", "GoodsReceipt", &entityIDStr, map[string]interface{}{
+		"receipt_number": receipt.ReceiptNumber,
+		"order_id":       receipt.OrderID,
+		"order_number":   receipt.OrderNumber,
+		"supplier_id":    receipt.SupplierID,
+		"warehouse_id":   receipt.WarehouseID,
+		"total_amount":   receipt.TotalAmount,
+	}, auditCtx)
+	return nil
+}
+
+// GetReceipt retrieves a goods receipt with its lines
+func (s *purchaseOrderService) GetReceipt(ctx context.Context, tenantID, id uuid.UUID) (*domain.GoodsReceipt, error) {
+	return s.repo.GetReceipt(ctx, tenantID, id)
+}
+
+// ListReceipts returns goods receipts, newest first
+func (s *purchaseOrderService) ListReceipts(ctx context.Context, tenantID uuid.UUID, filter domain.GoodsReceiptFilter) ([]*domain.GoodsReceipt, error) {
+	return s.repo.ListReceipts(ctx, tenantID, filter)
+}
+
+func (s *purchaseOrderService) auditOrder(ctx context.Context, action string, order *domain.PurchaseOrder, userID *uuid.UUID) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &order.TenantID,
+	}
+
+	entityIDStr := order.ID.String()
+	audit.Service.Log(ctx, action, "PurchaseOrder", &entityIDStr, map[string]interface{}{
+		"order_number":  order.OrderNumber,
+		"supplier_id":   order.SupplierID,
+		"expected_date": order.ExpectedDate,
+		"total_amount":  order.TotalAmount,
+		"status":        order.Status,
+	}, auditCtx)
+}
@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidInvoiceSend is returned for a send with no or unknown channels or share mode
+	ErrInvalidInvoiceSend = errors.New("invalid invoice send")
+	// ErrNoRecipient is returned when a channel has no address to send to: none was given
+	// and the customer has none on file
+	ErrNoRecipient = errors.New("no recipient")
+	// ErrShareLinksDisabled is returned when sending or opening a link with no signing secret configured
+	ErrShareLinksDisabled = errors.New("invoice links are not configured")
+	// ErrInvalidShareLink is returned for a link that is malformed, tampered with or expired
+	ErrInvalidShareLink = errors.New("invoice link is invalid or has expired")
+)
+
+// InvoiceSendTemplate is the notification template code an invoice is sent with, per
+// channel. Without one the built-in message is sent. Variables: invoiceNumber,
+// invoiceDate, buyerName, totalAmount, link, linkExpiresAt and message.
+const InvoiceSendTemplate = "INVOICE_SEND"
+
+// InvoiceReference is the reference type invoice messages carry in the communication history
+const InvoiceReference = "INVOICE"
+
+// SendChannel is how an invoice reaches the customer
+type SendChannel string
+
+const (
+	SendEmail SendChannel = "email"
+	SendSMS   SendChannel = "sms"
+)
+
+// ShareMode is what the message links to
+type ShareMode string
+
+const (
+	ShareView ShareMode = "link" // The invoice's public page in the web app
+	SharePDF  ShareMode = "pdf"  // The invoice PDF itself
+)
+
+// InvoiceSend is a request to send an invoice to its customer
+type InvoiceSend struct {
+	Channels []SendChannel
+	Share    ShareMode // Defaults to the public page
+	Email    *string   // Overrides the customer's email
+	Phone    *string   // Overrides the customer's phone
+	Message  string    // Added to the message, e.g. a thank-you note
+}
+
+// Normalize checks the channels and share mode and trims the addresses
+func (s *InvoiceSend) Normalize() error {
+	if len(s.Channels) == 0 {
+		return fmt.Errorf("%w: choose email, sms or both", ErrInvalidInvoiceSend)
+	}
+	seen := map[SendChannel]bool{}
+	channels := make([]SendChannel, 0, len(s.Channels))
+	for _, channel := range s.Channels {
+		channel = SendChannel(strings.ToLower(strings.TrimSpace(string(channel))))
+		if channel != SendEmail && channel != SendSMS {
+			return fmt.Errorf("%w: unknown channel %q", ErrInvalidInvoiceSend, channel)
+		}
+		if !seen[channel] {
+			seen[channel] = true
+			channels = append(channels, channel)
+		}
+	}
+	s.Channels = channels
+
+	if s.Share == "" {
+		s.Share = ShareView
+	}
+	if s.Share != ShareView && s.Share != SharePDF {
+		return fmt.Errorf("%w: share must be link or pdf", ErrInvalidInvoiceSend)
+	}
+
+	s.Email = trimmed(s.Email)
+	if s.Email != nil {
+		if _, err := mail.ParseAddress(*s.Email); err != nil {
+			return fmt.Errorf("%w: invalid email %q", ErrInvalidInvoiceSend, *s.Email)
+		}
+	}
+	s.Phone = trimmed(s.Phone)
+	s.Message = strings.TrimSpace(s.Message)
+	return nil
+}
+
+// Recipient is the address a channel sends to: the one given, else the customer's
+func (s *InvoiceSend) Recipient(channel SendChannel, contact *Contact) (string, error) {
+	address := s.Email
+	if channel == SendSMS {
+		address = s.Phone
+	}
+	if address == nil && contact != nil {
+		address = contact.Email
+		if channel == SendSMS {
+			address = contact.Phone
+		}
+	}
+	if address = trimmed(address); address == nil {
+		return "", fmt.Errorf("%w: no %s address given or on file for the customer", ErrNoRecipient, channel)
+	}
+	return *address, nil
+}
+
+// Contact is how a customer is reached
+type Contact struct {
+	Email *string
+	Phone *string
+}
+
+// InvoiceMessage is an invoice on its way to one address
+type InvoiceMessage struct {
+	Invoice       *Invoice
+	Channel       SendChannel
+	Recipient     string
+	Link          string
+	LinkExpiresAt time.Time
+	Message       string
+}
+
+// DeliveryFailed is the status of a message that could not be handed to the channel
+const DeliveryFailed = "FAILED"
+
+// InvoiceDelivery is one message sent for an invoice
+type InvoiceDelivery struct {
+	Channel        SendChannel `json:"channel"`
+	Recipient      string      `json:"recipient"`
+	NotificationID *uuid.UUID  `json:"notificationId,omitempty"` // In the customer's communication history; nil when it failed
+	Status         string      `json:"status"`                   // The notification's status, e.g. PENDING until delivered
+}
+
+// InvoiceSendResult is what sending an invoice did
+type InvoiceSendResult struct {
+	InvoiceID     uuid.UUID         `json:"invoiceId"`
+	Link          string            `json:"link"`
+	LinkExpiresAt time.Time         `json:"linkExpiresAt"`
+	Deliveries    []InvoiceDelivery `json:"deliveries"`
+}
+
+// trimmed returns nil for a nil or blank string, else the string trimmed
+func trimmed(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
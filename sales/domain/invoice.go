@@ -0,0 +1,354 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvoiceNotFound is returned when an invoice does not exist for the tenant
+	ErrInvoiceNotFound = errors.New("invoice not found")
+	// ErrInvalidInvoice is returned for an invoice with no lines, a bad quantity, price or discount
+	ErrInvalidInvoice = errors.New("invalid invoice")
+	// ErrInvoiceVoid is returned when a void invoice is voided again
+	ErrInvoiceVoid = errors.New("invoice is already void")
+	// ErrCreditInvoiceVoid is returned when voiding an invoice whose credit is on the customer's khata
+	ErrCreditInvoiceVoid = errors.New("a credit invoice cannot be voided once it is on the customer's khata")
+	// ErrNoFiscalYear is returned when no fiscal year of the tenant covers the invoice date
+	ErrNoFiscalYear = errors.New("no fiscal year covers the invoice date; create one before invoicing")
+	// ErrFiscalYearClosed is returned when the invoice date falls in a closed fiscal year
+	ErrFiscalYearClosed = errors.New("the invoice date's fiscal year is closed")
+	// ErrPriceNotAllowed is returned when a line is priced above MRP or below the seller's discount limit
+	ErrPriceNotAllowed = errors.New("sale price is not allowed")
+	// ErrCustomerNotFound is returned when the customer does not exist for the tenant
+	ErrCustomerNotFound = errors.New("customer not found")
+	// ErrCustomerNotVerified is returned for a credit sale without a fresh, unused
+	// verification of the customer
+	ErrCustomerNotVerified = errors.New("a credit sale needs a fresh verification of the customer")
+	// ErrCustomerBlocked is returned when invoicing a blocked customer
+	ErrCustomerBlocked = errors.New("customer is blocked")
+	// ErrProductNotFound is returned when a line's product does not exist for the tenant
+	ErrProductNotFound = errors.New("product not found")
+	// ErrProductUnavailable is returned when a line's product is inactive or discontinued
+	ErrProductUnavailable = errors.New("product is not available for sale")
+	// ErrPostingsPending is returned when an issued invoice still has postings to other
+	// modules queued for retry
+	ErrPostingsPending = errors.New("the invoice's stock, ledger or journal postings are queued for retry")
+)
+
+// TopicInvoices is the event topic invoice changes are published on
+const TopicInvoices = "invoices"
+
+// Invoice event types
+const (
+	EventInvoiceCreated = "invoice.created"
+	EventInvoiceVoided  = "invoice.voided"
+)
+
+// InvoiceChange is the payload of an invoice event
+type InvoiceChange struct {
+	ID            uuid.UUID     `json:"id"`
+	InvoiceNumber string        `json:"invoiceNumber"`
+	InvoiceDate   string        `json:"invoiceDate"` // 2006-01-02
+	CustomerID    *uuid.UUID    `json:"customerId,omitempty"`
+	BuyerName     string        `json:"buyerName"`
+	PaymentMode   PaymentMode   `json:"paymentMode"`
+	Status        InvoiceStatus `json:"status"`
+	TaxAmount     float64       `json:"taxAmount"`
+	TotalAmount   float64       `json:"totalAmount"`
+}
+
+// CashBuyer is the buyer name printed on cash sales without a customer
+const CashBuyer = "Cash"
+
+// PaymentMode is how an invoice is settled
+type PaymentMode string
+
+const (
+	PaymentCash   PaymentMode = "cash"   // Paid at the counter
+	PaymentCredit PaymentMode = "credit" // Charged to the customer's khata
+)
+
+// Valid reports whether the payment mode is known
+func (m PaymentMode) Valid() bool {
+	return m == PaymentCash || m == PaymentCredit
+}
+
+// PaymentStatus is how much of an invoice has been paid
+type PaymentStatus string
+
+const (
+	PaymentUnpaid  PaymentStatus = "unpaid"
+	PaymentPartial PaymentStatus = "partial"
+	PaymentPaid    PaymentStatus = "paid"
+)
+
+// Valid reports whether the payment status is known
+func (s PaymentStatus) Valid() bool {
+	return s == PaymentUnpaid || s == PaymentPartial || s == PaymentPaid
+}
+
+// PostingStep is a posting of a saved invoice to another module
+type PostingStep string
+
+const (
+	PostingNumberLedger PostingStep = "number_ledger" // Recorded against its number in the number ledger
+	PostingKhata        PostingStep = "khata"         // Charged to the customer's khata
+	PostingStock        PostingStep = "stock"         // Taken out of stock
+	PostingJournal      PostingStep = "journal"       // Posted to the journal
+)
+
+// InvoiceStatus is where an invoice is in its life
+type InvoiceStatus string
+
+const (
+	InvoiceIssued InvoiceStatus = "issued"
+	InvoiceVoid   InvoiceStatus = "void" // Cancelled; the number stays used
+)
+
+// Invoice is a tax invoice for a sale. Its number comes from the fiscal year's
+// invoice series and is recorded in the number ledger once the invoice is saved.
+// Buyer details are copied from the customer so reprints match what was issued.
+type Invoice struct {
+	ID               uuid.UUID     `json:"id" db:"id"`
+	TenantID         uuid.UUID     `json:"tenantId" db:"tenant_id"`
+	FiscalYearID     uuid.UUID     `json:"fiscalYearId" db:"fiscal_year_id"`
+	InvoiceNumber    string        `json:"invoiceNumber" db:"invoice_number"` // INV-8283-0001
+	InvoiceDate      time.Time     `json:"invoiceDate" db:"invoice_date"`
+	CustomerID       *uuid.UUID    `json:"customerId,omitempty" db:"customer_id"`   // Empty for walk-in cash sales
+	WarehouseID      *uuid.UUID    `json:"warehouseId,omitempty" db:"warehouse_id"` // Where the goods left from; nil for the default warehouse
+	BuyerName        string        `json:"buyerName" db:"buyer_name"`
+	BuyerPAN         *string       `json:"buyerPan,omitempty" db:"buyer_pan"`
+	BuyerAddress     *string       `json:"buyerAddress,omitempty" db:"buyer_address"`
+	PaymentMode      PaymentMode   `json:"paymentMode" db:"payment_mode"`
+	Status           InvoiceStatus `json:"status" db:"status"`
+	SubTotal         float64       `json:"subTotal" db:"sub_total"` // Quantity times price, before discounts
+	DiscountAmount   float64       `json:"discountAmount" db:"discount_amount"`
+	TaxableAmount    float64       `json:"taxableAmount" db:"taxable_amount"`        // Net of lines that carry tax
+	NonTaxableAmount float64       `json:"nonTaxableAmount" db:"non_taxable_amount"` // Net of exempt and zero-rated lines
+	TaxAmount        float64       `json:"taxAmount" db:"tax_amount"`
+	TotalAmount      float64       `json:"totalAmount" db:"total_amount"`
+	RoundOff         float64       `json:"roundOff" db:"round_off"`     // Added to reach the rounded total; negative when rounded down
+	AmountPaid       float64       `json:"amountPaid" db:"amount_paid"` // Paid at the counter, or allocated from payments
+	PaymentStatus    PaymentStatus `json:"paymentStatus" db:"payment_status"`
+	Note             *string       `json:"note,omitempty" db:"note"`
+	Locale           *string       `json:"locale,omitempty" db:"locale"` // Language product names were copied in; nil for the catalog's own
+	VoidReason       *string       `json:"voidReason,omitempty" db:"void_reason"`
+	VoidedAt         *time.Time    `json:"voidedAt,omitempty" db:"voided_at"`
+	VoidedBy         *uuid.UUID    `json:"voidedBy,omitempty" db:"voided_by"`
+	CreatedBy        *uuid.UUID    `json:"createdBy,omitempty" db:"created_by"`
+	PrintCount       int           `json:"printCount" db:"print_count"` // Copies printed or downloaded, including the original
+	// Postings to other modules not yet done; saved with the invoice and retried from RetryAt
+	PendingPostings []PostingStep `json:"pendingPostings,omitempty" db:"pending_postings"`
+	RetryAt         *time.Time    `json:"retryAt,omitempty" db:"retry_at"`
+	CreatedAt       time.Time     `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time     `json:"updatedAt" db:"updated_at"`
+	// Set on credit sales to the verification the customer passed at the counter
+	CustomerVerification *CustomerVerification `json:"customerVerification,omitempty" db:"customer_verification"`
+	Lines                []InvoiceLine         `json:"lines"`
+}
+
+// CustomerVerification proves the buyer of a credit sale read back a code sent to
+// the customer's phone or email
+type CustomerVerification struct {
+	VerificationID uuid.UUID `json:"verificationId"`
+	Channel        string    `json:"channel"`
+	Recipient      string    `json:"recipient"` // Masked
+	VerifiedAt     time.Time `json:"verifiedAt"`
+	VerifiedBy     uuid.UUID `json:"verifiedBy"`
+}
+
+// InvoiceLine is a product sold on an invoice. Product details are copied at sale time.
+type InvoiceLine struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	InvoiceID      uuid.UUID `json:"invoiceId" db:"invoice_id"`
+	LineNo         int       `json:"lineNo" db:"line_no"`
+	ProductID      uuid.UUID `json:"productId" db:"product_id"`
+	ProductCode    string    `json:"productCode" db:"product_code"`
+	ProductName    string    `json:"productName" db:"product_name"`
+	Unit           string    `json:"unit" db:"unit"`
+	Quantity       float64   `json:"quantity" db:"quantity"`
+	UnitPrice      float64   `json:"unitPrice" db:"unit_price"` // Before tax
+	DiscountAmount float64   `json:"discountAmount" db:"discount_amount"`
+	NetAmount      float64   `json:"netAmount" db:"net_amount"` // Quantity times price less discount
+	TaxRate        float64   `json:"taxRate" db:"tax_rate"`     // Percent, summed over the product's taxes
+	TaxAmount      float64   `json:"taxAmount" db:"tax_amount"`
+	TotalAmount    float64   `json:"totalAmount" db:"total_amount"`
+}
+
+// NewInvoice creates an invoice for a sale on invoiceDate; the number, buyer and
+// lines are filled in before it is saved
+func NewInvoice(tenantID uuid.UUID, paymentMode PaymentMode, invoiceDate time.Time) *Invoice {
+	now := time.Now()
+	return &Invoice{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		InvoiceDate:   invoiceDate,
+		BuyerName:     CashBuyer,
+		PaymentMode:   paymentMode,
+		Status:        InvoiceIssued,
+		PaymentStatus: PaymentUnpaid,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Lines:         []InvoiceLine{},
+	}
+}
+
+// AddLine adds a product sold at unitPrice (before tax) less a discount on the line
+func (i *Invoice) AddLine(productID uuid.UUID, quantity, unitPrice, discount float64) (*InvoiceLine, error) {
+	if productID == uuid.Nil {
+		return nil, fmt.Errorf("%w: line %d has no product", ErrInvalidInvoice, len(i.Lines)+1)
+	}
+	if quantity <= 0 || unitPrice < 0 || discount < 0 {
+		return nil, fmt.Errorf("%w: line %d needs a positive quantity and a price and discount not below zero", ErrInvalidInvoice, len(i.Lines)+1)
+	}
+	gross := roundMoney(quantity * unitPrice)
+	if discount > gross {
+		return nil, fmt.Errorf("%w: line %d discount is more than the line", ErrInvalidInvoice, len(i.Lines)+1)
+	}
+
+	i.Lines = append(i.Lines, InvoiceLine{
+		ID:             uuid.New(),
+		InvoiceID:      i.ID,
+		LineNo:         len(i.Lines) + 1,
+		ProductID:      productID,
+		Quantity:       quantity,
+		UnitPrice:      unitPrice,
+		DiscountAmount: roundMoney(discount),
+		NetAmount:      roundMoney(gross - discount),
+	})
+	return &i.Lines[len(i.Lines)-1], nil
+}
+
+// SetTax records the tax charged on the line's net amount
+func (l *InvoiceLine) SetTax(rate, amount float64) {
+	l.TaxRate = rate
+	l.TaxAmount = roundMoney(amount)
+	l.TotalAmount = roundMoney(l.NetAmount + l.TaxAmount)
+}
+
+// Calculate totals the lines. Lines without tax count as non-taxable.
+func (i *Invoice) Calculate() error {
+	if len(i.Lines) == 0 {
+		return fmt.Errorf("%w: invoice has no lines", ErrInvalidInvoice)
+	}
+
+	i.SubTotal, i.DiscountAmount, i.TaxableAmount, i.NonTaxableAmount, i.TaxAmount = 0, 0, 0, 0, 0
+	for idx := range i.Lines {
+		line := &i.Lines[idx]
+		line.TotalAmount = roundMoney(line.NetAmount + line.TaxAmount)
+
+		i.SubTotal += roundMoney(line.Quantity * line.UnitPrice)
+		i.DiscountAmount += line.DiscountAmount
+		if line.TaxAmount > 0 {
+			i.TaxableAmount += line.NetAmount
+		} else {
+			i.NonTaxableAmount += line.NetAmount
+		}
+		i.TaxAmount += line.TaxAmount
+	}
+
+	i.SubTotal = roundMoney(i.SubTotal)
+	i.DiscountAmount = roundMoney(i.DiscountAmount)
+	i.TaxableAmount = roundMoney(i.TaxableAmount)
+	i.NonTaxableAmount = roundMoney(i.NonTaxableAmount)
+	i.TaxAmount = roundMoney(i.TaxAmount)
+	i.TotalAmount = roundMoney(i.TaxableAmount + i.NonTaxableAmount + i.TaxAmount)
+	i.RoundOff = 0
+	return nil
+}
+
+// RoundTotal rounds the calculated total, keeping the difference as the round-off
+func (i *Invoice) RoundTotal(rounded float64) {
+	total := roundMoney(i.TaxableAmount + i.NonTaxableAmount + i.TaxAmount)
+	i.TotalAmount = roundMoney(rounded)
+	i.RoundOff = roundMoney(i.TotalAmount - total)
+}
+
+// SetAmountPaid records the total paid against the invoice and derives its payment status
+func (i *Invoice) SetAmountPaid(amount float64) {
+	i.AmountPaid = roundMoney(amount)
+	switch {
+	case i.AmountPaid >= i.TotalAmount:
+		i.PaymentStatus = PaymentPaid
+	case i.AmountPaid > 0:
+		i.PaymentStatus = PaymentPartial
+	default:
+		i.PaymentStatus = PaymentUnpaid
+	}
+	i.UpdatedAt = time.Now()
+}
+
+// AmountDue is what is still to be paid on the invoice
+func (i *Invoice) AmountDue() float64 {
+	return math.Max(roundMoney(i.TotalAmount-i.AmountPaid), 0)
+}
+
+// Void cancels the invoice. The number stays used so the series has no gap.
+func (i *Invoice) Void(reason string, userID *uuid.UUID) error {
+	if i.Status == InvoiceVoid {
+		return ErrInvoiceVoid
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("%w: a void needs a reason", ErrInvalidInvoice)
+	}
+
+	now := time.Now()
+	i.Status = InvoiceVoid
+	i.VoidReason = &reason
+	i.VoidedAt = &now
+	i.VoidedBy = userID
+	i.UpdatedAt = now
+	return nil
+}
+
+// Buyer is a customer as an invoice is made out to them
+type Buyer struct {
+	CustomerID uuid.UUID
+	Name       string
+	PAN        *string
+	Address    *string
+	Blocked    bool
+	Contact    Contact // Where invoices are sent; not copied onto the invoice
+}
+
+// SaleProduct is a catalog product as it is sold
+type SaleProduct struct {
+	ID        uuid.UUID
+	Code      string
+	Name      string
+	Unit      string
+	Price     float64 // Selling price before tax
+	Available bool
+}
+
+// SetBuyer makes the invoice out to a customer
+func (i *Invoice) SetBuyer(buyer *Buyer) {
+	customerID := buyer.CustomerID
+	i.CustomerID = &customerID
+	i.BuyerName = buyer.Name
+	i.BuyerPAN = buyer.PAN
+	i.BuyerAddress = buyer.Address
+}
+
+// InvoiceFilter narrows an invoice listing
+type InvoiceFilter struct {
+	CustomerID    *uuid.UUID
+	Status        *InvoiceStatus
+	PaymentStatus *PaymentStatus
+	Outstanding   bool       // Only issued invoices not yet fully paid
+	From          *time.Time // Invoice dates from this day
+	To            *time.Time // Invoice dates up to and including this day
+	Limit         int
+	Offset        int
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
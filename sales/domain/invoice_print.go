@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PrintKind is how an invoice was output
+type PrintKind string
+
+const (
+	PrintKindPrint     PrintKind = "PRINT"
+	PrintKindDownload  PrintKind = "DOWNLOAD"
+	PrintKindShareLink PrintKind = "SHARE_LINK" // Opened by the customer from a shared link
+)
+
+// MaxPrintCopies bounds how many copies one print request may produce
+const MaxPrintCopies = 5
+
+// OriginalLabel marks the first output of an invoice
+const OriginalLabel = "ORIGINAL"
+
+// ErrInvalidPrintCopies is returned for a print of fewer than one or more than MaxPrintCopies copies
+var ErrInvalidPrintCopies = fmt.Errorf("copies must be between 1 and %d", MaxPrintCopies)
+
+// InvoicePrint records one print or download of an invoice. Every copy ever output is
+// numbered: the invoice's first copy is the original and every later one is a copy,
+// as IRD requires reprints to be marked "COPY n OF m".
+type InvoicePrint struct {
+	ID        uuid.UUID  `json:"id"`
+	TenantID  uuid.UUID  `json:"tenantId"`
+	InvoiceID uuid.UUID  `json:"invoiceId"`
+	Kind      PrintKind  `json:"kind"`
+	FirstCopy int        `json:"firstCopy"` // Number of the first copy output; 0 is the original
+	Copies    int        `json:"copies"`    // Copies output by this request
+	Reason    *string    `json:"reason,omitempty"`
+	PrintedBy *uuid.UUID `json:"printedBy,omitempty"` // Empty for share links
+	IPAddress *string    `json:"ipAddress,omitempty"`
+	PrintedAt time.Time  `json:"printedAt"`
+}
+
+// NewInvoicePrint creates a print record; the repository assigns FirstCopy
+func NewInvoicePrint(tenantID, invoiceID uuid.UUID, kind PrintKind, copies int, printedBy *uuid.UUID) *InvoicePrint {
+	return &InvoicePrint{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		InvoiceID: invoiceID,
+		Kind:      kind,
+		Copies:    copies,
+		PrintedBy: printedBy,
+		PrintedAt: time.Now(),
+	}
+}
+
+// LastCopy is the number of the last copy output by this request
+func (p *InvoicePrint) LastCopy() int {
+	return p.FirstCopy + p.Copies - 1
+}
+
+// Labels returns the mark printed on each copy: ORIGINAL for the first copy ever
+// output, then COPY n OF m, where m counts every copy issued up to this request
+func (p *InvoicePrint) Labels() []string {
+	labels := make([]string, 0, p.Copies)
+	for n := p.FirstCopy; n <= p.LastCopy(); n++ {
+		if n == 0 {
+			labels = append(labels, OriginalLabel)
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("COPY %d OF %d", n, p.LastCopy()))
+	}
+	return labels
+}
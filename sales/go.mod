@@ -0,0 +1,68 @@
+module github.com/aceextension/sales
+
+go 1.24.0
+
+require (
+	github.com/aceextension/accounting v0.0.0
+	github.com/aceextension/analytics v0.0.0
+	github.com/aceextension/audit v0.0.0
+	github.com/aceextension/automation v0.0.0
+	github.com/aceextension/catalog v0.0.0
+	github.com/aceextension/comments v0.0.0
+	github.com/aceextension/core v0.0.0
+	github.com/aceextension/crm v0.0.0
+	github.com/aceextension/fiscal v0.0.0
+	github.com/aceextension/notification v0.0.0
+	github.com/aceextension/onboarding v0.0.0
+	github.com/aceextension/payments v0.0.0
+	github.com/google/uuid v1.6.0
+	github.com/jackc/pgx/v5 v5.8.0
+	github.com/labstack/echo/v4 v4.15.0
+)
+
+require (
+	github.com/fsnotify/fsnotify v1.9.0 // indirect
+	github.com/go-viper/mapstructure/v2 v2.4.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
+	github.com/labstack/gommon v0.4.2 // indirect
+	github.com/mattn/go-colorable v0.1.14 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/pelletier/go-toml/v2 v2.2.4 // indirect
+	github.com/sagikazarmark/locafero v0.11.0 // indirect
+	github.com/sourcegraph/conc v0.3.1-0.20240121214520-5f936abd7ae8 // indirect
+	github.com/spf13/afero v1.15.0 // indirect
+	github.com/spf13/cast v1.10.0 // indirect
+	github.com/spf13/pflag v1.0.10 // indirect
+	github.com/spf13/viper v1.21.0 // indirect
+	github.com/subosito/gotenv v1.6.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	go.uber.org/zap v1.27.1 // indirect
+	go.yaml.in/yaml/v3 v3.0.4 // indirect
+	golang.org/x/crypto v0.47.0 // indirect
+	golang.org/x/net v0.48.0 // indirect
+	golang.org/x/sync v0.19.0 // indirect
+	golang.org/x/sys v0.40.0 // indirect
+	golang.org/x/text v0.33.0 // indirect
+)
+
+replace (
+	github.com/aceextension/accounting => ../accounting
+	github.com/aceextension/analytics => ../analytics
+	github.com/aceextension/audit => ../audit
+	github.com/aceextension/automation => ../automation
+	github.com/aceextension/catalog => ../catalog
+	github.com/aceextension/comments => ../comments
+	github.com/aceextension/core => ../core
+	github.com/aceextension/crm => ../crm
+	github.com/aceextension/files => ../files
+	github.com/aceextension/fiscal => ../fiscal
+	github.com/aceextension/identity => ../identity
+	github.com/aceextension/notification => ../notification
+	github.com/aceextension/onboarding => ../onboarding
+	github.com/aceextension/payments => ../payments
+	github.com/aceextension/tags => ../tags
+)
@@ -0,0 +1,581 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/sales"
+	"github.com/aceextension/sales/domain"
+	"github.com/aceextension/sales/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// InvoiceHandler handles HTTP requests for sales invoices
+type InvoiceHandler struct{}
+
+// NewInvoiceHandler creates a new invoice handler
+func NewInvoiceHandler() *InvoiceHandler {
+	return &InvoiceHandler{}
+}
+
+// InvoiceRequest records a sale
+type InvoiceRequest struct {
+	CustomerID *uuid.UUID `json:"customerId,omitempty"` // Required for credit sales
+	// The customer's verification at the counter; required for credit sales
+	VerificationID *uuid.UUID           `json:"verificationId,omitempty"`
+	WarehouseID    *uuid.UUID           `json:"warehouseId,omitempty"` // Inventory warehouse sold from; defaults to the default warehouse
+	PaymentMode    domain.PaymentMode   `json:"paymentMode,omitempty"` // cash (default) or credit
+	InvoiceDate    string               `json:"invoiceDate,omitempty"` // YYYY-MM-DD; defaults to today
+	Note           *string              `json:"note,omitempty"`
+	Locale         *string              `json:"locale,omitempty"` // Language to print product names in; defaults to the tenant's invoice language
+	Lines          []InvoiceLineRequest `json:"lines" validate:"required,min=1"`
+}
+
+// InvoiceLineRequest is a product sold
+type InvoiceLineRequest struct {
+	ProductID uuid.UUID `json:"productId" validate:"required"`
+	Quantity  float64   `json:"quantity" validate:"required,gt=0"`
+	UnitPrice *float64  `json:"unitPrice,omitempty"` // Before tax; defaults to the product's selling price
+	Discount  float64   `json:"discount,omitempty"`  // Amount off the line
+	// An approved discount override for a price below the seller's discount limit
+	OverrideID *uuid.UUID `json:"overrideId,omitempty"`
+}
+
+// VoidInvoiceRequest gives the reason an invoice is cancelled
+type VoidInvoiceRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// SendInvoiceRequest sends an invoice to its customer
+type SendInvoiceRequest struct {
+	Channels []domain.SendChannel `json:"channels" validate:"required,min=1"` // email, sms or both
+	Share    domain.ShareMode     `json:"share,omitempty"`                    // link (default) to the invoice's page, or pdf
+	Email    *string              `json:"email,omitempty"`                    // Defaults to the customer's email
+	Phone    *string              `json:"phone,omitempty"`                    // Defaults to the customer's phone
+	Message  string               `json:"message,omitempty"`                  // Added to the message
+}
+
+// SharedInvoiceResponse is an invoice as a customer sees it through a share link
+type SharedInvoiceResponse struct {
+	InvoiceNumber    string               `json:"invoiceNumber"`
+	InvoiceDate      time.Time            `json:"invoiceDate"`
+	BuyerName        string               `json:"buyerName"`
+	BuyerPAN         *string              `json:"buyerPan,omitempty"`
+	BuyerAddress     *string              `json:"buyerAddress,omitempty"`
+	PaymentMode      domain.PaymentMode   `json:"paymentMode"`
+	Status           domain.InvoiceStatus `json:"status"`
+	SubTotal         float64              `json:"subTotal"`
+	DiscountAmount   float64              `json:"discountAmount"`
+	TaxableAmount    float64              `json:"taxableAmount"`
+	NonTaxableAmount float64              `json:"nonTaxableAmount"`
+	TaxAmount        float64              `json:"taxAmount"`
+	RoundOff         float64              `json:"roundOff"`
+	TotalAmount      float64              `json:"totalAmount"`
+	Note             *string              `json:"note,omitempty"`
+	Lines            []SharedInvoiceLine  `json:"lines"`
+}
+
+// SharedInvoiceLine is a line of a shared invoice
+type SharedInvoiceLine struct {
+	ProductName    string  `json:"productName"`
+	Unit           string  `json:"unit"`
+	Quantity       float64 `json:"quantity"`
+	UnitPrice      float64 `json:"unitPrice"`
+	DiscountAmount float64 `json:"discountAmount"`
+	NetAmount      float64 `json:"netAmount"`
+	TaxRate        float64 `json:"taxRate"`
+	TaxAmount      float64 `json:"taxAmount"`
+	TotalAmount    float64 `json:"totalAmount"`
+}
+
+// Create godoc
+// @Summary Create an invoice
+// @Description Record a sale. Lines without a unit price take the product's selling price; tax comes from the product's
+// @Description tax group on the invoice date. The number is the next in the invoice series of the fiscal year the invoice date falls in.
+// @Description Credit sales need a customer and are charged to their khata on their payment terms. When a posting to the number
+// @Description ledger, khata, stock or journal fails, the invoice is still issued and returned with 202 and its pendingPostings,
+// @Description which are retried in the background.
+// @Description Product names are copied in the invoice's locale where the product is translated into it.
+// @Description The total is rounded by the tenant's invoice rounding rule, with the difference as roundOff.
+// @Tags sales
+// @Accept json
+// @Produce json
+// @Param invoice body InvoiceRequest true "Invoice"
+// @Success 201 {object} domain.Invoice
+// @Success 202 {object} domain.Invoice "Issued, with pendingPostings queued for retry"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /api/v1/sales/invoices [post]
+// @Security BearerAuth
+func (h *InvoiceHandler) Create(c echo.Context) error {
+	var req InvoiceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	invoiceDate := time.Now()
+	if req.InvoiceDate != "" {
+		var err error
+		if invoiceDate, err = time.ParseInLocation("2006-01-02", req.InvoiceDate, time.Local); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid invoiceDate, expected YYYY-MM-DD"})
+		}
+	}
+	paymentMode := req.PaymentMode
+	if paymentMode == "" {
+		paymentMode = domain.PaymentCash
+	}
+
+	invoice := domain.NewInvoice(tenantID, paymentMode, invoiceDate)
+	invoice.CustomerID = req.CustomerID
+	invoice.WarehouseID = req.WarehouseID
+	invoice.Note = req.Note
+	invoice.Locale = req.Locale
+	invoice.CreatedBy = optionalUserID(c)
+	if req.VerificationID != nil {
+		invoice.CustomerVerification = &domain.CustomerVerification{VerificationID: *req.VerificationID}
+	}
+
+	lines := make([]service.InvoiceLineInput, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, service.InvoiceLineInput{
+			ProductID:  line.ProductID,
+			Quantity:   line.Quantity,
+			UnitPrice:  line.UnitPrice,
+			Discount:   line.Discount,
+			OverrideID: line.OverrideID,
+		})
+	}
+
+	err := sales.InvoiceService.Create(c.Request().Context(), invoice, lines)
+	if errors.Is(err, domain.ErrPostingsPending) {
+		// The invoice is issued; creating it again would issue a second one
+		return c.JSON(http.StatusAccepted, invoice)
+	}
+	if err != nil {
+		return invoiceError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, invoice)
+}
+
+// List godoc
+// @Summary List invoices
+// @Description Get invoices, newest first, optionally for a customer, a status, a payment status or a date range
+// @Tags sales
+// @Produce json
+// @Param customerId query string false "Customer ID"
+// @Param status query string false "issued or void"
+// @Param paymentStatus query string false "unpaid, partial or paid"
+// @Param outstanding query bool false "Only issued invoices not yet fully paid"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.Invoice
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/sales/invoices [get]
+// @Security BearerAuth
+func (h *InvoiceHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	filter := domain.InvoiceFilter{}
+	filter.Limit, _ = strconv.Atoi(c.QueryParam("limit"))
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	filter.Offset, _ = strconv.Atoi(c.QueryParam("offset"))
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	if value := c.QueryParam("customerId"); value != "" {
+		customerID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid customer ID"})
+		}
+		filter.CustomerID = &customerID
+	}
+	if value := c.QueryParam("status"); value != "" {
+		status := domain.InvoiceStatus(value)
+		if status != domain.InvoiceIssued && status != domain.InvoiceVoid {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+		}
+		filter.Status = &status
+	}
+	if value := c.QueryParam("paymentStatus"); value != "" {
+		paymentStatus := domain.PaymentStatus(value)
+		if !paymentStatus.Valid() {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid payment status"})
+		}
+		filter.PaymentStatus = &paymentStatus
+	}
+	filter.Outstanding = c.QueryParam("outstanding") == "true"
+	if value := c.QueryParam("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from date, expected YYYY-MM-DD"})
+		}
+		filter.From = &from
+	}
+	if value := c.QueryParam("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to date, expected YYYY-MM-DD"})
+		}
+		filter.To = &to
+	}
+
+	invoices, err := sales.InvoiceService.List(c.Request().Context(), tenantID, filter)
+	if err != nil {
+		return invoiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, invoices)
+}
+
+// Get godoc
+// @Summary Get an invoice
+// @Description Get an invoice with its lines
+// @Tags sales
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} domain.Invoice
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/sales/invoices/{id} [get]
+// @Security BearerAuth
+func (h *InvoiceHandler) Get(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid invoice ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	invoice, err := sales.InvoiceService.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return invoiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, invoice)
+}
+
+// Void godoc
+// @Summary Void an invoice
+// @Description Cancel an invoice with a reason. The number stays used so the invoice series has no gap.
+// @Description Credit invoices already charged to the customer's khata cannot be voided.
+// @Tags sales
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Param void body VoidInvoiceRequest true "Reason"
+// @Success 200 {object} domain.Invoice
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/sales/invoices/{id}/void [post]
+// @Security BearerAuth
+func (h *InvoiceHandler) Void(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid invoice ID"})
+	}
+
+	var req VoidInvoiceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	invoice, err := sales.InvoiceService.Void(c.Request().Context(), tenantID, id, req.Reason, optionalUserID(c))
+	if err != nil {
+		return invoiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, invoice)
+}
+
+// Send godoc
+// @Summary Send an invoice to the customer
+// @Description Message an issued invoice by email, SMS or both, to the customer's email and phone unless others are
+// @Description given. The message links to the invoice's public page or, with share=pdf, its PDF; links are signed
+// @Description and expire after SHARE_LINK_DAYS. The tenant's INVOICE_SEND template for the channel is used where
+// @Description it has one. Every message appears in the customer's communication history.
+// @Tags sales
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Param send body SendInvoiceRequest true "Channels and recipients"
+// @Success 200 {object} domain.InvoiceSendResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /api/v1/sales/invoices/{id}/send [post]
+// @Security BearerAuth
+func (h *InvoiceHandler) Send(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid invoice ID"})
+	}
+
+	var req SendInvoiceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	result, err := sales.InvoiceService.Send(c.Request().Context(), tenantID, id, domain.InvoiceSend{
+		Channels: req.Channels,
+		Share:    req.Share,
+		Email:    req.Email,
+		Phone:    req.Phone,
+		Message:  req.Message,
+	}, optionalUserID(c))
+	if err != nil {
+		return invoiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// DownloadPDF godoc
+// @Summary Download an invoice PDF
+// @Description Download or print an invoice as an A4 tax invoice. Every request is logged; the first copy ever
+// @Description output is the original and later copies are marked "COPY n OF m".
+// @Tags sales
+// @Produce application/pdf
+// @Param id path string true "Invoice ID"
+// @Param mode query string false "download (default) or print"
+// @Param copies query int false "Copies to output, 1 to 5 (default 1)"
+// @Param reason query string false "Reason for a reprint"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/sales/invoices/{id}/pdf [get]
+// @Security BearerAuth
+func (h *InvoiceHandler) DownloadPDF(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid invoice ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	req := service.PrintRequest{
+		TenantID:  tenantID,
+		InvoiceID: id,
+		Kind:      domain.PrintKindDownload,
+		Copies:    1,
+		Reason:    strings.TrimSpace(c.QueryParam("reason")),
+		UserID:    optionalUserID(c),
+		IPAddress: c.RealIP(),
+	}
+	switch c.QueryParam("mode") {
+	case "", "download":
+	case "print":
+		req.Kind = domain.PrintKindPrint
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "mode must be download or print"})
+	}
+	if raw := c.QueryParam("copies"); raw != "" {
+		if req.Copies, err = strconv.Atoi(raw); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": domain.ErrInvalidPrintCopies.Error()})
+		}
+	}
+
+	invoice, record, pdf, err := sales.InvoiceService.PrintPDF(c.Request().Context(), req)
+	if err != nil {
+		return invoiceError(c, err)
+	}
+
+	c.Response().Header().Set("X-Invoice-Copies", strings.Join(record.Labels(), ", "))
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="`+invoice.InvoiceNumber+`.pdf"`)
+	return c.Blob(http.StatusOK, "application/pdf", pdf)
+}
+
+// ListPrints godoc
+// @Summary List invoice prints
+// @Description Get every print and download of an invoice, including opens of its share link, oldest first, with the copy numbers issued
+// @Tags sales
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {array} domain.InvoicePrint
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/sales/invoices/{id}/prints [get]
+// @Security BearerAuth
+func (h *InvoiceHandler) ListPrints(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid invoice ID"})
+	}
+
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	prints, err := sales.InvoiceService.ListPrints(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return invoiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, prints)
+}
+
+// GetShared godoc
+// @Summary Get a shared invoice
+// @Description Public endpoint behind the link an invoice was sent with; shows the invoice without logging in
+// @Tags sales
+// @Produce json
+// @Param token path string true "Share link token"
+// @Success 200 {object} SharedInvoiceResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/public/invoices/{token} [get]
+func (h *InvoiceHandler) GetShared(c echo.Context) error {
+	invoice, err := sales.InvoiceService.OpenShared(c.Request().Context(), c.Param("token"))
+	if err != nil {
+		return sharedInvoiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toSharedInvoiceResponse(invoice))
+}
+
+// GetSharedPDF godoc
+// @Summary Download a shared invoice PDF
+// @Description Public endpoint behind the link an invoice was sent with; the invoice as a PDF without logging in.
+// @Description Each download is logged and numbered like one by the tenant, so copies after the original are marked.
+// @Tags sales
+// @Produce application/pdf
+// @Param token path string true "Share link token"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/public/invoices/{token}/pdf [get]
+func (h *InvoiceHandler) GetSharedPDF(c echo.Context) error {
+	invoice, record, content, err := sales.InvoiceService.SharedPDF(c.Request().Context(), c.Param("token"), c.RealIP())
+	if err != nil {
+		return sharedInvoiceError(c, err)
+	}
+
+	c.Response().Header().Set("X-Invoice-Copies", strings.Join(record.Labels(), ", "))
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="`+invoice.InvoiceNumber+`.pdf"`)
+	return c.Blob(http.StatusOK, "application/pdf", content)
+}
+
+// toSharedInvoiceResponse leaves out the invoice's internal references
+func toSharedInvoiceResponse(invoice *domain.Invoice) SharedInvoiceResponse {
+	response := SharedInvoiceResponse{
+		InvoiceNumber:    invoice.InvoiceNumber,
+		InvoiceDate:      invoice.InvoiceDate,
+		BuyerName:        invoice.BuyerName,
+		BuyerPAN:         invoice.BuyerPAN,
+		BuyerAddress:     invoice.BuyerAddress,
+		PaymentMode:      invoice.PaymentMode,
+		Status:           invoice.Status,
+		SubTotal:         invoice.SubTotal,
+		DiscountAmount:   invoice.DiscountAmount,
+		TaxableAmount:    invoice.TaxableAmount,
+		NonTaxableAmount: invoice.NonTaxableAmount,
+		TaxAmount:        invoice.TaxAmount,
+		RoundOff:         invoice.RoundOff,
+		TotalAmount:      invoice.TotalAmount,
+		Note:             invoice.Note,
+		Lines:            make([]SharedInvoiceLine, 0, len(invoice.Lines)),
+	}
+	for _, line := range invoice.Lines {
+		response.Lines = append(response.Lines, SharedInvoiceLine{
+			ProductName:    line.ProductName,
+			Unit:           line.Unit,
+			Quantity:       line.Quantity,
+			UnitPrice:      line.UnitPrice,
+			DiscountAmount: line.DiscountAmount,
+			NetAmount:      line.NetAmount,
+			TaxRate:        line.TaxRate,
+			TaxAmount:      line.TaxAmount,
+			TotalAmount:    line.TotalAmount,
+		})
+	}
+	return response
+}
+
+// sharedInvoiceError maps share link errors to HTTP responses without telling a
+// tampered link from an expired one
+func sharedInvoiceError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidShareLink), errors.Is(err, domain.ErrShareLinksDisabled):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": domain.ErrInvalidShareLink.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// optionalUserID returns the calling user, if known
+func optionalUserID(c echo.Context) *uuid.UUID {
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		return &userID
+	}
+	return nil
+}
+
+// invoiceError maps invoice domain errors to HTTP responses
+func invoiceError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvoiceNotFound), errors.Is(err, domain.ErrCustomerNotFound), errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvoiceVoid), errors.Is(err, domain.ErrCreditInvoiceVoid),
+		errors.Is(err, domain.ErrCustomerBlocked), errors.Is(err, domain.ErrNoFiscalYear),
+		errors.Is(err, domain.ErrFiscalYearClosed), errors.Is(err, domain.ErrPostingsPending):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInvoice), errors.Is(err, domain.ErrProductUnavailable),
+		errors.Is(err, domain.ErrInvalidInvoiceSend), errors.Is(err, domain.ErrNoRecipient),
+		errors.Is(err, domain.ErrInvalidPrintCopies):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrPriceNotAllowed), errors.Is(err, domain.ErrCustomerNotVerified):
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrShareLinksDisabled):
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"github.com/aceextension/core/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes registers the sales routes on v1, which the caller mounts at
+// /api/v1/sales behind its authentication, read-only and scope checks, and the shared
+// invoice routes on public, mounted at /api/v1/public/invoices without authentication
+func RegisterRoutes(v1, public *echo.Group) {
+	// Create handlers
+	invoiceHandler := NewInvoiceHandler()
+
+	// Invoice routes
+	invoices := v1.Group("/invoices")
+	{
+		invoices.POST("", invoiceHandler.Create, middleware.PlanLimit(middleware.PlanLimitInvoicesPerMonth))
+		invoices.GET("", invoiceHandler.List)
+		invoices.GET("/:id", invoiceHandler.Get)
+		invoices.POST("/:id/void", invoiceHandler.Void)
+		invoices.POST("/:id/send", invoiceHandler.Send)
+		invoices.GET("/:id/pdf", invoiceHandler.DownloadPDF)
+		invoices.GET("/:id/prints", invoiceHandler.ListPrints)
+	}
+
+	// Shared invoice routes; the signed token is the only credential
+	{
+		public.GET("/:token", invoiceHandler.GetShared)
+		public.GET("/:token/pdf", invoiceHandler.GetSharedPDF)
+	}
+}
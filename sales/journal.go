@@ -0,0 +1,89 @@
+package sales
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/accounting"
+	accountingDomain "github.com/aceextension/accounting/domain"
+	"github.com/aceextension/sales/domain"
+	"github.com/google/uuid"
+)
+
+// accountingJournal posts invoices with accounting's automatic posting
+type accountingJournal struct{}
+
+// PostInvoice books the invoice on the tenant's posting accounts. An invoice already
+// booked, e.g. by a retry whose result was not saved, is done.
+func (accountingJournal) PostInvoice(ctx context.Context, invoice *domain.Invoice) error {
+	_, err := accounting.Service.PostFromReference(ctx, invoice.TenantID, accountingDomain.ReferenceInvoice, invoice.ID)
+	if errors.Is(err, accountingDomain.ErrReferenceAlreadyPosted) {
+		return nil
+	}
+	return err
+}
+
+// ReverseVoided books the invoice's entry the other way round. An invoice that was
+// never posted has nothing to reverse.
+func (accountingJournal) ReverseVoided(ctx context.Context, invoice *domain.Invoice) error {
+	_, err := accounting.Service.PostFromReference(ctx, invoice.TenantID, accountingDomain.ReferenceInvoiceVoid, invoice.ID)
+	if errors.Is(err, accountingDomain.ErrReferenceNotPosted) {
+		return nil
+	}
+	return err
+}
+
+// postInvoice reads an issued invoice for posting: the total is due from the
+// customer, or received in cash, against the sale, the VAT charged and the round-off
+func postInvoice(ctx context.Context, tenantID, id uuid.UUID) (*accountingDomain.PostingDocument, error) {
+	invoice, err := InvoiceService.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status != domain.InvoiceIssued {
+		return nil, fmt.Errorf("%w: invoice %s is %s", accountingDomain.ErrReferenceNotPostable, invoice.InvoiceNumber, invoice.Status)
+	}
+
+	doc := &accountingDomain.PostingDocument{
+		TenantID:     tenantID,
+		FiscalYearID: &invoice.FiscalYearID,
+		Date:         invoice.InvoiceDate,
+		Description:  fmt.Sprintf("Invoice %s - %s", invoice.InvoiceNumber, invoice.BuyerName),
+		CreatedBy:    invoice.CreatedBy,
+	}
+	settlement := accountingDomain.RoleCash
+	if invoice.PaymentMode == domain.PaymentCredit {
+		settlement = accountingDomain.RoleReceivable
+	}
+	doc.Debit(settlement, invoice.TotalAmount, invoice.BuyerName)
+	doc.Credit(accountingDomain.RoleRevenue, invoice.TaxableAmount+invoice.NonTaxableAmount, "")
+	doc.Credit(accountingDomain.RoleOutputVAT, invoice.TaxAmount, "")
+	doc.RoundOff(invoice.RoundOff)
+	return doc, nil
+}
+
+// postInvoiceVoid reverses a voided invoice's entry on the day it was voided
+func postInvoiceVoid(ctx context.Context, tenantID, id uuid.UUID) (*accountingDomain.PostingDocument, error) {
+	invoice, err := InvoiceService.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status != domain.InvoiceVoid || invoice.VoidedAt == nil {
+		return nil, fmt.Errorf("%w: invoice %s is not void", accountingDomain.ErrReferenceNotPostable, invoice.InvoiceNumber)
+	}
+
+	year, month, day := invoice.VoidedAt.Date()
+	description := "Void of invoice " + invoice.InvoiceNumber
+	if invoice.VoidReason != nil {
+		description += ": " + *invoice.VoidReason
+	}
+	return &accountingDomain.PostingDocument{
+		TenantID:    tenantID,
+		Date:        time.Date(year, month, day, 0, 0, 0, 0, invoice.VoidedAt.Location()),
+		Description: description,
+		CreatedBy:   invoice.VoidedBy,
+		Reverses:    accountingDomain.ReferenceInvoice,
+	}, nil
+}
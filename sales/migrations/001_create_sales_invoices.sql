@@ -0,0 +1,93 @@
+-- Sales Module: Invoices
+-- Migration: 001_create_sales_invoices.sql
+-- Tax invoices with line items. Numbers come from the fiscal year's invoice series
+-- and are recorded in the number ledger; void invoices keep their number.
+
+CREATE TABLE IF NOT EXISTS sales_invoices (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    fiscal_year_id UUID NOT NULL REFERENCES fiscal_years(id),
+    invoice_number VARCHAR(50) NOT NULL,             -- INV-8283-0001
+    invoice_date DATE NOT NULL,
+    customer_id UUID REFERENCES customers(id),       -- NULL for walk-in cash sales
+    buyer_name VARCHAR(255) NOT NULL,
+    buyer_pan VARCHAR(20),
+    buyer_address TEXT,
+    payment_mode VARCHAR(20) NOT NULL,               -- cash, credit
+    status VARCHAR(20) NOT NULL DEFAULT 'issued',    -- issued, void
+    sub_total DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    taxable_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    non_taxable_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    note TEXT,
+    void_reason TEXT,
+    voided_at TIMESTAMP,
+    voided_by UUID,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_sales_invoice_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT uq_sales_invoices_number UNIQUE (tenant_id, invoice_number),
+    CONSTRAINT chk_sales_invoices_payment_mode CHECK (payment_mode IN ('cash', 'credit')),
+    CONSTRAINT chk_sales_invoices_status CHECK (status IN ('issued', 'void')),
+    CONSTRAINT chk_sales_invoices_credit_customer CHECK (payment_mode <> 'credit' OR customer_id IS NOT NULL),
+    CONSTRAINT chk_sales_invoices_void CHECK (status <> 'void' OR (void_reason IS NOT NULL AND voided_at IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_invoices_date ON sales_invoices(tenant_id, invoice_date DESC);
+CREATE INDEX IF NOT EXISTS idx_sales_invoices_customer ON sales_invoices(tenant_id, customer_id, invoice_date DESC)
+    WHERE customer_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS sales_invoice_lines (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    invoice_id UUID NOT NULL REFERENCES sales_invoices(id) ON DELETE CASCADE,
+    line_no INTEGER NOT NULL,
+    product_id UUID NOT NULL,
+    product_code VARCHAR(50) NOT NULL DEFAULT '',
+    product_name VARCHAR(255) NOT NULL,
+    unit VARCHAR(20) NOT NULL DEFAULT '',
+    quantity DECIMAL(15, 3) NOT NULL,
+    unit_price DECIMAL(15, 2) NOT NULL,
+    discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    net_amount DECIMAL(15, 2) NOT NULL,
+    tax_rate DECIMAL(7, 3) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15, 2) NOT NULL,
+
+    CONSTRAINT uq_sales_invoice_lines_no UNIQUE (invoice_id, line_no),
+    CONSTRAINT chk_sales_invoice_lines_amounts CHECK (
+        quantity > 0 AND unit_price >= 0 AND discount_amount >= 0 AND tax_amount >= 0
+    )
+);
+
+-- Demand statistics and sales summaries read lines by product over a date range
+CREATE INDEX IF NOT EXISTS idx_sales_invoice_lines_invoice ON sales_invoice_lines(invoice_id);
+CREATE INDEX IF NOT EXISTS idx_sales_invoice_lines_product ON sales_invoice_lines(tenant_id, product_id);
+
+-- Comments
+COMMENT ON TABLE sales_invoices IS 'Tax invoices for sales, numbered from the fiscal year invoice series';
+COMMENT ON COLUMN sales_invoices.buyer_name IS 'Customer name at sale time, or Cash for walk-in sales';
+COMMENT ON COLUMN sales_invoices.taxable_amount IS 'Net of lines carrying tax';
+COMMENT ON COLUMN sales_invoices.non_taxable_amount IS 'Net of exempt and zero-rated lines';
+COMMENT ON COLUMN sales_invoices.status IS 'void invoices keep their number so the series has no gap';
+COMMENT ON COLUMN sales_invoice_lines.tax_rate IS 'Percent summed over the product''s taxes on the invoice date';
+
+-- Enable RLS
+ALTER TABLE sales_invoices ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sales_invoice_lines ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON sales_invoices
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+CREATE POLICY tenant_isolation ON sales_invoice_lines
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,11 @@
+-- Sales Module: Invoice Warehouses
+-- Migration: 002_add_invoice_warehouses.sql
+-- Tenants with several outlets sell from each. An invoice without a warehouse takes
+-- its goods out of the tenant's default inventory warehouse.
+
+ALTER TABLE sales_invoices ADD COLUMN IF NOT EXISTS warehouse_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_sales_invoices_warehouse ON sales_invoices(tenant_id, warehouse_id, invoice_date DESC)
+    WHERE warehouse_id IS NOT NULL;
+
+COMMENT ON COLUMN sales_invoices.warehouse_id IS 'Inventory warehouse the goods were sold from; NULL for the default warehouse';
@@ -0,0 +1,8 @@
+-- Sales Module: Invoice Locale
+-- Migration: 003_add_invoice_locale.sql
+-- Product names on an invoice are copied in the language it is printed in, where the
+-- product is translated into it. Invoices without one carry the catalog's own names.
+
+ALTER TABLE sales_invoices ADD COLUMN IF NOT EXISTS locale VARCHAR(3);
+
+COMMENT ON COLUMN sales_invoices.locale IS 'Language product names were copied in; NULL for the catalog''s own names';
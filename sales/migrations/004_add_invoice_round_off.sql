@@ -0,0 +1,8 @@
+-- Sales Module: Invoice Round-off
+-- Migration: 004_add_invoice_round_off.sql
+-- Invoice totals are rounded by the tenant's rounding rule; the difference is shown
+-- on the invoice and posted to the round-off account.
+
+ALTER TABLE sales_invoices ADD COLUMN IF NOT EXISTS round_off DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN sales_invoices.round_off IS 'Added to the taxable, non-taxable and tax amounts to reach total_amount; negative when rounded down';
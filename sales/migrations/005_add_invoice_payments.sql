@@ -0,0 +1,19 @@
+-- Sales Module: Invoice Payments
+-- Migration: 005_add_invoice_payments.sql
+-- Payments recorded in the payments module are allocated to invoices; the amount
+-- paid so far is kept on the invoice so listings can show what is still due.
+
+ALTER TABLE sales_invoices ADD COLUMN IF NOT EXISTS amount_paid DECIMAL(15, 2) NOT NULL DEFAULT 0;
+ALTER TABLE sales_invoices ADD COLUMN IF NOT EXISTS payment_status VARCHAR(20) NOT NULL DEFAULT 'unpaid'; -- unpaid, partial, paid
+
+-- Cash sales were paid at the counter
+UPDATE sales_invoices
+SET amount_paid = total_amount, payment_status = 'paid'
+WHERE payment_mode = 'cash' AND payment_status = 'unpaid';
+
+-- A customer's invoices still due
+CREATE INDEX IF NOT EXISTS idx_sales_invoices_unpaid
+    ON sales_invoices(tenant_id, customer_id, invoice_date)
+    WHERE status = 'issued' AND payment_status <> 'paid';
+
+COMMENT ON COLUMN sales_invoices.amount_paid IS 'Paid at the counter for cash sales, or allocated from payments';
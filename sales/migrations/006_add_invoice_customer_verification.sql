@@ -0,0 +1,9 @@
+-- Sales Module: Customer Verification on Credit Invoices
+-- Migration: 006_add_invoice_customer_verification.sql
+-- A credit sale is made to a customer who read back a code sent to their phone or
+-- email. The verification is kept on the invoice to settle disputes over who took
+-- the credit.
+
+ALTER TABLE sales_invoices ADD COLUMN IF NOT EXISTS customer_verification JSONB;
+
+COMMENT ON COLUMN sales_invoices.customer_verification IS 'Proof of the customer''s code verification for a credit sale: verification ID, channel, masked recipient, when and by whom';
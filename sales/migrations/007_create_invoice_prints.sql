@@ -0,0 +1,32 @@
+-- Sales Module: Invoice Prints
+-- Migration: 007_create_invoice_prints.sql
+-- Every print and download of an invoice, including the customer opening it from a
+-- share link. The first copy output is the original and later copies are numbered,
+-- as IRD requires on reprints.
+
+ALTER TABLE sales_invoices ADD COLUMN IF NOT EXISTS print_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS sales_invoice_prints (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    invoice_id UUID NOT NULL REFERENCES sales_invoices(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL, -- PRINT, DOWNLOAD, SHARE_LINK
+    first_copy INTEGER NOT NULL, -- 0 is the original
+    copies INTEGER NOT NULL,
+    reason TEXT,
+    printed_by UUID,
+    ip_address VARCHAR(64),
+    printed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_invoice_prints_invoice ON sales_invoice_prints(invoice_id, printed_at);
+
+COMMENT ON COLUMN sales_invoices.print_count IS 'Copies printed or downloaded, including the original';
+
+ALTER TABLE sales_invoice_prints ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON sales_invoice_prints
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
@@ -0,0 +1,16 @@
+-- Sales Module: Pending Invoice Postings
+-- Migration: 008_add_invoice_pending_postings.sql
+-- An invoice is saved with the postings it still needs in other modules: the number
+-- ledger, the customer's khata, stock and the journal. Each is cleared once done;
+-- those that fail stay listed and are retried from retry_at, so a saved invoice is
+-- never left without its stock movement or journal entry.
+
+ALTER TABLE sales_invoices ADD COLUMN IF NOT EXISTS pending_postings JSONB;
+ALTER TABLE sales_invoices ADD COLUMN IF NOT EXISTS retry_at TIMESTAMP WITH TIME ZONE;
+
+-- The retry worker's queue
+CREATE INDEX IF NOT EXISTS idx_sales_invoices_retry ON sales_invoices(retry_at)
+    WHERE pending_postings IS NOT NULL;
+
+COMMENT ON COLUMN sales_invoices.pending_postings IS 'Postings not yet done: number_ledger, khata, stock, journal; NULL once all are';
+COMMENT ON COLUMN sales_invoices.retry_at IS 'When the pending postings are next retried';
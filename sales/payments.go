@@ -0,0 +1,63 @@
+package sales
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	paymentsDomain "github.com/aceextension/payments/domain"
+	"github.com/aceextension/sales/domain"
+	"github.com/google/uuid"
+)
+
+// openInvoicesLimit bounds how many of a customer's unpaid invoices automatic
+// allocation looks at
+const openInvoicesLimit = 500
+
+// paymentInvoice reads an invoice as a document customers pay. Cash sales were
+// settled at the counter, so payments cannot be allocated to them.
+func paymentInvoice(ctx context.Context, tenantID, id uuid.UUID) (*paymentsDomain.Document, error) {
+	invoice, err := InvoiceService.Get(ctx, tenantID, id)
+	if errors.Is(err, domain.ErrInvoiceNotFound) {
+		return nil, fmt.Errorf("%w: invoice %s", paymentsDomain.ErrDocumentNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPaymentDocument(invoice), nil
+}
+
+// openPaymentInvoices lists the customer's issued invoices not yet fully paid
+func openPaymentInvoices(ctx context.Context, tenantID, customerID uuid.UUID) ([]*paymentsDomain.Document, error) {
+	invoices, err := InvoiceService.List(ctx, tenantID, domain.InvoiceFilter{
+		CustomerID:  &customerID,
+		Outstanding: true,
+		Limit:       openInvoicesLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]*paymentsDomain.Document, 0, len(invoices))
+	for _, invoice := range invoices {
+		docs = append(docs, toPaymentDocument(invoice))
+	}
+	return docs, nil
+}
+
+// toPaymentDocument copies what payments need from an invoice
+func toPaymentDocument(invoice *domain.Invoice) *paymentsDomain.Document {
+	doc := &paymentsDomain.Document{
+		ID:        invoice.ID,
+		Number:    invoice.InvoiceNumber,
+		PartyID:   invoice.CustomerID,
+		PartyName: invoice.BuyerName,
+		Date:      invoice.InvoiceDate,
+		Total:     invoice.TotalAmount,
+		Void:      invoice.Status == domain.InvoiceVoid,
+	}
+	if invoice.PaymentMode == domain.PaymentCash {
+		doc.Settled = invoice.TotalAmount
+	}
+	return doc
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/sales/domain"
+	"github.com/google/uuid"
+)
+
+// InvoiceRepository defines the interface for sales invoice data access
+type InvoiceRepository interface {
+	// Create saves an invoice with its lines; the number is issued beforehand
+	Create(ctx context.Context, invoice *domain.Invoice) error
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Invoice, error)
+	// List retrieves invoices (without lines), newest first
+	List(ctx context.Context, tenantID uuid.UUID, filter domain.InvoiceFilter) ([]*domain.Invoice, error)
+	// SaveVoid saves a void; ErrInvoiceVoid if it was voided concurrently
+	SaveVoid(ctx context.Context, invoice *domain.Invoice) error
+	// SavePostings saves the invoice's pending postings and when they are retried
+	SavePostings(ctx context.Context, invoice *domain.Invoice) error
+	// ClaimPostingRetries takes up to limit invoices of any tenant whose pending postings
+	// are due a retry (without lines), holding them from other workers for lease
+	ClaimPostingRetries(ctx context.Context, lease time.Duration, limit int) ([]*domain.Invoice, error)
+	// SavePayment saves the amount paid and payment status
+	SavePayment(ctx context.Context, invoice *domain.Invoice) error
+	// Exists reports whether an invoice belongs to the tenant, for comment checks
+	Exists(ctx context.Context, tenantID, id uuid.UUID) (bool, error)
+	// HasInvoices reports whether the tenant has issued any invoice, voided or not
+	HasInvoices(ctx context.Context, tenantID uuid.UUID) (bool, error)
+	// RecordPrint numbers the copies of a print or download and logs it, setting record.FirstCopy
+	RecordPrint(ctx context.Context, record *domain.InvoicePrint) error
+	// ListPrints returns an invoice's print and download log, oldest first
+	ListPrints(ctx context.Context, tenantID, invoiceID uuid.UUID) ([]*domain.InvoicePrint, error)
+}
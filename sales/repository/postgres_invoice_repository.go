@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/sales/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresInvoiceRepository implements InvoiceRepository using PostgreSQL
+type PostgresInvoiceRepository struct{}
+
+// NewPostgresInvoiceRepository creates a new PostgreSQL invoice repository
+func NewPostgresInvoiceRepository() *PostgresInvoiceRepository {
+	return &PostgresInvoiceRepository{}
+}
+
+const invoiceColumns = `id, tenant_id, fiscal_year_id, invoice_number, invoice_date, customer_id,
+	buyer_name, buyer_pan, buyer_address, payment_mode, status,
+	sub_total, discount_amount, taxable_amount, non_taxable_amount, tax_amount, total_amount,
+	note, void_reason, voided_at, voided_by, created_by, created_at, updated_at, warehouse_id, locale, round_off,
+	amount_paid, payment_status, customer_verification, print_count, pending_postings, retry_at`
+
+const invoiceLineColumns = `id, invoice_id, line_no, product_id, product_code, product_name, unit,
+	quantity, unit_price, discount_amount, net_amount, tax_rate, tax_amount, total_amount`
+
+// Create creates an invoice and its lines
+func (r *PostgresInvoiceRepository) Create(ctx context.Context, invoice *domain.Invoice) error {
+	return db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO sales_invoices (`+invoiceColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33)
+		`,
+			invoice.ID, invoice.TenantID, invoice.FiscalYearID, invoice.InvoiceNumber, invoice.InvoiceDate, invoice.CustomerID,
+			invoice.BuyerName, invoice.BuyerPAN, invoice.BuyerAddress, invoice.PaymentMode, invoice.Status,
+			invoice.SubTotal, invoice.DiscountAmount, invoice.TaxableAmount, invoice.NonTaxableAmount, invoice.TaxAmount, invoice.TotalAmount,
+			invoice.Note, invoice.VoidReason, invoice.VoidedAt, invoice.VoidedBy, invoice.CreatedBy, invoice.CreatedAt, invoice.UpdatedAt,
+			invoice.WarehouseID, invoice.Locale, invoice.RoundOff,
+			invoice.AmountPaid, invoice.PaymentStatus, invoice.CustomerVerification, invoice.PrintCount,
+			pendingPostings(invoice), invoice.RetryAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create invoice: %w", err)
+		}
+
+		for _, line := range invoice.Lines {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO sales_invoice_lines (tenant_id, `+invoiceLineColumns+`)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			`,
+				invoice.TenantID, line.ID, invoice.ID, line.LineNo, line.ProductID, line.ProductCode, line.ProductName, line.Unit,
+				line.Quantity, line.UnitPrice, line.DiscountAmount, line.NetAmount, line.TaxRate, line.TaxAmount, line.TotalAmount,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create invoice line: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Get retrieves an invoice with its lines
+func (r *PostgresInvoiceRepository) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM sales_invoices WHERE tenant_id = $1 AND id = $2`
+
+	invoice, err := scanInvoice(db.MainPool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrInvoiceNotFound
+		}
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT `+invoiceLineColumns+`
+		FROM sales_invoice_lines
+		WHERE invoice_id = $1
+		ORDER BY line_no
+	`, invoice.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line domain.InvoiceLine
+		if err := rows.Scan(
+			&line.ID, &line.InvoiceID, &line.LineNo, &line.ProductID, &line.ProductCode, &line.ProductName, &line.Unit,
+			&line.Quantity, &line.UnitPrice, &line.DiscountAmount, &line.NetAmount, &line.TaxRate, &line.TaxAmount, &line.TotalAmount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice line: %w", err)
+		}
+		invoice.Lines = append(invoice.Lines, line)
+	}
+
+	return invoice, rows.Err()
+}
+
+// List retrieves invoices (without lines), newest first
+func (r *PostgresInvoiceRepository) List(ctx context.Context, tenantID uuid.UUID, filter domain.InvoiceFilter) ([]*domain.Invoice, error) {
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM sales_invoices
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR customer_id = $2)
+		  AND ($3::varchar IS NULL OR status = $3)
+		  AND ($4::date IS NULL OR invoice_date >= $4)
+		  AND ($5::date IS NULL OR invoice_date <= $5)
+		  AND ($6::varchar IS NULL OR payment_status = $6)
+		  AND (NOT $7 OR (status = 'issued' AND payment_status <> 'paid'))
+		ORDER BY invoice_date DESC, invoice_number DESC
+		LIMIT $8 OFFSET $9
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, filter.CustomerID, filter.Status, filter.From, filter.To,
+		filter.PaymentStatus, filter.Outstanding, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := []*domain.Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	return invoices, rows.Err()
+}
+
+// SaveVoid marks an issued invoice void
+func (r *PostgresInvoiceRepository) SaveVoid(ctx context.Context, invoice *domain.Invoice) error {
+	tag, err := db.MainPool.Exec(ctx, `
+		UPDATE sales_invoices
+		SET status = $3, void_reason = $4, voided_at = $5, voided_by = $6, updated_at = $7
+		WHERE tenant_id = $1 AND id = $2 AND status = 'issued'
+	`, invoice.TenantID, invoice.ID, invoice.Status, invoice.VoidReason, invoice.VoidedAt, invoice.VoidedBy, invoice.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to void invoice: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvoiceVoid
+	}
+	return nil
+}
+
+// SavePostings saves which postings are still pending and when they are retried
+func (r *PostgresInvoiceRepository) SavePostings(ctx context.Context, invoice *domain.Invoice) error {
+	_, err := db.MainPool.Exec(ctx, `
+		UPDATE sales_invoices
+		SET pending_postings = $3, retry_at = $4, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+	`, invoice.TenantID, invoice.ID, pendingPostings(invoice), invoice.RetryAt)
+	if err != nil {
+		return fmt.Errorf("failed to save invoice postings: %w", err)
+	}
+	return nil
+}
+
+// ClaimPostingRetries takes up to limit invoices of any tenant whose pending postings
+// are due, pushing their retry_at on by lease so another worker skips them meanwhile
+func (r *PostgresInvoiceRepository) ClaimPostingRetries(ctx context.Context, lease time.Duration, limit int) ([]*domain.Invoice, error) {
+	rows, err := db.MainPool.Query(ctx, `
+		UPDATE sales_invoices
+		SET retry_at = $1
+		WHERE id IN (
+			SELECT id FROM sales_invoices
+			WHERE pending_postings IS NOT NULL AND retry_at <= NOW()
+			ORDER BY retry_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+invoiceColumns,
+		time.Now().Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim invoice posting retries: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*domain.Invoice
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices, rows.Err()
+}
+
+// pendingPostings is the pending_postings value of an invoice, NULL when none are left
+func pendingPostings(invoice *domain.Invoice) any {
+	if len(invoice.PendingPostings) == 0 {
+		return nil
+	}
+	return invoice.PendingPostings
+}
+
+// SavePayment updates the amount paid and payment status
+func (r *PostgresInvoiceRepository) SavePayment(ctx context.Context, invoice *domain.Invoice) error {
+	tag, err := db.MainPool.Exec(ctx, `
+		UPDATE sales_invoices
+		SET amount_paid = $3, payment_status = $4, updated_at = $5
+		WHERE tenant_id = $1 AND id = $2
+	`, invoice.TenantID, invoice.ID, invoice.AmountPaid, invoice.PaymentStatus, invoice.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save invoice payment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvoiceNotFound
+	}
+	return nil
+}
+
+// Exists reports whether an invoice belongs to the tenant
+func (r *PostgresInvoiceRepository) Exists(ctx context.Context, tenantID, id uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM sales_invoices WHERE id = $1 AND tenant_id = $2)`
+	if err := db.MainPool.QueryRow(ctx, query, id, tenantID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check invoice: %w", err)
+	}
+	return exists, nil
+}
+
+// HasInvoices checks for any invoice of the tenant
+func (r *PostgresInvoiceRepository) HasInvoices(ctx context.Context, tenantID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM sales_invoices WHERE tenant_id = $1)`
+	if err := db.MainPool.QueryRow(ctx, query, tenantID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check invoices: %w", err)
+	}
+	return exists, nil
+}
+
+// RecordPrint reserves the next copy numbers on the invoice and logs the print in one
+// statement, so concurrent reprints never share a copy number
+func (r *PostgresInvoiceRepository) RecordPrint(ctx context.Context, record *domain.InvoicePrint) error {
+	query := `
+		WITH numbered AS (
+			UPDATE sales_invoices
+			SET print_count = print_count + $5
+			WHERE id = $3 AND tenant_id = $2
+			RETURNING print_count
+		)
+		INSERT INTO sales_invoice_prints (
+			id, tenant_id, invoice_id, kind, first_copy, copies, reason, printed_by, ip_address, printed_at
+		)
+		SELECT $1, $2, $3, $4, numbered.print_count - $5, $5, $6, $7, $8, $9 FROM numbered
+		RETURNING first_copy
+	`
+	err := db.MainPool.QueryRow(ctx, query,
+		record.ID, record.TenantID, record.InvoiceID, record.Kind, record.Copies,
+		record.Reason, record.PrintedBy, record.IPAddress, record.PrintedAt,
+	).Scan(&record.FirstCopy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record invoice print: %w", err)
+	}
+	return nil
+}
+
+// ListPrints returns an invoice's print log, oldest first
+func (r *PostgresInvoiceRepository) ListPrints(ctx context.Context, tenantID, invoiceID uuid.UUID) ([]*domain.InvoicePrint, error) {
+	rows, err := db.MainPool.Query(ctx, `
+		SELECT id, tenant_id, invoice_id, kind, first_copy, copies, reason, printed_by, ip_address, printed_at
+		FROM sales_invoice_prints
+		WHERE tenant_id = $1 AND invoice_id = $2
+		ORDER BY printed_at, first_copy
+	`, tenantID, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoice prints: %w", err)
+	}
+	defer rows.Close()
+
+	prints := []*domain.InvoicePrint{}
+	for rows.Next() {
+		var p domain.InvoicePrint
+		if err := rows.Scan(&p.ID, &p.TenantID, &p.InvoiceID, &p.Kind, &p.FirstCopy, &p.Copies,
+			&p.Reason, &p.PrintedBy, &p.IPAddress, &p.PrintedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice print: %w", err)
+		}
+		prints = append(prints, &p)
+	}
+	return prints, rows.Err()
+}
+
+// scanInvoice scans a sales_invoices row in invoiceColumns order
+func scanInvoice(row pgx.Row) (*domain.Invoice, error) {
+	invoice := domain.Invoice{Lines: []domain.InvoiceLine{}}
+	err := row.Scan(
+		&invoice.ID, &invoice.TenantID, &invoice.FiscalYearID, &invoice.InvoiceNumber, &invoice.InvoiceDate, &invoice.CustomerID,
+		&invoice.BuyerName, &invoice.BuyerPAN, &invoice.BuyerAddress, &invoice.PaymentMode, &invoice.Status,
+		&invoice.SubTotal, &invoice.DiscountAmount, &invoice.TaxableAmount, &invoice.NonTaxableAmount, &invoice.TaxAmount, &invoice.TotalAmount,
+		&invoice.Note, &invoice.VoidReason, &invoice.VoidedAt, &invoice.VoidedBy, &invoice.CreatedBy, &invoice.CreatedAt, &invoice.UpdatedAt,
+		&invoice.WarehouseID, &invoice.Locale, &invoice.RoundOff,
+		&invoice.AmountPaid, &invoice.PaymentStatus, &invoice.CustomerVerification, &invoice.PrintCount,
+		&invoice.PendingPostings, &invoice.RetryAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
@@ -0,0 +1,457 @@
+package sales
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aceextension/accounting"
+	accountingDomain "github.com/aceextension/accounting/domain"
+	"github.com/aceextension/analytics"
+	analyticsDomain "github.com/aceextension/analytics/domain"
+	"github.com/aceextension/automation"
+	automationDomain "github.com/aceextension/automation/domain"
+	"github.com/aceextension/catalog"
+	catalogDomain "github.com/aceextension/catalog/domain"
+	"github.com/aceextension/comments"
+	commentsDomain "github.com/aceextension/comments/domain"
+	"github.com/aceextension/core/config"
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/crm"
+	crmDomain "github.com/aceextension/crm/domain"
+	"github.com/aceextension/fiscal"
+	fiscalDomain "github.com/aceextension/fiscal/domain"
+	fiscalUtils "github.com/aceextension/fiscal/utils"
+	"github.com/aceextension/notification"
+	notificationDomain "github.com/aceextension/notification/domain"
+	notificationService "github.com/aceextension/notification/service"
+	"github.com/aceextension/onboarding"
+	onboardingDomain "github.com/aceextension/onboarding/domain"
+	"github.com/aceextension/payments"
+	paymentsDomain "github.com/aceextension/payments/domain"
+	paymentsService "github.com/aceextension/payments/service"
+	"github.com/aceextension/sales/domain"
+	"github.com/aceextension/sales/repository"
+	"github.com/aceextension/sales/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// khataReferenceInvoice is the khata reference type of credit sales
+const khataReferenceInvoice = "invoice"
+
+// salesFactSQL is one row per line of issued invoices, for analytics summaries
+const salesFactSQL = `
+	SELECT i.invoice_date AS txn_date,
+	       l.product_id, l.product_name,
+	       p.category_id, c.name AS category_name,
+	       i.customer_id AS party_id, i.buyer_name AS party_name,
+	       i.created_by AS salesperson_id, u.name AS salesperson_name,
+	       l.quantity AS qty, l.net_amount AS net, l.tax_amount AS vat, l.total_amount AS gross
+	FROM sales_invoices i
+	JOIN sales_invoice_lines l ON l.invoice_id = i.id
+	LEFT JOIN products p ON p.id = l.product_id
+	LEFT JOIN categories c ON c.id = p.category_id
+	LEFT JOIN users u ON u.id = i.created_by
+	WHERE i.tenant_id = $1 AND i.status = 'issued' AND i.invoice_date >= $2 AND i.invoice_date < $3`
+
+// salesDemandSQL is the quantity sold per product and day on issued invoices
+const salesDemandSQL = `
+	SELECT i.invoice_date AS day, l.product_id, SUM(l.quantity) AS qty
+	FROM sales_invoices i
+	JOIN sales_invoice_lines l ON l.invoice_id = i.id
+	WHERE i.tenant_id = $1 AND i.status = 'issued' AND i.invoice_date >= $2 AND i.invoice_date < $3
+	GROUP BY 1, 2`
+
+// invoiceTriggerFields are the fields of domain.InvoiceChange automations can use
+var invoiceTriggerFields = []automationDomain.TriggerField{
+	{Name: "id", Type: automationDomain.FieldID, EntityType: automationDomain.EntityInvoice},
+	{Name: "invoiceNumber", Type: automationDomain.FieldString},
+	{Name: "invoiceDate", Type: automationDomain.FieldString},
+	{Name: "customerId", Type: automationDomain.FieldID, EntityType: automationDomain.EntityCustomer},
+	{Name: "buyerName", Type: automationDomain.FieldString},
+	{Name: "paymentMode", Type: automationDomain.FieldString},
+	{Name: "status", Type: automationDomain.FieldString},
+	{Name: "taxAmount", Type: automationDomain.FieldNumber},
+	{Name: "totalAmount", Type: automationDomain.FieldNumber},
+}
+
+// Global service instances
+var (
+	InvoiceService service.InvoiceService
+)
+
+// Init initializes the sales module. Call fiscal.Init, catalog.Init and crm.Init
+// first; accounting, payments, analytics, comments, onboarding, automation and
+// notification are registered with when they have been initialized.
+func Init() {
+	invoiceRepo := repository.NewPostgresInvoiceRepository()
+
+	InvoiceService = service.NewInvoiceService(invoiceRepo, crmCustomers{}, catalogProducts{}, fiscalNumbers{}, fiscalRounding{}, fiscalDates{})
+	if crm.KhataService != nil {
+		InvoiceService.SetCreditLedger(khataCredit{})
+	}
+
+	// Invoices are sent to customers through the notification module with signed
+	// links; call notification.Init first
+	if notification.Service != nil {
+		InvoiceService.SetMessenger(notificationMessenger{})
+	}
+	if cfg := config.GlobalConfig; cfg != nil {
+		InvoiceService.SetShareLinks(service.NewShareLinks(cfg.ShareLinkSecret, cfg.AppURL, cfg.PublicAPIURL, time.Duration(cfg.ShareLinkDays)*24*time.Hour))
+	}
+
+	// Issued and voided invoices are posted to the journal; call accounting.Init first
+	if accounting.Service != nil {
+		accounting.Service.RegisterPostingSource(accountingDomain.ReferenceInvoice, postInvoice)
+		accounting.Service.RegisterPostingSource(accountingDomain.ReferenceInvoiceVoid, postInvoiceVoid)
+		InvoiceService.SetJournal(accountingJournal{})
+	}
+
+	// Customers' receipts are allocated to credit invoices, which track what is
+	// still due; call payments.Init first
+	if payments.Service != nil {
+		payments.Service.RegisterDocumentType(paymentsDomain.DocumentInvoice, paymentsService.DocumentSource{
+			Direction: paymentsDomain.DirectionReceipt,
+			Get:       paymentInvoice,
+			Open:      openPaymentInvoices,
+			Paid:      InvoiceService.SetAmountPaid,
+		})
+	}
+
+	// Sold quantities feed demand statistics and reorder suggestions
+	if catalog.DemandService != nil {
+		catalog.DemandService.RegisterSource(catalogDomain.DemandSource{Name: "sales", DailySQL: salesDemandSQL})
+	}
+
+	// Sales summaries and the built-in dashboards' sales widgets; call analytics.Init first
+	if analytics.PivotService != nil {
+		analytics.PivotService.RegisterSource(analyticsDomain.Source{
+			Name:    "sales",
+			FactSQL: salesFactSQL,
+			Dimensions: []analyticsDomain.Dimension{
+				analyticsDomain.DimensionProduct,
+				analyticsDomain.DimensionCategory,
+				analyticsDomain.DimensionCustomer,
+				analyticsDomain.DimensionSalesperson,
+				analyticsDomain.DimensionMonthBS,
+			},
+		})
+	}
+
+	// Teams can discuss invoices; call comments.Init first
+	if comments.CommentService != nil {
+		comments.CommentService.RegisterEntityType(commentsDomain.EntityInvoice, invoiceRepo.Exists)
+	}
+
+	// Tenants can automate on invoices being issued and voided; call automation.Init first
+	if automation.AutomationService != nil {
+		automation.AutomationService.RegisterTrigger(automationDomain.Trigger{
+			Event:       domain.EventInvoiceCreated,
+			Description: "An invoice is issued",
+			Fields:      invoiceTriggerFields,
+		})
+		automation.AutomationService.RegisterTrigger(automationDomain.Trigger{
+			Event:       domain.EventInvoiceVoided,
+			Description: "An invoice is voided",
+			Fields:      invoiceTriggerFields,
+		})
+	}
+
+	// Issuing an invoice is the last setup step; call onboarding.Init first
+	if onboarding.ChecklistService != nil {
+		onboarding.ChecklistService.RegisterStep(onboardingDomain.Step{Key: onboardingDomain.StepFirstInvoice, Title: "Issue your first invoice"}, invoiceRepo.HasInvoices)
+	}
+}
+
+// postingRetryInterval is how often invoices with failed postings are looked for
+const postingRetryInterval = time.Minute
+
+// StartPostingRetryWorker retries the failed stock, khata, ledger and journal postings
+// of invoices every postingRetryInterval. Call once after Init and after the modules
+// set on InvoiceService, from the process that hosts background jobs.
+func StartPostingRetryWorker() {
+	go func() {
+		ticker := time.NewTicker(postingRetryInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := InvoiceService.RetryPostings(context.Background()); err != nil {
+				logger.Log.Error("Invoice posting retry worker error: " + err.Error())
+			}
+		}
+	}()
+}
+
+// crmCustomers makes invoices out to crm customers
+type crmCustomers struct{}
+
+// Buyer returns the customer's name, PAN and address
+func (crmCustomers) Buyer(ctx context.Context, tenantID, customerID uuid.UUID) (*domain.Buyer, error) {
+	if crm.CustomerService == nil {
+		return nil, domain.ErrCustomerNotFound
+	}
+	customer, err := crm.CustomerService.GetByID(ctx, tenantID, customerID)
+	if err != nil {
+		return nil, domain.ErrCustomerNotFound
+	}
+
+	buyer := &domain.Buyer{
+		CustomerID: customer.ID,
+		Name:       customer.Name,
+		Blocked:    customer.Status == crmDomain.CustomerStatusBlocked,
+	}
+	if pan := customer.GetPANNumber(); pan != "" {
+		buyer.PAN = &pan
+	}
+	if address := customer.GetAddress(); address != "" {
+		buyer.Address = &address
+	}
+	buyer.Contact = domain.Contact{Email: customer.Email, Phone: customer.Phone}
+	return buyer, nil
+}
+
+// ClaimVerification records the customer's verification on the invoice being created
+func (crmCustomers) ClaimVerification(ctx context.Context, tenantID, verificationID, customerID, invoiceID uuid.UUID) (*domain.CustomerVerification, error) {
+	if crm.CustomerOTPService == nil {
+		return nil, domain.ErrCustomerNotVerified
+	}
+	proof, err := crm.CustomerOTPService.AttachToInvoice(ctx, tenantID, verificationID, customerID, invoiceID)
+	if errors.Is(err, crmDomain.ErrOTPNotFound) || errors.Is(err, crmDomain.ErrOTPNotVerified) {
+		return nil, domain.ErrCustomerNotVerified
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record customer verification: %w", err)
+	}
+
+	return &domain.CustomerVerification{
+		VerificationID: proof.VerificationID,
+		Channel:        string(proof.Channel),
+		Recipient:      proof.Recipient,
+		VerifiedAt:     proof.VerifiedAt,
+		VerifiedBy:     proof.VerifiedBy,
+	}, nil
+}
+
+// catalogProducts prices lines from catalog products and taxes them with their tax groups
+type catalogProducts struct{}
+
+// InvoiceLocale checks the requested language, or looks up the tenant's invoice language
+func (catalogProducts) InvoiceLocale(ctx context.Context, tenantID uuid.UUID, requested *string) (string, error) {
+	if requested != nil {
+		locale, err := catalogDomain.NormalizeLocale(*requested)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", domain.ErrInvalidInvoice, err.Error())
+		}
+		return locale, nil
+	}
+	if catalog.LocalizationService == nil {
+		return "", nil
+	}
+	return catalog.LocalizationService.DocumentLocale(ctx, tenantID, catalogDomain.DocumentSalesInvoice)
+}
+
+// SaleProduct returns the product's code, name in locale, unit and selling price
+func (catalogProducts) SaleProduct(ctx context.Context, tenantID, productID uuid.UUID, locale string) (*domain.SaleProduct, error) {
+	if catalog.ProductService == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	product, err := catalog.ProductService.GetByID(ctx, tenantID, productID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrProductNotFound, productID)
+	}
+	if locale != "" && catalog.LocalizationService != nil {
+		if err := catalog.LocalizationService.LocalizeProducts(ctx, tenantID, []string{locale}, product); err != nil {
+			return nil, err
+		}
+	}
+
+	return &domain.SaleProduct{
+		ID:        product.ID,
+		Code:      product.ProductCode,
+		Name:      product.Name,
+		Unit:      product.Unit,
+		Price:     product.SellingPrice,
+		Available: product.IsAvailable(),
+	}, nil
+}
+
+// ComputeTax applies the product's tax group on the invoice date, or its flat rate
+func (catalogProducts) ComputeTax(ctx context.Context, tenantID, productID uuid.UUID, amount float64, date time.Time) (float64, float64, error) {
+	product, err := catalog.ProductService.GetByID(ctx, tenantID, productID)
+	if err != nil {
+		return 0, 0, err
+	}
+	breakdown, err := catalog.TaxService.ComputeProductTax(ctx, product, amount, date)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	rate := 0.0
+	for _, line := range breakdown.Lines {
+		rate += line.Rate
+	}
+	return rate, breakdown.TotalTax, nil
+}
+
+// CheckSalePrice applies the catalog's MRP and discount guardrails to a line's price,
+// redeeming the override when one lifts the discount limit
+func (catalogProducts) CheckSalePrice(ctx context.Context, tenantID, productID uuid.UUID, unitPrice float64, role string, overrideID *uuid.UUID) error {
+	if catalog.GuardrailService == nil {
+		return nil
+	}
+	_, err := catalog.GuardrailService.CheckSale(ctx, tenantID, productID, unitPrice, role, overrideID)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, catalogDomain.ErrAboveMRP), errors.Is(err, catalogDomain.ErrDiscountLimitExceeded),
+		errors.Is(err, catalogDomain.ErrInvalidDiscountOverride):
+		return fmt.Errorf("%w: %s", domain.ErrPriceNotAllowed, err.Error())
+	default:
+		return fmt.Errorf("failed to check sale price: %w", err)
+	}
+}
+
+// fiscalRounding rounds invoice totals by the tenant's invoice rounding rule
+type fiscalRounding struct{}
+
+// RoundTotal rounds an invoice total
+func (fiscalRounding) RoundTotal(ctx context.Context, tenantID uuid.UUID, total float64) (float64, error) {
+	rule, err := fiscal.RoundingRule(ctx, tenantID, fiscalDomain.DocumentInvoice)
+	if err != nil {
+		return 0, err
+	}
+	return rule.Round(total), nil
+}
+
+// fiscalDates prints invoice dates in the tenant's preferred calendar first
+type fiscalDates struct{}
+
+// DateDisplay returns which calendar the tenant prints first
+func (fiscalDates) DateDisplay(ctx context.Context, tenantID uuid.UUID) fiscalUtils.DateDisplay {
+	return fiscal.DateDisplay(ctx, tenantID)
+}
+
+// fiscalNumbers numbers invoices from their date's fiscal year invoice series
+type fiscalNumbers struct{}
+
+// Issue takes the next invoice number of the fiscal year the date falls in, so a
+// backdated invoice is numbered in its own year's series
+func (fiscalNumbers) Issue(ctx context.Context, tenantID uuid.UUID, date time.Time) (uuid.UUID, string, error) {
+	fy, err := fiscal.FiscalYearOn(ctx, tenantID, date)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("failed to find fiscal year: %w", err)
+	}
+	if fy == nil {
+		return uuid.Nil, "", domain.ErrNoFiscalYear
+	}
+	if fy.IsClosed {
+		return uuid.Nil, "", fmt.Errorf("%w: %s", domain.ErrFiscalYearClosed, fy.Name)
+	}
+	number, err := fiscal.Service.GenerateInvoiceNumber(ctx, tenantID, fy.ID)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("failed to issue invoice number: %w", err)
+	}
+	return fy.ID, number, nil
+}
+
+// Record attaches the invoice to its number in the number ledger
+func (fiscalNumbers) Record(ctx context.Context, invoice *domain.Invoice) error {
+	return fiscal.RecordDocument(ctx, invoice.TenantID, fiscalDomain.DocumentInvoice, invoice.InvoiceNumber, invoice.ID)
+}
+
+// khataCredit charges credit sales to the customer's khata on their payment terms
+type khataCredit struct{}
+
+// ChargeInvoice records the invoice total as goods given on credit
+func (khataCredit) ChargeInvoice(ctx context.Context, invoice *domain.Invoice) error {
+	entry := crmDomain.NewKhataEntry(invoice.TenantID, *invoice.CustomerID, crmDomain.KhataCharge, invoice.TotalAmount, invoice.InvoiceDate, time.Time{})
+	referenceType := khataReferenceInvoice
+	note := "Invoice " + invoice.InvoiceNumber
+	entry.ReferenceType = &referenceType
+	entry.ReferenceID = &invoice.ID
+	entry.Note = &note
+	entry.CreatedBy = invoice.CreatedBy
+
+	_, err := crm.KhataService.RecordCharge(ctx, entry)
+	return err
+}
+
+// notificationMessenger sends invoices through the notification module, with the
+// tenant's INVOICE_SEND template for the channel where it has an active one
+type notificationMessenger struct{}
+
+// Send queues the message, linked to the customer and invoice for their communication history
+func (notificationMessenger) Send(ctx context.Context, msg domain.InvoiceMessage) (*domain.InvoiceDelivery, error) {
+	invoice := msg.Invoice
+	channel := notificationDomain.ChannelEmail
+	if msg.Channel == domain.SendSMS {
+		channel = notificationDomain.ChannelSMS
+	}
+	variables := map[string]interface{}{
+		"invoiceNumber": invoice.InvoiceNumber,
+		"invoiceDate":   invoice.InvoiceDate.Format("2006-01-02"),
+		"buyerName":     invoice.BuyerName,
+		"totalAmount":   fmt.Sprintf("%.2f", invoice.TotalAmount),
+		"link":          msg.Link,
+		"linkExpiresAt": msg.LinkExpiresAt.Format("2006-01-02"),
+		"message":       msg.Message,
+	}
+	referenceType := domain.InvoiceReference
+	req := notificationService.SendRequest{
+		TenantID:      invoice.TenantID,
+		Channel:       channel,
+		Recipient:     msg.Recipient,
+		Variables:     variables,
+		Priority:      notificationDomain.PriorityLow,
+		CustomerID:    invoice.CustomerID,
+		ReferenceType: &referenceType,
+		ReferenceID:   &invoice.ID,
+	}
+
+	template, err := notification.TemplateRepo.GetByCode(ctx, invoice.TenantID, domain.InvoiceSendTemplate, channel)
+	switch {
+	case err == nil && template.IsActive:
+		req.TemplateID = &template.ID
+	case err == nil, errors.Is(err, pgx.ErrNoRows):
+		req.Subject, req.Content = invoiceMessage(msg)
+	default:
+		return nil, fmt.Errorf("template %s: %w", domain.InvoiceSendTemplate, err)
+	}
+
+	sent, err := notification.Service.Send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.InvoiceDelivery{
+		Channel:        msg.Channel,
+		Recipient:      msg.Recipient,
+		NotificationID: &sent.ID,
+		Status:         string(sent.Status),
+	}, nil
+}
+
+// invoiceMessage is the built-in subject and body of a sent invoice. Notifications
+// carry text only, so the message links to the invoice rather than attaching it.
+func invoiceMessage(msg domain.InvoiceMessage) (string, string) {
+	invoice := msg.Invoice
+	subject := "Invoice " + invoice.InvoiceNumber
+	if msg.Channel == domain.SendSMS {
+		body := fmt.Sprintf("Invoice %s of NPR %.2f: %s", invoice.InvoiceNumber, invoice.TotalAmount, msg.Link)
+		if msg.Message != "" {
+			body = msg.Message + " " + body
+		}
+		return subject, body
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dear %s,\n\n", invoice.BuyerName)
+	if msg.Message != "" {
+		b.WriteString(msg.Message + "\n\n")
+	}
+	fmt.Fprintf(&b, "Please find invoice %s dated %s for NPR %.2f at the link below.\n\n%s\n\n",
+		invoice.InvoiceNumber, invoice.InvoiceDate.Format("2006-01-02"), invoice.TotalAmount, msg.Link)
+	fmt.Fprintf(&b, "The link works until %s.\n", msg.LinkExpiresAt.Format("2006-01-02"))
+	return subject, b.String()
+}
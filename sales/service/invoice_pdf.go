@@ -0,0 +1,177 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aceextension/core/pdf"
+	fiscalUtils "github.com/aceextension/fiscal/utils"
+	"github.com/aceextension/sales/domain"
+)
+
+// RenderInvoicePDF lays out a sales invoice as an A4 tax invoice, once per copy label
+// (see InvoicePrint.Labels). Lines that do not fit continue on further pages. Each
+// copy carries its label under the title, and copies after the original are
+// watermarked with it; void invoices are marked and watermarked VOID instead. Without
+// labels a single unmarked copy is rendered. The invoice date is printed in both
+// calendars, display's first.
+func RenderInvoicePDF(invoice *domain.Invoice, display fiscalUtils.DateDisplay, labels ...string) []byte {
+	doc := pdf.New("TAX INVOICE " + invoice.InvoiceNumber)
+	if len(labels) == 0 {
+		labels = []string{""}
+	}
+	for _, label := range labels {
+		renderInvoiceCopy(doc, invoice, display, label)
+	}
+	return doc.Bytes()
+}
+
+// renderInvoiceCopy draws one copy of the invoice from a new page
+func renderInvoiceCopy(doc *pdf.Document, invoice *domain.Invoice, display fiscalUtils.DateDisplay, label string) {
+	const left, right = 50.0, pdf.PageWidth - 50
+	const bottom = pdf.PageHeight - 90
+
+	page := newInvoicePage(doc, invoice, label)
+	y := 60.0
+
+	page.TextRight(right, y, 14, true, "TAX INVOICE")
+	markY := y + 18
+	if invoice.Status == domain.InvoiceVoid {
+		page.TextRight(right, markY, 12, true, "VOID")
+		markY += 16
+	}
+	if label != "" {
+		page.TextRight(right, markY, 10, true, label)
+	}
+
+	// Buyer and invoice details side by side
+	page.Text(left, y, 9, true, "BILL TO")
+	y += 14
+	page.Text(left, y, 10, true, invoice.BuyerName)
+	y += 13
+	if invoice.BuyerAddress != nil && *invoice.BuyerAddress != "" {
+		page.Text(left, y, 9, false, *invoice.BuyerAddress)
+		y += 12
+	}
+	if invoice.BuyerPAN != nil && *invoice.BuyerPAN != "" {
+		page.Text(left, y, 9, false, "PAN/VAT No: "+*invoice.BuyerPAN)
+		y += 12
+	}
+
+	meta := [][2]string{
+		{"Invoice No.", invoice.InvoiceNumber},
+		{"Invoice Date", fiscalUtils.FormatDualDate(invoice.InvoiceDate, display)},
+		{"Payment", string(invoice.PaymentMode)},
+	}
+	my := 100.0
+	if label != "" && invoice.Status == domain.InvoiceVoid {
+		my += 16 // Below both marks
+	}
+	for _, m := range meta {
+		page.Text(330, my, 9, true, m[0])
+		page.TextRight(right, my, 9, false, m[1])
+		my += 14
+	}
+	if my > y {
+		y = my
+	}
+	y += 20
+
+	// Line items
+	header := func() {
+		page.FillRect(left, y-12, right-left, 18, 0.9)
+		page.Text(left+6, y, 9, true, "Description")
+		page.TextRight(right-230, y, 9, true, "Qty")
+		page.TextRight(right-160, y, 9, true, "Rate")
+		page.TextRight(right-90, y, 9, true, "Discount")
+		page.TextRight(right-6, y, 9, true, "Amount")
+		y += 22
+	}
+	header()
+	for _, line := range invoice.Lines {
+		if y > bottom {
+			page = newInvoicePage(doc, invoice, label)
+			y = 60
+			header()
+		}
+		page.Text(left+6, y, 10, false, line.ProductName)
+		page.TextRight(right-230, y, 10, false, strings.TrimSpace(trimZeros(line.Quantity)+" "+line.Unit))
+		page.TextRight(right-160, y, 10, false, formatMoney(line.UnitPrice))
+		page.TextRight(right-90, y, 10, false, formatMoney(line.DiscountAmount))
+		page.TextRight(right-6, y, 10, false, formatMoney(line.NetAmount))
+		y += 16
+	}
+	page.Line(left, y, right, y, 0.5)
+	y += 20
+
+	// Totals
+	totals := [][2]string{
+		{"Sub Total", formatMoney(invoice.SubTotal)},
+		{"Discount", formatMoney(invoice.DiscountAmount)},
+		{"Taxable Amount", formatMoney(invoice.TaxableAmount)},
+		{"Non-taxable Amount", formatMoney(invoice.NonTaxableAmount)},
+		{"VAT", formatMoney(invoice.TaxAmount)},
+	}
+	if invoice.RoundOff != 0 {
+		totals = append(totals, [2]string{"Round Off", formatMoney(invoice.RoundOff)})
+	}
+	if y+float64(len(totals)+1)*16 > bottom {
+		page = newInvoicePage(doc, invoice, label)
+		y = 60
+	}
+	for _, t := range totals {
+		page.Text(330, y, 10, false, t[0])
+		page.TextRight(right-6, y, 10, false, t[1])
+		y += 16
+	}
+	page.Line(330, y-8, right, y-8, 0.5)
+	y += 6
+	page.Text(330, y, 11, true, "Total (NPR)")
+	page.TextRight(right-6, y, 11, true, formatMoney(invoice.TotalAmount))
+
+	if invoice.Note != nil && *invoice.Note != "" {
+		page.Text(left, y+30, 9, false, *invoice.Note)
+	}
+}
+
+// newInvoicePage adds a page of a copy with the footer, watermarked VOID when the
+// invoice is void and otherwise with the copy's label after the original
+func newInvoicePage(doc *pdf.Document, invoice *domain.Invoice, label string) *pdf.Page {
+	page := doc.AddPage()
+	switch {
+	case invoice.Status == domain.InvoiceVoid:
+		page.Watermark("VOID", 96)
+	case label != "" && label != domain.OriginalLabel:
+		page.Watermark(label, 64)
+	}
+	page.Text(50, pdf.PageHeight-50, 8, false, "This is a computer-generated invoice and does not require a signature.")
+	return page
+}
+
+// formatMoney formats an amount with thousands separators: 12,345.00
+func formatMoney(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	intPart, frac := s[:len(s)-3], s[len(s)-3:]
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+
+	out := b.String() + frac
+	if neg {
+		out = "-" + out
+	}
+	return out
+}
+
+func trimZeros(v float64) string {
+	s := fmt.Sprintf("%.3f", v)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,665 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/audit"
+	auditDomain "github.com/aceextension/audit/domain"
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/events"
+	"github.com/aceextension/core/logger"
+	fiscalUtils "github.com/aceextension/fiscal/utils"
+	"github.com/aceextension/sales/domain"
+	"github.com/aceextension/sales/repository"
+	"github.com/google/uuid"
+)
+
+// InvoiceService defines the interface for sales invoices
+type InvoiceService interface {
+	// Create prices the lines from the catalog where no price is given, computes
+	// tax, rounds the total, numbers the invoice from the fiscal year its date falls in
+	// and saves it. It is saved with its postings: the number is recorded in the number
+	// ledger, credit sales are charged to the customer's khata, the goods leave stock and
+	// the sale is posted to the journal. Postings that fail stay on the invoice for
+	// RetryPostings, and Create returns ErrPostingsPending with the invoice saved.
+	Create(ctx context.Context, invoice *domain.Invoice, lines []InvoiceLineInput) error
+	// RetryPostings retries the pending postings of every tenant's invoices that are
+	// due, returning how many invoices are now fully posted
+	RetryPostings(ctx context.Context) (int, error)
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Invoice, error)
+	List(ctx context.Context, tenantID uuid.UUID, filter domain.InvoiceFilter) ([]*domain.Invoice, error)
+	// Void cancels an invoice with a reason; its number stays used, the goods go back
+	// into stock and its journal entry is reversed. ErrPostingsPending while the
+	// invoice's own postings are still queued.
+	Void(ctx context.Context, tenantID, id uuid.UUID, reason string, userID *uuid.UUID) (*domain.Invoice, error)
+	// SetAmountPaid records the total paid against an invoice, as allocated from payments
+	SetAmountPaid(ctx context.Context, tenantID, id uuid.UUID, amount float64) error
+	// Send messages an issued invoice to its customer by email, SMS or both, linking
+	// to its public page or PDF. Every message is recorded in the customer's
+	// communication history.
+	Send(ctx context.Context, tenantID, id uuid.UUID, send domain.InvoiceSend, userID *uuid.UUID) (*domain.InvoiceSendResult, error)
+	// PrintPDF logs a print or download and renders the invoice as a printable tax
+	// invoice, one copy per requested copy: the first copy ever output is the original,
+	// later ones are marked "COPY n OF m"
+	PrintPDF(ctx context.Context, req PrintRequest) (*domain.Invoice, *domain.InvoicePrint, []byte, error)
+	// ListPrints returns the invoice's print and reprint log
+	ListPrints(ctx context.Context, tenantID, id uuid.UUID) ([]*domain.InvoicePrint, error)
+	// OpenShared returns the invoice a share link opens; ErrInvalidShareLink once it expires
+	OpenShared(ctx context.Context, token string) (*domain.Invoice, error)
+	// SharedPDF logs and renders the invoice a share link opens, numbered like a download
+	SharedPDF(ctx context.Context, token, ipAddress string) (*domain.Invoice, *domain.InvoicePrint, []byte, error)
+
+	SetCreditLedger(ledger CreditLedger)
+	SetStock(stock InvoiceStock)
+	SetJournal(journal InvoiceJournal)
+	SetMessenger(messenger InvoiceMessenger)
+	SetShareLinks(links *ShareLinks)
+}
+
+// InvoiceLineInput is a product sold. Without a unit price the product's selling price applies.
+// OverrideID is an approved discount override for a price below the seller's limit.
+type InvoiceLineInput struct {
+	ProductID  uuid.UUID
+	Quantity   float64
+	UnitPrice  *float64
+	Discount   float64
+	OverrideID *uuid.UUID
+}
+
+// PrintRequest is a print or download of an invoice by a tenant user
+type PrintRequest struct {
+	TenantID  uuid.UUID
+	InvoiceID uuid.UUID
+	Kind      domain.PrintKind
+	Copies    int
+	Reason    string // Optional, e.g. "customer lost original"
+	UserID    *uuid.UUID
+	IPAddress string
+}
+
+// CustomerDirectory finds who an invoice is made out to. Init uses crm customers.
+type CustomerDirectory interface {
+	// Buyer returns ErrCustomerNotFound if the tenant has no such customer
+	Buyer(ctx context.Context, tenantID, customerID uuid.UUID) (*domain.Buyer, error)
+	// ClaimVerification binds a verification of the customer to the invoice being
+	// created; ErrCustomerNotVerified unless it is verified, fresh, for the customer
+	// and not used by another sale
+	ClaimVerification(ctx context.Context, tenantID, verificationID, customerID, invoiceID uuid.UUID) (*domain.CustomerVerification, error)
+}
+
+// ProductCatalog prices and taxes products. Init uses catalog products and tax groups.
+type ProductCatalog interface {
+	// InvoiceLocale returns the language to copy product names in: the requested one,
+	// or the tenant's invoice language; empty for the catalog's own names
+	InvoiceLocale(ctx context.Context, tenantID uuid.UUID, requested *string) (string, error)
+	// SaleProduct returns ErrProductNotFound if the tenant has no such product. The name
+	// is in locale where the product is translated into it.
+	SaleProduct(ctx context.Context, tenantID, productID uuid.UUID, locale string) (*domain.SaleProduct, error)
+	// ComputeTax returns the tax rate and amount on a tax-exclusive amount on a date
+	ComputeTax(ctx context.Context, tenantID, productID uuid.UUID, amount float64, date time.Time) (float64, float64, error)
+	// CheckSalePrice returns ErrPriceNotAllowed if a tax-exclusive unit price is above MRP
+	// or below the role's discount limit without a valid override
+	CheckSalePrice(ctx context.Context, tenantID, productID uuid.UUID, unitPrice float64, role string, overrideID *uuid.UUID) error
+}
+
+// InvoiceNumbers issues invoice numbers. Init uses the fiscal year's invoice series.
+type InvoiceNumbers interface {
+	// Issue takes the next number of the fiscal year the date falls in; ErrNoFiscalYear
+	// without one, ErrFiscalYearClosed if it is closed
+	Issue(ctx context.Context, tenantID uuid.UUID, date time.Time) (uuid.UUID, string, error)
+	// Record marks the number used by the saved invoice
+	Record(ctx context.Context, invoice *domain.Invoice) error
+}
+
+// InvoiceRounding rounds invoice totals. Init uses the tenant's fiscal rounding rule for invoices.
+type InvoiceRounding interface {
+	// RoundTotal returns an invoice total rounded by the tenant's rule
+	RoundTotal(ctx context.Context, tenantID uuid.UUID, total float64) (float64, error)
+}
+
+// InvoiceDates tells which calendar a tenant's invoices print first. Init uses the
+// tenant's fiscal date display.
+type InvoiceDates interface {
+	DateDisplay(ctx context.Context, tenantID uuid.UUID) fiscalUtils.DateDisplay
+}
+
+// CreditLedger charges credit sales to the customer's account. Init uses the crm
+// khata when crm.Init has run first.
+type CreditLedger interface {
+	ChargeInvoice(ctx context.Context, invoice *domain.Invoice) error
+}
+
+// InvoiceStock takes sold goods out of stock. Implemented by the inventory side
+// and set after Init.
+type InvoiceStock interface {
+	// CheckWarehouse checks the tenant has the active warehouse goods are sold from
+	CheckWarehouse(ctx context.Context, tenantID, warehouseID uuid.UUID) error
+	// CheckStock refuses an invoice that would take a warehouse below zero where its
+	// stock policy forbids negative stock
+	CheckStock(ctx context.Context, invoice *domain.Invoice) error
+	// IssueInvoice takes the invoice's goods out of stock
+	IssueInvoice(ctx context.Context, invoice *domain.Invoice) error
+	// ReturnVoided puts the goods of a voided invoice back into stock
+	ReturnVoided(ctx context.Context, invoice *domain.Invoice) error
+}
+
+// InvoiceJournal books invoices in the general ledger. Init uses accounting's
+// automatic posting when accounting.Init has run first.
+type InvoiceJournal interface {
+	// PostInvoice books an issued invoice's sale, VAT and payment
+	PostInvoice(ctx context.Context, invoice *domain.Invoice) error
+	// ReverseVoided cancels a voided invoice's booking, if it was booked
+	ReverseVoided(ctx context.Context, invoice *domain.Invoice) error
+}
+
+// InvoiceMessenger delivers invoice messages to customers. Init uses the
+// notification module when notification.Init has run first.
+type InvoiceMessenger interface {
+	Send(ctx context.Context, msg domain.InvoiceMessage) (*domain.InvoiceDelivery, error)
+}
+
+// postingRetryDelay is how long failed invoice postings wait before they are retried,
+// and how long a retry holds an invoice from other workers
+const postingRetryDelay = 5 * time.Minute
+
+// postingRetryBatch bounds the invoices one RetryPostings run takes
+const postingRetryBatch = 100
+
+// invoiceService implements InvoiceService
+type invoiceService struct {
+	repo      repository.InvoiceRepository
+	customers CustomerDirectory
+	products  ProductCatalog
+	numbers   InvoiceNumbers
+	rounding  InvoiceRounding
+	dates     InvoiceDates
+	credit    CreditLedger
+	stock     InvoiceStock
+	journal   InvoiceJournal
+	messenger InvoiceMessenger
+	links     *ShareLinks
+}
+
+// NewInvoiceService creates a new invoice service
+func NewInvoiceService(repo repository.InvoiceRepository, customers CustomerDirectory, products ProductCatalog, numbers InvoiceNumbers, rounding InvoiceRounding, dates InvoiceDates) InvoiceService {
+	return &invoiceService{
+		repo:      repo,
+		customers: customers,
+		products:  products,
+		numbers:   numbers,
+		rounding:  rounding,
+		dates:     dates,
+	}
+}
+
+// SetCreditLedger sets where credit sales are charged
+func (s *invoiceService) SetCreditLedger(ledger CreditLedger) {
+	s.credit = ledger
+}
+
+// SetStock sets where sold goods are taken out of stock
+func (s *invoiceService) SetStock(stock InvoiceStock) {
+	s.stock = stock
+}
+
+// SetJournal sets where invoices are booked
+func (s *invoiceService) SetJournal(journal InvoiceJournal) {
+	s.journal = journal
+}
+
+// SetMessenger sets how invoices are sent to customers
+func (s *invoiceService) SetMessenger(messenger InvoiceMessenger) {
+	s.messenger = messenger
+}
+
+// SetShareLinks sets how links to sent invoices are signed
+func (s *invoiceService) SetShareLinks(links *ShareLinks) {
+	s.links = links
+}
+
+// Create builds, numbers and saves an invoice. Every line's price is checked against
+// MRP and the caller's discount limit, and the number comes from the invoice date's
+// fiscal year. A credit sale names the customer's verification in
+// CustomerVerification.VerificationID; it is claimed for the invoice and the proof
+// filled in.
+func (s *invoiceService) Create(ctx context.Context, invoice *domain.Invoice, lines []InvoiceLineInput) error {
+	if !invoice.PaymentMode.Valid() {
+		return fmt.Errorf("%w: unknown payment mode %q", domain.ErrInvalidInvoice, invoice.PaymentMode)
+	}
+	if invoice.PaymentMode == domain.PaymentCredit {
+		if invoice.CustomerID == nil {
+			return fmt.Errorf("%w: a credit sale needs a customer", domain.ErrInvalidInvoice)
+		}
+		if invoice.CustomerVerification == nil {
+			return domain.ErrCustomerNotVerified
+		}
+	} else {
+		invoice.CustomerVerification = nil
+	}
+	if invoice.WarehouseID != nil {
+		if s.stock == nil {
+			return fmt.Errorf("%w: warehouses are not available", domain.ErrInvalidInvoice)
+		}
+		if err := s.stock.CheckWarehouse(ctx, invoice.TenantID, *invoice.WarehouseID); err != nil {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidInvoice, err.Error())
+		}
+	}
+
+	if invoice.CustomerID != nil {
+		buyer, err := s.customers.Buyer(ctx, invoice.TenantID, *invoice.CustomerID)
+		if err != nil {
+			return err
+		}
+		if buyer.Blocked {
+			return domain.ErrCustomerBlocked
+		}
+		invoice.SetBuyer(buyer)
+	}
+
+	locale, err := s.products.InvoiceLocale(ctx, invoice.TenantID, invoice.Locale)
+	if err != nil {
+		return err
+	}
+	invoice.Locale = nil
+	if locale != "" {
+		invoice.Locale = &locale
+	}
+
+	role, _ := db.GetRole(ctx)
+	for _, input := range lines {
+		product, err := s.products.SaleProduct(ctx, invoice.TenantID, input.ProductID, locale)
+		if err != nil {
+			return err
+		}
+		if !product.Available {
+			return fmt.Errorf("%w: %s", domain.ErrProductUnavailable, product.Name)
+		}
+
+		price := product.Price
+		if input.UnitPrice != nil {
+			price = *input.UnitPrice
+		}
+		line, err := invoice.AddLine(product.ID, input.Quantity, price, input.Discount)
+		if err != nil {
+			return err
+		}
+		line.ProductCode, line.ProductName, line.Unit = product.Code, product.Name, product.Unit
+		if err := s.products.CheckSalePrice(ctx, invoice.TenantID, product.ID, line.NetAmount/line.Quantity, role, input.OverrideID); err != nil {
+			return err
+		}
+
+		rate, tax, err := s.products.ComputeTax(ctx, invoice.TenantID, product.ID, line.NetAmount, invoice.InvoiceDate)
+		if err != nil {
+			return fmt.Errorf("failed to compute tax for %s: %w", product.Name, err)
+		}
+		line.SetTax(rate, tax)
+	}
+	if err := invoice.Calculate(); err != nil {
+		return err
+	}
+	rounded, err := s.rounding.RoundTotal(ctx, invoice.TenantID, invoice.TotalAmount)
+	if err != nil {
+		return fmt.Errorf("failed to round invoice total: %w", err)
+	}
+	invoice.RoundTotal(rounded)
+	if invoice.PaymentMode == domain.PaymentCash {
+		invoice.SetAmountPaid(invoice.TotalAmount)
+	}
+	if s.stock != nil {
+		if err := s.stock.CheckStock(ctx, invoice); err != nil {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidInvoice, err.Error())
+		}
+	}
+
+	// Claimed last, so an invoice refused above leaves the verification usable; a
+	// failed save spends it and the customer is sent a new code
+	if invoice.CustomerVerification != nil {
+		verification, err := s.customers.ClaimVerification(ctx, invoice.TenantID, invoice.CustomerVerification.VerificationID, *invoice.CustomerID, invoice.ID)
+		if err != nil {
+			return err
+		}
+		invoice.CustomerVerification = verification
+	}
+
+	// The number is taken before saving; if the save fails it shows as unused in
+	// the number ledger rather than leaving a gap
+	fiscalYearID, number, err := s.numbers.Issue(ctx, invoice.TenantID, invoice.InvoiceDate)
+	if err != nil {
+		return err
+	}
+	invoice.FiscalYearID, invoice.InvoiceNumber = fiscalYearID, number
+
+	// The postings are saved with the invoice, so any a failure or a crash cuts short
+	// are retried; the retry waits out postingRetryDelay so it does not race this run
+	invoice.PendingPostings = s.postings(invoice)
+	retryAt := time.Now().Add(postingRetryDelay)
+	invoice.RetryAt = &retryAt
+	if err := s.repo.Create(ctx, invoice); err != nil {
+		return err
+	}
+
+	err = s.post(ctx, invoice)
+	s.audit(ctx, "CREATE_INVOICE", invoice, invoice.CreatedBy)
+	publishInvoiceChange(invoice, domain.EventInvoiceCreated)
+	return err
+}
+
+// postings lists what a new invoice has to post to the other modules
+func (s *invoiceService) postings(invoice *domain.Invoice) []domain.PostingStep {
+	steps := []domain.PostingStep{domain.PostingNumberLedger}
+	if s.credit != nil && invoice.PaymentMode == domain.PaymentCredit && invoice.TotalAmount != 0 {
+		steps = append(steps, domain.PostingKhata)
+	}
+	if s.stock != nil {
+		steps = append(steps, domain.PostingStock)
+	}
+	if s.journal != nil {
+		steps = append(steps, domain.PostingJournal)
+	}
+	return steps
+}
+
+// post runs the invoice's pending postings and saves those that failed for a retry,
+// returning ErrPostingsPending with their errors
+func (s *invoiceService) post(ctx context.Context, invoice *domain.Invoice) error {
+	var pending []domain.PostingStep
+	var errs []error
+	for _, step := range invoice.PendingPostings {
+		if err := s.postStep(ctx, invoice, step); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to post invoice %s (%s), will retry: %v", invoice.InvoiceNumber, step, err))
+			pending = append(pending, step)
+			errs = append(errs, fmt.Errorf("%s: %w", step, err))
+		}
+	}
+
+	invoice.PendingPostings, invoice.RetryAt = pending, nil
+	if len(pending) > 0 {
+		retryAt := time.Now().Add(postingRetryDelay)
+		invoice.RetryAt = &retryAt
+	}
+	if err := s.repo.SavePostings(ctx, invoice); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", domain.ErrPostingsPending, errors.Join(errs...))
+	}
+	return nil
+}
+
+// postStep posts the invoice to one module; a module no longer set has nothing to do
+func (s *invoiceService) postStep(ctx context.Context, invoice *domain.Invoice, step domain.PostingStep) error {
+	switch step {
+	case domain.PostingNumberLedger:
+		return s.numbers.Record(ctx, invoice)
+	case domain.PostingKhata:
+		if s.credit != nil {
+			return s.credit.ChargeInvoice(ctx, invoice)
+		}
+	case domain.PostingStock:
+		if s.stock != nil {
+			return s.stock.IssueInvoice(ctx, invoice)
+		}
+	case domain.PostingJournal:
+		if s.journal != nil {
+			return s.journal.PostInvoice(ctx, invoice)
+		}
+	}
+	return nil
+}
+
+// RetryPostings retries the due invoices' postings in their tenants
+func (s *invoiceService) RetryPostings(ctx context.Context) (int, error) {
+	due, err := s.repo.ClaimPostingRetries(ctx, postingRetryDelay, postingRetryBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, claimed := range due {
+		tenantCtx := db.WithTenantID(ctx, claimed.TenantID)
+		invoice, err := s.repo.Get(tenantCtx, claimed.TenantID, claimed.ID)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to load invoice %s to retry its postings: %v", claimed.InvoiceNumber, err))
+			continue
+		}
+		if err := s.post(tenantCtx, invoice); err == nil {
+			posted++
+		} else if !errors.Is(err, domain.ErrPostingsPending) {
+			logger.Log.Error(fmt.Sprintf("Failed to save the postings of invoice %s: %v", invoice.InvoiceNumber, err))
+		}
+	}
+	return posted, nil
+}
+
+// Get retrieves an invoice with its lines
+func (s *invoiceService) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Invoice, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// List returns invoices, newest first
+func (s *invoiceService) List(ctx context.Context, tenantID uuid.UUID, filter domain.InvoiceFilter) ([]*domain.Invoice, error) {
+	return s.repo.List(ctx, tenantID, filter)
+}
+
+// SetAmountPaid updates the amount paid and payment status
+func (s *invoiceService) SetAmountPaid(ctx context.Context, tenantID, id uuid.UUID, amount float64) error {
+	invoice, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	invoice.SetAmountPaid(amount)
+	return s.repo.SavePayment(ctx, invoice)
+}
+
+// Void cancels an issued invoice. Credit invoices already on the khata are refused;
+// the customer's account has no reversal for them.
+func (s *invoiceService) Void(ctx context.Context, tenantID, id uuid.UUID, reason string, userID *uuid.UUID) (*domain.Invoice, error) {
+	invoice, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.PaymentMode == domain.PaymentCredit && s.credit != nil && invoice.Status == domain.InvoiceIssued {
+		return nil, domain.ErrCreditInvoiceVoid
+	}
+	// Reversing goods or an entry that were never posted would unbalance stock and the journal
+	if len(invoice.PendingPostings) > 0 {
+		return nil, domain.ErrPostingsPending
+	}
+
+	if err := invoice.Void(reason, userID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveVoid(ctx, invoice); err != nil {
+		return nil, err
+	}
+	if s.stock != nil {
+		if err := s.stock.ReturnVoided(ctx, invoice); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to put voided invoice %s back into stock: %v", invoice.InvoiceNumber, err))
+		}
+	}
+	if s.journal != nil {
+		if err := s.journal.ReverseVoided(ctx, invoice); err != nil {
+			logger.Log.Error(fmt.Sprintf("Failed to reverse voided invoice %s in the journal: %v", invoice.InvoiceNumber, err))
+		}
+	}
+
+	// Analytics counts VOID_ actions per user for its voids anomaly metric
+	s.audit(ctx, "VOID_INVOICE", invoice, userID)
+	publishInvoiceChange(invoice, domain.EventInvoiceVoided)
+	return invoice, nil
+}
+
+// Send resolves every recipient before sending anything, so a missing address
+// sends nothing rather than half the messages
+func (s *invoiceService) Send(ctx context.Context, tenantID, id uuid.UUID, send domain.InvoiceSend, userID *uuid.UUID) (*domain.InvoiceSendResult, error) {
+	if err := send.Normalize(); err != nil {
+		return nil, err
+	}
+	if s.messenger == nil {
+		return nil, fmt.Errorf("%w: messaging is not available", domain.ErrInvalidInvoiceSend)
+	}
+	invoice, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == domain.InvoiceVoid {
+		return nil, fmt.Errorf("%w: the invoice is void", domain.ErrInvalidInvoiceSend)
+	}
+
+	var contact *domain.Contact
+	if invoice.CustomerID != nil {
+		buyer, err := s.customers.Buyer(ctx, tenantID, *invoice.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		contact = &buyer.Contact
+	}
+	recipients := make([]string, len(send.Channels))
+	for i, channel := range send.Channels {
+		if recipients[i], err = send.Recipient(channel, contact); err != nil {
+			return nil, err
+		}
+	}
+
+	link, expiresAt, err := s.links.Link(invoice, send.Share, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.InvoiceSendResult{InvoiceID: invoice.ID, Link: link, LinkExpiresAt: expiresAt, Deliveries: []domain.InvoiceDelivery{}}
+	for i, channel := range send.Channels {
+		delivery, err := s.messenger.Send(ctx, domain.InvoiceMessage{
+			Invoice:       invoice,
+			Channel:       channel,
+			Recipient:     recipients[i],
+			Link:          link,
+			LinkExpiresAt: expiresAt,
+			Message:       send.Message,
+		})
+		if err != nil {
+			// Messages already queued stay sent; report the failure alongside them
+			logger.Log.Error(fmt.Sprintf("Failed to send invoice %s by %s: %v", invoice.InvoiceNumber, channel, err))
+			delivery = &domain.InvoiceDelivery{Channel: channel, Recipient: recipients[i], Status: domain.DeliveryFailed}
+		}
+		result.Deliveries = append(result.Deliveries, *delivery)
+	}
+
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &invoice.TenantID,
+	}
+	entityIDStr := invoice.ID.String()
+	audit.Service.Log(ctx, "SEND_INVOICE", "Invoice", &entityIDStr, map[string]interface{}{
+		"invoice_number": invoice.InvoiceNumber,
+		"customer_id":    invoice.CustomerID,
+		"share":          send.Share,
+		"deliveries":     result.Deliveries,
+	}, auditCtx)
+	return result, nil
+}
+
+// PrintPDF logs a print or download and renders its copies
+func (s *invoiceService) PrintPDF(ctx context.Context, req PrintRequest) (*domain.Invoice, *domain.InvoicePrint, []byte, error) {
+	if req.Copies < 1 || req.Copies > domain.MaxPrintCopies {
+		return nil, nil, nil, domain.ErrInvalidPrintCopies
+	}
+	invoice, err := s.repo.Get(ctx, req.TenantID, req.InvoiceID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	record := domain.NewInvoicePrint(req.TenantID, invoice.ID, req.Kind, req.Copies, req.UserID)
+	if req.Reason != "" {
+		record.Reason = &req.Reason
+	}
+	if req.IPAddress != "" {
+		record.IPAddress = &req.IPAddress
+	}
+	return s.print(ctx, invoice, record)
+}
+
+// ListPrints returns an invoice's print log
+func (s *invoiceService) ListPrints(ctx context.Context, tenantID, id uuid.UUID) ([]*domain.InvoicePrint, error) {
+	exists, err := s.repo.Exists(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	return s.repo.ListPrints(ctx, tenantID, id)
+}
+
+// print numbers a print's copies and renders them. Numbers are reserved before
+// rendering, so a failed render leaves a gap rather than handing out a copy number twice.
+func (s *invoiceService) print(ctx context.Context, invoice *domain.Invoice, record *domain.InvoicePrint) (*domain.Invoice, *domain.InvoicePrint, []byte, error) {
+	if err := s.repo.RecordPrint(ctx, record); err != nil {
+		return nil, nil, nil, err
+	}
+	invoice.PrintCount = record.LastCopy() + 1
+
+	content := RenderInvoicePDF(invoice, s.dates.DateDisplay(ctx, invoice.TenantID), record.Labels()...)
+	return invoice, record, content, nil
+}
+
+// OpenShared checks the link's token and loads its invoice in its tenant
+func (s *invoiceService) OpenShared(ctx context.Context, token string) (*domain.Invoice, error) {
+	tenantID, id, err := s.links.Open(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	invoice, err := s.repo.Get(db.WithTenantID(ctx, tenantID), tenantID, id)
+	if errors.Is(err, domain.ErrInvoiceNotFound) {
+		return nil, domain.ErrInvalidShareLink
+	}
+	return invoice, err
+}
+
+// SharedPDF logs and renders the invoice a share link opens
+func (s *invoiceService) SharedPDF(ctx context.Context, token, ipAddress string) (*domain.Invoice, *domain.InvoicePrint, []byte, error) {
+	invoice, err := s.OpenShared(ctx, token)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	record := domain.NewInvoicePrint(invoice.TenantID, invoice.ID, domain.PrintKindShareLink, 1, nil)
+	if ipAddress != "" {
+		record.IPAddress = &ipAddress
+	}
+	return s.print(db.WithTenantID(ctx, invoice.TenantID), invoice, record)
+}
+
+// publishInvoiceChange announces a saved invoice change, e.g. to tenant automations
+func publishInvoiceChange(invoice *domain.Invoice, eventType string) {
+	events.Publish(events.New(invoice.TenantID, domain.TopicInvoices, eventType, domain.InvoiceChange{
+		ID:            invoice.ID,
+		InvoiceNumber: invoice.InvoiceNumber,
+		InvoiceDate:   invoice.InvoiceDate.Format("2006-01-02"),
+		CustomerID:    invoice.CustomerID,
+		BuyerName:     invoice.BuyerName,
+		PaymentMode:   invoice.PaymentMode,
+		Status:        invoice.Status,
+		TaxAmount:     invoice.TaxAmount,
+		TotalAmount:   invoice.TotalAmount,
+	}))
+}
+
+func (s *invoiceService) audit(ctx context.Context, action string, invoice *domain.Invoice, userID *uuid.UUID) {
+	auditCtx := &auditDomain.AuditContext{
+		UserID:   userID,
+		TenantID: &invoice.TenantID,
+	}
+
+	entityIDStr := invoice.ID.String()
+	audit.Service.Log(ctx, action, "Invoice", &entityIDStr, map[string]interface{}{
+		"invoice_number": invoice.InvoiceNumber,
+		"customer_id":    invoice.CustomerID,
+		"payment_mode":   invoice.PaymentMode,
+		"total_amount":   invoice.TotalAmount,
+		"status":         invoice.Status,
+		"void_reason":    invoice.VoidReason,
+	}, auditCtx)
+}
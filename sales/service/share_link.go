@@ -0,0 +1,79 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/security"
+	"github.com/aceextension/sales/domain"
+	"github.com/google/uuid"
+)
+
+// ShareLinks signs links customers open an invoice with, without logging in. A token
+// carries the tenant, invoice and expiry, signed with the configured secret; nothing
+// is stored, so a link cannot be revoked before it expires except by changing the secret.
+type ShareLinks struct {
+	secret string
+	appURL string // Web app origin serving the invoice's public page
+	apiURL string // API origin serving the PDF
+	ttl    time.Duration
+}
+
+// NewShareLinks creates share links valid for ttl. An empty secret disables them.
+func NewShareLinks(secret, appURL, apiURL string, ttl time.Duration) *ShareLinks {
+	if apiURL == "" {
+		apiURL = appURL
+	}
+	return &ShareLinks{
+		secret: secret,
+		appURL: strings.TrimRight(appURL, "/"),
+		apiURL: strings.TrimRight(apiURL, "/"),
+		ttl:    ttl,
+	}
+}
+
+// Link returns the URL of an invoice's public page or PDF, and when it expires
+func (l *ShareLinks) Link(invoice *domain.Invoice, mode domain.ShareMode, now time.Time) (string, time.Time, error) {
+	if l == nil || l.secret == "" {
+		return "", time.Time{}, domain.ErrShareLinksDisabled
+	}
+	expiresAt := now.Add(l.ttl).Truncate(time.Second)
+
+	payload := make([]byte, 0, 40)
+	payload = append(payload, invoice.TenantID[:]...)
+	payload = append(payload, invoice.ID[:]...)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(expiresAt.Unix()))
+	token := base64.RawURLEncoding.EncodeToString(payload) + "." + security.Sign(l.secret, payload, security.EncodingHex)
+
+	if mode == domain.SharePDF {
+		return l.apiURL + "/api/v1/public/invoices/" + token + "/pdf", expiresAt, nil
+	}
+	return l.appURL + "/invoices/shared/" + token, expiresAt, nil
+}
+
+// Open checks a token's signature and expiry and returns the tenant and invoice it opens
+func (l *ShareLinks) Open(token string, now time.Time) (uuid.UUID, uuid.UUID, error) {
+	if l == nil || l.secret == "" {
+		return uuid.Nil, uuid.Nil, domain.ErrShareLinksDisabled
+	}
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, uuid.Nil, domain.ErrInvalidShareLink
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(payload) != 40 {
+		return uuid.Nil, uuid.Nil, domain.ErrInvalidShareLink
+	}
+	if err := security.VerifySignature([]string{l.secret}, payload, signature, security.EncodingHex); err != nil {
+		return uuid.Nil, uuid.Nil, domain.ErrInvalidShareLink
+	}
+	if now.Unix() >= int64(binary.BigEndian.Uint64(payload[32:])) {
+		return uuid.Nil, uuid.Nil, domain.ErrInvalidShareLink
+	}
+
+	tenantID, _ := uuid.FromBytes(payload[:16])
+	invoiceID, _ := uuid.FromBytes(payload[16:32])
+	return tenantID, invoiceID, nil
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIUsageBucket is one daily rollup of a tenant's API traffic: a caller on an endpoint on a day
+type APIUsageBucket struct {
+	TenantID uuid.UUID
+	Day      time.Time // Start of the day, UTC
+	APIKeyID uuid.UUID // uuid.Nil for requests made with a user session
+	UserID   uuid.UUID // The session's user, or the key's creator
+	Method   string
+	Route    string // Route pattern such as /api/v1/products/:id, so IDs do not split counts
+}
+
+// APIUsageCounts counts requests and their failures
+type APIUsageCounts struct {
+	Requests     int64 `json:"requests"`
+	ClientErrors int64 `json:"clientErrors"` // 4xx responses
+	ServerErrors int64 `json:"serverErrors"` // 5xx responses
+}
+
+// Add counts one request that finished with status
+func (c *APIUsageCounts) Add(status int) {
+	c.Requests++
+	switch {
+	case status >= 500:
+		c.ServerErrors++
+	case status >= 400:
+		c.ClientErrors++
+	}
+}
+
+// Merge adds other's counts
+func (c *APIUsageCounts) Merge(other APIUsageCounts) {
+	c.Requests += other.Requests
+	c.ClientErrors += other.ClientErrors
+	c.ServerErrors += other.ServerErrors
+}
+
+// APIUsageStats is counts with the share of requests that failed
+type APIUsageStats struct {
+	APIUsageCounts
+	ErrorRate float64 `json:"errorRate"` // Percentage of requests with a 4xx or 5xx response
+}
+
+// NewAPIUsageStats computes the error rate of counts
+func NewAPIUsageStats(counts APIUsageCounts) APIUsageStats {
+	stats := APIUsageStats{APIUsageCounts: counts}
+	if counts.Requests > 0 {
+		stats.ErrorRate = float64(counts.ClientErrors+counts.ServerErrors) / float64(counts.Requests) * 100
+	}
+	return stats
+}
+
+// APIKeyUsage is the traffic of one API key
+type APIKeyUsage struct {
+	APIKeyID uuid.UUID `json:"apiKeyId"`
+	Name     string    `json:"name"`
+	APIUsageStats
+}
+
+// UserAPIUsage is the traffic of one user's sessions, not counting their API keys
+type UserAPIUsage struct {
+	UserID uuid.UUID `json:"userId"`
+	Name   string    `json:"name"`
+	APIUsageStats
+}
+
+// EndpointUsage is the traffic of one endpoint
+type EndpointUsage struct {
+	Method string `json:"method"`
+	Route  string `json:"route"`
+	APIUsageStats
+}
+
+// DailyAPIUsage is a tenant's traffic on one day
+type DailyAPIUsage struct {
+	Day time.Time `json:"day"`
+	APIUsageStats
+}
+
+// APIUsageReport breaks a tenant's API traffic down by key, user, endpoint and day
+type APIUsageReport struct {
+	From         time.Time       `json:"from"`
+	To           time.Time       `json:"to"` // Exclusive
+	Totals       APIUsageStats   `json:"totals"`
+	ByAPIKey     []APIKeyUsage   `json:"byApiKey"`
+	ByUser       []UserAPIUsage  `json:"byUser"`
+	TopEndpoints []EndpointUsage `json:"topEndpoints"`
+	Daily        []DailyAPIUsage `json:"daily"`
+}
+
+// DayStart returns the start of the day containing t (UTC)
+func DayStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CancellationReason is the structured exit survey answer
+type CancellationReason string
+
+const (
+	CancellationTooExpensive     CancellationReason = "TOO_EXPENSIVE"
+	CancellationMissingFeatures  CancellationReason = "MISSING_FEATURES"
+	CancellationSwitchedProvider CancellationReason = "SWITCHED_PROVIDER"
+	CancellationTooComplex       CancellationReason = "TOO_COMPLEX"
+	CancellationBusinessClosed   CancellationReason = "BUSINESS_CLOSED"
+	CancellationTemporary        CancellationReason = "TEMPORARY"
+	CancellationOther            CancellationReason = "OTHER"
+)
+
+// ValidCancellationReasons lists the accepted exit survey answers
+var ValidCancellationReasons = map[CancellationReason]bool{
+	CancellationTooExpensive:     true,
+	CancellationMissingFeatures:  true,
+	CancellationSwitchedProvider: true,
+	CancellationTooComplex:       true,
+	CancellationBusinessClosed:   true,
+	CancellationTemporary:        true,
+	CancellationOther:            true,
+}
+
+// CancellationStatus tracks a cancellation through its lifecycle
+type CancellationStatus string
+
+const (
+	// CancellationScheduled: the subscription runs until the end of the paid period
+	CancellationScheduled CancellationStatus = "SCHEDULED"
+	// CancellationEffective: the period ended; data is retained until PurgeAfter
+	CancellationEffective CancellationStatus = "EFFECTIVE"
+	// CancellationReactivated: the tenant came back before purge
+	CancellationReactivated CancellationStatus = "REACTIVATED"
+	// CancellationPurged: the retention period ran out
+	CancellationPurged CancellationStatus = "PURGED"
+)
+
+// RetentionNotice identifies which cancellation message a tenant is sent
+type RetentionNotice string
+
+const (
+	RetentionNoticeScheduled   RetentionNotice = "SCHEDULED"   // Cancellation confirmed, access until EffectiveAt
+	RetentionNoticeEffective   RetentionNotice = "EFFECTIVE"   // Subscription ended, retention countdown started
+	RetentionNoticeReminder    RetentionNotice = "REMINDER"    // Export your data, N days left
+	RetentionNoticeReactivated RetentionNotice = "REACTIVATED" // Tenant came back before purge
+)
+
+// RetentionReminderDays are the days-before-purge at which export reminders are sent
+var RetentionReminderDays = []int{60, 30, 7, 1}
+
+// Cancellation is a tenant's request to stop their subscription
+type Cancellation struct {
+	ID             uuid.UUID          `json:"id"`
+	TenantID       uuid.UUID          `json:"tenantId"`
+	SubscriptionID uuid.UUID          `json:"subscriptionId"`
+	Status         CancellationStatus `json:"status"`
+	Reason         CancellationReason `json:"reason"`
+	Feedback       *string            `json:"feedback,omitempty"`
+	RequestedBy    *uuid.UUID         `json:"requestedBy,omitempty"`
+	RequestedAt    time.Time          `json:"requestedAt"`
+	EffectiveAt    time.Time          `json:"effectiveAt"` // End of the paid period
+	PurgeAfter     time.Time          `json:"purgeAfter"`  // Data is deleted after this date
+	LastReminder   *int               `json:"lastReminderDays,omitempty"`
+	ReactivatedAt  *time.Time         `json:"reactivatedAt,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt"`
+}
+
+// NewCancellation schedules cancellation at the end of the subscription's paid period
+func NewCancellation(sub *Subscription, reason CancellationReason, feedback *string, requestedBy *uuid.UUID, retention time.Duration) *Cancellation {
+	now := time.Now()
+	return &Cancellation{
+		ID:             uuid.New(),
+		TenantID:       sub.TenantID,
+		SubscriptionID: sub.ID,
+		Status:         CancellationScheduled,
+		Reason:         reason,
+		Feedback:       feedback,
+		RequestedBy:    requestedBy,
+		RequestedAt:    now,
+		EffectiveAt:    sub.EndDate,
+		PurgeAfter:     sub.EndDate.Add(retention),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// IsOpen reports whether the cancellation can still be reactivated
+func (c *Cancellation) IsOpen() bool {
+	return c.Status == CancellationScheduled || c.Status == CancellationEffective
+}
+
+// DaysUntilPurge returns whole days left before data is purged (0 when due)
+func (c *Cancellation) DaysUntilPurge(now time.Time) int {
+	if !now.Before(c.PurgeAfter) {
+		return 0
+	}
+	return int(math.Ceil(c.PurgeAfter.Sub(now).Hours() / 24))
+}
+
+// DueReminder returns the reminder threshold to send now, if one is due and not yet sent
+func (c *Cancellation) DueReminder(now time.Time) (int, bool) {
+	if c.Status != CancellationEffective {
+		return 0, false
+	}
+	daysLeft := c.DaysUntilPurge(now)
+	for i := len(RetentionReminderDays) - 1; i >= 0; i-- {
+		threshold := RetentionReminderDays[i]
+		if daysLeft <= threshold && (c.LastReminder == nil || threshold < *c.LastReminder) {
+			return threshold, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessMode is what a tenant may do given its subscription state
+type AccessMode string
+
+const (
+	// AccessModeFull allows reads and writes
+	AccessModeFull AccessMode = "FULL"
+	// AccessModeGrace allows everything but the subscription has lapsed; read-only starts when grace ends
+	AccessModeGrace AccessMode = "GRACE"
+	// AccessModeReadOnly allows reads only until the tenant renews
+	AccessModeReadOnly AccessMode = "READ_ONLY"
+)
+
+// AccessOverride lets a super admin keep an expired tenant fully usable until a date
+type AccessOverride struct {
+	TenantID  uuid.UUID  `json:"tenantId"`
+	Until     time.Time  `json:"until"`
+	Reason    string     `json:"reason"`
+	GrantedBy *uuid.UUID `json:"grantedBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// IsActive reports whether the override still applies
+func (o *AccessOverride) IsActive(now time.Time) bool {
+	return o != nil && now.Before(o.Until)
+}
+
+// AccessState describes a tenant's enforcement state
+type AccessState struct {
+	Mode          AccessMode `json:"mode"`
+	ExpiredAt     *time.Time `json:"expiredAt,omitempty"`
+	ReadOnlyFrom  *time.Time `json:"readOnlyFrom,omitempty"`
+	OverrideUntil *time.Time `json:"overrideUntil,omitempty"`
+}
+
+// ResolveAccessState derives the access mode from the tenant's latest subscription.
+// Tenants without any subscription record keep full access.
+func ResolveAccessState(latest *Subscription, override *AccessOverride, grace time.Duration, now time.Time) AccessState {
+	if latest == nil || now.Before(latest.EndDate) {
+		return AccessState{Mode: AccessModeFull}
+	}
+
+	expiredAt := latest.EndDate
+	readOnlyFrom := expiredAt.Add(grace)
+	state := AccessState{ExpiredAt: &expiredAt, ReadOnlyFrom: &readOnlyFrom}
+
+	switch {
+	case override.IsActive(now):
+		state.Mode = AccessModeFull
+		state.OverrideUntil = &override.Until
+	case now.Before(readOnlyFrom):
+		state.Mode = AccessModeGrace
+	default:
+		state.Mode = AccessModeReadOnly
+	}
+	return state
+}
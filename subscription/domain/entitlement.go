@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EntitlementChange describes why a new entitlement snapshot was recorded
+type EntitlementChange string
+
+const (
+	EntitlementSubscribed  EntitlementChange = "SUBSCRIBED"
+	EntitlementPlanChanged EntitlementChange = "PLAN_CHANGED"
+	EntitlementRenewed     EntitlementChange = "RENEWED"
+	EntitlementPlanUpdated EntitlementChange = "PLAN_UPDATED" // The plan's features/limits were edited
+	EntitlementCancelled   EntitlementChange = "CANCELLED"
+	EntitlementExpired     EntitlementChange = "EXPIRED"
+)
+
+// EntitlementSnapshot freezes what a tenant was entitled to from EffectiveFrom until
+// EffectiveTo (nil while current), so later plan edits don't rewrite history
+type EntitlementSnapshot struct {
+	ID             uuid.UUID         `json:"id"`
+	TenantID       uuid.UUID         `json:"tenantId"`
+	SubscriptionID *uuid.UUID        `json:"subscriptionId,omitempty"`
+	PlanID         uuid.UUID         `json:"planId"`
+	PlanCode       string            `json:"planCode"`
+	PlanName       string            `json:"planName"`
+	Features       map[string]bool   `json:"features"`
+	Limits         map[string]int    `json:"limits"`
+	Change         EntitlementChange `json:"change"`
+	EffectiveFrom  time.Time         `json:"effectiveFrom"`
+	EffectiveTo    *time.Time        `json:"effectiveTo,omitempty"`
+	ChangedBy      *uuid.UUID        `json:"changedBy,omitempty"`
+	CreatedAt      time.Time         `json:"createdAt"`
+}
+
+// NewEntitlementSnapshot captures the plan's current features and limits for a subscription
+func NewEntitlementSnapshot(sub *Subscription, plan *Plan, change EntitlementChange, effectiveFrom time.Time) *EntitlementSnapshot {
+	features := make(map[string]bool, len(plan.Features))
+	for k, v := range plan.Features {
+		features[k] = v
+	}
+	limits := make(map[string]int, len(plan.Limits))
+	for k, v := range plan.Limits {
+		limits[k] = v
+	}
+
+	subID := sub.ID
+	return &EntitlementSnapshot{
+		ID:             uuid.New(),
+		TenantID:       sub.TenantID,
+		SubscriptionID: &subID,
+		PlanID:         plan.ID,
+		PlanCode:       plan.Code,
+		PlanName:       plan.Name,
+		Features:       features,
+		Limits:         limits,
+		Change:         change,
+		EffectiveFrom:  effectiveFrom,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// Covers reports whether the snapshot was in effect at the given time
+func (e *EntitlementSnapshot) Covers(at time.Time) bool {
+	return !at.Before(e.EffectiveFrom) && (e.EffectiveTo == nil || at.Before(*e.EffectiveTo))
+}
+
+// AllowsLimit applies the same rules as live limit checks: missing key = not allowed, -1 = unlimited
+func (e *EntitlementSnapshot) AllowsLimit(limitKey string, currentValue int) bool {
+	limit, ok := e.Limits[limitKey]
+	if !ok {
+		return false
+	}
+	return limit == -1 || currentValue < limit
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InvoiceStatus defines the status of a subscription invoice
+type InvoiceStatus string
+
+const (
+	InvoiceStatusIssued InvoiceStatus = "ISSUED"
+	InvoiceStatusPaid   InvoiceStatus = "PAID"
+	InvoiceStatusVoid   InvoiceStatus = "VOID"
+)
+
+// Party holds the legal details of a seller or buyer printed on a tax invoice
+type Party struct {
+	Name      string  `json:"name"`
+	VATNumber *string `json:"vatNumber,omitempty"` // PAN/VAT registration number
+	Address   *string `json:"address,omitempty"`
+	Email     *string `json:"email,omitempty"`
+}
+
+// SubscriptionInvoice is a tax invoice issued by the platform to a tenant
+type SubscriptionInvoice struct {
+	ID             uuid.UUID     `json:"id"`
+	TenantID       uuid.UUID     `json:"tenantId"`
+	SubscriptionID uuid.UUID     `json:"subscriptionId"`
+	PlanID         uuid.UUID     `json:"planId"`
+	InvoiceNumber  string        `json:"invoiceNumber"`
+	Status         InvoiceStatus `json:"status"`
+	IssueDate      time.Time     `json:"issueDate"`
+	PeriodStart    time.Time     `json:"periodStart"`
+	PeriodEnd      time.Time     `json:"periodEnd"`
+	Description    string        `json:"description"`
+	Currency       string        `json:"currency"`
+	Subtotal       float64       `json:"subtotal"`
+	VATRate        float64       `json:"vatRate"` // Percent
+	VATAmount      float64       `json:"vatAmount"`
+	Total          float64       `json:"total"`
+	Seller         Party         `json:"seller"`
+	Buyer          Party         `json:"buyer"`
+	PaidAt         *time.Time    `json:"paidAt,omitempty"`
+	PaymentRef     *string       `json:"paymentRef,omitempty"`
+	EmailedAt      *time.Time    `json:"emailedAt,omitempty"`
+	PrintCount     int           `json:"printCount"` // Pages printed or downloaded, including the original
+	CreatedAt      time.Time     `json:"createdAt"`
+	UpdatedAt      time.Time     `json:"updatedAt"`
+}
+
+// NewSubscriptionInvoice creates an invoice for a subscription period.
+// Plan prices are VAT-exclusive; VAT is added on top at vatRate percent.
+func NewSubscriptionInvoice(sub *Subscription, plan *Plan, seller, buyer Party, vatRate float64) *SubscriptionInvoice {
+	now := time.Now()
+	subtotal := roundMoney(plan.Price)
+	vat := roundMoney(subtotal * vatRate / 100)
+
+	return &SubscriptionInvoice{
+		ID:             uuid.New(),
+		TenantID:       sub.TenantID,
+		SubscriptionID: sub.ID,
+		PlanID:         plan.ID,
+		Status:         InvoiceStatusIssued,
+		IssueDate:      now,
+		PeriodStart:    sub.StartDate,
+		PeriodEnd:      sub.EndDate,
+		Description:    plan.Name + " subscription (" + plan.Interval + ")",
+		Currency:       plan.Currency,
+		Subtotal:       subtotal,
+		VATRate:        vatRate,
+		VATAmount:      vat,
+		Total:          roundMoney(subtotal + vat),
+		Seller:         seller,
+		Buyer:          buyer,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// IsPaid reports whether the invoice doubles as a payment receipt
+func (i *SubscriptionInvoice) IsPaid() bool {
+	return i.Status == InvoiceStatusPaid
+}
+
+// MarkPaid records the payment against the invoice
+func (i *SubscriptionInvoice) MarkPaid(paymentRef string, at time.Time) {
+	i.Status = InvoiceStatusPaid
+	i.PaidAt = &at
+	if paymentRef != "" {
+		i.PaymentRef = &paymentRef
+	}
+	i.UpdatedAt = time.Now()
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
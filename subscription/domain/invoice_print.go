@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PrintKind defines how an invoice was output
+type PrintKind string
+
+const (
+	PrintKindPrint    PrintKind = "PRINT"
+	PrintKindDownload PrintKind = "DOWNLOAD"
+)
+
+// MaxPrintCopies bounds how many copies one print request may produce
+const MaxPrintCopies = 5
+
+// OriginalLabel marks the first output of an invoice
+const OriginalLabel = "ORIGINAL"
+
+// InvoicePrint records one print or download of an invoice. Every page ever output is
+// numbered: the invoice's first page is the original and every later page is a copy,
+// as IRD requires reprints to be marked "COPY n OF m".
+type InvoicePrint struct {
+	ID        uuid.UUID  `json:"id"`
+	TenantID  uuid.UUID  `json:"tenantId"`
+	InvoiceID uuid.UUID  `json:"invoiceId"`
+	Kind      PrintKind  `json:"kind"`
+	FirstCopy int        `json:"firstCopy"` // Number of the first page output; 0 is the original
+	Copies    int        `json:"copies"`    // Pages output by this request
+	Reason    *string    `json:"reason,omitempty"`
+	PrintedBy *uuid.UUID `json:"printedBy,omitempty"`
+	IPAddress *string    `json:"ipAddress,omitempty"`
+	PrintedAt time.Time  `json:"printedAt"`
+}
+
+// NewInvoicePrint creates a print record; the repository assigns FirstCopy
+func NewInvoicePrint(tenantID, invoiceID uuid.UUID, kind PrintKind, copies int, printedBy *uuid.UUID) *InvoicePrint {
+	return &InvoicePrint{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		InvoiceID: invoiceID,
+		Kind:      kind,
+		Copies:    copies,
+		PrintedBy: printedBy,
+		PrintedAt: time.Now(),
+	}
+}
+
+// IsReprint reports whether the original had been output before this request
+func (p *InvoicePrint) IsReprint() bool {
+	return p.FirstCopy > 0
+}
+
+// LastCopy is the number of the last page output by this request
+func (p *InvoicePrint) LastCopy() int {
+	return p.FirstCopy + p.Copies - 1
+}
+
+// Labels returns the mark printed on each page: ORIGINAL for the first page ever
+// output, then COPY n OF m, where m counts every copy issued up to this request
+func (p *InvoicePrint) Labels() []string {
+	labels := make([]string, 0, p.Copies)
+	for n := p.FirstCopy; n <= p.LastCopy(); n++ {
+		if n == 0 {
+			labels = append(labels, OriginalLabel)
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("COPY %d OF %d", n, p.LastCopy()))
+	}
+	return labels
+}
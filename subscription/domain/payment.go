@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentStatus tracks a subscription payment through the gateway
+type PaymentStatus string
+
+const (
+	// PaymentPending: the payer was sent to the gateway and has not been confirmed yet
+	PaymentPending PaymentStatus = "PENDING"
+	// PaymentCompleted: the gateway confirmed the payment; the plan is activated
+	PaymentCompleted PaymentStatus = "COMPLETED"
+	// PaymentFailed: cancelled, expired or refused at the gateway
+	PaymentFailed PaymentStatus = "FAILED"
+)
+
+// SubscriptionPayment is a tenant paying for a plan through a payment gateway. The
+// subscription starts only once the gateway has confirmed the full amount.
+type SubscriptionPayment struct {
+	ID       uuid.UUID     `json:"id"`
+	TenantID uuid.UUID     `json:"tenantId"`
+	PlanID   uuid.UUID     `json:"planId"`
+	Gateway  string        `json:"gateway"` // esewa, khalti
+	Status   PaymentStatus `json:"status"`
+	Amount   float64       `json:"amount"` // Plan price plus VAT
+	Currency string        `json:"currency"`
+	// GatewayRef is the gateway's id for the payment (Khalti's pidx), if it issues one
+	GatewayRef *string `json:"gatewayRef,omitempty"`
+	// TransactionID is the gateway's transaction code once the payment completed
+	TransactionID  *string    `json:"transactionId,omitempty"`
+	SubscriptionID *uuid.UUID `json:"subscriptionId,omitempty"` // Set once the plan is activated
+	FailureReason  *string    `json:"failureReason,omitempty"`
+	InitiatedBy    *uuid.UUID `json:"initiatedBy,omitempty"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// NewSubscriptionPayment creates a pending payment for the plan's price with VAT at vatRate percent
+func NewSubscriptionPayment(tenantID uuid.UUID, plan *Plan, gateway string, vatRate float64) *SubscriptionPayment {
+	now := time.Now()
+	return &SubscriptionPayment{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		PlanID:    plan.ID,
+		Gateway:   gateway,
+		Status:    PaymentPending,
+		Amount:    PlanCharge(plan, vatRate),
+		Currency:  plan.Currency,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// PlanCharge is what one period of the plan costs with VAT, as invoiced
+func PlanCharge(plan *Plan, vatRate float64) float64 {
+	subtotal := roundMoney(plan.Price)
+	return roundMoney(subtotal + roundMoney(subtotal*vatRate/100))
+}
+
+// PaymentRef is how the payment is quoted on the invoice, e.g. "esewa 000AWEO"
+func (p *SubscriptionPayment) PaymentRef() string {
+	if p.TransactionID == nil {
+		return p.Gateway
+	}
+	return p.Gateway + " " + *p.TransactionID
+}
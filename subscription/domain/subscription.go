@@ -42,8 +42,45 @@ type Subscription struct {
 	StartDate time.Time          `json:"startDate"`
 	EndDate   time.Time          `json:"endDate"`
 	AutoRenew bool               `json:"autoRenew"`
-	CreatedAt time.Time          `json:"createdAt"`
-	UpdatedAt time.Time          `json:"updatedAt"`
+	// ExpiryWarnedAt is when the tenant was warned this period is ending
+	ExpiryWarnedAt *time.Time `json:"expiryWarnedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// ExpiryNotice identifies which renewal or expiry message a tenant is sent
+type ExpiryNotice string
+
+const (
+	ExpiryNoticeUpcoming   ExpiryNotice = "UPCOMING"    // Period ends soon and will not renew by itself
+	ExpiryNoticeRenewed    ExpiryNotice = "RENEWED"     // Free plan renewed for another period
+	ExpiryNoticePaymentDue ExpiryNotice = "PAYMENT_DUE" // Paid period ended; pay before the grace period runs out
+	ExpiryNoticeExpired    ExpiryNotice = "EXPIRED"     // Grace period over without renewal
+)
+
+// PeriodEnd returns when a period on the plan starting at start ends
+func (p *Plan) PeriodEnd(start time.Time) time.Time {
+	if p.Interval == "YEARLY" {
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// RenewsItself reports whether the subscription renews without the tenant paying:
+// auto-renew is on and the plan is free and still offered. eSewa and Khalti have
+// no recurring charges, so paid plans are renewed through checkout.
+func (s *Subscription) RenewsItself() bool {
+	return s.AutoRenew && s.Plan != nil && s.Plan.Price <= 0 && s.Plan.IsActive
+}
+
+// EntitledAt reports whether the subscription's plan still applies at now: it is active
+// or awaiting payment, and its period, plus the grace period after it, has not run out.
+// Expired and cancelled subscriptions entitle the tenant to nothing.
+func (s *Subscription) EntitledAt(now time.Time, grace time.Duration) bool {
+	if s.Status != SubscriptionStatusActive && s.Status != SubscriptionStatusPastDue {
+		return false
+	}
+	return now.Before(s.EndDate.Add(grace))
 }
 
 // NewPlan creates a new plan
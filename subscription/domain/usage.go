@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Plan limit keys tracked by the usage meter
+const (
+	LimitKeyProducts         = "max_products"
+	LimitKeyUsers            = "max_users"
+	LimitKeyAPICallsPerMonth = "max_api_calls_per_month"
+	LimitKeyInvoicesPerMonth = "max_invoices_per_month"
+)
+
+// UsageWarningThreshold is the share of a limit after which responses carry a warning
+const UsageWarningThreshold = 0.8
+
+// TenantUsage is a metered counter for a tenant within a billing period
+type TenantUsage struct {
+	TenantID    uuid.UUID `json:"tenantId"`
+	Metric      string    `json:"metric"`
+	PeriodStart time.Time `json:"periodStart"`
+	Value       int64     `json:"value"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// UsageStatus compares current usage against a plan limit
+type UsageStatus struct {
+	LimitKey  string  `json:"limitKey"`
+	Used      int64   `json:"used"`
+	Limit     int     `json:"limit"`     // -1 means unlimited
+	Remaining int64   `json:"remaining"` // -1 when unlimited
+	Percent   float64 `json:"percent"`
+}
+
+// NewUsageStatus builds a status from a usage value and a plan limit
+func NewUsageStatus(limitKey string, used int64, limit int) UsageStatus {
+	status := UsageStatus{LimitKey: limitKey, Used: used, Limit: limit, Remaining: -1}
+	if limit < 0 {
+		return status
+	}
+
+	status.Remaining = int64(limit) - used
+	if status.Remaining < 0 {
+		status.Remaining = 0
+	}
+	if limit == 0 {
+		status.Percent = 100
+	} else {
+		status.Percent = float64(used) / float64(limit) * 100
+	}
+	return status
+}
+
+func (u UsageStatus) IsUnlimited() bool {
+	return u.Limit < 0
+}
+
+// IsNearLimit reports whether usage has crossed the warning threshold
+func (u UsageStatus) IsNearLimit() bool {
+	return !u.IsUnlimited() && u.Percent >= UsageWarningThreshold*100
+}
+
+func (u UsageStatus) IsExceeded() bool {
+	return !u.IsUnlimited() && u.Used >= int64(u.Limit)
+}
+
+// WarningMessage returns a human-readable warning for the client
+func (u UsageStatus) WarningMessage() string {
+	if u.IsExceeded() {
+		return fmt.Sprintf("%s limit reached: %d of %d used", u.LimitKey, u.Used, u.Limit)
+	}
+	return fmt.Sprintf("%s at %.0f%% of plan limit: %d of %d used", u.LimitKey, u.Percent, u.Used, u.Limit)
+}
+
+// PlanUsage compares a tenant's consumption with every metered limit of their plan
+type PlanUsage struct {
+	PlanID   uuid.UUID     `json:"planId"`
+	PlanCode string        `json:"planCode"`
+	PlanName string        `json:"planName"`
+	Limits   []UsageStatus `json:"limits"`
+}
+
+// MonthStart returns the start of the calendar month containing t (UTC)
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// LimitLabel shortens a limit key for headers: max_api_calls_per_month -> api_calls
+func LimitLabel(limitKey string) string {
+	label := strings.TrimPrefix(limitKey, "max_")
+	return strings.TrimSuffix(label, "_per_month")
+}
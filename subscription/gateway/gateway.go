@@ -0,0 +1,76 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// requestTimeout bounds one call to a gateway; the payer is waiting on it
+const requestTimeout = 15 * time.Second
+
+var (
+	// ErrInvalidCallback is returned for a return to the callback URL that names no payment
+	// or whose signature does not match
+	ErrInvalidCallback = errors.New("invalid payment callback")
+	// ErrUnavailable wraps failures reaching the gateway or reading its answer
+	ErrUnavailable = errors.New("payment gateway unavailable")
+)
+
+// Status is what the gateway reports about a payment
+type Status string
+
+const (
+	StatusCompleted Status = "COMPLETED"
+	StatusPending   Status = "PENDING" // Not finished yet, or the gateway cannot tell yet
+	StatusFailed    Status = "FAILED"  // Cancelled, expired, refunded or never made
+)
+
+// Payment is a subscription payment as a gateway sees it
+type Payment struct {
+	ID          uuid.UUID // Sent to the gateway as the merchant's order id
+	Amount      float64   // Total charged, VAT included
+	Description string
+	// Reference is the gateway's own id for the payment, when it issues one (Khalti's pidx)
+	Reference string
+	// CallbackURL is where the payer returns to, successful or not
+	CallbackURL string
+}
+
+// Redirect sends the payer to the gateway: a GET of URL, or a POST of Fields to URL
+type Redirect struct {
+	Method string            `json:"method"`
+	URL    string            `json:"url"`
+	Fields map[string]string `json:"fields,omitempty"`
+	// Reference is the gateway's id for the payment, if it issued one
+	Reference string `json:"-"`
+}
+
+// Callback is what the payer's return to the callback URL says about a payment.
+// It is never trusted on its own: the payment is verified with the gateway.
+type Callback struct {
+	PaymentID uuid.UUID
+	Reference string
+}
+
+// Result is the gateway's answer to a verification
+type Result struct {
+	Status        Status
+	TransactionID string // Gateway's transaction code, e.g. eSewa ref_id
+	Amount        float64
+	Detail        string // Gateway's own status, e.g. "User canceled"
+}
+
+// Gateway is a payment provider tenants pay for their subscription through
+type Gateway interface {
+	Name() string
+	// Initiate starts a payment and returns where to send the payer
+	Initiate(ctx context.Context, payment Payment) (*Redirect, error)
+	// ParseCallback reads the payer's return to the callback URL
+	ParseCallback(query url.Values) (*Callback, error)
+	// Verify asks the gateway, server to server, how the payment stands
+	Verify(ctx context.Context, payment Payment) (*Result, error)
+}
@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// Khalti takes payments through Khalti's web checkout (KPG-2): the payment is
+// initiated server side and the payer returns with its pidx
+type Khalti struct {
+	secretKey string
+	baseURL   string
+	client    *http.Client
+}
+
+// NewKhalti creates a Khalti driver for the merchant; sandbox uses Khalti's test environment
+func NewKhalti(secretKey string, sandbox bool) *Khalti {
+	k := &Khalti{
+		secretKey: secretKey,
+		baseURL:   "https://khalti.com/api/v2/epayment/",
+		client:    &http.Client{Timeout: requestTimeout},
+	}
+	if sandbox {
+		k.baseURL = "https://dev.khalti.com/api/v2/epayment/"
+	}
+	return k
+}
+
+func (k *Khalti) Name() string {
+	return "khalti"
+}
+
+// Initiate registers the payment with Khalti, which answers with its payment page
+func (k *Khalti) Initiate(ctx context.Context, payment Payment) (*Redirect, error) {
+	websiteURL := payment.CallbackURL
+	if u, err := url.Parse(payment.CallbackURL); err == nil {
+		websiteURL = u.Scheme + "://" + u.Host
+	}
+
+	request := map[string]interface{}{
+		"return_url":          payment.CallbackURL,
+		"website_url":         websiteURL,
+		"amount":              khaltiPaisa(payment.Amount),
+		"purchase_order_id":   payment.ID.String(),
+		"purchase_order_name": payment.Description,
+	}
+	var body struct {
+		PIDX       string `json:"pidx"`
+		PaymentURL string `json:"payment_url"`
+		Detail     string `json:"detail"`
+	}
+	if err := k.post(ctx, "initiate/", request, &body); err != nil {
+		return nil, err
+	}
+	if body.PIDX == "" || body.PaymentURL == "" {
+		return nil, fmt.Errorf("%w: Khalti did not start the payment: %s", ErrUnavailable, body.Detail)
+	}
+
+	return &Redirect{Method: http.MethodGet, URL: body.PaymentURL, Reference: body.PIDX}, nil
+}
+
+// ParseCallback reads the order id and pidx Khalti returns the payer with
+func (k *Khalti) ParseCallback(query url.Values) (*Callback, error) {
+	id, err := uuid.Parse(query.Get("purchase_order_id"))
+	if err != nil {
+		return nil, ErrInvalidCallback
+	}
+	return &Callback{PaymentID: id, Reference: query.Get("pidx")}, nil
+}
+
+// Verify looks the payment up by its pidx
+func (k *Khalti) Verify(ctx context.Context, payment Payment) (*Result, error) {
+	if payment.Reference == "" {
+		return &Result{Status: StatusFailed, Detail: "never initiated"}, nil
+	}
+
+	var body struct {
+		Status        string  `json:"status"`
+		TransactionID *string `json:"transaction_id"`
+		TotalAmount   int64   `json:"total_amount"`
+	}
+	if err := k.post(ctx, "lookup/", map[string]string{"pidx": payment.Reference}, &body); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Detail: body.Status, Amount: float64(body.TotalAmount) / 100}
+	if body.TransactionID != nil {
+		result.TransactionID = *body.TransactionID
+	}
+	switch body.Status {
+	case "Completed":
+		result.Status = StatusCompleted
+	case "Pending", "Initiated":
+		result.Status = StatusPending
+	default:
+		// Expired, User canceled, Refunded, Partially Refunded
+		result.Status = StatusFailed
+	}
+	return result, nil
+}
+
+// post sends a JSON request authorized with the merchant's secret key
+func (k *Khalti) post(ctx context.Context, path string, request, response interface{}) error {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	req.Header.Set("Authorization", "Key "+k.secretKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	// Lookups of payments that did not complete answer 400 with the status in the body
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("%w: Khalti responded %d", ErrUnavailable, resp.StatusCode)
+	}
+	if err := json.Unmarshal(raw, response); err != nil {
+		return fmt.Errorf("%w: unreadable response: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// khaltiPaisa converts rupees to the paisa Khalti amounts are in
+func khaltiPaisa(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -4,6 +4,7 @@ go 1.24.0
 
 require (
 	github.com/aceextension/core v0.0.0
+	github.com/aceextension/fiscal v0.0.0
 	github.com/aceextension/identity v0.0.0
 	github.com/google/uuid v1.6.0
 	github.com/labstack/echo/v4 v4.13.3
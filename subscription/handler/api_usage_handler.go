@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/service"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// apiUsageDefaultDays is the period reported when no dates are given
+	apiUsageDefaultDays = 30
+	// apiUsageMaxDays bounds the period of one report
+	apiUsageMaxDays = 366
+)
+
+// APIUsageHandler reports a tenant's API traffic from the usage meter's daily rollups
+type APIUsageHandler struct {
+	metering service.MeteringService
+}
+
+func NewAPIUsageHandler(metering service.MeteringService) *APIUsageHandler {
+	return &APIUsageHandler{metering: metering}
+}
+
+// GetAPIUsage returns the tenant's API traffic
+// @Summary Get API usage analytics
+// @Description Request counts and error rates per API key, per user session and per day, with
+// @Description the busiest endpoints, for the tenant. Days are UTC; the last 30 seconds of
+// @Description traffic may not be counted yet.
+// @Tags usage
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD), default 29 days before to"
+// @Param to query string false "Last day (YYYY-MM-DD), default today"
+// @Param limit query int false "Number of top endpoints (default 10, max 100)"
+// @Success 200 {object} domain.APIUsageReport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/usage/api [get]
+// @Security BearerAuth
+func (h *APIUsageHandler) GetAPIUsage(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	to := domain.DayStart(time.Now())
+	if value := c.QueryParam("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to date, expected YYYY-MM-DD"})
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(apiUsageDefaultDays - 1))
+	if value := c.QueryParam("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from date, expected YYYY-MM-DD"})
+		}
+		from = parsed
+	}
+	// The last day is included
+	to = to.AddDate(0, 0, 1)
+	if !from.Before(to) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "from must not be after to"})
+	}
+	if to.Sub(from) > apiUsageMaxDays*24*time.Hour {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Period cannot be longer than " + strconv.Itoa(apiUsageMaxDays) + " days"})
+	}
+
+	limit := 10
+	if value := c.QueryParam("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 100 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 100"})
+		}
+		limit = parsed
+	}
+
+	report, err := h.metering.APIUsage(c.Request().Context(), tenantID, from, to, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, report)
+}
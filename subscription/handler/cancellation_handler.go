@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// CancellationHandler handles self-serve cancellation and reactivation
+type CancellationHandler struct {
+	service service.CancellationService
+}
+
+func NewCancellationHandler(service service.CancellationService) *CancellationHandler {
+	return &CancellationHandler{service: service}
+}
+
+// CancelRequest is the cancellation body with the exit survey
+type CancelRequest struct {
+	Reason   domain.CancellationReason `json:"reason" validate:"required"` // TOO_EXPENSIVE, MISSING_FEATURES, SWITCHED_PROVIDER, TOO_COMPLEX, BUSINESS_CLOSED, TEMPORARY, OTHER
+	Feedback string                    `json:"feedback"`
+}
+
+// ReactivateRequest optionally picks a plan when the subscription has already ended
+type ReactivateRequest struct {
+	PlanID *uuid.UUID `json:"planId"`
+}
+
+// Cancel schedules cancellation at the end of the paid period
+// @Summary Cancel subscription
+// @Description Stop renewal at the end of the current period. Data is kept for the retention period after that, with export reminders, and the account can be reactivated until it is purged.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param request body CancelRequest true "Exit survey"
+// @Success 201 {object} domain.Cancellation
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/subscriptions/cancel [post]
+// @Security BearerAuth
+func (h *CancellationHandler) Cancel(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var req CancelRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	cancellation, err := h.service.Cancel(c.Request().Context(), tenantID, req.Reason, req.Feedback)
+	if err != nil {
+		return cancellationError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, cancellation)
+}
+
+// GetCancellation returns the pending cancellation
+// @Summary Get pending cancellation
+// @Description Returns the scheduled or effective cancellation with its purge date, or 404 if none
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {object} domain.Cancellation
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/subscriptions/cancellation [get]
+// @Security BearerAuth
+func (h *CancellationHandler) GetCancellation(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	cancellation, err := h.service.GetCancellation(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if cancellation == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "No pending cancellation"})
+	}
+
+	return c.JSON(http.StatusOK, cancellation)
+}
+
+// Reactivate undoes a pending cancellation
+// @Summary Reactivate subscription
+// @Description Resume renewal before the period ends, or re-subscribe (default: previous plan) before data is purged
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param request body ReactivateRequest false "Plan"
+// @Success 200 {object} domain.Subscription
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Router /api/v1/subscriptions/reactivate [post]
+// @Security BearerAuth
+func (h *CancellationHandler) Reactivate(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var req ReactivateRequest
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+
+	sub, err := h.service.Reactivate(c.Request().Context(), tenantID, req.PlanID)
+	if err != nil {
+		return cancellationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, sub)
+}
+
+func cancellationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidCancelReason), errors.Is(err, service.ErrReactivationPlan):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrNoActiveSubscription), errors.Is(err, service.ErrNoPendingCancellation):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrAlreadyCancelled):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrRetentionExpired):
+		return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrPaymentRequired):
+		return c.JSON(http.StatusPaymentRequired, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
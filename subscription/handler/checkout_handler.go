@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/gateway"
+	"github.com/aceextension/subscription/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// CheckoutHandler handles paying for plans through eSewa and Khalti
+type CheckoutHandler struct {
+	service service.CheckoutService
+	// returnURL is the billing page payers are sent back to after the gateway
+	returnURL string
+}
+
+// NewCheckoutHandler creates the handler; returnURL is the app's billing page, which
+// gets ?payment=<id>&status=<status> after a payment. Empty answers callbacks with JSON.
+func NewCheckoutHandler(service service.CheckoutService, returnURL string) *CheckoutHandler {
+	return &CheckoutHandler{service: service, returnURL: returnURL}
+}
+
+// CheckoutRequest picks the plan and the gateway to pay through
+type CheckoutRequest struct {
+	PlanID  uuid.UUID `json:"planId" validate:"required"`
+	Gateway string    `json:"gateway" validate:"required"` // esewa, khalti
+}
+
+// CheckoutResponse is the pending payment and where to send the payer
+type CheckoutResponse struct {
+	Payment  *domain.SubscriptionPayment `json:"payment"`
+	Redirect *gateway.Redirect           `json:"redirect"`
+}
+
+// ListGateways lists the gateways plans can be paid through
+// @Summary List payment gateways
+// @Description Gateways paid plans can be paid through, e.g. ["esewa","khalti"]
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {array} string
+// @Router /api/v1/subscriptions/gateways [get]
+// @Security BearerAuth
+func (h *CheckoutHandler) ListGateways(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.service.Gateways())
+}
+
+// Checkout starts paying for a plan
+// @Summary Pay for a plan
+// @Description Create a pending payment and return where to send the payer: a GET of redirect.url, or for eSewa a form POST of redirect.fields to redirect.url. The plan starts once the gateway confirms the payment.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param request body CheckoutRequest true "Plan and gateway"
+// @Success 201 {object} CheckoutResponse
+// @Failure 400 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /api/v1/subscriptions/checkout [post]
+// @Security BearerAuth
+func (h *CheckoutHandler) Checkout(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	var req CheckoutRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var initiatedBy *uuid.UUID
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		initiatedBy = &userID
+	}
+
+	payment, redirect, err := h.service.Start(c.Request().Context(), tenantID, req.PlanID, strings.ToLower(req.Gateway), initiatedBy)
+	if err != nil {
+		return checkoutError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, CheckoutResponse{Payment: payment, Redirect: redirect})
+}
+
+// ListPayments lists the tenant's plan payments
+// @Summary List subscription payments
+// @Description Plan payments through eSewa and Khalti, newest first
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {array} domain.SubscriptionPayment
+// @Router /api/v1/subscriptions/payments [get]
+// @Security BearerAuth
+func (h *CheckoutHandler) ListPayments(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	payments, err := h.service.ListPayments(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, payments)
+}
+
+// GetPayment returns a plan payment
+// @Summary Get subscription payment
+// @Description Get a plan payment and its status
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} domain.SubscriptionPayment
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/subscriptions/payments/{id} [get]
+// @Security BearerAuth
+func (h *CheckoutHandler) GetPayment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid payment ID"})
+	}
+
+	payment, err := h.service.GetPayment(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return checkoutError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, payment)
+}
+
+// VerifyPayment checks a payment with its gateway
+// @Summary Verify subscription payment
+// @Description Ask the gateway how a pending payment stands, e.g. when the payer closed the page before returning. A confirmed payment starts the plan.
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} domain.SubscriptionPayment
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /api/v1/subscriptions/payments/{id}/verify [post]
+// @Security BearerAuth
+func (h *CheckoutHandler) VerifyPayment(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid payment ID"})
+	}
+
+	payment, err := h.service.Verify(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return checkoutError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, payment)
+}
+
+// Callback is where the gateway sends the payer back to
+// @Summary Payment gateway return
+// @Description The payer's return from eSewa or Khalti. The payment is verified with the gateway, the plan started if it was paid, and the payer redirected to the billing page with ?payment=<id>&status=<status>.
+// @Tags subscriptions
+// @Param gateway path string true "Gateway (esewa, khalti)"
+// @Success 303
+// @Router /api/v1/subscriptions/payments/callback/{gateway} [get]
+func (h *CheckoutHandler) Callback(c echo.Context) error {
+	payment, err := h.service.HandleCallback(c.Request().Context(), c.Param("gateway"), c.QueryParams())
+	if err != nil {
+		log.Printf("Failed to handle %s payment callback: %v", c.Param("gateway"), err)
+	}
+
+	if h.returnURL == "" {
+		if err != nil {
+			return checkoutError(c, err)
+		}
+		return c.JSON(http.StatusOK, payment)
+	}
+
+	query := url.Values{}
+	if payment != nil {
+		query.Set("payment", payment.ID.String())
+		query.Set("status", strings.ToLower(string(payment.Status)))
+	} else {
+		query.Set("status", "error")
+	}
+	separator := "?"
+	if strings.Contains(h.returnURL, "?") {
+		separator = "&"
+	}
+	return c.Redirect(http.StatusSeeOther, h.returnURL+separator+query.Encode())
+}
+
+func checkoutError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnknownGateway), errors.Is(err, service.ErrNoPaymentNeeded),
+		errors.Is(err, service.ErrPlanNotActive), errors.Is(err, gateway.ErrInvalidCallback):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrPaymentNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, gateway.ErrUnavailable):
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aceextension/core/db"
+	identityMiddleware "github.com/aceextension/identity/middleware"
+	"github.com/aceextension/subscription/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// EnforcementHandler exposes read-only enforcement state and super-admin overrides
+type EnforcementHandler struct {
+	service service.EnforcementService
+}
+
+func NewEnforcementHandler(service service.EnforcementService) *EnforcementHandler {
+	return &EnforcementHandler{service: service}
+}
+
+// GrantOverrideRequest is the body for a read-only override
+type GrantOverrideRequest struct {
+	Until  time.Time `json:"until" validate:"required"`
+	Reason string    `json:"reason" validate:"required"`
+}
+
+// GetAccess returns the tenant's access mode
+// @Summary Get subscription access mode
+// @Description FULL, GRACE (expired, still writable) or READ_ONLY, with the relevant dates
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {object} domain.AccessState
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/subscriptions/access [get]
+// @Security BearerAuth
+func (h *EnforcementHandler) GetAccess(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	state, err := h.service.AccessState(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, state)
+}
+
+// GrantOverride keeps an expired tenant writable until a date
+// @Summary Override read-only mode
+// @Description Keep an expired tenant fully usable until the given time (super admin)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Param request body GrantOverrideRequest true "Override"
+// @Success 200 {object} domain.AccessOverride
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/admin/tenants/{tenantId}/access-override [post]
+// @Security BearerAuth
+func (h *EnforcementHandler) GrantOverride(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Param("tenantId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tenant ID"})
+	}
+
+	var req GrantOverrideRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	var grantedBy *uuid.UUID
+	if user, ok := c.Get("user").(identityMiddleware.AuthUser); ok {
+		if id, err := uuid.Parse(user.UserID); err == nil {
+			grantedBy = &id
+		}
+	}
+
+	override, err := h.service.GrantOverride(c.Request().Context(), tenantID, req.Until, req.Reason, grantedBy)
+	if err != nil {
+		if errors.Is(err, service.ErrOverrideInPast) || errors.Is(err, service.ErrOverrideReasonEmpty) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, override)
+}
+
+// RevokeOverride removes a tenant's read-only override
+// @Summary Remove read-only override
+// @Description Remove a tenant's override so normal enforcement applies (super admin)
+// @Tags admin
+// @Param tenantId path string true "Tenant ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/admin/tenants/{tenantId}/access-override [delete]
+// @Security BearerAuth
+func (h *EnforcementHandler) RevokeOverride(c echo.Context) error {
+	tenantID, err := uuid.Parse(c.Param("tenantId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tenant ID"})
+	}
+
+	if err := h.service.RevokeOverride(c.Request().Context(), tenantID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/aceextension/core/db"
 	authService "github.com/aceextension/identity/service"
@@ -33,13 +35,14 @@ type CreatePlanRequest struct {
 
 // Create creates a new plan
 // @Summary Create a plan
-// @Description Create a new subscription plan (Admin)
+// @Description Create a new subscription plan (super admins only)
 // @Tags plans
 // @Accept json
 // @Produce json
 // @Param request body CreatePlanRequest true "Plan Request"
 // @Success 201 {object} map[string]string
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Router /api/v1/plans [post]
 // @Security BearerAuth
 func (h *PlanHandler) Create(c echo.Context) error {
@@ -131,6 +134,7 @@ type SubscribeRequest struct {
 // @Param request body SubscribeRequest true "Subscribe Request"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
+// @Failure 402 {object} map[string]string
 // @Router /api/v1/subscriptions/subscribe [post]
 // @Security BearerAuth
 func (h *SubscriptionHandler) Subscribe(c echo.Context) error {
@@ -151,8 +155,56 @@ func (h *SubscriptionHandler) Subscribe(c echo.Context) error {
 
 	sub, err := h.service.Subscribe(c.Request().Context(), tenantID, planID)
 	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentRequired):
+			return c.JSON(http.StatusPaymentRequired, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrPlanNotActive):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, sub)
 }
+
+// GetHistory returns the tenant's plan and entitlement history
+// @Summary Get subscription history
+// @Description Entitlement snapshots (plan, features, limits) with effective date ranges, newest first. Pass at=YYYY-MM-DD to get only the snapshot in effect on that date.
+// @Tags subscriptions
+// @Produce json
+// @Param at query string false "Date (YYYY-MM-DD)"
+// @Success 200 {array} domain.EntitlementSnapshot
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/subscriptions/history [get]
+// @Security BearerAuth
+func (h *SubscriptionHandler) GetHistory(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	if atParam := c.QueryParam("at"); atParam != "" {
+		at, err := time.Parse("2006-01-02", atParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid at date, expected YYYY-MM-DD"})
+		}
+
+		// End of day, so a change made that day is included
+		snapshot, err := h.service.GetEntitlementsAt(c.Request().Context(), tenantID, at.AddDate(0, 0, 1).Add(-time.Nanosecond))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		if snapshot == nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "No subscription on that date"})
+		}
+		return c.JSON(http.StatusOK, []*domain.EntitlementSnapshot{snapshot})
+	}
+
+	history, err := h.service.GetEntitlementHistory(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
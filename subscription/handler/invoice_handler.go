@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// InvoiceHandler handles subscription invoices
+type InvoiceHandler struct {
+	service service.InvoiceService
+}
+
+func NewInvoiceHandler(service service.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{service: service}
+}
+
+// List lists the tenant's subscription invoices
+// @Summary List subscription invoices
+// @Description Get the tenant's subscription invoice and receipt history, newest first
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {array} domain.SubscriptionInvoice
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/subscriptions/invoices [get]
+// @Security BearerAuth
+func (h *InvoiceHandler) List(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	invoices, err := h.service.ListInvoices(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, invoices)
+}
+
+// Get returns a single subscription invoice
+// @Summary Get subscription invoice
+// @Description Get a subscription invoice by ID
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} domain.SubscriptionInvoice
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/subscriptions/invoices/{id} [get]
+// @Security BearerAuth
+func (h *InvoiceHandler) Get(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid invoice ID"})
+	}
+
+	invoice, err := h.service.GetInvoice(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return invoiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, invoice)
+}
+
+// DownloadPDF renders a subscription invoice as PDF
+// @Summary Download subscription invoice PDF
+// @Description Download or print the tax invoice (or receipt, once paid) as a PDF. Every request is logged;
+// @Description the first page ever output is the original and later pages are marked "COPY n OF m".
+// @Tags subscriptions
+// @Produce application/pdf
+// @Param id path string true "Invoice ID"
+// @Param mode query string false "download (default) or print"
+// @Param copies query int false "Copies to output, 1 to 5 (default 1)"
+// @Param reason query string false "Reason for a reprint"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/subscriptions/invoices/{id}/pdf [get]
+// @Security BearerAuth
+func (h *InvoiceHandler) DownloadPDF(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid invoice ID"})
+	}
+
+	req := service.PrintRequest{
+		TenantID:  tenantID,
+		InvoiceID: id,
+		Kind:      domain.PrintKindDownload,
+		Copies:    1,
+		Reason:    strings.TrimSpace(c.QueryParam("reason")),
+		IPAddress: c.RealIP(),
+	}
+	switch c.QueryParam("mode") {
+	case "", "download":
+	case "print":
+		req.Kind = domain.PrintKindPrint
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "mode must be download or print"})
+	}
+	if raw := c.QueryParam("copies"); raw != "" {
+		if req.Copies, err = strconv.Atoi(raw); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": service.ErrInvalidPrintCopies.Error()})
+		}
+	}
+	if userID, ok := db.GetUserID(c.Request().Context()); ok {
+		req.UserID = &userID
+	}
+
+	invoice, record, pdf, err := h.service.PrintPDF(c.Request().Context(), req)
+	if err != nil {
+		return invoiceError(c, err)
+	}
+
+	c.Response().Header().Set("X-Invoice-Copies", strings.Join(record.Labels(), ", "))
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="`+invoice.InvoiceNumber+`.pdf"`)
+	return c.Blob(http.StatusOK, "application/pdf", pdf)
+}
+
+// ListPrints returns the print and reprint log of a subscription invoice
+// @Summary List subscription invoice prints
+// @Description Get every print and download of an invoice, oldest first, with the copy numbers issued
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {array} domain.InvoicePrint
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/subscriptions/invoices/{id}/prints [get]
+// @Security BearerAuth
+func (h *InvoiceHandler) ListPrints(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid invoice ID"})
+	}
+
+	prints, err := h.service.ListPrints(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return invoiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, prints)
+}
+
+// Send emails a subscription invoice to the tenant
+// @Summary Email subscription invoice
+// @Description Re-send the invoice PDF to the tenant's billing email
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/subscriptions/invoices/{id}/send [post]
+// @Security BearerAuth
+func (h *InvoiceHandler) Send(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid invoice ID"})
+	}
+
+	if err := h.service.SendEmail(c.Request().Context(), tenantID, id); err != nil {
+		return invoiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Invoice sent"})
+}
+
+func invoiceError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvoiceNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrNoBillingRecipient), errors.Is(err, service.ErrInvoiceVoid), errors.Is(err, service.ErrInvalidPrintCopies):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/service"
+	"github.com/labstack/echo/v4"
+)
+
+// LimitHandler reports a tenant's consumption against their plan limits
+type LimitHandler struct {
+	limits service.LimitChecker
+}
+
+func NewLimitHandler(limits service.LimitChecker) *LimitHandler {
+	return &LimitHandler{limits: limits}
+}
+
+// GetUsage returns the tenant's usage of each plan limit
+// @Summary Get plan usage
+// @Description Current consumption against each metered limit of the tenant's plan (users, products,
+// @Description invoices and API calls this month), counted now rather than from the cached meter.
+// @Description A limit of -1 is unlimited. Creations past a limit are refused with 402 PLAN_LIMIT_EXCEEDED.
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {object} domain.PlanUsage
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/subscriptions/usage [get]
+// @Security BearerAuth
+func (h *LimitHandler) GetUsage(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+	}
+
+	usage, err := h.limits.Usage(c.Request().Context(), tenantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if usage == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "No subscription found"})
+	}
+	return c.JSON(http.StatusOK, usage)
+}
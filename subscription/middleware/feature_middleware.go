@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/subscription/service"
+	"github.com/labstack/echo/v4"
+)
+
+// FeatureRequiredResponse is returned when the tenant's plan does not include a feature
+type FeatureRequiredResponse struct {
+	Error      string `json:"error"`
+	Code       string `json:"code"`
+	Feature    string `json:"feature"`
+	UpgradeURL string `json:"upgradeUrl"`
+}
+
+// RequireFeature lets requests through only when the tenant's plan includes feature
+// (e.g. "accounting"), answering 402 with an upgrade link otherwise. The plan's features
+// are cached on the request, so further checks by handlers through HasFeature are free.
+// It fails closed: when the plan cannot be looked up the request is refused with 503,
+// since letting it through would hand out features the tenant may not have paid for.
+// A request without a tenant is refused with 401. Super admins are never blocked. It
+// must run after JWTMiddleware so the tenant is known.
+func RequireFeature(subscriptions service.SubscriptionService, feature, upgradeURL string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if isSuperAdmin(c) {
+				return next(c)
+			}
+
+			tenantID, ok := resolveTenantID(c)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant not found"})
+			}
+
+			ctx := service.WithFeatureCache(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			allowed, err := subscriptions.HasFeature(ctx, tenantID, feature)
+			if err != nil {
+				logger.Log.Warn("Failed to check plan feature " + feature + ": " + err.Error())
+				return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "Could not check your plan; try again shortly"})
+			}
+			if !allowed {
+				return c.JSON(http.StatusPaymentRequired, FeatureRequiredResponse{
+					Error:      fmt.Sprintf("Your plan does not include %s. Upgrade your plan to use it.", feature),
+					Code:       "PLAN_FEATURE_REQUIRED",
+					Feature:    feature,
+					UpgradeURL: upgradeURL,
+				})
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aceextension/core/logger"
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/service"
+	"github.com/labstack/echo/v4"
+)
+
+// LimitExceededResponse is returned when a creation is blocked by a plan limit
+type LimitExceededResponse struct {
+	Error      string `json:"error"`
+	Code       string `json:"code"`
+	LimitKey   string `json:"limitKey"`
+	Used       int64  `json:"used"`
+	Limit      int    `json:"limit"`
+	UpgradeURL string `json:"upgradeUrl"`
+}
+
+// PlanLimitMiddleware blocks requests that create one more of limitKey (e.g. products
+// on POST /products with domain.LimitKeyProducts) once the tenant has reached their
+// plan's limit, with 402 and an upgrade link. Only mutations are checked and super
+// admins are never blocked. Concurrent creations may overshoot the limit by a few.
+// It must run after JWTMiddleware so the tenant is known.
+func PlanLimitMiddleware(limits service.LimitChecker, limitKey, upgradeURL string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !isMutation(c.Request().Method) || isSuperAdmin(c) {
+				return next(c)
+			}
+
+			tenantID, ok := resolveTenantID(c)
+			if !ok {
+				return next(c)
+			}
+
+			status, err := limits.Check(c.Request().Context(), tenantID, limitKey)
+			if errors.Is(err, service.ErrLimitExceeded) {
+				return c.JSON(http.StatusPaymentRequired, LimitExceededResponse{
+					Error: fmt.Sprintf("Plan limit reached: %d of %d %s used. Upgrade your plan to add more.",
+						status.Used, status.Limit, domain.LimitLabel(limitKey)),
+					Code:       "PLAN_LIMIT_EXCEEDED",
+					LimitKey:   limitKey,
+					Used:       status.Used,
+					Limit:      status.Limit,
+					UpgradeURL: upgradeURL,
+				})
+			}
+			if err != nil {
+				// Fail open: a billing lookup problem must not stop tenants working
+				logger.Log.Warn("Failed to check plan limit " + limitKey + ": " + err.Error())
+			}
+			return next(c)
+		}
+	}
+}
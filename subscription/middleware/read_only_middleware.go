@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
+	identityMiddleware "github.com/aceextension/identity/middleware"
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/service"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// HeaderSubscriptionAccess carries the tenant's access mode when it is not FULL
+	HeaderSubscriptionAccess = "X-Subscription-Access"
+	// HeaderReadOnlyFrom tells clients in the grace period when writes will be blocked
+	HeaderReadOnlyFrom = "X-Subscription-Read-Only-From"
+
+	roleSuperAdmin = "super_admin"
+)
+
+// readOnlyExemptPrefixes stay writable so a read-only tenant can still pay, renew and sign out
+var readOnlyExemptPrefixes = []string{
+	"/api/v1/subscriptions",
+	"/api/auth/",
+}
+
+// ReadOnlyResponse is returned when a mutation is blocked
+type ReadOnlyResponse struct {
+	Error      string     `json:"error"`
+	Code       string     `json:"code"`
+	UpgradeURL string     `json:"upgradeUrl"`
+	ExpiredAt  *time.Time `json:"expiredAt,omitempty"`
+}
+
+// ReadOnlyMiddleware switches tenants whose subscription lapsed beyond the grace period
+// to read-only: GET/HEAD/OPTIONS pass, other methods get 402 with an upgrade link.
+// Super admins are never blocked. It must run after JWTMiddleware so the tenant is known.
+func ReadOnlyMiddleware(enforcement service.EnforcementService, upgradeURL string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if isSuperAdmin(c) {
+				return next(c)
+			}
+
+			tenantID, ok := resolveTenantID(c)
+			if !ok {
+				return next(c)
+			}
+
+			state, err := enforcement.AccessState(c.Request().Context(), tenantID)
+			if err != nil {
+				// Fail open: a billing lookup problem must not take tenants offline
+				logger.Log.Warn("Failed to resolve subscription access state: " + err.Error())
+				return next(c)
+			}
+			if state.Mode == domain.AccessModeFull {
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderSubscriptionAccess, string(state.Mode))
+			if state.ReadOnlyFrom != nil {
+				header.Set(HeaderReadOnlyFrom, state.ReadOnlyFrom.UTC().Format(time.RFC3339))
+			}
+
+			if state.Mode == domain.AccessModeReadOnly && isMutation(c.Request().Method) && !isReadOnlyExempt(c.Request().URL.Path) {
+				return c.JSON(http.StatusPaymentRequired, ReadOnlyResponse{
+					Error:      "Your subscription has expired and your account is read-only. Renew your plan to make changes.",
+					Code:       "SUBSCRIPTION_READ_ONLY",
+					UpgradeURL: upgradeURL,
+					ExpiredAt:  state.ExpiredAt,
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+func isReadOnlyExempt(path string) bool {
+	for _, prefix := range readOnlyExemptPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSuperAdmin(c echo.Context) bool {
+	if user, ok := c.Get("user").(identityMiddleware.AuthUser); ok && user.Role == roleSuperAdmin {
+		return true
+	}
+	return db.IsSuperAdmin(c.Request().Context())
+}
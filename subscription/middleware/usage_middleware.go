@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/apperrors"
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/core/logger"
+	identityMiddleware "github.com/aceextension/identity/middleware"
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// HeaderPlanLimitRemaining lists remaining quota per limit, e.g. "api_calls=4500, products=120"
+	HeaderPlanLimitRemaining = "X-Plan-Limit-Remaining"
+	// HeaderPlanUsageWarning is set when any limit is above the warning threshold
+	HeaderPlanUsageWarning = "X-Plan-Usage-Warning"
+)
+
+// UsageMiddleware meters API calls and surfaces soft plan limits.
+// Each request is also counted in the daily rollup of its caller and route, which
+// GET /api/v1/usage/api reports.
+// Every response gets an X-Plan-Limit-Remaining header; once a limit is
+// past 80% the limit keys are listed in X-Plan-Usage-Warning and JSON object
+// responses get a top-level "warnings" array. Requests are never blocked.
+// It must run after JWTMiddleware so the tenant is known.
+func UsageMiddleware(metering service.MeteringService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			tenantID, ok := resolveTenantID(c)
+			if !ok {
+				return next(c)
+			}
+
+			metering.RecordAPICall(tenantID)
+			defer func() {
+				status := c.Response().Status
+				if err != nil && !c.Response().Committed {
+					// The error handler writes the response after the middleware returns
+					status = apperrors.StatusCode(err)
+				}
+				metering.RecordAPIRequest(usageBucket(c, tenantID), status)
+			}()
+
+			statuses, err := metering.GetUsage(c.Request().Context(), tenantID)
+			if err != nil {
+				logger.Log.Warn("Failed to load plan usage: " + err.Error())
+				return next(c)
+			}
+			if len(statuses) == 0 {
+				return next(c)
+			}
+
+			remaining := make([]string, 0, len(statuses))
+			warnings := []string{}
+			warnedKeys := []string{}
+			for _, status := range statuses {
+				if status.IsUnlimited() {
+					continue
+				}
+				remaining = append(remaining, fmt.Sprintf("%s=%d", domain.LimitLabel(status.LimitKey), status.Remaining))
+				if status.IsNearLimit() {
+					warnings = append(warnings, status.WarningMessage())
+					warnedKeys = append(warnedKeys, domain.LimitLabel(status.LimitKey))
+				}
+			}
+
+			header := c.Response().Header()
+			if len(remaining) > 0 {
+				header.Set(HeaderPlanLimitRemaining, strings.Join(remaining, ", "))
+			}
+			if len(warnings) == 0 {
+				return next(c)
+			}
+			header.Set(HeaderPlanUsageWarning, strings.Join(warnedKeys, ", "))
+
+			// Buffer the body so the warnings can be added to the JSON envelope
+			original := c.Response().Writer
+			buffer := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+			c.Response().Writer = buffer
+			defer func() { c.Response().Writer = original }()
+
+			if err := next(c); err != nil {
+				// Let the error handler write to the real writer
+				c.Response().Writer = original
+				if buffer.body.Len() > 0 || buffer.wroteHeader {
+					buffer.flushTo(original, nil)
+				}
+				return err
+			}
+
+			buffer.flushTo(original, warnings)
+			return nil
+		}
+	}
+}
+
+// resolveTenantID prefers the authenticated user's tenant over the request context,
+// since the context tenant can come from a client-supplied header
+func resolveTenantID(c echo.Context) (uuid.UUID, bool) {
+	if user, ok := c.Get("user").(identityMiddleware.AuthUser); ok && user.TenantID != "" {
+		if tenantID, err := uuid.Parse(user.TenantID); err == nil {
+			return tenantID, true
+		}
+	}
+
+	return db.GetTenantID(c.Request().Context())
+}
+
+// usageBucket identifies the request's caller and route for the daily rollup
+func usageBucket(c echo.Context, tenantID uuid.UUID) domain.APIUsageBucket {
+	bucket := domain.APIUsageBucket{
+		TenantID: tenantID,
+		Day:      domain.DayStart(time.Now()),
+		Method:   c.Request().Method,
+		Route:    c.Path(),
+	}
+	if user, ok := c.Get("user").(identityMiddleware.AuthUser); ok {
+		bucket.UserID, _ = uuid.Parse(user.UserID)
+		bucket.APIKeyID, _ = uuid.Parse(user.APIKeyID)
+	}
+	return bucket
+}
+
+// bufferedWriter holds the response until the handler returns
+type bufferedWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// flushTo writes the buffered response, adding warnings to JSON object bodies
+func (w *bufferedWriter) flushTo(dst http.ResponseWriter, warnings []string) {
+	body := w.body.Bytes()
+	if len(warnings) > 0 && strings.HasPrefix(dst.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		body = injectWarnings(body, warnings)
+	}
+	dst.Header().Del(echo.HeaderContentLength)
+	dst.WriteHeader(w.status)
+	dst.Write(body)
+}
+
+// injectWarnings adds a top-level "warnings" field to a JSON object.
+// Arrays and other payloads are returned unchanged; the headers still carry the warning.
+func injectWarnings(body []byte, warnings []string) []byte {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return body
+	}
+	if _, exists := envelope["warnings"]; exists {
+		return body
+	}
+
+	encoded, err := json.Marshal(warnings)
+	if err != nil {
+		return body
+	}
+	envelope["warnings"] = encoded
+
+	out, err := json.Marshal(envelope)
+	if err != nil {
+		return body
+	}
+	return append(out, '\n')
+}
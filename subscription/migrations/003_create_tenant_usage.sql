@@ -0,0 +1,10 @@
+-- Tenant Usage Table
+-- Metered counters per tenant and billing period (e.g. API calls per calendar month)
+CREATE TABLE IF NOT EXISTS tenant_usage (
+    tenant_id UUID NOT NULL,
+    metric VARCHAR(100) NOT NULL, -- matches a plan limit key, e.g. max_api_calls_per_month
+    period_start DATE NOT NULL,
+    value BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, metric, period_start)
+);
@@ -0,0 +1,31 @@
+-- Subscription Invoices Table
+-- Tax invoices issued by the platform to tenants; seller/buyer details are snapshotted at issue time
+CREATE SEQUENCE IF NOT EXISTS subscription_invoice_seq;
+
+CREATE TABLE IF NOT EXISTS subscription_invoices (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id),
+    plan_id UUID NOT NULL REFERENCES plans(id),
+    invoice_number VARCHAR(50) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL, -- ISSUED, PAID, VOID
+    issue_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    description TEXT NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    subtotal DECIMAL(12, 2) NOT NULL,
+    vat_rate DECIMAL(5, 2) NOT NULL,
+    vat_amount DECIMAL(12, 2) NOT NULL,
+    total DECIMAL(12, 2) NOT NULL,
+    seller JSONB NOT NULL,
+    buyer JSONB NOT NULL,
+    paid_at TIMESTAMP WITH TIME ZONE,
+    payment_ref VARCHAR(255),
+    emailed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_subscription_invoices_tenant ON subscription_invoices(tenant_id, issue_date DESC);
+CREATE INDEX idx_subscription_invoices_subscription ON subscription_invoices(subscription_id);
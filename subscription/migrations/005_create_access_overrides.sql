@@ -0,0 +1,9 @@
+-- Access Overrides Table
+-- Super-admin exemptions from read-only enforcement for expired tenants (one per tenant)
+CREATE TABLE IF NOT EXISTS subscription_access_overrides (
+    tenant_id UUID PRIMARY KEY,
+    until TIMESTAMP WITH TIME ZONE NOT NULL,
+    reason TEXT NOT NULL,
+    granted_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
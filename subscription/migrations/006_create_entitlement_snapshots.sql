@@ -0,0 +1,29 @@
+-- Entitlement Snapshots Table
+-- Plan, features and limits a tenant had over a date range; recorded at every subscription change
+CREATE TABLE IF NOT EXISTS entitlement_snapshots (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    subscription_id UUID REFERENCES subscriptions(id),
+    plan_id UUID NOT NULL REFERENCES plans(id),
+    plan_code VARCHAR(100) NOT NULL,
+    plan_name VARCHAR(255) NOT NULL,
+    features JSONB NOT NULL DEFAULT '{}'::jsonb,
+    limits JSONB NOT NULL DEFAULT '{}'::jsonb,
+    change VARCHAR(30) NOT NULL, -- SUBSCRIBED, PLAN_CHANGED, RENEWED, PLAN_UPDATED, CANCELLED, EXPIRED
+    effective_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    effective_to TIMESTAMP WITH TIME ZONE, -- NULL while current
+    changed_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_entitlement_snapshots_tenant ON entitlement_snapshots(tenant_id, effective_from DESC);
+-- At most one open snapshot per tenant
+CREATE UNIQUE INDEX idx_entitlement_snapshots_current ON entitlement_snapshots(tenant_id) WHERE effective_to IS NULL;
+
+-- Backfill from existing subscriptions with the plans' current entitlements (best available record)
+INSERT INTO entitlement_snapshots (id, tenant_id, subscription_id, plan_id, plan_code, plan_name, features, limits, change, effective_from, effective_to)
+SELECT gen_random_uuid(), s.tenant_id, s.id, p.id, p.code, p.name, p.features, p.limits, 'SUBSCRIBED',
+       s.start_date,
+       LEAD(s.start_date) OVER (PARTITION BY s.tenant_id ORDER BY s.start_date)
+FROM subscriptions s
+JOIN plans p ON p.id = s.plan_id;
@@ -0,0 +1,25 @@
+-- Subscription Cancellations Table
+-- End-of-period cancellations with exit survey and data retention countdown
+CREATE TABLE IF NOT EXISTS subscription_cancellations (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id),
+    status VARCHAR(20) NOT NULL, -- SCHEDULED, EFFECTIVE, REACTIVATED, PURGED
+    reason VARCHAR(50) NOT NULL,
+    feedback TEXT,
+    requested_by UUID,
+    requested_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    purge_after TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_reminder_days INT,
+    reactivated_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- One open cancellation per tenant
+CREATE UNIQUE INDEX idx_subscription_cancellations_open ON subscription_cancellations(tenant_id)
+    WHERE status IN ('SCHEDULED', 'EFFECTIVE');
+CREATE INDEX idx_subscription_cancellations_due ON subscription_cancellations(status, effective_at);
+-- Exit survey reporting
+CREATE INDEX idx_subscription_cancellations_reason ON subscription_cancellations(reason, requested_at);
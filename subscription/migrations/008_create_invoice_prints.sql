@@ -0,0 +1,19 @@
+-- Subscription Invoice Prints Table
+-- Every print and download of an invoice; the first page output is the original and
+-- later pages are numbered copies, as IRD requires on reprints
+ALTER TABLE subscription_invoices ADD COLUMN IF NOT EXISTS print_count INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS subscription_invoice_prints (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    invoice_id UUID NOT NULL REFERENCES subscription_invoices(id),
+    kind VARCHAR(20) NOT NULL, -- PRINT, DOWNLOAD
+    first_copy INT NOT NULL, -- 0 is the original
+    copies INT NOT NULL,
+    reason TEXT,
+    printed_by UUID,
+    ip_address VARCHAR(64),
+    printed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_subscription_invoice_prints_invoice ON subscription_invoice_prints(invoice_id, printed_at);
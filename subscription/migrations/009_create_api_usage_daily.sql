@@ -0,0 +1,16 @@
+-- API Usage Daily Rollups
+-- Requests per tenant, caller and endpoint per day, flushed by the usage meter, so
+-- tenant admins can see which API key or user is driving their traffic
+CREATE TABLE IF NOT EXISTS api_usage_daily (
+    tenant_id UUID NOT NULL,
+    day DATE NOT NULL,
+    api_key_id UUID NOT NULL, -- nil UUID for requests made with a user session
+    user_id UUID NOT NULL, -- the session's user, or the key's creator
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL, -- route pattern, e.g. /api/v1/products/:id
+    requests BIGINT NOT NULL DEFAULT 0,
+    client_errors BIGINT NOT NULL DEFAULT 0, -- 4xx responses
+    server_errors BIGINT NOT NULL DEFAULT 0, -- 5xx responses
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, day, api_key_id, user_id, method, route)
+);
@@ -0,0 +1,25 @@
+-- Subscription Payments Table
+-- Payments for paid plans through eSewa and Khalti; the plan is activated only
+-- after the gateway confirms the payment
+CREATE TABLE IF NOT EXISTS subscription_payments (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    plan_id UUID NOT NULL REFERENCES plans(id),
+    gateway VARCHAR(20) NOT NULL, -- esewa, khalti
+    status VARCHAR(20) NOT NULL, -- PENDING, COMPLETED, FAILED
+    amount DECIMAL(10, 2) NOT NULL,
+    currency VARCHAR(10) NOT NULL DEFAULT 'NPR',
+    gateway_ref VARCHAR(100),
+    transaction_id VARCHAR(100),
+    subscription_id UUID REFERENCES subscriptions(id),
+    failure_reason TEXT,
+    initiated_by UUID,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_subscription_payments_tenant ON subscription_payments(tenant_id, created_at DESC);
+-- A gateway transaction pays for one subscription only
+CREATE UNIQUE INDEX idx_subscription_payments_transaction ON subscription_payments(gateway, transaction_id)
+    WHERE transaction_id IS NOT NULL;
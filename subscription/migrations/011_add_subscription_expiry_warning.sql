@@ -0,0 +1,5 @@
+-- Subscription expiry warnings
+-- When the tenant was last warned that the current period is ending, so the renewal
+-- worker sends the warning once per period
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS expiry_warned_at TIMESTAMP WITH TIME ZONE;
+
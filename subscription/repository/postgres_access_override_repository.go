@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type postgresAccessOverrideRepository struct {
+	pool db.QueryExecutor
+}
+
+func NewPostgresAccessOverrideRepository(pool db.QueryExecutor) AccessOverrideRepository {
+	return &postgresAccessOverrideRepository{pool: pool}
+}
+
+func (r *postgresAccessOverrideRepository) Upsert(ctx context.Context, override *domain.AccessOverride) error {
+	query := `
+		INSERT INTO subscription_access_overrides (tenant_id, until, reason, granted_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET until = EXCLUDED.until, reason = EXCLUDED.reason, granted_by = EXCLUDED.granted_by, created_at = EXCLUDED.created_at
+	`
+	_, err := r.pool.Exec(ctx, query, override.TenantID, override.Until, override.Reason, override.GrantedBy, override.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save access override: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresAccessOverrideRepository) Get(ctx context.Context, tenantID uuid.UUID) (*domain.AccessOverride, error) {
+	query := `SELECT tenant_id, until, reason, granted_by, created_at FROM subscription_access_overrides WHERE tenant_id = $1`
+
+	var o domain.AccessOverride
+	err := r.pool.QueryRow(ctx, query, tenantID).Scan(&o.TenantID, &o.Until, &o.Reason, &o.GrantedBy, &o.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get access override: %w", err)
+	}
+	return &o, nil
+}
+
+func (r *postgresAccessOverrideRepository) Delete(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM subscription_access_overrides WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete access override: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/google/uuid"
+)
+
+type postgresAPIUsageRepository struct {
+	pool db.QueryExecutor
+}
+
+func NewPostgresAPIUsageRepository(pool db.QueryExecutor) APIUsageRepository {
+	return &postgresAPIUsageRepository{pool: pool}
+}
+
+// apiUsageSums are the aggregate columns every report query selects last
+const apiUsageSums = `SUM(u.requests), SUM(u.client_errors), SUM(u.server_errors)`
+
+func (r *postgresAPIUsageRepository) Increment(ctx context.Context, bucket domain.APIUsageBucket, counts domain.APIUsageCounts) error {
+	query := `
+		INSERT INTO api_usage_daily (tenant_id, day, api_key_id, user_id, method, route, requests, client_errors, server_errors, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		ON CONFLICT (tenant_id, day, api_key_id, user_id, method, route)
+		DO UPDATE SET requests = api_usage_daily.requests + EXCLUDED.requests,
+		              client_errors = api_usage_daily.client_errors + EXCLUDED.client_errors,
+		              server_errors = api_usage_daily.server_errors + EXCLUDED.server_errors,
+		              updated_at = NOW()
+	`
+	_, err := r.pool.Exec(ctx, query, bucket.TenantID, bucket.Day, bucket.APIKeyID, bucket.UserID, bucket.Method, bucket.Route,
+		counts.Requests, counts.ClientErrors, counts.ServerErrors)
+	return err
+}
+
+func (r *postgresAPIUsageRepository) ByAPIKey(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]domain.APIKeyUsage, error) {
+	query := `
+		SELECT u.api_key_id, COALESCE(k.name, ''), ` + apiUsageSums + `
+		FROM api_usage_daily u
+		LEFT JOIN api_keys k ON k.id = u.api_key_id
+		WHERE u.tenant_id = $1 AND u.day >= $2 AND u.day < $3 AND u.api_key_id <> $4
+		GROUP BY u.api_key_id, k.name
+		ORDER BY SUM(u.requests) DESC
+	`
+	rows, err := r.pool.Query(ctx, query, tenantID, from, to, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []domain.APIKeyUsage{}
+	for rows.Next() {
+		var item domain.APIKeyUsage
+		var counts domain.APIUsageCounts
+		if err := rows.Scan(&item.APIKeyID, &item.Name, &counts.Requests, &counts.ClientErrors, &counts.ServerErrors); err != nil {
+			return nil, err
+		}
+		item.APIUsageStats = domain.NewAPIUsageStats(counts)
+		usage = append(usage, item)
+	}
+	return usage, rows.Err()
+}
+
+func (r *postgresAPIUsageRepository) ByUser(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]domain.UserAPIUsage, error) {
+	query := `
+		SELECT u.user_id, COALESCE(usr.name, ''), ` + apiUsageSums + `
+		FROM api_usage_daily u
+		LEFT JOIN users usr ON usr.id = u.user_id
+		WHERE u.tenant_id = $1 AND u.day >= $2 AND u.day < $3 AND u.api_key_id = $4
+		GROUP BY u.user_id, usr.name
+		ORDER BY SUM(u.requests) DESC
+	`
+	rows, err := r.pool.Query(ctx, query, tenantID, from, to, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []domain.UserAPIUsage{}
+	for rows.Next() {
+		var item domain.UserAPIUsage
+		var counts domain.APIUsageCounts
+		if err := rows.Scan(&item.UserID, &item.Name, &counts.Requests, &counts.ClientErrors, &counts.ServerErrors); err != nil {
+			return nil, err
+		}
+		item.APIUsageStats = domain.NewAPIUsageStats(counts)
+		usage = append(usage, item)
+	}
+	return usage, rows.Err()
+}
+
+func (r *postgresAPIUsageRepository) TopEndpoints(ctx context.Context, tenantID uuid.UUID, from, to time.Time, limit int) ([]domain.EndpointUsage, error) {
+	query := `
+		SELECT u.method, u.route, ` + apiUsageSums + `
+		FROM api_usage_daily u
+		WHERE u.tenant_id = $1 AND u.day >= $2 AND u.day < $3
+		GROUP BY u.method, u.route
+		ORDER BY SUM(u.requests) DESC, u.route
+		LIMIT $4
+	`
+	rows, err := r.pool.Query(ctx, query, tenantID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []domain.EndpointUsage{}
+	for rows.Next() {
+		var item domain.EndpointUsage
+		var counts domain.APIUsageCounts
+		if err := rows.Scan(&item.Method, &item.Route, &counts.Requests, &counts.ClientErrors, &counts.ServerErrors); err != nil {
+			return nil, err
+		}
+		item.APIUsageStats = domain.NewAPIUsageStats(counts)
+		usage = append(usage, item)
+	}
+	return usage, rows.Err()
+}
+
+func (r *postgresAPIUsageRepository) Daily(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]domain.DailyAPIUsage, error) {
+	query := `
+		SELECT u.day, ` + apiUsageSums + `
+		FROM api_usage_daily u
+		WHERE u.tenant_id = $1 AND u.day >= $2 AND u.day < $3
+		GROUP BY u.day
+		ORDER BY u.day
+	`
+	rows, err := r.pool.Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []domain.DailyAPIUsage{}
+	for rows.Next() {
+		var item domain.DailyAPIUsage
+		var counts domain.APIUsageCounts
+		if err := rows.Scan(&item.Day, &counts.Requests, &counts.ClientErrors, &counts.ServerErrors); err != nil {
+			return nil, err
+		}
+		item.APIUsageStats = domain.NewAPIUsageStats(counts)
+		usage = append(usage, item)
+	}
+	return usage, rows.Err()
+}
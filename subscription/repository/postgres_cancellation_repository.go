@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type postgresCancellationRepository struct {
+	pool db.QueryExecutor
+}
+
+func NewPostgresCancellationRepository(pool db.QueryExecutor) CancellationRepository {
+	return &postgresCancellationRepository{pool: pool}
+}
+
+const cancellationColumns = `id, tenant_id, subscription_id, status, reason, feedback, requested_by, requested_at,
+	effective_at, purge_after, last_reminder_days, reactivated_at, created_at, updated_at`
+
+func (r *postgresCancellationRepository) Create(ctx context.Context, c *domain.Cancellation) error {
+	query := `
+		INSERT INTO subscription_cancellations (` + cancellationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		c.ID, c.TenantID, c.SubscriptionID, c.Status, c.Reason, c.Feedback, c.RequestedBy, c.RequestedAt,
+		c.EffectiveAt, c.PurgeAfter, c.LastReminder, c.ReactivatedAt, c.CreatedAt, c.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create cancellation: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresCancellationRepository) GetOpenByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.Cancellation, error) {
+	query := `
+		SELECT ` + cancellationColumns + `
+		FROM subscription_cancellations
+		WHERE tenant_id = $1 AND status IN ('SCHEDULED', 'EFFECTIVE')
+	`
+	c, err := r.scanCancellation(r.pool.QueryRow(ctx, query, tenantID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+func (r *postgresCancellationRepository) ListOpen(ctx context.Context) ([]*domain.Cancellation, error) {
+	query := `
+		SELECT ` + cancellationColumns + `
+		FROM subscription_cancellations
+		WHERE status IN ('SCHEDULED', 'EFFECTIVE')
+		ORDER BY effective_at
+	`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open cancellations: %w", err)
+	}
+	defer rows.Close()
+
+	var cancellations []*domain.Cancellation
+	for rows.Next() {
+		c, err := r.scanCancellation(rows)
+		if err != nil {
+			return nil, err
+		}
+		cancellations = append(cancellations, c)
+	}
+	return cancellations, rows.Err()
+}
+
+func (r *postgresCancellationRepository) Update(ctx context.Context, c *domain.Cancellation) error {
+	query := `
+		UPDATE subscription_cancellations
+		SET status = $2, last_reminder_days = $3, reactivated_at = $4, updated_at = $5
+		WHERE id = $1
+	`
+	_, err := r.pool.Exec(ctx, query, c.ID, c.Status, c.LastReminder, c.ReactivatedAt, c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update cancellation: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresCancellationRepository) scanCancellation(row pgx.Row) (*domain.Cancellation, error) {
+	var c domain.Cancellation
+	err := row.Scan(
+		&c.ID, &c.TenantID, &c.SubscriptionID, &c.Status, &c.Reason, &c.Feedback, &c.RequestedBy, &c.RequestedAt,
+		&c.EffectiveAt, &c.PurgeAfter, &c.LastReminder, &c.ReactivatedAt, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
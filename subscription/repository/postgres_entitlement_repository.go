@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type postgresEntitlementRepository struct {
+	pool db.QueryExecutor
+}
+
+func NewPostgresEntitlementRepository(pool db.QueryExecutor) EntitlementRepository {
+	return &postgresEntitlementRepository{pool: pool}
+}
+
+const entitlementColumns = `id, tenant_id, subscription_id, plan_id, plan_code, plan_name, features, limits,
+	change, effective_from, effective_to, changed_by, created_at`
+
+func (r *postgresEntitlementRepository) Record(ctx context.Context, snapshot *domain.EntitlementSnapshot) error {
+	featuresJSON, _ := json.Marshal(snapshot.Features)
+	limitsJSON, _ := json.Marshal(snapshot.Limits)
+
+	// A batch runs as one implicit transaction, so the close and insert are atomic
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		UPDATE entitlement_snapshots SET effective_to = $2
+		WHERE tenant_id = $1 AND effective_to IS NULL
+	`, snapshot.TenantID, snapshot.EffectiveFrom)
+	batch.Queue(`
+		INSERT INTO entitlement_snapshots (`+entitlementColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULL, $11, $12)
+	`,
+		snapshot.ID, snapshot.TenantID, snapshot.SubscriptionID, snapshot.PlanID, snapshot.PlanCode, snapshot.PlanName,
+		featuresJSON, limitsJSON, snapshot.Change, snapshot.EffectiveFrom, snapshot.ChangedBy, snapshot.CreatedAt,
+	)
+
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to record entitlement snapshot: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresEntitlementRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.EntitlementSnapshot, error) {
+	query := `SELECT ` + entitlementColumns + ` FROM entitlement_snapshots WHERE tenant_id = $1 ORDER BY effective_from DESC`
+	rows, err := r.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entitlement snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []*domain.EntitlementSnapshot{}
+	for rows.Next() {
+		snapshot, err := r.scanSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+func (r *postgresEntitlementRepository) GetAt(ctx context.Context, tenantID uuid.UUID, at time.Time) (*domain.EntitlementSnapshot, error) {
+	query := `
+		SELECT ` + entitlementColumns + `
+		FROM entitlement_snapshots
+		WHERE tenant_id = $1 AND effective_from <= $2 AND (effective_to IS NULL OR effective_to > $2)
+		ORDER BY effective_from DESC
+		LIMIT 1
+	`
+	snapshot, err := r.scanSnapshot(r.pool.QueryRow(ctx, query, tenantID, at))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return snapshot, err
+}
+
+func (r *postgresEntitlementRepository) scanSnapshot(row pgx.Row) (*domain.EntitlementSnapshot, error) {
+	var s domain.EntitlementSnapshot
+	var featuresJSON, limitsJSON []byte
+
+	err := row.Scan(
+		&s.ID, &s.TenantID, &s.SubscriptionID, &s.PlanID, &s.PlanCode, &s.PlanName, &featuresJSON, &limitsJSON,
+		&s.Change, &s.EffectiveFrom, &s.EffectiveTo, &s.ChangedBy, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal(featuresJSON, &s.Features)
+	json.Unmarshal(limitsJSON, &s.Limits)
+	return &s, nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type postgresInvoiceRepository struct {
+	pool db.QueryExecutor
+}
+
+func NewPostgresInvoiceRepository(pool db.QueryExecutor) InvoiceRepository {
+	return &postgresInvoiceRepository{pool: pool}
+}
+
+const invoiceColumns = `id, tenant_id, subscription_id, plan_id, invoice_number, status, issue_date,
+	period_start, period_end, description, currency, subtotal, vat_rate, vat_amount, total,
+	seller, buyer, paid_at, payment_ref, emailed_at, print_count, created_at, updated_at`
+
+func (r *postgresInvoiceRepository) Create(ctx context.Context, invoice *domain.SubscriptionInvoice) error {
+	sellerJSON, _ := json.Marshal(invoice.Seller)
+	buyerJSON, _ := json.Marshal(invoice.Buyer)
+
+	// Numbers are gapless per sequence and prefixed with the issue year: SUB-2026-000042
+	query := `
+		INSERT INTO subscription_invoices (
+			id, tenant_id, subscription_id, plan_id, invoice_number, status, issue_date,
+			period_start, period_end, description, currency, subtotal, vat_rate, vat_amount, total,
+			seller, buyer, paid_at, payment_ref, emailed_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4,
+			'SUB-' || to_char($6::timestamptz, 'YYYY') || '-' || lpad(nextval('subscription_invoice_seq')::text, 6, '0'),
+			$5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
+		)
+		RETURNING invoice_number
+	`
+	err := r.pool.QueryRow(ctx, query,
+		invoice.ID, invoice.TenantID, invoice.SubscriptionID, invoice.PlanID, invoice.Status, invoice.IssueDate,
+		invoice.PeriodStart, invoice.PeriodEnd, invoice.Description, invoice.Currency,
+		invoice.Subtotal, invoice.VATRate, invoice.VATAmount, invoice.Total,
+		sellerJSON, buyerJSON, invoice.PaidAt, invoice.PaymentRef, invoice.EmailedAt, invoice.CreatedAt, invoice.UpdatedAt,
+	).Scan(&invoice.InvoiceNumber)
+	if err != nil {
+		return fmt.Errorf("failed to create subscription invoice: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresInvoiceRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.SubscriptionInvoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM subscription_invoices WHERE id = $1 AND tenant_id = $2`
+	return r.scanInvoice(r.pool.QueryRow(ctx, query, id, tenantID))
+}
+
+func (r *postgresInvoiceRepository) GetBySubscriptionID(ctx context.Context, subscriptionID uuid.UUID) (*domain.SubscriptionInvoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM subscription_invoices WHERE subscription_id = $1 AND status <> 'VOID' LIMIT 1`
+	return r.scanInvoice(r.pool.QueryRow(ctx, query, subscriptionID))
+}
+
+func (r *postgresInvoiceRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.SubscriptionInvoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM subscription_invoices WHERE tenant_id = $1 ORDER BY issue_date DESC`
+	rows, err := r.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscription invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := []*domain.SubscriptionInvoice{}
+	for rows.Next() {
+		invoice, err := r.scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices, rows.Err()
+}
+
+func (r *postgresInvoiceRepository) Update(ctx context.Context, invoice *domain.SubscriptionInvoice) error {
+	query := `
+		UPDATE subscription_invoices
+		SET status = $1, paid_at = $2, payment_ref = $3, emailed_at = $4, updated_at = $5
+		WHERE id = $6
+	`
+	_, err := r.pool.Exec(ctx, query,
+		invoice.Status, invoice.PaidAt, invoice.PaymentRef, invoice.EmailedAt, invoice.UpdatedAt, invoice.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription invoice: %w", err)
+	}
+	return nil
+}
+
+// RecordPrint reserves the next page numbers on the invoice and logs the print in one
+// statement, so concurrent reprints never share a copy number
+func (r *postgresInvoiceRepository) RecordPrint(ctx context.Context, record *domain.InvoicePrint) error {
+	query := `
+		WITH numbered AS (
+			UPDATE subscription_invoices
+			SET print_count = print_count + $5
+			WHERE id = $3 AND tenant_id = $2
+			RETURNING print_count
+		)
+		INSERT INTO subscription_invoice_prints (
+			id, tenant_id, invoice_id, kind, first_copy, copies, reason, printed_by, ip_address, printed_at
+		)
+		SELECT $1, $2, $3, $4, numbered.print_count - $5, $5, $6, $7, $8, $9 FROM numbered
+		RETURNING first_copy
+	`
+	err := r.pool.QueryRow(ctx, query,
+		record.ID, record.TenantID, record.InvoiceID, record.Kind, record.Copies,
+		record.Reason, record.PrintedBy, record.IPAddress, record.PrintedAt,
+	).Scan(&record.FirstCopy)
+	if err != nil {
+		return fmt.Errorf("failed to record invoice print: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresInvoiceRepository) ListPrints(ctx context.Context, tenantID, invoiceID uuid.UUID) ([]*domain.InvoicePrint, error) {
+	query := `
+		SELECT id, tenant_id, invoice_id, kind, first_copy, copies, reason, printed_by, ip_address, printed_at
+		FROM subscription_invoice_prints
+		WHERE tenant_id = $1 AND invoice_id = $2
+		ORDER BY printed_at, first_copy
+	`
+	rows, err := r.pool.Query(ctx, query, tenantID, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoice prints: %w", err)
+	}
+	defer rows.Close()
+
+	prints := []*domain.InvoicePrint{}
+	for rows.Next() {
+		var p domain.InvoicePrint
+		err := rows.Scan(&p.ID, &p.TenantID, &p.InvoiceID, &p.Kind, &p.FirstCopy, &p.Copies,
+			&p.Reason, &p.PrintedBy, &p.IPAddress, &p.PrintedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invoice print: %w", err)
+		}
+		prints = append(prints, &p)
+	}
+	return prints, rows.Err()
+}
+
+func (r *postgresInvoiceRepository) scanInvoice(row pgx.Row) (*domain.SubscriptionInvoice, error) {
+	var inv domain.SubscriptionInvoice
+	var sellerJSON, buyerJSON []byte
+
+	err := row.Scan(
+		&inv.ID, &inv.TenantID, &inv.SubscriptionID, &inv.PlanID, &inv.InvoiceNumber, &inv.Status, &inv.IssueDate,
+		&inv.PeriodStart, &inv.PeriodEnd, &inv.Description, &inv.Currency,
+		&inv.Subtotal, &inv.VATRate, &inv.VATAmount, &inv.Total,
+		&sellerJSON, &buyerJSON, &inv.PaidAt, &inv.PaymentRef, &inv.EmailedAt, &inv.PrintCount, &inv.CreatedAt, &inv.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to scan subscription invoice: %w", err)
+	}
+	json.Unmarshal(sellerJSON, &inv.Seller)
+	json.Unmarshal(buyerJSON, &inv.Buyer)
+	return &inv, nil
+}
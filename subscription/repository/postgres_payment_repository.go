@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type postgresPaymentRepository struct {
+	pool db.QueryExecutor
+}
+
+func NewPostgresPaymentRepository(pool db.QueryExecutor) PaymentRepository {
+	return &postgresPaymentRepository{pool: pool}
+}
+
+const paymentColumns = `id, tenant_id, plan_id, gateway, status, amount, currency, gateway_ref, transaction_id,
+	subscription_id, failure_reason, initiated_by, completed_at, created_at, updated_at`
+
+func (r *postgresPaymentRepository) Create(ctx context.Context, p *domain.SubscriptionPayment) error {
+	query := `
+		INSERT INTO subscription_payments (` + paymentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		p.ID, p.TenantID, p.PlanID, p.Gateway, p.Status, p.Amount, p.Currency, p.GatewayRef, p.TransactionID,
+		p.SubscriptionID, p.FailureReason, p.InitiatedBy, p.CompletedAt, p.CreatedAt, p.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create subscription payment: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresPaymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.SubscriptionPayment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM subscription_payments WHERE id = $1`
+	p, err := r.scanPayment(r.pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (r *postgresPaymentRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.SubscriptionPayment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM subscription_payments WHERE tenant_id = $1 ORDER BY created_at DESC`
+	rows, err := r.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscription payments: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*domain.SubscriptionPayment
+	for rows.Next() {
+		p, err := r.scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+func (r *postgresPaymentRepository) Settle(ctx context.Context, p *domain.SubscriptionPayment) (bool, error) {
+	query := `
+		UPDATE subscription_payments
+		SET status = $2, transaction_id = $3, failure_reason = $4, completed_at = $5, updated_at = $6
+		WHERE id = $1 AND status = 'PENDING'
+	`
+	tag, err := r.pool.Exec(ctx, query, p.ID, p.Status, p.TransactionID, p.FailureReason, p.CompletedAt, p.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to settle subscription payment: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *postgresPaymentRepository) Update(ctx context.Context, p *domain.SubscriptionPayment) error {
+	query := `
+		UPDATE subscription_payments
+		SET gateway_ref = $2, subscription_id = $3, updated_at = $4
+		WHERE id = $1
+	`
+	_, err := r.pool.Exec(ctx, query, p.ID, p.GatewayRef, p.SubscriptionID, p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription payment: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresPaymentRepository) scanPayment(row pgx.Row) (*domain.SubscriptionPayment, error) {
+	var p domain.SubscriptionPayment
+	err := row.Scan(
+		&p.ID, &p.TenantID, &p.PlanID, &p.Gateway, &p.Status, &p.Amount, &p.Currency, &p.GatewayRef, &p.TransactionID,
+		&p.SubscriptionID, &p.FailureReason, &p.InitiatedBy, &p.CompletedAt, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
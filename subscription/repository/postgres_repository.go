@@ -61,6 +61,9 @@ func (r *postgresPlanRepository) GetByCode(ctx context.Context, code string) (*d
 		&featuresJSON, &limitsJSON, &plan.IsActive, &plan.CreatedAt, &plan.UpdatedAt,
 	)
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
 	json.Unmarshal(featuresJSON, &plan.Features)
@@ -229,7 +232,70 @@ func (r *postgresSubscriptionRepository) Update(ctx context.Context, sub *domain
 }
 
 func (r *postgresSubscriptionRepository) FindExpiringSubscriptions(ctx context.Context, within time.Duration) ([]*domain.Subscription, error) {
-	// Basic implementation for expiring logic
-	// Implementation deferred for brevity as typically run by worker
-	return nil, nil
+	// Only each tenant's latest subscription counts; earlier periods were superseded
+	query := `
+		SELECT s.id, s.tenant_id, s.plan_id, s.status, s.start_date, s.end_date, s.auto_renew, s.expiry_warned_at, s.created_at, s.updated_at,
+		       p.id, p.name, p.code, p.description, p.price, p.currency, p.interval, p.features, p.limits, p.is_active
+		FROM (
+			SELECT DISTINCT ON (tenant_id) *
+			FROM subscriptions
+			ORDER BY tenant_id, created_at DESC
+		) s
+		JOIN plans p ON s.plan_id = p.id
+		WHERE s.status IN ('ACTIVE', 'PAST_DUE') AND s.end_date <= $1
+		ORDER BY s.end_date
+	`
+	rows, err := r.pool.Query(ctx, query, time.Now().Add(within))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*domain.Subscription
+	for rows.Next() {
+		var sub domain.Subscription
+		sub.Plan = &domain.Plan{}
+		var featuresJSON, limitsJSON []byte
+		if err := rows.Scan(
+			&sub.ID, &sub.TenantID, &sub.PlanID, &sub.Status, &sub.StartDate, &sub.EndDate, &sub.AutoRenew, &sub.ExpiryWarnedAt, &sub.CreatedAt, &sub.UpdatedAt,
+			&sub.Plan.ID, &sub.Plan.Name, &sub.Plan.Code, &sub.Plan.Description, &sub.Plan.Price, &sub.Plan.Currency, &sub.Plan.Interval,
+			&featuresJSON, &limitsJSON, &sub.Plan.IsActive,
+		); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(featuresJSON, &sub.Plan.Features)
+		json.Unmarshal(limitsJSON, &sub.Plan.Limits)
+		subs = append(subs, &sub)
+	}
+	return subs, rows.Err()
+}
+
+func (r *postgresSubscriptionRepository) MarkExpiryWarned(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE subscriptions SET expiry_warned_at=$2 WHERE id=$1`, id, at)
+	return err
+}
+
+func (r *postgresSubscriptionRepository) ListActiveByPlanID(ctx context.Context, planID uuid.UUID) ([]*domain.Subscription, error) {
+	query := `
+		SELECT id, tenant_id, plan_id, status, start_date, end_date, auto_renew, created_at, updated_at
+		FROM subscriptions
+		WHERE plan_id = $1 AND status = 'ACTIVE' AND end_date > NOW()
+	`
+	rows, err := r.pool.Query(ctx, query, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*domain.Subscription
+	for rows.Next() {
+		var sub domain.Subscription
+		if err := rows.Scan(
+			&sub.ID, &sub.TenantID, &sub.PlanID, &sub.Status, &sub.StartDate, &sub.EndDate, &sub.AutoRenew, &sub.CreatedAt, &sub.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		subs = append(subs, &sub)
+	}
+	return subs, rows.Err()
 }
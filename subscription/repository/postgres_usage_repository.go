@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type postgresUsageRepository struct {
+	pool db.QueryExecutor
+}
+
+func NewPostgresUsageRepository(pool db.QueryExecutor) UsageRepository {
+	return &postgresUsageRepository{pool: pool}
+}
+
+func (r *postgresUsageRepository) Increment(ctx context.Context, tenantID uuid.UUID, metric string, periodStart time.Time, delta int64) error {
+	query := `
+		INSERT INTO tenant_usage (tenant_id, metric, period_start, value, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (tenant_id, metric, period_start)
+		DO UPDATE SET value = tenant_usage.value + EXCLUDED.value, updated_at = NOW()
+	`
+	_, err := r.pool.Exec(ctx, query, tenantID, metric, periodStart, delta)
+	return err
+}
+
+func (r *postgresUsageRepository) Get(ctx context.Context, tenantID uuid.UUID, metric string, periodStart time.Time) (int64, error) {
+	query := `SELECT value FROM tenant_usage WHERE tenant_id = $1 AND metric = $2 AND period_start = $3`
+	var value int64
+	err := r.pool.QueryRow(ctx, query, tenantID, metric, periodStart).Scan(&value)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return value, nil
+}
@@ -12,6 +12,7 @@ import (
 type PlanRepository interface {
 	Create(ctx context.Context, plan *domain.Plan) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Plan, error)
+	// GetByCode returns the plan with the code, nil when there is none
 	GetByCode(ctx context.Context, code string) (*domain.Plan, error)
 	List(ctx context.Context) ([]*domain.Plan, error)
 	Update(ctx context.Context, plan *domain.Plan) error
@@ -25,6 +26,93 @@ type SubscriptionRepository interface {
 	Update(ctx context.Context, sub *domain.Subscription) error
 	// GetActiveByTenantID returns the active subscription for a tenant (not expired, cancelled, etc.)
 	GetActiveByTenantID(ctx context.Context, tenantID uuid.UUID) (*domain.Subscription, error)
-	// FindExpiringSubscriptions returns subscriptions expiring within the given duration
+	// ListActiveByPlanID returns active subscriptions on a plan
+	ListActiveByPlanID(ctx context.Context, planID uuid.UUID) ([]*domain.Subscription, error)
+	// FindExpiringSubscriptions returns tenants' current ACTIVE or PAST_DUE subscriptions that
+	// end within the given duration, including those already past their end date, soonest first
 	FindExpiringSubscriptions(ctx context.Context, within time.Duration) ([]*domain.Subscription, error)
+	// MarkExpiryWarned records when the tenant was warned the subscription is ending
+	MarkExpiryWarned(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// UsageRepository defines the interface for metered usage counters
+type UsageRepository interface {
+	// Increment adds delta to a tenant's counter for the period, creating it if needed
+	Increment(ctx context.Context, tenantID uuid.UUID, metric string, periodStart time.Time, delta int64) error
+	// Get returns the counter value, or 0 if nothing has been recorded
+	Get(ctx context.Context, tenantID uuid.UUID, metric string, periodStart time.Time) (int64, error)
+}
+
+// APIUsageRepository defines the interface for daily API traffic rollups. Reports
+// cover days from from up to, but not including, to.
+type APIUsageRepository interface {
+	// Increment adds counts to a bucket, creating it if needed
+	Increment(ctx context.Context, bucket domain.APIUsageBucket, counts domain.APIUsageCounts) error
+	// ByAPIKey returns traffic per API key, busiest first
+	ByAPIKey(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]domain.APIKeyUsage, error)
+	// ByUser returns traffic per user session, busiest first; API key traffic is not included
+	ByUser(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]domain.UserAPIUsage, error)
+	// TopEndpoints returns the limit busiest endpoints
+	TopEndpoints(ctx context.Context, tenantID uuid.UUID, from, to time.Time, limit int) ([]domain.EndpointUsage, error)
+	// Daily returns traffic per day, oldest first; days without traffic are left out
+	Daily(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]domain.DailyAPIUsage, error)
+}
+
+// InvoiceRepository defines the interface for subscription invoice persistence
+type InvoiceRepository interface {
+	// Create stores the invoice and assigns its invoice number
+	Create(ctx context.Context, invoice *domain.SubscriptionInvoice) error
+	// GetByID returns the invoice if it belongs to the tenant, or nil if not found
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.SubscriptionInvoice, error)
+	// ListByTenant returns the tenant's invoices, newest first
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.SubscriptionInvoice, error)
+	// GetBySubscriptionID returns the invoice issued for a subscription period, or nil
+	GetBySubscriptionID(ctx context.Context, subscriptionID uuid.UUID) (*domain.SubscriptionInvoice, error)
+	Update(ctx context.Context, invoice *domain.SubscriptionInvoice) error
+	// RecordPrint numbers the pages of a print or download and logs it, setting record.FirstCopy
+	RecordPrint(ctx context.Context, record *domain.InvoicePrint) error
+	// ListPrints returns an invoice's print and download log, oldest first
+	ListPrints(ctx context.Context, tenantID, invoiceID uuid.UUID) ([]*domain.InvoicePrint, error)
+}
+
+// PaymentRepository defines the interface for subscription payment persistence
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *domain.SubscriptionPayment) error
+	// GetByID returns the payment, or nil if not found
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.SubscriptionPayment, error)
+	// ListByTenant returns the tenant's payments, newest first
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.SubscriptionPayment, error)
+	// Settle moves a pending payment to its final status; false means it was no longer
+	// pending, e.g. the callback and a manual check raced
+	Settle(ctx context.Context, payment *domain.SubscriptionPayment) (bool, error)
+	Update(ctx context.Context, payment *domain.SubscriptionPayment) error
+}
+
+// AccessOverrideRepository defines the interface for read-only enforcement overrides
+type AccessOverrideRepository interface {
+	// Upsert creates or replaces the tenant's override
+	Upsert(ctx context.Context, override *domain.AccessOverride) error
+	// Get returns the tenant's override, or nil if none exists
+	Get(ctx context.Context, tenantID uuid.UUID) (*domain.AccessOverride, error)
+	Delete(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// EntitlementRepository defines the interface for entitlement snapshot persistence
+type EntitlementRepository interface {
+	// Record closes the tenant's open snapshot at snapshot.EffectiveFrom and stores the new one
+	Record(ctx context.Context, snapshot *domain.EntitlementSnapshot) error
+	// ListByTenant returns the tenant's snapshots, newest first
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.EntitlementSnapshot, error)
+	// GetAt returns the snapshot in effect at the given time, or nil
+	GetAt(ctx context.Context, tenantID uuid.UUID, at time.Time) (*domain.EntitlementSnapshot, error)
+}
+
+// CancellationRepository defines the interface for subscription cancellation persistence
+type CancellationRepository interface {
+	Create(ctx context.Context, cancellation *domain.Cancellation) error
+	// GetOpenByTenant returns the tenant's scheduled or effective cancellation, or nil
+	GetOpenByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.Cancellation, error)
+	// ListOpen returns all scheduled and effective cancellations (for the retention worker)
+	ListOpen(ctx context.Context) ([]*domain.Cancellation, error)
+	Update(ctx context.Context, cancellation *domain.Cancellation) error
 }
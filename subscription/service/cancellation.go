@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoActiveSubscription  = errors.New("no active subscription to cancel")
+	ErrAlreadyCancelled      = errors.New("subscription is already scheduled for cancellation")
+	ErrInvalidCancelReason   = errors.New("invalid cancellation reason")
+	ErrNoPendingCancellation = errors.New("no pending cancellation to reactivate")
+	ErrReactivationPlan      = errors.New("a plan is required to reactivate an ended subscription")
+	ErrRetentionExpired      = errors.New("data retention period has ended; the account can no longer be reactivated")
+)
+
+// RetentionNotifier tells the tenant about their cancellation and the data retention countdown
+type RetentionNotifier interface {
+	SendRetentionNotice(ctx context.Context, cancellation *domain.Cancellation, notice domain.RetentionNotice, daysLeft int) error
+}
+
+// DataPurger deletes a tenant's data once the retention period has run out
+type DataPurger interface {
+	PurgeTenantData(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// CancellationService handles self-serve cancellation, the retention countdown and reactivation
+type CancellationService interface {
+	// Cancel schedules cancellation at the end of the paid period and records the exit survey
+	Cancel(ctx context.Context, tenantID uuid.UUID, reason domain.CancellationReason, feedback string) (*domain.Cancellation, error)
+	// GetCancellation returns the tenant's pending cancellation, or nil
+	GetCancellation(ctx context.Context, tenantID uuid.UUID) (*domain.Cancellation, error)
+	// Reactivate undoes a pending cancellation. Before the period ends auto-renew is simply
+	// switched back on; afterwards the tenant re-subscribes (planID defaults to their last plan).
+	Reactivate(ctx context.Context, tenantID uuid.UUID, planID *uuid.UUID) (*domain.Subscription, error)
+	// ProcessDue ends subscriptions whose period is over, sends export reminders and purges expired data
+	ProcessDue(ctx context.Context) error
+	// OnChange registers a callback run after a tenant's subscription changes
+	OnChange(fn func(tenantID uuid.UUID))
+
+	SetRetentionNotifier(notifier RetentionNotifier)
+	SetDataPurger(purger DataPurger)
+}
+
+type cancellationService struct {
+	cancellationRepo repository.CancellationRepository
+	subRepo          repository.SubscriptionRepository
+	planRepo         repository.PlanRepository
+	entitlementRepo  repository.EntitlementRepository
+	subscriptions    SubscriptionService
+	retention        time.Duration
+
+	notifier  RetentionNotifier
+	purger    DataPurger
+	listeners []func(tenantID uuid.UUID)
+}
+
+// NewCancellationService creates the cancellation service; retention is how long data is kept
+// after the subscription ends before it is purged
+func NewCancellationService(
+	cancellationRepo repository.CancellationRepository,
+	subRepo repository.SubscriptionRepository,
+	planRepo repository.PlanRepository,
+	entitlementRepo repository.EntitlementRepository,
+	subscriptions SubscriptionService,
+	retention time.Duration,
+) CancellationService {
+	return &cancellationService{
+		cancellationRepo: cancellationRepo,
+		subRepo:          subRepo,
+		planRepo:         planRepo,
+		entitlementRepo:  entitlementRepo,
+		subscriptions:    subscriptions,
+		retention:        retention,
+	}
+}
+
+func (s *cancellationService) SetRetentionNotifier(notifier RetentionNotifier) {
+	s.notifier = notifier
+}
+
+func (s *cancellationService) SetDataPurger(purger DataPurger) {
+	s.purger = purger
+}
+
+func (s *cancellationService) OnChange(fn func(tenantID uuid.UUID)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+func (s *cancellationService) notifyChange(tenantID uuid.UUID) {
+	for _, fn := range s.listeners {
+		fn(tenantID)
+	}
+}
+
+func (s *cancellationService) Cancel(ctx context.Context, tenantID uuid.UUID, reason domain.CancellationReason, feedback string) (*domain.Cancellation, error) {
+	if !domain.ValidCancellationReasons[reason] {
+		return nil, ErrInvalidCancelReason
+	}
+
+	sub, err := s.subRepo.GetActiveByTenantID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrNoActiveSubscription
+	}
+
+	existing, err := s.cancellationRepo.GetOpenByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlreadyCancelled
+	}
+
+	var feedbackPtr *string
+	if feedback = strings.TrimSpace(feedback); feedback != "" {
+		feedbackPtr = &feedback
+	}
+	var requestedBy *uuid.UUID
+	if userID, ok := db.GetUserID(ctx); ok {
+		requestedBy = &userID
+	}
+
+	cancellation := domain.NewCancellation(sub, reason, feedbackPtr, requestedBy, s.retention)
+	if err := s.cancellationRepo.Create(ctx, cancellation); err != nil {
+		return nil, err
+	}
+
+	// Access continues until the end of the paid period; only renewal stops
+	sub.AutoRenew = false
+	sub.UpdatedAt = time.Now()
+	if err := s.subRepo.Update(ctx, sub); err != nil {
+		return nil, err
+	}
+	s.notifyChange(tenantID)
+
+	s.sendNotice(ctx, cancellation, domain.RetentionNoticeScheduled)
+	return cancellation, nil
+}
+
+func (s *cancellationService) GetCancellation(ctx context.Context, tenantID uuid.UUID) (*domain.Cancellation, error) {
+	return s.cancellationRepo.GetOpenByTenant(ctx, tenantID)
+}
+
+func (s *cancellationService) Reactivate(ctx context.Context, tenantID uuid.UUID, planID *uuid.UUID) (*domain.Subscription, error) {
+	cancellation, err := s.cancellationRepo.GetOpenByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if cancellation == nil {
+		return nil, ErrNoPendingCancellation
+	}
+	if !time.Now().Before(cancellation.PurgeAfter) {
+		return nil, ErrRetentionExpired
+	}
+
+	sub, err := s.subRepo.GetByID(ctx, cancellation.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if cancellation.Status == domain.CancellationScheduled && sub != nil && now.Before(sub.EndDate) {
+		sub.AutoRenew = true
+		sub.UpdatedAt = now
+		if err := s.subRepo.Update(ctx, sub); err != nil {
+			return nil, err
+		}
+	} else {
+		// The period is over: start a new subscription, by default on the plan they left
+		if planID == nil {
+			if sub == nil {
+				return nil, ErrReactivationPlan
+			}
+			planID = &sub.PlanID
+		}
+		if sub, err = s.subscriptions.Subscribe(ctx, tenantID, *planID); err != nil {
+			return nil, err
+		}
+	}
+
+	cancellation.Status = domain.CancellationReactivated
+	cancellation.ReactivatedAt = &now
+	cancellation.UpdatedAt = now
+	if err := s.cancellationRepo.Update(ctx, cancellation); err != nil {
+		return nil, err
+	}
+	s.notifyChange(tenantID)
+
+	s.sendNotice(ctx, cancellation, domain.RetentionNoticeReactivated)
+	return sub, nil
+}
+
+func (s *cancellationService) ProcessDue(ctx context.Context) error {
+	cancellations, err := s.cancellationRepo.ListOpen(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, c := range cancellations {
+		// Subscribing again through the normal flow also counts as coming back
+		if resubscribed, err := s.resubscribed(ctx, c); err != nil {
+			log.Printf("Failed to check subscription of cancelled tenant %s: %v", c.TenantID, err)
+			continue
+		} else if resubscribed {
+			c.Status = domain.CancellationReactivated
+			c.ReactivatedAt = &now
+			c.UpdatedAt = now
+			if err := s.cancellationRepo.Update(ctx, c); err != nil {
+				log.Printf("Failed to close cancellation for tenant %s: %v", c.TenantID, err)
+			}
+			continue
+		}
+
+		switch {
+		case c.Status == domain.CancellationScheduled && !now.Before(c.EffectiveAt):
+			if err := s.end(ctx, c, now); err != nil {
+				log.Printf("Failed to end cancelled subscription for tenant %s: %v", c.TenantID, err)
+			}
+		case c.Status == domain.CancellationEffective && !now.Before(c.PurgeAfter):
+			if err := s.purge(ctx, c, now); err != nil {
+				log.Printf("Failed to purge data for tenant %s: %v", c.TenantID, err)
+			}
+		default:
+			if threshold, ok := c.DueReminder(now); ok {
+				c.LastReminder = &threshold
+				c.UpdatedAt = now
+				if err := s.cancellationRepo.Update(ctx, c); err != nil {
+					log.Printf("Failed to record retention reminder for tenant %s: %v", c.TenantID, err)
+					continue
+				}
+				s.sendNotice(ctx, c, domain.RetentionNoticeReminder)
+			}
+		}
+	}
+	return nil
+}
+
+// resubscribed reports whether the tenant has started a new subscription since cancelling
+func (s *cancellationService) resubscribed(ctx context.Context, c *domain.Cancellation) (bool, error) {
+	active, err := s.subRepo.GetActiveByTenantID(ctx, c.TenantID)
+	if err != nil {
+		return false, err
+	}
+	return active != nil && active.ID != c.SubscriptionID, nil
+}
+
+// end marks the subscription cancelled at the end of its period and starts the retention countdown
+func (s *cancellationService) end(ctx context.Context, c *domain.Cancellation, now time.Time) error {
+	sub, err := s.subRepo.GetByID(ctx, c.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	if sub != nil {
+		sub.Status = domain.SubscriptionStatusCancelled
+		sub.UpdatedAt = now
+		if err := s.subRepo.Update(ctx, sub); err != nil {
+			return err
+		}
+
+		if plan, err := s.planRepo.GetByID(ctx, sub.PlanID); err == nil && plan != nil {
+			ended := *plan
+			ended.Features = nil
+			ended.Limits = nil
+			recordEntitlementSnapshot(ctx, s.entitlementRepo, sub, &ended, domain.EntitlementCancelled, c.EffectiveAt)
+		}
+	}
+
+	c.Status = domain.CancellationEffective
+	c.UpdatedAt = now
+	if err := s.cancellationRepo.Update(ctx, c); err != nil {
+		return err
+	}
+	s.notifyChange(c.TenantID)
+
+	s.sendNotice(ctx, c, domain.RetentionNoticeEffective)
+	return nil
+}
+
+// purge deletes the tenant's data once retention has run out; reactivation is no longer possible
+func (s *cancellationService) purge(ctx context.Context, c *domain.Cancellation, now time.Time) error {
+	if s.purger != nil {
+		if err := s.purger.PurgeTenantData(ctx, c.TenantID); err != nil {
+			return err
+		}
+	} else {
+		log.Printf("Retention period ended for tenant %s but no data purger is configured; purge manually", c.TenantID)
+	}
+
+	c.Status = domain.CancellationPurged
+	c.UpdatedAt = now
+	return s.cancellationRepo.Update(ctx, c)
+}
+
+// sendNotice delivers a retention notice; failures are logged and never block the cancellation flow
+func (s *cancellationService) sendNotice(ctx context.Context, c *domain.Cancellation, notice domain.RetentionNotice) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.SendRetentionNotice(ctx, c, notice, c.DaysUntilPurge(time.Now())); err != nil {
+		log.Printf("Failed to send %s retention notice to tenant %s: %v", notice, c.TenantID, err)
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/gateway"
+	"github.com/aceextension/subscription/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownGateway  = errors.New("payment gateway is not available")
+	ErrPaymentNotFound = errors.New("payment not found")
+	ErrNoPaymentNeeded = errors.New("free plans need no payment; subscribe to them directly")
+)
+
+// CheckoutService takes payment for paid plans through eSewa or Khalti and starts
+// the plan only once the gateway confirms the full amount was paid
+type CheckoutService interface {
+	// Gateways lists the gateways that can take payments, by name
+	Gateways() []string
+	// Start creates a pending payment for the plan and returns where to send the payer
+	Start(ctx context.Context, tenantID, planID uuid.UUID, gatewayName string, initiatedBy *uuid.UUID) (*domain.SubscriptionPayment, *gateway.Redirect, error)
+	// HandleCallback verifies the payment the payer returned from the gateway with
+	HandleCallback(ctx context.Context, gatewayName string, query url.Values) (*domain.SubscriptionPayment, error)
+	// Verify checks a pending payment with its gateway, e.g. when the payer never
+	// returned; a confirmed payment whose plan failed to start is activated again
+	Verify(ctx context.Context, tenantID, paymentID uuid.UUID) (*domain.SubscriptionPayment, error)
+	GetPayment(ctx context.Context, tenantID, paymentID uuid.UUID) (*domain.SubscriptionPayment, error)
+	// ListPayments returns the tenant's payments, newest first
+	ListPayments(ctx context.Context, tenantID uuid.UUID) ([]*domain.SubscriptionPayment, error)
+
+	RegisterGateway(g gateway.Gateway)
+}
+
+type checkoutService struct {
+	paymentRepo   repository.PaymentRepository
+	subscriptions SubscriptionService
+	vatRate       float64
+	callbackURL   string
+
+	gateways map[string]gateway.Gateway
+}
+
+// NewCheckoutService creates the checkout service. vatRate is added to plan prices, as
+// on the invoice; payers return to callbackURL followed by "/<gateway>".
+func NewCheckoutService(paymentRepo repository.PaymentRepository, subscriptions SubscriptionService, vatRate float64, callbackURL string) CheckoutService {
+	return &checkoutService{
+		paymentRepo:   paymentRepo,
+		subscriptions: subscriptions,
+		vatRate:       vatRate,
+		callbackURL:   strings.TrimRight(callbackURL, "/"),
+		gateways:      make(map[string]gateway.Gateway),
+	}
+}
+
+func (s *checkoutService) RegisterGateway(g gateway.Gateway) {
+	s.gateways[g.Name()] = g
+}
+
+func (s *checkoutService) Gateways() []string {
+	names := make([]string, 0, len(s.gateways))
+	for name := range s.gateways {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *checkoutService) Start(ctx context.Context, tenantID, planID uuid.UUID, gatewayName string, initiatedBy *uuid.UUID) (*domain.SubscriptionPayment, *gateway.Redirect, error) {
+	g, ok := s.gateways[gatewayName]
+	if !ok {
+		return nil, nil, ErrUnknownGateway
+	}
+
+	plan, err := s.subscriptions.GetPlan(ctx, planID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !plan.IsActive {
+		return nil, nil, ErrPlanNotActive
+	}
+	if plan.Price <= 0 {
+		return nil, nil, ErrNoPaymentNeeded
+	}
+
+	payment := domain.NewSubscriptionPayment(tenantID, plan, g.Name(), s.vatRate)
+	payment.InitiatedBy = initiatedBy
+	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+		return nil, nil, err
+	}
+
+	request := s.gatewayPayment(payment)
+	request.Description = plan.Name + " subscription (" + plan.Interval + ")"
+	redirect, err := g.Initiate(ctx, request)
+	if err != nil {
+		s.fail(ctx, payment, err.Error())
+		return nil, nil, err
+	}
+
+	if redirect.Reference != "" {
+		payment.GatewayRef = &redirect.Reference
+		payment.UpdatedAt = time.Now()
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return nil, nil, err
+		}
+	}
+	return payment, redirect, nil
+}
+
+func (s *checkoutService) HandleCallback(ctx context.Context, gatewayName string, query url.Values) (*domain.SubscriptionPayment, error) {
+	g, ok := s.gateways[gatewayName]
+	if !ok {
+		return nil, ErrUnknownGateway
+	}
+
+	callback, err := g.ParseCallback(query)
+	if err != nil {
+		return nil, err
+	}
+	payment, err := s.paymentRepo.GetByID(ctx, callback.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil || payment.Gateway != gatewayName {
+		return nil, ErrPaymentNotFound
+	}
+
+	// What the callback says is only a hint; the gateway is asked directly
+	return s.settle(ctx, payment)
+}
+
+func (s *checkoutService) Verify(ctx context.Context, tenantID, paymentID uuid.UUID) (*domain.SubscriptionPayment, error) {
+	payment, err := s.GetPayment(ctx, tenantID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.settle(ctx, payment)
+}
+
+func (s *checkoutService) GetPayment(ctx context.Context, tenantID, paymentID uuid.UUID) (*domain.SubscriptionPayment, error) {
+	payment, err := s.paymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil || payment.TenantID != tenantID {
+		return nil, ErrPaymentNotFound
+	}
+	return payment, nil
+}
+
+func (s *checkoutService) ListPayments(ctx context.Context, tenantID uuid.UUID) ([]*domain.SubscriptionPayment, error) {
+	return s.paymentRepo.ListByTenant(ctx, tenantID)
+}
+
+// settle asks the gateway how a pending payment stands and records the outcome. The
+// request that records a completed payment starts the plan; a completed payment
+// without a subscription (its activation failed) is activated again.
+func (s *checkoutService) settle(ctx context.Context, payment *domain.SubscriptionPayment) (*domain.SubscriptionPayment, error) {
+	if payment.Status == domain.PaymentCompleted {
+		if payment.SubscriptionID == nil {
+			return s.activate(ctx, payment)
+		}
+		return payment, nil
+	}
+	if payment.Status != domain.PaymentPending {
+		return payment, nil
+	}
+
+	g, ok := s.gateways[payment.Gateway]
+	if !ok {
+		return nil, ErrUnknownGateway
+	}
+	result, err := g.Verify(ctx, s.gatewayPayment(payment))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch result.Status {
+	case gateway.StatusPending:
+		return payment, nil
+	case gateway.StatusCompleted:
+		if result.TransactionID != "" {
+			payment.TransactionID = &result.TransactionID
+		}
+		if math.Abs(result.Amount-payment.Amount) >= 0.01 {
+			// Refunded by hand; the plan is not started for a different amount
+			reason := fmt.Sprintf("gateway reported %.2f paid, %.2f was due", result.Amount, payment.Amount)
+			payment.Status = domain.PaymentFailed
+			payment.FailureReason = &reason
+		} else {
+			payment.Status = domain.PaymentCompleted
+			payment.CompletedAt = &now
+		}
+	default:
+		reason := result.Detail
+		payment.Status = domain.PaymentFailed
+		payment.FailureReason = &reason
+	}
+	payment.UpdatedAt = now
+
+	settled, err := s.paymentRepo.Settle(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	if !settled {
+		// Another request recorded the outcome first and starts the plan
+		return s.paymentRepo.GetByID(ctx, payment.ID)
+	}
+
+	if payment.Status == domain.PaymentCompleted {
+		return s.activate(ctx, payment)
+	}
+	return payment, nil
+}
+
+// activate starts the paid plan and links it to the payment
+func (s *checkoutService) activate(ctx context.Context, payment *domain.SubscriptionPayment) (*domain.SubscriptionPayment, error) {
+	sub, err := s.subscriptions.Activate(ctx, payment.TenantID, payment.PlanID, payment.PaymentRef())
+	if err != nil {
+		return nil, fmt.Errorf("payment %s was confirmed but the plan could not be started: %w", payment.ID, err)
+	}
+
+	payment.SubscriptionID = &sub.ID
+	payment.UpdatedAt = time.Now()
+	if err := s.paymentRepo.Update(ctx, payment); err != nil {
+		log.Printf("Failed to link payment %s to subscription %s: %v", payment.ID, sub.ID, err)
+	}
+	return payment, nil
+}
+
+// fail records a payment that could not be started at the gateway
+func (s *checkoutService) fail(ctx context.Context, payment *domain.SubscriptionPayment, reason string) {
+	payment.Status = domain.PaymentFailed
+	payment.FailureReason = &reason
+	payment.UpdatedAt = time.Now()
+	if _, err := s.paymentRepo.Settle(ctx, payment); err != nil {
+		log.Printf("Failed to record failed payment %s: %v", payment.ID, err)
+	}
+}
+
+// gatewayPayment is the payment as sent to its gateway
+func (s *checkoutService) gatewayPayment(payment *domain.SubscriptionPayment) gateway.Payment {
+	request := gateway.Payment{
+		ID:          payment.ID,
+		Amount:      payment.Amount,
+		CallbackURL: s.callbackURL + "/" + payment.Gateway,
+	}
+	if payment.GatewayRef != nil {
+		request.Reference = *payment.GatewayRef
+	}
+	return request
+}
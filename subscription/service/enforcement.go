@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/cache"
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOverrideInPast      = errors.New("override must end in the future")
+	ErrOverrideReasonEmpty = errors.New("override reason is required")
+)
+
+// EnforcementService decides whether a tenant with a lapsed subscription is read-only
+type EnforcementService interface {
+	// AccessState returns the tenant's current access mode (cached briefly)
+	AccessState(ctx context.Context, tenantID uuid.UUID) (domain.AccessState, error)
+	// GrantOverride keeps an expired tenant fully usable until the given time (super admin)
+	GrantOverride(ctx context.Context, tenantID uuid.UUID, until time.Time, reason string, grantedBy *uuid.UUID) (*domain.AccessOverride, error)
+	// RevokeOverride removes the tenant's override
+	RevokeOverride(ctx context.Context, tenantID uuid.UUID) error
+	// Invalidate drops the cached state, e.g. after the tenant renews
+	Invalidate(tenantID uuid.UUID)
+}
+
+// accessStateTTL bounds how long a renewal takes to lift read-only mode when not invalidated explicitly
+const accessStateTTL = time.Minute
+
+type enforcementService struct {
+	subRepo      repository.SubscriptionRepository
+	overrideRepo repository.AccessOverrideRepository
+	grace        time.Duration
+
+	states *cache.TTLCache[uuid.UUID, domain.AccessState]
+}
+
+// NewEnforcementService creates the enforcement service; grace is how long an expired
+// tenant keeps full access before switching to read-only
+func NewEnforcementService(subRepo repository.SubscriptionRepository, overrideRepo repository.AccessOverrideRepository, grace time.Duration) EnforcementService {
+	return &enforcementService{
+		subRepo:      subRepo,
+		overrideRepo: overrideRepo,
+		grace:        grace,
+		states:       cache.New[uuid.UUID, domain.AccessState](accessStateTTL, 10000),
+	}
+}
+
+func (s *enforcementService) AccessState(ctx context.Context, tenantID uuid.UUID) (domain.AccessState, error) {
+	if state, ok := s.states.Get(tenantID); ok {
+		return state, nil
+	}
+
+	latest, err := s.subRepo.GetByTenantID(ctx, tenantID)
+	if err != nil {
+		return domain.AccessState{}, err
+	}
+
+	var override *domain.AccessOverride
+	if latest != nil && !time.Now().Before(latest.EndDate) {
+		if override, err = s.overrideRepo.Get(ctx, tenantID); err != nil {
+			return domain.AccessState{}, err
+		}
+	}
+
+	state := domain.ResolveAccessState(latest, override, s.grace, time.Now())
+	s.states.Set(tenantID, state)
+	return state, nil
+}
+
+func (s *enforcementService) GrantOverride(ctx context.Context, tenantID uuid.UUID, until time.Time, reason string, grantedBy *uuid.UUID) (*domain.AccessOverride, error) {
+	if !until.After(time.Now()) {
+		return nil, ErrOverrideInPast
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrOverrideReasonEmpty
+	}
+
+	override := &domain.AccessOverride{
+		TenantID:  tenantID,
+		Until:     until,
+		Reason:    reason,
+		GrantedBy: grantedBy,
+		CreatedAt: time.Now(),
+	}
+	if err := s.overrideRepo.Upsert(ctx, override); err != nil {
+		return nil, err
+	}
+
+	s.Invalidate(tenantID)
+	return override, nil
+}
+
+func (s *enforcementService) RevokeOverride(ctx context.Context, tenantID uuid.UUID) error {
+	if err := s.overrideRepo.Delete(ctx, tenantID); err != nil {
+		return err
+	}
+	s.Invalidate(tenantID)
+	return nil
+}
+
+func (s *enforcementService) Invalidate(tenantID uuid.UUID) {
+	s.states.Delete(tenantID)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type featureCacheKey struct{}
+
+// featureCache holds tenants' plan features for the life of one request, so every
+// feature check a request makes costs one lookup
+type featureCache struct {
+	mu       sync.Mutex
+	features map[uuid.UUID]map[string]bool
+}
+
+// WithFeatureCache returns a context in which plan features are loaded once per tenant.
+// It is meant for one request; changes to the plan are not seen through it.
+func WithFeatureCache(ctx context.Context) context.Context {
+	if featureCacheFrom(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, featureCacheKey{}, &featureCache{features: make(map[uuid.UUID]map[string]bool)})
+}
+
+// featureCacheFrom returns the context's cache, or nil
+func featureCacheFrom(ctx context.Context) *featureCache {
+	cache, _ := ctx.Value(featureCacheKey{}).(*featureCache)
+	return cache
+}
+
+func (c *featureCache) get(tenantID uuid.UUID) (map[string]bool, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	features, ok := c.features[tenantID]
+	return features, ok
+}
+
+func (c *featureCache) set(tenantID uuid.UUID, features map[string]bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.features[tenantID] = features
+	c.mu.Unlock()
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aceextension/core/pdf"
+	fiscalUtils "github.com/aceextension/fiscal/utils"
+	"github.com/aceextension/subscription/domain"
+)
+
+// RenderInvoicePDF lays out a subscription invoice as an A4 tax invoice, one page per
+// copy label (see InvoicePrint.Labels). Copies carry their label under the title and as
+// a watermark; without labels a single unmarked page is rendered, as attached to emails.
+// Paid invoices are titled as receipts and show the payment details.
+func RenderInvoicePDF(inv *domain.SubscriptionInvoice, labels ...string) []byte {
+	title := "TAX INVOICE"
+	if inv.IsPaid() {
+		title = "TAX INVOICE / RECEIPT"
+	}
+
+	doc := pdf.New(title + " " + inv.InvoiceNumber)
+	if len(labels) == 0 {
+		renderInvoicePage(doc.AddPage(), inv, title, "")
+	}
+	for _, label := range labels {
+		page := doc.AddPage()
+		if label != domain.OriginalLabel {
+			page.Watermark(label, 64)
+		}
+		renderInvoicePage(page, inv, title, label)
+	}
+
+	return doc.Bytes()
+}
+
+// renderInvoicePage draws one copy of the invoice
+func renderInvoicePage(page *pdf.Page, inv *domain.SubscriptionInvoice, title, label string) {
+	const left, right = 50.0, pdf.PageWidth - 50
+	y := 60.0
+
+	// Seller block
+	page.Text(left, y, 16, true, inv.Seller.Name)
+	y += 16
+	for _, line := range partyLines(inv.Seller) {
+		page.Text(left, y, 9, false, line)
+		y += 12
+	}
+	page.TextRight(right, 60, 14, true, title)
+	markY := 78.0
+	if inv.Status == domain.InvoiceStatusVoid {
+		page.TextRight(right, markY, 12, true, "VOID")
+		markY += 16
+	}
+	if label != "" {
+		page.TextRight(right, markY, 10, true, label)
+	}
+
+	y += 12
+	page.Line(left, y, right, y, 0.5)
+	y += 20
+
+	// Buyer and invoice details side by side
+	top := y
+	page.Text(left, y, 9, true, "BILL TO")
+	y += 14
+	page.Text(left, y, 10, true, inv.Buyer.Name)
+	y += 13
+	for _, line := range partyLines(inv.Buyer) {
+		page.Text(left, y, 9, false, line)
+		y += 12
+	}
+
+	meta := [][2]string{
+		{"Invoice No.", inv.InvoiceNumber},
+		{"Invoice Date", fiscalUtils.FormatDualDate(inv.IssueDate, fiscalUtils.DefaultDateDisplay)},
+		{"Service Period", inv.PeriodStart.Format("2006-01-02") + " to " + inv.PeriodEnd.Format("2006-01-02")},
+		{"Period (BS)", fiscalUtils.BSDate(inv.PeriodStart) + " to " + fiscalUtils.BSDate(inv.PeriodEnd)},
+		{"Status", string(inv.Status)},
+	}
+	my := top
+	for _, m := range meta {
+		page.Text(330, my, 9, true, m[0])
+		page.TextRight(right, my, 9, false, m[1])
+		my += 14
+	}
+	if my > y {
+		y = my
+	}
+	y += 20
+
+	// Line items
+	page.FillRect(left, y-12, right-left, 18, 0.9)
+	page.Text(left+6, y, 9, true, "Description")
+	page.TextRight(right-170, y, 9, true, "Qty")
+	page.TextRight(right-90, y, 9, true, "Rate")
+	page.TextRight(right-6, y, 9, true, "Amount")
+	y += 22
+
+	page.Text(left+6, y, 10, false, inv.Description)
+	page.TextRight(right-170, y, 10, false, "1")
+	page.TextRight(right-90, y, 10, false, formatMoney(inv.Subtotal))
+	page.TextRight(right-6, y, 10, false, formatMoney(inv.Subtotal))
+	y += 16
+	page.Line(left, y, right, y, 0.5)
+	y += 20
+
+	// Totals
+	totals := [][2]string{
+		{"Taxable Amount", formatMoney(inv.Subtotal)},
+		{fmt.Sprintf("VAT (%s%%)", trimZeros(inv.VATRate)), formatMoney(inv.VATAmount)},
+	}
+	for _, t := range totals {
+		page.Text(330, y, 10, false, t[0])
+		page.TextRight(right-6, y, 10, false, t[1])
+		y += 16
+	}
+	page.Line(330, y-8, right, y-8, 0.5)
+	y += 6
+	page.Text(330, y, 11, true, "Total ("+inv.Currency+")")
+	page.TextRight(right-6, y, 11, true, formatMoney(inv.Total))
+	y += 36
+
+	// Receipt details
+	if inv.IsPaid() && inv.PaidAt != nil {
+		page.Text(left, y, 10, true, "PAYMENT RECEIVED")
+		y += 14
+		received := "Received " + inv.Currency + " " + formatMoney(inv.Total) + " on " + fiscalUtils.FormatDualDate(*inv.PaidAt, fiscalUtils.DefaultDateDisplay)
+		if inv.PaymentRef != nil {
+			received += " (ref. " + *inv.PaymentRef + ")"
+		}
+		page.Text(left, y, 9, false, received)
+	}
+
+	page.Text(left, pdf.PageHeight-50, 8, false, "This is a computer-generated invoice and does not require a signature.")
+}
+
+// partyLines returns the address and registration lines of a party
+func partyLines(p domain.Party) []string {
+	var lines []string
+	if p.Address != nil && *p.Address != "" {
+		lines = append(lines, *p.Address)
+	}
+	if p.VATNumber != nil && *p.VATNumber != "" {
+		lines = append(lines, "PAN/VAT No: "+*p.VATNumber)
+	}
+	if p.Email != nil && *p.Email != "" {
+		lines = append(lines, *p.Email)
+	}
+	return lines
+}
+
+// formatMoney formats an amount with thousands separators: 12,345.00
+func formatMoney(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	intPart, frac := s[:len(s)-3], s[len(s)-3:]
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+
+	out := b.String() + frac
+	if neg {
+		out = "-" + out
+	}
+	return out
+}
+
+func trimZeros(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
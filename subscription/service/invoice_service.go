@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvoiceNotFound    = errors.New("invoice not found")
+	ErrInvoiceVoid        = errors.New("invoice has been voided")
+	ErrNoBillingRecipient = errors.New("tenant has no billing email address")
+	ErrInvalidPrintCopies = fmt.Errorf("copies must be between 1 and %d", domain.MaxPrintCopies)
+)
+
+// PrintRequest is a print or download of an invoice by a tenant user
+type PrintRequest struct {
+	TenantID  uuid.UUID
+	InvoiceID uuid.UUID
+	Kind      domain.PrintKind
+	Copies    int
+	Reason    string // Optional, e.g. "customer lost original"
+	UserID    *uuid.UUID
+	IPAddress string
+}
+
+// BillingDetailsProvider supplies the tenant's legal details printed as the invoice buyer
+type BillingDetailsProvider interface {
+	BillingParty(ctx context.Context, tenantID uuid.UUID) (domain.Party, error)
+}
+
+// InvoiceMailer delivers a rendered invoice to the tenant's billing address
+type InvoiceMailer interface {
+	SendInvoice(ctx context.Context, invoice *domain.SubscriptionInvoice, pdf []byte) error
+}
+
+// InvoiceService issues tax invoices and receipts for tenant subscriptions
+type InvoiceService interface {
+	// IssueForSubscription creates the invoice for a paid subscription period and emails it;
+	// with a paymentRef it is issued paid, as a receipt. Free plans get no invoice (returns
+	// nil, nil); issuing twice returns the existing invoice.
+	IssueForSubscription(ctx context.Context, sub *domain.Subscription, plan *domain.Plan, paymentRef string) (*domain.SubscriptionInvoice, error)
+	ListInvoices(ctx context.Context, tenantID uuid.UUID) ([]*domain.SubscriptionInvoice, error)
+	GetInvoice(ctx context.Context, tenantID, id uuid.UUID) (*domain.SubscriptionInvoice, error)
+	// PrintPDF logs a print or download and returns the invoice as a PDF with one page per
+	// copy: the first page ever output is the original, later pages are marked "COPY n OF m".
+	// Paid invoices render as a receipt.
+	PrintPDF(ctx context.Context, req PrintRequest) (*domain.SubscriptionInvoice, *domain.InvoicePrint, []byte, error)
+	// ListPrints returns the invoice's print and reprint log
+	ListPrints(ctx context.Context, tenantID, id uuid.UUID) ([]*domain.InvoicePrint, error)
+	// MarkPaid records a payment, turning the invoice into a receipt, and re-sends it
+	MarkPaid(ctx context.Context, tenantID, id uuid.UUID, paymentRef string) (*domain.SubscriptionInvoice, error)
+	// SendEmail (re-)delivers the invoice PDF to the tenant
+	SendEmail(ctx context.Context, tenantID, id uuid.UUID) error
+
+	SetBillingDetailsProvider(provider BillingDetailsProvider)
+	SetMailer(mailer InvoiceMailer)
+}
+
+type invoiceService struct {
+	invoiceRepo repository.InvoiceRepository
+	seller      domain.Party
+	vatRate     float64
+
+	billing BillingDetailsProvider
+	mailer  InvoiceMailer
+}
+
+// NewInvoiceService creates an invoice service; seller and vatRate are the platform's own VAT details
+func NewInvoiceService(invoiceRepo repository.InvoiceRepository, seller domain.Party, vatRate float64) InvoiceService {
+	return &invoiceService{
+		invoiceRepo: invoiceRepo,
+		seller:      seller,
+		vatRate:     vatRate,
+	}
+}
+
+func (s *invoiceService) SetBillingDetailsProvider(provider BillingDetailsProvider) {
+	s.billing = provider
+}
+
+func (s *invoiceService) SetMailer(mailer InvoiceMailer) {
+	s.mailer = mailer
+}
+
+func (s *invoiceService) IssueForSubscription(ctx context.Context, sub *domain.Subscription, plan *domain.Plan, paymentRef string) (*domain.SubscriptionInvoice, error) {
+	if plan.Price <= 0 {
+		return nil, nil
+	}
+
+	existing, err := s.invoiceRepo.GetBySubscriptionID(ctx, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if paymentRef != "" && !existing.IsPaid() {
+			return s.MarkPaid(ctx, sub.TenantID, existing.ID, paymentRef)
+		}
+		return existing, nil
+	}
+
+	buyer := domain.Party{Name: sub.TenantID.String()}
+	if s.billing != nil {
+		party, err := s.billing.BillingParty(ctx, sub.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tenant billing details: %w", err)
+		}
+		buyer = party
+	}
+
+	invoice := domain.NewSubscriptionInvoice(sub, plan, s.seller, buyer, s.vatRate)
+	if paymentRef != "" {
+		invoice.MarkPaid(paymentRef, invoice.IssueDate)
+	}
+	if err := s.invoiceRepo.Create(ctx, invoice); err != nil {
+		return nil, err
+	}
+
+	// Delivery must not block or fail the subscription change
+	delivery := *invoice
+	go func() {
+		if err := s.deliver(context.Background(), &delivery); err != nil {
+			log.Printf("Failed to email subscription invoice %s: %v", delivery.InvoiceNumber, err)
+		}
+	}()
+
+	return invoice, nil
+}
+
+func (s *invoiceService) ListInvoices(ctx context.Context, tenantID uuid.UUID) ([]*domain.SubscriptionInvoice, error) {
+	return s.invoiceRepo.ListByTenant(ctx, tenantID)
+}
+
+func (s *invoiceService) GetInvoice(ctx context.Context, tenantID, id uuid.UUID) (*domain.SubscriptionInvoice, error) {
+	invoice, err := s.invoiceRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+func (s *invoiceService) PrintPDF(ctx context.Context, req PrintRequest) (*domain.SubscriptionInvoice, *domain.InvoicePrint, []byte, error) {
+	if req.Copies < 1 || req.Copies > domain.MaxPrintCopies {
+		return nil, nil, nil, ErrInvalidPrintCopies
+	}
+	invoice, err := s.GetInvoice(ctx, req.TenantID, req.InvoiceID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	record := domain.NewInvoicePrint(req.TenantID, invoice.ID, req.Kind, req.Copies, req.UserID)
+	if req.Reason != "" {
+		record.Reason = &req.Reason
+	}
+	if req.IPAddress != "" {
+		record.IPAddress = &req.IPAddress
+	}
+
+	// Numbers are reserved before rendering, so a failed render leaves a gap rather than
+	// handing out the same copy number twice
+	if err := s.invoiceRepo.RecordPrint(ctx, record); err != nil {
+		return nil, nil, nil, err
+	}
+	invoice.PrintCount = record.LastCopy() + 1
+
+	return invoice, record, RenderInvoicePDF(invoice, record.Labels()...), nil
+}
+
+func (s *invoiceService) ListPrints(ctx context.Context, tenantID, id uuid.UUID) ([]*domain.InvoicePrint, error) {
+	if _, err := s.GetInvoice(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+	return s.invoiceRepo.ListPrints(ctx, tenantID, id)
+}
+
+func (s *invoiceService) MarkPaid(ctx context.Context, tenantID, id uuid.UUID, paymentRef string) (*domain.SubscriptionInvoice, error) {
+	invoice, err := s.GetInvoice(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == domain.InvoiceStatusVoid {
+		return nil, ErrInvoiceVoid
+	}
+	if invoice.IsPaid() {
+		return invoice, nil
+	}
+
+	invoice.MarkPaid(paymentRef, time.Now())
+	if err := s.invoiceRepo.Update(ctx, invoice); err != nil {
+		return nil, err
+	}
+
+	delivery := *invoice
+	go func() {
+		if err := s.deliver(context.Background(), &delivery); err != nil {
+			log.Printf("Failed to email subscription receipt %s: %v", delivery.InvoiceNumber, err)
+		}
+	}()
+
+	return invoice, nil
+}
+
+func (s *invoiceService) SendEmail(ctx context.Context, tenantID, id uuid.UUID) error {
+	invoice, err := s.GetInvoice(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	return s.deliver(ctx, invoice)
+}
+
+// deliver renders and emails the invoice, then records the delivery time
+func (s *invoiceService) deliver(ctx context.Context, invoice *domain.SubscriptionInvoice) error {
+	if s.mailer == nil {
+		return nil
+	}
+	if invoice.Buyer.Email == nil || *invoice.Buyer.Email == "" {
+		return ErrNoBillingRecipient
+	}
+
+	if err := s.mailer.SendInvoice(ctx, invoice, RenderInvoicePDF(invoice)); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	invoice.EmailedAt = &now
+	invoice.UpdatedAt = now
+	return s.invoiceRepo.Update(ctx, invoice)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/repository"
+	"github.com/google/uuid"
+)
+
+// ErrLimitExceeded is returned when creating one more would take the tenant past a plan limit
+var ErrLimitExceeded = errors.New("plan limit reached; upgrade your plan to add more")
+
+// LimitChecker enforces plan limits on creations. Unlike the usage meter it blocks:
+// usage is counted fresh on every check, so a tenant at the limit cannot create more.
+// Limits missing from the plan, or that nothing meters, are not enforced.
+type LimitChecker interface {
+	// Check returns the tenant's usage of a limit, or nil when it is not enforced, and
+	// ErrLimitExceeded when the tenant cannot create one more
+	Check(ctx context.Context, tenantID uuid.UUID, limitKey string) (*domain.UsageStatus, error)
+	// Usage reports consumption against every metered limit of the tenant's plan, or nil
+	// when the tenant has never subscribed
+	Usage(ctx context.Context, tenantID uuid.UUID) (*domain.PlanUsage, error)
+}
+
+type limitChecker struct {
+	subRepo  repository.SubscriptionRepository
+	metering MeteringService
+}
+
+// NewLimitChecker creates the limit checker; usage comes from the meter's counters and gauges
+func NewLimitChecker(subRepo repository.SubscriptionRepository, metering MeteringService) LimitChecker {
+	return &limitChecker{subRepo: subRepo, metering: metering}
+}
+
+func (s *limitChecker) Check(ctx context.Context, tenantID uuid.UUID, limitKey string) (*domain.UsageStatus, error) {
+	plan, err := s.plan(ctx, tenantID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+	limit, ok := plan.Limits[limitKey]
+	if !ok || limit < 0 {
+		return nil, nil
+	}
+
+	used, metered, err := s.metering.Measure(ctx, tenantID, limitKey)
+	if err != nil || !metered {
+		return nil, err
+	}
+	status := domain.NewUsageStatus(limitKey, used, limit)
+	if status.IsExceeded() {
+		return &status, ErrLimitExceeded
+	}
+	return &status, nil
+}
+
+func (s *limitChecker) Usage(ctx context.Context, tenantID uuid.UUID) (*domain.PlanUsage, error) {
+	plan, err := s.plan(ctx, tenantID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+
+	usage := &domain.PlanUsage{PlanID: plan.ID, PlanCode: plan.Code, PlanName: plan.Name, Limits: []domain.UsageStatus{}}
+	for limitKey, limit := range plan.Limits {
+		used, metered, err := s.metering.Measure(ctx, tenantID, limitKey)
+		if err != nil {
+			return nil, err
+		}
+		if metered {
+			usage.Limits = append(usage.Limits, domain.NewUsageStatus(limitKey, used, limit))
+		}
+	}
+	sort.Slice(usage.Limits, func(i, j int) bool { return usage.Limits[i].LimitKey < usage.Limits[j].LimitKey })
+	return usage, nil
+}
+
+// plan returns the plan of the tenant's latest subscription, so its limits still apply
+// through the grace period after it ends; nil when the tenant has never subscribed
+func (s *limitChecker) plan(ctx context.Context, tenantID uuid.UUID) (*domain.Plan, error) {
+	sub, err := s.subRepo.GetByTenantID(ctx, tenantID)
+	if err != nil || sub == nil {
+		return nil, err
+	}
+	return sub.Plan, nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aceextension/core/cache"
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/repository"
+	"github.com/google/uuid"
+)
+
+// GaugeFunc returns the current value of a point-in-time metric (e.g. product count) for a tenant
+type GaugeFunc func(ctx context.Context, tenantID uuid.UUID) (int64, error)
+
+// MeteringService tracks tenant usage against plan limits.
+// Limits are soft: the meter reports usage, it never blocks a request.
+type MeteringService interface {
+	// RecordAPICall counts one API call in memory; counts are persisted by Flush
+	RecordAPICall(tenantID uuid.UUID)
+	// RecordAPIRequest counts a finished request in its daily rollup, in memory; rollups are persisted by Flush
+	RecordAPIRequest(bucket domain.APIUsageBucket, status int)
+	// Flush persists buffered API call counts and rollups
+	Flush(ctx context.Context) error
+	// RegisterGauge attaches a counter for a plan limit key owned by another module
+	RegisterGauge(limitKey string, fn GaugeFunc)
+	// Measure returns the tenant's current usage for a limit key, read fresh rather than
+	// from the snapshot; metered is false when nothing counts the key
+	Measure(ctx context.Context, tenantID uuid.UUID, limitKey string) (used int64, metered bool, err error)
+	// GetUsage returns usage for every metered limit defined on the tenant's plan
+	GetUsage(ctx context.Context, tenantID uuid.UUID) ([]domain.UsageStatus, error)
+	// Invalidate drops the cached usage snapshot for a tenant
+	Invalidate(tenantID uuid.UUID)
+	// APIUsage reports the tenant's API traffic on the days from from up to, but not
+	// including, to, with the limit busiest endpoints. Requests since the last flush are not included.
+	APIUsage(ctx context.Context, tenantID uuid.UUID, from, to time.Time, limit int) (*domain.APIUsageReport, error)
+}
+
+// usageCacheTTL bounds how stale headers can be; counting on every request would be too costly
+const usageCacheTTL = time.Minute
+
+type pendingKey struct {
+	tenantID    uuid.UUID
+	periodStart time.Time
+}
+
+type meteringService struct {
+	subRepo      repository.SubscriptionRepository
+	usageRepo    repository.UsageRepository
+	apiUsageRepo repository.APIUsageRepository
+
+	mu      sync.Mutex
+	pending map[pendingKey]int64
+	rollups map[domain.APIUsageBucket]domain.APIUsageCounts
+
+	gaugesMu sync.RWMutex
+	gauges   map[string]GaugeFunc
+
+	snapshots *cache.TTLCache[uuid.UUID, []domain.UsageStatus]
+}
+
+func NewMeteringService(subRepo repository.SubscriptionRepository, usageRepo repository.UsageRepository, apiUsageRepo repository.APIUsageRepository) MeteringService {
+	return &meteringService{
+		subRepo:      subRepo,
+		usageRepo:    usageRepo,
+		apiUsageRepo: apiUsageRepo,
+		pending:      make(map[pendingKey]int64),
+		rollups:      make(map[domain.APIUsageBucket]domain.APIUsageCounts),
+		gauges:       make(map[string]GaugeFunc),
+		snapshots:    cache.New[uuid.UUID, []domain.UsageStatus](usageCacheTTL, 10000),
+	}
+}
+
+// CountGauge builds a gauge from a COUNT query taking the tenant ID as $1
+func CountGauge(pool db.QueryExecutor, query string) GaugeFunc {
+	return func(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+		var count int64
+		err := pool.QueryRow(ctx, query, tenantID).Scan(&count)
+		return count, err
+	}
+}
+
+func (s *meteringService) RecordAPICall(tenantID uuid.UUID) {
+	key := pendingKey{tenantID: tenantID, periodStart: domain.MonthStart(time.Now())}
+	s.mu.Lock()
+	s.pending[key]++
+	s.mu.Unlock()
+}
+
+func (s *meteringService) RecordAPIRequest(bucket domain.APIUsageBucket, status int) {
+	s.mu.Lock()
+	counts := s.rollups[bucket]
+	counts.Add(status)
+	s.rollups[bucket] = counts
+	s.mu.Unlock()
+}
+
+func (s *meteringService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = make(map[pendingKey]int64)
+	rollups := s.rollups
+	s.rollups = make(map[domain.APIUsageBucket]domain.APIUsageCounts)
+	s.mu.Unlock()
+
+	var firstErr error
+	for key, delta := range batch {
+		if err := s.usageRepo.Increment(ctx, key.tenantID, domain.LimitKeyAPICallsPerMonth, key.periodStart, delta); err != nil {
+			// Put the count back so the next flush retries it
+			s.mu.Lock()
+			s.pending[key] += delta
+			s.mu.Unlock()
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	for bucket, counts := range rollups {
+		if err := s.apiUsageRepo.Increment(ctx, bucket, counts); err != nil {
+			s.mu.Lock()
+			pending := s.rollups[bucket]
+			pending.Merge(counts)
+			s.rollups[bucket] = pending
+			s.mu.Unlock()
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (s *meteringService) RegisterGauge(limitKey string, fn GaugeFunc) {
+	s.gaugesMu.Lock()
+	s.gauges[limitKey] = fn
+	s.gaugesMu.Unlock()
+}
+
+func (s *meteringService) Measure(ctx context.Context, tenantID uuid.UUID, limitKey string) (int64, bool, error) {
+	if limitKey == domain.LimitKeyAPICallsPerMonth {
+		periodStart := domain.MonthStart(time.Now())
+		used, err := s.usageRepo.Get(ctx, tenantID, limitKey, periodStart)
+		if err != nil {
+			return 0, true, err
+		}
+		s.mu.Lock()
+		used += s.pending[pendingKey{tenantID: tenantID, periodStart: periodStart}]
+		s.mu.Unlock()
+		return used, true, nil
+	}
+
+	s.gaugesMu.RLock()
+	gauge, ok := s.gauges[limitKey]
+	s.gaugesMu.RUnlock()
+	if !ok {
+		return 0, false, nil
+	}
+	used, err := gauge(ctx, tenantID)
+	return used, true, err
+}
+
+func (s *meteringService) GetUsage(ctx context.Context, tenantID uuid.UUID) ([]domain.UsageStatus, error) {
+	snapshot, ok := s.snapshots.Get(tenantID)
+	if !ok {
+		var err error
+		snapshot, err = s.loadUsage(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		s.snapshots.Set(tenantID, snapshot)
+	}
+
+	// Add calls not yet flushed so API usage moves between snapshot refreshes
+	s.mu.Lock()
+	unflushed := s.pending[pendingKey{tenantID: tenantID, periodStart: domain.MonthStart(time.Now())}]
+	s.mu.Unlock()
+
+	statuses := make([]domain.UsageStatus, len(snapshot))
+	for i, status := range snapshot {
+		if status.LimitKey == domain.LimitKeyAPICallsPerMonth && unflushed > 0 {
+			status = domain.NewUsageStatus(status.LimitKey, status.Used+unflushed, status.Limit)
+		}
+		statuses[i] = status
+	}
+	return statuses, nil
+}
+
+func (s *meteringService) Invalidate(tenantID uuid.UUID) {
+	s.snapshots.Delete(tenantID)
+}
+
+func (s *meteringService) APIUsage(ctx context.Context, tenantID uuid.UUID, from, to time.Time, limit int) (*domain.APIUsageReport, error) {
+	report := &domain.APIUsageReport{From: from, To: to}
+
+	var err error
+	if report.Daily, err = s.apiUsageRepo.Daily(ctx, tenantID, from, to); err != nil {
+		return nil, err
+	}
+	if report.ByAPIKey, err = s.apiUsageRepo.ByAPIKey(ctx, tenantID, from, to); err != nil {
+		return nil, err
+	}
+	if report.ByUser, err = s.apiUsageRepo.ByUser(ctx, tenantID, from, to); err != nil {
+		return nil, err
+	}
+	if report.TopEndpoints, err = s.apiUsageRepo.TopEndpoints(ctx, tenantID, from, to, limit); err != nil {
+		return nil, err
+	}
+
+	var totals domain.APIUsageCounts
+	for _, day := range report.Daily {
+		totals.Merge(day.APIUsageCounts)
+	}
+	report.Totals = domain.NewAPIUsageStats(totals)
+	return report, nil
+}
+
+func (s *meteringService) loadUsage(ctx context.Context, tenantID uuid.UUID) ([]domain.UsageStatus, error) {
+	sub, err := s.subRepo.GetActiveByTenantID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil || sub.Plan == nil {
+		return []domain.UsageStatus{}, nil
+	}
+
+	statuses := []domain.UsageStatus{}
+
+	// Limits missing from the plan are not metered
+	if limit, ok := sub.Plan.Limits[domain.LimitKeyAPICallsPerMonth]; ok {
+		used, err := s.usageRepo.Get(ctx, tenantID, domain.LimitKeyAPICallsPerMonth, domain.MonthStart(time.Now()))
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, domain.NewUsageStatus(domain.LimitKeyAPICallsPerMonth, used, limit))
+	}
+
+	s.gaugesMu.RLock()
+	defer s.gaugesMu.RUnlock()
+	for limitKey, gauge := range s.gauges {
+		limit, ok := sub.Plan.Limits[limitKey]
+		if !ok {
+			continue
+		}
+		used, err := gauge(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, domain.NewUsageStatus(limitKey, used, limit))
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].LimitKey < statuses[j].LimitKey })
+	return statuses, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/repository"
+	"github.com/google/uuid"
+)
+
+// ExpiryNotifier tells the tenant their subscription is ending, was renewed or has expired.
+// graceEnds is when access turns read-only if the subscription is not renewed.
+type ExpiryNotifier interface {
+	SendExpiryNotice(ctx context.Context, sub *domain.Subscription, notice domain.ExpiryNotice, graceEnds time.Time) error
+}
+
+// RenewalService renews, warns about and expires subscriptions as their periods end
+type RenewalService interface {
+	// ProcessDue renews free auto-renew subscriptions, warns tenants whose subscriptions are
+	// about to end, moves ended paid ones to PAST_DUE and expires those past the grace period
+	ProcessDue(ctx context.Context) error
+	// OnChange registers a callback run after a tenant's subscription changes
+	OnChange(fn func(tenantID uuid.UUID))
+
+	SetExpiryNotifier(notifier ExpiryNotifier)
+}
+
+type renewalService struct {
+	subRepo         repository.SubscriptionRepository
+	entitlementRepo repository.EntitlementRepository
+	subscriptions   SubscriptionService
+	warnBefore      time.Duration
+	grace           time.Duration
+
+	notifier  ExpiryNotifier
+	listeners []func(tenantID uuid.UUID)
+}
+
+// NewRenewalService creates the renewal service; warnBefore is how long before the end of a
+// period the tenant is warned, grace how long after it they keep access before it expires
+func NewRenewalService(
+	subRepo repository.SubscriptionRepository,
+	entitlementRepo repository.EntitlementRepository,
+	subscriptions SubscriptionService,
+	warnBefore, grace time.Duration,
+) RenewalService {
+	return &renewalService{
+		subRepo:         subRepo,
+		entitlementRepo: entitlementRepo,
+		subscriptions:   subscriptions,
+		warnBefore:      warnBefore,
+		grace:           grace,
+	}
+}
+
+func (s *renewalService) SetExpiryNotifier(notifier ExpiryNotifier) {
+	s.notifier = notifier
+}
+
+func (s *renewalService) OnChange(fn func(tenantID uuid.UUID)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+func (s *renewalService) notifyChange(tenantID uuid.UUID) {
+	for _, fn := range s.listeners {
+		fn(tenantID)
+	}
+}
+
+func (s *renewalService) ProcessDue(ctx context.Context) error {
+	subs, err := s.subRepo.FindExpiringSubscriptions(ctx, s.warnBefore)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, sub := range subs {
+		graceEnds := sub.EndDate.Add(s.grace)
+
+		switch {
+		case now.Before(sub.EndDate):
+			// Cancelled subscriptions (auto-renew off) get their own notices from the cancellation flow
+			if sub.AutoRenew && !sub.RenewsItself() && sub.ExpiryWarnedAt == nil {
+				if err := s.subRepo.MarkExpiryWarned(ctx, sub.ID, now); err != nil {
+					log.Printf("Failed to record expiry warning for tenant %s: %v", sub.TenantID, err)
+					continue
+				}
+				s.sendNotice(ctx, sub, domain.ExpiryNoticeUpcoming, graceEnds)
+			}
+		case sub.RenewsItself():
+			if err := s.renew(ctx, sub); err != nil {
+				log.Printf("Failed to renew subscription for tenant %s: %v", sub.TenantID, err)
+			}
+		case !now.Before(graceEnds):
+			if err := s.expire(ctx, sub, graceEnds, now); err != nil {
+				log.Printf("Failed to expire subscription for tenant %s: %v", sub.TenantID, err)
+			}
+		case sub.AutoRenew && sub.Status == domain.SubscriptionStatusActive:
+			sub.Status = domain.SubscriptionStatusPastDue
+			sub.UpdatedAt = now
+			if err := s.subRepo.Update(ctx, sub); err != nil {
+				log.Printf("Failed to mark subscription past due for tenant %s: %v", sub.TenantID, err)
+				continue
+			}
+			s.notifyChange(sub.TenantID)
+			s.sendNotice(ctx, sub, domain.ExpiryNoticePaymentDue, graceEnds)
+		}
+	}
+	return nil
+}
+
+// renew starts the next period of a free plan
+func (s *renewalService) renew(ctx context.Context, sub *domain.Subscription) error {
+	next, err := s.subscriptions.Renew(ctx, sub)
+	if err != nil {
+		return err
+	}
+	s.sendNotice(ctx, next, domain.ExpiryNoticeRenewed, next.EndDate.Add(s.grace))
+	return nil
+}
+
+// expire ends a subscription the tenant did not renew within the grace period
+func (s *renewalService) expire(ctx context.Context, sub *domain.Subscription, graceEnds, now time.Time) error {
+	sub.Status = domain.SubscriptionStatusExpired
+	sub.UpdatedAt = now
+	if err := s.subRepo.Update(ctx, sub); err != nil {
+		return err
+	}
+
+	// Access stays full through the grace period; after it the tenant is down to the
+	// Free plan's features and limits, or to none when there is no Free plan
+	ended := *sub.Plan
+	ended.Features = nil
+	ended.Limits = nil
+	free, err := s.subscriptions.FreePlan(ctx)
+	if err != nil {
+		log.Printf("Failed to get the free plan for tenant %s's expiry: %v", sub.TenantID, err)
+	} else if free != nil {
+		ended = *free
+	}
+	recordEntitlementSnapshot(ctx, s.entitlementRepo, sub, &ended, domain.EntitlementExpired, graceEnds)
+	s.notifyChange(sub.TenantID)
+
+	s.sendNotice(ctx, sub, domain.ExpiryNoticeExpired, graceEnds)
+	return nil
+}
+
+// sendNotice delivers an expiry notice; failures are logged and never block renewal or expiry
+func (s *renewalService) sendNotice(ctx context.Context, sub *domain.Subscription, notice domain.ExpiryNotice, graceEnds time.Time) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.SendExpiryNotice(ctx, sub, notice, graceEnds); err != nil {
+		log.Printf("Failed to send %s expiry notice to tenant %s: %v", notice, sub.TenantID, err)
+	}
+}
@@ -3,13 +3,20 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
 	"time"
 
+	"github.com/aceextension/core/db"
 	"github.com/aceextension/subscription/domain"
 	"github.com/aceextension/subscription/repository"
 	"github.com/google/uuid"
 )
 
+var (
+	ErrPlanNotActive   = errors.New("plan is not active")
+	ErrPaymentRequired = errors.New("paid plans start once their payment is confirmed; pay through checkout")
+)
+
 // SubscriptionService defines the interface
 type SubscriptionService interface {
 	// Plan Management
@@ -19,23 +26,68 @@ type SubscriptionService interface {
 	UpdatePlan(ctx context.Context, plan *domain.Plan) error
 
 	// Subscription Management
+	// Subscribe starts a free plan. Paid plans return ErrPaymentRequired: they are
+	// paid through CheckoutService, which activates them once the payment is confirmed.
 	Subscribe(ctx context.Context, tenantID, planID uuid.UUID) (*domain.Subscription, error)
+	// Activate starts the plan for the tenant; paymentRef, when set, is the confirmed
+	// payment the period's invoice is issued as paid against
+	Activate(ctx context.Context, tenantID, planID uuid.UUID, paymentRef string) (*domain.Subscription, error)
+	// Renew starts the next period of the subscription's plan where the current one ends
+	Renew(ctx context.Context, sub *domain.Subscription) (*domain.Subscription, error)
 	GetSubscription(ctx context.Context, tenantID uuid.UUID) (*domain.Subscription, error)
+	// OnChange registers a callback run after a tenant's subscription changes (e.g. to drop caches)
+	OnChange(fn func(tenantID uuid.UUID))
+
+	// Entitlement History
+	GetEntitlementHistory(ctx context.Context, tenantID uuid.UUID) ([]*domain.EntitlementSnapshot, error)
+	// GetEntitlementsAt returns what the tenant was entitled to at a point in time, or nil
+	GetEntitlementsAt(ctx context.Context, tenantID uuid.UUID, at time.Time) (*domain.EntitlementSnapshot, error)
 
 	// Feature Gating
+	// Features returns the features of the tenant's plan. The latest subscription's plan
+	// counts while it is active or awaiting payment, through the grace period after it ends;
+	// otherwise, and for tenants that never subscribed, the Free plan's. Within a context
+	// from WithFeatureCache they are loaded once per tenant.
+	Features(ctx context.Context, tenantID uuid.UUID) (map[string]bool, error)
+	// FreePlan returns the plan tenants without a current subscription fall back to, nil
+	// when it does not exist
+	FreePlan(ctx context.Context) (*domain.Plan, error)
+	// HasFeature reports whether the tenant's plan includes the feature; features the plan
+	// does not list are off
 	HasFeature(ctx context.Context, tenantID uuid.UUID, feature string) (bool, error)
 	CheckLimit(ctx context.Context, tenantID uuid.UUID, limitKey string, currentValue int) (bool, error)
+	// CheckLimitAt checks a limit against the entitlements in effect at a past time (e.g. backdated records)
+	CheckLimitAt(ctx context.Context, tenantID uuid.UUID, limitKey string, currentValue int, at time.Time) (bool, error)
 }
 
 type subscriptionService struct {
-	planRepo repository.PlanRepository
-	subRepo  repository.SubscriptionRepository
+	planRepo        repository.PlanRepository
+	subRepo         repository.SubscriptionRepository
+	entitlementRepo repository.EntitlementRepository
+	invoices        InvoiceService
+	grace           time.Duration
+	freePlanCode    string
+
+	listeners []func(tenantID uuid.UUID)
 }
 
-func NewSubscriptionService(planRepo repository.PlanRepository, subRepo repository.SubscriptionRepository) SubscriptionService {
+// NewSubscriptionService creates the subscription service; grace is how long after a period
+// ends its plan still applies, freePlanCode the plan tenants fall back to after that
+func NewSubscriptionService(
+	planRepo repository.PlanRepository,
+	subRepo repository.SubscriptionRepository,
+	entitlementRepo repository.EntitlementRepository,
+	invoices InvoiceService,
+	grace time.Duration,
+	freePlanCode string,
+) SubscriptionService {
 	return &subscriptionService{
-		planRepo: planRepo,
-		subRepo:  subRepo,
+		planRepo:        planRepo,
+		subRepo:         subRepo,
+		entitlementRepo: entitlementRepo,
+		invoices:        invoices,
+		grace:           grace,
+		freePlanCode:    freePlanCode,
 	}
 }
 
@@ -59,31 +111,91 @@ func (s *subscriptionService) ListPlans(ctx context.Context) ([]*domain.Plan, er
 }
 
 func (s *subscriptionService) UpdatePlan(ctx context.Context, plan *domain.Plan) error {
-	return s.planRepo.Update(ctx, plan)
+	if err := s.planRepo.Update(ctx, plan); err != nil {
+		return err
+	}
+
+	// Subscribers' entitlements change with the plan, so each gets a new snapshot
+	subs, err := s.subRepo.ListActiveByPlanID(ctx, plan.ID)
+	if err != nil {
+		log.Printf("Failed to list subscribers of plan %s for entitlement snapshots: %v", plan.Code, err)
+		return nil
+	}
+	now := time.Now()
+	for _, sub := range subs {
+		s.recordEntitlements(ctx, sub, plan, domain.EntitlementPlanUpdated, now)
+		s.notifyChange(sub.TenantID)
+	}
+	return nil
 }
 
 // Subscription Implementation
 
 func (s *subscriptionService) Subscribe(ctx context.Context, tenantID, planID uuid.UUID) (*domain.Subscription, error) {
+	plan, err := s.activePlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Price > 0 {
+		return nil, ErrPaymentRequired
+	}
+	return s.activate(ctx, tenantID, plan, "", time.Now())
+}
+
+func (s *subscriptionService) Activate(ctx context.Context, tenantID, planID uuid.UUID, paymentRef string) (*domain.Subscription, error) {
+	plan, err := s.planRepo.GetByID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	return s.activate(ctx, tenantID, plan, paymentRef, time.Now())
+}
+
+func (s *subscriptionService) Renew(ctx context.Context, sub *domain.Subscription) (*domain.Subscription, error) {
+	plan, err := s.activePlan(ctx, sub.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	next, err := s.activate(ctx, sub.TenantID, plan, "", sub.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// The renewed period is over; the new one takes its place
+	sub.Status = domain.SubscriptionStatusExpired
+	sub.UpdatedAt = time.Now()
+	if err := s.subRepo.Update(ctx, sub); err != nil {
+		log.Printf("Failed to close renewed subscription %s: %v", sub.ID, err)
+	}
+	return next, nil
+}
+
+// activePlan returns the plan if tenants can still subscribe to it
+func (s *subscriptionService) activePlan(ctx context.Context, planID uuid.UUID) (*domain.Plan, error) {
 	plan, err := s.planRepo.GetByID(ctx, planID)
 	if err != nil {
 		return nil, err
 	}
 	if !plan.IsActive {
-		return nil, errors.New("plan is not active")
+		return nil, ErrPlanNotActive
 	}
+	return plan, nil
+}
 
-	// Calculate dates
-	startDate := time.Now()
-	var endDate time.Time
-	if plan.Interval == "YEARLY" {
-		endDate = startDate.AddDate(1, 0, 0)
-	} else {
-		endDate = startDate.AddDate(0, 1, 0)
+// activate starts a period of the plan at startDate, or where the current period ends when
+// the same plan is renewed early (e.g. paid through checkout after an expiry warning)
+func (s *subscriptionService) activate(ctx context.Context, tenantID uuid.UUID, plan *domain.Plan, paymentRef string, startDate time.Time) (*domain.Subscription, error) {
+	previous, err := s.subRepo.GetByTenantID(ctx, tenantID)
+	if err != nil {
+		return nil, err
 	}
+	if previous != nil && previous.PlanID == plan.ID && previous.Status == domain.SubscriptionStatusActive &&
+		previous.EndDate.After(startDate) {
+		startDate = previous.EndDate
+	}
+	endDate := plan.PeriodEnd(startDate)
 
 	// Create subscription
-	sub := domain.NewSubscription(tenantID, planID, startDate, endDate)
+	sub := domain.NewSubscription(tenantID, plan.ID, startDate, endDate)
 
 	// In a real system, we'd cancel existing active subscriptions first or queue this one
 	// For now, simpler: just create new one which becomes the "latest"
@@ -91,6 +203,23 @@ func (s *subscriptionService) Subscribe(ctx context.Context, tenantID, planID uu
 		return nil, err
 	}
 
+	change := domain.EntitlementSubscribed
+	if previous != nil {
+		change = domain.EntitlementPlanChanged
+		if previous.PlanID == plan.ID {
+			change = domain.EntitlementRenewed
+		}
+	}
+	s.recordEntitlements(ctx, sub, plan, change, startDate)
+	s.notifyChange(tenantID)
+
+	// Paid plans get a tax invoice; a failure here must not undo the subscription
+	if s.invoices != nil {
+		if _, err := s.invoices.IssueForSubscription(ctx, sub, plan, paymentRef); err != nil {
+			log.Printf("Failed to issue invoice for subscription %s: %v", sub.ID, err)
+		}
+	}
+
 	sub.Plan = plan // Attach plan for return
 	return sub, nil
 }
@@ -99,26 +228,81 @@ func (s *subscriptionService) GetSubscription(ctx context.Context, tenantID uuid
 	return s.subRepo.GetActiveByTenantID(ctx, tenantID)
 }
 
+// Entitlement History Implementation
+
+func (s *subscriptionService) GetEntitlementHistory(ctx context.Context, tenantID uuid.UUID) ([]*domain.EntitlementSnapshot, error) {
+	return s.entitlementRepo.ListByTenant(ctx, tenantID)
+}
+
+func (s *subscriptionService) GetEntitlementsAt(ctx context.Context, tenantID uuid.UUID, at time.Time) (*domain.EntitlementSnapshot, error) {
+	return s.entitlementRepo.GetAt(ctx, tenantID, at)
+}
+
+// recordEntitlements snapshots the plan for the tenant; history gaps are logged, not fatal
+func (s *subscriptionService) recordEntitlements(ctx context.Context, sub *domain.Subscription, plan *domain.Plan, change domain.EntitlementChange, effectiveFrom time.Time) {
+	recordEntitlementSnapshot(ctx, s.entitlementRepo, sub, plan, change, effectiveFrom)
+}
+
+func recordEntitlementSnapshot(ctx context.Context, repo repository.EntitlementRepository, sub *domain.Subscription, plan *domain.Plan, change domain.EntitlementChange, effectiveFrom time.Time) {
+	snapshot := domain.NewEntitlementSnapshot(sub, plan, change, effectiveFrom)
+	if userID, ok := db.GetUserID(ctx); ok {
+		snapshot.ChangedBy = &userID
+	}
+	if err := repo.Record(ctx, snapshot); err != nil {
+		log.Printf("Failed to record entitlement snapshot for tenant %s: %v", sub.TenantID, err)
+	}
+}
+
+func (s *subscriptionService) OnChange(fn func(tenantID uuid.UUID)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+func (s *subscriptionService) notifyChange(tenantID uuid.UUID) {
+	for _, fn := range s.listeners {
+		fn(tenantID)
+	}
+}
+
 // Feature Gating Implementation
 
-func (s *subscriptionService) HasFeature(ctx context.Context, tenantID uuid.UUID, feature string) (bool, error) {
-	sub, err := s.subRepo.GetActiveByTenantID(ctx, tenantID)
+func (s *subscriptionService) Features(ctx context.Context, tenantID uuid.UUID) (map[string]bool, error) {
+	cache := featureCacheFrom(ctx)
+	if features, ok := cache.get(tenantID); ok {
+		return features, nil
+	}
+
+	sub, err := s.subRepo.GetByTenantID(ctx, tenantID)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	if sub == nil {
-		// No active subscription -> No features (or maybe a default fallback?)
-		// For stricter SaaS, no sub = no access. For freemium, might have a default free plan.
-		// Assuming every tenant gets a Free plan on registration.
-		return false, nil
+	var plan *domain.Plan
+	if sub != nil && sub.EntitledAt(time.Now(), s.grace) {
+		plan = sub.Plan
+	} else if plan, err = s.FreePlan(ctx); err != nil {
+		return nil, err
 	}
 
-	// Check plan features
-	allowed, ok := sub.Plan.Features[feature]
-	if !ok {
-		return false, nil // Feature not listed = not allowed
+	features := map[string]bool{}
+	if plan != nil && plan.Features != nil {
+		features = plan.Features
+	}
+	cache.set(tenantID, features)
+	return features, nil
+}
+
+func (s *subscriptionService) FreePlan(ctx context.Context) (*domain.Plan, error) {
+	if s.freePlanCode == "" {
+		return nil, nil
+	}
+	return s.planRepo.GetByCode(ctx, s.freePlanCode)
+}
+
+func (s *subscriptionService) HasFeature(ctx context.Context, tenantID uuid.UUID, feature string) (bool, error) {
+	features, err := s.Features(ctx, tenantID)
+	if err != nil {
+		return false, err
 	}
-	return allowed, nil
+	return features[feature], nil // Feature not listed = not allowed
 }
 
 func (s *subscriptionService) CheckLimit(ctx context.Context, tenantID uuid.UUID, limitKey string, currentValue int) (bool, error) {
@@ -146,3 +330,15 @@ func (s *subscriptionService) CheckLimit(ctx context.Context, tenantID uuid.UUID
 
 	return currentValue < limit, nil
 }
+
+func (s *subscriptionService) CheckLimitAt(ctx context.Context, tenantID uuid.UUID, limitKey string, currentValue int, at time.Time) (bool, error) {
+	snapshot, err := s.entitlementRepo.GetAt(ctx, tenantID, at)
+	if err != nil {
+		return false, err
+	}
+	if snapshot == nil {
+		// No history for that date (before the tenant's first subscription)
+		return false, nil
+	}
+	return snapshot.AllowsLimit(limitKey, currentValue), nil
+}
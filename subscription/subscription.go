@@ -1,13 +1,33 @@
 package subscription
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aceextension/core/config"
 	"github.com/aceextension/core/db"
+	"github.com/aceextension/subscription/domain"
+	"github.com/aceextension/subscription/gateway"
 	"github.com/aceextension/subscription/repository"
 	"github.com/aceextension/subscription/service"
+	"github.com/google/uuid"
 )
 
+// ErrNotInitialized is returned by package functions called before Init
+var ErrNotInitialized = errors.New("subscription module not initialized")
+
 var (
-	Service service.SubscriptionService
+	Service       service.SubscriptionService
+	Metering      service.MeteringService
+	Invoices      service.InvoiceService
+	Enforcement   service.EnforcementService
+	Cancellations service.CancellationService
+	Checkout      service.CheckoutService
+	Renewals      service.RenewalService
+	// LimitChecker blocks creations past the plan's limits; see middleware.PlanLimitMiddleware
+	LimitChecker service.LimitChecker
 )
 
 // Init initializes the subscription module
@@ -18,5 +38,127 @@ func Init() {
 
 	planRepo := repository.NewPostgresPlanRepository(db.MainPool)
 	subRepo := repository.NewPostgresSubscriptionRepository(db.MainPool)
-	Service = service.NewSubscriptionService(planRepo, subRepo)
+	usageRepo := repository.NewPostgresUsageRepository(db.MainPool)
+	invoiceRepo := repository.NewPostgresInvoiceRepository(db.MainPool)
+
+	Invoices = service.NewInvoiceService(invoiceRepo, platformSeller(), platformVATRate())
+	entitlementRepo := repository.NewPostgresEntitlementRepository(db.MainPool)
+	Service = service.NewSubscriptionService(planRepo, subRepo, entitlementRepo, Invoices, gracePeriod(), freePlanCode())
+	Enforcement = service.NewEnforcementService(subRepo, repository.NewPostgresAccessOverrideRepository(db.MainPool), gracePeriod())
+	Metering = service.NewMeteringService(subRepo, usageRepo, repository.NewPostgresAPIUsageRepository(db.MainPool))
+	LimitChecker = service.NewLimitChecker(subRepo, Metering)
+	Cancellations = service.NewCancellationService(repository.NewPostgresCancellationRepository(db.MainPool),
+		subRepo, planRepo, entitlementRepo, Service, retentionPeriod())
+
+	Checkout = service.NewCheckoutService(repository.NewPostgresPaymentRepository(db.MainPool), Service,
+		platformVATRate(), paymentCallbackURL())
+	registerGateways(Checkout)
+	Renewals = service.NewRenewalService(subRepo, entitlementRepo, Service, expiryWarningPeriod(), gracePeriod())
+
+	// Plan changes affect limits and read-only enforcement immediately
+	Service.OnChange(Enforcement.Invalidate)
+	Service.OnChange(Metering.Invalidate)
+	Cancellations.OnChange(Enforcement.Invalidate)
+	Cancellations.OnChange(Metering.Invalidate)
+	Renewals.OnChange(Enforcement.Invalidate)
+	Renewals.OnChange(Metering.Invalidate)
+}
+
+// HasFeature reports whether the tenant's plan includes feature, e.g. "accounting".
+// Within a request gated by RequireFeature the plan's features are already loaded;
+// elsewhere wrap the context with service.WithFeatureCache to check several at once.
+func HasFeature(ctx context.Context, tenantID uuid.UUID, feature string) (bool, error) {
+	if Service == nil {
+		return false, ErrNotInitialized
+	}
+	return Service.HasFeature(ctx, tenantID, feature)
+}
+
+// platformSeller returns the platform's own legal details for subscription invoices
+func platformSeller() domain.Party {
+	seller := domain.Party{Name: "AceExtension Pvt. Ltd."}
+	cfg := config.GlobalConfig
+	if cfg == nil {
+		return seller
+	}
+	if cfg.PlatformLegalName != "" {
+		seller.Name = cfg.PlatformLegalName
+	}
+	if cfg.PlatformVATNumber != "" {
+		seller.VATNumber = &cfg.PlatformVATNumber
+	}
+	if cfg.PlatformAddress != "" {
+		seller.Address = &cfg.PlatformAddress
+	}
+	return seller
+}
+
+// platformVATRate returns the VAT percentage charged on subscriptions (Nepal standard rate by default)
+func platformVATRate() float64 {
+	if config.GlobalConfig != nil && config.GlobalConfig.PlatformVATRate > 0 {
+		return config.GlobalConfig.PlatformVATRate
+	}
+	return 13
+}
+
+// paymentCallbackURL is where payers return from eSewa and Khalti, on the public API origin
+func paymentCallbackURL() string {
+	cfg := config.GlobalConfig
+	if cfg == nil {
+		return "/api/v1/subscriptions/payments/callback"
+	}
+	origin := cfg.PublicAPIURL
+	if origin == "" {
+		origin = cfg.AppURL
+	}
+	return strings.TrimRight(origin, "/") + "/api/v1/subscriptions/payments/callback"
+}
+
+// registerGateways offers the gateways whose merchant keys are configured
+func registerGateways(checkout service.CheckoutService) {
+	cfg := config.GlobalConfig
+	if cfg == nil {
+		return
+	}
+	if cfg.ESewaProductCode != "" && cfg.ESewaSecretKey != "" {
+		checkout.RegisterGateway(gateway.NewESewa(cfg.ESewaProductCode, cfg.ESewaSecretKey, cfg.PaymentGatewaySandbox))
+	}
+	if cfg.KhaltiSecretKey != "" {
+		checkout.RegisterGateway(gateway.NewKhalti(cfg.KhaltiSecretKey, cfg.PaymentGatewaySandbox))
+	}
+}
+
+// gracePeriod returns how long an expired tenant keeps full access before read-only mode
+func gracePeriod() time.Duration {
+	days := 7
+	if config.GlobalConfig != nil && config.GlobalConfig.SubscriptionGraceDays >= 0 {
+		days = config.GlobalConfig.SubscriptionGraceDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// freePlanCode returns the code of the plan tenants without a current subscription get
+func freePlanCode() string {
+	if config.GlobalConfig != nil {
+		return config.GlobalConfig.FreePlanCode
+	}
+	return "FREE"
+}
+
+// expiryWarningPeriod returns how long before a period ends the tenant is warned
+func expiryWarningPeriod() time.Duration {
+	days := 7
+	if config.GlobalConfig != nil && config.GlobalConfig.ExpiryWarningDays > 0 {
+		days = config.GlobalConfig.ExpiryWarningDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// retentionPeriod returns how long a cancelled tenant's data is kept before purge
+func retentionPeriod() time.Duration {
+	days := 90
+	if config.GlobalConfig != nil && config.GlobalConfig.DataRetentionDays > 0 {
+		days = config.GlobalConfig.DataRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
 }
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Entity types that can be tagged
+const (
+	EntityProduct  = "product"
+	EntityCustomer = "customer"
+	EntitySupplier = "supplier"
+)
+
+// MaxTagNameLength bounds a tag name in characters
+const MaxTagNameLength = 50
+
+// MaxBulkEntities bounds how many records one tag or untag request may touch
+const MaxBulkEntities = 1000
+
+var (
+	// ErrUnknownEntityType is returned for entity types no module has registered
+	ErrUnknownEntityType = errors.New("tags are not supported for this entity type")
+	// ErrEntityNotFound is returned when a target record does not exist for the tenant
+	ErrEntityNotFound = errors.New("entity not found")
+	// ErrTagNotFound is returned when a tag does not exist for the tenant
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrTagExists is returned when the tenant already has a tag with the name
+	ErrTagExists = errors.New("a tag with this name already exists")
+	// ErrInvalidTag is returned for an empty or over-long name, a name with punctuation, or a malformed color
+	ErrInvalidTag = errors.New("invalid tag")
+	// ErrTooManyEntities is returned when a bulk request exceeds MaxBulkEntities
+	ErrTooManyEntities = errors.New("too many records in one request")
+)
+
+// colorPattern matches a #RRGGBB color for tag chips
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// Tag is a tenant's label (e.g. "monsoon-sale", "wholesale-west") that can be put on
+// products, customers and suppliers
+type Tag struct {
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	Name        string
+	Color       *string
+	Description *string
+	UsageCount  int // Records carrying the tag; filled by list queries
+	CreatedBy   *uuid.UUID
+
+	// Metadata
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewTag creates a tag with a normalized name
+func NewTag(tenantID uuid.UUID, name string, createdBy *uuid.UUID) *Tag {
+	now := time.Now()
+	return &Tag{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Name:      NormalizeTagName(name),
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NormalizeTagName lower-cases a name and joins its words with dashes, so
+// "Monsoon Sale" and "monsoon-sale" are the same tag
+func NormalizeTagName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+// Validate checks the name and color
+func (t *Tag) Validate() error {
+	if err := validateTagName(t.Name); err != nil {
+		return err
+	}
+	if t.Color != nil && !colorPattern.MatchString(*t.Color) {
+		return errors.Join(ErrInvalidTag, errors.New("color must be #RRGGBB"))
+	}
+	return nil
+}
+
+// validateTagName accepts letters in any script, digits, dashes and underscores
+func validateTagName(name string) error {
+	if name == "" || utf8.RuneCountInString(name) > MaxTagNameLength {
+		return errors.Join(ErrInvalidTag, errors.New("name must be 1 to 50 characters"))
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r) && r != '-' && r != '_' {
+			return errors.Join(ErrInvalidTag, errors.New("name may only contain letters, digits, - and _"))
+		}
+	}
+	return nil
+}
+
+// NormalizeTagNames normalizes, validates and de-duplicates names
+func NormalizeTagNames(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = NormalizeTagName(name)
+		if err := validateTagName(name); err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized, nil
+}
+
+// TagFilter narrows a list to records carrying tags
+type TagFilter struct {
+	Names    []string
+	MatchAll bool // Every tag rather than any of them
+}
+
+// MinMatches is how many of the filter's tags a record needs
+func (f *TagFilter) MinMatches() int {
+	if f.MatchAll {
+		return len(f.Names)
+	}
+	return 1
+}
+
+// ParseTagFilter reads the tags and tagMatch query parameters: a comma-separated list
+// and "all" (default) or "any". It returns nil when no tags are given.
+func ParseTagFilter(tags, match string) (*TagFilter, error) {
+	if strings.TrimSpace(tags) == "" {
+		return nil, nil
+	}
+	names, err := NormalizeTagNames(strings.Split(tags, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	switch match {
+	case "", "all":
+		return &TagFilter{Names: names, MatchAll: true}, nil
+	case "any":
+		return &TagFilter{Names: names}, nil
+	}
+	return nil, errors.Join(ErrInvalidTag, errors.New("tagMatch must be all or any"))
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(e *echo.Group, tagHandler *TagHandler) {
+	tagsGroup := e.Group("/tags")
+
+	// Registry
+	tagsGroup.GET("", tagHandler.ListTags)
+	tagsGroup.POST("", tagHandler.CreateTag)
+	tagsGroup.GET("/:id", tagHandler.GetTag)
+	tagsGroup.PUT("/:id", tagHandler.UpdateTag)
+	tagsGroup.DELETE("/:id", tagHandler.DeleteTag)
+
+	// Assignments
+	tagsGroup.POST("/assign", tagHandler.AssignTags)
+	tagsGroup.POST("/unassign", tagHandler.UnassignTags)
+	tagsGroup.GET("/entities", tagHandler.ListEntityTags)
+}
@@ -0,0 +1,345 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/tags/domain"
+	"github.com/aceextension/tags/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type TagHandler struct {
+	service service.TagService
+}
+
+func NewTagHandler(service service.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+// TagRequest is the body for creating or updating a tag
+type TagRequest struct {
+	Name        string  `json:"name"`
+	Color       *string `json:"color"`
+	Description *string `json:"description"`
+}
+
+// BulkTagRequest is the body for tagging or untagging a batch of records
+type BulkTagRequest struct {
+	EntityType string      `json:"entityType"`
+	EntityIDs  []uuid.UUID `json:"entityIds"`
+	Tags       []string    `json:"tags"`
+}
+
+// BulkTagResponse reports how many assignments a bulk request changed
+type BulkTagResponse struct {
+	Changed int64 `json:"changed"`
+}
+
+// TagResponse is the API representation of a tag
+type TagResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Color       *string   `json:"color"`
+	Description *string   `json:"description"`
+	UsageCount  int       `json:"usageCount"`
+	CreatedAt   string    `json:"createdAt"`
+	UpdatedAt   string    `json:"updatedAt"`
+}
+
+func toTagResponse(t *domain.Tag) TagResponse {
+	return TagResponse{
+		ID:          t.ID,
+		Name:        t.Name,
+		Color:       t.Color,
+		Description: t.Description,
+		UsageCount:  t.UsageCount,
+		CreatedAt:   t.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:   t.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// ListTags lists the tenant's tags
+// @Summary List Tags
+// @Description List tags with how many records carry each, for pickers and filter chips
+// @Tags Tags
+// @Produce json
+// @Param entityType query string false "Count usage on one entity type (product, customer, supplier)"
+// @Param q query string false "Name prefix"
+// @Success 200 {array} TagResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/tags [get]
+func (h *TagHandler) ListTags(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	tags, err := h.service.List(c.Request().Context(), tenantID, c.QueryParam("entityType"), c.QueryParam("q"))
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	response := make([]TagResponse, 0, len(tags))
+	for _, t := range tags {
+		response = append(response, toTagResponse(t))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// CreateTag adds a tag to the tenant's registry
+// @Summary Create Tag
+// @Description Create a tag; the name is lower-cased and its words joined with dashes
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param request body TagRequest true "Tag"
+// @Success 201 {object} TagResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/tags [post]
+func (h *TagHandler) CreateTag(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	var req TagRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tag, err := h.service.Create(c.Request().Context(), tenantID, toTagInput(req), &userID)
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, toTagResponse(tag))
+}
+
+// GetTag retrieves a tag
+// @Summary Get Tag
+// @Description Get a tag with its usage count
+// @Tags Tags
+// @Produce json
+// @Param id path string true "Tag ID"
+// @Success 200 {object} TagResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/tags/{id} [get]
+func (h *TagHandler) GetTag(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tag ID"})
+	}
+
+	tag, err := h.service.Get(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toTagResponse(tag))
+}
+
+// UpdateTag renames or recolors a tag
+// @Summary Update Tag
+// @Description Rename, recolor or describe a tag; tagged records keep it
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Tag ID"
+// @Param request body TagRequest true "Tag"
+// @Success 200 {object} TagResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/tags/{id} [put]
+func (h *TagHandler) UpdateTag(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tag ID"})
+	}
+
+	var req TagRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tag, err := h.service.Update(c.Request().Context(), tenantID, id, toTagInput(req))
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toTagResponse(tag))
+}
+
+// DeleteTag removes a tag
+// @Summary Delete Tag
+// @Description Delete a tag and take it off every record
+// @Tags Tags
+// @Param id path string true "Tag ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/tags/{id} [delete]
+func (h *TagHandler) DeleteTag(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tag ID"})
+	}
+
+	if err := h.service.Delete(c.Request().Context(), tenantID, id); err != nil {
+		return tagError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AssignTags tags a batch of records
+// @Summary Bulk Tag
+// @Description Put tags on up to 1000 products, customers or suppliers; missing tags are created
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param request body BulkTagRequest true "Records and tags"
+// @Success 200 {object} BulkTagResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/tags/assign [post]
+func (h *TagHandler) AssignTags(c echo.Context) error {
+	return h.bulk(c, h.service.Tag)
+}
+
+// UnassignTags untags a batch of records
+// @Summary Bulk Untag
+// @Description Take tags off up to 1000 products, customers or suppliers
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param request body BulkTagRequest true "Records and tags"
+// @Success 200 {object} BulkTagResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/tags/unassign [post]
+func (h *TagHandler) UnassignTags(c echo.Context) error {
+	return h.bulk(c, h.service.Untag)
+}
+
+// ListEntityTags returns the tags of a batch of records
+// @Summary List Record Tags
+// @Description Tag names of each record, for list views
+// @Tags Tags
+// @Produce json
+// @Param entityType query string true "Entity type (product, customer, supplier)"
+// @Param ids query string true "Comma-separated record IDs"
+// @Success 200 {object} map[string][]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/tags/entities [get]
+func (h *TagHandler) ListEntityTags(c echo.Context) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+
+	var ids []uuid.UUID
+	for _, raw := range strings.Split(c.QueryParam("ids"), ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID: " + raw})
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) > domain.MaxBulkEntities {
+		return tagError(c, domain.ErrTooManyEntities)
+	}
+
+	names, err := h.service.EntityTags(c.Request().Context(), tenantID, c.QueryParam("entityType"), ids)
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, names)
+}
+
+func (h *TagHandler) bulk(c echo.Context, apply func(ctx context.Context, input service.BulkTagInput) (int64, error)) error {
+	tenantID, ok := db.GetTenantID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Tenant ID not found"})
+	}
+	userID, ok := db.GetUserID(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User ID not found"})
+	}
+
+	var req BulkTagRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	changed, err := apply(c.Request().Context(), service.BulkTagInput{
+		TenantID:   tenantID,
+		UserID:     &userID,
+		EntityType: req.EntityType,
+		EntityIDs:  req.EntityIDs,
+		Tags:       req.Tags,
+	})
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, BulkTagResponse{Changed: changed})
+}
+
+func toTagInput(req TagRequest) service.TagInput {
+	return service.TagInput{
+		Name:        req.Name,
+		Color:       req.Color,
+		Description: req.Description,
+	}
+}
+
+func tagError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrUnknownEntityType), errors.Is(err, domain.ErrInvalidTag), errors.Is(err, domain.ErrTooManyEntities):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrTagNotFound), errors.Is(err, domain.ErrEntityNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain.ErrTagExists):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
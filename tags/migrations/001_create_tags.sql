@@ -0,0 +1,55 @@
+-- ============================================================================
+-- TAGS
+-- Tenant-defined labels ("monsoon-sale", "wholesale-west") and their
+-- assignments to products, customers and suppliers.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    name VARCHAR(50) NOT NULL, -- lower-case, words joined with dashes
+    color VARCHAR(7), -- #RRGGBB
+    description TEXT,
+    created_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT fk_tag_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT uq_tags_name UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS tag_assignments (
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    entity_type VARCHAR(50) NOT NULL, -- product, customer, supplier
+    entity_id UUID NOT NULL,
+    created_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tag_id, entity_type, entity_id)
+);
+
+-- Tag filters look up records by tag; tag chips look up tags by record
+CREATE INDEX IF NOT EXISTS idx_tag_assignments_entity
+    ON tag_assignments(tenant_id, entity_type, entity_id);
+
+-- ============================================================================
+-- ROW LEVEL SECURITY
+-- ============================================================================
+
+ALTER TABLE tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tag_assignments ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON tags;
+CREATE POLICY tenant_isolation ON tags
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
+
+DROP POLICY IF EXISTS tenant_isolation ON tag_assignments;
+CREATE POLICY tenant_isolation ON tag_assignments
+    USING (
+        tenant_id = current_setting('app.current_tenant_id', true)::uuid
+        OR current_setting('app.is_super_admin', true)::boolean = true
+    );
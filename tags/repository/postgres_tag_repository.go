@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aceextension/core/db"
+	"github.com/aceextension/tags/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresTagRepository implements TagRepository using PostgreSQL
+type PostgresTagRepository struct{}
+
+// NewPostgresTagRepository creates a new PostgreSQL tag repository
+func NewPostgresTagRepository() *PostgresTagRepository {
+	return &PostgresTagRepository{}
+}
+
+const tagColumns = `t.id, t.tenant_id, t.name, t.color, t.description, t.created_by, t.created_at, t.updated_at`
+
+// Create stores a new tag
+func (r *PostgresTagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	query := `
+		INSERT INTO tags (id, tenant_id, name, color, description, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := db.MainPool.Exec(ctx, query,
+		tag.ID, tag.TenantID, tag.Name, tag.Color, tag.Description, tag.CreatedBy, tag.CreatedAt, tag.UpdatedAt,
+	)
+	if isNameConflict(err) {
+		return domain.ErrTagExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a tag of the tenant with its usage count
+func (r *PostgresTagRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Tag, error) {
+	query := `
+		SELECT ` + tagColumns + `,
+			(SELECT COUNT(*) FROM tag_assignments ta WHERE ta.tag_id = t.id)
+		FROM tags t
+		WHERE t.id = $1 AND t.tenant_id = $2
+	`
+	return r.scanTag(db.MainPool.QueryRow(ctx, query, id, tenantID))
+}
+
+// List retrieves the tenant's tags with usage counts
+func (r *PostgresTagRepository) List(ctx context.Context, tenantID uuid.UUID, entityType, query string) ([]*domain.Tag, error) {
+	sql := `
+		SELECT ` + tagColumns + `, COUNT(ta.entity_id)
+		FROM tags t
+		LEFT JOIN tag_assignments ta ON ta.tag_id = t.id AND ($2 = '' OR ta.entity_type = $2)
+		WHERE t.tenant_id = $1 AND ($3 = '' OR t.name LIKE $3 || '%')
+		GROUP BY t.id
+		ORDER BY t.name
+	`
+
+	rows, err := db.MainPool.Query(ctx, sql, tenantID, entityType, domain.NormalizeTagName(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []*domain.Tag{}
+	for rows.Next() {
+		tag, err := r.scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return tags, nil
+}
+
+// Update saves a tag's name, color and description
+func (r *PostgresTagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	query := `
+		UPDATE tags SET name = $3, color = $4, description = $5, updated_at = $6
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	result, err := db.MainPool.Exec(ctx, query, tag.ID, tag.TenantID, tag.Name, tag.Color, tag.Description, tag.UpdatedAt)
+	if isNameConflict(err) {
+		return domain.ErrTagExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update tag: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrTagNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a tag; its assignments go with it
+func (r *PostgresTagRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	result, err := db.MainPool.Exec(ctx, `DELETE FROM tags WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrTagNotFound
+	}
+
+	return nil
+}
+
+// Assign creates missing tags and assigns every tag to every record in one transaction
+func (r *PostgresTagRepository) Assign(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID, names []string, createdBy *uuid.UUID) (int64, error) {
+	var assigned int64
+	err := db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		ensure := `
+			INSERT INTO tags (id, tenant_id, name, created_by, created_at, updated_at)
+			SELECT gen_random_uuid(), $1, name, $3, NOW(), NOW()
+			FROM unnest($2::text[]) AS name
+			ON CONFLICT (tenant_id, name) DO NOTHING
+		`
+		if _, err := tx.Exec(ctx, ensure, tenantID, names, createdBy); err != nil {
+			return fmt.Errorf("failed to create tags: %w", err)
+		}
+
+		assign := `
+			INSERT INTO tag_assignments (tag_id, tenant_id, entity_type, entity_id, created_by, created_at)
+			SELECT t.id, $1, $2, e.id, $5, NOW()
+			FROM tags t
+			CROSS JOIN unnest($3::uuid[]) AS e(id)
+			WHERE t.tenant_id = $1 AND t.name = ANY($4)
+			ON CONFLICT DO NOTHING
+		`
+		result, err := tx.Exec(ctx, assign, tenantID, entityType, entityIDs, names, createdBy)
+		if err != nil {
+			return fmt.Errorf("failed to assign tags: %w", err)
+		}
+		assigned = result.RowsAffected()
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return assigned, nil
+}
+
+// Unassign removes the named tags from the records
+func (r *PostgresTagRepository) Unassign(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID, names []string) (int64, error) {
+	query := `
+		DELETE FROM tag_assignments ta
+		USING tags t
+		WHERE t.id = ta.tag_id AND ta.tenant_id = $1 AND ta.entity_type = $2
+			AND ta.entity_id = ANY($3) AND t.name = ANY($4)
+	`
+
+	result, err := db.MainPool.Exec(ctx, query, tenantID, entityType, entityIDs, names)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unassign tags: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// NamesByEntities lists tag names per record, alphabetically
+func (r *PostgresTagRepository) NamesByEntities(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	names := make(map[uuid.UUID][]string)
+	if len(entityIDs) == 0 {
+		return names, nil
+	}
+
+	query := `
+		SELECT ta.entity_id, t.name
+		FROM tag_assignments ta
+		JOIN tags t ON t.id = ta.tag_id
+		WHERE ta.tenant_id = $1 AND ta.entity_type = $2 AND ta.entity_id = ANY($3)
+		ORDER BY ta.entity_id, t.name
+	`
+
+	rows, err := db.MainPool.Query(ctx, query, tenantID, entityType, entityIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entity tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan entity tag: %w", err)
+		}
+		names[id] = append(names[id], name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return names, nil
+}
+
+// scanTag scans a tag row followed by its usage count
+func (r *PostgresTagRepository) scanTag(row pgx.Row) (*domain.Tag, error) {
+	var t domain.Tag
+
+	err := row.Scan(
+		&t.ID, &t.TenantID, &t.Name, &t.Color, &t.Description, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt,
+		&t.UsageCount,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTagNotFound
+		}
+		return nil, fmt.Errorf("failed to scan tag: %w", err)
+	}
+
+	return &t, nil
+}
+
+// isNameConflict reports whether err is the tenant's unique tag name constraint
+func isNameConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uq_tags_name"
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aceextension/tags/domain"
+	"github.com/google/uuid"
+)
+
+// TagRepository defines the interface for tag and tag assignment access
+type TagRepository interface {
+	Create(ctx context.Context, tag *domain.Tag) error
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Tag, error)
+	// List returns the tenant's tags by name with usage counts. A non-empty entityType
+	// counts only that type's records; query matches a name prefix.
+	List(ctx context.Context, tenantID uuid.UUID, entityType, query string) ([]*domain.Tag, error)
+	Update(ctx context.Context, tag *domain.Tag) error
+	// Delete removes a tag and takes it off every record
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+
+	// Assign puts tags on records, creating tags the tenant does not have yet.
+	// It returns how many new assignments were made; existing ones are kept.
+	Assign(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID, names []string, createdBy *uuid.UUID) (int64, error)
+	// Unassign takes tags off records and returns how many assignments were removed
+	Unassign(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID, names []string) (int64, error)
+	// NamesByEntities returns tag names keyed by entity ID; untagged entities are omitted
+	NamesByEntities(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"github.com/aceextension/tags/domain"
+	"github.com/google/uuid"
+)
+
+// EntityCountFunc reports how many of the given records of a registered entity type exist for the tenant
+type EntityCountFunc func(ctx context.Context, tenantID uuid.UUID, entityIDs []uuid.UUID) (int, error)
+
+// TagInput holds the editable fields of a tag
+type TagInput struct {
+	Name        string
+	Color       *string
+	Description *string
+}
+
+// BulkTagInput puts tags on, or takes them off, a batch of records of one type
+type BulkTagInput struct {
+	TenantID   uuid.UUID
+	UserID     *uuid.UUID
+	EntityType string
+	EntityIDs  []uuid.UUID
+	Tags       []string
+}
+
+// TagService defines the interface for tag business logic
+type TagService interface {
+	// RegisterEntityType enables tagging for an entity type owned by another module
+	RegisterEntityType(entityType string, count EntityCountFunc)
+
+	Create(ctx context.Context, tenantID uuid.UUID, input TagInput, userID *uuid.UUID) (*domain.Tag, error)
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Tag, error)
+	// List returns the tenant's tags; entityType narrows usage counts and query matches a name prefix
+	List(ctx context.Context, tenantID uuid.UUID, entityType, query string) ([]*domain.Tag, error)
+	// Update renames or recolors a tag; records keep it under the new name
+	Update(ctx context.Context, tenantID, id uuid.UUID, input TagInput) (*domain.Tag, error)
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+
+	// Tag puts tags on records, creating tags the tenant does not have yet, and
+	// returns how many new assignments were made
+	Tag(ctx context.Context, input BulkTagInput) (int64, error)
+	// Untag takes tags off records and returns how many assignments were removed
+	Untag(ctx context.Context, input BulkTagInput) (int64, error)
+	// EntityTags returns tag names for list views, keyed by entity ID
+	EntityTags(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+}
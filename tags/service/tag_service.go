@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aceextension/tags/domain"
+	"github.com/aceextension/tags/repository"
+	"github.com/google/uuid"
+)
+
+// tagService implements TagService
+type tagService struct {
+	repo repository.TagRepository
+
+	mu          sync.RWMutex
+	entityTypes map[string]EntityCountFunc
+}
+
+// NewTagService creates a new tag service
+func NewTagService(repo repository.TagRepository) TagService {
+	return &tagService{
+		repo:        repo,
+		entityTypes: make(map[string]EntityCountFunc),
+	}
+}
+
+// RegisterEntityType enables tagging for an entity type
+func (s *tagService) RegisterEntityType(entityType string, count EntityCountFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entityTypes[entityType] = count
+}
+
+// Create adds a tag to the tenant's registry
+func (s *tagService) Create(ctx context.Context, tenantID uuid.UUID, input TagInput, userID *uuid.UUID) (*domain.Tag, error) {
+	tag := domain.NewTag(tenantID, input.Name, userID)
+	tag.Color = input.Color
+	tag.Description = input.Description
+	if err := tag.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, tag); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// Get retrieves a tag with its usage count
+func (s *tagService) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Tag, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// List retrieves the tenant's tags
+func (s *tagService) List(ctx context.Context, tenantID uuid.UUID, entityType, query string) ([]*domain.Tag, error) {
+	if entityType != "" && !s.registered(entityType) {
+		return nil, domain.ErrUnknownEntityType
+	}
+	return s.repo.List(ctx, tenantID, entityType, query)
+}
+
+// Update changes a tag's name, color and description
+func (s *tagService) Update(ctx context.Context, tenantID, id uuid.UUID, input TagInput) (*domain.Tag, error) {
+	tag, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tag.Name = domain.NormalizeTagName(input.Name)
+	tag.Color = input.Color
+	tag.Description = input.Description
+	tag.UpdatedAt = time.Now()
+	if err := tag.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, tag); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// Delete removes a tag from the registry and from every record
+func (s *tagService) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// Tag assigns tags to a batch of records
+func (s *tagService) Tag(ctx context.Context, input BulkTagInput) (int64, error) {
+	names, entityIDs, err := s.prepareBulk(ctx, input)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.Assign(ctx, input.TenantID, input.EntityType, entityIDs, names, input.UserID)
+}
+
+// Untag removes tags from a batch of records
+func (s *tagService) Untag(ctx context.Context, input BulkTagInput) (int64, error) {
+	names, entityIDs, err := s.prepareBulk(ctx, input)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.Unassign(ctx, input.TenantID, input.EntityType, entityIDs, names)
+}
+
+// EntityTags retrieves tag names for a batch of records
+func (s *tagService) EntityTags(ctx context.Context, tenantID uuid.UUID, entityType string, entityIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	if !s.registered(entityType) {
+		return nil, domain.ErrUnknownEntityType
+	}
+	return s.repo.NamesByEntities(ctx, tenantID, entityType, entityIDs)
+}
+
+// prepareBulk validates a bulk request and checks that every record belongs to the tenant,
+// so tags cannot be put on another tenant's records or on IDs that do not exist
+func (s *tagService) prepareBulk(ctx context.Context, input BulkTagInput) ([]string, []uuid.UUID, error) {
+	s.mu.RLock()
+	count, ok := s.entityTypes[input.EntityType]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, nil, domain.ErrUnknownEntityType
+	}
+
+	if len(input.Tags) == 0 {
+		return nil, nil, fmt.Errorf("%w: at least one tag is required", domain.ErrInvalidTag)
+	}
+	names, err := domain.NormalizeTagNames(input.Tags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool, len(input.EntityIDs))
+	entityIDs := make([]uuid.UUID, 0, len(input.EntityIDs))
+	for _, id := range input.EntityIDs {
+		if !seen[id] {
+			seen[id] = true
+			entityIDs = append(entityIDs, id)
+		}
+	}
+	if len(entityIDs) == 0 {
+		return nil, nil, domain.ErrEntityNotFound
+	}
+	if len(entityIDs) > domain.MaxBulkEntities {
+		return nil, nil, domain.ErrTooManyEntities
+	}
+
+	found, err := count(ctx, input.TenantID, entityIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if found != len(entityIDs) {
+		return nil, nil, domain.ErrEntityNotFound
+	}
+
+	return names, entityIDs, nil
+}
+
+func (s *tagService) registered(entityType string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.entityTypes[entityType]
+	return ok
+}
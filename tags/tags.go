@@ -0,0 +1,18 @@
+package tags
+
+import (
+	"github.com/aceextension/tags/repository"
+	"github.com/aceextension/tags/service"
+)
+
+// Module-level service instances
+var (
+	TagService service.TagService
+)
+
+// Init initializes the tags module.
+// Modules owning taggable records (catalog products, crm customers and suppliers)
+// register their entity types through TagService.RegisterEntityType.
+func Init() {
+	TagService = service.NewTagService(repository.NewPostgresTagRepository())
+}